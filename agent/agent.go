@@ -57,6 +57,7 @@ type Options struct {
 	StatsReporter               StatsReporter
 	WorkspaceAgentApps          WorkspaceAgentApps
 	PostWorkspaceAgentAppHealth PostWorkspaceAgentAppHealth
+	PostStartupScriptResult     PostStartupScriptResult
 	ReconnectingPTYTimeout      time.Duration
 	EnvironmentVariables        map[string]string
 	Logger                      slog.Logger
@@ -69,6 +70,10 @@ type CoordinatorDialer func(context.Context) (net.Conn, error)
 // FetchMetadata is a function to obtain metadata for the agent.
 type FetchMetadata func(context.Context) (codersdk.WorkspaceAgentMetadata, error)
 
+// PostStartupScriptResult reports the exit code of the startup script, so
+// coderd can rebuild workspaces whose startup scripts fail repeatedly.
+type PostStartupScriptResult func(context.Context, codersdk.PostWorkspaceAgentStartupScriptRequest) error
+
 func New(options Options) io.Closer {
 	if options.ReconnectingPTYTimeout == 0 {
 		options.ReconnectingPTYTimeout = 5 * time.Minute
@@ -86,6 +91,7 @@ func New(options Options) io.Closer {
 		statsReporter:               options.StatsReporter,
 		workspaceAgentApps:          options.WorkspaceAgentApps,
 		postWorkspaceAgentAppHealth: options.PostWorkspaceAgentAppHealth,
+		postStartupScriptResult:     options.PostStartupScriptResult,
 	}
 	server.init(ctx)
 	return server
@@ -111,9 +117,11 @@ type agent struct {
 	network                     *tailnet.Conn
 	coordinatorDialer           CoordinatorDialer
 	stats                       *Stats
+	usage                       usageCollector
 	statsReporter               StatsReporter
 	workspaceAgentApps          WorkspaceAgentApps
 	postWorkspaceAgentAppHealth PostWorkspaceAgentAppHealth
+	postStartupScriptResult     PostStartupScriptResult
 }
 
 func (a *agent) run(ctx context.Context) {
@@ -153,6 +161,9 @@ func (a *agent) run(ctx context.Context) {
 		if err != nil {
 			a.logger.Warn(ctx, "agent script failed", slog.Error(err))
 		}
+		if metadata.StartupScript != "" && a.postStartupScriptResult != nil {
+			a.reportStartupScriptResult(ctx, err)
+		}
 	}()
 
 	if metadata.DERPMap != nil {
@@ -377,6 +388,31 @@ func (a *agent) runStartupScript(ctx context.Context, script string) error {
 	return nil
 }
 
+// reportStartupScriptResult reports the exit code of the startup script given
+// the error it returned. Scripts that couldn't be run at all report -1.
+func (a *agent) reportStartupScriptResult(ctx context.Context, scriptErr error) {
+	var exitCode int32
+	if scriptErr != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(scriptErr, &exitErr) {
+			exitCode = int32(exitErr.ExitCode())
+		}
+	}
+	for retrier := retry.New(50*time.Millisecond, 10*time.Second); retrier.Wait(ctx); {
+		err := a.postStartupScriptResult(ctx, codersdk.PostWorkspaceAgentStartupScriptRequest{
+			ExitCode: exitCode,
+		})
+		if err == nil {
+			return
+		}
+		if errors.Is(err, context.Canceled) || a.isClosed() {
+			return
+		}
+		a.logger.Warn(ctx, "failed to report startup script result", slog.Error(err))
+	}
+}
+
 func (a *agent) init(ctx context.Context) {
 	a.logger.Info(ctx, "generating host key")
 	// Clients' should ignore the host key when connecting.
@@ -465,7 +501,17 @@ func (a *agent) init(ctx context.Context) {
 	go a.run(ctx)
 	if a.statsReporter != nil {
 		cl, err := a.statsReporter(ctx, a.logger, func() *codersdk.AgentStats {
-			return a.stats.Copy()
+			stats := a.stats.Copy()
+			var directory string
+			if metadata, ok := a.metadata.Load().(codersdk.WorkspaceAgentMetadata); ok {
+				directory = metadata.Directory
+			}
+			usage, err := a.usage.collect(directory)
+			if err != nil {
+				a.logger.Debug(ctx, "collect usage", slog.Error(err))
+			}
+			stats.Usage = usage
+			return stats
 		})
 		if err != nil {
 			a.logger.Error(ctx, "report stats", slog.Error(err))
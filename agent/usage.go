@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/elastic/go-sysinfo"
+	"github.com/elastic/go-sysinfo/types"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// usageCollector samples the resource usage of the machine the agent runs
+// on. CPU usage is averaged between samples, so the previous CPU times are
+// kept.
+type usageCollector struct {
+	mut     sync.Mutex
+	lastCPU types.CPUTimes
+}
+
+// collect samples the resource usage, with the disk usage being of the
+// filesystem holding dir.
+func (u *usageCollector) collect(dir string) (*codersdk.AgentUsage, error) {
+	host, err := sysinfo.Host()
+	if err != nil {
+		return nil, xerrors.Errorf("get host: %w", err)
+	}
+	cpu, err := host.CPUTime()
+	if err != nil {
+		return nil, xerrors.Errorf("get cpu time: %w", err)
+	}
+	memory, err := host.Memory()
+	if err != nil {
+		return nil, xerrors.Errorf("get memory: %w", err)
+	}
+	if dir == "" {
+		dir, err = os.UserHomeDir()
+		if err != nil {
+			return nil, xerrors.Errorf("get home directory: %w", err)
+		}
+	}
+	diskUsed, diskTotal, err := diskUsage(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("get disk usage of %q: %w", dir, err)
+	}
+
+	u.mut.Lock()
+	last := u.lastCPU
+	u.lastCPU = cpu
+	u.mut.Unlock()
+
+	cores := float64(runtime.NumCPU())
+	usage := &codersdk.AgentUsage{
+		CPUTotal:         cores,
+		MemoryUsedBytes:  int64(memory.Total - memory.Available),
+		MemoryTotalBytes: int64(memory.Total),
+		DiskUsedBytes:    int64(diskUsed),
+		DiskTotalBytes:   int64(diskTotal),
+	}
+	// The first sample is averaged since the machine booted.
+	total := cpu.Total() - last.Total()
+	if total > 0 {
+		idle := (cpu.Idle + cpu.IOWait) - (last.Idle + last.IOWait)
+		usage.CPUUsed = float64(total-idle) / float64(total) * cores
+	}
+	return usage, nil
+}
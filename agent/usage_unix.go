@@ -0,0 +1,21 @@
+//go:build !windows
+
+package agent
+
+import (
+	"syscall"
+)
+
+// diskUsage returns the used and total bytes of the filesystem holding path.
+func diskUsage(path string) (used uint64, total uint64, err error) {
+	var stat syscall.Statfs_t
+	err = syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, 0, err
+	}
+	//nolint:unconvert // The types of the fields differ between platforms.
+	bsize := uint64(stat.Bsize)
+	total = uint64(stat.Blocks) * bsize
+	used = total - uint64(stat.Bfree)*bsize
+	return used, total, nil
+}
@@ -0,0 +1,19 @@
+package agent
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// diskUsage returns the used and total bytes of the volume holding path.
+func diskUsage(path string) (used uint64, total uint64, err error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var free uint64
+	err = windows.GetDiskFreeSpaceEx(pathPtr, nil, &total, &free)
+	if err != nil {
+		return 0, 0, err
+	}
+	return total - free, total, nil
+}
@@ -201,6 +201,7 @@ func workspaceAgent() *cobra.Command {
 				StatsReporter:               client.AgentReportStats,
 				WorkspaceAgentApps:          client.WorkspaceAgentApps,
 				PostWorkspaceAgentAppHealth: client.PostWorkspaceAgentAppHealth,
+				PostStartupScriptResult:     client.PostWorkspaceAgentStartupScript,
 			})
 			<-cmd.Context().Done()
 			return closer.Close()
@@ -120,6 +120,12 @@ func Flags() codersdk.DeploymentFlags {
 			Description: "The bind address to serve pprof.",
 			Default:     "127.0.0.1:6060",
 		},
+		GRPCAddress: codersdk.StringFlag{
+			Name:        "gRPC Address",
+			Flag:        "grpc-address",
+			EnvVar:      "CODER_GRPC_ADDRESS",
+			Description: "The bind address to serve the gRPC API. It's disabled if empty, and uses the TLS configuration of the API.",
+		},
 		CacheDir: codersdk.StringFlag{
 			Name:        "Cache Directory",
 			Flag:        "cache-dir",
@@ -133,6 +139,12 @@ func Flags() codersdk.DeploymentFlags {
 			EnvVar:      "CODER_INMEMORY",
 			Description: "Controls whether data will be stored in an in-memory database.",
 		},
+		InMemoryDatabasePath: codersdk.StringFlag{
+			Name:        "In-Memory Database Path",
+			Flag:        "in-memory-path",
+			EnvVar:      "CODER_INMEMORY_PATH",
+			Description: "Path to a file the in-memory database is persisted to, so data survives restarts. Only used with --in-memory. If empty, all data is lost when the server stops.",
+		},
 		ProvisionerDaemonCount: codersdk.IntFlag{
 			Name:        "Provisioner Daemons",
 			Flag:        "provisioner-daemons",
@@ -223,6 +235,24 @@ func Flags() codersdk.DeploymentFlags {
 			Description: "Scopes to grant when authenticating with OIDC.",
 			Default:     []string{oidc.ScopeOpenID, "profile", "email"},
 		},
+		OIDCGroupField: codersdk.StringFlag{
+			Name:        "OIDC Group Field",
+			Flag:        "oidc-group-field",
+			EnvVar:      "CODER_OIDC_GROUP_FIELD",
+			Description: "Claim that lists the groups a user belongs to. When set, group memberships are synced from the identity provider on login.",
+		},
+		OIDCGroupRegexFilter: codersdk.StringFlag{
+			Name:        "OIDC Group Regex Filter",
+			Flag:        "oidc-group-regex-filter",
+			EnvVar:      "CODER_OIDC_GROUP_REGEX_FILTER",
+			Description: "Only sync groups from the identity provider whose names match this regular expression.",
+		},
+		OIDCGroupMapping: codersdk.StringFlag{
+			Name:        "OIDC Group Mapping",
+			Flag:        "oidc-group-mapping",
+			EnvVar:      "CODER_OIDC_GROUP_MAPPING",
+			Description: "JSON object mapping group names from the identity provider to Coder group names, e.g. {\"idp-admins\": \"admins\"}.",
+		},
 		TelemetryEnable: codersdk.BoolFlag{
 			Name:        "Telemetry Enabled",
 			Flag:        "telemetry",
@@ -288,6 +318,22 @@ func Flags() codersdk.DeploymentFlags {
 			Description: `Minimum supported version of TLS. Accepted values are "tls10", "tls11", "tls12" or "tls13"`,
 			Default:     "tls12",
 		},
+		TLSClientCertAuthEmail: codersdk.BoolFlag{
+			Name:   "TLS Client Cert Auth Email",
+			Flag:   "tls-client-cert-auth-email",
+			EnvVar: "CODER_TLS_CLIENT_CERT_AUTH_EMAIL",
+			Description: "Authenticate API requests without a session token as the user with an email address " +
+				"in the subject alternative names of their verified client certificate.",
+		},
+		TLSClientCertIdentities: codersdk.StringArrayFlag{
+			Name:   "TLS Client Cert Identities",
+			Flag:   "tls-client-cert-identity",
+			EnvVar: "CODER_TLS_CLIENT_CERT_IDENTITIES",
+			Description: `Authenticate API requests without a session token as a user by a subject alternative name or ` +
+				`organizational unit of their verified client certificate. Each identity is in the format ` +
+				`"san:<value>=<username>" or "ou:<value>=<username>". Identities are checked before email addresses.`,
+			Default: []string{},
+		},
 		TraceEnable: codersdk.BoolFlag{
 			Name:        "Trace Enabled",
 			Flag:        "trace",
@@ -336,6 +382,33 @@ func Flags() codersdk.DeploymentFlags {
 			Shorthand:   "v",
 			Description: "Enables verbose logging.",
 		},
+		AuthzDenialLogPercent: codersdk.IntFlag{
+			Name:        "Authorization Denial Logging",
+			Flag:        "log-authz-denials",
+			EnvVar:      "CODER_LOG_AUTHZ_DENIALS",
+			Description: "Percentage (0-100) of denied authorization decisions to log with the roles and permissions that were evaluated. Useful to debug unexpected 403 and 404 responses. Disabled when 0.",
+			Default:     0,
+		},
+		APIKeyRateLimit: codersdk.IntFlag{
+			Name:        "API Key Rate Limit",
+			Flag:        "api-key-rate-limit",
+			EnvVar:      "CODER_API_KEY_RATE_LIMIT",
+			Description: "Requests per minute each API key can send. Up to a minute's worth of requests can be sent at once. Disabled when 0.",
+			Default:     0,
+		},
+		WorkspaceBuildRateLimit: codersdk.IntFlag{
+			Name:        "Workspace Build Rate Limit",
+			Flag:        "workspace-build-rate-limit",
+			EnvVar:      "CODER_WORKSPACE_BUILD_RATE_LIMIT",
+			Description: "Workspaces and workspace builds per minute each API key can create. Applies on top of the API key rate limit. Disabled when 0.",
+			Default:     0,
+		},
+		WorkspaceBuildRateLimitPerOrg: codersdk.BoolFlag{
+			Name:        "Workspace Build Rate Limit By Organization",
+			Flag:        "workspace-build-rate-limit-by-organization",
+			EnvVar:      "CODER_WORKSPACE_BUILD_RATE_LIMIT_BY_ORGANIZATION",
+			Description: "Applies the workspace build rate limit to each organization separately, so builds in one organization don't use up the limit of another.",
+		},
 		AuditLogging: codersdk.BoolFlag{
 			Name:        "Audit Logging",
 			Flag:        "audit-logging",
@@ -359,6 +432,13 @@ func Flags() codersdk.DeploymentFlags {
 			Secret:      true,
 			Enterprise:  true,
 		},
+		SCIMOrganization: codersdk.StringFlag{
+			Name:        "SCIM Organization",
+			Flag:        "scim-organization",
+			EnvVar:      "CODER_SCIM_ORGANIZATION",
+			Description: "The name or ID of the organization groups pushed over SCIM are created in. Defaults to the first organization.",
+			Enterprise:  true,
+		},
 		UserWorkspaceQuota: codersdk.IntFlag{
 			Name:        "User Workspace Quota",
 			Flag:        "user-workspace-quota",
@@ -367,6 +447,104 @@ func Flags() codersdk.DeploymentFlags {
 			Default:     0,
 			Enterprise:  true,
 		},
+		GroupWorkspaceQuota: codersdk.BoolFlag{
+			Name:        "Group Workspace Quota",
+			Flag:        "group-workspace-quota",
+			EnvVar:      "CODER_GROUP_WORKSPACE_QUOTA",
+			Description: "Limits the workspaces users can create by the quota allowances of their groups and the quota weights of templates.",
+			Enterprise:  true,
+		},
+		OrganizationWorkspaceQuota: codersdk.BoolFlag{
+			Name:        "Organization Workspace Quota",
+			Flag:        "organization-workspace-quota",
+			EnvVar:      "CODER_ORGANIZATION_WORKSPACE_QUOTA",
+			Description: "Limits the workspaces, running workspaces, and compute credits of each organization by the quota set for it.",
+			Enterprise:  true,
+		},
+		DeletedGroupRetention: codersdk.DurationFlag{
+			Name:        "Deleted Group Retention",
+			Flag:        "deleted-group-retention",
+			EnvVar:      "CODER_DELETED_GROUP_RETENTION",
+			Description: "How long deleted groups can be restored before they are removed permanently.",
+			Default:     30 * 24 * time.Hour,
+			Enterprise:  true,
+		},
+		OIDCGroupMetadataProvider: codersdk.StringFlag{
+			Name:        "OIDC Group Metadata Provider",
+			Flag:        "oidc-group-metadata-provider",
+			EnvVar:      "CODER_OIDC_GROUP_METADATA_PROVIDER",
+			Description: `Directory to copy the display name and picture of groups synced with OIDC from. Accepted values are "azure-ad" and "okta". Disabled when empty.`,
+			Enterprise:  true,
+		},
+		OIDCGroupMetadataURL: codersdk.StringFlag{
+			Name:        "OIDC Group Metadata URL",
+			Flag:        "oidc-group-metadata-url",
+			EnvVar:      "CODER_OIDC_GROUP_METADATA_URL",
+			Description: "Base URL of the group metadata directory API, e.g. https://example.okta.com. Defaults to https://graph.microsoft.com for Azure AD.",
+			Enterprise:  true,
+		},
+		OIDCGroupMetadataToken: codersdk.StringFlag{
+			Name:        "OIDC Group Metadata Token",
+			Flag:        "oidc-group-metadata-token",
+			EnvVar:      "CODER_OIDC_GROUP_METADATA_TOKEN",
+			Description: "API token used to read groups from Okta. Azure AD is read with the OIDC client credentials instead.",
+			Secret:      true,
+			Enterprise:  true,
+		},
+		OIDCGroupMetadataSyncInterval: codersdk.DurationFlag{
+			Name:        "OIDC Group Metadata Sync Interval",
+			Flag:        "oidc-group-metadata-sync-interval",
+			EnvVar:      "CODER_OIDC_GROUP_METADATA_SYNC_INTERVAL",
+			Description: "How often the display name and picture of groups synced with OIDC are copied from the directory.",
+			Default:     time.Hour,
+			Enterprise:  true,
+		},
+		AutostopMaxExtensionsPerDay: codersdk.IntFlag{
+			Name:        "Autostop Max Extensions Per Day",
+			Flag:        "autostop-max-extensions-per-day",
+			EnvVar:      "CODER_AUTOSTOP_MAX_EXTENSIONS_PER_DAY",
+			Description: "How many times users can extend the deadline of a running workspace in a day, unless its template sets a limit. Unlimited when 0.",
+			Default:     0,
+		},
+		AutostopMaxExtensionPerDay: codersdk.DurationFlag{
+			Name:        "Autostop Max Extension Per Day",
+			Flag:        "autostop-max-extension-per-day",
+			EnvVar:      "CODER_AUTOSTOP_MAX_EXTENSION_PER_DAY",
+			Description: "How far users can extend the deadline of a running workspace in total in a day, unless its template sets a limit. Unlimited when 0.",
+			Default:     0,
+		},
+		ArchiveS3Endpoint: codersdk.StringFlag{
+			Name:        "Archive S3 Endpoint",
+			Flag:        "archive-s3-endpoint",
+			EnvVar:      "CODER_ARCHIVE_S3_ENDPOINT",
+			Description: "URL of the S3-compatible API that workspace archives are stored in. Defaults to AWS S3 in the region.",
+		},
+		ArchiveS3Region: codersdk.StringFlag{
+			Name:        "Archive S3 Region",
+			Flag:        "archive-s3-region",
+			EnvVar:      "CODER_ARCHIVE_S3_REGION",
+			Description: "Region of the bucket that workspace archives are stored in.",
+			Default:     "us-east-1",
+		},
+		ArchiveS3Bucket: codersdk.StringFlag{
+			Name:        "Archive S3 Bucket",
+			Flag:        "archive-s3-bucket",
+			EnvVar:      "CODER_ARCHIVE_S3_BUCKET",
+			Description: "Bucket that workspace archives are stored in. Workspaces can't be archived when empty.",
+		},
+		ArchiveS3AccessKeyID: codersdk.StringFlag{
+			Name:        "Archive S3 Access Key ID",
+			Flag:        "archive-s3-access-key-id",
+			EnvVar:      "CODER_ARCHIVE_S3_ACCESS_KEY_ID",
+			Description: "Access key ID used to store workspace archives.",
+		},
+		ArchiveS3SecretAccessKey: codersdk.StringFlag{
+			Name:        "Archive S3 Secret Access Key",
+			Flag:        "archive-s3-secret-access-key",
+			EnvVar:      "CODER_ARCHIVE_S3_SECRET_ACCESS_KEY",
+			Description: "Secret access key used to store workspace archives.",
+			Secret:      true,
+		},
 	}
 }
 
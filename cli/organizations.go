@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func organizations() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "organizations",
+		Short:   "Create, rename, and delete organizations",
+		Aliases: []string{"organization", "orgs"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		organizationCreate(),
+		organizationRename(),
+		organizationDelete(),
+	)
+	return cmd
+}
+
+func organizationCreate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an organization, with you as its admin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			organization, err := client.CreateOrganization(cmd.Context(), codersdk.CreateOrganizationRequest{
+				Name: args[0],
+			})
+			if err != nil {
+				return xerrors.Errorf("create organization: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Organization %s created\n", cliui.Styles.Keyword.Render(organization.Name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func organizationRename() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <name> <new-name>",
+		Short: "Rename an organization",
+		Long:  "Rename an organization. The old name continues to resolve to the organization until another organization takes it.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := client.OrganizationByName(cmd.Context(), codersdk.Me, args[0])
+			if err != nil {
+				return xerrors.Errorf("get organization %q: %w", args[0], err)
+			}
+
+			organization, err = client.UpdateOrganization(cmd.Context(), organization.ID, codersdk.UpdateOrganizationRequest{
+				Name: &args[1],
+			})
+			if err != nil {
+				return xerrors.Errorf("rename organization: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Organization %s renamed to %s\n", cliui.Styles.Keyword.Render(args[0]), cliui.Styles.Keyword.Render(organization.Name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+func organizationDelete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "Delete an organization along with its workspaces, templates, and members",
+		Long:    "Delete an organization along with its workspaces, templates, and members. The deletion runs on the server and continues if the command is interrupted. The default organization can't be deleted.",
+		Aliases: []string{"rm"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := client.OrganizationByName(cmd.Context(), codersdk.Me, args[0])
+			if err != nil {
+				return xerrors.Errorf("get organization %q: %w", args[0], err)
+			}
+
+			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Delete organization %s?", cliui.Styles.Code.Render(organization.Name)),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			deletion, err := client.DeleteOrganization(cmd.Context(), organization.ID)
+			if err != nil {
+				return xerrors.Errorf("delete organization: %w", err)
+			}
+
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			var progress string
+			for deletion.Status == codersdk.OrganizationDeletionStatusRunning {
+				next := fmt.Sprintf("Deleted %d/%d workspaces, %d/%d templates, and %d/%d members",
+					deletion.WorkspacesDeleted, deletion.WorkspacesTotal,
+					deletion.TemplatesDeleted, deletion.TemplatesTotal,
+					deletion.MembersDeleted, deletion.MembersTotal)
+				if next != progress {
+					progress = next
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), progress)
+				}
+				select {
+				case <-cmd.Context().Done():
+					return cmd.Context().Err()
+				case <-ticker.C:
+				}
+				deletion, err = client.OrganizationDeletionStatus(cmd.Context(), organization.ID)
+				if err != nil {
+					return xerrors.Errorf("get organization deletion status: %w", err)
+				}
+			}
+			if deletion.Status == codersdk.OrganizationDeletionStatusFailed {
+				return xerrors.Errorf("delete organization: %s", deletion.Error)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Organization %s deleted\n", cliui.Styles.Keyword.Render(organization.Name))
+			return nil
+		},
+	}
+	cliui.AllowSkipPrompt(cmd)
+	return cmd
+}
@@ -0,0 +1,90 @@
+package cli_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CreateDelete", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "organizations", "create", "tenant")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		pty.ExpectMatch("created")
+
+		org, err := client.OrganizationByName(ctx, codersdk.Me, "tenant")
+		require.NoError(t, err)
+
+		cmd, root = clitest.New(t, "organizations", "delete", "tenant", "--yes")
+		clitest.SetupConfig(t, client, root)
+		pty = ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		pty.ExpectMatch("deleted")
+
+		_, err = client.Organization(ctx, org.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+
+		cmd, root := clitest.New(t, "organizations", "rename", org.Name, "renamed")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		pty.ExpectMatch("renamed")
+
+		org, err = client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, "renamed", org.Name)
+	})
+
+	t.Run("DeleteDefault", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+
+		cmd, root := clitest.New(t, "organizations", "delete", org.Name, "--yes")
+		clitest.SetupConfig(t, client, root)
+		err = cmd.ExecuteContext(ctx)
+		require.ErrorContains(t, err, "default organization")
+	})
+}
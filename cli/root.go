@@ -44,6 +44,7 @@ const (
 	varAgentURL         = "agent-url"
 	varGlobalConfig     = "global-config"
 	varHeader           = "header"
+	varMaxRetries       = "max-retries"
 	varNoOpen           = "no-open"
 	varNoVersionCheck   = "no-version-warning"
 	varNoFeatureWarning = "no-feature-warning"
@@ -77,6 +78,7 @@ func Core() []*cobra.Command {
 		list(),
 		login(),
 		logout(),
+		organizations(),
 		parameters(),
 		portForward(),
 		publickey(),
@@ -185,6 +187,7 @@ func Root(subcommands []*cobra.Command) *cobra.Command {
 	_ = cmd.PersistentFlags().MarkHidden(varAgentURL)
 	cliflag.String(cmd.PersistentFlags(), varGlobalConfig, "", "CODER_CONFIG_DIR", configdir.LocalConfig("coderv2"), "Path to the global `coder` config directory.")
 	cliflag.StringArray(cmd.PersistentFlags(), varHeader, "", "CODER_HEADER", []string{}, "HTTP headers added to all requests. Provide as \"Key=Value\"")
+	cliflag.IntVarP(cmd.PersistentFlags(), new(int), varMaxRetries, "", "CODER_MAX_RETRIES", codersdk.DefaultRetryPolicy.MaxRetries, "Retry requests that fail because the deployment is overloaded or unavailable up to this many times. Set to 0 to disable retries.")
 	cmd.PersistentFlags().Bool(varForceTty, false, "Force the `coder` command to run as if connected to a TTY.")
 	_ = cmd.PersistentFlags().MarkHidden(varForceTty)
 	cmd.PersistentFlags().Bool(varNoOpen, false, "Block automatically opening URLs in the browser.")
@@ -304,6 +307,14 @@ func createUnauthenticatedClient(cmd *cobra.Command, serverURL *url.URL) (*coder
 		transport.headers[parts[0]] = parts[1]
 	}
 	client.HTTPClient.Transport = transport
+	maxRetries, err := cmd.Flags().GetInt(varMaxRetries)
+	if err != nil {
+		return nil, err
+	}
+	if maxRetries > 0 {
+		client.Retry = codersdk.DefaultRetryPolicy
+		client.Retry.MaxRetries = maxRetries
+	}
 	return client, nil
 }
 
@@ -327,14 +338,19 @@ func createAgentClient(cmd *cobra.Command) (*codersdk.Client, error) {
 	return client, nil
 }
 
-// currentOrganization returns the currently active organization for the authenticated user.
-func currentOrganization(cmd *cobra.Command, client *codersdk.Client) (codersdk.Organization, error) {
+// CurrentOrganization returns the currently active organization for the authenticated user.
+func CurrentOrganization(cmd *cobra.Command, client *codersdk.Client) (codersdk.Organization, error) {
 	orgs, err := client.OrganizationsByUser(cmd.Context(), codersdk.Me)
 	if err != nil {
 		return codersdk.Organization{}, nil
 	}
 	// For now, we won't use the config to set this.
 	// Eventually, we will support changing using "coder switch <org>"
+	for _, org := range orgs {
+		if org.IsDefault {
+			return org, nil
+		}
+	}
 	return orgs[0], nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -17,6 +18,7 @@ import (
 	"os/signal"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,6 +40,8 @@ import (
 	"golang.org/x/xerrors"
 	"google.golang.org/api/idtoken"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
@@ -53,7 +57,10 @@ import (
 	"github.com/coder/coder/coderd/database/migrations"
 	"github.com/coder/coder/coderd/devtunnel"
 	"github.com/coder/coder/coderd/gitsshkey"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/objectstore"
 	"github.com/coder/coder/coderd/prometheusmetrics"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/codersdk"
@@ -319,6 +326,38 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 				AgentStatsRefreshInterval:   dflags.AgentStatRefreshInterval.Value,
 				Experimental:                ExperimentalEnabled(cmd),
 				DeploymentFlags:             &dflags,
+				APIKeyRateLimit: httpmw.RateLimitConfig{
+					PerMinute: dflags.APIKeyRateLimit.Value,
+				},
+				WorkspaceBuildRateLimit: httpmw.RateLimitConfig{
+					PerMinute:      dflags.WorkspaceBuildRateLimit.Value,
+					ByOrganization: dflags.WorkspaceBuildRateLimitPerOrg.Value,
+				},
+				AutostopExtensionPolicy: codersdk.AutostopExtensionPolicy{
+					MaxExtensionsPerDay:      int32(dflags.AutostopMaxExtensionsPerDay.Value),
+					MaxExtensionPerDayMillis: dflags.AutostopMaxExtensionPerDay.Value.Milliseconds(),
+				},
+			}
+
+			options.ClientCertificates, err = clientCertificateConfig(dflags)
+			if err != nil {
+				return xerrors.Errorf("configure client certificate authentication: %w", err)
+			}
+
+			if dflags.AuthzDenialLogPercent.Value < 0 || dflags.AuthzDenialLogPercent.Value > 100 {
+				return xerrors.Errorf("--%s must be between 0 and 100", dflags.AuthzDenialLogPercent.Flag)
+			}
+			if dflags.AuthzDenialLogPercent.Value > 0 {
+				options.Authorizer = rbac.NewDecisionLogger(rbac.NewAuthorizer(), logger.Named("authz"), dflags.AuthzDenialLogPercent.Value)
+			}
+			if dflags.AutostopMaxExtensionsPerDay.Value < 0 || dflags.AutostopMaxExtensionPerDay.Value < 0 {
+				return xerrors.Errorf("--%s and --%s can't be negative", dflags.AutostopMaxExtensionsPerDay.Flag, dflags.AutostopMaxExtensionPerDay.Flag)
+			}
+			if dflags.ArchiveS3Bucket.Value != "" {
+				options.WorkspaceArchiveStore, err = workspaceArchiveStore(dflags)
+				if err != nil {
+					return xerrors.Errorf("configure workspace archive store: %w", err)
+				}
 			}
 
 			if dflags.OAuth2GithubClientSecret.Value != "" {
@@ -351,6 +390,20 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 				if err != nil {
 					return xerrors.Errorf("parse oidc oauth callback url: %w", err)
 				}
+				var groupFilter *regexp.Regexp
+				if dflags.OIDCGroupRegexFilter.Value != "" {
+					groupFilter, err = regexp.Compile(dflags.OIDCGroupRegexFilter.Value)
+					if err != nil {
+						return xerrors.Errorf("parse oidc group regex filter: %w", err)
+					}
+				}
+				var groupMapping map[string]string
+				if dflags.OIDCGroupMapping.Value != "" {
+					err = json.Unmarshal([]byte(dflags.OIDCGroupMapping.Value), &groupMapping)
+					if err != nil {
+						return xerrors.Errorf("parse oidc group mapping: %w", err)
+					}
+				}
 				options.OIDCConfig = &coderd.OIDCConfig{
 					OAuth2Config: &oauth2.Config{
 						ClientID:     dflags.OIDCClientID.Value,
@@ -364,11 +417,27 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 					}),
 					EmailDomain:  dflags.OIDCEmailDomain.Value,
 					AllowSignups: dflags.OIDCAllowSignups.Value,
+					GroupField:   dflags.OIDCGroupField.Value,
+					GroupFilter:  groupFilter,
+					GroupMapping: groupMapping,
 				}
 			}
 
 			if dflags.InMemoryDatabase.Value {
 				options.Database = databasefake.New()
+				if dflags.InMemoryDatabasePath.Value != "" {
+					store, err := databasefake.NewPersistent(dflags.InMemoryDatabasePath.Value, time.Second)
+					if err != nil {
+						return xerrors.Errorf("open persistent in-memory database: %w", err)
+					}
+					defer func() {
+						err := store.Close()
+						if err != nil {
+							cmd.PrintErrf("Failed to persist in-memory database: %s\n", err)
+						}
+					}()
+					options.Database = store
+				}
 				options.Pubsub = database.NewPubsubInMemory()
 			} else {
 				sqlDB, err := sql.Open(sqlDriver, dflags.PostgresURL.Value)
@@ -469,6 +538,14 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 			}
 			defer coderAPI.Close()
 
+			if dflags.GRPCAddress.Value != "" {
+				closeGRPC, err := serveGRPC(ctx, logger, coderAPI, dflags)
+				if err != nil {
+					return xerrors.Errorf("serve grpc: %w", err)
+				}
+				defer closeGRPC()
+			}
+
 			client := codersdk.New(localURL)
 			if dflags.TLSEnable.Value {
 				// Secure transport isn't needed for locally communicating!
@@ -727,9 +804,12 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 	deployment.BoolFlag(root.Flags(), &dflags.PromEnabled)
 	deployment.StringFlag(root.Flags(), &dflags.PromAddress)
 	deployment.BoolFlag(root.Flags(), &dflags.PprofEnabled)
+	deployment.StringFlag(root.Flags(), &dflags.GRPCAddress)
 	deployment.StringFlag(root.Flags(), &dflags.CacheDir)
 	deployment.BoolFlag(root.Flags(), &dflags.InMemoryDatabase)
 	_ = root.Flags().MarkHidden(dflags.InMemoryDatabase.Flag)
+	deployment.StringFlag(root.Flags(), &dflags.InMemoryDatabasePath)
+	_ = root.Flags().MarkHidden(dflags.InMemoryDatabasePath.Flag)
 	deployment.IntFlag(root.Flags(), &dflags.ProvisionerDaemonCount)
 	deployment.StringFlag(root.Flags(), &dflags.PostgresURL)
 	deployment.StringFlag(root.Flags(), &dflags.OAuth2GithubClientID)
@@ -744,6 +824,9 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 	deployment.StringFlag(root.Flags(), &dflags.OIDCEmailDomain)
 	deployment.StringFlag(root.Flags(), &dflags.OIDCIssuerURL)
 	deployment.StringArrayFlag(root.Flags(), &dflags.OIDCScopes)
+	deployment.StringFlag(root.Flags(), &dflags.OIDCGroupField)
+	deployment.StringFlag(root.Flags(), &dflags.OIDCGroupRegexFilter)
+	deployment.StringFlag(root.Flags(), &dflags.OIDCGroupMapping)
 	deployment.BoolFlag(root.Flags(), &dflags.TelemetryEnable)
 	deployment.BoolFlag(root.Flags(), &dflags.TelemetryTraceEnable)
 	deployment.StringFlag(root.Flags(), &dflags.TelemetryURL)
@@ -754,6 +837,8 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 	deployment.StringFlag(root.Flags(), &dflags.TLSClientAuth)
 	deployment.StringArrayFlag(root.Flags(), &dflags.TLSKeyFiles)
 	deployment.StringFlag(root.Flags(), &dflags.TLSMinVersion)
+	deployment.BoolFlag(root.Flags(), &dflags.TLSClientCertAuthEmail)
+	deployment.StringArrayFlag(root.Flags(), &dflags.TLSClientCertIdentities)
 	deployment.BoolFlag(root.Flags(), &dflags.TraceEnable)
 	deployment.BoolFlag(root.Flags(), &dflags.SecureAuthCookie)
 	deployment.StringFlag(root.Flags(), &dflags.SSHKeygenAlgorithm)
@@ -764,6 +849,17 @@ func Server(dflags codersdk.DeploymentFlags, newAPI func(context.Context, *coder
 	deployment.DurationFlag(root.Flags(), &dflags.AgentStatRefreshInterval)
 	_ = root.Flags().MarkHidden(dflags.AgentStatRefreshInterval.Flag)
 	deployment.BoolFlag(root.Flags(), &dflags.Verbose)
+	deployment.IntFlag(root.Flags(), &dflags.AuthzDenialLogPercent)
+	deployment.IntFlag(root.Flags(), &dflags.APIKeyRateLimit)
+	deployment.IntFlag(root.Flags(), &dflags.WorkspaceBuildRateLimit)
+	deployment.BoolFlag(root.Flags(), &dflags.WorkspaceBuildRateLimitPerOrg)
+	deployment.IntFlag(root.Flags(), &dflags.AutostopMaxExtensionsPerDay)
+	deployment.DurationFlag(root.Flags(), &dflags.AutostopMaxExtensionPerDay)
+	deployment.StringFlag(root.Flags(), &dflags.ArchiveS3Endpoint)
+	deployment.StringFlag(root.Flags(), &dflags.ArchiveS3Region)
+	deployment.StringFlag(root.Flags(), &dflags.ArchiveS3Bucket)
+	deployment.StringFlag(root.Flags(), &dflags.ArchiveS3AccessKeyID)
+	deployment.StringFlag(root.Flags(), &dflags.ArchiveS3SecretAccessKey)
 
 	return root
 }
@@ -936,7 +1032,64 @@ func loadCertificates(tlsCertFiles, tlsKeyFiles []string) ([]tls.Certificate, er
 	return certs, nil
 }
 
+// clientCertificateConfig returns how verified client certificates map to
+// users, or nil if certificates don't authenticate requests.
+func clientCertificateConfig(dflags codersdk.DeploymentFlags) (*httpmw.ClientCertificateConfig, error) {
+	if !dflags.TLSClientCertAuthEmail.Value && len(dflags.TLSClientCertIdentities.Value) == 0 {
+		return nil, nil
+	}
+	// Certificates are only trusted if they're verified against the client CA.
+	if !dflags.TLSEnable.Value || dflags.TLSClientCAFile.Value == "" {
+		return nil, xerrors.Errorf("--%s and --%s are required", dflags.TLSEnable.Flag, dflags.TLSClientCAFile.Flag)
+	}
+	if dflags.TLSClientAuth.Value != "verify-if-given" && dflags.TLSClientAuth.Value != "require-and-verify" {
+		return nil, xerrors.Errorf("--%s must be \"verify-if-given\" or \"require-and-verify\"", dflags.TLSClientAuth.Flag)
+	}
+	config := &httpmw.ClientCertificateConfig{
+		EmailSAN: dflags.TLSClientCertAuthEmail.Value,
+	}
+	for _, value := range dflags.TLSClientCertIdentities.Value {
+		identity, err := httpmw.ParseClientCertificateIdentity(value)
+		if err != nil {
+			return nil, xerrors.Errorf("--%s: %w", dflags.TLSClientCertIdentities.Flag, err)
+		}
+		config.Identities = append(config.Identities, identity)
+	}
+	return config, nil
+}
+
+// workspaceArchiveStore returns the S3-compatible bucket that workspace
+// archives are stored in.
+func workspaceArchiveStore(dflags codersdk.DeploymentFlags) (objectstore.Store, error) {
+	rawEndpoint := dflags.ArchiveS3Endpoint.Value
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", dflags.ArchiveS3Region.Value)
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, xerrors.Errorf("--%s must be a URL", dflags.ArchiveS3Endpoint.Flag)
+	}
+	if dflags.ArchiveS3AccessKeyID.Value == "" || dflags.ArchiveS3SecretAccessKey.Value == "" {
+		return nil, xerrors.Errorf("--%s and --%s are required", dflags.ArchiveS3AccessKeyID.Flag, dflags.ArchiveS3SecretAccessKey.Flag)
+	}
+	return objectstore.NewS3(objectstore.S3Options{
+		Endpoint:        endpoint,
+		Region:          dflags.ArchiveS3Region.Value,
+		Bucket:          dflags.ArchiveS3Bucket.Value,
+		AccessKeyID:     dflags.ArchiveS3AccessKeyID.Value,
+		SecretAccessKey: dflags.ArchiveS3SecretAccessKey.Value,
+	}), nil
+}
+
 func configureServerTLS(listener net.Listener, tlsMinVersion, tlsClientAuth string, tlsCertFiles, tlsKeyFiles []string, tlsClientCAFile string) (net.Listener, error) {
+	tlsConfig, err := configureTLS(tlsMinVersion, tlsClientAuth, tlsCertFiles, tlsKeyFiles, tlsClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(listener, tlsConfig), nil
+}
+
+func configureTLS(tlsMinVersion, tlsClientAuth string, tlsCertFiles, tlsKeyFiles []string, tlsClientCAFile string) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 	}
@@ -1006,7 +1159,7 @@ func configureServerTLS(listener net.Listener, tlsMinVersion, tlsClientAuth stri
 		tlsConfig.ClientCAs = caPool
 	}
 
-	return tls.NewListener(listener, tlsConfig), nil
+	return tlsConfig, nil
 }
 
 func configureGithubOAuth2(accessURL *url.URL, clientID, clientSecret string, allowSignups bool, allowOrgs []string, rawTeams []string, enterpriseBaseURL string) (*coderd.GithubOAuth2Config, error) {
@@ -1128,6 +1281,39 @@ func serveHandler(ctx context.Context, logger slog.Logger, handler http.Handler,
 	return func() { _ = srv.Close() }
 }
 
+// serveGRPC serves the gRPC API on its own address, since gRPC requires
+// HTTP/2. It's secured with the TLS configuration of the API.
+func serveGRPC(ctx context.Context, logger slog.Logger, coderAPI *coderd.API, dflags codersdk.DeploymentFlags) (closeFunc func(), err error) {
+	var opts []grpc.ServerOption
+	if dflags.TLSEnable.Value {
+		tlsConfig, err := configureTLS(
+			dflags.TLSMinVersion.Value,
+			dflags.TLSClientAuth.Value,
+			dflags.TLSCertFiles.Value,
+			dflags.TLSKeyFiles.Value,
+			dflags.TLSClientCAFile.Value,
+		)
+		if err != nil {
+			return nil, xerrors.Errorf("configure tls: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	listener, err := net.Listen("tcp", dflags.GRPCAddress.Value)
+	if err != nil {
+		return nil, xerrors.Errorf("listen %q: %w", dflags.GRPCAddress.Value, err)
+	}
+	logger.Debug(ctx, "grpc server listening", slog.F("addr", listener.Addr()))
+
+	server := coderAPI.GRPCServer(opts...)
+	go func() {
+		err := server.Serve(listener)
+		if err != nil {
+			logger.Error(ctx, "grpc server serve", slog.Error(err))
+		}
+	}()
+	return server.Stop, nil
+}
+
 // embeddedPostgresURL returns the URL for the embedded PostgreSQL deployment.
 func embeddedPostgresURL(cfg config.Root) (string, error) {
 	pgPassword, err := cfg.PostgresPassword().Read()
@@ -247,6 +247,28 @@ func TestServer(t *testing.T) {
 		err := root.ExecuteContext(ctx)
 		require.Error(t, err)
 	})
+	t.Run("TLSClientCertAuthWithoutVerification", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+
+		certPath, keyPath := generateTLSCertificate(t)
+		root, _ := clitest.New(t,
+			"server",
+			"--in-memory",
+			"--address", ":0",
+			"--access-url", "example.com",
+			"--tls-enable",
+			"--tls-cert-file", certPath,
+			"--tls-key-file", keyPath,
+			"--tls-client-ca-file", certPath,
+			"--tls-client-auth", "request",
+			"--tls-client-cert-auth-email",
+			"--cache-dir", t.TempDir(),
+		)
+		err := root.ExecuteContext(ctx)
+		require.ErrorContains(t, err, "--tls-client-auth must be")
+	})
 	t.Run("TLSInvalid", func(t *testing.T) {
 		t.Parallel()
 
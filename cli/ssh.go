@@ -309,7 +309,8 @@ func tryPollWorkspaceAutostop(ctx context.Context, client *codersdk.Client, work
 	return notify.Notify(condition, workspacePollInterval, autostopNotifyCountdown...)
 }
 
-// Notify the user if the workspace is due to shutdown.
+// Notify the user if the workspace is due to shutdown, or to restart during
+// the maintenance window of its template.
 func notifyCondition(ctx context.Context, client *codersdk.Client, workspaceID uuid.UUID, lock *flock.Flock) notify.Condition {
 	return func(now time.Time) (deadline time.Time, callback func()) {
 		// Keep trying to regain the lock.
@@ -323,21 +324,27 @@ func notifyCondition(ctx context.Context, client *codersdk.Client, workspaceID u
 			return time.Time{}, nil
 		}
 
-		if ptr.NilOrZero(ws.TTLMillis) {
+		action, actioning := "stop", "stopping"
+		switch {
+		case ws.MaintenanceAt != nil && (ptr.NilOrZero(ws.TTLMillis) || ws.MaintenanceAt.Before(ws.LatestBuild.Deadline.Time)):
+			action, actioning = "restart for maintenance", "restarting"
+			deadline = *ws.MaintenanceAt
+		case ptr.NilOrZero(ws.TTLMillis):
 			return time.Time{}, nil
+		default:
+			deadline = ws.LatestBuild.Deadline.Time
 		}
 
-		deadline = ws.LatestBuild.Deadline.Time
 		callback = func() {
 			ttl := deadline.Sub(now)
 			var title, body string
 			if ttl > time.Minute {
-				title = fmt.Sprintf(`Workspace %s stopping soon`, ws.Name)
+				title = fmt.Sprintf(`Workspace %s %s soon`, ws.Name, actioning)
 				body = fmt.Sprintf(
-					`Your Coder workspace %s is scheduled to stop in %.0f mins`, ws.Name, ttl.Minutes())
+					`Your Coder workspace %s is scheduled to %s in %.0f mins`, ws.Name, action, ttl.Minutes())
 			} else {
-				title = fmt.Sprintf("Workspace %s stopping!", ws.Name)
-				body = fmt.Sprintf("Your Coder workspace %s is stopping any time now!", ws.Name)
+				title = fmt.Sprintf("Workspace %s %s!", ws.Name, actioning)
+				body = fmt.Sprintf("Your Coder workspace %s is %s any time now!", ws.Name, actioning)
 			}
 			// notify user with a native system notification (best effort)
 			_ = beeep.Notify(title, body, "")
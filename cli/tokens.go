@@ -23,6 +23,10 @@ func tokens() *cobra.Command {
 				Description: "Create a token for automation",
 				Command:     "coder tokens create",
 			},
+			example{
+				Description: "Create a token that can only read workspaces in an organization",
+				Command:     "coder tokens create --permission workspace:read --org my-org",
+			},
 			example{
 				Description: "List your tokens",
 				Command:     "coder tokens ls",
@@ -46,6 +50,11 @@ func tokens() *cobra.Command {
 }
 
 func createToken() *cobra.Command {
+	var (
+		scope        string
+		permissions  []string
+		organization string
+	)
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a tokens",
@@ -55,7 +64,19 @@ func createToken() *cobra.Command {
 				return xerrors.Errorf("create codersdk client: %w", err)
 			}
 
-			res, err := client.CreateToken(cmd.Context(), codersdk.Me)
+			req := codersdk.CreateTokenRequest{
+				Scope:       codersdk.APIKeyScope(scope),
+				Permissions: permissions,
+			}
+			if organization != "" {
+				org, err := client.OrganizationByName(cmd.Context(), codersdk.Me, organization)
+				if err != nil {
+					return xerrors.Errorf("get organization %q: %w", organization, err)
+				}
+				req.OrganizationID = &org.ID
+			}
+
+			res, err := client.CreateToken(cmd.Context(), codersdk.Me, req)
 			if err != nil {
 				return xerrors.Errorf("create tokens: %w", err)
 			}
@@ -73,12 +94,16 @@ func createToken() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().StringVar(&scope, "scope", "", `Scope of the token: "all", "application_connect" or "restricted". Defaults to "restricted" if permissions are set, otherwise "all".`)
+	cmd.Flags().StringArrayVarP(&permissions, "permission", "p", nil, `Restrict the token to a "<resource>:<action>" permission, e.g. "workspace:read". Can be repeated.`)
+	cmd.Flags().StringVar(&organization, "org", "", "Only allow the permissions of a restricted token in this organization.")
 
 	return cmd
 }
 
 type tokenRow struct {
 	ID        string    `table:"ID"`
+	Scope     string    `table:"Scope"`
 	LastUsed  time.Time `table:"Last Used"`
 	ExpiresAt time.Time `table:"Expires At"`
 	CreatedAt time.Time `table:"Created At"`
@@ -110,6 +135,7 @@ func listTokens() *cobra.Command {
 			for _, key := range keys {
 				rows = append(rows, tokenRow{
 					ID:        key.ID,
+					Scope:     tokenScope(key),
 					LastUsed:  key.LastUsed,
 					ExpiresAt: key.ExpiresAt,
 					CreatedAt: key.CreatedAt,
@@ -156,3 +182,16 @@ func removeToken() *cobra.Command {
 
 	return cmd
 }
+
+// tokenScope describes the scope of a token, including the permissions of
+// restricted tokens.
+func tokenScope(key codersdk.APIKey) string {
+	if key.Scope != codersdk.APIKeyScopeRestricted {
+		return string(key.Scope)
+	}
+	scope := fmt.Sprintf("%s (%s)", key.Scope, strings.Join(key.Permissions, ", "))
+	if key.OrganizationID != nil {
+		scope += " in " + key.OrganizationID.String()
+	}
+	return scope
+}
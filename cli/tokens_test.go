@@ -63,4 +63,20 @@ func TestTokens(t *testing.T) {
 	res = buf.String()
 	require.NotEmpty(t, res)
 	require.Contains(t, res, "deleted")
+
+	cmd, root = clitest.New(t, "tokens", "create", "--permission", "workspace:read", "-p", "template:read")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.Execute()
+	require.NoError(t, err)
+	require.Regexp(t, r, buf.String())
+
+	cmd, root = clitest.New(t, "tokens", "ls")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.Execute()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "restricted (workspace:read, template:read)")
 }
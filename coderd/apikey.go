@@ -34,14 +34,84 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req codersdk.CreateTokenRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	scope := database.APIKeyScope(req.Scope)
+	if scope == "" {
+		scope = database.APIKeyScopeAll
+		if len(req.Permissions) > 0 {
+			scope = database.APIKeyScopeRestricted
+		}
+	}
+	params := createAPIKeyParams{
+		UserID:    user.ID,
+		LoginType: database.LoginTypeToken,
+		Scope:     scope,
+	}
+	switch scope {
+	case database.APIKeyScopeAll, database.APIKeyScopeApplicationConnect:
+		if len(req.Permissions) > 0 || req.OrganizationID != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Permissions and organization can only be set for %q tokens.", codersdk.APIKeyScopeRestricted),
+			})
+			return
+		}
+	case database.APIKeyScopeRestricted:
+		if req.OrganizationID != nil {
+			_, err := api.Database.GetOrganizationByID(ctx, *req.OrganizationID)
+			if errors.Is(err, sql.ErrNoRows) {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+					Message: fmt.Sprintf("Organization %q does not exist.", req.OrganizationID.String()),
+					Validations: []codersdk.ValidationError{
+						{Field: "organization_id", Detail: "organization does not exist"},
+					},
+				})
+				return
+			}
+			if err != nil {
+				httpapi.InternalServerError(rw, err)
+				return
+			}
+			params.ScopeOrganizationID = uuid.NullUUID{UUID: *req.OrganizationID, Valid: true}
+		}
+		_, err := rbac.RestrictedScope(params.ScopeOrganizationID, req.Permissions)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid token permissions.",
+				Validations: []codersdk.ValidationError{
+					{Field: "permissions", Detail: err.Error()},
+				},
+			})
+			return
+		}
+		params.ScopePermissions = req.Permissions
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid scope %q.", req.Scope),
+			Validations: []codersdk.ValidationError{
+				{Field: "scope", Detail: fmt.Sprintf("must be one of %q, %q or %q", codersdk.APIKeyScopeAll, codersdk.APIKeyScopeApplicationConnect, codersdk.APIKeyScopeRestricted)},
+			},
+		})
+		return
+	}
+
+	// A token can't be used to create a token that is allowed to do more
+	// than itself.
+	if key := httpmw.APIKey(r); !apiKeyScopeCovers(key, params) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "The scope of the new token must not exceed the scope of the API key used to create it.",
+		})
+		return
+	}
+
 	// tokens last 100 years
 	lifeTime := time.Hour * 876000
-	cookie, err := api.createAPIKey(ctx, createAPIKeyParams{
-		UserID:          user.ID,
-		LoginType:       database.LoginTypeToken,
-		ExpiresAt:       database.Now().Add(lifeTime),
-		LifetimeSeconds: int64(lifeTime.Seconds()),
-	})
+	params.ExpiresAt = database.Now().Add(lifeTime)
+	params.LifetimeSeconds = int64(lifeTime.Seconds())
+	cookie, err := api.createAPIKey(ctx, params)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Failed to create API key.",
@@ -200,6 +270,50 @@ type createAPIKeyParams struct {
 	ExpiresAt       time.Time
 	LifetimeSeconds int64
 	Scope           database.APIKeyScope
+	// ScopePermissions and ScopeOrganizationID are only used by the
+	// restricted scope.
+	ScopePermissions    []string
+	ScopeOrganizationID uuid.NullUUID
+}
+
+// apiKeyScopeCovers returns whether the key allows everything a key created
+// with params would.
+func apiKeyScopeCovers(key database.APIKey, params createAPIKeyParams) bool {
+	switch key.Scope {
+	case database.APIKeyScopeAll:
+		return true
+	case database.APIKeyScopeRestricted:
+		if params.Scope != database.APIKeyScopeRestricted {
+			return false
+		}
+		if key.ScopeOrganizationID.Valid && key.ScopeOrganizationID != params.ScopeOrganizationID {
+			return false
+		}
+		for _, permission := range params.ScopePermissions {
+			resourceType, action, err := rbac.ParseScopePermission(permission)
+			if err != nil {
+				return false
+			}
+			covered := false
+			for _, keyPermission := range key.ScopePermissions {
+				keyResourceType, keyAction, err := rbac.ParseScopePermission(keyPermission)
+				if err != nil {
+					continue
+				}
+				if (keyResourceType == rbac.WildcardSymbol || keyResourceType == resourceType) &&
+					(keyAction == rbac.WildcardSymbol || keyAction == action) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+		return true
+	default:
+		return key.Scope == params.Scope
+	}
 }
 
 func (api *API) createAPIKey(ctx context.Context, params createAPIKeyParams) (*http.Cookie, error) {
@@ -229,6 +343,10 @@ func (api *API) createAPIKey(ctx context.Context, params createAPIKeyParams) (*h
 	if params.Scope != "" {
 		scope = params.Scope
 	}
+	scopePermissions := params.ScopePermissions
+	if scopePermissions == nil {
+		scopePermissions = []string{}
+	}
 
 	key, err := api.Database.InsertAPIKey(ctx, database.InsertAPIKeyParams{
 		ID:              keyID,
@@ -242,12 +360,14 @@ func (api *API) createAPIKey(ctx context.Context, params createAPIKeyParams) (*h
 			Valid: true,
 		},
 		// Make sure in UTC time for common time zone
-		ExpiresAt:    params.ExpiresAt.UTC(),
-		CreatedAt:    database.Now(),
-		UpdatedAt:    database.Now(),
-		HashedSecret: hashed[:],
-		LoginType:    params.LoginType,
-		Scope:        scope,
+		ExpiresAt:           params.ExpiresAt.UTC(),
+		CreatedAt:           database.Now(),
+		UpdatedAt:           database.Now(),
+		HashedSecret:        hashed[:],
+		LoginType:           params.LoginType,
+		Scope:               scope,
+		ScopePermissions:    scopePermissions,
+		ScopeOrganizationID: params.ScopeOrganizationID,
 	})
 	if err != nil {
 		return nil, xerrors.Errorf("insert API key: %w", err)
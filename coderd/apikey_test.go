@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -22,7 +23,7 @@ func TestTokens(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, keys)
 
-	res, err := client.CreateToken(ctx, codersdk.Me)
+	res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
 	require.NoError(t, err)
 	require.Greater(t, len(res.Key), 2)
 
@@ -40,6 +41,118 @@ func TestTokens(t *testing.T) {
 	require.Empty(t, keys)
 }
 
+func TestTokensRestricted(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+	restrictedClient := func(ctx context.Context, t *testing.T, req codersdk.CreateTokenRequest) *codersdk.Client {
+		t.Helper()
+		res, err := client.CreateToken(ctx, codersdk.Me, req)
+		require.NoError(t, err)
+		restricted := codersdk.New(client.URL)
+		restricted.SessionToken = res.Key
+		return restricted
+	}
+
+	t.Run("ReadWorkspaces", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		restricted := restrictedClient(ctx, t, codersdk.CreateTokenRequest{
+			Permissions:    []string{"workspace:read"},
+			OrganizationID: &user.OrganizationID,
+		})
+
+		workspaces, err := restricted.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+		require.Equal(t, workspace.ID, workspaces[0].ID)
+
+		_, err = restricted.Template(ctx, template.ID)
+		require.Error(t, err)
+		_, err = restricted.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.Error(t, err)
+
+		keys, err := client.GetTokens(ctx, codersdk.Me)
+		require.NoError(t, err)
+		var found bool
+		for _, key := range keys {
+			if key.Scope == codersdk.APIKeyScopeRestricted && key.OrganizationID != nil {
+				require.Equal(t, []string{"workspace:read"}, key.Permissions)
+				require.Equal(t, user.OrganizationID, *key.OrganizationID)
+				found = true
+			}
+		}
+		require.True(t, found)
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "restricted-other",
+		})
+		require.NoError(t, err)
+		restricted := restrictedClient(ctx, t, codersdk.CreateTokenRequest{
+			Permissions:    []string{"workspace:read"},
+			OrganizationID: &org.ID,
+		})
+
+		workspaces, err := restricted.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Empty(t, workspaces)
+	})
+
+	t.Run("CannotEscalate", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		restricted := restrictedClient(ctx, t, codersdk.CreateTokenRequest{
+			Permissions: []string{"api_key:*", "workspace:read"},
+		})
+
+		_, err := restricted.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Permissions: []string{"workspace:read"},
+		})
+		require.NoError(t, err)
+
+		for _, req := range []codersdk.CreateTokenRequest{
+			{},
+			{Permissions: []string{"workspace:*"}},
+			{Scope: codersdk.APIKeyScopeApplicationConnect},
+		} {
+			_, err = restricted.CreateToken(ctx, codersdk.Me, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		for _, req := range []codersdk.CreateTokenRequest{
+			{Scope: codersdk.APIKeyScopeRestricted},
+			{Permissions: []string{"workspace"}},
+			{Permissions: []string{"workspace:write"}},
+			{Scope: codersdk.APIKeyScopeAll, Permissions: []string{"workspace:read"}},
+			{Scope: "unknown"},
+		} {
+			_, err := client.CreateToken(ctx, codersdk.Me, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+}
+
 func TestAPIKey(t *testing.T) {
 	t.Parallel()
 	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
@@ -22,15 +22,30 @@ import (
 
 func (api *API) auditLogs(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
-		httpapi.Forbidden(rw)
+	page, ok := httpapi.ParseCursorPagination(rw, r)
+	if !ok {
 		return
 	}
-
-	page, ok := parsePagination(rw, r)
-	if !ok {
+	// The dashboard numbers its pages, so it skips to them with an offset
+	// instead of following cursors.
+	parser := httpapi.NewQueryParamParser()
+	offset := parser.Int(r.URL.Query(), 0, "offset")
+	if offset < 0 {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "offset",
+			Detail: `Query param "offset" must not be negative`,
+		})
+	}
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
 		return
 	}
+	if page.AfterID != uuid.Nil {
+		offset = 0
+	}
 
 	queryStr := r.URL.Query().Get("q")
 	filter, errs := auditSearchQuery(queryStr)
@@ -41,33 +56,38 @@ func (api *API) auditLogs(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if !api.Authorize(r, rbac.ActionRead, auditLogObject(filter.OrganizationID)) {
+		httpapi.Forbidden(rw)
+		return
+	}
 
 	dblogs, err := api.Database.GetAuditLogsOffset(ctx, database.GetAuditLogsOffsetParams{
-		Offset:       int32(page.Offset),
-		Limit:        int32(page.Limit),
-		ResourceType: filter.ResourceType,
-		ResourceID:   filter.ResourceID,
-		Action:       filter.Action,
-		Username:     filter.Username,
-		Email:        filter.Email,
+		Offset:         int32(offset),
+		Limit:          page.FetchLimit(),
+		AfterID:        page.AfterID,
+		ResourceType:   filter.ResourceType,
+		ResourceID:     filter.ResourceID,
+		Action:         filter.Action,
+		Username:       filter.Username,
+		Email:          filter.Email,
+		OrganizationID: filter.OrganizationID,
 	})
 	if err != nil {
 		httpapi.InternalServerError(rw, err)
 		return
 	}
+	dblogs, nextCursor := httpapi.Page(page, dblogs, func(alog database.GetAuditLogsOffsetRow) uuid.UUID {
+		return alog.ID
+	})
 
 	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AuditLogResponse{
-		AuditLogs: convertAuditLogs(dblogs),
+		AuditLogs:  convertAuditLogs(dblogs),
+		NextCursor: nextCursor,
 	})
 }
 
 func (api *API) auditLogCount(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
-		httpapi.Forbidden(rw)
-		return
-	}
-
 	queryStr := r.URL.Query().Get("q")
 	filter, errs := auditSearchQuery(queryStr)
 	if len(errs) > 0 {
@@ -77,13 +97,18 @@ func (api *API) auditLogCount(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if !api.Authorize(r, rbac.ActionRead, auditLogObject(filter.OrganizationID)) {
+		httpapi.Forbidden(rw)
+		return
+	}
 
 	count, err := api.Database.GetAuditLogCount(ctx, database.GetAuditLogCountParams{
-		ResourceType: filter.ResourceType,
-		ResourceID:   filter.ResourceID,
-		Action:       filter.Action,
-		Username:     filter.Username,
-		Email:        filter.Email,
+		ResourceType:   filter.ResourceType,
+		ResourceID:     filter.ResourceID,
+		Action:         filter.Action,
+		Username:       filter.Username,
+		Email:          filter.Email,
+		OrganizationID: filter.OrganizationID,
 	})
 	if err != nil {
 		httpapi.InternalServerError(rw, err)
@@ -148,6 +173,7 @@ func (api *API) generateFakeAuditLog(rw http.ResponseWriter, r *http.Request) {
 		ID:               uuid.New(),
 		Time:             time.Now(),
 		UserID:           user.ID,
+		OrganizationID:   params.OrganizationID,
 		Ip:               ipNet,
 		UserAgent:        r.UserAgent(),
 		ResourceType:     database.ResourceType(params.ResourceType),
@@ -166,6 +192,16 @@ func (api *API) generateFakeAuditLog(rw http.ResponseWriter, r *http.Request) {
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+// auditLogObject is the object that's authorized to read the audit logs of
+// an organization. Reading the logs of all organizations requires the
+// site-wide permission.
+func auditLogObject(organizationID uuid.UUID) rbac.Object {
+	if organizationID == uuid.Nil {
+		return rbac.ResourceAuditLog
+	}
+	return rbac.ResourceAuditLog.InOrg(organizationID)
+}
+
 func convertAuditLogs(dblogs []database.GetAuditLogsOffsetRow) []codersdk.AuditLog {
 	alogs := make([]codersdk.AuditLog, 0, len(dblogs))
 
@@ -259,11 +295,12 @@ func auditSearchQuery(query string) (database.GetAuditLogsOffsetParams, []coders
 	// other parsing.
 	parser := httpapi.NewQueryParamParser()
 	filter := database.GetAuditLogsOffsetParams{
-		ResourceType: resourceTypeFromString(parser.String(searchParams, "", "resource_type")),
-		ResourceID:   parser.UUID(searchParams, uuid.Nil, "resource_id"),
-		Action:       actionFromString(parser.String(searchParams, "", "action")),
-		Username:     parser.String(searchParams, "", "username"),
-		Email:        parser.String(searchParams, "", "email"),
+		ResourceType:   resourceTypeFromString(parser.String(searchParams, "", "resource_type")),
+		ResourceID:     parser.UUID(searchParams, uuid.Nil, "resource_id"),
+		Action:         actionFromString(parser.String(searchParams, "", "action")),
+		Username:       parser.String(searchParams, "", "username"),
+		Email:          parser.String(searchParams, "", "email"),
+		OrganizationID: parser.UUID(searchParams, uuid.Nil, "organization_id"),
 	}
 
 	return filter, parser.Errors
@@ -285,6 +322,10 @@ func resourceTypeFromString(resourceTypeString string) string {
 		return resourceTypeString
 	case codersdk.ResourceTypeAPIKey:
 		return resourceTypeString
+	case codersdk.ResourceTypeGroupMember:
+		return resourceTypeString
+	case codersdk.ResourceTypeOrganizationMember:
+		return resourceTypeString
 	}
 	return ""
 }
@@ -297,6 +338,8 @@ func actionFromString(actionString string) string {
 		return actionString
 	case codersdk.AuditActionDelete:
 		return actionString
+	case codersdk.AuditActionDeny:
+		return actionString
 	default:
 	}
 	return ""
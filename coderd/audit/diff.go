@@ -15,7 +15,8 @@ type Auditable interface {
 		database.TemplateVersion |
 		database.User |
 		database.Workspace |
-		database.GitSSHKey
+		database.GitSSHKey |
+		database.GroupMember
 }
 
 // Map is a map of changed fields in an audited resource. It maps field names to
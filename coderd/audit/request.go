@@ -22,6 +22,12 @@ type RequestParams struct {
 
 	Request *http.Request
 	Action  database.AuditAction
+	// AdditionalFields is stored alongside the audit log for context that
+	// isn't part of the audited resource.
+	AdditionalFields json.RawMessage
+	// ResourceTarget overrides the target derived from the resource, for
+	// resources that don't hold a human-readable name themselves.
+	ResourceTarget string
 }
 
 type Request[T Auditable] struct {
@@ -45,6 +51,10 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return typed.Name
 	case database.GitSSHKey:
 		return typed.PublicKey
+	case database.GroupMember:
+		return typed.UserID.String()
+	case database.OrganizationMember:
+		return typed.UserID.String()
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -64,6 +74,10 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 		return typed.ID
 	case database.GitSSHKey:
 		return typed.UserID
+	case database.GroupMember:
+		return typed.GroupID
+	case database.OrganizationMember:
+		return typed.UserID
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -83,6 +97,31 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeWorkspace
 	case database.GitSSHKey:
 		return database.ResourceTypeGitSshKey
+	case database.GroupMember:
+		return database.ResourceTypeGroupMember
+	case database.OrganizationMember:
+		return database.ResourceTypeOrganizationMember
+	default:
+		panic(fmt.Sprintf("unknown resource %T", tgt))
+	}
+}
+
+// OrganizationID returns the organization of the resource. Resources that
+// don't belong to an organization return uuid.Nil.
+func OrganizationID[T Auditable](tgt T) uuid.UUID {
+	switch typed := any(tgt).(type) {
+	case database.Organization:
+		return typed.ID
+	case database.Template:
+		return typed.OrganizationID
+	case database.TemplateVersion:
+		return typed.OrganizationID
+	case database.Workspace:
+		return typed.OrganizationID
+	case database.OrganizationMember:
+		return typed.OrganizationID
+	case database.User, database.GitSSHKey, database.GroupMember:
+		return uuid.Nil
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -123,6 +162,15 @@ func InitRequest[T Auditable](w http.ResponseWriter, p *RequestParams) (*Request
 			}
 		}
 
+		additionalFields := p.AdditionalFields
+		if len(additionalFields) == 0 {
+			additionalFields = json.RawMessage("{}")
+		}
+		resourceTarget := p.ResourceTarget
+		if resourceTarget == "" {
+			resourceTarget = either(req.Old, req.New, ResourceTarget[T])
+		}
+
 		ip, err := parseIP(p.Request.RemoteAddr)
 		if err != nil {
 			p.Log.Warn(logCtx, "parse ip", slog.Error(err))
@@ -132,16 +180,17 @@ func InitRequest[T Auditable](w http.ResponseWriter, p *RequestParams) (*Request
 			ID:               uuid.New(),
 			Time:             database.Now(),
 			UserID:           httpmw.APIKey(p.Request).UserID,
+			OrganizationID:   either(req.Old, req.New, OrganizationID[T]),
 			Ip:               ip,
 			UserAgent:        p.Request.UserAgent(),
 			ResourceType:     either(req.Old, req.New, ResourceType[T]),
 			ResourceID:       either(req.Old, req.New, ResourceID[T]),
-			ResourceTarget:   either(req.Old, req.New, ResourceTarget[T]),
+			ResourceTarget:   resourceTarget,
 			Action:           p.Action,
 			Diff:             diffRaw,
 			StatusCode:       int32(sw.Status),
 			RequestID:        httpmw.RequestID(p.Request),
-			AdditionalFields: json.RawMessage("{}"),
+			AdditionalFields: additionalFields,
 		})
 		if err != nil {
 			p.Log.Error(logCtx, "export audit log", slog.Error(err))
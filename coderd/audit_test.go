@@ -2,12 +2,14 @@ package coderd_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -28,7 +30,7 @@ func TestAuditLogs(t *testing.T) {
 		require.NoError(t, err)
 
 		alogs, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
-			Pagination: codersdk.Pagination{
+			CursorPagination: codersdk.CursorPagination{
 				Limit: 1,
 			},
 		})
@@ -37,6 +39,92 @@ func TestAuditLogs(t *testing.T) {
 		require.Equal(t, int64(1), count.Count)
 		require.Len(t, alogs.AuditLogs, 1)
 	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		for i := 0; i < 3; i++ {
+			err := client.CreateTestAuditLog(ctx, codersdk.CreateTestAuditLogRequest{})
+			require.NoError(t, err)
+		}
+
+		all, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			CursorPagination: codersdk.CursorPagination{Limit: 25},
+		})
+		require.NoError(t, err)
+		require.Len(t, all.AuditLogs, 3)
+		require.Empty(t, all.NextCursor)
+
+		page, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			CursorPagination: codersdk.CursorPagination{Limit: 2},
+		})
+		require.NoError(t, err)
+		require.Equal(t, all.AuditLogs[:2], page.AuditLogs)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			CursorPagination: codersdk.CursorPagination{Cursor: page.NextCursor, Limit: 2},
+		})
+		require.NoError(t, err)
+		require.Equal(t, all.AuditLogs[2:], page.AuditLogs)
+		require.Empty(t, page.NextCursor)
+
+		// Offsets are still supported for numbered pages.
+		page, err = client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			CursorPagination: codersdk.CursorPagination{Limit: 1},
+			Offset:           1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, all.AuditLogs[1:2], page.AuditLogs)
+	})
+
+	t.Run("OrganizationAuditor", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		auditor := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleOrgAuditor(user.OrganizationID))
+
+		err := client.CreateTestAuditLog(ctx, codersdk.CreateTestAuditLogRequest{
+			OrganizationID: user.OrganizationID,
+		})
+		require.NoError(t, err)
+		err = client.CreateTestAuditLog(ctx, codersdk.CreateTestAuditLogRequest{})
+		require.NoError(t, err)
+
+		// Organization auditors can only read the logs of their organization.
+		_, err = auditor.AuditLogs(ctx, codersdk.AuditLogsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		query := "organization_id:" + user.OrganizationID.String()
+		alogs, err := auditor.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			SearchQuery: query,
+			CursorPagination: codersdk.CursorPagination{
+				Limit: 25,
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, alogs.AuditLogs, 1)
+
+		count, err := auditor.AuditLogCount(ctx, codersdk.AuditLogCountRequest{
+			SearchQuery: query,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count.Count)
+
+		_, err = auditor.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			SearchQuery: "organization_id:" + uuid.NewString(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
 }
 
 func TestAuditLogsFilter(t *testing.T) {
@@ -136,7 +224,7 @@ func TestAuditLogsFilter(t *testing.T) {
 				t.Parallel()
 				auditLogs, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
 					SearchQuery: testCase.SearchQuery,
-					Pagination: codersdk.Pagination{
+					CursorPagination: codersdk.CursorPagination{
 						Limit: 25,
 					},
 				})
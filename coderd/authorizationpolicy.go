@@ -0,0 +1,164 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// authorizationPolicy exports the roles and the rego policy that authorize
+// requests. The policy alone is returned as text with "?format=rego".
+// Reviewing the policy is limited to those who can read the audit log.
+func (api *API) authorizationPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+	case "rego":
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(rbac.Policy()))
+		return
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid query param \"format\".",
+			Validations: []codersdk.ValidationError{{Field: "format", Detail: "Must be \"json\" or \"rego\"."}},
+		})
+		return
+	}
+
+	organizations, err := api.Database.GetOrganizations(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	groups, err := api.Database.GetGroupsWithRoles(ctx)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	roles := convertAuthorizationRoles(rbac.SiteRoles())
+	for _, organization := range organizations {
+		roles = append(roles, convertAuthorizationRoles(rbac.OrganizationRoles(organization.ID))...)
+	}
+	groupRoles := make([]codersdk.AuthorizationGroupRoles, 0, len(groups))
+	for _, group := range groups {
+		groupRoles = append(groupRoles, codersdk.AuthorizationGroupRoles{
+			GroupID:        group.ID,
+			GroupName:      group.Name,
+			OrganizationID: group.OrganizationID.UUID,
+			Roles:          group.Roles,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AuthorizationPolicy{
+		Rego:       rbac.Policy(),
+		Roles:      roles,
+		Scopes:     convertAuthorizationRoles(rbac.ScopeRoles()),
+		GroupRoles: groupRoles,
+	})
+}
+
+// simulateAuthorization evaluates permission checks as another user would,
+// with the roles they have right now.
+func (api *API) simulateAuthorization(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.AuthorizationSimulateRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	subject, err := api.Database.GetAuthorizationUserRoles(ctx, req.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "User not found.",
+			Validations: []codersdk.ValidationError{{Field: "user_id", Detail: "Must be the ID of an existing user."}},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	checks, ok := api.authorizationChecks(ctx, rw, httpmw.Authorization{
+		ID:               subject.ID,
+		Username:         subject.Username,
+		Roles:            subject.Roles,
+		Groups:           subject.Groups,
+		EveryoneExcluded: subject.EveryoneExcludedOrgs,
+		Scope:            rbac.ScopeAll,
+	}, req.Checks)
+	if !ok {
+		return
+	}
+	// Users that aren't active can't use the API at all.
+	if subject.Status != database.UserStatusActive {
+		for k := range checks {
+			checks[k] = false
+		}
+	}
+
+	groups := subject.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AuthorizationSimulateResponse{
+		UserID:   subject.ID,
+		Username: subject.Username,
+		Status:   codersdk.UserStatus(subject.Status),
+		Roles:    subject.Roles,
+		Groups:   groups,
+		Checks:   checks,
+	})
+}
+
+func convertAuthorizationRoles(roles []rbac.Role) []codersdk.AuthorizationRole {
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	converted := make([]codersdk.AuthorizationRole, 0, len(roles))
+	for _, role := range roles {
+		org := make(map[string][]codersdk.AuthorizationPermission, len(role.Org))
+		for orgID, perms := range role.Org {
+			org[orgID] = convertAuthorizationPermissions(perms)
+		}
+		converted = append(converted, codersdk.AuthorizationRole{
+			Name:        role.Name,
+			DisplayName: role.DisplayName,
+			Site:        convertAuthorizationPermissions(role.Site),
+			Org:         org,
+			User:        convertAuthorizationPermissions(role.User),
+		})
+	}
+	return converted
+}
+
+func convertAuthorizationPermissions(perms []rbac.Permission) []codersdk.AuthorizationPermission {
+	converted := make([]codersdk.AuthorizationPermission, 0, len(perms))
+	for _, perm := range perms {
+		converted = append(converted, codersdk.AuthorizationPermission{
+			Negate:       perm.Negate,
+			ResourceType: perm.ResourceType,
+			Action:       string(perm.Action),
+		})
+	}
+	return converted
+}
@@ -0,0 +1,145 @@
+package coderd_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAuthorizationPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		t.Cleanup(cancel)
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		policy, err := client.AuthorizationPolicy(ctx)
+		require.NoError(t, err)
+		require.Equal(t, rbac.Policy(), policy.Rego)
+		names := make([]string, 0, len(policy.Roles))
+		for _, role := range policy.Roles {
+			names = append(names, role.Name)
+		}
+		require.Contains(t, names, rbac.RoleOwner())
+		require.Contains(t, names, rbac.RoleOrgAdmin(user.OrganizationID))
+		require.NotEmpty(t, policy.Scopes)
+		require.Empty(t, policy.GroupRoles)
+
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/authcheck/policy?format=rego", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, rbac.Policy(), string(body))
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		t.Cleanup(cancel)
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		_, err := member.AuthorizationPolicy(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
+
+func TestSimulateAuthorization(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	t.Cleanup(cancel)
+
+	client := coderdtest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	member, memberUser := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+	checks := map[string]codersdk.AuthorizationCheck{
+		"read-own-workspaces": {
+			Object: codersdk.AuthorizationObject{
+				ResourceType:   rbac.ResourceWorkspace.Type,
+				OwnerID:        "me",
+				OrganizationID: user.OrganizationID.String(),
+			},
+			Action: rbac.ActionRead,
+		},
+		"read-all-workspaces": {
+			Object: codersdk.AuthorizationObject{
+				ResourceType:   rbac.ResourceWorkspace.Type,
+				OrganizationID: user.OrganizationID.String(),
+			},
+			Action: rbac.ActionRead,
+		},
+	}
+
+	resp, err := client.SimulateAuthorization(ctx, codersdk.AuthorizationSimulateRequest{
+		UserID: memberUser.ID,
+		Checks: checks,
+	})
+	require.NoError(t, err)
+	require.Equal(t, memberUser.ID, resp.UserID)
+	require.Contains(t, resp.Roles, rbac.RoleOrgMember(user.OrganizationID))
+	require.Equal(t, codersdk.AuthorizationResponse{
+		"read-own-workspaces": true,
+		"read-all-workspaces": false,
+	}, resp.Checks)
+
+	// Auditors can simulate checks too, and the results are those of the
+	// simulated user.
+	_, err = client.UpdateUserRoles(ctx, memberUser.ID.String(), codersdk.UpdateRoles{
+		Roles: []string{"auditor"},
+	})
+	require.NoError(t, err)
+	resp, err = member.SimulateAuthorization(ctx, codersdk.AuthorizationSimulateRequest{
+		UserID: user.UserID,
+		Checks: checks,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codersdk.AuthorizationResponse{
+		"read-own-workspaces": true,
+		"read-all-workspaces": true,
+	}, resp.Checks)
+
+	// Suspended users can't do anything.
+	_, err = client.UpdateUserStatus(ctx, memberUser.ID.String(), codersdk.UserStatusSuspended)
+	require.NoError(t, err)
+	resp, err = client.SimulateAuthorization(ctx, codersdk.AuthorizationSimulateRequest{
+		UserID: memberUser.ID,
+		Checks: checks,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codersdk.UserStatusSuspended, resp.Status)
+	require.Equal(t, codersdk.AuthorizationResponse{
+		"read-own-workspaces": false,
+		"read-all-workspaces": false,
+	}, resp.Checks)
+
+	_, err = client.SimulateAuthorization(ctx, codersdk.AuthorizationSimulateRequest{
+		UserID: uuid.New(),
+		Checks: checks,
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
@@ -1,6 +1,7 @@
 package coderd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -9,6 +10,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
@@ -20,7 +22,7 @@ import (
 // This is faster than calling Authorize() on each object.
 func AuthorizeFilter[O rbac.Objecter](h *HTTPAuthorizer, r *http.Request, action rbac.Action, objects []O) ([]O, error) {
 	roles := httpmw.UserAuthorization(r)
-	objects, err := rbac.Filter(r.Context(), h.Authorizer, roles.ID.String(), roles.Roles, roles.Scope.ToRBAC(), roles.Groups, action, objects)
+	objects, err := rbac.Filter(r.Context(), h.Authorizer, roles.ID.String(), roles.Roles, roles.Scope, roles.Groups, roles.EveryoneExcluded, action, objects)
 	if err != nil {
 		// Log the error as Filter should not be erroring.
 		h.Logger.Error(r.Context(), "filter failed",
@@ -65,7 +67,7 @@ func (api *API) Authorize(r *http.Request, action rbac.Action, object rbac.Objec
 //	}
 func (h *HTTPAuthorizer) Authorize(r *http.Request, action rbac.Action, object rbac.Objecter) bool {
 	roles := httpmw.UserAuthorization(r)
-	err := h.Authorizer.ByRoleName(r.Context(), roles.ID.String(), roles.Roles, roles.Scope.ToRBAC(), roles.Groups, action, object.RBACObject())
+	err := h.Authorizer.ByRoleName(r.Context(), roles.ID.String(), roles.Roles, roles.Scope, roles.Groups, roles.EveryoneExcluded, action, object.RBACObject())
 	if err != nil {
 		// Log the errors for debugging
 		internalError := new(rbac.UnauthorizedError)
@@ -97,7 +99,7 @@ func (h *HTTPAuthorizer) Authorize(r *http.Request, action rbac.Action, object r
 // Note the authorization is only for the given action and object type.
 func (h *HTTPAuthorizer) AuthorizeSQLFilter(r *http.Request, action rbac.Action, objectType string) (rbac.AuthorizeFilter, error) {
 	roles := httpmw.UserAuthorization(r)
-	prepared, err := h.Authorizer.PrepareByRoleName(r.Context(), roles.ID.String(), roles.Roles, roles.Scope.ToRBAC(), roles.Groups, action, objectType)
+	prepared, err := h.Authorizer.PrepareByRoleName(r.Context(), roles.ID.String(), roles.Roles, roles.Scope, roles.Groups, roles.EveryoneExcluded, action, objectType)
 	if err != nil {
 		return nil, xerrors.Errorf("prepare filter: %w", err)
 	}
@@ -110,6 +112,49 @@ func (h *HTTPAuthorizer) AuthorizeSQLFilter(r *http.Request, action rbac.Action,
 	return filter, nil
 }
 
+// authorizationPermissions returns the actions the current API key can
+// perform on an object, so clients can hide the actions that would fail.
+func (api *API) authorizationPermissions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		auth  = httpmw.UserAuthorization(r)
+		query = r.URL.Query()
+	)
+
+	object := codersdk.AuthorizationObject{
+		ResourceType:   query.Get("resource_type"),
+		OwnerID:        query.Get("owner_id"),
+		OrganizationID: query.Get("organization_id"),
+		ResourceID:     query.Get("resource_id"),
+	}
+	if object.ResourceType == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query param \"resource_type\" is required.",
+			Validations: []codersdk.ValidationError{{Field: "resource_type", Detail: "Must be defined."}},
+		})
+		return
+	}
+
+	obj, found, ok := api.authorizationObject(ctx, rw, auth, object)
+	if !ok {
+		return
+	}
+	actions := make([]string, 0)
+	if found {
+		for _, action := range []rbac.Action{rbac.ActionCreate, rbac.ActionRead, rbac.ActionUpdate, rbac.ActionDelete} {
+			err := api.Authorizer.ByRoleName(ctx, auth.ID.String(), auth.Roles, auth.Scope, auth.Groups, auth.EveryoneExcluded, action, obj)
+			if err == nil {
+				actions = append(actions, string(action))
+			}
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AuthorizationPermissions{
+		Object:  object,
+		Actions: actions,
+	})
+}
+
 // checkAuthorization returns if the current API key can use the given
 // permissions, factoring in the current user's roles and the API key scopes.
 func (api *API) checkAuthorization(rw http.ResponseWriter, r *http.Request) {
@@ -128,6 +173,17 @@ func (api *API) checkAuthorization(rw http.ResponseWriter, r *http.Request) {
 		slog.F("roles", auth.Roles), slog.F("scope", auth.Scope),
 	)
 
+	response, ok := api.authorizationChecks(ctx, rw, auth, params.Checks)
+	if !ok {
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, response)
+}
+
+// authorizationChecks evaluates permission checks for the subject. If a
+// check is invalid, a response is written and ok is false.
+func (api *API) authorizationChecks(ctx context.Context, rw http.ResponseWriter, auth httpmw.Authorization, checks map[string]codersdk.AuthorizationCheck) (codersdk.AuthorizationResponse, bool) {
 	response := make(codersdk.AuthorizationResponse)
 	// Prevent using too many resources by ID. This prevents database abuse
 	// from this endpoint. This also prevents misuse of this endpoint, as
@@ -136,7 +192,7 @@ func (api *API) checkAuthorization(rw http.ResponseWriter, r *http.Request) {
 		idFetch  int
 		maxFetch = 10
 	)
-	for _, v := range params.Checks {
+	for _, v := range checks {
 		if v.Object.ResourceID != "" {
 			idFetch++
 		}
@@ -148,73 +204,101 @@ func (api *API) checkAuthorization(rw http.ResponseWriter, r *http.Request) {
 				maxFetch, idFetch, idFetch-maxFetch,
 			),
 		})
-		return
+		return nil, false
 	}
 
-	for k, v := range params.Checks {
+	for k, v := range checks {
 		if v.Object.ResourceType == "" {
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 				Message: fmt.Sprintf("Object's \"resource_type\" field must be defined for key %q.", k),
 			})
-			return
+			return nil, false
 		}
 
-		obj := rbac.Object{
-			Owner: v.Object.OwnerID,
-			OrgID: v.Object.OrganizationID,
-			Type:  v.Object.ResourceType,
+		obj, found, ok := api.authorizationObject(ctx, rw, auth, v.Object)
+		if !ok {
+			return nil, false
 		}
-		if obj.Owner == "me" {
-			obj.Owner = auth.ID.String()
+		if !found {
+			response[k] = false
+			continue
 		}
 
-		// If a resource ID is specified, fetch that specific resource.
-		if v.Object.ResourceID != "" {
-			id, err := uuid.Parse(v.Object.ResourceID)
-			if err != nil {
-				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-					Message:     fmt.Sprintf("Object %q id is not a valid uuid.", v.Object.ResourceID),
-					Validations: []codersdk.ValidationError{{Field: "resource_id", Detail: err.Error()}},
-				})
-				return
-			}
+		err := api.Authorizer.ByRoleName(ctx, auth.ID.String(), auth.Roles, auth.Scope, auth.Groups, auth.EveryoneExcluded, rbac.Action(v.Action), obj)
+		response[k] = err == nil
+	}
 
-			var dbObj rbac.Objecter
-			var dbErr error
-			// Only support referencing some resources by ID.
-			switch v.Object.ResourceType {
-			case rbac.ResourceWorkspaceExecution.Type:
-				wrkSpace, err := api.Database.GetWorkspaceByID(ctx, id)
-				if err == nil {
-					dbObj = wrkSpace.ExecutionRBAC()
-				}
-				dbErr = err
-			case rbac.ResourceWorkspace.Type:
-				dbObj, dbErr = api.Database.GetWorkspaceByID(ctx, id)
-			case rbac.ResourceTemplate.Type:
-				dbObj, dbErr = api.Database.GetTemplateByID(ctx, id)
-			case rbac.ResourceUser.Type:
-				dbObj, dbErr = api.Database.GetUserByID(ctx, id)
-			case rbac.ResourceGroup.Type:
-				dbObj, dbErr = api.Database.GetGroupByID(ctx, id)
-			default:
-				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-					Message:     fmt.Sprintf("Object type %q does not support \"resource_id\" field.", v.Object.ResourceType),
-					Validations: []codersdk.ValidationError{{Field: "resource_type", Detail: err.Error()}},
-				})
-				return
+	return response, true
+}
+
+// authorizationObject converts the object of a permission check to an rbac
+// object. Objects referenced by ID are fetched from the database, and found
+// is false if they don't exist or can't be read. If the object is invalid, a
+// response is written and ok is false.
+func (api *API) authorizationObject(ctx context.Context, rw http.ResponseWriter, auth httpmw.Authorization, object codersdk.AuthorizationObject) (obj rbac.Object, found bool, ok bool) {
+	obj = rbac.Object{
+		Owner: object.OwnerID,
+		OrgID: object.OrganizationID,
+		Type:  object.ResourceType,
+	}
+	if obj.Owner == "me" {
+		obj.Owner = auth.ID.String()
+	}
+
+	// If a resource ID is specified, fetch that specific resource.
+	if object.ResourceID != "" {
+		id, err := uuid.Parse(object.ResourceID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message:     fmt.Sprintf("Object %q id is not a valid uuid.", object.ResourceID),
+				Validations: []codersdk.ValidationError{{Field: "resource_id", Detail: err.Error()}},
+			})
+			return rbac.Object{}, false, false
+		}
+
+		var dbObj rbac.Objecter
+		var dbErr error
+		// Only support referencing some resources by ID.
+		switch object.ResourceType {
+		case rbac.ResourceWorkspaceExecution.Type:
+			wrkSpace, err := api.Database.GetWorkspaceByID(ctx, id)
+			if err == nil {
+				dbObj = wrkSpace.ExecutionRBAC()
 			}
-			if dbErr != nil {
-				// 404 or unauthorized is false
-				response[k] = false
-				continue
+			dbErr = err
+		case rbac.ResourceWorkspace.Type:
+			dbObj, dbErr = api.Database.GetWorkspaceByID(ctx, id)
+		case rbac.ResourceTemplate.Type:
+			dbObj, dbErr = api.Database.GetTemplateByID(ctx, id)
+		case rbac.ResourceUser.Type:
+			dbObj, dbErr = api.Database.GetUserByID(ctx, id)
+		case rbac.ResourceGroup.Type:
+			dbObj, dbErr = api.Database.GetGroupByID(ctx, id)
+		case rbac.ResourceGroupMember.Type:
+			// The resource ID is the ID of the group whose members
+			// are being checked.
+			group, err := api.Database.GetGroupByID(ctx, id)
+			if err == nil {
+				var admins []uuid.UUID
+				admins, err = api.Database.GetGroupMemberIDsWithRole(ctx, database.GetGroupMemberIDsWithRoleParams{
+					GroupID: group.ID,
+					Role:    rbac.RoleGroupAdmin(),
+				})
+				dbObj = group.MembersRBACObject(admins)
 			}
-			obj = dbObj.RBACObject()
+			dbErr = err
+		default:
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message:     fmt.Sprintf("Object type %q does not support \"resource_id\" field.", object.ResourceType),
+				Validations: []codersdk.ValidationError{{Field: "resource_type", Detail: "Only workspaces, templates, users, and groups can be referenced by ID."}},
+			})
+			return rbac.Object{}, false, false
 		}
-
-		err := api.Authorizer.ByRoleName(r.Context(), auth.ID.String(), auth.Roles, auth.Scope.ToRBAC(), auth.Groups, rbac.Action(v.Action), obj)
-		response[k] = err == nil
+		if dbErr != nil {
+			// 404 or unauthorized is false
+			return rbac.Object{}, false, true
+		}
+		obj = dbObj.RBACObject()
 	}
-
-	httpapi.Write(ctx, rw, http.StatusOK, response)
+	return obj, true, true
 }
@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/google/uuid"
@@ -139,3 +140,61 @@ func TestCheckPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizationPermissions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	t.Cleanup(cancel)
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	memberClient := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	templateObject := codersdk.AuthorizationObject{
+		ResourceType: rbac.ResourceTemplate.Type,
+		ResourceID:   template.ID.String(),
+	}
+
+	perms, err := client.AuthorizationPermissions(ctx, templateObject)
+	require.NoError(t, err)
+	require.Equal(t, templateObject, perms.Object)
+	require.Equal(t, []string{rbac.ActionCreate, rbac.ActionRead, rbac.ActionUpdate, rbac.ActionDelete}, perms.Actions)
+
+	perms, err = memberClient.AuthorizationPermissions(ctx, templateObject)
+	require.NoError(t, err)
+	require.NotContains(t, perms.Actions, rbac.ActionUpdate)
+	require.NotContains(t, perms.Actions, rbac.ActionDelete)
+
+	perms, err = memberClient.AuthorizationPermissions(ctx, codersdk.AuthorizationObject{
+		ResourceType:   rbac.ResourceWorkspace.Type,
+		OwnerID:        "me",
+		OrganizationID: user.OrganizationID.String(),
+	})
+	require.NoError(t, err)
+	require.Contains(t, perms.Actions, rbac.ActionCreate)
+
+	// Missing objects have no actions.
+	perms, err = client.AuthorizationPermissions(ctx, codersdk.AuthorizationObject{
+		ResourceType: rbac.ResourceTemplate.Type,
+		ResourceID:   uuid.NewString(),
+	})
+	require.NoError(t, err)
+	require.Empty(t, perms.Actions)
+
+	for _, object := range []codersdk.AuthorizationObject{
+		{},
+		{ResourceType: rbac.ResourceTemplate.Type, ResourceID: "not-a-uuid"},
+		{ResourceType: rbac.ResourceFile.Type, ResourceID: uuid.NewString()},
+	} {
+		_, err = client.AuthorizationPermissions(ctx, object)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	}
+}
@@ -107,9 +107,21 @@ func (e *Executor) runOnce(t time.Time) Stats {
 		return stats
 	}
 
+	// Members of groups with an autostop policy have a deadline even if their
+	// workspaces don't have a TTL.
+	groupAutostopUserIDs, err := e.db.GetGroupAutostopUserIDs(e.ctx)
+	if err != nil {
+		e.log.Error(e.ctx, "get users with group autostop policies", slog.Error(err))
+		return stats
+	}
+	groupAutostopUsers := make(map[uuid.UUID]struct{}, len(groupAutostopUserIDs))
+	for _, id := range groupAutostopUserIDs {
+		groupAutostopUsers[id] = struct{}{}
+	}
+
 	var eligibleWorkspaceIDs []uuid.UUID
 	for _, ws := range workspaces {
-		if isEligibleForAutoStartStop(ws) {
+		if isEligibleForAutoStartStop(ws, groupAutostopUsers) {
 			eligibleWorkspaceIDs = append(eligibleWorkspaceIDs, ws.ID)
 		}
 	}
@@ -133,7 +145,7 @@ func (e *Executor) runOnce(t time.Time) Stats {
 					log.Error(e.ctx, "get workspace autostart failed", slog.Error(err))
 					return nil
 				}
-				if !isEligibleForAutoStartStop(ws) {
+				if !isEligibleForAutoStartStop(ws, groupAutostopUsers) {
 					return nil
 				}
 
@@ -167,8 +179,12 @@ func (e *Executor) runOnce(t time.Time) Stats {
 
 				log.Info(e.ctx, "scheduling workspace transition", slog.F("transition", validTransition))
 
+				reason := database.BuildReasonAutostart
+				if validTransition == database.WorkspaceTransitionStop {
+					reason = database.BuildReasonAutostop
+				}
 				stats.Transitions[ws.ID] = validTransition
-				if err := build(e.ctx, db, ws, validTransition, priorHistory, priorJob); err != nil {
+				if err := build(e.ctx, db, ws, validTransition, reason, priorHistory.TemplateVersionID, priorHistory, priorJob); err != nil {
 					log.Error(e.ctx, "unable to transition workspace",
 						slog.F("transition", validTransition),
 						slog.Error(err),
@@ -191,11 +207,19 @@ func (e *Executor) runOnce(t time.Time) Stats {
 		e.log.Error(e.ctx, "workspace scheduling errgroup failed", slog.Error(err))
 	}
 
+	e.runMaintenance(currentTick, stats.Transitions)
+
 	return stats
 }
 
-func isEligibleForAutoStartStop(ws database.Workspace) bool {
-	return !ws.Deleted && (ws.AutostartSchedule.String != "" || ws.Ttl.Int64 > 0)
+func isEligibleForAutoStartStop(ws database.Workspace, groupAutostopUsers map[uuid.UUID]struct{}) bool {
+	if ws.Deleted {
+		return false
+	}
+	if _, ok := groupAutostopUsers[ws.OwnerID]; ok {
+		return true
+	}
+	return ws.AutostartSchedule.String != "" || ws.Ttl.Int64 > 0
 }
 
 func getNextTransition(
@@ -235,7 +259,7 @@ func getNextTransition(
 
 // TODO(cian): this function duplicates most of api.postWorkspaceBuilds. Refactor.
 // See: https://github.com/coder/coder/issues/1401
-func build(ctx context.Context, store database.Store, workspace database.Workspace, trans database.WorkspaceTransition, priorHistory database.WorkspaceBuild, priorJob database.ProvisionerJob) error {
+func build(ctx context.Context, store database.Store, workspace database.Workspace, trans database.WorkspaceTransition, buildReason database.BuildReason, templateVersionID uuid.UUID, priorHistory database.WorkspaceBuild, priorJob database.ProvisionerJob) error {
 	template, err := store.GetTemplateByID(ctx, workspace.TemplateID)
 	if err != nil {
 		return xerrors.Errorf("get workspace template: %w", err)
@@ -257,16 +281,6 @@ func build(ctx context.Context, store database.Store, workspace database.Workspa
 	provisionerJobID := uuid.New()
 	now := database.Now()
 
-	var buildReason database.BuildReason
-	switch trans {
-	case database.WorkspaceTransitionStart:
-		buildReason = database.BuildReasonAutostart
-	case database.WorkspaceTransitionStop:
-		buildReason = database.BuildReasonAutostop
-	default:
-		return xerrors.Errorf("Unsupported transition: %q", trans)
-	}
-
 	newProvisionerJob, err := store.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
 		ID:             provisionerJobID,
 		CreatedAt:      now,
@@ -287,7 +301,7 @@ func build(ctx context.Context, store database.Store, workspace database.Workspa
 		CreatedAt:         now,
 		UpdatedAt:         now,
 		WorkspaceID:       workspace.ID,
-		TemplateVersionID: priorHistory.TemplateVersionID,
+		TemplateVersionID: templateVersionID,
 		BuildNumber:       priorBuildNumber + 1,
 		ProvisionerState:  priorHistory.ProvisionerState,
 		InitiatorID:       workspace.OwnerID,
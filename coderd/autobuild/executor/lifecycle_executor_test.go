@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/goleak"
 
 	"github.com/coder/coder/coderd/autobuild/executor"
@@ -540,6 +541,99 @@ func TestExecutorAutostartMultipleOK(t *testing.T) {
 	assert.Len(t, stats2.Transitions, 0)
 }
 
+func TestExecutorMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sched   = mustSchedule(t, "CRON_TZ=UTC 0 * * * *")
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+		})
+		// Given: we have a user with a running workspace
+		workspace = mustProvisionWorkspace(t, client)
+	)
+
+	// Given: the workspace template has been updated and has a maintenance window
+	template, err := client.Template(ctx, workspace.TemplateID)
+	require.NoError(t, err)
+	newVersion := coderdtest.UpdateTemplateVersion(t, client, template.OrganizationID, nil, workspace.TemplateID)
+	coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+	require.NoError(t, client.UpdateActiveTemplateVersion(ctx, workspace.TemplateID, codersdk.UpdateActiveTemplateVersion{
+		ID: newVersion.ID,
+	}))
+	_, err = client.UpdateTemplateMaintenanceWindow(ctx, workspace.TemplateID, codersdk.TemplateMaintenanceWindow{
+		Schedule:       sched.String(),
+		DurationMillis: (30 * time.Minute).Milliseconds(),
+	})
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks during the window
+	go func() {
+		tickCh <- sched.Next(workspace.LatestBuild.CreatedAt).Add(time.Minute)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should be restarted with the updated version
+	stats := <-statsCh
+	assert.NoError(t, stats.Error)
+	assert.Len(t, stats.Transitions, 1)
+	assert.Equal(t, database.WorkspaceTransitionStart, stats.Transitions[workspace.ID])
+
+	workspace = coderdtest.MustWorkspace(t, client, workspace.ID)
+	assert.Equal(t, codersdk.BuildReasonMaintenance, workspace.LatestBuild.Reason)
+	assert.Equal(t, newVersion.ID, workspace.LatestBuild.TemplateVersionID)
+}
+
+func TestExecutorMaintenanceWindowExempt(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sched   = mustSchedule(t, "CRON_TZ=UTC 0 * * * *")
+		ctx     = context.Background()
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+		})
+		// Given: we have a user with a running workspace
+		workspace = mustProvisionWorkspace(t, client)
+	)
+
+	// Given: the workspace template has been updated, and its maintenance
+	// window exempts everyone
+	template, err := client.Template(ctx, workspace.TemplateID)
+	require.NoError(t, err)
+	newVersion := coderdtest.UpdateTemplateVersion(t, client, template.OrganizationID, nil, workspace.TemplateID)
+	coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+	require.NoError(t, client.UpdateActiveTemplateVersion(ctx, workspace.TemplateID, codersdk.UpdateActiveTemplateVersion{
+		ID: newVersion.ID,
+	}))
+	_, err = client.UpdateTemplateMaintenanceWindow(ctx, workspace.TemplateID, codersdk.TemplateMaintenanceWindow{
+		Schedule:       sched.String(),
+		DurationMillis: (30 * time.Minute).Milliseconds(),
+		ExemptGroupIDs: []uuid.UUID{template.OrganizationID},
+	})
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks during the window
+	go func() {
+		tickCh <- sched.Next(workspace.LatestBuild.CreatedAt).Add(time.Minute)
+		close(tickCh)
+	}()
+
+	// Then: the workspace should not be updated
+	stats := <-statsCh
+	assert.NoError(t, stats.Error)
+	assert.Len(t, stats.Transitions, 0)
+}
+
 func mustProvisionWorkspace(t *testing.T, client *codersdk.Client, mut ...func(*codersdk.CreateWorkspaceRequest)) codersdk.Workspace {
 	t.Helper()
 	user := coderdtest.CreateFirstUser(t, client)
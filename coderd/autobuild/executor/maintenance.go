@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/autobuild/schedule"
+	"github.com/coder/coder/coderd/database"
+)
+
+// runMaintenance restarts running workspaces on old versions of a template
+// with its active version while the template's maintenance window is open.
+// Workspaces that are stopped, building, or whose last build failed are left
+// as they are, so failed updates aren't retried in a loop.
+func (e *Executor) runMaintenance(currentTick time.Time, transitions map[uuid.UUID]database.WorkspaceTransition) {
+	windows, err := e.db.GetTemplateMaintenanceWindows(e.ctx)
+	if err != nil {
+		e.log.Error(e.ctx, "get template maintenance windows", slog.Error(err))
+		return
+	}
+	openWindows := make(map[uuid.UUID]database.TemplateMaintenanceWindow)
+	templateIDs := make([]uuid.UUID, 0, len(windows))
+	for _, window := range windows {
+		sched, err := schedule.Weekly(window.Schedule)
+		if err != nil {
+			e.log.Warn(e.ctx, "invalid maintenance window schedule",
+				slog.F("template_id", window.TemplateID), slog.Error(err))
+			continue
+		}
+		if _, open := sched.Window(currentTick, time.Duration(window.Duration)); !open {
+			continue
+		}
+		openWindows[window.TemplateID] = window
+		templateIDs = append(templateIDs, window.TemplateID)
+	}
+	if len(openWindows) == 0 {
+		return
+	}
+
+	workspaces, err := e.db.GetWorkspaces(e.ctx, database.GetWorkspacesParams{
+		TemplateIds: templateIDs,
+	})
+	if err != nil {
+		e.log.Error(e.ctx, "get workspaces for maintenance", slog.Error(err))
+		return
+	}
+	workspacesByTemplateID := make(map[uuid.UUID][]database.Workspace)
+	for _, workspace := range workspaces {
+		workspacesByTemplateID[workspace.TemplateID] = append(workspacesByTemplateID[workspace.TemplateID], workspace)
+	}
+
+	for templateID, workspaces := range workspacesByTemplateID {
+		window := openWindows[templateID]
+		ownerIDs := make([]uuid.UUID, 0, len(workspaces))
+		for _, workspace := range workspaces {
+			ownerIDs = append(ownerIDs, workspace.OwnerID)
+		}
+		exempt, err := MaintenanceExemptUsers(e.ctx, e.db, workspaces[0].OrganizationID, window, ownerIDs)
+		if err != nil {
+			e.log.Error(e.ctx, "get users exempt from maintenance",
+				slog.F("template_id", templateID), slog.Error(err))
+			continue
+		}
+
+		for _, workspace := range workspaces {
+			if exempt[workspace.OwnerID] {
+				continue
+			}
+			log := e.log.With(slog.F("workspace_id", workspace.ID))
+			err := e.db.InTx(func(db database.Store) error {
+				template, err := db.GetTemplateByID(e.ctx, templateID)
+				if err != nil {
+					return xerrors.Errorf("get template: %w", err)
+				}
+				priorHistory, err := db.GetLatestWorkspaceBuildByWorkspaceID(e.ctx, workspace.ID)
+				if err != nil {
+					return xerrors.Errorf("get latest workspace build: %w", err)
+				}
+				if priorHistory.Transition != database.WorkspaceTransitionStart ||
+					priorHistory.TemplateVersionID == template.ActiveVersionID {
+					return nil
+				}
+				priorJob, err := db.GetProvisionerJobByID(e.ctx, priorHistory.JobID)
+				if err != nil {
+					return xerrors.Errorf("get last provisioner job: %w", err)
+				}
+				if !priorJob.CompletedAt.Valid || priorJob.CanceledAt.Valid || priorJob.Error.String != "" {
+					return nil
+				}
+
+				log.Info(e.ctx, "updating workspace during maintenance window",
+					slog.F("template_version_id", template.ActiveVersionID))
+				transitions[workspace.ID] = database.WorkspaceTransitionStart
+				return build(e.ctx, db, workspace, database.WorkspaceTransitionStart, database.BuildReasonMaintenance,
+					template.ActiveVersionID, priorHistory, priorJob)
+			})
+			if err != nil {
+				log.Error(e.ctx, "unable to update workspace during maintenance window", slog.Error(err))
+			}
+		}
+	}
+}
+
+// MaintenanceExemptUsers returns which of the users are members of the groups
+// exempt from the maintenance window.
+func MaintenanceExemptUsers(ctx context.Context, db database.Store, organizationID uuid.UUID, window database.TemplateMaintenanceWindow, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	exempt := make(map[uuid.UUID]bool)
+	if len(window.ExemptGroupIds) == 0 || len(userIDs) == 0 {
+		return exempt, nil
+	}
+	exemptGroups := make(map[uuid.UUID]bool, len(window.ExemptGroupIds))
+	for _, groupID := range window.ExemptGroupIds {
+		exemptGroups[groupID] = true
+	}
+	// The members of the Everyone group, whose ID is the organization's,
+	// aren't stored as group members.
+	if exemptGroups[organizationID] {
+		for _, userID := range userIDs {
+			exempt[userID] = true
+		}
+		return exempt, nil
+	}
+	memberships, err := db.GetGroupMembershipsByUserIDs(ctx, database.GetGroupMembershipsByUserIDsParams{
+		OrganizationID: organizationID,
+		UserIds:        userIDs,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get group memberships: %w", err)
+	}
+	for _, membership := range memberships {
+		if exemptGroups[membership.GroupID] {
+			exempt[membership.UserID] = true
+		}
+	}
+	return exempt, nil
+}
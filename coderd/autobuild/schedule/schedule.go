@@ -111,6 +111,14 @@ func (s Schedule) Next(t time.Time) time.Time {
 	return s.sched.Next(t)
 }
 
+// Window returns the start of the window that's open at t, where windows open
+// at the times in the schedule and stay open for d. If no window is open at t,
+// it returns the start of the next one.
+func (s Schedule) Window(t time.Time, d time.Duration) (start time.Time, open bool) {
+	start = s.Next(t.Add(-d))
+	return start, !start.After(t)
+}
+
 var t0 = time.Date(1970, 1, 1, 1, 1, 1, 0, time.UTC)
 var tMax = t0.Add(168 * time.Hour)
 
@@ -163,6 +163,31 @@ func Test_Weekly(t *testing.T) {
 	}
 }
 
+func Test_Window(t *testing.T) {
+	t.Parallel()
+
+	sched, err := schedule.Weekly("CRON_TZ=UTC 0 2 * * SAT")
+	require.NoError(t, err)
+	// A Saturday.
+	opensAt := time.Date(2022, 10, 8, 2, 0, 0, 0, time.UTC)
+
+	start, open := sched.Window(opensAt.Add(-time.Minute), time.Hour)
+	require.False(t, open)
+	require.Equal(t, opensAt, start)
+
+	start, open = sched.Window(opensAt, time.Hour)
+	require.True(t, open)
+	require.Equal(t, opensAt, start)
+
+	start, open = sched.Window(opensAt.Add(59*time.Minute), time.Hour)
+	require.True(t, open)
+	require.Equal(t, opensAt, start)
+
+	start, open = sched.Window(opensAt.Add(time.Hour), time.Hour)
+	require.False(t, open)
+	require.Equal(t, opensAt.AddDate(0, 0, 7), start)
+}
+
 func mustLocation(t *testing.T, s string) *time.Location {
 	t.Helper()
 	loc, err := time.LoadLocation(s)
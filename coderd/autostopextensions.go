@@ -0,0 +1,351 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// autostopExtensionPeriod is the "day" the limits of autostop extension
+// policies apply to. It's rolling so it doesn't depend on time zones.
+const autostopExtensionPeriod = 24 * time.Hour
+
+func (api *API) templateAutostopExtensionPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	policy, err := api.autostopExtensionPolicy(ctx, api.Database, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching autostop extension policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, policy)
+}
+
+func (api *API) putTemplateAutostopExtensionPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.AutostopExtensionPolicy
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.MaxExtensionsPerDay < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "max_extensions_per_day",
+			Detail: "Must not be negative.",
+		})
+	}
+	if req.MaxExtensionPerDayMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "max_extension_per_day_ms",
+			Detail: "Must not be negative.",
+		})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid autostop extension policy.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	policy, err := api.Database.UpsertTemplateAutostopExtensionPolicy(ctx, database.UpsertTemplateAutostopExtensionPolicyParams{
+		TemplateID:          template.ID,
+		MaxExtensionsPerDay: req.MaxExtensionsPerDay,
+		MaxExtensionPerDay:  int64(time.Duration(req.MaxExtensionPerDayMillis) * time.Millisecond),
+		UpdatedAt:           database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating autostop extension policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateAutostopExtensionPolicy(policy))
+}
+
+func (api *API) deleteTemplateAutostopExtensionPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteTemplateAutostopExtensionPolicyByTemplateID(ctx, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting autostop extension policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Autostop extension policy deleted.",
+	})
+}
+
+// postExtendWorkspace pushes back the deadline of a running workspace by a
+// duration, as opposed to putExtendWorkspace, which sets it.
+func (api *API) postExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	apiKey := httpmw.APIKey(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.ExtendWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	extended, code, resp, err := api.extendWorkspaceDeadline(ctx, workspace, apiKey.UserID, func(deadline time.Time) time.Time {
+		return deadline.Add(time.Duration(req.DurationMillis) * time.Millisecond)
+	})
+	if err != nil {
+		api.Logger.Info(ctx, "extending workspace", slog.Error(err))
+		httpapi.Write(ctx, rw, code, resp)
+		return
+	}
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	httpapi.Write(ctx, rw, http.StatusOK, extended)
+}
+
+// extendWorkspaceDeadline moves the deadline of the workspace's running build
+// to the time returned by deadline, which is passed the current deadline.
+// Pushing the deadline back counts against the autostop extension policy of
+// the workspace's template. The returned code and response describe errors.
+func (api *API) extendWorkspaceDeadline(ctx context.Context, workspace database.Workspace, userID uuid.UUID, deadline func(time.Time) time.Time) (codersdk.ExtendWorkspaceResponse, int, codersdk.Response, error) {
+	var (
+		code     = http.StatusOK
+		resp     = codersdk.Response{}
+		extended codersdk.ExtendWorkspaceResponse
+	)
+
+	err := api.Database.InTx(func(s database.Store) error {
+		template, err := s.GetTemplateByID(ctx, workspace.TemplateID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Error fetching workspace template!"
+			return xerrors.Errorf("get workspace template: %w", err)
+		}
+
+		build, err := s.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Error fetching workspace build."
+			return xerrors.Errorf("get latest workspace build: %w", err)
+		}
+
+		job, err := s.GetProvisionerJobByID(ctx, build.JobID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Error fetching workspace provisioner job."
+			return xerrors.Errorf("get provisioner job: %w", err)
+		}
+
+		if build.Transition != database.WorkspaceTransitionStart {
+			code = http.StatusConflict
+			resp.Message = "Workspace must be started, current status: " + string(build.Transition)
+			return xerrors.Errorf("workspace must be started, current status: %s", build.Transition)
+		}
+
+		if !job.CompletedAt.Valid {
+			code = http.StatusConflict
+			resp.Message = "Workspace is still building!"
+			return xerrors.Errorf("workspace is still building")
+		}
+
+		if build.Deadline.IsZero() {
+			code = http.StatusConflict
+			resp.Message = "Workspace shutdown is manual."
+			return xerrors.Errorf("workspace shutdown is manual")
+		}
+
+		groups, err := s.GetUserEffectiveGroups(ctx, database.GetUserEffectiveGroupsParams{
+			UserID:         workspace.OwnerID,
+			OrganizationID: workspace.OrganizationID,
+		})
+		if err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Error fetching workspace owner groups."
+			return xerrors.Errorf("get user groups: %w", err)
+		}
+
+		policy, err := api.autostopExtensionPolicy(ctx, s, template.ID)
+		if err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Error fetching autostop extension policy."
+			return err
+		}
+
+		now := database.Now()
+		extensions, err := s.GetWorkspaceAutostopExtensionsByWorkspaceID(ctx, database.GetWorkspaceAutostopExtensionsByWorkspaceIDParams{
+			WorkspaceID:  workspace.ID,
+			CreatedAfter: now.Add(-autostopExtensionPeriod),
+		})
+		if err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Error fetching workspace autostop extensions."
+			return xerrors.Errorf("get workspace autostop extensions: %w", err)
+		}
+
+		newDeadline := deadline(build.Deadline).UTC()
+		extension := newDeadline.Sub(build.Deadline)
+		err = validWorkspaceDeadline(job.CompletedAt.Time, newDeadline, time.Duration(template.MaxTtl))
+		if err == nil {
+			groupDeadline := groupAutostopDeadline(groups, job.CompletedAt.Time)
+			if !groupDeadline.IsZero() && newDeadline.After(groupDeadline) {
+				err = errDeadlineOverGroupMax
+			}
+		}
+		if err == nil && extension > 0 {
+			err = checkAutostopExtension(policy.AutostopExtensionPolicy, extensions, extension)
+		}
+		if err != nil {
+			// NOTE(Cian): Putting the error in the Message field on request from the FE folks.
+			// Normally, we would put the validation error in Validations, but this endpoint is
+			// not tied to a form or specific named user input on the FE.
+			code = http.StatusBadRequest
+			resp.Message = "Cannot extend workspace: " + err.Error()
+			return err
+		}
+
+		if err := s.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
+			ID:               build.ID,
+			UpdatedAt:        build.UpdatedAt,
+			ProvisionerState: build.ProvisionerState,
+			Deadline:         newDeadline,
+		}); err != nil {
+			code = http.StatusInternalServerError
+			resp.Message = "Failed to extend workspace deadline."
+			return xerrors.Errorf("update workspace build: %w", err)
+		}
+		if extension > 0 {
+			inserted, err := s.InsertWorkspaceAutostopExtension(ctx, database.InsertWorkspaceAutostopExtensionParams{
+				ID:          uuid.New(),
+				WorkspaceID: workspace.ID,
+				UserID:      userID,
+				Extension:   int64(extension),
+				CreatedAt:   now,
+			})
+			if err != nil {
+				code = http.StatusInternalServerError
+				resp.Message = "Failed to record workspace autostop extension."
+				return xerrors.Errorf("insert workspace autostop extension: %w", err)
+			}
+			extensions = append(extensions, inserted)
+		}
+		resp.Message = "Deadline updated to " + newDeadline.Format(time.RFC3339) + "."
+		extended = autostopExtensionsLeft(policy.AutostopExtensionPolicy, extensions, newDeadline)
+
+		return nil
+	})
+	return extended, code, resp, err
+}
+
+// autostopExtensionPolicy returns the autostop extension policy of the
+// template, or the deployment's if the template has none.
+func (api *API) autostopExtensionPolicy(ctx context.Context, db database.Store, templateID uuid.UUID) (codersdk.TemplateAutostopExtensionPolicy, error) {
+	policy, err := db.GetTemplateAutostopExtensionPolicyByTemplateID(ctx, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return codersdk.TemplateAutostopExtensionPolicy{
+			AutostopExtensionPolicy: api.AutostopExtensionPolicy,
+			Inherited:               true,
+		}, nil
+	}
+	if err != nil {
+		return codersdk.TemplateAutostopExtensionPolicy{}, xerrors.Errorf("get template autostop extension policy: %w", err)
+	}
+	return convertTemplateAutostopExtensionPolicy(policy), nil
+}
+
+// checkAutostopExtension returns an error if extending a deadline by the
+// extension would exceed the policy, given the extensions of the last day.
+func checkAutostopExtension(policy codersdk.AutostopExtensionPolicy, extensions []database.WorkspaceAutostopExtension, extension time.Duration) error {
+	if policy.MaxExtensionsPerDay > 0 && len(extensions) >= int(policy.MaxExtensionsPerDay) {
+		return xerrors.Errorf("the workspace was already extended %d times in the last 24 hours, the most allowed", len(extensions))
+	}
+	maxExtension := time.Duration(policy.MaxExtensionPerDayMillis) * time.Millisecond
+	if maxExtension > 0 {
+		extended := totalAutostopExtension(extensions)
+		if extended+extension > maxExtension {
+			return xerrors.Errorf("the workspace was already extended by %s in the last 24 hours, and can be extended by at most %s",
+				extended.Round(time.Second), maxExtension)
+		}
+	}
+	return nil
+}
+
+// autostopExtensionsLeft returns how much more the deadline can be extended
+// under the policy, given the extensions of the last day.
+func autostopExtensionsLeft(policy codersdk.AutostopExtensionPolicy, extensions []database.WorkspaceAutostopExtension, deadline time.Time) codersdk.ExtendWorkspaceResponse {
+	extended := codersdk.ExtendWorkspaceResponse{
+		Deadline: deadline,
+	}
+	if policy.MaxExtensionsPerDay > 0 {
+		left := policy.MaxExtensionsPerDay - int32(len(extensions))
+		if left < 0 {
+			left = 0
+		}
+		extended.ExtensionsLeft = &left
+	}
+	if policy.MaxExtensionPerDayMillis > 0 {
+		left := policy.MaxExtensionPerDayMillis - totalAutostopExtension(extensions).Milliseconds()
+		if left < 0 {
+			left = 0
+		}
+		extended.ExtensionLeftMillis = &left
+	}
+	return extended
+}
+
+func totalAutostopExtension(extensions []database.WorkspaceAutostopExtension) time.Duration {
+	var total time.Duration
+	for _, extension := range extensions {
+		total += time.Duration(extension.Extension)
+	}
+	return total
+}
+
+func convertTemplateAutostopExtensionPolicy(policy database.TemplateAutostopExtensionPolicy) codersdk.TemplateAutostopExtensionPolicy {
+	return codersdk.TemplateAutostopExtensionPolicy{
+		AutostopExtensionPolicy: codersdk.AutostopExtensionPolicy{
+			MaxExtensionsPerDay:      policy.MaxExtensionsPerDay,
+			MaxExtensionPerDayMillis: time.Duration(policy.MaxExtensionPerDay).Milliseconds(),
+		},
+	}
+}
@@ -0,0 +1,201 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateAutostopExtensionPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		deploymentPolicy := codersdk.AutostopExtensionPolicy{
+			MaxExtensionsPerDay: 3,
+		}
+		client := coderdtest.New(t, &coderdtest.Options{
+			AutostopExtensionPolicy: deploymentPolicy,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		policy, err := client.TemplateAutostopExtensionPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.True(t, policy.Inherited)
+		require.Equal(t, deploymentPolicy, policy.AutostopExtensionPolicy)
+
+		templatePolicy := codersdk.AutostopExtensionPolicy{
+			MaxExtensionsPerDay:      1,
+			MaxExtensionPerDayMillis: time.Hour.Milliseconds(),
+		}
+		policy, err = client.UpdateTemplateAutostopExtensionPolicy(ctx, template.ID, templatePolicy)
+		require.NoError(t, err)
+		require.False(t, policy.Inherited)
+		require.Equal(t, templatePolicy, policy.AutostopExtensionPolicy)
+
+		err = client.DeleteTemplateAutostopExtensionPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		policy, err = client.TemplateAutostopExtensionPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.True(t, policy.Inherited)
+		require.Equal(t, deploymentPolicy, policy.AutostopExtensionPolicy)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateAutostopExtensionPolicy(ctx, template.ID, codersdk.AutostopExtensionPolicy{
+			MaxExtensionsPerDay:      -1,
+			MaxExtensionPerDayMillis: -1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.TemplateAutostopExtensionPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateAutostopExtensionPolicy(ctx, template.ID, codersdk.AutostopExtensionPolicy{
+			MaxExtensionsPerDay: 1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestExtendWorkspace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unlimited", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = ptr.Ref(time.Hour.Milliseconds())
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		workspace, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		deadline := workspace.LatestBuild.Deadline.Time
+		extended, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, deadline.Add(time.Hour), extended.Deadline, time.Second)
+		require.Nil(t, extended.ExtensionsLeft)
+		require.Nil(t, extended.ExtensionLeftMillis)
+
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.WithinDuration(t, extended.Deadline, workspace.LatestBuild.Deadline.Time, time.Second)
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = ptr.Ref(time.Hour.Milliseconds())
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateAutostopExtensionPolicy(ctx, template.ID, codersdk.AutostopExtensionPolicy{
+			MaxExtensionsPerDay:      2,
+			MaxExtensionPerDayMillis: (90 * time.Minute).Milliseconds(),
+		})
+		require.NoError(t, err)
+
+		extended, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, ptr.Ref[int32](1), extended.ExtensionsLeft)
+		require.Equal(t, ptr.Ref((30 * time.Minute).Milliseconds()), extended.ExtensionLeftMillis)
+
+		// Another hour is more than the policy allows in total.
+		_, err = client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		extended, err = client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: (30 * time.Minute).Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, ptr.Ref[int32](0), extended.ExtensionsLeft)
+		require.Equal(t, ptr.Ref[int64](0), extended.ExtensionLeftMillis)
+
+		// Setting a later deadline counts as an extension too.
+		err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+			Deadline: extended.Deadline.Add(time.Minute),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Contains(t, apiErr.Message, "already extended 2 times")
+
+		// But moving the deadline sooner doesn't.
+		err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+			Deadline: extended.Deadline.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Stopped", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		build := coderdtest.CreateWorkspaceBuild(t, client, workspace, database.WorkspaceTransitionStop)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,196 @@
+package coderd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+// batch executes the sub-requests of a batch request in order, so a page
+// that needs many resources can fetch them in a single round trip. Every
+// sub-request goes through the regular router, so it's authenticated,
+// authorized, rate limited, and audited like it was sent on its own.
+func (api *API) batch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req codersdk.BatchRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if len(req.Requests) > codersdk.BatchRequestLimit {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("A batch can contain at most %d requests.", codersdk.BatchRequestLimit),
+			Validations: []codersdk.ValidationError{
+				{Field: "requests", Detail: fmt.Sprintf("got %d requests", len(req.Requests))},
+			},
+		})
+		return
+	}
+
+	subRequests := make([]*http.Request, 0, len(req.Requests))
+	for i, subReq := range req.Requests {
+		subRequest, err := newBatchSubRequest(r, subReq)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Invalid request at index %d.", i),
+				Detail:  err.Error(),
+			})
+			return
+		}
+		subRequests = append(subRequests, subRequest)
+	}
+
+	resp := codersdk.BatchResponse{
+		Responses: make([]codersdk.BatchSubResponse, 0, len(subRequests)),
+	}
+	for _, subRequest := range subRequests {
+		if ctx.Err() != nil {
+			return
+		}
+		resp.Responses = append(resp.Responses, api.serveBatchSubRequest(subRequest))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// newBatchSubRequest creates a request for the sub-request that's
+// authenticated the same as the batch request.
+func newBatchSubRequest(r *http.Request, subReq codersdk.BatchSubRequest) (*http.Request, error) {
+	switch subReq.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return nil, xerrors.Errorf("method %q is not supported", subReq.Method)
+	}
+	u, err := url.Parse(subReq.Path)
+	if err != nil {
+		return nil, xerrors.Errorf("parse path: %w", err)
+	}
+	if u.IsAbs() || u.Host != "" || !strings.HasPrefix(u.Path, "/api/v2/") {
+		return nil, xerrors.Errorf("path %q must start with \"/api/v2/\"", subReq.Path)
+	}
+	if strings.TrimSuffix(u.Path, "/") == "/api/v2/batch" {
+		return nil, xerrors.New("batch requests can't be nested")
+	}
+	// Query parameter authentication carries over, since the sub-request
+	// can't have the session token in its own path.
+	if token := r.URL.Query().Get(codersdk.SessionTokenKey); token != "" {
+		query := u.Query()
+		query.Set(codersdk.SessionTokenKey, token)
+		u.RawQuery = query.Encode()
+	}
+
+	subRequest, err := http.NewRequestWithContext(r.Context(), subReq.Method, u.String(), bytes.NewReader(subReq.Body))
+	if err != nil {
+		return nil, err
+	}
+	subRequest.Header = r.Header.Clone()
+	subRequest.Header.Del("Content-Length")
+	// Bodies are embedded in the batch response, so they mustn't be
+	// compressed on their own.
+	subRequest.Header.Del("Accept-Encoding")
+	for name, value := range subReq.Headers {
+		// The caller's authentication can't be swapped for another.
+		if strings.EqualFold(name, codersdk.SessionCustomHeader) || strings.EqualFold(name, "Cookie") {
+			continue
+		}
+		subRequest.Header.Set(name, value)
+	}
+	subRequest.RemoteAddr = r.RemoteAddr
+	subRequest.Host = r.Host
+	// Client certificates authenticate sub-requests too.
+	subRequest.TLS = r.TLS
+	return subRequest, nil
+}
+
+// serveBatchSubRequest runs the sub-request through the router and returns
+// the recorded response.
+func (api *API) serveBatchSubRequest(r *http.Request) codersdk.BatchSubResponse {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	// The router would otherwise continue routing from where the batch
+	// request's routing left off.
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, chi.NewRouteContext())
+	rec := &batchResponseRecorder{
+		header:     http.Header{},
+		statusCode: http.StatusOK,
+		cancel:     cancel,
+	}
+	api.RootHandler.ServeHTTP(rec, r.WithContext(ctx))
+
+	if rec.streaming {
+		return codersdk.BatchSubResponse{
+			StatusCode: http.StatusBadRequest,
+			Headers:    map[string]string{},
+			Body:       json.RawMessage(`{"message":"Streaming endpoints can't be used in a batch request."}`),
+		}
+	}
+
+	resp := codersdk.BatchSubResponse{
+		StatusCode: rec.statusCode,
+		Headers:    make(map[string]string, len(rec.header)),
+		Body:       batchResponseBody(rec.body.Bytes()),
+	}
+	for name := range rec.header {
+		resp.Headers[name] = rec.header.Get(name)
+	}
+	return resp
+}
+
+// batchResponseBody returns the body as JSON. Bodies that aren't JSON are
+// encoded as a string.
+func batchResponseBody(body []byte) json.RawMessage {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return body
+	}
+	data, err := json.Marshal(string(body))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// batchResponseRecorder buffers the response of a sub-request. Streaming
+// endpoints flush before they're done, so the first flush cancels the
+// sub-request instead of waiting for a stream that never ends.
+type batchResponseRecorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	statusCode  int
+	wroteHeader bool
+	streaming   bool
+	cancel      context.CancelFunc
+}
+
+func (b *batchResponseRecorder) Header() http.Header {
+	return b.header
+}
+
+func (b *batchResponseRecorder) WriteHeader(statusCode int) {
+	if b.wroteHeader {
+		return
+	}
+	b.wroteHeader = true
+	b.statusCode = statusCode
+}
+
+func (b *batchResponseRecorder) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+func (b *batchResponseRecorder) Flush() {
+	b.streaming = true
+	b.cancel()
+}
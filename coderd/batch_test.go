@@ -0,0 +1,115 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+	t.Run("Ordered", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		resp, err := client.Batch(ctx, codersdk.BatchRequest{
+			Requests: []codersdk.BatchSubRequest{
+				{Method: http.MethodGet, Path: "/api/v2/users/me"},
+				{Method: http.MethodGet, Path: fmt.Sprintf("/api/v2/organizations/%s", user.OrganizationID)},
+				{
+					Method: http.MethodPost,
+					Path:   fmt.Sprintf("/api/v2/organizations/%s/members/me/workspaces", user.OrganizationID),
+					Body:   json.RawMessage(`{"name":"nope"}`),
+				},
+				{Method: http.MethodGet, Path: "/api/v2/users?limit=1"},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 4)
+
+		require.Equal(t, http.StatusOK, resp.Responses[0].StatusCode)
+		var me codersdk.User
+		require.NoError(t, json.Unmarshal(resp.Responses[0].Body, &me))
+		require.Equal(t, user.UserID, me.ID)
+
+		require.Equal(t, http.StatusOK, resp.Responses[1].StatusCode)
+		var org codersdk.Organization
+		require.NoError(t, json.Unmarshal(resp.Responses[1].Body, &org))
+		require.Equal(t, user.OrganizationID, org.ID)
+
+		// A failed sub-request doesn't fail the batch.
+		require.Equal(t, http.StatusBadRequest, resp.Responses[2].StatusCode)
+
+		require.Equal(t, http.StatusOK, resp.Responses[3].StatusCode)
+		var users []codersdk.User
+		require.NoError(t, json.Unmarshal(resp.Responses[3].Body, &users))
+		require.Len(t, users, 1)
+	})
+
+	t.Run("CallerAuthorization", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		resp, err := member.Batch(ctx, codersdk.BatchRequest{
+			Requests: []codersdk.BatchSubRequest{{
+				Method: http.MethodGet,
+				Path:   "/api/v2/webhooks",
+				// The caller's session is used regardless.
+				Headers: map[string]string{codersdk.SessionCustomHeader: client.SessionToken},
+			}},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 1)
+		require.Equal(t, http.StatusNotFound, resp.Responses[0].StatusCode)
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		resp, err := client.Batch(ctx, codersdk.BatchRequest{
+			Requests: []codersdk.BatchSubRequest{
+				{Method: http.MethodGet, Path: "/api/v2/events"},
+				{Method: http.MethodGet, Path: "/api/v2/buildinfo"},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 2)
+		require.Equal(t, http.StatusBadRequest, resp.Responses[0].StatusCode)
+		require.Equal(t, http.StatusOK, resp.Responses[1].StatusCode)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		for _, req := range []codersdk.BatchRequest{
+			{Requests: make([]codersdk.BatchSubRequest, codersdk.BatchRequestLimit+1)},
+			{Requests: []codersdk.BatchSubRequest{{Method: http.MethodGet, Path: "https://example.com/api/v2/users"}}},
+			{Requests: []codersdk.BatchSubRequest{{Method: http.MethodGet, Path: "/healthz"}}},
+			{Requests: []codersdk.BatchSubRequest{{Method: http.MethodPost, Path: "/api/v2/batch"}}},
+			{Requests: []codersdk.BatchSubRequest{{Method: "CONNECT", Path: "/api/v2/users"}}},
+		} {
+			ctx, _ := testutil.Context(t)
+			_, err := client.Batch(ctx, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package coderd
 
 import (
+	"context"
 	"crypto/x509"
 	"io"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/andybalholm/brotli"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
@@ -29,12 +31,17 @@ import (
 	"github.com/coder/coder/coderd/awsidentity"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/gitsshkey"
+	"github.com/coder/coder/coderd/groupsync"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/metricscache"
+	"github.com/coder/coder/coderd/objectstore"
+	"github.com/coder/coder/coderd/openapi"
+	"github.com/coder/coder/coderd/orgcache"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/coderd/webhookdelivery"
 	"github.com/coder/coder/coderd/workspacequota"
 	"github.com/coder/coder/coderd/wsconncache"
 	"github.com/coder/coder/codersdk"
@@ -57,6 +64,7 @@ type Options struct {
 
 	Auditor                        audit.Auditor
 	WorkspaceQuotaEnforcer         workspacequota.Enforcer
+	GroupSyncer                    groupsync.Syncer
 	AgentConnectionUpdateFrequency time.Duration
 	AgentInactiveDisconnectTimeout time.Duration
 	// APIRateLimit is the minutely throughput rate limit per user or ip.
@@ -83,8 +91,64 @@ type Options struct {
 	AgentStatsRefreshInterval   time.Duration
 	Experimental                bool
 	DeploymentFlags             *codersdk.DeploymentFlags
+
+	// OrganizationOIDCProvider returns the OIDC config for the identity
+	// provider of an organization. It discovers the provider from the
+	// issuer by default.
+	OrganizationOIDCProvider func(ctx context.Context, config database.OrganizationOIDCConfig) (*OIDCConfig, error)
+
+	// OrganizationWebhookRetryInterval is the initial delay before a failed
+	// organization webhook delivery is retried.
+	OrganizationWebhookRetryInterval time.Duration
+	// WebhookRetryInterval is the initial delay before a failed
+	// deployment-wide webhook delivery is retried.
+	WebhookRetryInterval time.Duration
+
+	// OrganizationDeletionPollInterval is how often organization deletions
+	// check whether the workspaces of the organization are deleted, and how
+	// often deletions that no replica runs are resumed.
+	OrganizationDeletionPollInterval time.Duration
+
+	// WorkspaceBatchPollInterval is how often bulk actions on workspaces
+	// check whether the builds they started completed.
+	WorkspaceBatchPollInterval time.Duration
+
+	// WorkspaceCostAccrualInterval is how often the costs of running
+	// workspaces are accrued.
+	WorkspaceCostAccrualInterval time.Duration
+
+	// AutostopExtensionPolicy limits how far users can extend the deadlines
+	// of running workspaces whose templates don't set a policy of their own.
+	AutostopExtensionPolicy codersdk.AutostopExtensionPolicy
+
+	// WorkspaceArchiveStore stores the archives of workspaces. Workspaces
+	// can't be archived when it's nil.
+	WorkspaceArchiveStore objectstore.Store
+	// WorkspaceArchivePollInterval is how often archives check whether the
+	// builds that archive and restore workspaces completed, and whether
+	// workspaces became dormant.
+	WorkspaceArchivePollInterval time.Duration
+
+	// WorkspaceAutoRebuildPollInterval is how often the workspaces of
+	// templates with an auto-rebuild policy are checked for failed agents.
+	WorkspaceAutoRebuildPollInterval time.Duration
+
+	// ClientCertificates authenticates API requests without a session token
+	// by their verified TLS client certificate.
+	ClientCertificates *httpmw.ClientCertificateConfig
+
+	// APIKeyRateLimit limits the requests of each API key across the API.
+	APIKeyRateLimit httpmw.RateLimitConfig
+	// WorkspaceBuildRateLimit additionally limits how often each API key
+	// can create workspaces and workspace builds, since they're expensive.
+	WorkspaceBuildRateLimit httpmw.RateLimitConfig
 }
 
+// organizationCacheTTL is how long organizations resolved from URLs are
+// cached. Changes are published to every replica, so it only bounds how
+// stale a replica that missed one can be.
+const organizationCacheTTL = 10 * time.Second
+
 // New constructs a Coder API handler.
 func New(options *Options) *API {
 	if options == nil {
@@ -127,6 +191,33 @@ func New(options *Options) *API {
 	if options.WorkspaceQuotaEnforcer == nil {
 		options.WorkspaceQuotaEnforcer = workspacequota.NewNop()
 	}
+	if options.GroupSyncer == nil {
+		options.GroupSyncer = groupsync.NewNop()
+	}
+	if options.OrganizationOIDCProvider == nil {
+		options.OrganizationOIDCProvider = discoverOrganizationOIDC(options.AccessURL)
+	}
+	if options.OrganizationWebhookRetryInterval == 0 {
+		options.OrganizationWebhookRetryInterval = time.Second
+	}
+	if options.WebhookRetryInterval == 0 {
+		options.WebhookRetryInterval = time.Second
+	}
+	if options.OrganizationDeletionPollInterval == 0 {
+		options.OrganizationDeletionPollInterval = 5 * time.Second
+	}
+	if options.WorkspaceBatchPollInterval == 0 {
+		options.WorkspaceBatchPollInterval = 5 * time.Second
+	}
+	if options.WorkspaceCostAccrualInterval == 0 {
+		options.WorkspaceCostAccrualInterval = time.Minute
+	}
+	if options.WorkspaceArchivePollInterval == 0 {
+		options.WorkspaceArchivePollInterval = time.Minute
+	}
+	if options.WorkspaceAutoRebuildPollInterval == 0 {
+		options.WorkspaceAutoRebuildPollInterval = time.Minute
+	}
 
 	siteCacheDir := options.CacheDir
 	if siteCacheDir != "" {
@@ -144,6 +235,12 @@ func New(options *Options) *API {
 	)
 
 	r := chi.NewRouter()
+	maintenanceCtx, maintenanceCancel := context.WithCancel(context.Background())
+	organizationDeletionsCtx, organizationDeletionsCancel := context.WithCancel(context.Background())
+	workspaceBatchesCtx, workspaceBatchesCancel := context.WithCancel(context.Background())
+	workspaceCostsCtx, workspaceCostsCancel := context.WithCancel(context.Background())
+	workspaceArchivesCtx, workspaceArchivesCancel := context.WithCancel(context.Background())
+	workspaceAutoRebuildsCtx, workspaceAutoRebuildsCancel := context.WithCancel(context.Background())
 	api := &API{
 		Options:     options,
 		RootHandler: r,
@@ -155,22 +252,63 @@ func New(options *Options) *API {
 		metricsCache:           metricsCache,
 		Auditor:                atomic.Pointer[audit.Auditor]{},
 		WorkspaceQuotaEnforcer: atomic.Pointer[workspacequota.Enforcer]{},
+		GroupSyncer:            atomic.Pointer[groupsync.Syncer]{},
+		Capabilities: []codersdk.Capability{
+			codersdk.CapabilityErrorCodes,
+			codersdk.CapabilityOrganizationMembers,
+			codersdk.CapabilityDeprecationHeaders,
+			codersdk.CapabilityOpenAPI,
+			codersdk.CapabilityRPC,
+		},
+		OpenAPISpecs:     openAPISpecs(),
+		deprecationUsage: httpmw.NewDeprecationUsage(options.PrometheusRegistry),
+		organizationOIDC: map[uuid.UUID]organizationOIDCEntry{},
+
+		maintenanceCtx:    maintenanceCtx,
+		maintenanceCancel: maintenanceCancel,
+
+		organizationDeletionsCtx:      organizationDeletionsCtx,
+		organizationDeletionsCancel:   organizationDeletionsCancel,
+		organizationDeletionsWorkerID: uuid.New(),
+
+		workspaceBatchesCtx:    workspaceBatchesCtx,
+		workspaceBatchesCancel: workspaceBatchesCancel,
+
+		workspaceCostsCtx:    workspaceCostsCtx,
+		workspaceCostsCancel: workspaceCostsCancel,
+
+		workspaceArchivesCtx:    workspaceArchivesCtx,
+		workspaceArchivesCancel: workspaceArchivesCancel,
+
+		workspaceAutoRebuildsCtx:    workspaceAutoRebuildsCtx,
+		workspaceAutoRebuildsCancel: workspaceAutoRebuildsCancel,
 	}
 	api.Auditor.Store(&options.Auditor)
 	api.WorkspaceQuotaEnforcer.Store(&options.WorkspaceQuotaEnforcer)
+	api.GroupSyncer.Store(&options.GroupSyncer)
 	api.workspaceAgentCache = wsconncache.New(api.dialWorkspaceAgentTailnet, 0)
+	api.OrganizationCache = orgcache.New(options.Database, options.Pubsub, options.Logger.Named("orgcache"), organizationCacheTTL)
+	api.WebhookEngine = webhookdelivery.New(options.Database, options.Logger.Named("webhooks"))
+	api.WebhookEngine.Register(webhookdelivery.KindDeployment, api.webhookHandler())
+	api.WebhookEngine.Register(webhookdelivery.KindOrganization, api.organizationWebhookHandler())
 	api.derpServer = derp.NewServer(key.NewNode(), tailnet.Logger(options.Logger))
 	oauthConfigs := &httpmw.OAuth2Configs{
 		Github: options.GithubOAuth2Config,
 		OIDC:   options.OIDCConfig,
 	}
 
-	apiKeyMiddleware := httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
-		DB:              options.Database,
-		OAuth2Configs:   oauthConfigs,
-		RedirectToLogin: false,
-		Optional:        false,
+	extractAPIKey := httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
+		DB:                 options.Database,
+		OAuth2Configs:      oauthConfigs,
+		RedirectToLogin:    false,
+		Optional:           false,
+		ClientCertificates: options.ClientCertificates,
 	})
+	api.APIKeyRateLimiter = httpmw.RateLimitAPIKey(options.APIKeyRateLimit)
+	apiKeyMiddleware := func(next http.Handler) http.Handler {
+		return extractAPIKey(api.APIKeyRateLimiter(api.EnforceMaintenance(next)))
+	}
+	workspaceBuildRateLimiter := httpmw.RateLimitAPIKey(options.WorkspaceBuildRateLimit)
 	// Same as above but it redirects to the login page.
 	apiKeyMiddlewareRedirect := httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
 		DB:              options.Database,
@@ -184,6 +322,7 @@ func New(options *Options) *API {
 		httpmw.Recover(api.Logger),
 		httpmw.Logger(api.Logger),
 		httpmw.Prometheus(options.PrometheusRegistry),
+		api.deprecationUsage.Middleware,
 		// handleSubdomainApplications checks if the first subdomain is a valid
 		// app URL. If it is, it will serve that application.
 		api.handleSubdomainApplications(
@@ -199,6 +338,7 @@ func New(options *Options) *API {
 			}),
 			httpmw.ExtractUserParam(api.Database),
 			httpmw.ExtractWorkspaceAndAgentParam(api.Database),
+			api.EnforceOrganizationIPAllowlist(workspaceOrganizationID),
 		),
 		// Build-Version is helpful for debugging.
 		func(next http.Handler) http.Handler {
@@ -218,6 +358,7 @@ func New(options *Options) *API {
 			httpmw.ExtractUserParam(api.Database),
 			// Extracts the <workspace.agent> from the url
 			httpmw.ExtractWorkspaceAndAgentParam(api.Database),
+			api.EnforceOrganizationIPAllowlist(workspaceOrganizationID),
 		)
 		r.HandleFunc("/*", api.workspaceAppsProxyPath)
 	}
@@ -239,9 +380,11 @@ func New(options *Options) *API {
 
 		r.NotFound(func(rw http.ResponseWriter, r *http.Request) { httpapi.RouteNotFound(rw) })
 		r.Use(
+			httpmw.Compress(options.PrometheusRegistry, httpmw.DefaultCompressMinBytes),
 			tracing.Middleware(api.TracerProvider),
 			// Specific routes can specify smaller limits.
 			httpmw.RateLimitPerMinute(options.APIRateLimit),
+			httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: httpmw.DefaultMaxBodyBytes}),
 		)
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			httpapi.Write(r.Context(), w, http.StatusOK, codersdk.Response{
@@ -252,6 +395,16 @@ func New(options *Options) *API {
 		// All CSP errors will be logged
 		r.Post("/csp/reports", api.logReportCSPViolations)
 
+		r.Route("/meta", func(r chi.Router) {
+			r.Get("/", api.apiMeta)
+			r.With(apiKeyMiddleware).Get("/deprecations", api.deprecationReport)
+		})
+		r.Get("/openapi.json", api.openAPIDocument)
+		r.Route("/maintenance", func(r chi.Router) {
+			// Clients check maintenance mode before they authenticate.
+			r.Get("/", api.maintenance)
+			r.With(extractAPIKey).Put("/", api.putMaintenance)
+		})
 		r.Route("/buildinfo", func(r chi.Router) {
 			r.Get("/", func(rw http.ResponseWriter, r *http.Request) {
 				httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.BuildInfoResponse{
@@ -273,6 +426,58 @@ func New(options *Options) *API {
 			r.Get("/count", api.auditLogCount)
 			r.Post("/testgenerate", api.generateFakeAuditLog)
 		})
+		r.Route("/batch", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Post("/", api.batch)
+		})
+		r.Route("/operations", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/{operation}", api.operation)
+		})
+		r.Route("/rpc", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.rpc)
+		})
+		r.Route("/search", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.search)
+		})
+		r.Route("/events", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.resourceEvents)
+		})
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.webhooks)
+			r.Post("/", api.postWebhook)
+			r.Route("/{webhook}", func(r chi.Router) {
+				r.Delete("/", api.deleteWebhook)
+				r.Get("/deliveries", api.webhookDeliveries)
+				r.Post("/deliveries/{delivery}/redeliver", api.postWebhookRedelivery)
+			})
+		})
+		r.Route("/oauth2-provider/apps", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.oauth2ProviderApps)
+			r.Post("/", api.postOAuth2ProviderApp)
+			r.Route("/{app}", func(r chi.Router) {
+				r.Get("/", api.oauth2ProviderApp)
+				r.Put("/", api.putOAuth2ProviderApp)
+				r.Delete("/", api.deleteOAuth2ProviderApp)
+				r.Post("/secret", api.postOAuth2ProviderAppSecret)
+			})
+		})
+		r.Route("/oauth2", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(apiKeyMiddleware)
+				r.Get("/authorize", api.oauth2Authorization)
+				r.Post("/authorize", api.postOAuth2Authorize)
+				r.Get("/userinfo", api.oauth2UserInfo)
+			})
+			// Apps authenticate to these with their client secret.
+			r.Post("/tokens", api.postOAuth2Token)
+			r.Post("/introspect", api.postOAuth2Introspect)
+		})
 		r.Route("/files", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -281,7 +486,8 @@ func New(options *Options) *API {
 				httpmw.RateLimitPerMinute(12),
 			)
 			r.Get("/{hash}", api.fileByHash)
-			r.Post("/", api.postFile)
+			// Template versions are uploaded as files.
+			r.With(httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: 100 << 20})).Post("/", api.postFile)
 		})
 
 		r.Route("/provisionerdaemons", func(r chi.Router) {
@@ -295,30 +501,93 @@ func New(options *Options) *API {
 				apiKeyMiddleware,
 			)
 			r.Post("/", api.postOrganizations)
+			// The status of a deletion is still available after the
+			// organization is gone.
+			r.Get("/{organization}/deletion-status", api.organizationDeletionStatus)
 			r.Route("/{organization}", func(r chi.Router) {
 				r.Use(
-					httpmw.ExtractOrganizationParam(options.Database),
+					httpmw.ExtractOrganizationParam(api.OrganizationCache),
 				)
-				r.Get("/", api.organization)
-				r.Post("/templateversions", api.postTemplateVersionsByOrganization)
-				r.Route("/templates", func(r chi.Router) {
-					r.Post("/", api.postTemplateByOrganization)
-					r.Get("/", api.templatesByOrganization)
-					r.Get("/{templatename}", api.templateByOrganizationAndName)
+				// The allowlist is exempt, so admins can fix a list that
+				// locks them out from another network.
+				r.Route("/ip-allowlist", func(r chi.Router) {
+					r.Get("/", api.organizationIPAllowlist)
+					r.Put("/", api.putOrganizationIPAllowlist)
 				})
-				r.Route("/members", func(r chi.Router) {
-					r.Get("/roles", api.assignableOrgRoles)
-					r.Route("/{user}", func(r chi.Router) {
-						r.Use(
-							httpmw.ExtractUserParam(options.Database),
-							httpmw.ExtractOrganizationMemberParam(options.Database),
-						)
-						r.Put("/roles", api.putMemberRoles)
-						r.Post("/workspaces", api.postWorkspacesByOrganization)
+				r.Group(func(r chi.Router) {
+					r.Use(api.EnforceOrganizationIPAllowlist(organizationIDFromParam))
+					r.Get("/", api.organization)
+					r.Patch("/", api.patchOrganization)
+					r.Delete("/", api.deleteOrganization)
+					r.Post("/templateversions", api.postTemplateVersionsByOrganization)
+					r.With(httpmw.Limit(httpmw.RouteLimit{Timeout: time.Minute})).Get("/insights", api.organizationInsights)
+					r.Route("/costs", func(r chi.Router) {
+						r.Use(httpmw.Limit(httpmw.RouteLimit{Timeout: time.Minute}))
+						r.Get("/", api.workspaceCostReport)
+						r.Get("/export", api.exportWorkspaceCostReport)
+					})
+					r.Route("/oidc", func(r chi.Router) {
+						r.Get("/", api.organizationOIDCConfig)
+						r.Put("/", api.putOrganizationOIDCConfig)
+						r.Delete("/", api.deleteOrganizationOIDCConfig)
+					})
+					r.Route("/templatedefaults", func(r chi.Router) {
+						r.Get("/", api.organizationTemplateDefaults)
+						r.Put("/", api.putOrganizationTemplateDefaults)
+					})
+					r.Route("/workspace-name-policy", func(r chi.Router) {
+						r.Get("/", api.organizationWorkspaceNamePolicy)
+						r.Put("/", api.putOrganizationWorkspaceNamePolicy)
+					})
+					r.Route("/webhooks", func(r chi.Router) {
+						r.Get("/", api.organizationWebhooks)
+						r.Post("/", api.postOrganizationWebhook)
+						r.Route("/{webhook}", func(r chi.Router) {
+							r.Delete("/", api.deleteOrganizationWebhook)
+							r.Get("/deliveries", api.organizationWebhookDeliveries)
+						})
+					})
+					r.Route("/invites", func(r chi.Router) {
+						r.Get("/", api.organizationInvites)
+						r.Post("/", api.postOrganizationInvite)
+						r.Delete("/{invite}", api.deleteOrganizationInvite)
+					})
+					r.Route("/templates", func(r chi.Router) {
+						r.Post("/", api.postTemplateByOrganization)
+						r.Get("/", api.templatesByOrganization)
+						r.Get("/{templatename}", api.templateByOrganizationAndName)
+					})
+					r.Route("/members", func(r chi.Router) {
+						r.Get("/", api.organizationMembers)
+						r.Get("/roles", api.assignableOrgRoles)
+						r.Patch("/roles", api.patchMemberRoles)
+						r.Route("/{user}", func(r chi.Router) {
+							r.Use(
+								httpmw.ExtractUserParam(options.Database),
+								httpmw.ExtractOrganizationMemberParam(options.Database),
+							)
+							r.Put("/roles", api.putMemberRoles)
+							r.With(workspaceBuildRateLimiter).Post("/workspaces", api.postWorkspacesByOrganization)
+						})
 					})
 				})
 			})
 		})
+		r.Route("/invites", func(r chi.Router) {
+			r.Use(
+				// Users without an account redeem invites to sign up.
+				httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
+					DB:              options.Database,
+					OAuth2Configs:   oauthConfigs,
+					RedirectToLogin: false,
+					Optional:        true,
+				}),
+				// Redeeming invites can create users without authenticating,
+				// so attempts are limited.
+				httpmw.RateLimitPerMinute(12),
+			)
+			r.Post("/redeem", api.postRedeemOrganizationInvite)
+		})
 		r.Route("/parameters/{scope}/{id}", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Post("/", api.postParameter)
@@ -331,11 +600,42 @@ func New(options *Options) *API {
 			r.Use(
 				apiKeyMiddleware,
 				httpmw.ExtractTemplateParam(options.Database),
+				api.EnforceOrganizationIPAllowlist(templateOrganizationID),
 			)
 			r.Get("/daus", api.templateDAUs)
 			r.Get("/", api.template)
 			r.Delete("/", api.deleteTemplate)
 			r.Patch("/", api.patchTemplateMeta)
+			r.Route("/maintenance", func(r chi.Router) {
+				r.Get("/", api.templateMaintenanceWindow)
+				r.Put("/", api.putTemplateMaintenanceWindow)
+			})
+			r.Route("/costs", func(r chi.Router) {
+				r.Get("/", api.templateResourceCosts)
+				r.Put("/", api.putTemplateResourceCosts)
+			})
+			r.Route("/extension-policy", func(r chi.Router) {
+				r.Get("/", api.templateAutostopExtensionPolicy)
+				r.Put("/", api.putTemplateAutostopExtensionPolicy)
+				r.Delete("/", api.deleteTemplateAutostopExtensionPolicy)
+			})
+			r.Route("/archive-policy", func(r chi.Router) {
+				r.Get("/", api.templateArchivePolicy)
+				r.Put("/", api.putTemplateArchivePolicy)
+			})
+			r.Route("/auto-rebuild-policy", func(r chi.Router) {
+				r.Get("/", api.templateAutoRebuildPolicy)
+				r.Put("/", api.putTemplateAutoRebuildPolicy)
+			})
+			r.Route("/workspace-name-policy", func(r chi.Router) {
+				r.Get("/", api.templateWorkspaceNamePolicy)
+				r.Put("/", api.putTemplateWorkspaceNamePolicy)
+			})
+			r.Route("/deprecation", func(r chi.Router) {
+				r.Get("/", api.templateDeprecation)
+				r.Put("/", api.putTemplateDeprecation)
+				r.Delete("/", api.deleteTemplateDeprecation)
+			})
 			r.Route("/versions", func(r chi.Router) {
 				r.Get("/", api.templateVersionsByTemplate)
 				r.Patch("/", api.patchActiveTemplateVersion)
@@ -346,6 +646,7 @@ func New(options *Options) *API {
 			r.Use(
 				apiKeyMiddleware,
 				httpmw.ExtractTemplateVersionParam(options.Database),
+				api.EnforceOrganizationIPAllowlist(templateVersionOrganizationID),
 			)
 
 			r.Get("/", api.templateVersion)
@@ -354,6 +655,7 @@ func New(options *Options) *API {
 			r.Get("/parameters", api.templateVersionParameters)
 			r.Get("/resources", api.templateVersionResources)
 			r.Get("/logs", api.templateVersionLogs)
+			r.Get("/diff/{othertemplateversion}", api.templateVersionDiff)
 			r.Route("/dry-run", func(r chi.Router) {
 				r.Post("/", api.postTemplateVersionDryRun)
 				r.Get("/{jobID}", api.templateVersionDryRun)
@@ -377,6 +679,11 @@ func New(options *Options) *API {
 				r.Use(httpmw.ExtractOAuth2(options.OIDCConfig))
 				r.Get("/", api.userOIDC)
 			})
+			r.Get("/oidc/route", api.oidcLoginRoute)
+			r.Route("/oidc/{organization}/callback", func(r chi.Router) {
+				r.Use(httpmw.ExtractOrganizationParam(api.OrganizationCache))
+				r.Get("/", api.userOrganizationOIDC)
+			})
 			r.Group(func(r chi.Router) {
 				r.Use(
 					apiKeyMiddleware,
@@ -403,6 +710,10 @@ func New(options *Options) *API {
 					// These roles apply to the site wide permissions.
 					r.Put("/roles", api.putUserRoles)
 					r.Get("/roles", api.userRoles)
+					r.Route("/role-requests", func(r chi.Router) {
+						r.Post("/", api.postRoleRequest)
+						r.Get("/", api.userRoleRequests)
+					})
 
 					r.Route("/keys", func(r chi.Router) {
 						r.Post("/", api.postAPIKey)
@@ -426,19 +737,34 @@ func New(options *Options) *API {
 					})
 					r.Get("/gitsshkey", api.gitSSHKey)
 					r.Put("/gitsshkey", api.regenerateGitSSHKey)
+					r.Get("/favorites", api.favorites)
+					r.Put("/favorites", api.putFavorites)
 				})
 			})
 		})
+		r.Route("/role-requests", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.pendingRoleRequests)
+			r.Route("/{rolerequest}", func(r chi.Router) {
+				r.Use(httpmw.ExtractRoleRequestParam(options.Database))
+				r.Post("/approve", api.approveRoleRequest)
+				r.Post("/deny", api.denyRoleRequest)
+			})
+		})
 		r.Route("/workspaceagents", func(r chi.Router) {
 			r.Post("/azure-instance-identity", api.postWorkspaceAuthAzureInstanceIdentity)
 			r.Post("/aws-instance-identity", api.postWorkspaceAuthAWSInstanceIdentity)
 			r.Post("/google-instance-identity", api.postWorkspaceAuthGoogleInstanceIdentity)
 			r.Route("/me", func(r chi.Router) {
-				r.Use(httpmw.ExtractWorkspaceAgent(options.Database))
+				r.Use(
+					httpmw.ExtractWorkspaceAgent(options.Database),
+					api.EnforceMaintenance,
+				)
 				r.Get("/apps", api.workspaceAgentApps)
 				r.Get("/metadata", api.workspaceAgentMetadata)
 				r.Post("/version", api.postWorkspaceAgentVersion)
 				r.Post("/app-health", api.postWorkspaceAppHealth)
+				r.Post("/startup-script", api.postWorkspaceAgentStartupScript)
 				r.Get("/gitsshkey", api.agentGitSSHKey)
 				r.Get("/coordinate", api.workspaceAgentCoordinate)
 				r.Get("/report-stats", api.workspaceAgentReportStats)
@@ -448,19 +774,23 @@ func New(options *Options) *API {
 					apiKeyMiddleware,
 					httpmw.ExtractWorkspaceAgentParam(options.Database),
 					httpmw.ExtractWorkspaceParam(options.Database),
+					api.EnforceOrganizationIPAllowlist(workspaceOrganizationID),
 				)
 				r.Get("/", api.workspaceAgent)
 				r.Get("/pty", api.workspaceAgentPTY)
 				r.Get("/listening-ports", api.workspaceAgentListeningPorts)
 				r.Get("/connection", api.workspaceAgentConnection)
 				r.Get("/coordinate", api.workspaceAgentClientCoordinate)
-				// TODO: This can be removed in October. It allows for a friendly
-				// error message when transitioning from WebRTC to Tailscale. See:
-				// https://github.com/coder/coder/issues/4126
-				r.Get("/dial", func(w http.ResponseWriter, r *http.Request) {
-					httpapi.Write(r.Context(), w, http.StatusGone, codersdk.Response{
-						Message: "Your Coder CLI is out of date, and requires v0.8.15+ to connect!",
-					})
+			})
+			// TODO: This can be removed in October. It allows for a friendly
+			// error message when transitioning from WebRTC to Tailscale. See:
+			// https://github.com/coder/coder/issues/4126
+			r.With(api.deprecate(http.MethodGet, "/api/v2/workspaceagents/{workspaceagent}/dial", httpmw.Deprecation{
+				Sunset:    time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC),
+				Successor: "/api/v2/workspaceagents/{workspaceagent}/coordinate",
+			})).Get("/{workspaceagent}/dial", func(w http.ResponseWriter, r *http.Request) {
+				httpapi.Write(r.Context(), w, http.StatusGone, codersdk.Response{
+					Message: "Your Coder CLI is out of date, and requires v0.8.15+ to connect!",
 				})
 			})
 		})
@@ -469,15 +799,20 @@ func New(options *Options) *API {
 				apiKeyMiddleware,
 			)
 			r.Get("/", api.workspaces)
+			r.Route("/batch", func(r chi.Router) {
+				r.With(workspaceBuildRateLimiter).Post("/", api.postWorkspaceBatch)
+				r.Get("/{operation}", api.workspaceBatch)
+			})
 			r.Route("/{workspace}", func(r chi.Router) {
 				r.Use(
 					httpmw.ExtractWorkspaceParam(options.Database),
+					api.EnforceOrganizationIPAllowlist(workspaceOrganizationID),
 				)
 				r.Get("/", api.workspace)
 				r.Patch("/", api.patchWorkspace)
 				r.Route("/builds", func(r chi.Router) {
 					r.Get("/", api.workspaceBuilds)
-					r.Post("/", api.postWorkspaceBuilds)
+					r.With(workspaceBuildRateLimiter).Post("/", api.postWorkspaceBuilds)
 				})
 				r.Route("/autostart", func(r chi.Router) {
 					r.Put("/", api.putWorkspaceAutostart)
@@ -487,6 +822,23 @@ func New(options *Options) *API {
 				})
 				r.Get("/watch", api.watchWorkspace)
 				r.Put("/extend", api.putExtendWorkspace)
+				r.Post("/extend", api.postExtendWorkspace)
+				r.Post("/transfer", api.postWorkspaceTransfer)
+				r.Post("/clone", api.postWorkspaceClone)
+				r.With(workspaceBuildRateLimiter).Post("/migrate", api.postWorkspaceMigrate)
+				r.Put("/owner", api.putWorkspaceOwner)
+				r.Put("/labels", api.putWorkspaceLabels)
+				r.Get("/timeline", api.workspaceTimeline)
+				r.Get("/usage", api.workspaceUsage)
+				r.Route("/archive", func(r chi.Router) {
+					r.Get("/", api.workspaceArchive)
+					r.With(workspaceBuildRateLimiter).Post("/", api.postWorkspaceArchive)
+					r.With(workspaceBuildRateLimiter).Post("/restore", api.postWorkspaceArchiveRestore)
+				})
+				r.Route("/acl", func(r chi.Router) {
+					r.Get("/", api.workspaceACL)
+					r.Patch("/", api.patchWorkspaceACL)
+				})
 			})
 		})
 		r.Route("/workspacebuilds/{workspacebuild}", func(r chi.Router) {
@@ -494,6 +846,7 @@ func New(options *Options) *API {
 				apiKeyMiddleware,
 				httpmw.ExtractWorkspaceBuildParam(options.Database),
 				httpmw.ExtractWorkspaceParam(options.Database),
+				api.EnforceOrganizationIPAllowlist(workspaceOrganizationID),
 			)
 			r.Get("/", api.workspaceBuild)
 			r.Patch("/cancel", api.patchCancelWorkspaceBuild)
@@ -504,6 +857,9 @@ func New(options *Options) *API {
 		r.Route("/authcheck", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Post("/", api.checkAuthorization)
+			r.Get("/permissions", api.authorizationPermissions)
+			r.Get("/policy", api.authorizationPolicy)
+			r.Post("/simulate", api.simulateAuthorization)
 		})
 		r.Route("/applications", func(r chi.Router) {
 			r.Route("/host", func(r chi.Router) {
@@ -523,6 +879,11 @@ func New(options *Options) *API {
 	})
 
 	r.NotFound(compressHandler(http.HandlerFunc(api.siteHandler.ServeHTTP)).ServeHTTP)
+	api.resumeOrganizationDeletions()
+	api.resumeWorkspaceBatches()
+	api.startWorkspaceCostAccrual()
+	api.startWorkspaceArchiver()
+	api.startWorkspaceAutoRebuilder()
 	return api
 }
 
@@ -531,19 +892,84 @@ type API struct {
 	Auditor                           atomic.Pointer[audit.Auditor]
 	WorkspaceClientCoordinateOverride atomic.Pointer[func(rw http.ResponseWriter) bool]
 	WorkspaceQuotaEnforcer            atomic.Pointer[workspacequota.Enforcer]
+	GroupSyncer                       atomic.Pointer[groupsync.Syncer]
 	HTTPAuth                          *HTTPAuthorizer
+	// Capabilities are advertised to clients by the API meta endpoint.
+	// Wrapping APIs may append to this before serving requests.
+	Capabilities []codersdk.Capability
+	// OpenAPISpecs describe route payloads for the generated OpenAPI
+	// document. Wrapping APIs should add specs for routes they mount.
+	OpenAPISpecs map[string]openapi.Spec
+	// OrganizationCache resolves the organization of organization routes.
+	// It must be invalidated whenever an organization changes.
+	OrganizationCache *orgcache.Store
+	// WebhookEngine delivers events to webhooks. Wrapping APIs register the
+	// kinds of webhooks they add.
+	WebhookEngine *webhookdelivery.Engine
+
+	// APIKeyRateLimiter limits requests by API key. Wrapping APIs use it
+	// for their routes, so they share the buckets of this API.
+	APIKeyRateLimiter func(http.Handler) http.Handler
 
 	// APIHandler serves "/api/v2"
 	APIHandler chi.Router
 	// RootHandler serves "/"
 	RootHandler chi.Router
 
-	derpServer          *derp.Server
-	metricsCache        *metricscache.Cache
-	siteHandler         http.Handler
-	websocketWaitMutex  sync.Mutex
-	websocketWaitGroup  sync.WaitGroup
-	workspaceAgentCache *wsconncache.Cache
+	deprecations          []codersdk.APIDeprecation
+	deprecationUsage      *httpmw.DeprecationUsage
+	maintenanceCache      maintenanceCache
+	derpServer            *derp.Server
+	metricsCache          *metricscache.Cache
+	openAPIOnce           sync.Once
+	openAPIDoc            []byte
+	openAPIErr            error
+	organizationOIDCMutex sync.Mutex
+	organizationOIDC      map[uuid.UUID]organizationOIDCEntry
+	siteHandler           http.Handler
+	websocketWaitMutex    sync.Mutex
+	websocketWaitGroup    sync.WaitGroup
+	workspaceAgentCache   *wsconncache.Cache
+
+	// maintenanceCtx is canceled on Close to stop refreshing the
+	// maintenance mode. Refreshes are shared between requests, so they
+	// don't use the context of any one of them.
+	maintenanceCtx    context.Context
+	maintenanceCancel context.CancelFunc
+
+	// organizationDeletionsCtx is canceled on Close to stop deleting
+	// organizations. Deletions are resumed by a replica once their heartbeat
+	// is stale.
+	organizationDeletionsCtx    context.Context
+	organizationDeletionsCancel context.CancelFunc
+	organizationDeletionsWG     sync.WaitGroup
+	// organizationDeletionsWorkerID identifies the deletions this replica
+	// claimed.
+	organizationDeletionsWorkerID uuid.UUID
+
+	// workspaceBatchesCtx is canceled on Close to stop bulk actions on
+	// workspaces. They resume when the API is started again.
+	workspaceBatchesCtx    context.Context
+	workspaceBatchesCancel context.CancelFunc
+	workspaceBatchesWG     sync.WaitGroup
+
+	// workspaceCostsCtx is canceled on Close to stop accruing the costs of
+	// running workspaces.
+	workspaceCostsCtx    context.Context
+	workspaceCostsCancel context.CancelFunc
+	workspaceCostsWG     sync.WaitGroup
+
+	// workspaceArchivesCtx is canceled on Close to stop archiving, restoring,
+	// and purging workspaces.
+	workspaceArchivesCtx    context.Context
+	workspaceArchivesCancel context.CancelFunc
+	workspaceArchivesWG     sync.WaitGroup
+
+	// workspaceAutoRebuildsCtx is canceled on Close to stop rebuilding
+	// workspaces whose agents fail.
+	workspaceAutoRebuildsCtx    context.Context
+	workspaceAutoRebuildsCancel context.CancelFunc
+	workspaceAutoRebuildsWG     sync.WaitGroup
 }
 
 // Close waits for all WebSocket connections to drain before returning.
@@ -553,6 +979,20 @@ func (api *API) Close() error {
 	api.websocketWaitMutex.Unlock()
 
 	api.metricsCache.Close()
+	api.OrganizationCache.Close()
+
+	api.WebhookEngine.Close()
+	api.maintenanceCancel()
+	api.organizationDeletionsCancel()
+	api.organizationDeletionsWG.Wait()
+	api.workspaceBatchesCancel()
+	api.workspaceBatchesWG.Wait()
+	api.workspaceCostsCancel()
+	api.workspaceCostsWG.Wait()
+	api.workspaceArchivesCancel()
+	api.workspaceArchivesWG.Wait()
+	api.workspaceAutoRebuildsCancel()
+	api.workspaceAutoRebuildsWG.Wait()
 
 	return api.workspaceAgentCache.Close()
 }
@@ -2,11 +2,13 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/netip"
 	"strconv"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
@@ -17,6 +19,8 @@ import (
 
 	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/openapi"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/tailnet"
 	"github.com/coder/coder/testutil"
 )
@@ -38,6 +42,64 @@ func TestBuildInfo(t *testing.T) {
 	require.Equal(t, buildinfo.Version(), buildInfo.Version, "version")
 }
 
+func TestAPIMeta(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	meta, err := client.APIMeta(ctx)
+	require.NoError(t, err)
+	require.Equal(t, codersdk.APIVersion, meta.APIVersion)
+	require.Equal(t, buildinfo.Version(), meta.Version)
+	require.True(t, meta.HasCapability(codersdk.CapabilityErrorCodes))
+	require.False(t, meta.HasCapability(codersdk.CapabilityGroups))
+	require.NotEmpty(t, meta.Deprecations)
+
+	res, err := client.Request(ctx, http.MethodGet, "/api/v2/workspaceagents/"+uuid.NewString()+"/dial", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.True(t, codersdk.IsDeprecated(res))
+	require.NotEmpty(t, res.Header.Get("Sunset"))
+
+	// Only admins can see how deprecated routes are used.
+	_, err = client.DeprecationReport(ctx)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+	_ = coderdtest.CreateFirstUser(t, client)
+	report, err := client.DeprecationReport(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Routes, 1)
+	require.Equal(t, "/api/v2/workspaceagents/{workspaceagent}/dial", report.Routes[0].Path)
+	require.EqualValues(t, 1, report.Routes[0].Requests)
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	res, err := client.Request(ctx, http.MethodGet, "/api/v2/openapi.json", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, codersdk.APIVersion, res.Header.Get("X-Coder-API-Version"))
+
+	var doc openapi.Document
+	err = json.NewDecoder(res.Body).Decode(&doc)
+	require.NoError(t, err)
+	require.Equal(t, buildinfo.Version(), doc.Info.Version)
+	require.Contains(t, doc.Paths, "/users/{user}")
+	require.Contains(t, doc.Paths["/organizations/{organization}/members"], "get")
+	require.True(t, doc.Paths["/workspaceagents/{workspaceagent}/dial"]["get"].Deprecated)
+	require.Contains(t, doc.Components.Schemas, "User")
+}
+
 func TestDERP(t *testing.T) {
 	t.Parallel()
 	client := coderdtest.New(t, nil)
@@ -33,25 +33,54 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 		"POST:/api/v2/users/logout": "Logging out deletes the API Key for other routes",
 		"GET:/derp":                 "This requires a WebSocket upgrade!",
 		"GET:/derp/latency-check":   "This always returns a 200!",
+		"GET:/api/v2/events":        "This streams events until the client disconnects!",
 	}
 
 	assertRoute := map[string]RouteCheck{
 		// These endpoints do not require auth
-		"GET:/api/v2":                   {NoAuthorize: true},
-		"GET:/api/v2/buildinfo":         {NoAuthorize: true},
-		"GET:/api/v2/users/first":       {NoAuthorize: true},
-		"POST:/api/v2/users/first":      {NoAuthorize: true},
-		"POST:/api/v2/users/login":      {NoAuthorize: true},
-		"GET:/api/v2/users/authmethods": {NoAuthorize: true},
-		"POST:/api/v2/csp/reports":      {NoAuthorize: true},
-		"POST:/api/v2/authcheck":        {NoAuthorize: true},
-		"GET:/api/v2/applications/host": {NoAuthorize: true},
+		"GET:/api/v2":                       {NoAuthorize: true},
+		"GET:/api/v2/buildinfo":             {NoAuthorize: true},
+		"GET:/api/v2/meta":                  {NoAuthorize: true},
+		"GET:/api/v2/maintenance":           {NoAuthorize: true},
+		"GET:/api/v2/openapi.json":          {NoAuthorize: true},
+		"GET:/api/v2/users/first":           {NoAuthorize: true},
+		"POST:/api/v2/users/first":          {NoAuthorize: true},
+		"POST:/api/v2/users/login":          {NoAuthorize: true},
+		"GET:/api/v2/users/authmethods":     {NoAuthorize: true},
+		"POST:/api/v2/csp/reports":          {NoAuthorize: true},
+		"POST:/api/v2/authcheck":            {NoAuthorize: true},
+		"GET:/api/v2/authcheck/permissions": {NoAuthorize: true},
+		"GET:/api/v2/applications/host":     {NoAuthorize: true},
+		// The invite token authorizes joining the organization.
+		"POST:/api/v2/invites/redeem": {NoAuthorize: true},
+		// Sub-requests are authorized by their own routes.
+		"POST:/api/v2/batch": {NoAuthorize: true},
+		// Requests without a search query are rejected before results are
+		// authorized.
+		"GET:/api/v2/search": {StatusCode: http.StatusBadRequest, NoAuthorize: true},
+		// Calls over the connection are authorized like REST requests.
+		"GET:/api/v2/rpc": {StatusCode: http.StatusBadRequest, NoAuthorize: true},
+		// The route param isn't an operation ID, so it's rejected before
+		// the operation is authorized.
+		"GET:/api/v2/operations/{operation}": {StatusCode: http.StatusBadRequest, NoAuthorize: true},
+
+		// Any user can authorize an app, and apps authenticate with their
+		// client secret instead of a session.
+		"GET:/api/v2/oauth2/authorize":   {StatusCode: http.StatusBadRequest, NoAuthorize: true},
+		"POST:/api/v2/oauth2/authorize":  {StatusCode: http.StatusBadRequest, NoAuthorize: true},
+		"POST:/api/v2/oauth2/tokens":     {StatusCode: http.StatusUnauthorized, NoAuthorize: true},
+		"POST:/api/v2/oauth2/introspect": {StatusCode: http.StatusUnauthorized, NoAuthorize: true},
+		"GET:/api/v2/oauth2/userinfo":    {NoAuthorize: true},
+
 		// This is a dummy endpoint for compatibility with older CLI versions.
 		"GET:/api/v2/workspaceagents/{workspaceagent}/dial": {NoAuthorize: true},
 
 		// Has it's own auth
 		"GET:/api/v2/users/oauth2/github/callback": {NoAuthorize: true},
 		"GET:/api/v2/users/oidc/callback":          {NoAuthorize: true},
+		// Users aren't signed in when logging in with OIDC.
+		"GET:/api/v2/users/oidc/route":                   {NoAuthorize: true},
+		"GET:/api/v2/users/oidc/{organization}/callback": {NoAuthorize: true},
 
 		// All workspaceagents endpoints do not use rbac
 		"POST:/api/v2/workspaceagents/aws-instance-identity":    {NoAuthorize: true},
@@ -63,11 +92,152 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 		"GET:/api/v2/workspaceagents/me/coordinate":             {NoAuthorize: true},
 		"POST:/api/v2/workspaceagents/me/version":               {NoAuthorize: true},
 		"POST:/api/v2/workspaceagents/me/app-health":            {NoAuthorize: true},
+		"POST:/api/v2/workspaceagents/me/startup-script":        {NoAuthorize: true},
 		"GET:/api/v2/workspaceagents/me/report-stats":           {NoAuthorize: true},
 
 		// These endpoints have more assertions. This is good, add more endpoints to assert if you can!
 		"GET:/api/v2/organizations/{organization}": {AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID)},
 		"GET:/api/v2/users/{user}/organizations":   {StatusCode: http.StatusOK, AssertObject: rbac.ResourceOrganization},
+		"PATCH:/api/v2/organizations/{organization}": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"DELETE:/api/v2/organizations/{organization}": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: rbac.ResourceOrganization,
+		},
+		"GET:/api/v2/organizations/{organization}/oidc": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"PUT:/api/v2/organizations/{organization}/oidc": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"DELETE:/api/v2/organizations/{organization}/oidc": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/authcheck/policy": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceAuditLog,
+		},
+		"POST:/api/v2/authcheck/simulate": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceAuditLog,
+		},
+		"GET:/api/v2/organizations/{organization}/costs": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/costs/export": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/deletion-status": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/insights": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/invites": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
+		"POST:/api/v2/organizations/{organization}/invites": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
+		"DELETE:/api/v2/organizations/{organization}/invites/{invite}": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/templatedefaults": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"PUT:/api/v2/organizations/{organization}/templatedefaults": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/workspace-name-policy": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"PUT:/api/v2/organizations/{organization}/workspace-name-policy": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/ip-allowlist": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"PUT:/api/v2/organizations/{organization}/ip-allowlist": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/webhooks": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceWebhook,
+		},
+		"POST:/api/v2/webhooks": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceWebhook,
+		},
+		"DELETE:/api/v2/webhooks/{webhook}": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: rbac.ResourceWebhook,
+		},
+		"GET:/api/v2/webhooks/{webhook}/deliveries": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceWebhook,
+		},
+		"POST:/api/v2/webhooks/{webhook}/deliveries/{delivery}/redeliver": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceWebhook,
+		},
+		"GET:/api/v2/oauth2-provider/apps": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOAuth2ProviderApp,
+		},
+		"POST:/api/v2/oauth2-provider/apps": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceOAuth2ProviderApp,
+		},
+		"GET:/api/v2/oauth2-provider/apps/{app}": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOAuth2ProviderApp,
+		},
+		"PUT:/api/v2/oauth2-provider/apps/{app}": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOAuth2ProviderApp,
+		},
+		"DELETE:/api/v2/oauth2-provider/apps/{app}": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: rbac.ResourceOAuth2ProviderApp,
+		},
+		"POST:/api/v2/oauth2-provider/apps/{app}/secret": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOAuth2ProviderApp,
+		},
+		"GET:/api/v2/organizations/{organization}/webhooks": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"POST:/api/v2/organizations/{organization}/webhooks": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"DELETE:/api/v2/organizations/{organization}/webhooks/{webhook}": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
+		"GET:/api/v2/organizations/{organization}/webhooks/{webhook}/deliveries": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceOrganization.InOrg(a.Admin.OrganizationID),
+		},
 		"GET:/api/v2/users/{user}/workspace/{workspacename}": {
 			AssertObject: rbac.ResourceWorkspace,
 			AssertAction: rbac.ActionRead,
@@ -100,6 +270,58 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: workspaceRBACObj,
 		},
+		"POST:/api/v2/workspaces/{workspace}/extend": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/transfer": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"PUT:/api/v2/workspaces/{workspace}/owner": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"PUT:/api/v2/workspaces/{workspace}/labels": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/clone": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/migrate": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaces/{workspace}/timeline": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaces/{workspace}/usage": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaces/{workspace}/archive": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/archive": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/archive/restore": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"GET:/api/v2/workspaces/{workspace}/acl": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"PATCH:/api/v2/workspaces/{workspace}/acl": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
 		"PATCH:/api/v2/workspacebuilds/{workspacebuild}/cancel": {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: workspaceRBACObj,
@@ -146,6 +368,70 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceFile.WithOwner(a.Admin.UserID.String()),
 		},
+		"GET:/api/v2/templates/{template}/costs": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/costs": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/extension-policy": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/extension-policy": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"DELETE:/api/v2/templates/{template}/extension-policy": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/archive-policy": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/archive-policy": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/auto-rebuild-policy": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/auto-rebuild-policy": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/workspace-name-policy": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/workspace-name-policy": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/deprecation": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/deprecation": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"DELETE:/api/v2/templates/{template}/deprecation": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"GET:/api/v2/templates/{template}/maintenance": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
+		"PUT:/api/v2/templates/{template}/maintenance": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
 		"GET:/api/v2/templates/{template}/versions": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
@@ -162,6 +448,10 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
 		},
+		"GET:/api/v2/templateversions/{templateversion}/diff/{othertemplateversion}": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
+		},
 		"PATCH:/api/v2/templateversions/{templateversion}/cancel": {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: rbac.ResourceTemplate.InOrg(a.Template.OrganizationID),
@@ -233,17 +523,58 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: workspaceRBACObj,
 		},
-		"GET:/api/v2/users":                      {StatusCode: http.StatusOK, AssertObject: rbac.ResourceUser},
+		"GET:/api/v2/users": {StatusCode: http.StatusOK, AssertObject: rbac.ResourceUser},
+		"GET:/api/v2/organizations/{organization}/members": {
+			StatusCode:   http.StatusOK,
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceOrganizationMember.InOrg(a.Admin.OrganizationID),
+		},
 		"GET:/api/v2/applications/auth-redirect": {AssertAction: rbac.ActionCreate, AssertObject: rbac.ResourceAPIKey},
 
+		"POST:/api/v2/users/{user}/role-requests": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceUserData,
+		},
+		"GET:/api/v2/users/{user}/role-requests": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceUserData,
+		},
+		"GET:/api/v2/users/{user}/favorites": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceUserData,
+		},
+		"PUT:/api/v2/users/{user}/favorites": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: rbac.ResourceUserData,
+		},
+		// The queue is filtered to the requests the user can approve.
+		"GET:/api/v2/role-requests": {
+			StatusCode:   http.StatusOK,
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceRoleAssignment,
+		},
+		"POST:/api/v2/role-requests/{rolerequest}/approve": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceRoleAssignment,
+		},
+		"POST:/api/v2/role-requests/{rolerequest}/deny": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceRoleAssignment,
+		},
+
 		// These endpoints need payloads to get to the auth part. Payloads will be required
 		"PUT:/api/v2/users/{user}/roles":                                {StatusCode: http.StatusBadRequest, NoAuthorize: true},
 		"PUT:/api/v2/organizations/{organization}/members/{user}/roles": {NoAuthorize: true},
+		"PATCH:/api/v2/organizations/{organization}/members/roles":      {StatusCode: http.StatusBadRequest, NoAuthorize: true},
 		"POST:/api/v2/workspaces/{workspace}/builds":                    {StatusCode: http.StatusBadRequest, NoAuthorize: true},
 		"POST:/api/v2/organizations/{organization}/templateversions":    {StatusCode: http.StatusBadRequest, NoAuthorize: true},
 
 		// Endpoints that use the SQLQuery filter.
-		"GET:/api/v2/workspaces/": {StatusCode: http.StatusOK, NoAuthorize: true},
+		"GET:/api/v2/workspaces/":        {StatusCode: http.StatusOK, NoAuthorize: true},
+		"POST:/api/v2/workspaces/batch/": {StatusCode: http.StatusBadRequest, NoAuthorize: true},
+		// The route param isn't an operation ID, so it's rejected before
+		// the operation is authorized.
+		"GET:/api/v2/workspaces/batch/{operation}": {StatusCode: http.StatusBadRequest, NoAuthorize: true},
 	}
 
 	// Routes like proxy routes support all HTTP methods. A helper func to expand
@@ -359,23 +690,29 @@ func NewAuthTester(ctx context.Context, t *testing.T, client *codersdk.Client, a
 		DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
 	})
 	require.NoError(t, err, "create template param")
+	roleRequest, err := client.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+		Role: rbac.RoleTemplateAdmin(),
+	})
+	require.NoError(t, err, "create role request")
 	urlParameters := map[string]string{
-		"{organization}":        admin.OrganizationID.String(),
-		"{user}":                admin.UserID.String(),
-		"{organizationname}":    organization.Name,
-		"{workspace}":           workspace.ID.String(),
-		"{workspacebuild}":      workspace.LatestBuild.ID.String(),
-		"{workspacename}":       workspace.Name,
-		"{workspaceagent}":      workspace.LatestBuild.Resources[0].Agents[0].ID.String(),
-		"{buildnumber}":         strconv.FormatInt(int64(workspace.LatestBuild.BuildNumber), 10),
-		"{template}":            template.ID.String(),
-		"{hash}":                file.Hash,
-		"{workspaceresource}":   workspace.LatestBuild.Resources[0].ID.String(),
-		"{workspaceapp}":        workspace.LatestBuild.Resources[0].Agents[0].Apps[0].Name,
-		"{templateversion}":     version.ID.String(),
-		"{jobID}":               templateVersionDryRun.ID.String(),
-		"{templatename}":        template.Name,
-		"{workspace_and_agent}": workspace.Name + "." + workspace.LatestBuild.Resources[0].Agents[0].Name,
+		"{organization}":         admin.OrganizationID.String(),
+		"{user}":                 admin.UserID.String(),
+		"{organizationname}":     organization.Name,
+		"{workspace}":            workspace.ID.String(),
+		"{workspacebuild}":       workspace.LatestBuild.ID.String(),
+		"{workspacename}":        workspace.Name,
+		"{workspaceagent}":       workspace.LatestBuild.Resources[0].Agents[0].ID.String(),
+		"{buildnumber}":          strconv.FormatInt(int64(workspace.LatestBuild.BuildNumber), 10),
+		"{template}":             template.ID.String(),
+		"{hash}":                 file.Hash,
+		"{workspaceresource}":    workspace.LatestBuild.Resources[0].ID.String(),
+		"{workspaceapp}":         workspace.LatestBuild.Resources[0].Agents[0].Apps[0].Name,
+		"{templateversion}":      version.ID.String(),
+		"{othertemplateversion}": version.ID.String(),
+		"{jobID}":                templateVersionDryRun.ID.String(),
+		"{templatename}":         template.Name,
+		"{rolerequest}":          roleRequest.ID.String(),
+		"{workspace_and_agent}":  workspace.Name + "." + workspace.LatestBuild.Resources[0].Agents[0].Name,
 		// Only checking template scoped params here
 		"parameters/{scope}/{id}": fmt.Sprintf("parameters/%s/%s",
 			string(templateParam.Scope), templateParam.ScopeID.String()),
@@ -497,12 +834,13 @@ func (a *AuthTester) Test(ctx context.Context, assertRoute map[string]RouteCheck
 }
 
 type authCall struct {
-	SubjectID string
-	Roles     []string
-	Groups    []string
-	Scope     rbac.Scope
-	Action    rbac.Action
-	Object    rbac.Object
+	SubjectID        string
+	Roles            []string
+	Groups           []string
+	EveryoneExcluded []string
+	Scope            rbac.Scope
+	Action           rbac.Action
+	Object           rbac.Object
 }
 
 type RecordingAuthorizer struct {
@@ -514,23 +852,24 @@ var _ rbac.Authorizer = (*RecordingAuthorizer)(nil)
 
 // ByRoleNameSQL does not record the call. This matches the postgres behavior
 // of not calling Authorize()
-func (r *RecordingAuthorizer) ByRoleNameSQL(_ context.Context, _ string, _ []string, _ rbac.Scope, _ []string, _ rbac.Action, _ rbac.Object) error {
+func (r *RecordingAuthorizer) ByRoleNameSQL(_ context.Context, _ string, _ []string, _ rbac.Scope, _ []string, _ []string, _ rbac.Action, _ rbac.Object) error {
 	return r.AlwaysReturn
 }
 
-func (r *RecordingAuthorizer) ByRoleName(_ context.Context, subjectID string, roleNames []string, scope rbac.Scope, groups []string, action rbac.Action, object rbac.Object) error {
+func (r *RecordingAuthorizer) ByRoleName(_ context.Context, subjectID string, roleNames []string, scope rbac.Scope, groups []string, everyoneExcluded []string, action rbac.Action, object rbac.Object) error {
 	r.Called = &authCall{
-		SubjectID: subjectID,
-		Roles:     roleNames,
-		Groups:    groups,
-		Scope:     scope,
-		Action:    action,
-		Object:    object,
+		SubjectID:        subjectID,
+		Roles:            roleNames,
+		Groups:           groups,
+		EveryoneExcluded: everyoneExcluded,
+		Scope:            scope,
+		Action:           action,
+		Object:           object,
 	}
 	return r.AlwaysReturn
 }
 
-func (r *RecordingAuthorizer) PrepareByRoleName(_ context.Context, subjectID string, roles []string, scope rbac.Scope, groups []string, action rbac.Action, _ string) (rbac.PreparedAuthorized, error) {
+func (r *RecordingAuthorizer) PrepareByRoleName(_ context.Context, subjectID string, roles []string, scope rbac.Scope, groups []string, everyoneExcluded []string, action rbac.Action, _ string) (rbac.PreparedAuthorized, error) {
 	return &fakePreparedAuthorizer{
 		Original:           r,
 		SubjectID:          subjectID,
@@ -539,6 +878,7 @@ func (r *RecordingAuthorizer) PrepareByRoleName(_ context.Context, subjectID str
 		Action:             action,
 		HardCodedSQLString: "true",
 		Groups:             groups,
+		EveryoneExcluded:   everyoneExcluded,
 	}, nil
 }
 
@@ -553,12 +893,13 @@ type fakePreparedAuthorizer struct {
 	Scope               rbac.Scope
 	Action              rbac.Action
 	Groups              []string
+	EveryoneExcluded    []string
 	HardCodedSQLString  string
 	HardCodedRegoString string
 }
 
 func (f *fakePreparedAuthorizer) Authorize(ctx context.Context, object rbac.Object) error {
-	return f.Original.ByRoleName(ctx, f.SubjectID, f.Roles, f.Scope, f.Groups, f.Action, object)
+	return f.Original.ByRoleName(ctx, f.SubjectID, f.Roles, f.Scope, f.Groups, f.EveryoneExcluded, f.Action, object)
 }
 
 // Compile returns a compiled version of the authorizer that will work for
@@ -568,7 +909,7 @@ func (f *fakePreparedAuthorizer) Compile() (rbac.AuthorizeFilter, error) {
 }
 
 func (f *fakePreparedAuthorizer) Eval(object rbac.Object) bool {
-	return f.Original.ByRoleNameSQL(context.Background(), f.SubjectID, f.Roles, f.Scope, f.Groups, f.Action, object) == nil
+	return f.Original.ByRoleNameSQL(context.Background(), f.SubjectID, f.Roles, f.Scope, f.Groups, f.EveryoneExcluded, f.Action, object) == nil
 }
 
 func (f fakePreparedAuthorizer) RegoString() string {
@@ -49,6 +49,9 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbtestutil"
 	"github.com/coder/coder/coderd/gitsshkey"
+	"github.com/coder/coder/coderd/groupsync"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/objectstore"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/util/ptr"
@@ -75,12 +78,34 @@ type Options struct {
 	AutobuildTicker      <-chan time.Time
 	AutobuildStats       chan<- executor.Stats
 	Auditor              audit.Auditor
+	GroupSyncer          groupsync.Syncer
+	// Database and Pubsub are shared by API instances that act as replicas.
+	// A new database is used if they're nil.
+	Database database.Store
+	Pubsub   database.Pubsub
 
 	// IncludeProvisionerDaemon when true means to start an in-memory provisionerD
 	IncludeProvisionerDaemon    bool
 	MetricsCacheRefreshInterval time.Duration
 	AgentStatsRefreshInterval   time.Duration
 	DeploymentFlags             *codersdk.DeploymentFlags
+	OrganizationOIDCProvider    func(ctx context.Context, config database.OrganizationOIDCConfig) (*coderd.OIDCConfig, error)
+
+	OrganizationWebhookRetryInterval time.Duration
+	OrganizationDeletionPollInterval time.Duration
+	WorkspaceBatchPollInterval       time.Duration
+	WorkspaceCostAccrualInterval     time.Duration
+	WebhookRetryInterval             time.Duration
+
+	AutostopExtensionPolicy codersdk.AutostopExtensionPolicy
+
+	WorkspaceArchiveStore        objectstore.Store
+	WorkspaceArchivePollInterval time.Duration
+
+	WorkspaceAutoRebuildPollInterval time.Duration
+
+	APIKeyRateLimit         httpmw.RateLimitConfig
+	WorkspaceBuildRateLimit httpmw.RateLimitConfig
 }
 
 // New constructs a codersdk client connected to an in-memory API instance.
@@ -136,7 +161,10 @@ func NewOptions(t *testing.T, options *Options) (*httptest.Server, context.Cance
 		})
 	}
 
-	db, pubsub := dbtestutil.NewDB(t)
+	db, pubsub := options.Database, options.Pubsub
+	if db == nil {
+		db, pubsub = dbtestutil.NewDB(t)
+	}
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	lifecycleExecutor := executor.New(
@@ -185,6 +213,7 @@ func NewOptions(t *testing.T, options *Options) (*httptest.Server, context.Cance
 		Pubsub:                         pubsub,
 
 		Auditor:              options.Auditor,
+		GroupSyncer:          options.GroupSyncer,
 		AWSCertificates:      options.AWSCertificates,
 		AzureCertificates:    options.AzureCertificates,
 		GithubOAuth2Config:   options.GithubOAuth2Config,
@@ -217,6 +246,23 @@ func NewOptions(t *testing.T, options *Options) (*httptest.Server, context.Cance
 		MetricsCacheRefreshInterval: options.MetricsCacheRefreshInterval,
 		AgentStatsRefreshInterval:   options.AgentStatsRefreshInterval,
 		DeploymentFlags:             options.DeploymentFlags,
+		OrganizationOIDCProvider:    options.OrganizationOIDCProvider,
+
+		OrganizationWebhookRetryInterval: options.OrganizationWebhookRetryInterval,
+		OrganizationDeletionPollInterval: options.OrganizationDeletionPollInterval,
+		WorkspaceBatchPollInterval:       options.WorkspaceBatchPollInterval,
+		WorkspaceCostAccrualInterval:     options.WorkspaceCostAccrualInterval,
+		WebhookRetryInterval:             options.WebhookRetryInterval,
+
+		AutostopExtensionPolicy: options.AutostopExtensionPolicy,
+
+		WorkspaceArchiveStore:        options.WorkspaceArchiveStore,
+		WorkspaceArchivePollInterval: options.WorkspaceArchivePollInterval,
+
+		WorkspaceAutoRebuildPollInterval: options.WorkspaceAutoRebuildPollInterval,
+
+		APIKeyRateLimit:         options.APIKeyRateLimit,
+		WorkspaceBuildRateLimit: options.WorkspaceBuildRateLimit,
 	}
 }
 
@@ -1,8 +1,10 @@
 package databasefake
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"sort"
 	"strings"
 	"sync"
@@ -36,6 +38,7 @@ func New() database.Store {
 			users:                          make([]database.User, 0),
 			groups:                         make([]database.Group, 0),
 			groupMembers:                   make([]database.GroupMember, 0),
+			groupJoinRequests:              make([]database.GroupJoinRequest, 0),
 			auditLogs:                      make([]database.AuditLog, 0),
 			files:                          make([]database.File, 0),
 			gitSSHKey:                      make([]database.GitSSHKey, 0),
@@ -82,7 +85,11 @@ type data struct {
 	// Legacy tables
 	apiKeys             []database.APIKey
 	organizations       []database.Organization
+	organizationAliases []database.OrganizationAlias
 	organizationMembers []database.OrganizationMember
+	organizationInvites []database.OrganizationInvite
+	organizationOIDC    []database.OrganizationOIDCConfig
+	organizationQuotas  []database.OrganizationQuota
 	users               []database.User
 	userLinks           []database.UserLink
 
@@ -93,6 +100,23 @@ type data struct {
 	gitSSHKey                      []database.GitSSHKey
 	groups                         []database.Group
 	groupMembers                   []database.GroupMember
+	groupJoinRequests              []database.GroupJoinRequest
+	groupWebhooks                  []database.GroupWebhook
+	roleRequests                   []database.RoleRequest
+	organizationWebhooks           []database.OrganizationWebhook
+	organizationWebhookDeliveries  []database.OrganizationWebhookDelivery
+	webhooks                       []database.Webhook
+	webhookDeliveries              []database.WebhookDelivery
+	webhookQueue                   []database.WebhookQueue
+	organizationTemplateDefaults   []database.OrganizationTemplateDefault
+	organizationNamePolicies       []database.OrganizationWorkspaceNamePolicy
+	organizationIPAllowlists       []database.OrganizationIpAllowlist
+	organizationDeletions          []database.OrganizationDeletion
+	operations                     []database.Operation
+	oauth2ProviderApps             []database.OAuth2ProviderApp
+	oauth2ProviderAppCodes         []database.OAuth2ProviderAppCode
+	oauth2ProviderAppTokens        []database.OAuth2ProviderAppToken
+	everyoneGroupExclusions        []database.EveryoneGroupExclusion
 	parameterSchemas               []database.ParameterSchema
 	parameterValues                []database.ParameterValue
 	provisionerDaemons             []database.ProvisionerDaemon
@@ -103,12 +127,29 @@ type data struct {
 	provisionerJobs                []database.ProvisionerJob
 	templateVersions               []database.TemplateVersion
 	templates                      []database.Template
+	templateArchivePolicies        []database.TemplateArchivePolicy
+	templateAutoRebuildPolicies    []database.TemplateAutoRebuildPolicy
+	templateDeprecations           []database.TemplateDeprecation
+	templateNamePolicies           []database.TemplateWorkspaceNamePolicy
+	templateExtensionPolicies      []database.TemplateAutostopExtensionPolicy
+	templateMaintenanceWindows     []database.TemplateMaintenanceWindow
+	templateResourceCosts          []database.TemplateResourceCost
+	templateFavorites              []database.TemplateFavorite
+	workspaceAgentScriptResults    []database.WorkspaceAgentStartupScriptResult
+	workspaceArchives              []database.WorkspaceArchive
+	workspaceAutostopExtensions    []database.WorkspaceAutostopExtension
 	workspaceBuilds                []database.WorkspaceBuild
+	workspaceCosts                 []database.WorkspaceCost
+	workspaceBatchResults          []database.WorkspaceBatchResult
+	workspaceTimelineEvents        []database.WorkspaceTimelineEvent
+	workspaceAgentUsageSamples     []database.WorkspaceAgentUsageSample
+	workspaceFavorites             []database.WorkspaceFavorite
 	workspaceApps                  []database.WorkspaceApp
 	workspaces                     []database.Workspace
 	licenses                       []database.License
 
 	deploymentID  string
+	maintenance   string
 	lastLicenseID int32
 }
 
@@ -119,6 +160,12 @@ func (q *fakeQuerier) InTx(fn func(database.Store) error) error {
 	return fn(&fakeQuerier{mutex: inTxMutex{}, data: q.data})
 }
 
+func (*fakeQuerier) AcquireLock(_ context.Context, _ int64) error {
+	// Transactions hold the mutex of the store, so they are serialized
+	// already.
+	return nil
+}
+
 func (q *fakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.AcquireProvisionerJobParams) (database.ProvisionerJob, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -504,6 +551,23 @@ func (q *fakeQuerier) GetUsersByIDs(_ context.Context, ids []uuid.UUID) ([]datab
 	return users, nil
 }
 
+func (q *fakeQuerier) GetUsersByUsernamesOrEmails(_ context.Context, identifiers []string) ([]database.User, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	users := make([]database.User, 0)
+	for _, user := range q.users {
+		if user.Deleted {
+			continue
+		}
+		if slice.Contains(identifiers, strings.ToLower(user.Username)) ||
+			slice.Contains(identifiers, strings.ToLower(user.Email)) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (q *fakeQuerier) GetAuthorizationUserRoles(_ context.Context, userID uuid.UUID) (database.GetAuthorizationUserRolesRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -526,24 +590,66 @@ func (q *fakeQuerier) GetAuthorizationUserRoles(_ context.Context, userID uuid.U
 			roles = append(roles, "organization-member:"+mem.OrganizationID.String())
 		}
 	}
+	var everyoneExcluded []string
+	for _, exclusion := range q.everyoneGroupExclusions {
+		if exclusion.UserID == userID {
+			everyoneExcluded = append(everyoneExcluded, exclusion.OrganizationID.String())
+		}
+	}
 
-	var groups []string
+	var groupIDs []uuid.UUID
 	for _, member := range q.groupMembers {
-		if member.UserID == userID {
-			groups = append(groups, member.GroupID.String())
+		if member.UserID != userID {
+			continue
+		}
+		if member.ExpiresAt.Valid && !member.ExpiresAt.Time.After(database.Now()) {
+			continue
+		}
+		groupIDs = append(groupIDs, member.GroupID)
+	}
+	deletedGroupIDs := make(map[uuid.UUID]struct{})
+	for _, group := range q.groups {
+		if group.DeletedAt.Valid {
+			deletedGroupIDs[group.ID] = struct{}{}
+		}
+	}
+	// Users inherit the groups above the groups they're a member of,
+	// up to the first deleted group.
+	var groups []string
+	for _, groupID := range groupIDs {
+		for _, id := range q.groupAncestorIDsNoLock(groupID) {
+			if _, ok := deletedGroupIDs[id]; ok {
+				break
+			}
+			if !slice.Contains(groups, id.String()) {
+				groups = append(groups, id.String())
+			}
 		}
 	}
 
+	// Organization groups only grant their roles while the user is a
+	// member of the organization.
+	for _, group := range q.groups {
+		if !slice.Contains(groups, group.ID.String()) {
+			continue
+		}
+		if group.OrganizationID.Valid && !slice.Contains(roles, "organization-member:"+group.OrganizationID.UUID.String()) {
+			continue
+		}
+		roles = append(roles, group.Roles...)
+	}
+
 	if user == nil {
 		return database.GetAuthorizationUserRolesRow{}, sql.ErrNoRows
 	}
 
 	return database.GetAuthorizationUserRolesRow{
-		ID:       userID,
-		Username: user.Username,
-		Status:   user.Status,
-		Roles:    roles,
-		Groups:   groups,
+		ID:                   userID,
+		Username:             user.Username,
+		Status:               user.Status,
+		Roles:                roles,
+		Groups:               groups,
+		EveryoneExcludedOrgs: everyoneExcluded,
 	}, nil
 }
 
@@ -557,6 +663,14 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(_ context.Context, arg database.Ge
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	var labels map[string]string
+	if len(arg.Labels) > 0 {
+		err := json.Unmarshal(arg.Labels, &labels)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	workspaces := make([]database.Workspace, 0)
 	for _, workspace := range q.workspaces {
 		if arg.OwnerID != uuid.Nil && workspace.OwnerID != arg.OwnerID {
@@ -593,12 +707,29 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(_ context.Context, arg database.Ge
 				continue
 			}
 		}
+		if len(labels) > 0 {
+			var workspaceLabels map[string]string
+			_ = json.Unmarshal(workspace.Labels, &workspaceLabels)
+			match := true
+			for key, value := range labels {
+				if v, ok := workspaceLabels[key]; !ok || v != value {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+		}
 
 		// If the filter exists, ensure the object is authorized.
 		if authorizedFilter != nil && !authorizedFilter.Eval(workspace.RBACObject()) {
 			continue
 		}
 		workspaces = append(workspaces, workspace)
+		if arg.LimitOpt > 0 && len(workspaces) == int(arg.LimitOpt) {
+			break
+		}
 	}
 
 	return workspaces, nil
@@ -616,6 +747,12 @@ func (q *fakeQuerier) GetWorkspaceByID(_ context.Context, id uuid.UUID) (databas
 	return database.Workspace{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) GetWorkspaceByIDForUpdate(ctx context.Context, id uuid.UUID) (database.Workspace, error) {
+	// Transactions hold the mutex of the store, so the workspace is locked
+	// already.
+	return q.GetWorkspaceByID(ctx, id)
+}
+
 func (q *fakeQuerier) GetWorkspaceByOwnerIDAndName(_ context.Context, arg database.GetWorkspaceByOwnerIDAndNameParams) (database.Workspace, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1077,6 +1214,9 @@ func (q *fakeQuerier) GetTemplatesWithFilter(_ context.Context, arg database.Get
 		if arg.ExactName != "" && !strings.EqualFold(template.Name, arg.ExactName) {
 			continue
 		}
+		if arg.FuzzyName != "" && !strings.Contains(strings.ToLower(template.Name), strings.ToLower(arg.FuzzyName)) {
+			continue
+		}
 
 		if len(arg.IDs) > 0 {
 			match := false
@@ -1099,6 +1239,18 @@ func (q *fakeQuerier) GetTemplatesWithFilter(_ context.Context, arg database.Get
 			}
 			return i.ID.String() < j.ID.String()
 		})
+		if arg.AfterID != uuid.Nil {
+			index := slices.IndexFunc(templates, func(template database.Template) bool {
+				return template.ID == arg.AfterID
+			})
+			if index < 0 {
+				return []database.Template{}, nil
+			}
+			templates = templates[index+1:]
+		}
+		if arg.LimitOpt > 0 && len(templates) > int(arg.LimitOpt) {
+			templates = templates[:arg.LimitOpt]
+		}
 		return templates, nil
 	}
 
@@ -1383,7 +1535,7 @@ func (q *fakeQuerier) GetTemplateGroupRoles(_ context.Context, id uuid.UUID) ([]
 		}
 		// We don't delete groups from the map if they
 		// get deleted so just skip.
-		if xerrors.Is(err, sql.ErrNoRows) {
+		if xerrors.Is(err, sql.ErrNoRows) || group.DeletedAt.Valid {
 			continue
 		}
 
@@ -1396,6 +1548,30 @@ func (q *fakeQuerier) GetTemplateGroupRoles(_ context.Context, id uuid.UUID) ([]
 	return groups, nil
 }
 
+func (q *fakeQuerier) GetGroupTemplateRoles(_ context.Context, groupID uuid.UUID) ([]database.GroupTemplate, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	templates := make([]database.GroupTemplate, 0)
+	for _, template := range q.templates {
+		if template.Deleted {
+			continue
+		}
+		actions, ok := template.GroupACL()[groupID.String()]
+		if !ok {
+			continue
+		}
+		templates = append(templates, database.GroupTemplate{
+			Template: template,
+			Actions:  actions,
+		})
+	}
+	slices.SortFunc(templates, func(a, b database.GroupTemplate) bool {
+		return a.Name < b.Name
+	})
+	return templates, nil
+}
+
 func (q *fakeQuerier) GetOrganizationMemberByUserID(_ context.Context, arg database.GetOrganizationMemberByUserIDParams) (database.OrganizationMember, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1450,6 +1626,111 @@ func (q *fakeQuerier) GetOrganizationMembershipsByUserID(_ context.Context, user
 	return memberships, nil
 }
 
+func (q *fakeQuerier) GetOrganizationMembers(_ context.Context, arg database.GetOrganizationMembersParams) ([]database.GetOrganizationMembersRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	members := make([]database.GetOrganizationMembersRow, 0)
+	for _, member := range q.organizationMembers {
+		if member.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		for _, user := range q.users {
+			if user.ID != member.UserID || user.Deleted {
+				continue
+			}
+			members = append(members, database.GetOrganizationMembersRow{
+				UserID:         member.UserID,
+				OrganizationID: member.OrganizationID,
+				CreatedAt:      member.CreatedAt,
+				UpdatedAt:      member.UpdatedAt,
+				Roles:          member.Roles,
+				Username:       user.Username,
+				Email:          user.Email,
+				AvatarURL:      user.AvatarURL,
+			})
+			break
+		}
+	}
+
+	// Database orders by created_at
+	slices.SortFunc(members, func(a, b database.GetOrganizationMembersRow) bool {
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			// Technically the postgres database also orders by uuid. So match
+			// that behavior
+			return a.UserID.String() < b.UserID.String()
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	if arg.AfterID != uuid.Nil {
+		found := false
+		for i, v := range members {
+			if v.UserID == arg.AfterID {
+				// We want to return all members after index i.
+				members = members[i+1:]
+				found = true
+				break
+			}
+		}
+
+		// If no members after the time, then we return an empty list.
+		if !found {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	if arg.Search != "" {
+		tmp := make([]database.GetOrganizationMembersRow, 0, len(members))
+		for _, member := range members {
+			if strings.Contains(strings.ToLower(member.Email), strings.ToLower(arg.Search)) ||
+				strings.Contains(strings.ToLower(member.Username), strings.ToLower(arg.Search)) {
+				tmp = append(tmp, member)
+			}
+		}
+		members = tmp
+	}
+
+	if len(arg.Roles) > 0 {
+		tmp := make([]database.GetOrganizationMembersRow, 0, len(members))
+		for _, member := range members {
+			if slice.OverlapCompare(arg.Roles, member.Roles, strings.EqualFold) {
+				tmp = append(tmp, member)
+			}
+		}
+		members = tmp
+	}
+
+	if arg.GroupID != uuid.Nil {
+		tmp := make([]database.GetOrganizationMembersRow, 0, len(members))
+		for _, member := range members {
+			for _, groupMember := range q.groupMembers {
+				if groupMember.GroupID == arg.GroupID && groupMember.UserID == member.UserID {
+					tmp = append(tmp, member)
+					break
+				}
+			}
+		}
+		members = tmp
+	}
+
+	if arg.OffsetOpt > 0 {
+		if int(arg.OffsetOpt) > len(members)-1 {
+			return nil, sql.ErrNoRows
+		}
+		members = members[arg.OffsetOpt:]
+	}
+
+	if arg.LimitOpt > 0 {
+		if int(arg.LimitOpt) > len(members) {
+			arg.LimitOpt = int32(len(members))
+		}
+		members = members[:arg.LimitOpt]
+	}
+
+	return members, nil
+}
+
 func (q *fakeQuerier) UpdateMemberRoles(_ context.Context, arg database.UpdateMemberRolesParams) (database.OrganizationMember, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1772,17 +2053,22 @@ func (q *fakeQuerier) InsertAPIKey(_ context.Context, arg database.InsertAPIKeyP
 
 	//nolint:gosimple
 	key := database.APIKey{
-		ID:              arg.ID,
-		LifetimeSeconds: arg.LifetimeSeconds,
-		HashedSecret:    arg.HashedSecret,
-		IPAddress:       arg.IPAddress,
-		UserID:          arg.UserID,
-		ExpiresAt:       arg.ExpiresAt,
-		CreatedAt:       arg.CreatedAt,
-		UpdatedAt:       arg.UpdatedAt,
-		LastUsed:        arg.LastUsed,
-		LoginType:       arg.LoginType,
-		Scope:           arg.Scope,
+		ID:                  arg.ID,
+		LifetimeSeconds:     arg.LifetimeSeconds,
+		HashedSecret:        arg.HashedSecret,
+		IPAddress:           arg.IPAddress,
+		UserID:              arg.UserID,
+		ExpiresAt:           arg.ExpiresAt,
+		CreatedAt:           arg.CreatedAt,
+		UpdatedAt:           arg.UpdatedAt,
+		LastUsed:            arg.LastUsed,
+		LoginType:           arg.LoginType,
+		Scope:               arg.Scope,
+		ScopePermissions:    arg.ScopePermissions,
+		ScopeOrganizationID: arg.ScopeOrganizationID,
+	}
+	if key.ScopePermissions == nil {
+		key.ScopePermissions = []string{}
 	}
 	q.apiKeys = append(q.apiKeys, key)
 	return key, nil
@@ -1813,6 +2099,7 @@ func (q *fakeQuerier) InsertOrganization(_ context.Context, arg database.InsertO
 		Name:      arg.Name,
 		CreatedAt: arg.CreatedAt,
 		UpdatedAt: arg.UpdatedAt,
+		IsDefault: arg.IsDefault,
 	}
 	q.organizations = append(q.organizations, organization)
 	return organization, nil
@@ -1875,6 +2162,8 @@ func (q *fakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTempl
 		MaxTtl:               arg.MaxTtl,
 		MinAutostartInterval: arg.MinAutostartInterval,
 		CreatedBy:            arg.CreatedBy,
+		QuotaWeight:          1,
+		WorkspaceLabels:      json.RawMessage("{}"),
 	}
 	template = template.SetUserACL(database.TemplateACL{})
 	template = template.SetGroupACL(database.TemplateACL{
@@ -2173,6 +2462,7 @@ func (q *fakeQuerier) InsertWorkspace(_ context.Context, arg database.InsertWork
 		Name:              arg.Name,
 		AutostartSchedule: arg.AutostartSchedule,
 		Ttl:               arg.Ttl,
+		Labels:            json.RawMessage("{}"),
 	}
 	q.workspaces = append(q.workspaces, workspace)
 	return workspace, nil
@@ -2369,6 +2659,22 @@ func (q *fakeQuerier) UpdateWorkspaceAgentVersionByID(_ context.Context, arg dat
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateWorkspaceAgentAuthToken(_ context.Context, arg database.UpdateWorkspaceAgentAuthTokenParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, agent := range q.provisionerJobAgents {
+		if agent.AuthToken != arg.AuthToken {
+			continue
+		}
+
+		agent.AuthToken = arg.NewAuthToken
+		agent.UpdatedAt = arg.UpdatedAt
+		q.provisionerJobAgents[index] = agent
+	}
+	return nil
+}
+
 func (q *fakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2459,6 +2765,32 @@ func (q *fakeQuerier) UpdateWorkspaceAutostart(_ context.Context, arg database.U
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateWorkspaceUserACLByID(_ context.Context, id uuid.UUID, acl database.WorkspaceACL) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, w := range q.workspaces {
+		if w.ID == id {
+			q.workspaces[i] = w.SetUserACL(acl)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceGroupACLByID(_ context.Context, id uuid.UUID, acl database.WorkspaceACL) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, w := range q.workspaces {
+		if w.ID == id {
+			q.workspaces[i] = w.SetGroupACL(acl)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateWorkspaceTTL(_ context.Context, arg database.UpdateWorkspaceTTLParams) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2581,13 +2913,102 @@ func (q *fakeQuerier) InsertGroupMember(_ context.Context, arg database.InsertGr
 
 	//nolint:gosimple
 	q.groupMembers = append(q.groupMembers, database.GroupMember{
-		GroupID: arg.GroupID,
-		UserID:  arg.UserID,
+		GroupID:   arg.GroupID,
+		UserID:    arg.UserID,
+		ExpiresAt: arg.ExpiresAt,
+		Roles:     []string{},
 	})
 
 	return nil
 }
 
+func (q *fakeQuerier) InsertGroupMembers(_ context.Context, arg database.InsertGroupMembersParams) ([]uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	added := make([]uuid.UUID, 0)
+	for _, userID := range arg.UserIds {
+		exists := false
+		for _, member := range q.groupMembers {
+			if member.GroupID == arg.GroupID && member.UserID == userID {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+		q.groupMembers = append(q.groupMembers, database.GroupMember{
+			GroupID: arg.GroupID,
+			UserID:  userID,
+			Roles:   []string{},
+		})
+		added = append(added, userID)
+	}
+	return added, nil
+}
+
+func (q *fakeQuerier) UpdateGroupMemberRoles(_ context.Context, arg database.UpdateGroupMemberRolesParams) (database.GroupMember, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, member := range q.groupMembers {
+		if member.UserID == arg.UserID && member.GroupID == arg.GroupID {
+			uniqueRoles := make([]string, 0, len(arg.GrantedRoles))
+			exist := make(map[string]struct{})
+			for _, r := range arg.GrantedRoles {
+				if _, ok := exist[r]; ok {
+					continue
+				}
+				exist[r] = struct{}{}
+				uniqueRoles = append(uniqueRoles, r)
+			}
+			sort.Strings(uniqueRoles)
+
+			member.Roles = uniqueRoles
+			q.groupMembers[i] = member
+			return member, nil
+		}
+	}
+
+	return database.GroupMember{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteGroupMembersExceptUserIDs(_ context.Context, arg database.DeleteGroupMembersExceptUserIDsParams) ([]uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	removed := make([]uuid.UUID, 0)
+	kept := make([]database.GroupMember, 0, len(q.groupMembers))
+	for _, member := range q.groupMembers {
+		if member.GroupID == arg.GroupID && !slice.Contains(arg.UserIds, member.UserID) {
+			removed = append(removed, member.UserID)
+			continue
+		}
+		kept = append(kept, member)
+	}
+	q.groupMembers = kept
+	return removed, nil
+}
+
+func (q *fakeQuerier) DeleteExpiredGroupMembers(_ context.Context) ([]database.GroupMember, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := database.Now()
+	removed := make([]database.GroupMember, 0)
+	kept := make([]database.GroupMember, 0, len(q.groupMembers))
+	for _, member := range q.groupMembers {
+		if member.ExpiresAt.Valid && !member.ExpiresAt.Time.After(now) {
+			removed = append(removed, member)
+			continue
+		}
+		kept = append(kept, member)
+	}
+	q.groupMembers = kept
+	return removed, nil
+}
+
 func (q *fakeQuerier) DeleteGroupMember(_ context.Context, userID uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2600,6 +3021,23 @@ func (q *fakeQuerier) DeleteGroupMember(_ context.Context, userID uuid.UUID) err
 	return nil
 }
 
+func (q *fakeQuerier) DeleteUserFromGroups(_ context.Context, arg database.DeleteUserFromGroupsParams) ([]uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	kept := make([]database.GroupMember, 0, len(q.groupMembers))
+	removed := make([]uuid.UUID, 0)
+	for _, member := range q.groupMembers {
+		if member.UserID == arg.UserID && slice.Contains(arg.GroupIds, member.GroupID) {
+			removed = append(removed, member.GroupID)
+			continue
+		}
+		kept = append(kept, member)
+	}
+	q.groupMembers = kept
+	return removed, nil
+}
+
 func (q *fakeQuerier) UpdateGroupByID(_ context.Context, arg database.UpdateGroupByIDParams) (database.Group, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2607,6 +3045,15 @@ func (q *fakeQuerier) UpdateGroupByID(_ context.Context, arg database.UpdateGrou
 	for i, group := range q.groups {
 		if group.ID == arg.ID {
 			group.Name = arg.Name
+			group.ParentID = arg.ParentID
+			group.DisplayName = arg.DisplayName
+			group.AvatarURL = arg.AvatarURL
+			group.Description = arg.Description
+			group.Metadata = arg.Metadata
+			group.AutostopSchedule = arg.AutostopSchedule
+			group.MaxTtl = arg.MaxTtl
+			group.QuotaAllowance = arg.QuotaAllowance
+			group.Roles = arg.Roles
 			q.groups[i] = group
 			return group, nil
 		}
@@ -2635,8 +3082,19 @@ func (q *fakeQuerier) GetAuditLogsOffset(ctx context.Context, arg database.GetAu
 
 	logs := make([]database.GetAuditLogsOffsetRow, 0, arg.Limit)
 
+	auditLogs := q.auditLogs
+	if arg.AfterID != uuid.Nil {
+		index := slices.IndexFunc(auditLogs, func(alog database.AuditLog) bool {
+			return alog.ID == arg.AfterID
+		})
+		if index < 0 {
+			return logs, nil
+		}
+		auditLogs = auditLogs[index+1:]
+	}
+
 	// q.auditLogs are already sorted by time DESC, so no need to sort after the fact.
-	for _, alog := range q.auditLogs {
+	for _, alog := range auditLogs {
 		if arg.Offset > 0 {
 			arg.Offset--
 			continue
@@ -2650,6 +3108,9 @@ func (q *fakeQuerier) GetAuditLogsOffset(ctx context.Context, arg database.GetAu
 		if arg.ResourceID != uuid.Nil && alog.ResourceID != arg.ResourceID {
 			continue
 		}
+		if arg.OrganizationID != uuid.Nil && alog.OrganizationID != arg.OrganizationID {
+			continue
+		}
 		if arg.Username != "" {
 			user, err := q.GetUserByID(context.Background(), alog.UserID)
 			if err == nil && !strings.EqualFold(arg.Username, user.Username) {
@@ -2712,6 +3173,9 @@ func (q *fakeQuerier) GetAuditLogCount(_ context.Context, arg database.GetAuditL
 		if arg.ResourceID != uuid.Nil && alog.ResourceID != arg.ResourceID {
 			continue
 		}
+		if arg.OrganizationID != uuid.Nil && alog.OrganizationID != arg.OrganizationID {
+			continue
+		}
 		if arg.Username != "" {
 			user, err := q.GetUserByID(context.Background(), alog.UserID)
 			if err == nil && !strings.EqualFold(arg.Username, user.Username) {
@@ -2760,6 +3224,24 @@ func (q *fakeQuerier) GetDeploymentID(_ context.Context) (string, error) {
 	return q.deploymentID, nil
 }
 
+func (q *fakeQuerier) GetMaintenance(_ context.Context) (string, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if q.maintenance == "" {
+		return "", sql.ErrNoRows
+	}
+	return q.maintenance, nil
+}
+
+func (q *fakeQuerier) UpsertMaintenance(_ context.Context, value string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.maintenance = value
+	return nil
+}
+
 func (q *fakeQuerier) InsertLicense(
 	_ context.Context, arg database.InsertLicenseParams,
 ) (database.License, error) {
@@ -2911,7 +3393,8 @@ func (q *fakeQuerier) GetGroupByOrgAndName(_ context.Context, arg database.GetGr
 
 	for _, group := range q.groups {
 		if group.OrganizationID == arg.OrganizationID &&
-			group.Name == arg.Name {
+			group.Name == arg.Name &&
+			!group.DeletedAt.Valid {
 			return group, nil
 		}
 	}
@@ -2923,17 +3406,19 @@ func (q *fakeQuerier) InsertAllUsersGroup(ctx context.Context, orgID uuid.UUID)
 	return q.InsertGroup(ctx, database.InsertGroupParams{
 		ID:             orgID,
 		Name:           database.AllUsersGroup,
-		OrganizationID: orgID,
+		OrganizationID: uuid.NullUUID{UUID: orgID, Valid: true},
+		Source:         database.GroupSourceUser,
 	})
 }
 
 func (q *fakeQuerier) InsertGroup(_ context.Context, arg database.InsertGroupParams) (database.Group, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
 	for _, group := range q.groups {
-		if group.OrganizationID.String() == arg.OrganizationID.String() &&
-			group.Name == arg.Name {
+		if group.OrganizationID == arg.OrganizationID &&
+			group.Name == arg.Name &&
+			!group.DeletedAt.Valid {
 			return database.Group{}, errDuplicateKey
 		}
 	}
@@ -2943,6 +3428,13 @@ func (q *fakeQuerier) InsertGroup(_ context.Context, arg database.InsertGroupPar
 		ID:             arg.ID,
 		Name:           arg.Name,
 		OrganizationID: arg.OrganizationID,
+		ParentID:       arg.ParentID,
+		DisplayName:    arg.DisplayName,
+		AvatarURL:      arg.AvatarURL,
+		Description:    arg.Description,
+		Source:         arg.Source,
+		Metadata:       json.RawMessage("{}"),
+		Roles:          []string{},
 	}
 
 	q.groups = append(q.groups, group)
@@ -2950,16 +3442,168 @@ func (q *fakeQuerier) InsertGroup(_ context.Context, arg database.InsertGroupPar
 	return group, nil
 }
 
-func (*fakeQuerier) GetUserGroups(_ context.Context, _ uuid.UUID) ([]database.Group, error) {
-	panic("not implemented")
-}
-
-func (q *fakeQuerier) GetGroupMembers(_ context.Context, groupID uuid.UUID) ([]database.User, error) {
+func (q *fakeQuerier) GetGroupAncestorIDs(_ context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	var members []database.GroupMember
-	for _, member := range q.groupMembers {
+	return q.groupAncestorIDsNoLock(groupID), nil
+}
+
+func (q *fakeQuerier) groupAncestorIDsNoLock(groupID uuid.UUID) []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	next := uuid.NullUUID{UUID: groupID, Valid: true}
+	for next.Valid && !slice.Contains(ids, next.UUID) {
+		found := false
+		for _, group := range q.groups {
+			if group.ID == next.UUID {
+				ids = append(ids, group.ID)
+				next = group.ParentID
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return ids
+}
+
+func (q *fakeQuerier) GetGroupAutostopUserIDs(_ context.Context) ([]uuid.UUID, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	// Policies apply to the groups nested beneath a group too.
+	groups := make(map[uuid.UUID]struct{})
+	for _, group := range q.groups {
+		if group.DeletedAt.Valid {
+			continue
+		}
+		for _, id := range q.groupAncestorIDsNoLock(group.ID) {
+			ancestor, ok := q.groupByIDNoLock(id)
+			if !ok || ancestor.DeletedAt.Valid {
+				break
+			}
+			if ancestor.AutostopSchedule != "" || ancestor.MaxTtl > 0 {
+				groups[group.ID] = struct{}{}
+				break
+			}
+		}
+	}
+	userIDs := make([]uuid.UUID, 0)
+	for _, member := range q.groupMembers {
+		if member.ExpiresAt.Valid && !member.ExpiresAt.Time.After(database.Now()) {
+			continue
+		}
+		if _, ok := groups[member.GroupID]; ok && !slice.Contains(userIDs, member.UserID) {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+	// The "Everyone" group of an organization shares its ID.
+	for _, member := range q.organizationMembers {
+		if _, ok := groups[member.OrganizationID]; !ok || slice.Contains(userIDs, member.UserID) {
+			continue
+		}
+		if q.everyoneGroupExcludedNoLock(member.OrganizationID, member.UserID) {
+			continue
+		}
+		userIDs = append(userIDs, member.UserID)
+	}
+	return userIDs, nil
+}
+
+func (q *fakeQuerier) groupByIDNoLock(id uuid.UUID) (database.Group, bool) {
+	for _, group := range q.groups {
+		if group.ID == id {
+			return group, true
+		}
+	}
+	return database.Group{}, false
+}
+
+func (q *fakeQuerier) everyoneGroupExcludedNoLock(organizationID, userID uuid.UUID) bool {
+	for _, exclusion := range q.everyoneGroupExclusions {
+		if exclusion.OrganizationID == organizationID && exclusion.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *fakeQuerier) GetUserEffectiveGroups(_ context.Context, arg database.GetUserEffectiveGroupsParams) ([]database.Group, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	groupIDs := make(map[uuid.UUID]struct{})
+	for _, member := range q.groupMembers {
+		if member.UserID != arg.UserID {
+			continue
+		}
+		if member.ExpiresAt.Valid && !member.ExpiresAt.Time.After(database.Now()) {
+			continue
+		}
+		// Users inherit the groups above the groups they're a member of,
+		// up to the first deleted group.
+		for _, id := range q.groupAncestorIDsNoLock(member.GroupID) {
+			group, ok := q.groupByIDNoLock(id)
+			if !ok || group.DeletedAt.Valid {
+				break
+			}
+			groupIDs[id] = struct{}{}
+		}
+	}
+	for _, member := range q.organizationMembers {
+		if member.OrganizationID == arg.OrganizationID && member.UserID == arg.UserID &&
+			!q.everyoneGroupExcludedNoLock(arg.OrganizationID, arg.UserID) {
+			groupIDs[arg.OrganizationID] = struct{}{}
+		}
+	}
+
+	groups := make([]database.Group, 0)
+	for _, group := range q.groups {
+		if _, ok := groupIDs[group.ID]; !ok || group.DeletedAt.Valid {
+			continue
+		}
+		if group.OrganizationID.Valid && group.OrganizationID.UUID != arg.OrganizationID {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	slices.SortFunc(groups, func(a, b database.Group) bool {
+		return a.Name < b.Name
+	})
+	return groups, nil
+}
+
+func (q *fakeQuerier) GetUserGroups(_ context.Context, userID uuid.UUID) ([]database.Group, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	groupIDs := make(map[uuid.UUID]struct{})
+	for _, member := range q.groupMembers {
+		if member.UserID == userID {
+			groupIDs[member.GroupID] = struct{}{}
+		}
+	}
+
+	groups := make([]database.Group, 0, len(groupIDs))
+	for _, group := range q.groups {
+		if _, ok := groupIDs[group.ID]; ok && !group.DeletedAt.Valid {
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups, nil
+}
+
+func (q *fakeQuerier) GetGroupMembers(_ context.Context, groupID uuid.UUID) ([]database.User, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var members []database.GroupMember
+	for _, member := range q.groupMembers {
 		if member.GroupID == groupID {
 			members = append(members, member)
 		}
@@ -2979,6 +3623,249 @@ func (q *fakeQuerier) GetGroupMembers(_ context.Context, groupID uuid.UUID) ([]d
 	return users, nil
 }
 
+func (q *fakeQuerier) GetGroupMemberIDsWithRole(_ context.Context, arg database.GetGroupMemberIDsWithRoleParams) ([]uuid.UUID, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	ids := make([]uuid.UUID, 0)
+	for _, member := range q.groupMembers {
+		if member.GroupID == arg.GroupID && slices.Contains(member.Roles, arg.Role) {
+			ids = append(ids, member.UserID)
+		}
+	}
+	return ids, nil
+}
+
+func (q *fakeQuerier) GetGroupMemberCountsByGroupIDs(ctx context.Context, groupIDs []uuid.UUID) ([]database.GetGroupMemberCountsByGroupIDsRow, error) {
+	members, err := q.GetGroupMembersByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]database.GetGroupMemberCountsByGroupIDsRow, 0)
+	for _, member := range members {
+		i := slices.IndexFunc(counts, func(row database.GetGroupMemberCountsByGroupIDsRow) bool {
+			return row.GroupID == member.GroupID
+		})
+		if i < 0 {
+			counts = append(counts, database.GetGroupMemberCountsByGroupIDsRow{GroupID: member.GroupID})
+			i = len(counts) - 1
+		}
+		counts[i].Count++
+	}
+	return counts, nil
+}
+
+func (q *fakeQuerier) GetGroupMembersByGroupIDs(_ context.Context, groupIDs []uuid.UUID) ([]database.GetGroupMembersByGroupIDsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetGroupMembersByGroupIDsRow, 0)
+	for _, member := range q.groupMembers {
+		if !slice.Contains(groupIDs, member.GroupID) {
+			continue
+		}
+		for _, user := range q.users {
+			if user.ID == member.UserID && user.Status == database.UserStatusActive && !user.Deleted {
+				rows = append(rows, database.GetGroupMembersByGroupIDsRow{
+					GroupID:        member.GroupID,
+					ExpiresAt:      member.ExpiresAt,
+					GroupRoles:     member.Roles,
+					ID:             user.ID,
+					Email:          user.Email,
+					Username:       user.Username,
+					HashedPassword: user.HashedPassword,
+					CreatedAt:      user.CreatedAt,
+					UpdatedAt:      user.UpdatedAt,
+					Status:         user.Status,
+					RBACRoles:      user.RBACRoles,
+					LoginType:      user.LoginType,
+					AvatarURL:      user.AvatarURL,
+					Deleted:        user.Deleted,
+					LastSeenAt:     user.LastSeenAt,
+				})
+				break
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetGroupMembersPage(_ context.Context, arg database.GetGroupMembersPageParams) ([]database.GetGroupMembersPageRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var after *database.User
+	if arg.AfterID != uuid.Nil {
+		for i := range q.users {
+			if q.users[i].ID == arg.AfterID {
+				after = &q.users[i]
+				break
+			}
+		}
+		// The cursor doesn't match any row, so nothing is after it.
+		if after == nil {
+			return []database.GetGroupMembersPageRow{}, nil
+		}
+	}
+
+	rows := make([]database.GetGroupMembersPageRow, 0)
+	for _, member := range q.groupMembers {
+		if member.GroupID != arg.GroupID {
+			continue
+		}
+		for _, user := range q.users {
+			if user.ID != member.UserID || user.Status != database.UserStatusActive || user.Deleted {
+				continue
+			}
+			if after != nil && (user.Username < after.Username ||
+				(user.Username == after.Username && user.ID.String() <= after.ID.String())) {
+				break
+			}
+			rows = append(rows, database.GetGroupMembersPageRow{
+				ExpiresAt:      member.ExpiresAt,
+				GroupRoles:     member.Roles,
+				ID:             user.ID,
+				Email:          user.Email,
+				Username:       user.Username,
+				HashedPassword: user.HashedPassword,
+				CreatedAt:      user.CreatedAt,
+				UpdatedAt:      user.UpdatedAt,
+				Status:         user.Status,
+				RBACRoles:      user.RBACRoles,
+				LoginType:      user.LoginType,
+				AvatarURL:      user.AvatarURL,
+				Deleted:        user.Deleted,
+				LastSeenAt:     user.LastSeenAt,
+			})
+			break
+		}
+	}
+
+	// Database orders by username
+	slices.SortFunc(rows, func(a, b database.GetGroupMembersPageRow) bool {
+		if a.Username == b.Username {
+			return a.ID.String() < b.ID.String()
+		}
+		return a.Username < b.Username
+	})
+	if arg.LimitOpt > 0 && int(arg.LimitOpt) < len(rows) {
+		rows = rows[:arg.LimitOpt]
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetGroupMembershipsByUserIDs(_ context.Context, arg database.GetGroupMembershipsByUserIDsParams) ([]database.GetGroupMembershipsByUserIDsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	memberships := make([]database.GetGroupMembershipsByUserIDsRow, 0)
+	for _, member := range q.groupMembers {
+		if !slice.Contains(arg.UserIds, member.UserID) {
+			continue
+		}
+		for _, group := range q.groups {
+			if group.ID == member.GroupID && group.OrganizationID.Valid && group.OrganizationID.UUID == arg.OrganizationID && !group.DeletedAt.Valid {
+				memberships = append(memberships, database.GetGroupMembershipsByUserIDsRow{
+					UserID:    member.UserID,
+					GroupID:   group.ID,
+					GroupName: group.Name,
+				})
+				break
+			}
+		}
+	}
+
+	return memberships, nil
+}
+
+func (q *fakeQuerier) GetGroups(ctx context.Context, arg database.GetGroupsParams) ([]database.GetGroupsRow, error) {
+	return q.GetAuthorizedGroups(ctx, arg, nil)
+}
+
+func (q *fakeQuerier) GetAuthorizedGroups(_ context.Context, arg database.GetGroupsParams, authorizedFilter rbac.AuthorizeFilter) ([]database.GetGroupsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	groups := make([]database.Group, 0)
+	for _, group := range q.groups {
+		if group.OrganizationID != arg.OrganizationID || (arg.OrganizationID.Valid && group.ID == arg.OrganizationID.UUID) || group.DeletedAt.Valid {
+			continue
+		}
+		if arg.Search != "" && !strings.Contains(strings.ToLower(group.Name), strings.ToLower(arg.Search)) {
+			continue
+		}
+		if authorizedFilter != nil && !authorizedFilter.Eval(group.RBACObject()) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	// Database orders by name
+	slices.SortFunc(groups, func(a, b database.Group) bool {
+		if a.Name == b.Name {
+			return a.ID.String() < b.ID.String()
+		}
+		return a.Name < b.Name
+	})
+
+	// The count ignores the cursor, offset, and limit.
+	count := int64(len(groups))
+	if arg.AfterID != uuid.Nil {
+		found := false
+		for i, v := range groups {
+			if v.ID == arg.AfterID {
+				// We want to return all groups after index i.
+				groups = groups[i+1:]
+				found = true
+				break
+			}
+		}
+
+		// If no groups after the cursor, then we return an empty list.
+		if !found {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	if arg.OffsetOpt > 0 {
+		if int(arg.OffsetOpt) > len(groups)-1 {
+			return nil, sql.ErrNoRows
+		}
+		groups = groups[arg.OffsetOpt:]
+	}
+
+	if arg.LimitOpt > 0 {
+		if int(arg.LimitOpt) > len(groups) {
+			arg.LimitOpt = int32(len(groups))
+		}
+		groups = groups[:arg.LimitOpt]
+	}
+
+	rows := make([]database.GetGroupsRow, 0, len(groups))
+	for _, group := range groups {
+		rows = append(rows, database.GetGroupsRow{
+			ID:               group.ID,
+			Name:             group.Name,
+			OrganizationID:   group.OrganizationID,
+			ParentID:         group.ParentID,
+			DisplayName:      group.DisplayName,
+			AvatarURL:        group.AvatarURL,
+			Description:      group.Description,
+			Source:           group.Source,
+			DeletedAt:        group.DeletedAt,
+			Metadata:         group.Metadata,
+			AutostopSchedule: group.AutostopSchedule,
+			MaxTtl:           group.MaxTtl,
+			QuotaAllowance:   group.QuotaAllowance,
+			Roles:            group.Roles,
+			Count:            count,
+		})
+	}
+	return rows, nil
+}
+
 func (q *fakeQuerier) GetGroupsByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.Group, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2986,7 +3873,7 @@ func (q *fakeQuerier) GetGroupsByOrganizationID(_ context.Context, organizationI
 	var groups []database.Group
 	for _, group := range q.groups {
 		// Omit the allUsers group.
-		if group.OrganizationID == organizationID && group.ID != organizationID {
+		if group.OrganizationID.Valid && group.OrganizationID.UUID == organizationID && group.ID != organizationID && !group.DeletedAt.Valid {
 			groups = append(groups, group)
 		}
 	}
@@ -3012,16 +3899,2882 @@ func (q *fakeQuerier) GetAllOrganizationMembers(_ context.Context, organizationI
 	return users, nil
 }
 
-func (q *fakeQuerier) DeleteGroupByID(_ context.Context, id uuid.UUID) error {
+func (q *fakeQuerier) UpdateGroupDeletedAtByID(_ context.Context, arg database.UpdateGroupDeletedAtByIDParams) (database.Group, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
 	for i, group := range q.groups {
-		if group.ID == id {
-			q.groups = append(q.groups[:i], q.groups[i+1:]...)
-			return nil
+		if group.ID != arg.ID {
+			continue
 		}
+		if !arg.DeletedAt.Valid {
+			for _, other := range q.groups {
+				if other.ID != group.ID && other.OrganizationID == group.OrganizationID &&
+					other.Name == group.Name && !other.DeletedAt.Valid {
+					return database.Group{}, errDuplicateKey
+				}
+			}
+		}
+		group.DeletedAt = arg.DeletedAt
+		q.groups[i] = group
+		return group, nil
 	}
 
-	return sql.ErrNoRows
+	return database.Group{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteGroupsDeletedBefore(_ context.Context, deletedBefore time.Time) ([]database.Group, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	removed := make([]database.Group, 0)
+	groups := make([]database.Group, 0, len(q.groups))
+	for _, group := range q.groups {
+		if group.DeletedAt.Valid && group.DeletedAt.Time.Before(deletedBefore) {
+			removed = append(removed, group)
+			continue
+		}
+		groups = append(groups, group)
+	}
+	q.groups = groups
+
+	for _, group := range removed {
+		// Children of the removed group become top-level groups.
+		for i, child := range q.groups {
+			if child.ParentID.Valid && child.ParentID.UUID == group.ID {
+				q.groups[i].ParentID = uuid.NullUUID{}
+			}
+		}
+		members := make([]database.GroupMember, 0, len(q.groupMembers))
+		for _, member := range q.groupMembers {
+			if member.GroupID != group.ID {
+				members = append(members, member)
+			}
+		}
+		q.groupMembers = members
+		joinRequests := make([]database.GroupJoinRequest, 0, len(q.groupJoinRequests))
+		for _, joinRequest := range q.groupJoinRequests {
+			if joinRequest.GroupID != group.ID {
+				joinRequests = append(joinRequests, joinRequest)
+			}
+		}
+		q.groupJoinRequests = joinRequests
+	}
+
+	return removed, nil
+}
+
+func (q *fakeQuerier) InsertGroupJoinRequest(_ context.Context, arg database.InsertGroupJoinRequestParams) (database.GroupJoinRequest, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, joinRequest := range q.groupJoinRequests {
+		if joinRequest.GroupID == arg.GroupID && joinRequest.UserID == arg.UserID {
+			return database.GroupJoinRequest{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	joinRequest := database.GroupJoinRequest{
+		ID:        arg.ID,
+		GroupID:   arg.GroupID,
+		UserID:    arg.UserID,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.groupJoinRequests = append(q.groupJoinRequests, joinRequest)
+	return joinRequest, nil
+}
+
+func (q *fakeQuerier) GetGroupJoinRequestByID(_ context.Context, id uuid.UUID) (database.GroupJoinRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, joinRequest := range q.groupJoinRequests {
+		if joinRequest.ID == id {
+			return joinRequest, nil
+		}
+	}
+	return database.GroupJoinRequest{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetGroupJoinRequestsByGroupID(_ context.Context, groupID uuid.UUID) ([]database.GroupJoinRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	joinRequests := make([]database.GroupJoinRequest, 0)
+	for _, joinRequest := range q.groupJoinRequests {
+		if joinRequest.GroupID == groupID {
+			joinRequests = append(joinRequests, joinRequest)
+		}
+	}
+	sort.Slice(joinRequests, func(i, j int) bool {
+		return joinRequests[i].CreatedAt.Before(joinRequests[j].CreatedAt)
+	})
+	return joinRequests, nil
+}
+
+func (q *fakeQuerier) DeleteGroupJoinRequestByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, joinRequest := range q.groupJoinRequests {
+		if joinRequest.ID == id {
+			q.groupJoinRequests = append(q.groupJoinRequests[:i], q.groupJoinRequests[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) InsertGroupWebhook(_ context.Context, arg database.InsertGroupWebhookParams) (database.GroupWebhook, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	webhook := database.GroupWebhook{
+		ID:             arg.ID,
+		OrganizationID: arg.OrganizationID,
+		Url:            arg.Url,
+		Secret:         arg.Secret,
+		CreatedAt:      arg.CreatedAt,
+	}
+	q.groupWebhooks = append(q.groupWebhooks, webhook)
+	return webhook, nil
+}
+
+func (q *fakeQuerier) GetGroupWebhookByID(_ context.Context, id uuid.UUID) (database.GroupWebhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, webhook := range q.groupWebhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return database.GroupWebhook{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetGroupWebhooksByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.GroupWebhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	webhooks := make([]database.GroupWebhook, 0)
+	for _, webhook := range q.groupWebhooks {
+		if webhook.OrganizationID == organizationID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+func (q *fakeQuerier) DeleteGroupWebhookByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, webhook := range q.groupWebhooks {
+		if webhook.ID == id {
+			q.groupWebhooks = append(q.groupWebhooks[:i], q.groupWebhooks[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) InsertEveryoneGroupExclusion(_ context.Context, arg database.InsertEveryoneGroupExclusionParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, exclusion := range q.everyoneGroupExclusions {
+		if exclusion.OrganizationID == arg.OrganizationID && exclusion.UserID == arg.UserID {
+			return nil
+		}
+	}
+	//nolint:gosimple
+	q.everyoneGroupExclusions = append(q.everyoneGroupExclusions, database.EveryoneGroupExclusion{
+		OrganizationID: arg.OrganizationID,
+		UserID:         arg.UserID,
+		CreatedAt:      arg.CreatedAt,
+	})
+	return nil
+}
+
+func (q *fakeQuerier) GetEveryoneGroupExclusionsByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.EveryoneGroupExclusion, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	exclusions := make([]database.EveryoneGroupExclusion, 0)
+	for _, exclusion := range q.everyoneGroupExclusions {
+		if exclusion.OrganizationID == organizationID {
+			exclusions = append(exclusions, exclusion)
+		}
+	}
+	slices.SortFunc(exclusions, func(a, b database.EveryoneGroupExclusion) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return exclusions, nil
+}
+
+func (q *fakeQuerier) DeleteEveryoneGroupExclusion(_ context.Context, arg database.DeleteEveryoneGroupExclusionParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, exclusion := range q.everyoneGroupExclusions {
+		if exclusion.OrganizationID == arg.OrganizationID && exclusion.UserID == arg.UserID {
+			q.everyoneGroupExclusions = append(q.everyoneGroupExclusions[:i], q.everyoneGroupExclusions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetEveryoneGroupMembers(_ context.Context, organizationID uuid.UUID) ([]database.User, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	excluded := make(map[uuid.UUID]struct{})
+	for _, exclusion := range q.everyoneGroupExclusions {
+		if exclusion.OrganizationID == organizationID {
+			excluded[exclusion.UserID] = struct{}{}
+		}
+	}
+
+	var users []database.User
+	for _, member := range q.organizationMembers {
+		if member.OrganizationID != organizationID {
+			continue
+		}
+		if _, ok := excluded[member.UserID]; ok {
+			continue
+		}
+		for _, user := range q.users {
+			if user.ID == member.UserID {
+				users = append(users, user)
+			}
+		}
+	}
+	return users, nil
+}
+
+func (q *fakeQuerier) UpdateTemplateQuotaWeightByID(_ context.Context, arg database.UpdateTemplateQuotaWeightByIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, tpl := range q.templates {
+		if tpl.ID != arg.ID {
+			continue
+		}
+		tpl.QuotaWeight = arg.QuotaWeight
+		q.templates[idx] = tpl
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateTemplateWorkspaceLabelsByID(_ context.Context, arg database.UpdateTemplateWorkspaceLabelsByIDParams) (database.Template, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, tpl := range q.templates {
+		if tpl.ID != arg.ID {
+			continue
+		}
+		tpl.WorkspaceLabels = arg.WorkspaceLabels
+		tpl.UpdatedAt = arg.UpdatedAt
+		q.templates[idx] = tpl
+		return tpl, nil
+	}
+	return database.Template{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspaceQuotaConsumptionByOwnerID(_ context.Context, ownerID uuid.UUID) ([]database.GetWorkspaceQuotaConsumptionByOwnerIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	counts := make(map[uuid.UUID]int64)
+	for _, workspace := range q.workspaces {
+		if workspace.OwnerID != ownerID || workspace.Deleted {
+			continue
+		}
+		counts[workspace.TemplateID]++
+	}
+
+	rows := make([]database.GetWorkspaceQuotaConsumptionByOwnerIDRow, 0, len(counts))
+	for _, template := range q.templates {
+		count, ok := counts[template.ID]
+		if !ok {
+			continue
+		}
+		rows = append(rows, database.GetWorkspaceQuotaConsumptionByOwnerIDRow{
+			TemplateID:     template.ID,
+			TemplateName:   template.Name,
+			OrganizationID: template.OrganizationID,
+			QuotaWeight:    template.QuotaWeight,
+			WorkspaceCount: count,
+		})
+	}
+	slices.SortFunc(rows, func(a, b database.GetWorkspaceQuotaConsumptionByOwnerIDRow) bool {
+		return a.TemplateName < b.TemplateName
+	})
+	return rows, nil
+}
+
+func (q *fakeQuerier) DeleteOrganization(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, organization := range q.organizations {
+		if organization.ID != id || organization.IsDefault {
+			continue
+		}
+		q.organizations = append(q.organizations[:i], q.organizations[i+1:]...)
+		members := make([]database.OrganizationMember, 0, len(q.organizationMembers))
+		for _, member := range q.organizationMembers {
+			if member.OrganizationID != id {
+				members = append(members, member)
+			}
+		}
+		q.organizationMembers = members
+		invites := make([]database.OrganizationInvite, 0, len(q.organizationInvites))
+		for _, invite := range q.organizationInvites {
+			if invite.OrganizationID != id {
+				invites = append(invites, invite)
+			}
+		}
+		q.organizationInvites = invites
+		quotas := make([]database.OrganizationQuota, 0, len(q.organizationQuotas))
+		for _, quota := range q.organizationQuotas {
+			if quota.OrganizationID != id {
+				quotas = append(quotas, quota)
+			}
+		}
+		q.organizationQuotas = quotas
+		aliases := make([]database.OrganizationAlias, 0, len(q.organizationAliases))
+		for _, alias := range q.organizationAliases {
+			if alias.OrganizationID != id {
+				aliases = append(aliases, alias)
+			}
+		}
+		q.organizationAliases = aliases
+		oidcConfigs := make([]database.OrganizationOIDCConfig, 0, len(q.organizationOIDC))
+		for _, config := range q.organizationOIDC {
+			if config.OrganizationID != id {
+				oidcConfigs = append(oidcConfigs, config)
+			}
+		}
+		q.organizationOIDC = oidcConfigs
+		webhooks := make([]database.OrganizationWebhook, 0, len(q.organizationWebhooks))
+		for _, webhook := range q.organizationWebhooks {
+			if webhook.OrganizationID != id {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+		q.organizationWebhooks = webhooks
+		templateDefaults := make([]database.OrganizationTemplateDefault, 0, len(q.organizationTemplateDefaults))
+		for _, defaults := range q.organizationTemplateDefaults {
+			if defaults.OrganizationID != id {
+				templateDefaults = append(templateDefaults, defaults)
+			}
+		}
+		q.organizationTemplateDefaults = templateDefaults
+		namePolicies := make([]database.OrganizationWorkspaceNamePolicy, 0, len(q.organizationNamePolicies))
+		for _, policy := range q.organizationNamePolicies {
+			if policy.OrganizationID != id {
+				namePolicies = append(namePolicies, policy)
+			}
+		}
+		q.organizationNamePolicies = namePolicies
+		ipAllowlists := make([]database.OrganizationIpAllowlist, 0, len(q.organizationIPAllowlists))
+		for _, allowlist := range q.organizationIPAllowlists {
+			if allowlist.OrganizationID != id {
+				ipAllowlists = append(ipAllowlists, allowlist)
+			}
+		}
+		q.organizationIPAllowlists = ipAllowlists
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetWorkspaceCountByOrganizationID(_ context.Context, organizationID uuid.UUID) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var count int64
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID == organizationID && !workspace.Deleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *fakeQuerier) DeleteDeletedWorkspacesByOrganizationID(_ context.Context, arg database.DeleteDeletedWorkspacesByOrganizationIDParams) ([]uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deleted := make([]uuid.UUID, 0)
+	workspaces := make([]database.Workspace, 0, len(q.workspaces))
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID == arg.OrganizationID && workspace.Deleted && len(deleted) < int(arg.LimitOpt) {
+			deleted = append(deleted, workspace.ID)
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	q.workspaces = workspaces
+	costs := make([]database.WorkspaceCost, 0, len(q.workspaceCosts))
+	for _, cost := range q.workspaceCosts {
+		if slices.Contains(deleted, cost.WorkspaceID) {
+			continue
+		}
+		costs = append(costs, cost)
+	}
+	q.workspaceCosts = costs
+	extensions := make([]database.WorkspaceAutostopExtension, 0, len(q.workspaceAutostopExtensions))
+	for _, extension := range q.workspaceAutostopExtensions {
+		if slices.Contains(deleted, extension.WorkspaceID) {
+			continue
+		}
+		extensions = append(extensions, extension)
+	}
+	q.workspaceAutostopExtensions = extensions
+	archives := make([]database.WorkspaceArchive, 0, len(q.workspaceArchives))
+	for _, archive := range q.workspaceArchives {
+		if slices.Contains(deleted, archive.WorkspaceID) {
+			continue
+		}
+		archives = append(archives, archive)
+	}
+	q.workspaceArchives = archives
+	events := make([]database.WorkspaceTimelineEvent, 0, len(q.workspaceTimelineEvents))
+	for _, event := range q.workspaceTimelineEvents {
+		if slices.Contains(deleted, event.WorkspaceID) {
+			continue
+		}
+		events = append(events, event)
+	}
+	q.workspaceTimelineEvents = events
+	return deleted, nil
+}
+
+func (q *fakeQuerier) GetDefaultOrganization(_ context.Context) (database.Organization, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, organization := range q.organizations {
+		if organization.IsDefault {
+			return organization, nil
+		}
+	}
+	return database.Organization{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOrganizationByID(_ context.Context, arg database.UpdateOrganizationByIDParams) (database.Organization, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, organization := range q.organizations {
+		if organization.ID != arg.ID {
+			continue
+		}
+		organization.DisplayName = arg.DisplayName
+		organization.Icon = arg.Icon
+		organization.Description = arg.Description
+		organization.UpdatedAt = arg.UpdatedAt
+		organization.Name = arg.Name
+		q.organizations[i] = organization
+		return organization, nil
+	}
+	return database.Organization{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) InsertOrganizationInvite(_ context.Context, arg database.InsertOrganizationInviteParams) (database.OrganizationInvite, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, invite := range q.organizationInvites {
+		if string(invite.HashedToken) == string(arg.HashedToken) {
+			return database.OrganizationInvite{}, errDuplicateKey
+		}
+	}
+	//nolint:gosimple
+	invite := database.OrganizationInvite{
+		ID:             arg.ID,
+		OrganizationID: arg.OrganizationID,
+		HashedToken:    arg.HashedToken,
+		EmailDomain:    arg.EmailDomain,
+		Roles:          arg.Roles,
+		CreatedBy:      arg.CreatedBy,
+		CreatedAt:      arg.CreatedAt,
+		ExpiresAt:      arg.ExpiresAt,
+		MaxUses:        arg.MaxUses,
+	}
+	q.organizationInvites = append(q.organizationInvites, invite)
+	return invite, nil
+}
+
+func (q *fakeQuerier) UseOrganizationInvite(_ context.Context, id uuid.UUID) (database.OrganizationInvite, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, invite := range q.organizationInvites {
+		if invite.ID != id {
+			continue
+		}
+		if invite.MaxUses > 0 && invite.Uses >= invite.MaxUses {
+			return database.OrganizationInvite{}, sql.ErrNoRows
+		}
+		invite.Uses++
+		q.organizationInvites[i] = invite
+		return invite, nil
+	}
+	return database.OrganizationInvite{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOrganizationInvitesByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.OrganizationInvite, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	invites := make([]database.OrganizationInvite, 0)
+	for _, invite := range q.organizationInvites {
+		if invite.OrganizationID == organizationID {
+			invites = append(invites, invite)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.Before(invites[j].CreatedAt)
+	})
+	return invites, nil
+}
+
+func (q *fakeQuerier) GetOrganizationInviteByID(_ context.Context, id uuid.UUID) (database.OrganizationInvite, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, invite := range q.organizationInvites {
+		if invite.ID == id {
+			return invite, nil
+		}
+	}
+	return database.OrganizationInvite{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOrganizationInviteByHashedToken(_ context.Context, hashedToken []byte) (database.OrganizationInvite, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, invite := range q.organizationInvites {
+		if string(invite.HashedToken) == string(hashedToken) {
+			return invite, nil
+		}
+	}
+	return database.OrganizationInvite{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteOrganizationInviteByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, invite := range q.organizationInvites {
+		if invite.ID == id {
+			q.organizationInvites = append(q.organizationInvites[:i], q.organizationInvites[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOrganizationQuotaByOrganizationID(_ context.Context, organizationID uuid.UUID) (database.OrganizationQuota, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, quota := range q.organizationQuotas {
+		if quota.OrganizationID == organizationID {
+			return quota, nil
+		}
+	}
+	return database.OrganizationQuota{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertOrganizationQuota(_ context.Context, arg database.UpsertOrganizationQuotaParams) (database.OrganizationQuota, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	quota := database.OrganizationQuota{
+		OrganizationID:       arg.OrganizationID,
+		MaxWorkspaces:        arg.MaxWorkspaces,
+		MaxRunningWorkspaces: arg.MaxRunningWorkspaces,
+		ComputeCredits:       arg.ComputeCredits,
+		UpdatedAt:            arg.UpdatedAt,
+	}
+	for i, existing := range q.organizationQuotas {
+		if existing.OrganizationID == arg.OrganizationID {
+			q.organizationQuotas[i] = quota
+			return quota, nil
+		}
+	}
+	q.organizationQuotas = append(q.organizationQuotas, quota)
+	return quota, nil
+}
+
+func (q *fakeQuerier) GetOrganizationQuotaConsumption(_ context.Context, organizationID uuid.UUID) ([]database.GetOrganizationQuotaConsumptionRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	latestBuilds := make(map[uuid.UUID]database.WorkspaceBuild)
+	for _, build := range q.workspaceBuilds {
+		if latest, ok := latestBuilds[build.WorkspaceID]; !ok || build.BuildNumber > latest.BuildNumber {
+			latestBuilds[build.WorkspaceID] = build
+		}
+	}
+
+	counts := make(map[uuid.UUID]*database.GetOrganizationQuotaConsumptionRow)
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID != organizationID || workspace.Deleted {
+			continue
+		}
+		row, ok := counts[workspace.TemplateID]
+		if !ok {
+			row = &database.GetOrganizationQuotaConsumptionRow{}
+			counts[workspace.TemplateID] = row
+		}
+		row.WorkspaceCount++
+		if build, ok := latestBuilds[workspace.ID]; ok && build.Transition == database.WorkspaceTransitionStart {
+			row.RunningWorkspaceCount++
+		}
+	}
+
+	rows := make([]database.GetOrganizationQuotaConsumptionRow, 0, len(counts))
+	for _, template := range q.templates {
+		row, ok := counts[template.ID]
+		if !ok {
+			continue
+		}
+		row.TemplateID = template.ID
+		row.TemplateName = template.Name
+		row.QuotaWeight = template.QuotaWeight
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b database.GetOrganizationQuotaConsumptionRow) bool {
+		return a.TemplateName < b.TemplateName
+	})
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetOrganizationAliasByName(_ context.Context, name string) (database.OrganizationAlias, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, alias := range q.organizationAliases {
+		if strings.EqualFold(alias.Name, name) {
+			return alias, nil
+		}
+	}
+	return database.OrganizationAlias{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) InsertOrganizationAlias(_ context.Context, arg database.InsertOrganizationAliasParams) (database.OrganizationAlias, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, alias := range q.organizationAliases {
+		if strings.EqualFold(alias.Name, arg.Name) {
+			return database.OrganizationAlias{}, errDuplicateKey
+		}
+	}
+	alias := database.OrganizationAlias{
+		Name:           arg.Name,
+		OrganizationID: arg.OrganizationID,
+		CreatedAt:      arg.CreatedAt,
+	}
+	q.organizationAliases = append(q.organizationAliases, alias)
+	return alias, nil
+}
+
+func (q *fakeQuerier) DeleteOrganizationAliasByName(_ context.Context, name string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, alias := range q.organizationAliases {
+		if strings.EqualFold(alias.Name, name) {
+			q.organizationAliases = append(q.organizationAliases[:i], q.organizationAliases[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetOrganizationOIDCConfigByOrganizationID(_ context.Context, organizationID uuid.UUID) (database.OrganizationOIDCConfig, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, config := range q.organizationOIDC {
+		if config.OrganizationID == organizationID {
+			return config, nil
+		}
+	}
+	return database.OrganizationOIDCConfig{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOrganizationOIDCConfigByEmailDomain(_ context.Context, emailDomain string) (database.OrganizationOIDCConfig, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	emailDomain = strings.ToLower(emailDomain)
+	for _, config := range q.organizationOIDC {
+		if slices.Contains(config.EmailDomains, emailDomain) {
+			return config, nil
+		}
+	}
+	return database.OrganizationOIDCConfig{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertOrganizationOIDCConfig(_ context.Context, arg database.UpsertOrganizationOIDCConfigParams) (database.OrganizationOIDCConfig, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	config := database.OrganizationOIDCConfig{
+		OrganizationID: arg.OrganizationID,
+		IssuerURL:      arg.IssuerURL,
+		ClientID:       arg.ClientID,
+		ClientSecret:   arg.ClientSecret,
+		Scopes:         arg.Scopes,
+		EmailDomains:   arg.EmailDomains,
+		UsernameField:  arg.UsernameField,
+		EmailField:     arg.EmailField,
+		AllowSignups:   arg.AllowSignups,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.UpdatedAt,
+	}
+	for i, existing := range q.organizationOIDC {
+		if existing.OrganizationID == arg.OrganizationID {
+			config.CreatedAt = existing.CreatedAt
+			q.organizationOIDC[i] = config
+			return config, nil
+		}
+	}
+	q.organizationOIDC = append(q.organizationOIDC, config)
+	return config, nil
+}
+
+func (q *fakeQuerier) DeleteOrganizationOIDCConfigByOrganizationID(_ context.Context, organizationID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, config := range q.organizationOIDC {
+		if config.OrganizationID == organizationID {
+			q.organizationOIDC = append(q.organizationOIDC[:i], q.organizationOIDC[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) InsertOrganizationWebhook(_ context.Context, arg database.InsertOrganizationWebhookParams) (database.OrganizationWebhook, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	webhook := database.OrganizationWebhook{
+		ID:             arg.ID,
+		OrganizationID: arg.OrganizationID,
+		Url:            arg.Url,
+		Secret:         arg.Secret,
+		Events:         arg.Events,
+		CreatedAt:      arg.CreatedAt,
+	}
+	q.organizationWebhooks = append(q.organizationWebhooks, webhook)
+	return webhook, nil
+}
+
+func (q *fakeQuerier) GetOrganizationWebhookByID(_ context.Context, id uuid.UUID) (database.OrganizationWebhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, webhook := range q.organizationWebhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return database.OrganizationWebhook{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOrganizationWebhooksByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.OrganizationWebhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	webhooks := make([]database.OrganizationWebhook, 0)
+	for _, webhook := range q.organizationWebhooks {
+		if webhook.OrganizationID == organizationID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+func (q *fakeQuerier) DeleteOrganizationWebhookByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, webhook := range q.organizationWebhooks {
+		if webhook.ID == id {
+			q.organizationWebhooks = append(q.organizationWebhooks[:i], q.organizationWebhooks[i+1:]...)
+			break
+		}
+	}
+	deliveries := make([]database.OrganizationWebhookDelivery, 0, len(q.organizationWebhookDeliveries))
+	for _, delivery := range q.organizationWebhookDeliveries {
+		if delivery.WebhookID != id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	q.organizationWebhookDeliveries = deliveries
+	return nil
+}
+
+func (q *fakeQuerier) InsertOrganizationWebhookDelivery(_ context.Context, arg database.InsertOrganizationWebhookDeliveryParams) (database.OrganizationWebhookDelivery, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	delivery := database.OrganizationWebhookDelivery{
+		ID:         arg.ID,
+		WebhookID:  arg.WebhookID,
+		EventID:    arg.EventID,
+		EventType:  arg.EventType,
+		Attempt:    arg.Attempt,
+		StatusCode: arg.StatusCode,
+		Error:      arg.Error,
+		CreatedAt:  arg.CreatedAt,
+	}
+	q.organizationWebhookDeliveries = append(q.organizationWebhookDeliveries, delivery)
+	return delivery, nil
+}
+
+func (q *fakeQuerier) GetOrganizationWebhookDeliveriesByWebhookID(_ context.Context, arg database.GetOrganizationWebhookDeliveriesByWebhookIDParams) ([]database.OrganizationWebhookDelivery, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	deliveries := make([]database.OrganizationWebhookDelivery, 0)
+	for _, delivery := range q.organizationWebhookDeliveries {
+		if delivery.WebhookID == arg.WebhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > int(arg.Limit) {
+		deliveries = deliveries[:arg.Limit]
+	}
+	return deliveries, nil
+}
+
+func (q *fakeQuerier) InsertWebhook(_ context.Context, arg database.InsertWebhookParams) (database.Webhook, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	webhook := database.Webhook{
+		ID:        arg.ID,
+		Url:       arg.Url,
+		Secret:    arg.Secret,
+		Events:    arg.Events,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.webhooks = append(q.webhooks, webhook)
+	return webhook, nil
+}
+
+func (q *fakeQuerier) GetWebhookByID(_ context.Context, id uuid.UUID) (database.Webhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, webhook := range q.webhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return database.Webhook{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWebhooks(_ context.Context) ([]database.Webhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	webhooks := make([]database.Webhook, len(q.webhooks))
+	copy(webhooks, q.webhooks)
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+func (q *fakeQuerier) DeleteWebhookByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, webhook := range q.webhooks {
+		if webhook.ID == id {
+			q.webhooks = append(q.webhooks[:i], q.webhooks[i+1:]...)
+			break
+		}
+	}
+	deliveries := make([]database.WebhookDelivery, 0, len(q.webhookDeliveries))
+	for _, delivery := range q.webhookDeliveries {
+		if delivery.WebhookID != id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	q.webhookDeliveries = deliveries
+	return nil
+}
+
+func (q *fakeQuerier) InsertWebhookDelivery(_ context.Context, arg database.InsertWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	delivery := database.WebhookDelivery{
+		ID:         arg.ID,
+		WebhookID:  arg.WebhookID,
+		EventID:    arg.EventID,
+		EventType:  arg.EventType,
+		Payload:    arg.Payload,
+		Attempt:    arg.Attempt,
+		Redelivery: arg.Redelivery,
+		StatusCode: arg.StatusCode,
+		Error:      arg.Error,
+		CreatedAt:  arg.CreatedAt,
+	}
+	q.webhookDeliveries = append(q.webhookDeliveries, delivery)
+	return delivery, nil
+}
+
+func (q *fakeQuerier) GetWebhookDeliveryByID(_ context.Context, id uuid.UUID) (database.WebhookDelivery, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, delivery := range q.webhookDeliveries {
+		if delivery.ID == id {
+			return delivery, nil
+		}
+	}
+	return database.WebhookDelivery{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWebhookDeliveriesByWebhookID(_ context.Context, arg database.GetWebhookDeliveriesByWebhookIDParams) ([]database.WebhookDelivery, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	deliveries := make([]database.WebhookDelivery, 0)
+	for _, delivery := range q.webhookDeliveries {
+		if delivery.WebhookID == arg.WebhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > int(arg.Limit) {
+		deliveries = deliveries[:arg.Limit]
+	}
+	return deliveries, nil
+}
+
+func (q *fakeQuerier) InsertWebhookQueue(_ context.Context, arg database.InsertWebhookQueueParams) (database.WebhookQueue, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	item := database.WebhookQueue{
+		ID:        arg.ID,
+		Kind:      arg.Kind,
+		WebhookID: arg.WebhookID,
+		EventID:   arg.EventID,
+		EventType: arg.EventType,
+		Payload:   arg.Payload,
+		RunAt:     arg.RunAt,
+		CreatedAt: arg.RunAt,
+	}
+	q.webhookQueue = append(q.webhookQueue, item)
+	return item, nil
+}
+
+func (q *fakeQuerier) AcquireWebhookQueue(_ context.Context, arg database.AcquireWebhookQueueParams) ([]database.WebhookQueue, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	due := make([]int, 0)
+	for i, item := range q.webhookQueue {
+		if item.RunAt.After(arg.Now) || !slices.Contains(arg.Kinds, item.Kind) {
+			continue
+		}
+		due = append(due, i)
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return q.webhookQueue[due[i]].RunAt.Before(q.webhookQueue[due[j]].RunAt)
+	})
+	if len(due) > int(arg.LimitOpt) {
+		due = due[:arg.LimitOpt]
+	}
+	items := make([]database.WebhookQueue, 0, len(due))
+	for _, i := range due {
+		q.webhookQueue[i].RunAt = arg.LeaseUntil
+		items = append(items, q.webhookQueue[i])
+	}
+	return items, nil
+}
+
+func (q *fakeQuerier) UpdateWebhookQueueByID(_ context.Context, arg database.UpdateWebhookQueueByIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, item := range q.webhookQueue {
+		if item.ID == arg.ID {
+			q.webhookQueue[i].Attempts = arg.Attempts
+			q.webhookQueue[i].RunAt = arg.RunAt
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteWebhookQueueByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, item := range q.webhookQueue {
+		if item.ID == id {
+			q.webhookQueue = append(q.webhookQueue[:i], q.webhookQueue[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetAgentStatUsersByOrganizationID(_ context.Context, arg database.GetAgentStatUsersByOrganizationIDParams) ([]database.GetAgentStatUsersByOrganizationIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	templateIDs := map[uuid.UUID]struct{}{}
+	for _, template := range q.templates {
+		if template.OrganizationID == arg.OrganizationID {
+			templateIDs[template.ID] = struct{}{}
+		}
+	}
+
+	seen := map[database.GetAgentStatUsersByOrganizationIDRow]struct{}{}
+	rows := make([]database.GetAgentStatUsersByOrganizationIDRow, 0)
+	for _, stat := range q.agentStats {
+		if _, ok := templateIDs[stat.TemplateID]; !ok {
+			continue
+		}
+		if stat.CreatedAt.Before(arg.StartTime) || !stat.CreatedAt.Before(arg.EndTime) {
+			continue
+		}
+		row := database.GetAgentStatUsersByOrganizationIDRow{
+			TemplateID: stat.TemplateID,
+			UserID:     stat.UserID,
+		}
+		if _, ok := seen[row]; ok {
+			continue
+		}
+		seen[row] = struct{}{}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceBuildsByOrganizationID(_ context.Context, arg database.GetWorkspaceBuildsByOrganizationIDParams) ([]database.GetWorkspaceBuildsByOrganizationIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := map[uuid.UUID]database.Workspace{}
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID == arg.OrganizationID {
+			workspaces[workspace.ID] = workspace
+		}
+	}
+	// The last build of each workspace before the start time.
+	firstBuildNumbers := map[uuid.UUID]int32{}
+	for _, build := range q.workspaceBuilds {
+		if build.CreatedAt.Before(arg.StartTime) && build.BuildNumber > firstBuildNumbers[build.WorkspaceID] {
+			firstBuildNumbers[build.WorkspaceID] = build.BuildNumber
+		}
+	}
+
+	builds := make([]database.WorkspaceBuild, 0)
+	for _, build := range q.workspaceBuilds {
+		if _, ok := workspaces[build.WorkspaceID]; !ok {
+			continue
+		}
+		if !build.CreatedAt.Before(arg.EndTime) || build.BuildNumber < firstBuildNumbers[build.WorkspaceID] {
+			continue
+		}
+		builds = append(builds, build)
+	}
+	sort.Slice(builds, func(i, j int) bool {
+		if builds[i].WorkspaceID != builds[j].WorkspaceID {
+			return builds[i].WorkspaceID.String() < builds[j].WorkspaceID.String()
+		}
+		return builds[i].BuildNumber < builds[j].BuildNumber
+	})
+
+	rows := make([]database.GetWorkspaceBuildsByOrganizationIDRow, 0, len(builds))
+	for _, build := range builds {
+		row := database.GetWorkspaceBuildsByOrganizationIDRow{
+			WorkspaceID: build.WorkspaceID,
+			TemplateID:  workspaces[build.WorkspaceID].TemplateID,
+			Transition:  build.Transition,
+			CreatedAt:   build.CreatedAt,
+		}
+		for _, job := range q.provisionerJobs {
+			if job.ID == build.JobID {
+				row.CompletedAt = job.CompletedAt
+				row.Error = job.Error
+				break
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetOrganizationTemplateDefaults(_ context.Context, organizationID uuid.UUID) (database.OrganizationTemplateDefault, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, defaults := range q.organizationTemplateDefaults {
+		if defaults.OrganizationID == organizationID {
+			return defaults, nil
+		}
+	}
+	return database.OrganizationTemplateDefault{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertOrganizationTemplateDefaults(_ context.Context, arg database.UpsertOrganizationTemplateDefaultsParams) (database.OrganizationTemplateDefault, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	defaults := database.OrganizationTemplateDefault{
+		OrganizationID:         arg.OrganizationID,
+		DefaultTtl:             arg.DefaultTtl,
+		MaxTtl:                 arg.MaxTtl,
+		MinAutostartInterval:   arg.MinAutostartInterval,
+		ParameterValues:        arg.ParameterValues,
+		AllowedParameterValues: arg.AllowedParameterValues,
+		UpdatedAt:              arg.UpdatedAt,
+	}
+	for i, existing := range q.organizationTemplateDefaults {
+		if existing.OrganizationID == arg.OrganizationID {
+			q.organizationTemplateDefaults[i] = defaults
+			return defaults, nil
+		}
+	}
+	q.organizationTemplateDefaults = append(q.organizationTemplateDefaults, defaults)
+	return defaults, nil
+}
+
+func (q *fakeQuerier) GetTemplateMaintenanceWindowByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateMaintenanceWindow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, window := range q.templateMaintenanceWindows {
+		if window.TemplateID == templateID {
+			return window, nil
+		}
+	}
+	return database.TemplateMaintenanceWindow{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateMaintenanceWindowsByTemplateIDs(_ context.Context, templateIDs []uuid.UUID) ([]database.TemplateMaintenanceWindow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	windows := make([]database.TemplateMaintenanceWindow, 0)
+	for _, window := range q.templateMaintenanceWindows {
+		if slices.Contains(templateIDs, window.TemplateID) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+func (q *fakeQuerier) GetTemplateMaintenanceWindows(_ context.Context) ([]database.TemplateMaintenanceWindow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	windows := make([]database.TemplateMaintenanceWindow, 0)
+	for _, window := range q.templateMaintenanceWindows {
+		for _, template := range q.templates {
+			if template.ID == window.TemplateID && !template.Deleted {
+				windows = append(windows, window)
+				break
+			}
+		}
+	}
+	return windows, nil
+}
+
+func (q *fakeQuerier) UpsertTemplateMaintenanceWindow(_ context.Context, arg database.UpsertTemplateMaintenanceWindowParams) (database.TemplateMaintenanceWindow, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	window := database.TemplateMaintenanceWindow{
+		TemplateID:     arg.TemplateID,
+		Schedule:       arg.Schedule,
+		Duration:       arg.Duration,
+		ExemptGroupIds: arg.ExemptGroupIds,
+		UpdatedAt:      arg.UpdatedAt,
+	}
+	for i, existing := range q.templateMaintenanceWindows {
+		if existing.TemplateID == arg.TemplateID {
+			q.templateMaintenanceWindows[i] = window
+			return window, nil
+		}
+	}
+	q.templateMaintenanceWindows = append(q.templateMaintenanceWindows, window)
+	return window, nil
+}
+
+func (q *fakeQuerier) DeleteTemplateMaintenanceWindowByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, window := range q.templateMaintenanceWindows {
+		if window.TemplateID == templateID {
+			q.templateMaintenanceWindows = append(q.templateMaintenanceWindows[:i], q.templateMaintenanceWindows[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetTemplateAutostopExtensionPolicyByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateAutostopExtensionPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.templateExtensionPolicies {
+		if policy.TemplateID == templateID {
+			return policy, nil
+		}
+	}
+	return database.TemplateAutostopExtensionPolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertTemplateAutostopExtensionPolicy(_ context.Context, arg database.UpsertTemplateAutostopExtensionPolicyParams) (database.TemplateAutostopExtensionPolicy, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	policy := database.TemplateAutostopExtensionPolicy{
+		TemplateID:          arg.TemplateID,
+		MaxExtensionsPerDay: arg.MaxExtensionsPerDay,
+		MaxExtensionPerDay:  arg.MaxExtensionPerDay,
+		UpdatedAt:           arg.UpdatedAt,
+	}
+	for i, existing := range q.templateExtensionPolicies {
+		if existing.TemplateID == arg.TemplateID {
+			q.templateExtensionPolicies[i] = policy
+			return policy, nil
+		}
+	}
+	q.templateExtensionPolicies = append(q.templateExtensionPolicies, policy)
+	return policy, nil
+}
+
+func (q *fakeQuerier) DeleteTemplateAutostopExtensionPolicyByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, policy := range q.templateExtensionPolicies {
+		if policy.TemplateID == templateID {
+			q.templateExtensionPolicies = append(q.templateExtensionPolicies[:i], q.templateExtensionPolicies[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetWorkspaceAutostopExtensionsByWorkspaceID(_ context.Context, arg database.GetWorkspaceAutostopExtensionsByWorkspaceIDParams) ([]database.WorkspaceAutostopExtension, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	extensions := make([]database.WorkspaceAutostopExtension, 0)
+	for _, extension := range q.workspaceAutostopExtensions {
+		if extension.WorkspaceID == arg.WorkspaceID && extension.CreatedAt.After(arg.CreatedAfter) {
+			extensions = append(extensions, extension)
+		}
+	}
+	slices.SortFunc(extensions, func(a, b database.WorkspaceAutostopExtension) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return extensions, nil
+}
+
+func (q *fakeQuerier) InsertWorkspaceAutostopExtension(_ context.Context, arg database.InsertWorkspaceAutostopExtensionParams) (database.WorkspaceAutostopExtension, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	extension := database.WorkspaceAutostopExtension{
+		ID:          arg.ID,
+		WorkspaceID: arg.WorkspaceID,
+		UserID:      arg.UserID,
+		Extension:   arg.Extension,
+		CreatedAt:   arg.CreatedAt,
+	}
+	q.workspaceAutostopExtensions = append(q.workspaceAutostopExtensions, extension)
+	return extension, nil
+}
+
+func (q *fakeQuerier) GetTemplateArchivePolicies(_ context.Context) ([]database.TemplateArchivePolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	policies := make([]database.TemplateArchivePolicy, len(q.templateArchivePolicies))
+	copy(policies, q.templateArchivePolicies)
+	return policies, nil
+}
+
+func (q *fakeQuerier) GetTemplateArchivePolicyByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateArchivePolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.templateArchivePolicies {
+		if policy.TemplateID == templateID {
+			return policy, nil
+		}
+	}
+	return database.TemplateArchivePolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertTemplateArchivePolicy(_ context.Context, arg database.UpsertTemplateArchivePolicyParams) (database.TemplateArchivePolicy, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	policy := database.TemplateArchivePolicy{
+		TemplateID:        arg.TemplateID,
+		DormancyThreshold: arg.DormancyThreshold,
+		Retention:         arg.Retention,
+		UpdatedAt:         arg.UpdatedAt,
+	}
+	for i, existing := range q.templateArchivePolicies {
+		if existing.TemplateID == arg.TemplateID {
+			q.templateArchivePolicies[i] = policy
+			return policy, nil
+		}
+	}
+	q.templateArchivePolicies = append(q.templateArchivePolicies, policy)
+	return policy, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceArchiveByWorkspaceID(_ context.Context, workspaceID uuid.UUID) (database.WorkspaceArchive, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, archive := range q.workspaceArchives {
+		if archive.WorkspaceID == workspaceID {
+			return archive, nil
+		}
+	}
+	return database.WorkspaceArchive{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspaceArchivesByStatus(_ context.Context, status database.WorkspaceArchiveStatus) ([]database.WorkspaceArchive, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	archives := make([]database.WorkspaceArchive, 0)
+	for _, archive := range q.workspaceArchives {
+		if archive.Status == status {
+			archives = append(archives, archive)
+		}
+	}
+	return archives, nil
+}
+
+func (q *fakeQuerier) GetPurgeableWorkspaceArchives(_ context.Context, now time.Time) ([]database.WorkspaceArchive, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	archives := make([]database.WorkspaceArchive, 0)
+	for _, archive := range q.workspaceArchives {
+		expired := archive.Status == database.WorkspaceArchiveStatusArchived &&
+			archive.ExpiresAt.Valid && !archive.ExpiresAt.Time.After(now)
+		for _, workspace := range q.workspaces {
+			if workspace.ID == archive.WorkspaceID && (workspace.Deleted || expired) {
+				archives = append(archives, archive)
+			}
+		}
+	}
+	return archives, nil
+}
+
+func (q *fakeQuerier) UpsertWorkspaceArchive(_ context.Context, arg database.UpsertWorkspaceArchiveParams) (database.WorkspaceArchive, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	archive := database.WorkspaceArchive{
+		WorkspaceID: arg.WorkspaceID,
+		Status:      arg.Status,
+		ObjectKey:   arg.ObjectKey,
+		BuildID:     arg.BuildID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.CreatedAt,
+	}
+	for i, existing := range q.workspaceArchives {
+		if existing.WorkspaceID == arg.WorkspaceID {
+			q.workspaceArchives[i] = archive
+			return archive, nil
+		}
+	}
+	q.workspaceArchives = append(q.workspaceArchives, archive)
+	return archive, nil
+}
+
+func (q *fakeQuerier) UpdateWorkspaceArchiveByWorkspaceID(_ context.Context, arg database.UpdateWorkspaceArchiveByWorkspaceIDParams) (database.WorkspaceArchive, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, archive := range q.workspaceArchives {
+		if archive.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		archive.Status = arg.Status
+		archive.BuildID = arg.BuildID
+		archive.Error = arg.Error
+		archive.UpdatedAt = arg.UpdatedAt
+		archive.ArchivedAt = arg.ArchivedAt
+		archive.ExpiresAt = arg.ExpiresAt
+		q.workspaceArchives[i] = archive
+		return archive, nil
+	}
+	return database.WorkspaceArchive{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteWorkspaceArchiveByWorkspaceID(_ context.Context, workspaceID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, archive := range q.workspaceArchives {
+		if archive.WorkspaceID == workspaceID {
+			q.workspaceArchives = append(q.workspaceArchives[:i], q.workspaceArchives[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetTemplateResourceCostsByTemplateID(_ context.Context, templateID uuid.UUID) ([]database.TemplateResourceCost, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	costs := make([]database.TemplateResourceCost, 0)
+	for _, cost := range q.templateResourceCosts {
+		if cost.TemplateID == templateID {
+			costs = append(costs, cost)
+		}
+	}
+	slices.SortFunc(costs, func(a, b database.TemplateResourceCost) bool {
+		return a.ResourceType < b.ResourceType
+	})
+	return costs, nil
+}
+
+func (q *fakeQuerier) GetTemplateResourceCosts(_ context.Context) ([]database.TemplateResourceCost, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	costs := make([]database.TemplateResourceCost, 0)
+	for _, cost := range q.templateResourceCosts {
+		for _, template := range q.templates {
+			if template.ID == cost.TemplateID && !template.Deleted {
+				costs = append(costs, cost)
+				break
+			}
+		}
+	}
+	return costs, nil
+}
+
+func (q *fakeQuerier) InsertTemplateResourceCost(_ context.Context, arg database.InsertTemplateResourceCostParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, cost := range q.templateResourceCosts {
+		if cost.TemplateID == arg.TemplateID && cost.ResourceType == arg.ResourceType {
+			return errDuplicateKey
+		}
+	}
+	//nolint:gosimple
+	q.templateResourceCosts = append(q.templateResourceCosts, database.TemplateResourceCost{
+		TemplateID:   arg.TemplateID,
+		ResourceType: arg.ResourceType,
+		HourlyCost:   arg.HourlyCost,
+	})
+	return nil
+}
+
+func (q *fakeQuerier) DeleteTemplateResourceCostsByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	costs := make([]database.TemplateResourceCost, 0, len(q.templateResourceCosts))
+	for _, cost := range q.templateResourceCosts {
+		if cost.TemplateID != templateID {
+			costs = append(costs, cost)
+		}
+	}
+	q.templateResourceCosts = costs
+	return nil
+}
+
+func (q *fakeQuerier) AccrueWorkspaceCost(_ context.Context, arg database.AccrueWorkspaceCostParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, cost := range q.workspaceCosts {
+		if cost.WorkspaceID != arg.WorkspaceID || cost.OwnerID != arg.OwnerID || !cost.StartTime.Equal(arg.StartTime) {
+			continue
+		}
+		if !cost.AccruedUntil.Before(arg.AccruedUntil) {
+			return nil
+		}
+		cost.Cost += arg.Cost
+		cost.RunningSeconds += arg.RunningSeconds
+		cost.AccruedUntil = arg.AccruedUntil
+		q.workspaceCosts[i] = cost
+		return nil
+	}
+	//nolint:gosimple
+	q.workspaceCosts = append(q.workspaceCosts, database.WorkspaceCost{
+		WorkspaceID:    arg.WorkspaceID,
+		OwnerID:        arg.OwnerID,
+		OrganizationID: arg.OrganizationID,
+		TemplateID:     arg.TemplateID,
+		StartTime:      arg.StartTime,
+		Cost:           arg.Cost,
+		RunningSeconds: arg.RunningSeconds,
+		AccruedUntil:   arg.AccruedUntil,
+	})
+	return nil
+}
+
+func (q *fakeQuerier) GetWorkspaceCostsAccruedUntil(_ context.Context, workspaceIDs []uuid.UUID) ([]database.GetWorkspaceCostsAccruedUntilRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	accruedUntil := make(map[uuid.UUID]time.Time)
+	for _, cost := range q.workspaceCosts {
+		if !slices.Contains(workspaceIDs, cost.WorkspaceID) {
+			continue
+		}
+		if cost.AccruedUntil.After(accruedUntil[cost.WorkspaceID]) {
+			accruedUntil[cost.WorkspaceID] = cost.AccruedUntil
+		}
+	}
+	rows := make([]database.GetWorkspaceCostsAccruedUntilRow, 0, len(accruedUntil))
+	for workspaceID, until := range accruedUntil {
+		rows = append(rows, database.GetWorkspaceCostsAccruedUntilRow{
+			WorkspaceID:  workspaceID,
+			AccruedUntil: until,
+		})
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceCostsByOrganizationID(_ context.Context, arg database.GetWorkspaceCostsByOrganizationIDParams) ([]database.GetWorkspaceCostsByOrganizationIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type key struct {
+		ownerID    uuid.UUID
+		templateID uuid.UUID
+	}
+	sums := make(map[key]*database.GetWorkspaceCostsByOrganizationIDRow)
+	for _, cost := range q.workspaceCosts {
+		if cost.OrganizationID != arg.OrganizationID || cost.StartTime.Before(arg.StartTime) || !cost.StartTime.Before(arg.EndTime) {
+			continue
+		}
+		k := key{ownerID: cost.OwnerID, templateID: cost.TemplateID}
+		row, ok := sums[k]
+		if !ok {
+			row = &database.GetWorkspaceCostsByOrganizationIDRow{
+				OwnerID:    cost.OwnerID,
+				TemplateID: cost.TemplateID,
+			}
+			sums[k] = row
+		}
+		row.Cost += cost.Cost
+		row.RunningSeconds += cost.RunningSeconds
+	}
+	rows := make([]database.GetWorkspaceCostsByOrganizationIDRow, 0, len(sums))
+	for _, row := range sums {
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b database.GetWorkspaceCostsByOrganizationIDRow) bool {
+		if a.OwnerID != b.OwnerID {
+			return a.OwnerID.String() < b.OwnerID.String()
+		}
+		return a.TemplateID.String() < b.TemplateID.String()
+	})
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetOrganizationIPAllowlist(_ context.Context, organizationID uuid.UUID) (database.OrganizationIpAllowlist, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, allowlist := range q.organizationIPAllowlists {
+		if allowlist.OrganizationID == organizationID {
+			return allowlist, nil
+		}
+	}
+	return database.OrganizationIpAllowlist{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertOrganizationIPAllowlist(_ context.Context, arg database.UpsertOrganizationIPAllowlistParams) (database.OrganizationIpAllowlist, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	allowlist := database.OrganizationIpAllowlist{
+		OrganizationID: arg.OrganizationID,
+		Cidrs:          arg.Cidrs,
+		UpdatedAt:      arg.UpdatedAt,
+	}
+	for i, existing := range q.organizationIPAllowlists {
+		if existing.OrganizationID == arg.OrganizationID {
+			q.organizationIPAllowlists[i] = allowlist
+			return allowlist, nil
+		}
+	}
+	q.organizationIPAllowlists = append(q.organizationIPAllowlists, allowlist)
+	return allowlist, nil
+}
+
+func (q *fakeQuerier) UpdateWorkspaceOrganization(_ context.Context, arg database.UpdateWorkspaceOrganizationParams) (database.Workspace, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.ID != arg.ID {
+			continue
+		}
+		workspace.OrganizationID = arg.OrganizationID
+		workspace.TemplateID = arg.TemplateID
+		workspace.UpdatedAt = arg.UpdatedAt
+		q.workspaces[i] = workspace
+		return workspace, nil
+	}
+	return database.Workspace{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceOwner(_ context.Context, arg database.UpdateWorkspaceOwnerParams) (database.Workspace, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.ID != arg.ID {
+			continue
+		}
+		workspace.OwnerID = arg.OwnerID
+		workspace.UpdatedAt = arg.UpdatedAt
+		q.workspaces[i] = workspace
+		return workspace, nil
+	}
+	return database.Workspace{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceLabels(_ context.Context, arg database.UpdateWorkspaceLabelsParams) (database.Workspace, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.ID != arg.ID {
+			continue
+		}
+		workspace.Labels = arg.Labels
+		workspace.UpdatedAt = arg.UpdatedAt
+		q.workspaces[i] = workspace
+		return workspace, nil
+	}
+	return database.Workspace{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceLabelsByTemplateID(_ context.Context, arg database.UpdateWorkspaceLabelsByTemplateIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var templateLabels map[string]string
+	err := json.Unmarshal(arg.Labels, &templateLabels)
+	if err != nil {
+		return err
+	}
+	for i, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.TemplateID != arg.TemplateID {
+			continue
+		}
+		labels := map[string]string{}
+		_ = json.Unmarshal(workspace.Labels, &labels)
+		for _, key := range arg.RemovedKeys {
+			delete(labels, key)
+		}
+		for key, value := range templateLabels {
+			labels[key] = value
+		}
+		raw, err := json.Marshal(labels)
+		if err != nil {
+			return err
+		}
+		workspace.Labels = raw
+		q.workspaces[i] = workspace
+	}
+	return nil
+}
+
+func (q *fakeQuerier) UpdateProvisionerJobsOrganizationByWorkspaceID(_ context.Context, arg database.UpdateProvisionerJobsOrganizationByWorkspaceIDParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	jobIDs := map[uuid.UUID]struct{}{}
+	for _, build := range q.workspaceBuilds {
+		if build.WorkspaceID == arg.WorkspaceID {
+			jobIDs[build.JobID] = struct{}{}
+		}
+	}
+	for i, job := range q.provisionerJobs {
+		if _, ok := jobIDs[job.ID]; ok {
+			job.OrganizationID = arg.OrganizationID
+			q.provisionerJobs[i] = job
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetOrganizationDeletionByOrganizationID(_ context.Context, organizationID uuid.UUID) (database.OrganizationDeletion, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, deletion := range q.organizationDeletions {
+		if deletion.OrganizationID == organizationID {
+			return deletion, nil
+		}
+	}
+	return database.OrganizationDeletion{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) AcquireOrganizationDeletions(_ context.Context, arg database.AcquireOrganizationDeletionsParams) ([]database.OrganizationDeletion, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deletions := make([]database.OrganizationDeletion, 0)
+	for i, deletion := range q.organizationDeletions {
+		if deletion.Status != database.OrganizationDeletionStatusRunning {
+			continue
+		}
+		if deletion.HeartbeatAt.Valid && !deletion.HeartbeatAt.Time.Before(arg.StaleBefore) {
+			continue
+		}
+		deletion.WorkerID = uuid.NullUUID{UUID: arg.WorkerID, Valid: true}
+		deletion.HeartbeatAt = sql.NullTime{Time: arg.Now, Valid: true}
+		q.organizationDeletions[i] = deletion
+		deletions = append(deletions, deletion)
+	}
+	return deletions, nil
+}
+
+func (q *fakeQuerier) InsertOrganizationDeletion(_ context.Context, arg database.InsertOrganizationDeletionParams) (database.OrganizationDeletion, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, deletion := range q.organizationDeletions {
+		if deletion.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		if deletion.Status == database.OrganizationDeletionStatusRunning {
+			return database.OrganizationDeletion{}, sql.ErrNoRows
+		}
+		deletion.InitiatorID = arg.InitiatorID
+		deletion.Status = database.OrganizationDeletionStatusRunning
+		deletion.Error = ""
+		deletion.UpdatedAt = arg.CreatedAt
+		deletion.CompletedAt = sql.NullTime{}
+		deletion.OperationID = arg.OperationID
+		deletion.WorkerID = arg.WorkerID
+		deletion.HeartbeatAt = sql.NullTime{Time: arg.CreatedAt, Valid: true}
+		q.organizationDeletions[i] = deletion
+		return deletion, nil
+	}
+	deletion := database.OrganizationDeletion{
+		OrganizationID: arg.OrganizationID,
+		InitiatorID:    arg.InitiatorID,
+		Status:         database.OrganizationDeletionStatusRunning,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.CreatedAt,
+		OperationID:    arg.OperationID,
+		WorkerID:       arg.WorkerID,
+		HeartbeatAt:    sql.NullTime{Time: arg.CreatedAt, Valid: true},
+	}
+	q.organizationDeletions = append(q.organizationDeletions, deletion)
+	return deletion, nil
+}
+
+func (q *fakeQuerier) GetOperationByID(_ context.Context, id uuid.UUID) (database.Operation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, operation := range q.operations {
+		if operation.ID == id {
+			return operation, nil
+		}
+	}
+	return database.Operation{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOperationsByTypeAndStatus(_ context.Context, arg database.GetOperationsByTypeAndStatusParams) ([]database.Operation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	operations := make([]database.Operation, 0)
+	for _, operation := range q.operations {
+		if operation.Type == arg.Type && operation.Status == arg.Status {
+			operations = append(operations, operation)
+		}
+	}
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].CreatedAt.Before(operations[j].CreatedAt)
+	})
+	return operations, nil
+}
+
+func (q *fakeQuerier) InsertOperation(_ context.Context, arg database.InsertOperationParams) (database.Operation, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	operation := database.Operation{
+		ID:             arg.ID,
+		Type:           arg.Type,
+		Status:         database.OperationStatusRunning,
+		InitiatorID:    arg.InitiatorID,
+		OrganizationID: arg.OrganizationID,
+		ResourceID:     arg.ResourceID,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.CreatedAt,
+	}
+	q.operations = append(q.operations, operation)
+	return operation, nil
+}
+
+func (q *fakeQuerier) UpdateOperationByID(_ context.Context, arg database.UpdateOperationByIDParams) (database.Operation, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, operation := range q.operations {
+		if operation.ID != arg.ID {
+			continue
+		}
+		operation.Status = arg.Status
+		operation.ProgressCompleted = arg.ProgressCompleted
+		operation.ProgressTotal = arg.ProgressTotal
+		operation.Error = arg.Error
+		operation.UpdatedAt = arg.UpdatedAt
+		operation.CompletedAt = arg.CompletedAt
+		q.operations[i] = operation
+		return operation, nil
+	}
+	return database.Operation{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOrganizationDeletionByOrganizationID(_ context.Context, arg database.UpdateOrganizationDeletionByOrganizationIDParams) (database.OrganizationDeletion, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, deletion := range q.organizationDeletions {
+		if deletion.OrganizationID != arg.OrganizationID || !arg.WorkerID.Valid || deletion.WorkerID != arg.WorkerID {
+			continue
+		}
+		deletion.Status = arg.Status
+		deletion.Error = arg.Error
+		deletion.WorkspacesTotal = arg.WorkspacesTotal
+		deletion.WorkspacesDeleted = arg.WorkspacesDeleted
+		deletion.TemplatesTotal = arg.TemplatesTotal
+		deletion.TemplatesDeleted = arg.TemplatesDeleted
+		deletion.MembersTotal = arg.MembersTotal
+		deletion.MembersDeleted = arg.MembersDeleted
+		deletion.UpdatedAt = arg.UpdatedAt
+		deletion.CompletedAt = arg.CompletedAt
+		q.organizationDeletions[i] = deletion
+		return deletion, nil
+	}
+	return database.OrganizationDeletion{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOrganizationDeletionHeartbeat(_ context.Context, arg database.UpdateOrganizationDeletionHeartbeatParams) (database.OrganizationDeletion, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, deletion := range q.organizationDeletions {
+		if deletion.OrganizationID != arg.OrganizationID || !arg.WorkerID.Valid || deletion.WorkerID != arg.WorkerID {
+			continue
+		}
+		deletion.HeartbeatAt = arg.HeartbeatAt
+		q.organizationDeletions[i] = deletion
+		return deletion, nil
+	}
+	return database.OrganizationDeletion{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspacesByOrganizationID(_ context.Context, arg database.GetWorkspacesByOrganizationIDParams) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := make([]database.Workspace, 0)
+	for _, workspace := range q.workspaces {
+		if workspace.OrganizationID != arg.OrganizationID || workspace.Deleted != arg.Deleted {
+			continue
+		}
+		if bytes.Compare(workspace.ID[:], arg.AfterID[:]) <= 0 {
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return bytes.Compare(workspaces[i].ID[:], workspaces[j].ID[:]) < 0
+	})
+	if len(workspaces) > int(arg.LimitOpt) {
+		workspaces = workspaces[:arg.LimitOpt]
+	}
+	return workspaces, nil
+}
+
+func (q *fakeQuerier) GetTemplateCountByOrganizationID(_ context.Context, organizationID uuid.UUID) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var count int64
+	for _, template := range q.templates {
+		if template.OrganizationID == organizationID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *fakeQuerier) DeleteTemplatesByOrganizationID(_ context.Context, arg database.DeleteTemplatesByOrganizationIDParams) ([]uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deleted := make([]uuid.UUID, 0)
+	templates := make([]database.Template, 0, len(q.templates))
+	for _, template := range q.templates {
+		if template.OrganizationID == arg.OrganizationID && len(deleted) < int(arg.LimitOpt) {
+			deleted = append(deleted, template.ID)
+			continue
+		}
+		templates = append(templates, template)
+	}
+	q.templates = templates
+	versions := make([]database.TemplateVersion, 0, len(q.templateVersions))
+	for _, version := range q.templateVersions {
+		if version.TemplateID.Valid && slices.Contains(deleted, version.TemplateID.UUID) {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	q.templateVersions = versions
+	windows := make([]database.TemplateMaintenanceWindow, 0, len(q.templateMaintenanceWindows))
+	for _, window := range q.templateMaintenanceWindows {
+		if slices.Contains(deleted, window.TemplateID) {
+			continue
+		}
+		windows = append(windows, window)
+	}
+	q.templateMaintenanceWindows = windows
+	costs := make([]database.TemplateResourceCost, 0, len(q.templateResourceCosts))
+	for _, cost := range q.templateResourceCosts {
+		if slices.Contains(deleted, cost.TemplateID) {
+			continue
+		}
+		costs = append(costs, cost)
+	}
+	q.templateResourceCosts = costs
+	policies := make([]database.TemplateAutostopExtensionPolicy, 0, len(q.templateExtensionPolicies))
+	for _, policy := range q.templateExtensionPolicies {
+		if slices.Contains(deleted, policy.TemplateID) {
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	q.templateExtensionPolicies = policies
+	archivePolicies := make([]database.TemplateArchivePolicy, 0, len(q.templateArchivePolicies))
+	for _, policy := range q.templateArchivePolicies {
+		if slices.Contains(deleted, policy.TemplateID) {
+			continue
+		}
+		archivePolicies = append(archivePolicies, policy)
+	}
+	q.templateArchivePolicies = archivePolicies
+	rebuildPolicies := make([]database.TemplateAutoRebuildPolicy, 0, len(q.templateAutoRebuildPolicies))
+	for _, policy := range q.templateAutoRebuildPolicies {
+		if slices.Contains(deleted, policy.TemplateID) {
+			continue
+		}
+		rebuildPolicies = append(rebuildPolicies, policy)
+	}
+	q.templateAutoRebuildPolicies = rebuildPolicies
+	deprecations := make([]database.TemplateDeprecation, 0, len(q.templateDeprecations))
+	for _, deprecation := range q.templateDeprecations {
+		if slices.Contains(deleted, deprecation.TemplateID) {
+			continue
+		}
+		if deprecation.SuccessorID.Valid && slices.Contains(deleted, deprecation.SuccessorID.UUID) {
+			deprecation.SuccessorID = uuid.NullUUID{}
+		}
+		deprecations = append(deprecations, deprecation)
+	}
+	q.templateDeprecations = deprecations
+	return deleted, nil
+}
+
+func (q *fakeQuerier) GetOrganizationMemberCountByOrganizationID(_ context.Context, organizationID uuid.UUID) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var count int64
+	for _, member := range q.organizationMembers {
+		if member.OrganizationID == organizationID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *fakeQuerier) DeleteOrganizationMembersByOrganizationID(_ context.Context, arg database.DeleteOrganizationMembersByOrganizationIDParams) ([]uuid.UUID, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deleted := make([]uuid.UUID, 0)
+	members := make([]database.OrganizationMember, 0, len(q.organizationMembers))
+	for _, member := range q.organizationMembers {
+		if member.OrganizationID == arg.OrganizationID && len(deleted) < int(arg.LimitOpt) {
+			deleted = append(deleted, member.UserID)
+			continue
+		}
+		members = append(members, member)
+	}
+	q.organizationMembers = members
+	return deleted, nil
+}
+
+func (q *fakeQuerier) SearchGroups(_ context.Context, arg database.SearchGroupsParams) ([]database.Group, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	search := strings.ToLower(arg.Search)
+	groups := make([]database.Group, 0)
+	for _, group := range q.groups {
+		if group.DeletedAt.Valid || (group.OrganizationID.Valid && group.ID == group.OrganizationID.UUID) {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(group.Name), search) && !strings.Contains(strings.ToLower(group.DisplayName), search) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Name != groups[j].Name {
+			return groups[i].Name < groups[j].Name
+		}
+		return groups[i].ID.String() < groups[j].ID.String()
+	})
+	if arg.LimitOpt > 0 && len(groups) > int(arg.LimitOpt) {
+		groups = groups[:arg.LimitOpt]
+	}
+	return groups, nil
+}
+
+func (q *fakeQuerier) GetGroupsWithRoles(_ context.Context) ([]database.Group, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	groups := make([]database.Group, 0)
+	for _, group := range q.groups {
+		if len(group.Roles) > 0 && !group.DeletedAt.Valid {
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.OrganizationID.Valid != b.OrganizationID.Valid {
+			return !a.OrganizationID.Valid
+		}
+		if a.OrganizationID.UUID != b.OrganizationID.UUID {
+			return bytes.Compare(a.OrganizationID.UUID[:], b.OrganizationID.UUID[:]) < 0
+		}
+		return a.Name < b.Name
+	})
+	return groups, nil
+}
+
+func (q *fakeQuerier) InsertRoleRequest(_ context.Context, arg database.InsertRoleRequestParams) (database.RoleRequest, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, request := range q.roleRequests {
+		if request.UserID == arg.UserID && request.Role == arg.Role && request.Status == database.RoleRequestStatusPending {
+			return database.RoleRequest{}, errDuplicateKey
+		}
+	}
+
+	request := database.RoleRequest{
+		ID:             arg.ID,
+		UserID:         arg.UserID,
+		OrganizationID: arg.OrganizationID,
+		Role:           arg.Role,
+		Reason:         arg.Reason,
+		Status:         database.RoleRequestStatusPending,
+		CreatedAt:      arg.CreatedAt,
+	}
+	q.roleRequests = append(q.roleRequests, request)
+	return request, nil
+}
+
+func (q *fakeQuerier) GetRoleRequestByID(_ context.Context, id uuid.UUID) (database.RoleRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, request := range q.roleRequests {
+		if request.ID == id {
+			return request, nil
+		}
+	}
+	return database.RoleRequest{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetRoleRequestsByUserID(_ context.Context, userID uuid.UUID) ([]database.RoleRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	requests := make([]database.RoleRequest, 0)
+	for _, request := range q.roleRequests {
+		if request.UserID == userID {
+			requests = append(requests, request)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func (q *fakeQuerier) GetPendingRoleRequests(_ context.Context) ([]database.RoleRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	requests := make([]database.RoleRequest, 0)
+	for _, request := range q.roleRequests {
+		if request.Status == database.RoleRequestStatusPending {
+			requests = append(requests, request)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func (q *fakeQuerier) UpdateRoleRequestStatus(_ context.Context, arg database.UpdateRoleRequestStatusParams) (database.RoleRequest, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, request := range q.roleRequests {
+		if request.ID != arg.ID || request.Status != database.RoleRequestStatusPending {
+			continue
+		}
+		request.Status = arg.Status
+		request.ReviewedBy = arg.ReviewedBy
+		request.ReviewedAt = arg.ReviewedAt
+		q.roleRequests[i] = request
+		return request, nil
+	}
+	return database.RoleRequest{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteOAuth2ProviderAppAPIKeysByAppID(_ context.Context, appID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	keyIDs := map[string]struct{}{}
+	for _, token := range q.oauth2ProviderAppTokens {
+		if token.AppID == appID {
+			keyIDs[token.APIKeyID] = struct{}{}
+		}
+	}
+	apiKeys := make([]database.APIKey, 0, len(q.apiKeys))
+	for _, apiKey := range q.apiKeys {
+		if _, ok := keyIDs[apiKey.ID]; !ok {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	q.apiKeys = apiKeys
+	tokens := make([]database.OAuth2ProviderAppToken, 0, len(q.oauth2ProviderAppTokens))
+	for _, token := range q.oauth2ProviderAppTokens {
+		if token.AppID != appID {
+			tokens = append(tokens, token)
+		}
+	}
+	q.oauth2ProviderAppTokens = tokens
+	return nil
+}
+
+func (q *fakeQuerier) DeleteOAuth2ProviderAppByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, app := range q.oauth2ProviderApps {
+		if app.ID != id {
+			continue
+		}
+		q.oauth2ProviderApps = append(q.oauth2ProviderApps[:i], q.oauth2ProviderApps[i+1:]...)
+
+		codes := make([]database.OAuth2ProviderAppCode, 0, len(q.oauth2ProviderAppCodes))
+		for _, code := range q.oauth2ProviderAppCodes {
+			if code.AppID != id {
+				codes = append(codes, code)
+			}
+		}
+		q.oauth2ProviderAppCodes = codes
+		tokens := make([]database.OAuth2ProviderAppToken, 0, len(q.oauth2ProviderAppTokens))
+		for _, token := range q.oauth2ProviderAppTokens {
+			if token.AppID != id {
+				tokens = append(tokens, token)
+			}
+		}
+		q.oauth2ProviderAppTokens = tokens
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteOAuth2ProviderAppCodeByHashedSecret(_ context.Context, hashedSecret []byte) (database.OAuth2ProviderAppCode, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, code := range q.oauth2ProviderAppCodes {
+		if !bytes.Equal(code.HashedSecret, hashedSecret) {
+			continue
+		}
+		q.oauth2ProviderAppCodes = append(q.oauth2ProviderAppCodes[:i], q.oauth2ProviderAppCodes[i+1:]...)
+		return code, nil
+	}
+	return database.OAuth2ProviderAppCode{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppByID(_ context.Context, id uuid.UUID) (database.OAuth2ProviderApp, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.ID == id {
+			return app, nil
+		}
+	}
+	return database.OAuth2ProviderApp{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderAppTokenByAPIKeyID(_ context.Context, apiKeyID string) (database.OAuth2ProviderAppToken, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, token := range q.oauth2ProviderAppTokens {
+		if token.APIKeyID == apiKeyID {
+			return token, nil
+		}
+	}
+	return database.OAuth2ProviderAppToken{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetOAuth2ProviderApps(_ context.Context) ([]database.OAuth2ProviderApp, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apps := make([]database.OAuth2ProviderApp, len(q.oauth2ProviderApps))
+	copy(apps, q.oauth2ProviderApps)
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Name < apps[j].Name
+	})
+	return apps, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderApp(_ context.Context, arg database.InsertOAuth2ProviderAppParams) (database.OAuth2ProviderApp, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.Name == arg.Name {
+			return database.OAuth2ProviderApp{}, errDuplicateKey
+		}
+	}
+	app := database.OAuth2ProviderApp{
+		ID:           arg.ID,
+		CreatedAt:    arg.CreatedAt,
+		UpdatedAt:    arg.CreatedAt,
+		Name:         arg.Name,
+		Icon:         arg.Icon,
+		CallbackURL:  arg.CallbackURL,
+		HashedSecret: arg.HashedSecret,
+	}
+	q.oauth2ProviderApps = append(q.oauth2ProviderApps, app)
+	return app, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderAppCode(_ context.Context, arg database.InsertOAuth2ProviderAppCodeParams) (database.OAuth2ProviderAppCode, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	code := database.OAuth2ProviderAppCode{
+		ID:                  arg.ID,
+		CreatedAt:           arg.CreatedAt,
+		ExpiresAt:           arg.ExpiresAt,
+		HashedSecret:        arg.HashedSecret,
+		AppID:               arg.AppID,
+		UserID:              arg.UserID,
+		RedirectURI:         arg.RedirectURI,
+		Scope:               arg.Scope,
+		CodeChallenge:       arg.CodeChallenge,
+		CodeChallengeMethod: arg.CodeChallengeMethod,
+	}
+	q.oauth2ProviderAppCodes = append(q.oauth2ProviderAppCodes, code)
+	return code, nil
+}
+
+func (q *fakeQuerier) InsertOAuth2ProviderAppToken(_ context.Context, arg database.InsertOAuth2ProviderAppTokenParams) (database.OAuth2ProviderAppToken, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	token := database.OAuth2ProviderAppToken{
+		APIKeyID:  arg.APIKeyID,
+		AppID:     arg.AppID,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.oauth2ProviderAppTokens = append(q.oauth2ProviderAppTokens, token)
+	return token, nil
+}
+
+func (q *fakeQuerier) UpdateOAuth2ProviderAppByID(_ context.Context, arg database.UpdateOAuth2ProviderAppByIDParams) (database.OAuth2ProviderApp, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.ID != arg.ID && app.Name == arg.Name {
+			return database.OAuth2ProviderApp{}, errDuplicateKey
+		}
+	}
+	for i, app := range q.oauth2ProviderApps {
+		if app.ID != arg.ID {
+			continue
+		}
+		app.UpdatedAt = arg.UpdatedAt
+		app.Name = arg.Name
+		app.Icon = arg.Icon
+		app.CallbackURL = arg.CallbackURL
+		q.oauth2ProviderApps[i] = app
+		return app, nil
+	}
+	return database.OAuth2ProviderApp{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateOAuth2ProviderAppSecretByID(_ context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderApp, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, app := range q.oauth2ProviderApps {
+		if app.ID != arg.ID {
+			continue
+		}
+		app.UpdatedAt = arg.UpdatedAt
+		app.HashedSecret = arg.HashedSecret
+		q.oauth2ProviderApps[i] = app
+		return app, nil
+	}
+	return database.OAuth2ProviderApp{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspaceBatchResultsByOperationID(_ context.Context, operationID uuid.UUID) ([]database.WorkspaceBatchResult, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	results := make([]database.WorkspaceBatchResult, 0)
+	for _, result := range q.workspaceBatchResults {
+		if result.OperationID == operationID {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].WorkspaceName != results[j].WorkspaceName {
+			return results[i].WorkspaceName < results[j].WorkspaceName
+		}
+		return results[i].WorkspaceID.String() < results[j].WorkspaceID.String()
+	})
+	return results, nil
+}
+
+func (q *fakeQuerier) InsertWorkspaceBatchResult(_ context.Context, arg database.InsertWorkspaceBatchResultParams) (database.WorkspaceBatchResult, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, result := range q.workspaceBatchResults {
+		if result.OperationID == arg.OperationID && result.WorkspaceID == arg.WorkspaceID {
+			return database.WorkspaceBatchResult{}, errDuplicateKey
+		}
+	}
+	result := database.WorkspaceBatchResult{
+		OperationID:   arg.OperationID,
+		WorkspaceID:   arg.WorkspaceID,
+		WorkspaceName: arg.WorkspaceName,
+		Action:        arg.Action,
+		Status:        arg.Status,
+		Error:         arg.Error,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.CreatedAt,
+	}
+	q.workspaceBatchResults = append(q.workspaceBatchResults, result)
+	return result, nil
+}
+
+func (q *fakeQuerier) UpdateWorkspaceBatchResult(_ context.Context, arg database.UpdateWorkspaceBatchResultParams) (database.WorkspaceBatchResult, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, result := range q.workspaceBatchResults {
+		if result.OperationID != arg.OperationID || result.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		result.Status = arg.Status
+		result.BuildID = arg.BuildID
+		result.Error = arg.Error
+		result.UpdatedAt = arg.UpdatedAt
+		q.workspaceBatchResults[i] = result
+		return result, nil
+	}
+	return database.WorkspaceBatchResult{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWorkspaceTimelineEventsByWorkspaceID(_ context.Context, arg database.GetWorkspaceTimelineEventsByWorkspaceIDParams) ([]database.WorkspaceTimelineEvent, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	events := make([]database.WorkspaceTimelineEvent, 0)
+	for _, event := range q.workspaceTimelineEvents {
+		if event.WorkspaceID != arg.WorkspaceID || event.CreatedAt.Before(arg.StartTime) || !event.CreatedAt.Before(arg.EndTime) {
+			continue
+		}
+		events = append(events, event)
+	}
+	slices.SortFunc(events, func(a, b database.WorkspaceTimelineEvent) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+	if arg.LimitOpt > 0 && len(events) > int(arg.LimitOpt) {
+		events = events[:arg.LimitOpt]
+	}
+	return events, nil
+}
+
+func (q *fakeQuerier) InsertWorkspaceTimelineEvent(_ context.Context, arg database.InsertWorkspaceTimelineEventParams) (database.WorkspaceTimelineEvent, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	event := database.WorkspaceTimelineEvent{
+		ID:          arg.ID,
+		WorkspaceID: arg.WorkspaceID,
+		Type:        arg.Type,
+		AgentID:     arg.AgentID,
+		UserID:      arg.UserID,
+		AppName:     arg.AppName,
+		CreatedAt:   arg.CreatedAt,
+	}
+	q.workspaceTimelineEvents = append(q.workspaceTimelineEvents, event)
+	return event, nil
+}
+
+func (q *fakeQuerier) DeleteWorkspaceAgentUsageSamplesBefore(_ context.Context, arg database.DeleteWorkspaceAgentUsageSamplesBeforeParams) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	samples := make([]database.WorkspaceAgentUsageSample, 0, len(q.workspaceAgentUsageSamples))
+	for _, sample := range q.workspaceAgentUsageSamples {
+		if sample.AgentID == arg.AgentID && sample.CreatedAt.Before(arg.CreatedBefore) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	q.workspaceAgentUsageSamples = samples
+	return nil
+}
+
+func (q *fakeQuerier) GetWorkspaceAgentUsageSamplesByAgentIDs(_ context.Context, arg database.GetWorkspaceAgentUsageSamplesByAgentIDsParams) ([]database.WorkspaceAgentUsageSample, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	samples := make([]database.WorkspaceAgentUsageSample, 0)
+	for _, sample := range q.workspaceAgentUsageSamples {
+		if !slices.Contains(arg.IDs, sample.AgentID) || sample.CreatedAt.Before(arg.CreatedAfter) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	slices.SortFunc(samples, func(a, b database.WorkspaceAgentUsageSample) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return samples, nil
+}
+
+func (q *fakeQuerier) InsertWorkspaceAgentUsageSample(_ context.Context, arg database.InsertWorkspaceAgentUsageSampleParams) (database.WorkspaceAgentUsageSample, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	sample := database.WorkspaceAgentUsageSample{
+		ID:          arg.ID,
+		AgentID:     arg.AgentID,
+		CreatedAt:   arg.CreatedAt,
+		CPUUsed:     arg.CPUUsed,
+		CPUTotal:    arg.CPUTotal,
+		MemoryUsed:  arg.MemoryUsed,
+		MemoryTotal: arg.MemoryTotal,
+		DiskUsed:    arg.DiskUsed,
+		DiskTotal:   arg.DiskTotal,
+	}
+	q.workspaceAgentUsageSamples = append(q.workspaceAgentUsageSamples, sample)
+	return sample, nil
+}
+
+func (q *fakeQuerier) GetOrganizationWorkspaceNamePolicy(_ context.Context, organizationID uuid.UUID) (database.OrganizationWorkspaceNamePolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.organizationNamePolicies {
+		if policy.OrganizationID == organizationID {
+			return policy, nil
+		}
+	}
+	return database.OrganizationWorkspaceNamePolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertOrganizationWorkspaceNamePolicy(_ context.Context, arg database.UpsertOrganizationWorkspaceNamePolicyParams) (database.OrganizationWorkspaceNamePolicy, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	policy := database.OrganizationWorkspaceNamePolicy{
+		OrganizationID: arg.OrganizationID,
+		Pattern:        arg.Pattern,
+		Prefix:         arg.Prefix,
+		ReservedNames:  arg.ReservedNames,
+		UpdatedAt:      arg.UpdatedAt,
+	}
+	for i, existing := range q.organizationNamePolicies {
+		if existing.OrganizationID == arg.OrganizationID {
+			q.organizationNamePolicies[i] = policy
+			return policy, nil
+		}
+	}
+	q.organizationNamePolicies = append(q.organizationNamePolicies, policy)
+	return policy, nil
+}
+
+func (q *fakeQuerier) GetTemplateWorkspaceNamePolicyByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateWorkspaceNamePolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.templateNamePolicies {
+		if policy.TemplateID == templateID {
+			return policy, nil
+		}
+	}
+	return database.TemplateWorkspaceNamePolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertTemplateWorkspaceNamePolicy(_ context.Context, arg database.UpsertTemplateWorkspaceNamePolicyParams) (database.TemplateWorkspaceNamePolicy, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	policy := database.TemplateWorkspaceNamePolicy{
+		TemplateID:    arg.TemplateID,
+		Pattern:       arg.Pattern,
+		Prefix:        arg.Prefix,
+		ReservedNames: arg.ReservedNames,
+		UpdatedAt:     arg.UpdatedAt,
+	}
+	for i, existing := range q.templateNamePolicies {
+		if existing.TemplateID == arg.TemplateID {
+			q.templateNamePolicies[i] = policy
+			return policy, nil
+		}
+	}
+	q.templateNamePolicies = append(q.templateNamePolicies, policy)
+	return policy, nil
+}
+
+func (q *fakeQuerier) DeleteWorkspaceFavoritesByUserID(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	favorites := make([]database.WorkspaceFavorite, 0, len(q.workspaceFavorites))
+	for _, favorite := range q.workspaceFavorites {
+		if favorite.UserID != userID {
+			favorites = append(favorites, favorite)
+		}
+	}
+	q.workspaceFavorites = favorites
+	return nil
+}
+
+func (q *fakeQuerier) GetWorkspaceFavoritesByUserID(_ context.Context, userID uuid.UUID) ([]database.WorkspaceFavorite, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	favorites := make([]database.WorkspaceFavorite, 0)
+	for _, favorite := range q.workspaceFavorites {
+		if favorite.UserID != userID {
+			continue
+		}
+		for _, workspace := range q.workspaces {
+			if workspace.ID == favorite.WorkspaceID && !workspace.Deleted {
+				favorites = append(favorites, favorite)
+				break
+			}
+		}
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].Position < favorites[j].Position
+	})
+	return favorites, nil
+}
+
+func (q *fakeQuerier) InsertWorkspaceFavorite(_ context.Context, arg database.InsertWorkspaceFavoriteParams) (database.WorkspaceFavorite, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, favorite := range q.workspaceFavorites {
+		if favorite.UserID == arg.UserID && favorite.WorkspaceID == arg.WorkspaceID {
+			return database.WorkspaceFavorite{}, errDuplicateKey
+		}
+	}
+	//nolint:gosimple
+	favorite := database.WorkspaceFavorite{
+		UserID:      arg.UserID,
+		WorkspaceID: arg.WorkspaceID,
+		Position:    arg.Position,
+		CreatedAt:   arg.CreatedAt,
+	}
+	q.workspaceFavorites = append(q.workspaceFavorites, favorite)
+	return favorite, nil
+}
+
+func (q *fakeQuerier) DeleteTemplateFavoritesByUserID(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	favorites := make([]database.TemplateFavorite, 0, len(q.templateFavorites))
+	for _, favorite := range q.templateFavorites {
+		if favorite.UserID != userID {
+			favorites = append(favorites, favorite)
+		}
+	}
+	q.templateFavorites = favorites
+	return nil
+}
+
+func (q *fakeQuerier) GetTemplateFavoritesByUserID(_ context.Context, userID uuid.UUID) ([]database.TemplateFavorite, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	favorites := make([]database.TemplateFavorite, 0)
+	for _, favorite := range q.templateFavorites {
+		if favorite.UserID != userID {
+			continue
+		}
+		for _, template := range q.templates {
+			if template.ID == favorite.TemplateID && !template.Deleted {
+				favorites = append(favorites, favorite)
+				break
+			}
+		}
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].Position < favorites[j].Position
+	})
+	return favorites, nil
+}
+
+func (q *fakeQuerier) InsertTemplateFavorite(_ context.Context, arg database.InsertTemplateFavoriteParams) (database.TemplateFavorite, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, favorite := range q.templateFavorites {
+		if favorite.UserID == arg.UserID && favorite.TemplateID == arg.TemplateID {
+			return database.TemplateFavorite{}, errDuplicateKey
+		}
+	}
+	//nolint:gosimple
+	favorite := database.TemplateFavorite{
+		UserID:     arg.UserID,
+		TemplateID: arg.TemplateID,
+		Position:   arg.Position,
+		CreatedAt:  arg.CreatedAt,
+	}
+	q.templateFavorites = append(q.templateFavorites, favorite)
+	return favorite, nil
+}
+
+func (q *fakeQuerier) GetTemplateAutoRebuildPolicies(_ context.Context) ([]database.TemplateAutoRebuildPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	policies := make([]database.TemplateAutoRebuildPolicy, len(q.templateAutoRebuildPolicies))
+	copy(policies, q.templateAutoRebuildPolicies)
+	return policies, nil
+}
+
+func (q *fakeQuerier) GetTemplateAutoRebuildPolicyByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateAutoRebuildPolicy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, policy := range q.templateAutoRebuildPolicies {
+		if policy.TemplateID == templateID {
+			return policy, nil
+		}
+	}
+	return database.TemplateAutoRebuildPolicy{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpsertTemplateAutoRebuildPolicy(_ context.Context, arg database.UpsertTemplateAutoRebuildPolicyParams) (database.TemplateAutoRebuildPolicy, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	policy := database.TemplateAutoRebuildPolicy{
+		TemplateID:       arg.TemplateID,
+		FailureThreshold: arg.FailureThreshold,
+		AgentTimeout:     arg.AgentTimeout,
+		Action:           arg.Action,
+		UpdatedAt:        arg.UpdatedAt,
+	}
+	for i, existing := range q.templateAutoRebuildPolicies {
+		if existing.TemplateID == arg.TemplateID {
+			q.templateAutoRebuildPolicies[i] = policy
+			return policy, nil
+		}
+	}
+	q.templateAutoRebuildPolicies = append(q.templateAutoRebuildPolicies, policy)
+	return policy, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceAgentStartupScriptResultsByAgentIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceAgentStartupScriptResult, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	results := make([]database.WorkspaceAgentStartupScriptResult, 0)
+	for _, result := range q.workspaceAgentScriptResults {
+		if slices.Contains(ids, result.AgentID) {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (q *fakeQuerier) UpsertWorkspaceAgentStartupScriptResult(_ context.Context, arg database.UpsertWorkspaceAgentStartupScriptResultParams) (database.WorkspaceAgentStartupScriptResult, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	result := database.WorkspaceAgentStartupScriptResult{
+		AgentID:     arg.AgentID,
+		ExitCode:    arg.ExitCode,
+		CompletedAt: arg.CompletedAt,
+	}
+	for i, existing := range q.workspaceAgentScriptResults {
+		if existing.AgentID == arg.AgentID {
+			q.workspaceAgentScriptResults[i] = result
+			return result, nil
+		}
+	}
+	q.workspaceAgentScriptResults = append(q.workspaceAgentScriptResults, result)
+	return result, nil
+}
+
+func (q *fakeQuerier) GetTemplateDeprecationByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateDeprecation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, deprecation := range q.templateDeprecations {
+		if deprecation.TemplateID == templateID {
+			return deprecation, nil
+		}
+	}
+	return database.TemplateDeprecation{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateDeprecationsByTemplateIDs(_ context.Context, templateIDs []uuid.UUID) ([]database.TemplateDeprecation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	deprecations := make([]database.TemplateDeprecation, 0)
+	for _, deprecation := range q.templateDeprecations {
+		if slices.Contains(templateIDs, deprecation.TemplateID) {
+			deprecations = append(deprecations, deprecation)
+		}
+	}
+	return deprecations, nil
+}
+
+func (q *fakeQuerier) UpsertTemplateDeprecation(_ context.Context, arg database.UpsertTemplateDeprecationParams) (database.TemplateDeprecation, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deprecation := database.TemplateDeprecation{
+		TemplateID:       arg.TemplateID,
+		Message:          arg.Message,
+		SuccessorID:      arg.SuccessorID,
+		ParameterMapping: arg.ParameterMapping,
+		DeprecatedAt:     arg.DeprecatedAt,
+		UpdatedAt:        arg.DeprecatedAt,
+	}
+	for i, existing := range q.templateDeprecations {
+		if existing.TemplateID == arg.TemplateID {
+			deprecation.DeprecatedAt = existing.DeprecatedAt
+			q.templateDeprecations[i] = deprecation
+			return deprecation, nil
+		}
+	}
+	q.templateDeprecations = append(q.templateDeprecations, deprecation)
+	return deprecation, nil
+}
+
+func (q *fakeQuerier) DeleteTemplateDeprecationByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, deprecation := range q.templateDeprecations {
+		if deprecation.TemplateID == templateID {
+			q.templateDeprecations = append(q.templateDeprecations[:i], q.templateDeprecations[i+1:]...)
+			return nil
+		}
+	}
+	return nil
 }
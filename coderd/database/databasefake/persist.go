@@ -0,0 +1,436 @@
+package databasefake
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// snapshotVersion is bumped whenever the tables or the on-disk format of a
+// snapshot change.
+const snapshotVersion = 2
+
+// PersistentStore is an in-memory database that is loaded from a file on
+// start and flushed back to it after writes. It's intended for single-user
+// and development deployments that want data to survive a restart without
+// running PostgreSQL.
+type PersistentStore struct {
+	database.Store
+
+	path    string
+	querier *fakeQuerier
+	dirty   *atomic.Bool
+
+	flushMutex sync.Mutex
+	closeOnce  sync.Once
+	closed     chan struct{}
+	done       chan struct{}
+}
+
+// NewPersistent returns an in-memory fake of the database that persists to
+// path. Existing data at path is loaded, and changes are flushed to disk
+// every interval and when the store is closed.
+func NewPersistent(path string, interval time.Duration) (*PersistentStore, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	querier, ok := New().(*fakeQuerier)
+	if !ok {
+		return nil, xerrors.New("unexpected fake database type")
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, xerrors.Errorf("create database directory: %w", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, xerrors.Errorf("read database file: %w", err)
+	}
+	if len(raw) > 0 {
+		err = querier.data.restore(raw)
+		if err != nil {
+			return nil, xerrors.Errorf("restore database from %q: %w", path, err)
+		}
+	}
+
+	dirty := &atomic.Bool{}
+	querier.mutex = &dirtyMutex{RWMutex: &sync.RWMutex{}, dirty: dirty}
+	store := &PersistentStore{
+		Store:   querier,
+		path:    path,
+		querier: querier,
+		dirty:   dirty,
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go store.flushLoop(interval)
+	return store, nil
+}
+
+// Flush writes the current state of the database to disk if it has changed
+// since the last flush.
+func (s *PersistentStore) Flush() error {
+	s.flushMutex.Lock()
+	defer s.flushMutex.Unlock()
+	if !s.dirty.Swap(false) {
+		return nil
+	}
+
+	s.querier.mutex.RLock()
+	raw, err := s.querier.data.snapshot()
+	s.querier.mutex.RUnlock()
+	if err != nil {
+		s.dirty.Store(true)
+		return xerrors.Errorf("snapshot database: %w", err)
+	}
+
+	// Write to a temporary file first so a crash mid-write never leaves a
+	// truncated database behind.
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, raw, 0o600)
+	if err != nil {
+		s.dirty.Store(true)
+		return xerrors.Errorf("write database file: %w", err)
+	}
+	err = os.Rename(tmp, s.path)
+	if err != nil {
+		s.dirty.Store(true)
+		return xerrors.Errorf("rename database file: %w", err)
+	}
+	return nil
+}
+
+// Close stops the background flusher and writes any pending changes.
+func (s *PersistentStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	<-s.done
+	return s.Flush()
+}
+
+func (s *PersistentStore) flushLoop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+			// Errors are retried on the next tick since the dirty flag
+			// is restored on failure.
+			_ = s.Flush()
+		}
+	}
+}
+
+// dirtyMutex marks the database as changed whenever a write lock is
+// released.
+type dirtyMutex struct {
+	*sync.RWMutex
+	dirty *atomic.Bool
+}
+
+func (m *dirtyMutex) Unlock() {
+	m.dirty.Store(true)
+	m.RWMutex.Unlock()
+}
+
+// templateACL holds the unexported ACL columns of a template, which gob
+// can't encode directly.
+type templateACL struct {
+	User  database.TemplateACL
+	Group database.TemplateACL
+}
+
+// workspaceACL holds the unexported ACL columns of a workspace.
+type workspaceACL struct {
+	User  database.WorkspaceACL
+	Group database.WorkspaceACL
+}
+
+// snapshot is the exported, encodable mirror of data. Every field of data
+// must have one here, which TestPersistentEveryTable enforces.
+type snapshot struct {
+	Version int
+
+	APIKeys                        []database.APIKey
+	Organizations                  []database.Organization
+	OrganizationAliases            []database.OrganizationAlias
+	OrganizationMembers            []database.OrganizationMember
+	OrganizationInvites            []database.OrganizationInvite
+	OrganizationOIDC               []database.OrganizationOIDCConfig
+	OrganizationQuotas             []database.OrganizationQuota
+	Users                          []database.User
+	UserLinks                      []database.UserLink
+	AgentStats                     []database.AgentStat
+	AuditLogs                      []database.AuditLog
+	Files                          []database.File
+	GitSSHKey                      []database.GitSSHKey
+	Groups                         []database.Group
+	GroupMembers                   []database.GroupMember
+	GroupJoinRequests              []database.GroupJoinRequest
+	GroupWebhooks                  []database.GroupWebhook
+	RoleRequests                   []database.RoleRequest
+	OrganizationWebhooks           []database.OrganizationWebhook
+	OrganizationWebhookDeliveries  []database.OrganizationWebhookDelivery
+	Webhooks                       []database.Webhook
+	WebhookDeliveries              []database.WebhookDelivery
+	WebhookQueue                   []database.WebhookQueue
+	OrganizationTemplateDefaults   []database.OrganizationTemplateDefault
+	OrganizationNamePolicies       []database.OrganizationWorkspaceNamePolicy
+	OrganizationIPAllowlists       []database.OrganizationIpAllowlist
+	OrganizationDeletions          []database.OrganizationDeletion
+	Operations                     []database.Operation
+	OAuth2ProviderApps             []database.OAuth2ProviderApp
+	OAuth2ProviderAppCodes         []database.OAuth2ProviderAppCode
+	OAuth2ProviderAppTokens        []database.OAuth2ProviderAppToken
+	EveryoneGroupExclusions        []database.EveryoneGroupExclusion
+	ParameterSchemas               []database.ParameterSchema
+	ParameterValues                []database.ParameterValue
+	ProvisionerDaemons             []database.ProvisionerDaemon
+	ProvisionerJobAgents           []database.WorkspaceAgent
+	ProvisionerJobLogs             []database.ProvisionerJobLog
+	ProvisionerJobResources        []database.WorkspaceResource
+	ProvisionerJobResourceMetadata []database.WorkspaceResourceMetadatum
+	ProvisionerJobs                []database.ProvisionerJob
+	TemplateVersions               []database.TemplateVersion
+	Templates                      []database.Template
+	TemplateACLs                   map[uuid.UUID]templateACL
+	TemplateArchivePolicies        []database.TemplateArchivePolicy
+	TemplateAutoRebuildPolicies    []database.TemplateAutoRebuildPolicy
+	TemplateDeprecations           []database.TemplateDeprecation
+	TemplateNamePolicies           []database.TemplateWorkspaceNamePolicy
+	TemplateExtensionPolicies      []database.TemplateAutostopExtensionPolicy
+	TemplateMaintenanceWindows     []database.TemplateMaintenanceWindow
+	TemplateResourceCosts          []database.TemplateResourceCost
+	TemplateFavorites              []database.TemplateFavorite
+	WorkspaceAgentScriptResults    []database.WorkspaceAgentStartupScriptResult
+	WorkspaceArchives              []database.WorkspaceArchive
+	WorkspaceAutostopExtensions    []database.WorkspaceAutostopExtension
+	WorkspaceBuilds                []database.WorkspaceBuild
+	WorkspaceCosts                 []database.WorkspaceCost
+	WorkspaceBatchResults          []database.WorkspaceBatchResult
+	WorkspaceTimelineEvents        []database.WorkspaceTimelineEvent
+	WorkspaceAgentUsageSamples     []database.WorkspaceAgentUsageSample
+	WorkspaceFavorites             []database.WorkspaceFavorite
+	WorkspaceApps                  []database.WorkspaceApp
+	Workspaces                     []database.Workspace
+	WorkspaceACLs                  map[uuid.UUID]workspaceACL
+	Licenses                       []database.License
+
+	DeploymentID  string
+	Maintenance   string
+	LastLicenseID int32
+}
+
+func (d *data) snapshot() ([]byte, error) {
+	acls := make(map[uuid.UUID]templateACL, len(d.templates))
+	for _, template := range d.templates {
+		acls[template.ID] = templateACL{
+			User:  template.UserACL(),
+			Group: template.GroupACL(),
+		}
+	}
+	workspaceACLs := make(map[uuid.UUID]workspaceACL, len(d.workspaces))
+	for _, workspace := range d.workspaces {
+		workspaceACLs[workspace.ID] = workspaceACL{
+			User:  workspace.UserACL(),
+			Group: workspace.GroupACL(),
+		}
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(snapshot{
+		Version:                        snapshotVersion,
+		APIKeys:                        d.apiKeys,
+		Organizations:                  d.organizations,
+		OrganizationAliases:            d.organizationAliases,
+		OrganizationMembers:            d.organizationMembers,
+		OrganizationInvites:            d.organizationInvites,
+		OrganizationOIDC:               d.organizationOIDC,
+		OrganizationQuotas:             d.organizationQuotas,
+		Users:                          d.users,
+		UserLinks:                      d.userLinks,
+		AgentStats:                     d.agentStats,
+		AuditLogs:                      d.auditLogs,
+		Files:                          d.files,
+		GitSSHKey:                      d.gitSSHKey,
+		Groups:                         d.groups,
+		GroupMembers:                   d.groupMembers,
+		GroupJoinRequests:              d.groupJoinRequests,
+		GroupWebhooks:                  d.groupWebhooks,
+		RoleRequests:                   d.roleRequests,
+		OrganizationWebhooks:           d.organizationWebhooks,
+		OrganizationWebhookDeliveries:  d.organizationWebhookDeliveries,
+		Webhooks:                       d.webhooks,
+		WebhookDeliveries:              d.webhookDeliveries,
+		WebhookQueue:                   d.webhookQueue,
+		OrganizationTemplateDefaults:   d.organizationTemplateDefaults,
+		OrganizationNamePolicies:       d.organizationNamePolicies,
+		OrganizationIPAllowlists:       d.organizationIPAllowlists,
+		OrganizationDeletions:          d.organizationDeletions,
+		Operations:                     d.operations,
+		OAuth2ProviderApps:             d.oauth2ProviderApps,
+		OAuth2ProviderAppCodes:         d.oauth2ProviderAppCodes,
+		OAuth2ProviderAppTokens:        d.oauth2ProviderAppTokens,
+		EveryoneGroupExclusions:        d.everyoneGroupExclusions,
+		ParameterSchemas:               d.parameterSchemas,
+		ParameterValues:                d.parameterValues,
+		ProvisionerDaemons:             d.provisionerDaemons,
+		ProvisionerJobAgents:           d.provisionerJobAgents,
+		ProvisionerJobLogs:             d.provisionerJobLogs,
+		ProvisionerJobResources:        d.provisionerJobResources,
+		ProvisionerJobResourceMetadata: d.provisionerJobResourceMetadata,
+		ProvisionerJobs:                d.provisionerJobs,
+		TemplateVersions:               d.templateVersions,
+		Templates:                      d.templates,
+		TemplateACLs:                   acls,
+		TemplateArchivePolicies:        d.templateArchivePolicies,
+		TemplateAutoRebuildPolicies:    d.templateAutoRebuildPolicies,
+		TemplateDeprecations:           d.templateDeprecations,
+		TemplateNamePolicies:           d.templateNamePolicies,
+		TemplateExtensionPolicies:      d.templateExtensionPolicies,
+		TemplateMaintenanceWindows:     d.templateMaintenanceWindows,
+		TemplateResourceCosts:          d.templateResourceCosts,
+		TemplateFavorites:              d.templateFavorites,
+		WorkspaceAgentScriptResults:    d.workspaceAgentScriptResults,
+		WorkspaceArchives:              d.workspaceArchives,
+		WorkspaceAutostopExtensions:    d.workspaceAutostopExtensions,
+		WorkspaceBuilds:                d.workspaceBuilds,
+		WorkspaceCosts:                 d.workspaceCosts,
+		WorkspaceBatchResults:          d.workspaceBatchResults,
+		WorkspaceTimelineEvents:        d.workspaceTimelineEvents,
+		WorkspaceAgentUsageSamples:     d.workspaceAgentUsageSamples,
+		WorkspaceFavorites:             d.workspaceFavorites,
+		WorkspaceApps:                  d.workspaceApps,
+		Workspaces:                     d.workspaces,
+		WorkspaceACLs:                  workspaceACLs,
+		Licenses:                       d.licenses,
+		DeploymentID:                   d.deploymentID,
+		Maintenance:                    d.maintenance,
+		LastLicenseID:                  d.lastLicenseID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *data) restore(raw []byte) error {
+	var snap snapshot
+	err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&snap)
+	if err != nil {
+		return xerrors.Errorf("decode: %w", err)
+	}
+	// Version 1 snapshots only had some of the tables, and gob leaves the
+	// others empty when decoding them.
+	if snap.Version < 1 || snap.Version > snapshotVersion {
+		return xerrors.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	templates := make([]database.Template, 0, len(snap.Templates))
+	for _, template := range snap.Templates {
+		acl, ok := snap.TemplateACLs[template.ID]
+		if ok {
+			template = template.SetUserACL(acl.User).SetGroupACL(acl.Group)
+		}
+		templates = append(templates, template)
+	}
+	workspaces := make([]database.Workspace, 0, len(snap.Workspaces))
+	for _, workspace := range snap.Workspaces {
+		acl, ok := snap.WorkspaceACLs[workspace.ID]
+		if ok {
+			workspace = workspace.SetUserACL(acl.User).SetGroupACL(acl.Group)
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	// Slices are only replaced when present so the non-nil defaults from
+	// New are kept for empty tables.
+	restoreSlice(&d.apiKeys, snap.APIKeys)
+	restoreSlice(&d.organizations, snap.Organizations)
+	restoreSlice(&d.organizationAliases, snap.OrganizationAliases)
+	restoreSlice(&d.organizationMembers, snap.OrganizationMembers)
+	restoreSlice(&d.organizationInvites, snap.OrganizationInvites)
+	restoreSlice(&d.organizationOIDC, snap.OrganizationOIDC)
+	restoreSlice(&d.organizationQuotas, snap.OrganizationQuotas)
+	restoreSlice(&d.users, snap.Users)
+	restoreSlice(&d.userLinks, snap.UserLinks)
+	restoreSlice(&d.agentStats, snap.AgentStats)
+	restoreSlice(&d.auditLogs, snap.AuditLogs)
+	restoreSlice(&d.files, snap.Files)
+	restoreSlice(&d.gitSSHKey, snap.GitSSHKey)
+	restoreSlice(&d.groups, snap.Groups)
+	restoreSlice(&d.groupMembers, snap.GroupMembers)
+	restoreSlice(&d.groupJoinRequests, snap.GroupJoinRequests)
+	restoreSlice(&d.groupWebhooks, snap.GroupWebhooks)
+	restoreSlice(&d.roleRequests, snap.RoleRequests)
+	restoreSlice(&d.organizationWebhooks, snap.OrganizationWebhooks)
+	restoreSlice(&d.organizationWebhookDeliveries, snap.OrganizationWebhookDeliveries)
+	restoreSlice(&d.webhooks, snap.Webhooks)
+	restoreSlice(&d.webhookDeliveries, snap.WebhookDeliveries)
+	restoreSlice(&d.webhookQueue, snap.WebhookQueue)
+	restoreSlice(&d.organizationTemplateDefaults, snap.OrganizationTemplateDefaults)
+	restoreSlice(&d.organizationNamePolicies, snap.OrganizationNamePolicies)
+	restoreSlice(&d.organizationIPAllowlists, snap.OrganizationIPAllowlists)
+	restoreSlice(&d.organizationDeletions, snap.OrganizationDeletions)
+	restoreSlice(&d.operations, snap.Operations)
+	restoreSlice(&d.oauth2ProviderApps, snap.OAuth2ProviderApps)
+	restoreSlice(&d.oauth2ProviderAppCodes, snap.OAuth2ProviderAppCodes)
+	restoreSlice(&d.oauth2ProviderAppTokens, snap.OAuth2ProviderAppTokens)
+	restoreSlice(&d.everyoneGroupExclusions, snap.EveryoneGroupExclusions)
+	restoreSlice(&d.parameterSchemas, snap.ParameterSchemas)
+	restoreSlice(&d.parameterValues, snap.ParameterValues)
+	restoreSlice(&d.provisionerDaemons, snap.ProvisionerDaemons)
+	restoreSlice(&d.provisionerJobAgents, snap.ProvisionerJobAgents)
+	restoreSlice(&d.provisionerJobLogs, snap.ProvisionerJobLogs)
+	restoreSlice(&d.provisionerJobResources, snap.ProvisionerJobResources)
+	restoreSlice(&d.provisionerJobResourceMetadata, snap.ProvisionerJobResourceMetadata)
+	restoreSlice(&d.provisionerJobs, snap.ProvisionerJobs)
+	restoreSlice(&d.templateVersions, snap.TemplateVersions)
+	restoreSlice(&d.templates, templates)
+	restoreSlice(&d.templateArchivePolicies, snap.TemplateArchivePolicies)
+	restoreSlice(&d.templateAutoRebuildPolicies, snap.TemplateAutoRebuildPolicies)
+	restoreSlice(&d.templateDeprecations, snap.TemplateDeprecations)
+	restoreSlice(&d.templateNamePolicies, snap.TemplateNamePolicies)
+	restoreSlice(&d.templateExtensionPolicies, snap.TemplateExtensionPolicies)
+	restoreSlice(&d.templateMaintenanceWindows, snap.TemplateMaintenanceWindows)
+	restoreSlice(&d.templateResourceCosts, snap.TemplateResourceCosts)
+	restoreSlice(&d.templateFavorites, snap.TemplateFavorites)
+	restoreSlice(&d.workspaceAgentScriptResults, snap.WorkspaceAgentScriptResults)
+	restoreSlice(&d.workspaceArchives, snap.WorkspaceArchives)
+	restoreSlice(&d.workspaceAutostopExtensions, snap.WorkspaceAutostopExtensions)
+	restoreSlice(&d.workspaceBuilds, snap.WorkspaceBuilds)
+	restoreSlice(&d.workspaceCosts, snap.WorkspaceCosts)
+	restoreSlice(&d.workspaceBatchResults, snap.WorkspaceBatchResults)
+	restoreSlice(&d.workspaceTimelineEvents, snap.WorkspaceTimelineEvents)
+	restoreSlice(&d.workspaceAgentUsageSamples, snap.WorkspaceAgentUsageSamples)
+	restoreSlice(&d.workspaceFavorites, snap.WorkspaceFavorites)
+	restoreSlice(&d.workspaceApps, snap.WorkspaceApps)
+	restoreSlice(&d.workspaces, workspaces)
+	restoreSlice(&d.licenses, snap.Licenses)
+	d.deploymentID = snap.DeploymentID
+	d.maintenance = snap.Maintenance
+	d.lastLicenseID = snap.LastLicenseID
+	return nil
+}
+
+func restoreSlice[T any](dst *[]T, src []T) {
+	if len(src) == 0 {
+		return
+	}
+	*dst = src
+}
@@ -0,0 +1,46 @@
+package databasefake
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPersistentEveryTable fails when a table is added to data without
+// being persisted in snapshots.
+func TestPersistentEveryTable(t *testing.T) {
+	t.Parallel()
+
+	from := &data{}
+	fields := reflect.ValueOf(from).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		field := settable(fields.Field(i))
+		switch field.Kind() {
+		case reflect.Slice:
+			field.Set(reflect.Append(field, reflect.New(field.Type().Elem()).Elem()))
+		case reflect.String:
+			field.SetString("persisted")
+		case reflect.Int32:
+			field.SetInt(1)
+		default:
+			t.Fatalf("field %q has unsupported kind %s", fields.Type().Field(i).Name, field.Kind())
+		}
+	}
+
+	raw, err := from.snapshot()
+	require.NoError(t, err)
+	to := &data{}
+	err = to.restore(raw)
+	require.NoError(t, err)
+
+	restored := reflect.ValueOf(to).Elem()
+	for i := 0; i < restored.NumField(); i++ {
+		require.False(t, restored.Field(i).IsZero(), "field %q isn't persisted", restored.Type().Field(i).Name)
+	}
+}
+
+func settable(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,65 @@
+package databasefake_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestPersistent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "coder.db")
+
+	store, err := databasefake.NewPersistent(path, time.Hour)
+	require.NoError(t, err)
+
+	org, err := store.InsertOrganization(ctx, database.InsertOrganizationParams{
+		ID:   uuid.New(),
+		Name: "persisted",
+	})
+	require.NoError(t, err)
+	err = store.InsertDeploymentID(ctx, "deployment")
+	require.NoError(t, err)
+	userID := uuid.New()
+	template, err := store.InsertTemplate(ctx, database.InsertTemplateParams{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Name:           "template",
+		Provisioner:    database.ProvisionerTypeEcho,
+	})
+	require.NoError(t, err)
+	acl := database.TemplateACL{
+		userID.String(): []rbac.Action{rbac.ActionRead},
+	}
+	err = store.UpdateTemplateUserACLByID(ctx, template.ID, acl)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = databasefake.NewPersistent(path, time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	got, err := store.GetOrganizationByID(ctx, org.ID)
+	require.NoError(t, err)
+	require.Equal(t, org.Name, got.Name)
+
+	deploymentID, err := store.GetDeploymentID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "deployment", deploymentID)
+
+	gotTemplate, err := store.GetTemplateByID(ctx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, acl, gotTemplate.UserACL())
+}
@@ -2,19 +2,33 @@
 
 CREATE TYPE api_key_scope AS ENUM (
     'all',
-    'application_connect'
+    'application_connect',
+    'restricted'
 );
 
 CREATE TYPE audit_action AS ENUM (
     'create',
     'write',
-    'delete'
+    'delete',
+    'deny'
+);
+
+CREATE TYPE auto_rebuild_action AS ENUM (
+    'restart',
+    'rebuild'
 );
 
 CREATE TYPE build_reason AS ENUM (
     'initiator',
     'autostart',
-    'autostop'
+    'autostop',
+    'maintenance',
+    'agent_failure'
+);
+
+CREATE TYPE group_source AS ENUM (
+    'user',
+    'oidc'
 );
 
 CREATE TYPE log_level AS ENUM (
@@ -37,6 +51,18 @@ CREATE TYPE login_type AS ENUM (
     'token'
 );
 
+CREATE TYPE operation_status AS ENUM (
+    'running',
+    'succeeded',
+    'failed'
+);
+
+CREATE TYPE organization_deletion_status AS ENUM (
+    'running',
+    'succeeded',
+    'failed'
+);
+
 CREATE TYPE parameter_destination_scheme AS ENUM (
     'none',
     'environment_variable',
@@ -81,7 +107,15 @@ CREATE TYPE resource_type AS ENUM (
     'user',
     'workspace',
     'git_ssh_key',
-    'api_key'
+    'api_key',
+    'group_member',
+    'organization_member'
+);
+
+CREATE TYPE role_request_status AS ENUM (
+    'pending',
+    'approved',
+    'denied'
 );
 
 CREATE TYPE user_status AS ENUM (
@@ -96,6 +130,33 @@ CREATE TYPE workspace_app_health AS ENUM (
     'unhealthy'
 );
 
+CREATE TYPE workspace_archive_status AS ENUM (
+    'archiving',
+    'archived',
+    'restoring',
+    'failed'
+);
+
+CREATE TYPE workspace_batch_action AS ENUM (
+    'start',
+    'stop',
+    'delete',
+    'update'
+);
+
+CREATE TYPE workspace_batch_result_status AS ENUM (
+    'pending',
+    'running',
+    'succeeded',
+    'failed'
+);
+
+CREATE TYPE workspace_timeline_event_type AS ENUM (
+    'agent_connected',
+    'agent_disconnected',
+    'app_opened'
+);
+
 CREATE TYPE workspace_transition AS ENUM (
     'start',
     'stop',
@@ -123,11 +184,17 @@ CREATE TABLE api_keys (
     login_type login_type NOT NULL,
     lifetime_seconds bigint DEFAULT 86400 NOT NULL,
     ip_address inet DEFAULT '0.0.0.0'::inet NOT NULL,
-    scope api_key_scope DEFAULT 'all'::public.api_key_scope NOT NULL
+    scope api_key_scope DEFAULT 'all'::public.api_key_scope NOT NULL,
+    scope_permissions text[] DEFAULT '{}'::text[] NOT NULL,
+    scope_organization_id uuid
 );
 
 COMMENT ON COLUMN api_keys.hashed_secret IS 'hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.';
 
+COMMENT ON COLUMN api_keys.scope_permissions IS 'scope_permissions lists the "<resource>:<action>" pairs a key with the restricted scope is allowed to use.';
+
+COMMENT ON COLUMN api_keys.scope_organization_id IS 'scope_organization_id limits the scope_permissions of a restricted key to a single organization.';
+
 CREATE TABLE audit_logs (
     id uuid NOT NULL,
     "time" timestamp with time zone NOT NULL,
@@ -146,6 +213,12 @@ CREATE TABLE audit_logs (
     resource_icon text NOT NULL
 );
 
+CREATE TABLE everyone_group_exclusions (
+    organization_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE files (
     hash character varying(64) NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -162,15 +235,43 @@ CREATE TABLE gitsshkeys (
     public_key text NOT NULL
 );
 
+CREATE TABLE group_join_requests (
+    id uuid NOT NULL,
+    group_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE group_members (
     user_id uuid NOT NULL,
-    group_id uuid NOT NULL
+    group_id uuid NOT NULL,
+    expires_at timestamp with time zone,
+    roles text[] DEFAULT '{}'::text[] NOT NULL
+);
+
+CREATE TABLE group_webhooks (
+    id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    created_at timestamp with time zone NOT NULL
 );
 
 CREATE TABLE groups (
     id uuid NOT NULL,
     name text NOT NULL,
-    organization_id uuid NOT NULL
+    organization_id uuid,
+    parent_id uuid,
+    display_name text DEFAULT ''::text NOT NULL,
+    avatar_url text DEFAULT ''::text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    source group_source DEFAULT 'user'::group_source NOT NULL,
+    deleted_at timestamp with time zone,
+    metadata jsonb DEFAULT '{}'::jsonb NOT NULL,
+    autostop_schedule text DEFAULT ''::text NOT NULL,
+    max_ttl bigint DEFAULT 0 NOT NULL,
+    quota_allowance integer DEFAULT 0 NOT NULL,
+    roles text[] DEFAULT '{}'::text[] NOT NULL
 );
 
 CREATE TABLE licenses (
@@ -192,6 +293,94 @@ CREATE SEQUENCE licenses_id_seq
 
 ALTER SEQUENCE licenses_id_seq OWNED BY public.licenses.id;
 
+CREATE TABLE oauth2_provider_app_codes (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    hashed_secret bytea NOT NULL,
+    app_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    redirect_uri text NOT NULL,
+    scope text NOT NULL,
+    code_challenge text DEFAULT ''::text NOT NULL,
+    code_challenge_method text DEFAULT ''::text NOT NULL
+);
+
+CREATE TABLE oauth2_provider_app_tokens (
+    api_key_id text NOT NULL,
+    app_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE oauth2_provider_apps (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    name character varying(64) NOT NULL,
+    icon text DEFAULT ''::text NOT NULL,
+    callback_url text NOT NULL,
+    hashed_secret bytea NOT NULL
+);
+
+CREATE TABLE operations (
+    id uuid NOT NULL,
+    type text NOT NULL,
+    status operation_status DEFAULT 'running'::operation_status NOT NULL,
+    initiator_id uuid NOT NULL,
+    organization_id uuid,
+    resource_id uuid NOT NULL,
+    progress_completed bigint DEFAULT 0 NOT NULL,
+    progress_total bigint DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone
+);
+
+CREATE TABLE organization_aliases (
+    name text NOT NULL,
+    organization_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE organization_deletions (
+    organization_id uuid NOT NULL,
+    initiator_id uuid NOT NULL,
+    status organization_deletion_status DEFAULT 'running'::organization_deletion_status NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    workspaces_total bigint DEFAULT 0 NOT NULL,
+    workspaces_deleted bigint DEFAULT 0 NOT NULL,
+    templates_total bigint DEFAULT 0 NOT NULL,
+    templates_deleted bigint DEFAULT 0 NOT NULL,
+    members_total bigint DEFAULT 0 NOT NULL,
+    members_deleted bigint DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone,
+    operation_id uuid,
+    worker_id uuid,
+    heartbeat_at timestamp with time zone
+);
+
+CREATE TABLE organization_invites (
+    id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    hashed_token bytea NOT NULL,
+    email_domain text DEFAULT ''::text NOT NULL,
+    roles text[] DEFAULT '{}'::text[] NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone,
+    max_uses integer DEFAULT 0 NOT NULL,
+    uses integer DEFAULT 0 NOT NULL
+);
+
+CREATE TABLE organization_ip_allowlists (
+    organization_id uuid NOT NULL,
+    cidrs text[] DEFAULT '{}'::text[] NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE organization_members (
     user_id uuid NOT NULL,
     organization_id uuid NOT NULL,
@@ -200,12 +389,75 @@ CREATE TABLE organization_members (
     roles text[] DEFAULT '{organization-member}'::text[] NOT NULL
 );
 
+CREATE TABLE organization_oidc_configs (
+    organization_id uuid NOT NULL,
+    issuer_url text NOT NULL,
+    client_id text NOT NULL,
+    client_secret text NOT NULL,
+    scopes text[] DEFAULT '{}'::text[] NOT NULL,
+    email_domains text[] DEFAULT '{}'::text[] NOT NULL,
+    username_field text DEFAULT 'preferred_username'::text NOT NULL,
+    email_field text DEFAULT 'email'::text NOT NULL,
+    allow_signups boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE organization_quotas (
+    organization_id uuid NOT NULL,
+    max_workspaces integer DEFAULT 0 NOT NULL,
+    max_running_workspaces integer DEFAULT 0 NOT NULL,
+    compute_credits integer DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE organization_template_defaults (
+    organization_id uuid NOT NULL,
+    default_ttl bigint DEFAULT 0 NOT NULL,
+    max_ttl bigint DEFAULT 0 NOT NULL,
+    min_autostart_interval bigint DEFAULT 0 NOT NULL,
+    parameter_values jsonb DEFAULT '[]'::jsonb NOT NULL,
+    allowed_parameter_values jsonb DEFAULT '{}'::jsonb NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE organization_webhook_deliveries (
+    id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    attempt integer NOT NULL,
+    status_code integer DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE organization_webhooks (
+    id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text[] DEFAULT '{}'::text[] NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE organization_workspace_name_policies (
+    organization_id uuid NOT NULL,
+    pattern text DEFAULT ''::text NOT NULL,
+    prefix text DEFAULT ''::text NOT NULL,
+    reserved_names text[] DEFAULT '{}'::text[] NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE organizations (
     id uuid NOT NULL,
     name text NOT NULL,
     description text NOT NULL,
     created_at timestamp with time zone NOT NULL,
-    updated_at timestamp with time zone NOT NULL
+    updated_at timestamp with time zone NOT NULL,
+    is_default boolean DEFAULT false NOT NULL,
+    display_name text DEFAULT ''::text NOT NULL,
+    icon text DEFAULT ''::text NOT NULL
 );
 
 CREATE TABLE parameter_schemas (
@@ -276,11 +528,75 @@ CREATE TABLE provisioner_jobs (
     worker_id uuid
 );
 
+CREATE TABLE role_requests (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    organization_id uuid,
+    role text NOT NULL,
+    reason text DEFAULT ''::text NOT NULL,
+    status role_request_status DEFAULT 'pending'::role_request_status NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    reviewed_by uuid,
+    reviewed_at timestamp with time zone
+);
+
 CREATE TABLE site_configs (
     key character varying(256) NOT NULL,
     value character varying(8192) NOT NULL
 );
 
+CREATE TABLE template_archive_policies (
+    template_id uuid NOT NULL,
+    dormancy_threshold bigint NOT NULL,
+    retention bigint NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE template_auto_rebuild_policies (
+    template_id uuid NOT NULL,
+    failure_threshold integer NOT NULL,
+    agent_timeout bigint NOT NULL,
+    action auto_rebuild_action NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE template_autostop_extension_policies (
+    template_id uuid NOT NULL,
+    max_extensions_per_day integer NOT NULL,
+    max_extension_per_day bigint NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE template_deprecations (
+    template_id uuid NOT NULL,
+    message text NOT NULL,
+    successor_id uuid,
+    parameter_mapping jsonb DEFAULT '{}'::jsonb NOT NULL,
+    deprecated_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE template_favorites (
+    user_id uuid NOT NULL,
+    template_id uuid NOT NULL,
+    "position" integer NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE template_maintenance_windows (
+    template_id uuid NOT NULL,
+    schedule text NOT NULL,
+    duration bigint NOT NULL,
+    exempt_group_ids uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE template_resource_costs (
+    template_id uuid NOT NULL,
+    resource_type text NOT NULL,
+    hourly_cost double precision NOT NULL
+);
+
 CREATE TABLE template_versions (
     id uuid NOT NULL,
     template_id uuid,
@@ -293,6 +609,14 @@ CREATE TABLE template_versions (
     created_by uuid
 );
 
+CREATE TABLE template_workspace_name_policies (
+    template_id uuid NOT NULL,
+    pattern text DEFAULT ''::text NOT NULL,
+    prefix text DEFAULT ''::text NOT NULL,
+    reserved_names text[] DEFAULT '{}'::text[] NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE templates (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -308,7 +632,9 @@ CREATE TABLE templates (
     created_by uuid NOT NULL,
     icon character varying(256) DEFAULT ''::character varying NOT NULL,
     user_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
-    group_acl jsonb DEFAULT '{}'::jsonb NOT NULL
+    group_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
+    quota_weight integer DEFAULT 1 NOT NULL,
+    workspace_labels jsonb DEFAULT '{}'::jsonb NOT NULL
 );
 
 CREATE TABLE user_links (
@@ -335,6 +661,57 @@ CREATE TABLE users (
     last_seen_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL
 );
 
+CREATE TABLE webhook_deliveries (
+    id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    attempt integer NOT NULL,
+    redelivery boolean DEFAULT false NOT NULL,
+    status_code integer DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE webhook_queue (
+    id uuid NOT NULL,
+    kind text NOT NULL,
+    webhook_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    run_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE webhooks (
+    id uuid NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text[] DEFAULT '{}'::text[] NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE workspace_agent_startup_script_results (
+    agent_id uuid NOT NULL,
+    exit_code integer NOT NULL,
+    completed_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE workspace_agent_usage_samples (
+    id uuid NOT NULL,
+    agent_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    cpu_used double precision NOT NULL,
+    cpu_total double precision NOT NULL,
+    memory_used bigint NOT NULL,
+    memory_total bigint NOT NULL,
+    disk_used bigint NOT NULL,
+    disk_total bigint NOT NULL
+);
+
 CREATE TABLE workspace_agents (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -373,6 +750,38 @@ CREATE TABLE workspace_apps (
     subdomain boolean DEFAULT false NOT NULL
 );
 
+CREATE TABLE workspace_archives (
+    workspace_id uuid NOT NULL,
+    status workspace_archive_status NOT NULL,
+    object_key text NOT NULL,
+    build_id uuid NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    archived_at timestamp with time zone,
+    expires_at timestamp with time zone
+);
+
+CREATE TABLE workspace_autostop_extensions (
+    id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    extension bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE workspace_batch_results (
+    operation_id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    workspace_name text NOT NULL,
+    action workspace_batch_action NOT NULL,
+    status workspace_batch_result_status DEFAULT 'pending'::workspace_batch_result_status NOT NULL,
+    build_id uuid,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE workspace_builds (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -388,6 +797,24 @@ CREATE TABLE workspace_builds (
     reason build_reason DEFAULT 'initiator'::public.build_reason NOT NULL
 );
 
+CREATE TABLE workspace_costs (
+    workspace_id uuid NOT NULL,
+    owner_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    template_id uuid NOT NULL,
+    start_time timestamp with time zone NOT NULL,
+    cost double precision NOT NULL,
+    running_seconds bigint NOT NULL,
+    accrued_until timestamp with time zone NOT NULL
+);
+
+CREATE TABLE workspace_favorites (
+    user_id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    "position" integer NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE workspace_resource_metadata (
     workspace_resource_id uuid NOT NULL,
     key character varying(1024) NOT NULL,
@@ -406,6 +833,16 @@ CREATE TABLE workspace_resources (
     icon character varying(256) DEFAULT ''::character varying NOT NULL
 );
 
+CREATE TABLE workspace_timeline_events (
+    id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    type workspace_timeline_event_type NOT NULL,
+    agent_id uuid NOT NULL,
+    user_id uuid,
+    app_name text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE workspaces (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -417,7 +854,10 @@ CREATE TABLE workspaces (
     name character varying(64) NOT NULL,
     autostart_schedule text,
     ttl bigint,
-    last_used_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL
+    last_used_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL,
+    user_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
+    group_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
+    labels jsonb DEFAULT '{}'::jsonb NOT NULL
 );
 
 ALTER TABLE ONLY licenses ALTER COLUMN id SET DEFAULT nextval('public.licenses_id_seq'::regclass);
@@ -431,17 +871,26 @@ ALTER TABLE ONLY api_keys
 ALTER TABLE ONLY audit_logs
     ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY everyone_group_exclusions
+    ADD CONSTRAINT everyone_group_exclusions_pkey PRIMARY KEY (organization_id, user_id);
+
 ALTER TABLE ONLY files
     ADD CONSTRAINT files_pkey PRIMARY KEY (hash);
 
 ALTER TABLE ONLY gitsshkeys
     ADD CONSTRAINT gitsshkeys_pkey PRIMARY KEY (user_id);
 
+ALTER TABLE ONLY group_join_requests
+    ADD CONSTRAINT group_join_requests_group_id_user_id_key UNIQUE (group_id, user_id);
+
+ALTER TABLE ONLY group_join_requests
+    ADD CONSTRAINT group_join_requests_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY group_members
     ADD CONSTRAINT group_members_user_id_group_id_key UNIQUE (user_id, group_id);
 
-ALTER TABLE ONLY groups
-    ADD CONSTRAINT groups_name_organization_id_key UNIQUE (name, organization_id);
+ALTER TABLE ONLY group_webhooks
+    ADD CONSTRAINT group_webhooks_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY groups
     ADD CONSTRAINT groups_pkey PRIMARY KEY (id);
@@ -452,9 +901,60 @@ ALTER TABLE ONLY licenses
 ALTER TABLE ONLY licenses
     ADD CONSTRAINT licenses_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_hashed_secret_key UNIQUE (hashed_secret);
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_pkey PRIMARY KEY (api_key_id);
+
+ALTER TABLE ONLY oauth2_provider_apps
+    ADD CONSTRAINT oauth2_provider_apps_name_key UNIQUE (name);
+
+ALTER TABLE ONLY oauth2_provider_apps
+    ADD CONSTRAINT oauth2_provider_apps_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY operations
+    ADD CONSTRAINT operations_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY organization_aliases
+    ADD CONSTRAINT organization_aliases_pkey PRIMARY KEY (name);
+
+ALTER TABLE ONLY organization_deletions
+    ADD CONSTRAINT organization_deletions_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_invites
+    ADD CONSTRAINT organization_invites_hashed_token_key UNIQUE (hashed_token);
+
+ALTER TABLE ONLY organization_invites
+    ADD CONSTRAINT organization_invites_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY organization_ip_allowlists
+    ADD CONSTRAINT organization_ip_allowlists_pkey PRIMARY KEY (organization_id);
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_pkey PRIMARY KEY (organization_id, user_id);
 
+ALTER TABLE ONLY organization_oidc_configs
+    ADD CONSTRAINT organization_oidc_configs_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_quotas
+    ADD CONSTRAINT organization_quotas_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_template_defaults
+    ADD CONSTRAINT organization_template_defaults_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_webhook_deliveries
+    ADD CONSTRAINT organization_webhook_deliveries_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY organization_webhooks
+    ADD CONSTRAINT organization_webhooks_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY organization_workspace_name_policies
+    ADD CONSTRAINT organization_workspace_name_policies_pkey PRIMARY KEY (organization_id);
+
 ALTER TABLE ONLY organizations
     ADD CONSTRAINT organizations_pkey PRIMARY KEY (id);
 
@@ -482,15 +982,42 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY role_requests
+    ADD CONSTRAINT role_requests_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
+ALTER TABLE ONLY template_archive_policies
+    ADD CONSTRAINT template_archive_policies_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_auto_rebuild_policies
+    ADD CONSTRAINT template_auto_rebuild_policies_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_autostop_extension_policies
+    ADD CONSTRAINT template_autostop_extension_policies_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_deprecations
+    ADD CONSTRAINT template_deprecations_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_favorites
+    ADD CONSTRAINT template_favorites_pkey PRIMARY KEY (user_id, template_id);
+
+ALTER TABLE ONLY template_maintenance_windows
+    ADD CONSTRAINT template_maintenance_windows_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_resource_costs
+    ADD CONSTRAINT template_resource_costs_pkey PRIMARY KEY (template_id, resource_type);
+
 ALTER TABLE ONLY template_versions
     ADD CONSTRAINT template_versions_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY template_versions
     ADD CONSTRAINT template_versions_template_id_name_key UNIQUE (template_id, name);
 
+ALTER TABLE ONLY template_workspace_name_policies
+    ADD CONSTRAINT template_workspace_name_policies_pkey PRIMARY KEY (template_id);
+
 ALTER TABLE ONLY templates
     ADD CONSTRAINT templates_pkey PRIMARY KEY (id);
 
@@ -500,6 +1027,21 @@ ALTER TABLE ONLY user_links
 ALTER TABLE ONLY users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY webhook_queue
+    ADD CONSTRAINT webhook_queue_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY webhooks
+    ADD CONSTRAINT webhooks_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY workspace_agent_startup_script_results
+    ADD CONSTRAINT workspace_agent_startup_script_results_pkey PRIMARY KEY (agent_id);
+
+ALTER TABLE ONLY workspace_agent_usage_samples
+    ADD CONSTRAINT workspace_agent_usage_samples_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_pkey PRIMARY KEY (id);
 
@@ -509,6 +1051,15 @@ ALTER TABLE ONLY workspace_apps
 ALTER TABLE ONLY workspace_apps
     ADD CONSTRAINT workspace_apps_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_archives
+    ADD CONSTRAINT workspace_archives_pkey PRIMARY KEY (workspace_id);
+
+ALTER TABLE ONLY workspace_autostop_extensions
+    ADD CONSTRAINT workspace_autostop_extensions_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY workspace_batch_results
+    ADD CONSTRAINT workspace_batch_results_pkey PRIMARY KEY (operation_id, workspace_id);
+
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_job_id_key UNIQUE (job_id);
 
@@ -518,15 +1069,28 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
 
+ALTER TABLE ONLY workspace_costs
+    ADD CONSTRAINT workspace_costs_pkey PRIMARY KEY (workspace_id, owner_id, start_time);
+
+ALTER TABLE ONLY workspace_favorites
+    ADD CONSTRAINT workspace_favorites_pkey PRIMARY KEY (user_id, workspace_id);
+
 ALTER TABLE ONLY workspace_resource_metadata
     ADD CONSTRAINT workspace_resource_metadata_pkey PRIMARY KEY (workspace_resource_id, key);
 
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_timeline_events
+    ADD CONSTRAINT workspace_timeline_events_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_pkey PRIMARY KEY (id);
 
+CREATE UNIQUE INDEX groups_name_deployment_idx ON groups USING btree (name) WHERE ((organization_id IS NULL) AND (deleted_at IS NULL));
+
+CREATE UNIQUE INDEX groups_name_organization_id_idx ON groups USING btree (name, organization_id) WHERE (deleted_at IS NULL);
+
 CREATE INDEX idx_agent_stats_created_at ON agent_stats USING btree (created_at);
 
 CREATE INDEX idx_agent_stats_user_id ON agent_stats USING btree (user_id);
@@ -541,6 +1105,8 @@ CREATE INDEX idx_audit_log_user_id ON audit_logs USING btree (user_id);
 
 CREATE INDEX idx_audit_logs_time_desc ON audit_logs USING btree ("time" DESC);
 
+CREATE UNIQUE INDEX idx_organization_aliases_name_lower ON organization_aliases USING btree (lower(name));
+
 CREATE INDEX idx_organization_member_organization_id_uuid ON organization_members USING btree (organization_id);
 
 CREATE INDEX idx_organization_member_user_id_uuid ON organization_members USING btree (user_id);
@@ -549,39 +1115,124 @@ CREATE UNIQUE INDEX idx_organization_name ON organizations USING btree (name);
 
 CREATE UNIQUE INDEX idx_organization_name_lower ON organizations USING btree (lower(name));
 
+CREATE INDEX idx_organization_webhook_deliveries_webhook_id ON organization_webhook_deliveries USING btree (webhook_id, created_at DESC);
+
 CREATE UNIQUE INDEX idx_users_email ON users USING btree (email) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX idx_users_username ON users USING btree (username) WHERE (deleted = false);
 
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries USING btree (webhook_id, created_at DESC);
+
+CREATE INDEX idx_webhook_queue_run_at ON webhook_queue USING btree (run_at);
+
+CREATE INDEX oauth2_provider_app_tokens_app_id_idx ON oauth2_provider_app_tokens USING btree (app_id);
+
+CREATE UNIQUE INDEX organizations_single_default_org ON organizations USING btree (is_default) WHERE (is_default = true);
+
+CREATE UNIQUE INDEX role_requests_pending_idx ON role_requests USING btree (user_id, role) WHERE (status = 'pending'::role_request_status);
+
 CREATE UNIQUE INDEX templates_organization_id_name_idx ON templates USING btree (organization_id, lower((name)::text)) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX users_email_lower_idx ON users USING btree (lower(email)) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX users_username_lower_idx ON users USING btree (lower(username)) WHERE (deleted = false);
 
+CREATE INDEX workspace_agent_usage_samples_agent_id_created_at_idx ON workspace_agent_usage_samples USING btree (agent_id, created_at);
+
+CREATE INDEX workspace_autostop_extensions_workspace_id_created_at_idx ON workspace_autostop_extensions USING btree (workspace_id, created_at);
+
+CREATE INDEX workspace_costs_organization_id_start_time_idx ON workspace_costs USING btree (organization_id, start_time);
+
+CREATE INDEX workspace_timeline_events_workspace_id_created_at_idx ON workspace_timeline_events USING btree (workspace_id, created_at);
+
+CREATE INDEX workspaces_labels_idx ON workspaces USING gin (labels);
+
 CREATE UNIQUE INDEX workspaces_owner_id_lower_idx ON workspaces USING btree (owner_id, lower((name)::text)) WHERE (deleted = false);
 
+ALTER TABLE ONLY api_keys
+    ADD CONSTRAINT api_keys_scope_organization_id_fkey FOREIGN KEY (scope_organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_user_id_uuid_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY everyone_group_exclusions
+    ADD CONSTRAINT everyone_group_exclusions_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY everyone_group_exclusions
+    ADD CONSTRAINT everyone_group_exclusions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY gitsshkeys
     ADD CONSTRAINT gitsshkeys_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
 
+ALTER TABLE ONLY group_join_requests
+    ADD CONSTRAINT group_join_requests_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY group_join_requests
+    ADD CONSTRAINT group_join_requests_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY group_members
     ADD CONSTRAINT group_members_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY group_members
     ADD CONSTRAINT group_members_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY group_webhooks
+    ADD CONSTRAINT group_webhooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY groups
     ADD CONSTRAINT groups_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY groups
+    ADD CONSTRAINT groups_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES groups(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_codes
+    ADD CONSTRAINT oauth2_provider_app_codes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_api_key_id_fkey FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_invites
+    ADD CONSTRAINT organization_invites_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_aliases
+    ADD CONSTRAINT organization_aliases_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_invites
+    ADD CONSTRAINT organization_invites_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_ip_allowlists
+    ADD CONSTRAINT organization_ip_allowlists_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_organization_id_uuid_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_user_id_uuid_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY organization_oidc_configs
+    ADD CONSTRAINT organization_oidc_configs_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_quotas
+    ADD CONSTRAINT organization_quotas_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_template_defaults
+    ADD CONSTRAINT organization_template_defaults_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_webhook_deliveries
+    ADD CONSTRAINT organization_webhook_deliveries_webhook_id_fkey FOREIGN KEY (webhook_id) REFERENCES organization_webhooks(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_webhooks
+    ADD CONSTRAINT organization_webhooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_workspace_name_policies
+    ADD CONSTRAINT organization_workspace_name_policies_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY parameter_schemas
     ADD CONSTRAINT parameter_schemas_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
@@ -591,6 +1242,42 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY role_requests
+    ADD CONSTRAINT role_requests_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY role_requests
+    ADD CONSTRAINT role_requests_reviewed_by_fkey FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY role_requests
+    ADD CONSTRAINT role_requests_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_archive_policies
+    ADD CONSTRAINT template_archive_policies_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_auto_rebuild_policies
+    ADD CONSTRAINT template_auto_rebuild_policies_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_autostop_extension_policies
+    ADD CONSTRAINT template_autostop_extension_policies_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_deprecations
+    ADD CONSTRAINT template_deprecations_successor_id_fkey FOREIGN KEY (successor_id) REFERENCES templates(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_deprecations
+    ADD CONSTRAINT template_deprecations_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_favorites
+    ADD CONSTRAINT template_favorites_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_favorites
+    ADD CONSTRAINT template_favorites_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_maintenance_windows
+    ADD CONSTRAINT template_maintenance_windows_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_resource_costs
+    ADD CONSTRAINT template_resource_costs_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_versions
     ADD CONSTRAINT template_versions_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE RESTRICT;
 
@@ -600,6 +1287,9 @@ ALTER TABLE ONLY template_versions
 ALTER TABLE ONLY template_versions
     ADD CONSTRAINT template_versions_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_workspace_name_policies
+    ADD CONSTRAINT template_workspace_name_policies_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY templates
     ADD CONSTRAINT templates_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE RESTRICT;
 
@@ -609,12 +1299,30 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_webhook_id_fkey FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_startup_script_results
+    ADD CONSTRAINT workspace_agent_startup_script_results_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_usage_samples
+    ADD CONSTRAINT workspace_agent_usage_samples_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_resource_id_fkey FOREIGN KEY (resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY workspace_apps
     ADD CONSTRAINT workspace_apps_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_archives
+    ADD CONSTRAINT workspace_archives_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_autostop_extensions
+    ADD CONSTRAINT workspace_autostop_extensions_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_batch_results
+    ADD CONSTRAINT workspace_batch_results_operation_id_fkey FOREIGN KEY (operation_id) REFERENCES operations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
@@ -624,12 +1332,24 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_costs
+    ADD CONSTRAINT workspace_costs_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_favorites
+    ADD CONSTRAINT workspace_favorites_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_favorites
+    ADD CONSTRAINT workspace_favorites_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_resource_metadata
     ADD CONSTRAINT workspace_resource_metadata_workspace_resource_id_fkey FOREIGN KEY (workspace_resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_timeline_events
+    ADD CONSTRAINT workspace_timeline_events_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE RESTRICT;
 
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE groups DROP COLUMN parent_id;
+
+COMMIT;
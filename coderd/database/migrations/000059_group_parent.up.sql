@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Groups can be nested under a parent group in the same organization.
+-- Members of a child group inherit the permissions of every ancestor.
+ALTER TABLE groups ADD COLUMN parent_id uuid REFERENCES groups(id) ON DELETE SET NULL;
+
+COMMIT;
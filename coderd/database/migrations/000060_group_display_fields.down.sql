@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE groups DROP COLUMN description;
+ALTER TABLE groups DROP COLUMN avatar_url;
+ALTER TABLE groups DROP COLUMN display_name;
+
+COMMIT;
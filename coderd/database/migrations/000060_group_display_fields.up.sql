@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE groups ADD COLUMN display_name text NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN avatar_url text NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN description text NOT NULL DEFAULT '';
+
+COMMIT;
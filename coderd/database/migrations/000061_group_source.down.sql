@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE groups DROP COLUMN source;
+DROP TYPE group_source;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Groups synced from an identity provider have their membership managed by
+-- the sync and can't be edited manually.
+CREATE TYPE group_source AS ENUM (
+	'user',
+	'oidc'
+);
+
+ALTER TABLE groups ADD COLUMN source group_source NOT NULL DEFAULT 'user';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE group_members DROP COLUMN expires_at;
+
+COMMIT;
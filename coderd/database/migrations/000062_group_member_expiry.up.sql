@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Memberships with an expiry are removed by a background reaper once it
+-- passes, and are ignored for authorization in the meantime.
+ALTER TABLE group_members ADD COLUMN expires_at timestamptz;
+
+COMMIT;
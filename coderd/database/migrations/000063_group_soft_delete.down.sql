@@ -0,0 +1,10 @@
+BEGIN;
+
+DELETE FROM groups WHERE deleted_at IS NOT NULL;
+
+DROP INDEX groups_name_organization_id_idx;
+ALTER TABLE groups ADD CONSTRAINT groups_name_organization_id_key UNIQUE (name, organization_id);
+
+ALTER TABLE groups DROP COLUMN deleted_at;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Deleted groups are kept, along with their members and template ACL
+-- entries, until a retention sweep removes them so they can be restored.
+ALTER TABLE groups ADD COLUMN deleted_at timestamptz;
+
+-- Names only need to be unique among groups that haven't been deleted.
+ALTER TABLE groups DROP CONSTRAINT groups_name_organization_id_key;
+CREATE UNIQUE INDEX groups_name_organization_id_idx ON groups (name, organization_id) WHERE deleted_at IS NULL;
+
+COMMIT;
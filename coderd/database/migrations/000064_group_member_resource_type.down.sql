@@ -0,0 +1,7 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+
+DELETE FROM
+    audit_logs
+WHERE
+    resource_type = 'group_member';
@@ -0,0 +1 @@
+ALTER TABLE group_members ADD COLUMN roles text[] DEFAULT '{}'::text[] NOT NULL;
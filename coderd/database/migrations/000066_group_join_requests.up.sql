@@ -0,0 +1,10 @@
+-- Pending requests from users asking to join a group. Requests are removed
+-- once a group admin approves or denies them.
+CREATE TABLE IF NOT EXISTS group_join_requests (
+	id uuid NOT NULL,
+	group_id uuid NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (group_id, user_id)
+);
@@ -0,0 +1,3 @@
+-- Arbitrary string key/value pairs that external tooling can attach to a
+-- group, such as a cost center or an owner's email.
+ALTER TABLE groups ADD COLUMN metadata jsonb DEFAULT '{}'::jsonb NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX groups_name_deployment_idx;
+
+DELETE FROM groups WHERE organization_id IS NULL;
+
+ALTER TABLE groups ALTER COLUMN organization_id SET NOT NULL;
@@ -0,0 +1,5 @@
+-- Groups without an organization span the whole deployment, so their
+-- members can come from any organization.
+ALTER TABLE groups ALTER COLUMN organization_id DROP NOT NULL;
+
+CREATE UNIQUE INDEX groups_name_deployment_idx ON groups USING btree (name) WHERE (organization_id IS NULL AND deleted_at IS NULL);
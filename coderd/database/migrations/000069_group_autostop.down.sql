@@ -0,0 +1,2 @@
+ALTER TABLE groups DROP COLUMN max_ttl;
+ALTER TABLE groups DROP COLUMN autostop_schedule;
@@ -0,0 +1,6 @@
+-- A weekly cron schedule at which workspaces owned by members of the group
+-- are stopped, e.g. every weekday evening.
+ALTER TABLE groups ADD COLUMN autostop_schedule text DEFAULT ''::text NOT NULL;
+-- The longest a workspace owned by a member of the group can run before it's
+-- stopped, in nanoseconds. Zero means the group doesn't limit it.
+ALTER TABLE groups ADD COLUMN max_ttl bigint DEFAULT 0 NOT NULL;
@@ -0,0 +1,11 @@
+-- Endpoints that are sent an event whenever a group in the organization is
+-- created, deleted, renamed, or has its members changed. Payloads are signed
+-- with the secret.
+CREATE TABLE IF NOT EXISTS group_webhooks (
+	id uuid NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	url text NOT NULL,
+	secret text NOT NULL,
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS everyone_group_exclusions;
@@ -0,0 +1,8 @@
+-- Organization members that are left out of the organization's "Everyone"
+-- group, e.g. service accounts or contractors.
+CREATE TABLE IF NOT EXISTS everyone_group_exclusions (
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (organization_id, user_id)
+);
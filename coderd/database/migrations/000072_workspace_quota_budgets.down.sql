@@ -0,0 +1,2 @@
+ALTER TABLE templates DROP COLUMN quota_weight;
+ALTER TABLE groups DROP COLUMN quota_allowance;
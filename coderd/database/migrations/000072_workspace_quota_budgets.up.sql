@@ -0,0 +1,4 @@
+-- Members of a group can spend the group's allowance on workspaces. Each
+-- workspace costs the weight of its template.
+ALTER TABLE groups ADD COLUMN quota_allowance integer DEFAULT 0 NOT NULL;
+ALTER TABLE templates ADD COLUMN quota_weight integer DEFAULT 1 NOT NULL;
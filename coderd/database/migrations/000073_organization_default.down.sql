@@ -0,0 +1,3 @@
+DROP INDEX organizations_single_default_org;
+
+ALTER TABLE organizations DROP COLUMN is_default;
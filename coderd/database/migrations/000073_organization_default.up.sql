@@ -0,0 +1,8 @@
+ALTER TABLE organizations ADD COLUMN is_default boolean DEFAULT false NOT NULL;
+
+-- The oldest organization is the one that was created with the first user.
+UPDATE organizations SET is_default = true WHERE id = (
+	SELECT id FROM organizations ORDER BY created_at ASC LIMIT 1
+);
+
+CREATE UNIQUE INDEX organizations_single_default_org ON organizations USING btree (is_default) WHERE (is_default = true);
@@ -0,0 +1,2 @@
+ALTER TABLE organizations DROP COLUMN icon;
+ALTER TABLE organizations DROP COLUMN display_name;
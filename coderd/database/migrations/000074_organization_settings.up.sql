@@ -0,0 +1,2 @@
+ALTER TABLE organizations ADD COLUMN display_name text DEFAULT '' NOT NULL;
+ALTER TABLE organizations ADD COLUMN icon text DEFAULT '' NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS organization_invites (
+	id uuid NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	hashed_token bytea NOT NULL,
+	-- Only users with an email in this domain can redeem the invite. Any
+	-- user can if it's empty.
+	email_domain text NOT NULL DEFAULT '',
+	roles text[] NOT NULL DEFAULT '{}',
+	created_by uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	expires_at timestamp with time zone,
+	PRIMARY KEY (id),
+	UNIQUE (hashed_token)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS organization_quotas (
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	-- A limit of 0 means the organization is unlimited.
+	max_workspaces integer NOT NULL DEFAULT 0,
+	max_running_workspaces integer NOT NULL DEFAULT 0,
+	-- Running workspaces consume the quota weight of their template in
+	-- credits.
+	compute_credits integer NOT NULL DEFAULT 0,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (organization_id)
+);
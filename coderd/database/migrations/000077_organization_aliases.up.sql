@@ -0,0 +1,10 @@
+-- Organizations keep the names they had before being renamed, so URLs
+-- that reference an old name still resolve.
+CREATE TABLE IF NOT EXISTS organization_aliases (
+	name text NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (name)
+);
+
+CREATE UNIQUE INDEX idx_organization_aliases_name_lower ON organization_aliases USING btree (lower(name));
@@ -0,0 +1,17 @@
+-- Organizations can federate against their own identity provider. Users
+-- are routed to it by the domain of their email address.
+CREATE TABLE IF NOT EXISTS organization_oidc_configs (
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	issuer_url text NOT NULL,
+	client_id text NOT NULL,
+	client_secret text NOT NULL,
+	scopes text[] NOT NULL DEFAULT '{}',
+	-- Email domains are stored lowercase.
+	email_domains text[] NOT NULL DEFAULT '{}',
+	username_field text NOT NULL DEFAULT 'preferred_username',
+	email_field text NOT NULL DEFAULT 'email',
+	allow_signups boolean NOT NULL DEFAULT false,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (organization_id)
+);
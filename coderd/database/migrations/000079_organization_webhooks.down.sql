@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS organization_webhook_deliveries;
+DROP TABLE IF EXISTS organization_webhooks;
@@ -0,0 +1,29 @@
+-- Endpoints that are sent an event whenever a workspace is created or
+-- deleted, a member is added or removed, or a template is published in the
+-- organization. Payloads are signed with the secret.
+CREATE TABLE IF NOT EXISTS organization_webhooks (
+	id uuid NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	url text NOT NULL,
+	secret text NOT NULL,
+	-- An empty list subscribes to every event.
+	events text[] NOT NULL DEFAULT '{}',
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id)
+);
+
+-- Every attempt at delivering an event to a webhook.
+CREATE TABLE IF NOT EXISTS organization_webhook_deliveries (
+	id uuid NOT NULL,
+	webhook_id uuid NOT NULL REFERENCES organization_webhooks (id) ON DELETE CASCADE,
+	event_id uuid NOT NULL,
+	event_type text NOT NULL,
+	attempt integer NOT NULL,
+	-- Zero when no response was received.
+	status_code integer NOT NULL DEFAULT 0,
+	error text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_organization_webhook_deliveries_webhook_id ON organization_webhook_deliveries USING btree (webhook_id, created_at DESC);
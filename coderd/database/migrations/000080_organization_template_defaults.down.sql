@@ -0,0 +1 @@
+DROP TABLE IF EXISTS organization_template_defaults;
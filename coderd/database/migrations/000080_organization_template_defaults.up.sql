@@ -0,0 +1,15 @@
+-- Defaults and policies that templates and workspaces of an organization
+-- inherit when they're created. Durations are in nanoseconds, zero leaves
+-- the deployment default in place.
+CREATE TABLE IF NOT EXISTS organization_template_defaults (
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	default_ttl bigint NOT NULL DEFAULT 0,
+	max_ttl bigint NOT NULL DEFAULT 0,
+	min_autostart_interval bigint NOT NULL DEFAULT 0,
+	-- Parameter values added to template imports that don't set them.
+	parameter_values jsonb NOT NULL DEFAULT '[]'::jsonb,
+	-- Maps parameter names to the only values they can be set to.
+	allowed_parameter_values jsonb NOT NULL DEFAULT '{}'::jsonb,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (organization_id)
+);
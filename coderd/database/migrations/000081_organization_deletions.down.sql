@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS organization_deletions;
+DROP TYPE IF EXISTS organization_deletion_status;
@@ -0,0 +1,24 @@
+CREATE TYPE organization_deletion_status AS ENUM (
+	'running',
+	'succeeded',
+	'failed'
+);
+
+-- Tracks the background jobs that delete organizations. Rows don't reference
+-- the organization, so the outcome stays visible after it's gone.
+CREATE TABLE IF NOT EXISTS organization_deletions (
+	organization_id uuid NOT NULL,
+	initiator_id uuid NOT NULL,
+	status organization_deletion_status NOT NULL DEFAULT 'running',
+	error text NOT NULL DEFAULT '',
+	workspaces_total bigint NOT NULL DEFAULT 0,
+	workspaces_deleted bigint NOT NULL DEFAULT 0,
+	templates_total bigint NOT NULL DEFAULT 0,
+	templates_deleted bigint NOT NULL DEFAULT 0,
+	members_total bigint NOT NULL DEFAULT 0,
+	members_deleted bigint NOT NULL DEFAULT 0,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	completed_at timestamp with time zone,
+	PRIMARY KEY (organization_id)
+);
@@ -0,0 +1,4 @@
+-- Site and organization roles granted to every member of a group, including
+-- the members of groups nested beneath it.
+ALTER TABLE groups
+	ADD COLUMN IF NOT EXISTS roles text[] DEFAULT '{}' NOT NULL;
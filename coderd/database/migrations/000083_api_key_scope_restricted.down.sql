@@ -0,0 +1,6 @@
+ALTER TABLE api_keys
+	DROP COLUMN IF EXISTS scope_organization_id,
+	DROP COLUMN IF EXISTS scope_permissions;
+
+-- You cannot safely remove values from enums https://www.postgresql.org/docs/current/datatype-enum.html
+-- You cannot create a new type and do a rename because objects depend on this type now.
@@ -0,0 +1,8 @@
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'restricted';
+
+ALTER TABLE api_keys
+	ADD COLUMN IF NOT EXISTS scope_permissions text[] DEFAULT '{}' NOT NULL,
+	ADD COLUMN IF NOT EXISTS scope_organization_id uuid REFERENCES organizations (id) ON DELETE CASCADE;
+
+COMMENT ON COLUMN api_keys.scope_permissions IS 'scope_permissions lists the "<resource>:<action>" pairs a key with the restricted scope is allowed to use.';
+COMMENT ON COLUMN api_keys.scope_organization_id IS 'scope_organization_id limits the scope_permissions of a restricted key to a single organization.';
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS role_requests;
+DROP TYPE IF EXISTS role_request_status;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+DELETE FROM
+	audit_logs
+WHERE
+	resource_type = 'organization_member';
@@ -0,0 +1,25 @@
+ALTER TYPE resource_type ADD VALUE IF NOT EXISTS 'organization_member';
+
+CREATE TYPE role_request_status AS ENUM (
+	'pending',
+	'approved',
+	'denied'
+);
+
+CREATE TABLE IF NOT EXISTS role_requests (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	-- organization_id is set for organization roles, and is the organization
+	-- in the role name.
+	organization_id uuid REFERENCES organizations (id) ON DELETE CASCADE,
+	role text NOT NULL,
+	reason text DEFAULT ''::text NOT NULL,
+	status role_request_status DEFAULT 'pending'::role_request_status NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	reviewed_by uuid REFERENCES users (id) ON DELETE SET NULL,
+	reviewed_at timestamp with time zone,
+	PRIMARY KEY (id)
+);
+
+-- A user can only have one pending request for a role.
+CREATE UNIQUE INDEX IF NOT EXISTS role_requests_pending_idx ON role_requests USING btree (user_id, role) WHERE (status = 'pending'::role_request_status);
@@ -0,0 +1,31 @@
+-- Deployment-wide endpoints that are sent events about workspaces, builds,
+-- users, and groups. Payloads are signed with the secret.
+CREATE TABLE IF NOT EXISTS webhooks (
+	id uuid NOT NULL,
+	url text NOT NULL,
+	secret text NOT NULL,
+	-- An empty list subscribes to every event.
+	events text[] NOT NULL DEFAULT '{}',
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id)
+);
+
+-- Every attempt at delivering an event to a webhook. The payload is kept so
+-- the event can be redelivered.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id uuid NOT NULL,
+	webhook_id uuid NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+	event_id uuid NOT NULL,
+	event_type text NOT NULL,
+	payload jsonb NOT NULL,
+	attempt integer NOT NULL,
+	-- Whether the attempt was requested through the redelivery API.
+	redelivery boolean NOT NULL DEFAULT false,
+	-- Zero when no response was received.
+	status_code integer NOT NULL DEFAULT 0,
+	error text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries USING btree (webhook_id, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE organization_deletions DROP COLUMN IF EXISTS operation_id;
+DROP TABLE IF EXISTS operations;
+DROP TYPE IF EXISTS operation_status;
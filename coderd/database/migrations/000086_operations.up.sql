@@ -0,0 +1,31 @@
+CREATE TYPE operation_status AS ENUM (
+	'running',
+	'succeeded',
+	'failed'
+);
+
+-- Long-running actions that are started by a request and finish in the
+-- background. Rows don't reference the resource, so the outcome stays
+-- visible after it's gone.
+CREATE TABLE IF NOT EXISTS operations (
+	id uuid NOT NULL,
+	type text NOT NULL,
+	status operation_status NOT NULL DEFAULT 'running',
+	initiator_id uuid NOT NULL,
+	-- Null for deployment-wide operations.
+	organization_id uuid,
+	-- The resource the operation acts on, e.g. the organization being
+	-- deleted.
+	resource_id uuid NOT NULL,
+	progress_completed bigint NOT NULL DEFAULT 0,
+	progress_total bigint NOT NULL DEFAULT 0,
+	error text NOT NULL DEFAULT '',
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	completed_at timestamp with time zone,
+	PRIMARY KEY (id)
+);
+
+-- The operation that reports the progress of the deletion. It's replaced
+-- when a failed deletion is retried.
+ALTER TABLE organization_deletions ADD COLUMN operation_id uuid;
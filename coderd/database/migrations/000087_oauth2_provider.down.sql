@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS oauth2_provider_app_tokens;
+DROP TABLE IF EXISTS oauth2_provider_app_codes;
+DROP TABLE IF EXISTS oauth2_provider_apps;
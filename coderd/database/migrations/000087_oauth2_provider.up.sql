@@ -0,0 +1,38 @@
+-- Applications that users can sign in to with Coder.
+CREATE TABLE IF NOT EXISTS oauth2_provider_apps (
+	id uuid NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	name varchar(64) NOT NULL,
+	icon text NOT NULL DEFAULT '',
+	callback_url text NOT NULL,
+	hashed_secret bytea NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (name)
+);
+
+-- Authorization codes that users granted to applications. They're exchanged
+-- for an API key once.
+CREATE TABLE IF NOT EXISTS oauth2_provider_app_codes (
+	id uuid NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	expires_at timestamp with time zone NOT NULL,
+	hashed_secret bytea NOT NULL,
+	app_id uuid NOT NULL REFERENCES oauth2_provider_apps (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	redirect_uri text NOT NULL,
+	-- Space separated, like the scope parameter of the request.
+	scope text NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (hashed_secret)
+);
+
+-- The API keys that were issued to applications.
+CREATE TABLE IF NOT EXISTS oauth2_provider_app_tokens (
+	api_key_id text NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+	app_id uuid NOT NULL REFERENCES oauth2_provider_apps (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (api_key_id)
+);
+
+CREATE INDEX oauth2_provider_app_tokens_app_id_idx ON oauth2_provider_app_tokens (app_id);
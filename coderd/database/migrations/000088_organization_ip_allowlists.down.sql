@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS organization_ip_allowlists;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+DELETE FROM
+	audit_logs
+WHERE
+	action = 'deny';
@@ -0,0 +1,12 @@
+-- Requests that are rejected by an organization's IP allowlist are audited
+-- with the deny action.
+ALTER TYPE audit_action ADD VALUE IF NOT EXISTS 'deny';
+
+-- The networks that API and workspace app requests to an organization's
+-- resources must come from. An empty list allows every network.
+CREATE TABLE IF NOT EXISTS organization_ip_allowlists (
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	cidrs text[] NOT NULL DEFAULT '{}'::text[],
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (organization_id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workspace_batch_results;
+DROP TYPE IF EXISTS workspace_batch_result_status;
+DROP TYPE IF EXISTS workspace_batch_action;
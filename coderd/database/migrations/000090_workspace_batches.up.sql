@@ -0,0 +1,29 @@
+CREATE TYPE workspace_batch_action AS ENUM (
+	'start',
+	'stop',
+	'delete',
+	'update'
+);
+
+CREATE TYPE workspace_batch_result_status AS ENUM (
+	'pending',
+	'running',
+	'succeeded',
+	'failed'
+);
+
+-- The outcome of a bulk action for each workspace it matched. Workspaces
+-- aren't referenced, so results of deleted workspaces are kept.
+CREATE TABLE IF NOT EXISTS workspace_batch_results (
+	operation_id uuid NOT NULL REFERENCES operations (id) ON DELETE CASCADE,
+	workspace_id uuid NOT NULL,
+	workspace_name text NOT NULL,
+	action workspace_batch_action NOT NULL,
+	status workspace_batch_result_status NOT NULL DEFAULT 'pending',
+	-- The build started for the workspace, if any.
+	build_id uuid,
+	error text NOT NULL DEFAULT '',
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (operation_id, workspace_id)
+);
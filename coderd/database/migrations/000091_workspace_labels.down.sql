@@ -0,0 +1,5 @@
+ALTER TABLE templates DROP COLUMN IF EXISTS workspace_labels;
+
+DROP INDEX IF EXISTS workspaces_labels_idx;
+
+ALTER TABLE workspaces DROP COLUMN IF EXISTS labels;
@@ -0,0 +1,8 @@
+-- Free-form key/value labels, set by the owner of the workspace or by its
+-- template, to filter workspaces by e.g. team or cost center.
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS labels jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS workspaces_labels_idx ON workspaces USING gin (labels);
+
+-- Labels applied to every workspace of the template. Owners can't change them.
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS workspace_labels jsonb NOT NULL DEFAULT '{}'::jsonb;
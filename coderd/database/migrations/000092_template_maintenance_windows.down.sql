@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS template_maintenance_windows;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+UPDATE
+	workspace_builds
+SET
+	reason = 'autostart'
+WHERE
+	reason = 'maintenance';
@@ -0,0 +1,14 @@
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'maintenance';
+
+-- A recurring window during which running workspaces on old versions of the
+-- template are restarted with its active version.
+CREATE TABLE IF NOT EXISTS template_maintenance_windows (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates (id) ON DELETE CASCADE,
+	-- A weekly cron schedule of when the window opens.
+	schedule text NOT NULL,
+	-- How long the window stays open, in nanoseconds.
+	duration bigint NOT NULL,
+	-- Workspaces of members of these groups are left as they are.
+	exempt_group_ids uuid[] NOT NULL DEFAULT '{}',
+	updated_at timestamp with time zone NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workspace_costs;
+DROP TABLE IF EXISTS template_resource_costs;
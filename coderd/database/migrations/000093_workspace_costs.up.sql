@@ -0,0 +1,27 @@
+-- The hourly cost of a running workspace resource of a type, e.g.
+-- "aws_instance", in the organization's currency.
+CREATE TABLE IF NOT EXISTS template_resource_costs (
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	resource_type text NOT NULL,
+	hourly_cost double precision NOT NULL,
+	PRIMARY KEY (template_id, resource_type)
+);
+
+-- The cost of running a workspace, accrued into hourly buckets. The owner is
+-- recorded so costs stay with the user who ran the workspace when it's
+-- transferred.
+CREATE TABLE IF NOT EXISTS workspace_costs (
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	owner_id uuid NOT NULL,
+	organization_id uuid NOT NULL,
+	template_id uuid NOT NULL,
+	-- The start of the hour the cost accrued in.
+	start_time timestamp with time zone NOT NULL,
+	cost double precision NOT NULL,
+	running_seconds bigint NOT NULL,
+	-- The time up to which the cost of the workspace was accrued.
+	accrued_until timestamp with time zone NOT NULL,
+	PRIMARY KEY (workspace_id, owner_id, start_time)
+);
+
+CREATE INDEX IF NOT EXISTS workspace_costs_organization_id_start_time_idx ON workspace_costs USING btree (organization_id, start_time);
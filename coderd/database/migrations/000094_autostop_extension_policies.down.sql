@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workspace_autostop_extensions;
+DROP TABLE IF EXISTS template_autostop_extension_policies;
@@ -0,0 +1,24 @@
+-- Limits how far users can extend the deadlines of the template's running
+-- workspaces. Templates without a policy use the deployment's.
+CREATE TABLE IF NOT EXISTS template_autostop_extension_policies (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates (id) ON DELETE CASCADE,
+	-- How many times a workspace's deadline can be extended in a day. Zero is
+	-- unlimited.
+	max_extensions_per_day integer NOT NULL,
+	-- How far a workspace's deadline can be extended in total in a day, in
+	-- nanoseconds. Zero is unlimited.
+	max_extension_per_day bigint NOT NULL,
+	updated_at timestamp with time zone NOT NULL
+);
+
+-- Every extension of a running workspace's deadline, to enforce the policies.
+CREATE TABLE IF NOT EXISTS workspace_autostop_extensions (
+	id uuid NOT NULL PRIMARY KEY,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL,
+	-- How far the deadline was extended, in nanoseconds.
+	extension bigint NOT NULL,
+	created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS workspace_autostop_extensions_workspace_id_created_at_idx ON workspace_autostop_extensions USING btree (workspace_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS template_archive_policies;
+DROP TABLE IF EXISTS workspace_archives;
+DROP TYPE IF EXISTS workspace_archive_status;
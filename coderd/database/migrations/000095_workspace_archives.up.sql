@@ -0,0 +1,35 @@
+CREATE TYPE workspace_archive_status AS ENUM (
+	'archiving',
+	'archived',
+	'restoring',
+	'failed'
+);
+
+-- Workspaces whose resources were destroyed after their data and state were
+-- exported to object storage. Restored workspaces have no archive.
+CREATE TABLE IF NOT EXISTS workspace_archives (
+	workspace_id uuid NOT NULL PRIMARY KEY REFERENCES workspaces (id) ON DELETE CASCADE,
+	status workspace_archive_status NOT NULL,
+	-- The prefix of the archive's objects.
+	object_key text NOT NULL,
+	-- The build that archives or restores the workspace.
+	build_id uuid NOT NULL,
+	error text NOT NULL DEFAULT '',
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	archived_at timestamp with time zone,
+	-- When the archive is purged and the workspace deleted, if ever.
+	expires_at timestamp with time zone
+);
+
+-- Archives the template's dormant workspaces and limits how long archives
+-- are kept.
+CREATE TABLE IF NOT EXISTS template_archive_policies (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates (id) ON DELETE CASCADE,
+	-- How long a workspace must be unused before it's archived, in
+	-- nanoseconds. Zero never archives workspaces automatically.
+	dormancy_threshold bigint NOT NULL,
+	-- How long archives are kept, in nanoseconds. Zero keeps them forever.
+	retention bigint NOT NULL,
+	updated_at timestamp with time zone NOT NULL
+);
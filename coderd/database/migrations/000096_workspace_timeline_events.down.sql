@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workspace_timeline_events;
+DROP TYPE IF EXISTS workspace_timeline_event_type;
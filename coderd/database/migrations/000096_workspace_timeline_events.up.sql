@@ -0,0 +1,20 @@
+CREATE TYPE workspace_timeline_event_type AS ENUM (
+	'agent_connected',
+	'agent_disconnected',
+	'app_opened'
+);
+
+-- Events of a workspace that aren't recorded anywhere else. Builds and
+-- autostop extensions are merged with them into the workspace's timeline.
+CREATE TABLE IF NOT EXISTS workspace_timeline_events (
+	id uuid NOT NULL PRIMARY KEY,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	type workspace_timeline_event_type NOT NULL,
+	agent_id uuid NOT NULL,
+	-- The user that opened the app, for app events.
+	user_id uuid,
+	app_name text NOT NULL DEFAULT '',
+	created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS workspace_timeline_events_workspace_id_created_at_idx ON workspace_timeline_events USING btree (workspace_id, created_at);
@@ -0,0 +1,15 @@
+-- Samples of the resource usage of the machines agents run on, taken each
+-- time an agent reports its stats. Only the latest samples are kept.
+CREATE TABLE IF NOT EXISTS workspace_agent_usage_samples (
+	id uuid NOT NULL PRIMARY KEY,
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	cpu_used double precision NOT NULL,
+	cpu_total double precision NOT NULL,
+	memory_used bigint NOT NULL,
+	memory_total bigint NOT NULL,
+	disk_used bigint NOT NULL,
+	disk_total bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS workspace_agent_usage_samples_agent_id_created_at_idx ON workspace_agent_usage_samples USING btree (agent_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS template_workspace_name_policies;
+DROP TABLE IF EXISTS organization_workspace_name_policies;
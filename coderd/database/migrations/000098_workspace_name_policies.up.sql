@@ -0,0 +1,21 @@
+-- Policies for the names of workspaces, enforced when workspaces are created
+-- or renamed. The policies of a workspace's organization and template both
+-- apply.
+CREATE TABLE IF NOT EXISTS organization_workspace_name_policies (
+	organization_id uuid NOT NULL PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+	-- A regular expression names must match. Empty allows any name.
+	pattern text NOT NULL DEFAULT '',
+	-- Names must start with the prefix, after its placeholders like
+	-- {username} are replaced.
+	prefix text NOT NULL DEFAULT '',
+	reserved_names text[] NOT NULL DEFAULT '{}',
+	updated_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS template_workspace_name_policies (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates (id) ON DELETE CASCADE,
+	pattern text NOT NULL DEFAULT '',
+	prefix text NOT NULL DEFAULT '',
+	reserved_names text[] NOT NULL DEFAULT '{}',
+	updated_at timestamp with time zone NOT NULL
+);
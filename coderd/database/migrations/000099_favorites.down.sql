@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS template_favorites;
+DROP TABLE IF EXISTS workspace_favorites;
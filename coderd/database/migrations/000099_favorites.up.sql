@@ -0,0 +1,17 @@
+-- Workspaces and templates users pinned to the top of their lists. Position
+-- is the user's custom order, lowest first.
+CREATE TABLE IF NOT EXISTS workspace_favorites (
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	position integer NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (user_id, workspace_id)
+);
+
+CREATE TABLE IF NOT EXISTS template_favorites (
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	position integer NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (user_id, template_id)
+);
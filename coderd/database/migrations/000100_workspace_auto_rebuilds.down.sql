@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS workspace_agent_startup_script_results;
+DROP TABLE IF EXISTS template_auto_rebuild_policies;
+DROP TYPE IF EXISTS auto_rebuild_action;
+
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+UPDATE
+	workspace_builds
+SET
+	reason = 'autostop'
+WHERE
+	reason = 'agent_failure'
+	AND transition = 'stop';
+UPDATE
+	workspace_builds
+SET
+	reason = 'autostart'
+WHERE
+	reason = 'agent_failure';
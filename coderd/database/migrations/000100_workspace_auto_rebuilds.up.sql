@@ -0,0 +1,28 @@
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'agent_failure';
+
+CREATE TYPE auto_rebuild_action AS ENUM (
+	-- Stop the workspace, then start it again.
+	'restart',
+	-- Start the workspace again without stopping it first.
+	'rebuild'
+);
+
+-- Workspaces of the template whose agents failed after this many starts in a
+-- row are restarted or rebuilt automatically.
+CREATE TABLE IF NOT EXISTS template_auto_rebuild_policies (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates (id) ON DELETE CASCADE,
+	-- Zero disables automatic rebuilds.
+	failure_threshold integer NOT NULL,
+	-- How long agents have to connect after a start, in nanoseconds.
+	agent_timeout bigint NOT NULL,
+	action auto_rebuild_action NOT NULL,
+	updated_at timestamp with time zone NOT NULL
+);
+
+-- The exit code of the startup script of each agent, reported by the agent
+-- when the script exits.
+CREATE TABLE IF NOT EXISTS workspace_agent_startup_script_results (
+	agent_id uuid NOT NULL PRIMARY KEY REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	exit_code integer NOT NULL,
+	completed_at timestamp with time zone NOT NULL
+);
@@ -0,0 +1,12 @@
+-- Workspaces can't be created from deprecated templates, and the owners of
+-- existing workspaces are asked to migrate them to the successor.
+CREATE TABLE IF NOT EXISTS template_deprecations (
+	template_id uuid NOT NULL PRIMARY KEY REFERENCES templates (id) ON DELETE CASCADE,
+	message text NOT NULL,
+	successor_id uuid REFERENCES templates (id) ON DELETE SET NULL,
+	-- Maps the names of the template's parameters to the names of the
+	-- successor's parameters their values are migrated to.
+	parameter_mapping jsonb NOT NULL DEFAULT '{}'::jsonb,
+	deprecated_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL
+);
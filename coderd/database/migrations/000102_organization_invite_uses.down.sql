@@ -0,0 +1,3 @@
+ALTER TABLE organization_invites
+	DROP COLUMN IF EXISTS max_uses,
+	DROP COLUMN IF EXISTS uses;
@@ -0,0 +1,5 @@
+-- Invites can be limited to a number of uses. A max_uses of 0 doesn't limit
+-- them.
+ALTER TABLE organization_invites
+	ADD COLUMN max_uses integer NOT NULL DEFAULT 0,
+	ADD COLUMN uses integer NOT NULL DEFAULT 0;
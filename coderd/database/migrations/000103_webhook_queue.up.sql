@@ -0,0 +1,21 @@
+-- Events waiting to be delivered to a webhook. Deployment, organization, and
+-- group webhooks share the queue. A row is removed once its event is
+-- delivered or its retries run out, so pending retries survive a restart.
+CREATE TABLE IF NOT EXISTS webhook_queue (
+	id uuid NOT NULL,
+	-- The kind of webhook, which decides the table webhook_id refers to.
+	kind text NOT NULL,
+	webhook_id uuid NOT NULL,
+	event_id uuid NOT NULL,
+	event_type text NOT NULL,
+	payload jsonb NOT NULL,
+	-- How many attempts were made to deliver the event.
+	attempts integer NOT NULL DEFAULT 0,
+	-- When the next attempt is due. It's pushed back while an attempt is
+	-- made, so other replicas don't make it too.
+	run_at timestamptz NOT NULL,
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_webhook_queue_run_at ON webhook_queue USING btree (run_at);
@@ -0,0 +1,3 @@
+ALTER TABLE oauth2_provider_app_codes
+	DROP COLUMN code_challenge,
+	DROP COLUMN code_challenge_method;
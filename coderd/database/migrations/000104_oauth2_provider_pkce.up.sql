@@ -0,0 +1,5 @@
+-- The PKCE challenge of the authorization request, as defined by RFC 7636.
+-- Both are empty when the app didn't send one.
+ALTER TABLE oauth2_provider_app_codes
+	ADD COLUMN code_challenge text NOT NULL DEFAULT '',
+	ADD COLUMN code_challenge_method text NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE organization_deletions
+	DROP COLUMN worker_id,
+	DROP COLUMN heartbeat_at;
@@ -0,0 +1,5 @@
+-- The replica that runs a deletion claims it and records a heartbeat while
+-- it runs, so other replicas only take over deletions whose replica stopped.
+ALTER TABLE organization_deletions
+	ADD COLUMN worker_id uuid,
+	ADD COLUMN heartbeat_at timestamp with time zone;
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/google/uuid"
+
 	"github.com/coder/coder/coderd/rbac"
 )
 
@@ -66,11 +68,28 @@ func (s APIKeyScope) ToRBAC() rbac.Scope {
 		return rbac.ScopeAll
 	case APIKeyScopeApplicationConnect:
 		return rbac.ScopeApplicationConnect
+	case APIKeyScopeRestricted:
+		// The permissions are stored on the key, see APIKey.RBACScope. The
+		// bare scope name denies everything.
+		return rbac.ScopeRestricted
 	default:
 		panic("developer error: unknown scope type " + string(s))
 	}
 }
 
+// RBACScope returns the scope used to authorize requests made with the key.
+func (k APIKey) RBACScope() rbac.Scope {
+	if k.Scope != APIKeyScopeRestricted {
+		return k.Scope.ToRBAC()
+	}
+	scope, err := rbac.RestrictedScope(k.ScopeOrganizationID, k.ScopePermissions)
+	if err != nil {
+		// A restricted key without valid permissions can't do anything.
+		return rbac.ScopeRestricted
+	}
+	return scope
+}
+
 func (t Template) RBACObject() rbac.Object {
 	obj := rbac.ResourceTemplate
 	return obj.InOrg(t.OrganizationID).
@@ -84,29 +103,146 @@ func (TemplateVersion) RBACObject(template Template) rbac.Object {
 }
 
 func (g Group) RBACObject() rbac.Object {
-	return rbac.ResourceGroup.InOrg(g.OrganizationID)
+	return groupRBACObject(rbac.ResourceGroup, g.OrganizationID)
+}
+
+// MembersRBACObject returns the object used to authorize changes to the
+// group's members. The given group admins are granted access through the
+// object's user ACL.
+func (g Group) MembersRBACObject(adminIDs []uuid.UUID) rbac.Object {
+	acl := make(map[string][]rbac.Action, len(adminIDs))
+	for _, id := range adminIDs {
+		acl[id.String()] = []rbac.Action{rbac.ActionCreate, rbac.ActionRead, rbac.ActionUpdate, rbac.ActionDelete}
+	}
+	return groupRBACObject(rbac.ResourceGroupMember, g.OrganizationID).WithACLUserList(acl)
+}
+
+func (g GetGroupsRow) RBACObject() rbac.Object {
+	return groupRBACObject(rbac.ResourceGroup, g.OrganizationID)
+}
+
+// groupRBACObject scopes a group resource to the group's organization.
+// Deployment-wide groups have no organization, so only site-wide roles
+// apply to them.
+func groupRBACObject(resource rbac.Object, organizationID uuid.NullUUID) rbac.Object {
+	if !organizationID.Valid {
+		return resource
+	}
+	return resource.InOrg(organizationID.UUID)
+}
+
+// WorkspaceACL is a map of user or group IDs to the actions they're granted
+// on a workspace someone else owns. Entries always grant read, and the
+// WorkspaceACLAction markers grant access to the workspace's apps and
+// terminal.
+type WorkspaceACL map[string][]rbac.Action
+
+const (
+	// WorkspaceACLActionApplicationConnect grants access to the apps of the
+	// workspace.
+	WorkspaceACLActionApplicationConnect rbac.Action = "application_connect"
+	// WorkspaceACLActionSSH grants SSH and terminal access to the workspace.
+	WorkspaceACLActionSSH rbac.Action = "ssh"
+)
+
+// grants returns the entries with the marker as an ACL that grants creating
+// the object derived from the workspace, e.g. a connection to an app.
+func (acl WorkspaceACL) grants(marker rbac.Action) map[string][]rbac.Action {
+	granted := map[string][]rbac.Action{}
+	for id, actions := range acl {
+		for _, action := range actions {
+			if action == marker {
+				granted[id] = []rbac.Action{rbac.ActionCreate}
+				break
+			}
+		}
+	}
+	return granted
+}
+
+func (w Workspace) UserACL() WorkspaceACL {
+	acl := WorkspaceACL{}
+	if len(w.userACL) == 0 {
+		return acl
+	}
+	err := json.Unmarshal(w.userACL, &acl)
+	if err != nil {
+		panic(fmt.Sprintf("failed to unmarshal workspace.userACL: %v", err.Error()))
+	}
+	return acl
+}
+
+func (w Workspace) GroupACL() WorkspaceACL {
+	acl := WorkspaceACL{}
+	if len(w.groupACL) == 0 {
+		return acl
+	}
+	err := json.Unmarshal(w.groupACL, &acl)
+	if err != nil {
+		panic(fmt.Sprintf("failed to unmarshal workspace.groupACL: %v", err.Error()))
+	}
+	return acl
+}
+
+func (w Workspace) SetUserACL(acl WorkspaceACL) Workspace {
+	raw, err := json.Marshal(acl)
+	if err != nil {
+		panic(fmt.Sprintf("marshal user acl: %v", err))
+	}
+	w.userACL = raw
+	return w
+}
+
+func (w Workspace) SetGroupACL(acl WorkspaceACL) Workspace {
+	raw, err := json.Marshal(acl)
+	if err != nil {
+		panic(fmt.Sprintf("marshal group acl: %v", err))
+	}
+	w.groupACL = raw
+	return w
 }
 
 func (w Workspace) RBACObject() rbac.Object {
-	return rbac.ResourceWorkspace.InOrg(w.OrganizationID).WithOwner(w.OwnerID.String())
+	return rbac.ResourceWorkspace.InOrg(w.OrganizationID).WithOwner(w.OwnerID.String()).
+		WithACLUserList(w.UserACL()).
+		WithGroupACL(w.GroupACL())
 }
 
 func (w Workspace) ExecutionRBAC() rbac.Object {
-	return rbac.ResourceWorkspaceExecution.InOrg(w.OrganizationID).WithOwner(w.OwnerID.String())
+	return rbac.ResourceWorkspaceExecution.InOrg(w.OrganizationID).WithOwner(w.OwnerID.String()).
+		WithACLUserList(w.UserACL().grants(WorkspaceACLActionSSH)).
+		WithGroupACL(w.GroupACL().grants(WorkspaceACLActionSSH))
 }
 
 func (w Workspace) ApplicationConnectRBAC() rbac.Object {
-	return rbac.ResourceWorkspaceApplicationConnect.InOrg(w.OrganizationID).WithOwner(w.OwnerID.String())
+	return rbac.ResourceWorkspaceApplicationConnect.InOrg(w.OrganizationID).WithOwner(w.OwnerID.String()).
+		WithACLUserList(w.UserACL().grants(WorkspaceACLActionApplicationConnect)).
+		WithGroupACL(w.GroupACL().grants(WorkspaceACLActionApplicationConnect))
 }
 
 func (m OrganizationMember) RBACObject() rbac.Object {
 	return rbac.ResourceOrganizationMember.InOrg(m.OrganizationID)
 }
 
+func (m GetOrganizationMembersRow) RBACObject() rbac.Object {
+	return rbac.ResourceOrganizationMember.InOrg(m.OrganizationID)
+}
+
 func (o Organization) RBACObject() rbac.Object {
 	return rbac.ResourceOrganization.InOrg(o.ID)
 }
 
+// RBACObject returns the object of the operation. The initiator can always
+// read it, and organization admins can read the operations of their
+// organization.
+func (o Operation) RBACObject() rbac.Object {
+	object := rbac.ResourceOperation.WithOwner(o.InitiatorID.String())
+	if o.OrganizationID.Valid {
+		object = object.InOrg(o.OrganizationID.UUID)
+	}
+	return object
+}
+
 func (ProvisionerDaemon) RBACObject() rbac.Object {
 	return rbac.ResourceProvisionerDaemon
 }
@@ -125,3 +261,39 @@ func (User) RBACObject() rbac.Object {
 func (License) RBACObject() rbac.Object {
 	return rbac.ResourceLicense
 }
+
+// User returns the user columns of a group membership row.
+func (r GetGroupMembersByGroupIDsRow) User() User {
+	return User{
+		ID:             r.ID,
+		Email:          r.Email,
+		Username:       r.Username,
+		HashedPassword: r.HashedPassword,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		Status:         r.Status,
+		RBACRoles:      r.RBACRoles,
+		LoginType:      r.LoginType,
+		AvatarURL:      r.AvatarURL,
+		Deleted:        r.Deleted,
+		LastSeenAt:     r.LastSeenAt,
+	}
+}
+
+// User returns the user columns of a group membership row.
+func (r GetGroupMembersPageRow) User() User {
+	return User{
+		ID:             r.ID,
+		Email:          r.Email,
+		Username:       r.Username,
+		HashedPassword: r.HashedPassword,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		Status:         r.Status,
+		RBACRoles:      r.RBACRoles,
+		LoginType:      r.LoginType,
+		AvatarURL:      r.AvatarURL,
+		Deleted:        r.Deleted,
+		LastSeenAt:     r.LastSeenAt,
+	}
+}
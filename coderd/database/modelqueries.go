@@ -3,7 +3,8 @@ package database
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/lib/pq"
 
@@ -17,15 +18,99 @@ import (
 // It provides a flexible way to write queries for cases
 // where sqlc proves inadequate.
 type customQuerier interface {
+	groupQuerier
 	templateQuerier
 	workspaceQuerier
 }
 
+type groupQuerier interface {
+	GetAuthorizedGroups(ctx context.Context, arg GetGroupsParams, authorizedFilter rbac.AuthorizeFilter) ([]GetGroupsRow, error)
+}
+
+// groupSQLConfig converts authorize filters to SQL for the groups table,
+// which has no owner or ACL columns. Deployment-wide groups have no
+// organization.
+var groupSQLConfig = rbac.SQLConfig{
+	Variables: []rbac.SQLColumn{
+		{
+			RegoMatch:    regexp.MustCompile(`^input\.object\.acl_group_list\.?(.*)$`),
+			ColumnSelect: "",
+			Type:         rbac.VarTypeSkip,
+		},
+		{
+			RegoMatch:    regexp.MustCompile(`^input\.object\.acl_user_list\.?(.*)$`),
+			ColumnSelect: "",
+			Type:         rbac.VarTypeSkip,
+		},
+		{
+			RegoMatch:    regexp.MustCompile(`^input\.object\.org_owner$`),
+			ColumnSelect: "COALESCE(organization_id :: text, '')",
+			Type:         rbac.VarTypeText,
+		},
+		{
+			RegoMatch:    regexp.MustCompile(`^input\.object\.owner$`),
+			ColumnSelect: "''",
+			Type:         rbac.VarTypeText,
+		},
+	},
+}
+
+// GetAuthorizedGroups returns the groups of GetGroups that the user is
+// authorized to access. The count of each row only includes those groups.
+func (q *sqlQuerier) GetAuthorizedGroups(ctx context.Context, arg GetGroupsParams, authorizedFilter rbac.AuthorizeFilter) ([]GetGroupsRow, error) {
+	// The name comment is for metric tracking
+	query := strings.Replace(getGroups, "-- name: GetGroups :many", "-- name: GetAuthorizedGroups :many", 1)
+	query = strings.Replace(query, "-- @authorize_filter", "AND "+authorizedFilter.SQLString(groupSQLConfig), 1)
+	rows, err := q.db.QueryContext(ctx, query,
+		arg.OrganizationID,
+		arg.Search,
+		arg.AfterID,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("get authorized groups: %w", err)
+	}
+	defer rows.Close()
+	var items []GetGroupsRow
+	for rows.Next() {
+		var i GetGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
+			&i.Description,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 type templateQuerier interface {
 	UpdateTemplateUserACLByID(ctx context.Context, id uuid.UUID, acl TemplateACL) error
 	UpdateTemplateGroupACLByID(ctx context.Context, id uuid.UUID, acl TemplateACL) error
 	GetTemplateGroupRoles(ctx context.Context, id uuid.UUID) ([]TemplateGroup, error)
 	GetTemplateUserRoles(ctx context.Context, id uuid.UUID) ([]TemplateUser, error)
+	GetGroupTemplateRoles(ctx context.Context, groupID uuid.UUID) ([]GroupTemplate, error)
 }
 
 type TemplateUser struct {
@@ -144,7 +229,9 @@ func (q *sqlQuerier) GetTemplateGroupRoles(ctx context.Context, id uuid.UUID) ([
 				)
 		) AS perms
 	ON
-		groups.id::text = perms.key;
+		groups.id::text = perms.key
+	WHERE
+		groups.deleted_at IS NULL;
 	`
 
 	var tgs []TemplateGroup
@@ -156,8 +243,98 @@ func (q *sqlQuerier) GetTemplateGroupRoles(ctx context.Context, id uuid.UUID) ([
 	return tgs, nil
 }
 
+type GroupTemplate struct {
+	Template
+	Actions Actions `db:"actions"`
+}
+
+// GetGroupTemplateRoles returns the templates the group has been granted a
+// role on, along with the role.
+func (q *sqlQuerier) GetGroupTemplateRoles(ctx context.Context, groupID uuid.UUID) ([]GroupTemplate, error) {
+	const query = `
+	SELECT
+		templates.group_acl -> $1::text AS actions,
+		templates.id,
+		templates.created_at,
+		templates.updated_at,
+		templates.organization_id,
+		templates.deleted,
+		templates.name,
+		templates.provisioner,
+		templates.active_version_id,
+		templates.description,
+		templates.max_ttl,
+		templates.min_autostart_interval,
+		templates.created_by,
+		templates.icon,
+		templates.quota_weight
+	FROM
+		templates
+	WHERE
+		templates.group_acl ? $1::text
+	AND
+		templates.deleted = false
+	ORDER BY
+		templates.name ASC;
+	`
+
+	var gts []GroupTemplate
+	err := q.db.SelectContext(ctx, &gts, query, groupID.String())
+	if err != nil {
+		return nil, xerrors.Errorf("select group templates: %w", err)
+	}
+
+	return gts, nil
+}
+
 type workspaceQuerier interface {
 	GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspacesParams, authorizedFilter rbac.AuthorizeFilter) ([]Workspace, error)
+	UpdateWorkspaceUserACLByID(ctx context.Context, id uuid.UUID, acl WorkspaceACL) error
+	UpdateWorkspaceGroupACLByID(ctx context.Context, id uuid.UUID, acl WorkspaceACL) error
+}
+
+func (q *sqlQuerier) UpdateWorkspaceUserACLByID(ctx context.Context, id uuid.UUID, acl WorkspaceACL) error {
+	raw, err := json.Marshal(acl)
+	if err != nil {
+		return xerrors.Errorf("marshal user acl: %w", err)
+	}
+
+	const query = `
+UPDATE
+	workspaces
+SET
+	user_acl = $2
+WHERE
+	id = $1`
+
+	_, err = q.db.ExecContext(ctx, query, id.String(), raw)
+	if err != nil {
+		return xerrors.Errorf("update user acl: %w", err)
+	}
+
+	return nil
+}
+
+func (q *sqlQuerier) UpdateWorkspaceGroupACLByID(ctx context.Context, id uuid.UUID, acl WorkspaceACL) error {
+	raw, err := json.Marshal(acl)
+	if err != nil {
+		return xerrors.Errorf("marshal group acl: %w", err)
+	}
+
+	const query = `
+UPDATE
+	workspaces
+SET
+	group_acl = $2
+WHERE
+	id = $1`
+
+	_, err = q.db.ExecContext(ctx, query, id.String(), raw)
+	if err != nil {
+		return xerrors.Errorf("update group acl: %w", err)
+	}
+
+	return nil
 }
 
 // GetAuthorizedWorkspaces returns all workspaces that the user is authorized to access.
@@ -165,7 +342,8 @@ type workspaceQuerier interface {
 // clause.
 func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspacesParams, authorizedFilter rbac.AuthorizeFilter) ([]Workspace, error) {
 	// The name comment is for metric tracking
-	query := fmt.Sprintf("-- name: GetAuthorizedWorkspaces :many\n%s AND %s", getWorkspaces, authorizedFilter.SQLString(rbac.NoACLConfig()))
+	query := strings.Replace(getWorkspaces, "-- name: GetWorkspaces :many", "-- name: GetAuthorizedWorkspaces :many", 1)
+	query = strings.Replace(query, "-- @authorize_filter", "AND "+authorizedFilter.SQLString(rbac.DefaultConfig()), 1)
 	rows, err := q.db.QueryContext(ctx, query,
 		arg.Deleted,
 		arg.OwnerID,
@@ -173,6 +351,8 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 		arg.TemplateName,
 		pq.Array(arg.TemplateIds),
 		arg.Name,
+		arg.Labels,
+		arg.LimitOpt,
 	)
 	if err != nil {
 		return nil, xerrors.Errorf("get authorized workspaces: %w", err)
@@ -193,6 +373,9 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 			&i.AutostartSchedule,
 			&i.Ttl,
 			&i.LastUsedAt,
+			&i.userACL,
+			&i.groupACL,
+			&i.Labels,
 		); err != nil {
 			return nil, err
 		}
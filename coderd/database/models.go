@@ -20,6 +20,7 @@ type APIKeyScope string
 const (
 	APIKeyScopeAll                APIKeyScope = "all"
 	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
+	APIKeyScopeRestricted         APIKeyScope = "restricted"
 )
 
 func (e *APIKeyScope) Scan(src interface{}) error {
@@ -40,6 +41,7 @@ const (
 	AuditActionCreate AuditAction = "create"
 	AuditActionWrite  AuditAction = "write"
 	AuditActionDelete AuditAction = "delete"
+	AuditActionDeny   AuditAction = "deny"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -54,12 +56,33 @@ func (e *AuditAction) Scan(src interface{}) error {
 	return nil
 }
 
+type AutoRebuildAction string
+
+const (
+	AutoRebuildActionRestart AutoRebuildAction = "restart"
+	AutoRebuildActionRebuild AutoRebuildAction = "rebuild"
+)
+
+func (e *AutoRebuildAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AutoRebuildAction(s)
+	case string:
+		*e = AutoRebuildAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AutoRebuildAction: %T", src)
+	}
+	return nil
+}
+
 type BuildReason string
 
 const (
-	BuildReasonInitiator BuildReason = "initiator"
-	BuildReasonAutostart BuildReason = "autostart"
-	BuildReasonAutostop  BuildReason = "autostop"
+	BuildReasonInitiator    BuildReason = "initiator"
+	BuildReasonAutostart    BuildReason = "autostart"
+	BuildReasonAutostop     BuildReason = "autostop"
+	BuildReasonMaintenance  BuildReason = "maintenance"
+	BuildReasonAgentFailure BuildReason = "agent_failure"
 )
 
 func (e *BuildReason) Scan(src interface{}) error {
@@ -74,6 +97,25 @@ func (e *BuildReason) Scan(src interface{}) error {
 	return nil
 }
 
+type GroupSource string
+
+const (
+	GroupSourceUser GroupSource = "user"
+	GroupSourceOIDC GroupSource = "oidc"
+)
+
+func (e *GroupSource) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GroupSource(s)
+	case string:
+		*e = GroupSource(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GroupSource: %T", src)
+	}
+	return nil
+}
+
 type LogLevel string
 
 const (
@@ -136,6 +178,46 @@ func (e *LoginType) Scan(src interface{}) error {
 	return nil
 }
 
+type OperationStatus string
+
+const (
+	OperationStatusRunning   OperationStatus = "running"
+	OperationStatusSucceeded OperationStatus = "succeeded"
+	OperationStatusFailed    OperationStatus = "failed"
+)
+
+func (e *OperationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OperationStatus(s)
+	case string:
+		*e = OperationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OperationStatus: %T", src)
+	}
+	return nil
+}
+
+type OrganizationDeletionStatus string
+
+const (
+	OrganizationDeletionStatusRunning   OrganizationDeletionStatus = "running"
+	OrganizationDeletionStatusSucceeded OrganizationDeletionStatus = "succeeded"
+	OrganizationDeletionStatusFailed    OrganizationDeletionStatus = "failed"
+)
+
+func (e *OrganizationDeletionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrganizationDeletionStatus(s)
+	case string:
+		*e = OrganizationDeletionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrganizationDeletionStatus: %T", src)
+	}
+	return nil
+}
+
 type ParameterDestinationScheme string
 
 const (
@@ -274,13 +356,15 @@ func (e *ProvisionerType) Scan(src interface{}) error {
 type ResourceType string
 
 const (
-	ResourceTypeOrganization    ResourceType = "organization"
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeGitSshKey       ResourceType = "git_ssh_key"
-	ResourceTypeApiKey          ResourceType = "api_key"
+	ResourceTypeOrganization       ResourceType = "organization"
+	ResourceTypeTemplate           ResourceType = "template"
+	ResourceTypeTemplateVersion    ResourceType = "template_version"
+	ResourceTypeUser               ResourceType = "user"
+	ResourceTypeWorkspace          ResourceType = "workspace"
+	ResourceTypeGitSshKey          ResourceType = "git_ssh_key"
+	ResourceTypeApiKey             ResourceType = "api_key"
+	ResourceTypeGroupMember        ResourceType = "group_member"
+	ResourceTypeOrganizationMember ResourceType = "organization_member"
 )
 
 func (e *ResourceType) Scan(src interface{}) error {
@@ -295,6 +379,26 @@ func (e *ResourceType) Scan(src interface{}) error {
 	return nil
 }
 
+type RoleRequestStatus string
+
+const (
+	RoleRequestStatusPending  RoleRequestStatus = "pending"
+	RoleRequestStatusApproved RoleRequestStatus = "approved"
+	RoleRequestStatusDenied   RoleRequestStatus = "denied"
+)
+
+func (e *RoleRequestStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RoleRequestStatus(s)
+	case string:
+		*e = RoleRequestStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RoleRequestStatus: %T", src)
+	}
+	return nil
+}
+
 type UserStatus string
 
 const (
@@ -335,6 +439,89 @@ func (e *WorkspaceAppHealth) Scan(src interface{}) error {
 	return nil
 }
 
+type WorkspaceArchiveStatus string
+
+const (
+	WorkspaceArchiveStatusArchiving WorkspaceArchiveStatus = "archiving"
+	WorkspaceArchiveStatusArchived  WorkspaceArchiveStatus = "archived"
+	WorkspaceArchiveStatusRestoring WorkspaceArchiveStatus = "restoring"
+	WorkspaceArchiveStatusFailed    WorkspaceArchiveStatus = "failed"
+)
+
+func (e *WorkspaceArchiveStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceArchiveStatus(s)
+	case string:
+		*e = WorkspaceArchiveStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceArchiveStatus: %T", src)
+	}
+	return nil
+}
+
+type WorkspaceBatchAction string
+
+const (
+	WorkspaceBatchActionStart  WorkspaceBatchAction = "start"
+	WorkspaceBatchActionStop   WorkspaceBatchAction = "stop"
+	WorkspaceBatchActionDelete WorkspaceBatchAction = "delete"
+	WorkspaceBatchActionUpdate WorkspaceBatchAction = "update"
+)
+
+func (e *WorkspaceBatchAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceBatchAction(s)
+	case string:
+		*e = WorkspaceBatchAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceBatchAction: %T", src)
+	}
+	return nil
+}
+
+type WorkspaceBatchResultStatus string
+
+const (
+	WorkspaceBatchResultStatusPending   WorkspaceBatchResultStatus = "pending"
+	WorkspaceBatchResultStatusRunning   WorkspaceBatchResultStatus = "running"
+	WorkspaceBatchResultStatusSucceeded WorkspaceBatchResultStatus = "succeeded"
+	WorkspaceBatchResultStatusFailed    WorkspaceBatchResultStatus = "failed"
+)
+
+func (e *WorkspaceBatchResultStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceBatchResultStatus(s)
+	case string:
+		*e = WorkspaceBatchResultStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceBatchResultStatus: %T", src)
+	}
+	return nil
+}
+
+type WorkspaceTimelineEventType string
+
+const (
+	WorkspaceTimelineEventTypeAgentConnected    WorkspaceTimelineEventType = "agent_connected"
+	WorkspaceTimelineEventTypeAgentDisconnected WorkspaceTimelineEventType = "agent_disconnected"
+	WorkspaceTimelineEventTypeAppOpened         WorkspaceTimelineEventType = "app_opened"
+)
+
+func (e *WorkspaceTimelineEventType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceTimelineEventType(s)
+	case string:
+		*e = WorkspaceTimelineEventType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceTimelineEventType: %T", src)
+	}
+	return nil
+}
+
 type WorkspaceTransition string
 
 const (
@@ -368,6 +555,10 @@ type APIKey struct {
 	LifetimeSeconds int64       `db:"lifetime_seconds" json:"lifetime_seconds"`
 	IPAddress       pqtype.Inet `db:"ip_address" json:"ip_address"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
+	// scope_permissions lists the "<resource>:<action>" pairs a key with the restricted scope is allowed to use.
+	ScopePermissions []string `db:"scope_permissions" json:"scope_permissions"`
+	// scope_organization_id limits the scope_permissions of a restricted key to a single organization.
+	ScopeOrganizationID uuid.NullUUID `db:"scope_organization_id" json:"scope_organization_id"`
 }
 
 type AgentStat struct {
@@ -398,6 +589,12 @@ type AuditLog struct {
 	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
 }
 
+type EveryoneGroupExclusion struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
 type File struct {
 	Hash      string    `db:"hash" json:"hash"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -415,14 +612,42 @@ type GitSSHKey struct {
 }
 
 type Group struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	Name           string    `db:"name" json:"name"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	ID               uuid.UUID       `db:"id" json:"id"`
+	Name             string          `db:"name" json:"name"`
+	OrganizationID   uuid.NullUUID   `db:"organization_id" json:"organization_id"`
+	ParentID         uuid.NullUUID   `db:"parent_id" json:"parent_id"`
+	DisplayName      string          `db:"display_name" json:"display_name"`
+	AvatarURL        string          `db:"avatar_url" json:"avatar_url"`
+	Description      string          `db:"description" json:"description"`
+	Source           GroupSource     `db:"source" json:"source"`
+	DeletedAt        sql.NullTime    `db:"deleted_at" json:"deleted_at"`
+	Metadata         json.RawMessage `db:"metadata" json:"metadata"`
+	AutostopSchedule string          `db:"autostop_schedule" json:"autostop_schedule"`
+	MaxTtl           int64           `db:"max_ttl" json:"max_ttl"`
+	QuotaAllowance   int32           `db:"quota_allowance" json:"quota_allowance"`
+	Roles            []string        `db:"roles" json:"roles"`
+}
+
+type GroupJoinRequest struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	GroupID   uuid.UUID `db:"group_id" json:"group_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
 type GroupMember struct {
-	UserID  uuid.UUID `db:"user_id" json:"user_id"`
-	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+	UserID    uuid.UUID    `db:"user_id" json:"user_id"`
+	GroupID   uuid.UUID    `db:"group_id" json:"group_id"`
+	ExpiresAt sql.NullTime `db:"expires_at" json:"expires_at"`
+	Roles     []string     `db:"roles" json:"roles"`
+}
+
+type GroupWebhook struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Url            string    `db:"url" json:"url"`
+	Secret         string    `db:"secret" json:"secret"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
 }
 
 type License struct {
@@ -433,12 +658,103 @@ type License struct {
 	Exp time.Time `db:"exp" json:"exp"`
 }
 
+type OAuth2ProviderApp struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	Name         string    `db:"name" json:"name"`
+	Icon         string    `db:"icon" json:"icon"`
+	CallbackURL  string    `db:"callback_url" json:"callback_url"`
+	HashedSecret []byte    `db:"hashed_secret" json:"hashed_secret"`
+}
+
+type OAuth2ProviderAppCode struct {
+	ID                  uuid.UUID `db:"id" json:"id"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt           time.Time `db:"expires_at" json:"expires_at"`
+	HashedSecret        []byte    `db:"hashed_secret" json:"hashed_secret"`
+	AppID               uuid.UUID `db:"app_id" json:"app_id"`
+	UserID              uuid.UUID `db:"user_id" json:"user_id"`
+	RedirectURI         string    `db:"redirect_uri" json:"redirect_uri"`
+	Scope               string    `db:"scope" json:"scope"`
+	CodeChallenge       string    `db:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string    `db:"code_challenge_method" json:"code_challenge_method"`
+}
+
+type OAuth2ProviderAppToken struct {
+	APIKeyID  string    `db:"api_key_id" json:"api_key_id"`
+	AppID     uuid.UUID `db:"app_id" json:"app_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type Operation struct {
+	ID                uuid.UUID       `db:"id" json:"id"`
+	Type              string          `db:"type" json:"type"`
+	Status            OperationStatus `db:"status" json:"status"`
+	InitiatorID       uuid.UUID       `db:"initiator_id" json:"initiator_id"`
+	OrganizationID    uuid.NullUUID   `db:"organization_id" json:"organization_id"`
+	ResourceID        uuid.UUID       `db:"resource_id" json:"resource_id"`
+	ProgressCompleted int64           `db:"progress_completed" json:"progress_completed"`
+	ProgressTotal     int64           `db:"progress_total" json:"progress_total"`
+	Error             string          `db:"error" json:"error"`
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+	CompletedAt       sql.NullTime    `db:"completed_at" json:"completed_at"`
+}
+
 type Organization struct {
 	ID          uuid.UUID `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
 	Description string    `db:"description" json:"description"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	IsDefault   bool      `db:"is_default" json:"is_default"`
+	DisplayName string    `db:"display_name" json:"display_name"`
+	Icon        string    `db:"icon" json:"icon"`
+}
+
+type OrganizationAlias struct {
+	Name           string    `db:"name" json:"name"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+type OrganizationDeletion struct {
+	OrganizationID    uuid.UUID                  `db:"organization_id" json:"organization_id"`
+	InitiatorID       uuid.UUID                  `db:"initiator_id" json:"initiator_id"`
+	Status            OrganizationDeletionStatus `db:"status" json:"status"`
+	Error             string                     `db:"error" json:"error"`
+	WorkspacesTotal   int64                      `db:"workspaces_total" json:"workspaces_total"`
+	WorkspacesDeleted int64                      `db:"workspaces_deleted" json:"workspaces_deleted"`
+	TemplatesTotal    int64                      `db:"templates_total" json:"templates_total"`
+	TemplatesDeleted  int64                      `db:"templates_deleted" json:"templates_deleted"`
+	MembersTotal      int64                      `db:"members_total" json:"members_total"`
+	MembersDeleted    int64                      `db:"members_deleted" json:"members_deleted"`
+	CreatedAt         time.Time                  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time                  `db:"updated_at" json:"updated_at"`
+	CompletedAt       sql.NullTime               `db:"completed_at" json:"completed_at"`
+	OperationID       uuid.NullUUID              `db:"operation_id" json:"operation_id"`
+	WorkerID          uuid.NullUUID              `db:"worker_id" json:"worker_id"`
+	HeartbeatAt       sql.NullTime               `db:"heartbeat_at" json:"heartbeat_at"`
+}
+
+type OrganizationInvite struct {
+	ID             uuid.UUID    `db:"id" json:"id"`
+	OrganizationID uuid.UUID    `db:"organization_id" json:"organization_id"`
+	HashedToken    []byte       `db:"hashed_token" json:"hashed_token"`
+	EmailDomain    string       `db:"email_domain" json:"email_domain"`
+	Roles          []string     `db:"roles" json:"roles"`
+	CreatedBy      uuid.UUID    `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	ExpiresAt      sql.NullTime `db:"expires_at" json:"expires_at"`
+	MaxUses        int32        `db:"max_uses" json:"max_uses"`
+	Uses           int32        `db:"uses" json:"uses"`
+}
+
+type OrganizationIpAllowlist struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Cidrs          []string  `db:"cidrs" json:"cidrs"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type OrganizationMember struct {
@@ -449,6 +765,66 @@ type OrganizationMember struct {
 	Roles          []string  `db:"roles" json:"roles"`
 }
 
+type OrganizationOIDCConfig struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	IssuerURL      string    `db:"issuer_url" json:"issuer_url"`
+	ClientID       string    `db:"client_id" json:"client_id"`
+	ClientSecret   string    `db:"client_secret" json:"client_secret"`
+	Scopes         []string  `db:"scopes" json:"scopes"`
+	EmailDomains   []string  `db:"email_domains" json:"email_domains"`
+	UsernameField  string    `db:"username_field" json:"username_field"`
+	EmailField     string    `db:"email_field" json:"email_field"`
+	AllowSignups   bool      `db:"allow_signups" json:"allow_signups"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type OrganizationQuota struct {
+	OrganizationID       uuid.UUID `db:"organization_id" json:"organization_id"`
+	MaxWorkspaces        int32     `db:"max_workspaces" json:"max_workspaces"`
+	MaxRunningWorkspaces int32     `db:"max_running_workspaces" json:"max_running_workspaces"`
+	ComputeCredits       int32     `db:"compute_credits" json:"compute_credits"`
+	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type OrganizationTemplateDefault struct {
+	OrganizationID         uuid.UUID       `db:"organization_id" json:"organization_id"`
+	DefaultTtl             int64           `db:"default_ttl" json:"default_ttl"`
+	MaxTtl                 int64           `db:"max_ttl" json:"max_ttl"`
+	MinAutostartInterval   int64           `db:"min_autostart_interval" json:"min_autostart_interval"`
+	ParameterValues        json.RawMessage `db:"parameter_values" json:"parameter_values"`
+	AllowedParameterValues json.RawMessage `db:"allowed_parameter_values" json:"allowed_parameter_values"`
+	UpdatedAt              time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+type OrganizationWebhook struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Url            string    `db:"url" json:"url"`
+	Secret         string    `db:"secret" json:"secret"`
+	Events         []string  `db:"events" json:"events"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+type OrganizationWebhookDelivery struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	WebhookID  uuid.UUID `db:"webhook_id" json:"webhook_id"`
+	EventID    uuid.UUID `db:"event_id" json:"event_id"`
+	EventType  string    `db:"event_type" json:"event_type"`
+	Attempt    int32     `db:"attempt" json:"attempt"`
+	StatusCode int32     `db:"status_code" json:"status_code"`
+	Error      string    `db:"error" json:"error"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+type OrganizationWorkspaceNamePolicy struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Pattern        string    `db:"pattern" json:"pattern"`
+	Prefix         string    `db:"prefix" json:"prefix"`
+	ReservedNames  []string  `db:"reserved_names" json:"reserved_names"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type ParameterSchema struct {
 	ID                       uuid.UUID                  `db:"id" json:"id"`
 	CreatedAt                time.Time                  `db:"created_at" json:"created_at"`
@@ -517,6 +893,18 @@ type ProvisionerJobLog struct {
 	Output    string    `db:"output" json:"output"`
 }
 
+type RoleRequest struct {
+	ID             uuid.UUID         `db:"id" json:"id"`
+	UserID         uuid.UUID         `db:"user_id" json:"user_id"`
+	OrganizationID uuid.NullUUID     `db:"organization_id" json:"organization_id"`
+	Role           string            `db:"role" json:"role"`
+	Reason         string            `db:"reason" json:"reason"`
+	Status         RoleRequestStatus `db:"status" json:"status"`
+	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
+	ReviewedBy     uuid.NullUUID     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt     sql.NullTime      `db:"reviewed_at" json:"reviewed_at"`
+}
+
 type SiteConfig struct {
 	Key   string `db:"key" json:"key"`
 	Value string `db:"value" json:"value"`
@@ -538,6 +926,60 @@ type Template struct {
 	Icon                 string          `db:"icon" json:"icon"`
 	userACL              json.RawMessage `db:"user_acl" json:"user_acl"`
 	groupACL             json.RawMessage `db:"group_acl" json:"group_acl"`
+	QuotaWeight          int32           `db:"quota_weight" json:"quota_weight"`
+	WorkspaceLabels      json.RawMessage `db:"workspace_labels" json:"workspace_labels"`
+}
+
+type TemplateArchivePolicy struct {
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	DormancyThreshold int64     `db:"dormancy_threshold" json:"dormancy_threshold"`
+	Retention         int64     `db:"retention" json:"retention"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TemplateAutoRebuildPolicy struct {
+	TemplateID       uuid.UUID         `db:"template_id" json:"template_id"`
+	FailureThreshold int32             `db:"failure_threshold" json:"failure_threshold"`
+	AgentTimeout     int64             `db:"agent_timeout" json:"agent_timeout"`
+	Action           AutoRebuildAction `db:"action" json:"action"`
+	UpdatedAt        time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+type TemplateAutostopExtensionPolicy struct {
+	TemplateID          uuid.UUID `db:"template_id" json:"template_id"`
+	MaxExtensionsPerDay int32     `db:"max_extensions_per_day" json:"max_extensions_per_day"`
+	MaxExtensionPerDay  int64     `db:"max_extension_per_day" json:"max_extension_per_day"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TemplateDeprecation struct {
+	TemplateID       uuid.UUID       `db:"template_id" json:"template_id"`
+	Message          string          `db:"message" json:"message"`
+	SuccessorID      uuid.NullUUID   `db:"successor_id" json:"successor_id"`
+	ParameterMapping json.RawMessage `db:"parameter_mapping" json:"parameter_mapping"`
+	DeprecatedAt     time.Time       `db:"deprecated_at" json:"deprecated_at"`
+	UpdatedAt        time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+type TemplateFavorite struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	Position   int32     `db:"position" json:"position"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+type TemplateMaintenanceWindow struct {
+	TemplateID     uuid.UUID   `db:"template_id" json:"template_id"`
+	Schedule       string      `db:"schedule" json:"schedule"`
+	Duration       int64       `db:"duration" json:"duration"`
+	ExemptGroupIds []uuid.UUID `db:"exempt_group_ids" json:"exempt_group_ids"`
+	UpdatedAt      time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+type TemplateResourceCost struct {
+	TemplateID   uuid.UUID `db:"template_id" json:"template_id"`
+	ResourceType string    `db:"resource_type" json:"resource_type"`
+	HourlyCost   float64   `db:"hourly_cost" json:"hourly_cost"`
 }
 
 type TemplateVersion struct {
@@ -552,6 +994,14 @@ type TemplateVersion struct {
 	CreatedBy      uuid.NullUUID `db:"created_by" json:"created_by"`
 }
 
+type TemplateWorkspaceNamePolicy struct {
+	TemplateID    uuid.UUID `db:"template_id" json:"template_id"`
+	Pattern       string    `db:"pattern" json:"pattern"`
+	Prefix        string    `db:"prefix" json:"prefix"`
+	ReservedNames []string  `db:"reserved_names" json:"reserved_names"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type User struct {
 	ID             uuid.UUID      `db:"id" json:"id"`
 	Email          string         `db:"email" json:"email"`
@@ -577,17 +1027,53 @@ type UserLink struct {
 }
 
 type Workspace struct {
-	ID                uuid.UUID      `db:"id" json:"id"`
-	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
-	OwnerID           uuid.UUID      `db:"owner_id" json:"owner_id"`
-	OrganizationID    uuid.UUID      `db:"organization_id" json:"organization_id"`
-	TemplateID        uuid.UUID      `db:"template_id" json:"template_id"`
-	Deleted           bool           `db:"deleted" json:"deleted"`
-	Name              string         `db:"name" json:"name"`
-	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
-	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
-	LastUsedAt        time.Time      `db:"last_used_at" json:"last_used_at"`
+	ID                uuid.UUID       `db:"id" json:"id"`
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+	OwnerID           uuid.UUID       `db:"owner_id" json:"owner_id"`
+	OrganizationID    uuid.UUID       `db:"organization_id" json:"organization_id"`
+	TemplateID        uuid.UUID       `db:"template_id" json:"template_id"`
+	Deleted           bool            `db:"deleted" json:"deleted"`
+	Name              string          `db:"name" json:"name"`
+	AutostartSchedule sql.NullString  `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl               sql.NullInt64   `db:"ttl" json:"ttl"`
+	LastUsedAt        time.Time       `db:"last_used_at" json:"last_used_at"`
+	userACL           json.RawMessage `db:"user_acl" json:"user_acl"`
+	groupACL          json.RawMessage `db:"group_acl" json:"group_acl"`
+	Labels            json.RawMessage `db:"labels" json:"labels"`
+}
+
+type Webhook struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Url       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret"`
+	Events    []string  `db:"events" json:"events"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID       `db:"id" json:"id"`
+	WebhookID  uuid.UUID       `db:"webhook_id" json:"webhook_id"`
+	EventID    uuid.UUID       `db:"event_id" json:"event_id"`
+	EventType  string          `db:"event_type" json:"event_type"`
+	Payload    json.RawMessage `db:"payload" json:"payload"`
+	Attempt    int32           `db:"attempt" json:"attempt"`
+	Redelivery bool            `db:"redelivery" json:"redelivery"`
+	StatusCode int32           `db:"status_code" json:"status_code"`
+	Error      string          `db:"error" json:"error"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+type WebhookQueue struct {
+	ID        uuid.UUID       `db:"id" json:"id"`
+	Kind      string          `db:"kind" json:"kind"`
+	WebhookID uuid.UUID       `db:"webhook_id" json:"webhook_id"`
+	EventID   uuid.UUID       `db:"event_id" json:"event_id"`
+	EventType string          `db:"event_type" json:"event_type"`
+	Payload   json.RawMessage `db:"payload" json:"payload"`
+	Attempts  int32           `db:"attempts" json:"attempts"`
+	RunAt     time.Time       `db:"run_at" json:"run_at"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
 }
 
 type WorkspaceAgent struct {
@@ -612,6 +1098,24 @@ type WorkspaceAgent struct {
 	Version string `db:"version" json:"version"`
 }
 
+type WorkspaceAgentStartupScriptResult struct {
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	ExitCode    int32     `db:"exit_code" json:"exit_code"`
+	CompletedAt time.Time `db:"completed_at" json:"completed_at"`
+}
+
+type WorkspaceAgentUsageSample struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	CPUUsed     float64   `db:"cpu_used" json:"cpu_used"`
+	CPUTotal    float64   `db:"cpu_total" json:"cpu_total"`
+	MemoryUsed  int64     `db:"memory_used" json:"memory_used"`
+	MemoryTotal int64     `db:"memory_total" json:"memory_total"`
+	DiskUsed    int64     `db:"disk_used" json:"disk_used"`
+	DiskTotal   int64     `db:"disk_total" json:"disk_total"`
+}
+
 type WorkspaceApp struct {
 	ID                   uuid.UUID          `db:"id" json:"id"`
 	CreatedAt            time.Time          `db:"created_at" json:"created_at"`
@@ -627,6 +1131,38 @@ type WorkspaceApp struct {
 	Subdomain            bool               `db:"subdomain" json:"subdomain"`
 }
 
+type WorkspaceArchive struct {
+	WorkspaceID uuid.UUID              `db:"workspace_id" json:"workspace_id"`
+	Status      WorkspaceArchiveStatus `db:"status" json:"status"`
+	ObjectKey   string                 `db:"object_key" json:"object_key"`
+	BuildID     uuid.UUID              `db:"build_id" json:"build_id"`
+	Error       string                 `db:"error" json:"error"`
+	CreatedAt   time.Time              `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time              `db:"updated_at" json:"updated_at"`
+	ArchivedAt  sql.NullTime           `db:"archived_at" json:"archived_at"`
+	ExpiresAt   sql.NullTime           `db:"expires_at" json:"expires_at"`
+}
+
+type WorkspaceAutostopExtension struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	Extension   int64     `db:"extension" json:"extension"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+type WorkspaceBatchResult struct {
+	OperationID   uuid.UUID                  `db:"operation_id" json:"operation_id"`
+	WorkspaceID   uuid.UUID                  `db:"workspace_id" json:"workspace_id"`
+	WorkspaceName string                     `db:"workspace_name" json:"workspace_name"`
+	Action        WorkspaceBatchAction       `db:"action" json:"action"`
+	Status        WorkspaceBatchResultStatus `db:"status" json:"status"`
+	BuildID       uuid.NullUUID              `db:"build_id" json:"build_id"`
+	Error         string                     `db:"error" json:"error"`
+	CreatedAt     time.Time                  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time                  `db:"updated_at" json:"updated_at"`
+}
+
 type WorkspaceBuild struct {
 	ID                uuid.UUID           `db:"id" json:"id"`
 	CreatedAt         time.Time           `db:"created_at" json:"created_at"`
@@ -642,6 +1178,24 @@ type WorkspaceBuild struct {
 	Reason            BuildReason         `db:"reason" json:"reason"`
 }
 
+type WorkspaceCost struct {
+	WorkspaceID    uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	OwnerID        uuid.UUID `db:"owner_id" json:"owner_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	StartTime      time.Time `db:"start_time" json:"start_time"`
+	Cost           float64   `db:"cost" json:"cost"`
+	RunningSeconds int64     `db:"running_seconds" json:"running_seconds"`
+	AccruedUntil   time.Time `db:"accrued_until" json:"accrued_until"`
+}
+
+type WorkspaceFavorite struct {
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	Position    int32     `db:"position" json:"position"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
 type WorkspaceResource struct {
 	ID         uuid.UUID           `db:"id" json:"id"`
 	CreatedAt  time.Time           `db:"created_at" json:"created_at"`
@@ -659,3 +1213,13 @@ type WorkspaceResourceMetadatum struct {
 	Value               sql.NullString `db:"value" json:"value"`
 	Sensitive           bool           `db:"sensitive" json:"sensitive"`
 }
+
+type WorkspaceTimelineEvent struct {
+	ID          uuid.UUID                  `db:"id" json:"id"`
+	WorkspaceID uuid.UUID                  `db:"workspace_id" json:"workspace_id"`
+	Type        WorkspaceTimelineEventType `db:"type" json:"type"`
+	AgentID     uuid.UUID                  `db:"agent_id" json:"agent_id"`
+	UserID      uuid.NullUUID              `db:"user_id" json:"user_id"`
+	AppName     string                     `db:"app_name" json:"app_name"`
+	CreatedAt   time.Time                  `db:"created_at" json:"created_at"`
+}
@@ -12,6 +12,16 @@ import (
 )
 
 type sqlcQuerier interface {
+	// Adds the cost of running a workspace to the hour it accrued in. Costs that
+	// were accrued already, e.g. by another replica, are ignored.
+	AccrueWorkspaceCost(ctx context.Context, arg AccrueWorkspaceCostParams) error
+	// Blocks until the lock is acquired. The lock is released when the
+	// transaction ends, so this must be called in a transaction.
+	AcquireLock(ctx context.Context, pgAdvisoryXactLock int64) error
+	// Claims the running deletions that no replica works on, because their
+	// replica stopped recording heartbeats before @stale_before. Concurrent
+	// claims of the same deletion only succeed once.
+	AcquireOrganizationDeletions(ctx context.Context, arg AcquireOrganizationDeletionsParams) ([]OrganizationDeletion, error)
 	// Acquires the lock for a single job that isn't started, completed,
 	// canceled, and that matches an array of provisioner types.
 	//
@@ -19,17 +29,58 @@ type sqlcQuerier interface {
 	// multiple provisioners from acquiring the same jobs. See:
 	// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
 	AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error)
+	// Leases the due items of the kinds until @lease_until, so other replicas
+	// skip them while they're delivered. An item whose attempt doesn't finish,
+	// e.g. because the replica stopped, is acquired again once its lease ends.
+	AcquireWebhookQueue(ctx context.Context, arg AcquireWebhookQueueParams) ([]WebhookQueue, error)
 	DeleteAPIKeyByID(ctx context.Context, id string) error
+	// Removes a batch of deleted workspaces, which are otherwise kept for their
+	// history, so the organization can be deleted.
+	DeleteDeletedWorkspacesByOrganizationID(ctx context.Context, arg DeleteDeletedWorkspacesByOrganizationIDParams) ([]uuid.UUID, error)
+	DeleteEveryoneGroupExclusion(ctx context.Context, arg DeleteEveryoneGroupExclusionParams) error
+	DeleteExpiredGroupMembers(ctx context.Context) ([]GroupMember, error)
 	DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error
-	DeleteGroupByID(ctx context.Context, id uuid.UUID) error
+	DeleteGroupJoinRequestByID(ctx context.Context, id uuid.UUID) error
 	DeleteGroupMember(ctx context.Context, userID uuid.UUID) error
+	DeleteGroupMembersExceptUserIDs(ctx context.Context, arg DeleteGroupMembersExceptUserIDsParams) ([]uuid.UUID, error)
+	DeleteGroupWebhookByID(ctx context.Context, id uuid.UUID) error
+	// Permanently removes groups that were soft deleted before the given time.
+	DeleteGroupsDeletedBefore(ctx context.Context, deletedBefore time.Time) ([]Group, error)
 	DeleteLicense(ctx context.Context, id int32) (int32, error)
+	DeleteOAuth2ProviderAppAPIKeysByAppID(ctx context.Context, appID uuid.UUID) error
+	DeleteOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) error
+	// Codes can only be exchanged once, so they're deleted when they're used.
+	DeleteOAuth2ProviderAppCodeByHashedSecret(ctx context.Context, hashedSecret []byte) (OAuth2ProviderAppCode, error)
 	DeleteOldAgentStats(ctx context.Context) error
+	DeleteOrganization(ctx context.Context, id uuid.UUID) error
+	DeleteOrganizationAliasByName(ctx context.Context, name string) error
+	DeleteOrganizationInviteByID(ctx context.Context, id uuid.UUID) error
+	// Removes a batch of members so the organization can be deleted.
+	DeleteOrganizationMembersByOrganizationID(ctx context.Context, arg DeleteOrganizationMembersByOrganizationIDParams) ([]uuid.UUID, error)
+	DeleteOrganizationOIDCConfigByOrganizationID(ctx context.Context, organizationID uuid.UUID) error
+	DeleteOrganizationWebhookByID(ctx context.Context, id uuid.UUID) error
 	DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error
+	DeleteTemplateAutostopExtensionPolicyByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	DeleteTemplateDeprecationByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	DeleteTemplateFavoritesByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteTemplateMaintenanceWindowByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	DeleteTemplateResourceCostsByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	// Removes a batch of templates along with their versions. The workspaces of
+	// the templates must be removed first.
+	DeleteTemplatesByOrganizationID(ctx context.Context, arg DeleteTemplatesByOrganizationIDParams) ([]uuid.UUID, error)
+	DeleteUserFromGroups(ctx context.Context, arg DeleteUserFromGroupsParams) ([]uuid.UUID, error)
+	DeleteWebhookByID(ctx context.Context, id uuid.UUID) error
+	DeleteWebhookQueueByID(ctx context.Context, id uuid.UUID) error
+	DeleteWorkspaceAgentUsageSamplesBefore(ctx context.Context, arg DeleteWorkspaceAgentUsageSamplesBeforeParams) error
+	DeleteWorkspaceArchiveByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) error
+	DeleteWorkspaceFavoritesByUserID(ctx context.Context, userID uuid.UUID) error
 	GetAPIKeyByID(ctx context.Context, id string) (APIKey, error)
 	GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error)
 	GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error)
 	GetActiveUserCount(ctx context.Context) (int64, error)
+	// Returns every user that used a workspace of a template in the organization
+	// within the time range, once per template.
+	GetAgentStatUsersByOrganizationID(ctx context.Context, arg GetAgentStatUsersByOrganizationIDParams) ([]GetAgentStatUsersByOrganizationIDRow, error)
 	GetAllOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error)
 	GetAuditLogCount(ctx context.Context, arg GetAuditLogCountParams) (int64, error)
 	// GetAuditLogsBefore retrieves `row_limit` number of audit logs before the provided
@@ -38,48 +89,129 @@ type sqlcQuerier interface {
 	// This function returns roles for authorization purposes. Implied member roles
 	// are included.
 	GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error)
+	GetDefaultOrganization(ctx context.Context) (Organization, error)
 	GetDeploymentID(ctx context.Context) (string, error)
+	GetEveryoneGroupExclusionsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]EveryoneGroupExclusion, error)
+	// Returns the members of the organization's "Everyone" group, which is every
+	// organization member that hasn't been excluded from it.
+	GetEveryoneGroupMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error)
 	GetFileByHash(ctx context.Context, hash string) (File, error)
 	GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error)
+	// Returns the IDs of the group and every group above it in the hierarchy.
+	GetGroupAncestorIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+	// Returns the users at least one group with an autostop policy applies to:
+	// unexpired members of the group or of the groups nested beneath it, and
+	// the members of the organization for its "Everyone" group.
+	GetGroupAutostopUserIDs(ctx context.Context) ([]uuid.UUID, error)
 	GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error)
 	GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error)
+	GetGroupJoinRequestByID(ctx context.Context, id uuid.UUID) (GroupJoinRequest, error)
+	GetGroupJoinRequestsByGroupID(ctx context.Context, groupID uuid.UUID) ([]GroupJoinRequest, error)
+	// Counts the members returned by GetGroupMembersByGroupIDs without fetching
+	// them. Groups without members are omitted.
+	GetGroupMemberCountsByGroupIDs(ctx context.Context, groupIds []uuid.UUID) ([]GetGroupMemberCountsByGroupIDsRow, error)
+	GetGroupMemberIDsWithRole(ctx context.Context, arg GetGroupMemberIDsWithRoleParams) ([]uuid.UUID, error)
 	GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error)
+	GetGroupMembersByGroupIDs(ctx context.Context, groupIds []uuid.UUID) ([]GetGroupMembersByGroupIDsRow, error)
+	// Returns the active members of a group ordered by username, a page at a
+	// time.
+	GetGroupMembersPage(ctx context.Context, arg GetGroupMembersPageParams) ([]GetGroupMembersPageRow, error)
+	GetGroupMembershipsByUserIDs(ctx context.Context, arg GetGroupMembershipsByUserIDsParams) ([]GetGroupMembershipsByUserIDsRow, error)
+	GetGroupWebhookByID(ctx context.Context, id uuid.UUID) (GroupWebhook, error)
+	GetGroupWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]GroupWebhook, error)
+	GetGroups(ctx context.Context, arg GetGroupsParams) ([]GetGroupsRow, error)
 	GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error)
+	// Returns the groups that grant roles to their members, across all
+	// organizations.
+	GetGroupsWithRoles(ctx context.Context) ([]Group, error)
 	GetLatestAgentStat(ctx context.Context, agentID uuid.UUID) (AgentStat, error)
 	GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceBuild, error)
 	GetLatestWorkspaceBuilds(ctx context.Context) ([]WorkspaceBuild, error)
 	GetLatestWorkspaceBuildsByWorkspaceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceBuild, error)
 	GetLicenses(ctx context.Context) ([]License, error)
+	GetMaintenance(ctx context.Context) (string, error)
+	GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error)
+	GetOAuth2ProviderAppTokenByAPIKeyID(ctx context.Context, apiKeyID string) (OAuth2ProviderAppToken, error)
+	GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2ProviderApp, error)
+	GetOperationByID(ctx context.Context, id uuid.UUID) (Operation, error)
+	GetOperationsByTypeAndStatus(ctx context.Context, arg GetOperationsByTypeAndStatusParams) ([]Operation, error)
+	GetOrganizationAliasByName(ctx context.Context, name string) (OrganizationAlias, error)
 	GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error)
 	GetOrganizationByName(ctx context.Context, name string) (Organization, error)
+	GetOrganizationDeletionByOrganizationID(ctx context.Context, organizationID uuid.UUID) (OrganizationDeletion, error)
 	GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error)
+	GetOrganizationInviteByHashedToken(ctx context.Context, hashedToken []byte) (OrganizationInvite, error)
+	GetOrganizationInviteByID(ctx context.Context, id uuid.UUID) (OrganizationInvite, error)
+	GetOrganizationIPAllowlist(ctx context.Context, organizationID uuid.UUID) (OrganizationIpAllowlist, error)
+	GetOrganizationInvitesByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]OrganizationInvite, error)
 	GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error)
+	GetOrganizationMemberCountByOrganizationID(ctx context.Context, organizationID uuid.UUID) (int64, error)
+	GetOrganizationMembers(ctx context.Context, arg GetOrganizationMembersParams) ([]GetOrganizationMembersRow, error)
 	GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error)
+	GetOrganizationOIDCConfigByEmailDomain(ctx context.Context, emailDomain string) (OrganizationOIDCConfig, error)
+	GetOrganizationOIDCConfigByOrganizationID(ctx context.Context, organizationID uuid.UUID) (OrganizationOIDCConfig, error)
+	GetOrganizationQuotaByOrganizationID(ctx context.Context, organizationID uuid.UUID) (OrganizationQuota, error)
+	// Counts the workspaces of the organization per template, along with how
+	// many of them are running and the quota weight of the template.
+	GetOrganizationQuotaConsumption(ctx context.Context, organizationID uuid.UUID) ([]GetOrganizationQuotaConsumptionRow, error)
+	GetOrganizationTemplateDefaults(ctx context.Context, organizationID uuid.UUID) (OrganizationTemplateDefault, error)
+	GetOrganizationWebhookByID(ctx context.Context, id uuid.UUID) (OrganizationWebhook, error)
+	GetOrganizationWebhookDeliveriesByWebhookID(ctx context.Context, arg GetOrganizationWebhookDeliveriesByWebhookIDParams) ([]OrganizationWebhookDelivery, error)
+	GetOrganizationWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]OrganizationWebhook, error)
+	GetOrganizationWorkspaceNamePolicy(ctx context.Context, organizationID uuid.UUID) (OrganizationWorkspaceNamePolicy, error)
 	GetOrganizations(ctx context.Context) ([]Organization, error)
 	GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error)
 	GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error)
 	GetParameterSchemasCreatedAfter(ctx context.Context, createdAt time.Time) ([]ParameterSchema, error)
 	GetParameterValueByScopeAndName(ctx context.Context, arg GetParameterValueByScopeAndNameParams) (ParameterValue, error)
+	GetPendingRoleRequests(ctx context.Context) ([]RoleRequest, error)
 	GetProvisionerDaemonByID(ctx context.Context, id uuid.UUID) (ProvisionerDaemon, error)
 	GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error)
 	GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error)
 	GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error)
 	GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error)
 	GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error)
+	// Returns the archives of deleted workspaces, and archives that expired.
+	GetPurgeableWorkspaceArchives(ctx context.Context, now time.Time) ([]WorkspaceArchive, error)
+	GetRoleRequestByID(ctx context.Context, id uuid.UUID) (RoleRequest, error)
+	GetRoleRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]RoleRequest, error)
+	GetTemplateArchivePolicies(ctx context.Context) ([]TemplateArchivePolicy, error)
+	GetTemplateArchivePolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateArchivePolicy, error)
+	GetTemplateAutoRebuildPolicies(ctx context.Context) ([]TemplateAutoRebuildPolicy, error)
+	GetTemplateAutoRebuildPolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateAutoRebuildPolicy, error)
+	GetTemplateAutostopExtensionPolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateAutostopExtensionPolicy, error)
 	GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error)
 	GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error)
+	// Counts deleted templates too, they're kept until the organization is deleted.
+	GetTemplateCountByOrganizationID(ctx context.Context, organizationID uuid.UUID) (int64, error)
 	GetTemplateDAUs(ctx context.Context, templateID uuid.UUID) ([]GetTemplateDAUsRow, error)
+	GetTemplateDeprecationByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateDeprecation, error)
+	GetTemplateDeprecationsByTemplateIDs(ctx context.Context, templateIds []uuid.UUID) ([]TemplateDeprecation, error)
+	GetTemplateFavoritesByUserID(ctx context.Context, userID uuid.UUID) ([]TemplateFavorite, error)
+	GetTemplateMaintenanceWindowByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateMaintenanceWindow, error)
+	// Returns the maintenance windows of templates that aren't deleted.
+	GetTemplateMaintenanceWindows(ctx context.Context) ([]TemplateMaintenanceWindow, error)
+	GetTemplateMaintenanceWindowsByTemplateIDs(ctx context.Context, templateIds []uuid.UUID) ([]TemplateMaintenanceWindow, error)
+	// Returns the resource costs of templates that aren't deleted.
+	GetTemplateResourceCosts(ctx context.Context) ([]TemplateResourceCost, error)
+	GetTemplateResourceCostsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]TemplateResourceCost, error)
 	GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error)
 	GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error)
 	GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error)
+	GetTemplateWorkspaceNamePolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateWorkspaceNamePolicy, error)
 	GetTemplates(ctx context.Context) ([]Template, error)
 	GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error)
 	GetUnexpiredLicenses(ctx context.Context) ([]License, error)
 	GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserCount(ctx context.Context) (int64, error)
+	// Returns the groups whose policies apply to the user in the organization:
+	// the groups the user is an unexpired member of and the groups above them,
+	// limited to the organization's groups and deployment-wide groups, and the
+	// organization's "Everyone" group unless the user has been excluded from it.
+	GetUserEffectiveGroups(ctx context.Context, arg GetUserEffectiveGroupsParams) ([]Group, error)
 	GetUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error)
 	GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error)
 	GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error)
@@ -88,24 +220,52 @@ type sqlcQuerier interface {
 	// to look up references to actions. eg. a user could build a workspace
 	// for another user, then be deleted... we still want them to appear!
 	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error)
+	GetUsersByUsernamesOrEmails(ctx context.Context, identifiers []string) ([]User, error)
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (Webhook, error)
+	GetWebhookDeliveriesByWebhookID(ctx context.Context, arg GetWebhookDeliveriesByWebhookIDParams) ([]WebhookDelivery, error)
+	GetWebhookDeliveryByID(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
+	GetWebhooks(ctx context.Context) ([]Webhook, error)
 	GetWorkspaceAgentByAuthToken(ctx context.Context, authToken uuid.UUID) (WorkspaceAgent, error)
 	GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (WorkspaceAgent, error)
 	GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanceID string) (WorkspaceAgent, error)
+	GetWorkspaceAgentStartupScriptResultsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgentStartupScriptResult, error)
+	GetWorkspaceAgentUsageSamplesByAgentIDs(ctx context.Context, arg GetWorkspaceAgentUsageSamplesByAgentIDsParams) ([]WorkspaceAgentUsageSample, error)
 	GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgent, error)
 	GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceAgent, error)
 	GetWorkspaceAppByAgentIDAndName(ctx context.Context, arg GetWorkspaceAppByAgentIDAndNameParams) (WorkspaceApp, error)
 	GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error)
 	GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error)
 	GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error)
+	GetWorkspaceArchiveByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceArchive, error)
+	GetWorkspaceArchivesByStatus(ctx context.Context, status WorkspaceArchiveStatus) ([]WorkspaceArchive, error)
+	GetWorkspaceAutostopExtensionsByWorkspaceID(ctx context.Context, arg GetWorkspaceAutostopExtensionsByWorkspaceIDParams) ([]WorkspaceAutostopExtension, error)
+	GetWorkspaceBatchResultsByOperationID(ctx context.Context, operationID uuid.UUID) ([]WorkspaceBatchResult, error)
 	GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (WorkspaceBuild, error)
 	GetWorkspaceBuildByJobID(ctx context.Context, jobID uuid.UUID) (WorkspaceBuild, error)
 	GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Context, arg GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams) (WorkspaceBuild, error)
+	// Returns the builds of the workspaces in an organization that were created
+	// before the end time, starting with the last build of each workspace before
+	// the start time so the state of the workspace at the start is known.
+	GetWorkspaceBuildsByOrganizationID(ctx context.Context, arg GetWorkspaceBuildsByOrganizationIDParams) ([]GetWorkspaceBuildsByOrganizationIDRow, error)
 	GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg GetWorkspaceBuildsByWorkspaceIDParams) ([]WorkspaceBuild, error)
 	GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceBuild, error)
 	GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Workspace, error)
+	// Locks the workspace until the transaction ends, so concurrent updates to
+	// its ACLs aren't lost.
+	GetWorkspaceByIDForUpdate(ctx context.Context, id uuid.UUID) (Workspace, error)
 	GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWorkspaceByOwnerIDAndNameParams) (Workspace, error)
+	// Returns the time up to which the cost of each workspace was accrued.
+	GetWorkspaceCostsAccruedUntil(ctx context.Context, workspaceIds []uuid.UUID) ([]GetWorkspaceCostsAccruedUntilRow, error)
+	// Sums the costs of the organization's workspaces per owner and template over
+	// the hours that start in the range.
+	GetWorkspaceCostsByOrganizationID(ctx context.Context, arg GetWorkspaceCostsByOrganizationIDParams) ([]GetWorkspaceCostsByOrganizationIDRow, error)
+	GetWorkspaceCountByOrganizationID(ctx context.Context, organizationID uuid.UUID) (int64, error)
 	GetWorkspaceCountByUserID(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	GetWorkspaceFavoritesByUserID(ctx context.Context, userID uuid.UUID) ([]WorkspaceFavorite, error)
 	GetWorkspaceOwnerCountsByTemplateIDs(ctx context.Context, ids []uuid.UUID) ([]GetWorkspaceOwnerCountsByTemplateIDsRow, error)
+	// Counts the user's workspaces per template, along with the quota weight
+	// each of them costs.
+	GetWorkspaceQuotaConsumptionByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]GetWorkspaceQuotaConsumptionByOwnerIDRow, error)
 	GetWorkspaceResourceByID(ctx context.Context, id uuid.UUID) (WorkspaceResource, error)
 	GetWorkspaceResourceMetadataByResourceID(ctx context.Context, workspaceResourceID uuid.UUID) ([]WorkspaceResourceMetadatum, error)
 	GetWorkspaceResourceMetadataByResourceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceResourceMetadatum, error)
@@ -113,7 +273,10 @@ type sqlcQuerier interface {
 	GetWorkspaceResourcesByJobID(ctx context.Context, jobID uuid.UUID) ([]WorkspaceResource, error)
 	GetWorkspaceResourcesByJobIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceResource, error)
 	GetWorkspaceResourcesCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceResource, error)
+	GetWorkspaceTimelineEventsByWorkspaceID(ctx context.Context, arg GetWorkspaceTimelineEventsByWorkspaceIDParams) ([]WorkspaceTimelineEvent, error)
 	GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]Workspace, error)
+	// Pages through the workspaces of an organization in the order of their IDs.
+	GetWorkspacesByOrganizationID(ctx context.Context, arg GetWorkspacesByOrganizationIDParams) ([]Workspace, error)
 	InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error)
 	InsertAgentStat(ctx context.Context, arg InsertAgentStatParams) (AgentStat, error)
 	// We use the organization_id as the id
@@ -122,41 +285,85 @@ type sqlcQuerier interface {
 	InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error)
 	InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error)
 	InsertDeploymentID(ctx context.Context, value string) error
+	InsertEveryoneGroupExclusion(ctx context.Context, arg InsertEveryoneGroupExclusionParams) error
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
 	InsertGitSSHKey(ctx context.Context, arg InsertGitSSHKeyParams) (GitSSHKey, error)
 	InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error)
+	InsertGroupJoinRequest(ctx context.Context, arg InsertGroupJoinRequestParams) (GroupJoinRequest, error)
 	InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error
+	InsertGroupMembers(ctx context.Context, arg InsertGroupMembersParams) ([]uuid.UUID, error)
+	InsertGroupWebhook(ctx context.Context, arg InsertGroupWebhookParams) (GroupWebhook, error)
 	InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error)
+	InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAuth2ProviderAppParams) (OAuth2ProviderApp, error)
+	InsertOAuth2ProviderAppCode(ctx context.Context, arg InsertOAuth2ProviderAppCodeParams) (OAuth2ProviderAppCode, error)
+	InsertOAuth2ProviderAppToken(ctx context.Context, arg InsertOAuth2ProviderAppTokenParams) (OAuth2ProviderAppToken, error)
+	InsertOperation(ctx context.Context, arg InsertOperationParams) (Operation, error)
 	InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error)
+	InsertOrganizationAlias(ctx context.Context, arg InsertOrganizationAliasParams) (OrganizationAlias, error)
+	// Restarts deletions that failed. Returns no rows if the organization is
+	// already being deleted. The deletion is claimed by the worker.
+	InsertOrganizationDeletion(ctx context.Context, arg InsertOrganizationDeletionParams) (OrganizationDeletion, error)
+	InsertOrganizationInvite(ctx context.Context, arg InsertOrganizationInviteParams) (OrganizationInvite, error)
 	InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error)
+	InsertOrganizationWebhook(ctx context.Context, arg InsertOrganizationWebhookParams) (OrganizationWebhook, error)
+	InsertOrganizationWebhookDelivery(ctx context.Context, arg InsertOrganizationWebhookDeliveryParams) (OrganizationWebhookDelivery, error)
 	InsertParameterSchema(ctx context.Context, arg InsertParameterSchemaParams) (ParameterSchema, error)
 	InsertParameterValue(ctx context.Context, arg InsertParameterValueParams) (ParameterValue, error)
 	InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error)
 	InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error)
 	InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error)
+	InsertRoleRequest(ctx context.Context, arg InsertRoleRequestParams) (RoleRequest, error)
 	InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error)
+	InsertTemplateFavorite(ctx context.Context, arg InsertTemplateFavoriteParams) (TemplateFavorite, error)
+	InsertTemplateResourceCost(ctx context.Context, arg InsertTemplateResourceCostParams) error
 	InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
 	InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error)
+	InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error)
+	InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) (WebhookDelivery, error)
+	InsertWebhookQueue(ctx context.Context, arg InsertWebhookQueueParams) (WebhookQueue, error)
 	InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error)
 	InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspaceAgentParams) (WorkspaceAgent, error)
+	InsertWorkspaceAgentUsageSample(ctx context.Context, arg InsertWorkspaceAgentUsageSampleParams) (WorkspaceAgentUsageSample, error)
 	InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error)
+	InsertWorkspaceAutostopExtension(ctx context.Context, arg InsertWorkspaceAutostopExtensionParams) (WorkspaceAutostopExtension, error)
+	InsertWorkspaceBatchResult(ctx context.Context, arg InsertWorkspaceBatchResultParams) (WorkspaceBatchResult, error)
 	InsertWorkspaceBuild(ctx context.Context, arg InsertWorkspaceBuildParams) (WorkspaceBuild, error)
+	InsertWorkspaceFavorite(ctx context.Context, arg InsertWorkspaceFavoriteParams) (WorkspaceFavorite, error)
 	InsertWorkspaceResource(ctx context.Context, arg InsertWorkspaceResourceParams) (WorkspaceResource, error)
 	InsertWorkspaceResourceMetadata(ctx context.Context, arg InsertWorkspaceResourceMetadataParams) (WorkspaceResourceMetadatum, error)
+	InsertWorkspaceTimelineEvent(ctx context.Context, arg InsertWorkspaceTimelineEventParams) (WorkspaceTimelineEvent, error)
 	ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error)
 	ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error)
+	// Returns the groups across all organizations with a name or display name
+	// containing the search term. The "Everyone" groups aren't included.
+	SearchGroups(ctx context.Context, arg SearchGroupsParams) ([]Group, error)
 	UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDParams) error
 	UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) error
 	UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error)
+	UpdateGroupDeletedAtByID(ctx context.Context, arg UpdateGroupDeletedAtByIDParams) (Group, error)
+	UpdateGroupMemberRoles(ctx context.Context, arg UpdateGroupMemberRolesParams) (GroupMember, error)
 	UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error)
+	UpdateOAuth2ProviderAppByID(ctx context.Context, arg UpdateOAuth2ProviderAppByIDParams) (OAuth2ProviderApp, error)
+	UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg UpdateOAuth2ProviderAppSecretByIDParams) (OAuth2ProviderApp, error)
+	UpdateOperationByID(ctx context.Context, arg UpdateOperationByIDParams) (Operation, error)
+	UpdateOrganizationByID(ctx context.Context, arg UpdateOrganizationByIDParams) (Organization, error)
+	// Returns no rows if the deletion was claimed by another worker.
+	UpdateOrganizationDeletionByOrganizationID(ctx context.Context, arg UpdateOrganizationDeletionByOrganizationIDParams) (OrganizationDeletion, error)
+	// Returns no rows if the deletion was claimed by another worker.
+	UpdateOrganizationDeletionHeartbeat(ctx context.Context, arg UpdateOrganizationDeletionHeartbeatParams) (OrganizationDeletion, error)
 	UpdateProvisionerDaemonByID(ctx context.Context, arg UpdateProvisionerDaemonByIDParams) error
 	UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error
 	UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error
 	UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error
+	// Moves the jobs of a workspace's builds along with the workspace.
+	UpdateProvisionerJobsOrganizationByWorkspaceID(ctx context.Context, arg UpdateProvisionerJobsOrganizationByWorkspaceIDParams) error
+	UpdateRoleRequestStatus(ctx context.Context, arg UpdateRoleRequestStatusParams) (RoleRequest, error)
 	UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error
 	UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error
 	UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error)
+	UpdateTemplateQuotaWeightByID(ctx context.Context, arg UpdateTemplateQuotaWeightByIDParams) error
+	UpdateTemplateWorkspaceLabelsByID(ctx context.Context, arg UpdateTemplateWorkspaceLabelsByIDParams) (Template, error)
 	UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error
 	UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error
 	UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error
@@ -167,15 +374,44 @@ type sqlcQuerier interface {
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 	UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
+	UpdateWebhookQueueByID(ctx context.Context, arg UpdateWebhookQueueByIDParams) error
 	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
+	// Replaces the token of every agent that authenticates with it, which
+	// includes the agents of previous builds of the workspace.
+	UpdateWorkspaceAgentAuthToken(ctx context.Context, arg UpdateWorkspaceAgentAuthTokenParams) error
 	UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error
 	UpdateWorkspaceAgentVersionByID(ctx context.Context, arg UpdateWorkspaceAgentVersionByIDParams) error
 	UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error
+	UpdateWorkspaceArchiveByWorkspaceID(ctx context.Context, arg UpdateWorkspaceArchiveByWorkspaceIDParams) (WorkspaceArchive, error)
 	UpdateWorkspaceAutostart(ctx context.Context, arg UpdateWorkspaceAutostartParams) error
+	UpdateWorkspaceBatchResult(ctx context.Context, arg UpdateWorkspaceBatchResultParams) (WorkspaceBatchResult, error)
 	UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWorkspaceBuildByIDParams) error
 	UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateWorkspaceDeletedByIDParams) error
+	UpdateWorkspaceLabels(ctx context.Context, arg UpdateWorkspaceLabelsParams) (Workspace, error)
+	// Applies the labels of a template to its workspaces. Keys the template no
+	// longer sets are removed, labels set by the owners are kept.
+	UpdateWorkspaceLabelsByTemplateID(ctx context.Context, arg UpdateWorkspaceLabelsByTemplateIDParams) error
 	UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWorkspaceLastUsedAtParams) error
+	UpdateWorkspaceOrganization(ctx context.Context, arg UpdateWorkspaceOrganizationParams) (Workspace, error)
+	UpdateWorkspaceOwner(ctx context.Context, arg UpdateWorkspaceOwnerParams) (Workspace, error)
 	UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspaceTTLParams) error
+	UpsertMaintenance(ctx context.Context, value string) error
+	UpsertOrganizationIPAllowlist(ctx context.Context, arg UpsertOrganizationIPAllowlistParams) (OrganizationIpAllowlist, error)
+	UpsertOrganizationOIDCConfig(ctx context.Context, arg UpsertOrganizationOIDCConfigParams) (OrganizationOIDCConfig, error)
+	UpsertOrganizationQuota(ctx context.Context, arg UpsertOrganizationQuotaParams) (OrganizationQuota, error)
+	UpsertOrganizationTemplateDefaults(ctx context.Context, arg UpsertOrganizationTemplateDefaultsParams) (OrganizationTemplateDefault, error)
+	UpsertOrganizationWorkspaceNamePolicy(ctx context.Context, arg UpsertOrganizationWorkspaceNamePolicyParams) (OrganizationWorkspaceNamePolicy, error)
+	UpsertTemplateArchivePolicy(ctx context.Context, arg UpsertTemplateArchivePolicyParams) (TemplateArchivePolicy, error)
+	UpsertTemplateAutoRebuildPolicy(ctx context.Context, arg UpsertTemplateAutoRebuildPolicyParams) (TemplateAutoRebuildPolicy, error)
+	UpsertTemplateAutostopExtensionPolicy(ctx context.Context, arg UpsertTemplateAutostopExtensionPolicyParams) (TemplateAutostopExtensionPolicy, error)
+	UpsertTemplateDeprecation(ctx context.Context, arg UpsertTemplateDeprecationParams) (TemplateDeprecation, error)
+	UpsertTemplateMaintenanceWindow(ctx context.Context, arg UpsertTemplateMaintenanceWindowParams) (TemplateMaintenanceWindow, error)
+	UpsertTemplateWorkspaceNamePolicy(ctx context.Context, arg UpsertTemplateWorkspaceNamePolicyParams) (TemplateWorkspaceNamePolicy, error)
+	UpsertWorkspaceAgentStartupScriptResult(ctx context.Context, arg UpsertWorkspaceAgentStartupScriptResultParams) (WorkspaceAgentStartupScriptResult, error)
+	UpsertWorkspaceArchive(ctx context.Context, arg UpsertWorkspaceArchiveParams) (WorkspaceArchive, error)
+	// UseOrganizationInvite counts a use of the invite, and returns no rows if
+	// the invite has no uses left.
+	UseOrganizationInvite(ctx context.Context, id uuid.UUID) (OrganizationInvite, error)
 }
 
 var _ sqlcQuerier = (*sqlQuerier)(nil)
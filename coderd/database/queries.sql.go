@@ -24,6 +24,56 @@ func (q *sqlQuerier) DeleteOldAgentStats(ctx context.Context) error {
 	return err
 }
 
+const getAgentStatUsersByOrganizationID = `-- name: GetAgentStatUsersByOrganizationID :many
+SELECT DISTINCT
+	agent_stats.template_id,
+	agent_stats.user_id
+FROM
+	agent_stats
+JOIN
+	templates ON templates.id = agent_stats.template_id
+WHERE
+	templates.organization_id = $1
+	AND agent_stats.created_at >= $2
+	AND agent_stats.created_at < $3
+`
+
+type GetAgentStatUsersByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	StartTime      time.Time `db:"start_time" json:"start_time"`
+	EndTime        time.Time `db:"end_time" json:"end_time"`
+}
+
+type GetAgentStatUsersByOrganizationIDRow struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+// Returns every user that used a workspace of a template in the organization
+// within the time range, once per template.
+func (q *sqlQuerier) GetAgentStatUsersByOrganizationID(ctx context.Context, arg GetAgentStatUsersByOrganizationIDParams) ([]GetAgentStatUsersByOrganizationIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAgentStatUsersByOrganizationID, arg.OrganizationID, arg.StartTime, arg.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAgentStatUsersByOrganizationIDRow
+	for rows.Next() {
+		var i GetAgentStatUsersByOrganizationIDRow
+		if err := rows.Scan(&i.TemplateID, &i.UserID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLatestAgentStat = `-- name: GetLatestAgentStat :one
 SELECT id, created_at, user_id, agent_id, workspace_id, template_id, payload FROM agent_stats WHERE agent_id = $1 ORDER BY created_at DESC LIMIT 1
 `
@@ -147,7 +197,7 @@ func (q *sqlQuerier) DeleteAPIKeyByID(ctx context.Context, id string) error {
 
 const getAPIKeyByID = `-- name: GetAPIKeyByID :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, scope_permissions, scope_organization_id
 FROM
 	api_keys
 WHERE
@@ -171,12 +221,14 @@ func (q *sqlQuerier) GetAPIKeyByID(ctx context.Context, id string) (APIKey, erro
 		&i.LifetimeSeconds,
 		&i.IPAddress,
 		&i.Scope,
+		pq.Array(&i.ScopePermissions),
+		&i.ScopeOrganizationID,
 	)
 	return i, err
 }
 
 const getAPIKeysByLoginType = `-- name: GetAPIKeysByLoginType :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope FROM api_keys WHERE login_type = $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, scope_permissions, scope_organization_id FROM api_keys WHERE login_type = $1
 `
 
 func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error) {
@@ -200,6 +252,8 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 			&i.LifetimeSeconds,
 			&i.IPAddress,
 			&i.Scope,
+			pq.Array(&i.ScopePermissions),
+			&i.ScopeOrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -215,7 +269,7 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 }
 
 const getAPIKeysLastUsedAfter = `-- name: GetAPIKeysLastUsedAfter :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope FROM api_keys WHERE last_used > $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, scope_permissions, scope_organization_id FROM api_keys WHERE last_used > $1
 `
 
 func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error) {
@@ -239,6 +293,8 @@ func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.
 			&i.LifetimeSeconds,
 			&i.IPAddress,
 			&i.Scope,
+			pq.Array(&i.ScopePermissions),
+			&i.ScopeOrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -266,7 +322,9 @@ INSERT INTO
 		created_at,
 		updated_at,
 		login_type,
-		scope
+		scope,
+		scope_permissions,
+		scope_organization_id
 	)
 VALUES
 	($1,
@@ -275,21 +333,23 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE $2::bigint
 	 END
-	 , $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope
+	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, scope_permissions, scope_organization_id
 `
 
 type InsertAPIKeyParams struct {
-	ID              string      `db:"id" json:"id"`
-	LifetimeSeconds int64       `db:"lifetime_seconds" json:"lifetime_seconds"`
-	HashedSecret    []byte      `db:"hashed_secret" json:"hashed_secret"`
-	IPAddress       pqtype.Inet `db:"ip_address" json:"ip_address"`
-	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
-	LastUsed        time.Time   `db:"last_used" json:"last_used"`
-	ExpiresAt       time.Time   `db:"expires_at" json:"expires_at"`
-	CreatedAt       time.Time   `db:"created_at" json:"created_at"`
-	UpdatedAt       time.Time   `db:"updated_at" json:"updated_at"`
-	LoginType       LoginType   `db:"login_type" json:"login_type"`
-	Scope           APIKeyScope `db:"scope" json:"scope"`
+	ID                  string        `db:"id" json:"id"`
+	LifetimeSeconds     int64         `db:"lifetime_seconds" json:"lifetime_seconds"`
+	HashedSecret        []byte        `db:"hashed_secret" json:"hashed_secret"`
+	IPAddress           pqtype.Inet   `db:"ip_address" json:"ip_address"`
+	UserID              uuid.UUID     `db:"user_id" json:"user_id"`
+	LastUsed            time.Time     `db:"last_used" json:"last_used"`
+	ExpiresAt           time.Time     `db:"expires_at" json:"expires_at"`
+	CreatedAt           time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time     `db:"updated_at" json:"updated_at"`
+	LoginType           LoginType     `db:"login_type" json:"login_type"`
+	Scope               APIKeyScope   `db:"scope" json:"scope"`
+	ScopePermissions    []string      `db:"scope_permissions" json:"scope_permissions"`
+	ScopeOrganizationID uuid.NullUUID `db:"scope_organization_id" json:"scope_organization_id"`
 }
 
 func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error) {
@@ -305,6 +365,8 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		arg.UpdatedAt,
 		arg.LoginType,
 		arg.Scope,
+		pq.Array(arg.ScopePermissions),
+		arg.ScopeOrganizationID,
 	)
 	var i APIKey
 	err := row.Scan(
@@ -319,6 +381,8 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		&i.LifetimeSeconds,
 		&i.IPAddress,
 		&i.Scope,
+		pq.Array(&i.ScopePermissions),
+		&i.ScopeOrganizationID,
 	)
 	return i, err
 }
@@ -393,6 +457,12 @@ WHERE
 			user_id = (SELECT id from users WHERE users.email = $6 )
 		ELSE true
 	END
+	-- Filter by organization_id
+	AND CASE
+		WHEN $7 :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_id = $7
+		ELSE true
+	END
 `
 
 type GetAuditLogCountParams struct {
@@ -402,6 +472,7 @@ type GetAuditLogCountParams struct {
 	Action         string    `db:"action" json:"action"`
 	Username       string    `db:"username" json:"username"`
 	Email          string    `db:"email" json:"email"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
 }
 
 func (q *sqlQuerier) GetAuditLogCount(ctx context.Context, arg GetAuditLogCountParams) (int64, error) {
@@ -412,6 +483,7 @@ func (q *sqlQuerier) GetAuditLogCount(ctx context.Context, arg GetAuditLogCountP
 		arg.Action,
 		arg.Username,
 		arg.Email,
+		arg.OrganizationID,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -468,8 +540,28 @@ WHERE
 			users.email = $8
 		ELSE true
 	END
+	-- Filter by organization_id
+	AND CASE
+		WHEN $9 :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_id = $9
+		ELSE true
+	END
+	-- This allows using the last element on a page as effectively a cursor.
+	AND CASE
+		WHEN $10 :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(audit_logs."time", audit_logs.id) < (
+				SELECT
+					"time", id
+				FROM
+					audit_logs
+				WHERE
+					id = $10
+			)
+		)
+		ELSE true
+	END
 ORDER BY
-    "time" DESC
+    "time" DESC, audit_logs.id DESC
 LIMIT
     $1
 OFFSET
@@ -485,6 +577,8 @@ type GetAuditLogsOffsetParams struct {
 	Action         string    `db:"action" json:"action"`
 	Username       string    `db:"username" json:"username"`
 	Email          string    `db:"email" json:"email"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	AfterID        uuid.UUID `db:"after_id" json:"after_id"`
 }
 
 type GetAuditLogsOffsetRow struct {
@@ -523,6 +617,8 @@ func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOff
 		arg.Action,
 		arg.Username,
 		arg.Email,
+		arg.OrganizationID,
+		arg.AfterID,
 	)
 	if err != nil {
 		return nil, err
@@ -647,6 +743,81 @@ func (q *sqlQuerier) InsertAuditLog(ctx context.Context, arg InsertAuditLogParam
 	return i, err
 }
 
+const deleteEveryoneGroupExclusion = `-- name: DeleteEveryoneGroupExclusion :exec
+DELETE FROM
+	everyone_group_exclusions
+WHERE
+	organization_id = $1
+AND
+	user_id = $2
+`
+
+type DeleteEveryoneGroupExclusionParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) DeleteEveryoneGroupExclusion(ctx context.Context, arg DeleteEveryoneGroupExclusionParams) error {
+	_, err := q.db.ExecContext(ctx, deleteEveryoneGroupExclusion, arg.OrganizationID, arg.UserID)
+	return err
+}
+
+const getEveryoneGroupExclusionsByOrganizationID = `-- name: GetEveryoneGroupExclusionsByOrganizationID :many
+SELECT
+	organization_id, user_id, created_at
+FROM
+	everyone_group_exclusions
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetEveryoneGroupExclusionsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]EveryoneGroupExclusion, error) {
+	rows, err := q.db.QueryContext(ctx, getEveryoneGroupExclusionsByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EveryoneGroupExclusion
+	for rows.Next() {
+		var i EveryoneGroupExclusion
+		if err := rows.Scan(&i.OrganizationID, &i.UserID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertEveryoneGroupExclusion = `-- name: InsertEveryoneGroupExclusion :exec
+INSERT INTO everyone_group_exclusions (
+	organization_id,
+	user_id,
+	created_at
+)
+VALUES
+	($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type InsertEveryoneGroupExclusionParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertEveryoneGroupExclusion(ctx context.Context, arg InsertEveryoneGroupExclusionParams) error {
+	_, err := q.db.ExecContext(ctx, insertEveryoneGroupExclusion, arg.OrganizationID, arg.UserID, arg.CreatedAt)
+	return err
+}
+
 const getFileByHash = `-- name: GetFileByHash :one
 SELECT
 	hash, created_at, created_by, mimetype, data
@@ -807,65 +978,64 @@ func (q *sqlQuerier) UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyPar
 	return err
 }
 
-const deleteGroupByID = `-- name: DeleteGroupByID :exec
-DELETE FROM 
-	groups 
+const deleteGroupJoinRequestByID = `-- name: DeleteGroupJoinRequestByID :exec
+DELETE FROM
+	group_join_requests
 WHERE
 	id = $1
 `
 
-func (q *sqlQuerier) DeleteGroupByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupByID, id)
+func (q *sqlQuerier) DeleteGroupJoinRequestByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupJoinRequestByID, id)
 	return err
 }
 
-const deleteGroupMember = `-- name: DeleteGroupMember :exec
-DELETE FROM 
-	group_members 
+const getGroupJoinRequestByID = `-- name: GetGroupJoinRequestByID :one
+SELECT
+	id, group_id, user_id, created_at
+FROM
+	group_join_requests
 WHERE
-	user_id = $1
+	id = $1
 `
 
-func (q *sqlQuerier) DeleteGroupMember(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupMember, userID)
-	return err
+func (q *sqlQuerier) GetGroupJoinRequestByID(ctx context.Context, id uuid.UUID) (GroupJoinRequest, error) {
+	row := q.db.QueryRowContext(ctx, getGroupJoinRequestByID, id)
+	var i GroupJoinRequest
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.UserID,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
-const getAllOrganizationMembers = `-- name: GetAllOrganizationMembers :many
+const getGroupJoinRequestsByGroupID = `-- name: GetGroupJoinRequestsByGroupID :many
 SELECT
-	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
+	id, group_id, user_id, created_at
 FROM
-	users
-JOIN
-	organization_members
-ON
-	users.id = organization_members.user_id
+	group_join_requests
 WHERE
-	organization_members.organization_id = $1
+	group_id = $1
+ORDER BY
+	created_at ASC
 `
 
-func (q *sqlQuerier) GetAllOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getAllOrganizationMembers, organizationID)
+func (q *sqlQuerier) GetGroupJoinRequestsByGroupID(ctx context.Context, groupID uuid.UUID) ([]GroupJoinRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupJoinRequestsByGroupID, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []GroupJoinRequest
 	for rows.Next() {
-		var i User
+		var i GroupJoinRequest
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
+			&i.GroupID,
+			&i.UserID,
 			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -880,68 +1050,105 @@ func (q *sqlQuerier) GetAllOrganizationMembers(ctx context.Context, organization
 	return items, nil
 }
 
-const getGroupByID = `-- name: GetGroupByID :one
-SELECT
-	id, name, organization_id
-FROM
-	groups
-WHERE
-	id = $1
-LIMIT
-	1
+const insertGroupJoinRequest = `-- name: InsertGroupJoinRequest :one
+INSERT INTO group_join_requests (
+	id,
+	group_id,
+	user_id,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4) RETURNING id, group_id, user_id, created_at
 `
 
-func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByID, id)
-	var i Group
-	err := row.Scan(&i.ID, &i.Name, &i.OrganizationID)
+type InsertGroupJoinRequestParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	GroupID   uuid.UUID `db:"group_id" json:"group_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertGroupJoinRequest(ctx context.Context, arg InsertGroupJoinRequestParams) (GroupJoinRequest, error) {
+	row := q.db.QueryRowContext(ctx,
+		insertGroupJoinRequest,
+		arg.ID,
+		arg.GroupID,
+		arg.UserID,
+		arg.CreatedAt,
+	)
+	var i GroupJoinRequest
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.UserID,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
-const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
-SELECT
-	id, name, organization_id
-FROM
-	groups
+const deleteExpiredGroupMembers = `-- name: DeleteExpiredGroupMembers :many
+DELETE FROM
+	group_members
 WHERE
-	organization_id = $1
-AND
-	name = $2
-LIMIT
-	1
+	expires_at <= NOW()
+RETURNING user_id, group_id, expires_at, roles
 `
 
-type GetGroupByOrgAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Name           string    `db:"name" json:"name"`
+func (q *sqlQuerier) DeleteExpiredGroupMembers(ctx context.Context) ([]GroupMember, error) {
+	rows, err := q.db.QueryContext(ctx, deleteExpiredGroupMembers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GroupMember
+	for rows.Next() {
+		var i GroupMember
+		if err := rows.Scan(
+			&i.UserID,
+			&i.GroupID,
+			&i.ExpiresAt,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
-	var i Group
-	err := row.Scan(&i.ID, &i.Name, &i.OrganizationID)
-	return i, err
+const deleteGroupMember = `-- name: DeleteGroupMember :exec
+DELETE FROM 
+	group_members 
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteGroupMember(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupMember, userID)
+	return err
 }
 
-const getGroupMembers = `-- name: GetGroupMembers :many
+const getAllOrganizationMembers = `-- name: GetAllOrganizationMembers :many
 SELECT
 	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
 FROM
 	users
 JOIN
-	group_members
+	organization_members
 ON
-	users.id = group_members.user_id
+	users.id = organization_members.user_id
 WHERE
-	group_members.group_id = $1
-AND
-	users.status = 'active'
-AND
-	users.deleted = 'false'
+	organization_members.organization_id = $1
 `
 
-func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupMembers, groupID)
+func (q *sqlQuerier) GetAllOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getAllOrganizationMembers, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -976,27 +1183,55 @@ func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]
 	return items, nil
 }
 
-const getGroupsByOrganizationID = `-- name: GetGroupsByOrganizationID :many
+const getEveryoneGroupMembers = `-- name: GetEveryoneGroupMembers :many
 SELECT
-	id, name, organization_id
+	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
 FROM
-	groups
+	users
+JOIN
+	organization_members
+ON
+	users.id = organization_members.user_id
 WHERE
-	organization_id = $1
+	organization_members.organization_id = $1
 AND
-	id != $1
-`
-
-func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
+	NOT EXISTS (
+		SELECT
+			1
+		FROM
+			everyone_group_exclusions
+		WHERE
+			everyone_group_exclusions.organization_id = organization_members.organization_id
+		AND
+			everyone_group_exclusions.user_id = users.id
+	)
+`
+
+// Returns the members of the organization's "Everyone" group, which is every
+// organization member that hasn't been excluded from it.
+func (q *sqlQuerier) GetEveryoneGroupMembers(ctx context.Context, organizationID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getEveryoneGroupMembers, organizationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Group
+	var items []User
 	for rows.Next() {
-		var i Group
-		if err := rows.Scan(&i.ID, &i.Name, &i.OrganizationID); err != nil {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1010,32 +1245,34 @@ func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organization
 	return items, nil
 }
 
-const getUserGroups = `-- name: GetUserGroups :many
-SELECT
-	groups.id, groups.name, groups.organization_id
-FROM
-	groups
-JOIN
+const deleteGroupMembersExceptUserIDs = `-- name: DeleteGroupMembersExceptUserIDs :many
+DELETE FROM
 	group_members
-ON
-	groups.id = group_members.group_id
 WHERE
-	group_members.user_id = $1
+	group_id = $1
+AND
+	NOT (user_id = ANY($2 :: uuid [ ]))
+RETURNING user_id
 `
 
-func (q *sqlQuerier) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error) {
-	rows, err := q.db.QueryContext(ctx, getUserGroups, userID)
+type DeleteGroupMembersExceptUserIDsParams struct {
+	GroupID uuid.UUID   `db:"group_id" json:"group_id"`
+	UserIds []uuid.UUID `db:"user_ids" json:"user_ids"`
+}
+
+func (q *sqlQuerier) DeleteGroupMembersExceptUserIDs(ctx context.Context, arg DeleteGroupMembersExceptUserIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteGroupMembersExceptUserIDs, arg.GroupID, pq.Array(arg.UserIds))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Group
+	var items []uuid.UUID
 	for rows.Next() {
-		var i Group
-		if err := rows.Scan(&i.ID, &i.Name, &i.OrganizationID); err != nil {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, user_id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -1046,122 +1283,39 @@ func (q *sqlQuerier) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]Gro
 	return items, nil
 }
 
-const insertAllUsersGroup = `-- name: InsertAllUsersGroup :one
-INSERT INTO groups (
-	id,
-	name,
-	organization_id
-)
-VALUES
-	( $1, 'Everyone', $1) RETURNING id, name, organization_id
-`
-
-// We use the organization_id as the id
-// for simplicity since all users is
-// every member of the org.
-func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertAllUsersGroup, organizationID)
-	var i Group
-	err := row.Scan(&i.ID, &i.Name, &i.OrganizationID)
-	return i, err
-}
-
-const insertGroup = `-- name: InsertGroup :one
-INSERT INTO groups (
-	id,
-	name,
-	organization_id
-)
-VALUES
-	( $1, $2, $3) RETURNING id, name, organization_id
-`
-
-type InsertGroupParams struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	Name           string    `db:"name" json:"name"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-}
-
-func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertGroup, arg.ID, arg.Name, arg.OrganizationID)
-	var i Group
-	err := row.Scan(&i.ID, &i.Name, &i.OrganizationID)
-	return i, err
-}
-
-const insertGroupMember = `-- name: InsertGroupMember :exec
-INSERT INTO group_members (
-	user_id,
-	group_id
-)
-VALUES ( $1, $2)
-`
-
-type InsertGroupMemberParams struct {
-	UserID  uuid.UUID `db:"user_id" json:"user_id"`
-	GroupID uuid.UUID `db:"group_id" json:"group_id"`
-}
-
-func (q *sqlQuerier) InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error {
-	_, err := q.db.ExecContext(ctx, insertGroupMember, arg.UserID, arg.GroupID)
-	return err
-}
-
-const updateGroupByID = `-- name: UpdateGroupByID :one
-UPDATE
+const deleteGroupsDeletedBefore = `-- name: DeleteGroupsDeletedBefore :many
+DELETE FROM
 	groups
-SET
-	name = $1
 WHERE
-	id = $2
-RETURNING id, name, organization_id
-`
-
-type UpdateGroupByIDParams struct {
-	Name string    `db:"name" json:"name"`
-	ID   uuid.UUID `db:"id" json:"id"`
-}
-
-func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, updateGroupByID, arg.Name, arg.ID)
-	var i Group
-	err := row.Scan(&i.ID, &i.Name, &i.OrganizationID)
-	return i, err
-}
-
-const deleteLicense = `-- name: DeleteLicense :one
-DELETE
-FROM licenses
-WHERE id = $1
-RETURNING id
-`
-
-func (q *sqlQuerier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
-	row := q.db.QueryRowContext(ctx, deleteLicense, id)
-	err := row.Scan(&id)
-	return id, err
-}
-
-const getLicenses = `-- name: GetLicenses :many
-SELECT id, uploaded_at, jwt, exp
-FROM licenses
-ORDER BY (id)
+	deleted_at < $1 :: timestamptz
+RETURNING id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
 `
 
-func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
-	rows, err := q.db.QueryContext(ctx, getLicenses)
+// Permanently removes groups that were soft deleted before the given time.
+func (q *sqlQuerier) DeleteGroupsDeletedBefore(ctx context.Context, deletedBefore time.Time) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, deleteGroupsDeletedBefore, deletedBefore)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []License
+	var items []Group
 	for rows.Next() {
-		var i License
+		var i Group
 		if err := rows.Scan(
 			&i.ID,
-			&i.UploadedAt,
-			&i.JWT,
-			&i.Exp,
+			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
+			&i.Description,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
 		); err != nil {
 			return nil, err
 		}
@@ -1176,31 +1330,34 @@ func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
 	return items, nil
 }
 
-const getUnexpiredLicenses = `-- name: GetUnexpiredLicenses :many
-SELECT id, uploaded_at, jwt, exp
-FROM licenses
-WHERE exp > NOW()
-ORDER BY (id)
+const deleteUserFromGroups = `-- name: DeleteUserFromGroups :many
+DELETE FROM
+	group_members
+WHERE
+	user_id = $1
+AND
+	group_id = ANY($2 :: uuid [ ])
+RETURNING group_id
 `
 
-func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error) {
-	rows, err := q.db.QueryContext(ctx, getUnexpiredLicenses)
+type DeleteUserFromGroupsParams struct {
+	UserID   uuid.UUID   `db:"user_id" json:"user_id"`
+	GroupIds []uuid.UUID `db:"group_ids" json:"group_ids"`
+}
+
+func (q *sqlQuerier) DeleteUserFromGroups(ctx context.Context, arg DeleteUserFromGroupsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteUserFromGroups, arg.UserID, pq.Array(arg.GroupIds))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []License
+	var items []uuid.UUID
 	for rows.Next() {
-		var i License
-		if err := rows.Scan(
-			&i.ID,
-			&i.UploadedAt,
-			&i.JWT,
-			&i.Exp,
-		); err != nil {
+		var group_id uuid.UUID
+		if err := rows.Scan(&group_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, group_id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -1211,64 +1368,46 @@ func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error
 	return items, nil
 }
 
-const insertLicense = `-- name: InsertLicense :one
-INSERT INTO
-	licenses (
-	uploaded_at,
-	jwt,
-	exp
+const getGroupAncestorIDs = `-- name: GetGroupAncestorIDs :many
+WITH RECURSIVE ancestors AS (
+	SELECT
+		id,
+		parent_id
+	FROM
+		groups
+	WHERE
+		groups.id = $1
+	UNION
+	SELECT
+		groups.id,
+		groups.parent_id
+	FROM
+		groups
+	JOIN
+		ancestors
+	ON
+		groups.id = ancestors.parent_id
 )
-VALUES
-	($1, $2, $3) RETURNING id, uploaded_at, jwt, exp
-`
-
-type InsertLicenseParams struct {
-	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
-	JWT        string    `db:"jwt" json:"jwt"`
-	Exp        time.Time `db:"exp" json:"exp"`
-}
-
-func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error) {
-	row := q.db.QueryRowContext(ctx, insertLicense, arg.UploadedAt, arg.JWT, arg.Exp)
-	var i License
-	err := row.Scan(
-		&i.ID,
-		&i.UploadedAt,
-		&i.JWT,
-		&i.Exp,
-	)
-	return i, err
-}
-
-const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
 SELECT
-    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+	id
 FROM
-    organization_members
-WHERE
-    user_id = ANY($1 :: uuid [ ])
-GROUP BY
-    user_id
+	ancestors
 `
 
-type GetOrganizationIDsByMemberIDsRow struct {
-	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
-	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
-}
-
-func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
+// Returns the IDs of the group and every group above it in the hierarchy.
+func (q *sqlQuerier) GetGroupAncestorIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupAncestorIDs, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetOrganizationIDsByMemberIDsRow
+	var items []uuid.UUID
 	for rows.Next() {
-		var i GetOrganizationIDsByMemberIDsRow
-		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -1279,64 +1418,74 @@ func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uu
 	return items, nil
 }
 
-const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
+const getGroupAutostopUserIDs = `-- name: GetGroupAutostopUserIDs :many
+-- Returns the users at least one group with an autostop policy applies to:
+-- unexpired members of the group or of the groups nested beneath it, and
+-- the members of the organization for its "Everyone" group.
+WITH RECURSIVE policy_groups AS (
+	SELECT
+		id
+	FROM
+		groups
+	WHERE
+		deleted_at IS NULL
+	AND
+		(autostop_schedule != '' OR max_ttl > 0)
+	UNION
+	SELECT
+		groups.id
+	FROM
+		groups
+	JOIN
+		policy_groups
+	ON
+		groups.parent_id = policy_groups.id
+	WHERE
+		groups.deleted_at IS NULL
+)
 SELECT
-	user_id, organization_id, created_at, updated_at, roles
+	group_members.user_id
 FROM
-	organization_members
+	group_members
 WHERE
-	organization_id = $1
-	AND user_id = $2
-LIMIT
-	1
-`
-
-type GetOrganizationMemberByUserIDParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-}
-
-func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationMemberByUserID, arg.OrganizationID, arg.UserID)
-	var i OrganizationMember
-	err := row.Scan(
-		&i.UserID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		pq.Array(&i.Roles),
-	)
-	return i, err
-}
-
-const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
+	group_members.group_id IN (SELECT id FROM policy_groups)
+AND
+	(group_members.expires_at IS NULL OR group_members.expires_at > NOW())
+UNION
 SELECT
-	user_id, organization_id, created_at, updated_at, roles
+	organization_members.user_id
 FROM
 	organization_members
 WHERE
-  user_id = $1
+	organization_members.organization_id IN (SELECT id FROM policy_groups)
+AND NOT EXISTS (
+	SELECT
+		1
+	FROM
+		everyone_group_exclusions
+	WHERE
+		everyone_group_exclusions.organization_id = organization_members.organization_id
+	AND
+		everyone_group_exclusions.user_id = organization_members.user_id
+)
 `
 
-func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationMembershipsByUserID, userID)
+// Returns the users at least one group with an autostop policy applies to:
+// unexpired members of the group or of the groups nested beneath it, and
+// the members of the organization for its "Everyone" group.
+func (q *sqlQuerier) GetGroupAutostopUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupAutostopUserIDs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []OrganizationMember
+	var items []uuid.UUID
 	for rows.Next() {
-		var i OrganizationMember
-		if err := rows.Scan(
-			&i.UserID,
-			&i.OrganizationID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			pq.Array(&i.Roles),
-		); err != nil {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, user_id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -1347,145 +1496,210 @@ func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, use
 	return items, nil
 }
 
-const insertOrganizationMember = `-- name: InsertOrganizationMember :one
-INSERT INTO
-	organization_members (
-		organization_id,
-		user_id,
-		created_at,
-		updated_at,
-		roles
-	)
-VALUES
-	($1, $2, $3, $4, $5) RETURNING user_id, organization_id, created_at, updated_at, roles
+const getGroupByID = `-- name: GetGroupByID :one
+SELECT
+	id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+FROM
+	groups
+WHERE
+	id = $1
+LIMIT
+	1
 `
 
-type InsertOrganizationMemberParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt      time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
-	Roles          []string  `db:"roles" json:"roles"`
-}
-
-func (q *sqlQuerier) InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, insertOrganizationMember,
-		arg.OrganizationID,
-		arg.UserID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		pq.Array(arg.Roles),
-	)
-	var i OrganizationMember
+func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroupByID, id)
+	var i Group
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
 		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.ParentID,
+		&i.DisplayName,
+		&i.AvatarURL,
+		&i.Description,
+		&i.Source,
+		&i.DeletedAt,
+		&i.Metadata,
+		&i.AutostopSchedule,
+		&i.MaxTtl,
+		&i.QuotaAllowance,
 		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const updateMemberRoles = `-- name: UpdateMemberRoles :one
-UPDATE
-	organization_members
-SET
-	-- Remove all duplicates from the roles.
-	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
+SELECT
+	id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+FROM
+	groups
 WHERE
-	user_id = $2
-	AND organization_id = $3
-RETURNING user_id, organization_id, created_at, updated_at, roles
+	-- Deployment-wide groups are matched when organization_id is null.
+	organization_id IS NOT DISTINCT FROM $1
+AND
+	name = $2
+AND
+	deleted_at IS NULL
+LIMIT
+	1
 `
 
-type UpdateMemberRolesParams struct {
-	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
-	UserID       uuid.UUID `db:"user_id" json:"user_id"`
-	OrgID        uuid.UUID `db:"org_id" json:"org_id"`
+type GetGroupByOrgAndNameParams struct {
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	Name           string        `db:"name" json:"name"`
 }
 
-func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, updateMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.OrgID)
-	var i OrganizationMember
+func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
+	var i Group
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
 		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.ParentID,
+		&i.DisplayName,
+		&i.AvatarURL,
+		&i.Description,
+		&i.Source,
+		&i.DeletedAt,
+		&i.Metadata,
+		&i.AutostopSchedule,
+		&i.MaxTtl,
+		&i.QuotaAllowance,
 		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const getOrganizationByID = `-- name: GetOrganizationByID :one
+const getGroupMemberCountsByGroupIDs = `-- name: GetGroupMemberCountsByGroupIDs :many
 SELECT
-	id, name, description, created_at, updated_at
+	group_members.group_id,
+	COUNT(*) AS count
 FROM
-	organizations
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
 WHERE
-	id = $1
+	group_members.group_id = ANY($1 :: uuid [ ])
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
+GROUP BY
+	group_members.group_id
 `
 
-func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
-	var i Organization
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type GetGroupMemberCountsByGroupIDsRow struct {
+	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+	Count   int64     `db:"count" json:"count"`
 }
 
-const getOrganizationByName = `-- name: GetOrganizationByName :one
+// Counts the members returned by GetGroupMembersByGroupIDs without fetching
+// them. Groups without members are omitted.
+func (q *sqlQuerier) GetGroupMemberCountsByGroupIDs(ctx context.Context, groupIds []uuid.UUID) ([]GetGroupMemberCountsByGroupIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMemberCountsByGroupIDs, pq.Array(groupIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupMemberCountsByGroupIDsRow
+	for rows.Next() {
+		var i GetGroupMemberCountsByGroupIDsRow
+		if err := rows.Scan(&i.GroupID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupMemberIDsWithRole = `-- name: GetGroupMemberIDsWithRole :many
 SELECT
-	id, name, description, created_at, updated_at
+	user_id
 FROM
-	organizations
+	group_members
 WHERE
-	LOWER("name") = LOWER($1)
-LIMIT
-	1
+	group_id = $1
+AND
+	$2 :: text = ANY(roles)
 `
 
-func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
-	var i Organization
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type GetGroupMemberIDsWithRoleParams struct {
+	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+	Role    string    `db:"role" json:"role"`
 }
 
-const getOrganizations = `-- name: GetOrganizations :many
+func (q *sqlQuerier) GetGroupMemberIDsWithRole(ctx context.Context, arg GetGroupMemberIDsWithRoleParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMemberIDsWithRole, arg.GroupID, arg.Role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupMembers = `-- name: GetGroupMembers :many
 SELECT
-	id, name, description, created_at, updated_at
+	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
 FROM
-	organizations
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = $1
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
 `
 
-func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizations)
+func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMembers, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Organization
+	var items []User
 	for rows.Next() {
-		var i Organization
+		var i User
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1500,37 +1714,69 @@ func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, erro
 	return items, nil
 }
 
-const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
+const getGroupMembersByGroupIDs = `-- name: GetGroupMembersByGroupIDs :many
 SELECT
-	id, name, description, created_at, updated_at
+	group_members.group_id,
+	group_members.expires_at,
+	group_members.roles AS group_roles,
+	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
 FROM
-	organizations
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
 WHERE
-	id = (
-		SELECT
-			organization_id
-		FROM
-			organization_members
-		WHERE
-			user_id = $1
-	)
+	group_members.group_id = ANY($1 :: uuid [ ])
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
 `
 
-func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationsByUserID, userID)
+type GetGroupMembersByGroupIDsRow struct {
+	GroupID        uuid.UUID      `db:"group_id" json:"group_id"`
+	ExpiresAt      sql.NullTime   `db:"expires_at" json:"expires_at"`
+	GroupRoles     []string       `db:"group_roles" json:"group_roles"`
+	ID             uuid.UUID      `db:"id" json:"id"`
+	Email          string         `db:"email" json:"email"`
+	Username       string         `db:"username" json:"username"`
+	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	Status         UserStatus     `db:"status" json:"status"`
+	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType      LoginType      `db:"login_type" json:"login_type"`
+	AvatarURL      sql.NullString `db:"avatar_url" json:"avatar_url"`
+	Deleted        bool           `db:"deleted" json:"deleted"`
+	LastSeenAt     time.Time      `db:"last_seen_at" json:"last_seen_at"`
+}
+
+func (q *sqlQuerier) GetGroupMembersByGroupIDs(ctx context.Context, groupIds []uuid.UUID) ([]GetGroupMembersByGroupIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMembersByGroupIDs, pq.Array(groupIds))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Organization
+	var items []GetGroupMembersByGroupIDsRow
 	for rows.Next() {
-		var i Organization
+		var i GetGroupMembersByGroupIDsRow
 		if err := rows.Scan(
+			&i.GroupID,
+			&i.ExpiresAt,
+			pq.Array(&i.GroupRoles),
 			&i.ID,
-			&i.Name,
-			&i.Description,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1545,78 +1791,267 @@ func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.U
 	return items, nil
 }
 
-const insertOrganization = `-- name: InsertOrganization :one
-INSERT INTO
-	organizations (id, "name", description, created_at, updated_at)
-VALUES
-	($1, $2, $3, $4, $5) RETURNING id, name, description, created_at, updated_at
-`
-
-type InsertOrganizationParams struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+const getGroupMembersPage = `-- name: GetGroupMembersPage :many
+SELECT
+	group_members.expires_at,
+	group_members.roles AS group_roles,
+	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
+FROM
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = $1
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
+AND CASE
+	-- This allows using the last element on a page as effectively a cursor.
+	WHEN $2 :: uuid != '00000000-00000000-00000000-00000000' THEN (
+		(users.username, users.id) > (
+			SELECT
+				username, id
+			FROM
+				users
+			WHERE
+				id = $2
+		)
+	)
+	ELSE true
+END
+ORDER BY
+	(users.username, users.id) ASC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($3 :: int, 0)
+`
+
+type GetGroupMembersPageParams struct {
+	GroupID  uuid.UUID `db:"group_id" json:"group_id"`
+	AfterID  uuid.UUID `db:"after_id" json:"after_id"`
+	LimitOpt int32     `db:"limit_opt" json:"limit_opt"`
 }
 
-func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, insertOrganization,
-		arg.ID,
-		arg.Name,
-		arg.Description,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-	)
-	var i Organization
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type GetGroupMembersPageRow struct {
+	ExpiresAt      sql.NullTime   `db:"expires_at" json:"expires_at"`
+	GroupRoles     []string       `db:"group_roles" json:"group_roles"`
+	ID             uuid.UUID      `db:"id" json:"id"`
+	Email          string         `db:"email" json:"email"`
+	Username       string         `db:"username" json:"username"`
+	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	Status         UserStatus     `db:"status" json:"status"`
+	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType      LoginType      `db:"login_type" json:"login_type"`
+	AvatarURL      sql.NullString `db:"avatar_url" json:"avatar_url"`
+	Deleted        bool           `db:"deleted" json:"deleted"`
+	LastSeenAt     time.Time      `db:"last_seen_at" json:"last_seen_at"`
 }
 
-const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+// Returns the active members of a group ordered by username, a page at a
+// time.
+func (q *sqlQuerier) GetGroupMembersPage(ctx context.Context, arg GetGroupMembersPageParams) ([]GetGroupMembersPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMembersPage, arg.GroupID, arg.AfterID, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupMembersPageRow
+	for rows.Next() {
+		var i GetGroupMembersPageRow
+		if err := rows.Scan(
+			&i.ExpiresAt,
+			pq.Array(&i.GroupRoles),
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupMembershipsByUserIDs = `-- name: GetGroupMembershipsByUserIDs :many
 SELECT
-	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+	group_members.user_id,
+	groups.id AS group_id,
+	groups.name AS group_name
 FROM
-	parameter_schemas
+	group_members
+JOIN
+	groups
+ON
+	groups.id = group_members.group_id
 WHERE
-	job_id = $1
+	groups.organization_id = $1 :: uuid
+AND
+	groups.deleted_at IS NULL
+AND
+	group_members.user_id = ANY($2 :: uuid [ ])
+`
+
+type GetGroupMembershipsByUserIDsParams struct {
+	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
+	UserIds        []uuid.UUID `db:"user_ids" json:"user_ids"`
+}
+
+type GetGroupMembershipsByUserIDsRow struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	GroupID   uuid.UUID `db:"group_id" json:"group_id"`
+	GroupName string    `db:"group_name" json:"group_name"`
+}
+
+func (q *sqlQuerier) GetGroupMembershipsByUserIDs(ctx context.Context, arg GetGroupMembershipsByUserIDsParams) ([]GetGroupMembershipsByUserIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMembershipsByUserIDs, arg.OrganizationID, pq.Array(arg.UserIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupMembershipsByUserIDsRow
+	for rows.Next() {
+		var i GetGroupMembershipsByUserIDsRow
+		if err := rows.Scan(&i.UserID, &i.GroupID, &i.GroupName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroups = `-- name: GetGroups :many
+SELECT
+	id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles, count
+FROM
+	(
+		SELECT
+			id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles,
+			-- The number of groups matching the filters, ignoring the cursor,
+			-- offset, and limit, so every page reports the same total.
+			COUNT(*) OVER() AS count
+		FROM
+			groups
+		WHERE
+			-- Deployment-wide groups are listed when organization_id is null.
+			organization_id IS NOT DISTINCT FROM $1
+			-- The "Everyone" group shares its ID with the organization and is
+			-- never listed.
+			AND id IS DISTINCT FROM $1
+			AND deleted_at IS NULL
+			-- Filter by name
+			AND CASE
+				WHEN $2 :: text != '' THEN
+					name ILIKE concat('%', $2, '%')
+				ELSE true
+			END
+			-- GetAuthorizedGroups replaces this comment with the authorize filter.
+			-- @authorize_filter
+	) AS filtered_groups
+WHERE
+	CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		WHEN $3 :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(name, id) > (
+				SELECT
+					name, id
+				FROM
+					groups
+				WHERE
+					id = $3
+			)
+		)
+		ELSE true
+	END
 ORDER BY
-	index
+	(name, id) ASC OFFSET $4
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($5 :: int, 0)
 `
 
-func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
-	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
+type GetGroupsParams struct {
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	Search         string        `db:"search" json:"search"`
+	AfterID        uuid.UUID     `db:"after_id" json:"after_id"`
+	OffsetOpt      int32         `db:"offset_opt" json:"offset_opt"`
+	LimitOpt       int32         `db:"limit_opt" json:"limit_opt"`
+}
+
+type GetGroupsRow struct {
+	ID               uuid.UUID       `db:"id" json:"id"`
+	Name             string          `db:"name" json:"name"`
+	OrganizationID   uuid.NullUUID   `db:"organization_id" json:"organization_id"`
+	ParentID         uuid.NullUUID   `db:"parent_id" json:"parent_id"`
+	DisplayName      string          `db:"display_name" json:"display_name"`
+	AvatarURL        string          `db:"avatar_url" json:"avatar_url"`
+	Description      string          `db:"description" json:"description"`
+	Source           GroupSource     `db:"source" json:"source"`
+	DeletedAt        sql.NullTime    `db:"deleted_at" json:"deleted_at"`
+	Metadata         json.RawMessage `db:"metadata" json:"metadata"`
+	AutostopSchedule string          `db:"autostop_schedule" json:"autostop_schedule"`
+	MaxTtl           int64           `db:"max_ttl" json:"max_ttl"`
+	QuotaAllowance   int32           `db:"quota_allowance" json:"quota_allowance"`
+	Roles            []string        `db:"roles" json:"roles"`
+	Count            int64           `db:"count" json:"count"`
+}
+
+func (q *sqlQuerier) GetGroups(ctx context.Context, arg GetGroupsParams) ([]GetGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGroups,
+		arg.OrganizationID,
+		arg.Search,
+		arg.AfterID,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ParameterSchema
+	var items []GetGroupsRow
 	for rows.Next() {
-		var i ParameterSchema
+		var i GetGroupsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
-			&i.JobID,
 			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
 			&i.Description,
-			&i.DefaultSourceScheme,
-			&i.DefaultSourceValue,
-			&i.AllowOverrideSource,
-			&i.DefaultDestinationScheme,
-			&i.AllowOverrideDestination,
-			&i.DefaultRefresh,
-			&i.RedisplayValue,
-			&i.ValidationError,
-			&i.ValidationCondition,
-			&i.ValidationTypeSystem,
-			&i.ValidationValueType,
-			&i.Index,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
+			&i.Count,
 		); err != nil {
 			return nil, err
 		}
@@ -1631,37 +2066,43 @@ func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.
 	return items, nil
 }
 
-const getParameterSchemasCreatedAfter = `-- name: GetParameterSchemasCreatedAfter :many
-SELECT id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index FROM parameter_schemas WHERE created_at > $1
+const getGroupsByOrganizationID = `-- name: GetGroupsByOrganizationID :many
+SELECT
+	id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+FROM
+	groups
+WHERE
+	organization_id = $1 :: uuid
+AND
+	id != $1
+AND
+	deleted_at IS NULL
 `
 
-func (q *sqlQuerier) GetParameterSchemasCreatedAfter(ctx context.Context, createdAt time.Time) ([]ParameterSchema, error) {
-	rows, err := q.db.QueryContext(ctx, getParameterSchemasCreatedAfter, createdAt)
+func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ParameterSchema
+	var items []Group
 	for rows.Next() {
-		var i ParameterSchema
+		var i Group
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
-			&i.JobID,
 			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
 			&i.Description,
-			&i.DefaultSourceScheme,
-			&i.DefaultSourceValue,
-			&i.AllowOverrideSource,
-			&i.DefaultDestinationScheme,
-			&i.AllowOverrideDestination,
-			&i.DefaultRefresh,
-			&i.RedisplayValue,
-			&i.ValidationError,
-			&i.ValidationCondition,
-			&i.ValidationTypeSystem,
-			&i.ValidationValueType,
-			&i.Index,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
 		); err != nil {
 			return nil, err
 		}
@@ -1676,299 +2117,4967 @@ func (q *sqlQuerier) GetParameterSchemasCreatedAfter(ctx context.Context, create
 	return items, nil
 }
 
-const insertParameterSchema = `-- name: InsertParameterSchema :one
-INSERT INTO
-	parameter_schemas (
-		id,
-		created_at,
-		job_id,
-		"name",
-		description,
-		default_source_scheme,
-		default_source_value,
-		allow_override_source,
-		default_destination_scheme,
-		allow_override_destination,
-		default_refresh,
-		redisplay_value,
-		validation_error,
-		validation_condition,
-		validation_type_system,
-		validation_value_type,
-		index
-	)
-VALUES
-	(
-		$1,
-		$2,
-		$3,
-		$4,
-		$5,
-		$6,
-		$7,
-		$8,
-		$9,
-		$10,
-		$11,
-		$12,
-		$13,
-		$14,
-		$15,
-		$16,
-		$17
+const getGroupsWithRoles = `-- name: GetGroupsWithRoles :many
+SELECT
+	id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+FROM
+	groups
+WHERE
+	cardinality(roles) > 0
+AND
+	deleted_at IS NULL
+ORDER BY
+	organization_id NULLS FIRST, name
+`
+
+// Returns the groups that grant roles to their members, across all
+// organizations.
+func (q *sqlQuerier) GetGroupsWithRoles(ctx context.Context) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupsWithRoles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
+			&i.Description,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserEffectiveGroups = `-- name: GetUserEffectiveGroups :many
+-- Returns the groups whose policies apply to the user in the organization:
+-- the groups the user is an unexpired member of and the groups above them,
+-- limited to the organization's groups and deployment-wide groups, and the
+-- organization's "Everyone" group unless the user has been excluded from it.
+WITH RECURSIVE user_groups AS (
+	SELECT
+		group_members.group_id AS id
+	FROM
+		group_members
+	JOIN
+		groups
+	ON
+		groups.id = group_members.group_id
+	WHERE
+		group_members.user_id = $1 :: uuid
+	AND
+		(group_members.expires_at IS NULL OR group_members.expires_at > NOW())
+	AND
+		groups.deleted_at IS NULL
+	UNION
+	SELECT
+		parents.id
+	FROM
+		groups
+	JOIN
+		user_groups
+	ON
+		groups.id = user_groups.id
+	JOIN
+		groups parents
+	ON
+		parents.id = groups.parent_id
+	WHERE
+		parents.deleted_at IS NULL
+)
+SELECT
+	groups.id, groups.name, groups.organization_id, groups.parent_id, groups.display_name, groups.avatar_url, groups.description, groups.source, groups.deleted_at, groups.metadata, groups.autostop_schedule, groups.max_ttl, groups.quota_allowance, groups.roles
+FROM
+	groups
+WHERE
+	groups.deleted_at IS NULL
+AND (
+	(
+		groups.id IN (SELECT id FROM user_groups)
+		AND (groups.organization_id IS NULL OR groups.organization_id = $2 :: uuid)
+	)
+	OR (
+		groups.id = $2 :: uuid
+		AND EXISTS (
+			SELECT
+				1
+			FROM
+				organization_members
+			WHERE
+				organization_id = $2 :: uuid
+			AND
+				user_id = $1 :: uuid
+		)
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				everyone_group_exclusions
+			WHERE
+				organization_id = $2 :: uuid
+			AND
+				user_id = $1 :: uuid
+		)
+	)
+)
+ORDER BY
+	groups.name ASC
+`
+
+type GetUserEffectiveGroupsParams struct {
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+}
+
+// Returns the groups whose policies apply to the user in the organization:
+// the groups the user is an unexpired member of and the groups above them,
+// limited to the organization's groups and deployment-wide groups, and the
+// organization's "Everyone" group unless the user has been excluded from it.
+func (q *sqlQuerier) GetUserEffectiveGroups(ctx context.Context, arg GetUserEffectiveGroupsParams) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getUserEffectiveGroups, arg.UserID, arg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
+			&i.Description,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserGroups = `-- name: GetUserGroups :many
+SELECT
+	groups.id, groups.name, groups.organization_id, groups.parent_id, groups.display_name, groups.avatar_url, groups.description, groups.source, groups.deleted_at, groups.metadata, groups.autostop_schedule, groups.max_ttl, groups.quota_allowance, groups.roles
+FROM
+	groups
+JOIN
+	group_members
+ON
+	groups.id = group_members.group_id
+WHERE
+	group_members.user_id = $1
+AND
+	groups.deleted_at IS NULL
+ORDER BY
+	groups.name ASC
+`
+
+func (q *sqlQuerier) GetUserGroups(ctx context.Context, userID uuid.UUID) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getUserGroups, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
+			&i.Description,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAllUsersGroup = `-- name: InsertAllUsersGroup :one
+INSERT INTO groups (
+	id,
+	name,
+	organization_id
+)
+VALUES
+	( $1, 'Everyone', $1) RETURNING id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+`
+
+// We use the organization_id as the id
+// for simplicity since all users is
+// every member of the org.
+func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertAllUsersGroup, organizationID)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.ParentID,
+		&i.DisplayName,
+		&i.AvatarURL,
+		&i.Description,
+		&i.Source,
+		&i.DeletedAt,
+		&i.Metadata,
+		&i.AutostopSchedule,
+		&i.MaxTtl,
+		&i.QuotaAllowance,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const insertGroup = `-- name: InsertGroup :one
+INSERT INTO groups (
+	id,
+	name,
+	organization_id,
+	parent_id,
+	display_name,
+	avatar_url,
+	description,
+	source
+)
+VALUES
+	( $1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+`
+
+type InsertGroupParams struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	Name           string        `db:"name" json:"name"`
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	ParentID       uuid.NullUUID `db:"parent_id" json:"parent_id"`
+	DisplayName    string        `db:"display_name" json:"display_name"`
+	AvatarURL      string        `db:"avatar_url" json:"avatar_url"`
+	Description    string        `db:"description" json:"description"`
+	Source         GroupSource   `db:"source" json:"source"`
+}
+
+func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertGroup,
+		arg.ID,
+		arg.Name,
+		arg.OrganizationID,
+		arg.ParentID,
+		arg.DisplayName,
+		arg.AvatarURL,
+		arg.Description,
+		arg.Source,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.ParentID,
+		&i.DisplayName,
+		&i.AvatarURL,
+		&i.Description,
+		&i.Source,
+		&i.DeletedAt,
+		&i.Metadata,
+		&i.AutostopSchedule,
+		&i.MaxTtl,
+		&i.QuotaAllowance,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const insertGroupMember = `-- name: InsertGroupMember :exec
+INSERT INTO group_members (
+	user_id,
+	group_id,
+	expires_at
+)
+VALUES ( $1, $2, $3)
+`
+
+type InsertGroupMemberParams struct {
+	UserID    uuid.UUID    `db:"user_id" json:"user_id"`
+	GroupID   uuid.UUID    `db:"group_id" json:"group_id"`
+	ExpiresAt sql.NullTime `db:"expires_at" json:"expires_at"`
+}
+
+func (q *sqlQuerier) InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error {
+	_, err := q.db.ExecContext(ctx, insertGroupMember, arg.UserID, arg.GroupID, arg.ExpiresAt)
+	return err
+}
+
+const insertGroupMembers = `-- name: InsertGroupMembers :many
+INSERT INTO group_members (
+	user_id,
+	group_id
+)
+SELECT
+	unnest($1 :: uuid [ ]),
+	$2
+ON CONFLICT DO NOTHING
+RETURNING user_id
+`
+
+type InsertGroupMembersParams struct {
+	UserIds []uuid.UUID `db:"user_ids" json:"user_ids"`
+	GroupID uuid.UUID   `db:"group_id" json:"group_id"`
+}
+
+func (q *sqlQuerier) InsertGroupMembers(ctx context.Context, arg InsertGroupMembersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, insertGroupMembers, pq.Array(arg.UserIds), arg.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchGroups = `-- name: SearchGroups :many
+SELECT
+	id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+FROM
+	groups
+WHERE
+	deleted_at IS NULL
+	AND id IS DISTINCT FROM organization_id
+	AND (
+		name ILIKE concat('%', $1 :: text, '%')
+		OR display_name ILIKE concat('%', $1 :: text, '%')
+	)
+ORDER BY
+	name, id
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($2 :: int, 0)
+`
+
+type SearchGroupsParams struct {
+	Search   string `db:"search" json:"search"`
+	LimitOpt int32  `db:"limit_opt" json:"limit_opt"`
+}
+
+// Returns the groups across all organizations with a name or display name
+// containing the search term. The "Everyone" groups aren't included.
+func (q *sqlQuerier) SearchGroups(ctx context.Context, arg SearchGroupsParams) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, searchGroups, arg.Search, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OrganizationID,
+			&i.ParentID,
+			&i.DisplayName,
+			&i.AvatarURL,
+			&i.Description,
+			&i.Source,
+			&i.DeletedAt,
+			&i.Metadata,
+			&i.AutostopSchedule,
+			&i.MaxTtl,
+			&i.QuotaAllowance,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateGroupByID = `-- name: UpdateGroupByID :one
+UPDATE
+	groups
+SET
+	name = $1,
+	parent_id = $2,
+	display_name = $3,
+	avatar_url = $4,
+	description = $5,
+	metadata = $6,
+	autostop_schedule = $7,
+	max_ttl = $8,
+	quota_allowance = $9,
+	roles = $10
+WHERE
+	id = $11
+RETURNING id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+`
+
+type UpdateGroupByIDParams struct {
+	Name             string          `db:"name" json:"name"`
+	ParentID         uuid.NullUUID   `db:"parent_id" json:"parent_id"`
+	DisplayName      string          `db:"display_name" json:"display_name"`
+	AvatarURL        string          `db:"avatar_url" json:"avatar_url"`
+	Description      string          `db:"description" json:"description"`
+	Metadata         json.RawMessage `db:"metadata" json:"metadata"`
+	AutostopSchedule string          `db:"autostop_schedule" json:"autostop_schedule"`
+	MaxTtl           int64           `db:"max_ttl" json:"max_ttl"`
+	QuotaAllowance   int32           `db:"quota_allowance" json:"quota_allowance"`
+	Roles            []string        `db:"roles" json:"roles"`
+	ID               uuid.UUID       `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, updateGroupByID,
+		arg.Name,
+		arg.ParentID,
+		arg.DisplayName,
+		arg.AvatarURL,
+		arg.Description,
+		arg.Metadata,
+		arg.AutostopSchedule,
+		arg.MaxTtl,
+		arg.QuotaAllowance,
+		pq.Array(arg.Roles),
+		arg.ID,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.ParentID,
+		&i.DisplayName,
+		&i.AvatarURL,
+		&i.Description,
+		&i.Source,
+		&i.DeletedAt,
+		&i.Metadata,
+		&i.AutostopSchedule,
+		&i.MaxTtl,
+		&i.QuotaAllowance,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const updateGroupDeletedAtByID = `-- name: UpdateGroupDeletedAtByID :one
+UPDATE
+	groups
+SET
+	deleted_at = $1
+WHERE
+	id = $2
+RETURNING id, name, organization_id, parent_id, display_name, avatar_url, description, source, deleted_at, metadata, autostop_schedule, max_ttl, quota_allowance, roles
+`
+
+type UpdateGroupDeletedAtByIDParams struct {
+	DeletedAt sql.NullTime `db:"deleted_at" json:"deleted_at"`
+	ID        uuid.UUID    `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateGroupDeletedAtByID(ctx context.Context, arg UpdateGroupDeletedAtByIDParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, updateGroupDeletedAtByID, arg.DeletedAt, arg.ID)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.ParentID,
+		&i.DisplayName,
+		&i.AvatarURL,
+		&i.Description,
+		&i.Source,
+		&i.DeletedAt,
+		&i.Metadata,
+		&i.AutostopSchedule,
+		&i.MaxTtl,
+		&i.QuotaAllowance,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const updateGroupMemberRoles = `-- name: UpdateGroupMemberRoles :one
+UPDATE
+	group_members
+SET
+	-- Remove all duplicates from the roles.
+	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+WHERE
+	user_id = $2
+	AND group_id = $3
+RETURNING user_id, group_id, expires_at, roles
+`
+
+type UpdateGroupMemberRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	GroupID      uuid.UUID `db:"group_id" json:"group_id"`
+}
+
+func (q *sqlQuerier) UpdateGroupMemberRoles(ctx context.Context, arg UpdateGroupMemberRolesParams) (GroupMember, error) {
+	row := q.db.QueryRowContext(ctx, updateGroupMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.GroupID)
+	var i GroupMember
+	err := row.Scan(
+		&i.UserID,
+		&i.GroupID,
+		&i.ExpiresAt,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const deleteGroupWebhookByID = `-- name: DeleteGroupWebhookByID :exec
+DELETE FROM
+	group_webhooks
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteGroupWebhookByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupWebhookByID, id)
+	return err
+}
+
+const getGroupWebhookByID = `-- name: GetGroupWebhookByID :one
+SELECT
+	id, organization_id, url, secret, created_at
+FROM
+	group_webhooks
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetGroupWebhookByID(ctx context.Context, id uuid.UUID) (GroupWebhook, error) {
+	row := q.db.QueryRowContext(ctx, getGroupWebhookByID, id)
+	var i GroupWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Url,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGroupWebhooksByOrganizationID = `-- name: GetGroupWebhooksByOrganizationID :many
+SELECT
+	id, organization_id, url, secret, created_at
+FROM
+	group_webhooks
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetGroupWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]GroupWebhook, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupWebhooksByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GroupWebhook
+	for rows.Next() {
+		var i GroupWebhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Url,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertGroupWebhook = `-- name: InsertGroupWebhook :one
+INSERT INTO group_webhooks (
+	id,
+	organization_id,
+	url,
+	secret,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING id, organization_id, url, secret, created_at
+`
+
+type InsertGroupWebhookParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Url            string    `db:"url" json:"url"`
+	Secret         string    `db:"secret" json:"secret"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertGroupWebhook(ctx context.Context, arg InsertGroupWebhookParams) (GroupWebhook, error) {
+	row := q.db.QueryRowContext(ctx, insertGroupWebhook,
+		arg.ID,
+		arg.OrganizationID,
+		arg.Url,
+		arg.Secret,
+		arg.CreatedAt,
+	)
+	var i GroupWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Url,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteLicense = `-- name: DeleteLicense :one
+DELETE
+FROM licenses
+WHERE id = $1
+RETURNING id
+`
+
+func (q *sqlQuerier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, deleteLicense, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getLicenses = `-- name: GetLicenses :many
+SELECT id, uploaded_at, jwt, exp
+FROM licenses
+ORDER BY (id)
+`
+
+func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
+	rows, err := q.db.QueryContext(ctx, getLicenses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []License
+	for rows.Next() {
+		var i License
+		if err := rows.Scan(
+			&i.ID,
+			&i.UploadedAt,
+			&i.JWT,
+			&i.Exp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnexpiredLicenses = `-- name: GetUnexpiredLicenses :many
+SELECT id, uploaded_at, jwt, exp
+FROM licenses
+WHERE exp > NOW()
+ORDER BY (id)
+`
+
+func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error) {
+	rows, err := q.db.QueryContext(ctx, getUnexpiredLicenses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []License
+	for rows.Next() {
+		var i License
+		if err := rows.Scan(
+			&i.ID,
+			&i.UploadedAt,
+			&i.JWT,
+			&i.Exp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertLicense = `-- name: InsertLicense :one
+INSERT INTO
+	licenses (
+	uploaded_at,
+	jwt,
+	exp
+)
+VALUES
+	($1, $2, $3) RETURNING id, uploaded_at, jwt, exp
+`
+
+type InsertLicenseParams struct {
+	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
+	JWT        string    `db:"jwt" json:"jwt"`
+	Exp        time.Time `db:"exp" json:"exp"`
+}
+
+func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error) {
+	row := q.db.QueryRowContext(ctx, insertLicense, arg.UploadedAt, arg.JWT, arg.Exp)
+	var i License
+	err := row.Scan(
+		&i.ID,
+		&i.UploadedAt,
+		&i.JWT,
+		&i.Exp,
+	)
+	return i, err
+}
+
+const acquireLock = `-- name: AcquireLock :exec
+SELECT pg_advisory_xact_lock($1)
+`
+
+// Blocks until the lock is acquired. The lock is released when the
+// transaction ends, so this must be called in a transaction.
+func (q *sqlQuerier) AcquireLock(ctx context.Context, pgAdvisoryXactLock int64) error {
+	_, err := q.db.ExecContext(ctx, acquireLock, pgAdvisoryXactLock)
+	return err
+}
+
+const deleteOAuth2ProviderAppAPIKeysByAppID = `-- name: DeleteOAuth2ProviderAppAPIKeysByAppID :exec
+DELETE FROM
+	api_keys
+WHERE
+	id IN (
+		SELECT
+			api_key_id
+		FROM
+			oauth2_provider_app_tokens
+		WHERE
+			app_id = $1
+	)
+`
+
+func (q *sqlQuerier) DeleteOAuth2ProviderAppAPIKeysByAppID(ctx context.Context, appID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppAPIKeysByAppID, appID)
+	return err
+}
+
+const deleteOAuth2ProviderAppByID = `-- name: DeleteOAuth2ProviderAppByID :exec
+DELETE FROM
+	oauth2_provider_apps
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppByID, id)
+	return err
+}
+
+const deleteOAuth2ProviderAppCodeByHashedSecret = `-- name: DeleteOAuth2ProviderAppCodeByHashedSecret :one
+DELETE FROM
+	oauth2_provider_app_codes
+WHERE
+	hashed_secret = $1
+RETURNING id, created_at, expires_at, hashed_secret, app_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method
+`
+
+// Codes can only be exchanged once, so they're deleted when they're used.
+func (q *sqlQuerier) DeleteOAuth2ProviderAppCodeByHashedSecret(ctx context.Context, hashedSecret []byte) (OAuth2ProviderAppCode, error) {
+	row := q.db.QueryRowContext(ctx, deleteOAuth2ProviderAppCodeByHashedSecret, hashedSecret)
+	var i OAuth2ProviderAppCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.HashedSecret,
+		&i.AppID,
+		&i.UserID,
+		&i.RedirectURI,
+		&i.Scope,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppByID = `-- name: GetOAuth2ProviderAppByID :one
+SELECT
+	id, created_at, updated_at, name, icon, callback_url, hashed_secret
+FROM
+	oauth2_provider_apps
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppByID, id)
+	var i OAuth2ProviderApp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Icon,
+		&i.CallbackURL,
+		&i.HashedSecret,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppTokenByAPIKeyID = `-- name: GetOAuth2ProviderAppTokenByAPIKeyID :one
+SELECT
+	api_key_id, app_id, created_at
+FROM
+	oauth2_provider_app_tokens
+WHERE
+	api_key_id = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppTokenByAPIKeyID(ctx context.Context, apiKeyID string) (OAuth2ProviderAppToken, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppTokenByAPIKeyID, apiKeyID)
+	var i OAuth2ProviderAppToken
+	err := row.Scan(
+		&i.APIKeyID,
+		&i.AppID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderApps = `-- name: GetOAuth2ProviderApps :many
+SELECT
+	id, created_at, updated_at, name, icon, callback_url, hashed_secret
+FROM
+	oauth2_provider_apps
+ORDER BY
+	name ASC
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2ProviderApp, error) {
+	rows, err := q.db.QueryContext(ctx, getOAuth2ProviderApps)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OAuth2ProviderApp
+	for rows.Next() {
+		var i OAuth2ProviderApp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Icon,
+			&i.CallbackURL,
+			&i.HashedSecret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOAuth2ProviderApp = `-- name: InsertOAuth2ProviderApp :one
+INSERT INTO
+	oauth2_provider_apps (id, created_at, updated_at, name, icon, callback_url, hashed_secret)
+VALUES
+	($1, $2, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, name, icon, callback_url, hashed_secret
+`
+
+type InsertOAuth2ProviderAppParams struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	Name         string    `db:"name" json:"name"`
+	Icon         string    `db:"icon" json:"icon"`
+	CallbackURL  string    `db:"callback_url" json:"callback_url"`
+	HashedSecret []byte    `db:"hashed_secret" json:"hashed_secret"`
+}
+
+func (q *sqlQuerier) InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAuth2ProviderAppParams) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderApp,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Name,
+		arg.Icon,
+		arg.CallbackURL,
+		arg.HashedSecret,
+	)
+	var i OAuth2ProviderApp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Icon,
+		&i.CallbackURL,
+		&i.HashedSecret,
+	)
+	return i, err
+}
+
+const insertOAuth2ProviderAppCode = `-- name: InsertOAuth2ProviderAppCode :one
+INSERT INTO
+	oauth2_provider_app_codes (id, created_at, expires_at, hashed_secret, app_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, created_at, expires_at, hashed_secret, app_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method
+`
+
+type InsertOAuth2ProviderAppCodeParams struct {
+	ID                  uuid.UUID `db:"id" json:"id"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt           time.Time `db:"expires_at" json:"expires_at"`
+	HashedSecret        []byte    `db:"hashed_secret" json:"hashed_secret"`
+	AppID               uuid.UUID `db:"app_id" json:"app_id"`
+	UserID              uuid.UUID `db:"user_id" json:"user_id"`
+	RedirectURI         string    `db:"redirect_uri" json:"redirect_uri"`
+	Scope               string    `db:"scope" json:"scope"`
+	CodeChallenge       string    `db:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string    `db:"code_challenge_method" json:"code_challenge_method"`
+}
+
+func (q *sqlQuerier) InsertOAuth2ProviderAppCode(ctx context.Context, arg InsertOAuth2ProviderAppCodeParams) (OAuth2ProviderAppCode, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderAppCode,
+		arg.ID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.HashedSecret,
+		arg.AppID,
+		arg.UserID,
+		arg.RedirectURI,
+		arg.Scope,
+		arg.CodeChallenge,
+		arg.CodeChallengeMethod,
+	)
+	var i OAuth2ProviderAppCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.HashedSecret,
+		&i.AppID,
+		&i.UserID,
+		&i.RedirectURI,
+		&i.Scope,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+	)
+	return i, err
+}
+
+const insertOAuth2ProviderAppToken = `-- name: InsertOAuth2ProviderAppToken :one
+INSERT INTO
+	oauth2_provider_app_tokens (api_key_id, app_id, created_at)
+VALUES
+	($1, $2, $3)
+RETURNING api_key_id, app_id, created_at
+`
+
+type InsertOAuth2ProviderAppTokenParams struct {
+	APIKeyID  string    `db:"api_key_id" json:"api_key_id"`
+	AppID     uuid.UUID `db:"app_id" json:"app_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertOAuth2ProviderAppToken(ctx context.Context, arg InsertOAuth2ProviderAppTokenParams) (OAuth2ProviderAppToken, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderAppToken,
+		arg.APIKeyID,
+		arg.AppID,
+		arg.CreatedAt,
+	)
+	var i OAuth2ProviderAppToken
+	err := row.Scan(
+		&i.APIKeyID,
+		&i.AppID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateOAuth2ProviderAppByID = `-- name: UpdateOAuth2ProviderAppByID :one
+UPDATE
+	oauth2_provider_apps
+SET
+	updated_at = $2,
+	name = $3,
+	icon = $4,
+	callback_url = $5
+WHERE
+	id = $1
+RETURNING id, created_at, updated_at, name, icon, callback_url, hashed_secret
+`
+
+type UpdateOAuth2ProviderAppByIDParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Name        string    `db:"name" json:"name"`
+	Icon        string    `db:"icon" json:"icon"`
+	CallbackURL string    `db:"callback_url" json:"callback_url"`
+}
+
+func (q *sqlQuerier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg UpdateOAuth2ProviderAppByIDParams) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2ProviderAppByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Icon,
+		arg.CallbackURL,
+	)
+	var i OAuth2ProviderApp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Icon,
+		&i.CallbackURL,
+		&i.HashedSecret,
+	)
+	return i, err
+}
+
+const updateOAuth2ProviderAppSecretByID = `-- name: UpdateOAuth2ProviderAppSecretByID :one
+UPDATE
+	oauth2_provider_apps
+SET
+	updated_at = $2,
+	hashed_secret = $3
+WHERE
+	id = $1
+RETURNING id, created_at, updated_at, name, icon, callback_url, hashed_secret
+`
+
+type UpdateOAuth2ProviderAppSecretByIDParams struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	HashedSecret []byte    `db:"hashed_secret" json:"hashed_secret"`
+}
+
+func (q *sqlQuerier) UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg UpdateOAuth2ProviderAppSecretByIDParams) (OAuth2ProviderApp, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2ProviderAppSecretByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.HashedSecret,
+	)
+	var i OAuth2ProviderApp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Icon,
+		&i.CallbackURL,
+		&i.HashedSecret,
+	)
+	return i, err
+}
+
+const getOperationByID = `-- name: GetOperationByID :one
+SELECT
+	id, type, status, initiator_id, organization_id, resource_id, progress_completed, progress_total, error, created_at, updated_at, completed_at
+FROM
+	operations
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetOperationByID(ctx context.Context, id uuid.UUID) (Operation, error) {
+	row := q.db.QueryRowContext(ctx, getOperationByID, id)
+	var i Operation
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Status,
+		&i.InitiatorID,
+		&i.OrganizationID,
+		&i.ResourceID,
+		&i.ProgressCompleted,
+		&i.ProgressTotal,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getOperationsByTypeAndStatus = `-- name: GetOperationsByTypeAndStatus :many
+SELECT
+	id, type, status, initiator_id, organization_id, resource_id, progress_completed, progress_total, error, created_at, updated_at, completed_at
+FROM
+	operations
+WHERE
+	type = $1
+	AND status = $2
+ORDER BY
+	created_at ASC
+`
+
+type GetOperationsByTypeAndStatusParams struct {
+	Type   string          `db:"type" json:"type"`
+	Status OperationStatus `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) GetOperationsByTypeAndStatus(ctx context.Context, arg GetOperationsByTypeAndStatusParams) ([]Operation, error) {
+	rows, err := q.db.QueryContext(ctx, getOperationsByTypeAndStatus, arg.Type, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Operation
+	for rows.Next() {
+		var i Operation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Status,
+			&i.InitiatorID,
+			&i.OrganizationID,
+			&i.ResourceID,
+			&i.ProgressCompleted,
+			&i.ProgressTotal,
+			&i.Error,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOperation = `-- name: InsertOperation :one
+INSERT INTO
+	operations (id, type, status, initiator_id, organization_id, resource_id, created_at, updated_at)
+VALUES
+	($1, $2, 'running', $3, $4, $5, $6, $6)
+RETURNING id, type, status, initiator_id, organization_id, resource_id, progress_completed, progress_total, error, created_at, updated_at, completed_at
+`
+
+type InsertOperationParams struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	Type           string        `db:"type" json:"type"`
+	InitiatorID    uuid.UUID     `db:"initiator_id" json:"initiator_id"`
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	ResourceID     uuid.UUID     `db:"resource_id" json:"resource_id"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertOperation(ctx context.Context, arg InsertOperationParams) (Operation, error) {
+	row := q.db.QueryRowContext(ctx, insertOperation,
+		arg.ID,
+		arg.Type,
+		arg.InitiatorID,
+		arg.OrganizationID,
+		arg.ResourceID,
+		arg.CreatedAt,
+	)
+	var i Operation
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Status,
+		&i.InitiatorID,
+		&i.OrganizationID,
+		&i.ResourceID,
+		&i.ProgressCompleted,
+		&i.ProgressTotal,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const updateOperationByID = `-- name: UpdateOperationByID :one
+UPDATE
+	operations
+SET
+	status = $2,
+	progress_completed = $3,
+	progress_total = $4,
+	error = $5,
+	updated_at = $6,
+	completed_at = $7
+WHERE
+	id = $1
+RETURNING id, type, status, initiator_id, organization_id, resource_id, progress_completed, progress_total, error, created_at, updated_at, completed_at
+`
+
+type UpdateOperationByIDParams struct {
+	ID                uuid.UUID       `db:"id" json:"id"`
+	Status            OperationStatus `db:"status" json:"status"`
+	ProgressCompleted int64           `db:"progress_completed" json:"progress_completed"`
+	ProgressTotal     int64           `db:"progress_total" json:"progress_total"`
+	Error             string          `db:"error" json:"error"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+	CompletedAt       sql.NullTime    `db:"completed_at" json:"completed_at"`
+}
+
+func (q *sqlQuerier) UpdateOperationByID(ctx context.Context, arg UpdateOperationByIDParams) (Operation, error) {
+	row := q.db.QueryRowContext(ctx, updateOperationByID,
+		arg.ID,
+		arg.Status,
+		arg.ProgressCompleted,
+		arg.ProgressTotal,
+		arg.Error,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+	)
+	var i Operation
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Status,
+		&i.InitiatorID,
+		&i.OrganizationID,
+		&i.ResourceID,
+		&i.ProgressCompleted,
+		&i.ProgressTotal,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const deleteOrganizationAliasByName = `-- name: DeleteOrganizationAliasByName :exec
+DELETE FROM
+	organization_aliases
+WHERE
+	LOWER("name") = LOWER($1)
+`
+
+func (q *sqlQuerier) DeleteOrganizationAliasByName(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationAliasByName, name)
+	return err
+}
+
+const getOrganizationAliasByName = `-- name: GetOrganizationAliasByName :one
+SELECT
+	name, organization_id, created_at
+FROM
+	organization_aliases
+WHERE
+	LOWER("name") = LOWER($1)
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetOrganizationAliasByName(ctx context.Context, name string) (OrganizationAlias, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationAliasByName, name)
+	var i OrganizationAlias
+	err := row.Scan(
+		&i.Name,
+		&i.OrganizationID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertOrganizationAlias = `-- name: InsertOrganizationAlias :one
+INSERT INTO
+	organization_aliases (name, organization_id, created_at)
+VALUES
+	($1, $2, $3) RETURNING name, organization_id, created_at
+`
+
+type InsertOrganizationAliasParams struct {
+	Name           string    `db:"name" json:"name"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertOrganizationAlias(ctx context.Context, arg InsertOrganizationAliasParams) (OrganizationAlias, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationAlias,
+		arg.Name,
+		arg.OrganizationID,
+		arg.CreatedAt,
+	)
+	var i OrganizationAlias
+	err := row.Scan(
+		&i.Name,
+		&i.OrganizationID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const acquireOrganizationDeletions = `-- name: AcquireOrganizationDeletions :many
+UPDATE
+	organization_deletions
+SET
+	worker_id = $1 :: uuid,
+	heartbeat_at = $2 :: timestamptz
+WHERE
+	status = 'running'
+	AND (
+		heartbeat_at IS NULL
+		OR heartbeat_at < $3 :: timestamptz
+	)
+RETURNING organization_id, initiator_id, status, error, workspaces_total, workspaces_deleted, templates_total, templates_deleted, members_total, members_deleted, created_at, updated_at, completed_at, operation_id, worker_id, heartbeat_at
+`
+
+type AcquireOrganizationDeletionsParams struct {
+	WorkerID    uuid.UUID `db:"worker_id" json:"worker_id"`
+	Now         time.Time `db:"now" json:"now"`
+	StaleBefore time.Time `db:"stale_before" json:"stale_before"`
+}
+
+// Claims the running deletions that no replica works on, because their
+// replica stopped recording heartbeats before @stale_before. Concurrent
+// claims of the same deletion only succeed once.
+func (q *sqlQuerier) AcquireOrganizationDeletions(ctx context.Context, arg AcquireOrganizationDeletionsParams) ([]OrganizationDeletion, error) {
+	rows, err := q.db.QueryContext(ctx, acquireOrganizationDeletions, arg.WorkerID, arg.Now, arg.StaleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationDeletion
+	for rows.Next() {
+		var i OrganizationDeletion
+		if err := rows.Scan(
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Status,
+			&i.Error,
+			&i.WorkspacesTotal,
+			&i.WorkspacesDeleted,
+			&i.TemplatesTotal,
+			&i.TemplatesDeleted,
+			&i.MembersTotal,
+			&i.MembersDeleted,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.OperationID,
+			&i.WorkerID,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationDeletionByOrganizationID = `-- name: GetOrganizationDeletionByOrganizationID :one
+SELECT
+	organization_id, initiator_id, status, error, workspaces_total, workspaces_deleted, templates_total, templates_deleted, members_total, members_deleted, created_at, updated_at, completed_at, operation_id, worker_id, heartbeat_at
+FROM
+	organization_deletions
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationDeletionByOrganizationID(ctx context.Context, organizationID uuid.UUID) (OrganizationDeletion, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationDeletionByOrganizationID, organizationID)
+	var i OrganizationDeletion
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Status,
+		&i.Error,
+		&i.WorkspacesTotal,
+		&i.WorkspacesDeleted,
+		&i.TemplatesTotal,
+		&i.TemplatesDeleted,
+		&i.MembersTotal,
+		&i.MembersDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.OperationID,
+		&i.WorkerID,
+		&i.HeartbeatAt,
+	)
+	return i, err
+}
+
+const insertOrganizationDeletion = `-- name: InsertOrganizationDeletion :one
+INSERT INTO
+	organization_deletions (organization_id, initiator_id, status, created_at, updated_at, operation_id, worker_id, heartbeat_at)
+VALUES
+	($1, $2, 'running', $3, $3, $4, $5, $3)
+ON CONFLICT (organization_id) DO UPDATE SET
+	initiator_id = $2,
+	status = 'running',
+	error = '',
+	updated_at = $3,
+	completed_at = NULL,
+	operation_id = $4,
+	worker_id = $5,
+	heartbeat_at = $3
+WHERE
+	organization_deletions.status != 'running'
+RETURNING organization_id, initiator_id, status, error, workspaces_total, workspaces_deleted, templates_total, templates_deleted, members_total, members_deleted, created_at, updated_at, completed_at, operation_id, worker_id, heartbeat_at
+`
+
+type InsertOrganizationDeletionParams struct {
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	InitiatorID    uuid.UUID     `db:"initiator_id" json:"initiator_id"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	OperationID    uuid.NullUUID `db:"operation_id" json:"operation_id"`
+	WorkerID       uuid.NullUUID `db:"worker_id" json:"worker_id"`
+}
+
+// Restarts deletions that failed. Returns no rows if the organization is
+// already being deleted. The deletion is claimed by the worker.
+func (q *sqlQuerier) InsertOrganizationDeletion(ctx context.Context, arg InsertOrganizationDeletionParams) (OrganizationDeletion, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationDeletion,
+		arg.OrganizationID,
+		arg.InitiatorID,
+		arg.CreatedAt,
+		arg.OperationID,
+		arg.WorkerID,
+	)
+	var i OrganizationDeletion
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Status,
+		&i.Error,
+		&i.WorkspacesTotal,
+		&i.WorkspacesDeleted,
+		&i.TemplatesTotal,
+		&i.TemplatesDeleted,
+		&i.MembersTotal,
+		&i.MembersDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.OperationID,
+		&i.WorkerID,
+		&i.HeartbeatAt,
+	)
+	return i, err
+}
+
+const updateOrganizationDeletionByOrganizationID = `-- name: UpdateOrganizationDeletionByOrganizationID :one
+UPDATE
+	organization_deletions
+SET
+	status = $3,
+	error = $4,
+	workspaces_total = $5,
+	workspaces_deleted = $6,
+	templates_total = $7,
+	templates_deleted = $8,
+	members_total = $9,
+	members_deleted = $10,
+	updated_at = $11,
+	completed_at = $12
+WHERE
+	organization_id = $1
+	AND worker_id = $2
+RETURNING organization_id, initiator_id, status, error, workspaces_total, workspaces_deleted, templates_total, templates_deleted, members_total, members_deleted, created_at, updated_at, completed_at, operation_id, worker_id, heartbeat_at
+`
+
+type UpdateOrganizationDeletionByOrganizationIDParams struct {
+	OrganizationID    uuid.UUID                  `db:"organization_id" json:"organization_id"`
+	WorkerID          uuid.NullUUID              `db:"worker_id" json:"worker_id"`
+	Status            OrganizationDeletionStatus `db:"status" json:"status"`
+	Error             string                     `db:"error" json:"error"`
+	WorkspacesTotal   int64                      `db:"workspaces_total" json:"workspaces_total"`
+	WorkspacesDeleted int64                      `db:"workspaces_deleted" json:"workspaces_deleted"`
+	TemplatesTotal    int64                      `db:"templates_total" json:"templates_total"`
+	TemplatesDeleted  int64                      `db:"templates_deleted" json:"templates_deleted"`
+	MembersTotal      int64                      `db:"members_total" json:"members_total"`
+	MembersDeleted    int64                      `db:"members_deleted" json:"members_deleted"`
+	UpdatedAt         time.Time                  `db:"updated_at" json:"updated_at"`
+	CompletedAt       sql.NullTime               `db:"completed_at" json:"completed_at"`
+}
+
+// Returns no rows if the deletion was claimed by another worker.
+func (q *sqlQuerier) UpdateOrganizationDeletionByOrganizationID(ctx context.Context, arg UpdateOrganizationDeletionByOrganizationIDParams) (OrganizationDeletion, error) {
+	row := q.db.QueryRowContext(ctx, updateOrganizationDeletionByOrganizationID,
+		arg.OrganizationID,
+		arg.WorkerID,
+		arg.Status,
+		arg.Error,
+		arg.WorkspacesTotal,
+		arg.WorkspacesDeleted,
+		arg.TemplatesTotal,
+		arg.TemplatesDeleted,
+		arg.MembersTotal,
+		arg.MembersDeleted,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+	)
+	var i OrganizationDeletion
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Status,
+		&i.Error,
+		&i.WorkspacesTotal,
+		&i.WorkspacesDeleted,
+		&i.TemplatesTotal,
+		&i.TemplatesDeleted,
+		&i.MembersTotal,
+		&i.MembersDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.OperationID,
+		&i.WorkerID,
+		&i.HeartbeatAt,
+	)
+	return i, err
+}
+
+const updateOrganizationDeletionHeartbeat = `-- name: UpdateOrganizationDeletionHeartbeat :one
+UPDATE
+	organization_deletions
+SET
+	heartbeat_at = $3
+WHERE
+	organization_id = $1
+	AND worker_id = $2
+RETURNING organization_id, initiator_id, status, error, workspaces_total, workspaces_deleted, templates_total, templates_deleted, members_total, members_deleted, created_at, updated_at, completed_at, operation_id, worker_id, heartbeat_at
+`
+
+type UpdateOrganizationDeletionHeartbeatParams struct {
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	WorkerID       uuid.NullUUID `db:"worker_id" json:"worker_id"`
+	HeartbeatAt    sql.NullTime  `db:"heartbeat_at" json:"heartbeat_at"`
+}
+
+// Returns no rows if the deletion was claimed by another worker.
+func (q *sqlQuerier) UpdateOrganizationDeletionHeartbeat(ctx context.Context, arg UpdateOrganizationDeletionHeartbeatParams) (OrganizationDeletion, error) {
+	row := q.db.QueryRowContext(ctx, updateOrganizationDeletionHeartbeat, arg.OrganizationID, arg.WorkerID, arg.HeartbeatAt)
+	var i OrganizationDeletion
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Status,
+		&i.Error,
+		&i.WorkspacesTotal,
+		&i.WorkspacesDeleted,
+		&i.TemplatesTotal,
+		&i.TemplatesDeleted,
+		&i.MembersTotal,
+		&i.MembersDeleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.OperationID,
+		&i.WorkerID,
+		&i.HeartbeatAt,
+	)
+	return i, err
+}
+
+const deleteOrganizationInviteByID = `-- name: DeleteOrganizationInviteByID :exec
+DELETE FROM
+	organization_invites
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteOrganizationInviteByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationInviteByID, id)
+	return err
+}
+
+const getOrganizationInviteByHashedToken = `-- name: GetOrganizationInviteByHashedToken :one
+SELECT
+	id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at, max_uses, uses
+FROM
+	organization_invites
+WHERE
+	hashed_token = $1
+`
+
+func (q *sqlQuerier) GetOrganizationInviteByHashedToken(ctx context.Context, hashedToken []byte) (OrganizationInvite, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationInviteByHashedToken, hashedToken)
+	var i OrganizationInvite
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.HashedToken,
+		&i.EmailDomain,
+		pq.Array(&i.Roles),
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.MaxUses,
+		&i.Uses,
+	)
+	return i, err
+}
+
+const getOrganizationInviteByID = `-- name: GetOrganizationInviteByID :one
+SELECT
+	id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at, max_uses, uses
+FROM
+	organization_invites
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationInviteByID(ctx context.Context, id uuid.UUID) (OrganizationInvite, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationInviteByID, id)
+	var i OrganizationInvite
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.HashedToken,
+		&i.EmailDomain,
+		pq.Array(&i.Roles),
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.MaxUses,
+		&i.Uses,
+	)
+	return i, err
+}
+
+const getOrganizationInvitesByOrganizationID = `-- name: GetOrganizationInvitesByOrganizationID :many
+SELECT
+	id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at, max_uses, uses
+FROM
+	organization_invites
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetOrganizationInvitesByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]OrganizationInvite, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationInvitesByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationInvite
+	for rows.Next() {
+		var i OrganizationInvite
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.HashedToken,
+			&i.EmailDomain,
+			pq.Array(&i.Roles),
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.MaxUses,
+			&i.Uses,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOrganizationInvite = `-- name: InsertOrganizationInvite :one
+INSERT INTO
+	organization_invites (id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at, max_uses, uses
+`
+
+type InsertOrganizationInviteParams struct {
+	ID             uuid.UUID    `db:"id" json:"id"`
+	OrganizationID uuid.UUID    `db:"organization_id" json:"organization_id"`
+	HashedToken    []byte       `db:"hashed_token" json:"hashed_token"`
+	EmailDomain    string       `db:"email_domain" json:"email_domain"`
+	Roles          []string     `db:"roles" json:"roles"`
+	CreatedBy      uuid.UUID    `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	ExpiresAt      sql.NullTime `db:"expires_at" json:"expires_at"`
+	MaxUses        int32        `db:"max_uses" json:"max_uses"`
+}
+
+func (q *sqlQuerier) InsertOrganizationInvite(ctx context.Context, arg InsertOrganizationInviteParams) (OrganizationInvite, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationInvite,
+		arg.ID,
+		arg.OrganizationID,
+		arg.HashedToken,
+		arg.EmailDomain,
+		pq.Array(arg.Roles),
+		arg.CreatedBy,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.MaxUses,
+	)
+	var i OrganizationInvite
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.HashedToken,
+		&i.EmailDomain,
+		pq.Array(&i.Roles),
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.MaxUses,
+		&i.Uses,
+	)
+	return i, err
+}
+
+const useOrganizationInvite = `-- name: UseOrganizationInvite :one
+UPDATE
+	organization_invites
+SET
+	uses = uses + 1
+WHERE
+	id = $1
+	AND (max_uses = 0 OR uses < max_uses)
+RETURNING id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at, max_uses, uses
+`
+
+// UseOrganizationInvite counts a use of the invite, and returns no rows if
+// the invite has no uses left.
+func (q *sqlQuerier) UseOrganizationInvite(ctx context.Context, id uuid.UUID) (OrganizationInvite, error) {
+	row := q.db.QueryRowContext(ctx, useOrganizationInvite, id)
+	var i OrganizationInvite
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.HashedToken,
+		&i.EmailDomain,
+		pq.Array(&i.Roles),
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.MaxUses,
+		&i.Uses,
+	)
+	return i, err
+}
+
+const getOrganizationIPAllowlist = `-- name: GetOrganizationIPAllowlist :one
+SELECT
+	organization_id, cidrs, updated_at
+FROM
+	organization_ip_allowlists
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationIPAllowlist(ctx context.Context, organizationID uuid.UUID) (OrganizationIpAllowlist, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationIPAllowlist, organizationID)
+	var i OrganizationIpAllowlist
+	err := row.Scan(
+		&i.OrganizationID,
+		pq.Array(&i.Cidrs),
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertOrganizationIPAllowlist = `-- name: UpsertOrganizationIPAllowlist :one
+INSERT INTO
+	organization_ip_allowlists (
+		organization_id,
+		cidrs,
+		updated_at
+	)
+VALUES
+	($1, $2, $3)
+ON CONFLICT (organization_id) DO UPDATE SET
+	cidrs = $2,
+	updated_at = $3
+RETURNING organization_id, cidrs, updated_at
+`
+
+type UpsertOrganizationIPAllowlistParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Cidrs          []string  `db:"cidrs" json:"cidrs"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationIPAllowlist(ctx context.Context, arg UpsertOrganizationIPAllowlistParams) (OrganizationIpAllowlist, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationIPAllowlist,
+		arg.OrganizationID,
+		pq.Array(arg.Cidrs),
+		arg.UpdatedAt,
+	)
+	var i OrganizationIpAllowlist
+	err := row.Scan(
+		&i.OrganizationID,
+		pq.Array(&i.Cidrs),
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteOrganizationMembersByOrganizationID = `-- name: DeleteOrganizationMembersByOrganizationID :many
+DELETE FROM
+	organization_members
+WHERE
+	organization_id = $1
+	AND user_id IN (
+		SELECT
+			user_id
+		FROM
+			organization_members
+		WHERE
+			organization_id = $1
+		LIMIT
+			$2
+	)
+RETURNING user_id
+`
+
+type DeleteOrganizationMembersByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	LimitOpt       int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+// Removes a batch of members so the organization can be deleted.
+func (q *sqlQuerier) DeleteOrganizationMembersByOrganizationID(ctx context.Context, arg DeleteOrganizationMembersByOrganizationIDParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteOrganizationMembersByOrganizationID, arg.OrganizationID, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
+SELECT
+    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+FROM
+    organization_members
+WHERE
+    user_id = ANY($1 :: uuid [ ])
+GROUP BY
+    user_id
+`
+
+type GetOrganizationIDsByMemberIDsRow struct {
+	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
+	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
+}
+
+func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationIDsByMemberIDsRow
+	for rows.Next() {
+		var i GetOrganizationIDsByMemberIDsRow
+		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
+SELECT
+	user_id, organization_id, created_at, updated_at, roles
+FROM
+	organization_members
+WHERE
+	organization_id = $1
+	AND user_id = $2
+LIMIT
+	1
+`
+
+type GetOrganizationMemberByUserIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationMemberByUserID, arg.OrganizationID, arg.UserID)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.UserID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const getOrganizationMemberCountByOrganizationID = `-- name: GetOrganizationMemberCountByOrganizationID :one
+SELECT
+	COUNT(user_id)
+FROM
+	organization_members
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationMemberCountByOrganizationID(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationMemberCountByOrganizationID, organizationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getOrganizationMembers = `-- name: GetOrganizationMembers :many
+SELECT
+	organization_members.user_id,
+	organization_members.organization_id,
+	organization_members.created_at,
+	organization_members.updated_at,
+	organization_members.roles,
+	users.username,
+	users.email,
+	users.avatar_url
+FROM
+	organization_members
+JOIN
+	users
+ON
+	users.id = organization_members.user_id
+WHERE
+	organization_members.organization_id = $1
+	AND users.deleted = false
+	AND CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		WHEN $2 :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(organization_members.created_at, organization_members.user_id) > (
+				SELECT
+					created_at, user_id
+				FROM
+					organization_members
+				WHERE
+					organization_id = $1
+					AND user_id = $2
+			)
+		)
+		ELSE true
+	END
+	-- Start filters
+	-- Filter by email or username
+	AND CASE
+		WHEN $3 :: text != '' THEN (
+			users.email ILIKE concat('%', $3, '%')
+			OR users.username ILIKE concat('%', $3, '%')
+		)
+		ELSE true
+	END
+	-- Filter by organization roles
+	AND CASE
+		WHEN cardinality($4 :: text[]) > 0 THEN
+			organization_members.roles && $4 :: text[]
+		ELSE true
+	END
+	-- Filter by group membership
+	AND CASE
+		WHEN $5 :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_members.user_id IN (
+				SELECT
+					user_id
+				FROM
+					group_members
+				WHERE
+					group_id = $5
+			)
+		ELSE true
+	END
+	-- End of filters
+ORDER BY
+	-- Deterministic and consistent ordering of all members, even if they share
+	-- a timestamp. This is to ensure consistent pagination.
+	(organization_members.created_at, organization_members.user_id) ASC OFFSET $6
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($7 :: int, 0)
+`
+
+type GetOrganizationMembersParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	AfterID        uuid.UUID `db:"after_id" json:"after_id"`
+	Search         string    `db:"search" json:"search"`
+	Roles          []string  `db:"roles" json:"roles"`
+	GroupID        uuid.UUID `db:"group_id" json:"group_id"`
+	OffsetOpt      int32     `db:"offset_opt" json:"offset_opt"`
+	LimitOpt       int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+type GetOrganizationMembersRow struct {
+	UserID         uuid.UUID      `db:"user_id" json:"user_id"`
+	OrganizationID uuid.UUID      `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	Roles          []string       `db:"roles" json:"roles"`
+	Username       string         `db:"username" json:"username"`
+	Email          string         `db:"email" json:"email"`
+	AvatarURL      sql.NullString `db:"avatar_url" json:"avatar_url"`
+}
+
+func (q *sqlQuerier) GetOrganizationMembers(ctx context.Context, arg GetOrganizationMembersParams) ([]GetOrganizationMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationMembers,
+		arg.OrganizationID,
+		arg.AfterID,
+		arg.Search,
+		pq.Array(arg.Roles),
+		arg.GroupID,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationMembersRow
+	for rows.Next() {
+		var i GetOrganizationMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			pq.Array(&i.Roles),
+			&i.Username,
+			&i.Email,
+			&i.AvatarURL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
+SELECT
+	user_id, organization_id, created_at, updated_at, roles
+FROM
+	organization_members
+WHERE
+  user_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationMembershipsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationMember
+	for rows.Next() {
+		var i OrganizationMember
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOrganizationMember = `-- name: InsertOrganizationMember :one
+INSERT INTO
+	organization_members (
+		organization_id,
+		user_id,
+		created_at,
+		updated_at,
+		roles
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING user_id, organization_id, created_at, updated_at, roles
+`
+
+type InsertOrganizationMemberParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	Roles          []string  `db:"roles" json:"roles"`
+}
+
+func (q *sqlQuerier) InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationMember,
+		arg.OrganizationID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		pq.Array(arg.Roles),
+	)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.UserID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const updateMemberRoles = `-- name: UpdateMemberRoles :one
+UPDATE
+	organization_members
+SET
+	-- Remove all duplicates from the roles.
+	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+WHERE
+	user_id = $2
+	AND organization_id = $3
+RETURNING user_id, organization_id, created_at, updated_at, roles
+`
+
+type UpdateMemberRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	OrgID        uuid.UUID `db:"org_id" json:"org_id"`
+}
+
+func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, updateMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.OrgID)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.UserID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		pq.Array(&i.Roles),
+	)
+	return i, err
+}
+
+const deleteOrganizationOIDCConfigByOrganizationID = `-- name: DeleteOrganizationOIDCConfigByOrganizationID :exec
+DELETE FROM
+	organization_oidc_configs
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) DeleteOrganizationOIDCConfigByOrganizationID(ctx context.Context, organizationID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationOIDCConfigByOrganizationID, organizationID)
+	return err
+}
+
+const getOrganizationOIDCConfigByEmailDomain = `-- name: GetOrganizationOIDCConfigByEmailDomain :one
+SELECT
+	organization_id, issuer_url, client_id, client_secret, scopes, email_domains, username_field, email_field, allow_signups, created_at, updated_at
+FROM
+	organization_oidc_configs
+WHERE
+	LOWER($1 :: text) = ANY(email_domains)
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetOrganizationOIDCConfigByEmailDomain(ctx context.Context, emailDomain string) (OrganizationOIDCConfig, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationOIDCConfigByEmailDomain, emailDomain)
+	var i OrganizationOIDCConfig
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.IssuerURL,
+		&i.ClientID,
+		&i.ClientSecret,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.EmailDomains),
+		&i.UsernameField,
+		&i.EmailField,
+		&i.AllowSignups,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationOIDCConfigByOrganizationID = `-- name: GetOrganizationOIDCConfigByOrganizationID :one
+SELECT
+	organization_id, issuer_url, client_id, client_secret, scopes, email_domains, username_field, email_field, allow_signups, created_at, updated_at
+FROM
+	organization_oidc_configs
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationOIDCConfigByOrganizationID(ctx context.Context, organizationID uuid.UUID) (OrganizationOIDCConfig, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationOIDCConfigByOrganizationID, organizationID)
+	var i OrganizationOIDCConfig
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.IssuerURL,
+		&i.ClientID,
+		&i.ClientSecret,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.EmailDomains),
+		&i.UsernameField,
+		&i.EmailField,
+		&i.AllowSignups,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertOrganizationOIDCConfig = `-- name: UpsertOrganizationOIDCConfig :one
+INSERT INTO
+	organization_oidc_configs (
+		organization_id,
+		issuer_url,
+		client_id,
+		client_secret,
+		scopes,
+		email_domains,
+		username_field,
+		email_field,
+		allow_signups,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (organization_id) DO UPDATE SET
+	issuer_url = $2,
+	client_id = $3,
+	client_secret = $4,
+	scopes = $5,
+	email_domains = $6,
+	username_field = $7,
+	email_field = $8,
+	allow_signups = $9,
+	updated_at = $11
+RETURNING organization_id, issuer_url, client_id, client_secret, scopes, email_domains, username_field, email_field, allow_signups, created_at, updated_at
+`
+
+type UpsertOrganizationOIDCConfigParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	IssuerURL      string    `db:"issuer_url" json:"issuer_url"`
+	ClientID       string    `db:"client_id" json:"client_id"`
+	ClientSecret   string    `db:"client_secret" json:"client_secret"`
+	Scopes         []string  `db:"scopes" json:"scopes"`
+	EmailDomains   []string  `db:"email_domains" json:"email_domains"`
+	UsernameField  string    `db:"username_field" json:"username_field"`
+	EmailField     string    `db:"email_field" json:"email_field"`
+	AllowSignups   bool      `db:"allow_signups" json:"allow_signups"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationOIDCConfig(ctx context.Context, arg UpsertOrganizationOIDCConfigParams) (OrganizationOIDCConfig, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationOIDCConfig,
+		arg.OrganizationID,
+		arg.IssuerURL,
+		arg.ClientID,
+		arg.ClientSecret,
+		pq.Array(arg.Scopes),
+		pq.Array(arg.EmailDomains),
+		arg.UsernameField,
+		arg.EmailField,
+		arg.AllowSignups,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i OrganizationOIDCConfig
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.IssuerURL,
+		&i.ClientID,
+		&i.ClientSecret,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.EmailDomains),
+		&i.UsernameField,
+		&i.EmailField,
+		&i.AllowSignups,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationQuotaByOrganizationID = `-- name: GetOrganizationQuotaByOrganizationID :one
+SELECT
+	organization_id, max_workspaces, max_running_workspaces, compute_credits, updated_at
+FROM
+	organization_quotas
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationQuotaByOrganizationID(ctx context.Context, organizationID uuid.UUID) (OrganizationQuota, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationQuotaByOrganizationID, organizationID)
+	var i OrganizationQuota
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.MaxWorkspaces,
+		&i.MaxRunningWorkspaces,
+		&i.ComputeCredits,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationQuotaConsumption = `-- name: GetOrganizationQuotaConsumption :many
+SELECT
+	templates.id AS template_id,
+	templates.name AS template_name,
+	templates.quota_weight,
+	COUNT(workspaces.id) AS workspace_count,
+	COUNT(workspaces.id) FILTER (WHERE latest_build.transition = 'start') AS running_workspace_count
+FROM
+	workspaces
+JOIN
+	templates
+ON
+	templates.id = workspaces.template_id
+LEFT JOIN LATERAL (
+	SELECT
+		transition
+	FROM
+		workspace_builds
+	WHERE
+		workspace_builds.workspace_id = workspaces.id
+	ORDER BY
+		build_number DESC
+	LIMIT
+		1
+) latest_build ON TRUE
+WHERE
+	workspaces.organization_id = $1
+	-- Ignore deleted workspaces
+	AND workspaces.deleted != true
+GROUP BY
+	templates.id
+ORDER BY
+	templates.name ASC
+`
+
+type GetOrganizationQuotaConsumptionRow struct {
+	TemplateID            uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateName          string    `db:"template_name" json:"template_name"`
+	QuotaWeight           int32     `db:"quota_weight" json:"quota_weight"`
+	WorkspaceCount        int64     `db:"workspace_count" json:"workspace_count"`
+	RunningWorkspaceCount int64     `db:"running_workspace_count" json:"running_workspace_count"`
+}
+
+// Counts the workspaces of the organization per template, along with how
+// many of them are running and the quota weight of the template.
+func (q *sqlQuerier) GetOrganizationQuotaConsumption(ctx context.Context, organizationID uuid.UUID) ([]GetOrganizationQuotaConsumptionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationQuotaConsumption, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationQuotaConsumptionRow
+	for rows.Next() {
+		var i GetOrganizationQuotaConsumptionRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.TemplateName,
+			&i.QuotaWeight,
+			&i.WorkspaceCount,
+			&i.RunningWorkspaceCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertOrganizationQuota = `-- name: UpsertOrganizationQuota :one
+INSERT INTO
+	organization_quotas (organization_id, max_workspaces, max_running_workspaces, compute_credits, updated_at)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (organization_id) DO UPDATE SET
+	max_workspaces = $2,
+	max_running_workspaces = $3,
+	compute_credits = $4,
+	updated_at = $5
+RETURNING organization_id, max_workspaces, max_running_workspaces, compute_credits, updated_at
+`
+
+type UpsertOrganizationQuotaParams struct {
+	OrganizationID       uuid.UUID `db:"organization_id" json:"organization_id"`
+	MaxWorkspaces        int32     `db:"max_workspaces" json:"max_workspaces"`
+	MaxRunningWorkspaces int32     `db:"max_running_workspaces" json:"max_running_workspaces"`
+	ComputeCredits       int32     `db:"compute_credits" json:"compute_credits"`
+	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationQuota(ctx context.Context, arg UpsertOrganizationQuotaParams) (OrganizationQuota, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationQuota,
+		arg.OrganizationID,
+		arg.MaxWorkspaces,
+		arg.MaxRunningWorkspaces,
+		arg.ComputeCredits,
+		arg.UpdatedAt,
+	)
+	var i OrganizationQuota
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.MaxWorkspaces,
+		&i.MaxRunningWorkspaces,
+		&i.ComputeCredits,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteOrganization = `-- name: DeleteOrganization :exec
+DELETE FROM
+	organizations
+WHERE
+	id = $1 AND
+	is_default = false
+`
+
+func (q *sqlQuerier) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganization, id)
+	return err
+}
+
+const getDefaultOrganization = `-- name: GetDefaultOrganization :one
+SELECT
+	id, name, description, created_at, updated_at, is_default, display_name, icon
+FROM
+	organizations
+WHERE
+	is_default = true
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetDefaultOrganization(ctx context.Context) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getDefaultOrganization)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsDefault,
+		&i.DisplayName,
+		&i.Icon,
+	)
+	return i, err
+}
+
+const getOrganizationByID = `-- name: GetOrganizationByID :one
+SELECT
+	id, name, description, created_at, updated_at, is_default, display_name, icon
+FROM
+	organizations
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsDefault,
+		&i.DisplayName,
+		&i.Icon,
+	)
+	return i, err
+}
+
+const getOrganizationByName = `-- name: GetOrganizationByName :one
+SELECT
+	id, name, description, created_at, updated_at, is_default, display_name, icon
+FROM
+	organizations
+WHERE
+	LOWER("name") = LOWER($1)
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsDefault,
+		&i.DisplayName,
+		&i.Icon,
+	)
+	return i, err
+}
+
+const getOrganizations = `-- name: GetOrganizations :many
+SELECT
+	id, name, description, created_at, updated_at, is_default, display_name, icon
+FROM
+	organizations
+`
+
+func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsDefault,
+			&i.DisplayName,
+			&i.Icon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
+SELECT
+	id, name, description, created_at, updated_at, is_default, display_name, icon
+FROM
+	organizations
+WHERE
+	id = ANY(
+		SELECT
+			organization_id
+		FROM
+			organization_members
+		WHERE
+			user_id = $1
+	)
+`
+
+func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsDefault,
+			&i.DisplayName,
+			&i.Icon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOrganization = `-- name: InsertOrganization :one
+INSERT INTO
+	organizations (id, "name", description, created_at, updated_at, is_default)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING id, name, description, created_at, updated_at, is_default, display_name, icon
+`
+
+type InsertOrganizationParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	IsDefault   bool      `db:"is_default" json:"is_default"`
+}
+
+func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganization,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.IsDefault,
+	)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsDefault,
+		&i.DisplayName,
+		&i.Icon,
+	)
+	return i, err
+}
+
+const updateOrganizationByID = `-- name: UpdateOrganizationByID :one
+UPDATE
+	organizations
+SET
+	display_name = $2,
+	icon = $3,
+	description = $4,
+	updated_at = $5,
+	name = $6
+WHERE
+	id = $1
+RETURNING id, name, description, created_at, updated_at, is_default, display_name, icon
+`
+
+type UpdateOrganizationByIDParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	DisplayName string    `db:"display_name" json:"display_name"`
+	Icon        string    `db:"icon" json:"icon"`
+	Description string    `db:"description" json:"description"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Name        string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) UpdateOrganizationByID(ctx context.Context, arg UpdateOrganizationByIDParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, updateOrganizationByID,
+		arg.ID,
+		arg.DisplayName,
+		arg.Icon,
+		arg.Description,
+		arg.UpdatedAt,
+		arg.Name,
+	)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.IsDefault,
+		&i.DisplayName,
+		&i.Icon,
+	)
+	return i, err
+}
+
+const getOrganizationTemplateDefaults = `-- name: GetOrganizationTemplateDefaults :one
+SELECT
+	organization_id, default_ttl, max_ttl, min_autostart_interval, parameter_values, allowed_parameter_values, updated_at
+FROM
+	organization_template_defaults
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationTemplateDefaults(ctx context.Context, organizationID uuid.UUID) (OrganizationTemplateDefault, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationTemplateDefaults, organizationID)
+	var i OrganizationTemplateDefault
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.DefaultTtl,
+		&i.MaxTtl,
+		&i.MinAutostartInterval,
+		&i.ParameterValues,
+		&i.AllowedParameterValues,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertOrganizationTemplateDefaults = `-- name: UpsertOrganizationTemplateDefaults :one
+INSERT INTO
+	organization_template_defaults (
+		organization_id,
+		default_ttl,
+		max_ttl,
+		min_autostart_interval,
+		parameter_values,
+		allowed_parameter_values,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (organization_id) DO UPDATE SET
+	default_ttl = $2,
+	max_ttl = $3,
+	min_autostart_interval = $4,
+	parameter_values = $5,
+	allowed_parameter_values = $6,
+	updated_at = $7
+RETURNING organization_id, default_ttl, max_ttl, min_autostart_interval, parameter_values, allowed_parameter_values, updated_at
+`
+
+type UpsertOrganizationTemplateDefaultsParams struct {
+	OrganizationID         uuid.UUID       `db:"organization_id" json:"organization_id"`
+	DefaultTtl             int64           `db:"default_ttl" json:"default_ttl"`
+	MaxTtl                 int64           `db:"max_ttl" json:"max_ttl"`
+	MinAutostartInterval   int64           `db:"min_autostart_interval" json:"min_autostart_interval"`
+	ParameterValues        json.RawMessage `db:"parameter_values" json:"parameter_values"`
+	AllowedParameterValues json.RawMessage `db:"allowed_parameter_values" json:"allowed_parameter_values"`
+	UpdatedAt              time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationTemplateDefaults(ctx context.Context, arg UpsertOrganizationTemplateDefaultsParams) (OrganizationTemplateDefault, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationTemplateDefaults,
+		arg.OrganizationID,
+		arg.DefaultTtl,
+		arg.MaxTtl,
+		arg.MinAutostartInterval,
+		arg.ParameterValues,
+		arg.AllowedParameterValues,
+		arg.UpdatedAt,
+	)
+	var i OrganizationTemplateDefault
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.DefaultTtl,
+		&i.MaxTtl,
+		&i.MinAutostartInterval,
+		&i.ParameterValues,
+		&i.AllowedParameterValues,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteOrganizationWebhookByID = `-- name: DeleteOrganizationWebhookByID :exec
+DELETE FROM
+	organization_webhooks
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteOrganizationWebhookByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationWebhookByID, id)
+	return err
+}
+
+const getOrganizationWebhookByID = `-- name: GetOrganizationWebhookByID :one
+SELECT
+	id, organization_id, url, secret, events, created_at
+FROM
+	organization_webhooks
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationWebhookByID(ctx context.Context, id uuid.UUID) (OrganizationWebhook, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationWebhookByID, id)
+	var i OrganizationWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationWebhookDeliveriesByWebhookID = `-- name: GetOrganizationWebhookDeliveriesByWebhookID :many
+SELECT
+	id, webhook_id, event_id, event_type, attempt, status_code, error, created_at
+FROM
+	organization_webhook_deliveries
+WHERE
+	webhook_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	$2
+`
+
+type GetOrganizationWebhookDeliveriesByWebhookIDParams struct {
+	WebhookID uuid.UUID `db:"webhook_id" json:"webhook_id"`
+	Limit     int32     `db:"limit" json:"limit"`
+}
+
+func (q *sqlQuerier) GetOrganizationWebhookDeliveriesByWebhookID(ctx context.Context, arg GetOrganizationWebhookDeliveriesByWebhookIDParams) ([]OrganizationWebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationWebhookDeliveriesByWebhookID, arg.WebhookID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationWebhookDelivery
+	for rows.Next() {
+		var i OrganizationWebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Attempt,
+			&i.StatusCode,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationWebhooksByOrganizationID = `-- name: GetOrganizationWebhooksByOrganizationID :many
+SELECT
+	id, organization_id, url, secret, events, created_at
+FROM
+	organization_webhooks
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetOrganizationWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]OrganizationWebhook, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationWebhooksByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationWebhook
+	for rows.Next() {
+		var i OrganizationWebhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOrganizationWebhook = `-- name: InsertOrganizationWebhook :one
+INSERT INTO organization_webhooks (
+	id,
+	organization_id,
+	url,
+	secret,
+	events,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING id, organization_id, url, secret, events, created_at
+`
+
+type InsertOrganizationWebhookParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Url            string    `db:"url" json:"url"`
+	Secret         string    `db:"secret" json:"secret"`
+	Events         []string  `db:"events" json:"events"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertOrganizationWebhook(ctx context.Context, arg InsertOrganizationWebhookParams) (OrganizationWebhook, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationWebhook,
+		arg.ID,
+		arg.OrganizationID,
+		arg.Url,
+		arg.Secret,
+		pq.Array(arg.Events),
+		arg.CreatedAt,
+	)
+	var i OrganizationWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertOrganizationWebhookDelivery = `-- name: InsertOrganizationWebhookDelivery :one
+INSERT INTO organization_webhook_deliveries (
+	id,
+	webhook_id,
+	event_id,
+	event_type,
+	attempt,
+	status_code,
+	error,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, webhook_id, event_id, event_type, attempt, status_code, error, created_at
+`
+
+type InsertOrganizationWebhookDeliveryParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	WebhookID  uuid.UUID `db:"webhook_id" json:"webhook_id"`
+	EventID    uuid.UUID `db:"event_id" json:"event_id"`
+	EventType  string    `db:"event_type" json:"event_type"`
+	Attempt    int32     `db:"attempt" json:"attempt"`
+	StatusCode int32     `db:"status_code" json:"status_code"`
+	Error      string    `db:"error" json:"error"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertOrganizationWebhookDelivery(ctx context.Context, arg InsertOrganizationWebhookDeliveryParams) (OrganizationWebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationWebhookDelivery,
+		arg.ID,
+		arg.WebhookID,
+		arg.EventID,
+		arg.EventType,
+		arg.Attempt,
+		arg.StatusCode,
+		arg.Error,
+		arg.CreatedAt,
+	)
+	var i OrganizationWebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.EventID,
+		&i.EventType,
+		&i.Attempt,
+		&i.StatusCode,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationWorkspaceNamePolicy = `-- name: GetOrganizationWorkspaceNamePolicy :one
+SELECT
+	organization_id, pattern, prefix, reserved_names, updated_at
+FROM
+	organization_workspace_name_policies
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationWorkspaceNamePolicy(ctx context.Context, organizationID uuid.UUID) (OrganizationWorkspaceNamePolicy, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationWorkspaceNamePolicy, organizationID)
+	var i OrganizationWorkspaceNamePolicy
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.Pattern,
+		&i.Prefix,
+		pq.Array(&i.ReservedNames),
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertOrganizationWorkspaceNamePolicy = `-- name: UpsertOrganizationWorkspaceNamePolicy :one
+INSERT INTO
+	organization_workspace_name_policies (
+		organization_id,
+		pattern,
+		prefix,
+		reserved_names,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (organization_id) DO UPDATE SET
+	pattern = $2,
+	prefix = $3,
+	reserved_names = $4,
+	updated_at = $5
+RETURNING organization_id, pattern, prefix, reserved_names, updated_at
+`
+
+type UpsertOrganizationWorkspaceNamePolicyParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Pattern        string    `db:"pattern" json:"pattern"`
+	Prefix         string    `db:"prefix" json:"prefix"`
+	ReservedNames  []string  `db:"reserved_names" json:"reserved_names"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationWorkspaceNamePolicy(ctx context.Context, arg UpsertOrganizationWorkspaceNamePolicyParams) (OrganizationWorkspaceNamePolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationWorkspaceNamePolicy,
+		arg.OrganizationID,
+		arg.Pattern,
+		arg.Prefix,
+		pq.Array(arg.ReservedNames),
+		arg.UpdatedAt,
+	)
+	var i OrganizationWorkspaceNamePolicy
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.Pattern,
+		&i.Prefix,
+		pq.Array(&i.ReservedNames),
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+SELECT
+	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+FROM
+	parameter_schemas
+WHERE
+	job_id = $1
+ORDER BY
+	index
+`
+
+func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
+	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParameterSchema
+	for rows.Next() {
+		var i ParameterSchema
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.JobID,
+			&i.Name,
+			&i.Description,
+			&i.DefaultSourceScheme,
+			&i.DefaultSourceValue,
+			&i.AllowOverrideSource,
+			&i.DefaultDestinationScheme,
+			&i.AllowOverrideDestination,
+			&i.DefaultRefresh,
+			&i.RedisplayValue,
+			&i.ValidationError,
+			&i.ValidationCondition,
+			&i.ValidationTypeSystem,
+			&i.ValidationValueType,
+			&i.Index,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getParameterSchemasCreatedAfter = `-- name: GetParameterSchemasCreatedAfter :many
+SELECT id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index FROM parameter_schemas WHERE created_at > $1
+`
+
+func (q *sqlQuerier) GetParameterSchemasCreatedAfter(ctx context.Context, createdAt time.Time) ([]ParameterSchema, error) {
+	rows, err := q.db.QueryContext(ctx, getParameterSchemasCreatedAfter, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParameterSchema
+	for rows.Next() {
+		var i ParameterSchema
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.JobID,
+			&i.Name,
+			&i.Description,
+			&i.DefaultSourceScheme,
+			&i.DefaultSourceValue,
+			&i.AllowOverrideSource,
+			&i.DefaultDestinationScheme,
+			&i.AllowOverrideDestination,
+			&i.DefaultRefresh,
+			&i.RedisplayValue,
+			&i.ValidationError,
+			&i.ValidationCondition,
+			&i.ValidationTypeSystem,
+			&i.ValidationValueType,
+			&i.Index,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertParameterSchema = `-- name: InsertParameterSchema :one
+INSERT INTO
+	parameter_schemas (
+		id,
+		created_at,
+		job_id,
+		"name",
+		description,
+		default_source_scheme,
+		default_source_value,
+		allow_override_source,
+		default_destination_scheme,
+		allow_override_destination,
+		default_refresh,
+		redisplay_value,
+		validation_error,
+		validation_condition,
+		validation_type_system,
+		validation_value_type,
+		index
+	)
+VALUES
+	(
+		$1,
+		$2,
+		$3,
+		$4,
+		$5,
+		$6,
+		$7,
+		$8,
+		$9,
+		$10,
+		$11,
+		$12,
+		$13,
+		$14,
+		$15,
+		$16,
+		$17
 	) RETURNING id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
 `
 
-type InsertParameterSchemaParams struct {
-	ID                       uuid.UUID                  `db:"id" json:"id"`
-	CreatedAt                time.Time                  `db:"created_at" json:"created_at"`
-	JobID                    uuid.UUID                  `db:"job_id" json:"job_id"`
-	Name                     string                     `db:"name" json:"name"`
-	Description              string                     `db:"description" json:"description"`
-	DefaultSourceScheme      ParameterSourceScheme      `db:"default_source_scheme" json:"default_source_scheme"`
-	DefaultSourceValue       string                     `db:"default_source_value" json:"default_source_value"`
-	AllowOverrideSource      bool                       `db:"allow_override_source" json:"allow_override_source"`
-	DefaultDestinationScheme ParameterDestinationScheme `db:"default_destination_scheme" json:"default_destination_scheme"`
-	AllowOverrideDestination bool                       `db:"allow_override_destination" json:"allow_override_destination"`
-	DefaultRefresh           string                     `db:"default_refresh" json:"default_refresh"`
-	RedisplayValue           bool                       `db:"redisplay_value" json:"redisplay_value"`
-	ValidationError          string                     `db:"validation_error" json:"validation_error"`
-	ValidationCondition      string                     `db:"validation_condition" json:"validation_condition"`
-	ValidationTypeSystem     ParameterTypeSystem        `db:"validation_type_system" json:"validation_type_system"`
-	ValidationValueType      string                     `db:"validation_value_type" json:"validation_value_type"`
-	Index                    int32                      `db:"index" json:"index"`
+type InsertParameterSchemaParams struct {
+	ID                       uuid.UUID                  `db:"id" json:"id"`
+	CreatedAt                time.Time                  `db:"created_at" json:"created_at"`
+	JobID                    uuid.UUID                  `db:"job_id" json:"job_id"`
+	Name                     string                     `db:"name" json:"name"`
+	Description              string                     `db:"description" json:"description"`
+	DefaultSourceScheme      ParameterSourceScheme      `db:"default_source_scheme" json:"default_source_scheme"`
+	DefaultSourceValue       string                     `db:"default_source_value" json:"default_source_value"`
+	AllowOverrideSource      bool                       `db:"allow_override_source" json:"allow_override_source"`
+	DefaultDestinationScheme ParameterDestinationScheme `db:"default_destination_scheme" json:"default_destination_scheme"`
+	AllowOverrideDestination bool                       `db:"allow_override_destination" json:"allow_override_destination"`
+	DefaultRefresh           string                     `db:"default_refresh" json:"default_refresh"`
+	RedisplayValue           bool                       `db:"redisplay_value" json:"redisplay_value"`
+	ValidationError          string                     `db:"validation_error" json:"validation_error"`
+	ValidationCondition      string                     `db:"validation_condition" json:"validation_condition"`
+	ValidationTypeSystem     ParameterTypeSystem        `db:"validation_type_system" json:"validation_type_system"`
+	ValidationValueType      string                     `db:"validation_value_type" json:"validation_value_type"`
+	Index                    int32                      `db:"index" json:"index"`
+}
+
+func (q *sqlQuerier) InsertParameterSchema(ctx context.Context, arg InsertParameterSchemaParams) (ParameterSchema, error) {
+	row := q.db.QueryRowContext(ctx, insertParameterSchema,
+		arg.ID,
+		arg.CreatedAt,
+		arg.JobID,
+		arg.Name,
+		arg.Description,
+		arg.DefaultSourceScheme,
+		arg.DefaultSourceValue,
+		arg.AllowOverrideSource,
+		arg.DefaultDestinationScheme,
+		arg.AllowOverrideDestination,
+		arg.DefaultRefresh,
+		arg.RedisplayValue,
+		arg.ValidationError,
+		arg.ValidationCondition,
+		arg.ValidationTypeSystem,
+		arg.ValidationValueType,
+		arg.Index,
+	)
+	var i ParameterSchema
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.JobID,
+		&i.Name,
+		&i.Description,
+		&i.DefaultSourceScheme,
+		&i.DefaultSourceValue,
+		&i.AllowOverrideSource,
+		&i.DefaultDestinationScheme,
+		&i.AllowOverrideDestination,
+		&i.DefaultRefresh,
+		&i.RedisplayValue,
+		&i.ValidationError,
+		&i.ValidationCondition,
+		&i.ValidationTypeSystem,
+		&i.ValidationValueType,
+		&i.Index,
+	)
+	return i, err
+}
+
+const deleteParameterValueByID = `-- name: DeleteParameterValueByID :exec
+DELETE FROM
+	parameter_values
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteParameterValueByID, id)
+	return err
+}
+
+const getParameterValueByScopeAndName = `-- name: GetParameterValueByScopeAndName :one
+SELECT
+	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+FROM
+	parameter_values
+WHERE
+	scope = $1
+	AND scope_id = $2
+	AND NAME = $3
+LIMIT
+	1
+`
+
+type GetParameterValueByScopeAndNameParams struct {
+	Scope   ParameterScope `db:"scope" json:"scope"`
+	ScopeID uuid.UUID      `db:"scope_id" json:"scope_id"`
+	Name    string         `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetParameterValueByScopeAndName(ctx context.Context, arg GetParameterValueByScopeAndNameParams) (ParameterValue, error) {
+	row := q.db.QueryRowContext(ctx, getParameterValueByScopeAndName, arg.Scope, arg.ScopeID, arg.Name)
+	var i ParameterValue
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Scope,
+		&i.ScopeID,
+		&i.Name,
+		&i.SourceScheme,
+		&i.SourceValue,
+		&i.DestinationScheme,
+	)
+	return i, err
+}
+
+const insertParameterValue = `-- name: InsertParameterValue :one
+INSERT INTO
+	parameter_values (
+		id,
+		"name",
+		created_at,
+		updated_at,
+		scope,
+		scope_id,
+		source_scheme,
+		source_value,
+		destination_scheme
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+`
+
+type InsertParameterValueParams struct {
+	ID                uuid.UUID                  `db:"id" json:"id"`
+	Name              string                     `db:"name" json:"name"`
+	CreatedAt         time.Time                  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time                  `db:"updated_at" json:"updated_at"`
+	Scope             ParameterScope             `db:"scope" json:"scope"`
+	ScopeID           uuid.UUID                  `db:"scope_id" json:"scope_id"`
+	SourceScheme      ParameterSourceScheme      `db:"source_scheme" json:"source_scheme"`
+	SourceValue       string                     `db:"source_value" json:"source_value"`
+	DestinationScheme ParameterDestinationScheme `db:"destination_scheme" json:"destination_scheme"`
+}
+
+func (q *sqlQuerier) InsertParameterValue(ctx context.Context, arg InsertParameterValueParams) (ParameterValue, error) {
+	row := q.db.QueryRowContext(ctx, insertParameterValue,
+		arg.ID,
+		arg.Name,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Scope,
+		arg.ScopeID,
+		arg.SourceScheme,
+		arg.SourceValue,
+		arg.DestinationScheme,
+	)
+	var i ParameterValue
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Scope,
+		&i.ScopeID,
+		&i.Name,
+		&i.SourceScheme,
+		&i.SourceValue,
+		&i.DestinationScheme,
+	)
+	return i, err
+}
+
+const parameterValue = `-- name: ParameterValue :one
+SELECT id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme FROM
+	parameter_values
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error) {
+	row := q.db.QueryRowContext(ctx, parameterValue, id)
+	var i ParameterValue
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Scope,
+		&i.ScopeID,
+		&i.Name,
+		&i.SourceScheme,
+		&i.SourceValue,
+		&i.DestinationScheme,
+	)
+	return i, err
+}
+
+const parameterValues = `-- name: ParameterValues :many
+SELECT
+	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+FROM
+	parameter_values
+WHERE
+  	CASE
+		  WHEN cardinality($1 :: parameter_scope[]) > 0 THEN
+				  scope = ANY($1 :: parameter_scope[])
+		  ELSE true
+	END
+    AND CASE
+		WHEN cardinality($2 :: uuid[]) > 0 THEN
+			scope_id = ANY($2 :: uuid[])
+		ELSE true
+	END
+  	AND CASE
+		WHEN cardinality($3 :: uuid[]) > 0 THEN
+			id = ANY($3 :: uuid[])
+		ELSE true
+	END
+  	AND CASE
+		  WHEN cardinality($4 :: text[]) > 0 THEN
+				  "name" = ANY($4 :: text[])
+		  ELSE true
+	END
+`
+
+type ParameterValuesParams struct {
+	Scopes   []ParameterScope `db:"scopes" json:"scopes"`
+	ScopeIds []uuid.UUID      `db:"scope_ids" json:"scope_ids"`
+	IDs      []uuid.UUID      `db:"ids" json:"ids"`
+	Names    []string         `db:"names" json:"names"`
+}
+
+func (q *sqlQuerier) ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error) {
+	rows, err := q.db.QueryContext(ctx, parameterValues,
+		pq.Array(arg.Scopes),
+		pq.Array(arg.ScopeIds),
+		pq.Array(arg.IDs),
+		pq.Array(arg.Names),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParameterValue
+	for rows.Next() {
+		var i ParameterValue
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Scope,
+			&i.ScopeID,
+			&i.Name,
+			&i.SourceScheme,
+			&i.SourceValue,
+			&i.DestinationScheme,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProvisionerDaemonByID = `-- name: GetProvisionerDaemonByID :one
+SELECT
+	id, created_at, updated_at, name, provisioners
+FROM
+	provisioner_daemons
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetProvisionerDaemonByID(ctx context.Context, id uuid.UUID) (ProvisionerDaemon, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerDaemonByID, id)
+	var i ProvisionerDaemon
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		pq.Array(&i.Provisioners),
+	)
+	return i, err
+}
+
+const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
+SELECT
+	id, created_at, updated_at, name, provisioners
+FROM
+	provisioner_daemons
+`
+
+func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerDaemons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerDaemon
+	for rows.Next() {
+		var i ProvisionerDaemon
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			pq.Array(&i.Provisioners),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerDaemon = `-- name: InsertProvisionerDaemon :one
+INSERT INTO
+	provisioner_daemons (
+		id,
+		created_at,
+		"name",
+		provisioners
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING id, created_at, updated_at, name, provisioners
+`
+
+type InsertProvisionerDaemonParams struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+	Name         string            `db:"name" json:"name"`
+	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
+}
+
+func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerDaemon,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Name,
+		pq.Array(arg.Provisioners),
+	)
+	var i ProvisionerDaemon
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		pq.Array(&i.Provisioners),
+	)
+	return i, err
+}
+
+const updateProvisionerDaemonByID = `-- name: UpdateProvisionerDaemonByID :exec
+UPDATE
+	provisioner_daemons
+SET
+	updated_at = $2,
+	provisioners = $3
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerDaemonByIDParams struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	UpdatedAt    sql.NullTime      `db:"updated_at" json:"updated_at"`
+	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerDaemonByID(ctx context.Context, arg UpdateProvisionerDaemonByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerDaemonByID, arg.ID, arg.UpdatedAt, pq.Array(arg.Provisioners))
+	return err
+}
+
+const getProvisionerLogsByIDBetween = `-- name: GetProvisionerLogsByIDBetween :many
+SELECT
+	id, job_id, created_at, source, level, stage, output
+FROM
+	provisioner_job_logs
+WHERE
+	job_id = $1
+	AND (
+		created_at >= $2
+		OR created_at <= $3
+	)
+ORDER BY
+	created_at DESC
+`
+
+type GetProvisionerLogsByIDBetweenParams struct {
+	JobID         uuid.UUID `db:"job_id" json:"job_id"`
+	CreatedAfter  time.Time `db:"created_after" json:"created_after"`
+	CreatedBefore time.Time `db:"created_before" json:"created_before"`
+}
+
+func (q *sqlQuerier) GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerLogsByIDBetween, arg.JobID, arg.CreatedAfter, arg.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobLog
+	for rows.Next() {
+		var i ProvisionerJobLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.CreatedAt,
+			&i.Source,
+			&i.Level,
+			&i.Stage,
+			&i.Output,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
+INSERT INTO
+	provisioner_job_logs
+SELECT
+	unnest($1 :: uuid [ ]) AS id,
+	$2 :: uuid AS job_id,
+	unnest($3 :: timestamptz [ ]) AS created_at,
+	unnest($4 :: log_source [ ]) AS source,
+	unnest($5 :: log_level [ ]) AS LEVEL,
+	unnest($6 :: VARCHAR(128) [ ]) AS stage,
+	unnest($7 :: VARCHAR(1024) [ ]) AS output RETURNING id, job_id, created_at, source, level, stage, output
+`
+
+type InsertProvisionerJobLogsParams struct {
+	ID        []uuid.UUID `db:"id" json:"id"`
+	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
+	CreatedAt []time.Time `db:"created_at" json:"created_at"`
+	Source    []LogSource `db:"source" json:"source"`
+	Level     []LogLevel  `db:"level" json:"level"`
+	Stage     []string    `db:"stage" json:"stage"`
+	Output    []string    `db:"output" json:"output"`
+}
+
+func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
+	rows, err := q.db.QueryContext(ctx, insertProvisionerJobLogs,
+		pq.Array(arg.ID),
+		arg.JobID,
+		pq.Array(arg.CreatedAt),
+		pq.Array(arg.Source),
+		pq.Array(arg.Level),
+		pq.Array(arg.Stage),
+		pq.Array(arg.Output),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobLog
+	for rows.Next() {
+		var i ProvisionerJobLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.CreatedAt,
+			&i.Source,
+			&i.Level,
+			&i.Stage,
+			&i.Output,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const acquireProvisionerJob = `-- name: AcquireProvisionerJob :one
+UPDATE
+	provisioner_jobs
+SET
+	started_at = $1,
+	updated_at = $1,
+	worker_id = $2
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			provisioner_jobs AS nested
+		WHERE
+			nested.started_at IS NULL
+			AND nested.canceled_at IS NULL
+			AND nested.completed_at IS NULL
+			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
+		ORDER BY
+			nested.created_at FOR
+		UPDATE
+			SKIP LOCKED
+		LIMIT
+			1
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+`
+
+type AcquireProvisionerJobParams struct {
+	StartedAt sql.NullTime      `db:"started_at" json:"started_at"`
+	WorkerID  uuid.NullUUID     `db:"worker_id" json:"worker_id"`
+	Types     []ProvisionerType `db:"types" json:"types"`
+}
+
+// Acquires the lock for a single job that isn't started, completed,
+// canceled, and that matches an array of provisioner types.
+//
+// SKIP LOCKED is used to jump over locked rows. This prevents
+// multiple provisioners from acquiring the same jobs. See:
+// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
+func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, acquireProvisionerJob, arg.StartedAt, arg.WorkerID, pq.Array(arg.Types))
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.StorageSource,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+	)
+	return i, err
+}
+
+const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
+SELECT
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+FROM
+	provisioner_jobs
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.StorageSource,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+	)
+	return i, err
+}
+
+const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
+SELECT
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+FROM
+	provisioner_jobs
+WHERE
+	id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJob
+	for rows.Next() {
+		var i ProvisionerJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.StorageSource,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
+SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id FROM provisioner_jobs WHERE created_at > $1
+`
+
+func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJob
+	for rows.Next() {
+		var i ProvisionerJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.StorageSource,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerJob = `-- name: InsertProvisionerJob :one
+INSERT INTO
+	provisioner_jobs (
+		id,
+		created_at,
+		updated_at,
+		organization_id,
+		initiator_id,
+		provisioner,
+		storage_method,
+		storage_source,
+		"type",
+		"input"
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+`
+
+type InsertProvisionerJobParams struct {
+	ID             uuid.UUID                `db:"id" json:"id"`
+	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
+	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
+	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
+	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
+	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
+	StorageSource  string                   `db:"storage_source" json:"storage_source"`
+	Type           ProvisionerJobType       `db:"type" json:"type"`
+	Input          json.RawMessage          `db:"input" json:"input"`
+}
+
+func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.OrganizationID,
+		arg.InitiatorID,
+		arg.Provisioner,
+		arg.StorageMethod,
+		arg.StorageSource,
+		arg.Type,
+		arg.Input,
+	)
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.StorageSource,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+	)
+	return i, err
+}
+
+const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	canceled_at = $2,
+	completed_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCancelByIDParams struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
+	return err
+}
+
+const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	completed_at = $3,
+	error = $4
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCompleteByIDParams struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
+	Error       sql.NullString `db:"error" json:"error"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+		arg.Error,
+	)
+	return err
+}
+
+const updateProvisionerJobsOrganizationByWorkspaceID = `-- name: UpdateProvisionerJobsOrganizationByWorkspaceID :exec
+UPDATE
+	provisioner_jobs
+SET
+	organization_id = $1
+WHERE
+	id IN (
+		SELECT
+			job_id
+		FROM
+			workspace_builds
+		WHERE
+			workspace_id = $2
+	)
+`
+
+type UpdateProvisionerJobsOrganizationByWorkspaceIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	WorkspaceID    uuid.UUID `db:"workspace_id" json:"workspace_id"`
+}
+
+// Moves the jobs of a workspace's builds along with the workspace.
+func (q *sqlQuerier) UpdateProvisionerJobsOrganizationByWorkspaceID(ctx context.Context, arg UpdateProvisionerJobsOrganizationByWorkspaceIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobsOrganizationByWorkspaceID, arg.OrganizationID, arg.WorkspaceID)
+	return err
+}
+
+const getPendingRoleRequests = `-- name: GetPendingRoleRequests :many
+SELECT
+	id, user_id, organization_id, role, reason, status, created_at, reviewed_by, reviewed_at
+FROM
+	role_requests
+WHERE
+	status = 'pending'
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetPendingRoleRequests(ctx context.Context) ([]RoleRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingRoleRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleRequest
+	for rows.Next() {
+		var i RoleRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Role,
+			&i.Reason,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoleRequestByID = `-- name: GetRoleRequestByID :one
+SELECT
+	id, user_id, organization_id, role, reason, status, created_at, reviewed_by, reviewed_at
+FROM
+	role_requests
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetRoleRequestByID(ctx context.Context, id uuid.UUID) (RoleRequest, error) {
+	row := q.db.QueryRowContext(ctx, getRoleRequestByID, id)
+	var i RoleRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.Role,
+		&i.Reason,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const getRoleRequestsByUserID = `-- name: GetRoleRequestsByUserID :many
+SELECT
+	id, user_id, organization_id, role, reason, status, created_at, reviewed_by, reviewed_at
+FROM
+	role_requests
+WHERE
+	user_id = $1
+ORDER BY
+	created_at DESC
+`
+
+func (q *sqlQuerier) GetRoleRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]RoleRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getRoleRequestsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleRequest
+	for rows.Next() {
+		var i RoleRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Role,
+			&i.Reason,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertRoleRequest = `-- name: InsertRoleRequest :one
+INSERT INTO role_requests (
+	id,
+	user_id,
+	organization_id,
+	role,
+	reason,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING id, user_id, organization_id, role, reason, status, created_at, reviewed_by, reviewed_at
+`
+
+type InsertRoleRequestParams struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	UserID         uuid.UUID     `db:"user_id" json:"user_id"`
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	Role           string        `db:"role" json:"role"`
+	Reason         string        `db:"reason" json:"reason"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertRoleRequest(ctx context.Context, arg InsertRoleRequestParams) (RoleRequest, error) {
+	row := q.db.QueryRowContext(ctx,
+		insertRoleRequest,
+		arg.ID,
+		arg.UserID,
+		arg.OrganizationID,
+		arg.Role,
+		arg.Reason,
+		arg.CreatedAt,
+	)
+	var i RoleRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.Role,
+		&i.Reason,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const updateRoleRequestStatus = `-- name: UpdateRoleRequestStatus :one
+UPDATE
+	role_requests
+SET
+	status = $1,
+	reviewed_by = $2,
+	reviewed_at = $3
+WHERE
+	id = $4
+	AND status = 'pending'
+RETURNING id, user_id, organization_id, role, reason, status, created_at, reviewed_by, reviewed_at
+`
+
+type UpdateRoleRequestStatusParams struct {
+	Status     RoleRequestStatus `db:"status" json:"status"`
+	ReviewedBy uuid.NullUUID     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt sql.NullTime      `db:"reviewed_at" json:"reviewed_at"`
+	ID         uuid.UUID         `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateRoleRequestStatus(ctx context.Context, arg UpdateRoleRequestStatusParams) (RoleRequest, error) {
+	row := q.db.QueryRowContext(ctx, updateRoleRequestStatus,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewedAt,
+		arg.ID,
+	)
+	var i RoleRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.Role,
+		&i.Reason,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const getDeploymentID = `-- name: GetDeploymentID :one
+SELECT value FROM site_configs WHERE key = 'deployment_id'
+`
+
+func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getDeploymentID)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getMaintenance = `-- name: GetMaintenance :one
+SELECT value FROM site_configs WHERE key = 'maintenance'
+`
+
+func (q *sqlQuerier) GetMaintenance(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getMaintenance)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const insertDeploymentID = `-- name: InsertDeploymentID :exec
+INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+`
+
+func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
+	return err
+}
+
+const upsertMaintenance = `-- name: UpsertMaintenance :exec
+INSERT INTO site_configs (key, value) VALUES ('maintenance', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1
+`
+
+func (q *sqlQuerier) UpsertMaintenance(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, upsertMaintenance, value)
+	return err
+}
+
+const getTemplateArchivePolicies = `-- name: GetTemplateArchivePolicies :many
+SELECT
+	template_id, dormancy_threshold, retention, updated_at
+FROM
+	template_archive_policies
+`
+
+func (q *sqlQuerier) GetTemplateArchivePolicies(ctx context.Context) ([]TemplateArchivePolicy, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateArchivePolicies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateArchivePolicy
+	for rows.Next() {
+		var i TemplateArchivePolicy
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.DormancyThreshold,
+			&i.Retention,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateArchivePolicyByTemplateID = `-- name: GetTemplateArchivePolicyByTemplateID :one
+SELECT
+	template_id, dormancy_threshold, retention, updated_at
+FROM
+	template_archive_policies
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateArchivePolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateArchivePolicy, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateArchivePolicyByTemplateID, templateID)
+	var i TemplateArchivePolicy
+	err := row.Scan(
+		&i.TemplateID,
+		&i.DormancyThreshold,
+		&i.Retention,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-func (q *sqlQuerier) InsertParameterSchema(ctx context.Context, arg InsertParameterSchemaParams) (ParameterSchema, error) {
-	row := q.db.QueryRowContext(ctx, insertParameterSchema,
-		arg.ID,
-		arg.CreatedAt,
-		arg.JobID,
-		arg.Name,
-		arg.Description,
-		arg.DefaultSourceScheme,
-		arg.DefaultSourceValue,
-		arg.AllowOverrideSource,
-		arg.DefaultDestinationScheme,
-		arg.AllowOverrideDestination,
-		arg.DefaultRefresh,
-		arg.RedisplayValue,
-		arg.ValidationError,
-		arg.ValidationCondition,
-		arg.ValidationTypeSystem,
-		arg.ValidationValueType,
-		arg.Index,
+const upsertTemplateArchivePolicy = `-- name: UpsertTemplateArchivePolicy :one
+INSERT INTO
+	template_archive_policies (template_id, dormancy_threshold, retention, updated_at)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (template_id) DO UPDATE SET
+	dormancy_threshold = $2,
+	retention = $3,
+	updated_at = $4
+RETURNING template_id, dormancy_threshold, retention, updated_at
+`
+
+type UpsertTemplateArchivePolicyParams struct {
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	DormancyThreshold int64     `db:"dormancy_threshold" json:"dormancy_threshold"`
+	Retention         int64     `db:"retention" json:"retention"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplateArchivePolicy(ctx context.Context, arg UpsertTemplateArchivePolicyParams) (TemplateArchivePolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateArchivePolicy,
+		arg.TemplateID,
+		arg.DormancyThreshold,
+		arg.Retention,
+		arg.UpdatedAt,
 	)
-	var i ParameterSchema
+	var i TemplateArchivePolicy
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.JobID,
-		&i.Name,
-		&i.Description,
-		&i.DefaultSourceScheme,
-		&i.DefaultSourceValue,
-		&i.AllowOverrideSource,
-		&i.DefaultDestinationScheme,
-		&i.AllowOverrideDestination,
-		&i.DefaultRefresh,
-		&i.RedisplayValue,
-		&i.ValidationError,
-		&i.ValidationCondition,
-		&i.ValidationTypeSystem,
-		&i.ValidationValueType,
-		&i.Index,
+		&i.TemplateID,
+		&i.DormancyThreshold,
+		&i.Retention,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const deleteParameterValueByID = `-- name: DeleteParameterValueByID :exec
+const getTemplateAutoRebuildPolicies = `-- name: GetTemplateAutoRebuildPolicies :many
+SELECT
+	template_id, failure_threshold, agent_timeout, action, updated_at
+FROM
+	template_auto_rebuild_policies
+`
+
+func (q *sqlQuerier) GetTemplateAutoRebuildPolicies(ctx context.Context) ([]TemplateAutoRebuildPolicy, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateAutoRebuildPolicies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateAutoRebuildPolicy
+	for rows.Next() {
+		var i TemplateAutoRebuildPolicy
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.FailureThreshold,
+			&i.AgentTimeout,
+			&i.Action,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateAutoRebuildPolicyByTemplateID = `-- name: GetTemplateAutoRebuildPolicyByTemplateID :one
+SELECT
+	template_id, failure_threshold, agent_timeout, action, updated_at
+FROM
+	template_auto_rebuild_policies
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateAutoRebuildPolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateAutoRebuildPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateAutoRebuildPolicyByTemplateID, templateID)
+	var i TemplateAutoRebuildPolicy
+	err := row.Scan(
+		&i.TemplateID,
+		&i.FailureThreshold,
+		&i.AgentTimeout,
+		&i.Action,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertTemplateAutoRebuildPolicy = `-- name: UpsertTemplateAutoRebuildPolicy :one
+INSERT INTO
+	template_auto_rebuild_policies (
+		template_id,
+		failure_threshold,
+		agent_timeout,
+		action,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	failure_threshold = $2,
+	agent_timeout = $3,
+	action = $4,
+	updated_at = $5
+RETURNING template_id, failure_threshold, agent_timeout, action, updated_at
+`
+
+type UpsertTemplateAutoRebuildPolicyParams struct {
+	TemplateID       uuid.UUID         `db:"template_id" json:"template_id"`
+	FailureThreshold int32             `db:"failure_threshold" json:"failure_threshold"`
+	AgentTimeout     int64             `db:"agent_timeout" json:"agent_timeout"`
+	Action           AutoRebuildAction `db:"action" json:"action"`
+	UpdatedAt        time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplateAutoRebuildPolicy(ctx context.Context, arg UpsertTemplateAutoRebuildPolicyParams) (TemplateAutoRebuildPolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateAutoRebuildPolicy,
+		arg.TemplateID,
+		arg.FailureThreshold,
+		arg.AgentTimeout,
+		arg.Action,
+		arg.UpdatedAt,
+	)
+	var i TemplateAutoRebuildPolicy
+	err := row.Scan(
+		&i.TemplateID,
+		&i.FailureThreshold,
+		&i.AgentTimeout,
+		&i.Action,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteTemplateAutostopExtensionPolicyByTemplateID = `-- name: DeleteTemplateAutostopExtensionPolicyByTemplateID :exec
 DELETE FROM
-	parameter_values
+	template_autostop_extension_policies
 WHERE
-	id = $1
+	template_id = $1
 `
 
-func (q *sqlQuerier) DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteParameterValueByID, id)
+func (q *sqlQuerier) DeleteTemplateAutostopExtensionPolicyByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateAutostopExtensionPolicyByTemplateID, templateID)
 	return err
 }
 
-const getParameterValueByScopeAndName = `-- name: GetParameterValueByScopeAndName :one
+const getTemplateAutostopExtensionPolicyByTemplateID = `-- name: GetTemplateAutostopExtensionPolicyByTemplateID :one
 SELECT
-	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+	template_id, max_extensions_per_day, max_extension_per_day, updated_at
 FROM
-	parameter_values
+	template_autostop_extension_policies
 WHERE
-	scope = $1
-	AND scope_id = $2
-	AND NAME = $3
-LIMIT
-	1
+	template_id = $1
 `
 
-type GetParameterValueByScopeAndNameParams struct {
-	Scope   ParameterScope `db:"scope" json:"scope"`
-	ScopeID uuid.UUID      `db:"scope_id" json:"scope_id"`
-	Name    string         `db:"name" json:"name"`
+func (q *sqlQuerier) GetTemplateAutostopExtensionPolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateAutostopExtensionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateAutostopExtensionPolicyByTemplateID, templateID)
+	var i TemplateAutostopExtensionPolicy
+	err := row.Scan(
+		&i.TemplateID,
+		&i.MaxExtensionsPerDay,
+		&i.MaxExtensionPerDay,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-func (q *sqlQuerier) GetParameterValueByScopeAndName(ctx context.Context, arg GetParameterValueByScopeAndNameParams) (ParameterValue, error) {
-	row := q.db.QueryRowContext(ctx, getParameterValueByScopeAndName, arg.Scope, arg.ScopeID, arg.Name)
-	var i ParameterValue
+const upsertTemplateAutostopExtensionPolicy = `-- name: UpsertTemplateAutostopExtensionPolicy :one
+INSERT INTO
+	template_autostop_extension_policies (template_id, max_extensions_per_day, max_extension_per_day, updated_at)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (template_id) DO UPDATE SET
+	max_extensions_per_day = $2,
+	max_extension_per_day = $3,
+	updated_at = $4
+RETURNING template_id, max_extensions_per_day, max_extension_per_day, updated_at
+`
+
+type UpsertTemplateAutostopExtensionPolicyParams struct {
+	TemplateID          uuid.UUID `db:"template_id" json:"template_id"`
+	MaxExtensionsPerDay int32     `db:"max_extensions_per_day" json:"max_extensions_per_day"`
+	MaxExtensionPerDay  int64     `db:"max_extension_per_day" json:"max_extension_per_day"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplateAutostopExtensionPolicy(ctx context.Context, arg UpsertTemplateAutostopExtensionPolicyParams) (TemplateAutostopExtensionPolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateAutostopExtensionPolicy,
+		arg.TemplateID,
+		arg.MaxExtensionsPerDay,
+		arg.MaxExtensionPerDay,
+		arg.UpdatedAt,
+	)
+	var i TemplateAutostopExtensionPolicy
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
+		&i.TemplateID,
+		&i.MaxExtensionsPerDay,
+		&i.MaxExtensionPerDay,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteTemplateDeprecationByTemplateID = `-- name: DeleteTemplateDeprecationByTemplateID :exec
+DELETE FROM
+	template_deprecations
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) DeleteTemplateDeprecationByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateDeprecationByTemplateID, templateID)
+	return err
+}
+
+const getTemplateDeprecationByTemplateID = `-- name: GetTemplateDeprecationByTemplateID :one
+SELECT
+	template_id, message, successor_id, parameter_mapping, deprecated_at, updated_at
+FROM
+	template_deprecations
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateDeprecationByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateDeprecation, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateDeprecationByTemplateID, templateID)
+	var i TemplateDeprecation
+	err := row.Scan(
+		&i.TemplateID,
+		&i.Message,
+		&i.SuccessorID,
+		&i.ParameterMapping,
+		&i.DeprecatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTemplateDeprecationsByTemplateIDs = `-- name: GetTemplateDeprecationsByTemplateIDs :many
+SELECT
+	template_id, message, successor_id, parameter_mapping, deprecated_at, updated_at
+FROM
+	template_deprecations
+WHERE
+	template_id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetTemplateDeprecationsByTemplateIDs(ctx context.Context, templateIds []uuid.UUID) ([]TemplateDeprecation, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateDeprecationsByTemplateIDs, pq.Array(templateIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateDeprecation
+	for rows.Next() {
+		var i TemplateDeprecation
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.Message,
+			&i.SuccessorID,
+			&i.ParameterMapping,
+			&i.DeprecatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTemplateDeprecation = `-- name: UpsertTemplateDeprecation :one
+INSERT INTO
+	template_deprecations (
+		template_id,
+		message,
+		successor_id,
+		parameter_mapping,
+		deprecated_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	message = $2,
+	successor_id = $3,
+	parameter_mapping = $4,
+	updated_at = $5
+RETURNING template_id, message, successor_id, parameter_mapping, deprecated_at, updated_at
+`
+
+type UpsertTemplateDeprecationParams struct {
+	TemplateID       uuid.UUID       `db:"template_id" json:"template_id"`
+	Message          string          `db:"message" json:"message"`
+	SuccessorID      uuid.NullUUID   `db:"successor_id" json:"successor_id"`
+	ParameterMapping json.RawMessage `db:"parameter_mapping" json:"parameter_mapping"`
+	DeprecatedAt     time.Time       `db:"deprecated_at" json:"deprecated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplateDeprecation(ctx context.Context, arg UpsertTemplateDeprecationParams) (TemplateDeprecation, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateDeprecation,
+		arg.TemplateID,
+		arg.Message,
+		arg.SuccessorID,
+		arg.ParameterMapping,
+		arg.DeprecatedAt,
+	)
+	var i TemplateDeprecation
+	err := row.Scan(
+		&i.TemplateID,
+		&i.Message,
+		&i.SuccessorID,
+		&i.ParameterMapping,
+		&i.DeprecatedAt,
 		&i.UpdatedAt,
-		&i.Scope,
-		&i.ScopeID,
-		&i.Name,
-		&i.SourceScheme,
-		&i.SourceValue,
-		&i.DestinationScheme,
 	)
 	return i, err
 }
 
-const insertParameterValue = `-- name: InsertParameterValue :one
+const deleteTemplateFavoritesByUserID = `-- name: DeleteTemplateFavoritesByUserID :exec
+DELETE FROM
+	template_favorites
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteTemplateFavoritesByUserID(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateFavoritesByUserID, userID)
+	return err
+}
+
+const getTemplateFavoritesByUserID = `-- name: GetTemplateFavoritesByUserID :many
+SELECT
+	template_favorites.user_id, template_favorites.template_id, template_favorites.position, template_favorites.created_at
+FROM
+	template_favorites
+JOIN
+	templates ON templates.id = template_favorites.template_id
+WHERE
+	template_favorites.user_id = $1
+	AND templates.deleted = false
+ORDER BY
+	template_favorites.position ASC
+`
+
+func (q *sqlQuerier) GetTemplateFavoritesByUserID(ctx context.Context, userID uuid.UUID) ([]TemplateFavorite, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateFavoritesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateFavorite
+	for rows.Next() {
+		var i TemplateFavorite
+		if err := rows.Scan(
+			&i.UserID,
+			&i.TemplateID,
+			&i.Position,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateFavorite = `-- name: InsertTemplateFavorite :one
 INSERT INTO
-	parameter_values (
-		id,
-		"name",
-		created_at,
-		updated_at,
-		scope,
-		scope_id,
-		source_scheme,
-		source_value,
-		destination_scheme
+	template_favorites (
+		user_id,
+		template_id,
+		position,
+		created_at
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+	($1, $2, $3, $4)
+RETURNING user_id, template_id, position, created_at
 `
 
-type InsertParameterValueParams struct {
-	ID                uuid.UUID                  `db:"id" json:"id"`
-	Name              string                     `db:"name" json:"name"`
-	CreatedAt         time.Time                  `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time                  `db:"updated_at" json:"updated_at"`
-	Scope             ParameterScope             `db:"scope" json:"scope"`
-	ScopeID           uuid.UUID                  `db:"scope_id" json:"scope_id"`
-	SourceScheme      ParameterSourceScheme      `db:"source_scheme" json:"source_scheme"`
-	SourceValue       string                     `db:"source_value" json:"source_value"`
-	DestinationScheme ParameterDestinationScheme `db:"destination_scheme" json:"destination_scheme"`
+type InsertTemplateFavoriteParams struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	Position   int32     `db:"position" json:"position"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
-func (q *sqlQuerier) InsertParameterValue(ctx context.Context, arg InsertParameterValueParams) (ParameterValue, error) {
-	row := q.db.QueryRowContext(ctx, insertParameterValue,
-		arg.ID,
-		arg.Name,
+func (q *sqlQuerier) InsertTemplateFavorite(ctx context.Context, arg InsertTemplateFavoriteParams) (TemplateFavorite, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateFavorite,
+		arg.UserID,
+		arg.TemplateID,
+		arg.Position,
 		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Scope,
-		arg.ScopeID,
-		arg.SourceScheme,
-		arg.SourceValue,
-		arg.DestinationScheme,
 	)
-	var i ParameterValue
+	var i TemplateFavorite
 	err := row.Scan(
-		&i.ID,
+		&i.UserID,
+		&i.TemplateID,
+		&i.Position,
 		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Scope,
-		&i.ScopeID,
-		&i.Name,
-		&i.SourceScheme,
-		&i.SourceValue,
-		&i.DestinationScheme,
 	)
 	return i, err
 }
 
-const parameterValue = `-- name: ParameterValue :one
-SELECT id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme FROM
-	parameter_values
+const deleteTemplateMaintenanceWindowByTemplateID = `-- name: DeleteTemplateMaintenanceWindowByTemplateID :exec
+DELETE FROM
+	template_maintenance_windows
 WHERE
-	id = $1
+	template_id = $1
 `
 
-func (q *sqlQuerier) ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error) {
-	row := q.db.QueryRowContext(ctx, parameterValue, id)
-	var i ParameterValue
+func (q *sqlQuerier) DeleteTemplateMaintenanceWindowByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateMaintenanceWindowByTemplateID, templateID)
+	return err
+}
+
+const getTemplateMaintenanceWindowByTemplateID = `-- name: GetTemplateMaintenanceWindowByTemplateID :one
+SELECT
+	template_id, schedule, duration, exempt_group_ids, updated_at
+FROM
+	template_maintenance_windows
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateMaintenanceWindowByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateMaintenanceWindow, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateMaintenanceWindowByTemplateID, templateID)
+	var i TemplateMaintenanceWindow
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
+		&i.TemplateID,
+		&i.Schedule,
+		&i.Duration,
+		pq.Array(&i.ExemptGroupIds),
 		&i.UpdatedAt,
-		&i.Scope,
-		&i.ScopeID,
-		&i.Name,
-		&i.SourceScheme,
-		&i.SourceValue,
-		&i.DestinationScheme,
 	)
 	return i, err
 }
 
-const parameterValues = `-- name: ParameterValues :many
+const getTemplateMaintenanceWindows = `-- name: GetTemplateMaintenanceWindows :many
 SELECT
-	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+	template_maintenance_windows.template_id, template_maintenance_windows.schedule, template_maintenance_windows.duration, template_maintenance_windows.exempt_group_ids, template_maintenance_windows.updated_at
 FROM
-	parameter_values
+	template_maintenance_windows
+JOIN
+	templates
+ON
+	templates.id = template_maintenance_windows.template_id
 WHERE
-  	CASE
-		  WHEN cardinality($1 :: parameter_scope[]) > 0 THEN
-				  scope = ANY($1 :: parameter_scope[])
-		  ELSE true
-	END
-    AND CASE
-		WHEN cardinality($2 :: uuid[]) > 0 THEN
-			scope_id = ANY($2 :: uuid[])
-		ELSE true
-	END
-  	AND CASE
-		WHEN cardinality($3 :: uuid[]) > 0 THEN
-			id = ANY($3 :: uuid[])
-		ELSE true
-	END
-  	AND CASE
-		  WHEN cardinality($4 :: text[]) > 0 THEN
-				  "name" = ANY($4 :: text[])
-		  ELSE true
-	END
+	templates.deleted = false
 `
 
-type ParameterValuesParams struct {
-	Scopes   []ParameterScope `db:"scopes" json:"scopes"`
-	ScopeIds []uuid.UUID      `db:"scope_ids" json:"scope_ids"`
-	IDs      []uuid.UUID      `db:"ids" json:"ids"`
-	Names    []string         `db:"names" json:"names"`
-}
-
-func (q *sqlQuerier) ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error) {
-	rows, err := q.db.QueryContext(ctx, parameterValues,
-		pq.Array(arg.Scopes),
-		pq.Array(arg.ScopeIds),
-		pq.Array(arg.IDs),
-		pq.Array(arg.Names),
-	)
+// Returns the maintenance windows of templates that aren't deleted.
+func (q *sqlQuerier) GetTemplateMaintenanceWindows(ctx context.Context) ([]TemplateMaintenanceWindow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateMaintenanceWindows)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ParameterValue
+	var items []TemplateMaintenanceWindow
 	for rows.Next() {
-		var i ParameterValue
+		var i TemplateMaintenanceWindow
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
+			&i.TemplateID,
+			&i.Schedule,
+			&i.Duration,
+			pq.Array(&i.ExemptGroupIds),
 			&i.UpdatedAt,
-			&i.Scope,
-			&i.ScopeID,
-			&i.Name,
-			&i.SourceScheme,
-			&i.SourceValue,
-			&i.DestinationScheme,
 		); err != nil {
 			return nil, err
 		}
@@ -1983,50 +7092,30 @@ func (q *sqlQuerier) ParameterValues(ctx context.Context, arg ParameterValuesPar
 	return items, nil
 }
 
-const getProvisionerDaemonByID = `-- name: GetProvisionerDaemonByID :one
+const getTemplateMaintenanceWindowsByTemplateIDs = `-- name: GetTemplateMaintenanceWindowsByTemplateIDs :many
 SELECT
-	id, created_at, updated_at, name, provisioners
+	template_id, schedule, duration, exempt_group_ids, updated_at
 FROM
-	provisioner_daemons
+	template_maintenance_windows
 WHERE
-	id = $1
-`
-
-func (q *sqlQuerier) GetProvisionerDaemonByID(ctx context.Context, id uuid.UUID) (ProvisionerDaemon, error) {
-	row := q.db.QueryRowContext(ctx, getProvisionerDaemonByID, id)
-	var i ProvisionerDaemon
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		pq.Array(&i.Provisioners),
-	)
-	return i, err
-}
-
-const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
-SELECT
-	id, created_at, updated_at, name, provisioners
-FROM
-	provisioner_daemons
+	template_id = ANY($1 :: uuid [ ])
 `
 
-func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerDaemons)
+func (q *sqlQuerier) GetTemplateMaintenanceWindowsByTemplateIDs(ctx context.Context, templateIds []uuid.UUID) ([]TemplateMaintenanceWindow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateMaintenanceWindowsByTemplateIDs, pq.Array(templateIds))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerDaemon
+	var items []TemplateMaintenanceWindow
 	for rows.Next() {
-		var i ProvisionerDaemon
+		var i TemplateMaintenanceWindow
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
+			&i.TemplateID,
+			&i.Schedule,
+			&i.Duration,
+			pq.Array(&i.ExemptGroupIds),
 			&i.UpdatedAt,
-			&i.Name,
-			pq.Array(&i.Provisioners),
 		); err != nil {
 			return nil, err
 		}
@@ -2041,103 +7130,82 @@ func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDa
 	return items, nil
 }
 
-const insertProvisionerDaemon = `-- name: InsertProvisionerDaemon :one
+const upsertTemplateMaintenanceWindow = `-- name: UpsertTemplateMaintenanceWindow :one
 INSERT INTO
-	provisioner_daemons (
-		id,
-		created_at,
-		"name",
-		provisioners
-	)
+	template_maintenance_windows (template_id, schedule, duration, exempt_group_ids, updated_at)
 VALUES
-	($1, $2, $3, $4) RETURNING id, created_at, updated_at, name, provisioners
+	($1, $2, $3, $4, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	schedule = $2,
+	duration = $3,
+	exempt_group_ids = $4,
+	updated_at = $5
+RETURNING template_id, schedule, duration, exempt_group_ids, updated_at
 `
 
-type InsertProvisionerDaemonParams struct {
-	ID           uuid.UUID         `db:"id" json:"id"`
-	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
-	Name         string            `db:"name" json:"name"`
-	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
+type UpsertTemplateMaintenanceWindowParams struct {
+	TemplateID     uuid.UUID   `db:"template_id" json:"template_id"`
+	Schedule       string      `db:"schedule" json:"schedule"`
+	Duration       int64       `db:"duration" json:"duration"`
+	ExemptGroupIds []uuid.UUID `db:"exempt_group_ids" json:"exempt_group_ids"`
+	UpdatedAt      time.Time   `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerDaemon,
-		arg.ID,
-		arg.CreatedAt,
-		arg.Name,
-		pq.Array(arg.Provisioners),
+func (q *sqlQuerier) UpsertTemplateMaintenanceWindow(ctx context.Context, arg UpsertTemplateMaintenanceWindowParams) (TemplateMaintenanceWindow, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateMaintenanceWindow,
+		arg.TemplateID,
+		arg.Schedule,
+		arg.Duration,
+		pq.Array(arg.ExemptGroupIds),
+		arg.UpdatedAt,
 	)
-	var i ProvisionerDaemon
+	var i TemplateMaintenanceWindow
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
+		&i.TemplateID,
+		&i.Schedule,
+		&i.Duration,
+		pq.Array(&i.ExemptGroupIds),
 		&i.UpdatedAt,
-		&i.Name,
-		pq.Array(&i.Provisioners),
 	)
 	return i, err
 }
 
-const updateProvisionerDaemonByID = `-- name: UpdateProvisionerDaemonByID :exec
-UPDATE
-	provisioner_daemons
-SET
-	updated_at = $2,
-	provisioners = $3
+const deleteTemplateResourceCostsByTemplateID = `-- name: DeleteTemplateResourceCostsByTemplateID :exec
+DELETE FROM
+	template_resource_costs
 WHERE
-	id = $1
+	template_id = $1
 `
 
-type UpdateProvisionerDaemonByIDParams struct {
-	ID           uuid.UUID         `db:"id" json:"id"`
-	UpdatedAt    sql.NullTime      `db:"updated_at" json:"updated_at"`
-	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
-}
-
-func (q *sqlQuerier) UpdateProvisionerDaemonByID(ctx context.Context, arg UpdateProvisionerDaemonByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerDaemonByID, arg.ID, arg.UpdatedAt, pq.Array(arg.Provisioners))
+func (q *sqlQuerier) DeleteTemplateResourceCostsByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateResourceCostsByTemplateID, templateID)
 	return err
 }
 
-const getProvisionerLogsByIDBetween = `-- name: GetProvisionerLogsByIDBetween :many
+const getTemplateResourceCosts = `-- name: GetTemplateResourceCosts :many
 SELECT
-	id, job_id, created_at, source, level, stage, output
+	template_resource_costs.template_id, template_resource_costs.resource_type, template_resource_costs.hourly_cost
 FROM
-	provisioner_job_logs
+	template_resource_costs
+JOIN
+	templates
+ON
+	templates.id = template_resource_costs.template_id
 WHERE
-	job_id = $1
-	AND (
-		created_at >= $2
-		OR created_at <= $3
-	)
-ORDER BY
-	created_at DESC
+	templates.deleted = false
 `
-
-type GetProvisionerLogsByIDBetweenParams struct {
-	JobID         uuid.UUID `db:"job_id" json:"job_id"`
-	CreatedAfter  time.Time `db:"created_after" json:"created_after"`
-	CreatedBefore time.Time `db:"created_before" json:"created_before"`
-}
-
-func (q *sqlQuerier) GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerLogsByIDBetween, arg.JobID, arg.CreatedAfter, arg.CreatedBefore)
+
+// Returns the resource costs of templates that aren't deleted.
+func (q *sqlQuerier) GetTemplateResourceCosts(ctx context.Context) ([]TemplateResourceCost, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateResourceCosts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJobLog
+	var items []TemplateResourceCost
 	for rows.Next() {
-		var i ProvisionerJobLog
-		if err := rows.Scan(
-			&i.ID,
-			&i.JobID,
-			&i.CreatedAt,
-			&i.Source,
-			&i.Level,
-			&i.Stage,
-			&i.Output,
-		); err != nil {
+		var i TemplateResourceCost
+		if err := rows.Scan(&i.TemplateID, &i.ResourceType, &i.HourlyCost); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -2151,55 +7219,27 @@ func (q *sqlQuerier) GetProvisionerLogsByIDBetween(ctx context.Context, arg GetP
 	return items, nil
 }
 
-const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
-INSERT INTO
-	provisioner_job_logs
+const getTemplateResourceCostsByTemplateID = `-- name: GetTemplateResourceCostsByTemplateID :many
 SELECT
-	unnest($1 :: uuid [ ]) AS id,
-	$2 :: uuid AS job_id,
-	unnest($3 :: timestamptz [ ]) AS created_at,
-	unnest($4 :: log_source [ ]) AS source,
-	unnest($5 :: log_level [ ]) AS LEVEL,
-	unnest($6 :: VARCHAR(128) [ ]) AS stage,
-	unnest($7 :: VARCHAR(1024) [ ]) AS output RETURNING id, job_id, created_at, source, level, stage, output
+	template_id, resource_type, hourly_cost
+FROM
+	template_resource_costs
+WHERE
+	template_id = $1
+ORDER BY
+	resource_type ASC
 `
 
-type InsertProvisionerJobLogsParams struct {
-	ID        []uuid.UUID `db:"id" json:"id"`
-	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
-	CreatedAt []time.Time `db:"created_at" json:"created_at"`
-	Source    []LogSource `db:"source" json:"source"`
-	Level     []LogLevel  `db:"level" json:"level"`
-	Stage     []string    `db:"stage" json:"stage"`
-	Output    []string    `db:"output" json:"output"`
-}
-
-func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, insertProvisionerJobLogs,
-		pq.Array(arg.ID),
-		arg.JobID,
-		pq.Array(arg.CreatedAt),
-		pq.Array(arg.Source),
-		pq.Array(arg.Level),
-		pq.Array(arg.Stage),
-		pq.Array(arg.Output),
-	)
+func (q *sqlQuerier) GetTemplateResourceCostsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]TemplateResourceCost, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateResourceCostsByTemplateID, templateID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJobLog
+	var items []TemplateResourceCost
 	for rows.Next() {
-		var i ProvisionerJobLog
-		if err := rows.Scan(
-			&i.ID,
-			&i.JobID,
-			&i.CreatedAt,
-			&i.Source,
-			&i.Level,
-			&i.Stage,
-			&i.Output,
-		); err != nil {
+		var i TemplateResourceCost
+		if err := rows.Scan(&i.TemplateID, &i.ResourceType, &i.HourlyCost); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -2213,134 +7253,200 @@ func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertPro
 	return items, nil
 }
 
-const acquireProvisionerJob = `-- name: AcquireProvisionerJob :one
-UPDATE
-	provisioner_jobs
-SET
-	started_at = $1,
-	updated_at = $1,
-	worker_id = $2
+const insertTemplateResourceCost = `-- name: InsertTemplateResourceCost :exec
+INSERT INTO
+	template_resource_costs (template_id, resource_type, hourly_cost)
+VALUES
+	($1, $2, $3)
+`
+
+type InsertTemplateResourceCostParams struct {
+	TemplateID   uuid.UUID `db:"template_id" json:"template_id"`
+	ResourceType string    `db:"resource_type" json:"resource_type"`
+	HourlyCost   float64   `db:"hourly_cost" json:"hourly_cost"`
+}
+
+func (q *sqlQuerier) InsertTemplateResourceCost(ctx context.Context, arg InsertTemplateResourceCostParams) error {
+	_, err := q.db.ExecContext(ctx, insertTemplateResourceCost, arg.TemplateID, arg.ResourceType, arg.HourlyCost)
+	return err
+}
+
+const deleteTemplatesByOrganizationID = `-- name: DeleteTemplatesByOrganizationID :many
+DELETE FROM
+	templates
 WHERE
-	id = (
+	id IN (
 		SELECT
 			id
 		FROM
-			provisioner_jobs AS nested
+			templates
 		WHERE
-			nested.started_at IS NULL
-			AND nested.canceled_at IS NULL
-			AND nested.completed_at IS NULL
-			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
-		ORDER BY
-			nested.created_at FOR
-		UPDATE
-			SKIP LOCKED
+			organization_id = $1
 		LIMIT
-			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+			$2
+	)
+RETURNING id
 `
 
-type AcquireProvisionerJobParams struct {
-	StartedAt sql.NullTime      `db:"started_at" json:"started_at"`
-	WorkerID  uuid.NullUUID     `db:"worker_id" json:"worker_id"`
-	Types     []ProvisionerType `db:"types" json:"types"`
+type DeleteTemplatesByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	LimitOpt       int32     `db:"limit_opt" json:"limit_opt"`
 }
 
-// Acquires the lock for a single job that isn't started, completed,
-// canceled, and that matches an array of provisioner types.
-//
-// SKIP LOCKED is used to jump over locked rows. This prevents
-// multiple provisioners from acquiring the same jobs. See:
-// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
-func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, acquireProvisionerJob, arg.StartedAt, arg.WorkerID, pq.Array(arg.Types))
-	var i ProvisionerJob
+// Removes a batch of templates along with their versions. The workspaces of
+// the templates must be removed first.
+func (q *sqlQuerier) DeleteTemplatesByOrganizationID(ctx context.Context, arg DeleteTemplatesByOrganizationIDParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteTemplatesByOrganizationID, arg.OrganizationID, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateByID = `-- name: GetTemplateByID :one
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels
+FROM
+	templates
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
+		&i.Deleted,
+		&i.Name,
 		&i.Provisioner,
-		&i.StorageMethod,
-		&i.StorageSource,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.MaxTtl,
+		&i.MinAutostartInterval,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.userACL,
+		&i.groupACL,
+		&i.QuotaWeight,
+		&i.WorkspaceLabels,
 	)
 	return i, err
 }
 
-const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
+const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels
 FROM
-	provisioner_jobs
+	templates
 WHERE
-	id = $1
+	organization_id = $1
+	AND deleted = $2
+	AND LOWER("name") = LOWER($3)
+LIMIT
+	1
 `
 
-func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
-	var i ProvisionerJob
+type GetTemplateByOrganizationAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Deleted        bool      `db:"deleted" json:"deleted"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
+		&i.Deleted,
+		&i.Name,
 		&i.Provisioner,
-		&i.StorageMethod,
-		&i.StorageSource,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.MaxTtl,
+		&i.MinAutostartInterval,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.userACL,
+		&i.groupACL,
+		&i.QuotaWeight,
+		&i.WorkspaceLabels,
 	)
 	return i, err
 }
 
-const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
+const getTemplateCountByOrganizationID = `-- name: GetTemplateCountByOrganizationID :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+	COUNT(id)
 FROM
-	provisioner_jobs
+	templates
 WHERE
-	id = ANY($1 :: uuid [ ])
+	organization_id = $1
 `
 
-func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsByIDs, pq.Array(ids))
+// Counts deleted templates too, they're kept until the organization is deleted.
+func (q *sqlQuerier) GetTemplateCountByOrganizationID(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateCountByOrganizationID, organizationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getTemplates = `-- name: GetTemplates :many
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels FROM templates
+ORDER BY (name, id) ASC
+`
+
+func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplates)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJob
+	var items []Template
 	for rows.Next() {
-		var i ProvisionerJob
+		var i Template
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.StartedAt,
-			&i.CanceledAt,
-			&i.CompletedAt,
-			&i.Error,
 			&i.OrganizationID,
-			&i.InitiatorID,
+			&i.Deleted,
+			&i.Name,
 			&i.Provisioner,
-			&i.StorageMethod,
-			&i.StorageSource,
-			&i.Type,
-			&i.Input,
-			&i.WorkerID,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.MaxTtl,
+			&i.MinAutostartInterval,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.userACL,
+			&i.groupACL,
+			&i.QuotaWeight,
+			&i.WorkspaceLabels,
 		); err != nil {
 			return nil, err
 		}
@@ -2355,35 +7461,103 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 	return items, nil
 }
 
-const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
-SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id FROM provisioner_jobs WHERE created_at > $1
+const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels
+FROM
+	templates
+WHERE
+	-- Optionally include deleted templates
+	templates.deleted = $1
+	-- Filter by organization_id
+	AND CASE
+		WHEN $2 :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_id = $2
+		ELSE true
+	END
+	-- Filter by exact name
+	AND CASE
+		WHEN $3 :: text != '' THEN
+			LOWER("name") = LOWER($3)
+		ELSE true
+	END
+	-- Filter by name, matching on substring
+	AND CASE
+		WHEN $4 :: text != '' THEN
+			"name" ILIKE concat('%', $4, '%')
+		ELSE true
+	END
+	-- Filter by ids
+	AND CASE
+		WHEN array_length($5 :: uuid[], 1) > 0 THEN
+			id = ANY($5)
+		ELSE true
+	END
+	-- This allows using the last element on a page as effectively a cursor.
+	AND CASE
+		WHEN $6 :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(name, id) > (
+				SELECT
+					name, id
+				FROM
+					templates
+				WHERE
+					id = $6
+			)
+		)
+		ELSE true
+	END
+ORDER BY (name, id) ASC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($7 :: int, 0)
 `
 
-func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
+type GetTemplatesWithFilterParams struct {
+	Deleted        bool        `db:"deleted" json:"deleted"`
+	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
+	ExactName      string      `db:"exact_name" json:"exact_name"`
+	FuzzyName      string      `db:"fuzzy_name" json:"fuzzy_name"`
+	IDs            []uuid.UUID `db:"ids" json:"ids"`
+	AfterID        uuid.UUID   `db:"after_id" json:"after_id"`
+	LimitOpt       int32       `db:"limit_opt" json:"limit_opt"`
+}
+
+func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplatesWithFilter,
+		arg.Deleted,
+		arg.OrganizationID,
+		arg.ExactName,
+		arg.FuzzyName,
+		pq.Array(arg.IDs),
+		arg.AfterID,
+		arg.LimitOpt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJob
+	var items []Template
 	for rows.Next() {
-		var i ProvisionerJob
+		var i Template
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.StartedAt,
-			&i.CanceledAt,
-			&i.CompletedAt,
-			&i.Error,
 			&i.OrganizationID,
-			&i.InitiatorID,
+			&i.Deleted,
+			&i.Name,
 			&i.Provisioner,
-			&i.StorageMethod,
-			&i.StorageSource,
-			&i.Type,
-			&i.Input,
-			&i.WorkerID,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.MaxTtl,
+			&i.MinAutostartInterval,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.userACL,
+			&i.groupACL,
+			&i.QuotaWeight,
+			&i.WorkspaceLabels,
 		); err != nil {
 			return nil, err
 		}
@@ -2398,172 +7572,219 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 	return items, nil
 }
 
-const insertProvisionerJob = `-- name: InsertProvisionerJob :one
+const insertTemplate = `-- name: InsertTemplate :one
 INSERT INTO
-	provisioner_jobs (
+	templates (
 		id,
 		created_at,
 		updated_at,
 		organization_id,
-		initiator_id,
+		"name",
 		provisioner,
-		storage_method,
-		storage_source,
-		"type",
-		"input"
+		active_version_id,
+		description,
+		max_ttl,
+		min_autostart_interval,
+		created_by,
+		icon
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, storage_source, type, input, worker_id
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels
 `
 
-type InsertProvisionerJobParams struct {
-	ID             uuid.UUID                `db:"id" json:"id"`
-	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
-	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
-	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
-	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
-	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
-	StorageSource  string                   `db:"storage_source" json:"storage_source"`
-	Type           ProvisionerJobType       `db:"type" json:"type"`
-	Input          json.RawMessage          `db:"input" json:"input"`
+type InsertTemplateParams struct {
+	ID                   uuid.UUID       `db:"id" json:"id"`
+	CreatedAt            time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time       `db:"updated_at" json:"updated_at"`
+	OrganizationID       uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Name                 string          `db:"name" json:"name"`
+	Provisioner          ProvisionerType `db:"provisioner" json:"provisioner"`
+	ActiveVersionID      uuid.UUID       `db:"active_version_id" json:"active_version_id"`
+	Description          string          `db:"description" json:"description"`
+	MaxTtl               int64           `db:"max_ttl" json:"max_ttl"`
+	MinAutostartInterval int64           `db:"min_autostart_interval" json:"min_autostart_interval"`
+	CreatedBy            uuid.UUID       `db:"created_by" json:"created_by"`
+	Icon                 string          `db:"icon" json:"icon"`
 }
 
-func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplate,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.OrganizationID,
-		arg.InitiatorID,
+		arg.Name,
 		arg.Provisioner,
-		arg.StorageMethod,
-		arg.StorageSource,
-		arg.Type,
-		arg.Input,
+		arg.ActiveVersionID,
+		arg.Description,
+		arg.MaxTtl,
+		arg.MinAutostartInterval,
+		arg.CreatedBy,
+		arg.Icon,
 	)
-	var i ProvisionerJob
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
+		&i.Deleted,
+		&i.Name,
 		&i.Provisioner,
-		&i.StorageMethod,
-		&i.StorageSource,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.MaxTtl,
+		&i.MinAutostartInterval,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.userACL,
+		&i.groupACL,
+		&i.QuotaWeight,
+		&i.WorkspaceLabels,
 	)
 	return i, err
 }
 
-const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
+const updateTemplateActiveVersionByID = `-- name: UpdateTemplateActiveVersionByID :exec
 UPDATE
-	provisioner_jobs
+	templates
 SET
-	updated_at = $2
+	active_version_id = $2,
+	updated_at = $3
 WHERE
 	id = $1
 `
 
-type UpdateProvisionerJobByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+type UpdateTemplateActiveVersionByIDParams struct {
+	ID              uuid.UUID `db:"id" json:"id"`
+	ActiveVersionID uuid.UUID `db:"active_version_id" json:"active_version_id"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
+func (q *sqlQuerier) UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateActiveVersionByID, arg.ID, arg.ActiveVersionID, arg.UpdatedAt)
 	return err
 }
 
-const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
+const updateTemplateDeletedByID = `-- name: UpdateTemplateDeletedByID :exec
 UPDATE
-	provisioner_jobs
+	templates
 SET
-	canceled_at = $2,
-	completed_at = $3
+	deleted = $2,
+	updated_at = $3
 WHERE
 	id = $1
 `
 
-type UpdateProvisionerJobWithCancelByIDParams struct {
-	ID          uuid.UUID    `db:"id" json:"id"`
-	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
-	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+type UpdateTemplateDeletedByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Deleted   bool      `db:"deleted" json:"deleted"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
+func (q *sqlQuerier) UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateDeletedByID, arg.ID, arg.Deleted, arg.UpdatedAt)
 	return err
 }
 
-const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+const updateTemplateMetaByID = `-- name: UpdateTemplateMetaByID :one
 UPDATE
-	provisioner_jobs
+	templates
 SET
 	updated_at = $2,
-	completed_at = $3,
-	error = $4
+	description = $3,
+	max_ttl = $4,
+	min_autostart_interval = $5,
+	name = $6,
+	icon = $7
 WHERE
 	id = $1
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels
 `
 
-type UpdateProvisionerJobWithCompleteByIDParams struct {
-	ID          uuid.UUID      `db:"id" json:"id"`
-	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
-	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
-	Error       sql.NullString `db:"error" json:"error"`
+type UpdateTemplateMetaByIDParams struct {
+	ID                   uuid.UUID `db:"id" json:"id"`
+	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
+	Description          string    `db:"description" json:"description"`
+	MaxTtl               int64     `db:"max_ttl" json:"max_ttl"`
+	MinAutostartInterval int64     `db:"min_autostart_interval" json:"min_autostart_interval"`
+	Name                 string    `db:"name" json:"name"`
+	Icon                 string    `db:"icon" json:"icon"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
+func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateMetaByID,
 		arg.ID,
 		arg.UpdatedAt,
-		arg.CompletedAt,
-		arg.Error,
+		arg.Description,
+		arg.MaxTtl,
+		arg.MinAutostartInterval,
+		arg.Name,
+		arg.Icon,
 	)
-	return err
-}
-
-const getDeploymentID = `-- name: GetDeploymentID :one
-SELECT value FROM site_configs WHERE key = 'deployment_id'
-`
-
-func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getDeploymentID)
-	var value string
-	err := row.Scan(&value)
-	return value, err
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.MaxTtl,
+		&i.MinAutostartInterval,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.userACL,
+		&i.groupACL,
+		&i.QuotaWeight,
+		&i.WorkspaceLabels,
+	)
+	return i, err
 }
 
-const insertDeploymentID = `-- name: InsertDeploymentID :exec
-INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+const updateTemplateQuotaWeightByID = `-- name: UpdateTemplateQuotaWeightByID :exec
+UPDATE
+	templates
+SET
+	quota_weight = $2
+WHERE
+	id = $1
 `
 
-func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
+type UpdateTemplateQuotaWeightByIDParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	QuotaWeight int32     `db:"quota_weight" json:"quota_weight"`
+}
+
+func (q *sqlQuerier) UpdateTemplateQuotaWeightByID(ctx context.Context, arg UpdateTemplateQuotaWeightByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateQuotaWeightByID, arg.ID, arg.QuotaWeight)
 	return err
 }
 
-const getTemplateByID = `-- name: GetTemplateByID :one
-SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl
-FROM
+const updateTemplateWorkspaceLabelsByID = `-- name: UpdateTemplateWorkspaceLabelsByID :one
+UPDATE
 	templates
+SET
+	workspace_labels = $2,
+	updated_at = $3
 WHERE
 	id = $1
-LIMIT
-	1
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl, quota_weight, workspace_labels
 `
 
-func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
+type UpdateTemplateWorkspaceLabelsByIDParams struct {
+	ID              uuid.UUID       `db:"id" json:"id"`
+	WorkspaceLabels json.RawMessage `db:"workspace_labels" json:"workspace_labels"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateTemplateWorkspaceLabelsByID(ctx context.Context, arg UpdateTemplateWorkspaceLabelsByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateWorkspaceLabelsByID, arg.ID, arg.WorkspaceLabels, arg.UpdatedAt)
 	var i Template
 	err := row.Scan(
 		&i.ID,
@@ -2581,82 +7802,162 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.Icon,
 		&i.userACL,
 		&i.groupACL,
+		&i.QuotaWeight,
+		&i.WorkspaceLabels,
 	)
 	return i, err
 }
 
-const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
+const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
 FROM
-	templates
+	template_versions
 WHERE
-	organization_id = $1
-	AND deleted = $2
-	AND LOWER("name") = LOWER($3)
-LIMIT
-	1
+	id = $1
 `
 
-type GetTemplateByOrganizationAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Deleted        bool      `db:"deleted" json:"deleted"`
-	Name           string    `db:"name" json:"name"`
+func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionByID, id)
+	var i TemplateVersion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Readme,
+		&i.JobID,
+		&i.CreatedBy,
+	)
+	return i, err
 }
 
-func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
-	var i Template
+const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
+SELECT
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+FROM
+	template_versions
+WHERE
+	job_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionByJobID, jobID)
+	var i TemplateVersion
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Name,
+		&i.Readme,
+		&i.JobID,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
+SELECT
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+FROM
+	template_versions
+WHERE
+	template_id = $1
+	AND "name" = $2
+`
+
+type GetTemplateVersionByTemplateIDAndNameParams struct {
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	Name       string        `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionByTemplateIDAndName, arg.TemplateID, arg.Name)
+	var i TemplateVersion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
 		&i.OrganizationID,
-		&i.Deleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.MaxTtl,
-		&i.MinAutostartInterval,
+		&i.Readme,
+		&i.JobID,
 		&i.CreatedBy,
-		&i.Icon,
-		&i.userACL,
-		&i.groupACL,
 	)
 	return i, err
 }
 
-const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl FROM templates
-ORDER BY (name, id) ASC
+const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
+SELECT
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+FROM
+	template_versions
+WHERE
+	template_id = $1 :: uuid
+	AND CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		-- This is an important option for scripts that need to paginate without
+		-- duplicating or missing data.
+		WHEN $2 :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			-- The pagination cursor is the last ID of the previous page.
+			-- The query is ordered by the created_at field, so select all
+			-- rows after the cursor.
+			(created_at, id) > (
+				SELECT
+					created_at, id
+				FROM
+					template_versions
+				WHERE
+					id = $2
+			)
+		)
+		ELSE true
+	END
+ORDER BY
+    -- Deterministic and consistent ordering of all rows, even if they share
+    -- a timestamp. This is to ensure consistent pagination.
+	(created_at, id) ASC OFFSET $3
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($4 :: int, 0)
 `
 
-func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplates)
+type GetTemplateVersionsByTemplateIDParams struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	AfterID    uuid.UUID `db:"after_id" json:"after_id"`
+	OffsetOpt  int32     `db:"offset_opt" json:"offset_opt"`
+	LimitOpt   int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsByTemplateID,
+		arg.TemplateID,
+		arg.AfterID,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Template
+	var items []TemplateVersion
 	for rows.Next() {
-		var i Template
+		var i TemplateVersion
 		if err := rows.Scan(
 			&i.ID,
+			&i.TemplateID,
+			&i.OrganizationID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.OrganizationID,
-			&i.Deleted,
 			&i.Name,
-			&i.Provisioner,
-			&i.ActiveVersionID,
-			&i.Description,
-			&i.MaxTtl,
-			&i.MinAutostartInterval,
+			&i.Readme,
+			&i.JobID,
 			&i.CreatedBy,
-			&i.Icon,
-			&i.userACL,
-			&i.groupACL,
 		); err != nil {
 			return nil, err
 		}
@@ -2671,72 +7972,29 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 	return items, nil
 }
 
-const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
-SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl
-FROM
-	templates
-WHERE
-	-- Optionally include deleted templates
-	templates.deleted = $1
-	-- Filter by organization_id
-	AND CASE
-		WHEN $2 :: uuid != '00000000-00000000-00000000-00000000' THEN
-			organization_id = $2
-		ELSE true
-	END
-	-- Filter by exact name
-	AND CASE
-		WHEN $3 :: text != '' THEN
-			LOWER("name") = LOWER($3)
-		ELSE true
-	END
-	-- Filter by ids
-	AND CASE
-		WHEN array_length($4 :: uuid[], 1) > 0 THEN
-			id = ANY($4)
-		ELSE true
-	END
-ORDER BY (name, id) ASC
+const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
+SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by FROM template_versions WHERE created_at > $1
 `
 
-type GetTemplatesWithFilterParams struct {
-	Deleted        bool        `db:"deleted" json:"deleted"`
-	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
-	ExactName      string      `db:"exact_name" json:"exact_name"`
-	IDs            []uuid.UUID `db:"ids" json:"ids"`
-}
-
-func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplatesWithFilter,
-		arg.Deleted,
-		arg.OrganizationID,
-		arg.ExactName,
-		pq.Array(arg.IDs),
-	)
+func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsCreatedAfter, createdAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Template
+	var items []TemplateVersion
 	for rows.Next() {
-		var i Template
+		var i TemplateVersion
 		if err := rows.Scan(
 			&i.ID,
+			&i.TemplateID,
+			&i.OrganizationID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.OrganizationID,
-			&i.Deleted,
 			&i.Name,
-			&i.Provisioner,
-			&i.ActiveVersionID,
-			&i.Description,
-			&i.MaxTtl,
-			&i.MinAutostartInterval,
+			&i.Readme,
+			&i.JobID,
 			&i.CreatedBy,
-			&i.Icon,
-			&i.userACL,
-			&i.groupACL,
 		); err != nil {
 			return nil, err
 		}
@@ -2751,267 +8009,577 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 	return items, nil
 }
 
-const insertTemplate = `-- name: InsertTemplate :one
+const insertTemplateVersion = `-- name: InsertTemplateVersion :one
 INSERT INTO
-	templates (
+	template_versions (
 		id,
-		created_at,
-		updated_at,
+		template_id,
 		organization_id,
-		"name",
-		provisioner,
-		active_version_id,
-		description,
-		max_ttl,
-		min_autostart_interval,
-		created_by,
-		icon
+		created_at,
+		updated_at,
+		"name",
+		readme,
+		job_id,
+		created_by
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
 `
 
-type InsertTemplateParams struct {
-	ID                   uuid.UUID       `db:"id" json:"id"`
-	CreatedAt            time.Time       `db:"created_at" json:"created_at"`
-	UpdatedAt            time.Time       `db:"updated_at" json:"updated_at"`
-	OrganizationID       uuid.UUID       `db:"organization_id" json:"organization_id"`
-	Name                 string          `db:"name" json:"name"`
-	Provisioner          ProvisionerType `db:"provisioner" json:"provisioner"`
-	ActiveVersionID      uuid.UUID       `db:"active_version_id" json:"active_version_id"`
-	Description          string          `db:"description" json:"description"`
-	MaxTtl               int64           `db:"max_ttl" json:"max_ttl"`
-	MinAutostartInterval int64           `db:"min_autostart_interval" json:"min_autostart_interval"`
-	CreatedBy            uuid.UUID       `db:"created_by" json:"created_by"`
-	Icon                 string          `db:"icon" json:"icon"`
+type InsertTemplateVersionParams struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+	Name           string        `db:"name" json:"name"`
+	Readme         string        `db:"readme" json:"readme"`
+	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
+	CreatedBy      uuid.NullUUID `db:"created_by" json:"created_by"`
 }
 
-func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplate,
+func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersion,
 		arg.ID,
+		arg.TemplateID,
+		arg.OrganizationID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.OrganizationID,
 		arg.Name,
-		arg.Provisioner,
-		arg.ActiveVersionID,
-		arg.Description,
-		arg.MaxTtl,
-		arg.MinAutostartInterval,
+		arg.Readme,
+		arg.JobID,
 		arg.CreatedBy,
-		arg.Icon,
 	)
-	var i Template
+	var i TemplateVersion
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
 		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.MaxTtl,
-		&i.MinAutostartInterval,
+		&i.Readme,
+		&i.JobID,
 		&i.CreatedBy,
-		&i.Icon,
-		&i.userACL,
-		&i.groupACL,
 	)
 	return i, err
 }
 
-const updateTemplateActiveVersionByID = `-- name: UpdateTemplateActiveVersionByID :exec
+const updateTemplateVersionByID = `-- name: UpdateTemplateVersionByID :exec
 UPDATE
-	templates
+	template_versions
 SET
-	active_version_id = $2,
+	template_id = $2,
 	updated_at = $3
 WHERE
 	id = $1
 `
 
-type UpdateTemplateActiveVersionByIDParams struct {
-	ID              uuid.UUID `db:"id" json:"id"`
-	ActiveVersionID uuid.UUID `db:"active_version_id" json:"active_version_id"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+type UpdateTemplateVersionByIDParams struct {
+	ID         uuid.UUID     `db:"id" json:"id"`
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateActiveVersionByID, arg.ID, arg.ActiveVersionID, arg.UpdatedAt)
+func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionByID, arg.ID, arg.TemplateID, arg.UpdatedAt)
 	return err
 }
 
-const updateTemplateDeletedByID = `-- name: UpdateTemplateDeletedByID :exec
+const updateTemplateVersionDescriptionByJobID = `-- name: UpdateTemplateVersionDescriptionByJobID :exec
 UPDATE
-	templates
+	template_versions
 SET
-	deleted = $2,
+	readme = $2,
 	updated_at = $3
 WHERE
-	id = $1
+	job_id = $1
 `
 
-type UpdateTemplateDeletedByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Deleted   bool      `db:"deleted" json:"deleted"`
+type UpdateTemplateVersionDescriptionByJobIDParams struct {
+	JobID     uuid.UUID `db:"job_id" json:"job_id"`
+	Readme    string    `db:"readme" json:"readme"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateDeletedByID, arg.ID, arg.Deleted, arg.UpdatedAt)
+func (q *sqlQuerier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionDescriptionByJobID, arg.JobID, arg.Readme, arg.UpdatedAt)
 	return err
 }
 
-const updateTemplateMetaByID = `-- name: UpdateTemplateMetaByID :one
+const getTemplateWorkspaceNamePolicyByTemplateID = `-- name: GetTemplateWorkspaceNamePolicyByTemplateID :one
+SELECT
+	template_id, pattern, prefix, reserved_names, updated_at
+FROM
+	template_workspace_name_policies
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateWorkspaceNamePolicyByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateWorkspaceNamePolicy, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateWorkspaceNamePolicyByTemplateID, templateID)
+	var i TemplateWorkspaceNamePolicy
+	err := row.Scan(
+		&i.TemplateID,
+		&i.Pattern,
+		&i.Prefix,
+		pq.Array(&i.ReservedNames),
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertTemplateWorkspaceNamePolicy = `-- name: UpsertTemplateWorkspaceNamePolicy :one
+INSERT INTO
+	template_workspace_name_policies (
+		template_id,
+		pattern,
+		prefix,
+		reserved_names,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	pattern = $2,
+	prefix = $3,
+	reserved_names = $4,
+	updated_at = $5
+RETURNING template_id, pattern, prefix, reserved_names, updated_at
+`
+
+type UpsertTemplateWorkspaceNamePolicyParams struct {
+	TemplateID    uuid.UUID `db:"template_id" json:"template_id"`
+	Pattern       string    `db:"pattern" json:"pattern"`
+	Prefix        string    `db:"prefix" json:"prefix"`
+	ReservedNames []string  `db:"reserved_names" json:"reserved_names"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplateWorkspaceNamePolicy(ctx context.Context, arg UpsertTemplateWorkspaceNamePolicyParams) (TemplateWorkspaceNamePolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateWorkspaceNamePolicy,
+		arg.TemplateID,
+		arg.Pattern,
+		arg.Prefix,
+		pq.Array(arg.ReservedNames),
+		arg.UpdatedAt,
+	)
+	var i TemplateWorkspaceNamePolicy
+	err := row.Scan(
+		&i.TemplateID,
+		&i.Pattern,
+		&i.Prefix,
+		pq.Array(&i.ReservedNames),
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserLinkByLinkedID = `-- name: GetUserLinkByLinkedID :one
+SELECT
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+FROM
+	user_links
+WHERE
+	linked_id = $1
+`
+
+func (q *sqlQuerier) GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, getUserLinkByLinkedID, linkedID)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const getUserLinkByUserIDLoginType = `-- name: GetUserLinkByUserIDLoginType :one
+SELECT
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+FROM
+	user_links
+WHERE
+	user_id = $1 AND login_type = $2
+`
+
+type GetUserLinkByUserIDLoginTypeParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType LoginType `db:"login_type" json:"login_type"`
+}
+
+func (q *sqlQuerier) GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, getUserLinkByUserIDLoginType, arg.UserID, arg.LoginType)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const insertUserLink = `-- name: InsertUserLink :one
+INSERT INTO
+	user_links (
+		user_id,
+		login_type,
+		linked_id,
+		oauth_access_token,
+		oauth_refresh_token,
+		oauth_expiry
+	)
+VALUES
+	( $1, $2, $3, $4, $5, $6 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+`
+
+type InsertUserLinkParams struct {
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType         LoginType `db:"login_type" json:"login_type"`
+	LinkedID          string    `db:"linked_id" json:"linked_id"`
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+}
+
+func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, insertUserLink,
+		arg.UserID,
+		arg.LoginType,
+		arg.LinkedID,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+	)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const updateUserLink = `-- name: UpdateUserLink :one
+UPDATE
+	user_links
+SET
+	oauth_access_token = $1,
+	oauth_refresh_token = $2,
+	oauth_expiry = $3
+WHERE
+	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+`
+
+type UpdateUserLinkParams struct {
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType         LoginType `db:"login_type" json:"login_type"`
+}
+
+func (q *sqlQuerier) UpdateUserLink(ctx context.Context, arg UpdateUserLinkParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLink,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+		arg.UserID,
+		arg.LoginType,
+	)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const updateUserLinkedID = `-- name: UpdateUserLinkedID :one
 UPDATE
-	templates
+	user_links
 SET
-	updated_at = $2,
-	description = $3,
-	max_ttl = $4,
-	min_autostart_interval = $5,
-	name = $6,
-	icon = $7
+	linked_id = $1
 WHERE
-	id = $1
-RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, max_ttl, min_autostart_interval, created_by, icon, user_acl, group_acl
+	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
 `
 
-type UpdateTemplateMetaByIDParams struct {
-	ID                   uuid.UUID `db:"id" json:"id"`
-	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
-	Description          string    `db:"description" json:"description"`
-	MaxTtl               int64     `db:"max_ttl" json:"max_ttl"`
-	MinAutostartInterval int64     `db:"min_autostart_interval" json:"min_autostart_interval"`
-	Name                 string    `db:"name" json:"name"`
-	Icon                 string    `db:"icon" json:"icon"`
+type UpdateUserLinkedIDParams struct {
+	LinkedID  string    `db:"linked_id" json:"linked_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType LoginType `db:"login_type" json:"login_type"`
 }
 
-func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, updateTemplateMetaByID,
-		arg.ID,
-		arg.UpdatedAt,
-		arg.Description,
-		arg.MaxTtl,
-		arg.MinAutostartInterval,
-		arg.Name,
-		arg.Icon,
-	)
-	var i Template
+func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLinkedID, arg.LinkedID, arg.UserID, arg.LoginType)
+	var i UserLink
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.MaxTtl,
-		&i.MinAutostartInterval,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.userACL,
-		&i.groupACL,
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
+const getActiveUserCount = `-- name: GetActiveUserCount :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	COUNT(*)
 FROM
-	template_versions
+	users
 WHERE
-	id = $1
+    status = 'active'::public.user_status AND deleted = false
 `
 
-func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateVersionByID, id)
-	var i TemplateVersion
+func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getActiveUserCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getAuthorizationUserRoles = `-- name: GetAuthorizationUserRoles :one
+WITH RECURSIVE user_groups AS (
+	-- All groups the user is in, including the ancestors of those groups
+	-- so permissions granted to a parent group are inherited.
+	SELECT
+		group_members.group_id AS id
+	FROM
+		group_members
+	JOIN
+		groups
+	ON
+		groups.id = group_members.group_id
+	WHERE
+		group_members.user_id = $1
+	AND
+		(group_members.expires_at IS NULL OR group_members.expires_at > NOW())
+	AND
+		groups.deleted_at IS NULL
+	UNION
+	SELECT
+		parents.id
+	FROM
+		groups
+	JOIN
+		user_groups
+	ON
+		groups.id = user_groups.id
+	JOIN
+		groups parents
+	ON
+		parents.id = groups.parent_id
+	WHERE
+		-- Deleted groups grant nothing, including to the groups
+		-- nested beneath them.
+		parents.deleted_at IS NULL
+)
+SELECT
+	-- username is returned just to help for logging purposes
+	-- status is used to enforce 'suspended' users, as all roles are ignored
+	--	when suspended.
+	id, username, status,
+	-- All user roles, including their org roles and the roles of their groups.
+	array_cat(
+		array_cat(
+			-- All users are members
+			array_append(users.rbac_roles, 'member'),
+			(
+				SELECT
+					array_agg(org_roles)
+				FROM
+					organization_members,
+					-- All org_members get the org-member role for their orgs
+					unnest(
+						array_append(roles, 'organization-member:' || organization_members.organization_id::text)
+					) AS org_roles
+				WHERE
+					user_id = users.id
+			)
+		),
+		-- Roles assigned to the user's groups. Organization groups only grant
+		-- their roles while the user is a member of the organization.
+		(
+			SELECT
+				array_agg(group_roles)
+			FROM
+				groups,
+				unnest(groups.roles) AS group_roles
+			WHERE
+				groups.id IN (SELECT user_groups.id FROM user_groups)
+			AND
+				(
+					groups.organization_id IS NULL
+					OR EXISTS (
+						SELECT
+							1
+						FROM
+							organization_members
+						WHERE
+							organization_members.organization_id = groups.organization_id
+						AND
+							organization_members.user_id = users.id
+					)
+				)
+		)
+	) :: text[] AS roles,
+	(
+		SELECT
+			array_agg(
+				user_groups.id :: text
+			)
+		FROM
+			user_groups
+	) :: text[] AS groups,
+	-- The organizations whose "Everyone" group the user is excluded from, so
+	-- the group's ACL entries don't apply to them.
+	(
+		SELECT
+			array_agg(
+				everyone_group_exclusions.organization_id :: text
+			)
+		FROM
+			everyone_group_exclusions
+		WHERE
+			user_id = users.id
+	) :: text[] AS everyone_excluded_orgs
+FROM
+	users
+WHERE
+	id = $1;
+`
+
+type GetAuthorizationUserRolesRow struct {
+	ID                   uuid.UUID  `db:"id" json:"id"`
+	Username             string     `db:"username" json:"username"`
+	Status               UserStatus `db:"status" json:"status"`
+	Roles                []string   `db:"roles" json:"roles"`
+	Groups               []string   `db:"groups" json:"groups"`
+	EveryoneExcludedOrgs []string   `db:"everyone_excluded_orgs" json:"everyone_excluded_orgs"`
+}
+
+// This function returns roles for authorization purposes. Implied member roles
+// are included.
+func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error) {
+	row := q.db.QueryRowContext(ctx, getAuthorizationUserRoles, userID)
+	var i GetAuthorizationUserRolesRow
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
+		&i.Username,
+		&i.Status,
+		pq.Array(&i.Roles),
+		pq.Array(&i.Groups),
+		pq.Array(&i.EveryoneExcludedOrgs),
 	)
 	return i, err
 }
 
-const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
+const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 FROM
-	template_versions
+	users
 WHERE
-	job_id = $1
+	(LOWER(username) = LOWER($1) OR LOWER(email) = LOWER($2))
+	AND deleted = $3
+LIMIT
+	1
 `
 
-func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateVersionByJobID, jobID)
-	var i TemplateVersion
+type GetUserByEmailOrUsernameParams struct {
+	Username string `db:"username" json:"username"`
+	Email    string `db:"email" json:"email"`
+	Deleted  bool   `db:"deleted" json:"deleted"`
+}
+
+func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmailOrUsername, arg.Username, arg.Email, arg.Deleted)
+	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
+const getUserByID = `-- name: GetUserByID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 FROM
-	template_versions
+	users
 WHERE
-	template_id = $1
-	AND "name" = $2
+	id = $1
+LIMIT
+	1
 `
 
-type GetTemplateVersionByTemplateIDAndNameParams struct {
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	Name       string        `db:"name" json:"name"`
-}
-
-func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateVersionByTemplateIDAndName, arg.TemplateID, arg.Name)
-	var i TemplateVersion
+func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
+const getUserCount = `-- name: GetUserCount :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+	COUNT(*)
 FROM
-	template_versions
+	users WHERE deleted = false
+`
+
+func (q *sqlQuerier) GetUserCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getUsers = `-- name: GetUsers :many
+SELECT
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+FROM
+	users
 WHERE
-	template_id = $1 :: uuid
+	users.deleted = $1
 	AND CASE
 		-- This allows using the last element on a page as effectively a cursor.
 		-- This is an important option for scripts that need to paginate without
@@ -3024,33 +8592,65 @@ WHERE
 				SELECT
 					created_at, id
 				FROM
-					template_versions
+					users
 				WHERE
 					id = $2
 			)
 		)
 		ELSE true
 	END
+	-- Start filters
+	-- Filter by name, email or username
+	AND CASE
+		WHEN $3 :: text != '' THEN (
+			email ILIKE concat('%', $3, '%')
+			OR username ILIKE concat('%', $3, '%')
+		)
+		ELSE true
+	END
+	-- Filter by status
+	AND CASE
+		-- @status needs to be a text because it can be empty, If it was
+		-- user_status enum, it would not.
+		WHEN cardinality($4 :: user_status[]) > 0 THEN
+			status = ANY($4 :: user_status[])
+		ELSE true
+	END
+	-- Filter by rbac_roles
+	AND CASE
+		-- @rbac_role allows filtering by rbac roles. If 'member' is included, show everyone, as
+	    -- everyone is a member.
+		WHEN cardinality($5 :: text[]) > 0 AND 'member' != ANY($5 :: text[]) THEN
+		    rbac_roles && $5 :: text[]
+		ELSE true
+	END
+	-- End of filters
 ORDER BY
-    -- Deterministic and consistent ordering of all rows, even if they share
-    -- a timestamp. This is to ensure consistent pagination.
-	(created_at, id) ASC OFFSET $3
+	-- Deterministic and consistent ordering of all users, even if they share
+	-- a timestamp. This is to ensure consistent pagination.
+	(created_at, id) ASC OFFSET $6
 LIMIT
 	-- A null limit means "no limit", so 0 means return all
-	NULLIF($4 :: int, 0)
+	NULLIF($7 :: int, 0)
 `
 
-type GetTemplateVersionsByTemplateIDParams struct {
-	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
-	AfterID    uuid.UUID `db:"after_id" json:"after_id"`
-	OffsetOpt  int32     `db:"offset_opt" json:"offset_opt"`
-	LimitOpt   int32     `db:"limit_opt" json:"limit_opt"`
+type GetUsersParams struct {
+	Deleted   bool         `db:"deleted" json:"deleted"`
+	AfterID   uuid.UUID    `db:"after_id" json:"after_id"`
+	Search    string       `db:"search" json:"search"`
+	Status    []UserStatus `db:"status" json:"status"`
+	RbacRole  []string     `db:"rbac_role" json:"rbac_role"`
+	OffsetOpt int32        `db:"offset_opt" json:"offset_opt"`
+	LimitOpt  int32        `db:"limit_opt" json:"limit_opt"`
 }
 
-func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionsByTemplateID,
-		arg.TemplateID,
+func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsers,
+		arg.Deleted,
 		arg.AfterID,
+		arg.Search,
+		pq.Array(arg.Status),
+		pq.Array(arg.RbacRole),
 		arg.OffsetOpt,
 		arg.LimitOpt,
 	)
@@ -3058,19 +8658,22 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TemplateVersion
+	var items []User
 	for rows.Next() {
-		var i TemplateVersion
+		var i User
 		if err := rows.Scan(
 			&i.ID,
-			&i.TemplateID,
-			&i.OrganizationID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Name,
-			&i.Readme,
-			&i.JobID,
-			&i.CreatedBy,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3085,29 +8688,84 @@ func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg Ge
 	return items, nil
 }
 
-const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
-SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by FROM template_versions WHERE created_at > $1
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at FROM users WHERE id = ANY($1 :: uuid [ ])
 `
 
-func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionsCreatedAfter, createdAt)
+// This shouldn't check for deleted, because it's frequently used
+// to look up references to actions. eg. a user could build a workspace
+// for another user, then be deleted... we still want them to appear!
+func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TemplateVersion
+	var items []User
 	for rows.Next() {
-		var i TemplateVersion
+		var i User
 		if err := rows.Scan(
 			&i.ID,
-			&i.TemplateID,
-			&i.OrganizationID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Name,
-			&i.Readme,
-			&i.JobID,
-			&i.CreatedBy,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByUsernamesOrEmails = `-- name: GetUsersByUsernamesOrEmails :many
+SELECT
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+FROM
+	users
+WHERE
+	deleted = false
+	AND (
+		LOWER(username) = ANY($1 :: text [ ])
+		OR LOWER(email) = ANY($1 :: text [ ])
+	)
+`
+
+func (q *sqlQuerier) GetUsersByUsernamesOrEmails(ctx context.Context, identifiers []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByUsernamesOrEmails, pq.Array(identifiers))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3122,530 +8780,804 @@ func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, create
 	return items, nil
 }
 
-const insertTemplateVersion = `-- name: InsertTemplateVersion :one
-INSERT INTO
-	template_versions (
-		id,
-		template_id,
-		organization_id,
-		created_at,
-		updated_at,
-		"name",
-		readme,
-		job_id,
-		created_by
-	)
-VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by
+const insertUser = `-- name: InsertUser :one
+INSERT INTO
+	users (
+		id,
+		email,
+		username,
+		hashed_password,
+		created_at,
+		updated_at,
+		rbac_roles,
+		login_type
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+`
+
+type InsertUserParams struct {
+	ID             uuid.UUID      `db:"id" json:"id"`
+	Email          string         `db:"email" json:"email"`
+	Username       string         `db:"username" json:"username"`
+	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType      LoginType      `db:"login_type" json:"login_type"`
+}
+
+func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertUser,
+		arg.ID,
+		arg.Email,
+		arg.Username,
+		arg.HashedPassword,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.RBACRoles,
+		arg.LoginType,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+	)
+	return i, err
+}
+
+const updateUserDeletedByID = `-- name: UpdateUserDeletedByID :exec
+UPDATE
+	users
+SET
+	deleted = $2
+WHERE
+	id = $1
+`
+
+type UpdateUserDeletedByIDParams struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Deleted bool      `db:"deleted" json:"deleted"`
+}
+
+func (q *sqlQuerier) UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserDeletedByID, arg.ID, arg.Deleted)
+	return err
+}
+
+const updateUserHashedPassword = `-- name: UpdateUserHashedPassword :exec
+UPDATE
+	users
+SET
+	hashed_password = $2
+WHERE
+	id = $1
+`
+
+type UpdateUserHashedPasswordParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	HashedPassword []byte    `db:"hashed_password" json:"hashed_password"`
+}
+
+func (q *sqlQuerier) UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserHashedPassword, arg.ID, arg.HashedPassword)
+	return err
+}
+
+const updateUserLastSeenAt = `-- name: UpdateUserLastSeenAt :one
+UPDATE
+	users
+SET
+	last_seen_at = $2,
+	updated_at = $3
+WHERE
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+`
+
+type UpdateUserLastSeenAtParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLastSeenAtParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLastSeenAt, arg.ID, arg.LastSeenAt, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE
+	users
+SET
+	email = $2,
+	username = $3,
+	avatar_url = $4,
+	updated_at = $5
+WHERE
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-type InsertTemplateVersionParams struct {
-	ID             uuid.UUID     `db:"id" json:"id"`
-	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
-	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
-	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
-	Name           string        `db:"name" json:"name"`
-	Readme         string        `db:"readme" json:"readme"`
-	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
-	CreatedBy      uuid.NullUUID `db:"created_by" json:"created_by"`
+type UpdateUserProfileParams struct {
+	ID        uuid.UUID      `db:"id" json:"id"`
+	Email     string         `db:"email" json:"email"`
+	Username  string         `db:"username" json:"username"`
+	AvatarURL sql.NullString `db:"avatar_url" json:"avatar_url"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplateVersion,
+func (q *sqlQuerier) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
 		arg.ID,
-		arg.TemplateID,
-		arg.OrganizationID,
-		arg.CreatedAt,
+		arg.Email,
+		arg.Username,
+		arg.AvatarURL,
 		arg.UpdatedAt,
-		arg.Name,
-		arg.Readme,
-		arg.JobID,
-		arg.CreatedBy,
 	)
-	var i TemplateVersion
+	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const updateTemplateVersionByID = `-- name: UpdateTemplateVersionByID :exec
+const updateUserRoles = `-- name: UpdateUserRoles :one
 UPDATE
-	template_versions
+	users
 SET
-	template_id = $2,
-	updated_at = $3
+	-- Remove all duplicates from the roles.
+	rbac_roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
 WHERE
-	id = $1
+	id = $2
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-type UpdateTemplateVersionByIDParams struct {
-	ID         uuid.UUID     `db:"id" json:"id"`
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
+type UpdateUserRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	ID           uuid.UUID `db:"id" json:"id"`
 }
 
-func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionByID, arg.ID, arg.TemplateID, arg.UpdatedAt)
-	return err
+func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRoles, pq.Array(arg.GrantedRoles), arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+	)
+	return i, err
 }
 
-const updateTemplateVersionDescriptionByJobID = `-- name: UpdateTemplateVersionDescriptionByJobID :exec
+const updateUserStatus = `-- name: UpdateUserStatus :one
 UPDATE
-	template_versions
+	users
 SET
-	readme = $2,
+	status = $2,
 	updated_at = $3
 WHERE
-	job_id = $1
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-type UpdateTemplateVersionDescriptionByJobIDParams struct {
-	JobID     uuid.UUID `db:"job_id" json:"job_id"`
-	Readme    string    `db:"readme" json:"readme"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionDescriptionByJobID, arg.JobID, arg.Readme, arg.UpdatedAt)
-	return err
+type UpdateUserStatusParams struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	Status    UserStatus `db:"status" json:"status"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
 }
 
-const getUserLinkByLinkedID = `-- name: GetUserLinkByLinkedID :one
-SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
-FROM
-	user_links
-WHERE
-	linked_id = $1
-`
-
-func (q *sqlQuerier) GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, getUserLinkByLinkedID, linkedID)
-	var i UserLink
+func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.ID, arg.Status, arg.UpdatedAt)
+	var i User
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
 		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const getUserLinkByUserIDLoginType = `-- name: GetUserLinkByUserIDLoginType :one
-SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
-FROM
-	user_links
+const acquireWebhookQueue = `-- name: AcquireWebhookQueue :many
+UPDATE
+	webhook_queue
+SET
+	run_at = $1
 WHERE
-	user_id = $1 AND login_type = $2
+	id IN (
+		SELECT
+			id
+		FROM
+			webhook_queue
+		WHERE
+			run_at <= $2
+			AND kind = ANY($3 :: text[])
+		ORDER BY
+			run_at ASC
+		LIMIT
+			$4
+		FOR UPDATE
+			SKIP LOCKED
+	)
+RETURNING id, kind, webhook_id, event_id, event_type, payload, attempts, run_at, created_at
 `
 
-type GetUserLinkByUserIDLoginTypeParams struct {
-	UserID    uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType LoginType `db:"login_type" json:"login_type"`
+type AcquireWebhookQueueParams struct {
+	LeaseUntil time.Time `db:"lease_until" json:"lease_until"`
+	Now        time.Time `db:"now" json:"now"`
+	Kinds      []string  `db:"kinds" json:"kinds"`
+	LimitOpt   int32     `db:"limit_opt" json:"limit_opt"`
 }
 
-func (q *sqlQuerier) GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, getUserLinkByUserIDLoginType, arg.UserID, arg.LoginType)
-	var i UserLink
-	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+// Leases the due items of the kinds until @lease_until, so other replicas
+// skip them while they're delivered. An item whose attempt doesn't finish,
+// e.g. because the replica stopped, is acquired again once its lease ends.
+func (q *sqlQuerier) AcquireWebhookQueue(ctx context.Context, arg AcquireWebhookQueueParams) ([]WebhookQueue, error) {
+	rows, err := q.db.QueryContext(ctx, acquireWebhookQueue,
+		arg.LeaseUntil,
+		arg.Now,
+		pq.Array(arg.Kinds),
+		arg.LimitOpt,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookQueue
+	for rows.Next() {
+		var i WebhookQueue
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.RunAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const insertUserLink = `-- name: InsertUserLink :one
-INSERT INTO
-	user_links (
-		user_id,
-		login_type,
-		linked_id,
-		oauth_access_token,
-		oauth_refresh_token,
-		oauth_expiry
-	)
+const deleteWebhookQueueByID = `-- name: DeleteWebhookQueueByID :exec
+DELETE FROM
+	webhook_queue
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteWebhookQueueByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookQueueByID, id)
+	return err
+}
+
+const insertWebhookQueue = `-- name: InsertWebhookQueue :one
+INSERT INTO webhook_queue (
+	id,
+	kind,
+	webhook_id,
+	event_id,
+	event_type,
+	payload,
+	run_at,
+	created_at
+)
 VALUES
-	( $1, $2, $3, $4, $5, $6 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	($1, $2, $3, $4, $5, $6, $7, $7) RETURNING id, kind, webhook_id, event_id, event_type, payload, attempts, run_at, created_at
 `
 
-type InsertUserLinkParams struct {
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType         LoginType `db:"login_type" json:"login_type"`
-	LinkedID          string    `db:"linked_id" json:"linked_id"`
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+type InsertWebhookQueueParams struct {
+	ID        uuid.UUID       `db:"id" json:"id"`
+	Kind      string          `db:"kind" json:"kind"`
+	WebhookID uuid.UUID       `db:"webhook_id" json:"webhook_id"`
+	EventID   uuid.UUID       `db:"event_id" json:"event_id"`
+	EventType string          `db:"event_type" json:"event_type"`
+	Payload   json.RawMessage `db:"payload" json:"payload"`
+	RunAt     time.Time       `db:"run_at" json:"run_at"`
 }
 
-func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, insertUserLink,
-		arg.UserID,
-		arg.LoginType,
-		arg.LinkedID,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
+func (q *sqlQuerier) InsertWebhookQueue(ctx context.Context, arg InsertWebhookQueueParams) (WebhookQueue, error) {
+	row := q.db.QueryRowContext(ctx, insertWebhookQueue,
+		arg.ID,
+		arg.Kind,
+		arg.WebhookID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.RunAt,
 	)
-	var i UserLink
+	var i WebhookQueue
 	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+		&i.ID,
+		&i.Kind,
+		&i.WebhookID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Attempts,
+		&i.RunAt,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const updateUserLink = `-- name: UpdateUserLink :one
+const updateWebhookQueueByID = `-- name: UpdateWebhookQueueByID :exec
 UPDATE
-	user_links
+	webhook_queue
 SET
-	oauth_access_token = $1,
-	oauth_refresh_token = $2,
-	oauth_expiry = $3
+	attempts = $2,
+	run_at = $3
 WHERE
-	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	id = $1
 `
 
-type UpdateUserLinkParams struct {
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType         LoginType `db:"login_type" json:"login_type"`
+type UpdateWebhookQueueByIDParams struct {
+	ID       uuid.UUID `db:"id" json:"id"`
+	Attempts int32     `db:"attempts" json:"attempts"`
+	RunAt    time.Time `db:"run_at" json:"run_at"`
 }
 
-func (q *sqlQuerier) UpdateUserLink(ctx context.Context, arg UpdateUserLinkParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLink,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
-		arg.UserID,
-		arg.LoginType,
-	)
-	var i UserLink
-	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
-	)
-	return i, err
+func (q *sqlQuerier) UpdateWebhookQueueByID(ctx context.Context, arg UpdateWebhookQueueByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookQueueByID, arg.ID, arg.Attempts, arg.RunAt)
+	return err
 }
 
-const updateUserLinkedID = `-- name: UpdateUserLinkedID :one
-UPDATE
-	user_links
-SET
-	linked_id = $1
+const deleteWebhookByID = `-- name: DeleteWebhookByID :exec
+DELETE FROM
+	webhooks
 WHERE
-	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	id = $1
 `
 
-type UpdateUserLinkedIDParams struct {
-	LinkedID  string    `db:"linked_id" json:"linked_id"`
-	UserID    uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType LoginType `db:"login_type" json:"login_type"`
+func (q *sqlQuerier) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookByID, id)
+	return err
 }
 
-func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLinkedID, arg.LinkedID, arg.UserID, arg.LoginType)
-	var i UserLink
+const getWebhookByID = `-- name: GetWebhookByID :one
+SELECT
+	id, url, secret, events, created_at
+FROM
+	webhooks
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetWebhookByID(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookByID, id)
+	var i Webhook
 	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+		&i.ID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getActiveUserCount = `-- name: GetActiveUserCount :one
+const getWebhookDeliveriesByWebhookID = `-- name: GetWebhookDeliveriesByWebhookID :many
 SELECT
-	COUNT(*)
+	id, webhook_id, event_id, event_type, payload, attempt, redelivery, status_code, error, created_at
 FROM
-	users
+	webhook_deliveries
 WHERE
-    status = 'active'::public.user_status AND deleted = false
+	webhook_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	$2
 `
 
-func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getActiveUserCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type GetWebhookDeliveriesByWebhookIDParams struct {
+	WebhookID uuid.UUID `db:"webhook_id" json:"webhook_id"`
+	Limit     int32     `db:"limit" json:"limit"`
 }
 
-const getAuthorizationUserRoles = `-- name: GetAuthorizationUserRoles :one
+func (q *sqlQuerier) GetWebhookDeliveriesByWebhookID(ctx context.Context, arg GetWebhookDeliveriesByWebhookIDParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveriesByWebhookID, arg.WebhookID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempt,
+			&i.Redelivery,
+			&i.StatusCode,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeliveryByID = `-- name: GetWebhookDeliveryByID :one
 SELECT
-	-- username is returned just to help for logging purposes
-	-- status is used to enforce 'suspended' users, as all roles are ignored
-	--	when suspended.
-	id, username, status,
-	-- All user roles, including their org roles.
-	array_cat(
-		-- All users are members
-		array_append(users.rbac_roles, 'member'),
-		(
-			SELECT
-				array_agg(org_roles)
-			FROM
-				organization_members,
-				-- All org_members get the org-member role for their orgs
-				unnest(
-					array_append(roles, 'organization-member:' || organization_members.organization_id::text)
-				) AS org_roles
-			WHERE
-				user_id = users.id
-		)
-	) :: text[] AS roles,
-	-- All groups the user is in.
-	(
-		SELECT
-			array_agg(
-				group_members.group_id :: text
-			)
-		FROM
-			group_members
-		WHERE
-			user_id = users.id
-	) :: text[] AS groups
+	id, webhook_id, event_id, event_type, payload, attempt, redelivery, status_code, error, created_at
 FROM
-	users
+	webhook_deliveries
 WHERE
 	id = $1
 `
 
-type GetAuthorizationUserRolesRow struct {
-	ID       uuid.UUID  `db:"id" json:"id"`
-	Username string     `db:"username" json:"username"`
-	Status   UserStatus `db:"status" json:"status"`
-	Roles    []string   `db:"roles" json:"roles"`
-	Groups   []string   `db:"groups" json:"groups"`
+func (q *sqlQuerier) GetWebhookDeliveryByID(ctx context.Context, id uuid.UUID) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDeliveryByID, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Attempt,
+		&i.Redelivery,
+		&i.StatusCode,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
-// This function returns roles for authorization purposes. Implied member roles
-// are included.
-func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error) {
-	row := q.db.QueryRowContext(ctx, getAuthorizationUserRoles, userID)
-	var i GetAuthorizationUserRolesRow
+const getWebhooks = `-- name: GetWebhooks :many
+SELECT
+	id, url, secret, events, created_at
+FROM
+	webhooks
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWebhook = `-- name: InsertWebhook :one
+INSERT INTO webhooks (
+	id,
+	url,
+	secret,
+	events,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING id, url, secret, events, created_at
+`
+
+type InsertWebhookParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Url       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret"`
+	Events    []string  `db:"events" json:"events"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, insertWebhook,
+		arg.ID,
+		arg.Url,
+		arg.Secret,
+		pq.Array(arg.Events),
+		arg.CreatedAt,
+	)
+	var i Webhook
 	err := row.Scan(
 		&i.ID,
-		&i.Username,
-		&i.Status,
-		pq.Array(&i.Roles),
-		pq.Array(&i.Groups),
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
+const insertWebhookDelivery = `-- name: InsertWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+	id,
+	webhook_id,
+	event_id,
+	event_type,
+	payload,
+	attempt,
+	redelivery,
+	status_code,
+	error,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, webhook_id, event_id, event_type, payload, attempt, redelivery, status_code, error, created_at
+`
+
+type InsertWebhookDeliveryParams struct {
+	ID         uuid.UUID       `db:"id" json:"id"`
+	WebhookID  uuid.UUID       `db:"webhook_id" json:"webhook_id"`
+	EventID    uuid.UUID       `db:"event_id" json:"event_id"`
+	EventType  string          `db:"event_type" json:"event_type"`
+	Payload    json.RawMessage `db:"payload" json:"payload"`
+	Attempt    int32           `db:"attempt" json:"attempt"`
+	Redelivery bool            `db:"redelivery" json:"redelivery"`
+	StatusCode int32           `db:"status_code" json:"status_code"`
+	Error      string          `db:"error" json:"error"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, insertWebhookDelivery,
+		arg.ID,
+		arg.WebhookID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.Attempt,
+		arg.Redelivery,
+		arg.StatusCode,
+		arg.Error,
+		arg.CreatedAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Attempt,
+		&i.Redelivery,
+		&i.StatusCode,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWorkspaceAgentByAuthToken = `-- name: GetWorkspaceAgentByAuthToken :one
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
 FROM
-	users
+	workspace_agents
 WHERE
-	(LOWER(username) = LOWER($1) OR LOWER(email) = LOWER($2))
-	AND deleted = $3
-LIMIT
-	1
+	auth_token = $1
+ORDER BY
+	created_at DESC
 `
 
-type GetUserByEmailOrUsernameParams struct {
-	Username string `db:"username" json:"username"`
-	Email    string `db:"email" json:"email"`
-	Deleted  bool   `db:"deleted" json:"deleted"`
-}
-
-func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmailOrUsername, arg.Username, arg.Email, arg.Deleted)
-	var i User
+func (q *sqlQuerier) GetWorkspaceAgentByAuthToken(ctx context.Context, authToken uuid.UUID) (WorkspaceAgent, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByAuthToken, authToken)
+	var i WorkspaceAgent
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.Name,
+		&i.FirstConnectedAt,
+		&i.LastConnectedAt,
+		&i.DisconnectedAt,
+		&i.ResourceID,
+		&i.AuthToken,
+		&i.AuthInstanceID,
+		&i.Architecture,
+		&i.EnvironmentVariables,
+		&i.OperatingSystem,
+		&i.StartupScript,
+		&i.InstanceMetadata,
+		&i.ResourceMetadata,
+		&i.Directory,
+		&i.Version,
 	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
+const getWorkspaceAgentByID = `-- name: GetWorkspaceAgentByID :one
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
 FROM
-	users
+	workspace_agents
 WHERE
 	id = $1
-LIMIT
-	1
 `
 
-func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
-	var i User
+func (q *sqlQuerier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (WorkspaceAgent, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByID, id)
+	var i WorkspaceAgent
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.Name,
+		&i.FirstConnectedAt,
+		&i.LastConnectedAt,
+		&i.DisconnectedAt,
+		&i.ResourceID,
+		&i.AuthToken,
+		&i.AuthInstanceID,
+		&i.Architecture,
+		&i.EnvironmentVariables,
+		&i.OperatingSystem,
+		&i.StartupScript,
+		&i.InstanceMetadata,
+		&i.ResourceMetadata,
+		&i.Directory,
+		&i.Version,
 	)
 	return i, err
 }
 
-const getUserCount = `-- name: GetUserCount :one
+const getWorkspaceAgentByInstanceID = `-- name: GetWorkspaceAgentByInstanceID :one
 SELECT
-	COUNT(*)
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
 FROM
-	users WHERE deleted = false
+	workspace_agents
+WHERE
+	auth_instance_id = $1 :: TEXT
+ORDER BY
+	created_at DESC
 `
 
-func (q *sqlQuerier) GetUserCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getUserCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *sqlQuerier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanceID string) (WorkspaceAgent, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByInstanceID, authInstanceID)
+	var i WorkspaceAgent
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.FirstConnectedAt,
+		&i.LastConnectedAt,
+		&i.DisconnectedAt,
+		&i.ResourceID,
+		&i.AuthToken,
+		&i.AuthInstanceID,
+		&i.Architecture,
+		&i.EnvironmentVariables,
+		&i.OperatingSystem,
+		&i.StartupScript,
+		&i.InstanceMetadata,
+		&i.ResourceMetadata,
+		&i.Directory,
+		&i.Version,
+	)
+	return i, err
 }
 
-const getUsers = `-- name: GetUsers :many
+const getWorkspaceAgentsByResourceIDs = `-- name: GetWorkspaceAgentsByResourceIDs :many
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
 FROM
-	users
+	workspace_agents
 WHERE
-	users.deleted = $1
-	AND CASE
-		-- This allows using the last element on a page as effectively a cursor.
-		-- This is an important option for scripts that need to paginate without
-		-- duplicating or missing data.
-		WHEN $2 :: uuid != '00000000-00000000-00000000-00000000' THEN (
-			-- The pagination cursor is the last ID of the previous page.
-			-- The query is ordered by the created_at field, so select all
-			-- rows after the cursor.
-			(created_at, id) > (
-				SELECT
-					created_at, id
-				FROM
-					users
-				WHERE
-					id = $2
-			)
-		)
-		ELSE true
-	END
-	-- Start filters
-	-- Filter by name, email or username
-	AND CASE
-		WHEN $3 :: text != '' THEN (
-			email ILIKE concat('%', $3, '%')
-			OR username ILIKE concat('%', $3, '%')
-		)
-		ELSE true
-	END
-	-- Filter by status
-	AND CASE
-		-- @status needs to be a text because it can be empty, If it was
-		-- user_status enum, it would not.
-		WHEN cardinality($4 :: user_status[]) > 0 THEN
-			status = ANY($4 :: user_status[])
-		ELSE true
-	END
-	-- Filter by rbac_roles
-	AND CASE
-		-- @rbac_role allows filtering by rbac roles. If 'member' is included, show everyone, as
-	    -- everyone is a member.
-		WHEN cardinality($5 :: text[]) > 0 AND 'member' != ANY($5 :: text[]) THEN
-		    rbac_roles && $5 :: text[]
-		ELSE true
-	END
-	-- End of filters
-ORDER BY
-	-- Deterministic and consistent ordering of all users, even if they share
-	-- a timestamp. This is to ensure consistent pagination.
-	(created_at, id) ASC OFFSET $6
-LIMIT
-	-- A null limit means "no limit", so 0 means return all
-	NULLIF($7 :: int, 0)
+	resource_id = ANY($1 :: uuid [ ])
 `
 
-type GetUsersParams struct {
-	Deleted   bool         `db:"deleted" json:"deleted"`
-	AfterID   uuid.UUID    `db:"after_id" json:"after_id"`
-	Search    string       `db:"search" json:"search"`
-	Status    []UserStatus `db:"status" json:"status"`
-	RbacRole  []string     `db:"rbac_role" json:"rbac_role"`
-	OffsetOpt int32        `db:"offset_opt" json:"offset_opt"`
-	LimitOpt  int32        `db:"limit_opt" json:"limit_opt"`
-}
-
-func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getUsers,
-		arg.Deleted,
-		arg.AfterID,
-		arg.Search,
-		pq.Array(arg.Status),
-		pq.Array(arg.RbacRole),
-		arg.OffsetOpt,
-		arg.LimitOpt,
-	)
+func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgent, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsByResourceIDs, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []WorkspaceAgent
 	for rows.Next() {
-		var i User
+		var i WorkspaceAgent
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
+			&i.Name,
+			&i.FirstConnectedAt,
+			&i.LastConnectedAt,
+			&i.DisconnectedAt,
+			&i.ResourceID,
+			&i.AuthToken,
+			&i.AuthInstanceID,
+			&i.Architecture,
+			&i.EnvironmentVariables,
+			&i.OperatingSystem,
+			&i.StartupScript,
+			&i.InstanceMetadata,
+			&i.ResourceMetadata,
+			&i.Directory,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -3660,35 +9592,38 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]User,
 	return items, nil
 }
 
-const getUsersByIDs = `-- name: GetUsersByIDs :many
-SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at FROM users WHERE id = ANY($1 :: uuid [ ])
+const getWorkspaceAgentsCreatedAfter = `-- name: GetWorkspaceAgentsCreatedAfter :many
+SELECT id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version FROM workspace_agents WHERE created_at > $1
 `
 
-// This shouldn't check for deleted, because it's frequently used
-// to look up references to actions. eg. a user could build a workspace
-// for another user, then be deleted... we still want them to appear!
-func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
+func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceAgent, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsCreatedAfter, createdAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []WorkspaceAgent
 	for rows.Next() {
-		var i User
+		var i WorkspaceAgent
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
+			&i.Name,
+			&i.FirstConnectedAt,
+			&i.LastConnectedAt,
+			&i.DisconnectedAt,
+			&i.ResourceID,
+			&i.AuthToken,
+			&i.AuthInstanceID,
+			&i.Architecture,
+			&i.EnvironmentVariables,
+			&i.OperatingSystem,
+			&i.StartupScript,
+			&i.InstanceMetadata,
+			&i.ResourceMetadata,
+			&i.Directory,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -3703,400 +9638,406 @@ func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User
 	return items, nil
 }
 
-const insertUser = `-- name: InsertUser :one
+const insertWorkspaceAgent = `-- name: InsertWorkspaceAgent :one
 INSERT INTO
-	users (
+	workspace_agents (
 		id,
-		email,
-		username,
-		hashed_password,
 		created_at,
 		updated_at,
-		rbac_roles,
-		login_type
+		name,
+		resource_id,
+		auth_token,
+		auth_instance_id,
+		architecture,
+		environment_variables,
+		operating_system,
+		startup_script,
+		directory,
+		instance_metadata,
+		resource_metadata
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
 `
 
-type InsertUserParams struct {
-	ID             uuid.UUID      `db:"id" json:"id"`
-	Email          string         `db:"email" json:"email"`
-	Username       string         `db:"username" json:"username"`
-	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
-	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType      LoginType      `db:"login_type" json:"login_type"`
+type InsertWorkspaceAgentParams struct {
+	ID                   uuid.UUID             `db:"id" json:"id"`
+	CreatedAt            time.Time             `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time             `db:"updated_at" json:"updated_at"`
+	Name                 string                `db:"name" json:"name"`
+	ResourceID           uuid.UUID             `db:"resource_id" json:"resource_id"`
+	AuthToken            uuid.UUID             `db:"auth_token" json:"auth_token"`
+	AuthInstanceID       sql.NullString        `db:"auth_instance_id" json:"auth_instance_id"`
+	Architecture         string                `db:"architecture" json:"architecture"`
+	EnvironmentVariables pqtype.NullRawMessage `db:"environment_variables" json:"environment_variables"`
+	OperatingSystem      string                `db:"operating_system" json:"operating_system"`
+	StartupScript        sql.NullString        `db:"startup_script" json:"startup_script"`
+	Directory            string                `db:"directory" json:"directory"`
+	InstanceMetadata     pqtype.NullRawMessage `db:"instance_metadata" json:"instance_metadata"`
+	ResourceMetadata     pqtype.NullRawMessage `db:"resource_metadata" json:"resource_metadata"`
 }
 
-func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, insertUser,
+func (q *sqlQuerier) InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspaceAgentParams) (WorkspaceAgent, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceAgent,
 		arg.ID,
-		arg.Email,
-		arg.Username,
-		arg.HashedPassword,
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.RBACRoles,
-		arg.LoginType,
+		arg.Name,
+		arg.ResourceID,
+		arg.AuthToken,
+		arg.AuthInstanceID,
+		arg.Architecture,
+		arg.EnvironmentVariables,
+		arg.OperatingSystem,
+		arg.StartupScript,
+		arg.Directory,
+		arg.InstanceMetadata,
+		arg.ResourceMetadata,
 	)
-	var i User
+	var i WorkspaceAgent
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.Name,
+		&i.FirstConnectedAt,
+		&i.LastConnectedAt,
+		&i.DisconnectedAt,
+		&i.ResourceID,
+		&i.AuthToken,
+		&i.AuthInstanceID,
+		&i.Architecture,
+		&i.EnvironmentVariables,
+		&i.OperatingSystem,
+		&i.StartupScript,
+		&i.InstanceMetadata,
+		&i.ResourceMetadata,
+		&i.Directory,
+		&i.Version,
 	)
 	return i, err
 }
 
-const updateUserDeletedByID = `-- name: UpdateUserDeletedByID :exec
+const updateWorkspaceAgentConnectionByID = `-- name: UpdateWorkspaceAgentConnectionByID :exec
 UPDATE
-	users
+	workspace_agents
 SET
-	deleted = $2
+	first_connected_at = $2,
+	last_connected_at = $3,
+	disconnected_at = $4,
+	updated_at = $5
 WHERE
 	id = $1
 `
 
-type UpdateUserDeletedByIDParams struct {
-	ID      uuid.UUID `db:"id" json:"id"`
-	Deleted bool      `db:"deleted" json:"deleted"`
+type UpdateWorkspaceAgentConnectionByIDParams struct {
+	ID               uuid.UUID    `db:"id" json:"id"`
+	FirstConnectedAt sql.NullTime `db:"first_connected_at" json:"first_connected_at"`
+	LastConnectedAt  sql.NullTime `db:"last_connected_at" json:"last_connected_at"`
+	DisconnectedAt   sql.NullTime `db:"disconnected_at" json:"disconnected_at"`
+	UpdatedAt        time.Time    `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateUserDeletedByID, arg.ID, arg.Deleted)
+func (q *sqlQuerier) UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentConnectionByID,
+		arg.ID,
+		arg.FirstConnectedAt,
+		arg.LastConnectedAt,
+		arg.DisconnectedAt,
+		arg.UpdatedAt,
+	)
 	return err
 }
 
-const updateUserHashedPassword = `-- name: UpdateUserHashedPassword :exec
+const updateWorkspaceAgentAuthToken = `-- name: UpdateWorkspaceAgentAuthToken :exec
 UPDATE
-	users
+	workspace_agents
 SET
-	hashed_password = $2
+	auth_token = $1,
+	updated_at = $2
 WHERE
-	id = $1
+	auth_token = $3
 `
 
-type UpdateUserHashedPasswordParams struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	HashedPassword []byte    `db:"hashed_password" json:"hashed_password"`
+type UpdateWorkspaceAgentAuthTokenParams struct {
+	NewAuthToken uuid.UUID `db:"new_auth_token" json:"new_auth_token"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	AuthToken    uuid.UUID `db:"auth_token" json:"auth_token"`
 }
 
-func (q *sqlQuerier) UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error {
-	_, err := q.db.ExecContext(ctx, updateUserHashedPassword, arg.ID, arg.HashedPassword)
+// Replaces the token of every agent that authenticates with it, which
+// includes the agents of previous builds of the workspace.
+func (q *sqlQuerier) UpdateWorkspaceAgentAuthToken(ctx context.Context, arg UpdateWorkspaceAgentAuthTokenParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentAuthToken, arg.NewAuthToken, arg.UpdatedAt, arg.AuthToken)
 	return err
 }
 
-const updateUserLastSeenAt = `-- name: UpdateUserLastSeenAt :one
-UPDATE
-	users
-SET
-	last_seen_at = $2,
-	updated_at = $3
-WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
-`
-
-type UpdateUserLastSeenAtParams struct {
-	ID         uuid.UUID `db:"id" json:"id"`
-	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLastSeenAtParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLastSeenAt, arg.ID, arg.LastSeenAt, arg.UpdatedAt)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
-	)
-	return i, err
-}
-
-const updateUserProfile = `-- name: UpdateUserProfile :one
+const updateWorkspaceAgentVersionByID = `-- name: UpdateWorkspaceAgentVersionByID :exec
 UPDATE
-	users
+	workspace_agents
 SET
-	email = $2,
-	username = $3,
-	avatar_url = $4,
-	updated_at = $5
+	version = $2
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	id = $1
 `
 
-type UpdateUserProfileParams struct {
-	ID        uuid.UUID      `db:"id" json:"id"`
-	Email     string         `db:"email" json:"email"`
-	Username  string         `db:"username" json:"username"`
-	AvatarURL sql.NullString `db:"avatar_url" json:"avatar_url"`
-	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+type UpdateWorkspaceAgentVersionByIDParams struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Version string    `db:"version" json:"version"`
 }
 
-func (q *sqlQuerier) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserProfile,
-		arg.ID,
-		arg.Email,
-		arg.Username,
-		arg.AvatarURL,
-		arg.UpdatedAt,
-	)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
-	)
-	return i, err
+func (q *sqlQuerier) UpdateWorkspaceAgentVersionByID(ctx context.Context, arg UpdateWorkspaceAgentVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentVersionByID, arg.ID, arg.Version)
+	return err
 }
 
-const updateUserRoles = `-- name: UpdateUserRoles :one
-UPDATE
-	users
-SET
-	-- Remove all duplicates from the roles.
-	rbac_roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+const getWorkspaceAgentStartupScriptResultsByAgentIDs = `-- name: GetWorkspaceAgentStartupScriptResultsByAgentIDs :many
+SELECT
+	agent_id, exit_code, completed_at
+FROM
+	workspace_agent_startup_script_results
 WHERE
-	id = $2
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	agent_id = ANY($1 :: uuid [ ])
 `
 
-type UpdateUserRolesParams struct {
-	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
-	ID           uuid.UUID `db:"id" json:"id"`
+func (q *sqlQuerier) GetWorkspaceAgentStartupScriptResultsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgentStartupScriptResult, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentStartupScriptResultsByAgentIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentStartupScriptResult
+	for rows.Next() {
+		var i WorkspaceAgentStartupScriptResult
+		if err := rows.Scan(
+			&i.AgentID,
+			&i.ExitCode,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserRoles, pq.Array(arg.GrantedRoles), arg.ID)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+const upsertWorkspaceAgentStartupScriptResult = `-- name: UpsertWorkspaceAgentStartupScriptResult :one
+INSERT INTO
+	workspace_agent_startup_script_results (
+		agent_id,
+		exit_code,
+		completed_at
 	)
-	return i, err
-}
-
-const updateUserStatus = `-- name: UpdateUserStatus :one
-UPDATE
-	users
-SET
-	status = $2,
-	updated_at = $3
-WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+VALUES
+	($1, $2, $3)
+ON CONFLICT (agent_id) DO UPDATE SET
+	exit_code = $2,
+	completed_at = $3
+RETURNING agent_id, exit_code, completed_at
 `
 
-type UpdateUserStatusParams struct {
-	ID        uuid.UUID  `db:"id" json:"id"`
-	Status    UserStatus `db:"status" json:"status"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+type UpsertWorkspaceAgentStartupScriptResultParams struct {
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	ExitCode    int32     `db:"exit_code" json:"exit_code"`
+	CompletedAt time.Time `db:"completed_at" json:"completed_at"`
 }
 
-func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.ID, arg.Status, arg.UpdatedAt)
-	var i User
+func (q *sqlQuerier) UpsertWorkspaceAgentStartupScriptResult(ctx context.Context, arg UpsertWorkspaceAgentStartupScriptResultParams) (WorkspaceAgentStartupScriptResult, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspaceAgentStartupScriptResult, arg.AgentID, arg.ExitCode, arg.CompletedAt)
+	var i WorkspaceAgentStartupScriptResult
 	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.AgentID,
+		&i.ExitCode,
+		&i.CompletedAt,
 	)
 	return i, err
 }
 
-const getWorkspaceAgentByAuthToken = `-- name: GetWorkspaceAgentByAuthToken :one
-SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
-FROM
-	workspace_agents
+const deleteWorkspaceAgentUsageSamplesBefore = `-- name: DeleteWorkspaceAgentUsageSamplesBefore :exec
+DELETE FROM
+	workspace_agent_usage_samples
 WHERE
-	auth_token = $1
-ORDER BY
-	created_at DESC
+	agent_id = $1
+	AND created_at < $2 :: timestamptz
 `
 
-func (q *sqlQuerier) GetWorkspaceAgentByAuthToken(ctx context.Context, authToken uuid.UUID) (WorkspaceAgent, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByAuthToken, authToken)
-	var i WorkspaceAgent
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.FirstConnectedAt,
-		&i.LastConnectedAt,
-		&i.DisconnectedAt,
-		&i.ResourceID,
-		&i.AuthToken,
-		&i.AuthInstanceID,
-		&i.Architecture,
-		&i.EnvironmentVariables,
-		&i.OperatingSystem,
-		&i.StartupScript,
-		&i.InstanceMetadata,
-		&i.ResourceMetadata,
-		&i.Directory,
-		&i.Version,
-	)
-	return i, err
+type DeleteWorkspaceAgentUsageSamplesBeforeParams struct {
+	AgentID       uuid.UUID `db:"agent_id" json:"agent_id"`
+	CreatedBefore time.Time `db:"created_before" json:"created_before"`
 }
 
-const getWorkspaceAgentByID = `-- name: GetWorkspaceAgentByID :one
+func (q *sqlQuerier) DeleteWorkspaceAgentUsageSamplesBefore(ctx context.Context, arg DeleteWorkspaceAgentUsageSamplesBeforeParams) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceAgentUsageSamplesBefore, arg.AgentID, arg.CreatedBefore)
+	return err
+}
+
+const getWorkspaceAgentUsageSamplesByAgentIDs = `-- name: GetWorkspaceAgentUsageSamplesByAgentIDs :many
 SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
+	id, agent_id, created_at, cpu_used, cpu_total, memory_used, memory_total, disk_used, disk_total
 FROM
-	workspace_agents
+	workspace_agent_usage_samples
 WHERE
-	id = $1
+	agent_id = ANY($1 :: uuid [ ])
+	AND created_at >= $2 :: timestamptz
+ORDER BY
+	created_at ASC
 `
 
-func (q *sqlQuerier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (WorkspaceAgent, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByID, id)
-	var i WorkspaceAgent
+type GetWorkspaceAgentUsageSamplesByAgentIDsParams struct {
+	IDs          []uuid.UUID `db:"ids" json:"ids"`
+	CreatedAfter time.Time   `db:"created_after" json:"created_after"`
+}
+
+func (q *sqlQuerier) GetWorkspaceAgentUsageSamplesByAgentIDs(ctx context.Context, arg GetWorkspaceAgentUsageSamplesByAgentIDsParams) ([]WorkspaceAgentUsageSample, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentUsageSamplesByAgentIDs, pq.Array(arg.IDs), arg.CreatedAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentUsageSample
+	for rows.Next() {
+		var i WorkspaceAgentUsageSample
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgentID,
+			&i.CreatedAt,
+			&i.CPUUsed,
+			&i.CPUTotal,
+			&i.MemoryUsed,
+			&i.MemoryTotal,
+			&i.DiskUsed,
+			&i.DiskTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceAgentUsageSample = `-- name: InsertWorkspaceAgentUsageSample :one
+INSERT INTO
+	workspace_agent_usage_samples (
+		id,
+		agent_id,
+		created_at,
+		cpu_used,
+		cpu_total,
+		memory_used,
+		memory_total,
+		disk_used,
+		disk_total
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, agent_id, created_at, cpu_used, cpu_total, memory_used, memory_total, disk_used, disk_total
+`
+
+type InsertWorkspaceAgentUsageSampleParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	CPUUsed     float64   `db:"cpu_used" json:"cpu_used"`
+	CPUTotal    float64   `db:"cpu_total" json:"cpu_total"`
+	MemoryUsed  int64     `db:"memory_used" json:"memory_used"`
+	MemoryTotal int64     `db:"memory_total" json:"memory_total"`
+	DiskUsed    int64     `db:"disk_used" json:"disk_used"`
+	DiskTotal   int64     `db:"disk_total" json:"disk_total"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentUsageSample(ctx context.Context, arg InsertWorkspaceAgentUsageSampleParams) (WorkspaceAgentUsageSample, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceAgentUsageSample,
+		arg.ID,
+		arg.AgentID,
+		arg.CreatedAt,
+		arg.CPUUsed,
+		arg.CPUTotal,
+		arg.MemoryUsed,
+		arg.MemoryTotal,
+		arg.DiskUsed,
+		arg.DiskTotal,
+	)
+	var i WorkspaceAgentUsageSample
 	err := row.Scan(
 		&i.ID,
+		&i.AgentID,
 		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.FirstConnectedAt,
-		&i.LastConnectedAt,
-		&i.DisconnectedAt,
-		&i.ResourceID,
-		&i.AuthToken,
-		&i.AuthInstanceID,
-		&i.Architecture,
-		&i.EnvironmentVariables,
-		&i.OperatingSystem,
-		&i.StartupScript,
-		&i.InstanceMetadata,
-		&i.ResourceMetadata,
-		&i.Directory,
-		&i.Version,
+		&i.CPUUsed,
+		&i.CPUTotal,
+		&i.MemoryUsed,
+		&i.MemoryTotal,
+		&i.DiskUsed,
+		&i.DiskTotal,
 	)
 	return i, err
 }
 
-const getWorkspaceAgentByInstanceID = `-- name: GetWorkspaceAgentByInstanceID :one
-SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
-FROM
-	workspace_agents
-WHERE
-	auth_instance_id = $1 :: TEXT
-ORDER BY
-	created_at DESC
+const getWorkspaceAppByAgentIDAndName = `-- name: GetWorkspaceAppByAgentIDAndName :one
+SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE agent_id = $1 AND name = $2
 `
 
-func (q *sqlQuerier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanceID string) (WorkspaceAgent, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByInstanceID, authInstanceID)
-	var i WorkspaceAgent
+type GetWorkspaceAppByAgentIDAndNameParams struct {
+	AgentID uuid.UUID `db:"agent_id" json:"agent_id"`
+	Name    string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndName(ctx context.Context, arg GetWorkspaceAppByAgentIDAndNameParams) (WorkspaceApp, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceAppByAgentIDAndName, arg.AgentID, arg.Name)
+	var i WorkspaceApp
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.AgentID,
 		&i.Name,
-		&i.FirstConnectedAt,
-		&i.LastConnectedAt,
-		&i.DisconnectedAt,
-		&i.ResourceID,
-		&i.AuthToken,
-		&i.AuthInstanceID,
-		&i.Architecture,
-		&i.EnvironmentVariables,
-		&i.OperatingSystem,
-		&i.StartupScript,
-		&i.InstanceMetadata,
-		&i.ResourceMetadata,
-		&i.Directory,
-		&i.Version,
+		&i.Icon,
+		&i.Command,
+		&i.Url,
+		&i.HealthcheckUrl,
+		&i.HealthcheckInterval,
+		&i.HealthcheckThreshold,
+		&i.Health,
+		&i.Subdomain,
 	)
 	return i, err
 }
 
-const getWorkspaceAgentsByResourceIDs = `-- name: GetWorkspaceAgentsByResourceIDs :many
-SELECT
-	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
-FROM
-	workspace_agents
-WHERE
-	resource_id = ANY($1 :: uuid [ ])
+const getWorkspaceAppsByAgentID = `-- name: GetWorkspaceAppsByAgentID :many
+SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE agent_id = $1 ORDER BY name ASC
 `
 
-func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgent, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsByResourceIDs, pq.Array(ids))
+func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAppsByAgentID, agentID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WorkspaceAgent
+	var items []WorkspaceApp
 	for rows.Next() {
-		var i WorkspaceAgent
+		var i WorkspaceApp
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.AgentID,
 			&i.Name,
-			&i.FirstConnectedAt,
-			&i.LastConnectedAt,
-			&i.DisconnectedAt,
-			&i.ResourceID,
-			&i.AuthToken,
-			&i.AuthInstanceID,
-			&i.Architecture,
-			&i.EnvironmentVariables,
-			&i.OperatingSystem,
-			&i.StartupScript,
-			&i.InstanceMetadata,
-			&i.ResourceMetadata,
-			&i.Directory,
-			&i.Version,
+			&i.Icon,
+			&i.Command,
+			&i.Url,
+			&i.HealthcheckUrl,
+			&i.HealthcheckInterval,
+			&i.HealthcheckThreshold,
+			&i.Health,
+			&i.Subdomain,
 		); err != nil {
 			return nil, err
 		}
@@ -4111,38 +10052,72 @@ func (q *sqlQuerier) GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []
 	return items, nil
 }
 
-const getWorkspaceAgentsCreatedAfter = `-- name: GetWorkspaceAgentsCreatedAfter :many
-SELECT id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version FROM workspace_agents WHERE created_at > $1
+const getWorkspaceAppsByAgentIDs = `-- name: GetWorkspaceAppsByAgentIDs :many
+SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY name ASC
 `
 
-func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceAgent, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsCreatedAfter, createdAt)
+func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAppsByAgentIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceApp
+	for rows.Next() {
+		var i WorkspaceApp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.AgentID,
+			&i.Name,
+			&i.Icon,
+			&i.Command,
+			&i.Url,
+			&i.HealthcheckUrl,
+			&i.HealthcheckInterval,
+			&i.HealthcheckThreshold,
+			&i.Health,
+			&i.Subdomain,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceAppsCreatedAfter = `-- name: GetWorkspaceAppsCreatedAfter :many
+SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE created_at > $1 ORDER BY name ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAppsCreatedAfter, createdAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WorkspaceAgent
+	var items []WorkspaceApp
 	for rows.Next() {
-		var i WorkspaceAgent
+		var i WorkspaceApp
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.AgentID,
 			&i.Name,
-			&i.FirstConnectedAt,
-			&i.LastConnectedAt,
-			&i.DisconnectedAt,
-			&i.ResourceID,
-			&i.AuthToken,
-			&i.AuthInstanceID,
-			&i.Architecture,
-			&i.EnvironmentVariables,
-			&i.OperatingSystem,
-			&i.StartupScript,
-			&i.InstanceMetadata,
-			&i.ResourceMetadata,
-			&i.Directory,
-			&i.Version,
+			&i.Icon,
+			&i.Command,
+			&i.Url,
+			&i.HealthcheckUrl,
+			&i.HealthcheckInterval,
+			&i.HealthcheckThreshold,
+			&i.Health,
+			&i.Subdomain,
 		); err != nil {
 			return nil, err
 		}
@@ -4157,191 +10132,207 @@ func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, created
 	return items, nil
 }
 
-const insertWorkspaceAgent = `-- name: InsertWorkspaceAgent :one
+const insertWorkspaceApp = `-- name: InsertWorkspaceApp :one
 INSERT INTO
-	workspace_agents (
-		id,
-		created_at,
-		updated_at,
-		name,
-		resource_id,
-		auth_token,
-		auth_instance_id,
-		architecture,
-		environment_variables,
-		operating_system,
-		startup_script,
-		directory,
-		instance_metadata,
-		resource_metadata
-	)
+    workspace_apps (
+        id,
+        created_at,
+        agent_id,
+        name,
+        icon,
+        command,
+        url,
+        subdomain,
+        healthcheck_url,
+        healthcheck_interval,
+        healthcheck_threshold,
+        health
+    )
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain
 `
 
-type InsertWorkspaceAgentParams struct {
-	ID                   uuid.UUID             `db:"id" json:"id"`
-	CreatedAt            time.Time             `db:"created_at" json:"created_at"`
-	UpdatedAt            time.Time             `db:"updated_at" json:"updated_at"`
-	Name                 string                `db:"name" json:"name"`
-	ResourceID           uuid.UUID             `db:"resource_id" json:"resource_id"`
-	AuthToken            uuid.UUID             `db:"auth_token" json:"auth_token"`
-	AuthInstanceID       sql.NullString        `db:"auth_instance_id" json:"auth_instance_id"`
-	Architecture         string                `db:"architecture" json:"architecture"`
-	EnvironmentVariables pqtype.NullRawMessage `db:"environment_variables" json:"environment_variables"`
-	OperatingSystem      string                `db:"operating_system" json:"operating_system"`
-	StartupScript        sql.NullString        `db:"startup_script" json:"startup_script"`
-	Directory            string                `db:"directory" json:"directory"`
-	InstanceMetadata     pqtype.NullRawMessage `db:"instance_metadata" json:"instance_metadata"`
-	ResourceMetadata     pqtype.NullRawMessage `db:"resource_metadata" json:"resource_metadata"`
+type InsertWorkspaceAppParams struct {
+	ID                   uuid.UUID          `db:"id" json:"id"`
+	CreatedAt            time.Time          `db:"created_at" json:"created_at"`
+	AgentID              uuid.UUID          `db:"agent_id" json:"agent_id"`
+	Name                 string             `db:"name" json:"name"`
+	Icon                 string             `db:"icon" json:"icon"`
+	Command              sql.NullString     `db:"command" json:"command"`
+	Url                  sql.NullString     `db:"url" json:"url"`
+	Subdomain            bool               `db:"subdomain" json:"subdomain"`
+	HealthcheckUrl       string             `db:"healthcheck_url" json:"healthcheck_url"`
+	HealthcheckInterval  int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
+	HealthcheckThreshold int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
+	Health               WorkspaceAppHealth `db:"health" json:"health"`
 }
 
-func (q *sqlQuerier) InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspaceAgentParams) (WorkspaceAgent, error) {
-	row := q.db.QueryRowContext(ctx, insertWorkspaceAgent,
+func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceApp,
 		arg.ID,
 		arg.CreatedAt,
-		arg.UpdatedAt,
+		arg.AgentID,
 		arg.Name,
-		arg.ResourceID,
-		arg.AuthToken,
-		arg.AuthInstanceID,
-		arg.Architecture,
-		arg.EnvironmentVariables,
-		arg.OperatingSystem,
-		arg.StartupScript,
-		arg.Directory,
-		arg.InstanceMetadata,
-		arg.ResourceMetadata,
+		arg.Icon,
+		arg.Command,
+		arg.Url,
+		arg.Subdomain,
+		arg.HealthcheckUrl,
+		arg.HealthcheckInterval,
+		arg.HealthcheckThreshold,
+		arg.Health,
 	)
-	var i WorkspaceAgent
+	var i WorkspaceApp
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.AgentID,
 		&i.Name,
-		&i.FirstConnectedAt,
-		&i.LastConnectedAt,
-		&i.DisconnectedAt,
-		&i.ResourceID,
-		&i.AuthToken,
-		&i.AuthInstanceID,
-		&i.Architecture,
-		&i.EnvironmentVariables,
-		&i.OperatingSystem,
-		&i.StartupScript,
-		&i.InstanceMetadata,
-		&i.ResourceMetadata,
-		&i.Directory,
-		&i.Version,
+		&i.Icon,
+		&i.Command,
+		&i.Url,
+		&i.HealthcheckUrl,
+		&i.HealthcheckInterval,
+		&i.HealthcheckThreshold,
+		&i.Health,
+		&i.Subdomain,
 	)
 	return i, err
 }
 
-const updateWorkspaceAgentConnectionByID = `-- name: UpdateWorkspaceAgentConnectionByID :exec
+const updateWorkspaceAppHealthByID = `-- name: UpdateWorkspaceAppHealthByID :exec
 UPDATE
-	workspace_agents
+	workspace_apps
 SET
-	first_connected_at = $2,
-	last_connected_at = $3,
-	disconnected_at = $4,
-	updated_at = $5
+	health = $2
 WHERE
 	id = $1
 `
 
-type UpdateWorkspaceAgentConnectionByIDParams struct {
-	ID               uuid.UUID    `db:"id" json:"id"`
-	FirstConnectedAt sql.NullTime `db:"first_connected_at" json:"first_connected_at"`
-	LastConnectedAt  sql.NullTime `db:"last_connected_at" json:"last_connected_at"`
-	DisconnectedAt   sql.NullTime `db:"disconnected_at" json:"disconnected_at"`
-	UpdatedAt        time.Time    `db:"updated_at" json:"updated_at"`
+type UpdateWorkspaceAppHealthByIDParams struct {
+	ID     uuid.UUID          `db:"id" json:"id"`
+	Health WorkspaceAppHealth `db:"health" json:"health"`
 }
 
-func (q *sqlQuerier) UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentConnectionByID,
-		arg.ID,
-		arg.FirstConnectedAt,
-		arg.LastConnectedAt,
-		arg.DisconnectedAt,
-		arg.UpdatedAt,
-	)
+func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAppHealthByID, arg.ID, arg.Health)
 	return err
 }
 
-const updateWorkspaceAgentVersionByID = `-- name: UpdateWorkspaceAgentVersionByID :exec
-UPDATE
-	workspace_agents
-SET
-	version = $2
+const deleteWorkspaceArchiveByWorkspaceID = `-- name: DeleteWorkspaceArchiveByWorkspaceID :exec
+DELETE FROM
+	workspace_archives
 WHERE
-	id = $1
+	workspace_id = $1
 `
 
-type UpdateWorkspaceAgentVersionByIDParams struct {
-	ID      uuid.UUID `db:"id" json:"id"`
-	Version string    `db:"version" json:"version"`
-}
-
-func (q *sqlQuerier) UpdateWorkspaceAgentVersionByID(ctx context.Context, arg UpdateWorkspaceAgentVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentVersionByID, arg.ID, arg.Version)
+func (q *sqlQuerier) DeleteWorkspaceArchiveByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceArchiveByWorkspaceID, workspaceID)
 	return err
 }
 
-const getWorkspaceAppByAgentIDAndName = `-- name: GetWorkspaceAppByAgentIDAndName :one
-SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE agent_id = $1 AND name = $2
+const getPurgeableWorkspaceArchives = `-- name: GetPurgeableWorkspaceArchives :many
+SELECT
+	workspace_archives.workspace_id, workspace_archives.status, workspace_archives.object_key, workspace_archives.build_id, workspace_archives.error, workspace_archives.created_at, workspace_archives.updated_at, workspace_archives.archived_at, workspace_archives.expires_at
+FROM
+	workspace_archives
+	INNER JOIN workspaces ON workspaces.id = workspace_archives.workspace_id
+WHERE
+	workspaces.deleted
+	OR (
+		workspace_archives.status = 'archived'
+		AND workspace_archives.expires_at <= $1 :: timestamptz
+	)
 `
 
-type GetWorkspaceAppByAgentIDAndNameParams struct {
-	AgentID uuid.UUID `db:"agent_id" json:"agent_id"`
-	Name    string    `db:"name" json:"name"`
+// Returns the archives of deleted workspaces, and archives that expired.
+func (q *sqlQuerier) GetPurgeableWorkspaceArchives(ctx context.Context, now time.Time) ([]WorkspaceArchive, error) {
+	rows, err := q.db.QueryContext(ctx, getPurgeableWorkspaceArchives, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceArchive
+	for rows.Next() {
+		var i WorkspaceArchive
+		if err := rows.Scan(
+			&i.WorkspaceID,
+			&i.Status,
+			&i.ObjectKey,
+			&i.BuildID,
+			&i.Error,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndName(ctx context.Context, arg GetWorkspaceAppByAgentIDAndNameParams) (WorkspaceApp, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceAppByAgentIDAndName, arg.AgentID, arg.Name)
-	var i WorkspaceApp
+const getWorkspaceArchiveByWorkspaceID = `-- name: GetWorkspaceArchiveByWorkspaceID :one
+SELECT
+	workspace_id, status, object_key, build_id, error, created_at, updated_at, archived_at, expires_at
+FROM
+	workspace_archives
+WHERE
+	workspace_id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceArchiveByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceArchive, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceArchiveByWorkspaceID, workspaceID)
+	var i WorkspaceArchive
 	err := row.Scan(
-		&i.ID,
+		&i.WorkspaceID,
+		&i.Status,
+		&i.ObjectKey,
+		&i.BuildID,
+		&i.Error,
 		&i.CreatedAt,
-		&i.AgentID,
-		&i.Name,
-		&i.Icon,
-		&i.Command,
-		&i.Url,
-		&i.HealthcheckUrl,
-		&i.HealthcheckInterval,
-		&i.HealthcheckThreshold,
-		&i.Health,
-		&i.Subdomain,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
-const getWorkspaceAppsByAgentID = `-- name: GetWorkspaceAppsByAgentID :many
-SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE agent_id = $1 ORDER BY name ASC
+const getWorkspaceArchivesByStatus = `-- name: GetWorkspaceArchivesByStatus :many
+SELECT
+	workspace_id, status, object_key, build_id, error, created_at, updated_at, archived_at, expires_at
+FROM
+	workspace_archives
+WHERE
+	status = $1
 `
 
-func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAppsByAgentID, agentID)
+func (q *sqlQuerier) GetWorkspaceArchivesByStatus(ctx context.Context, status WorkspaceArchiveStatus) ([]WorkspaceArchive, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceArchivesByStatus, status)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WorkspaceApp
+	var items []WorkspaceArchive
 	for rows.Next() {
-		var i WorkspaceApp
+		var i WorkspaceArchive
 		if err := rows.Scan(
-			&i.ID,
+			&i.WorkspaceID,
+			&i.Status,
+			&i.ObjectKey,
+			&i.BuildID,
+			&i.Error,
 			&i.CreatedAt,
-			&i.AgentID,
-			&i.Name,
-			&i.Icon,
-			&i.Command,
-			&i.Url,
-			&i.HealthcheckUrl,
-			&i.HealthcheckInterval,
-			&i.HealthcheckThreshold,
-			&i.Health,
-			&i.Subdomain,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -4356,32 +10347,136 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 	return items, nil
 }
 
-const getWorkspaceAppsByAgentIDs = `-- name: GetWorkspaceAppsByAgentIDs :many
-SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY name ASC
+const updateWorkspaceArchiveByWorkspaceID = `-- name: UpdateWorkspaceArchiveByWorkspaceID :one
+UPDATE
+	workspace_archives
+SET
+	status = $2,
+	build_id = $3,
+	error = $4,
+	updated_at = $5,
+	archived_at = $6,
+	expires_at = $7
+WHERE
+	workspace_id = $1
+RETURNING workspace_id, status, object_key, build_id, error, created_at, updated_at, archived_at, expires_at
 `
 
-func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAppsByAgentIDs, pq.Array(ids))
+type UpdateWorkspaceArchiveByWorkspaceIDParams struct {
+	WorkspaceID uuid.UUID              `db:"workspace_id" json:"workspace_id"`
+	Status      WorkspaceArchiveStatus `db:"status" json:"status"`
+	BuildID     uuid.UUID              `db:"build_id" json:"build_id"`
+	Error       string                 `db:"error" json:"error"`
+	UpdatedAt   time.Time              `db:"updated_at" json:"updated_at"`
+	ArchivedAt  sql.NullTime           `db:"archived_at" json:"archived_at"`
+	ExpiresAt   sql.NullTime           `db:"expires_at" json:"expires_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceArchiveByWorkspaceID(ctx context.Context, arg UpdateWorkspaceArchiveByWorkspaceIDParams) (WorkspaceArchive, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceArchiveByWorkspaceID,
+		arg.WorkspaceID,
+		arg.Status,
+		arg.BuildID,
+		arg.Error,
+		arg.UpdatedAt,
+		arg.ArchivedAt,
+		arg.ExpiresAt,
+	)
+	var i WorkspaceArchive
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.Status,
+		&i.ObjectKey,
+		&i.BuildID,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const upsertWorkspaceArchive = `-- name: UpsertWorkspaceArchive :one
+INSERT INTO
+	workspace_archives (workspace_id, status, object_key, build_id, error, created_at, updated_at, archived_at, expires_at)
+VALUES
+	($1, $2, $3, $4, '', $5, $5, NULL, NULL)
+ON CONFLICT (workspace_id) DO UPDATE SET
+	status = $2,
+	object_key = $3,
+	build_id = $4,
+	error = '',
+	created_at = $5,
+	updated_at = $5,
+	archived_at = NULL,
+	expires_at = NULL
+RETURNING workspace_id, status, object_key, build_id, error, created_at, updated_at, archived_at, expires_at
+`
+
+type UpsertWorkspaceArchiveParams struct {
+	WorkspaceID uuid.UUID              `db:"workspace_id" json:"workspace_id"`
+	Status      WorkspaceArchiveStatus `db:"status" json:"status"`
+	ObjectKey   string                 `db:"object_key" json:"object_key"`
+	BuildID     uuid.UUID              `db:"build_id" json:"build_id"`
+	CreatedAt   time.Time              `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) UpsertWorkspaceArchive(ctx context.Context, arg UpsertWorkspaceArchiveParams) (WorkspaceArchive, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspaceArchive,
+		arg.WorkspaceID,
+		arg.Status,
+		arg.ObjectKey,
+		arg.BuildID,
+		arg.CreatedAt,
+	)
+	var i WorkspaceArchive
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.Status,
+		&i.ObjectKey,
+		&i.BuildID,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getWorkspaceAutostopExtensionsByWorkspaceID = `-- name: GetWorkspaceAutostopExtensionsByWorkspaceID :many
+SELECT
+	id, workspace_id, user_id, extension, created_at
+FROM
+	workspace_autostop_extensions
+WHERE
+	workspace_id = $1
+	AND created_at > $2 :: timestamptz
+ORDER BY
+	created_at ASC
+`
+
+type GetWorkspaceAutostopExtensionsByWorkspaceIDParams struct {
+	WorkspaceID  uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	CreatedAfter time.Time `db:"created_after" json:"created_after"`
+}
+
+func (q *sqlQuerier) GetWorkspaceAutostopExtensionsByWorkspaceID(ctx context.Context, arg GetWorkspaceAutostopExtensionsByWorkspaceIDParams) ([]WorkspaceAutostopExtension, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAutostopExtensionsByWorkspaceID, arg.WorkspaceID, arg.CreatedAfter)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WorkspaceApp
+	var items []WorkspaceAutostopExtension
 	for rows.Next() {
-		var i WorkspaceApp
+		var i WorkspaceAutostopExtension
 		if err := rows.Scan(
 			&i.ID,
+			&i.WorkspaceID,
+			&i.UserID,
+			&i.Extension,
 			&i.CreatedAt,
-			&i.AgentID,
-			&i.Name,
-			&i.Icon,
-			&i.Command,
-			&i.Url,
-			&i.HealthcheckUrl,
-			&i.HealthcheckInterval,
-			&i.HealthcheckThreshold,
-			&i.Health,
-			&i.Subdomain,
 		); err != nil {
 			return nil, err
 		}
@@ -4396,32 +10491,72 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 	return items, nil
 }
 
-const getWorkspaceAppsCreatedAfter = `-- name: GetWorkspaceAppsCreatedAfter :many
-SELECT id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain FROM workspace_apps WHERE created_at > $1 ORDER BY name ASC
+const insertWorkspaceAutostopExtension = `-- name: InsertWorkspaceAutostopExtension :one
+INSERT INTO
+	workspace_autostop_extensions (id, workspace_id, user_id, extension, created_at)
+VALUES
+	($1, $2, $3, $4, $5)
+RETURNING id, workspace_id, user_id, extension, created_at
 `
 
-func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAppsCreatedAfter, createdAt)
+type InsertWorkspaceAutostopExtensionParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	Extension   int64     `db:"extension" json:"extension"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAutostopExtension(ctx context.Context, arg InsertWorkspaceAutostopExtensionParams) (WorkspaceAutostopExtension, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceAutostopExtension,
+		arg.ID,
+		arg.WorkspaceID,
+		arg.UserID,
+		arg.Extension,
+		arg.CreatedAt,
+	)
+	var i WorkspaceAutostopExtension
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.UserID,
+		&i.Extension,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWorkspaceBatchResultsByOperationID = `-- name: GetWorkspaceBatchResultsByOperationID :many
+SELECT
+	operation_id, workspace_id, workspace_name, action, status, build_id, error, created_at, updated_at
+FROM
+	workspace_batch_results
+WHERE
+	operation_id = $1
+ORDER BY
+	workspace_name ASC,
+	workspace_id ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceBatchResultsByOperationID(ctx context.Context, operationID uuid.UUID) ([]WorkspaceBatchResult, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBatchResultsByOperationID, operationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WorkspaceApp
+	var items []WorkspaceBatchResult
 	for rows.Next() {
-		var i WorkspaceApp
+		var i WorkspaceBatchResult
 		if err := rows.Scan(
-			&i.ID,
+			&i.OperationID,
+			&i.WorkspaceID,
+			&i.WorkspaceName,
+			&i.Action,
+			&i.Status,
+			&i.BuildID,
+			&i.Error,
 			&i.CreatedAt,
-			&i.AgentID,
-			&i.Name,
-			&i.Icon,
-			&i.Command,
-			&i.Url,
-			&i.HealthcheckUrl,
-			&i.HealthcheckInterval,
-			&i.HealthcheckThreshold,
-			&i.Health,
-			&i.Subdomain,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -4436,91 +10571,94 @@ func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt
 	return items, nil
 }
 
-const insertWorkspaceApp = `-- name: InsertWorkspaceApp :one
+const insertWorkspaceBatchResult = `-- name: InsertWorkspaceBatchResult :one
 INSERT INTO
-    workspace_apps (
-        id,
-        created_at,
-        agent_id,
-        name,
-        icon,
-        command,
-        url,
-        subdomain,
-        healthcheck_url,
-        healthcheck_interval,
-        healthcheck_threshold,
-        health
-    )
+	workspace_batch_results (operation_id, workspace_id, workspace_name, action, status, error, created_at, updated_at)
 VALUES
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, agent_id, name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain
+	($1, $2, $3, $4, $5, $6, $7, $7)
+RETURNING operation_id, workspace_id, workspace_name, action, status, build_id, error, created_at, updated_at
 `
 
-type InsertWorkspaceAppParams struct {
-	ID                   uuid.UUID          `db:"id" json:"id"`
-	CreatedAt            time.Time          `db:"created_at" json:"created_at"`
-	AgentID              uuid.UUID          `db:"agent_id" json:"agent_id"`
-	Name                 string             `db:"name" json:"name"`
-	Icon                 string             `db:"icon" json:"icon"`
-	Command              sql.NullString     `db:"command" json:"command"`
-	Url                  sql.NullString     `db:"url" json:"url"`
-	Subdomain            bool               `db:"subdomain" json:"subdomain"`
-	HealthcheckUrl       string             `db:"healthcheck_url" json:"healthcheck_url"`
-	HealthcheckInterval  int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
-	HealthcheckThreshold int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
-	Health               WorkspaceAppHealth `db:"health" json:"health"`
+type InsertWorkspaceBatchResultParams struct {
+	OperationID   uuid.UUID                  `db:"operation_id" json:"operation_id"`
+	WorkspaceID   uuid.UUID                  `db:"workspace_id" json:"workspace_id"`
+	WorkspaceName string                     `db:"workspace_name" json:"workspace_name"`
+	Action        WorkspaceBatchAction       `db:"action" json:"action"`
+	Status        WorkspaceBatchResultStatus `db:"status" json:"status"`
+	Error         string                     `db:"error" json:"error"`
+	CreatedAt     time.Time                  `db:"created_at" json:"created_at"`
 }
 
-func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error) {
-	row := q.db.QueryRowContext(ctx, insertWorkspaceApp,
-		arg.ID,
+func (q *sqlQuerier) InsertWorkspaceBatchResult(ctx context.Context, arg InsertWorkspaceBatchResultParams) (WorkspaceBatchResult, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceBatchResult,
+		arg.OperationID,
+		arg.WorkspaceID,
+		arg.WorkspaceName,
+		arg.Action,
+		arg.Status,
+		arg.Error,
 		arg.CreatedAt,
-		arg.AgentID,
-		arg.Name,
-		arg.Icon,
-		arg.Command,
-		arg.Url,
-		arg.Subdomain,
-		arg.HealthcheckUrl,
-		arg.HealthcheckInterval,
-		arg.HealthcheckThreshold,
-		arg.Health,
 	)
-	var i WorkspaceApp
+	var i WorkspaceBatchResult
 	err := row.Scan(
-		&i.ID,
+		&i.OperationID,
+		&i.WorkspaceID,
+		&i.WorkspaceName,
+		&i.Action,
+		&i.Status,
+		&i.BuildID,
+		&i.Error,
 		&i.CreatedAt,
-		&i.AgentID,
-		&i.Name,
-		&i.Icon,
-		&i.Command,
-		&i.Url,
-		&i.HealthcheckUrl,
-		&i.HealthcheckInterval,
-		&i.HealthcheckThreshold,
-		&i.Health,
-		&i.Subdomain,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const updateWorkspaceAppHealthByID = `-- name: UpdateWorkspaceAppHealthByID :exec
+const updateWorkspaceBatchResult = `-- name: UpdateWorkspaceBatchResult :one
 UPDATE
-	workspace_apps
+	workspace_batch_results
 SET
-	health = $2
+	status = $3,
+	build_id = $4,
+	error = $5,
+	updated_at = $6
 WHERE
-	id = $1
+	operation_id = $1
+	AND workspace_id = $2
+RETURNING operation_id, workspace_id, workspace_name, action, status, build_id, error, created_at, updated_at
 `
 
-type UpdateWorkspaceAppHealthByIDParams struct {
-	ID     uuid.UUID          `db:"id" json:"id"`
-	Health WorkspaceAppHealth `db:"health" json:"health"`
+type UpdateWorkspaceBatchResultParams struct {
+	OperationID uuid.UUID                  `db:"operation_id" json:"operation_id"`
+	WorkspaceID uuid.UUID                  `db:"workspace_id" json:"workspace_id"`
+	Status      WorkspaceBatchResultStatus `db:"status" json:"status"`
+	BuildID     uuid.NullUUID              `db:"build_id" json:"build_id"`
+	Error       string                     `db:"error" json:"error"`
+	UpdatedAt   time.Time                  `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceAppHealthByID, arg.ID, arg.Health)
-	return err
+func (q *sqlQuerier) UpdateWorkspaceBatchResult(ctx context.Context, arg UpdateWorkspaceBatchResultParams) (WorkspaceBatchResult, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceBatchResult,
+		arg.OperationID,
+		arg.WorkspaceID,
+		arg.Status,
+		arg.BuildID,
+		arg.Error,
+		arg.UpdatedAt,
+	)
+	var i WorkspaceBatchResult
+	err := row.Scan(
+		&i.OperationID,
+		&i.WorkspaceID,
+		&i.WorkspaceName,
+		&i.Action,
+		&i.Status,
+		&i.BuildID,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const getLatestWorkspaceBuildByWorkspaceID = `-- name: GetLatestWorkspaceBuildByWorkspaceID :one
@@ -4757,6 +10895,85 @@ func (q *sqlQuerier) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Co
 	return i, err
 }
 
+const getWorkspaceBuildsByOrganizationID = `-- name: GetWorkspaceBuildsByOrganizationID :many
+SELECT
+	workspace_builds.workspace_id,
+	workspaces.template_id,
+	workspace_builds.transition,
+	workspace_builds.created_at,
+	provisioner_jobs.completed_at,
+	provisioner_jobs.error
+FROM
+	workspace_builds
+JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+WHERE
+	workspaces.organization_id = $1
+	AND workspace_builds.created_at < $2
+	AND workspace_builds.build_number >= COALESCE((
+		SELECT
+			MAX(previous.build_number)
+		FROM
+			workspace_builds AS previous
+		WHERE
+			previous.workspace_id = workspace_builds.workspace_id
+			AND previous.created_at < $3
+	), 0)
+ORDER BY
+	workspace_builds.workspace_id,
+	workspace_builds.build_number
+`
+
+type GetWorkspaceBuildsByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	EndTime        time.Time `db:"end_time" json:"end_time"`
+	StartTime      time.Time `db:"start_time" json:"start_time"`
+}
+
+type GetWorkspaceBuildsByOrganizationIDRow struct {
+	WorkspaceID uuid.UUID           `db:"workspace_id" json:"workspace_id"`
+	TemplateID  uuid.UUID           `db:"template_id" json:"template_id"`
+	Transition  WorkspaceTransition `db:"transition" json:"transition"`
+	CreatedAt   time.Time           `db:"created_at" json:"created_at"`
+	CompletedAt sql.NullTime        `db:"completed_at" json:"completed_at"`
+	Error       sql.NullString      `db:"error" json:"error"`
+}
+
+// Returns the builds of the workspaces in an organization that were created
+// before the end time, starting with the last build of each workspace before
+// the start time so the state of the workspace at the start is known.
+func (q *sqlQuerier) GetWorkspaceBuildsByOrganizationID(ctx context.Context, arg GetWorkspaceBuildsByOrganizationIDParams) ([]GetWorkspaceBuildsByOrganizationIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBuildsByOrganizationID, arg.OrganizationID, arg.EndTime, arg.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceBuildsByOrganizationIDRow
+	for rows.Next() {
+		var i GetWorkspaceBuildsByOrganizationIDRow
+		if err := rows.Scan(
+			&i.WorkspaceID,
+			&i.TemplateID,
+			&i.Transition,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceBuildsByWorkspaceID = `-- name: GetWorkspaceBuildsByWorkspaceID :many
 SELECT
 	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason
@@ -4977,6 +11194,239 @@ func (q *sqlQuerier) UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWor
 	return err
 }
 
+const accrueWorkspaceCost = `-- name: AccrueWorkspaceCost :exec
+INSERT INTO
+	workspace_costs (workspace_id, owner_id, organization_id, template_id, start_time, cost, running_seconds, accrued_until)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (workspace_id, owner_id, start_time) DO UPDATE SET
+	cost = workspace_costs.cost + $6,
+	running_seconds = workspace_costs.running_seconds + $7,
+	accrued_until = $8
+WHERE
+	workspace_costs.accrued_until < $8
+`
+
+type AccrueWorkspaceCostParams struct {
+	WorkspaceID    uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	OwnerID        uuid.UUID `db:"owner_id" json:"owner_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	StartTime      time.Time `db:"start_time" json:"start_time"`
+	Cost           float64   `db:"cost" json:"cost"`
+	RunningSeconds int64     `db:"running_seconds" json:"running_seconds"`
+	AccruedUntil   time.Time `db:"accrued_until" json:"accrued_until"`
+}
+
+// Adds the cost of running a workspace to the hour it accrued in. Costs that
+// were accrued already, e.g. by another replica, are ignored.
+func (q *sqlQuerier) AccrueWorkspaceCost(ctx context.Context, arg AccrueWorkspaceCostParams) error {
+	_, err := q.db.ExecContext(ctx, accrueWorkspaceCost,
+		arg.WorkspaceID,
+		arg.OwnerID,
+		arg.OrganizationID,
+		arg.TemplateID,
+		arg.StartTime,
+		arg.Cost,
+		arg.RunningSeconds,
+		arg.AccruedUntil,
+	)
+	return err
+}
+
+const getWorkspaceCostsAccruedUntil = `-- name: GetWorkspaceCostsAccruedUntil :many
+SELECT
+	workspace_id,
+	MAX(accrued_until) :: timestamptz AS accrued_until
+FROM
+	workspace_costs
+WHERE
+	workspace_id = ANY($1 :: uuid [ ])
+GROUP BY
+	workspace_id
+`
+
+type GetWorkspaceCostsAccruedUntilRow struct {
+	WorkspaceID  uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	AccruedUntil time.Time `db:"accrued_until" json:"accrued_until"`
+}
+
+// Returns the time up to which the cost of each workspace was accrued.
+func (q *sqlQuerier) GetWorkspaceCostsAccruedUntil(ctx context.Context, workspaceIds []uuid.UUID) ([]GetWorkspaceCostsAccruedUntilRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceCostsAccruedUntil, pq.Array(workspaceIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceCostsAccruedUntilRow
+	for rows.Next() {
+		var i GetWorkspaceCostsAccruedUntilRow
+		if err := rows.Scan(&i.WorkspaceID, &i.AccruedUntil); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceCostsByOrganizationID = `-- name: GetWorkspaceCostsByOrganizationID :many
+SELECT
+	owner_id,
+	template_id,
+	SUM(cost) :: double precision AS cost,
+	SUM(running_seconds) :: bigint AS running_seconds
+FROM
+	workspace_costs
+WHERE
+	organization_id = $1
+	AND start_time >= $2
+	AND start_time < $3
+GROUP BY
+	owner_id, template_id
+ORDER BY
+	owner_id, template_id
+`
+
+type GetWorkspaceCostsByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	StartTime      time.Time `db:"start_time" json:"start_time"`
+	EndTime        time.Time `db:"end_time" json:"end_time"`
+}
+
+type GetWorkspaceCostsByOrganizationIDRow struct {
+	OwnerID        uuid.UUID `db:"owner_id" json:"owner_id"`
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	Cost           float64   `db:"cost" json:"cost"`
+	RunningSeconds int64     `db:"running_seconds" json:"running_seconds"`
+}
+
+// Sums the costs of the organization's workspaces per owner and template over
+// the hours that start in the range.
+func (q *sqlQuerier) GetWorkspaceCostsByOrganizationID(ctx context.Context, arg GetWorkspaceCostsByOrganizationIDParams) ([]GetWorkspaceCostsByOrganizationIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceCostsByOrganizationID, arg.OrganizationID, arg.StartTime, arg.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceCostsByOrganizationIDRow
+	for rows.Next() {
+		var i GetWorkspaceCostsByOrganizationIDRow
+		if err := rows.Scan(
+			&i.OwnerID,
+			&i.TemplateID,
+			&i.Cost,
+			&i.RunningSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteWorkspaceFavoritesByUserID = `-- name: DeleteWorkspaceFavoritesByUserID :exec
+DELETE FROM
+	workspace_favorites
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteWorkspaceFavoritesByUserID(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceFavoritesByUserID, userID)
+	return err
+}
+
+const getWorkspaceFavoritesByUserID = `-- name: GetWorkspaceFavoritesByUserID :many
+SELECT
+	workspace_favorites.user_id, workspace_favorites.workspace_id, workspace_favorites.position, workspace_favorites.created_at
+FROM
+	workspace_favorites
+JOIN
+	workspaces ON workspaces.id = workspace_favorites.workspace_id
+WHERE
+	workspace_favorites.user_id = $1
+	AND workspaces.deleted = false
+ORDER BY
+	workspace_favorites.position ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceFavoritesByUserID(ctx context.Context, userID uuid.UUID) ([]WorkspaceFavorite, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceFavoritesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceFavorite
+	for rows.Next() {
+		var i WorkspaceFavorite
+		if err := rows.Scan(
+			&i.UserID,
+			&i.WorkspaceID,
+			&i.Position,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceFavorite = `-- name: InsertWorkspaceFavorite :one
+INSERT INTO
+	workspace_favorites (
+		user_id,
+		workspace_id,
+		position,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4)
+RETURNING user_id, workspace_id, position, created_at
+`
+
+type InsertWorkspaceFavoriteParams struct {
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	Position    int32     `db:"position" json:"position"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceFavorite(ctx context.Context, arg InsertWorkspaceFavoriteParams) (WorkspaceFavorite, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceFavorite,
+		arg.UserID,
+		arg.WorkspaceID,
+		arg.Position,
+		arg.CreatedAt,
+	)
+	var i WorkspaceFavorite
+	err := row.Scan(
+		&i.UserID,
+		&i.WorkspaceID,
+		&i.Position,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getWorkspaceResourceByID = `-- name: GetWorkspaceResourceByID :one
 SELECT
 	id, created_at, job_id, transition, type, name, hide, icon
@@ -5302,9 +11752,57 @@ func (q *sqlQuerier) InsertWorkspaceResourceMetadata(ctx context.Context, arg In
 	return i, err
 }
 
+const deleteDeletedWorkspacesByOrganizationID = `-- name: DeleteDeletedWorkspacesByOrganizationID :many
+DELETE FROM
+	workspaces
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			workspaces
+		WHERE
+			organization_id = $1
+			AND deleted = true
+		LIMIT
+			$2
+	)
+RETURNING id
+`
+
+type DeleteDeletedWorkspacesByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	LimitOpt       int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+// Removes a batch of deleted workspaces, which are otherwise kept for their
+// history, so the organization can be deleted.
+func (q *sqlQuerier) DeleteDeletedWorkspacesByOrganizationID(ctx context.Context, arg DeleteDeletedWorkspacesByOrganizationIDParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteDeletedWorkspacesByOrganizationID, arg.OrganizationID, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceByID = `-- name: GetWorkspaceByID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
 	workspaces
 WHERE
@@ -5328,13 +11826,52 @@ func (q *sqlQuerier) GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Worksp
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
+const getWorkspaceByIDForUpdate = `-- name: GetWorkspaceByIDForUpdate :one
+SELECT
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+FROM
+	workspaces
+WHERE
+	id = $1
+LIMIT
+	1
+FOR UPDATE
+`
+
+// Locks the workspace until the transaction ends, so concurrent updates to
+// its ACLs aren't lost.
+func (q *sqlQuerier) GetWorkspaceByIDForUpdate(ctx context.Context, id uuid.UUID) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceByIDForUpdate, id)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
 	)
 	return i, err
 }
 
 const getWorkspaceByOwnerIDAndName = `-- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
 	workspaces
 WHERE
@@ -5365,10 +11902,31 @@ func (q *sqlQuerier) GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWo
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
 	)
 	return i, err
 }
 
+const getWorkspaceCountByOrganizationID = `-- name: GetWorkspaceCountByOrganizationID :one
+SELECT
+	COUNT(id)
+FROM
+	workspaces
+WHERE
+	organization_id = $1
+	-- Ignore deleted workspaces
+	AND deleted != true
+`
+
+func (q *sqlQuerier) GetWorkspaceCountByOrganizationID(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceCountByOrganizationID, organizationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getWorkspaceCountByUserID = `-- name: GetWorkspaceCountByUserID :one
 SELECT
 	COUNT(id)
@@ -5406,16 +11964,78 @@ type GetWorkspaceOwnerCountsByTemplateIDsRow struct {
 	Count      int64     `db:"count" json:"count"`
 }
 
-func (q *sqlQuerier) GetWorkspaceOwnerCountsByTemplateIDs(ctx context.Context, ids []uuid.UUID) ([]GetWorkspaceOwnerCountsByTemplateIDsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceOwnerCountsByTemplateIDs, pq.Array(ids))
+func (q *sqlQuerier) GetWorkspaceOwnerCountsByTemplateIDs(ctx context.Context, ids []uuid.UUID) ([]GetWorkspaceOwnerCountsByTemplateIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceOwnerCountsByTemplateIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceOwnerCountsByTemplateIDsRow
+	for rows.Next() {
+		var i GetWorkspaceOwnerCountsByTemplateIDsRow
+		if err := rows.Scan(&i.TemplateID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceQuotaConsumptionByOwnerID = `-- name: GetWorkspaceQuotaConsumptionByOwnerID :many
+SELECT
+	templates.id AS template_id,
+	templates.name AS template_name,
+	templates.organization_id,
+	templates.quota_weight,
+	COUNT(workspaces.id) AS workspace_count
+FROM
+	workspaces
+JOIN
+	templates
+ON
+	templates.id = workspaces.template_id
+WHERE
+	workspaces.owner_id = $1
+	-- Ignore deleted workspaces
+	AND workspaces.deleted != true
+GROUP BY
+	templates.id
+ORDER BY
+	templates.name ASC
+`
+
+type GetWorkspaceQuotaConsumptionByOwnerIDRow struct {
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateName   string    `db:"template_name" json:"template_name"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	QuotaWeight    int32     `db:"quota_weight" json:"quota_weight"`
+	WorkspaceCount int64     `db:"workspace_count" json:"workspace_count"`
+}
+
+// Counts the user's workspaces per template, along with the quota weight
+// each of them costs.
+func (q *sqlQuerier) GetWorkspaceQuotaConsumptionByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]GetWorkspaceQuotaConsumptionByOwnerIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceQuotaConsumptionByOwnerID, ownerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetWorkspaceOwnerCountsByTemplateIDsRow
+	var items []GetWorkspaceQuotaConsumptionByOwnerIDRow
 	for rows.Next() {
-		var i GetWorkspaceOwnerCountsByTemplateIDsRow
-		if err := rows.Scan(&i.TemplateID, &i.Count); err != nil {
+		var i GetWorkspaceQuotaConsumptionByOwnerIDRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.TemplateName,
+			&i.OrganizationID,
+			&i.QuotaWeight,
+			&i.WorkspaceCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -5431,7 +12051,7 @@ func (q *sqlQuerier) GetWorkspaceOwnerCountsByTemplateIDs(ctx context.Context, i
 
 const getWorkspaces = `-- name: GetWorkspaces :many
 SELECT
-    id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+    id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
     workspaces
 WHERE
@@ -5469,15 +12089,28 @@ WHERE
 		    name ILIKE '%' || $6 || '%'
 		ELSE true
 	END
+	-- Filter by labels, matching all of them
+	AND CASE
+		WHEN $7 :: jsonb != '{}' :: jsonb THEN
+			labels @> $7
+		ELSE true
+	END
+	-- GetAuthorizedWorkspaces replaces this comment with the authorize filter.
+	-- @authorize_filter
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($8 :: int, 0)
 `
 
 type GetWorkspacesParams struct {
-	Deleted       bool        `db:"deleted" json:"deleted"`
-	OwnerID       uuid.UUID   `db:"owner_id" json:"owner_id"`
-	OwnerUsername string      `db:"owner_username" json:"owner_username"`
-	TemplateName  string      `db:"template_name" json:"template_name"`
-	TemplateIds   []uuid.UUID `db:"template_ids" json:"template_ids"`
-	Name          string      `db:"name" json:"name"`
+	Deleted       bool            `db:"deleted" json:"deleted"`
+	OwnerID       uuid.UUID       `db:"owner_id" json:"owner_id"`
+	OwnerUsername string          `db:"owner_username" json:"owner_username"`
+	TemplateName  string          `db:"template_name" json:"template_name"`
+	TemplateIds   []uuid.UUID     `db:"template_ids" json:"template_ids"`
+	Name          string          `db:"name" json:"name"`
+	Labels        json.RawMessage `db:"labels" json:"labels"`
+	LimitOpt      int32           `db:"limit_opt" json:"limit_opt"`
 }
 
 func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]Workspace, error) {
@@ -5488,6 +12121,74 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 		arg.TemplateName,
 		pq.Array(arg.TemplateIds),
 		arg.Name,
+		arg.Labels,
+		arg.LimitOpt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.TemplateID,
+			&i.Deleted,
+			&i.Name,
+			&i.AutostartSchedule,
+			&i.Ttl,
+			&i.LastUsedAt,
+			&i.userACL,
+			&i.groupACL,
+			&i.Labels,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspacesByOrganizationID = `-- name: GetWorkspacesByOrganizationID :many
+SELECT
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+FROM
+	workspaces
+WHERE
+	organization_id = $1
+	AND deleted = $2
+	AND id > $3
+ORDER BY
+	id
+LIMIT
+	$4
+`
+
+type GetWorkspacesByOrganizationIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Deleted        bool      `db:"deleted" json:"deleted"`
+	AfterID        uuid.UUID `db:"after_id" json:"after_id"`
+	LimitOpt       int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+// Pages through the workspaces of an organization in the order of their IDs.
+func (q *sqlQuerier) GetWorkspacesByOrganizationID(ctx context.Context, arg GetWorkspacesByOrganizationIDParams) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesByOrganizationID,
+		arg.OrganizationID,
+		arg.Deleted,
+		arg.AfterID,
+		arg.LimitOpt,
 	)
 	if err != nil {
 		return nil, err
@@ -5508,6 +12209,9 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 			&i.AutostartSchedule,
 			&i.Ttl,
 			&i.LastUsedAt,
+			&i.userACL,
+			&i.groupACL,
+			&i.Labels,
 		); err != nil {
 			return nil, err
 		}
@@ -5536,7 +12240,7 @@ INSERT INTO
 		ttl
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 `
 
 type InsertWorkspaceParams struct {
@@ -5576,6 +12280,9 @@ func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspacePar
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -5588,7 +12295,7 @@ SET
 WHERE
 	id = $1
 	AND deleted = false
-RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 `
 
 type UpdateWorkspaceParams struct {
@@ -5611,6 +12318,9 @@ func (q *sqlQuerier) UpdateWorkspace(ctx context.Context, arg UpdateWorkspacePar
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -5653,6 +12363,69 @@ func (q *sqlQuerier) UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateW
 	return err
 }
 
+const updateWorkspaceLabels = `-- name: UpdateWorkspaceLabels :one
+UPDATE
+	workspaces
+SET
+	labels = $2,
+	updated_at = $3
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+`
+
+type UpdateWorkspaceLabelsParams struct {
+	ID        uuid.UUID       `db:"id" json:"id"`
+	Labels    json.RawMessage `db:"labels" json:"labels"`
+	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceLabels(ctx context.Context, arg UpdateWorkspaceLabelsParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceLabels, arg.ID, arg.Labels, arg.UpdatedAt)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
+const updateWorkspaceLabelsByTemplateID = `-- name: UpdateWorkspaceLabelsByTemplateID :exec
+UPDATE
+	workspaces
+SET
+	labels = (labels - $1 :: text[]) || $2 :: jsonb
+WHERE
+	template_id = $3
+	AND deleted = false
+`
+
+type UpdateWorkspaceLabelsByTemplateIDParams struct {
+	RemovedKeys []string        `db:"removed_keys" json:"removed_keys"`
+	Labels      json.RawMessage `db:"labels" json:"labels"`
+	TemplateID  uuid.UUID       `db:"template_id" json:"template_id"`
+}
+
+// Applies the labels of a template to its workspaces. Keys the template no
+// longer sets are removed, labels set by the owners are kept.
+func (q *sqlQuerier) UpdateWorkspaceLabelsByTemplateID(ctx context.Context, arg UpdateWorkspaceLabelsByTemplateIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceLabelsByTemplateID, pq.Array(arg.RemovedKeys), arg.Labels, arg.TemplateID)
+	return err
+}
+
 const updateWorkspaceLastUsedAt = `-- name: UpdateWorkspaceLastUsedAt :exec
 UPDATE
 	workspaces
@@ -5672,6 +12445,93 @@ func (q *sqlQuerier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWo
 	return err
 }
 
+const updateWorkspaceOrganization = `-- name: UpdateWorkspaceOrganization :one
+UPDATE
+	workspaces
+SET
+	organization_id = $2,
+	template_id = $3,
+	updated_at = $4
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+`
+
+type UpdateWorkspaceOrganizationParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	TemplateID     uuid.UUID `db:"template_id" json:"template_id"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceOrganization(ctx context.Context, arg UpdateWorkspaceOrganizationParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceOrganization,
+		arg.ID,
+		arg.OrganizationID,
+		arg.TemplateID,
+		arg.UpdatedAt,
+	)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
+const updateWorkspaceOwner = `-- name: UpdateWorkspaceOwner :one
+UPDATE
+	workspaces
+SET
+	owner_id = $2,
+	updated_at = $3
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+`
+
+type UpdateWorkspaceOwnerParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	OwnerID   uuid.UUID `db:"owner_id" json:"owner_id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceOwner(ctx context.Context, arg UpdateWorkspaceOwnerParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceOwner, arg.ID, arg.OwnerID, arg.UpdatedAt)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.userACL,
+		&i.groupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
 const updateWorkspaceTTL = `-- name: UpdateWorkspaceTTL :exec
 UPDATE
 	workspaces
@@ -5690,3 +12550,103 @@ func (q *sqlQuerier) UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspace
 	_, err := q.db.ExecContext(ctx, updateWorkspaceTTL, arg.ID, arg.Ttl)
 	return err
 }
+
+const getWorkspaceTimelineEventsByWorkspaceID = `-- name: GetWorkspaceTimelineEventsByWorkspaceID :many
+SELECT
+	id, workspace_id, type, agent_id, user_id, app_name, created_at
+FROM
+	workspace_timeline_events
+WHERE
+	workspace_id = $1
+	AND created_at >= $2 :: timestamptz
+	AND created_at < $3 :: timestamptz
+ORDER BY
+	created_at DESC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($4 :: int, 0)
+`
+
+type GetWorkspaceTimelineEventsByWorkspaceIDParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	StartTime   time.Time `db:"start_time" json:"start_time"`
+	EndTime     time.Time `db:"end_time" json:"end_time"`
+	LimitOpt    int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+func (q *sqlQuerier) GetWorkspaceTimelineEventsByWorkspaceID(ctx context.Context, arg GetWorkspaceTimelineEventsByWorkspaceIDParams) ([]WorkspaceTimelineEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceTimelineEventsByWorkspaceID,
+		arg.WorkspaceID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.LimitOpt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceTimelineEvent
+	for rows.Next() {
+		var i WorkspaceTimelineEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceID,
+			&i.Type,
+			&i.AgentID,
+			&i.UserID,
+			&i.AppName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceTimelineEvent = `-- name: InsertWorkspaceTimelineEvent :one
+INSERT INTO
+	workspace_timeline_events (id, workspace_id, type, agent_id, user_id, app_name, created_at)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, workspace_id, type, agent_id, user_id, app_name, created_at
+`
+
+type InsertWorkspaceTimelineEventParams struct {
+	ID          uuid.UUID                  `db:"id" json:"id"`
+	WorkspaceID uuid.UUID                  `db:"workspace_id" json:"workspace_id"`
+	Type        WorkspaceTimelineEventType `db:"type" json:"type"`
+	AgentID     uuid.UUID                  `db:"agent_id" json:"agent_id"`
+	UserID      uuid.NullUUID              `db:"user_id" json:"user_id"`
+	AppName     string                     `db:"app_name" json:"app_name"`
+	CreatedAt   time.Time                  `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceTimelineEvent(ctx context.Context, arg InsertWorkspaceTimelineEventParams) (WorkspaceTimelineEvent, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceTimelineEvent,
+		arg.ID,
+		arg.WorkspaceID,
+		arg.Type,
+		arg.AgentID,
+		arg.UserID,
+		arg.AppName,
+		arg.CreatedAt,
+	)
+	var i WorkspaceTimelineEvent
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.Type,
+		&i.AgentID,
+		&i.UserID,
+		&i.AppName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -27,5 +27,20 @@ group by
 order by
 	date asc;
 
+-- name: GetAgentStatUsersByOrganizationID :many
+-- Returns every user that used a workspace of a template in the organization
+-- within the time range, once per template.
+SELECT DISTINCT
+	agent_stats.template_id,
+	agent_stats.user_id
+FROM
+	agent_stats
+JOIN
+	templates ON templates.id = agent_stats.template_id
+WHERE
+	templates.organization_id = @organization_id
+	AND agent_stats.created_at >= @start_time
+	AND agent_stats.created_at < @end_time;
+
 -- name: DeleteOldAgentStats :exec
 DELETE FROM AGENT_STATS WHERE created_at  < now() - interval '30 days';
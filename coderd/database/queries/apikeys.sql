@@ -27,7 +27,9 @@ INSERT INTO
 		created_at,
 		updated_at,
 		login_type,
-		scope
+		scope,
+		scope_permissions,
+		scope_organization_id
 	)
 VALUES
 	(@id,
@@ -36,7 +38,7 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE @lifetime_seconds::bigint
 	 END
-	 , @hashed_secret, @ip_address, @user_id, @last_used, @expires_at, @created_at, @updated_at, @login_type, @scope) RETURNING *;
+	 , @hashed_secret, @ip_address, @user_id, @last_used, @expires_at, @created_at, @updated_at, @login_type, @scope, @scope_permissions, @scope_organization_id) RETURNING *;
 
 -- name: UpdateAPIKeyByID :exec
 UPDATE
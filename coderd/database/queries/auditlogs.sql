@@ -50,8 +50,28 @@ WHERE
 			users.email = @email
 		ELSE true
 	END
+	-- Filter by organization_id
+	AND CASE
+		WHEN @organization_id :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_id = @organization_id
+		ELSE true
+	END
+	-- This allows using the last element on a page as effectively a cursor.
+	AND CASE
+		WHEN @after_id :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(audit_logs."time", audit_logs.id) < (
+				SELECT
+					"time", id
+				FROM
+					audit_logs
+				WHERE
+					id = @after_id
+			)
+		)
+		ELSE true
+	END
 ORDER BY
-    "time" DESC
+    "time" DESC, audit_logs.id DESC
 LIMIT
     $1
 OFFSET
@@ -98,6 +118,12 @@ WHERE
 		WHEN @email :: text != '' THEN
 			user_id = (SELECT id from users WHERE users.email = @email )
 		ELSE true
+	END
+	-- Filter by organization_id
+	AND CASE
+		WHEN @organization_id :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_id = @organization_id
+		ELSE true
 	END;
 
 -- name: InsertAuditLog :one
@@ -0,0 +1,27 @@
+-- name: InsertEveryoneGroupExclusion :exec
+INSERT INTO everyone_group_exclusions (
+	organization_id,
+	user_id,
+	created_at
+)
+VALUES
+	($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetEveryoneGroupExclusionsByOrganizationID :many
+SELECT
+	*
+FROM
+	everyone_group_exclusions
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: DeleteEveryoneGroupExclusion :exec
+DELETE FROM
+	everyone_group_exclusions
+WHERE
+	organization_id = $1
+AND
+	user_id = $2;
@@ -0,0 +1,33 @@
+-- name: InsertGroupJoinRequest :one
+INSERT INTO group_join_requests (
+	id,
+	group_id,
+	user_id,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4) RETURNING *;
+
+-- name: GetGroupJoinRequestByID :one
+SELECT
+	*
+FROM
+	group_join_requests
+WHERE
+	id = $1;
+
+-- name: GetGroupJoinRequestsByGroupID :many
+SELECT
+	*
+FROM
+	group_join_requests
+WHERE
+	group_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: DeleteGroupJoinRequestByID :exec
+DELETE FROM
+	group_join_requests
+WHERE
+	id = $1;
@@ -14,9 +14,12 @@ SELECT
 FROM
 	groups
 WHERE
-	organization_id = $1
+	-- Deployment-wide groups are matched when organization_id is null.
+	organization_id IS NOT DISTINCT FROM $1
 AND
 	name = $2
+AND
+	deleted_at IS NULL
 LIMIT
 	1;
 
@@ -30,7 +33,11 @@ JOIN
 ON
 	groups.id = group_members.group_id
 WHERE
-	group_members.user_id = $1;
+	group_members.user_id = $1
+AND
+	groups.deleted_at IS NULL
+ORDER BY
+	groups.name ASC;
 
 -- name: GetGroupMembers :many
 SELECT
@@ -60,24 +67,139 @@ ON
 WHERE
 	organization_members.organization_id = $1;
 
+-- name: GetEveryoneGroupMembers :many
+-- Returns the members of the organization's "Everyone" group, which is every
+-- organization member that hasn't been excluded from it.
+SELECT
+	users.*
+FROM
+	users
+JOIN
+	organization_members
+ON
+	users.id = organization_members.user_id
+WHERE
+	organization_members.organization_id = $1
+AND
+	NOT EXISTS (
+		SELECT
+			1
+		FROM
+			everyone_group_exclusions
+		WHERE
+			everyone_group_exclusions.organization_id = organization_members.organization_id
+		AND
+			everyone_group_exclusions.user_id = users.id
+	);
+
 -- name: GetGroupsByOrganizationID :many
 SELECT
 	*
 FROM
 	groups
 WHERE
-	organization_id = $1
+	organization_id = @organization_id :: uuid
 AND
-	id != $1;
+	id != @organization_id
+AND
+	deleted_at IS NULL;
+
+-- name: GetGroupsWithRoles :many
+-- Returns the groups that grant roles to their members, across all
+-- organizations.
+SELECT
+	*
+FROM
+	groups
+WHERE
+	cardinality(roles) > 0
+AND
+	deleted_at IS NULL
+ORDER BY
+	organization_id NULLS FIRST, name;
+
+-- name: GetGroups :many
+SELECT
+	*
+FROM
+	(
+		SELECT
+			*,
+			-- The number of groups matching the filters, ignoring the cursor,
+			-- offset, and limit, so every page reports the same total.
+			COUNT(*) OVER() AS count
+		FROM
+			groups
+		WHERE
+			-- Deployment-wide groups are listed when organization_id is null.
+			organization_id IS NOT DISTINCT FROM @organization_id
+			-- The "Everyone" group shares its ID with the organization and is
+			-- never listed.
+			AND id IS DISTINCT FROM @organization_id
+			AND deleted_at IS NULL
+			-- Filter by name
+			AND CASE
+				WHEN @search :: text != '' THEN
+					name ILIKE concat('%', @search, '%')
+				ELSE true
+			END
+			-- GetAuthorizedGroups replaces this comment with the authorize filter.
+			-- @authorize_filter
+	) AS filtered_groups
+WHERE
+	CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		WHEN @after_id :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(name, id) > (
+				SELECT
+					name, id
+				FROM
+					groups
+				WHERE
+					id = @after_id
+			)
+		)
+		ELSE true
+	END
+ORDER BY
+	(name, id) ASC OFFSET @offset_opt
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
+
+-- name: SearchGroups :many
+-- Returns the groups across all organizations with a name or display name
+-- containing the search term. The "Everyone" groups aren't included.
+SELECT
+	*
+FROM
+	groups
+WHERE
+	deleted_at IS NULL
+	AND id IS DISTINCT FROM organization_id
+	AND (
+		name ILIKE concat('%', @search :: text, '%')
+		OR display_name ILIKE concat('%', @search :: text, '%')
+	)
+ORDER BY
+	name, id
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
 
 -- name: InsertGroup :one
 INSERT INTO groups (
 	id,
 	name,
-	organization_id
+	organization_id,
+	parent_id,
+	display_name,
+	avatar_url,
+	description,
+	source
 )
 VALUES
-	( $1, $2, $3) RETURNING *;
+	( $1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
 
 -- We use the organization_id as the id
 -- for simplicity since all users is 
@@ -95,17 +217,56 @@ VALUES
 UPDATE
 	groups
 SET
-	name = $1
+	name = $1,
+	parent_id = $2,
+	display_name = $3,
+	avatar_url = $4,
+	description = $5,
+	metadata = $6,
+	autostop_schedule = $7,
+	max_ttl = $8,
+	quota_allowance = $9,
+	roles = $10
+WHERE
+	id = $11
+RETURNING *;
+
+-- name: UpdateGroupDeletedAtByID :one
+UPDATE
+	groups
+SET
+	deleted_at = $1
 WHERE
 	id = $2
 RETURNING *;
 
 -- name: InsertGroupMember :exec
+INSERT INTO group_members (
+	user_id,
+	group_id,
+	expires_at
+)
+VALUES ( $1, $2, $3);
+
+-- name: InsertGroupMembers :many
 INSERT INTO group_members (
 	user_id,
 	group_id
 )
-VALUES ( $1, $2);
+SELECT
+	unnest(@user_ids :: uuid [ ]),
+	@group_id
+ON CONFLICT DO NOTHING
+RETURNING user_id;
+
+-- name: DeleteGroupMembersExceptUserIDs :many
+DELETE FROM
+	group_members
+WHERE
+	group_id = @group_id
+AND
+	NOT (user_id = ANY(@user_ids :: uuid [ ]))
+RETURNING user_id;
 
 -- name: DeleteGroupMember :exec
 DELETE FROM 
@@ -113,10 +274,295 @@ DELETE FROM
 WHERE
 	user_id = $1;
 
--- name: DeleteGroupByID :exec
-DELETE FROM 
-	groups 
+-- name: UpdateGroupMemberRoles :one
+UPDATE
+	group_members
+SET
+	-- Remove all duplicates from the roles.
+	roles = ARRAY(SELECT DISTINCT UNNEST(@granted_roles :: text[]))
+WHERE
+	user_id = @user_id
+	AND group_id = @group_id
+RETURNING *;
+
+-- name: DeleteExpiredGroupMembers :many
+DELETE FROM
+	group_members
 WHERE
-	id = $1;
+	expires_at <= NOW()
+RETURNING *;
 
+-- name: DeleteUserFromGroups :many
+DELETE FROM
+	group_members
+WHERE
+	user_id = @user_id
+AND
+	group_id = ANY(@group_ids :: uuid [ ])
+RETURNING group_id;
+
+-- name: DeleteGroupsDeletedBefore :many
+-- Permanently removes groups that were soft deleted before the given time.
+DELETE FROM
+	groups
+WHERE
+	deleted_at < @deleted_before :: timestamptz
+RETURNING *;
 
+-- name: GetGroupMembershipsByUserIDs :many
+SELECT
+	group_members.user_id,
+	groups.id AS group_id,
+	groups.name AS group_name
+FROM
+	group_members
+JOIN
+	groups
+ON
+	groups.id = group_members.group_id
+WHERE
+	groups.organization_id = @organization_id :: uuid
+AND
+	groups.deleted_at IS NULL
+AND
+	group_members.user_id = ANY(@user_ids :: uuid [ ]);
+
+-- name: GetGroupMemberIDsWithRole :many
+SELECT
+	user_id
+FROM
+	group_members
+WHERE
+	group_id = @group_id
+AND
+	@role :: text = ANY(roles);
+
+-- name: GetGroupMembersByGroupIDs :many
+SELECT
+	group_members.group_id,
+	group_members.expires_at,
+	group_members.roles AS group_roles,
+	users.*
+FROM
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = ANY(@group_ids :: uuid [ ])
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false';
+
+-- name: GetGroupMembersPage :many
+-- Returns the active members of a group ordered by username, a page at a
+-- time.
+SELECT
+	group_members.expires_at,
+	group_members.roles AS group_roles,
+	users.*
+FROM
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = @group_id
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
+AND CASE
+	-- This allows using the last element on a page as effectively a cursor.
+	WHEN @after_id :: uuid != '00000000-00000000-00000000-00000000' THEN (
+		(users.username, users.id) > (
+			SELECT
+				username, id
+			FROM
+				users
+			WHERE
+				id = @after_id
+		)
+	)
+	ELSE true
+END
+ORDER BY
+	(users.username, users.id) ASC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
+
+-- name: GetGroupMemberCountsByGroupIDs :many
+-- Counts the members returned by GetGroupMembersByGroupIDs without fetching
+-- them. Groups without members are omitted.
+SELECT
+	group_members.group_id,
+	COUNT(*) AS count
+FROM
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = ANY(@group_ids :: uuid [ ])
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
+GROUP BY
+	group_members.group_id;
+
+-- name: GetGroupAncestorIDs :many
+-- Returns the IDs of the group and every group above it in the hierarchy.
+WITH RECURSIVE ancestors AS (
+	SELECT
+		id,
+		parent_id
+	FROM
+		groups
+	WHERE
+		groups.id = @group_id
+	UNION
+	SELECT
+		groups.id,
+		groups.parent_id
+	FROM
+		groups
+	JOIN
+		ancestors
+	ON
+		groups.id = ancestors.parent_id
+)
+SELECT
+	id
+FROM
+	ancestors;
+
+-- name: GetGroupAutostopUserIDs :many
+-- Returns the users at least one group with an autostop policy applies to:
+-- unexpired members of the group or of the groups nested beneath it, and
+-- the members of the organization for its "Everyone" group.
+WITH RECURSIVE policy_groups AS (
+	SELECT
+		id
+	FROM
+		groups
+	WHERE
+		deleted_at IS NULL
+	AND
+		(autostop_schedule != '' OR max_ttl > 0)
+	UNION
+	SELECT
+		groups.id
+	FROM
+		groups
+	JOIN
+		policy_groups
+	ON
+		groups.parent_id = policy_groups.id
+	WHERE
+		groups.deleted_at IS NULL
+)
+SELECT
+	group_members.user_id
+FROM
+	group_members
+WHERE
+	group_members.group_id IN (SELECT id FROM policy_groups)
+AND
+	(group_members.expires_at IS NULL OR group_members.expires_at > NOW())
+UNION
+SELECT
+	organization_members.user_id
+FROM
+	organization_members
+WHERE
+	organization_members.organization_id IN (SELECT id FROM policy_groups)
+AND NOT EXISTS (
+	SELECT
+		1
+	FROM
+		everyone_group_exclusions
+	WHERE
+		everyone_group_exclusions.organization_id = organization_members.organization_id
+	AND
+		everyone_group_exclusions.user_id = organization_members.user_id
+);
+
+-- name: GetUserEffectiveGroups :many
+-- Returns the groups whose policies apply to the user in the organization:
+-- the groups the user is an unexpired member of and the groups above them,
+-- limited to the organization's groups and deployment-wide groups, and the
+-- organization's "Everyone" group unless the user has been excluded from it.
+WITH RECURSIVE user_groups AS (
+	SELECT
+		group_members.group_id AS id
+	FROM
+		group_members
+	JOIN
+		groups
+	ON
+		groups.id = group_members.group_id
+	WHERE
+		group_members.user_id = @user_id :: uuid
+	AND
+		(group_members.expires_at IS NULL OR group_members.expires_at > NOW())
+	AND
+		groups.deleted_at IS NULL
+	UNION
+	SELECT
+		parents.id
+	FROM
+		groups
+	JOIN
+		user_groups
+	ON
+		groups.id = user_groups.id
+	JOIN
+		groups parents
+	ON
+		parents.id = groups.parent_id
+	WHERE
+		parents.deleted_at IS NULL
+)
+SELECT
+	groups.*
+FROM
+	groups
+WHERE
+	groups.deleted_at IS NULL
+AND (
+	(
+		groups.id IN (SELECT id FROM user_groups)
+		AND (groups.organization_id IS NULL OR groups.organization_id = @organization_id :: uuid)
+	)
+	OR (
+		groups.id = @organization_id :: uuid
+		AND EXISTS (
+			SELECT
+				1
+			FROM
+				organization_members
+			WHERE
+				organization_id = @organization_id :: uuid
+			AND
+				user_id = @user_id :: uuid
+		)
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				everyone_group_exclusions
+			WHERE
+				organization_id = @organization_id :: uuid
+			AND
+				user_id = @user_id :: uuid
+		)
+	)
+)
+ORDER BY
+	groups.name ASC;
@@ -0,0 +1,34 @@
+-- name: InsertGroupWebhook :one
+INSERT INTO group_webhooks (
+	id,
+	organization_id,
+	url,
+	secret,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetGroupWebhookByID :one
+SELECT
+	*
+FROM
+	group_webhooks
+WHERE
+	id = $1;
+
+-- name: GetGroupWebhooksByOrganizationID :many
+SELECT
+	*
+FROM
+	group_webhooks
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: DeleteGroupWebhookByID :exec
+DELETE FROM
+	group_webhooks
+WHERE
+	id = $1;
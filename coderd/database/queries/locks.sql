@@ -0,0 +1,4 @@
+-- name: AcquireLock :exec
+-- Blocks until the lock is acquired. The lock is released when the
+-- transaction ends, so this must be called in a transaction.
+SELECT pg_advisory_xact_lock($1);
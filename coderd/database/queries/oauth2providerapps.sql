@@ -0,0 +1,93 @@
+-- name: DeleteOAuth2ProviderAppAPIKeysByAppID :exec
+DELETE FROM
+	api_keys
+WHERE
+	id IN (
+		SELECT
+			api_key_id
+		FROM
+			oauth2_provider_app_tokens
+		WHERE
+			app_id = $1
+	);
+
+-- name: DeleteOAuth2ProviderAppByID :exec
+DELETE FROM
+	oauth2_provider_apps
+WHERE
+	id = $1;
+
+-- name: DeleteOAuth2ProviderAppCodeByHashedSecret :one
+-- Codes can only be exchanged once, so they're deleted when they're used.
+DELETE FROM
+	oauth2_provider_app_codes
+WHERE
+	hashed_secret = $1
+RETURNING *;
+
+-- name: GetOAuth2ProviderAppByID :one
+SELECT
+	*
+FROM
+	oauth2_provider_apps
+WHERE
+	id = $1;
+
+-- name: GetOAuth2ProviderAppTokenByAPIKeyID :one
+SELECT
+	*
+FROM
+	oauth2_provider_app_tokens
+WHERE
+	api_key_id = $1;
+
+-- name: GetOAuth2ProviderApps :many
+SELECT
+	*
+FROM
+	oauth2_provider_apps
+ORDER BY
+	name ASC;
+
+-- name: InsertOAuth2ProviderApp :one
+INSERT INTO
+	oauth2_provider_apps (id, created_at, updated_at, name, icon, callback_url, hashed_secret)
+VALUES
+	($1, $2, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: InsertOAuth2ProviderAppCode :one
+INSERT INTO
+	oauth2_provider_app_codes (id, created_at, expires_at, hashed_secret, app_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: InsertOAuth2ProviderAppToken :one
+INSERT INTO
+	oauth2_provider_app_tokens (api_key_id, app_id, created_at)
+VALUES
+	($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateOAuth2ProviderAppByID :one
+UPDATE
+	oauth2_provider_apps
+SET
+	updated_at = $2,
+	name = $3,
+	icon = $4,
+	callback_url = $5
+WHERE
+	id = $1
+RETURNING *;
+
+-- name: UpdateOAuth2ProviderAppSecretByID :one
+UPDATE
+	oauth2_provider_apps
+SET
+	updated_at = $2,
+	hashed_secret = $3
+WHERE
+	id = $1
+RETURNING *;
@@ -0,0 +1,39 @@
+-- name: GetOperationByID :one
+SELECT
+	*
+FROM
+	operations
+WHERE
+	id = $1;
+
+-- name: GetOperationsByTypeAndStatus :many
+SELECT
+	*
+FROM
+	operations
+WHERE
+	type = $1
+	AND status = $2
+ORDER BY
+	created_at ASC;
+
+-- name: InsertOperation :one
+INSERT INTO
+	operations (id, type, status, initiator_id, organization_id, resource_id, created_at, updated_at)
+VALUES
+	($1, $2, 'running', $3, $4, $5, $6, $6)
+RETURNING *;
+
+-- name: UpdateOperationByID :one
+UPDATE
+	operations
+SET
+	status = $2,
+	progress_completed = $3,
+	progress_total = $4,
+	error = $5,
+	updated_at = $6,
+	completed_at = $7
+WHERE
+	id = $1
+RETURNING *;
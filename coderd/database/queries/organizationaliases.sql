@@ -0,0 +1,21 @@
+-- name: GetOrganizationAliasByName :one
+SELECT
+	*
+FROM
+	organization_aliases
+WHERE
+	LOWER("name") = LOWER(@name)
+LIMIT
+	1;
+
+-- name: InsertOrganizationAlias :one
+INSERT INTO
+	organization_aliases (name, organization_id, created_at)
+VALUES
+	($1, $2, $3) RETURNING *;
+
+-- name: DeleteOrganizationAliasByName :exec
+DELETE FROM
+	organization_aliases
+WHERE
+	LOWER("name") = LOWER(@name);
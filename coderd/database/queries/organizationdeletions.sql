@@ -0,0 +1,75 @@
+-- name: GetOrganizationDeletionByOrganizationID :one
+SELECT
+	*
+FROM
+	organization_deletions
+WHERE
+	organization_id = $1;
+
+-- name: AcquireOrganizationDeletions :many
+-- Claims the running deletions that no replica works on, because their
+-- replica stopped recording heartbeats before @stale_before. Concurrent
+-- claims of the same deletion only succeed once.
+UPDATE
+	organization_deletions
+SET
+	worker_id = @worker_id :: uuid,
+	heartbeat_at = @now :: timestamptz
+WHERE
+	status = 'running'
+	AND (
+		heartbeat_at IS NULL
+		OR heartbeat_at < @stale_before :: timestamptz
+	)
+RETURNING *;
+
+-- name: InsertOrganizationDeletion :one
+-- Restarts deletions that failed. Returns no rows if the organization is
+-- already being deleted. The deletion is claimed by the worker.
+INSERT INTO
+	organization_deletions (organization_id, initiator_id, status, created_at, updated_at, operation_id, worker_id, heartbeat_at)
+VALUES
+	($1, $2, 'running', $3, $3, $4, $5, $3)
+ON CONFLICT (organization_id) DO UPDATE SET
+	initiator_id = $2,
+	status = 'running',
+	error = '',
+	updated_at = $3,
+	completed_at = NULL,
+	operation_id = $4,
+	worker_id = $5,
+	heartbeat_at = $3
+WHERE
+	organization_deletions.status != 'running'
+RETURNING *;
+
+-- name: UpdateOrganizationDeletionByOrganizationID :one
+-- Returns no rows if the deletion was claimed by another worker.
+UPDATE
+	organization_deletions
+SET
+	status = $3,
+	error = $4,
+	workspaces_total = $5,
+	workspaces_deleted = $6,
+	templates_total = $7,
+	templates_deleted = $8,
+	members_total = $9,
+	members_deleted = $10,
+	updated_at = $11,
+	completed_at = $12
+WHERE
+	organization_id = $1
+	AND worker_id = $2
+RETURNING *;
+
+-- name: UpdateOrganizationDeletionHeartbeat :one
+-- Returns no rows if the deletion was claimed by another worker.
+UPDATE
+	organization_deletions
+SET
+	heartbeat_at = $3
+WHERE
+	organization_id = $1
+	AND worker_id = $2
+RETURNING *;
@@ -0,0 +1,49 @@
+-- name: InsertOrganizationInvite :one
+INSERT INTO
+	organization_invites (id, organization_id, hashed_token, email_domain, roles, created_by, created_at, expires_at, max_uses)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+
+-- name: GetOrganizationInvitesByOrganizationID :many
+SELECT
+	*
+FROM
+	organization_invites
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: GetOrganizationInviteByID :one
+SELECT
+	*
+FROM
+	organization_invites
+WHERE
+	id = $1;
+
+-- name: GetOrganizationInviteByHashedToken :one
+SELECT
+	*
+FROM
+	organization_invites
+WHERE
+	hashed_token = $1;
+
+-- UseOrganizationInvite counts a use of the invite, and returns no rows if
+-- the invite has no uses left.
+-- name: UseOrganizationInvite :one
+UPDATE
+	organization_invites
+SET
+	uses = uses + 1
+WHERE
+	id = $1
+	AND (max_uses = 0 OR uses < max_uses)
+RETURNING *;
+
+-- name: DeleteOrganizationInviteByID :exec
+DELETE FROM
+	organization_invites
+WHERE
+	id = $1;
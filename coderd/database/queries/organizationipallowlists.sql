@@ -0,0 +1,21 @@
+-- name: GetOrganizationIPAllowlist :one
+SELECT
+	*
+FROM
+	organization_ip_allowlists
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationIPAllowlist :one
+INSERT INTO
+	organization_ip_allowlists (
+		organization_id,
+		cidrs,
+		updated_at
+	)
+VALUES
+	($1, $2, $3)
+ON CONFLICT (organization_id) DO UPDATE SET
+	cidrs = $2,
+	updated_at = $3
+RETURNING *;
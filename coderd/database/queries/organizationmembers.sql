@@ -50,3 +50,100 @@ WHERE
 	user_id = @user_id
 	AND organization_id = @org_id
 RETURNING *;
+
+-- name: GetOrganizationMembers :many
+SELECT
+	organization_members.user_id,
+	organization_members.organization_id,
+	organization_members.created_at,
+	organization_members.updated_at,
+	organization_members.roles,
+	users.username,
+	users.email,
+	users.avatar_url
+FROM
+	organization_members
+JOIN
+	users
+ON
+	users.id = organization_members.user_id
+WHERE
+	organization_members.organization_id = @organization_id
+	AND users.deleted = false
+	AND CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		WHEN @after_id :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(organization_members.created_at, organization_members.user_id) > (
+				SELECT
+					created_at, user_id
+				FROM
+					organization_members
+				WHERE
+					organization_id = @organization_id
+					AND user_id = @after_id
+			)
+		)
+		ELSE true
+	END
+	-- Start filters
+	-- Filter by email or username
+	AND CASE
+		WHEN @search :: text != '' THEN (
+			users.email ILIKE concat('%', @search, '%')
+			OR users.username ILIKE concat('%', @search, '%')
+		)
+		ELSE true
+	END
+	-- Filter by organization roles
+	AND CASE
+		WHEN cardinality(@roles :: text[]) > 0 THEN
+			organization_members.roles && @roles :: text[]
+		ELSE true
+	END
+	-- Filter by group membership
+	AND CASE
+		WHEN @group_id :: uuid != '00000000-00000000-00000000-00000000' THEN
+			organization_members.user_id IN (
+				SELECT
+					user_id
+				FROM
+					group_members
+				WHERE
+					group_id = @group_id
+			)
+		ELSE true
+	END
+	-- End of filters
+ORDER BY
+	-- Deterministic and consistent ordering of all members, even if they share
+	-- a timestamp. This is to ensure consistent pagination.
+	(organization_members.created_at, organization_members.user_id) ASC OFFSET @offset_opt
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
+
+-- name: GetOrganizationMemberCountByOrganizationID :one
+SELECT
+	COUNT(user_id)
+FROM
+	organization_members
+WHERE
+	organization_id = $1;
+
+-- name: DeleteOrganizationMembersByOrganizationID :many
+-- Removes a batch of members so the organization can be deleted.
+DELETE FROM
+	organization_members
+WHERE
+	organization_id = @organization_id
+	AND user_id IN (
+		SELECT
+			user_id
+		FROM
+			organization_members
+		WHERE
+			organization_id = @organization_id
+		LIMIT
+			@limit_opt
+	)
+RETURNING user_id;
@@ -0,0 +1,52 @@
+-- name: GetOrganizationOIDCConfigByOrganizationID :one
+SELECT
+	*
+FROM
+	organization_oidc_configs
+WHERE
+	organization_id = $1;
+
+-- name: GetOrganizationOIDCConfigByEmailDomain :one
+SELECT
+	*
+FROM
+	organization_oidc_configs
+WHERE
+	LOWER(@email_domain :: text) = ANY(email_domains)
+LIMIT
+	1;
+
+-- name: UpsertOrganizationOIDCConfig :one
+INSERT INTO
+	organization_oidc_configs (
+		organization_id,
+		issuer_url,
+		client_id,
+		client_secret,
+		scopes,
+		email_domains,
+		username_field,
+		email_field,
+		allow_signups,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (organization_id) DO UPDATE SET
+	issuer_url = $2,
+	client_id = $3,
+	client_secret = $4,
+	scopes = $5,
+	email_domains = $6,
+	username_field = $7,
+	email_field = $8,
+	allow_signups = $9,
+	updated_at = $11
+RETURNING *;
+
+-- name: DeleteOrganizationOIDCConfigByOrganizationID :exec
+DELETE FROM
+	organization_oidc_configs
+WHERE
+	organization_id = $1;
@@ -0,0 +1,55 @@
+-- name: GetOrganizationQuotaByOrganizationID :one
+SELECT
+	*
+FROM
+	organization_quotas
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationQuota :one
+INSERT INTO
+	organization_quotas (organization_id, max_workspaces, max_running_workspaces, compute_credits, updated_at)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (organization_id) DO UPDATE SET
+	max_workspaces = $2,
+	max_running_workspaces = $3,
+	compute_credits = $4,
+	updated_at = $5
+RETURNING *;
+
+-- name: GetOrganizationQuotaConsumption :many
+-- Counts the workspaces of the organization per template, along with how
+-- many of them are running and the quota weight of the template.
+SELECT
+	templates.id AS template_id,
+	templates.name AS template_name,
+	templates.quota_weight,
+	COUNT(workspaces.id) AS workspace_count,
+	COUNT(workspaces.id) FILTER (WHERE latest_build.transition = 'start') AS running_workspace_count
+FROM
+	workspaces
+JOIN
+	templates
+ON
+	templates.id = workspaces.template_id
+LEFT JOIN LATERAL (
+	SELECT
+		transition
+	FROM
+		workspace_builds
+	WHERE
+		workspace_builds.workspace_id = workspaces.id
+	ORDER BY
+		build_number DESC
+	LIMIT
+		1
+) latest_build ON TRUE
+WHERE
+	workspaces.organization_id = @organization_id
+	-- Ignore deleted workspaces
+	AND workspaces.deleted != true
+GROUP BY
+	templates.id
+ORDER BY
+	templates.name ASC;
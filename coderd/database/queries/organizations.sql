@@ -4,6 +4,16 @@ SELECT
 FROM
 	organizations;
 
+-- name: GetDefaultOrganization :one
+SELECT
+	*
+FROM
+	organizations
+WHERE
+	is_default = true
+LIMIT
+	1;
+
 -- name: GetOrganizationByID :one
 SELECT
 	*
@@ -28,7 +38,7 @@ SELECT
 FROM
 	organizations
 WHERE
-	id = (
+	id = ANY(
 		SELECT
 			organization_id
 		FROM
@@ -39,6 +49,26 @@ WHERE
 
 -- name: InsertOrganization :one
 INSERT INTO
-	organizations (id, "name", description, created_at, updated_at)
+	organizations (id, "name", description, created_at, updated_at, is_default)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING *;
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: DeleteOrganization :exec
+DELETE FROM
+	organizations
+WHERE
+	id = $1 AND
+	is_default = false;
+
+-- name: UpdateOrganizationByID :one
+UPDATE
+	organizations
+SET
+	display_name = $2,
+	icon = $3,
+	description = $4,
+	updated_at = $5,
+	name = $6
+WHERE
+	id = $1
+RETURNING *;
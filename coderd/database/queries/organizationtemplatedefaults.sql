@@ -0,0 +1,29 @@
+-- name: GetOrganizationTemplateDefaults :one
+SELECT
+	*
+FROM
+	organization_template_defaults
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationTemplateDefaults :one
+INSERT INTO
+	organization_template_defaults (
+		organization_id,
+		default_ttl,
+		max_ttl,
+		min_autostart_interval,
+		parameter_values,
+		allowed_parameter_values,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (organization_id) DO UPDATE SET
+	default_ttl = $2,
+	max_ttl = $3,
+	min_autostart_interval = $4,
+	parameter_values = $5,
+	allowed_parameter_values = $6,
+	updated_at = $7
+RETURNING *;
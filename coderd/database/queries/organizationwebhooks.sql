@@ -0,0 +1,61 @@
+-- name: InsertOrganizationWebhook :one
+INSERT INTO organization_webhooks (
+	id,
+	organization_id,
+	url,
+	secret,
+	events,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetOrganizationWebhookByID :one
+SELECT
+	*
+FROM
+	organization_webhooks
+WHERE
+	id = $1;
+
+-- name: GetOrganizationWebhooksByOrganizationID :many
+SELECT
+	*
+FROM
+	organization_webhooks
+WHERE
+	organization_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: DeleteOrganizationWebhookByID :exec
+DELETE FROM
+	organization_webhooks
+WHERE
+	id = $1;
+
+-- name: InsertOrganizationWebhookDelivery :one
+INSERT INTO organization_webhook_deliveries (
+	id,
+	webhook_id,
+	event_id,
+	event_type,
+	attempt,
+	status_code,
+	error,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- name: GetOrganizationWebhookDeliveriesByWebhookID :many
+SELECT
+	*
+FROM
+	organization_webhook_deliveries
+WHERE
+	webhook_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	$2;
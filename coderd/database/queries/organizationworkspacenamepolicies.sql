@@ -0,0 +1,25 @@
+-- name: GetOrganizationWorkspaceNamePolicy :one
+SELECT
+	*
+FROM
+	organization_workspace_name_policies
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationWorkspaceNamePolicy :one
+INSERT INTO
+	organization_workspace_name_policies (
+		organization_id,
+		pattern,
+		prefix,
+		reserved_names,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (organization_id) DO UPDATE SET
+	pattern = $2,
+	prefix = $3,
+	reserved_names = $4,
+	updated_at = $5
+RETURNING *;
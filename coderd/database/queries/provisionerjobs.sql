@@ -92,3 +92,19 @@ SET
 	error = $4
 WHERE
 	id = $1;
+
+-- name: UpdateProvisionerJobsOrganizationByWorkspaceID :exec
+-- Moves the jobs of a workspace's builds along with the workspace.
+UPDATE
+	provisioner_jobs
+SET
+	organization_id = @organization_id
+WHERE
+	id IN (
+		SELECT
+			job_id
+		FROM
+			workspace_builds
+		WHERE
+			workspace_id = @workspace_id
+	);
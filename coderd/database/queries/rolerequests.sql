@@ -0,0 +1,51 @@
+-- name: InsertRoleRequest :one
+INSERT INTO role_requests (
+	id,
+	user_id,
+	organization_id,
+	role,
+	reason,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetRoleRequestByID :one
+SELECT
+	*
+FROM
+	role_requests
+WHERE
+	id = $1;
+
+-- name: GetRoleRequestsByUserID :many
+SELECT
+	*
+FROM
+	role_requests
+WHERE
+	user_id = $1
+ORDER BY
+	created_at DESC;
+
+-- name: GetPendingRoleRequests :many
+SELECT
+	*
+FROM
+	role_requests
+WHERE
+	status = 'pending'
+ORDER BY
+	created_at ASC;
+
+-- name: UpdateRoleRequestStatus :one
+UPDATE
+	role_requests
+SET
+	status = @status,
+	reviewed_by = @reviewed_by,
+	reviewed_at = @reviewed_at
+WHERE
+	id = @id
+	AND status = 'pending'
+RETURNING *;
@@ -3,3 +3,10 @@ INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1);
 
 -- name: GetDeploymentID :one
 SELECT value FROM site_configs WHERE key = 'deployment_id';
+
+-- name: GetMaintenance :one
+SELECT value FROM site_configs WHERE key = 'maintenance';
+
+-- name: UpsertMaintenance :exec
+INSERT INTO site_configs (key, value) VALUES ('maintenance', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1;
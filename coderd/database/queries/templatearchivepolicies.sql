@@ -0,0 +1,24 @@
+-- name: GetTemplateArchivePolicies :many
+SELECT
+	*
+FROM
+	template_archive_policies;
+
+-- name: GetTemplateArchivePolicyByTemplateID :one
+SELECT
+	*
+FROM
+	template_archive_policies
+WHERE
+	template_id = $1;
+
+-- name: UpsertTemplateArchivePolicy :one
+INSERT INTO
+	template_archive_policies (template_id, dormancy_threshold, retention, updated_at)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (template_id) DO UPDATE SET
+	dormancy_threshold = $2,
+	retention = $3,
+	updated_at = $4
+RETURNING *;
@@ -0,0 +1,31 @@
+-- name: GetTemplateAutoRebuildPolicies :many
+SELECT
+	*
+FROM
+	template_auto_rebuild_policies;
+
+-- name: GetTemplateAutoRebuildPolicyByTemplateID :one
+SELECT
+	*
+FROM
+	template_auto_rebuild_policies
+WHERE
+	template_id = $1;
+
+-- name: UpsertTemplateAutoRebuildPolicy :one
+INSERT INTO
+	template_auto_rebuild_policies (
+		template_id,
+		failure_threshold,
+		agent_timeout,
+		action,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	failure_threshold = $2,
+	agent_timeout = $3,
+	action = $4,
+	updated_at = $5
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: GetTemplateAutostopExtensionPolicyByTemplateID :one
+SELECT
+	*
+FROM
+	template_autostop_extension_policies
+WHERE
+	template_id = $1;
+
+-- name: UpsertTemplateAutostopExtensionPolicy :one
+INSERT INTO
+	template_autostop_extension_policies (template_id, max_extensions_per_day, max_extension_per_day, updated_at)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (template_id) DO UPDATE SET
+	max_extensions_per_day = $2,
+	max_extension_per_day = $3,
+	updated_at = $4
+RETURNING *;
+
+-- name: DeleteTemplateAutostopExtensionPolicyByTemplateID :exec
+DELETE FROM
+	template_autostop_extension_policies
+WHERE
+	template_id = $1;
@@ -0,0 +1,40 @@
+-- name: GetTemplateDeprecationByTemplateID :one
+SELECT
+	*
+FROM
+	template_deprecations
+WHERE
+	template_id = $1;
+
+-- name: GetTemplateDeprecationsByTemplateIDs :many
+SELECT
+	*
+FROM
+	template_deprecations
+WHERE
+	template_id = ANY(@template_ids :: uuid [ ]);
+
+-- name: UpsertTemplateDeprecation :one
+INSERT INTO
+	template_deprecations (
+		template_id,
+		message,
+		successor_id,
+		parameter_mapping,
+		deprecated_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	message = $2,
+	successor_id = $3,
+	parameter_mapping = $4,
+	updated_at = $5
+RETURNING *;
+
+-- name: DeleteTemplateDeprecationByTemplateID :exec
+DELETE FROM
+	template_deprecations
+WHERE
+	template_id = $1;
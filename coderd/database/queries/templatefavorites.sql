@@ -0,0 +1,30 @@
+-- name: DeleteTemplateFavoritesByUserID :exec
+DELETE FROM
+	template_favorites
+WHERE
+	user_id = $1;
+
+-- name: GetTemplateFavoritesByUserID :many
+SELECT
+	template_favorites.*
+FROM
+	template_favorites
+JOIN
+	templates ON templates.id = template_favorites.template_id
+WHERE
+	template_favorites.user_id = $1
+	AND templates.deleted = false
+ORDER BY
+	template_favorites.position ASC;
+
+-- name: InsertTemplateFavorite :one
+INSERT INTO
+	template_favorites (
+		user_id,
+		template_id,
+		position,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4)
+RETURNING *;
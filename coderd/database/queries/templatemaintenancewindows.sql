@@ -0,0 +1,46 @@
+-- name: GetTemplateMaintenanceWindowByTemplateID :one
+SELECT
+	*
+FROM
+	template_maintenance_windows
+WHERE
+	template_id = $1;
+
+-- name: GetTemplateMaintenanceWindowsByTemplateIDs :many
+SELECT
+	*
+FROM
+	template_maintenance_windows
+WHERE
+	template_id = ANY(@template_ids :: uuid [ ]);
+
+-- name: GetTemplateMaintenanceWindows :many
+-- Returns the maintenance windows of templates that aren't deleted.
+SELECT
+	template_maintenance_windows.*
+FROM
+	template_maintenance_windows
+JOIN
+	templates
+ON
+	templates.id = template_maintenance_windows.template_id
+WHERE
+	templates.deleted = false;
+
+-- name: UpsertTemplateMaintenanceWindow :one
+INSERT INTO
+	template_maintenance_windows (template_id, schedule, duration, exempt_group_ids, updated_at)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	schedule = $2,
+	duration = $3,
+	exempt_group_ids = $4,
+	updated_at = $5
+RETURNING *;
+
+-- name: DeleteTemplateMaintenanceWindowByTemplateID :exec
+DELETE FROM
+	template_maintenance_windows
+WHERE
+	template_id = $1;
@@ -0,0 +1,34 @@
+-- name: GetTemplateResourceCostsByTemplateID :many
+SELECT
+	*
+FROM
+	template_resource_costs
+WHERE
+	template_id = $1
+ORDER BY
+	resource_type ASC;
+
+-- name: GetTemplateResourceCosts :many
+-- Returns the resource costs of templates that aren't deleted.
+SELECT
+	template_resource_costs.*
+FROM
+	template_resource_costs
+JOIN
+	templates
+ON
+	templates.id = template_resource_costs.template_id
+WHERE
+	templates.deleted = false;
+
+-- name: InsertTemplateResourceCost :exec
+INSERT INTO
+	template_resource_costs (template_id, resource_type, hourly_cost)
+VALUES
+	($1, $2, $3);
+
+-- name: DeleteTemplateResourceCostsByTemplateID :exec
+DELETE FROM
+	template_resource_costs
+WHERE
+	template_id = $1;
@@ -28,13 +28,36 @@ WHERE
 			LOWER("name") = LOWER(@exact_name)
 		ELSE true
 	END
+	-- Filter by name, matching on substring
+	AND CASE
+		WHEN @fuzzy_name :: text != '' THEN
+			"name" ILIKE concat('%', @fuzzy_name, '%')
+		ELSE true
+	END
 	-- Filter by ids
 	AND CASE
 		WHEN array_length(@ids :: uuid[], 1) > 0 THEN
 			id = ANY(@ids)
 		ELSE true
 	END
+	-- This allows using the last element on a page as effectively a cursor.
+	AND CASE
+		WHEN @after_id :: uuid != '00000000-00000000-00000000-00000000' THEN (
+			(name, id) > (
+				SELECT
+					name, id
+				FROM
+					templates
+				WHERE
+					id = @after_id
+			)
+		)
+		ELSE true
+	END
 ORDER BY (name, id) ASC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0)
 ;
 
 -- name: GetTemplateByOrganizationAndName :one
@@ -105,3 +128,49 @@ WHERE
 	id = $1
 RETURNING
 	*;
+
+-- name: UpdateTemplateQuotaWeightByID :exec
+UPDATE
+	templates
+SET
+	quota_weight = $2
+WHERE
+	id = $1;
+
+-- name: UpdateTemplateWorkspaceLabelsByID :one
+UPDATE
+	templates
+SET
+	workspace_labels = $2,
+	updated_at = $3
+WHERE
+	id = $1
+RETURNING
+	*;
+
+-- name: GetTemplateCountByOrganizationID :one
+-- Counts deleted templates too, they're kept until the organization is deleted.
+SELECT
+	COUNT(id)
+FROM
+	templates
+WHERE
+	organization_id = $1;
+
+-- name: DeleteTemplatesByOrganizationID :many
+-- Removes a batch of templates along with their versions. The workspaces of
+-- the templates must be removed first.
+DELETE FROM
+	templates
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			templates
+		WHERE
+			organization_id = @organization_id
+		LIMIT
+			@limit_opt
+	)
+RETURNING id;
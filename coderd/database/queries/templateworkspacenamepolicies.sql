@@ -0,0 +1,25 @@
+-- name: GetTemplateWorkspaceNamePolicyByTemplateID :one
+SELECT
+	*
+FROM
+	template_workspace_name_policies
+WHERE
+	template_id = $1;
+
+-- name: UpsertTemplateWorkspaceNamePolicy :one
+INSERT INTO
+	template_workspace_name_policies (
+		template_id,
+		pattern,
+		prefix,
+		reserved_names,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (template_id) DO UPDATE SET
+	pattern = $2,
+	prefix = $3,
+	reserved_names = $4,
+	updated_at = $5
+RETURNING *;
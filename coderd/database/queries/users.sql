@@ -14,6 +14,18 @@ LIMIT
 -- for another user, then be deleted... we still want them to appear!
 SELECT * FROM users WHERE id = ANY(@ids :: uuid [ ]);
 
+-- name: GetUsersByUsernamesOrEmails :many
+SELECT
+	*
+FROM
+	users
+WHERE
+	deleted = false
+	AND (
+		LOWER(username) = ANY(@identifiers :: text [ ])
+		OR LOWER(email) = ANY(@identifiers :: text [ ])
+	);
+
 -- name: GetUserByEmailOrUsername :one
 SELECT
 	*
@@ -173,39 +185,110 @@ WHERE
 -- name: GetAuthorizationUserRoles :one
 -- This function returns roles for authorization purposes. Implied member roles
 -- are included.
+WITH RECURSIVE user_groups AS (
+	-- All groups the user is in, including the ancestors of those groups
+	-- so permissions granted to a parent group are inherited.
+	SELECT
+		group_members.group_id AS id
+	FROM
+		group_members
+	JOIN
+		groups
+	ON
+		groups.id = group_members.group_id
+	WHERE
+		group_members.user_id = @user_id
+	AND
+		(group_members.expires_at IS NULL OR group_members.expires_at > NOW())
+	AND
+		groups.deleted_at IS NULL
+	UNION
+	SELECT
+		parents.id
+	FROM
+		groups
+	JOIN
+		user_groups
+	ON
+		groups.id = user_groups.id
+	JOIN
+		groups parents
+	ON
+		parents.id = groups.parent_id
+	WHERE
+		-- Deleted groups grant nothing, including to the groups
+		-- nested beneath them.
+		parents.deleted_at IS NULL
+)
 SELECT
 	-- username is returned just to help for logging purposes
 	-- status is used to enforce 'suspended' users, as all roles are ignored
 	--	when suspended.
 	id, username, status,
-	-- All user roles, including their org roles.
+	-- All user roles, including their org roles and the roles of their groups.
 	array_cat(
-		-- All users are members
-		array_append(users.rbac_roles, 'member'),
+		array_cat(
+			-- All users are members
+			array_append(users.rbac_roles, 'member'),
+			(
+				SELECT
+					array_agg(org_roles)
+				FROM
+					organization_members,
+					-- All org_members get the org-member role for their orgs
+					unnest(
+						array_append(roles, 'organization-member:' || organization_members.organization_id::text)
+					) AS org_roles
+				WHERE
+					user_id = users.id
+			)
+		),
+		-- Roles assigned to the user's groups. Organization groups only grant
+		-- their roles while the user is a member of the organization.
 		(
 			SELECT
-				array_agg(org_roles)
+				array_agg(group_roles)
 			FROM
-				organization_members,
-				-- All org_members get the org-member role for their orgs
-				unnest(
-					array_append(roles, 'organization-member:' || organization_members.organization_id::text)
-				) AS org_roles
+				groups,
+				unnest(groups.roles) AS group_roles
 			WHERE
-				user_id = users.id
+				groups.id IN (SELECT user_groups.id FROM user_groups)
+			AND
+				(
+					groups.organization_id IS NULL
+					OR EXISTS (
+						SELECT
+							1
+						FROM
+							organization_members
+						WHERE
+							organization_members.organization_id = groups.organization_id
+						AND
+							organization_members.user_id = users.id
+					)
+				)
 		)
 	) :: text[] AS roles,
-	-- All groups the user is in.
 	(
 		SELECT
 			array_agg(
-				group_members.group_id :: text
+				user_groups.id :: text
+			)
+		FROM
+			user_groups
+	) :: text[] AS groups,
+	-- The organizations whose "Everyone" group the user is excluded from, so
+	-- the group's ACL entries don't apply to them.
+	(
+		SELECT
+			array_agg(
+				everyone_group_exclusions.organization_id :: text
 			)
 		FROM
-			group_members
+			everyone_group_exclusions
 		WHERE
 			user_id = users.id
-	) :: text[] AS groups
+	) :: text[] AS everyone_excluded_orgs
 FROM
 	users
 WHERE
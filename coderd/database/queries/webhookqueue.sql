@@ -0,0 +1,54 @@
+-- name: InsertWebhookQueue :one
+INSERT INTO webhook_queue (
+	id,
+	kind,
+	webhook_id,
+	event_id,
+	event_type,
+	payload,
+	run_at,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $7) RETURNING *;
+
+-- name: AcquireWebhookQueue :many
+-- Leases the due items of the kinds until @lease_until, so other replicas
+-- skip them while they're delivered. An item whose attempt doesn't finish,
+-- e.g. because the replica stopped, is acquired again once its lease ends.
+UPDATE
+	webhook_queue
+SET
+	run_at = @lease_until
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			webhook_queue
+		WHERE
+			run_at <= @now
+			AND kind = ANY(@kinds :: text[])
+		ORDER BY
+			run_at ASC
+		LIMIT
+			@limit_opt
+		FOR UPDATE
+			SKIP LOCKED
+	)
+RETURNING *;
+
+-- name: UpdateWebhookQueueByID :exec
+UPDATE
+	webhook_queue
+SET
+	attempts = $2,
+	run_at = $3
+WHERE
+	id = $1;
+
+-- name: DeleteWebhookQueueByID :exec
+DELETE FROM
+	webhook_queue
+WHERE
+	id = $1;
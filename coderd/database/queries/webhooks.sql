@@ -0,0 +1,68 @@
+-- name: InsertWebhook :one
+INSERT INTO webhooks (
+	id,
+	url,
+	secret,
+	events,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetWebhookByID :one
+SELECT
+	*
+FROM
+	webhooks
+WHERE
+	id = $1;
+
+-- name: GetWebhooks :many
+SELECT
+	*
+FROM
+	webhooks
+ORDER BY
+	created_at ASC;
+
+-- name: DeleteWebhookByID :exec
+DELETE FROM
+	webhooks
+WHERE
+	id = $1;
+
+-- name: InsertWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+	id,
+	webhook_id,
+	event_id,
+	event_type,
+	payload,
+	attempt,
+	redelivery,
+	status_code,
+	error,
+	created_at
+)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+
+-- name: GetWebhookDeliveryByID :one
+SELECT
+	*
+FROM
+	webhook_deliveries
+WHERE
+	id = $1;
+
+-- name: GetWebhookDeliveriesByWebhookID :many
+SELECT
+	*
+FROM
+	webhook_deliveries
+WHERE
+	webhook_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	$2;
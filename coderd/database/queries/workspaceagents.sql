@@ -69,6 +69,17 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateWorkspaceAgentAuthToken :exec
+-- Replaces the token of every agent that authenticates with it, which
+-- includes the agents of previous builds of the workspace.
+UPDATE
+	workspace_agents
+SET
+	auth_token = @new_auth_token,
+	updated_at = @updated_at
+WHERE
+	auth_token = @auth_token;
+
 -- name: UpdateWorkspaceAgentVersionByID :exec
 UPDATE
 	workspace_agents
@@ -0,0 +1,21 @@
+-- name: GetWorkspaceAgentStartupScriptResultsByAgentIDs :many
+SELECT
+	*
+FROM
+	workspace_agent_startup_script_results
+WHERE
+	agent_id = ANY(@ids :: uuid [ ]);
+
+-- name: UpsertWorkspaceAgentStartupScriptResult :one
+INSERT INTO
+	workspace_agent_startup_script_results (
+		agent_id,
+		exit_code,
+		completed_at
+	)
+VALUES
+	($1, $2, $3)
+ON CONFLICT (agent_id) DO UPDATE SET
+	exit_code = $2,
+	completed_at = $3
+RETURNING *;
@@ -0,0 +1,33 @@
+-- name: DeleteWorkspaceAgentUsageSamplesBefore :exec
+DELETE FROM
+	workspace_agent_usage_samples
+WHERE
+	agent_id = $1
+	AND created_at < @created_before :: timestamptz;
+
+-- name: GetWorkspaceAgentUsageSamplesByAgentIDs :many
+SELECT
+	*
+FROM
+	workspace_agent_usage_samples
+WHERE
+	agent_id = ANY(@ids :: uuid [ ])
+	AND created_at >= @created_after :: timestamptz
+ORDER BY
+	created_at ASC;
+
+-- name: InsertWorkspaceAgentUsageSample :one
+INSERT INTO
+	workspace_agent_usage_samples (
+		id,
+		agent_id,
+		created_at,
+		cpu_used,
+		cpu_total,
+		memory_used,
+		memory_total,
+		disk_used,
+		disk_total
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
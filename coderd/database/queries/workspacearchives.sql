@@ -0,0 +1,65 @@
+-- name: GetWorkspaceArchiveByWorkspaceID :one
+SELECT
+	*
+FROM
+	workspace_archives
+WHERE
+	workspace_id = $1;
+
+-- name: GetWorkspaceArchivesByStatus :many
+SELECT
+	*
+FROM
+	workspace_archives
+WHERE
+	status = $1;
+
+-- name: GetPurgeableWorkspaceArchives :many
+-- Returns the archives of deleted workspaces, and archives that expired.
+SELECT
+	workspace_archives.*
+FROM
+	workspace_archives
+	INNER JOIN workspaces ON workspaces.id = workspace_archives.workspace_id
+WHERE
+	workspaces.deleted
+	OR (
+		workspace_archives.status = 'archived'
+		AND workspace_archives.expires_at <= @now :: timestamptz
+	);
+
+-- name: UpsertWorkspaceArchive :one
+INSERT INTO
+	workspace_archives (workspace_id, status, object_key, build_id, error, created_at, updated_at, archived_at, expires_at)
+VALUES
+	($1, $2, $3, $4, '', $5, $5, NULL, NULL)
+ON CONFLICT (workspace_id) DO UPDATE SET
+	status = $2,
+	object_key = $3,
+	build_id = $4,
+	error = '',
+	created_at = $5,
+	updated_at = $5,
+	archived_at = NULL,
+	expires_at = NULL
+RETURNING *;
+
+-- name: UpdateWorkspaceArchiveByWorkspaceID :one
+UPDATE
+	workspace_archives
+SET
+	status = $2,
+	build_id = $3,
+	error = $4,
+	updated_at = $5,
+	archived_at = $6,
+	expires_at = $7
+WHERE
+	workspace_id = $1
+RETURNING *;
+
+-- name: DeleteWorkspaceArchiveByWorkspaceID :exec
+DELETE FROM
+	workspace_archives
+WHERE
+	workspace_id = $1;
@@ -0,0 +1,17 @@
+-- name: GetWorkspaceAutostopExtensionsByWorkspaceID :many
+SELECT
+	*
+FROM
+	workspace_autostop_extensions
+WHERE
+	workspace_id = $1
+	AND created_at > @created_after :: timestamptz
+ORDER BY
+	created_at ASC;
+
+-- name: InsertWorkspaceAutostopExtension :one
+INSERT INTO
+	workspace_autostop_extensions (id, workspace_id, user_id, extension, created_at)
+VALUES
+	($1, $2, $3, $4, $5)
+RETURNING *;
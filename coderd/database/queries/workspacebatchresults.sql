@@ -0,0 +1,30 @@
+-- name: GetWorkspaceBatchResultsByOperationID :many
+SELECT
+	*
+FROM
+	workspace_batch_results
+WHERE
+	operation_id = $1
+ORDER BY
+	workspace_name ASC,
+	workspace_id ASC;
+
+-- name: InsertWorkspaceBatchResult :one
+INSERT INTO
+	workspace_batch_results (operation_id, workspace_id, workspace_name, action, status, error, created_at, updated_at)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $7)
+RETURNING *;
+
+-- name: UpdateWorkspaceBatchResult :one
+UPDATE
+	workspace_batch_results
+SET
+	status = $3,
+	build_id = $4,
+	error = $5,
+	updated_at = $6
+WHERE
+	operation_id = $1
+	AND workspace_id = $2
+RETURNING *;
@@ -30,6 +30,39 @@ WHERE
 	workspace_id = $1
 	AND build_number = $2;
 
+-- name: GetWorkspaceBuildsByOrganizationID :many
+-- Returns the builds of the workspaces in an organization that were created
+-- before the end time, starting with the last build of each workspace before
+-- the start time so the state of the workspace at the start is known.
+SELECT
+	workspace_builds.workspace_id,
+	workspaces.template_id,
+	workspace_builds.transition,
+	workspace_builds.created_at,
+	provisioner_jobs.completed_at,
+	provisioner_jobs.error
+FROM
+	workspace_builds
+JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+WHERE
+	workspaces.organization_id = @organization_id
+	AND workspace_builds.created_at < @end_time
+	AND workspace_builds.build_number >= COALESCE((
+		SELECT
+			MAX(previous.build_number)
+		FROM
+			workspace_builds AS previous
+		WHERE
+			previous.workspace_id = workspace_builds.workspace_id
+			AND previous.created_at < @start_time
+	), 0)
+ORDER BY
+	workspace_builds.workspace_id,
+	workspace_builds.build_number;
+
 -- name: GetWorkspaceBuildsByWorkspaceID :many
 SELECT
 	*
@@ -0,0 +1,44 @@
+-- name: AccrueWorkspaceCost :exec
+-- Adds the cost of running a workspace to the hour it accrued in. Costs that
+-- were accrued already, e.g. by another replica, are ignored.
+INSERT INTO
+	workspace_costs (workspace_id, owner_id, organization_id, template_id, start_time, cost, running_seconds, accrued_until)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (workspace_id, owner_id, start_time) DO UPDATE SET
+	cost = workspace_costs.cost + $6,
+	running_seconds = workspace_costs.running_seconds + $7,
+	accrued_until = $8
+WHERE
+	workspace_costs.accrued_until < $8;
+
+-- name: GetWorkspaceCostsAccruedUntil :many
+-- Returns the time up to which the cost of each workspace was accrued.
+SELECT
+	workspace_id,
+	MAX(accrued_until) :: timestamptz AS accrued_until
+FROM
+	workspace_costs
+WHERE
+	workspace_id = ANY(@workspace_ids :: uuid [ ])
+GROUP BY
+	workspace_id;
+
+-- name: GetWorkspaceCostsByOrganizationID :many
+-- Sums the costs of the organization's workspaces per owner and template over
+-- the hours that start in the range.
+SELECT
+	owner_id,
+	template_id,
+	SUM(cost) :: double precision AS cost,
+	SUM(running_seconds) :: bigint AS running_seconds
+FROM
+	workspace_costs
+WHERE
+	organization_id = @organization_id
+	AND start_time >= @start_time
+	AND start_time < @end_time
+GROUP BY
+	owner_id, template_id
+ORDER BY
+	owner_id, template_id;
@@ -0,0 +1,30 @@
+-- name: DeleteWorkspaceFavoritesByUserID :exec
+DELETE FROM
+	workspace_favorites
+WHERE
+	user_id = $1;
+
+-- name: GetWorkspaceFavoritesByUserID :many
+SELECT
+	workspace_favorites.*
+FROM
+	workspace_favorites
+JOIN
+	workspaces ON workspaces.id = workspace_favorites.workspace_id
+WHERE
+	workspace_favorites.user_id = $1
+	AND workspaces.deleted = false
+ORDER BY
+	workspace_favorites.position ASC;
+
+-- name: InsertWorkspaceFavorite :one
+INSERT INTO
+	workspace_favorites (
+		user_id,
+		workspace_id,
+		position,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4)
+RETURNING *;
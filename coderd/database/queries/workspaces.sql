@@ -8,6 +8,19 @@ WHERE
 LIMIT
 	1;
 
+-- name: GetWorkspaceByIDForUpdate :one
+-- Locks the workspace until the transaction ends, so concurrent updates to
+-- its ACLs aren't lost.
+SELECT
+	*
+FROM
+	workspaces
+WHERE
+	id = $1
+LIMIT
+	1
+FOR UPDATE;
+
 -- name: GetWorkspaces :many
 SELECT
     *
@@ -48,7 +61,17 @@ WHERE
 		    name ILIKE '%' || @name || '%'
 		ELSE true
 	END
-;
+	-- Filter by labels, matching all of them
+	AND CASE
+		WHEN @labels :: jsonb != '{}' :: jsonb THEN
+			labels @> @labels
+		ELSE true
+	END
+	-- GetAuthorizedWorkspaces replaces this comment with the authorize filter.
+	-- @authorize_filter
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
 
 -- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
@@ -84,6 +107,40 @@ WHERE
 	-- Ignore deleted workspaces
 	AND deleted != true;
 
+-- name: GetWorkspaceCountByOrganizationID :one
+SELECT
+	COUNT(id)
+FROM
+	workspaces
+WHERE
+	organization_id = @organization_id
+	-- Ignore deleted workspaces
+	AND deleted != true;
+
+-- name: GetWorkspaceQuotaConsumptionByOwnerID :many
+-- Counts the user's workspaces per template, along with the quota weight
+-- each of them costs.
+SELECT
+	templates.id AS template_id,
+	templates.name AS template_name,
+	templates.organization_id,
+	templates.quota_weight,
+	COUNT(workspaces.id) AS workspace_count
+FROM
+	workspaces
+JOIN
+	templates
+ON
+	templates.id = workspaces.template_id
+WHERE
+	workspaces.owner_id = @owner_id
+	-- Ignore deleted workspaces
+	AND workspaces.deleted != true
+GROUP BY
+	templates.id
+ORDER BY
+	templates.name ASC;
+
 -- name: InsertWorkspace :one
 INSERT INTO
 	workspaces (
@@ -141,3 +198,82 @@ SET
 	last_used_at = $2
 WHERE
 	id = $1;
+
+-- name: UpdateWorkspaceOrganization :one
+UPDATE
+	workspaces
+SET
+	organization_id = $2,
+	template_id = $3,
+	updated_at = $4
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING *;
+
+-- name: UpdateWorkspaceOwner :one
+UPDATE
+	workspaces
+SET
+	owner_id = $2,
+	updated_at = $3
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING *;
+
+-- name: UpdateWorkspaceLabels :one
+UPDATE
+	workspaces
+SET
+	labels = $2,
+	updated_at = $3
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING *;
+
+-- name: UpdateWorkspaceLabelsByTemplateID :exec
+-- Applies the labels of a template to its workspaces. Keys the template no
+-- longer sets are removed, labels set by the owners are kept.
+UPDATE
+	workspaces
+SET
+	labels = (labels - @removed_keys :: text[]) || @labels :: jsonb
+WHERE
+	template_id = @template_id
+	AND deleted = false;
+
+-- name: GetWorkspacesByOrganizationID :many
+-- Pages through the workspaces of an organization in the order of their IDs.
+SELECT
+	*
+FROM
+	workspaces
+WHERE
+	organization_id = @organization_id
+	AND deleted = @deleted
+	AND id > @after_id
+ORDER BY
+	id
+LIMIT
+	@limit_opt;
+
+-- name: DeleteDeletedWorkspacesByOrganizationID :many
+-- Removes a batch of deleted workspaces, which are otherwise kept for their
+-- history, so the organization can be deleted.
+DELETE FROM
+	workspaces
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			workspaces
+		WHERE
+			organization_id = @organization_id
+			AND deleted = true
+		LIMIT
+			@limit_opt
+	)
+RETURNING id;
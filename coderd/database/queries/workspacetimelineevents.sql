@@ -0,0 +1,21 @@
+-- name: GetWorkspaceTimelineEventsByWorkspaceID :many
+SELECT
+	*
+FROM
+	workspace_timeline_events
+WHERE
+	workspace_id = $1
+	AND created_at >= @start_time :: timestamptz
+	AND created_at < @end_time :: timestamptz
+ORDER BY
+	created_at DESC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
+
+-- name: InsertWorkspaceTimelineEvent :one
+INSERT INTO
+	workspace_timeline_events (id, workspace_id, type, agent_id, user_id, app_name, created_at)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
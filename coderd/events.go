@@ -0,0 +1,158 @@
+package coderd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	// resourceEventsChannel is the pubsub channel of resource events, so
+	// streams get the events of every coderd replica.
+	resourceEventsChannel = "resource_events"
+	// resourceEventsBufferSize is how many events a stream buffers for a
+	// slow client before it's closed.
+	resourceEventsBufferSize = 64
+)
+
+// resourceEventMessage is published to resourceEventsChannel. The RBAC object
+// of the resource is included so each stream can filter the events its user
+// can read without fetching the resource.
+type resourceEventMessage struct {
+	Event  codersdk.ResourceEvent `json:"event"`
+	Object rbac.Object            `json:"object"`
+}
+
+// PublishResourceEvent notifies the events streams of a change to a resource.
+// Errors are logged, since the change itself already succeeded.
+func (api *API) PublishResourceEvent(ctx context.Context, event codersdk.ResourceEvent, object rbac.Objecter) {
+	event.ID = uuid.New()
+	event.CreatedAt = database.Now()
+	data, err := json.Marshal(resourceEventMessage{
+		Event:  event,
+		Object: object.RBACObject(),
+	})
+	if err != nil {
+		api.Logger.Error(ctx, "marshal resource event", slog.Error(err))
+		return
+	}
+	err = api.Pubsub.Publish(resourceEventsChannel, data)
+	if err != nil {
+		api.Logger.Warn(ctx, "publish resource event", slog.Error(err))
+	}
+}
+
+func (api *API) publishWorkspaceEvent(ctx context.Context, action codersdk.ResourceEventAction, workspace database.Workspace) {
+	api.PublishResourceEvent(ctx, codersdk.ResourceEvent{
+		Type:           codersdk.ResourceEventTypeWorkspace,
+		Action:         action,
+		OrganizationID: workspace.OrganizationID,
+		ResourceID:     workspace.ID,
+	}, workspace)
+}
+
+func (api *API) publishOrganizationMemberEvent(ctx context.Context, action codersdk.ResourceEventAction, organizationID, userID uuid.UUID) {
+	api.PublishResourceEvent(ctx, codersdk.ResourceEvent{
+		Type:           codersdk.ResourceEventTypeOrganizationMember,
+		Action:         action,
+		OrganizationID: organizationID,
+		ResourceID:     userID,
+	}, rbac.ResourceOrganizationMember.InOrg(organizationID))
+}
+
+// resourceEvents streams the events of resources the user can read, so
+// clients don't have to poll for changes. The user's roles are those of when
+// the stream was opened.
+func (api *API) resourceEvents(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	roles := httpmw.UserAuthorization(r)
+
+	events := make(chan resourceEventMessage, resourceEventsBufferSize)
+	overflow := make(chan struct{})
+	// Listeners can run concurrently, so more than one may find the buffer
+	// full.
+	var closeOverflow sync.Once
+	closeSubscribe, err := api.Pubsub.Subscribe(resourceEventsChannel, func(_ context.Context, message []byte) {
+		var event resourceEventMessage
+		err := json.Unmarshal(message, &event)
+		if err != nil {
+			api.Logger.Warn(ctx, "unmarshal resource event", slog.Error(err))
+			return
+		}
+		select {
+		case events <- event:
+		case <-overflow:
+		default:
+			// The listener mustn't block the pubsub, so a client that
+			// can't keep up is disconnected instead.
+			closeOverflow.Do(func() {
+				close(overflow)
+			})
+		}
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error subscribing to resource events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer closeSubscribe()
+
+	sendEvent, err := httpapi.ServerSentEventSender(rw, r)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error setting up server-sent events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Send the headers now, since there may not be an event for a while.
+	rw.WriteHeader(http.StatusOK)
+	rw.(http.Flusher).Flush()
+
+	// Ignore all trace spans after this, they're not too useful.
+	ctx = trace.ContextWithSpan(ctx, tracing.NoopSpan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-overflow:
+			_ = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeError,
+				Data: codersdk.Response{
+					Message: "Events were sent faster than they were read.",
+					Detail:  "Reconnect, and fetch the resources again to catch up.",
+				},
+			})
+			return
+		case event := <-events:
+			// Denials are expected for most events, so the authorizer is
+			// called directly instead of logging each one like Authorize.
+			err := api.Authorizer.ByRoleName(ctx, roles.ID.String(), roles.Roles, roles.Scope, roles.Groups, roles.EveryoneExcluded, rbac.ActionRead, event.Object)
+			if err != nil {
+				continue
+			}
+			err = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeData,
+				Data: event.Event,
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+}
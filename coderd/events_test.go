@@ -0,0 +1,98 @@
+package coderd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestResourceEvents(t *testing.T) {
+	t.Parallel()
+	t.Run("Workspaces", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		ownerEvents, err := client.WatchEvents(ctx)
+		require.NoError(t, err)
+		memberEvents, err := memberClient.WatchEvents(ctx)
+		require.NoError(t, err)
+
+		ownerWorkspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		memberWorkspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+
+		// The owner can read every workspace.
+		for _, workspace := range []codersdk.Workspace{ownerWorkspace, memberWorkspace} {
+			event := <-ownerEvents
+			require.Equal(t, codersdk.ResourceEventTypeWorkspace, event.Type)
+			require.Equal(t, codersdk.ResourceEventActionCreated, event.Action)
+			require.Equal(t, workspace.ID, event.ResourceID)
+			require.Equal(t, user.OrganizationID, event.OrganizationID)
+		}
+
+		// The member only reads their own, so the owner's workspace is
+		// skipped.
+		event := <-memberEvents
+		require.Equal(t, codersdk.ResourceEventTypeWorkspace, event.Type)
+		require.Equal(t, memberWorkspace.ID, event.ResourceID)
+
+		err = memberClient.UpdateWorkspace(ctx, memberWorkspace.ID, codersdk.UpdateWorkspaceRequest{
+			Name: "renamed",
+		})
+		require.NoError(t, err)
+		event = <-memberEvents
+		require.Equal(t, codersdk.ResourceEventActionUpdated, event.Action)
+		require.Equal(t, memberWorkspace.ID, event.ResourceID)
+	})
+
+	t.Run("OrganizationMembers", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		events, err := memberClient.WatchEvents(ctx)
+		require.NoError(t, err)
+
+		_, err = client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "another@coder.com",
+			Username:       "another",
+			Password:       "SomeSecurePassword!",
+			OrganizationID: user.OrganizationID,
+		})
+		require.NoError(t, err)
+
+		event := <-events
+		require.Equal(t, codersdk.ResourceEventTypeOrganizationMember, event.Type)
+		require.Equal(t, codersdk.ResourceEventActionCreated, event.Action)
+		require.Equal(t, user.OrganizationID, event.OrganizationID)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		events, err := client.WatchEvents(ctx)
+		require.NoError(t, err)
+		cancel()
+		_, more := <-events
+		require.False(t, more)
+	})
+}
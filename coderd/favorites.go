@@ -0,0 +1,229 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) favorites(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceUserData.WithOwner(user.ID.String())) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	favorites, err := api.userFavorites(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching favorites.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, favorites)
+}
+
+func (api *API) putFavorites(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceUserData.WithOwner(user.ID.String())) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.Favorites
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// Favorites must exist and be visible to whoever sets them, so IDs of
+	// other users' resources can't be probed.
+	var validErrs []codersdk.ValidationError
+	seen := map[uuid.UUID]bool{}
+	for i, id := range req.WorkspaceIDs {
+		field := fmt.Sprintf("workspace_ids[%d]", i)
+		if seen[id] {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "Workspace is listed more than once."})
+			continue
+		}
+		seen[id] = true
+		workspace, err := api.Database.GetWorkspaceByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (workspace.Deleted || !api.Authorize(r, rbac.ActionRead, workspace))) {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "Workspace not found."})
+			continue
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspace.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	for i, id := range req.TemplateIDs {
+		field := fmt.Sprintf("template_ids[%d]", i)
+		if seen[id] {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "Template is listed more than once."})
+			continue
+		}
+		seen[id] = true
+		template, err := api.Database.GetTemplateByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (template.Deleted || !api.Authorize(r, rbac.ActionRead, template))) {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "Template not found."})
+			continue
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid favorites.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	err := api.Database.InTx(func(tx database.Store) error {
+		now := database.Now()
+		err := tx.DeleteWorkspaceFavoritesByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		for i, id := range req.WorkspaceIDs {
+			_, err = tx.InsertWorkspaceFavorite(ctx, database.InsertWorkspaceFavoriteParams{
+				UserID:      user.ID,
+				WorkspaceID: id,
+				Position:    int32(i),
+				CreatedAt:   now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		err = tx.DeleteTemplateFavoritesByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		for i, id := range req.TemplateIDs {
+			_, err = tx.InsertTemplateFavorite(ctx, database.InsertTemplateFavoriteParams{
+				UserID:     user.ID,
+				TemplateID: id,
+				Position:   int32(i),
+				CreatedAt:  now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating favorites.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	favorites, err := api.userFavorites(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching favorites.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, favorites)
+}
+
+func (api *API) userFavorites(ctx context.Context, userID uuid.UUID) (codersdk.Favorites, error) {
+	workspaceFavorites, err := api.Database.GetWorkspaceFavoritesByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return codersdk.Favorites{}, err
+	}
+	templateFavorites, err := api.Database.GetTemplateFavoritesByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return codersdk.Favorites{}, err
+	}
+
+	favorites := codersdk.Favorites{
+		WorkspaceIDs: make([]uuid.UUID, 0, len(workspaceFavorites)),
+		TemplateIDs:  make([]uuid.UUID, 0, len(templateFavorites)),
+	}
+	for _, favorite := range workspaceFavorites {
+		favorites.WorkspaceIDs = append(favorites.WorkspaceIDs, favorite.WorkspaceID)
+	}
+	for _, favorite := range templateFavorites {
+		favorites.TemplateIDs = append(favorites.TemplateIDs, favorite.TemplateID)
+	}
+	return favorites, nil
+}
+
+// parseListSort reads the "sort" query parameter of list endpoints. It writes
+// an error and returns false if the value isn't supported.
+func parseListSort(rw http.ResponseWriter, r *http.Request) (string, bool) {
+	ctx := r.Context()
+	sortBy := r.URL.Query().Get("sort")
+	switch sortBy {
+	case "", codersdk.SortFavorites:
+		return sortBy, true
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid value %q for \"sort\" query param.", sortBy),
+			Validations: []codersdk.ValidationError{
+				{Field: "sort", Detail: fmt.Sprintf("Must be empty or %q", codersdk.SortFavorites)},
+			},
+		})
+		return "", false
+	}
+}
+
+// favoritesFirst returns a less function that orders the IDs of favorites
+// before the rest, in the order the user chose. The rest keep their order
+// when used with a stable sort.
+func favoritesFirst(favoriteIDs []uuid.UUID, idAt func(i int) uuid.UUID) func(i, j int) bool {
+	positions := make(map[uuid.UUID]int, len(favoriteIDs))
+	for i, id := range favoriteIDs {
+		positions[id] = i
+	}
+	return func(i, j int) bool {
+		iPosition, iFavorite := positions[idAt(i)]
+		jPosition, jFavorite := positions[idAt(j)]
+		if iFavorite && jFavorite {
+			return iPosition < jPosition
+		}
+		return iFavorite && !jFavorite
+	}
+}
+
+func sortWorkspacesByFavorites(workspaces []codersdk.Workspace, favoriteIDs []uuid.UUID) {
+	sort.SliceStable(workspaces, favoritesFirst(favoriteIDs, func(i int) uuid.UUID {
+		return workspaces[i].ID
+	}))
+}
+
+func sortTemplatesByFavorites(templates []codersdk.Template, favoriteIDs []uuid.UUID) {
+	sort.SliceStable(templates, favoritesFirst(favoriteIDs, func(i int) uuid.UUID {
+		return templates[i].ID
+	}))
+}
@@ -0,0 +1,120 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestFavorites(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		templateA := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		templateB := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspaceA := coderdtest.CreateWorkspace(t, client, user.OrganizationID, templateA.ID)
+		workspaceB := coderdtest.CreateWorkspace(t, client, user.OrganizationID, templateA.ID)
+		workspaceC := coderdtest.CreateWorkspace(t, client, user.OrganizationID, templateA.ID)
+
+		ctx, _ := testutil.Context(t)
+		favorites, err := client.Favorites(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.Favorites{WorkspaceIDs: []uuid.UUID{}, TemplateIDs: []uuid.UUID{}}, favorites)
+
+		favorites, err = client.UpdateFavorites(ctx, codersdk.Me, codersdk.Favorites{
+			WorkspaceIDs: []uuid.UUID{workspaceC.ID, workspaceA.ID},
+			TemplateIDs:  []uuid.UUID{templateB.ID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{workspaceC.ID, workspaceA.ID}, favorites.WorkspaceIDs)
+		require.Equal(t, []uuid.UUID{templateB.ID}, favorites.TemplateIDs)
+
+		workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{Sort: codersdk.SortFavorites})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 3)
+		require.Equal(t, workspaceC.ID, workspaces[0].ID)
+		require.Equal(t, workspaceA.ID, workspaces[1].ID)
+		require.Equal(t, workspaceB.ID, workspaces[2].ID)
+
+		templates, err := client.TemplatesByOrganization(ctx, user.OrganizationID, codersdk.WithQueryParam("sort", codersdk.SortFavorites))
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+		require.Equal(t, templateB.ID, templates[0].ID)
+
+		// Replacing the favorites changes the order.
+		favorites, err = client.UpdateFavorites(ctx, codersdk.Me, codersdk.Favorites{
+			WorkspaceIDs: []uuid.UUID{workspaceB.ID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{workspaceB.ID}, favorites.WorkspaceIDs)
+		require.Empty(t, favorites.TemplateIDs)
+		workspaces, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{Sort: codersdk.SortFavorites})
+		require.NoError(t, err)
+		require.Equal(t, workspaceB.ID, workspaces[0].ID)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateFavorites(ctx, codersdk.Me, codersdk.Favorites{
+			WorkspaceIDs: []uuid.UUID{uuid.New()},
+			TemplateIDs:  []uuid.UUID{template.ID, template.ID},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, []codersdk.ValidationError{
+			{Field: "workspace_ids[0]", Detail: "Workspace not found."},
+			{Field: "template_ids[1]", Detail: "Template is listed more than once."},
+		}, apiErr.Validations)
+
+		_, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{Sort: "name"})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		// Members can't favorite workspaces they can't see.
+		_, err := member.UpdateFavorites(ctx, codersdk.Me, codersdk.Favorites{
+			WorkspaceIDs: []uuid.UUID{workspace.ID},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		_, err = member.UpdateFavorites(ctx, codersdk.Me, codersdk.Favorites{
+			TemplateIDs: []uuid.UUID{template.ID},
+		})
+		require.NoError(t, err)
+
+		// Nor read the favorites of other users.
+		_, err = member.Favorites(ctx, user.UserID.String())
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -39,8 +39,10 @@ func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	r.Body = http.MaxBytesReader(rw, r.Body, 10*(10<<20))
 	data, err := io.ReadAll(r.Body)
+	if httpapi.IsRequestTooLarge(rw, err) {
+		return
+	}
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Failed to read file from request.",
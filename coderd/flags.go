@@ -14,5 +14,5 @@ func (api *API) deploymentFlags(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(r.Context(), rw, http.StatusOK, deployment.RemoveSensitiveValues(*api.DeploymentFlags))
+	httpapi.WriteWithETag(r.Context(), rw, r, deployment.RemoveSensitiveValues(*api.DeploymentFlags))
 }
@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/codersdk"
+)
+
+// WriteWithETag writes the response like Write, with an ETag of its body. If
+// the request's If-None-Match header matches the ETag, only a 304 Not
+// Modified status is written, so clients polling a resource don't download it
+// again until it changes.
+//
+// The ETag is a hash of the body rather than of an updated_at timestamp, so it
+// also changes with the data that's joined into the response.
+func WriteWithETag(ctx context.Context, rw http.ResponseWriter, r *http.Request, response interface{}) {
+	_, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	body, err := encode(response)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := bodyETag(body)
+	rw.Header().Set("ETag", etag)
+	// Responses depend on the user's permissions, so they're only cached by
+	// the user's browser, and always revalidated.
+	rw.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	_, err = rw.Write(body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CheckIfMatch ensures a resource hasn't changed since the client read it, if
+// the request has an If-Match header. The header must match the ETag that
+// WriteWithETag returns for one of current, the representations of the
+// resource that can be read. Otherwise a 409 Conflict is written and ok is
+// false, so concurrent edits don't silently overwrite each other.
+func CheckIfMatch(ctx context.Context, rw http.ResponseWriter, r *http.Request, current ...interface{}) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	for _, response := range current {
+		body, err := encode(response)
+		if err != nil {
+			InternalServerError(rw, err)
+			return false
+		}
+		etag := bodyETag(body)
+		// If-Match uses the strong comparison, so weak ETags never match.
+		for _, candidate := range strings.Split(ifMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	Write(ctx, rw, http.StatusConflict, codersdk.Response{
+		Message: "The resource was changed since it was read.",
+		Detail:  "Read it again to get its current ETag, and retry the change if it still applies.",
+		Code:    codersdk.ErrorCodeEditConflict,
+	})
+	return false
+}
+
+func encode(response interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(true)
+	err := enc.Encode(response)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func bodyETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// etagMatches uses the weak comparison of If-None-Match, which ignores the
+// "W/" prefix of weak ETags.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package httpapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpapi"
+)
+
+func TestWriteWithETag(t *testing.T) {
+	t.Parallel()
+
+	write := func(t *testing.T, ifNoneMatch string, response any) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rw := httptest.NewRecorder()
+		httpapi.WriteWithETag(context.Background(), rw, r, response)
+		return rw
+	}
+
+	first := write(t, "", map[string]string{"name": "one"})
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, first.Body.String())
+
+	t.Run("NotModified", func(t *testing.T) {
+		t.Parallel()
+
+		for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+			rw := write(t, ifNoneMatch, map[string]string{"name": "one"})
+			require.Equal(t, http.StatusNotModified, rw.Code, ifNoneMatch)
+			require.Empty(t, rw.Body.String(), ifNoneMatch)
+			require.Equal(t, etag, rw.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		t.Parallel()
+
+		rw := write(t, etag, map[string]string{"name": "two"})
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.NotEqual(t, etag, rw.Header().Get("ETag"))
+		require.Contains(t, rw.Body.String(), "two")
+	})
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, ifMatch string, current ...any) (*httptest.ResponseRecorder, bool) {
+		t.Helper()
+		r := httptest.NewRequest("PATCH", "/", nil)
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		rw := httptest.NewRecorder()
+		return rw, httpapi.CheckIfMatch(context.Background(), rw, r, current...)
+	}
+
+	read := httptest.NewRecorder()
+	httpapi.WriteWithETag(context.Background(), read, httptest.NewRequest("GET", "/", nil), map[string]string{"name": "one"})
+	etag := read.Header().Get("ETag")
+
+	for _, ifMatch := range []string{"", etag, "*", `"other", ` + etag} {
+		_, ok := check(t, ifMatch, map[string]string{"name": "one"})
+		require.True(t, ok, ifMatch)
+	}
+	_, ok := check(t, etag, map[string]string{"name": "two"}, map[string]string{"name": "one"})
+	require.True(t, ok)
+
+	rw, ok := check(t, etag, map[string]string{"name": "two"})
+	require.False(t, ok)
+	require.Equal(t, http.StatusConflict, rw.Code)
+	rw, ok = check(t, "W/"+etag, map[string]string{"name": "one"})
+	require.False(t, ok)
+	require.Equal(t, http.StatusConflict, rw.Code)
+}
@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// Fields are the JSON fields a client asked for in the "fields" query
+// parameter of a list endpoint, e.g. "fields=id,name". Clients that only need
+// a few fields, like autocomplete widgets, can skip serializing the rest. A
+// nil Fields, from an empty or missing parameter, returns every field.
+type Fields []string
+
+// ParseFields extracts the "fields" query parameter from the request. Each
+// field must be the JSON name of a field of item, the type of the listed
+// results. If it isn't, the error is written to rw and ok is false.
+func ParseFields(rw http.ResponseWriter, r *http.Request, item any) (fields Fields, ok bool) {
+	if !r.URL.Query().Has("fields") {
+		return nil, true
+	}
+	known := jsonFields(reflect.TypeOf(item))
+	var validations []codersdk.ValidationError
+	for _, field := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := known[field]; !ok {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  "fields",
+				Detail: fmt.Sprintf("%q is not a field of the response", field),
+			})
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(validations) > 0 {
+		Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: validations,
+			Code:        codersdk.ErrorCodeInvalidQueryParameter,
+		})
+		return nil, false
+	}
+	return fields, true
+}
+
+// Has returns whether the field is in the response.
+func (f Fields) Has(field string) bool {
+	if f == nil {
+		return true
+	}
+	for _, name := range f {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFields writes the response like Write, keeping only the fields of the
+// listed results. The results are the response itself if it's a list, or the
+// list in the listKey field of the response otherwise.
+func WriteFields(ctx context.Context, rw http.ResponseWriter, status int, response any, fields Fields, listKey string) {
+	if fields == nil {
+		Write(ctx, rw, status, response)
+		return
+	}
+	shaped, err := shapeFields(response, fields, listKey)
+	if err != nil {
+		InternalServerError(rw, err)
+		return
+	}
+	Write(ctx, rw, status, shaped)
+}
+
+func shapeFields(response any, fields Fields, listKey string) (json.RawMessage, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal response: %w", err)
+	}
+	if listKey == "" {
+		return shapeList(data, fields)
+	}
+
+	var object map[string]json.RawMessage
+	err = json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal response: %w", err)
+	}
+	object[listKey], err = shapeList(object[listKey], fields)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(object)
+}
+
+func shapeList(data json.RawMessage, fields Fields) (json.RawMessage, error) {
+	var items []map[string]json.RawMessage
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal list: %w", err)
+	}
+	for _, item := range items {
+		for key := range item {
+			if !fields.Has(key) {
+				delete(item, key)
+			}
+		}
+	}
+	if items == nil {
+		items = []map[string]json.RawMessage{}
+	}
+	return json.Marshal(items)
+}
+
+// jsonFields returns the JSON names of the fields of a struct type.
+func jsonFields(t reflect.Type) map[string]struct{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields := map[string]struct{}{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			for embedded := range jsonFields(field.Type) {
+				fields[embedded] = struct{}{}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = struct{}{}
+	}
+	return fields
+}
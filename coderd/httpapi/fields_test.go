@@ -0,0 +1,90 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpapi"
+)
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID      string   `json:"id"`
+		Name    string   `json:"name"`
+		Members []string `json:"members"`
+	}
+	items := []item{
+		{ID: "1", Name: "one", Members: []string{"a"}},
+		{ID: "2", Name: "two", Members: []string{"b"}},
+	}
+
+	parse := func(t *testing.T, query string) (httpapi.Fields, *httptest.ResponseRecorder, bool) {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/?"+query, nil)
+		rw := httptest.NewRecorder()
+		fields, ok := httpapi.ParseFields(rw, r, item{})
+		return fields, rw, ok
+	}
+
+	t.Run("All", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{"", "fields="} {
+			fields, _, ok := parse(t, query)
+			require.True(t, ok)
+			require.Nil(t, fields)
+			require.True(t, fields.Has("members"))
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		t.Parallel()
+
+		_, rw, ok := parse(t, "fields=id,nope")
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+
+		fields, _, ok := parse(t, "fields=id,name")
+		require.True(t, ok)
+		require.False(t, fields.Has("members"))
+
+		rw := httptest.NewRecorder()
+		httpapi.WriteFields(context.Background(), rw, http.StatusOK, items, fields, "")
+		var got []map[string]any
+		require.NoError(t, json.NewDecoder(rw.Body).Decode(&got))
+		require.Equal(t, []map[string]any{
+			{"id": "1", "name": "one"},
+			{"id": "2", "name": "two"},
+		}, got)
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		fields, _, ok := parse(t, "fields=id")
+		require.True(t, ok)
+
+		rw := httptest.NewRecorder()
+		httpapi.WriteFields(context.Background(), rw, http.StatusOK, map[string]any{
+			"items": items,
+			"count": 2,
+		}, fields, "items")
+		var got map[string]any
+		require.NoError(t, json.NewDecoder(rw.Body).Decode(&got))
+		require.Equal(t, map[string]any{
+			"items": []any{map[string]any{"id": "1"}, map[string]any{"id": "2"}},
+			"count": float64(2),
+		}, got)
+	})
+}
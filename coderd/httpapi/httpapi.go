@@ -58,12 +58,14 @@ func init() {
 func ResourceNotFound(rw http.ResponseWriter) {
 	Write(context.Background(), rw, http.StatusNotFound, codersdk.Response{
 		Message: "Resource not found or you do not have access to this resource",
+		Code:    codersdk.ErrorCodeResourceNotFound,
 	})
 }
 
 func Forbidden(rw http.ResponseWriter) {
 	Write(context.Background(), rw, http.StatusForbidden, codersdk.Response{
 		Message: "Forbidden.",
+		Code:    codersdk.ErrorCodeForbidden,
 	})
 }
 
@@ -76,12 +78,34 @@ func InternalServerError(rw http.ResponseWriter, err error) {
 	Write(context.Background(), rw, http.StatusInternalServerError, codersdk.Response{
 		Message: "An internal server error occurred.",
 		Detail:  details,
+		Code:    codersdk.ErrorCodeInternalError,
 	})
 }
 
+// RequestTooLarge writes a 413 for a body that exceeds the limit in bytes.
+func RequestTooLarge(rw http.ResponseWriter, limit int64) {
+	Write(context.Background(), rw, http.StatusRequestEntityTooLarge, codersdk.Response{
+		Message: "Request body is too large.",
+		Detail:  fmt.Sprintf("The body of this request can't be larger than %d bytes.", limit),
+		Code:    codersdk.ErrorCodeRequestTooLarge,
+	})
+}
+
+// IsRequestTooLarge writes a 413 and returns true if err came from reading a
+// body that exceeds its limit.
+func IsRequestTooLarge(rw http.ResponseWriter, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	RequestTooLarge(rw, maxBytesErr.Limit)
+	return true
+}
+
 func RouteNotFound(rw http.ResponseWriter) {
 	Write(context.Background(), rw, http.StatusNotFound, codersdk.Response{
 		Message: "Route not found.",
+		Code:    codersdk.ErrorCodeRouteNotFound,
 	})
 }
 
@@ -96,6 +120,10 @@ func Write(ctx context.Context, rw http.ResponseWriter, status int, response int
 	_, span := tracing.StartSpan(ctx)
 	defer span.End()
 
+	if status >= http.StatusBadRequest {
+		response = withRequestID(rw, response)
+	}
+
 	buf := &bytes.Buffer{}
 	enc := json.NewEncoder(buf)
 	enc.SetEscapeHTML(true)
@@ -113,6 +141,33 @@ func Write(ctx context.Context, rw http.ResponseWriter, status int, response int
 	}
 }
 
+// withRequestID adds the request ID to the detail of error responses, so users
+// can quote it when reporting problems.
+func withRequestID(rw http.ResponseWriter, response interface{}) interface{} {
+	requestID := rw.Header().Get(codersdk.RequestIDHeader)
+	if requestID == "" {
+		return response
+	}
+	var resp codersdk.Response
+	switch r := response.(type) {
+	case codersdk.Response:
+		resp = r
+	case *codersdk.Response:
+		if r == nil {
+			return response
+		}
+		resp = *r
+	default:
+		return response
+	}
+	if resp.Detail == "" {
+		resp.Detail = "Request ID: " + requestID
+	} else {
+		resp.Detail = fmt.Sprintf("%s (request ID: %s)", resp.Detail, requestID)
+	}
+	return resp
+}
+
 // Read decodes JSON from the HTTP request into the value provided. It uses
 // go-validator to validate the incoming request body. ctx is used for tracing
 // and can be nil. Although tracing this function isn't likely too helpful, it
@@ -122,10 +177,14 @@ func Read(ctx context.Context, rw http.ResponseWriter, r *http.Request, value in
 	defer span.End()
 
 	err := json.NewDecoder(r.Body).Decode(value)
+	if IsRequestTooLarge(rw, err) {
+		return false
+	}
 	if err != nil {
 		Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Request body must be valid JSON.",
 			Detail:  err.Error(),
+			Code:    codersdk.ErrorCodeInvalidRequestBody,
 		})
 		return false
 	}
@@ -142,6 +201,7 @@ func Read(ctx context.Context, rw http.ResponseWriter, r *http.Request, value in
 		Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message:     "Validation failed.",
 			Validations: apiErrors,
+			Code:        codersdk.ErrorCodeValidationFailed,
 		})
 		return false
 	}
@@ -149,6 +209,7 @@ func Read(ctx context.Context, rw http.ResponseWriter, r *http.Request, value in
 		Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error validating request body payload.",
 			Detail:  err.Error(),
+			Code:    codersdk.ErrorCodeInternalError,
 		})
 		return false
 	}
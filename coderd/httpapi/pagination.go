@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// CursorPagination is a page of results requested from a list endpoint. The
+// first page is requested without a cursor, and each page returns the
+// "next_cursor" to request the page after it. Cursors are opaque to clients.
+type CursorPagination struct {
+	// AfterID is the ID of the last result of the previous page, decoded from
+	// the "cursor" query parameter. It's uuid.Nil for the first page.
+	AfterID uuid.UUID
+	// Limit is the maximum number of results in the page. 0 means no limit.
+	Limit int
+}
+
+// ParseCursorPagination extracts the "cursor" and "limit" query parameters
+// from the request. If they are invalid, the error is written to rw and ok is
+// false.
+func ParseCursorPagination(rw http.ResponseWriter, r *http.Request) (p CursorPagination, ok bool) {
+	queryParams := r.URL.Query()
+	parser := NewQueryParamParser()
+	p = CursorPagination{
+		AfterID: parser.Cursor(queryParams, "cursor"),
+		Limit:   parser.Int(queryParams, 0, "limit"),
+	}
+	if p.Limit < 0 {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "limit",
+			Detail: `Query param "limit" must not be negative`,
+		})
+	}
+	if len(parser.Errors) > 0 {
+		Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+			Code:        codersdk.ErrorCodeInvalidQueryParameter,
+		})
+		return p, false
+	}
+	return p, true
+}
+
+// Requested returns whether the request asked for a page. List endpoints that
+// returned a bare array before they were paginated keep doing so when it
+// didn't, so existing API clients aren't broken.
+func (p CursorPagination) Requested() bool {
+	return p.AfterID != uuid.Nil || p.Limit > 0
+}
+
+// FetchLimit is the number of rows to query for the page. It's one more than
+// the limit, so Page can tell whether there is a next page.
+func (p CursorPagination) FetchLimit() int32 {
+	if p.Limit <= 0 {
+		return 0
+	}
+	return int32(p.Limit) + 1
+}
+
+// Page trims rows queried with FetchLimit to the page, and returns the cursor
+// of the next page, which is empty on the last page. id must return the ID
+// that the query continues after.
+//
+// Rows the user can't read should be filtered out after calling Page, so
+// they don't stop the next page from starting in the right place.
+func Page[T any](p CursorPagination, rows []T, id func(T) uuid.UUID) ([]T, string) {
+	if p.Limit <= 0 || len(rows) <= p.Limit {
+		return rows, ""
+	}
+	rows = rows[:p.Limit]
+	return rows, EncodeCursor(id(rows[len(rows)-1]))
+}
+
+// EncodeCursor returns the cursor of the page that starts after id.
+func EncodeCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// DecodeCursor returns the ID encoded in cursor by EncodeCursor.
+func DecodeCursor(cursor string) (uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return uuid.Nil, xerrors.Errorf("decode cursor: %w", err)
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return uuid.Nil, xerrors.Errorf("parse cursor: %w", err)
+	}
+	return id, nil
+}
+
+func (p *QueryParamParser) Cursor(vals url.Values, queryParam string) uuid.UUID {
+	v, err := parseQueryParam(vals, DecodeCursor, uuid.Nil, queryParam)
+	if err != nil {
+		p.Errors = append(p.Errors, codersdk.ValidationError{
+			Field:  queryParam,
+			Detail: fmt.Sprintf("Query param %q must be a cursor returned by a previous page", queryParam),
+		})
+	}
+	return v
+}
@@ -0,0 +1,73 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpapi"
+)
+
+func TestCursorPagination(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Parse", func(t *testing.T) {
+		t.Parallel()
+
+		id := uuid.New()
+		r := httptest.NewRequest("GET", "/?cursor="+httpapi.EncodeCursor(id)+"&limit=10", nil)
+		rw := httptest.NewRecorder()
+		page, ok := httpapi.ParseCursorPagination(rw, r)
+		require.True(t, ok)
+		require.Equal(t, id, page.AfterID)
+		require.Equal(t, 10, page.Limit)
+		require.Equal(t, int32(11), page.FetchLimit())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest("GET", "/", nil)
+		rw := httptest.NewRecorder()
+		page, ok := httpapi.ParseCursorPagination(rw, r)
+		require.True(t, ok)
+		require.Equal(t, uuid.Nil, page.AfterID)
+		require.Equal(t, int32(0), page.FetchLimit())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{"cursor=nope", "cursor=" + uuid.NewString(), "limit=-1", "limit=ten"} {
+			r := httptest.NewRequest("GET", "/?"+query, nil)
+			rw := httptest.NewRecorder()
+			_, ok := httpapi.ParseCursorPagination(rw, r)
+			require.False(t, ok, query)
+			require.Equal(t, http.StatusBadRequest, rw.Code, query)
+		}
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		t.Parallel()
+
+		ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+		identity := func(id uuid.UUID) uuid.UUID { return id }
+
+		page, next := httpapi.Page(httpapi.CursorPagination{Limit: 2}, ids, identity)
+		require.Equal(t, ids[:2], page)
+		after, err := httpapi.DecodeCursor(next)
+		require.NoError(t, err)
+		require.Equal(t, ids[1], after)
+
+		page, next = httpapi.Page(httpapi.CursorPagination{Limit: 3}, ids, identity)
+		require.Equal(t, ids, page)
+		require.Empty(t, next)
+
+		page, next = httpapi.Page(httpapi.CursorPagination{}, ids, identity)
+		require.Equal(t, ids, page)
+		require.Empty(t, next)
+	})
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -55,7 +56,10 @@ type Authorization struct {
 	Username string
 	Roles    []string
 	Groups   []string
-	Scope    database.APIKeyScope
+	// EveryoneExcluded are the organizations whose "Everyone" group the user
+	// is excluded from.
+	EveryoneExcluded []string
+	Scope            rbac.Scope
 }
 
 // UserAuthorizationOptional may return the roles and scope used for
@@ -104,6 +108,11 @@ type ExtractAPIKeyConfig struct {
 	// will be deleted and the request will continue. If the request is not a
 	// cookie-based request, the request will be rejected with a 401.
 	Optional bool
+
+	// ClientCertificates authenticates requests without a session token by
+	// their verified TLS client certificate. If nil, certificates are
+	// ignored.
+	ClientCertificates *ClientCertificateConfig
 }
 
 // ExtractAPIKey requires authentication using a valid API key. It handles
@@ -154,8 +163,56 @@ func ExtractAPIKey(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 				write(code, response)
 			}
 
+			// serveWithKey serves the request as the user of a valid key.
+			serveWithKey := func(key database.APIKey) {
+				// If the key is valid, we also fetch the user roles and status.
+				// The roles are used for RBAC authorize checks, and the status
+				// is to block 'suspended' users from accessing the platform.
+				roles, err := cfg.DB.GetAuthorizationUserRoles(r.Context(), key.UserID)
+				if err != nil {
+					write(http.StatusUnauthorized, codersdk.Response{
+						Message: internalErrorMessage,
+						Detail:  fmt.Sprintf("Internal error fetching user's roles. %s", err.Error()),
+					})
+					return
+				}
+
+				if roles.Status != database.UserStatusActive {
+					write(http.StatusUnauthorized, codersdk.Response{
+						Message: fmt.Sprintf("User is not active (status = %q). Contact an admin to reactivate your account.", roles.Status),
+					})
+					return
+				}
+
+				ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
+				ctx = context.WithValue(ctx, userAuthKey{}, Authorization{
+					ID:               key.UserID,
+					Username:         roles.Username,
+					Roles:            roles.Roles,
+					Scope:            key.RBACScope(),
+					Groups:           roles.Groups,
+					EveryoneExcluded: roles.EveryoneExcludedOrgs,
+				})
+
+				next.ServeHTTP(rw, r.WithContext(ctx))
+			}
+
 			token := apiTokenFromRequest(r)
 			if token == "" {
+				// A session token takes precedence, so a user can act as
+				// themselves on a machine with a certificate.
+				key, ok, err := clientCertificateAPIKey(ctx, cfg.DB, cfg.ClientCertificates, r)
+				if err != nil {
+					write(http.StatusInternalServerError, codersdk.Response{
+						Message: internalErrorMessage,
+						Detail:  fmt.Sprintf("Internal error authenticating client certificate. %s", err.Error()),
+					})
+					return
+				}
+				if ok {
+					serveWithKey(key)
+					return
+				}
 				optionalWrite(http.StatusUnauthorized, codersdk.Response{
 					Message: signedOutErrorMessage,
 					Detail:  fmt.Sprintf("Cookie %q or query parameter must be provided.", codersdk.SessionTokenKey),
@@ -204,7 +261,8 @@ func ExtractAPIKey(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 				// Tracks if the API key has properties updated
 				changed = false
 			)
-			if key.LoginType != database.LoginTypePassword {
+			// Password and token keys aren't linked to an OAuth provider.
+			if key.LoginType == database.LoginTypeGithub || key.LoginType == database.LoginTypeOIDC {
 				link, err = cfg.DB.GetUserLinkByUserIDLoginType(r.Context(), database.GetUserLinkByUserIDLoginTypeParams{
 					UserID:    key.UserID,
 					LoginType: key.LoginType,
@@ -336,35 +394,7 @@ func ExtractAPIKey(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 				}
 			}
 
-			// If the key is valid, we also fetch the user roles and status.
-			// The roles are used for RBAC authorize checks, and the status
-			// is to block 'suspended' users from accessing the platform.
-			roles, err := cfg.DB.GetAuthorizationUserRoles(r.Context(), key.UserID)
-			if err != nil {
-				write(http.StatusUnauthorized, codersdk.Response{
-					Message: internalErrorMessage,
-					Detail:  fmt.Sprintf("Internal error fetching user's roles. %s", err.Error()),
-				})
-				return
-			}
-
-			if roles.Status != database.UserStatusActive {
-				write(http.StatusUnauthorized, codersdk.Response{
-					Message: fmt.Sprintf("User is not active (status = %q). Contact an admin to reactivate your account.", roles.Status),
-				})
-				return
-			}
-
-			ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
-			ctx = context.WithValue(ctx, userAuthKey{}, Authorization{
-				ID:       key.UserID,
-				Username: roles.Username,
-				Roles:    roles.Roles,
-				Scope:    key.Scope,
-				Groups:   roles.Groups,
-			})
-
-			next.ServeHTTP(rw, r.WithContext(ctx))
+			serveWithKey(key)
 		})
 	}
 }
@@ -392,6 +422,19 @@ func apiTokenFromRequest(r *http.Request) string {
 		return headerValue
 	}
 
+	// Applications that users signed in to with Coder send their tokens as
+	// bearer tokens. Only API routes accept them, since workspace apps may
+	// use the header for their own authentication.
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		authorization := r.Header.Get("Authorization")
+		if strings.HasPrefix(authorization, "Bearer ") {
+			bearer := strings.TrimPrefix(authorization, "Bearer ")
+			if _, _, err := SplitAPIToken(bearer); err == nil {
+				return bearer
+			}
+		}
+	}
+
 	cookie, err = r.Cookie(DevURLSessionTokenCookie)
 	if err == nil && cookie.Value != "" {
 		return cookie.Value
@@ -0,0 +1,139 @@
+package httpmw
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// ClientCertificateConfig maps verified TLS client certificates to users, so
+// machines can authenticate without a bearer token.
+type ClientCertificateConfig struct {
+	// Identities map certificates to users by a subject alternative name or
+	// organizational unit. They're checked in order, before EmailSAN.
+	Identities []ClientCertificateIdentity
+	// EmailSAN maps certificates to the user with an email address in their
+	// subject alternative names.
+	EmailSAN bool
+}
+
+// ClientCertificateIdentity maps certificates with a subject alternative name
+// or organizational unit to a user, e.g. a service account.
+type ClientCertificateIdentity struct {
+	// Field is "san" or "ou".
+	Field    string
+	Value    string
+	Username string
+}
+
+// ParseClientCertificateIdentity parses an identity in the
+// "<san|ou>:<value>=<username>" format.
+func ParseClientCertificateIdentity(s string) (ClientCertificateIdentity, error) {
+	field, rest, ok := strings.Cut(s, ":")
+	if !ok || (field != "san" && field != "ou") {
+		return ClientCertificateIdentity{}, xerrors.Errorf("identity %q must start with \"san:\" or \"ou:\"", s)
+	}
+	// Values can contain "=", e.g. URIs, so the username is after the last one.
+	index := strings.LastIndex(rest, "=")
+	if index <= 0 || index == len(rest)-1 {
+		return ClientCertificateIdentity{}, xerrors.Errorf("identity %q must be in the format \"%s:<value>=<username>\"", s, field)
+	}
+	return ClientCertificateIdentity{
+		Field:    field,
+		Value:    rest[:index],
+		Username: rest[index+1:],
+	}, nil
+}
+
+func (i ClientCertificateIdentity) matches(cert *x509.Certificate) bool {
+	if i.Field == "ou" {
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if ou == i.Value {
+				return true
+			}
+		}
+		return false
+	}
+	for _, san := range certificateSANs(cert) {
+		if san == i.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func certificateSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.URIs)+len(cert.IPAddresses))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+// clientCertificateAPIKey returns an API key for the user the verified client
+// certificate of the request maps to. The key isn't stored, and expires with
+// the certificate. ok is false if the request has no verified certificate,
+// or it doesn't map to a user.
+func clientCertificateAPIKey(ctx context.Context, db database.Store, cfg *ClientCertificateConfig, r *http.Request) (database.APIKey, bool, error) {
+	if cfg == nil || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return database.APIKey{}, false, nil
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+
+	var user database.User
+	err := sql.ErrNoRows
+	for _, identity := range cfg.Identities {
+		if identity.matches(cert) {
+			user, err = db.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+				Username: identity.Username,
+			})
+			break
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) && cfg.EmailSAN {
+		for _, email := range cert.EmailAddresses {
+			user, err = db.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+				Email: email,
+			})
+			if !errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.APIKey{}, false, nil
+	}
+	if err != nil {
+		return database.APIKey{}, false, xerrors.Errorf("get user: %w", err)
+	}
+
+	// The ID is unique to the certificate, so rate limits apply to each
+	// certificate separately. It can't collide with stored keys, which don't
+	// contain dashes.
+	fingerprint := sha256.Sum256(cert.Raw)
+	now := database.Now()
+	return database.APIKey{
+		ID:        "cert-" + hex.EncodeToString(fingerprint[:5]),
+		UserID:    user.ID,
+		LastUsed:  now,
+		ExpiresAt: cert.NotAfter,
+		CreatedAt: now,
+		UpdatedAt: now,
+		LoginType: database.LoginTypeToken,
+		Scope:     database.APIKeyScopeAll,
+	}, true, nil
+}
@@ -0,0 +1,143 @@
+package httpmw_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/httpmw"
+)
+
+func TestClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	certificate := func(email, ou string) *x509.Certificate {
+		cert := &x509.Certificate{
+			Raw:      []byte(email + ou),
+			NotAfter: time.Now().Add(time.Hour),
+			Subject:  pkix.Name{OrganizationalUnit: []string{ou}},
+		}
+		if email != "" {
+			cert.EmailAddresses = []string{email}
+		}
+		return cert
+	}
+	verified := func(cert *x509.Certificate) *tls.ConnectionState {
+		return &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+	}
+	serve := func(t *testing.T, db database.Store, cfg *httpmw.ClientCertificateConfig, state *tls.ConnectionState) (*http.Response, database.APIKey) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.TLS = state
+		rw := httptest.NewRecorder()
+		var key database.APIKey
+		httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
+			DB:                 db,
+			ClientCertificates: cfg,
+		})(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			key = httpmw.APIKey(r)
+			rw.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rw, r)
+		res := rw.Result()
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res, key
+	}
+
+	t.Run("Email", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		user := createUser(context.Background(), t, db)
+
+		res, key := serve(t, db, &httpmw.ClientCertificateConfig{EmailSAN: true}, verified(certificate(user.Email, "")))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, user.ID, key.UserID)
+		require.Equal(t, database.APIKeyScopeAll, key.Scope)
+	})
+
+	t.Run("Identity", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		user := createUser(context.Background(), t, db)
+		identity, err := httpmw.ParseClientCertificateIdentity("ou:ci-runners=" + user.Username)
+		require.NoError(t, err)
+
+		// Identities are checked before email addresses.
+		res, key := serve(t, db, &httpmw.ClientCertificateConfig{
+			Identities: []httpmw.ClientCertificateIdentity{identity},
+			EmailSAN:   true,
+		}, verified(certificate("someone@coder.com", "ci-runners")))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, user.ID, key.UserID)
+	})
+
+	t.Run("Unverified", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		user := createUser(context.Background(), t, db)
+
+		res, _ := serve(t, db, &httpmw.ClientCertificateConfig{EmailSAN: true}, &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{certificate(user.Email, "")},
+		})
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		user := createUser(context.Background(), t, db)
+
+		res, _ := serve(t, db, nil, verified(certificate(user.Email, "")))
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+
+		res, _ := serve(t, db, &httpmw.ClientCertificateConfig{EmailSAN: true}, verified(certificate("nobody@coder.com", "")))
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Suspended", func(t *testing.T) {
+		t.Parallel()
+		db := databasefake.New()
+		user := createUser(context.Background(), t, db)
+		_, err := db.UpdateUserStatus(context.Background(), database.UpdateUserStatusParams{
+			ID:        user.ID,
+			Status:    database.UserStatusSuspended,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+
+		res, _ := serve(t, db, &httpmw.ClientCertificateConfig{EmailSAN: true}, verified(certificate(user.Email, "")))
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestParseClientCertificateIdentity(t *testing.T) {
+	t.Parallel()
+
+	identity, err := httpmw.ParseClientCertificateIdentity("san:spiffe://coder.com/ci?env=prod=ci-bot")
+	require.NoError(t, err)
+	require.Equal(t, httpmw.ClientCertificateIdentity{
+		Field:    "san",
+		Value:    "spiffe://coder.com/ci?env=prod",
+		Username: "ci-bot",
+	}, identity)
+
+	for _, value := range []string{"cn:bot=ci-bot", "san:bot", "ou:=ci-bot", "ou:bot="} {
+		_, err := httpmw.ParseClientCertificateIdentity(value)
+		require.Error(t, err, value)
+	}
+}
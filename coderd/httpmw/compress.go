@@ -0,0 +1,292 @@
+package httpmw
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/tracing"
+)
+
+// DefaultCompressMinBytes is the smallest response that's compressed by
+// default. Smaller responses aren't worth the CPU time.
+const DefaultCompressMinBytes = 1 << 10
+
+// compressEncodings are the supported encodings in order of preference.
+var compressEncodings = []string{"zstd", "br"}
+
+var (
+	zstdEncoders = sync.Pool{New: func() any {
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic("invalid zstd encoder: " + err.Error())
+		}
+		return encoder
+	}}
+	brotliEncoders = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}}
+)
+
+// Compress compresses JSON and text responses of at least minBytes with zstd
+// or brotli, depending on the Accept-Encoding header of the request.
+// Responses are sent uncompressed if they're flushed before reaching
+// minBytes, so streamed responses aren't delayed.
+//
+// It must be higher in the stack than tracing.Middleware.
+func Compress(register prometheus.Registerer, minBytes int) func(http.Handler) http.Handler {
+	factory := promauto.With(register)
+	ratio := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "coderd",
+		Subsystem: "api",
+		Name:      "response_compression_ratio",
+		Help:      "The uncompressed size of compressed API responses divided by their compressed size",
+		Buckets:   []float64{1, 1.5, 2, 3, 5, 10, 20, 50},
+	}, []string{"encoding"})
+	uncompressedBytes := factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "coderd",
+		Subsystem: "api",
+		Name:      "response_uncompressed_bytes_total",
+		Help:      "The total size of compressed API responses before compression",
+	}, []string{"encoding"})
+	compressedBytes := factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "coderd",
+		Subsystem: "api",
+		Name:      "response_compressed_bytes_total",
+		Help:      "The total size of compressed API responses after compression",
+	}, []string{"encoding"})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: rw,
+				encoding:       encoding,
+				minBytes:       minBytes,
+			}
+			// Everything downstream depends on the status writer.
+			next.ServeHTTP(&tracing.StatusWriter{ResponseWriter: cw}, r)
+			err := cw.close()
+			if err != nil || cw.compressed.n == 0 {
+				return
+			}
+			ratio.WithLabelValues(encoding).Observe(float64(cw.uncompressed) / float64(cw.compressed.n))
+			uncompressedBytes.WithLabelValues(encoding).Add(float64(cw.uncompressed))
+			compressedBytes.WithLabelValues(encoding).Add(float64(cw.compressed.n))
+		})
+	}
+}
+
+// negotiateEncoding returns the preferred supported encoding of an
+// Accept-Encoding header, or an empty string if none are acceptable.
+func negotiateEncoding(header string) string {
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		quality := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+		rank := indexOf(compressEncodings, name)
+		if rank == -1 || quality <= 0 {
+			continue
+		}
+		if quality > bestQuality || (quality == bestQuality && rank < indexOf(compressEncodings, best)) {
+			best = name
+			bestQuality = quality
+		}
+	}
+	return best
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// compressible returns true if responses with the headers are worth
+// compressing.
+func compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return false
+	}
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/")
+}
+
+// compressWriter buffers the response until it's large enough to compress,
+// or it's flushed.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minBytes int
+
+	status      int
+	wroteHeader bool
+	// passthrough is set once the response is sent uncompressed.
+	passthrough bool
+	buffer      []byte
+	encoder     io.WriteCloser
+	compressed  countWriter
+	// uncompressed is the size of the compressed response before
+	// compression.
+	uncompressed int
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified || !compressible(w.Header()) {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.encoder != nil {
+		w.uncompressed += len(b)
+		return w.encoder.Write(b)
+	}
+	w.buffer = append(w.buffer, b...)
+	if len(w.buffer) >= w.minBytes {
+		err := w.startEncoder()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) startEncoder() error {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.compressed.w = w.ResponseWriter
+	switch w.encoding {
+	case "zstd":
+		encoder, _ := zstdEncoders.Get().(*zstd.Encoder)
+		encoder.Reset(&w.compressed)
+		w.encoder = encoder
+	case "br":
+		encoder, _ := brotliEncoders.Get().(*brotli.Writer)
+		encoder.Reset(&w.compressed)
+		w.encoder = encoder
+	}
+	buffer := w.buffer
+	w.buffer = nil
+	w.uncompressed = len(buffer)
+	_, err := w.encoder.Write(buffer)
+	return err
+}
+
+// sendUncompressed sends the buffered response as is.
+func (w *compressWriter) sendUncompressed() error {
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	buffer := w.buffer
+	w.buffer = nil
+	_, err := w.ResponseWriter.Write(buffer)
+	return err
+}
+
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		return
+	}
+	switch {
+	case w.encoder != nil:
+		if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+			_ = flusher.Flush()
+		}
+	case !w.passthrough:
+		// Flushed responses are streamed, so they're sent as they are.
+		_ = w.sendUncompressed()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, xerrors.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// close finishes the response once the handler returns.
+func (w *compressWriter) close() error {
+	if w.encoder != nil {
+		err := w.encoder.Close()
+		switch encoder := w.encoder.(type) {
+		case *zstd.Encoder:
+			encoder.Reset(nil)
+			zstdEncoders.Put(encoder)
+		case *brotli.Writer:
+			encoder.Reset(nil)
+			brotliEncoders.Put(encoder)
+		}
+		return err
+	}
+	if w.wroteHeader && !w.passthrough {
+		return w.sendUncompressed()
+	}
+	return nil
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += n
+	return n, err
+}
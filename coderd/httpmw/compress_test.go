@@ -0,0 +1,129 @@
+package httpmw_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/tracing"
+)
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat("coder", 1000)
+	serve := func(t *testing.T, registry *prometheus.Registry, acceptEncoding string, handler http.HandlerFunc) *http.Response {
+		t.Helper()
+		rtr := chi.NewRouter()
+		rtr.Use(
+			// Logger wraps responses in the status writer in coderd.
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(&tracing.StatusWriter{ResponseWriter: rw}, r)
+				})
+			},
+			httpmw.Compress(registry, httpmw.DefaultCompressMinBytes),
+			tracing.Middleware(nil),
+		)
+		rtr.Get("/", handler)
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, r)
+		res := rw.Result()
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+	writeJSON := func(value string) http.HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), rw, http.StatusOK, value)
+		}
+	}
+
+	t.Run("Zstd", func(t *testing.T) {
+		t.Parallel()
+		registry := prometheus.NewRegistry()
+		res := serve(t, registry, "gzip, br;q=0.5, zstd", writeJSON(large))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "zstd", res.Header.Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", res.Header.Get("Vary"))
+
+		decoder, err := zstd.NewReader(res.Body)
+		require.NoError(t, err)
+		defer decoder.Close()
+		body, err := io.ReadAll(decoder)
+		require.NoError(t, err)
+		require.Contains(t, string(body), large)
+
+		metrics, err := registry.Gather()
+		require.NoError(t, err)
+		var ratio *float64
+		for _, metric := range metrics {
+			if metric.GetName() == "coderd_api_response_compression_ratio" {
+				sum := metric.GetMetric()[0].GetHistogram().GetSampleSum()
+				ratio = &sum
+			}
+		}
+		require.NotNil(t, ratio)
+		require.Greater(t, *ratio, 10.0)
+	})
+
+	t.Run("Brotli", func(t *testing.T) {
+		t.Parallel()
+		res := serve(t, prometheus.NewRegistry(), "br, zstd;q=0", writeJSON(large))
+		require.Equal(t, "br", res.Header.Get("Content-Encoding"))
+		body, err := io.ReadAll(brotli.NewReader(res.Body))
+		require.NoError(t, err)
+		require.Contains(t, string(body), large)
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		t.Parallel()
+		res := serve(t, prometheus.NewRegistry(), "zstd", writeJSON("small"))
+		require.Empty(t, res.Header.Get("Content-Encoding"))
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), "small")
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Parallel()
+		res := serve(t, prometheus.NewRegistry(), "gzip", writeJSON(large))
+		require.Empty(t, res.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("Flushed", func(t *testing.T) {
+		t.Parallel()
+		// Streamed responses aren't held back to be compressed.
+		res := serve(t, prometheus.NewRegistry(), "zstd", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write([]byte(`"first"`))
+			rw.(http.Flusher).Flush()
+			_, _ = rw.Write([]byte(large))
+		})
+		require.Empty(t, res.Header.Get("Content-Encoding"))
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, `"first"`+large, string(body))
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		t.Parallel()
+		res := serve(t, prometheus.NewRegistry(), "zstd", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Type", "application/x-tar")
+			_, _ = rw.Write([]byte(large))
+		})
+		require.Empty(t, res.Header.Get("Content-Encoding"))
+	})
+}
@@ -0,0 +1,153 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// Deprecation describes a route that is scheduled for removal.
+type Deprecation struct {
+	// Since is when the route was deprecated. If zero, the route is
+	// flagged as deprecated without a date.
+	Since time.Time
+	// Sunset is when the route will stop working. If zero, no Sunset
+	// header is sent.
+	Sunset time.Time
+	// Successor is an optional path to the route that replaces this one.
+	Successor string
+}
+
+// Deprecated marks every response of the wrapped handler with the
+// "Deprecation", "Sunset" and "Link" headers, so clients can detect and
+// warn about routes that are going away before they break.
+//
+// See https://datatracker.ietf.org/doc/draft-ietf-httpapi-deprecation-header
+// and https://www.rfc-editor.org/rfc/rfc8594.
+func Deprecated(d Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			h := rw.Header()
+			if d.Since.IsZero() {
+				h.Set("Deprecation", "true")
+			} else {
+				h.Set("Deprecation", d.Since.UTC().Format(http.TimeFormat))
+			}
+			if !d.Sunset.IsZero() {
+				h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Successor != "" {
+				h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Successor))
+			}
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// maxDeprecationUserAgents caps the user agents kept for each route, so a
+// client that changes its user agent on every request can't grow the
+// report without bound.
+const maxDeprecationUserAgents = 10
+
+// DeprecationUsage counts requests that are served with a "Deprecation"
+// header. Usage is kept in memory, so it covers the requests this replica
+// served since it started.
+type DeprecationUsage struct {
+	since    time.Time
+	requests *prometheus.CounterVec
+
+	mutex  sync.Mutex
+	routes map[string]*codersdk.DeprecatedRouteUsage
+}
+
+// NewDeprecationUsage creates a tracker that exports the
+// "coderd_api_deprecated_requests_total" metric.
+func NewDeprecationUsage(register prometheus.Registerer) *DeprecationUsage {
+	return &DeprecationUsage{
+		since: time.Now(),
+		requests: promauto.With(register).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "api",
+			Name:      "deprecated_requests_total",
+			Help:      "The total number of API requests served by deprecated routes",
+		}, []string{"method", "path"}),
+		routes: map[string]*codersdk.DeprecatedRouteUsage{},
+	}
+}
+
+// Middleware records the requests of the wrapped handler that were marked as
+// deprecated. It catches routes wrapped with Deprecated, and handlers that
+// only deprecate some requests, e.g. ones that use an old organization name.
+func (u *DeprecationUsage) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(rw, r)
+		if rw.Header().Get("Deprecation") == "" {
+			return
+		}
+		path := r.URL.Path
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			path = rctx.RoutePattern()
+		}
+		u.record(r.Method, path, r.UserAgent())
+	})
+}
+
+func (u *DeprecationUsage) record(method, path, userAgent string) {
+	u.requests.WithLabelValues(method, path).Inc()
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	key := method + " " + path
+	route, ok := u.routes[key]
+	if !ok {
+		route = &codersdk.DeprecatedRouteUsage{
+			Method: method,
+			Path:   path,
+		}
+		u.routes[key] = route
+	}
+	route.Requests++
+	route.LastUsedAt = time.Now()
+	if userAgent == "" {
+		return
+	}
+	for _, ua := range route.UserAgents {
+		if ua == userAgent {
+			return
+		}
+	}
+	if len(route.UserAgents) < maxDeprecationUserAgents {
+		route.UserAgents = append(route.UserAgents, userAgent)
+	}
+}
+
+// Report returns the usage of deprecated routes, most requested first.
+func (u *DeprecationUsage) Report() codersdk.DeprecationReport {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	report := codersdk.DeprecationReport{
+		Since:  u.since,
+		Routes: make([]codersdk.DeprecatedRouteUsage, 0, len(u.routes)),
+	}
+	for _, route := range u.routes {
+		usage := *route
+		usage.UserAgents = append([]string{}, route.UserAgents...)
+		report.Routes = append(report.Routes, usage)
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		a, b := report.Routes[i], report.Routes[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Method+" "+a.Path < b.Method+" "+b.Path
+	})
+	return report
+}
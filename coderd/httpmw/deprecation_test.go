@@ -0,0 +1,101 @@
+package httpmw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpmw"
+)
+
+func TestDeprecated(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoDates", func(t *testing.T) {
+		t.Parallel()
+
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Deprecated(httpmw.Deprecation{}))
+		rtr.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "true", res.Header.Get("Deprecation"))
+		require.Empty(t, res.Header.Get("Sunset"))
+		require.Empty(t, res.Header.Get("Link"))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		t.Parallel()
+
+		since := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+		sunset := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Deprecated(httpmw.Deprecation{
+			Since:     since,
+			Sunset:    sunset,
+			Successor: "/api/v2/new",
+		}))
+		rtr.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, "Thu, 01 Sep 2022 00:00:00 GMT", res.Header.Get("Deprecation"))
+		require.Equal(t, "Tue, 01 Nov 2022 00:00:00 GMT", res.Header.Get("Sunset"))
+		require.Equal(t, `</api/v2/new>; rel="successor-version"`, res.Header.Get("Link"))
+	})
+}
+
+func TestDeprecationUsage(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	usage := httpmw.NewDeprecationUsage(registry)
+	rtr := chi.NewRouter()
+	rtr.Use(usage.Middleware)
+	rtr.With(httpmw.Deprecated(httpmw.Deprecation{})).Get("/old/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	rtr.Get("/maybe", func(w http.ResponseWriter, r *http.Request) {
+		// Only some requests are deprecated, e.g. ones that use an old
+		// organization name.
+		if r.URL.Query().Has("old") {
+			w.Header().Set("Deprecation", "true")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, path := range []string{"/old/1", "/old/2", "/maybe", "/maybe?old"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("User-Agent", "coder/v0.9.0")
+		rtr.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	report := usage.Report()
+	require.Len(t, report.Routes, 2)
+	require.Equal(t, "/old/{id}", report.Routes[0].Path)
+	require.EqualValues(t, 2, report.Routes[0].Requests)
+	require.Equal(t, []string{"coder/v0.9.0"}, report.Routes[0].UserAgents)
+	require.Equal(t, "/maybe", report.Routes[1].Path)
+	require.EqualValues(t, 1, report.Routes[1].Requests)
+
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, "coderd_api_deprecated_requests_total", metrics[0].GetName())
+	require.Len(t, metrics[0].GetMetric(), 2)
+}
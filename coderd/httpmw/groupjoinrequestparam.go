@@ -0,0 +1,57 @@
+package httpmw
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+type groupJoinRequestParamContextKey struct{}
+
+// GroupJoinRequestParam returns the join request extracted via the
+// ExtractGroupJoinRequestParam middleware.
+func GroupJoinRequestParam(r *http.Request) database.GroupJoinRequest {
+	joinRequest, ok := r.Context().Value(groupJoinRequestParamContextKey{}).(database.GroupJoinRequest)
+	if !ok {
+		panic("developer error: group join request param middleware not provided")
+	}
+	return joinRequest
+}
+
+// ExtractGroupJoinRequestParam grabs a join request from the "request" URL
+// parameter. This middleware requires the ExtractGroupParam middleware, and
+// join requests for other groups are treated as not found.
+func ExtractGroupJoinRequestParam(db database.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			group := GroupParam(r)
+
+			joinRequestID, parsed := parseUUID(rw, r, "request")
+			if !parsed {
+				return
+			}
+
+			joinRequest, err := db.GetGroupJoinRequestByID(ctx, joinRequestID)
+			if errors.Is(err, sql.ErrNoRows) || (err == nil && joinRequest.GroupID != group.ID) {
+				httpapi.ResourceNotFound(rw)
+				return
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching group join request.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+
+			ctx = context.WithValue(ctx, groupJoinRequestParamContextKey{}, joinRequest)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package httpmw_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGroupJoinRequestParam(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (database.Store, database.GroupJoinRequest) {
+		t.Helper()
+
+		ctx, _ := testutil.Context(t)
+		db := databasefake.New()
+
+		organization, err := db.InsertOrganization(ctx, database.InsertOrganizationParams{
+			ID:          uuid.New(),
+			Name:        "banana",
+			Description: "wowie",
+			CreatedAt:   database.Now(),
+			UpdatedAt:   database.Now(),
+		})
+		require.NoError(t, err)
+
+		group, err := db.InsertGroup(ctx, database.InsertGroupParams{
+			ID:             uuid.New(),
+			Name:           "yeww",
+			OrganizationID: uuid.NullUUID{UUID: organization.ID, Valid: true},
+			Source:         database.GroupSourceUser,
+		})
+		require.NoError(t, err)
+
+		joinRequest, err := db.InsertGroupJoinRequest(ctx, database.InsertGroupJoinRequestParams{
+			ID:        uuid.New(),
+			GroupID:   group.ID,
+			UserID:    uuid.New(),
+			CreatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+
+		return db, joinRequest
+	}
+
+	serve := func(t *testing.T, db database.Store, groupID, joinRequestID uuid.UUID) int {
+		r := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		router := chi.NewRouter()
+		router.Use(
+			httpmw.ExtractGroupParam(db),
+			httpmw.ExtractGroupJoinRequestParam(db),
+		)
+		router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			joinRequest := httpmw.GroupJoinRequestParam(r)
+			require.Equal(t, joinRequestID, joinRequest.ID)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("group", groupID.String())
+		rctx.URLParams.Add("request", joinRequestID.String())
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		router.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		db, joinRequest := setup(t)
+		require.Equal(t, http.StatusOK, serve(t, db, joinRequest.GroupID, joinRequest.ID))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		db, joinRequest := setup(t)
+		require.Equal(t, http.StatusNotFound, serve(t, db, joinRequest.GroupID, uuid.New()))
+	})
+
+	t.Run("OtherGroup", func(t *testing.T) {
+		t.Parallel()
+
+		db, joinRequest := setup(t)
+		ctx, _ := testutil.Context(t)
+		group, err := db.GetGroupByID(ctx, joinRequest.GroupID)
+		require.NoError(t, err)
+		other, err := db.InsertGroup(ctx, database.InsertGroupParams{
+			ID:             uuid.New(),
+			Name:           "other",
+			OrganizationID: group.OrganizationID,
+			Source:         database.GroupSourceUser,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusNotFound, serve(t, db, other.ID, joinRequest.ID))
+	})
+}
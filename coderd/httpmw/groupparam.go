@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
@@ -24,8 +25,54 @@ func GroupParam(r *http.Request) database.Group {
 	return group
 }
 
-// ExtraGroupParam grabs a group from the "group" URL parameter.
+// ExtraGroupParam grabs a group from the "group" URL parameter. Deleted
+// groups are treated as not found.
 func ExtractGroupParam(db database.Store) func(http.Handler) http.Handler {
+	return extractGroupParam(db, false)
+}
+
+// ExtractDeletedGroupParam grabs a deleted group from the "group" URL
+// parameter. Groups that haven't been deleted are treated as not found.
+func ExtractDeletedGroupParam(db database.Store) func(http.Handler) http.Handler {
+	return extractGroupParam(db, true)
+}
+
+// ExtractGroupByNameParam grabs a group from the "groupname" URL parameter
+// within the organization from the ExtractOrganizationParam middleware, which
+// must be higher in the stack.
+func ExtractGroupByNameParam(db database.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			organization := OrganizationParam(r)
+
+			name := chi.URLParam(r, "groupname")
+			if name == "" {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+					Message: "\"groupname\" must be provided.",
+				})
+				return
+			}
+
+			spanCtx, span := startParamSpan(ctx, "httpmw.ExtractGroupByNameParam", "groupname", name)
+			group, err := traceQuery(span, func() (database.Group, error) {
+				return db.GetGroupByOrgAndName(spanCtx, database.GetGroupByOrgAndNameParams{
+					OrganizationID: uuid.NullUUID{UUID: organization.ID, Valid: true},
+					Name:           name,
+				})
+			})
+			span.end(err)
+			if !writeGroupParamError(rw, r, err) {
+				return
+			}
+
+			ctx = context.WithValue(ctx, groupParamContextKey{}, group)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+func extractGroupParam(db database.Store, deleted bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
@@ -35,22 +82,41 @@ func ExtractGroupParam(db database.Store) func(http.Handler) http.Handler {
 				return
 			}
 
-			group, err := db.GetGroupByID(r.Context(), groupID)
-			if errors.Is(err, sql.ErrNoRows) {
-				httpapi.ResourceNotFound(rw)
-				return
+			spanCtx, span := startParamSpan(ctx, "httpmw.ExtractGroupParam", "group", groupID.String())
+			group, err := traceQuery(span, func() (database.Group, error) {
+				return db.GetGroupByID(spanCtx, groupID)
+			})
+			if err == nil && group.DeletedAt.Valid != deleted {
+				err = sql.ErrNoRows
 			}
-			if err != nil {
-				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-					Message: "Internal error fetching group.",
-					Detail:  err.Error(),
-				})
+			span.end(err)
+			if !writeGroupParamError(rw, r, err) {
 				return
 			}
 
 			ctx = context.WithValue(ctx, groupParamContextKey{}, group)
-			chi.RouteContext(ctx).URLParams.Add("organization", group.OrganizationID.String())
+			// Deployment-wide groups don't belong to an organization.
+			if group.OrganizationID.Valid {
+				chi.RouteContext(ctx).URLParams.Add("organization", group.OrganizationID.UUID.String())
+			}
 			next.ServeHTTP(rw, r.WithContext(ctx))
 		})
 	}
 }
+
+// writeGroupParamError writes a response for an error fetching a group and
+// returns true if there was no error.
+func writeGroupParamError(rw http.ResponseWriter, r *http.Request, err error) bool {
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return false
+	}
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching group.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package httpmw_test
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -38,7 +39,8 @@ func TestGroupParam(t *testing.T) {
 		group, err := db.InsertGroup(ctx, database.InsertGroupParams{
 			ID:             uuid.New(),
 			Name:           "yeww",
-			OrganizationID: organization.ID,
+			OrganizationID: uuid.NullUUID{UUID: organization.ID, Valid: true},
+			Source:         database.GroupSourceUser,
 		})
 		require.NoError(t, err)
 
@@ -100,4 +102,77 @@ func TestGroupParam(t *testing.T) {
 		defer res.Body.Close()
 		require.Equal(t, http.StatusNotFound, res.StatusCode)
 	})
+	t.Run("Deleted", func(t *testing.T) {
+		t.Parallel()
+
+		db, group := setup(t)
+		ctx, _ := testutil.Context(t)
+		group, err := db.UpdateGroupDeletedAtByID(ctx, database.UpdateGroupDeletedAtByIDParams{
+			ID:        group.ID,
+			DeletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+
+		serve := func(mw func(http.Handler) http.Handler) int {
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Use(mw)
+			router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				g := httpmw.GroupParam(r)
+				require.Equal(t, group, g)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("group", group.ID.String())
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			router.ServeHTTP(w, r)
+
+			res := w.Result()
+			defer res.Body.Close()
+			return res.StatusCode
+		}
+
+		require.Equal(t, http.StatusNotFound, serve(httpmw.ExtractGroupParam(db)))
+		require.Equal(t, http.StatusOK, serve(httpmw.ExtractDeletedGroupParam(db)))
+	})
+
+	t.Run("ByName", func(t *testing.T) {
+		t.Parallel()
+
+		db, group := setup(t)
+
+		serve := func(name string) int {
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Use(
+				httpmw.ExtractOrganizationParam(db),
+				httpmw.ExtractGroupByNameParam(db),
+			)
+			router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				g := httpmw.GroupParam(r)
+				require.Equal(t, group, g)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("organization", group.OrganizationID.UUID.String())
+			rctx.URLParams.Add("groupname", name)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			router.ServeHTTP(w, r)
+
+			res := w.Result()
+			defer res.Body.Close()
+			return res.StatusCode
+		}
+
+		require.Equal(t, http.StatusOK, serve(group.Name))
+		require.Equal(t, http.StatusNotFound, serve("nope"))
+	})
 }
@@ -0,0 +1,161 @@
+package httpmw
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/codersdk"
+)
+
+// DefaultMaxBodyBytes limits the body of API requests that don't set a limit.
+const DefaultMaxBodyBytes = 4 << 20
+
+// RouteLimit limits how long a route takes to respond and how large the
+// bodies it accepts are. Zero values don't limit anything.
+type RouteLimit struct {
+	// Timeout responds with a 408 if the handler doesn't finish in time. The
+	// response is buffered, so it must not be set on routes that stream or
+	// hijack the connection.
+	Timeout time.Duration
+	// MaxBodyBytes responds with a 413 if the body is larger. Handlers that
+	// read the body with httpapi.Read get the same response when the body
+	// exceeds the limit without a Content-Length.
+	MaxBodyBytes int64
+}
+
+type limitedBodyKey struct{}
+
+type limitedBody struct {
+	original io.ReadCloser
+	limited  io.ReadCloser
+}
+
+// Limit applies the limit to the routes it wraps. Limits of inner routes
+// replace the limits of outer routes, so a router can set defaults that
+// specific routes raise or lower.
+func Limit(limit RouteLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if limit.MaxBodyBytes > 0 && r.Body != nil {
+				if r.ContentLength > limit.MaxBodyBytes {
+					httpapi.RequestTooLarge(rw, limit.MaxBodyBytes)
+					return
+				}
+				// Outer limits wrap the body already, which would cap a
+				// larger limit. Sub-requests, e.g. in a batch, inherit the
+				// context but have their own body.
+				body := r.Body
+				if limited, ok := r.Context().Value(limitedBodyKey{}).(limitedBody); ok && limited.limited == r.Body {
+					body = limited.original
+				}
+				r.Body = http.MaxBytesReader(rw, body, limit.MaxBodyBytes)
+				r = r.WithContext(context.WithValue(r.Context(), limitedBodyKey{}, limitedBody{
+					original: body,
+					limited:  r.Body,
+				}))
+			}
+			if limit.Timeout <= 0 {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			serveWithTimeout(rw, r, next, limit.Timeout)
+		})
+	}
+}
+
+// serveWithTimeout is like http.TimeoutHandler, but it responds with a
+// codersdk.Response.
+func serveWithTimeout(rw http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	// Headers set upstream, like the request ID, must be visible to handlers.
+	tw := &timeoutWriter{header: rw.Header().Clone()}
+	var w http.ResponseWriter = tw
+	// Audit logs depend on the status writer being downstream of
+	// tracing.Middleware, so handlers get one that records their status.
+	if _, ok := rw.(*tracing.StatusWriter); ok {
+		w = &tracing.StatusWriter{ResponseWriter: tw}
+	}
+
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(w, r)
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		// Re-panic, so Recover handles it like any other panic.
+		panic(p)
+	case <-done:
+		tw.mutex.Lock()
+		defer tw.mutex.Unlock()
+		for key, values := range tw.header {
+			rw.Header()[key] = values
+		}
+		if tw.status == 0 {
+			tw.status = http.StatusOK
+		}
+		rw.WriteHeader(tw.status)
+		_, _ = rw.Write(tw.body.Bytes())
+	case <-ctx.Done():
+		tw.mutex.Lock()
+		defer tw.mutex.Unlock()
+		tw.timedOut = true
+		httpapi.Write(ctx, rw, http.StatusRequestTimeout, codersdk.Response{
+			Message: "Request timed out.",
+			Detail:  fmt.Sprintf("The request didn't finish within %s.", timeout),
+			Code:    codersdk.ErrorCodeRequestTimeout,
+		})
+	}
+}
+
+// timeoutWriter buffers the response until the handler finishes. Writes
+// after the timeout are discarded.
+type timeoutWriter struct {
+	mutex    sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timedOut || w.status != 0 {
+		return
+	}
+	w.status = status
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
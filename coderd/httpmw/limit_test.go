@@ -0,0 +1,132 @@
+package httpmw_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+func TestLimit(t *testing.T) {
+	t.Parallel()
+
+	decode := func(t *testing.T, res *http.Response) codersdk.Response {
+		t.Helper()
+		var response codersdk.Response
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+		return response
+	}
+	readJSON := func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if !httpapi.Read(r.Context(), rw, r, &body) {
+			return
+		}
+		httpapi.Write(r.Context(), rw, http.StatusOK, body)
+	}
+
+	t.Run("ContentLength", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: 8}))
+		rtr.Post("/", readJSON)
+
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"too-long"}`)))
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+		require.Equal(t, codersdk.ErrorCodeRequestTooLarge, decode(t, res).Code)
+	})
+
+	t.Run("Streamed", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: 8}))
+		rtr.Post("/", readJSON)
+
+		// Bodies without a Content-Length are limited while they're read.
+		req := httptest.NewRequest("POST", "/", io.MultiReader(strings.NewReader(`{"name":"too-long"}`)))
+		req.ContentLength = -1
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, req)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+		require.Equal(t, codersdk.ErrorCodeRequestTooLarge, decode(t, res).Code)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: 8}))
+		rtr.With(httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: 1024})).Post("/", readJSON)
+
+		req := httptest.NewRequest("POST", "/", io.MultiReader(strings.NewReader(`{"name":"long-enough"}`)))
+		req.ContentLength = -1
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, req)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Limit(httpmw.RouteLimit{Timeout: time.Millisecond}))
+		rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			rw.WriteHeader(http.StatusInternalServerError)
+		})
+
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusRequestTimeout, res.StatusCode)
+		require.Equal(t, codersdk.ErrorCodeRequestTimeout, decode(t, res).Code)
+	})
+
+	t.Run("WithinTimeout", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Limit(httpmw.RouteLimit{Timeout: time.Minute}))
+		rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("X-Test", "value")
+			httpapi.Write(r.Context(), rw, http.StatusCreated, codersdk.Response{Message: "created"})
+		})
+
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.Equal(t, "value", res.Header.Get("X-Test"))
+		require.Equal(t, "created", decode(t, res).Message)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.Limit(httpmw.RouteLimit{Timeout: time.Minute}))
+		rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+			panic("oops")
+		})
+
+		require.PanicsWithValue(t, "oops", func() {
+			rtr.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		})
+	})
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
@@ -34,17 +39,58 @@ func OrganizationMemberParam(r *http.Request) database.OrganizationMember {
 }
 
 // ExtractOrganizationParam grabs an organization from the "organization" URL parameter.
+// The parameter is either the ID or the name of the organization. Names an
+// organization had before it was renamed still resolve, but the response
+// includes a deprecation header that links to the new name.
 // This middleware requires the API key middleware higher in the call stack for authentication.
 func ExtractOrganizationParam(db database.Store) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
-			orgID, ok := parseUUID(rw, r, "organization")
-			if !ok {
+			orgQuery := chi.URLParam(r, "organization")
+			if orgQuery == "" {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+					Message: "\"organization\" must be provided.",
+				})
 				return
 			}
 
-			organization, err := db.GetOrganizationByID(ctx, orgID)
+			orgID, parseErr := uuid.Parse(orgQuery)
+			if parseErr != nil && !httpapi.UsernameValid(orgQuery) {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+					Message: fmt.Sprintf("Invalid organization %q.", orgQuery),
+					Detail:  "Must be an organization ID or name.",
+				})
+				return
+			}
+
+			spanCtx, span := startParamSpan(ctx, "httpmw.ExtractOrganizationParam", "organization", orgQuery)
+			var (
+				organization database.Organization
+				renamed      bool
+				err          error
+			)
+			if parseErr == nil {
+				organization, err = traceQuery(span, func() (database.Organization, error) {
+					return db.GetOrganizationByID(spanCtx, orgID)
+				})
+			} else {
+				organization, err = traceQuery(span, func() (database.Organization, error) {
+					return db.GetOrganizationByName(spanCtx, orgQuery)
+				})
+				if errors.Is(err, sql.ErrNoRows) {
+					organization, err = organizationByAlias(spanCtx, db, span, orgQuery)
+					renamed = err == nil
+				}
+			}
+			span.end(err)
+			if renamed {
+				// Clients should move to the new name, since the old one can
+				// be taken by another organization.
+				rw.Header().Set("Deprecation", "true")
+				rw.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"",
+					strings.Replace(r.URL.Path, "/"+orgQuery, "/"+organization.Name, 1)))
+			}
 			if errors.Is(err, sql.ErrNoRows) {
 				httpapi.ResourceNotFound(rw)
 				return
@@ -62,6 +108,20 @@ func ExtractOrganizationParam(db database.Store) func(http.Handler) http.Handler
 	}
 }
 
+// organizationByAlias returns the organization that previously had the
+// name.
+func organizationByAlias(ctx context.Context, db database.Store, span *paramSpan, name string) (database.Organization, error) {
+	alias, err := traceQuery(span, func() (database.OrganizationAlias, error) {
+		return db.GetOrganizationAliasByName(ctx, name)
+	})
+	if err != nil {
+		return database.Organization{}, err
+	}
+	return traceQuery(span, func() (database.Organization, error) {
+		return db.GetOrganizationByID(ctx, alias.OrganizationID)
+	})
+}
+
 // ExtractOrganizationMemberParam grabs a user membership from the "organization" and "user" URL parameter.
 // This middleware requires the ExtractUser and ExtractOrganization middleware higher in the stack
 func ExtractOrganizationMemberParam(db database.Store) func(http.Handler) http.Handler {
@@ -71,10 +131,14 @@ func ExtractOrganizationMemberParam(db database.Store) func(http.Handler) http.H
 			organization := OrganizationParam(r)
 			user := UserParam(r)
 
-			organizationMember, err := db.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
-				OrganizationID: organization.ID,
-				UserID:         user.ID,
+			spanCtx, span := startParamSpan(ctx, "httpmw.ExtractOrganizationMemberParam", "user", user.ID.String())
+			organizationMember, err := traceQuery(span, func() (database.OrganizationMember, error) {
+				return db.GetOrganizationMemberByUserID(spanCtx, database.GetOrganizationMemberByUserIDParams{
+					OrganizationID: organization.ID,
+					UserID:         user.ID,
+				})
 			})
+			span.end(err)
 			if errors.Is(err, sql.ErrNoRows) {
 				httpapi.ResourceNotFound(rw)
 				return
@@ -12,6 +12,10 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/databasefake"
@@ -103,7 +107,7 @@ func TestOrganizationParam(t *testing.T) {
 		require.Equal(t, http.StatusNotFound, res.StatusCode)
 	})
 
-	t.Run("InvalidUUID", func(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
 		t.Parallel()
 		var (
 			db   = databasefake.New()
@@ -111,7 +115,7 @@ func TestOrganizationParam(t *testing.T) {
 			r, _ = setupAuthentication(db)
 			rtr  = chi.NewRouter()
 		)
-		chi.RouteContext(r.Context()).URLParams.Add("organization", "not-a-uuid")
+		chi.RouteContext(r.Context()).URLParams.Add("organization", "not a name!")
 		rtr.Use(
 			httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
 				DB:              db,
@@ -202,4 +206,109 @@ func TestOrganizationParam(t *testing.T) {
 		defer res.Body.Close()
 		require.Equal(t, http.StatusOK, res.StatusCode)
 	})
+	t.Run("Alias", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db   = databasefake.New()
+			rw   = httptest.NewRecorder()
+			r, _ = setupAuthentication(db)
+			rtr  = chi.NewRouter()
+		)
+		organization, err := db.InsertOrganization(r.Context(), database.InsertOrganizationParams{
+			ID:        uuid.New(),
+			Name:      "new",
+			CreatedAt: database.Now(),
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+		_, err = db.InsertOrganizationAlias(r.Context(), database.InsertOrganizationAliasParams{
+			Name:           "old",
+			OrganizationID: organization.ID,
+			CreatedAt:      database.Now(),
+		})
+		require.NoError(t, err)
+		r.URL.Path = "/organizations/old/members"
+		chi.RouteContext(r.Context()).URLParams.Add("organization", "old")
+		rtr.Use(
+			httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
+				DB:              db,
+				RedirectToLogin: false,
+			}),
+			httpmw.ExtractOrganizationParam(db),
+		)
+		rtr.Get("/organizations/old/members", func(rw http.ResponseWriter, r *http.Request) {
+			require.Equal(t, organization.ID, httpmw.OrganizationParam(r).ID)
+			rw.WriteHeader(http.StatusOK)
+		})
+		rtr.ServeHTTP(rw, r)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "true", res.Header.Get("Deprecation"))
+		require.Equal(t, `</organizations/new/members>; rel="successor-version"`, res.Header.Get("Link"))
+	})
+	t.Run("Traced", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db      = databasefake.New()
+			rw      = httptest.NewRecorder()
+			r, user = setupAuthentication(db)
+			rtr     = chi.NewRouter()
+			spans   = tracetest.NewSpanRecorder()
+		)
+		organization, err := db.InsertOrganization(r.Context(), database.InsertOrganizationParams{
+			ID:        uuid.New(),
+			Name:      "test",
+			CreatedAt: database.Now(),
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+		_, err = db.InsertOrganizationMember(r.Context(), database.InsertOrganizationMemberParams{
+			OrganizationID: organization.ID,
+			UserID:         user.ID,
+			CreatedAt:      database.Now(),
+			UpdatedAt:      database.Now(),
+		})
+		require.NoError(t, err)
+		chi.RouteContext(r.Context()).URLParams.Add("organization", organization.Name)
+		chi.RouteContext(r.Context()).URLParams.Add("user", user.ID.String())
+
+		ctx, parent := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test").Start(r.Context(), "request")
+		r = r.WithContext(ctx)
+		rtr.Use(
+			httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
+				DB:              db,
+				RedirectToLogin: false,
+			}),
+			httpmw.ExtractOrganizationParam(db),
+			httpmw.ExtractUserParam(db),
+			httpmw.ExtractOrganizationMemberParam(db),
+		)
+		rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+			// Handlers aren't part of the lookup spans.
+			require.Equal(t, parent.SpanContext().SpanID(), trace.SpanFromContext(r.Context()).SpanContext().SpanID())
+			rw.WriteHeader(http.StatusOK)
+		})
+		rtr.ServeHTTP(rw, r)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		ended := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range spans.Ended() {
+			ended[span.Name()] = span
+		}
+		for _, name := range []string{"httpmw.ExtractOrganizationParam", "httpmw.ExtractOrganizationMemberParam"} {
+			span, ok := ended[name]
+			require.True(t, ok, name)
+			require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			attributes := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes() {
+				attributes[kv.Key] = kv.Value
+			}
+			require.EqualValues(t, 1, attributes["db.queries"].AsInt64(), name)
+			require.True(t, attributes["coder.param_found"].AsBool(), name)
+			require.Contains(t, attributes, attribute.Key("db.duration_ms"))
+		}
+	})
 }
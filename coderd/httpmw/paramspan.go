@@ -0,0 +1,56 @@
+package httpmw
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/coder/coder/coderd/tracing"
+)
+
+// paramSpan traces how a middleware looks up the resource of a URL parameter,
+// so slow requests can be attributed to the lookup or to the handler. It
+// ends before the handler is called.
+type paramSpan struct {
+	span    trace.Span
+	queries int
+	dbTime  time.Duration
+}
+
+func startParamSpan(ctx context.Context, name, param, value string) (context.Context, *paramSpan) {
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracing.TracerName).Start(ctx, name, trace.WithAttributes(
+		attribute.String("coder.param", param),
+		attribute.String("coder.param_value", value),
+	))
+	return ctx, &paramSpan{span: span}
+}
+
+// end records the database time of the lookup and ends the span. Resources
+// that don't exist aren't errors.
+func (s *paramSpan) end(err error) {
+	s.span.SetAttributes(
+		attribute.Int("db.queries", s.queries),
+		attribute.Float64("db.duration_ms", float64(s.dbTime)/float64(time.Millisecond)),
+		attribute.Bool("coder.param_found", err == nil),
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// traceQuery runs a database query of the lookup and adds its duration to
+// the span.
+func traceQuery[T any](s *paramSpan, query func() (T, error)) (T, error) {
+	start := time.Now()
+	value, err := query()
+	s.queries++
+	s.dbTime += time.Since(start)
+	return value, err
+}
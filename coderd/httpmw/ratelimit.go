@@ -1,10 +1,15 @@
 package httpmw
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-chi/httprate"
+	"github.com/google/uuid"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
@@ -38,3 +43,153 @@ func RateLimitPerMinute(count int) func(http.Handler) http.Handler {
 		}),
 	)
 }
+
+// RateLimitConfig configures a token bucket rate limiter.
+type RateLimitConfig struct {
+	// PerMinute is how many requests are allowed per minute. The limiter is
+	// disabled if it's <= 0.
+	PerMinute int
+	// Burst is how many requests can be made at once. It defaults to
+	// PerMinute.
+	Burst int
+	// ByOrganization gives an API key a separate bucket for each
+	// organization its requests are made in. The organization comes from
+	// the organization, workspace, or template param of the request, so
+	// those middlewares must be higher in the call stack.
+	ByOrganization bool
+}
+
+// RateLimitAPIKey returns a handler that limits the requests of each API key
+// with a token bucket. The limits are reported in the RateLimit-Limit,
+// RateLimit-Remaining, and RateLimit-Reset headers, and requests over the
+// limit get a 429 with a Retry-After header. Requests without an API key
+// aren't limited, so ExtractAPIKey must be higher in the call stack.
+func RateLimitAPIKey(config RateLimitConfig) func(http.Handler) http.Handler {
+	if config.PerMinute <= 0 {
+		return func(handler http.Handler) http.Handler {
+			return handler
+		}
+	}
+	if config.Burst <= 0 {
+		config.Burst = config.PerMinute
+	}
+	limiter := newTokenBucketLimiter(config.PerMinute, config.Burst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			apiKey, ok := APIKeyOptional(r)
+			if !ok {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			key := apiKey.ID
+			if config.ByOrganization {
+				if organizationID, ok := requestOrganizationID(r); ok {
+					key += "/" + organizationID.String()
+				}
+			}
+
+			allowed, remaining, reset, retryAfter := limiter.take(key, time.Now())
+			rw.Header().Set("RateLimit-Limit", strconv.Itoa(config.Burst))
+			rw.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			rw.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+			if !allowed {
+				rw.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+				httpapi.Write(r.Context(), rw, http.StatusTooManyRequests, codersdk.Response{
+					Message: "You've been rate limited for sending too many requests!",
+					Detail:  fmt.Sprintf("Each API key can send %d requests per minute to this endpoint.", config.PerMinute),
+				})
+				return
+			}
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// requestOrganizationID returns the organization of the resource the request
+// is made to, if a param middleware has extracted it.
+func requestOrganizationID(r *http.Request) (uuid.UUID, bool) {
+	ctx := r.Context()
+	if organization, ok := ctx.Value(organizationParamContextKey{}).(database.Organization); ok {
+		return organization.ID, true
+	}
+	if workspace, ok := ctx.Value(workspaceParamContextKey{}).(database.Workspace); ok {
+		return workspace.OrganizationID, true
+	}
+	if template, ok := ctx.Value(templateParamContextKey{}).(database.Template); ok {
+		return template.OrganizationID, true
+	}
+	return uuid.Nil, false
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// tokenBucketLimiter keeps a token bucket for each key. Buckets refill
+// continuously, and full buckets are forgotten since they're the same as a
+// new one.
+type tokenBucketLimiter struct {
+	// perSecond is how many tokens are added to a bucket each second.
+	perSecond float64
+	burst     float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newTokenBucketLimiter(perMinute, burst int) *tokenBucketLimiter {
+	return &tokenBucketLimiter{
+		perSecond: float64(perMinute) / 60,
+		burst:     float64(burst),
+		buckets:   map[string]*tokenBucket{},
+	}
+}
+
+// take removes a token from the bucket of the key. It returns whether there
+// was a token to take, how many are left, how long until the bucket is full,
+// and how long until the next token is available.
+func (l *tokenBucketLimiter) take(key string, now time.Time) (allowed bool, remaining int, reset, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		l.lastSweep = now
+		for bucketKey, bucket := range l.buckets {
+			if l.refill(bucket, now) >= l.burst {
+				delete(l.buckets, bucketKey)
+			}
+		}
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updatedAt: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = l.refill(bucket, now)
+	bucket.updatedAt = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		allowed = true
+	} else {
+		retryAfter = l.duration(1 - bucket.tokens)
+	}
+	return allowed, int(bucket.tokens), l.duration(l.burst - bucket.tokens), retryAfter
+}
+
+// refill returns the tokens in the bucket at the given time.
+func (l *tokenBucketLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	tokens := bucket.tokens + now.Sub(bucket.updatedAt).Seconds()*l.perSecond
+	return math.Min(tokens, l.burst)
+}
+
+// duration returns how long it takes to add the tokens to a bucket.
+func (l *tokenBucketLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.perSecond * float64(time.Second))
+}
@@ -1,14 +1,20 @@
 package httpmw_test
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
 
@@ -32,3 +38,103 @@ func TestRateLimit(t *testing.T) {
 		}, testutil.WaitShort, testutil.IntervalFast)
 	})
 }
+
+func TestRateLimitAPIKey(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T, config httpmw.RateLimitConfig) (http.Handler, func() *http.Request) {
+		db := databasefake.New()
+		rtr := chi.NewRouter()
+		rtr.Use(
+			httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{DB: db}),
+			httpmw.RateLimitAPIKey(config),
+		)
+		rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		})
+
+		r := httptest.NewRequest("GET", "/", nil)
+		user := createUser(r.Context(), t, db)
+		newRequest := func() *http.Request {
+			id, secret := randomAPIKeyParts()
+			hashed := sha256.Sum256([]byte(secret))
+			_, err := db.InsertAPIKey(r.Context(), database.InsertAPIKeyParams{
+				ID:           id,
+				HashedSecret: hashed[:],
+				UserID:       user.ID,
+				LastUsed:     database.Now(),
+				ExpiresAt:    database.Now().Add(time.Hour),
+				LoginType:    database.LoginTypePassword,
+				Scope:        database.APIKeyScopeAll,
+			})
+			require.NoError(t, err)
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set(codersdk.SessionCustomHeader, fmt.Sprintf("%s-%s", id, secret))
+			return r
+		}
+		return rtr, newRequest
+	}
+	serve := func(handler http.Handler, r *http.Request) *http.Response {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, r)
+		return rw.Result()
+	}
+
+	t.Run("Limited", func(t *testing.T) {
+		t.Parallel()
+		handler, newRequest := setup(t, httpmw.RateLimitConfig{PerMinute: 2})
+		r := newRequest()
+
+		res := serve(handler, r)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "2", res.Header.Get("RateLimit-Limit"))
+		require.Equal(t, "1", res.Header.Get("RateLimit-Remaining"))
+		require.Equal(t, "30", res.Header.Get("RateLimit-Reset"))
+
+		res = serve(handler, r)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "0", res.Header.Get("RateLimit-Remaining"))
+
+		res = serve(handler, r)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.Equal(t, "0", res.Header.Get("RateLimit-Remaining"))
+		require.Equal(t, "30", res.Header.Get("Retry-After"))
+
+		// Each API key has its own bucket.
+		res = serve(handler, newRequest())
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Burst", func(t *testing.T) {
+		t.Parallel()
+		handler, newRequest := setup(t, httpmw.RateLimitConfig{PerMinute: 1, Burst: 3})
+		r := newRequest()
+
+		for i := 0; i < 3; i++ {
+			res := serve(handler, r)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "3", res.Header.Get("RateLimit-Limit"))
+		}
+		res := serve(handler, r)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		handler, newRequest := setup(t, httpmw.RateLimitConfig{})
+		r := newRequest()
+
+		for i := 0; i < 10; i++ {
+			res := serve(handler, r)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Empty(t, res.Header.Get("RateLimit-Limit"))
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
 )
 
 type requestIDContextKey struct{}
@@ -20,15 +21,22 @@ func RequestID(r *http.Request) uuid.UUID {
 	return rid
 }
 
-// AttachRequestID adds a request ID to each HTTP request.
+// AttachRequestID adds a request ID to each HTTP request. Requests that have a
+// UUID in the X-Request-Id header keep it, so clients and proxies can
+// correlate their logs with ours. The ID is returned in the response headers.
 func AttachRequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		rid := uuid.New()
+		rid, err := uuid.Parse(r.Header.Get(codersdk.RequestIDHeader))
+		if err != nil || rid == uuid.Nil {
+			rid = uuid.New()
+		}
 		ridString := rid.String()
 
 		ctx := context.WithValue(r.Context(), requestIDContextKey{}, rid)
 		ctx = slog.With(ctx, slog.F("request_id", rid))
 
+		rw.Header().Set(codersdk.RequestIDHeader, ridString)
+		// Older clients read this header.
 		rw.Header().Set("X-Coder-Request-Id", ridString)
 		next.ServeHTTP(rw, r.WithContext(ctx))
 	})
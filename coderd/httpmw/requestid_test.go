@@ -1,33 +1,87 @@
 package httpmw_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
 )
 
 func TestRequestID(t *testing.T) {
 	t.Parallel()
 
-	rtr := chi.NewRouter()
-	rtr.Use(httpmw.AttachRequestID)
-	rtr.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		rid := httpmw.RequestID(r)
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(rid.String()))
+	serve := func(t *testing.T, requestID string) (*http.Response, string) {
+		t.Helper()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.AttachRequestID)
+		rtr.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			rid := httpmw.RequestID(r)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(rid.String()))
+		})
+		r := httptest.NewRequest("GET", "/", nil)
+		if requestID != "" {
+			r.Header.Set(codersdk.RequestIDHeader, requestID)
+		}
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, r)
+
+		res := rw.Result()
+		t.Cleanup(func() { _ = res.Body.Close() })
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		return res, rw.Body.String()
+	}
+
+	t.Run("Generated", func(t *testing.T) {
+		t.Parallel()
+		res, rid := serve(t, "")
+		require.NotEmpty(t, rid)
+		require.Equal(t, rid, res.Header.Get(codersdk.RequestIDHeader))
+		require.Equal(t, rid, res.Header.Get("X-Coder-Request-ID"))
+	})
+
+	t.Run("Accepted", func(t *testing.T) {
+		t.Parallel()
+		expected := uuid.NewString()
+		res, rid := serve(t, expected)
+		require.Equal(t, expected, rid)
+		require.Equal(t, expected, res.Header.Get(codersdk.RequestIDHeader))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		res, rid := serve(t, "not-a-uuid")
+		_, err := uuid.Parse(rid)
+		require.NoError(t, err)
+		require.Equal(t, rid, res.Header.Get(codersdk.RequestIDHeader))
+	})
+
+	t.Run("ErrorDetail", func(t *testing.T) {
+		t.Parallel()
+		rtr := chi.NewRouter()
+		rtr.Use(httpmw.AttachRequestID)
+		rtr.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), w, http.StatusBadRequest, codersdk.Response{
+				Message: "Bad request.",
+				Detail:  "Something went wrong.",
+			})
+		})
+		rid := uuid.NewString()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(codersdk.RequestIDHeader, rid)
+		rw := httptest.NewRecorder()
+		rtr.ServeHTTP(rw, r)
+
+		var response codersdk.Response
+		require.NoError(t, json.NewDecoder(rw.Body).Decode(&response))
+		require.Equal(t, "Something went wrong. (request ID: "+rid+")", response.Detail)
 	})
-	r := httptest.NewRequest("GET", "/", nil)
-	rw := httptest.NewRecorder()
-	rtr.ServeHTTP(rw, r)
-
-	res := rw.Result()
-	defer res.Body.Close()
-	require.Equal(t, http.StatusOK, res.StatusCode)
-	require.NotEmpty(t, res.Header.Get("X-Coder-Request-ID"))
-	require.NotEmpty(t, rw.Body.Bytes())
 }
@@ -0,0 +1,55 @@
+package httpmw
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+type roleRequestParamContextKey struct{}
+
+// RoleRequestParam returns the role request extracted via the
+// ExtractRoleRequestParam middleware.
+func RoleRequestParam(r *http.Request) database.RoleRequest {
+	request, ok := r.Context().Value(roleRequestParamContextKey{}).(database.RoleRequest)
+	if !ok {
+		panic("developer error: role request param middleware not provided")
+	}
+	return request
+}
+
+// ExtractRoleRequestParam grabs a role request from the "rolerequest" URL
+// parameter.
+func ExtractRoleRequestParam(db database.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			requestID, parsed := parseUUID(rw, r, "rolerequest")
+			if !parsed {
+				return
+			}
+
+			request, err := db.GetRoleRequestByID(ctx, requestID)
+			if errors.Is(err, sql.ErrNoRows) {
+				httpapi.ResourceNotFound(rw)
+				return
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching role request.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+
+			ctx = context.WithValue(ctx, roleRequestParamContextKey{}, request)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
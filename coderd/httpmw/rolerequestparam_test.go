@@ -0,0 +1,83 @@
+package httpmw_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRoleRequestParam(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (database.Store, database.RoleRequest) {
+		t.Helper()
+
+		ctx, _ := testutil.Context(t)
+		db := databasefake.New()
+
+		request, err := db.InsertRoleRequest(ctx, database.InsertRoleRequestParams{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			Role:      rbac.RoleTemplateAdmin(),
+			CreatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+
+		return db, request
+	}
+
+	serve := func(t *testing.T, db database.Store, requestID string) int {
+		r := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		router := chi.NewRouter()
+		router.Use(httpmw.ExtractRoleRequestParam(db))
+		router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			request := httpmw.RoleRequestParam(r)
+			require.Equal(t, requestID, request.ID.String())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("rolerequest", requestID)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+		router.ServeHTTP(w, r)
+
+		res := w.Result()
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		db, request := setup(t)
+		require.Equal(t, http.StatusOK, serve(t, db, request.ID.String()))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		db, _ := setup(t)
+		require.Equal(t, http.StatusNotFound, serve(t, db, uuid.NewString()))
+	})
+
+	t.Run("BadUUID", func(t *testing.T) {
+		t.Parallel()
+
+		db, _ := setup(t)
+		require.Equal(t, http.StatusBadRequest, serve(t, db, "not-a-uuid"))
+	})
+}
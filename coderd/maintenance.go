@@ -0,0 +1,188 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// maintenanceCacheTTL is how long a replica takes to notice that another
+// replica changed maintenance mode.
+const maintenanceCacheTTL = 5 * time.Second
+
+// maintenanceRefreshTimeout bounds how long requests wait on the database for
+// the maintenance mode before the last known mode is used.
+const maintenanceRefreshTimeout = 5 * time.Second
+
+// maintenanceCache keeps the maintenance mode in memory, since it's checked
+// on every authenticated request. The mutex only guards the fields, and
+// concurrent refreshes share one query.
+type maintenanceCache struct {
+	mutex       sync.Mutex
+	maintenance codersdk.Maintenance
+	expiresAt   time.Time
+	refresh     singleflight.Group
+}
+
+// get returns the cached maintenance mode and when it expires. The expiry is
+// zero if the mode was never loaded.
+func (c *maintenanceCache) get() (codersdk.Maintenance, time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.maintenance, c.expiresAt
+}
+
+func (c *maintenanceCache) set(maintenance codersdk.Maintenance) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maintenance = maintenance
+	c.expiresAt = time.Now().Add(maintenanceCacheTTL)
+}
+
+func (api *API) maintenance(rw http.ResponseWriter, r *http.Request) {
+	maintenance, err := api.maintenanceMode(r.Context())
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, maintenance)
+}
+
+func (api *API) putMaintenance(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceDeploymentFlags) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.UpdateMaintenanceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	maintenance := codersdk.Maintenance{
+		Enabled:           req.Enabled,
+		Message:           req.Message,
+		RetryAfterSeconds: req.RetryAfterSeconds,
+		UpdatedAt:         database.Now(),
+	}
+	value, err := json.Marshal(maintenance)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	err = api.Database.UpsertMaintenance(ctx, string(value))
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	// Other replicas notice the change when their cache expires.
+	api.maintenanceCache.set(maintenance)
+
+	httpapi.Write(ctx, rw, http.StatusOK, maintenance)
+}
+
+// maintenanceMode returns the maintenance mode of the deployment. The last
+// known mode is kept if the database can't be reached, e.g. while it's
+// migrated.
+func (api *API) maintenanceMode(ctx context.Context) (codersdk.Maintenance, error) {
+	cache := &api.maintenanceCache
+	maintenance, expiresAt := cache.get()
+	if time.Now().Before(expiresAt) {
+		return maintenance, nil
+	}
+
+	// The query runs without holding the mutex, so requests aren't blocked
+	// on a slow database while a fresh value is loaded. It's shared by every
+	// waiting request, so it doesn't fail when the first one is canceled.
+	value, err, _ := cache.refresh.Do("", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(api.maintenanceCtx, maintenanceRefreshTimeout)
+		defer cancel()
+		value, err := api.Database.GetMaintenance(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			value, err = "{}", nil
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("get maintenance mode: %w", err)
+		}
+		var maintenance codersdk.Maintenance
+		err = json.Unmarshal([]byte(value), &maintenance)
+		if err != nil {
+			return nil, xerrors.Errorf("unmarshal maintenance mode: %w", err)
+		}
+		cache.set(maintenance)
+		return maintenance, nil
+	})
+	if err != nil {
+		if !expiresAt.IsZero() {
+			api.Logger.Warn(ctx, "refresh maintenance mode", slog.Error(err))
+			cache.set(maintenance)
+			return maintenance, nil
+		}
+		return codersdk.Maintenance{}, err
+	}
+	return value.(codersdk.Maintenance), nil
+}
+
+// maintenanceEnabled returns whether the deployment is in maintenance mode.
+// It fails open, so an unreachable database never locks everyone out.
+func (api *API) maintenanceEnabled(ctx context.Context) (codersdk.Maintenance, bool) {
+	maintenance, err := api.maintenanceMode(ctx)
+	if err != nil {
+		api.Logger.Warn(ctx, "get maintenance mode", slog.Error(err))
+		return codersdk.Maintenance{}, false
+	}
+	return maintenance, maintenance.Enabled
+}
+
+// EnforceMaintenance rejects requests while the deployment is in maintenance
+// mode, except from users that can change maintenance mode. It runs after the
+// ExtractAPIKey or ExtractWorkspaceAgent handlers, so unauthenticated routes,
+// e.g. build info and login, stay available.
+func (api *API) EnforceMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		maintenance, enabled := api.maintenanceEnabled(ctx)
+		if !enabled {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		if _, ok := httpmw.UserAuthorizationOptional(r); ok && api.Authorize(r, rbac.ActionUpdate, rbac.ResourceDeploymentFlags) {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		detail := "Try again later."
+		if maintenance.RetryAfterSeconds > 0 {
+			rw.Header().Set("Retry-After", strconv.FormatInt(maintenance.RetryAfterSeconds, 10))
+			detail = fmt.Sprintf("Try again in %s.", time.Duration(maintenance.RetryAfterSeconds)*time.Second)
+		}
+		message := maintenance.Message
+		if message == "" {
+			message = "The deployment is in maintenance mode."
+		}
+		httpapi.Write(ctx, rw, http.StatusServiceUnavailable, codersdk.Response{
+			Message: message,
+			Detail:  detail,
+			Code:    codersdk.ErrorCodeMaintenance,
+		})
+	})
+}
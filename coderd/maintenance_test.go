@@ -0,0 +1,148 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMaintenance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		maintenance, err := client.UpdateMaintenance(ctx, codersdk.UpdateMaintenanceRequest{
+			Enabled:           true,
+			Message:           "Upgrading to the next release.",
+			RetryAfterSeconds: 120,
+		})
+		require.NoError(t, err)
+		require.True(t, maintenance.Enabled)
+
+		// Anyone can check maintenance mode.
+		anonymous := codersdk.New(client.URL)
+		maintenance, err = anonymous.Maintenance(ctx)
+		require.NoError(t, err)
+		require.True(t, maintenance.Enabled)
+		require.Equal(t, "Upgrading to the next release.", maintenance.Message)
+
+		res, err := member.Request(ctx, http.MethodGet, "/api/v2/users/me", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.Equal(t, "120", res.Header.Get("Retry-After"))
+
+		_, err = member.User(ctx, codersdk.Me)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeMaintenance))
+
+		// Owners aren't affected.
+		_, err = client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		_, err = client.UpdateMaintenance(ctx, codersdk.UpdateMaintenanceRequest{})
+		require.NoError(t, err)
+		_, err = member.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+	})
+
+	t.Run("AgentsAndProvisioners", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:           echo.ParseComplete,
+			ProvisionDryRun: echo.ProvisionComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id: uuid.NewString(),
+								Auth: &proto.Agent_Token{
+									Token: authToken,
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateMaintenance(ctx, codersdk.UpdateMaintenanceRequest{Enabled: true})
+		require.NoError(t, err)
+
+		agentClient := codersdk.New(client.URL)
+		agentClient.SessionToken = authToken
+		_, err = agentClient.WorkspaceAgentMetadata(ctx)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeMaintenance))
+
+		// Provisioners don't start jobs until maintenance mode is disabled.
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		require.Never(t, func() bool {
+			build, err := client.WorkspaceBuild(ctx, build.ID)
+			return err == nil && build.Job.Status != codersdk.ProvisionerJobPending
+		}, testutil.IntervalSlow*3, testutil.IntervalFast)
+
+		_, err = client.UpdateMaintenance(ctx, codersdk.UpdateMaintenanceRequest{})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+		_, err = agentClient.WorkspaceAgentMetadata(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("MemberCannotUpdate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.UpdateMaintenance(ctx, codersdk.UpdateMaintenanceRequest{Enabled: true})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("NegativeRetryAfter", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateMaintenance(ctx, codersdk.UpdateMaintenanceRequest{
+			Enabled:           true,
+			RetryAfterSeconds: -1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		maintenance, err := client.Maintenance(ctx)
+		require.NoError(t, err)
+		require.False(t, maintenance.Enabled)
+	})
+}
@@ -2,7 +2,12 @@ package coderd
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -73,32 +78,273 @@ func (api *API) putMemberRoles(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionUpdated, organization.ID, user.ID)
 
 	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationMember(updatedUser))
 }
 
-func (api *API) updateOrganizationMemberRoles(ctx context.Context, args database.UpdateMemberRolesParams) (database.OrganizationMember, error) {
+func (api *API) organizationMembers(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	params, errs := organizationMemberSearchQuery(r.URL.Query().Get("q"), organization.ID)
+	if len(errs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid organization member search query.",
+			Validations: errs,
+		})
+		return
+	}
+
+	page, ok := httpapi.ParseCursorPagination(rw, r)
+	if !ok {
+		return
+	}
+
+	params.OrganizationID = organization.ID
+	params.AfterID = page.AfterID
+	params.LimitOpt = page.FetchLimit()
+	members, err := api.Database.GetOrganizationMembers(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization members.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	members, nextCursor := httpapi.Page(page, members, func(member database.GetOrganizationMembersRow) uuid.UUID {
+		return member.UserID
+	})
+	members, err = AuthorizeFilter(api.HTTPAuth, r, rbac.ActionRead, members)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization members.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	groupMemberships, err := api.Database.GetGroupMembershipsByUserIDs(ctx, database.GetGroupMembershipsByUserIDsParams{
+		OrganizationID: organization.ID,
+		UserIds:        userIDs,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching group memberships.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	groupsByUserID := make(map[uuid.UUID][]codersdk.OrganizationMemberGroup)
+	for _, membership := range groupMemberships {
+		groupsByUserID[membership.UserID] = append(groupsByUserID[membership.UserID], codersdk.OrganizationMemberGroup{
+			ID:   membership.GroupID,
+			Name: membership.GroupName,
+		})
+	}
+
+	resp := make([]codersdk.OrganizationMemberWithUser, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, convertOrganizationMemberWithUser(member, groupsByUserID[member.UserID]))
+	}
+
+	if !page.Requested() {
+		httpapi.Write(ctx, rw, http.StatusOK, resp)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OrganizationMembersResponse{
+		Members:    resp,
+		NextCursor: nextCursor,
+	})
+}
+
+// patchMemberRoles updates the roles of many members of an organization in
+// a single transaction. Nothing is changed if any of the updates is invalid.
+func (api *API) patchMemberRoles(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+		apiKey       = httpmw.APIKey(r)
+		actorRoles   = httpmw.UserAuthorization(r)
+	)
+
+	var req codersdk.UpdateOrganizationMembersRolesRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var (
+		validations []codersdk.ValidationError
+		updates     = make([]database.UpdateMemberRolesParams, 0, len(req.Updates))
+		seen        = make(map[uuid.UUID]struct{}, len(req.Updates))
+		added       []string
+		removed     []string
+	)
+	for i, update := range req.Updates {
+		field := fmt.Sprintf("updates[%d]", i)
+		member, err := organizationMemberByUser(ctx, api.Database, organization.ID, update.User)
+		if errors.Is(err, sql.ErrNoRows) {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field,
+				Detail: fmt.Sprintf("%q is not a member of the organization", update.User),
+			})
+			continue
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		if _, ok := seen[member.UserID]; ok {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field,
+				Detail: fmt.Sprintf("%q is updated more than once", update.User),
+			})
+			continue
+		}
+		seen[member.UserID] = struct{}{}
+		if apiKey.UserID == member.UserID {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field,
+				Detail: "You cannot change your own organization roles.",
+			})
+			continue
+		}
+		err = validateOrganizationRoles(organization.ID, update.Roles)
+		if err != nil {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field,
+				Detail: err.Error(),
+			})
+			continue
+		}
+
+		// The org-member role is always implied.
+		impliedTypes := append(update.Roles, rbac.RoleOrgMember(organization.ID))
+		memberAdded, memberRemoved := rbac.ChangeRoleSet(member.Roles, impliedTypes)
+		added = append(added, memberAdded...)
+		removed = append(removed, memberRemoved...)
+		updates = append(updates, database.UpdateMemberRolesParams{
+			GrantedRoles: update.Roles,
+			UserID:       member.UserID,
+			OrgID:        organization.ID,
+		})
+	}
+
+	// Assigning a role requires the create permission.
+	if len(added) > 0 && !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	// Removing a role requires the delete permission.
+	if len(removed) > 0 && !api.Authorize(r, rbac.ActionDelete, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	// Just treat adding & removing as "assigning" for now.
+	for _, roleName := range append(added, removed...) {
+		if !rbac.CanAssignRole(actorRoles.Roles, roleName) {
+			httpapi.Forbidden(rw)
+			return
+		}
+	}
+
+	if len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid role updates. No roles were changed.",
+			Validations: validations,
+		})
+		return
+	}
+
+	members := make([]codersdk.OrganizationMember, 0, len(updates))
+	err := api.Database.InTx(func(tx database.Store) error {
+		for _, update := range updates {
+			member, err := tx.UpdateMemberRoles(ctx, update)
+			if err != nil {
+				return xerrors.Errorf("update roles of %q: %w", update.UserID, err)
+			}
+			members = append(members, convertOrganizationMember(member))
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	for _, member := range members {
+		api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionUpdated, member.OrganizationID, member.UserID)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, members)
+}
+
+// organizationMemberByUser returns the membership of the user in the
+// organization. The user is either an ID or a username.
+func organizationMemberByUser(ctx context.Context, db database.Store, organizationID uuid.UUID, userQuery string) (database.OrganizationMember, error) {
+	var (
+		user database.User
+		err  error
+	)
+	if userID, parseErr := uuid.Parse(userQuery); parseErr == nil {
+		user, err = db.GetUserByID(ctx, userID)
+	} else {
+		user, err = db.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+			Username: userQuery,
+		})
+	}
+	if err != nil {
+		return database.OrganizationMember{}, err
+	}
+	return db.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: organizationID,
+		UserID:         user.ID,
+	})
+}
+
+// validateOrganizationRoles returns an error unless all the roles are
+// organization roles of the organization.
+func validateOrganizationRoles(organizationID uuid.UUID, roles []string) error {
 	// Enforce only site wide roles
-	for _, r := range args.GrantedRoles {
+	for _, r := range roles {
 		// Must be an org role for the org in the args
 		orgID, ok := rbac.IsOrgRole(r)
 		if !ok {
-			return database.OrganizationMember{}, xerrors.Errorf("must only update organization roles")
+			return xerrors.Errorf("must only update organization roles")
 		}
 
 		roleOrg, err := uuid.Parse(orgID)
 		if err != nil {
-			return database.OrganizationMember{}, xerrors.Errorf("Role must have proper UUIDs for organization, %q does not", r)
+			return xerrors.Errorf("Role must have proper UUIDs for organization, %q does not", r)
 		}
 
-		if roleOrg != args.OrgID {
-			return database.OrganizationMember{}, xerrors.Errorf("Must only pass roles for org %q", args.OrgID.String())
+		if roleOrg != organizationID {
+			return xerrors.Errorf("Must only pass roles for org %q", organizationID.String())
 		}
 
 		if _, err := rbac.RoleByName(r); err != nil {
-			return database.OrganizationMember{}, xerrors.Errorf("%q is not a supported role", r)
+			return xerrors.Errorf("%q is not a supported role", r)
 		}
 	}
+	return nil
+}
+
+func (api *API) updateOrganizationMemberRoles(ctx context.Context, args database.UpdateMemberRolesParams) (database.OrganizationMember, error) {
+	err := validateOrganizationRoles(args.OrgID, args.GrantedRoles)
+	if err != nil {
+		return database.OrganizationMember{}, err
+	}
 
 	updatedUser, err := api.Database.UpdateMemberRoles(ctx, args)
 	if err != nil {
@@ -122,3 +368,74 @@ func convertOrganizationMember(mem database.OrganizationMember) codersdk.Organiz
 	}
 	return convertedMember
 }
+
+func convertOrganizationMemberWithUser(mem database.GetOrganizationMembersRow, groups []codersdk.OrganizationMemberGroup) codersdk.OrganizationMemberWithUser {
+	if groups == nil {
+		groups = []codersdk.OrganizationMemberGroup{}
+	}
+	convertedMember := codersdk.OrganizationMemberWithUser{
+		UserID:         mem.UserID,
+		OrganizationID: mem.OrganizationID,
+		Username:       mem.Username,
+		Email:          mem.Email,
+		AvatarURL:      mem.AvatarURL.String,
+		CreatedAt:      mem.CreatedAt,
+		UpdatedAt:      mem.UpdatedAt,
+		Roles:          make([]codersdk.Role, 0, len(mem.Roles)),
+		Groups:         groups,
+	}
+
+	for _, roleName := range mem.Roles {
+		rbacRole, _ := rbac.RoleByName(roleName)
+		convertedMember.Roles = append(convertedMember.Roles, convertRole(rbacRole))
+	}
+	return convertedMember
+}
+
+// organizationMemberSearchQuery parses the "q" query parameter of the
+// organization members endpoint. Supported filters are a free text search
+// on username and email, "role:<name>" and "group:<uuid>". Organization
+// role names may omit the organization ID suffix.
+func organizationMemberSearchQuery(query string, organizationID uuid.UUID) (database.GetOrganizationMembersParams, []codersdk.ValidationError) {
+	searchParams := make(url.Values)
+	if query == "" {
+		// No filter
+		return database.GetOrganizationMembersParams{}, nil
+	}
+	query = strings.ToLower(query)
+	elements := splitQueryParameterByDelimiter(query, ' ', true)
+	for _, element := range elements {
+		parts := splitQueryParameterByDelimiter(element, ':', false)
+		switch len(parts) {
+		case 1:
+			// No key:value pair.
+			searchParams.Set("search", parts[0])
+		case 2:
+			searchParams.Add(parts[0], parts[1])
+		default:
+			return database.GetOrganizationMembersParams{}, []codersdk.ValidationError{
+				{Field: "q", Detail: fmt.Sprintf("Query element %q can only contain 1 ':'", element)},
+			}
+		}
+	}
+
+	parser := httpapi.NewQueryParamParser()
+	filter := database.GetOrganizationMembersParams{
+		Search:  parser.String(searchParams, "", "search"),
+		GroupID: parser.UUID(searchParams, uuid.Nil, "group"),
+	}
+	for _, role := range searchParams["role"] {
+		if _, ok := rbac.IsOrgRole(role); !ok {
+			role = fmt.Sprintf("%s:%s", role, organizationID.String())
+		}
+		// Every member implicitly has the member role, so filtering on it
+		// is the same as not filtering at all.
+		if role == rbac.RoleOrgMember(organizationID) {
+			filter.Roles = nil
+			break
+		}
+		filter.Roles = append(filter.Roles, role)
+	}
+
+	return filter, parser.Errors
+}
@@ -0,0 +1,180 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationMembers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		_, other := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		res, err := client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{})
+		require.NoError(t, err)
+		require.Empty(t, res.NextCursor)
+		members := res.Members
+		require.Len(t, members, 2)
+		require.Equal(t, first.UserID, members[0].UserID)
+		require.Equal(t, other.ID, members[1].UserID)
+		require.Equal(t, other.Username, members[1].Username)
+		require.Equal(t, other.Email, members[1].Email)
+		require.NotNil(t, members[1].Groups)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		for i := 0; i < 3; i++ {
+			coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		all, err := client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{})
+		require.NoError(t, err)
+		require.Len(t, all.Members, 4)
+
+		page, err := client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{
+			CursorPagination: codersdk.CursorPagination{Limit: 3},
+		})
+		require.NoError(t, err)
+		require.Equal(t, all.Members[:3], page.Members)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{
+			CursorPagination: codersdk.CursorPagination{Cursor: page.NextCursor, Limit: 3},
+		})
+		require.NoError(t, err)
+		require.Equal(t, all.Members[3:], page.Members)
+		require.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		_, other := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		res, err := client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{
+			Search: other.Username,
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Members, 1)
+		require.Equal(t, other.ID, res.Members[0].UserID)
+
+		res, err = client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{
+			Role: "organization-admin",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Members, 1)
+		require.Equal(t, first.UserID, res.Members[0].UserID)
+
+		res, err = client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{
+			Role: rbac.RoleOrgMember(first.OrganizationID),
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Members, 2)
+	})
+}
+
+func TestUpdateOrganizationMembersRoles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		_, one := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+		_, two := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		members, err := client.UpdateOrganizationMembersRoles(ctx, first.OrganizationID, codersdk.UpdateOrganizationMembersRolesRequest{
+			Updates: []codersdk.OrganizationMemberRolesUpdate{
+				{User: one.ID.String(), Roles: []string{rbac.RoleOrgAdmin(first.OrganizationID)}},
+				{User: two.Username, Roles: []string{rbac.RoleOrgAuditor(first.OrganizationID)}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+		require.Equal(t, one.ID, members[0].UserID)
+		require.Equal(t, rbac.RoleOrgAdmin(first.OrganizationID), members[0].Roles[0].Name)
+		require.Equal(t, two.ID, members[1].UserID)
+		require.Equal(t, rbac.RoleOrgAuditor(first.OrganizationID), members[1].Roles[0].Name)
+	})
+
+	t.Run("AllOrNothing", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		_, one := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateOrganizationMembersRoles(ctx, first.OrganizationID, codersdk.UpdateOrganizationMembersRolesRequest{
+			Updates: []codersdk.OrganizationMemberRolesUpdate{
+				{User: one.Username, Roles: []string{rbac.RoleOrgAdmin(first.OrganizationID)}},
+				{User: "nobody", Roles: []string{}},
+				{User: one.Username, Roles: []string{}},
+				{User: first.UserID.String(), Roles: []string{}},
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 3)
+		require.Equal(t, "updates[1]", apiErr.Validations[0].Field)
+		require.Equal(t, "updates[2]", apiErr.Validations[1].Field)
+		require.Equal(t, "updates[3]", apiErr.Validations[2].Field)
+
+		res, err := client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{
+			Search: one.Username,
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Members, 1)
+		require.Len(t, res.Members[0].Roles, 0)
+	})
+
+	t.Run("MemberCannotAssign", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+		_, other := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.UpdateOrganizationMembersRoles(ctx, first.OrganizationID, codersdk.UpdateOrganizationMembersRolesRequest{
+			Updates: []codersdk.OrganizationMemberRolesUpdate{
+				{User: other.Username, Roles: []string{rbac.RoleOrgAdmin(first.OrganizationID)}},
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
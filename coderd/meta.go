@@ -0,0 +1,59 @@
+package coderd
+
+import (
+	"net/http"
+
+	"github.com/coder/coder/buildinfo"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// apiMeta advertises the API version, capabilities and deprecated routes of
+// this deployment so clients can adapt across server versions.
+func (api *API) apiMeta(rw http.ResponseWriter, r *http.Request) {
+	capabilities := make([]codersdk.Capability, len(api.Capabilities))
+	copy(capabilities, api.Capabilities)
+	deprecations := make([]codersdk.APIDeprecation, len(api.deprecations))
+	copy(deprecations, api.deprecations)
+
+	httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.APIMeta{
+		APIVersion:   codersdk.APIVersion,
+		Version:      buildinfo.Version(),
+		Capabilities: capabilities,
+		Experimental: api.Experimental,
+		Deprecations: deprecations,
+	})
+}
+
+// deprecate marks a route as deprecated. Responses carry the deprecation
+// headers, and the route is listed in the API meta endpoint.
+func (api *API) deprecate(method, path string, d httpmw.Deprecation) func(http.Handler) http.Handler {
+	deprecation := codersdk.APIDeprecation{
+		Method:    method,
+		Path:      path,
+		Successor: d.Successor,
+	}
+	if !d.Since.IsZero() {
+		since := d.Since
+		deprecation.Since = &since
+	}
+	if !d.Sunset.IsZero() {
+		sunset := d.Sunset
+		deprecation.Sunset = &sunset
+	}
+	api.deprecations = append(api.deprecations, deprecation)
+	return httpmw.Deprecated(d)
+}
+
+// deprecationReport returns how often deprecated routes were used, so
+// admins can tell whether removing them breaks clients.
+func (api *API) deprecationReport(rw http.ResponseWriter, r *http.Request) {
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentFlags) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	httpapi.Write(r.Context(), rw, http.StatusOK, api.deprecationUsage.Report())
+}
@@ -0,0 +1,734 @@
+package coderd
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+const (
+	// oauth2ProviderCodeLifetime is how long an app has to exchange an
+	// authorization code for a token.
+	oauth2ProviderCodeLifetime = 10 * time.Minute
+	// oauth2ProviderTokenLifetime is how long the tokens issued to apps last.
+	oauth2ProviderTokenLifetime = 7 * 24 * time.Hour
+)
+
+// The PKCE code challenge methods defined by RFC 7636.
+const (
+	oauth2PKCEMethodPlain = "plain"
+	oauth2PKCEMethodS256  = "S256"
+)
+
+// oauth2PKCEChallenge matches code challenges, which have the same format as
+// code verifiers.
+var oauth2PKCEChallenge = regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+
+func (api *API) oauth2ProviderApps(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOAuth2ProviderApp) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	apps, err := api.Database.GetOAuth2ProviderApps(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.OAuth2ProviderApp, 0, len(apps))
+	for _, app := range apps {
+		resp = append(resp, convertOAuth2ProviderApp(app))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) oauth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := api.oauth2ProviderAppParam(rw, r, rbac.ActionRead)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOAuth2ProviderApp(app))
+}
+
+func (api *API) postOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOAuth2ProviderApp) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateOAuth2ProviderAppRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !validOAuth2CallbackURL(ctx, rw, req.CallbackURL) {
+		return
+	}
+
+	secret, hashedSecret, err := generateOAuth2Secret()
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	app, err := api.Database.InsertOAuth2ProviderApp(ctx, database.InsertOAuth2ProviderAppParams{
+		ID:           uuid.New(),
+		CreatedAt:    database.Now(),
+		Name:         req.Name,
+		Icon:         req.Icon,
+		CallbackURL:  req.CallbackURL,
+		HashedSecret: hashedSecret,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("OAuth2 app %q already exists.", req.Name),
+			Validations: []codersdk.ValidationError{
+				{Field: "name", Detail: "this value is already in use and should be unique"},
+			},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := convertOAuth2ProviderApp(app)
+	// Only the hash of the secret is stored, so this is the only time it's
+	// returned.
+	resp.ClientSecret = secret
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+func (api *API) putOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := api.oauth2ProviderAppParam(rw, r, rbac.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var req codersdk.UpdateOAuth2ProviderAppRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !validOAuth2CallbackURL(ctx, rw, req.CallbackURL) {
+		return
+	}
+
+	app, err := api.Database.UpdateOAuth2ProviderAppByID(ctx, database.UpdateOAuth2ProviderAppByIDParams{
+		ID:          app.ID,
+		UpdatedAt:   database.Now(),
+		Name:        req.Name,
+		Icon:        req.Icon,
+		CallbackURL: req.CallbackURL,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("OAuth2 app %q already exists.", req.Name),
+			Validations: []codersdk.ValidationError{
+				{Field: "name", Detail: "this value is already in use and should be unique"},
+			},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOAuth2ProviderApp(app))
+}
+
+// deleteOAuth2ProviderApp deletes the app and revokes every token that was
+// issued to it.
+func (api *API) deleteOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := api.oauth2ProviderAppParam(rw, r, rbac.ActionDelete)
+	if !ok {
+		return
+	}
+
+	err := api.Database.InTx(func(tx database.Store) error {
+		err := tx.DeleteOAuth2ProviderAppAPIKeysByAppID(ctx, app.ID)
+		if err != nil {
+			return err
+		}
+		return tx.DeleteOAuth2ProviderAppByID(ctx, app.ID)
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully deleted OAuth2 app!",
+	})
+}
+
+func (api *API) postOAuth2ProviderAppSecret(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := api.oauth2ProviderAppParam(rw, r, rbac.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	secret, hashedSecret, err := generateOAuth2Secret()
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	app, err = api.Database.UpdateOAuth2ProviderAppSecretByID(ctx, database.UpdateOAuth2ProviderAppSecretByIDParams{
+		ID:           app.ID,
+		UpdatedAt:    database.Now(),
+		HashedSecret: hashedSecret,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := convertOAuth2ProviderApp(app)
+	resp.ClientSecret = secret
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// oauth2Authorization returns what the consent screen asks the user to
+// allow. Any user can sign in to an app, so reading the app isn't authorized
+// like the admin endpoints.
+func (api *API) oauth2Authorization(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params, ok := api.oauth2AuthorizeParams(rw, r)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OAuth2Authorization{
+		App:         convertOAuth2ProviderApp(params.app),
+		Scopes:      params.scopes,
+		RedirectURI: params.redirectURI.String(),
+	})
+}
+
+// postOAuth2Authorize is called when the user allows the app. It issues an
+// authorization code the app exchanges for a token.
+func (api *API) postOAuth2Authorize(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+	params, ok := api.oauth2AuthorizeParams(rw, r)
+	if !ok {
+		return
+	}
+	// Apps can't be given more than the session that authorizes them.
+	if !apiKeyScopeCovers(apiKey, params.key) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "The requested scope must not exceed the scope of your session.",
+		})
+		return
+	}
+
+	code, hashedCode, err := generateOAuth2Secret()
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	now := database.Now()
+	_, err = api.Database.InsertOAuth2ProviderAppCode(ctx, database.InsertOAuth2ProviderAppCodeParams{
+		ID:           uuid.New(),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(oauth2ProviderCodeLifetime),
+		HashedSecret: hashedCode,
+		AppID:        params.app.ID,
+		UserID:       apiKey.UserID,
+		// Only the redirect URI the app sent is kept, since the token
+		// request must repeat it.
+		RedirectURI:         params.requestedRedirectURI,
+		Scope:               strings.Join(params.scopes, " "),
+		CodeChallenge:       params.codeChallenge,
+		CodeChallengeMethod: params.codeChallengeMethod,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	redirectURI := *params.redirectURI
+	query := redirectURI.Query()
+	query.Set("code", code)
+	if params.state != "" {
+		query.Set("state", params.state)
+	}
+	redirectURI.RawQuery = query.Encode()
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OAuth2AuthorizeResponse{
+		RedirectURI: redirectURI.String(),
+	})
+}
+
+// postOAuth2Token exchanges an authorization code for an API key of the user
+// that authorized the app. Errors are formatted as defined by RFC 6749, so
+// standard OAuth2 clients can be used. The redirect URI and PKCE code
+// verifier must match the authorization request.
+func (api *API) postOAuth2Token(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	app, ok := api.oauth2Client(rw, r)
+	if !ok {
+		return
+	}
+	if grantType := r.PostForm.Get("grant_type"); grantType != "authorization_code" {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("Grant type %q is not supported.", grantType))
+		return
+	}
+
+	hashedCode := sha256.Sum256([]byte(r.PostForm.Get("code")))
+	code, err := api.Database.DeleteOAuth2ProviderAppCodeByHashedSecret(ctx, hashedCode[:])
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if err != nil || code.AppID != app.ID || code.ExpiresAt.Before(database.Now()) ||
+		!oauth2RedirectMatches(app, code, r.PostForm.Get("redirect_uri")) ||
+		!oauth2VerifierMatches(code, r.PostForm.Get("code_verifier")) {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_grant", "The authorization code is invalid or expired.")
+		return
+	}
+
+	params, _, err := oauth2ScopeParams(code.Scope)
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	params.UserID = code.UserID
+	params.LoginType = database.LoginTypeToken
+	params.RemoteAddr = r.RemoteAddr
+	params.ExpiresAt = database.Now().Add(oauth2ProviderTokenLifetime)
+	params.LifetimeSeconds = int64(oauth2ProviderTokenLifetime.Seconds())
+	cookie, err := api.createAPIKey(ctx, params)
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	keyID, _, err := httpmw.SplitAPIToken(cookie.Value)
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	_, err = api.Database.InsertOAuth2ProviderAppToken(ctx, database.InsertOAuth2ProviderAppTokenParams{
+		APIKeyID:  keyID,
+		AppID:     app.ID,
+		CreatedAt: database.Now(),
+	})
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OAuth2TokenResponse{
+		AccessToken: cookie.Value,
+		TokenType:   "Bearer",
+		ExpiresIn:   params.LifetimeSeconds,
+		Scope:       code.Scope,
+	})
+}
+
+// postOAuth2Introspect describes a token to an app, as defined by RFC 7662.
+// Only the tokens issued to the app are active, so apps can't learn about
+// the sessions of users or the tokens of other apps.
+func (api *API) postOAuth2Introspect(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	app, ok := api.oauth2Client(rw, r)
+	if !ok {
+		return
+	}
+
+	inactive := codersdk.OAuth2Introspection{Active: false}
+	keyID, keySecret, err := httpmw.SplitAPIToken(r.PostForm.Get("token"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusOK, inactive)
+		return
+	}
+	key, err := api.Database.GetAPIKeyByID(ctx, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusOK, inactive)
+		return
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	hashedSecret := sha256.Sum256([]byte(keySecret))
+	if subtle.ConstantTimeCompare(hashedSecret[:], key.HashedSecret) != 1 || key.ExpiresAt.Before(database.Now()) {
+		httpapi.Write(ctx, rw, http.StatusOK, inactive)
+		return
+	}
+	token, err := api.Database.GetOAuth2ProviderAppTokenByAPIKeyID(ctx, key.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && token.AppID != app.ID) {
+		httpapi.Write(ctx, rw, http.StatusOK, inactive)
+		return
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	user, err := api.Database.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	if user.Status != database.UserStatusActive {
+		httpapi.Write(ctx, rw, http.StatusOK, inactive)
+		return
+	}
+
+	resp := codersdk.OAuth2Introspection{
+		Active:    true,
+		Scope:     string(key.Scope),
+		Username:  user.Username,
+		TokenType: "Bearer",
+		ExpiresAt: key.ExpiresAt.Unix(),
+		IssuedAt:  key.CreatedAt.Unix(),
+		Subject:   user.ID.String(),
+		ClientID:  app.ID.String(),
+	}
+	if key.Scope == database.APIKeyScopeRestricted {
+		resp.Scope = strings.Join(key.ScopePermissions, " ")
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// oauth2UserInfo returns the user of the token, so apps can sign the user in.
+func (api *API) oauth2UserInfo(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+
+	user, err := api.Database.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OAuth2UserInfo{
+		Subject:           user.ID.String(),
+		PreferredUsername: user.Username,
+		Email:             user.Email,
+		Picture:           user.AvatarURL.String,
+	})
+}
+
+type oauth2AuthorizeParams struct {
+	app         database.OAuth2ProviderApp
+	redirectURI *url.URL
+	// requestedRedirectURI is the redirect URI the app sent, or empty if it
+	// relies on its callback URL.
+	requestedRedirectURI string
+	scopes               []string
+	state                string
+	codeChallenge        string
+	codeChallengeMethod  string
+	// key is the scope of the API key the app will be issued.
+	key createAPIKeyParams
+}
+
+// oauth2AuthorizeParams validates the query parameters of an authorization
+// request. It writes an error response if it returns false.
+func (api *API) oauth2AuthorizeParams(rw http.ResponseWriter, r *http.Request) (oauth2AuthorizeParams, bool) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	if responseType := query.Get("response_type"); responseType != "code" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Response type %q is not supported.", responseType),
+			Validations: []codersdk.ValidationError{
+				{Field: "response_type", Detail: `must be "code"`},
+			},
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+
+	clientID, err := uuid.Parse(query.Get("client_id"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid client ID.",
+			Detail:  err.Error(),
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+	app, err := api.Database.GetOAuth2ProviderAppByID(ctx, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("OAuth2 app %q does not exist.", clientID),
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return oauth2AuthorizeParams{}, false
+	}
+
+	callbackURL, err := url.Parse(app.CallbackURL)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return oauth2AuthorizeParams{}, false
+	}
+	redirectURI := callbackURL
+	if raw := query.Get("redirect_uri"); raw != "" {
+		redirectURI, err = url.Parse(raw)
+		if err != nil || !oauth2RedirectAllowed(callbackURL, redirectURI) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "The redirect URI must be the callback URL of the app or below it.",
+				Validations: []codersdk.ValidationError{
+					{Field: "redirect_uri", Detail: fmt.Sprintf("%q is not allowed", raw)},
+				},
+			})
+			return oauth2AuthorizeParams{}, false
+		}
+	}
+
+	key, scopes, err := oauth2ScopeParams(query.Get("scope"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid scope.",
+			Validations: []codersdk.ValidationError{
+				{Field: "scope", Detail: err.Error()},
+			},
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+
+	codeChallenge := query.Get("code_challenge")
+	codeChallengeMethod := query.Get("code_challenge_method")
+	if codeChallenge != "" && codeChallengeMethod == "" {
+		codeChallengeMethod = oauth2PKCEMethodPlain
+	}
+	if codeChallengeMethod != "" && codeChallengeMethod != oauth2PKCEMethodPlain && codeChallengeMethod != oauth2PKCEMethodS256 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Code challenge method %q is not supported.", codeChallengeMethod),
+			Validations: []codersdk.ValidationError{
+				{Field: "code_challenge_method", Detail: fmt.Sprintf("must be %q or %q", oauth2PKCEMethodS256, oauth2PKCEMethodPlain)},
+			},
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+	if codeChallengeMethod != "" && !oauth2PKCEChallenge.MatchString(codeChallenge) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid code challenge.",
+			Validations: []codersdk.ValidationError{
+				{Field: "code_challenge", Detail: "must be 43 to 128 unreserved characters"},
+			},
+		})
+		return oauth2AuthorizeParams{}, false
+	}
+
+	return oauth2AuthorizeParams{
+		app:                  app,
+		redirectURI:          redirectURI,
+		requestedRedirectURI: query.Get("redirect_uri"),
+		scopes:               scopes,
+		state:                query.Get("state"),
+		codeChallenge:        codeChallenge,
+		codeChallengeMethod:  codeChallengeMethod,
+		key:                  key,
+	}, true
+}
+
+// oauth2ScopeParams returns the scope of the API key issued for the space
+// separated scope of an authorization request, along with the scopes it's
+// made of.
+func oauth2ScopeParams(scope string) (createAPIKeyParams, []string, error) {
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = []string{string(codersdk.APIKeyScopeAll)}
+	}
+	if len(scopes) == 1 {
+		switch codersdk.APIKeyScope(scopes[0]) {
+		case codersdk.APIKeyScopeAll:
+			return createAPIKeyParams{Scope: database.APIKeyScopeAll}, scopes, nil
+		case codersdk.APIKeyScopeApplicationConnect:
+			return createAPIKeyParams{Scope: database.APIKeyScopeApplicationConnect}, scopes, nil
+		}
+	}
+	for _, s := range scopes {
+		if s == string(codersdk.APIKeyScopeAll) || s == string(codersdk.APIKeyScopeApplicationConnect) {
+			return createAPIKeyParams{}, nil, xerrors.Errorf("%q can't be combined with other scopes", s)
+		}
+	}
+	_, err := rbac.RestrictedScope(uuid.NullUUID{}, scopes)
+	if err != nil {
+		return createAPIKeyParams{}, nil, err
+	}
+	return createAPIKeyParams{
+		Scope:            database.APIKeyScopeRestricted,
+		ScopePermissions: scopes,
+	}, scopes, nil
+}
+
+// oauth2RedirectAllowed returns whether the redirect URI is the callback URL
+// of the app or a path below it.
+func oauth2RedirectAllowed(callbackURL, redirectURI *url.URL) bool {
+	if redirectURI.Scheme != callbackURL.Scheme || redirectURI.Host != callbackURL.Host ||
+		redirectURI.User != nil || redirectURI.Fragment != "" || strings.Contains(redirectURI.Path, "..") {
+		return false
+	}
+	if redirectURI.Path == callbackURL.Path {
+		return true
+	}
+	return strings.HasPrefix(redirectURI.Path, strings.TrimSuffix(callbackURL.Path, "/")+"/")
+}
+
+// oauth2RedirectMatches returns whether the redirect URI of a token request
+// matches the authorization request. It must be repeated when the
+// authorization request included one, as required by RFC 6749.
+func oauth2RedirectMatches(app database.OAuth2ProviderApp, code database.OAuth2ProviderAppCode, redirectURI string) bool {
+	if code.RedirectURI != "" {
+		return redirectURI == code.RedirectURI
+	}
+	return redirectURI == "" || redirectURI == app.CallbackURL
+}
+
+// oauth2VerifierMatches checks the PKCE code verifier of a token request
+// against the challenge of the authorization request, as defined by RFC 7636.
+// A verifier without a challenge is rejected too, since the challenge was
+// stripped from the authorization request.
+func oauth2VerifierMatches(code database.OAuth2ProviderAppCode, verifier string) bool {
+	challenge := verifier
+	switch code.CodeChallengeMethod {
+	case "":
+		return verifier == ""
+	case oauth2PKCEMethodS256:
+		sum := sha256.Sum256([]byte(verifier))
+		challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return verifier != "" && subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) == 1
+}
+
+// oauth2Client authenticates the app calling the token or introspection
+// endpoint with its client ID and secret, sent with HTTP basic auth or as
+// form parameters. It writes an error response if it returns false.
+func (api *API) oauth2Client(rw http.ResponseWriter, r *http.Request) (database.OAuth2ProviderApp, bool) {
+	ctx := r.Context()
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusUnauthorized, "invalid_client", "Client authentication failed.")
+		return database.OAuth2ProviderApp{}, false
+	}
+	app, err := api.Database.GetOAuth2ProviderAppByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeOAuth2Error(ctx, rw, http.StatusUnauthorized, "invalid_client", "Client authentication failed.")
+		return database.OAuth2ProviderApp{}, false
+	}
+	if err != nil {
+		writeOAuth2Error(ctx, rw, http.StatusInternalServerError, "server_error", err.Error())
+		return database.OAuth2ProviderApp{}, false
+	}
+	hashedSecret := sha256.Sum256([]byte(clientSecret))
+	if subtle.ConstantTimeCompare(hashedSecret[:], app.HashedSecret) != 1 {
+		writeOAuth2Error(ctx, rw, http.StatusUnauthorized, "invalid_client", "Client authentication failed.")
+		return database.OAuth2ProviderApp{}, false
+	}
+	return app, true
+}
+
+// oauth2ProviderAppParam authorizes the action and returns the app in the
+// URL. It writes an error response if it returns false.
+func (api *API) oauth2ProviderAppParam(rw http.ResponseWriter, r *http.Request, action rbac.Action) (database.OAuth2ProviderApp, bool) {
+	ctx := r.Context()
+	if !api.Authorize(r, action, rbac.ResourceOAuth2ProviderApp) {
+		httpapi.ResourceNotFound(rw)
+		return database.OAuth2ProviderApp{}, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "app"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid OAuth2 app ID.",
+			Detail:  err.Error(),
+		})
+		return database.OAuth2ProviderApp{}, false
+	}
+
+	app, err := api.Database.GetOAuth2ProviderAppByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return database.OAuth2ProviderApp{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return database.OAuth2ProviderApp{}, false
+	}
+	return app, true
+}
+
+// validOAuth2CallbackURL writes an error response if the callback URL isn't
+// an absolute http or https URL.
+func validOAuth2CallbackURL(ctx context.Context, rw http.ResponseWriter, callbackURL string) bool {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Callback URL must be an absolute http or https URL.",
+			Validations: []codersdk.ValidationError{
+				{Field: "callback_url", Detail: fmt.Sprintf("invalid callback URL %q", callbackURL)},
+			},
+		})
+		return false
+	}
+	return true
+}
+
+// generateOAuth2Secret returns a random client secret or authorization code
+// along with the hash that's stored.
+func generateOAuth2Secret() (string, []byte, error) {
+	secret, err := cryptorand.String(40)
+	if err != nil {
+		return "", nil, err
+	}
+	hashed := sha256.Sum256([]byte(secret))
+	return secret, hashed[:], nil
+}
+
+func writeOAuth2Error(ctx context.Context, rw http.ResponseWriter, status int, code, description string) {
+	httpapi.Write(ctx, rw, status, codersdk.OAuth2Error{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+func convertOAuth2ProviderApp(app database.OAuth2ProviderApp) codersdk.OAuth2ProviderApp {
+	return codersdk.OAuth2ProviderApp{
+		ID:          app.ID,
+		Name:        app.Name,
+		Icon:        app.Icon,
+		CallbackURL: app.CallbackURL,
+		CreatedAt:   app.CreatedAt,
+		UpdatedAt:   app.UpdatedAt,
+	}
+}
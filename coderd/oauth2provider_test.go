@@ -0,0 +1,364 @@
+package coderd_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOAuth2ProviderApps(t *testing.T) {
+	t.Parallel()
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		app, err := client.CreateOAuth2ProviderApp(ctx, codersdk.CreateOAuth2ProviderAppRequest{
+			Name:        "internal-tool",
+			CallbackURL: "http://localhost:3000/callback",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, app.ClientSecret)
+
+		_, err = client.CreateOAuth2ProviderApp(ctx, codersdk.CreateOAuth2ProviderAppRequest{
+			Name:        "internal-tool",
+			CallbackURL: "http://localhost:3001/callback",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		updated, err := client.UpdateOAuth2ProviderApp(ctx, app.ID, codersdk.UpdateOAuth2ProviderAppRequest{
+			Name:        "dashboard",
+			CallbackURL: "https://dashboard.example.com/callback",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "dashboard", updated.Name)
+		// The secret is never returned again.
+		require.Empty(t, updated.ClientSecret)
+
+		fetched, err := client.OAuth2ProviderApp(ctx, app.ID)
+		require.NoError(t, err)
+		require.Equal(t, updated.CallbackURL, fetched.CallbackURL)
+
+		regenerated, err := client.RegenerateOAuth2ProviderAppSecret(ctx, app.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, regenerated.ClientSecret)
+		require.NotEqual(t, app.ClientSecret, regenerated.ClientSecret)
+
+		apps, err := client.OAuth2ProviderApps(ctx)
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+
+		err = client.DeleteOAuth2ProviderApp(ctx, app.ID)
+		require.NoError(t, err)
+		apps, err = client.OAuth2ProviderApps(ctx)
+		require.NoError(t, err)
+		require.Len(t, apps, 0)
+	})
+
+	t.Run("InvalidCallbackURL", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateOAuth2ProviderApp(ctx, codersdk.CreateOAuth2ProviderAppRequest{
+			Name:        "internal-tool",
+			CallbackURL: "ftp://localhost/callback",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.OAuth2ProviderApps(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestOAuth2Provider(t *testing.T) {
+	t.Parallel()
+	t.Run("SignIn", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		app, config := createOAuth2ProviderApp(t, client)
+
+		ctx, _ := testutil.Context(t)
+		req := codersdk.OAuth2AuthorizeRequest{
+			ClientID:    app.ID,
+			RedirectURI: "http://localhost:3000/callback/done",
+			State:       "some-state",
+		}
+		authorization, err := member.OAuth2Authorization(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, app.ID, authorization.App.ID)
+		require.Equal(t, []string{"all"}, authorization.Scopes)
+
+		code := authorizeOAuth2(ctx, t, member, req)
+		token, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("redirect_uri", req.RedirectURI))
+		require.NoError(t, err)
+		require.Equal(t, "Bearer", token.TokenType)
+
+		// Codes can only be used once.
+		_, err = config.Exchange(ctx, code)
+		require.Error(t, err)
+
+		// The token is sent as a bearer token by OAuth2 clients.
+		me, err := member.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		info := oauth2UserInfo(ctx, t, client, config.Client(ctx, token))
+		require.Equal(t, me.ID.String(), info.Subject)
+		require.Equal(t, me.Username, info.PreferredUsername)
+		require.Equal(t, me.Email, info.Email)
+
+		introspection := introspectOAuth2(ctx, t, client, app, token.AccessToken)
+		require.True(t, introspection.Active)
+		require.Equal(t, app.ID.String(), introspection.ClientID)
+		require.Equal(t, me.Username, introspection.Username)
+		require.Equal(t, "all", introspection.Scope)
+
+		// Deleting the app revokes its tokens.
+		err = client.DeleteOAuth2ProviderApp(ctx, app.ID)
+		require.NoError(t, err)
+		res, err := config.Client(ctx, token).Get(client.URL.String() + "/api/v2/oauth2/userinfo")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("RestrictedScope", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		app, config := createOAuth2ProviderApp(t, client)
+
+		ctx, _ := testutil.Context(t)
+		code := authorizeOAuth2(ctx, t, client, codersdk.OAuth2AuthorizeRequest{
+			ClientID: app.ID,
+			Scope:    "workspace:read template:read",
+		})
+		token, err := config.Exchange(ctx, code)
+		require.NoError(t, err)
+
+		introspection := introspectOAuth2(ctx, t, client, app, token.AccessToken)
+		require.True(t, introspection.Active)
+		require.Equal(t, "workspace:read template:read", introspection.Scope)
+
+		for _, scope := range []string{"all workspace:read", "workspace:fly"} {
+			_, err = client.OAuth2Authorize(ctx, codersdk.OAuth2AuthorizeRequest{
+				ClientID: app.ID,
+				Scope:    scope,
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+
+	t.Run("InvalidRedirectURI", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		app, _ := createOAuth2ProviderApp(t, client)
+
+		for _, redirectURI := range []string{
+			"http://evil.example.com/callback",
+			"https://localhost:3000/callback",
+			"http://localhost:3000/callbackfoo",
+			"http://localhost:3000/callback/../other",
+		} {
+			ctx, _ := testutil.Context(t)
+			_, err := client.OAuth2Authorize(ctx, codersdk.OAuth2AuthorizeRequest{
+				ClientID:    app.ID,
+				RedirectURI: redirectURI,
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), redirectURI)
+		}
+	})
+
+	t.Run("IntrospectOtherApp", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		app, config := createOAuth2ProviderApp(t, client)
+
+		ctx, _ := testutil.Context(t)
+		other, err := client.CreateOAuth2ProviderApp(ctx, codersdk.CreateOAuth2ProviderAppRequest{
+			Name:        "other-tool",
+			CallbackURL: "http://localhost:3001/callback",
+		})
+		require.NoError(t, err)
+		code := authorizeOAuth2(ctx, t, client, codersdk.OAuth2AuthorizeRequest{ClientID: app.ID})
+		token, err := config.Exchange(ctx, code)
+		require.NoError(t, err)
+
+		// Apps can't learn about the tokens of other apps or the sessions of
+		// users.
+		require.False(t, introspectOAuth2(ctx, t, client, other, token.AccessToken).Active)
+		require.False(t, introspectOAuth2(ctx, t, client, app, client.SessionToken).Active)
+	})
+
+	t.Run("RedirectURIRequired", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		app, config := createOAuth2ProviderApp(t, client)
+
+		ctx, _ := testutil.Context(t)
+		req := codersdk.OAuth2AuthorizeRequest{
+			ClientID:    app.ID,
+			RedirectURI: "http://localhost:3000/callback/done",
+		}
+		for _, redirectURI := range []string{"", "http://localhost:3000/callback"} {
+			code := authorizeOAuth2(ctx, t, client, req)
+			_, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("redirect_uri", redirectURI))
+			var retrieveErr *oauth2.RetrieveError
+			require.ErrorAs(t, err, &retrieveErr, redirectURI)
+			require.Equal(t, http.StatusBadRequest, retrieveErr.Response.StatusCode)
+		}
+	})
+
+	t.Run("PKCE", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		app, config := createOAuth2ProviderApp(t, client)
+
+		ctx, _ := testutil.Context(t)
+		verifier := strings.Repeat("verifier-", 6)
+		sum := sha256.Sum256([]byte(verifier))
+		req := codersdk.OAuth2AuthorizeRequest{
+			ClientID:            app.ID,
+			CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+			CodeChallengeMethod: "S256",
+		}
+
+		// The verifier must match the challenge.
+		for _, wrong := range []string{"", strings.Repeat("wrong-", 8)} {
+			code := authorizeOAuth2(ctx, t, client, req)
+			_, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", wrong))
+			require.Error(t, err, wrong)
+		}
+		code := authorizeOAuth2(ctx, t, client, req)
+		_, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+		require.NoError(t, err)
+
+		// A verifier without a challenge is rejected.
+		code = authorizeOAuth2(ctx, t, client, codersdk.OAuth2AuthorizeRequest{ClientID: app.ID})
+		_, err = config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+		require.Error(t, err)
+
+		_, err = client.OAuth2Authorize(ctx, codersdk.OAuth2AuthorizeRequest{
+			ClientID:            app.ID,
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: "S512",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidClient", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		app, config := createOAuth2ProviderApp(t, client)
+
+		ctx, _ := testutil.Context(t)
+		code := authorizeOAuth2(ctx, t, client, codersdk.OAuth2AuthorizeRequest{ClientID: app.ID})
+		config.ClientSecret = "wrong"
+		_, err := config.Exchange(ctx, code)
+		var retrieveErr *oauth2.RetrieveError
+		require.ErrorAs(t, err, &retrieveErr)
+		require.Equal(t, http.StatusUnauthorized, retrieveErr.Response.StatusCode)
+	})
+}
+
+func createOAuth2ProviderApp(t *testing.T, client *codersdk.Client) (codersdk.OAuth2ProviderApp, *oauth2.Config) {
+	t.Helper()
+	ctx, _ := testutil.Context(t)
+	app, err := client.CreateOAuth2ProviderApp(ctx, codersdk.CreateOAuth2ProviderAppRequest{
+		Name:        "internal-tool",
+		CallbackURL: "http://localhost:3000/callback",
+	})
+	require.NoError(t, err)
+	return app, &oauth2.Config{
+		ClientID:     app.ID.String(),
+		ClientSecret: app.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   client.URL.String() + "/api/v2/oauth2/authorize",
+			TokenURL:  client.URL.String() + "/api/v2/oauth2/tokens",
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+	}
+}
+
+// authorizeOAuth2 allows the app on behalf of the user and returns the
+// authorization code from the redirect.
+func authorizeOAuth2(ctx context.Context, t *testing.T, client *codersdk.Client, req codersdk.OAuth2AuthorizeRequest) string {
+	t.Helper()
+	resp, err := client.OAuth2Authorize(ctx, req)
+	require.NoError(t, err)
+	redirectURI, err := url.Parse(resp.RedirectURI)
+	require.NoError(t, err)
+	require.Equal(t, req.State, redirectURI.Query().Get("state"))
+	code := redirectURI.Query().Get("code")
+	require.NotEmpty(t, code)
+	return code
+}
+
+func introspectOAuth2(ctx context.Context, t *testing.T, client *codersdk.Client, app codersdk.OAuth2ProviderApp, token string) codersdk.OAuth2Introspection {
+	t.Helper()
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.URL.String()+"/api/v2/oauth2/introspect", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(app.ID.String(), app.ClientSecret)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var introspection codersdk.OAuth2Introspection
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&introspection))
+	return introspection
+}
+
+func oauth2UserInfo(ctx context.Context, t *testing.T, client *codersdk.Client, httpClient *http.Client) codersdk.OAuth2UserInfo {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.URL.String()+"/api/v2/oauth2/userinfo", nil)
+	require.NoError(t, err)
+	res, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var info codersdk.OAuth2UserInfo
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&info))
+	return info
+}
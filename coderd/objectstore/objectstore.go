@@ -0,0 +1,73 @@
+// Package objectstore stores objects in S3-compatible object storage.
+package objectstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrNotFound is returned when getting an object that doesn't exist.
+var ErrNotFound = xerrors.New("object not found")
+
+// Store stores objects by key.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns ErrNotFound if the object doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// DeletePrefix deletes every object whose key starts with the prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// NewMemory returns a Store that keeps objects in memory, for tests.
+func NewMemory() *Memory {
+	return &Memory{objects: map[string][]byte{}}
+}
+
+type Memory struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *Memory) Put(_ context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *Memory) DeletePrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.objects, key)
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys of the stored objects in order.
+func (m *Memory) Keys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.objects))
+	for key := range m.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
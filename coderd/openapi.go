@@ -0,0 +1,567 @@
+package coderd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coder/coder/buildinfo"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/openapi"
+	"github.com/coder/coder/codersdk"
+)
+
+// openAPISpecs describes the payloads of AGPL routes. Routes that aren't
+// listed are still included in the document, just without schemas.
+func openAPISpecs() map[string]openapi.Spec {
+	return map[string]openapi.Spec{
+		openapi.Key(http.MethodGet, "/buildinfo"): {
+			Summary:  "Get build information",
+			Response: codersdk.BuildInfoResponse{},
+		},
+		openapi.Key(http.MethodGet, "/meta"): {
+			Summary:  "Get API capabilities",
+			Response: codersdk.APIMeta{},
+		},
+		openapi.Key(http.MethodGet, "/meta/deprecations"): {
+			Summary:  "Get the usage of deprecated routes",
+			Response: codersdk.DeprecationReport{},
+		},
+		openapi.Key(http.MethodGet, "/maintenance"): {
+			Summary:  "Get the maintenance mode of the deployment",
+			Response: codersdk.Maintenance{},
+		},
+		openapi.Key(http.MethodPut, "/maintenance"): {
+			Summary:  "Enable or disable maintenance mode",
+			Request:  codersdk.UpdateMaintenanceRequest{},
+			Response: codersdk.Maintenance{},
+		},
+		openapi.Key(http.MethodGet, "/authcheck/permissions"): {
+			Summary:  "Get the actions the authenticated user can perform on an object",
+			Response: codersdk.AuthorizationPermissions{},
+		},
+		openapi.Key(http.MethodGet, "/authcheck/policy"): {
+			Summary:  "Export the roles and policy that authorize requests",
+			Response: codersdk.AuthorizationPolicy{},
+		},
+		openapi.Key(http.MethodPost, "/authcheck/simulate"): {
+			Summary:  "Evaluate permission checks as another user",
+			Request:  codersdk.AuthorizationSimulateRequest{},
+			Response: codersdk.AuthorizationSimulateResponse{},
+		},
+		openapi.Key(http.MethodPost, "/batch"): {
+			Summary:  "Execute multiple API requests in one round trip",
+			Request:  codersdk.BatchRequest{},
+			Response: codersdk.BatchResponse{},
+		},
+		openapi.Key(http.MethodGet, "/operations/{operation}"): {
+			Summary:  "Get the progress of a long-running operation",
+			Response: codersdk.Operation{},
+		},
+		openapi.Key(http.MethodGet, "/rpc"): {
+			Summary: "Connect to the dRPC service defined in codersdk/proto over a websocket",
+		},
+		openapi.Key(http.MethodGet, "/search"): {
+			Summary:  "Search workspaces, templates, users, and groups by name",
+			Response: []codersdk.SearchResult{},
+		},
+		openapi.Key(http.MethodGet, "/events"): {
+			Summary: "Stream server-sent events about workspaces, groups, and organization members",
+		},
+		openapi.Key(http.MethodGet, "/users"): {
+			Summary:  "List users",
+			Response: []codersdk.User{},
+		},
+		openapi.Key(http.MethodPost, "/users"): {
+			Summary:  "Create a user",
+			Request:  codersdk.CreateUserRequest{},
+			Response: codersdk.User{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/users/{user}"): {
+			Summary:  "Get a user",
+			Response: codersdk.User{},
+		},
+		openapi.Key(http.MethodPost, "/users/{user}/keys/tokens"): {
+			Summary:  "Create an API token",
+			Request:  codersdk.CreateTokenRequest{},
+			Response: codersdk.GenerateAPIKeyResponse{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/users/{user}/keys/tokens"): {
+			Summary:  "List API tokens",
+			Response: []codersdk.APIKey{},
+		},
+		openapi.Key(http.MethodPost, "/users/{user}/role-requests"): {
+			Summary:  "Request a role",
+			Request:  codersdk.CreateRoleRequestRequest{},
+			Response: codersdk.RoleRequest{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/users/{user}/role-requests"): {
+			Summary:  "List the role requests of a user",
+			Response: []codersdk.RoleRequest{},
+		},
+		openapi.Key(http.MethodGet, "/role-requests"): {
+			Summary:  "List pending role requests you can approve",
+			Response: []codersdk.RoleRequest{},
+		},
+		openapi.Key(http.MethodPost, "/role-requests/{rolerequest}/approve"): {
+			Summary:  "Approve a role request",
+			Response: codersdk.RoleRequest{},
+		},
+		openapi.Key(http.MethodPost, "/role-requests/{rolerequest}/deny"): {
+			Summary:  "Deny a role request",
+			Response: codersdk.RoleRequest{},
+		},
+		openapi.Key(http.MethodGet, "/users/{user}/favorites"): {
+			Summary:  "Get the favorite workspaces and templates of a user",
+			Response: codersdk.Favorites{},
+		},
+		openapi.Key(http.MethodPut, "/users/{user}/favorites"): {
+			Summary:  "Replace the favorite workspaces and templates of a user",
+			Request:  codersdk.Favorites{},
+			Response: codersdk.Favorites{},
+		},
+		openapi.Key(http.MethodGet, "/users/{user}/organizations"): {
+			Summary:  "List organizations of a user",
+			Response: []codersdk.Organization{},
+		},
+		openapi.Key(http.MethodPost, "/organizations"): {
+			Summary:  "Create an organization",
+			Request:  codersdk.CreateOrganizationRequest{},
+			Response: codersdk.Organization{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}"): {
+			Summary:  "Get an organization",
+			Response: codersdk.Organization{},
+		},
+		openapi.Key(http.MethodPatch, "/organizations/{organization}"): {
+			Summary:  "Update the settings of an organization",
+			Request:  codersdk.UpdateOrganizationRequest{},
+			Response: codersdk.Organization{},
+		},
+		openapi.Key(http.MethodDelete, "/organizations/{organization}"): {
+			Summary:  "Start deleting an organization",
+			Response: codersdk.OrganizationDeletion{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/deletion-status"): {
+			Summary:  "Get the progress of deleting an organization",
+			Response: codersdk.OrganizationDeletion{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/oidc"): {
+			Summary:  "Get the OIDC config of an organization",
+			Response: codersdk.OrganizationOIDCConfig{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/oidc"): {
+			Summary:  "Configure OIDC for an organization",
+			Request:  codersdk.UpdateOrganizationOIDCConfigRequest{},
+			Response: codersdk.OrganizationOIDCConfig{},
+		},
+		openapi.Key(http.MethodDelete, "/organizations/{organization}/oidc"): {
+			Summary:  "Delete the OIDC config of an organization",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/users/oidc/route"): {
+			Summary:  "Find where a user signs in with OIDC",
+			Response: codersdk.OIDCLoginRoute{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/costs"): {
+			Summary:  "Get the costs of an organization's workspaces over a time range",
+			Response: codersdk.WorkspaceCostReport{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/costs/export"): {
+			Summary: "Export the costs of an organization's workspaces as CSV",
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/insights"): {
+			Summary:  "Get the usage of an organization over a time range",
+			Response: codersdk.OrganizationInsights{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/invites"): {
+			Summary:  "List invites of an organization",
+			Response: []codersdk.OrganizationInvite{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/invites"): {
+			Summary:  "Create an organization invite",
+			Request:  codersdk.CreateOrganizationInviteRequest{},
+			Response: codersdk.OrganizationInvite{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodDelete, "/organizations/{organization}/invites/{invite}"): {
+			Summary:  "Delete an organization invite",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/templatedefaults"): {
+			Summary:  "Get the defaults templates of an organization inherit",
+			Response: codersdk.OrganizationTemplateDefaults{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/templatedefaults"): {
+			Summary:  "Update the defaults templates of an organization inherit",
+			Request:  codersdk.UpdateOrganizationTemplateDefaultsRequest{},
+			Response: codersdk.OrganizationTemplateDefaults{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/workspace-name-policy"): {
+			Summary:  "Get the name policy of the workspaces of an organization",
+			Response: codersdk.WorkspaceNamePolicy{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/workspace-name-policy"): {
+			Summary:  "Update the name policy of the workspaces of an organization",
+			Request:  codersdk.WorkspaceNamePolicy{},
+			Response: codersdk.WorkspaceNamePolicy{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/ip-allowlist"): {
+			Summary:  "Get the IP allowlist of an organization",
+			Response: codersdk.OrganizationIPAllowlist{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/ip-allowlist"): {
+			Summary:  "Update the IP allowlist of an organization",
+			Request:  codersdk.UpdateOrganizationIPAllowlistRequest{},
+			Response: codersdk.OrganizationIPAllowlist{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/webhooks"): {
+			Summary:  "List webhooks of an organization",
+			Response: []codersdk.OrganizationWebhook{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/webhooks"): {
+			Summary:  "Create an organization webhook",
+			Request:  codersdk.CreateOrganizationWebhookRequest{},
+			Response: codersdk.OrganizationWebhook{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodDelete, "/organizations/{organization}/webhooks/{webhook}"): {
+			Summary:  "Delete an organization webhook",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/webhooks/{webhook}/deliveries"): {
+			Summary:  "List recent deliveries of an organization webhook",
+			Response: []codersdk.OrganizationWebhookDelivery{},
+		},
+		openapi.Key(http.MethodGet, "/webhooks"): {
+			Summary:  "List deployment-wide webhooks",
+			Response: []codersdk.Webhook{},
+		},
+		openapi.Key(http.MethodPost, "/webhooks"): {
+			Summary:  "Create a deployment-wide webhook",
+			Request:  codersdk.CreateWebhookRequest{},
+			Response: codersdk.Webhook{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodDelete, "/webhooks/{webhook}"): {
+			Summary:  "Delete a webhook",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/webhooks/{webhook}/deliveries"): {
+			Summary:  "List recent deliveries of a webhook",
+			Response: []codersdk.WebhookDelivery{},
+		},
+		openapi.Key(http.MethodPost, "/webhooks/{webhook}/deliveries/{delivery}/redeliver"): {
+			Summary:  "Send the event of a delivery to its webhook again",
+			Response: codersdk.WebhookDelivery{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/oauth2-provider/apps"): {
+			Summary:  "List OAuth2 apps users can sign in to with Coder",
+			Response: []codersdk.OAuth2ProviderApp{},
+		},
+		openapi.Key(http.MethodPost, "/oauth2-provider/apps"): {
+			Summary:  "Register an OAuth2 app",
+			Request:  codersdk.CreateOAuth2ProviderAppRequest{},
+			Response: codersdk.OAuth2ProviderApp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/oauth2-provider/apps/{app}"): {
+			Summary:  "Get an OAuth2 app",
+			Response: codersdk.OAuth2ProviderApp{},
+		},
+		openapi.Key(http.MethodPut, "/oauth2-provider/apps/{app}"): {
+			Summary:  "Update an OAuth2 app",
+			Request:  codersdk.UpdateOAuth2ProviderAppRequest{},
+			Response: codersdk.OAuth2ProviderApp{},
+		},
+		openapi.Key(http.MethodDelete, "/oauth2-provider/apps/{app}"): {
+			Summary:  "Delete an OAuth2 app and revoke its tokens",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodPost, "/oauth2-provider/apps/{app}/secret"): {
+			Summary:  "Regenerate the client secret of an OAuth2 app",
+			Response: codersdk.OAuth2ProviderApp{},
+		},
+		openapi.Key(http.MethodGet, "/oauth2/authorize"): {
+			Summary:  "Get what an OAuth2 authorization request asks the user to allow",
+			Response: codersdk.OAuth2Authorization{},
+		},
+		openapi.Key(http.MethodPost, "/oauth2/authorize"): {
+			Summary:  "Allow an OAuth2 app to act on behalf of the user",
+			Response: codersdk.OAuth2AuthorizeResponse{},
+		},
+		openapi.Key(http.MethodPost, "/oauth2/tokens"): {
+			Summary:  "Exchange an OAuth2 authorization code for a token",
+			Response: codersdk.OAuth2TokenResponse{},
+		},
+		openapi.Key(http.MethodPost, "/oauth2/introspect"): {
+			Summary:  "Describe a token to an OAuth2 app",
+			Response: codersdk.OAuth2Introspection{},
+		},
+		openapi.Key(http.MethodGet, "/oauth2/userinfo"): {
+			Summary:  "Get the user of an OAuth2 token",
+			Response: codersdk.OAuth2UserInfo{},
+		},
+		openapi.Key(http.MethodPost, "/invites/redeem"): {
+			Summary:  "Join an organization with an invite",
+			Request:  codersdk.RedeemOrganizationInviteRequest{},
+			Response: codersdk.OrganizationMember{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/members"): {
+			Summary:  "List organization members",
+			Response: []codersdk.OrganizationMemberWithUser{},
+		},
+		openapi.Key(http.MethodPatch, "/organizations/{organization}/members/roles"): {
+			Summary:  "Assign organization roles to many members at once",
+			Request:  codersdk.UpdateOrganizationMembersRolesRequest{},
+			Response: []codersdk.OrganizationMember{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/members/{user}/roles"): {
+			Summary:  "Assign organization roles to a member",
+			Request:  codersdk.UpdateRoles{},
+			Response: codersdk.OrganizationMember{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/templates"): {
+			Summary:  "List templates of an organization",
+			Response: []codersdk.Template{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/templates"): {
+			Summary:  "Create a template",
+			Request:  codersdk.CreateTemplateRequest{},
+			Response: codersdk.Template{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}"): {
+			Summary:  "Get a template",
+			Response: codersdk.Template{},
+		},
+		openapi.Key(http.MethodPatch, "/templates/{template}"): {
+			Summary:  "Update template metadata",
+			Request:  codersdk.UpdateTemplateMeta{},
+			Response: codersdk.Template{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/costs"): {
+			Summary:  "Get the resource costs of a template",
+			Response: codersdk.TemplateResourceCosts{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/costs"): {
+			Summary:  "Update the resource costs of a template",
+			Request:  codersdk.TemplateResourceCosts{},
+			Response: codersdk.TemplateResourceCosts{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/extension-policy"): {
+			Summary:  "Get the autostop extension policy of a template",
+			Response: codersdk.TemplateAutostopExtensionPolicy{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/extension-policy"): {
+			Summary:  "Update the autostop extension policy of a template",
+			Request:  codersdk.AutostopExtensionPolicy{},
+			Response: codersdk.TemplateAutostopExtensionPolicy{},
+		},
+		openapi.Key(http.MethodDelete, "/templates/{template}/extension-policy"): {
+			Summary:  "Use the deployment's autostop extension policy for a template",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/archive-policy"): {
+			Summary:  "Get the archive policy of a template",
+			Response: codersdk.TemplateArchivePolicy{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/archive-policy"): {
+			Summary:  "Update the archive policy of a template",
+			Request:  codersdk.TemplateArchivePolicy{},
+			Response: codersdk.TemplateArchivePolicy{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/auto-rebuild-policy"): {
+			Summary:  "Get the auto-rebuild policy of a template",
+			Response: codersdk.TemplateAutoRebuildPolicy{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/auto-rebuild-policy"): {
+			Summary:  "Update the auto-rebuild policy of a template",
+			Request:  codersdk.TemplateAutoRebuildPolicy{},
+			Response: codersdk.TemplateAutoRebuildPolicy{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/workspace-name-policy"): {
+			Summary:  "Get the name policy of the workspaces of a template",
+			Response: codersdk.WorkspaceNamePolicy{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/workspace-name-policy"): {
+			Summary:  "Update the name policy of the workspaces of a template",
+			Request:  codersdk.WorkspaceNamePolicy{},
+			Response: codersdk.WorkspaceNamePolicy{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/deprecation"): {
+			Summary:  "Get the deprecation of a template",
+			Response: codersdk.TemplateDeprecation{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/deprecation"): {
+			Summary:  "Deprecate a template",
+			Request:  codersdk.UpdateTemplateDeprecationRequest{},
+			Response: codersdk.TemplateDeprecation{},
+		},
+		openapi.Key(http.MethodDelete, "/templates/{template}/deprecation"): {
+			Summary:  "Undeprecate a template",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/maintenance"): {
+			Summary:  "Get the maintenance window of a template",
+			Response: codersdk.TemplateMaintenanceWindow{},
+		},
+		openapi.Key(http.MethodPut, "/templates/{template}/maintenance"): {
+			Summary:  "Update the maintenance window of a template",
+			Request:  codersdk.TemplateMaintenanceWindow{},
+			Response: codersdk.TemplateMaintenanceWindow{},
+		},
+		openapi.Key(http.MethodGet, "/templateversions/{templateversion}"): {
+			Summary:  "Get a template version",
+			Response: codersdk.TemplateVersion{},
+		},
+		openapi.Key(http.MethodGet, "/templateversions/{templateversion}/diff/{othertemplateversion}"): {
+			Summary:  "Compare two template versions",
+			Response: codersdk.TemplateVersionDiff{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/members/{user}/workspaces"): {
+			Summary:  "Create a workspace",
+			Request:  codersdk.CreateWorkspaceRequest{},
+			Response: codersdk.Workspace{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/workspaces"): {
+			Summary:  "List workspaces",
+			Response: []codersdk.Workspace{},
+		},
+		openapi.Key(http.MethodPost, "/workspaces/batch"): {
+			Summary:  "Start, stop, delete, or update the workspaces that match a filter",
+			Request:  codersdk.CreateWorkspaceBatchRequest{},
+			Response: codersdk.WorkspaceBatch{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Key(http.MethodGet, "/workspaces/batch/{operation}"): {
+			Summary:  "Get the results of a bulk action on workspaces",
+			Response: codersdk.WorkspaceBatch{},
+		},
+		openapi.Key(http.MethodGet, "/workspaces/{workspace}"): {
+			Summary:  "Get a workspace",
+			Response: codersdk.Workspace{},
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/builds"): {
+			Summary:  "Create a workspace build",
+			Request:  codersdk.CreateWorkspaceBuildRequest{},
+			Response: codersdk.WorkspaceBuild{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/extend"): {
+			Summary:  "Extend the deadline of a running workspace",
+			Request:  codersdk.ExtendWorkspaceRequest{},
+			Response: codersdk.ExtendWorkspaceResponse{},
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/transfer"): {
+			Summary:  "Transfer a workspace to another organization",
+			Request:  codersdk.TransferWorkspaceRequest{},
+			Response: codersdk.Workspace{},
+		},
+		openapi.Key(http.MethodPut, "/workspaces/{workspace}/owner"): {
+			Summary:  "Transfer a workspace to another user",
+			Request:  codersdk.UpdateWorkspaceOwnerRequest{},
+			Response: codersdk.Workspace{},
+		},
+		openapi.Key(http.MethodPut, "/workspaces/{workspace}/labels"): {
+			Summary:  "Update the labels of a workspace",
+			Request:  codersdk.UpdateWorkspaceLabelsRequest{},
+			Response: codersdk.Workspace{},
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/clone"): {
+			Summary:  "Clone a workspace",
+			Request:  codersdk.CloneWorkspaceRequest{},
+			Response: codersdk.Workspace{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/migrate"): {
+			Summary:  "Migrate a workspace of a deprecated template to its successor",
+			Response: codersdk.Workspace{},
+		},
+		openapi.Key(http.MethodGet, "/workspaces/{workspace}/timeline"): {
+			Summary:  "Get what happened to a workspace over a time range",
+			Response: codersdk.WorkspaceTimeline{},
+		},
+		openapi.Key(http.MethodGet, "/workspaces/{workspace}/usage"): {
+			Summary:  "Get the resource usage of the agents of a workspace",
+			Response: codersdk.WorkspaceUsage{},
+		},
+		openapi.Key(http.MethodGet, "/workspaces/{workspace}/archive"): {
+			Summary:  "Get the archive of a workspace",
+			Response: codersdk.WorkspaceArchive{},
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/archive"): {
+			Summary:  "Archive a workspace to object storage",
+			Response: codersdk.WorkspaceArchive{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Key(http.MethodPost, "/workspaces/{workspace}/archive/restore"): {
+			Summary:  "Restore an archived workspace",
+			Response: codersdk.WorkspaceArchive{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Key(http.MethodGet, "/workspaces/{workspace}/acl"): {
+			Summary:  "Get the users and groups a workspace is shared with",
+			Response: codersdk.WorkspaceACL{},
+		},
+		openapi.Key(http.MethodPatch, "/workspaces/{workspace}/acl"): {
+			Summary:  "Share a workspace with users and groups",
+			Request:  codersdk.UpdateWorkspaceACL{},
+			Response: codersdk.WorkspaceACL{},
+		},
+		openapi.Key(http.MethodGet, "/workspacebuilds/{workspacebuild}"): {
+			Summary:  "Get a workspace build",
+			Response: codersdk.WorkspaceBuild{},
+		},
+	}
+}
+
+// openAPIDocument serves an OpenAPI 3 document generated from the routes
+// mounted under /api/v2, including routes added by wrapping APIs.
+func (api *API) openAPIDocument(rw http.ResponseWriter, r *http.Request) {
+	// Routes are static once the server is serving, so the document is only
+	// generated once.
+	api.openAPIOnce.Do(func() {
+		deprecated := make(map[string]bool, len(api.deprecations))
+		for _, d := range api.deprecations {
+			deprecated[openapi.Key(d.Method, strings.TrimPrefix(d.Path, "/api/"+codersdk.APIVersion))] = true
+		}
+		doc, err := openapi.Generate(api.APIHandler, openapi.Options{
+			Info: openapi.Info{
+				Title:       "Coder API",
+				Description: fmt.Sprintf("Coder REST API %s.", codersdk.APIVersion),
+				Version:     buildinfo.Version(),
+			},
+			BasePath:      "/api/" + codersdk.APIVersion,
+			Specs:         api.OpenAPISpecs,
+			Deprecated:    deprecated,
+			ErrorResponse: codersdk.Response{},
+		})
+		if err != nil {
+			api.openAPIErr = err
+			return
+		}
+		api.openAPIDoc, api.openAPIErr = json.Marshal(doc)
+	})
+	if api.openAPIErr != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error generating OpenAPI document.",
+			Detail:  api.openAPIErr.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.Header().Set("X-Coder-API-Version", codersdk.APIVersion)
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(api.openAPIDoc)
+}
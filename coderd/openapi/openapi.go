@@ -0,0 +1,347 @@
+// Package openapi generates an OpenAPI 3 document from a chi router.
+//
+// Every route registered on the router is included. Request and response
+// schemas are derived by reflection from the Go types registered with a
+// Spec, so routes without a Spec are still listed with generic responses.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lowercase HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Spec describes the payloads of a single route. Request and Response are
+// zero values of the Go types sent and received, e.g. codersdk.Group{}.
+type Spec struct {
+	Summary  string
+	Request  any
+	Response any
+	// Status is the success status code. Defaults to 200.
+	Status int
+}
+
+// Options configure document generation.
+type Options struct {
+	Info Info
+	// BasePath is the prefix the router is mounted at, e.g. "/api/v2".
+	BasePath string
+	// Specs maps "METHOD /path" (relative to BasePath) to route payloads.
+	Specs map[string]Spec
+	// Deprecated contains "METHOD /path" keys of deprecated routes.
+	Deprecated map[string]bool
+	// ErrorResponse is the type returned for non-2xx responses.
+	ErrorResponse any
+}
+
+// Key formats the lookup key used by Options.Specs and Options.Deprecated.
+func Key(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+var paramRegex = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Generate walks the routes and produces an OpenAPI document.
+func Generate(routes chi.Routes, opts Options) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    opts.Info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+	}
+	if opts.BasePath != "" {
+		doc.Servers = []Server{{URL: opts.BasePath}}
+	}
+	gen := &schemaGenerator{components: doc.Components.Schemas}
+	var errorSchema *Schema
+	if opts.ErrorResponse != nil {
+		errorSchema = gen.schema(reflect.TypeOf(opts.ErrorResponse))
+	}
+
+	operationIDs := map[string]int{}
+	err := chi.Walk(routes, func(method string, route string, handler http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := normalizePath(route)
+		if strings.Contains(path, "*") {
+			// Wildcard routes proxy arbitrary paths and can't be described.
+			return nil
+		}
+		key := Key(method, path)
+		spec := opts.Specs[key]
+
+		op := &Operation{
+			OperationID: operationID(method, path, handler),
+			Summary:     spec.Summary,
+			Tags:        []string{tag(path)},
+			Deprecated:  opts.Deprecated[key],
+			Responses:   map[string]Response{},
+		}
+		operationIDs[op.OperationID]++
+		for _, match := range paramRegex.FindAllStringSubmatch(path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		if spec.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/json": {Schema: gen.schema(reflect.TypeOf(spec.Request))},
+				},
+			}
+		}
+		status := spec.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if spec.Response != nil {
+			success.Content = map[string]MediaType{
+				"application/json": {Schema: gen.schema(reflect.TypeOf(spec.Response))},
+			}
+		}
+		op.Responses[fmt.Sprint(status)] = success
+		failure := Response{Description: "Error"}
+		if errorSchema != nil {
+			failure.Content = map[string]MediaType{
+				"application/json": {Schema: errorSchema},
+			}
+		}
+		op.Responses["default"] = failure
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(method)] = op
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("walk routes: %w", err)
+	}
+
+	// Operation IDs must be unique, so fall back to the method and path for
+	// handlers that share a name.
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if operationIDs[op.OperationID] > 1 {
+				op.OperationID = pathOperationID(method, path)
+			}
+		}
+	}
+	return doc, nil
+}
+
+func normalizePath(route string) string {
+	path := strings.ReplaceAll(route, "/*/", "/")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return paramRegex.ReplaceAllString(path, "{$1}")
+}
+
+func tag(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if parts[0] == "" {
+		return "general"
+	}
+	return parts[0]
+}
+
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+
+func operationID(method, path string, handler http.Handler) string {
+	if fn, ok := handler.(http.HandlerFunc); ok {
+		name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+		name = name[strings.LastIndex(name, ".")+1:]
+		name = strings.TrimSuffix(name, "-fm")
+		if identifierRegex.MatchString(name) && !strings.HasPrefix(name, "func") {
+			return name
+		}
+	}
+	return pathOperationID(method, path)
+}
+
+func pathOperationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '_'
+	}) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+type schemaGenerator struct {
+	components map[string]*Schema
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.components[name]; !ok {
+			// Reserve the name before recursing to support cyclic types.
+			g.components[name] = &Schema{}
+			*g.components[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+			embedded := g.structSchema(embeddedType)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+	return s
+}
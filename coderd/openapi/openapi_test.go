@@ -0,0 +1,86 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/openapi"
+)
+
+type widget struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	CreatedAt time.Time         `json:"created_at"`
+	Labels    map[string]string `json:"labels"`
+	Parts     []widgetPart      `json:"parts"`
+	Secret    string            `json:"-"`
+}
+
+type widgetPart struct {
+	Count  int64   `json:"count"`
+	Parent *widget `json:"parent"`
+}
+
+func listWidgets(http.ResponseWriter, *http.Request) {}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	r := chi.NewRouter()
+	r.Route("/widgets", func(r chi.Router) {
+		r.Get("/", listWidgets)
+		r.Route("/{widget}", func(r chi.Router) {
+			r.Get("/", func(http.ResponseWriter, *http.Request) {})
+			r.Delete("/", func(http.ResponseWriter, *http.Request) {})
+		})
+	})
+	r.HandleFunc("/proxy/*", func(http.ResponseWriter, *http.Request) {})
+
+	doc, err := openapi.Generate(r, openapi.Options{
+		Info:     openapi.Info{Title: "Test", Version: "v1.2.3"},
+		BasePath: "/api/v1",
+		Specs: map[string]openapi.Spec{
+			openapi.Key(http.MethodGet, "/widgets"): {Response: []widget{}},
+		},
+		Deprecated: map[string]bool{
+			openapi.Key(http.MethodDelete, "/widgets/{widget}"): true,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, openapi.Version, doc.OpenAPI)
+	require.Equal(t, "v1.2.3", doc.Info.Version)
+	require.Equal(t, []openapi.Server{{URL: "/api/v1"}}, doc.Servers)
+
+	require.Len(t, doc.Paths, 2, "wildcard routes are skipped")
+	list := doc.Paths["/widgets"]["get"]
+	require.NotNil(t, list)
+	require.Equal(t, "listWidgets", list.OperationID)
+	require.Equal(t, []string{"widgets"}, list.Tags)
+	schema := list.Responses["200"].Content["application/json"].Schema
+	require.Equal(t, "array", schema.Type)
+	require.Equal(t, "#/components/schemas/widget", schema.Items.Ref)
+
+	get := doc.Paths["/widgets/{widget}"]["get"]
+	require.Equal(t, []openapi.Parameter{{
+		Name:     "widget",
+		In:       "path",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string"},
+	}}, get.Parameters)
+	require.Equal(t, "getWidgetsWidget", get.OperationID)
+	require.True(t, doc.Paths["/widgets/{widget}"]["delete"].Deprecated)
+
+	component := doc.Components.Schemas["widget"]
+	require.NotNil(t, component)
+	require.Len(t, component.Properties, 5)
+	require.Equal(t, &openapi.Schema{Type: "string", Format: "uuid"}, component.Properties["id"])
+	require.Equal(t, &openapi.Schema{Type: "string", Format: "date-time"}, component.Properties["created_at"])
+	require.Equal(t, "object", component.Properties["labels"].Type)
+	require.Equal(t, "#/components/schemas/widgetPart", component.Properties["parts"].Items.Ref)
+	require.Equal(t, "#/components/schemas/widget", doc.Components.Schemas["widgetPart"].Properties["parent"].Ref)
+}
@@ -0,0 +1,87 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) operation(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := uuid.Parse(chi.URLParam(r, "operation"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid operation ID.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	operation, err := api.Database.GetOperationByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionRead, operation) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOperation(operation))
+}
+
+// writeOperationAccepted responds that the request started the operation,
+// and points the client to where its progress is reported.
+func writeOperationAccepted(ctx context.Context, rw http.ResponseWriter, operationID uuid.UUID, response interface{}) {
+	rw.Header().Set("Location", "/api/v2/operations/"+operationID.String())
+	httpapi.Write(ctx, rw, http.StatusAccepted, response)
+}
+
+func updateOperation(ctx context.Context, db database.Store, operation database.Operation) (database.Operation, error) {
+	updated, err := db.UpdateOperationByID(ctx, database.UpdateOperationByIDParams{
+		ID:                operation.ID,
+		Status:            operation.Status,
+		ProgressCompleted: operation.ProgressCompleted,
+		ProgressTotal:     operation.ProgressTotal,
+		Error:             operation.Error,
+		UpdatedAt:         database.Now(),
+		CompletedAt:       operation.CompletedAt,
+	})
+	if err != nil {
+		return operation, xerrors.Errorf("update operation: %w", err)
+	}
+	return updated, nil
+}
+
+func convertOperation(operation database.Operation) codersdk.Operation {
+	converted := codersdk.Operation{
+		ID:                operation.ID,
+		Type:              codersdk.OperationType(operation.Type),
+		Status:            codersdk.OperationStatus(operation.Status),
+		InitiatorID:       operation.InitiatorID,
+		OrganizationID:    operation.OrganizationID.UUID,
+		ResourceID:        operation.ResourceID,
+		ProgressCompleted: operation.ProgressCompleted,
+		ProgressTotal:     operation.ProgressTotal,
+		Error:             operation.Error,
+		CreatedAt:         operation.CreatedAt,
+		UpdatedAt:         operation.UpdatedAt,
+	}
+	if operation.CompletedAt.Valid {
+		converted.CompletedAt = &operation.CompletedAt.Time
+	}
+	return converted
+}
@@ -0,0 +1,88 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOperation(t *testing.T) {
+	t.Parallel()
+	t.Run("OrganizationDeletion", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			OrganizationDeletionPollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "new",
+		})
+		require.NoError(t, err)
+		deletion, err := client.DeleteOrganization(ctx, org.ID)
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, deletion.OperationID)
+
+		var operation codersdk.Operation
+		require.Eventually(t, func() bool {
+			operation, err = client.Operation(ctx, deletion.OperationID)
+			return assert.NoError(t, err) && operation.Status != codersdk.OperationStatusRunning
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.OperationTypeOrganizationDeletion, operation.Type)
+		require.Equal(t, codersdk.OperationStatusSucceeded, operation.Status)
+		require.Equal(t, user.UserID, operation.InitiatorID)
+		require.Equal(t, org.ID, operation.OrganizationID)
+		require.Equal(t, org.ID, operation.ResourceID)
+		require.EqualValues(t, 1, operation.ProgressTotal)
+		require.EqualValues(t, 1, operation.ProgressCompleted)
+		require.Empty(t, operation.Error)
+		require.NotNil(t, operation.CompletedAt)
+
+		// The organization is gone once the operation succeeds.
+		_, err = client.Organization(ctx, org.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "new",
+		})
+		require.NoError(t, err)
+		deletion, err := client.DeleteOrganization(ctx, org.ID)
+		require.NoError(t, err)
+
+		// Members can't see operations started by others.
+		_, err = member.Operation(ctx, deletion.OperationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.Operation(ctx, uuid.New())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,424 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	// organizationDeletionBatchSize is how many rows a single query of an
+	// organization deletion handles, so large organizations aren't deleted in
+	// one long transaction.
+	organizationDeletionBatchSize = 100
+	// organizationDeletionHeartbeatInterval is how often the replica that runs
+	// a deletion records that it's still running.
+	organizationDeletionHeartbeatInterval = 15 * time.Second
+	// organizationDeletionStaleAfter is how long a deletion goes without a
+	// heartbeat before another replica takes it over.
+	organizationDeletionStaleAfter = time.Minute
+)
+
+func (api *API) organizationDeletionStatus(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organizationID, err := uuid.Parse(chi.URLParam(r, "organization"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid organization ID.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganization.InOrg(organizationID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	deletion, err := api.Database.GetOrganizationDeletionByOrganizationID(ctx, organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationDeletion(deletion))
+}
+
+// organizationDeleting writes an error and returns true if the organization
+// is being deleted, so nothing is added to it in the meantime.
+func (api *API) organizationDeleting(rw http.ResponseWriter, r *http.Request, organizationID uuid.UUID) bool {
+	ctx := r.Context()
+	deletion, err := api.Database.GetOrganizationDeletionByOrganizationID(ctx, organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return true
+	}
+	if deletion.Status != database.OrganizationDeletionStatusRunning {
+		return false
+	}
+	httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+		Message: "The organization is being deleted.",
+	})
+	return true
+}
+
+// resumeOrganizationDeletions continues the deletions that no replica runs,
+// e.g. because the API was stopped. Every replica checks for them on start and
+// then on every poll, but a deletion is only claimed by one of them.
+func (api *API) resumeOrganizationDeletions() {
+	api.organizationDeletionsWG.Add(1)
+	go func() {
+		defer api.organizationDeletionsWG.Done()
+		ctx := api.organizationDeletionsCtx
+		ticker := time.NewTicker(api.OrganizationDeletionPollInterval)
+		defer ticker.Stop()
+		for {
+			now := database.Now()
+			deletions, err := api.Database.AcquireOrganizationDeletions(ctx, database.AcquireOrganizationDeletionsParams{
+				WorkerID:    api.organizationDeletionsWorkerID,
+				Now:         now,
+				StaleBefore: now.Add(-organizationDeletionStaleAfter),
+			})
+			if err != nil && ctx.Err() == nil {
+				api.Logger.Warn(ctx, "acquire organization deletions", slog.Error(err))
+			}
+			for _, deletion := range deletions {
+				api.startOrganizationDeletion(deletion)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// startOrganizationDeletion deletes the organization in the background. The
+// deletion must be claimed by this replica, and stops if another replica
+// takes it over. Deletions that fail are marked as failed so they can be
+// retried.
+func (api *API) startOrganizationDeletion(deletion database.OrganizationDeletion) {
+	ctx, cancel := context.WithCancel(api.organizationDeletionsCtx)
+	logger := api.Logger.With(slog.F("organization_id", deletion.OrganizationID))
+	api.organizationDeletionsWG.Add(2)
+	go func() {
+		defer api.organizationDeletionsWG.Done()
+		api.heartbeatOrganizationDeletion(ctx, cancel, deletion)
+	}()
+	go func() {
+		defer api.organizationDeletionsWG.Done()
+		defer cancel()
+
+		deletion, err := api.runOrganizationDeletion(ctx, deletion)
+		if err == nil {
+			logger.Info(ctx, "deleted organization")
+			return
+		}
+		if ctx.Err() != nil {
+			// The deletion is still running, and is resumed by the replica
+			// that claims it.
+			return
+		}
+		logger.Warn(ctx, "delete organization", slog.Error(err))
+		deletion.Status = database.OrganizationDeletionStatusFailed
+		deletion.Error = err.Error()
+		deletion.CompletedAt = sql.NullTime{Time: database.Now(), Valid: true}
+		_, err = updateOrganizationDeletion(ctx, api.Database, deletion)
+		if err != nil {
+			logger.Error(ctx, "mark organization deletion failed", slog.Error(err))
+		}
+	}()
+}
+
+// heartbeatOrganizationDeletion records that the deletion is running until
+// the context is canceled. It cancels the deletion if another replica claimed
+// it, e.g. because a heartbeat was missed.
+func (api *API) heartbeatOrganizationDeletion(ctx context.Context, cancel context.CancelFunc, deletion database.OrganizationDeletion) {
+	ticker := time.NewTicker(organizationDeletionHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		_, err := api.Database.UpdateOrganizationDeletionHeartbeat(ctx, database.UpdateOrganizationDeletionHeartbeatParams{
+			OrganizationID: deletion.OrganizationID,
+			WorkerID:       deletion.WorkerID,
+			HeartbeatAt:    sql.NullTime{Time: database.Now(), Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			api.Logger.Warn(ctx, "organization deletion claimed by another replica", slog.F("organization_id", deletion.OrganizationID))
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			api.Logger.Warn(ctx, "record organization deletion heartbeat", slog.F("organization_id", deletion.OrganizationID), slog.Error(err))
+		}
+	}
+}
+
+// runOrganizationDeletion deletes the workspaces of the organization with
+// builds, so their resources are destroyed, and then removes its templates,
+// members, and the organization itself in batches. The progress is recorded
+// after every step.
+func (api *API) runOrganizationDeletion(ctx context.Context, deletion database.OrganizationDeletion) (database.OrganizationDeletion, error) {
+	var (
+		organizationID = deletion.OrganizationID
+		startedAt      = database.Now()
+		err            error
+	)
+
+	// Totals are kept when a failed deletion is retried, so the progress
+	// covers the whole organization.
+	if deletion.WorkspacesTotal == 0 && deletion.TemplatesTotal == 0 && deletion.MembersTotal == 0 {
+		deletion.WorkspacesTotal, err = api.Database.GetWorkspaceCountByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return deletion, xerrors.Errorf("get workspace count: %w", err)
+		}
+		deletion.TemplatesTotal, err = api.Database.GetTemplateCountByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return deletion, xerrors.Errorf("get template count: %w", err)
+		}
+		deletion.MembersTotal, err = api.Database.GetOrganizationMemberCountByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return deletion, xerrors.Errorf("get member count: %w", err)
+		}
+		deletion, err = updateOrganizationDeletion(ctx, api.Database, deletion)
+		if err != nil {
+			return deletion, err
+		}
+	}
+
+	ticker := time.NewTicker(api.OrganizationDeletionPollInterval)
+	defer ticker.Stop()
+	for {
+		remaining, err := api.Database.GetWorkspaceCountByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return deletion, xerrors.Errorf("get workspace count: %w", err)
+		}
+		if deleted := deletion.WorkspacesTotal - remaining; deleted > deletion.WorkspacesDeleted {
+			deletion.WorkspacesDeleted = deleted
+			deletion, err = updateOrganizationDeletion(ctx, api.Database, deletion)
+			if err != nil {
+				return deletion, err
+			}
+		}
+		if remaining == 0 {
+			break
+		}
+		err = api.buildOrganizationWorkspaceDeletes(ctx, deletion, startedAt)
+		if err != nil {
+			return deletion, err
+		}
+		select {
+		case <-ctx.Done():
+			return deletion, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	for {
+		ids, err := api.Database.DeleteDeletedWorkspacesByOrganizationID(ctx, database.DeleteDeletedWorkspacesByOrganizationIDParams{
+			OrganizationID: organizationID,
+			LimitOpt:       organizationDeletionBatchSize,
+		})
+		if err != nil {
+			return deletion, xerrors.Errorf("delete deleted workspaces: %w", err)
+		}
+		if len(ids) < organizationDeletionBatchSize {
+			break
+		}
+	}
+
+	for {
+		ids, err := api.Database.DeleteTemplatesByOrganizationID(ctx, database.DeleteTemplatesByOrganizationIDParams{
+			OrganizationID: organizationID,
+			LimitOpt:       organizationDeletionBatchSize,
+		})
+		if err != nil {
+			return deletion, xerrors.Errorf("delete templates: %w", err)
+		}
+		remaining, err := api.Database.GetTemplateCountByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return deletion, xerrors.Errorf("get template count: %w", err)
+		}
+		if deleted := deletion.TemplatesTotal - remaining; deleted > deletion.TemplatesDeleted {
+			deletion.TemplatesDeleted = deleted
+			deletion, err = updateOrganizationDeletion(ctx, api.Database, deletion)
+			if err != nil {
+				return deletion, err
+			}
+		}
+		if len(ids) < organizationDeletionBatchSize {
+			break
+		}
+	}
+
+	for {
+		ids, err := api.Database.DeleteOrganizationMembersByOrganizationID(ctx, database.DeleteOrganizationMembersByOrganizationIDParams{
+			OrganizationID: organizationID,
+			LimitOpt:       organizationDeletionBatchSize,
+		})
+		if err != nil {
+			return deletion, xerrors.Errorf("delete members: %w", err)
+		}
+		for _, userID := range ids {
+			api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionDeleted, organizationID, userID)
+		}
+		remaining, err := api.Database.GetOrganizationMemberCountByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return deletion, xerrors.Errorf("get member count: %w", err)
+		}
+		if deleted := deletion.MembersTotal - remaining; deleted > deletion.MembersDeleted {
+			deletion.MembersDeleted = deleted
+			deletion, err = updateOrganizationDeletion(ctx, api.Database, deletion)
+			if err != nil {
+				return deletion, err
+			}
+		}
+		if len(ids) < organizationDeletionBatchSize {
+			break
+		}
+	}
+
+	err = api.Database.DeleteOrganization(ctx, organizationID)
+	if err != nil {
+		return deletion, xerrors.Errorf("delete organization: %w", err)
+	}
+	api.OrganizationCache.Invalidate(ctx)
+
+	deletion.Status = database.OrganizationDeletionStatusSucceeded
+	deletion.CompletedAt = sql.NullTime{Time: database.Now(), Valid: true}
+	return updateOrganizationDeletion(ctx, api.Database, deletion)
+}
+
+// buildOrganizationWorkspaceDeletes starts a delete build for every workspace
+// of the organization that isn't being built. It fails if a delete build
+// that was started after startedAt failed.
+func (api *API) buildOrganizationWorkspaceDeletes(ctx context.Context, deletion database.OrganizationDeletion, startedAt time.Time) error {
+	afterID := uuid.Nil
+	for {
+		workspaces, err := api.Database.GetWorkspacesByOrganizationID(ctx, database.GetWorkspacesByOrganizationIDParams{
+			OrganizationID: deletion.OrganizationID,
+			Deleted:        false,
+			AfterID:        afterID,
+			LimitOpt:       organizationDeletionBatchSize,
+		})
+		if err != nil {
+			return xerrors.Errorf("get workspaces: %w", err)
+		}
+		for _, workspace := range workspaces {
+			build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("get latest build of workspace %q: %w", workspace.Name, err)
+			}
+			job, err := api.Database.GetProvisionerJobByID(ctx, build.JobID)
+			if err != nil {
+				return xerrors.Errorf("get provisioner job of workspace %q: %w", workspace.Name, err)
+			}
+			status := convertProvisionerJob(job).Status
+			if status.Active() {
+				continue
+			}
+			if build.Transition == database.WorkspaceTransitionDelete && status != codersdk.ProvisionerJobFailed && status != codersdk.ProvisionerJobCanceled {
+				continue
+			}
+			// Delete builds that failed before this attempt are retried.
+			if build.Transition == database.WorkspaceTransitionDelete && !build.CreatedAt.Before(startedAt) {
+				reason := job.Error.String
+				if reason == "" {
+					reason = string(status)
+				}
+				return xerrors.Errorf("workspace %q failed to delete: %s", workspace.Name, reason)
+			}
+			_, err = api.insertBackgroundWorkspaceBuild(ctx, deletion.InitiatorID, workspace, build, build.TemplateVersionID, database.WorkspaceTransitionDelete, database.BuildReasonInitiator)
+			if err != nil {
+				return xerrors.Errorf("delete workspace %q: %w", workspace.Name, err)
+			}
+		}
+		if len(workspaces) < organizationDeletionBatchSize {
+			return nil
+		}
+		afterID = workspaces[len(workspaces)-1].ID
+	}
+}
+
+func updateOrganizationDeletion(ctx context.Context, db database.Store, deletion database.OrganizationDeletion) (database.OrganizationDeletion, error) {
+	updated, err := db.UpdateOrganizationDeletionByOrganizationID(ctx, database.UpdateOrganizationDeletionByOrganizationIDParams{
+		OrganizationID:    deletion.OrganizationID,
+		WorkerID:          deletion.WorkerID,
+		Status:            deletion.Status,
+		Error:             deletion.Error,
+		WorkspacesTotal:   deletion.WorkspacesTotal,
+		WorkspacesDeleted: deletion.WorkspacesDeleted,
+		TemplatesTotal:    deletion.TemplatesTotal,
+		TemplatesDeleted:  deletion.TemplatesDeleted,
+		MembersTotal:      deletion.MembersTotal,
+		MembersDeleted:    deletion.MembersDeleted,
+		UpdatedAt:         database.Now(),
+		CompletedAt:       deletion.CompletedAt,
+	})
+	if err != nil {
+		return deletion, xerrors.Errorf("update organization deletion: %w", err)
+	}
+	if !updated.OperationID.Valid {
+		return updated, nil
+	}
+
+	// The operation reports the progress of all steps together.
+	_, err = updateOperation(ctx, db, database.Operation{
+		ID:                updated.OperationID.UUID,
+		Status:            database.OperationStatus(updated.Status),
+		ProgressCompleted: updated.WorkspacesDeleted + updated.TemplatesDeleted + updated.MembersDeleted,
+		ProgressTotal:     updated.WorkspacesTotal + updated.TemplatesTotal + updated.MembersTotal,
+		Error:             updated.Error,
+		CompletedAt:       updated.CompletedAt,
+	})
+	if err != nil {
+		return updated, err
+	}
+	return updated, nil
+}
+
+func convertOrganizationDeletion(deletion database.OrganizationDeletion) codersdk.OrganizationDeletion {
+	converted := codersdk.OrganizationDeletion{
+		OrganizationID:    deletion.OrganizationID,
+		InitiatorID:       deletion.InitiatorID,
+		OperationID:       deletion.OperationID.UUID,
+		Status:            codersdk.OrganizationDeletionStatus(deletion.Status),
+		Error:             deletion.Error,
+		WorkspacesTotal:   deletion.WorkspacesTotal,
+		WorkspacesDeleted: deletion.WorkspacesDeleted,
+		TemplatesTotal:    deletion.TemplatesTotal,
+		TemplatesDeleted:  deletion.TemplatesDeleted,
+		MembersTotal:      deletion.MembersTotal,
+		MembersDeleted:    deletion.MembersDeleted,
+		CreatedAt:         deletion.CreatedAt,
+		UpdatedAt:         deletion.UpdatedAt,
+	}
+	if deletion.CompletedAt.Valid {
+		converted.CompletedAt = &deletion.CompletedAt.Time
+	}
+	return converted
+}
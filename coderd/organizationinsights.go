@@ -0,0 +1,181 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// organizationInsightsDefaultRange is the range of insights when no start
+// time is given.
+const organizationInsightsDefaultRange = 30 * 24 * time.Hour
+
+func (api *API) organizationInsights(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+		now          = database.Now()
+	)
+
+	// Usage is only visible to those that manage the organization.
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	endTime := now
+	startTime := time.Time{}
+	for param, value := range map[string]*time.Time{
+		"start_time": &startTime,
+		"end_time":   &endTime,
+	} {
+		raw := r.URL.Query().Get(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Query param `" + param + "` must be a valid RFC3339 timestamp.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		*value = parsed
+	}
+	if startTime.IsZero() {
+		startTime = endTime.Add(-organizationInsightsDefaultRange)
+	}
+	if !startTime.Before(endTime) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The start time must be before the end time.",
+		})
+		return
+	}
+
+	builds, err := api.Database.GetWorkspaceBuildsByOrganizationID(ctx, database.GetWorkspaceBuildsByOrganizationIDParams{
+		OrganizationID: organization.ID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	users, err := api.Database.GetAgentStatUsersByOrganizationID(ctx, database.GetAgentStatUsersByOrganizationIDParams{
+		OrganizationID: organization.ID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	insights := codersdk.OrganizationInsights{
+		OrganizationID: organization.ID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Templates:      []codersdk.OrganizationTemplateInsights{},
+	}
+	templates := map[uuid.UUID]*codersdk.OrganizationTemplateInsights{}
+	templateInsights := func(templateID uuid.UUID) *codersdk.OrganizationTemplateInsights {
+		template, ok := templates[templateID]
+		if !ok {
+			template = &codersdk.OrganizationTemplateInsights{
+				TemplateID: templateID,
+			}
+			templates[templateID] = template
+		}
+		return template
+	}
+
+	// Workspaces can't be running after now, even if the range ends later.
+	runningUntil := endTime
+	if runningUntil.After(now) {
+		runningUntil = now
+	}
+	for i, build := range builds {
+		template := templateInsights(build.TemplateID)
+		failed := build.Error.Valid && build.Error.String != ""
+		// The first build of a workspace can be from before the range. It's
+		// only returned to know whether the workspace was running.
+		if !build.CreatedAt.Before(startTime) {
+			insights.Builds++
+			template.Builds++
+			if failed {
+				insights.FailedBuilds++
+				template.FailedBuilds++
+			}
+		}
+
+		// A workspace runs from the end of a successful start build until
+		// the next build of the workspace.
+		if build.Transition != database.WorkspaceTransitionStart || !build.CompletedAt.Valid || failed {
+			continue
+		}
+		from := build.CompletedAt.Time
+		if from.Before(startTime) {
+			from = startTime
+		}
+		until := runningUntil
+		if i+1 < len(builds) && builds[i+1].WorkspaceID == build.WorkspaceID && builds[i+1].CreatedAt.Before(until) {
+			until = builds[i+1].CreatedAt
+		}
+		if until.After(from) {
+			hours := until.Sub(from).Hours()
+			insights.WorkspaceHours += hours
+			template.WorkspaceHours += hours
+		}
+	}
+
+	activeUsers := map[uuid.UUID]struct{}{}
+	for _, user := range users {
+		activeUsers[user.UserID] = struct{}{}
+		templateInsights(user.TemplateID).ActiveUsers++
+	}
+	insights.ActiveUsers = int64(len(activeUsers))
+
+	if len(templates) > 0 {
+		templateIDs := make([]uuid.UUID, 0, len(templates))
+		for templateID := range templates {
+			templateIDs = append(templateIDs, templateID)
+		}
+		// Templates that were deleted in the meantime are still reported.
+		for _, deleted := range []bool{false, true} {
+			found, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+				Deleted:        deleted,
+				OrganizationID: organization.ID,
+				IDs:            templateIDs,
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				httpapi.InternalServerError(rw, err)
+				return
+			}
+			for _, template := range found {
+				templates[template.ID].TemplateName = template.Name
+			}
+		}
+		for _, template := range templates {
+			if template.Builds == 0 && template.ActiveUsers == 0 && template.WorkspaceHours == 0 {
+				continue
+			}
+			insights.Templates = append(insights.Templates, *template)
+		}
+		sort.Slice(insights.Templates, func(i, j int) bool {
+			return insights.Templates[i].TemplateName < insights.Templates[j].TemplateName
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, insights)
+}
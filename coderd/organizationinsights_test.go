@@ -0,0 +1,90 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationInsights(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Builds", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		insights, err := client.OrganizationInsights(ctx, user.OrganizationID, codersdk.OrganizationInsightsRequest{})
+		require.NoError(t, err)
+		require.Zero(t, insights.Builds)
+		require.Empty(t, insights.Templates)
+		require.Equal(t, 30*24*time.Hour, insights.EndTime.Sub(insights.StartTime))
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+
+		insights, err = client.OrganizationInsights(ctx, user.OrganizationID, codersdk.OrganizationInsightsRequest{})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, insights.Builds)
+		require.Zero(t, insights.FailedBuilds)
+		require.Len(t, insights.Templates, 1)
+		require.Equal(t, template.ID, insights.Templates[0].TemplateID)
+		require.Equal(t, template.Name, insights.Templates[0].TemplateName)
+		require.EqualValues(t, 2, insights.Templates[0].Builds)
+		require.Equal(t, insights.WorkspaceHours, insights.Templates[0].WorkspaceHours)
+
+		// Builds outside of the range aren't counted.
+		insights, err = client.OrganizationInsights(ctx, user.OrganizationID, codersdk.OrganizationInsightsRequest{
+			StartTime: time.Now().Add(-2 * time.Hour),
+			EndTime:   time.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Zero(t, insights.Builds)
+		require.Zero(t, insights.WorkspaceHours)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.OrganizationInsights(ctx, user.OrganizationID, codersdk.OrganizationInsightsRequest{
+			StartTime: time.Now(),
+			EndTime:   time.Now().Add(-time.Hour),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotRead", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.OrganizationInsights(ctx, user.OrganizationID, codersdk.OrganizationInsightsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,414 @@
+package coderd
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/userpassword"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+func (api *API) organizationInvites(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrganizationMember.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	invites, err := api.Database.GetOrganizationInvitesByOrganizationID(ctx, organization.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.OrganizationInvite, 0, len(invites))
+	for _, invite := range invites {
+		resp = append(resp, convertOrganizationInvite(invite))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) postOrganizationInvite(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+		apiKey       = httpmw.APIKey(r)
+		actorRoles   = httpmw.UserAuthorization(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrganizationMember.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateOrganizationInviteRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if req.Roles == nil {
+		req.Roles = []string{}
+	}
+	// Granting roles through an invite is the same as assigning them.
+	if len(req.Roles) > 0 && !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	for _, roleName := range req.Roles {
+		orgID, ok := rbac.IsOrgRole(roleName)
+		if !ok || orgID != organization.ID.String() {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Role %q isn't a role of the organization.", roleName),
+			})
+			return
+		}
+		if _, err := rbac.RoleByName(roleName); err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("%q is not a supported role.", roleName),
+			})
+			return
+		}
+		if !rbac.CanAssignRole(actorRoles.Roles, roleName) {
+			httpapi.Forbidden(rw)
+			return
+		}
+	}
+
+	expiresAt := sql.NullTime{}
+	if req.ExpiresAt != nil {
+		if req.ExpiresAt.Before(database.Now()) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Expiry must be in the future.",
+			})
+			return
+		}
+		expiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	token, err := cryptorand.String(32)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	hashed := sha256.Sum256([]byte(token))
+	invite, err := api.Database.InsertOrganizationInvite(ctx, database.InsertOrganizationInviteParams{
+		ID:             uuid.New(),
+		OrganizationID: organization.ID,
+		HashedToken:    hashed[:],
+		EmailDomain:    strings.ToLower(strings.TrimPrefix(req.EmailDomain, "@")),
+		Roles:          req.Roles,
+		CreatedBy:      apiKey.UserID,
+		CreatedAt:      database.Now(),
+		ExpiresAt:      expiresAt,
+		MaxUses:        req.MaxUses,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := convertOrganizationInvite(invite)
+	resp.Token = token
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+func (api *API) deleteOrganizationInvite(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+		inviteID     = chi.URLParam(r, "invite")
+	)
+
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrganizationMember.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	id, err := uuid.Parse(inviteID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invite ID %q must be a valid UUID.", inviteID),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	invite, err := api.Database.GetOrganizationInviteByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && invite.OrganizationID != organization.ID) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	err = api.Database.DeleteOrganizationInviteByID(ctx, invite.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Invite deleted.",
+	})
+}
+
+// postRedeemOrganizationInvite adds the authenticated user to the
+// organization of an invite, or creates a user in it if the request isn't
+// authenticated.
+func (api *API) postRedeemOrganizationInvite(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req codersdk.RedeemOrganizationInviteRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	hashed := sha256.Sum256([]byte(req.Token))
+	invite, err := api.Database.GetOrganizationInviteByHashedToken(ctx, hashed[:])
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "Invite not found.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if invite.ExpiresAt.Valid && invite.ExpiresAt.Time.Before(database.Now()) {
+		httpapi.Write(ctx, rw, http.StatusGone, codersdk.Response{
+			Message: "Invite has expired.",
+		})
+		return
+	}
+	if inviteUsedUp(invite) {
+		writeInviteUsedUp(ctx, rw)
+		return
+	}
+
+	apiKey, authenticated := httpmw.APIKeyOptional(r)
+	if !authenticated {
+		api.redeemOrganizationInviteAsNewUser(rw, r, req, invite)
+		return
+	}
+
+	user, err := api.Database.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if invite.EmailDomain != "" && !verifiedEmailLoginType(user.LoginType) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: fmt.Sprintf("Sign in with GitHub or OpenID Connect to redeem an invite limited to email addresses in %q.", invite.EmailDomain),
+		})
+		return
+	}
+	if !inviteAllowsEmail(invite, user.Email) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: fmt.Sprintf("Invite is limited to email addresses in %q.", invite.EmailDomain),
+		})
+		return
+	}
+	_, err = api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: invite.OrganizationID,
+		UserID:         user.ID,
+	})
+	if err == nil {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "You're already a member of the organization.",
+		})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	var member database.OrganizationMember
+	err = api.Database.InTx(func(tx database.Store) error {
+		_, err := tx.UseOrganizationInvite(ctx, invite.ID)
+		if err != nil {
+			return err
+		}
+		member, err = tx.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
+			OrganizationID: invite.OrganizationID,
+			UserID:         user.ID,
+			CreatedAt:      database.Now(),
+			UpdatedAt:      database.Now(),
+			Roles:          invite.Roles,
+		})
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeInviteUsedUp(ctx, rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishOrganizationEvent(invite.OrganizationID, codersdk.OrganizationWebhookEvent{
+		Type:         codersdk.OrganizationWebhookEventMemberAdded,
+		ResourceID:   user.ID,
+		ResourceName: user.Username,
+	})
+	api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionCreated, invite.OrganizationID, user.ID)
+	httpapi.Write(ctx, rw, http.StatusCreated, convertOrganizationMember(member))
+}
+
+func (api *API) redeemOrganizationInviteAsNewUser(rw http.ResponseWriter, r *http.Request, req codersdk.RedeemOrganizationInviteRequest, invite database.OrganizationInvite) {
+	ctx := r.Context()
+
+	if req.Email == "" || req.Username == "" || req.Password == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "An email, username, and password are required to sign up with an invite.",
+		})
+		return
+	}
+	// The email address of a new user isn't verified, so it can't be trusted
+	// to be in the domain.
+	if invite.EmailDomain != "" {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: fmt.Sprintf("Sign in with GitHub or OpenID Connect to redeem an invite limited to email addresses in %q.", invite.EmailDomain),
+		})
+		return
+	}
+	err := userpassword.Validate(req.Password)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Password not strong enough!",
+			Validations: []codersdk.ValidationError{{
+				Field:  "password",
+				Detail: err.Error(),
+			}},
+		})
+		return
+	}
+
+	_, err = api.Database.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+		Username: req.Username,
+		Email:    req.Email,
+	})
+	if err == nil {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "User already exists. Log in to redeem the invite.",
+		})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	var user database.User
+	err = api.Database.InTx(func(tx database.Store) error {
+		_, err := tx.UseOrganizationInvite(ctx, invite.ID)
+		if err != nil {
+			return err
+		}
+		user, _, err = api.CreateUser(ctx, tx, CreateUserRequest{
+			CreateUserRequest: codersdk.CreateUserRequest{
+				Email:          req.Email,
+				Username:       req.Username,
+				Password:       req.Password,
+				OrganizationID: invite.OrganizationID,
+			},
+			LoginType:         database.LoginTypePassword,
+			OrganizationRoles: invite.Roles,
+		})
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeInviteUsedUp(ctx, rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.PublishUserWebhookEvent(codersdk.WebhookEventUserCreated, user)
+
+	member, err := api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: invite.OrganizationID,
+		UserID:         user.ID,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishOrganizationEvent(invite.OrganizationID, codersdk.OrganizationWebhookEvent{
+		Type:         codersdk.OrganizationWebhookEventMemberAdded,
+		ResourceID:   user.ID,
+		ResourceName: user.Username,
+	})
+	api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionCreated, invite.OrganizationID, user.ID)
+	httpapi.Write(ctx, rw, http.StatusCreated, convertOrganizationMember(member))
+}
+
+// inviteUsedUp returns whether the invite was redeemed as many times as it
+// allows.
+func inviteUsedUp(invite database.OrganizationInvite) bool {
+	return invite.MaxUses > 0 && invite.Uses >= invite.MaxUses
+}
+
+func writeInviteUsedUp(ctx context.Context, rw http.ResponseWriter) {
+	httpapi.Write(ctx, rw, http.StatusGone, codersdk.Response{
+		Message: "Invite has no uses left.",
+	})
+}
+
+// verifiedEmailLoginType returns whether users of the login type have their
+// email address verified by an identity provider.
+func verifiedEmailLoginType(loginType database.LoginType) bool {
+	return loginType == database.LoginTypeGithub || loginType == database.LoginTypeOIDC
+}
+
+// inviteAllowsEmail returns whether the email address is in the domain the
+// invite is limited to.
+func inviteAllowsEmail(invite database.OrganizationInvite, email string) bool {
+	if invite.EmailDomain == "" {
+		return true
+	}
+	_, domain, ok := strings.Cut(email, "@")
+	return ok && strings.EqualFold(domain, invite.EmailDomain)
+}
+
+func convertOrganizationInvite(invite database.OrganizationInvite) codersdk.OrganizationInvite {
+	converted := codersdk.OrganizationInvite{
+		ID:             invite.ID,
+		OrganizationID: invite.OrganizationID,
+		EmailDomain:    invite.EmailDomain,
+		Roles:          invite.Roles,
+		CreatedBy:      invite.CreatedBy,
+		CreatedAt:      invite.CreatedAt,
+		MaxUses:        invite.MaxUses,
+		Uses:           invite.Uses,
+	}
+	if converted.Roles == nil {
+		converted.Roles = []string{}
+	}
+	if invite.ExpiresAt.Valid {
+		converted.ExpiresAt = &invite.ExpiresAt.Time
+	}
+	return converted
+}
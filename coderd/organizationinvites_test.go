@@ -0,0 +1,224 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationInvites(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ExistingUser", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		invite, err := client.CreateOrganizationInvite(ctx, org.ID, codersdk.CreateOrganizationInviteRequest{
+			Roles: []string{rbac.RoleOrgAdmin(org.ID)},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, invite.Token)
+
+		invites, err := client.OrganizationInvites(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, invites, 1)
+		require.Empty(t, invites[0].Token)
+
+		member, err := other.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token: invite.Token,
+		})
+		require.NoError(t, err)
+		require.Equal(t, org.ID, member.OrganizationID)
+		require.Len(t, member.Roles, 1)
+		require.Equal(t, rbac.RoleOrgAdmin(org.ID), member.Roles[0].Name)
+
+		_, err = other.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token: invite.Token,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("NewUser", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		invite, err := client.CreateOrganizationInvite(ctx, first.OrganizationID, codersdk.CreateOrganizationInviteRequest{})
+		require.NoError(t, err)
+
+		anonymous := codersdk.New(client.URL)
+		member, err := anonymous.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token:    invite.Token,
+			Email:    "someone@coder.com",
+			Username: "someone",
+			Password: "SomeSecurePassword!",
+		})
+		require.NoError(t, err)
+		require.Equal(t, first.OrganizationID, member.OrganizationID)
+
+		_, err = anonymous.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    "someone@coder.com",
+			Password: "SomeSecurePassword!",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("EmailDomainUnverified", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		invite, err := client.CreateOrganizationInvite(ctx, org.ID, codersdk.CreateOrganizationInviteRequest{
+			EmailDomain: "coder.com",
+		})
+		require.NoError(t, err)
+
+		// Anyone can sign up with an address in the domain, so new users
+		// can't redeem the invite.
+		anonymous := codersdk.New(client.URL)
+		_, err = anonymous.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token:    invite.Token,
+			Email:    "someone@coder.com",
+			Username: "someone",
+			Password: "SomeSecurePassword!",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		// Nor can users that signed in with a password.
+		_, err = other.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token: invite.Token,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("MaxUses", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		invite, err := client.CreateOrganizationInvite(ctx, org.ID, codersdk.CreateOrganizationInviteRequest{
+			MaxUses: 1,
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, invite.MaxUses)
+
+		_, err = other.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token: invite.Token,
+		})
+		require.NoError(t, err)
+		invites, err := client.OrganizationInvites(ctx, org.ID)
+		require.NoError(t, err)
+		require.Len(t, invites, 1)
+		require.EqualValues(t, 1, invites[0].Uses)
+
+		anonymous := codersdk.New(client.URL)
+		_, err = anonymous.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token:    invite.Token,
+			Email:    "someone@coder.com",
+			Username: "someone",
+			Password: "SomeSecurePassword!",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusGone, apiErr.StatusCode())
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		past := time.Now().Add(-time.Hour)
+		_, err := client.CreateOrganizationInvite(ctx, first.OrganizationID, codersdk.CreateOrganizationInviteRequest{
+			ExpiresAt: &past,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		invite, err := client.CreateOrganizationInvite(ctx, org.ID, codersdk.CreateOrganizationInviteRequest{})
+		require.NoError(t, err)
+		err = client.DeleteOrganizationInvite(ctx, org.ID, invite.ID)
+		require.NoError(t, err)
+
+		_, err = other.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token: invite.Token,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotInvite", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := other.CreateOrganizationInvite(ctx, first.OrganizationID, codersdk.CreateOrganizationInviteRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
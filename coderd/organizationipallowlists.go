@@ -0,0 +1,301 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tabbed/pqtype"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) organizationIPAllowlist(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	allowlist, err := api.Database.GetOrganizationIPAllowlist(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.OrganizationIPAllowlist{
+			OrganizationID: organization.ID,
+			CIDRs:          []string{},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationIPAllowlist(allowlist))
+}
+
+func (api *API) putOrganizationIPAllowlist(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateOrganizationIPAllowlistRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	cidrs := make([]string, 0, len(req.CIDRs))
+	networks := make([]*net.IPNet, 0, len(req.CIDRs))
+	for i, cidr := range req.CIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  fmt.Sprintf("cidrs[%d]", i),
+				Detail: fmt.Sprintf("%q is not a valid CIDR, e.g. \"10.0.0.0/8\".", cidr),
+			})
+			continue
+		}
+		cidrs = append(cidrs, network.String())
+		networks = append(networks, network)
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid IP allowlist.",
+			Validations: validErrs,
+			Code:        codersdk.ErrorCodeValidationFailed,
+		})
+		return
+	}
+	// Admins manage the allowlist through the API it restricts, so they'd
+	// be locked out by a list that doesn't include their own network.
+	if len(networks) > 0 && !ipAllowed(requestIP(r), networks) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The IP allowlist must include the network of this request.",
+			Detail:  fmt.Sprintf("Your IP address is %s.", requestIP(r)),
+			Code:    codersdk.ErrorCodeValidationFailed,
+		})
+		return
+	}
+
+	allowlist, err := api.Database.UpsertOrganizationIPAllowlist(ctx, database.UpsertOrganizationIPAllowlistParams{
+		OrganizationID: organization.ID,
+		Cidrs:          cidrs,
+		UpdatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationIPAllowlist(allowlist))
+}
+
+// EnforceOrganizationIPAllowlist rejects requests from outside the IP
+// allowlist of the organization the request belongs to. organizationID is
+// called after the middleware that extracts the resource of the request.
+// Rejected requests are audited.
+func (api *API) EnforceOrganizationIPAllowlist(organizationID func(r *http.Request) uuid.UUID) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if !api.checkOrganizationIPAllowed(rw, r, organizationID(r)) {
+				return
+			}
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// checkOrganizationIPAllowed writes an error and returns false if the IP
+// address of the request isn't in the IP allowlist of the organization. It's
+// for handlers that find the organization of the request themselves.
+func (api *API) checkOrganizationIPAllowed(rw http.ResponseWriter, r *http.Request, organizationID uuid.UUID) bool {
+	ctx := r.Context()
+	allowed, err := api.organizationIPAllowed(r, organizationID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return false
+	}
+	if allowed {
+		return true
+	}
+
+	ip := requestIP(r)
+	api.auditIPAllowlistDenial(ctx, r, organizationID, ip)
+	httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+		Message: "Your IP address isn't allowed to access this organization.",
+		Detail:  fmt.Sprintf("The IP allowlist of the organization doesn't include %s.", ip),
+		Code:    codersdk.ErrorCodeIPNotAllowed,
+	})
+	return false
+}
+
+// filterIPAllowedWorkspaces removes the workspaces of organizations whose IP
+// allowlist doesn't include the IP address of the request. Lists are fetched
+// often, so the workspaces left out aren't audited.
+func (api *API) filterIPAllowedWorkspaces(r *http.Request, workspaces []database.Workspace) ([]database.Workspace, error) {
+	allowedByOrg := make(map[uuid.UUID]bool)
+	filtered := make([]database.Workspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		allowed, ok := allowedByOrg[workspace.OrganizationID]
+		if !ok {
+			var err error
+			allowed, err = api.organizationIPAllowed(r, workspace.OrganizationID)
+			if err != nil {
+				return nil, err
+			}
+			allowedByOrg[workspace.OrganizationID] = allowed
+		}
+		if allowed {
+			filtered = append(filtered, workspace)
+		}
+	}
+	return filtered, nil
+}
+
+// organizationIPAllowed returns whether the IP address of the request is in
+// the IP allowlist of the organization. Organizations without an allowlist
+// allow all addresses.
+func (api *API) organizationIPAllowed(r *http.Request, organizationID uuid.UUID) (bool, error) {
+	allowlist, err := api.Database.GetOrganizationIPAllowlist(r.Context(), organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	networks := make([]*net.IPNet, 0, len(allowlist.Cidrs))
+	for _, cidr := range allowlist.Cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			// CIDRs are validated when they're stored.
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return len(networks) == 0 || ipAllowed(requestIP(r), networks), nil
+}
+
+// auditIPAllowlistDenial exports an audit log for a request that was
+// rejected by the IP allowlist of an organization. The request may not be
+// authenticated, e.g. for workspace apps.
+func (api *API) auditIPAllowlistDenial(ctx context.Context, r *http.Request, organizationID uuid.UUID, ip net.IP) {
+	organization, err := api.OrganizationCache.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		api.Logger.Warn(ctx, "get organization for ip allowlist audit", slog.Error(err))
+		organization = database.Organization{ID: organizationID}
+	}
+	additionalFields, err := json.Marshal(map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "marshal ip allowlist audit fields", slog.Error(err))
+		additionalFields = []byte("{}")
+	}
+	var userID uuid.UUID
+	if key, ok := httpmw.APIKeyOptional(r); ok {
+		userID = key.UserID
+	}
+	inet := pqtype.Inet{}
+	if ip != nil {
+		inet = pqtype.Inet{
+			IPNet: net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)},
+			Valid: true,
+		}
+	}
+
+	// The request is rejected regardless of whether the audit log is
+	// exported, so it doesn't use the context of the request.
+	err = (*api.Auditor.Load()).Export(context.Background(), database.AuditLog{
+		ID:               uuid.New(),
+		Time:             database.Now(),
+		UserID:           userID,
+		OrganizationID:   organization.ID,
+		Ip:               inet,
+		UserAgent:        r.UserAgent(),
+		ResourceType:     database.ResourceTypeOrganization,
+		ResourceID:       organization.ID,
+		ResourceTarget:   organization.Name,
+		Action:           database.AuditActionDeny,
+		Diff:             []byte("{}"),
+		StatusCode:       http.StatusForbidden,
+		RequestID:        httpmw.RequestID(r),
+		AdditionalFields: additionalFields,
+	})
+	if err != nil {
+		api.Logger.Error(ctx, "export ip allowlist audit log", slog.Error(err))
+	}
+}
+
+// requestIP returns the IP address the request was sent from, or nil if it
+// can't be parsed.
+func requestIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+func ipAllowed(ip net.IP, networks []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func convertOrganizationIPAllowlist(allowlist database.OrganizationIpAllowlist) codersdk.OrganizationIPAllowlist {
+	cidrs := allowlist.Cidrs
+	if cidrs == nil {
+		cidrs = []string{}
+	}
+	return codersdk.OrganizationIPAllowlist{
+		OrganizationID: allowlist.OrganizationID,
+		CIDRs:          cidrs,
+		UpdatedAt:      allowlist.UpdatedAt,
+	}
+}
+
+// organizationIDFromParam, templateOrganizationID,
+// templateVersionOrganizationID and workspaceOrganizationID return the
+// organization of the resource extracted from the URL, for
+// EnforceOrganizationIPAllowlist.
+func organizationIDFromParam(r *http.Request) uuid.UUID {
+	return httpmw.OrganizationParam(r).ID
+}
+
+func templateOrganizationID(r *http.Request) uuid.UUID {
+	return httpmw.TemplateParam(r).OrganizationID
+}
+
+func templateVersionOrganizationID(r *http.Request) uuid.UUID {
+	return httpmw.TemplateVersionParam(r).OrganizationID
+}
+
+func workspaceOrganizationID(r *http.Request) uuid.UUID {
+	return httpmw.WorkspaceParam(r).OrganizationID
+}
@@ -0,0 +1,164 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationIPAllowlist(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		allowlist, err := client.OrganizationIPAllowlist(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, allowlist.CIDRs)
+
+		_, err = client.UpdateOrganizationIPAllowlist(ctx, user.OrganizationID, codersdk.UpdateOrganizationIPAllowlistRequest{
+			CIDRs: []string{"10.0.0.0/8", "localhost"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "cidrs[1]", apiErr.Validations[0].Field)
+
+		// Test clients connect from the loopback address, so a list without
+		// it would lock them out.
+		_, err = client.UpdateOrganizationIPAllowlist(ctx, user.OrganizationID, codersdk.UpdateOrganizationIPAllowlistRequest{
+			CIDRs: []string{"10.0.0.0/8"},
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		allowlist, err = client.UpdateOrganizationIPAllowlist(ctx, user.OrganizationID, codersdk.UpdateOrganizationIPAllowlistRequest{
+			CIDRs: []string{"10.0.0.0/8", "127.0.0.1/8"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"10.0.0.0/8", "127.0.0.0/8"}, allowlist.CIDRs)
+
+		// Requests from allowed networks succeed.
+		_, err = client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Enforced", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		// The API doesn't allow lists that exclude the client.
+		_, err := api.Database.UpsertOrganizationIPAllowlist(ctx, database.UpsertOrganizationIPAllowlistParams{
+			OrganizationID: user.OrganizationID,
+			Cidrs:          []string{"10.0.0.0/8"},
+			UpdatedAt:      database.Now(),
+		})
+		require.NoError(t, err)
+
+		_, err = client.Organization(ctx, user.OrganizationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeIPNotAllowed, apiErr.Code)
+
+		require.NotEmpty(t, auditor.AuditLogs)
+		denial := auditor.AuditLogs[len(auditor.AuditLogs)-1]
+		require.Equal(t, database.AuditActionDeny, denial.Action)
+		require.Equal(t, user.OrganizationID, denial.ResourceID)
+		require.Equal(t, user.UserID, denial.UserID)
+		require.EqualValues(t, http.StatusForbidden, denial.StatusCode)
+
+		// The allowlist itself stays available, so it can be fixed.
+		_, err = client.UpdateOrganizationIPAllowlist(ctx, user.OrganizationID, codersdk.UpdateOrganizationIPAllowlistRequest{
+			CIDRs: []string{},
+		})
+		require.NoError(t, err)
+		_, err = client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Workspaces", func(t *testing.T) {
+		t.Parallel()
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id:   uuid.NewString(),
+								Name: "example",
+								Auth: &proto.Agent_Token{
+									Token: uuid.NewString(),
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		build, err := client.WorkspaceBuild(ctx, workspace.LatestBuild.ID)
+		require.NoError(t, err)
+		require.Len(t, build.Resources, 1)
+		require.Len(t, build.Resources[0].Agents, 1)
+		agentID := build.Resources[0].Agents[0].ID
+
+		_, err = api.Database.UpsertOrganizationIPAllowlist(ctx, database.UpsertOrganizationIPAllowlistParams{
+			OrganizationID: user.OrganizationID,
+			Cidrs:          []string{"10.0.0.0/8"},
+			UpdatedAt:      database.Now(),
+		})
+		require.NoError(t, err)
+
+		requireDenied := func(err error) {
+			t.Helper()
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+			require.Equal(t, codersdk.ErrorCodeIPNotAllowed, apiErr.Code)
+		}
+
+		// Resources that are found by something other than the organization
+		// are denied too.
+		_, err = client.WorkspaceAgentReconnectingPTY(ctx, agentID, uuid.New(), 80, 80, "/bin/bash")
+		requireDenied(err)
+		_, err = client.WorkspaceAgentListeningPorts(ctx, agentID)
+		requireDenied(err)
+		_, err = client.TemplateVersion(ctx, version.ID)
+		requireDenied(err)
+		_, err = client.WorkspaceByOwnerAndName(ctx, codersdk.Me, workspace.Name, codersdk.WorkspaceOptions{})
+		requireDenied(err)
+
+		// Lists leave out the workspaces of the organization.
+		workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Empty(t, workspaces)
+	})
+}
@@ -0,0 +1,296 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) organizationOIDCConfig(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	// The config is only visible to those that can change it.
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	config, err := api.Database.GetOrganizationOIDCConfigByOrganizationID(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationOIDCConfig(config))
+}
+
+func (api *API) putOrganizationOIDCConfig(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateOrganizationOIDCConfigRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	existing, err := api.Database.GetOrganizationOIDCConfigByOrganizationID(ctx, organization.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if req.ClientSecret == "" {
+		if existing.ClientSecret == "" {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "A client secret is required.",
+			})
+			return
+		}
+		req.ClientSecret = existing.ClientSecret
+	}
+	if req.UsernameField == "" {
+		req.UsernameField = "preferred_username"
+	}
+	if req.EmailField == "" {
+		req.EmailField = "email"
+	}
+	if req.Scopes == nil {
+		req.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+
+	emailDomains := make([]string, 0, len(req.EmailDomains))
+	for _, domain := range req.EmailDomains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "@"))
+		if domain == "" {
+			continue
+		}
+		// Logins are routed by email domain, so a domain can only belong to
+		// one organization.
+		other, err := api.Database.GetOrganizationOIDCConfigByEmailDomain(ctx, domain)
+		if err == nil && other.OrganizationID != organization.ID {
+			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+				Message: fmt.Sprintf("The email domain %q is used by another organization.", domain),
+			})
+			return
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		emailDomains = append(emailDomains, domain)
+	}
+
+	config, err := api.Database.UpsertOrganizationOIDCConfig(ctx, database.UpsertOrganizationOIDCConfigParams{
+		OrganizationID: organization.ID,
+		IssuerURL:      req.IssuerURL,
+		ClientID:       req.ClientID,
+		ClientSecret:   req.ClientSecret,
+		Scopes:         req.Scopes,
+		EmailDomains:   emailDomains,
+		UsernameField:  req.UsernameField,
+		EmailField:     req.EmailField,
+		AllowSignups:   req.AllowSignups,
+		CreatedAt:      database.Now(),
+		UpdatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationOIDCConfig(config))
+}
+
+func (api *API) deleteOrganizationOIDCConfig(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteOrganizationOIDCConfigByOrganizationID(ctx, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "OIDC config deleted.",
+	})
+}
+
+// oidcLoginRoute returns where a user signs in with OIDC, based on the
+// domain of their email address.
+func (api *API) oidcLoginRoute(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	email := r.URL.Query().Get("email")
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok || domain == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "A valid email address is required.",
+		})
+		return
+	}
+
+	config, err := api.Database.GetOrganizationOIDCConfigByEmailDomain(ctx, domain)
+	if err == nil {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.OIDCLoginRoute{
+			OrganizationID: config.OrganizationID,
+			URL:            fmt.Sprintf("/api/v2/users/oidc/%s/callback", config.OrganizationID),
+		})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if api.OIDCConfig == nil {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: fmt.Sprintf("OIDC isn't configured for %q.", domain),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OIDCLoginRoute{
+		URL: "/api/v2/users/oidc/callback",
+	})
+}
+
+// userOrganizationOIDC signs in users with the identity provider of the
+// organization.
+func (api *API) userOrganizationOIDC(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	config, err := api.Database.GetOrganizationOIDCConfigByOrganizationID(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusPreconditionRequired, codersdk.Response{
+			Message: "OIDC isn't configured for the organization.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	oidcConfig, err := api.cachedOrganizationOIDC(ctx, config)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadGateway, codersdk.Response{
+			Message: "Failed to configure the identity provider of the organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpmw.ExtractOAuth2(oidcConfig)(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		api.oidcLogin(rw, r, oidcConfig, organization.ID)
+	})).ServeHTTP(rw, r)
+}
+
+type organizationOIDCEntry struct {
+	updatedAt time.Time
+	config    *OIDCConfig
+}
+
+// cachedOrganizationOIDC returns the OIDC config of an organization. The
+// provider is only discovered again after the config changes.
+func (api *API) cachedOrganizationOIDC(ctx context.Context, config database.OrganizationOIDCConfig) (*OIDCConfig, error) {
+	api.organizationOIDCMutex.Lock()
+	defer api.organizationOIDCMutex.Unlock()
+
+	entry, ok := api.organizationOIDC[config.OrganizationID]
+	if ok && entry.updatedAt.Equal(config.UpdatedAt) {
+		return entry.config, nil
+	}
+	oidcConfig, err := api.OrganizationOIDCProvider(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	api.organizationOIDC[config.OrganizationID] = organizationOIDCEntry{
+		updatedAt: config.UpdatedAt,
+		config:    oidcConfig,
+	}
+	return oidcConfig, nil
+}
+
+// discoverOrganizationOIDC returns a provider that discovers the endpoints
+// of an organization's identity provider from its issuer.
+func discoverOrganizationOIDC(accessURL *url.URL) func(ctx context.Context, config database.OrganizationOIDCConfig) (*OIDCConfig, error) {
+	return func(ctx context.Context, config database.OrganizationOIDCConfig) (*OIDCConfig, error) {
+		provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+		if err != nil {
+			return nil, xerrors.Errorf("discover provider: %w", err)
+		}
+		redirectURL, err := accessURL.Parse(fmt.Sprintf("/api/v2/users/oidc/%s/callback", config.OrganizationID))
+		if err != nil {
+			return nil, xerrors.Errorf("parse callback url: %w", err)
+		}
+		return &OIDCConfig{
+			OAuth2Config: &oauth2.Config{
+				ClientID:     config.ClientID,
+				ClientSecret: config.ClientSecret,
+				RedirectURL:  redirectURL.String(),
+				Endpoint:     provider.Endpoint(),
+				Scopes:       config.Scopes,
+			},
+			Verifier: provider.Verifier(&oidc.Config{
+				ClientID: config.ClientID,
+			}),
+			AllowSignups:  config.AllowSignups,
+			EmailField:    config.EmailField,
+			UsernameField: config.UsernameField,
+		}, nil
+	}
+}
+
+func convertOrganizationOIDCConfig(config database.OrganizationOIDCConfig) codersdk.OrganizationOIDCConfig {
+	converted := codersdk.OrganizationOIDCConfig{
+		OrganizationID: config.OrganizationID,
+		IssuerURL:      config.IssuerURL,
+		ClientID:       config.ClientID,
+		Scopes:         config.Scopes,
+		EmailDomains:   config.EmailDomains,
+		UsernameField:  config.UsernameField,
+		EmailField:     config.EmailField,
+		AllowSignups:   config.AllowSignups,
+		CreatedAt:      config.CreatedAt,
+		UpdatedAt:      config.UpdatedAt,
+	}
+	if converted.Scopes == nil {
+		converted.Scopes = []string{}
+	}
+	if converted.EmailDomains == nil {
+		converted.EmailDomains = []string{}
+	}
+	return converted
+}
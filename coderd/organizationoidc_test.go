@@ -0,0 +1,204 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationOIDCConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		req := codersdk.UpdateOrganizationOIDCConfigRequest{
+			IssuerURL:    "https://idp.example.com",
+			ClientID:     "client",
+			EmailDomains: []string{"@Example.com"},
+		}
+		_, err := client.UpdateOrganizationOIDCConfig(ctx, user.OrganizationID, req)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		req.ClientSecret = "secret"
+		config, err := client.UpdateOrganizationOIDCConfig(ctx, user.OrganizationID, req)
+		require.NoError(t, err)
+		require.Equal(t, []string{"example.com"}, config.EmailDomains)
+		require.Equal(t, "preferred_username", config.UsernameField)
+
+		// The secret is kept when it's omitted.
+		req.ClientSecret = ""
+		req.AllowSignups = true
+		config, err = client.UpdateOrganizationOIDCConfig(ctx, user.OrganizationID, req)
+		require.NoError(t, err)
+		require.True(t, config.AllowSignups)
+
+		config, err = client.OrganizationOIDCConfig(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, "client", config.ClientID)
+
+		err = client.DeleteOrganizationOIDCConfig(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		_, err = client.OrganizationOIDCConfig(ctx, user.OrganizationID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("DomainConflict", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		req := codersdk.UpdateOrganizationOIDCConfigRequest{
+			IssuerURL:    "https://idp.example.com",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			EmailDomains: []string{"example.com"},
+		}
+		_, err := client.UpdateOrganizationOIDCConfig(ctx, user.OrganizationID, req)
+		require.NoError(t, err)
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateOrganizationOIDCConfig(ctx, org.ID, req)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotRead", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateOrganizationOIDCConfig(ctx, user.OrganizationID, codersdk.UpdateOrganizationOIDCConfigRequest{
+			IssuerURL:    "https://idp.example.com",
+			ClientID:     "client",
+			ClientSecret: "secret",
+		})
+		require.NoError(t, err)
+
+		_, err = member.OrganizationOIDCConfig(ctx, user.OrganizationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestOrganizationOIDCLogin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Route", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateOrganizationOIDCConfig(ctx, user.OrganizationID, codersdk.UpdateOrganizationOIDCConfigRequest{
+			IssuerURL:    "https://idp.example.com",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			EmailDomains: []string{"example.com"},
+		})
+		require.NoError(t, err)
+
+		route, err := client.OIDCLoginRoute(ctx, "someone@EXAMPLE.com")
+		require.NoError(t, err)
+		require.Equal(t, user.OrganizationID, route.OrganizationID)
+		require.Equal(t, "/api/v2/users/oidc/"+user.OrganizationID.String()+"/callback", route.URL)
+
+		// Other domains have nowhere to go without deployment-wide OIDC.
+		_, err = client.OIDCLoginRoute(ctx, "someone@coder.com")
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Signup", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			OrganizationOIDCProvider: func(_ context.Context, config database.OrganizationOIDCConfig) (*coderd.OIDCConfig, error) {
+				oidcConfig := createOIDCConfig(t, jwt.MapClaims{
+					"mail":  "someone@tenant.com",
+					"login": "someone",
+				})
+				oidcConfig.AllowSignups = config.AllowSignups
+				oidcConfig.EmailField = config.EmailField
+				oidcConfig.UsernameField = config.UsernameField
+				return oidcConfig, nil
+			},
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "tenant",
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateOrganizationOIDCConfig(ctx, org.ID, codersdk.UpdateOrganizationOIDCConfigRequest{
+			IssuerURL:     "https://idp.tenant.com",
+			ClientID:      "client",
+			ClientSecret:  "secret",
+			EmailDomains:  []string{"tenant.com"},
+			EmailField:    "mail",
+			UsernameField: "login",
+			AllowSignups:  true,
+		})
+		require.NoError(t, err)
+
+		route, err := client.OIDCLoginRoute(ctx, "someone@tenant.com")
+		require.NoError(t, err)
+
+		anonymous := codersdk.New(client.URL)
+		resp := oidcCallbackPath(t, anonymous, route.URL)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		res, err := client.OrganizationMembers(ctx, org.ID, codersdk.OrganizationMembersRequest{})
+		require.NoError(t, err)
+		var joined bool
+		for _, member := range res.Members {
+			if member.Username == "someone" {
+				joined = member.Email == "someone@tenant.com"
+			}
+		}
+		require.True(t, joined, "user should join the organization")
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		resp := oidcCallbackPath(t, client, "/api/v2/users/oidc/"+user.OrganizationID.String()+"/callback")
+		require.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
+	})
+}
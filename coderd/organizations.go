@@ -26,7 +26,7 @@ func (api *API) organization(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, convertOrganization(organization))
+	httpapi.WriteWithETag(ctx, rw, r, convertOrganization(organization))
 }
 
 func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
@@ -70,6 +70,12 @@ func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			return xerrors.Errorf("create organization: %w", err)
 		}
+		// The name may have belonged to a renamed organization, which
+		// stops resolving by it.
+		err = tx.DeleteOrganizationAliasByName(ctx, organization.Name)
+		if err != nil {
+			return xerrors.Errorf("delete organization alias: %w", err)
+		}
 		_, err = tx.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
 			OrganizationID: organization.ID,
 			UserID:         apiKey.UserID,
@@ -96,16 +102,191 @@ func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionCreated, organization.ID, apiKey.UserID)
 
 	httpapi.Write(ctx, rw, http.StatusCreated, convertOrganization(organization))
 }
 
+func (api *API) patchOrganization(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organization := httpmw.OrganizationParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.
+		InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateOrganizationRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !httpapi.CheckIfMatch(ctx, rw, r, convertOrganization(organization)) {
+		return
+	}
+
+	params := database.UpdateOrganizationByIDParams{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		DisplayName: organization.DisplayName,
+		Icon:        organization.Icon,
+		Description: organization.Description,
+		UpdatedAt:   database.Now(),
+	}
+	if req.DisplayName != nil {
+		params.DisplayName = *req.DisplayName
+	}
+	if req.Icon != nil {
+		params.Icon = *req.Icon
+	}
+	if req.Description != nil {
+		params.Description = *req.Description
+	}
+	renamed := req.Name != nil && *req.Name != organization.Name
+	if renamed {
+		params.Name = *req.Name
+		existing, err := api.Database.GetOrganizationByName(ctx, params.Name)
+		if err == nil && existing.ID != organization.ID {
+			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+				Message: "Organization already exists with that name.",
+			})
+			return
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: fmt.Sprintf("Internal error fetching organization %q.", params.Name),
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	oldName := organization.Name
+	err := api.Database.InTx(func(tx database.Store) error {
+		var err error
+		organization, err = tx.UpdateOrganizationByID(ctx, params)
+		if err != nil {
+			return xerrors.Errorf("update organization: %w", err)
+		}
+		if !renamed {
+			return nil
+		}
+		// Keep the old name resolving to the organization, so existing
+		// URLs and scripts continue to work.
+		for _, name := range []string{oldName, organization.Name} {
+			err = tx.DeleteOrganizationAliasByName(ctx, name)
+			if err != nil {
+				return xerrors.Errorf("delete organization alias: %w", err)
+			}
+		}
+		_, err = tx.InsertOrganizationAlias(ctx, database.InsertOrganizationAliasParams{
+			Name:           oldName,
+			OrganizationID: organization.ID,
+			CreatedAt:      database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("insert organization alias: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.OrganizationCache.Invalidate(ctx)
+
+	httpapi.WriteWithETag(ctx, rw, r, convertOrganization(organization))
+}
+
+// deleteOrganization starts deleting the organization in the background,
+// since organizations can have too many workspaces, templates, and members
+// to delete them in a single request.
+func (api *API) deleteOrganization(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organization := httpmw.OrganizationParam(r)
+	apiKey := httpmw.APIKey(r)
+	// Like creating, deleting an organization needs the site wide
+	// permission, so organization admins can't remove their own.
+	if !api.Authorize(r, rbac.ActionDelete, rbac.ResourceOrganization) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	if organization.IsDefault {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The default organization cannot be deleted.",
+		})
+		return
+	}
+
+	var (
+		deletion database.OrganizationDeletion
+		started  bool
+	)
+	err := api.Database.InTx(func(db database.Store) error {
+		now := database.Now()
+		operationID := uuid.New()
+		var err error
+		deletion, err = db.InsertOrganizationDeletion(ctx, database.InsertOrganizationDeletionParams{
+			OrganizationID: organization.ID,
+			InitiatorID:    apiKey.UserID,
+			CreatedAt:      now,
+			OperationID:    uuid.NullUUID{UUID: operationID, Valid: true},
+			WorkerID:       uuid.NullUUID{UUID: api.organizationDeletionsWorkerID, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// The organization is already being deleted.
+			deletion, err = db.GetOrganizationDeletionByOrganizationID(ctx, organization.ID)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		_, err = db.InsertOperation(ctx, database.InsertOperationParams{
+			ID:             operationID,
+			Type:           string(codersdk.OperationTypeOrganizationDeletion),
+			InitiatorID:    apiKey.UserID,
+			OrganizationID: uuid.NullUUID{UUID: organization.ID, Valid: true},
+			ResourceID:     organization.ID,
+			CreatedAt:      now,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert operation: %w", err)
+		}
+		started = true
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if started {
+		api.startOrganizationDeletion(deletion)
+	}
+
+	if deletion.OperationID.Valid {
+		writeOperationAccepted(ctx, rw, deletion.OperationID.UUID, convertOrganizationDeletion(deletion))
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusAccepted, convertOrganizationDeletion(deletion))
+}
+
 // convertOrganization consumes the database representation and outputs an API friendly representation.
 func convertOrganization(organization database.Organization) codersdk.Organization {
 	return codersdk.Organization{
-		ID:        organization.ID,
-		Name:      organization.Name,
-		CreatedAt: organization.CreatedAt,
-		UpdatedAt: organization.UpdatedAt,
+		ID:          organization.ID,
+		Name:        organization.Name,
+		CreatedAt:   organization.CreatedAt,
+		UpdatedAt:   organization.UpdatedAt,
+		IsDefault:   organization.IsDefault,
+		DisplayName: organization.DisplayName,
+		Icon:        organization.Icon,
+		Description: organization.Description,
 	}
 }
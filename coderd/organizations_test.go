@@ -4,10 +4,16 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
@@ -110,3 +116,288 @@ func TestPostOrganizationsByUser(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestDeleteOrganization(t *testing.T) {
+	t.Parallel()
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.Organization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.True(t, org.IsDefault)
+		_, err = client.DeleteOrganization(ctx, org.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Workspaces", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:         true,
+			OrganizationDeletionPollInterval: testutil.IntervalFast,
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "new",
+		})
+		require.NoError(t, err)
+		require.False(t, org.IsDefault)
+		version := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, org.ID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, org.ID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		deletion, err := client.DeleteOrganization(ctx, org.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.OrganizationDeletionStatusRunning, deletion.Status)
+
+		require.Eventually(t, func() bool {
+			deletion, err = client.OrganizationDeletionStatus(ctx, org.ID)
+			return assert.NoError(t, err) && deletion.Status != codersdk.OrganizationDeletionStatusRunning
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.OrganizationDeletionStatusSucceeded, deletion.Status)
+		require.EqualValues(t, 1, deletion.WorkspacesTotal)
+		require.EqualValues(t, 1, deletion.WorkspacesDeleted)
+		require.EqualValues(t, 1, deletion.TemplatesDeleted)
+		require.EqualValues(t, 1, deletion.MembersDeleted)
+		require.NotNil(t, deletion.CompletedAt)
+
+		_, err = client.Organization(ctx, org.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Replicas", func(t *testing.T) {
+		t.Parallel()
+		db, pubsub := dbtestutil.NewDB(t)
+		client := coderdtest.New(t, &coderdtest.Options{
+			Database:                         db,
+			Pubsub:                           pubsub,
+			IncludeProvisionerDaemon:         true,
+			OrganizationDeletionPollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "new",
+		})
+		require.NoError(t, err)
+		version := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		_ = coderdtest.CreateTemplate(t, client, org.ID, version.ID)
+		_ = coderdtest.CreateAnotherUser(t, client, org.ID)
+
+		// The deletion was started by a replica that stopped, so both
+		// replicas try to resume it.
+		_ = coderdtest.New(t, &coderdtest.Options{
+			Database:                         db,
+			Pubsub:                           pubsub,
+			OrganizationDeletionPollInterval: testutil.IntervalFast,
+		})
+		_, err = db.InsertOrganizationDeletion(ctx, database.InsertOrganizationDeletionParams{
+			OrganizationID: org.ID,
+			InitiatorID:    user.UserID,
+			CreatedAt:      database.Now().Add(-time.Hour),
+			WorkerID:       uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		})
+		require.NoError(t, err)
+
+		var deletion codersdk.OrganizationDeletion
+		require.Eventually(t, func() bool {
+			deletion, err = client.OrganizationDeletionStatus(ctx, org.ID)
+			return assert.NoError(t, err) && deletion.Status != codersdk.OrganizationDeletionStatusRunning
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.OrganizationDeletionStatusSucceeded, deletion.Status)
+		require.EqualValues(t, 1, deletion.TemplatesTotal)
+		require.EqualValues(t, 1, deletion.TemplatesDeleted)
+		require.EqualValues(t, 2, deletion.MembersTotal)
+		require.EqualValues(t, 2, deletion.MembersDeleted)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "new",
+		})
+		require.NoError(t, err)
+		orgs, err := client.OrganizationsByUser(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, orgs, 2)
+
+		// Organizations that aren't being deleted don't have a status.
+		_, err = client.OrganizationDeletionStatus(ctx, org.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		_, err = client.DeleteOrganization(ctx, org.ID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			deletion, err := client.OrganizationDeletionStatus(ctx, org.ID)
+			return assert.NoError(t, err) && deletion.Status == codersdk.OrganizationDeletionStatusSucceeded
+		}, testutil.WaitLong, testutil.IntervalFast)
+		orgs, err = client.OrganizationsByUser(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+	})
+}
+
+func TestPatchOrganization(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	org, err := client.UpdateOrganization(ctx, user.OrganizationID, codersdk.UpdateOrganizationRequest{
+		DisplayName: ptr.Ref("Acme Corp"),
+		Icon:        ptr.Ref("/emojis/1f3e2.png"),
+		Description: ptr.Ref("Everything Acme builds."),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Acme Corp", org.DisplayName)
+	require.Equal(t, "/emojis/1f3e2.png", org.Icon)
+	require.Equal(t, "Everything Acme builds.", org.Description)
+
+	// Omitted fields are left unchanged.
+	org, err = client.UpdateOrganization(ctx, user.OrganizationID, codersdk.UpdateOrganizationRequest{
+		Icon: ptr.Ref(""),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Acme Corp", org.DisplayName)
+	require.Empty(t, org.Icon)
+
+	org, err = client.Organization(ctx, user.OrganizationID)
+	require.NoError(t, err)
+	require.Equal(t, "Acme Corp", org.DisplayName)
+	require.Equal(t, "Everything Acme builds.", org.Description)
+
+	// Edits made with the ETag of an outdated read conflict.
+	res, err := client.Request(ctx, http.MethodGet, "/api/v2/organizations/"+user.OrganizationID.String(), nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	etag := res.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	_, err = client.UpdateOrganization(ctx, user.OrganizationID, codersdk.UpdateOrganizationRequest{
+		DisplayName: ptr.Ref("Acme Inc"),
+	})
+	require.NoError(t, err)
+	res, err = client.Request(ctx, http.MethodPatch, "/api/v2/organizations/"+user.OrganizationID.String(), codersdk.UpdateOrganizationRequest{
+		DisplayName: ptr.Ref("Acme Corp"),
+	}, func(r *http.Request) {
+		r.Header.Set("If-Match", etag)
+	})
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestRenameOrganization(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OldNameResolves", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "before",
+		})
+		require.NoError(t, err)
+		org, err = client.UpdateOrganization(ctx, org.ID, codersdk.UpdateOrganizationRequest{
+			Name: ptr.Ref("after"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "after", org.Name)
+
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/organizations/before", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "true", res.Header.Get("Deprecation"))
+		require.Equal(t, `</api/v2/organizations/after>; rel="successor-version"`, res.Header.Get("Link"))
+
+		// The current name doesn't include the deprecation header.
+		res, err = client.Request(ctx, http.MethodGet, "/api/v2/organizations/after", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Empty(t, res.Header.Get("Deprecation"))
+
+		byName, err := client.OrganizationByName(ctx, codersdk.Me, "before")
+		require.NoError(t, err)
+		require.Equal(t, org.ID, byName.ID)
+	})
+
+	t.Run("NameTakenOver", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		renamed, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "before",
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateOrganization(ctx, renamed.ID, codersdk.UpdateOrganizationRequest{
+			Name: ptr.Ref("after"),
+		})
+		require.NoError(t, err)
+
+		// Old names are free to be used by other organizations.
+		created, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "before",
+		})
+		require.NoError(t, err)
+		org, err := client.OrganizationByName(ctx, codersdk.Me, "before")
+		require.NoError(t, err)
+		require.Equal(t, created.ID, org.ID)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "taken",
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateOrganization(ctx, user.OrganizationID, codersdk.UpdateOrganizationRequest{
+			Name: ptr.Ref("taken"),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+}
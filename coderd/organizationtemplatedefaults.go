@@ -0,0 +1,203 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) organizationTemplateDefaults(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	// Template authors need to know what their templates inherit.
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, defaults)
+}
+
+func (api *API) putOrganizationTemplateDefaults(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateOrganizationTemplateDefaultsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.ParameterValues == nil {
+		req.ParameterValues = []codersdk.CreateParameterRequest{}
+	}
+	if req.AllowedParameterValues == nil {
+		req.AllowedParameterValues = map[string][]string{}
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.MaxTTLMillis > maxTTLDefault.Milliseconds() {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: "Cannot be greater than " + maxTTLDefault.String()})
+	}
+	if req.DefaultTTLMillis > 0 {
+		_, err := validWorkspaceTTLMillis(&req.DefaultTTLMillis, time.Duration(req.MaxTTLMillis)*time.Millisecond)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: err.Error()})
+		}
+	}
+	for name, values := range req.AllowedParameterValues {
+		if len(values) == 0 {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "allowed_parameter_values",
+				Detail: fmt.Sprintf("Parameter %q must allow at least one value.", name),
+			})
+		}
+	}
+	names := map[string]struct{}{}
+	for i, parameterValue := range req.ParameterValues {
+		field := fmt.Sprintf("parameter_values[%d]", i)
+		if parameterValue.CloneID != uuid.Nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "Parameters can't be copied from other parameters."})
+		}
+		if _, ok := names[parameterValue.Name]; ok {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("Parameter %q is set more than once.", parameterValue.Name)})
+		}
+		names[parameterValue.Name] = struct{}{}
+	}
+	validErrs = append(validErrs, disallowedParameterValues(req.AllowedParameterValues, req.ParameterValues)...)
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid template defaults.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	parameterValues, err := json.Marshal(req.ParameterValues)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	allowedParameterValues, err := json.Marshal(req.AllowedParameterValues)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	defaults, err := api.Database.UpsertOrganizationTemplateDefaults(ctx, database.UpsertOrganizationTemplateDefaultsParams{
+		OrganizationID:         organization.ID,
+		DefaultTtl:             int64(time.Duration(req.DefaultTTLMillis) * time.Millisecond),
+		MaxTtl:                 int64(time.Duration(req.MaxTTLMillis) * time.Millisecond),
+		MinAutostartInterval:   int64(time.Duration(req.MinAutostartIntervalMillis) * time.Millisecond),
+		ParameterValues:        parameterValues,
+		AllowedParameterValues: allowedParameterValues,
+		UpdatedAt:              database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	converted, err := convertOrganizationTemplateDefaults(defaults)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, converted)
+}
+
+// getOrganizationTemplateDefaults returns the defaults of an organization.
+// Organizations without defaults get zero values.
+func getOrganizationTemplateDefaults(ctx context.Context, db database.Store, organizationID uuid.UUID) (codersdk.OrganizationTemplateDefaults, error) {
+	defaults, err := db.GetOrganizationTemplateDefaults(ctx, organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return codersdk.OrganizationTemplateDefaults{
+			OrganizationID:         organizationID,
+			ParameterValues:        []codersdk.CreateParameterRequest{},
+			AllowedParameterValues: map[string][]string{},
+		}, nil
+	}
+	if err != nil {
+		return codersdk.OrganizationTemplateDefaults{}, xerrors.Errorf("get organization template defaults: %w", err)
+	}
+	return convertOrganizationTemplateDefaults(defaults)
+}
+
+// withDefaultParameterValues adds the default parameter values that aren't
+// set already.
+func withDefaultParameterValues(values, defaults []codersdk.CreateParameterRequest) []codersdk.CreateParameterRequest {
+	for _, parameterValue := range defaults {
+		set := slices.IndexFunc(values, func(value codersdk.CreateParameterRequest) bool {
+			return value.CloneID == uuid.Nil && value.Name == parameterValue.Name
+		}) != -1
+		if !set {
+			values = append(values, parameterValue)
+		}
+	}
+	return values
+}
+
+// disallowedParameterValues returns a validation error for every value that
+// isn't one of the allowed values of its parameter.
+func disallowedParameterValues(allowed map[string][]string, values []codersdk.CreateParameterRequest) []codersdk.ValidationError {
+	var validErrs []codersdk.ValidationError
+	for i, parameterValue := range values {
+		allowedValues, ok := allowed[parameterValue.Name]
+		if !ok || parameterValue.CloneID != uuid.Nil {
+			continue
+		}
+		if !slices.Contains(allowedValues, parameterValue.SourceValue) {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  fmt.Sprintf("parameter_values[%d]", i),
+				Detail: fmt.Sprintf("The organization only allows %q to be one of %q.", parameterValue.Name, allowedValues),
+			})
+		}
+	}
+	return validErrs
+}
+
+func convertOrganizationTemplateDefaults(defaults database.OrganizationTemplateDefault) (codersdk.OrganizationTemplateDefaults, error) {
+	converted := codersdk.OrganizationTemplateDefaults{
+		OrganizationID:             defaults.OrganizationID,
+		DefaultTTLMillis:           time.Duration(defaults.DefaultTtl).Milliseconds(),
+		MaxTTLMillis:               time.Duration(defaults.MaxTtl).Milliseconds(),
+		MinAutostartIntervalMillis: time.Duration(defaults.MinAutostartInterval).Milliseconds(),
+		ParameterValues:            []codersdk.CreateParameterRequest{},
+		AllowedParameterValues:     map[string][]string{},
+		UpdatedAt:                  defaults.UpdatedAt,
+	}
+	err := json.Unmarshal(defaults.ParameterValues, &converted.ParameterValues)
+	if err != nil {
+		return codersdk.OrganizationTemplateDefaults{}, xerrors.Errorf("unmarshal parameter values: %w", err)
+	}
+	err = json.Unmarshal(defaults.AllowedParameterValues, &converted.AllowedParameterValues)
+	if err != nil {
+		return codersdk.OrganizationTemplateDefaults{}, xerrors.Errorf("unmarshal allowed parameter values: %w", err)
+	}
+	return converted, nil
+}
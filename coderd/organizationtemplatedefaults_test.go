@@ -0,0 +1,130 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationTemplateDefaults(t *testing.T) {
+	t.Parallel()
+
+	regionParameter := func(region string) codersdk.CreateParameterRequest {
+		return codersdk.CreateParameterRequest{
+			Name:              "region",
+			SourceValue:       region,
+			SourceScheme:      codersdk.ParameterSourceSchemeData,
+			DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
+		}
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		defaults, err := client.OrganizationTemplateDefaults(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Zero(t, defaults.MaxTTLMillis)
+		require.Empty(t, defaults.ParameterValues)
+
+		// The default value must be allowed.
+		req := codersdk.UpdateOrganizationTemplateDefaultsRequest{
+			MaxTTLMillis:    time.Hour.Milliseconds(),
+			ParameterValues: []codersdk.CreateParameterRequest{regionParameter("ap")},
+			AllowedParameterValues: map[string][]string{
+				"region": {"us", "eu"},
+			},
+		}
+		_, err = client.UpdateOrganizationTemplateDefaults(ctx, user.OrganizationID, req)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// The default TTL can't exceed the max TTL.
+		req.ParameterValues = []codersdk.CreateParameterRequest{regionParameter("us")}
+		req.DefaultTTLMillis = (2 * time.Hour).Milliseconds()
+		_, err = client.UpdateOrganizationTemplateDefaults(ctx, user.OrganizationID, req)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		req.DefaultTTLMillis = (30 * time.Minute).Milliseconds()
+		_, err = client.UpdateOrganizationTemplateDefaults(ctx, user.OrganizationID, req)
+		require.NoError(t, err)
+
+		defaults, err = client.OrganizationTemplateDefaults(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, time.Hour.Milliseconds(), defaults.MaxTTLMillis)
+		require.Equal(t, (30 * time.Minute).Milliseconds(), defaults.DefaultTTLMillis)
+		require.Equal(t, []codersdk.CreateParameterRequest{regionParameter("us")}, defaults.ParameterValues)
+		require.Equal(t, []string{"us", "eu"}, defaults.AllowedParameterValues["region"])
+	})
+
+	t.Run("Inherit", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateOrganizationTemplateDefaults(ctx, user.OrganizationID, codersdk.UpdateOrganizationTemplateDefaultsRequest{
+			DefaultTTLMillis:           (30 * time.Minute).Milliseconds(),
+			MaxTTLMillis:               time.Hour.Milliseconds(),
+			MinAutostartIntervalMillis: (2 * time.Hour).Milliseconds(),
+			ParameterValues:            []codersdk.CreateParameterRequest{regionParameter("us")},
+			AllowedParameterValues: map[string][]string{
+				"region": {"us", "eu"},
+			},
+		})
+		require.NoError(t, err)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Equal(t, time.Hour.Milliseconds(), template.MaxTTLMillis)
+		require.Equal(t, (2 * time.Hour).Milliseconds(), template.MinAutostartIntervalMillis)
+
+		parameters, err := client.Parameters(ctx, codersdk.ParameterImportJob, version.Job.ID)
+		require.NoError(t, err)
+		require.Len(t, parameters, 1)
+		require.Equal(t, "region", parameters[0].Name)
+
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = nil
+		})
+		require.NotNil(t, workspace.TTLMillis)
+		require.Equal(t, (30 * time.Minute).Milliseconds(), *workspace.TTLMillis)
+
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID:      template.ID,
+			Name:            "disallowed",
+			ParameterValues: []codersdk.CreateParameterRequest{regionParameter("ap")},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotUpdate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.OrganizationTemplateDefaults(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		_, err = member.UpdateOrganizationTemplateDefaults(ctx, user.OrganizationID, codersdk.UpdateOrganizationTemplateDefaultsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,282 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhookdelivery"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+// organizationWebhookDeliveriesLimit is how many of the most recent delivery
+// attempts are returned for a webhook.
+const organizationWebhookDeliveriesLimit = 100
+
+func (api *API) organizationWebhooks(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	webhooks, err := api.Database.GetOrganizationWebhooksByOrganizationID(ctx, organization.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.OrganizationWebhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, convertOrganizationWebhook(webhook))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) postOrganizationWebhook(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateOrganizationWebhookRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Webhook URL must be an absolute http or https URL.",
+			Validations: []codersdk.ValidationError{
+				{Field: "url", Detail: fmt.Sprintf("invalid webhook URL %q", req.URL)},
+			},
+		})
+		return
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !slices.Contains(codersdk.OrganizationWebhookEventTypes, event) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Unknown webhook event %q.", event),
+				Validations: []codersdk.ValidationError{
+					{Field: "events", Detail: fmt.Sprintf("must be one of %v", codersdk.OrganizationWebhookEventTypes)},
+				},
+			})
+			return
+		}
+		events = append(events, string(event))
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = cryptorand.HexString(32)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+
+	webhook, err := api.Database.InsertOrganizationWebhook(ctx, database.InsertOrganizationWebhookParams{
+		ID:             uuid.New(),
+		OrganizationID: organization.ID,
+		Url:            req.URL,
+		Secret:         secret,
+		Events:         events,
+		CreatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := convertOrganizationWebhook(webhook)
+	// The secret is only ever returned here so it can be stored by the
+	// receiving end.
+	resp.Secret = webhook.Secret
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+func (api *API) deleteOrganizationWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook, ok := api.organizationWebhookParam(rw, r)
+	if !ok {
+		return
+	}
+
+	err := api.Database.DeleteOrganizationWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully deleted webhook!",
+	})
+}
+
+func (api *API) organizationWebhookDeliveries(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook, ok := api.organizationWebhookParam(rw, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := api.Database.GetOrganizationWebhookDeliveriesByWebhookID(ctx, database.GetOrganizationWebhookDeliveriesByWebhookIDParams{
+		WebhookID: webhook.ID,
+		Limit:     organizationWebhookDeliveriesLimit,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.OrganizationWebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, codersdk.OrganizationWebhookDelivery{
+			ID:         delivery.ID,
+			WebhookID:  delivery.WebhookID,
+			EventID:    delivery.EventID,
+			EventType:  codersdk.OrganizationWebhookEventType(delivery.EventType),
+			Attempt:    int(delivery.Attempt),
+			StatusCode: int(delivery.StatusCode),
+			Error:      delivery.Error,
+			CreatedAt:  delivery.CreatedAt,
+		})
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// organizationWebhookParam authorizes the request and returns the webhook in
+// the URL. It writes an error response if it returns false.
+func (api *API) organizationWebhookParam(rw http.ResponseWriter, r *http.Request) (database.OrganizationWebhook, bool) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return database.OrganizationWebhook{}, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "webhook"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid webhook ID.",
+			Detail:  err.Error(),
+		})
+		return database.OrganizationWebhook{}, false
+	}
+
+	webhook, err := api.Database.GetOrganizationWebhookByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && webhook.OrganizationID != organization.ID) {
+		httpapi.ResourceNotFound(rw)
+		return database.OrganizationWebhook{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return database.OrganizationWebhook{}, false
+	}
+	return webhook, true
+}
+
+// publishOrganizationEvent queues the event for every webhook of the
+// organization that's subscribed to it.
+func (api *API) publishOrganizationEvent(organizationID uuid.UUID, event codersdk.OrganizationWebhookEvent) {
+	event.ID = uuid.New()
+	event.CreatedAt = database.Now()
+	event.OrganizationID = organizationID
+	body, err := json.Marshal(event)
+	if err != nil {
+		api.Logger.Error(context.Background(), "marshal organization webhook event", slog.Error(err))
+		return
+	}
+
+	api.WebhookEngine.Publish(webhookdelivery.KindOrganization, webhookdelivery.Event{
+		ID:      event.ID,
+		Type:    string(event.Type),
+		Payload: body,
+	}, func(ctx context.Context) ([]uuid.UUID, error) {
+		webhooks, err := api.Database.GetOrganizationWebhooksByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uuid.UUID, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, string(event.Type)) {
+				continue
+			}
+			ids = append(ids, webhook.ID)
+		}
+		return ids, nil
+	})
+}
+
+// organizationWebhookHandler delivers events to organization webhooks. Every
+// attempt is recorded in the delivery log of the webhook.
+func (api *API) organizationWebhookHandler() webhookdelivery.Handler {
+	return webhookdelivery.Handler{
+		Webhook: func(ctx context.Context, id uuid.UUID) (webhookdelivery.Webhook, error) {
+			webhook, err := api.Database.GetOrganizationWebhookByID(ctx, id)
+			if err != nil {
+				return webhookdelivery.Webhook{}, err
+			}
+			return webhookdelivery.Webhook{URL: webhook.Url, Secret: webhook.Secret}, nil
+		},
+		Record: func(ctx context.Context, attempt webhookdelivery.Attempt) error {
+			delivery := database.InsertOrganizationWebhookDeliveryParams{
+				ID:         uuid.New(),
+				WebhookID:  attempt.Item.WebhookID,
+				EventID:    attempt.Item.EventID,
+				EventType:  attempt.Item.EventType,
+				Attempt:    attempt.Number,
+				StatusCode: int32(attempt.StatusCode),
+				CreatedAt:  database.Now(),
+			}
+			if attempt.Err != nil {
+				delivery.Error = attempt.Err.Error()
+			}
+			_, err := api.Database.InsertOrganizationWebhookDelivery(ctx, delivery)
+			return err
+		},
+		RetryInterval: api.OrganizationWebhookRetryInterval,
+	}
+}
+
+func convertOrganizationWebhook(webhook database.OrganizationWebhook) codersdk.OrganizationWebhook {
+	events := make([]codersdk.OrganizationWebhookEventType, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, codersdk.OrganizationWebhookEventType(event))
+	}
+	return codersdk.OrganizationWebhook{
+		ID:             webhook.ID,
+		OrganizationID: webhook.OrganizationID,
+		URL:            webhook.Url,
+		Events:         events,
+		CreatedAt:      webhook.CreatedAt,
+	}
+}
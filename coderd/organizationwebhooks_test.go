@@ -0,0 +1,187 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/webhookdelivery/webhookdeliverytest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationWebhooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Members", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.OrganizationWebhookEvent](t, "secret", nil)
+		webhook, err := client.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateOrganizationWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "secret", webhook.Secret)
+		require.Empty(t, webhook.Events)
+
+		webhooks, err := client.OrganizationWebhooks(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		require.Equal(t, webhook.ID, webhooks[0].ID)
+		require.Empty(t, webhooks[0].Secret)
+
+		_, member := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.OrganizationWebhookEventMemberAdded, event.Type)
+		require.Equal(t, user.OrganizationID, event.OrganizationID)
+		require.Equal(t, member.ID, event.ResourceID)
+		require.Equal(t, member.Username, event.ResourceName)
+
+		err = client.DeleteUser(ctx, member.ID)
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.OrganizationWebhookEventMemberRemoved, event.Type)
+		require.Equal(t, member.ID, event.ResourceID)
+
+		require.Eventually(t, func() bool {
+			deliveries, err := client.OrganizationWebhookDeliveries(ctx, user.OrganizationID, webhook.ID)
+			return err == nil && len(deliveries) == 2
+		}, testutil.WaitShort, testutil.IntervalFast)
+		deliveries, err := client.OrganizationWebhookDeliveries(ctx, user.OrganizationID, webhook.ID)
+		require.NoError(t, err)
+		for _, delivery := range deliveries {
+			require.Equal(t, http.StatusOK, delivery.StatusCode)
+			require.Equal(t, 1, delivery.Attempt)
+			require.Empty(t, delivery.Error)
+		}
+
+		err = client.DeleteOrganizationWebhook(ctx, user.OrganizationID, webhook.ID)
+		require.NoError(t, err)
+		webhooks, err = client.OrganizationWebhooks(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 0)
+	})
+
+	t.Run("TemplatesAndWorkspaces", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.OrganizationWebhookEvent](t, "secret", nil)
+		_, err := client.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateOrganizationWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+			Events: []codersdk.OrganizationWebhookEventType{
+				codersdk.OrganizationWebhookEventTemplatePublished,
+				codersdk.OrganizationWebhookEventWorkspaceCreated,
+				codersdk.OrganizationWebhookEventWorkspaceDeleted,
+			},
+		})
+		require.NoError(t, err)
+
+		// Members joining aren't delivered since the webhook isn't
+		// subscribed to them.
+		_ = coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.OrganizationWebhookEventTemplatePublished, event.Type)
+		require.Equal(t, template.ID, event.ResourceID)
+
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.OrganizationWebhookEventWorkspaceCreated, event.Type)
+		require.Equal(t, workspace.ID, event.ResourceID)
+		require.Equal(t, workspace.Name, event.ResourceName)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionDelete,
+		})
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.OrganizationWebhookEventWorkspaceDeleted, event.Type)
+		require.Equal(t, workspace.ID, event.ResourceID)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			OrganizationWebhookRetryInterval: time.Millisecond,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.OrganizationWebhookEvent](t, "secret", func(attempt int64, rw http.ResponseWriter) bool {
+			if attempt < 3 {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return false
+			}
+			return true
+		})
+		webhook, err := client.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateOrganizationWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+
+		_ = coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.OrganizationWebhookEventMemberAdded, event.Type)
+
+		var deliveries []codersdk.OrganizationWebhookDelivery
+		require.Eventually(t, func() bool {
+			deliveries, err = client.OrganizationWebhookDeliveries(ctx, user.OrganizationID, webhook.ID)
+			return err == nil && len(deliveries) == 3
+		}, testutil.WaitShort, testutil.IntervalFast)
+		// Deliveries are returned newest first.
+		require.Equal(t, 3, deliveries[0].Attempt)
+		require.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		require.Equal(t, 1, deliveries[2].Attempt)
+		require.Equal(t, http.StatusInternalServerError, deliveries[2].StatusCode)
+		require.NotEmpty(t, deliveries[2].Error)
+		require.Equal(t, event.ID, deliveries[2].EventID)
+	})
+
+	t.Run("InvalidEvent", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateOrganizationWebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []codersdk.OrganizationWebhookEventType{"workspace.renamed"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotCreate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateOrganizationWebhookRequest{
+			URL: "https://example.com/hook",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,164 @@
+// Package orgcache caches the organizations that API routes are scoped to.
+// Nearly every organization route resolves the organization in its URL, so
+// the lookups are kept in memory for a short time instead of querying the
+// database on every request.
+package orgcache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+)
+
+// PubsubEvent is published when an organization changes, so every replica
+// drops the organizations it cached.
+const PubsubEvent = "organizations"
+
+// Store is a database.Store that caches organizations looked up by ID or
+// name. All other queries go to the wrapped store.
+type Store struct {
+	database.Store
+
+	log    slog.Logger
+	pubsub database.Pubsub
+	ttl    time.Duration
+
+	mutex sync.Mutex
+	// cancel is set once the store subscribes to changes, which happens
+	// on the first lookup.
+	cancel func()
+	// closed stops the store from subscribing.
+	closed bool
+	// generation is bumped whenever the cache is purged, so lookups that
+	// were in flight don't store organizations that are already stale.
+	generation uint64
+	entries    map[string]entry
+}
+
+type entry struct {
+	organization database.Organization
+	expiresAt    time.Time
+}
+
+// New returns a store that caches organizations for the TTL. Nothing is
+// cached if the store can't subscribe to changes from other replicas.
+func New(db database.Store, pubsub database.Pubsub, log slog.Logger, ttl time.Duration) *Store {
+	if pubsub == nil {
+		ttl = 0
+	}
+	return &Store{
+		Store:   db,
+		log:     log,
+		pubsub:  pubsub,
+		ttl:     ttl,
+		entries: map[string]entry{},
+	}
+}
+
+func (s *Store) GetOrganizationByID(ctx context.Context, id uuid.UUID) (database.Organization, error) {
+	return s.get("id:"+id.String(), func() (database.Organization, error) {
+		return s.Store.GetOrganizationByID(ctx, id)
+	})
+}
+
+func (s *Store) GetOrganizationByName(ctx context.Context, name string) (database.Organization, error) {
+	// Names are matched case-insensitively.
+	return s.get("name:"+strings.ToLower(name), func() (database.Organization, error) {
+		return s.Store.GetOrganizationByName(ctx, name)
+	})
+}
+
+// Invalidate drops the cached organizations of every replica. It must be
+// called after an organization is changed.
+func (s *Store) Invalidate(ctx context.Context) {
+	s.purge()
+	if s.pubsub == nil {
+		return
+	}
+	err := s.pubsub.Publish(PubsubEvent, []byte{})
+	if err != nil {
+		s.log.Warn(ctx, "publish organization change", slog.Error(err))
+	}
+}
+
+// Close stops listening for changes from other replicas.
+func (s *Store) Close() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.closed = true
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (s *Store) get(key string, fetch func() (database.Organization, error)) (database.Organization, error) {
+	if s.ttl <= 0 {
+		return fetch()
+	}
+
+	s.mutex.Lock()
+	if !s.subscribe() {
+		s.mutex.Unlock()
+		return fetch()
+	}
+	cached, ok := s.entries[key]
+	generation := s.generation
+	s.mutex.Unlock()
+	now := time.Now()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.organization, nil
+	}
+
+	// Errors, including organizations that don't exist, aren't cached.
+	organization, err := fetch()
+	if err != nil {
+		return database.Organization{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.generation == generation {
+		s.entries[key] = entry{
+			organization: organization,
+			expiresAt:    now.Add(s.ttl),
+		}
+	}
+	return organization, nil
+}
+
+// subscribe listens for changes from other replicas if the store doesn't
+// already. It reports whether organizations can be cached. The mutex must
+// be held.
+func (s *Store) subscribe() bool {
+	if s.cancel != nil {
+		return true
+	}
+	if s.closed {
+		return false
+	}
+	cancel, err := s.pubsub.Subscribe(PubsubEvent, func(_ context.Context, _ []byte) {
+		s.purge()
+	})
+	if err != nil {
+		s.log.Warn(context.Background(), "subscribe to organization changes, caching is disabled", slog.Error(err))
+		// Don't try again on every lookup.
+		s.closed = true
+		return false
+	}
+	s.cancel = cancel
+	return true
+}
+
+func (s *Store) purge() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.generation++
+	s.entries = map[string]entry{}
+}
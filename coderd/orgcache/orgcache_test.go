@@ -0,0 +1,129 @@
+package orgcache_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/orgcache"
+	"github.com/coder/coder/testutil"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (database.Store, database.Pubsub, database.Organization) {
+		t.Helper()
+		db := databasefake.New()
+		organization, err := db.InsertOrganization(context.Background(), database.InsertOrganizationParams{
+			ID:        uuid.New(),
+			Name:      "acme",
+			CreatedAt: database.Now(),
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+		return db, database.NewPubsubInMemory(), organization
+	}
+
+	rename := func(t *testing.T, db database.Store, organization database.Organization, name string) {
+		t.Helper()
+		_, err := db.UpdateOrganizationByID(context.Background(), database.UpdateOrganizationByIDParams{
+			ID:        organization.ID,
+			Name:      name,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("Invalidate", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		db, pubsub, organization := setup(t)
+		store := orgcache.New(db, pubsub, slogtest.Make(t, nil), time.Hour)
+		defer store.Close()
+
+		cached, err := store.GetOrganizationByID(ctx, organization.ID)
+		require.NoError(t, err)
+		require.Equal(t, "acme", cached.Name)
+		cached, err = store.GetOrganizationByName(ctx, "acme")
+		require.NoError(t, err)
+		require.Equal(t, organization.ID, cached.ID)
+
+		// Changes that bypass the cache aren't seen until it's
+		// invalidated.
+		rename(t, db, organization, "globex")
+		cached, err = store.GetOrganizationByID(ctx, organization.ID)
+		require.NoError(t, err)
+		require.Equal(t, "acme", cached.Name)
+
+		store.Invalidate(ctx)
+		cached, err = store.GetOrganizationByID(ctx, organization.ID)
+		require.NoError(t, err)
+		require.Equal(t, "globex", cached.Name)
+		_, err = store.GetOrganizationByName(ctx, "acme")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("OtherReplica", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		db, pubsub, organization := setup(t)
+		store := orgcache.New(db, pubsub, slogtest.Make(t, nil), time.Hour)
+		defer store.Close()
+		replica := orgcache.New(db, pubsub, slogtest.Make(t, nil), time.Hour)
+		defer replica.Close()
+
+		_, err := replica.GetOrganizationByID(ctx, organization.ID)
+		require.NoError(t, err)
+
+		rename(t, db, organization, "globex")
+		store.Invalidate(ctx)
+		cached, err := replica.GetOrganizationByID(ctx, organization.ID)
+		require.NoError(t, err)
+		require.Equal(t, "globex", cached.Name)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		db, pubsub, organization := setup(t)
+		store := orgcache.New(db, pubsub, slogtest.Make(t, nil), time.Millisecond)
+		defer store.Close()
+
+		_, err := store.GetOrganizationByID(ctx, organization.ID)
+		require.NoError(t, err)
+		rename(t, db, organization, "globex")
+		require.Eventually(t, func() bool {
+			cached, err := store.GetOrganizationByID(ctx, organization.ID)
+			return err == nil && cached.Name == "globex"
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		db, pubsub, _ := setup(t)
+		store := orgcache.New(db, pubsub, slogtest.Make(t, nil), time.Hour)
+		defer store.Close()
+
+		// Misses aren't cached, so new organizations resolve right away.
+		_, err := store.GetOrganizationByName(ctx, "initech")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = db.InsertOrganization(ctx, database.InsertOrganizationParams{
+			ID:        uuid.New(),
+			Name:      "initech",
+			CreatedAt: database.Now(),
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+		_, err = store.GetOrganizationByName(ctx, "initech")
+		require.NoError(t, err)
+	})
+}
@@ -104,7 +104,7 @@ func TestPagination(t *testing.T) {
 			query.Set("offset", c.Offset)
 			r.URL.RawQuery = query.Encode()
 
-			params, ok := parsePagination(rw, r)
+			params, ok := ParsePagination(rw, r)
 			if c.ExpectedError == "" {
 				require.True(t, ok, "expect ok")
 				require.Equal(t, c.ExpectedParams, params, "expected params")
@@ -92,6 +92,10 @@ func (api *API) ListenProvisionerDaemon(ctx context.Context) (client proto.DRPCP
 		Provisioners: daemon.Provisioners,
 		Telemetry:    api.Telemetry,
 		Logger:       api.Logger.Named(fmt.Sprintf("provisionerd-%s", daemon.Name)),
+		Maintenance: func(ctx context.Context) bool {
+			_, enabled := api.maintenanceEnabled(ctx)
+			return enabled
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -139,10 +143,18 @@ type provisionerdServer struct {
 	Database     database.Store
 	Pubsub       database.Pubsub
 	Telemetry    telemetry.Reporter
+	// Maintenance returns whether the deployment is in maintenance mode, in
+	// which no jobs are acquired. Jobs that are running finish.
+	Maintenance func(ctx context.Context) bool
 }
 
 // AcquireJob queries the database to lock a job.
 func (server *provisionerdServer) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+	if server.Maintenance != nil && server.Maintenance(ctx) {
+		// The provisioner daemon assumes no jobs are available if
+		// an empty struct is returned.
+		return &proto.AcquiredJob{}, nil
+	}
 	// This marks the job as locked in the database.
 	job, err := server.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
 		StartedAt: sql.NullTime{
@@ -640,6 +652,21 @@ func (server *provisionerdServer) CompleteJob(ctx context.Context, completed *pr
 				if workspace.Ttl.Valid {
 					workspaceDeadline = now.Add(time.Duration(workspace.Ttl.Int64))
 				}
+				if workspaceBuild.Transition == database.WorkspaceTransitionStart {
+					groups, err := db.GetUserEffectiveGroups(ctx, database.GetUserEffectiveGroupsParams{
+						UserID:         workspace.OwnerID,
+						OrganizationID: workspace.OrganizationID,
+					})
+					if err != nil {
+						return xerrors.Errorf("get workspace owner groups: %w", err)
+					}
+					// Group autostop policies can stop a workspace before its
+					// TTL would, or stop one that doesn't have a TTL at all.
+					groupDeadline := groupAutostopDeadline(groups, now)
+					if !groupDeadline.IsZero() && (workspaceDeadline.IsZero() || groupDeadline.Before(workspaceDeadline)) {
+						workspaceDeadline = groupDeadline
+					}
+				}
 			} else {
 				// Huh? Did the workspace get deleted?
 				// In any case, since this is just for the TTL, try and continue anyway.
@@ -678,6 +705,15 @@ func (server *provisionerdServer) CompleteJob(ctx context.Context, completed *pr
 				return nil
 			}
 
+			// Archived workspaces are kept, so they can be restored.
+			archive, err := db.GetWorkspaceArchiveByWorkspaceID(ctx, workspaceBuild.WorkspaceID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get workspace archive: %w", err)
+			}
+			if err == nil && archive.BuildID == workspaceBuild.ID {
+				return nil
+			}
+
 			err = db.UpdateWorkspaceDeletedByID(ctx, database.UpdateWorkspaceDeletedByIDParams{
 				ID:      workspaceBuild.WorkspaceID,
 				Deleted: true,
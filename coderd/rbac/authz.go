@@ -14,8 +14,8 @@ import (
 )
 
 type Authorizer interface {
-	ByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, action Action, object Object) error
-	PrepareByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, action Action, objectType string) (PreparedAuthorized, error)
+	ByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, everyoneExcluded []string, action Action, object Object) error
+	PrepareByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, everyoneExcluded []string, action Action, objectType string) (PreparedAuthorized, error)
 }
 
 type PreparedAuthorized interface {
@@ -26,7 +26,7 @@ type PreparedAuthorized interface {
 // Filter takes in a list of objects, and will filter the list removing all
 // the elements the subject does not have permission for. All objects must be
 // of the same type.
-func Filter[O Objecter](ctx context.Context, auth Authorizer, subjID string, subjRoles []string, scope Scope, groups []string, action Action, objects []O) ([]O, error) {
+func Filter[O Objecter](ctx context.Context, auth Authorizer, subjID string, subjRoles []string, scope Scope, groups []string, everyoneExcluded []string, action Action, objects []O) ([]O, error) {
 	ctx, span := tracing.StartSpan(ctx, trace.WithAttributes(
 		attribute.String("subject_id", subjID),
 		attribute.StringSlice("subject_roles", subjRoles),
@@ -51,7 +51,7 @@ func Filter[O Objecter](ctx context.Context, auth Authorizer, subjID string, sub
 			if rbacObj.Type != objectType {
 				return nil, xerrors.Errorf("object types must be uniform across the set (%s), found %s", objectType, rbacObj)
 			}
-			err := auth.ByRoleName(ctx, subjID, subjRoles, scope, groups, action, o.RBACObject())
+			err := auth.ByRoleName(ctx, subjID, subjRoles, scope, groups, everyoneExcluded, action, o.RBACObject())
 			if err == nil {
 				filtered = append(filtered, o)
 			}
@@ -59,7 +59,7 @@ func Filter[O Objecter](ctx context.Context, auth Authorizer, subjID string, sub
 		return filtered, nil
 	}
 
-	prepared, err := auth.PrepareByRoleName(ctx, subjID, subjRoles, scope, groups, action, objectType)
+	prepared, err := auth.PrepareByRoleName(ctx, subjID, subjRoles, scope, groups, everyoneExcluded, action, objectType)
 	if err != nil {
 		return nil, xerrors.Errorf("prepare: %w", err)
 	}
@@ -94,6 +94,11 @@ var (
 	query     rego.PreparedEvalQuery
 )
 
+// Policy returns the source of the rego policy that authorizes requests.
+func Policy() string {
+	return policy
+}
+
 func NewAuthorizer() *RegoAuthorizer {
 	queryOnce.Do(func() {
 		var err error
@@ -112,13 +117,16 @@ type authSubject struct {
 	ID     string   `json:"id"`
 	Roles  []Role   `json:"roles"`
 	Groups []string `json:"groups"`
-	Scope  Role     `json:"scope"`
+	// EveryoneExcludedOrgs are the organizations whose "Everyone" group the
+	// subject is excluded from.
+	EveryoneExcludedOrgs []string `json:"everyone_excluded_orgs"`
+	Scope                Role     `json:"scope"`
 }
 
 // ByRoleName will expand all roleNames into roles before calling Authorize().
 // This is the function intended to be used outside this package.
 // The role is fetched from the builtin map located in memory.
-func (a RegoAuthorizer) ByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, action Action, object Object) error {
+func (a RegoAuthorizer) ByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, everyoneExcluded []string, action Action, object Object) error {
 	roles, err := RolesByNames(roleNames)
 	if err != nil {
 		return err
@@ -129,7 +137,7 @@ func (a RegoAuthorizer) ByRoleName(ctx context.Context, subjectID string, roleNa
 		return err
 	}
 
-	err = a.Authorize(ctx, subjectID, roles, scopeRole, groups, action, object)
+	err = a.Authorize(ctx, subjectID, roles, scopeRole, groups, everyoneExcluded, action, object)
 	if err != nil {
 		return err
 	}
@@ -139,16 +147,17 @@ func (a RegoAuthorizer) ByRoleName(ctx context.Context, subjectID string, roleNa
 
 // Authorize allows passing in custom Roles.
 // This is really helpful for unit testing, as we can create custom roles to exercise edge cases.
-func (a RegoAuthorizer) Authorize(ctx context.Context, subjectID string, roles []Role, scope Role, groups []string, action Action, object Object) error {
+func (a RegoAuthorizer) Authorize(ctx context.Context, subjectID string, roles []Role, scope Role, groups []string, everyoneExcluded []string, action Action, object Object) error {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
 	input := map[string]interface{}{
 		"subject": authSubject{
-			ID:     subjectID,
-			Roles:  roles,
-			Groups: groups,
-			Scope:  scope,
+			ID:                   subjectID,
+			Roles:                roles,
+			Groups:               groups,
+			EveryoneExcludedOrgs: everyoneExcluded,
+			Scope:                scope,
 		},
 		"object": object,
 		"action": action,
@@ -167,11 +176,11 @@ func (a RegoAuthorizer) Authorize(ctx context.Context, subjectID string, roles [
 
 // Prepare will partially execute the rego policy leaving the object fields unknown (except for the type).
 // This will vastly speed up performance if batch authorization on the same type of objects is needed.
-func (RegoAuthorizer) Prepare(ctx context.Context, subjectID string, roles []Role, scope Role, groups []string, action Action, objectType string) (*PartialAuthorizer, error) {
+func (RegoAuthorizer) Prepare(ctx context.Context, subjectID string, roles []Role, scope Role, groups []string, everyoneExcluded []string, action Action, objectType string) (*PartialAuthorizer, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
-	auth, err := newPartialAuthorizer(ctx, subjectID, roles, scope, groups, action, objectType)
+	auth, err := newPartialAuthorizer(ctx, subjectID, roles, scope, groups, everyoneExcluded, action, objectType)
 	if err != nil {
 		return nil, xerrors.Errorf("new partial authorizer: %w", err)
 	}
@@ -179,7 +188,7 @@ func (RegoAuthorizer) Prepare(ctx context.Context, subjectID string, roles []Rol
 	return auth, nil
 }
 
-func (a RegoAuthorizer) PrepareByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, action Action, objectType string) (PreparedAuthorized, error) {
+func (a RegoAuthorizer) PrepareByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, everyoneExcluded []string, action Action, objectType string) (PreparedAuthorized, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
@@ -193,5 +202,5 @@ func (a RegoAuthorizer) PrepareByRoleName(ctx context.Context, subjectID string,
 		return nil, err
 	}
 
-	return a.Prepare(ctx, subjectID, roles, scopeRole, groups, action, objectType)
+	return a.Prepare(ctx, subjectID, roles, scopeRole, groups, everyoneExcluded, action, objectType)
 }
@@ -19,9 +19,10 @@ type subject struct {
 	// For the unit test we want to pass in the roles directly, instead of just
 	// by name. This allows us to test custom roles that do not exist in the product,
 	// but test edge cases of the implementation.
-	Roles  []Role   `json:"roles"`
-	Groups []string `json:"groups"`
-	Scope  Role     `json:"scope"`
+	Roles                []Role   `json:"roles"`
+	Groups               []string `json:"groups"`
+	EveryoneExcludedOrgs []string `json:"everyone_excluded_orgs"`
+	Scope                Role     `json:"scope"`
 }
 
 type fakeObject struct {
@@ -43,7 +44,7 @@ func TestFilterError(t *testing.T) {
 	t.Parallel()
 	auth := NewAuthorizer()
 
-	_, err := Filter(context.Background(), auth, uuid.NewString(), []string{}, ScopeAll, []string{}, ActionRead, []Object{ResourceUser, ResourceWorkspace})
+	_, err := Filter(context.Background(), auth, uuid.NewString(), []string{}, ScopeAll, []string{}, []string{}, ActionRead, []Object{ResourceUser, ResourceWorkspace})
 	require.ErrorContains(t, err, "object types must be uniform")
 }
 
@@ -171,7 +172,7 @@ func TestFilter(t *testing.T) {
 			var allowedCount int
 			for i, obj := range localObjects {
 				obj.Type = tc.ObjectType
-				err := auth.ByRoleName(ctx, tc.SubjectID, tc.Roles, scope, []string{}, ActionRead, obj.RBACObject())
+				err := auth.ByRoleName(ctx, tc.SubjectID, tc.Roles, scope, []string{}, []string{}, ActionRead, obj.RBACObject())
 				obj.Allowed = err == nil
 				if err == nil {
 					allowedCount++
@@ -180,7 +181,7 @@ func TestFilter(t *testing.T) {
 			}
 
 			// Run by filter
-			list, err := Filter(ctx, auth, tc.SubjectID, tc.Roles, scope, []string{}, tc.Action, localObjects)
+			list, err := Filter(ctx, auth, tc.SubjectID, tc.Roles, scope, []string{}, []string{}, tc.Action, localObjects)
 			require.NoError(t, err)
 			require.Equal(t, allowedCount, len(list), "expected number of allowed")
 			for _, obj := range list {
@@ -271,6 +272,67 @@ func TestAuthorizeDomain(t *testing.T) {
 		},
 	})
 
+	excluded := subject{
+		UserID: "me",
+		Scope:  must(ScopeRole(ScopeAll)),
+		Roles: []Role{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+		},
+		EveryoneExcludedOrgs: []string{defOrg.String()},
+	}
+
+	testAuthorize(t, "EveryoneExcluded", excluded, []authTestCase{
+		{
+			resource: ResourceTemplate.InOrg(defOrg).WithGroupACL(map[string][]Action{
+				defOrg.String(): {ActionRead},
+			}),
+			actions: []Action{ActionRead},
+			allow:   false,
+		},
+		{
+			resource: ResourceTemplate.InOrg(defOrg).WithACLUserList(map[string][]Action{
+				excluded.UserID: {ActionRead},
+			}),
+			actions: []Action{ActionRead},
+			allow:   true,
+		},
+	})
+
+	// Only the organization the actor is excluded from is affected, and roles
+	// can't exclude the actor, whatever their name.
+	testAuthorize(t, "EveryoneIncluded", subject{
+		UserID: "me",
+		Scope:  must(ScopeRole(ScopeAll)),
+		Roles: []Role{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+			must(RoleByName(RoleOrgMember(unuseID))),
+			{
+				Name: "organization-everyone-excluded:" + defOrg.String(),
+				Org: map[string][]Permission{
+					defOrg.String(): {},
+				},
+			},
+		},
+		EveryoneExcludedOrgs: []string{unuseID.String()},
+	}, []authTestCase{
+		{
+			resource: ResourceTemplate.InOrg(defOrg).WithGroupACL(map[string][]Action{
+				defOrg.String(): {ActionRead},
+			}),
+			actions: []Action{ActionRead},
+			allow:   true,
+		},
+		{
+			resource: ResourceTemplate.InOrg(unuseID).WithGroupACL(map[string][]Action{
+				unuseID.String(): {ActionRead},
+			}),
+			actions: []Action{ActionRead},
+			allow:   false,
+		},
+	})
+
 	testAuthorize(t, "Member", user, []authTestCase{
 		// Org + me
 		{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID), actions: allActions(), allow: true},
@@ -628,34 +690,34 @@ func TestAuthorizeLevels(t *testing.T) {
 		},
 	}
 
-	testAuthorize(t, "AdminAlwaysAllow", user,
+	// Denies take precedence over the grants of the owner role.
+	testAuthorize(t, "DenyOverridesAdmin", user,
 		cases(func(c authTestCase) authTestCase {
 			c.actions = allActions()
-			c.allow = true
 			return c
 		}, []authTestCase{
 			// Org + me
-			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID)},
-			{resource: ResourceWorkspace.InOrg(defOrg)},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID), allow: false},
+			{resource: ResourceWorkspace.InOrg(defOrg), allow: false},
 
-			{resource: ResourceWorkspace.WithOwner(user.UserID)},
+			{resource: ResourceWorkspace.WithOwner(user.UserID), allow: false},
 
-			{resource: ResourceWorkspace.All()},
+			{resource: ResourceWorkspace.All(), allow: true},
 
 			// Other org + me
-			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner(user.UserID)},
-			{resource: ResourceWorkspace.InOrg(unusedID)},
+			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner(user.UserID), allow: false},
+			{resource: ResourceWorkspace.InOrg(unusedID), allow: true},
 
 			// Other org + other user
-			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me")},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me"), allow: false},
 
-			{resource: ResourceWorkspace.WithOwner("not-me")},
+			{resource: ResourceWorkspace.WithOwner("not-me"), allow: true},
 
 			// Other org + other use
-			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me")},
-			{resource: ResourceWorkspace.InOrg(unusedID)},
+			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me"), allow: true},
+			{resource: ResourceWorkspace.InOrg(unusedID), allow: true},
 
-			{resource: ResourceWorkspace.WithOwner("not-me")},
+			{resource: ResourceWorkspace.WithOwner("not-me"), allow: true},
 		}))
 
 	user = subject{
@@ -692,8 +754,8 @@ func TestAuthorizeLevels(t *testing.T) {
 			c.actions = allActions()
 			return c
 		}, []authTestCase{
-			// Org + me
-			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID), allow: true},
+			// Org + me, the user deny takes precedence over the org grant.
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID), allow: false},
 			{resource: ResourceWorkspace.InOrg(defOrg), allow: true},
 
 			{resource: ResourceWorkspace.WithOwner(user.UserID), allow: false},
@@ -717,6 +779,119 @@ func TestAuthorizeLevels(t *testing.T) {
 		}))
 }
 
+// TestAuthorizeDeny ensures deny permissions take precedence over grants
+// from other roles and ACLs.
+func TestAuthorizeDeny(t *testing.T) {
+	t.Parallel()
+	defOrg := uuid.New()
+	unusedID := uuid.New()
+
+	contractor := Role{
+		Name: "contractor",
+		Site: []Permission{
+			{
+				Negate:       true,
+				ResourceType: ResourceWorkspace.Type,
+				Action:       ActionDelete,
+			},
+		},
+	}
+	user := subject{
+		UserID: "me",
+		Scope:  must(ScopeRole(ScopeAll)),
+		Roles: []Role{
+			must(RoleByName(RoleOwner())),
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgAdmin(defOrg))),
+			contractor,
+		},
+	}
+
+	testAuthorize(t, "SiteDeny", user,
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionDelete}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID)},
+			{resource: ResourceWorkspace.InOrg(defOrg)},
+			{resource: ResourceWorkspace.WithOwner(user.UserID)},
+			{resource: ResourceWorkspace.All()},
+			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me")},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me").WithACLUserList(map[string][]Action{
+				user.UserID: {WildcardSymbol},
+			})},
+		}),
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionCreate, ActionRead, ActionUpdate}
+			c.allow = true
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID)},
+			{resource: ResourceWorkspace.All()},
+			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me")},
+		}),
+		// Other resources are unaffected.
+		[]authTestCase{
+			{resource: ResourceTemplate.InOrg(defOrg), actions: allActions(), allow: true},
+		},
+	)
+
+	user = subject{
+		UserID: "me",
+		Scope:  must(ScopeRole(ScopeAll)),
+		Groups: []string{"sre"},
+		Roles: []Role{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+			{
+				Name: "org-deny-update:" + defOrg.String(),
+				Org: map[string][]Permission{
+					defOrg.String(): {
+						{
+							Negate:       true,
+							ResourceType: ResourceTemplate.Type,
+							Action:       ActionUpdate,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testAuthorize(t, "OrgDenyOverridesACL", user, []authTestCase{
+		{
+			resource: ResourceTemplate.InOrg(defOrg).WithACLUserList(map[string][]Action{
+				user.UserID: {WildcardSymbol},
+			}),
+			actions: []Action{ActionUpdate},
+			allow:   false,
+		},
+		{
+			resource: ResourceTemplate.InOrg(defOrg).WithGroupACL(map[string][]Action{
+				"sre": {WildcardSymbol},
+			}),
+			actions: []Action{ActionUpdate},
+			allow:   false,
+		},
+		{
+			resource: ResourceTemplate.InOrg(defOrg).WithGroupACL(map[string][]Action{
+				"sre": {WildcardSymbol},
+			}),
+			actions: []Action{ActionRead, ActionDelete},
+			allow:   true,
+		},
+		// The deny only applies in its organization.
+		{
+			resource: ResourceTemplate.InOrg(unusedID).WithACLUserList(map[string][]Action{
+				user.UserID: {WildcardSymbol},
+			}),
+			actions: []Action{ActionUpdate},
+			allow:   true,
+		},
+	})
+}
+
 func TestAuthorizeScope(t *testing.T) {
 	t.Parallel()
 
@@ -789,6 +964,114 @@ func TestAuthorizeScope(t *testing.T) {
 	)
 }
 
+func TestAuthorizeRestrictedScope(t *testing.T) {
+	t.Parallel()
+
+	defOrg := uuid.New()
+	otherOrg := uuid.New()
+	scope, err := RestrictedScope(uuid.NullUUID{UUID: defOrg, Valid: true}, []string{"workspace:read", "template:read"})
+	require.NoError(t, err)
+
+	user := subject{
+		UserID: "me",
+		Roles: []Role{
+			must(RoleByName(RoleOwner())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+			must(RoleByName(RoleOrgMember(otherOrg))),
+		},
+		Scope: must(ScopeRole(scope)),
+	}
+
+	testAuthorize(t, "Admin_RestrictedOrgScope", user,
+		// Allowed by scope:
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionRead}
+			c.allow = true
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID)},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me")},
+			{resource: ResourceTemplate.InOrg(defOrg)},
+		}),
+		// Other actions, resources and organizations are not:
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionCreate, ActionUpdate, ActionDelete}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID)},
+			{resource: ResourceTemplate.InOrg(defOrg)},
+		}),
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionRead}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(otherOrg).WithOwner(user.UserID)},
+			{resource: ResourceWorkspace.WithOwner(user.UserID)},
+			{resource: ResourceUser},
+			{resource: ResourceAPIKey.WithOwner(user.UserID)},
+		}),
+	)
+
+	scope, err = RestrictedScope(uuid.NullUUID{}, []string{"workspace:*"})
+	require.NoError(t, err)
+	user = subject{
+		UserID: "me",
+		Roles: []Role{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+		},
+		Scope: must(ScopeRole(scope)),
+	}
+
+	testAuthorize(t, "User_RestrictedSiteScope", user,
+		// The scope only limits, the roles must still allow the action.
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionCreate, ActionRead, ActionUpdate, ActionDelete}
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.UserID), allow: true},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me"), allow: false},
+			{resource: ResourceTemplate.InOrg(defOrg), allow: false},
+		}),
+	)
+}
+
+func TestRestrictedScope(t *testing.T) {
+	t.Parallel()
+
+	orgID := uuid.New()
+	scope, err := RestrictedScope(uuid.NullUUID{UUID: orgID, Valid: true}, []string{"workspace:read", "template:*"})
+	require.NoError(t, err)
+	require.Equal(t, Scope("restricted:"+orgID.String()+":template:*,workspace:read"), scope)
+
+	role, err := ScopeRole(scope)
+	require.NoError(t, err)
+	require.Empty(t, role.Site)
+	require.Len(t, role.Org[orgID.String()], 2)
+
+	for _, perms := range [][]string{
+		{},
+		{"workspace"},
+		{"workspace:write"},
+		{"Workspace:read"},
+	} {
+		_, err := RestrictedScope(uuid.NullUUID{}, perms)
+		require.Error(t, err, perms)
+	}
+
+	for _, scope := range []Scope{
+		ScopeRestricted,
+		"restricted:not-a-uuid:workspace:read",
+		"restricted::",
+		"restricted::workspace:write",
+	} {
+		_, err := ScopeRole(scope)
+		require.Error(t, err, scope)
+	}
+}
+
 // cases applies a given function to all test cases. This makes generalities easier to create.
 func cases(opt func(c authTestCase) authTestCase, cases []authTestCase) []authTestCase {
 	if opt == nil {
@@ -819,7 +1102,7 @@ func testAuthorize(t *testing.T, name string, subject subject, sets ...[]authTes
 					ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
 					t.Cleanup(cancel)
 
-					authError := authorizer.Authorize(ctx, subject.UserID, subject.Roles, subject.Scope, subject.Groups, a, c.resource)
+					authError := authorizer.Authorize(ctx, subject.UserID, subject.Roles, subject.Scope, subject.Groups, subject.EveryoneExcludedOrgs, a, c.resource)
 
 					d, _ := json.Marshal(map[string]interface{}{
 						"subject": subject,
@@ -842,13 +1125,17 @@ func testAuthorize(t *testing.T, name string, subject subject, sets ...[]authTes
 						assert.Error(t, authError, "expected unauthorized")
 					}
 
-					partialAuthz, err := authorizer.Prepare(ctx, subject.UserID, subject.Roles, subject.Scope, subject.Groups, a, c.resource.Type)
+					partialAuthz, err := authorizer.Prepare(ctx, subject.UserID, subject.Roles, subject.Scope, subject.Groups, subject.EveryoneExcludedOrgs, a, c.resource.Type)
 					require.NoError(t, err, "make prepared authorizer")
 
 					// Ensure the partial can compile to a SQL clause.
 					// This does not guarantee that the clause is valid SQL.
-					_, err = Compile(partialAuthz)
+					filter, err := Compile(partialAuthz)
 					require.NoError(t, err, "compile prepared authorizer")
+					t.Logf("sql: %s", filter.SQLString(DefaultConfig()))
+					// The compiled filter is what AuthorizeFilter and the SQL
+					// filters use, so it must agree with the authorizer.
+					assert.Equal(t, authError == nil, filter.Eval(c.resource), "compiled filter disagrees with authorizer")
 
 					// Also check the rego policy can form a valid partial query result.
 					// This ensures we can convert the queries into SQL WHERE clauses in the future.
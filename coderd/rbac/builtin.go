@@ -15,8 +15,11 @@ const (
 	userAdmin     string = "user-admin"
 	auditor       string = "auditor"
 
-	orgAdmin  string = "organization-admin"
-	orgMember string = "organization-member"
+	orgAdmin   string = "organization-admin"
+	orgMember  string = "organization-member"
+	orgAuditor string = "organization-auditor"
+
+	groupAdmin string = "group-admin"
 )
 
 // The functions below ONLY need to exist for roles that are "defaulted" in some way.
@@ -48,6 +51,22 @@ func RoleOrgMember(organizationID uuid.UUID) string {
 	return roleName(orgMember, organizationID.String())
 }
 
+func RoleOrgAuditor(organizationID uuid.UUID) string {
+	return roleName(orgAuditor, organizationID.String())
+}
+
+// RoleGroupAdmin is assigned to members of a group rather than to users, so
+// it is not a built-in role. Group admins are granted access to the group's
+// membership through the ACL of the group member object.
+func RoleGroupAdmin() string {
+	return groupAdmin
+}
+
+// GroupRoles lists all roles that can be assigned to a member of a group.
+func GroupRoles() []string {
+	return []string{groupAdmin}
+}
+
 var (
 	// builtInRoles are just a hard coded set for now. Ideally we store these in
 	// the database. Right now they are functions because the org id should scope
@@ -180,6 +199,27 @@ var (
 				},
 			}
 		},
+
+		// orgAuditor can read the audit logs and templates of an
+		// organization, without the deployment-wide access of the auditor.
+		orgAuditor: func(organizationID string) Role {
+			return Role{
+				Name:        roleName(orgAuditor, organizationID),
+				DisplayName: "Organization Auditor",
+				Org: map[string][]Permission{
+					organizationID: {
+						{
+							ResourceType: ResourceAuditLog.Type,
+							Action:       ActionRead,
+						},
+						{
+							ResourceType: ResourceTemplate.Type,
+							Action:       ActionRead,
+						},
+					},
+				},
+			}
+		},
 	}
 )
 
@@ -195,6 +235,7 @@ var (
 			member:        true,
 			orgAdmin:      true,
 			orgMember:     true,
+			orgAuditor:    true,
 			templateAdmin: true,
 			userAdmin:     true,
 		},
@@ -203,8 +244,9 @@ var (
 			orgMember: true,
 		},
 		orgAdmin: {
-			orgAdmin:  true,
-			orgMember: true,
+			orgAdmin:   true,
+			orgMember:  true,
+			orgAuditor: true,
 		},
 	}
 )
@@ -0,0 +1,159 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// DecisionLogger is an Authorizer that logs a sample of the decisions it
+// denies, along with the permissions of the subject that apply to the object.
+// It is meant to help debug unexpected 403 and 404 responses, and is opt-in as
+// the permission lookup is not free.
+type DecisionLogger struct {
+	Authorizer
+	logger  slog.Logger
+	percent int
+}
+
+// NewDecisionLogger wraps auth so that percent (0-100) of denied decisions are
+// logged.
+func NewDecisionLogger(auth Authorizer, logger slog.Logger, percent int) *DecisionLogger {
+	return &DecisionLogger{
+		Authorizer: auth,
+		logger:     logger,
+		percent:    percent,
+	}
+}
+
+func (d *DecisionLogger) ByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, everyoneExcluded []string, action Action, object Object) error {
+	err := d.Authorizer.ByRoleName(ctx, subjectID, roleNames, scope, groups, everyoneExcluded, action, object)
+	if err != nil {
+		d.logDenied(ctx, subjectID, roleNames, scope, groups, action, object, err)
+	}
+	return err
+}
+
+func (d *DecisionLogger) PrepareByRoleName(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, everyoneExcluded []string, action Action, objectType string) (PreparedAuthorized, error) {
+	prepared, err := d.Authorizer.PrepareByRoleName(ctx, subjectID, roleNames, scope, groups, everyoneExcluded, action, objectType)
+	if err != nil {
+		return nil, err
+	}
+	return &loggedPrepared{
+		PreparedAuthorized: prepared,
+		logger:             d,
+		subjectID:          subjectID,
+		roleNames:          roleNames,
+		scope:              scope,
+		groups:             groups,
+		action:             action,
+	}, nil
+}
+
+// loggedPrepared logs the objects a prepared authorizer denies, which is how
+// objects are dropped by Filter.
+type loggedPrepared struct {
+	PreparedAuthorized
+	logger    *DecisionLogger
+	subjectID string
+	roleNames []string
+	scope     Scope
+	groups    []string
+	action    Action
+}
+
+func (p *loggedPrepared) Authorize(ctx context.Context, object Object) error {
+	err := p.PreparedAuthorized.Authorize(ctx, object)
+	if err != nil {
+		p.logger.logDenied(ctx, p.subjectID, p.roleNames, p.scope, p.groups, p.action, object, err)
+	}
+	return err
+}
+
+func (d *DecisionLogger) logDenied(ctx context.Context, subjectID string, roleNames []string, scope Scope, groups []string, action Action, object Object, err error) {
+	//nolint:gosec // Sampling doesn't need a secure random source.
+	if d.percent < 100 && rand.Intn(100) >= d.percent {
+		return
+	}
+
+	fields := []slog.Field{
+		slog.F("subject_id", subjectID),
+		slog.F("roles", roleNames),
+		slog.F("groups", groups),
+		slog.F("scope", scope),
+		slog.F("action", action),
+		slog.F("object", object),
+		slog.F("permissions", applicablePermissions(subjectID, roleNames, groups, action, object)),
+		slog.Error(err),
+	}
+	if scopeRole, scopeErr := ScopeRole(scope); scopeErr == nil {
+		fields = append(fields, slog.F("scope_permissions", rolePermissions(subjectID, scopeRole, action, object)))
+	}
+	var unauthorized *UnauthorizedError
+	if xerrors.As(err, &unauthorized) && unauthorized.Internal() != nil {
+		fields = append(fields, slog.F("internal", unauthorized.Internal().Error()))
+	}
+	d.logger.Info(ctx, "authorization denied", fields...)
+}
+
+// applicablePermissions lists the permissions of the subject's roles and the
+// object's ACL that match the action and object, e.g.
+// "organization-admin:<id> org *:*" or "member site deny workspace:delete".
+// An empty list means nothing grants the action.
+func applicablePermissions(subjectID string, roleNames []string, groups []string, action Action, object Object) []string {
+	perms := []string{}
+	for _, name := range roleNames {
+		role, err := RoleByName(name)
+		if err != nil {
+			perms = append(perms, fmt.Sprintf("%s unknown role", name))
+			continue
+		}
+		perms = append(perms, rolePermissions(subjectID, role, action, object)...)
+	}
+
+	for _, act := range object.ACLUserList[subjectID] {
+		if act == action || act == WildcardSymbol {
+			perms = append(perms, fmt.Sprintf("acl user %s", act))
+		}
+	}
+	for _, group := range groups {
+		for _, act := range object.ACLGroupList[group] {
+			if act == action || act == WildcardSymbol {
+				perms = append(perms, fmt.Sprintf("acl group %s %s", group, act))
+			}
+		}
+	}
+	return perms
+}
+
+func rolePermissions(subjectID string, role Role, action Action, object Object) []string {
+	perms := []string{}
+	add := func(level string, list []Permission) {
+		for _, perm := range list {
+			if perm.ResourceType != object.Type && perm.ResourceType != WildcardSymbol {
+				continue
+			}
+			if perm.Action != action && perm.Action != WildcardSymbol {
+				continue
+			}
+			effect := ""
+			if perm.Negate {
+				effect = "deny "
+			}
+			perms = append(perms, fmt.Sprintf("%s %s %s%s:%s", role.Name, level, effect, perm.ResourceType, perm.Action))
+		}
+	}
+
+	add("site", role.Site)
+	if object.OrgID != "" {
+		add("org", role.Org[object.OrgID])
+	}
+	if object.Owner != "" && object.Owner == subjectID {
+		add("user", role.User)
+	}
+	return perms
+}
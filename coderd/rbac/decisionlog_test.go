@@ -0,0 +1,96 @@
+package rbac_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestDecisionLogger(t *testing.T) {
+	t.Parallel()
+
+	orgID := uuid.New()
+	userID := uuid.NewString()
+	roles := []string{rbac.RoleMember(), rbac.RoleOrgMember(orgID)}
+	mine := rbac.ResourceWorkspace.InOrg(orgID).WithOwner(userID)
+	theirs := rbac.ResourceWorkspace.InOrg(orgID).WithOwner(uuid.NewString())
+
+	t.Run("Denied", func(t *testing.T) {
+		t.Parallel()
+		sink := &fakeSink{}
+		auth := rbac.NewDecisionLogger(rbac.NewAuthorizer(), slog.Make(sink), 100)
+
+		err := auth.ByRoleName(context.Background(), userID, roles, rbac.ScopeAll, nil, nil, rbac.ActionRead, mine)
+		require.NoError(t, err)
+		require.Empty(t, sink.entries)
+
+		err = auth.ByRoleName(context.Background(), userID, roles, rbac.ScopeAll, nil, nil, rbac.ActionRead, theirs)
+		require.Error(t, err)
+		require.Len(t, sink.entries, 1)
+		require.Equal(t, "authorization denied", sink.entries[0].Message)
+		fields := entryFields(sink.entries[0])
+		require.Equal(t, userID, fields["subject_id"])
+		require.Equal(t, rbac.Action(rbac.ActionRead), fields["action"])
+		require.Equal(t, theirs, fields["object"])
+		// Nothing grants reading another user's workspace.
+		require.Empty(t, fields["permissions"])
+
+		// Permissions that apply are listed, including denies.
+		err = auth.ByRoleName(context.Background(), userID, []string{rbac.RoleOrgAdmin(orgID), rbac.RoleOrgMember(orgID)}, rbac.ScopeApplicationConnect, nil, nil, rbac.ActionRead, theirs)
+		require.Error(t, err)
+		require.Len(t, sink.entries, 2)
+		fields = entryFields(sink.entries[1])
+		require.Contains(t, fields["permissions"], rbac.RoleOrgAdmin(orgID)+" org *:*")
+		require.Empty(t, fields["scope_permissions"])
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+		sink := &fakeSink{}
+		auth := rbac.NewDecisionLogger(rbac.NewAuthorizer(), slog.Make(sink), 100)
+
+		objects, err := rbac.Filter(context.Background(), auth, userID, roles, rbac.ScopeAll, nil, nil, rbac.ActionRead, []rbac.Object{mine, theirs, theirs})
+		require.NoError(t, err)
+		require.Equal(t, []rbac.Object{mine}, objects)
+		require.Len(t, sink.entries, 2)
+	})
+
+	t.Run("Sampled", func(t *testing.T) {
+		t.Parallel()
+		sink := &fakeSink{}
+		auth := rbac.NewDecisionLogger(rbac.NewAuthorizer(), slog.Make(sink), 0)
+
+		for i := 0; i < 10; i++ {
+			err := auth.ByRoleName(context.Background(), userID, roles, rbac.ScopeAll, nil, nil, rbac.ActionRead, theirs)
+			require.Error(t, err)
+		}
+		require.Empty(t, sink.entries)
+	})
+}
+
+func entryFields(entry slog.SinkEntry) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for _, field := range entry.Fields {
+		fields[field.Name] = field.Value
+	}
+	return fields
+}
+
+type fakeSink struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+func (*fakeSink) Sync() {}
@@ -56,12 +56,23 @@ var (
 
 	// ResourceGroup CRUD. Org admins only.
 	//	create/delete = Make or delete a new group.
-	//	update = Update the name or members of a group.
+	//	update = Update the name and settings of a group, not its members.
 	//	read = Read groups and their members.
 	ResourceGroup = Object{
 		Type: "group",
 	}
 
+	// ResourceGroupMember CRUD. Org admins and the group's admins. Granting
+	// this without ResourceGroup lets a role manage a group's members without
+	// being able to rename or delete the group.
+	//	create/delete = Add or remove members of a group, also when they're
+	//	  replaced in bulk.
+	//	update = Import members and answer join requests.
+	//	read = Read the members of a group.
+	ResourceGroupMember = Object{
+		Type: "group_member",
+	}
+
 	ResourceFile = Object{
 		Type: "file",
 	}
@@ -146,6 +157,29 @@ var (
 	ResourceDeploymentFlags = Object{
 		Type: "deployment_flags",
 	}
+
+	// ResourceOperation is a long-running action started by a user.
+	// 	read = view the progress of the operation
+	ResourceOperation = Object{
+		Type: "operation",
+	}
+
+	// ResourceOAuth2ProviderApp is an application users can sign in to with
+	// Coder.
+	// 	create/delete = register or remove an app
+	// 	read = view apps
+	// 	update = edit an app or regenerate its secret
+	ResourceOAuth2ProviderApp = Object{
+		Type: "oauth2_app",
+	}
+
+	// ResourceWebhook is a deployment-wide webhook.
+	// 	create/delete = register or remove a webhook.
+	// 	read = view webhooks and their delivery attempts
+	// 	update = redeliver an event
+	ResourceWebhook = Object{
+		Type: "webhook",
+	}
 )
 
 // Object is used to create objects for authz checks when you have none in
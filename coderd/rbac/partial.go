@@ -110,16 +110,17 @@ EachQueryLoop:
 	return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), pa.input, nil)
 }
 
-func newPartialAuthorizer(ctx context.Context, subjectID string, roles []Role, scope Role, groups []string, action Action, objectType string) (*PartialAuthorizer, error) {
+func newPartialAuthorizer(ctx context.Context, subjectID string, roles []Role, scope Role, groups []string, everyoneExcluded []string, action Action, objectType string) (*PartialAuthorizer, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
 	input := map[string]interface{}{
 		"subject": authSubject{
-			ID:     subjectID,
-			Roles:  roles,
-			Scope:  scope,
-			Groups: groups,
+			ID:                   subjectID,
+			Roles:                roles,
+			Scope:                scope,
+			Groups:               groups,
+			EveryoneExcludedOrgs: everyoneExcluded,
 		},
 		"object": map[string]string{
 			"type": objectType,
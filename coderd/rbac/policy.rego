@@ -91,7 +91,7 @@ org_members := { orgID |
 default org = 0
 org := org_allow(input.subject.roles)
 default scope_org := 0
-scope_org := org_allow([input.scope])
+scope_org := org_allow([input.subject.scope])
 
 org_allow(roles) := num {
 	allow := { id: num |
@@ -133,8 +133,8 @@ org_ok {
 # the user is apart of the org (if the object has an org).
 default user = 0
 user := user_allow(input.subject.roles)
-default user_scope := 0
-scope_user := user_allow([input.scope])
+default scope_user := 0
+scope_user := user_allow([input.subject.scope])
 
 user_allow(roles) := num {
     input.object.owner != ""
@@ -148,8 +148,14 @@ user_allow(roles) := num {
     num := number(allow)
 }
 
-# The allow block is quite simple. Any set with `-1` cascades down in levels.
-# Authorization looks for any `allow` statement that is true. Multiple can be true!
+# Denies always take precedence over grants. A matching negative permission
+# at any level that applies to the object denies the action, regardless of
+# the grants of other roles or the object's ACL:
+#   - site denies apply to every object.
+#   - org denies apply to the objects of that organization.
+#   - user denies apply to the objects the actor owns.
+# Without a deny, a grant at any level allows the action. Authorization looks
+# for any `allow` statement that is true. Multiple can be true!
 # Note that the absence of `allow` means "unauthorized".
 # An explicit `"allow": true` is required.
 #
@@ -160,13 +166,37 @@ user_allow(roles) := num {
 # Allow query:
 #	 data.authz.role_allow = true data.authz.scope_allow = true
 
+# user_denied is true if the actor's roles deny the action on objects they
+# own. It doesn't depend on the object, so it's known during partial
+# evaluation.
+user_denied {
+	perm := input.subject.roles[_].user[_]
+	perm.action in [input.action, "*"]
+	perm.resource_type in [input.object.type, "*"]
+	perm.negate
+}
+
+# user_deny_free is true if no user level deny applies to the object. It's
+# written without negating the unknown owner, so the policy still compresses
+# to simple queries.
+user_deny_free {
+	not user_denied
+}
+
+user_deny_free {
+	input.object.owner != input.subject.id
+}
+
 role_allow {
 	site = 1
+	not org = -1
+	user_deny_free
 }
 
 role_allow {
 	not site = -1
 	org = 1
+	user_deny_free
 }
 
 role_allow {
@@ -215,9 +245,16 @@ acl_allow {
 	[input.action, "*"][_] in perms
 }
 
+# everyone_excluded is the list of organizations whose 'all_users' group the
+# actor has been excluded from.
+everyone_excluded := { orgID |
+	orgID := input.subject.everyone_excluded_orgs[_]
+}
+
 # ACL for 'all_users' special group
 acl_allow {
 	org_mem
+	input.object.org_owner in (org_members - everyone_excluded)
 	perms := input.object.acl_group_list[input.object.org_owner]
 	[input.action, "*"][_] in perms
 }
@@ -232,8 +269,12 @@ allow {
 	scope_allow
 }
 
-# ACL list must also have the scope_allow to pass
+# ACL list must also have the scope_allow to pass, and denies take
+# precedence over it too.
 allow {
 	acl_allow
+	not site = -1
+	not org = -1
+	user_deny_free
 	scope_allow
 }
@@ -199,6 +199,21 @@ func processQuery(query ast.Body) (Expression, error) {
 }
 
 func processExpression(expr *ast.Expr) (Expression, error) {
+	if expr.Negated {
+		// Negated expressions come from deny permissions, such as
+		// 'not "<org_id>" = input.object.org_owner'.
+		positive := expr.Copy()
+		positive.Negated = false
+		exp, err := processExpression(positive)
+		if err != nil {
+			return nil, xerrors.Errorf("negated expression: %w", err)
+		}
+		return &expNot{
+			base:       base{Rego: expr.String()},
+			Expression: exp,
+		}, nil
+	}
+
 	if !expr.IsCall() {
 		// This could be a single term that is a valid expression.
 		if term, ok := expr.Terms.(*ast.Term); ok {
@@ -422,6 +437,15 @@ func (t expOr) SQLString(cfg SQLConfig) string {
 	return "(" + strings.Join(exprs, " OR ") + ")"
 }
 
+type expNot struct {
+	base
+	Expression Expression
+}
+
+func (t expNot) SQLString(cfg SQLConfig) string {
+	return "(NOT " + t.Expression.SQLString(cfg) + ")"
+}
+
 // Operator joins terms together to form an expression.
 // Operators are also expressions.
 //
@@ -78,6 +78,16 @@ func TestCompileQuery(t *testing.T) {
 			expression.SQLString(DefaultConfig()), "literal dereference")
 	})
 
+	t.Run("Negated", func(t *testing.T) {
+		t.Parallel()
+		expression, err := Compile(partialQueries(t,
+			`not "4d30d4a8-b87d-45ac-b0d4-51b2e68e7e75" = input.object.org_owner; input.object.owner != "me"`,
+		))
+		require.NoError(t, err, "compile")
+		require.Equal(t, `((NOT '4d30d4a8-b87d-45ac-b0d4-51b2e68e7e75' = organization_id :: text) AND owner_id :: text != 'me')`,
+			expression.SQLString(DefaultConfig()), "negated")
+	})
+
 	t.Run("NoACLColumns", func(t *testing.T) {
 		t.Parallel()
 		expression, err := Compile(partialQueries(t,
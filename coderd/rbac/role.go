@@ -2,7 +2,8 @@ package rbac
 
 // Permission is the format passed into the rego.
 type Permission struct {
-	// Negate makes this a negative permission
+	// Negate makes this a negative permission. Negative permissions take
+	// precedence over the positive permissions of every role and level.
 	Negate       bool   `json:"negate"`
 	ResourceType string `json:"resource_type"`
 	Action       Action `json:"action"`
@@ -2,7 +2,11 @@ package rbac
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 )
 
@@ -11,6 +15,10 @@ type Scope string
 const (
 	ScopeAll                Scope = "all"
 	ScopeApplicationConnect Scope = "application_connect"
+	// ScopeRestricted only allows a fixed set of actions on resource types,
+	// optionally within a single organization. Use RestrictedScope to build
+	// one, the bare name is not a valid scope.
+	ScopeRestricted Scope = "restricted"
 )
 
 var builtinScopes map[Scope]Role = map[Scope]Role{
@@ -38,9 +46,105 @@ var builtinScopes map[Scope]Role = map[Scope]Role{
 }
 
 func ScopeRole(scope Scope) (Role, error) {
+	if strings.HasPrefix(string(scope), string(ScopeRestricted)+":") {
+		return restrictedScopeRole(scope)
+	}
 	role, ok := builtinScopes[scope]
 	if !ok {
 		return Role{}, xerrors.Errorf("no scope named %q", scope)
 	}
 	return role, nil
 }
+
+var scopeResourceRegex = regexp.MustCompile(`^([a-z_]+|\*)$`)
+
+// ParseScopePermission splits a "<resource>:<action>" permission of a
+// restricted scope, e.g. "workspace:read" or "template:*".
+func ParseScopePermission(permission string) (resourceType string, action Action, err error) {
+	resourceType, act, ok := strings.Cut(permission, ":")
+	if !ok {
+		return "", "", xerrors.Errorf("permission %q must be formatted as \"<resource>:<action>\"", permission)
+	}
+	if !scopeResourceRegex.MatchString(resourceType) {
+		return "", "", xerrors.Errorf("invalid resource type %q", resourceType)
+	}
+	switch act {
+	case ActionCreate, ActionRead, ActionUpdate, ActionDelete, WildcardSymbol:
+	default:
+		return "", "", xerrors.Errorf("invalid action %q, must be one of create, read, update, delete or *", act)
+	}
+	return resourceType, Action(act), nil
+}
+
+// RestrictedScope returns a scope that only allows the given
+// "<resource>:<action>" permissions. If organizationID is set, the
+// permissions only apply to objects in that organization.
+//
+// The permissions are encoded into the scope name, in the same way org roles
+// carry their organization in the role name.
+func RestrictedScope(organizationID uuid.NullUUID, permissions []string) (Scope, error) {
+	if len(permissions) == 0 {
+		return "", xerrors.New("a restricted scope needs at least one permission")
+	}
+	for _, permission := range permissions {
+		_, _, err := ParseScopePermission(permission)
+		if err != nil {
+			return "", err
+		}
+	}
+	sorted := append([]string{}, permissions...)
+	sort.Strings(sorted)
+
+	orgID := ""
+	if organizationID.Valid {
+		orgID = organizationID.UUID.String()
+	}
+	return Scope(fmt.Sprintf("%s:%s:%s", ScopeRestricted, orgID, strings.Join(sorted, ","))), nil
+}
+
+// restrictedScopeRole builds the role of a scope created with RestrictedScope.
+func restrictedScopeRole(scope Scope) (Role, error) {
+	// restricted:<org_id>:<resource>:<action>,<resource>:<action>
+	parts := strings.SplitN(string(scope), ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return Role{}, xerrors.Errorf("malformed restricted scope %q", scope)
+	}
+	orgID := parts[1]
+	if orgID != "" {
+		if _, err := uuid.Parse(orgID); err != nil {
+			return Role{}, xerrors.Errorf("malformed restricted scope %q: invalid organization: %w", scope, err)
+		}
+	}
+
+	perms := make(map[string][]Action)
+	for _, permission := range strings.Split(parts[2], ",") {
+		resourceType, action, err := ParseScopePermission(permission)
+		if err != nil {
+			return Role{}, xerrors.Errorf("malformed restricted scope %q: %w", scope, err)
+		}
+		perms[resourceType] = append(perms[resourceType], action)
+	}
+
+	role := Role{
+		Name:        fmt.Sprintf("Scope_%s", ScopeRestricted),
+		DisplayName: "Restricted to " + parts[2],
+		Site:        []Permission{},
+		Org:         map[string][]Permission{},
+		User:        []Permission{},
+	}
+	if orgID == "" {
+		role.Site = permissions(perms)
+	} else {
+		role.Org[orgID] = permissions(perms)
+	}
+	return role, nil
+}
+
+// ScopeRoles lists the roles of all API key scopes.
+func ScopeRoles() []Role {
+	roles := make([]Role, 0, len(builtinScopes))
+	for _, role := range builtinScopes {
+		roles = append(roles, role)
+	}
+	return roles
+}
@@ -0,0 +1,421 @@
+package coderd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) postRoleRequest(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		user   = httpmw.UserParam(r)
+		apiKey = httpmw.APIKey(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceUserData.WithOwner(user.ID.String())) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if apiKey.UserID != user.ID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You can only request roles for yourself.",
+		})
+		return
+	}
+
+	var req codersdk.CreateRoleRequestRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	organizationID, ok := requestableRole(req.Role)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Role %q cannot be requested.", req.Role),
+			Validations: []codersdk.ValidationError{{
+				Field:  "role",
+				Detail: "Must be a site or organization role other than member.",
+			}},
+		})
+		return
+	}
+
+	roles := user.RBACRoles
+	if organizationID.Valid {
+		member, err := api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+			OrganizationID: organizationID.UUID,
+			UserID:         user.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("You must be a member of organization %q to request its roles.", organizationID.UUID),
+				Code:    codersdk.ErrorCodeOrgMemberRequired,
+			})
+			return
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		roles = member.Roles
+	}
+	if slices.Contains(roles, req.Role) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("You already have the role %q.", req.Role),
+		})
+		return
+	}
+
+	request, err := api.Database.InsertRoleRequest(ctx, database.InsertRoleRequestParams{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		OrganizationID: organizationID,
+		Role:           req.Role,
+		Reason:         req.Reason,
+		CreatedAt:      database.Now(),
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("You have already requested the role %q.", req.Role),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertRoleRequest(request, user.Username))
+}
+
+func (api *API) userRoleRequests(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceUserData.WithOwner(user.ID.String())) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	requests, err := api.Database.GetRoleRequestsByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.RoleRequest, 0, len(requests))
+	for _, request := range requests {
+		resp = append(resp, convertRoleRequest(request, user.Username))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// pendingRoleRequests returns the queue of pending role requests the user can
+// approve.
+func (api *API) pendingRoleRequests(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		apiKey = httpmw.APIKey(r)
+	)
+
+	requests, err := api.Database.GetPendingRoleRequests(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	reviewable := make([]database.RoleRequest, 0, len(requests))
+	userIDs := make([]uuid.UUID, 0, len(requests))
+	for _, request := range requests {
+		if !api.canReviewRoleRequest(r, request) || request.UserID == apiKey.UserID {
+			continue
+		}
+		reviewable = append(reviewable, request)
+		userIDs = append(userIDs, request.UserID)
+	}
+
+	usernames := make(map[uuid.UUID]string, len(userIDs))
+	if len(userIDs) > 0 {
+		users, err := api.Database.GetUsersByIDs(ctx, userIDs)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+	}
+
+	resp := make([]codersdk.RoleRequest, 0, len(reviewable))
+	for _, request := range reviewable {
+		resp = append(resp, convertRoleRequest(request, usernames[request.UserID]))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) approveRoleRequest(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		request = httpmw.RoleRequestParam(r)
+		apiKey  = httpmw.APIKey(r)
+		auditor = *api.Auditor.Load()
+	)
+
+	if !api.canReviewRoleRequest(r, request) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !roleRequestPending(rw, r, request) {
+		return
+	}
+	if apiKey.UserID == request.UserID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You cannot approve your own role request.",
+		})
+		return
+	}
+
+	requester, err := api.Database.GetUserByID(ctx, request.UserID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	additionalFields, err := json.Marshal(map[string]string{
+		"role_request_id": request.ID.String(),
+		"role":            request.Role,
+		"username":        requester.Username,
+		"reason":          request.Reason,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "marshal role request audit fields", slog.Error(err))
+	}
+	auditParams := &audit.RequestParams{
+		Audit:            auditor,
+		Log:              api.Logger,
+		Request:          r,
+		Action:           database.AuditActionWrite,
+		AdditionalFields: additionalFields,
+	}
+
+	var reviewed database.RoleRequest
+	reviewParams := database.UpdateRoleRequestStatusParams{
+		Status:     database.RoleRequestStatusApproved,
+		ReviewedBy: uuid.NullUUID{UUID: apiKey.UserID, Valid: true},
+		ReviewedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		ID:         request.ID,
+	}
+	if !request.OrganizationID.Valid {
+		aReq, commitAudit := audit.InitRequest[database.User](rw, auditParams)
+		defer commitAudit()
+		aReq.Old = requester
+
+		err = api.Database.InTx(func(tx database.Store) error {
+			var err error
+			reviewed, err = tx.UpdateRoleRequestStatus(ctx, reviewParams)
+			if err != nil {
+				return xerrors.Errorf("update role request: %w", err)
+			}
+			aReq.New, err = tx.UpdateUserRoles(ctx, database.UpdateUserRolesParams{
+				GrantedRoles: withRole(requester.RBACRoles, request.Role),
+				ID:           requester.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("update user roles: %w", err)
+			}
+			return nil
+		})
+	} else {
+		// The user may have left the organization since making the request.
+		var member database.OrganizationMember
+		member, err = api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+			OrganizationID: request.OrganizationID.UUID,
+			UserID:         request.UserID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+				Message: fmt.Sprintf("User %q must be a member of organization %q", requester.Username, request.OrganizationID.UUID),
+				Code:    codersdk.ErrorCodeOrgMemberRequired,
+			})
+			return
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+
+		aReq, commitAudit := audit.InitRequest[database.OrganizationMember](rw, auditParams)
+		defer commitAudit()
+		aReq.Old = member
+
+		err = api.Database.InTx(func(tx database.Store) error {
+			var err error
+			reviewed, err = tx.UpdateRoleRequestStatus(ctx, reviewParams)
+			if err != nil {
+				return xerrors.Errorf("update role request: %w", err)
+			}
+			aReq.New, err = tx.UpdateMemberRoles(ctx, database.UpdateMemberRolesParams{
+				GrantedRoles: withRole(member.Roles, request.Role),
+				UserID:       member.UserID,
+				OrgID:        member.OrganizationID,
+			})
+			if err != nil {
+				return xerrors.Errorf("update member roles: %w", err)
+			}
+			return nil
+		})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		// Another reviewer got there first.
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "Role request has already been reviewed.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if request.OrganizationID.Valid {
+		api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionUpdated, request.OrganizationID.UUID, request.UserID)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertRoleRequest(reviewed, requester.Username))
+}
+
+func (api *API) denyRoleRequest(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		request = httpmw.RoleRequestParam(r)
+		apiKey  = httpmw.APIKey(r)
+	)
+
+	if !api.canReviewRoleRequest(r, request) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !roleRequestPending(rw, r, request) {
+		return
+	}
+
+	requester, err := api.Database.GetUserByID(ctx, request.UserID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	reviewed, err := api.Database.UpdateRoleRequestStatus(ctx, database.UpdateRoleRequestStatusParams{
+		Status:     database.RoleRequestStatusDenied,
+		ReviewedBy: uuid.NullUUID{UUID: apiKey.UserID, Valid: true},
+		ReviewedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		ID:         request.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "Role request has already been reviewed.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertRoleRequest(reviewed, requester.Username))
+}
+
+// canReviewRoleRequest returns true if the user is allowed to assign the
+// requested role, which is what approving the request does.
+func (api *API) canReviewRoleRequest(r *http.Request, request database.RoleRequest) bool {
+	object := rbac.ResourceRoleAssignment
+	if request.OrganizationID.Valid {
+		object = rbac.ResourceOrgRoleAssignment.InOrg(request.OrganizationID.UUID)
+	}
+	if !api.Authorize(r, rbac.ActionCreate, object) {
+		return false
+	}
+	return rbac.CanAssignRole(httpmw.UserAuthorization(r).Roles, request.Role)
+}
+
+func roleRequestPending(rw http.ResponseWriter, r *http.Request, request database.RoleRequest) bool {
+	if request.Status == database.RoleRequestStatusPending {
+		return true
+	}
+	httpapi.Write(r.Context(), rw, http.StatusConflict, codersdk.Response{
+		Message: fmt.Sprintf("Role request has already been %s.", request.Status),
+	})
+	return false
+}
+
+// requestableRole returns the organization of the role if users are allowed
+// to request it. Member roles are implied, and hidden roles are managed
+// elsewhere, so neither can be requested.
+func requestableRole(roleName string) (uuid.NullUUID, bool) {
+	orgIDStr, isOrgRole := rbac.IsOrgRole(roleName)
+	if !isOrgRole {
+		if roleName == rbac.RoleMember() {
+			return uuid.NullUUID{}, false
+		}
+		return uuid.NullUUID{}, slices.IndexFunc(rbac.SiteRoles(), func(role rbac.Role) bool {
+			return role.Name == roleName && role.DisplayName != ""
+		}) >= 0
+	}
+
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil || roleName == rbac.RoleOrgMember(orgID) {
+		return uuid.NullUUID{}, false
+	}
+	return uuid.NullUUID{UUID: orgID, Valid: true}, slices.IndexFunc(rbac.OrganizationRoles(orgID), func(role rbac.Role) bool {
+		return role.Name == roleName && role.DisplayName != ""
+	}) >= 0
+}
+
+// withRole returns a copy of roles that includes role.
+func withRole(roles []string, role string) []string {
+	if slices.Contains(roles, role) {
+		return roles
+	}
+	return append(slices.Clone(roles), role)
+}
+
+func convertRoleRequest(request database.RoleRequest, username string) codersdk.RoleRequest {
+	converted := codersdk.RoleRequest{
+		ID:        request.ID,
+		UserID:    request.UserID,
+		Username:  username,
+		Role:      codersdk.Role{Name: request.Role},
+		Reason:    request.Reason,
+		Status:    codersdk.RoleRequestStatus(request.Status),
+		CreatedAt: request.CreatedAt,
+	}
+	if role, err := rbac.RoleByName(request.Role); err == nil {
+		converted.Role = convertRole(role)
+	}
+	if request.OrganizationID.Valid {
+		converted.OrganizationID = &request.OrganizationID.UUID
+	}
+	if request.ReviewedBy.Valid {
+		converted.ReviewedBy = &request.ReviewedBy.UUID
+	}
+	if request.ReviewedAt.Valid {
+		converted.ReviewedAt = &request.ReviewedAt.Time
+	}
+	return converted
+}
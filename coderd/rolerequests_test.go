@@ -0,0 +1,231 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRoleRequests(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ApproveSiteRole", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		first := coderdtest.CreateFirstUser(t, client)
+		member, memberUser := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		request, err := member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role:   rbac.RoleTemplateAdmin(),
+			Reason: "I maintain our templates.",
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleRequestStatusPending, request.Status)
+		require.Equal(t, "Template Admin", request.Role.DisplayName)
+		require.Nil(t, request.OrganizationID)
+
+		// A second request for the same role is a conflict.
+		_, err = member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleTemplateAdmin(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		// Members can't see or approve the queue.
+		pending, err := member.PendingRoleRequests(ctx)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		_, err = member.ApproveRoleRequest(ctx, request.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		pending, err = client.PendingRoleRequests(ctx)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, request.ID, pending[0].ID)
+		require.Equal(t, memberUser.Username, pending[0].Username)
+
+		approved, err := client.ApproveRoleRequest(ctx, request.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleRequestStatusApproved, approved.Status)
+		require.Equal(t, first.UserID, *approved.ReviewedBy)
+		require.NotNil(t, approved.ReviewedAt)
+
+		roles, err := client.GetUserRoles(ctx, memberUser.ID.String())
+		require.NoError(t, err)
+		require.Contains(t, roles.Roles, rbac.RoleTemplateAdmin())
+
+		lastLog := auditor.AuditLogs[len(auditor.AuditLogs)-1]
+		require.Equal(t, database.ResourceTypeUser, lastLog.ResourceType)
+		require.Equal(t, memberUser.ID, lastLog.ResourceID)
+		require.Contains(t, string(lastLog.AdditionalFields), request.ID.String())
+
+		// Reviewed requests leave the queue, but stay in the user's history.
+		pending, err = client.PendingRoleRequests(ctx)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		history, err := member.RoleRequests(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.Equal(t, codersdk.RoleRequestStatusApproved, history[0].Status)
+
+		_, err = client.DenyRoleRequest(ctx, request.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("ApproveOrganizationRole", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		first := coderdtest.CreateFirstUser(t, client)
+		orgAdmin := coderdtest.CreateAnotherUser(t, client, first.OrganizationID, rbac.RoleOrgAdmin(first.OrganizationID))
+		member, memberUser := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		orgRequest, err := member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleOrgAuditor(first.OrganizationID),
+		})
+		require.NoError(t, err)
+		require.Equal(t, first.OrganizationID, *orgRequest.OrganizationID)
+		_, err = member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleTemplateAdmin(),
+		})
+		require.NoError(t, err)
+
+		// Organization admins only see the requests for their organization.
+		pending, err := orgAdmin.PendingRoleRequests(ctx)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, orgRequest.ID, pending[0].ID)
+
+		_, err = orgAdmin.ApproveRoleRequest(ctx, orgRequest.ID)
+		require.NoError(t, err)
+
+		res, err := client.OrganizationMembers(ctx, first.OrganizationID, codersdk.OrganizationMembersRequest{})
+		require.NoError(t, err)
+		for _, m := range res.Members {
+			if m.UserID != memberUser.ID {
+				continue
+			}
+			names := make([]string, 0, len(m.Roles))
+			for _, role := range m.Roles {
+				names = append(names, role.Name)
+			}
+			require.Contains(t, names, rbac.RoleOrgAuditor(first.OrganizationID))
+		}
+
+		lastLog := auditor.AuditLogs[len(auditor.AuditLogs)-1]
+		require.Equal(t, database.ResourceTypeOrganizationMember, lastLog.ResourceType)
+		require.Equal(t, first.OrganizationID, lastLog.OrganizationID)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		request, err := member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleUserAdmin(),
+		})
+		require.NoError(t, err)
+
+		denied, err := client.DenyRoleRequest(ctx, request.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleRequestStatusDenied, denied.Status)
+
+		roles, err := member.GetUserRoles(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.NotContains(t, roles.Roles, rbac.RoleUserAdmin())
+
+		// The role can be requested again once the request is closed.
+		_, err = member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleUserAdmin(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("OwnRequest", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		orgAdmin := coderdtest.CreateAnotherUser(t, client, first.OrganizationID, rbac.RoleOrgAdmin(first.OrganizationID))
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		// Organization admins can assign the auditor role, but not to
+		// themselves.
+		request, err := orgAdmin.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleOrgAuditor(first.OrganizationID),
+		})
+		require.NoError(t, err)
+
+		pending, err := orgAdmin.PendingRoleRequests(ctx)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+
+		_, err = orgAdmin.ApproveRoleRequest(ctx, request.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		member, memberUser := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+		other, err := client.CreateOrganization(context.Background(), codersdk.CreateOrganizationRequest{Name: "other"})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		for _, role := range []string{
+			"not-a-role",
+			rbac.RoleMember(),
+			rbac.RoleOrgMember(first.OrganizationID),
+		} {
+			_, err := member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{Role: role})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr, role)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), role)
+		}
+
+		// Organization roles require membership.
+		_, err = member.CreateRoleRequest(ctx, codersdk.Me, codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleOrgAdmin(other.ID),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// Roles are only requested for yourself.
+		_, err = client.CreateRoleRequest(ctx, memberUser.ID.String(), codersdk.CreateRoleRequestRequest{
+			Role: rbac.RoleTemplateAdmin(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
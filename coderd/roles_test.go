@@ -58,7 +58,8 @@ func TestListRoles(t *testing.T) {
 				return member.ListOrganizationRoles(ctx, admin.OrganizationID)
 			},
 			ExpectedRoles: convertRoles(map[string]bool{
-				rbac.RoleOrgAdmin(admin.OrganizationID): false,
+				rbac.RoleOrgAdmin(admin.OrganizationID):   false,
+				rbac.RoleOrgAuditor(admin.OrganizationID): false,
 			}),
 		},
 		{
@@ -87,7 +88,8 @@ func TestListRoles(t *testing.T) {
 				return orgAdmin.ListOrganizationRoles(ctx, admin.OrganizationID)
 			},
 			ExpectedRoles: convertRoles(map[string]bool{
-				rbac.RoleOrgAdmin(admin.OrganizationID): true,
+				rbac.RoleOrgAdmin(admin.OrganizationID):   true,
+				rbac.RoleOrgAuditor(admin.OrganizationID): true,
 			}),
 		},
 		{
@@ -116,7 +118,8 @@ func TestListRoles(t *testing.T) {
 				return client.ListOrganizationRoles(ctx, admin.OrganizationID)
 			},
 			ExpectedRoles: convertRoles(map[string]bool{
-				rbac.RoleOrgAdmin(admin.OrganizationID): true,
+				rbac.RoleOrgAdmin(admin.OrganizationID):   true,
+				rbac.RoleOrgAuditor(admin.OrganizationID): true,
 			}),
 		},
 	}
@@ -0,0 +1,573 @@
+package coderd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/yamux"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"nhooyr.io/websocket"
+	"storj.io/drpc/drpcerr"
+	"storj.io/drpc/drpcmux"
+	"storj.io/drpc/drpcserver"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/proto"
+	"github.com/coder/coder/provisionersdk"
+)
+
+// rpc serves the dRPC service defined in codersdk/proto over a websocket,
+// for automation that wants typed clients and streaming. Calls are sent to
+// the REST API in-process with the authentication of the websocket, so
+// they're authorized, validated, and audited like REST requests.
+func (api *API) rpc(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	api.websocketWaitMutex.Lock()
+	api.websocketWaitGroup.Add(1)
+	api.websocketWaitMutex.Unlock()
+	defer api.websocketWaitGroup.Done()
+
+	conn, err := websocket.Accept(rw, r, &websocket.AcceptOptions{
+		// Multiplexed messages don't compress well.
+		CompressionMode: websocket.CompressionDisabled,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to accept websocket.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Messages can be larger than the default limit of 32KiB.
+	conn.SetReadLimit(provisionersdk.MaxMessageSize)
+	go httpapi.Heartbeat(ctx, conn)
+
+	ctx, wsNetConn := websocketNetConn(ctx, conn, websocket.MessageBinary)
+	defer wsNetConn.Close() // Also closes conn.
+
+	config := yamux.DefaultConfig()
+	config.LogOutput = io.Discard
+	session, err := yamux.Server(wsNetConn, config)
+	if err != nil {
+		_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("multiplex server: %s", err))
+		return
+	}
+	mux := drpcmux.New()
+	err = proto.DRPCRegisterCoder(mux, &rpcServer{api: api, r: r})
+	if err != nil {
+		_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("register rpc server: %s", err))
+		return
+	}
+	server := drpcserver.NewWithOptions(mux, drpcserver.Options{
+		Log: func(err error) {
+			if xerrors.Is(err, io.EOF) {
+				return
+			}
+			api.Logger.Debug(ctx, "drpc server error", slog.Error(err))
+		},
+	})
+	err = server.Serve(ctx, session)
+	if err != nil && !xerrors.Is(err, io.EOF) && !xerrors.Is(err, context.Canceled) {
+		api.Logger.Debug(ctx, "rpc connection closed", slog.Error(err))
+	}
+}
+
+// GRPCServer returns a gRPC server for the service defined in codersdk/proto.
+// Each call is authenticated with its own "Coder-Session-Token" or
+// "Authorization" metadata, and is sent to the REST API in-process like a
+// dRPC call.
+func (api *API) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	proto.RegisterCoderServer(server, &grpcServer{api: api})
+	return server
+}
+
+// grpcServer serves the rpcServer methods over gRPC.
+type grpcServer struct {
+	proto.UnimplementedCoderServer
+	api *API
+}
+
+// rpc returns the server for a call, authenticated with its metadata.
+func (s *grpcServer) rpc(ctx context.Context) *rpcServer {
+	r := &http.Request{
+		URL:    &url.URL{},
+		Header: http.Header{},
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, name := range []string{"Cookie", "Authorization", "User-Agent", codersdk.SessionCustomHeader} {
+		if values := md.Get(name); len(values) > 0 {
+			r.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if values := md.Get(":authority"); len(values) > 0 {
+		r.Host = values[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+	return &rpcServer{api: s.api, r: r}
+}
+
+func (s *grpcServer) GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.User, error) {
+	resp, err := s.rpc(ctx).GetUser(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) ListUsers(ctx context.Context, req *proto.ListUsersRequest) (*proto.ListUsersResponse, error) {
+	resp, err := s.rpc(ctx).ListUsers(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) GetWorkspace(ctx context.Context, req *proto.GetWorkspaceRequest) (*proto.Workspace, error) {
+	resp, err := s.rpc(ctx).GetWorkspace(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) ListWorkspaces(ctx context.Context, req *proto.ListWorkspacesRequest) (*proto.ListWorkspacesResponse, error) {
+	resp, err := s.rpc(ctx).ListWorkspaces(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) GetWorkspaceBuild(ctx context.Context, req *proto.GetWorkspaceBuildRequest) (*proto.WorkspaceBuild, error) {
+	resp, err := s.rpc(ctx).GetWorkspaceBuild(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) CreateWorkspaceBuild(ctx context.Context, req *proto.CreateWorkspaceBuildRequest) (*proto.WorkspaceBuild, error) {
+	resp, err := s.rpc(ctx).CreateWorkspaceBuild(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) StreamWorkspaceBuildLogs(req *proto.StreamWorkspaceBuildLogsRequest, stream proto.Coder_StreamWorkspaceBuildLogsServer) error {
+	return grpcError(s.rpc(stream.Context()).streamWorkspaceBuildLogs(req, stream))
+}
+
+func (s *grpcServer) GetGroup(ctx context.Context, req *proto.GetGroupRequest) (*proto.Group, error) {
+	resp, err := s.rpc(ctx).GetGroup(ctx, req)
+	return resp, grpcError(err)
+}
+
+func (s *grpcServer) ListGroups(ctx context.Context, req *proto.ListGroupsRequest) (*proto.ListGroupsResponse, error) {
+	resp, err := s.rpc(ctx).ListGroups(ctx, req)
+	return resp, grpcError(err)
+}
+
+// grpcError converts the HTTP status code of a failed call to the closest
+// gRPC status code.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Unknown
+	switch statusCode := drpcerr.Code(err); {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case statusCode == http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case statusCode == http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case statusCode == http.StatusForbidden:
+		code = codes.PermissionDenied
+	case statusCode == http.StatusNotFound:
+		code = codes.NotFound
+	case statusCode == http.StatusConflict:
+		code = codes.AlreadyExists
+	case statusCode == http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case statusCode == http.StatusNotImplemented:
+		code = codes.Unimplemented
+	case statusCode == http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case statusCode >= 500:
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
+
+// rpcServer implements the dRPC service on top of the REST API.
+type rpcServer struct {
+	api *API
+	// r is the request that opened the websocket, or is built from the
+	// metadata of a gRPC call. Calls are authenticated the same.
+	r *http.Request
+}
+
+func (s *rpcServer) GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.User, error) {
+	var user codersdk.User
+	err := s.call(ctx, http.MethodGet, "/api/v2/users/"+url.PathEscape(req.User), nil, nil, &user)
+	if err != nil {
+		return nil, err
+	}
+	return convertRPCUser(user), nil
+}
+
+func (s *rpcServer) ListUsers(ctx context.Context, req *proto.ListUsersRequest) (*proto.ListUsersResponse, error) {
+	query := url.Values{}
+	if req.Query != "" {
+		query.Set("q", req.Query)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", fmt.Sprint(req.Limit))
+	}
+	if req.Offset > 0 {
+		query.Set("offset", fmt.Sprint(req.Offset))
+	}
+	var users []codersdk.User
+	err := s.call(ctx, http.MethodGet, "/api/v2/users", query, nil, &users)
+	if err != nil {
+		return nil, err
+	}
+	resp := &proto.ListUsersResponse{Users: make([]*proto.User, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, convertRPCUser(user))
+	}
+	return resp, nil
+}
+
+func (s *rpcServer) GetWorkspace(ctx context.Context, req *proto.GetWorkspaceRequest) (*proto.Workspace, error) {
+	var workspace codersdk.Workspace
+	err := s.call(ctx, http.MethodGet, "/api/v2/workspaces/"+url.PathEscape(req.Id), nil, nil, &workspace)
+	if err != nil {
+		return nil, err
+	}
+	return convertRPCWorkspace(workspace), nil
+}
+
+func (s *rpcServer) ListWorkspaces(ctx context.Context, req *proto.ListWorkspacesRequest) (*proto.ListWorkspacesResponse, error) {
+	var filters []string
+	if req.Owner != "" {
+		filters = append(filters, fmt.Sprintf("owner:%q", req.Owner))
+	}
+	if req.Name != "" {
+		filters = append(filters, fmt.Sprintf("name:%q", req.Name))
+	}
+	if req.Template != "" {
+		filters = append(filters, fmt.Sprintf("template:%q", req.Template))
+	}
+	var workspaces []codersdk.Workspace
+	err := s.call(ctx, http.MethodGet, "/api/v2/workspaces", url.Values{"q": {strings.Join(filters, " ")}}, nil, &workspaces)
+	if err != nil {
+		return nil, err
+	}
+	resp := &proto.ListWorkspacesResponse{Workspaces: make([]*proto.Workspace, 0, len(workspaces))}
+	for _, workspace := range workspaces {
+		resp.Workspaces = append(resp.Workspaces, convertRPCWorkspace(workspace))
+	}
+	return resp, nil
+}
+
+func (s *rpcServer) GetWorkspaceBuild(ctx context.Context, req *proto.GetWorkspaceBuildRequest) (*proto.WorkspaceBuild, error) {
+	var build codersdk.WorkspaceBuild
+	err := s.call(ctx, http.MethodGet, "/api/v2/workspacebuilds/"+url.PathEscape(req.Id), nil, nil, &build)
+	if err != nil {
+		return nil, err
+	}
+	return convertRPCWorkspaceBuild(build), nil
+}
+
+func (s *rpcServer) CreateWorkspaceBuild(ctx context.Context, req *proto.CreateWorkspaceBuildRequest) (*proto.WorkspaceBuild, error) {
+	body := codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransition(req.Transition),
+	}
+	if req.TemplateVersionId != "" {
+		versionID, err := uuid.Parse(req.TemplateVersionId)
+		if err != nil {
+			return nil, drpcerr.WithCode(xerrors.Errorf("invalid template version ID: %w", err), http.StatusBadRequest)
+		}
+		body.TemplateVersionID = versionID
+	}
+	var build codersdk.WorkspaceBuild
+	err := s.call(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/builds", url.PathEscape(req.WorkspaceId)), nil, body, &build)
+	if err != nil {
+		return nil, err
+	}
+	return convertRPCWorkspaceBuild(build), nil
+}
+
+func (s *rpcServer) StreamWorkspaceBuildLogs(req *proto.StreamWorkspaceBuildLogsRequest, stream proto.DRPCCoder_StreamWorkspaceBuildLogsStream) error {
+	return s.streamWorkspaceBuildLogs(req, stream)
+}
+
+// rpcBuildLogStream is the stream of build logs of both dRPC and gRPC.
+type rpcBuildLogStream interface {
+	Context() context.Context
+	Send(*proto.WorkspaceBuildLog) error
+}
+
+func (s *rpcServer) streamWorkspaceBuildLogs(req *proto.StreamWorkspaceBuildLogsRequest, stream rpcBuildLogStream) error {
+	ctx := stream.Context()
+	// Fetching the build authorizes reading its logs.
+	var build codersdk.WorkspaceBuild
+	err := s.call(ctx, http.MethodGet, "/api/v2/workspacebuilds/"+url.PathEscape(req.BuildId), nil, nil, &build)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before querying, so logs that are published in between
+	// aren't missed.
+	bufferedLogs, closeFollow, err := s.api.followLogs(build.Job.ID)
+	if err != nil {
+		return xerrors.Errorf("follow logs: %w", err)
+	}
+	defer closeFollow()
+	job, err := s.api.Database.GetProvisionerJobByID(ctx, build.Job.ID)
+	if err != nil {
+		return xerrors.Errorf("get job: %w", err)
+	}
+	logs, err := s.api.Database.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{
+		JobID: job.ID,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get logs: %w", err)
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].CreatedAt.Before(logs[j].CreatedAt)
+	})
+
+	sent := make(map[uuid.UUID]bool, len(logs))
+	for _, log := range logs {
+		sent[log.ID] = true
+		err = stream.Send(convertRPCWorkspaceBuildLog(log))
+		if err != nil {
+			return err
+		}
+	}
+	if job.CompletedAt.Valid {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case log, ok := <-bufferedLogs:
+			if !ok {
+				return nil
+			}
+			if sent[log.ID] {
+				continue
+			}
+			err = stream.Send(convertRPCWorkspaceBuildLog(log))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *rpcServer) GetGroup(ctx context.Context, req *proto.GetGroupRequest) (*proto.Group, error) {
+	var group codersdk.Group
+	err := s.call(ctx, http.MethodGet, "/api/v2/groups/"+url.PathEscape(req.Id), nil, nil, &group)
+	if err != nil {
+		return nil, err
+	}
+	return convertRPCGroup(group), nil
+}
+
+func (s *rpcServer) ListGroups(ctx context.Context, req *proto.ListGroupsRequest) (*proto.ListGroupsResponse, error) {
+	path := "/api/v2/groups"
+	if req.OrganizationId != "" {
+		path = fmt.Sprintf("/api/v2/organizations/%s/groups", url.PathEscape(req.OrganizationId))
+	}
+	query := url.Values{}
+	if req.Query != "" {
+		query.Set("q", req.Query)
+	}
+	if req.Cursor != "" {
+		query.Set("cursor", req.Cursor)
+	}
+	if req.Limit > 0 {
+		query.Set("limit", fmt.Sprint(req.Limit))
+	}
+	var groups codersdk.GroupsResponse
+	err := s.call(ctx, http.MethodGet, path, query, nil, &groups)
+	if err != nil {
+		return nil, err
+	}
+	resp := &proto.ListGroupsResponse{
+		Groups:     make([]*proto.Group, 0, len(groups.Groups)),
+		NextCursor: groups.NextCursor,
+	}
+	for _, group := range groups.Groups {
+		resp.Groups = append(resp.Groups, convertRPCGroup(group))
+	}
+	return resp, nil
+}
+
+// call sends a request to the REST API and decodes the response into resp.
+// Error responses are returned with their HTTP status code as the dRPC error
+// code.
+func (s *rpcServer) call(ctx context.Context, method, path string, query url.Values, body, resp interface{}) error {
+	if token := s.r.URL.Query().Get(codersdk.SessionTokenKey); token != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set(codersdk.SessionTokenKey, token)
+	}
+	u := url.URL{Path: path, RawQuery: query.Encode()}
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return xerrors.Errorf("encode request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return xerrors.Errorf("create request: %w", err)
+	}
+	// Only the authentication of the websocket carries over, not its
+	// upgrade headers.
+	for _, name := range []string{"Cookie", "Authorization", "User-Agent", codersdk.SessionCustomHeader} {
+		if values := s.r.Header.Values(name); len(values) > 0 {
+			req.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.RemoteAddr = s.r.RemoteAddr
+	req.Host = s.r.Host
+	req.TLS = s.r.TLS
+
+	res := s.api.serveBatchSubRequest(req)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		var apiErr codersdk.Response
+		_ = json.Unmarshal(res.Body, &apiErr)
+		message := apiErr.Message
+		if message == "" {
+			message = http.StatusText(res.StatusCode)
+		}
+		if apiErr.Detail != "" {
+			message += " " + apiErr.Detail
+		}
+		for _, validation := range apiErr.Validations {
+			message += fmt.Sprintf(" %s: %s", validation.Field, validation.Detail)
+		}
+		return drpcerr.WithCode(xerrors.New(message), uint64(res.StatusCode))
+	}
+	err = json.Unmarshal(res.Body, resp)
+	if err != nil {
+		return xerrors.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func convertRPCUser(user codersdk.User) *proto.User {
+	resp := &proto.User{
+		Id:              user.ID.String(),
+		Username:        user.Username,
+		Email:           user.Email,
+		CreatedAt:       rpcTime(user.CreatedAt),
+		LastSeenAt:      rpcTime(user.LastSeenAt),
+		Status:          string(user.Status),
+		OrganizationIds: make([]string, 0, len(user.OrganizationIDs)),
+		Roles:           make([]string, 0, len(user.Roles)),
+		AvatarUrl:       user.AvatarURL,
+	}
+	for _, id := range user.OrganizationIDs {
+		resp.OrganizationIds = append(resp.OrganizationIds, id.String())
+	}
+	for _, role := range user.Roles {
+		resp.Roles = append(resp.Roles, role.Name)
+	}
+	return resp
+}
+
+func convertRPCWorkspace(workspace codersdk.Workspace) *proto.Workspace {
+	return &proto.Workspace{
+		Id:           workspace.ID.String(),
+		CreatedAt:    rpcTime(workspace.CreatedAt),
+		UpdatedAt:    rpcTime(workspace.UpdatedAt),
+		OwnerId:      workspace.OwnerID.String(),
+		OwnerName:    workspace.OwnerName,
+		TemplateId:   workspace.TemplateID.String(),
+		TemplateName: workspace.TemplateName,
+		Name:         workspace.Name,
+		Outdated:     workspace.Outdated,
+		LatestBuild:  convertRPCWorkspaceBuild(workspace.LatestBuild),
+	}
+}
+
+func convertRPCWorkspaceBuild(build codersdk.WorkspaceBuild) *proto.WorkspaceBuild {
+	return &proto.WorkspaceBuild{
+		Id:                 build.ID.String(),
+		CreatedAt:          rpcTime(build.CreatedAt),
+		WorkspaceId:        build.WorkspaceID.String(),
+		WorkspaceName:      build.WorkspaceName,
+		WorkspaceOwnerName: build.WorkspaceOwnerName,
+		TemplateVersionId:  build.TemplateVersionID.String(),
+		BuildNumber:        build.BuildNumber,
+		Transition:         string(build.Transition),
+		InitiatorId:        build.InitiatorID.String(),
+		Status:             string(build.Status),
+		JobId:              build.Job.ID.String(),
+		JobStatus:          string(build.Job.Status),
+		JobError:           build.Job.Error,
+	}
+}
+
+func convertRPCWorkspaceBuildLog(log database.ProvisionerJobLog) *proto.WorkspaceBuildLog {
+	return &proto.WorkspaceBuildLog{
+		CreatedAt: rpcTime(log.CreatedAt),
+		Source:    string(log.Source),
+		Level:     string(log.Level),
+		Stage:     log.Stage,
+		Output:    log.Output,
+	}
+}
+
+func convertRPCGroup(group codersdk.Group) *proto.Group {
+	resp := &proto.Group{
+		Id:          group.ID.String(),
+		Name:        group.Name,
+		DisplayName: group.DisplayName,
+		AvatarUrl:   group.AvatarURL,
+		MemberIds:   make([]string, 0, len(group.Members)),
+	}
+	if group.OrganizationID != uuid.Nil {
+		resp.OrganizationId = group.OrganizationID.String()
+	}
+	for _, member := range group.Members {
+		resp.MemberIds = append(resp.MemberIds, member.ID.String())
+	}
+	return resp
+}
+
+// rpcTime returns the time in Unix milliseconds, or zero if it's unset.
+func rpcTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
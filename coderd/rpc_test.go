@@ -0,0 +1,185 @@
+package coderd_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"storj.io/drpc/drpcerr"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	coderproto "github.com/coder/coder/codersdk/proto"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRPC(t *testing.T) {
+	t.Parallel()
+	t.Run("Workspaces", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Log{
+					Log: &proto.Log{
+						Level:  proto.LogLevel_INFO,
+						Output: "example",
+					},
+				},
+			}, {
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{},
+				},
+			}},
+			ProvisionDryRun: echo.ProvisionComplete,
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		rpc, err := client.DialRPC(ctx)
+		require.NoError(t, err)
+		defer rpc.DRPCConn().Close()
+
+		me, err := rpc.GetUser(ctx, &coderproto.GetUserRequest{User: codersdk.Me})
+		require.NoError(t, err)
+		require.Equal(t, user.UserID.String(), me.Id)
+
+		workspaces, err := rpc.ListWorkspaces(ctx, &coderproto.ListWorkspacesRequest{Owner: codersdk.Me})
+		require.NoError(t, err)
+		require.Len(t, workspaces.Workspaces, 1)
+		require.Equal(t, workspace.ID.String(), workspaces.Workspaces[0].Id)
+		require.Equal(t, template.Name, workspaces.Workspaces[0].TemplateName)
+
+		build, err := rpc.CreateWorkspaceBuild(ctx, &coderproto.CreateWorkspaceBuildRequest{
+			WorkspaceId: workspace.ID.String(),
+			Transition:  string(codersdk.WorkspaceTransitionStop),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), build.BuildNumber)
+
+		// Logs are followed until the build completes.
+		stream, err := rpc.StreamWorkspaceBuildLogs(ctx, &coderproto.StreamWorkspaceBuildLogsRequest{BuildId: build.Id})
+		require.NoError(t, err)
+		var outputs []string
+		for {
+			log, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			outputs = append(outputs, log.Output)
+		}
+		require.Contains(t, outputs, "example")
+
+		build, err = rpc.GetWorkspaceBuild(ctx, &coderproto.GetWorkspaceBuildRequest{Id: build.Id})
+		require.NoError(t, err)
+		require.Equal(t, string(codersdk.WorkspaceStatusStopped), build.Status)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		rpc, err := client.DialRPC(ctx)
+		require.NoError(t, err)
+		defer rpc.DRPCConn().Close()
+
+		_, err = rpc.GetWorkspace(ctx, &coderproto.GetWorkspaceRequest{Id: uuid.NewString()})
+		require.Error(t, err)
+		require.EqualValues(t, http.StatusNotFound, drpcerr.Code(err))
+
+		_, err = rpc.CreateWorkspaceBuild(ctx, &coderproto.CreateWorkspaceBuildRequest{
+			WorkspaceId:       uuid.NewString(),
+			Transition:        string(codersdk.WorkspaceTransitionStart),
+			TemplateVersionId: "nope",
+		})
+		require.Error(t, err)
+		require.EqualValues(t, http.StatusBadRequest, drpcerr.Code(err))
+
+		// Groups require a license.
+		_, err = rpc.ListGroups(ctx, &coderproto.ListGroupsRequest{OrganizationId: uuid.NewString()})
+		require.Error(t, err)
+		require.EqualValues(t, http.StatusNotFound, drpcerr.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.DialRPC(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+	})
+}
+
+func TestGRPC(t *testing.T) {
+	t.Parallel()
+	client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := api.GRPCServer()
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	ctx, _ := testutil.Context(t)
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	rpc := coderproto.NewCoderClient(conn)
+
+	// Calls are authenticated with the session token in their metadata.
+	_, err = rpc.GetUser(ctx, &coderproto.GetUserRequest{User: codersdk.Me})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	ctx = metadata.AppendToOutgoingContext(ctx, codersdk.SessionCustomHeader, client.SessionToken)
+
+	me, err := rpc.GetUser(ctx, &coderproto.GetUserRequest{User: codersdk.Me})
+	require.NoError(t, err)
+	require.Equal(t, user.UserID.String(), me.Id)
+
+	workspaces, err := rpc.ListWorkspaces(ctx, &coderproto.ListWorkspacesRequest{Owner: codersdk.Me})
+	require.NoError(t, err)
+	require.Len(t, workspaces.Workspaces, 1)
+	require.Equal(t, workspace.ID.String(), workspaces.Workspaces[0].Id)
+
+	// The build is complete, so the stream ends after its logs.
+	stream, err := rpc.StreamWorkspaceBuildLogs(ctx, &coderproto.StreamWorkspaceBuildLogsRequest{BuildId: workspace.LatestBuild.ID.String()})
+	require.NoError(t, err)
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	_, err = rpc.GetWorkspace(ctx, &coderproto.GetWorkspaceRequest{Id: uuid.NewString()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
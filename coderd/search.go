@@ -0,0 +1,256 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// searchDefaultLimit is how many results are returned if the request doesn't
+// set a limit.
+const searchDefaultLimit = 10
+
+// searchFetchFactor is how many times the limit is fetched from each query.
+// The database doesn't rank matches, and templates, users, and groups are
+// authorized after they're fetched, so fetching only the limit could miss
+// closer matches the user can read.
+const searchFetchFactor = 4
+
+// search matches the query against the names of workspaces, templates, users,
+// and groups the user can read, so the dashboard can offer a single search
+// box. Results are ranked by how closely their name matches.
+func (api *API) search(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	parser := httpapi.NewQueryParamParser()
+	limit := parser.Int(r.URL.Query(), searchDefaultLimit, "limit")
+	if limit < 1 || limit > codersdk.SearchResultLimit {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "limit",
+			Detail: fmt.Sprintf("Query param %q must be between 1 and %d", "limit", codersdk.SearchResultLimit),
+		})
+	}
+	if query == "" {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "q",
+			Detail: fmt.Sprintf("Query param %q is required", "q"),
+		})
+	}
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+
+	fetchLimit := int32(limit * searchFetchFactor)
+	var results []rankedSearchResult
+	add := func(result codersdk.SearchResult, names ...string) {
+		results = append(results, rankedSearchResult{
+			SearchResult: result,
+			rank:         searchRank(query, names...),
+		})
+	}
+
+	sqlFilter, err := api.HTTPAuth.AuthorizeSQLFilter(r, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error preparing sql filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	workspaces, err := api.Database.GetAuthorizedWorkspaces(ctx, database.GetWorkspacesParams{
+		Name:     query,
+		LimitOpt: fetchLimit,
+	}, sqlFilter)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	ownerIDs := make([]uuid.UUID, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		ownerIDs = append(ownerIDs, workspace.OwnerID)
+	}
+	owners, err := api.Database.GetUsersByIDs(ctx, ownerIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace owners.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	ownerNames := make(map[uuid.UUID]string, len(owners))
+	for _, owner := range owners {
+		ownerNames[owner.ID] = owner.Username
+	}
+	for _, workspace := range workspaces {
+		add(codersdk.SearchResult{
+			Type:           codersdk.SearchResultTypeWorkspace,
+			ID:             workspace.ID,
+			Name:           workspace.Name,
+			DisplayName:    ownerNames[workspace.OwnerID] + "/" + workspace.Name,
+			OrganizationID: workspace.OrganizationID,
+		}, workspace.Name)
+	}
+
+	templates, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+		Deleted:   false,
+		FuzzyName: query,
+		LimitOpt:  fetchLimit,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching templates.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templates, err = AuthorizeFilter(api.HTTPAuth, r, rbac.ActionRead, templates)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching templates.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	for _, template := range templates {
+		add(codersdk.SearchResult{
+			Type:           codersdk.SearchResultTypeTemplate,
+			ID:             template.ID,
+			Name:           template.Name,
+			OrganizationID: template.OrganizationID,
+		}, template.Name)
+	}
+
+	users, err := api.Database.GetUsers(ctx, database.GetUsersParams{
+		Search:   query,
+		LimitOpt: fetchLimit,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching users.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	users, err = AuthorizeFilter(api.HTTPAuth, r, rbac.ActionRead, users)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching users.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	for _, user := range users {
+		add(codersdk.SearchResult{
+			Type: codersdk.SearchResultTypeUser,
+			ID:   user.ID,
+			Name: user.Username,
+		}, user.Username, user.Email)
+	}
+
+	groups, err := api.Database.SearchGroups(ctx, database.SearchGroupsParams{
+		Search:   query,
+		LimitOpt: fetchLimit,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching groups.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	groups, err = AuthorizeFilter(api.HTTPAuth, r, rbac.ActionRead, groups)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error searching groups.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	for _, group := range groups {
+		add(codersdk.SearchResult{
+			Type:           codersdk.SearchResultTypeGroup,
+			ID:             group.ID,
+			Name:           group.Name,
+			DisplayName:    group.DisplayName,
+			OrganizationID: group.OrganizationID.UUID,
+		}, group.Name, group.DisplayName)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.Type != b.Type {
+			return searchResultTypeOrder[a.Type] < searchResultTypeOrder[b.Type]
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	resp := make([]codersdk.SearchResult, 0, len(results))
+	for _, result := range results {
+		resp = append(resp, result.SearchResult)
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// searchResultTypeOrder orders results of the same rank.
+var searchResultTypeOrder = map[codersdk.SearchResultType]int{
+	codersdk.SearchResultTypeWorkspace: 0,
+	codersdk.SearchResultTypeTemplate:  1,
+	codersdk.SearchResultTypeUser:      2,
+	codersdk.SearchResultTypeGroup:     3,
+}
+
+type rankedSearchResult struct {
+	codersdk.SearchResult
+	rank int
+}
+
+// Ranks of how closely a name matches a search, best first.
+const (
+	searchRankExact = iota
+	searchRankPrefix
+	searchRankContains
+	searchRankNone
+)
+
+// searchRank returns the best rank of the names for the query, ignoring case.
+func searchRank(query string, names ...string) int {
+	query = strings.ToLower(query)
+	rank := searchRankNone
+	for _, name := range names {
+		name = strings.ToLower(name)
+		switch {
+		case name == query:
+			return searchRankExact
+		case strings.HasPrefix(name, query) && rank > searchRankPrefix:
+			rank = searchRankPrefix
+		case strings.Contains(name, query) && rank > searchRankContains:
+			rank = searchRankContains
+		}
+	}
+	return rank
+}
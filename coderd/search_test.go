@@ -0,0 +1,95 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+	t.Run("Ranked", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.Name = "my-dev"
+		})
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.Name = "dev"
+		})
+
+		ctx, _ := testutil.Context(t)
+		devUser, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "developer@coder.com",
+			Username:       "developer",
+			Password:       "SomeSecurePassword!",
+			OrganizationID: user.OrganizationID,
+		})
+		require.NoError(t, err)
+
+		results, err := client.Search(ctx, codersdk.SearchRequest{Query: "DEV"})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		// The exact match first, then the prefix, then the substring.
+		require.Equal(t, codersdk.SearchResultTypeWorkspace, results[0].Type)
+		require.Equal(t, workspace.ID, results[0].ID)
+		require.Equal(t, "testuser/dev", results[0].DisplayName)
+		require.Equal(t, codersdk.SearchResultTypeUser, results[1].Type)
+		require.Equal(t, devUser.ID, results[1].ID)
+		require.Equal(t, codersdk.SearchResultTypeTemplate, results[2].Type)
+		require.Equal(t, template.ID, results[2].ID)
+
+		results, err = client.Search(ctx, codersdk.SearchRequest{Query: "dev", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, workspace.ID, results[0].ID)
+	})
+
+	t.Run("Authorized", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		_ = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.Name = "secret"
+		})
+
+		ctx, _ := testutil.Context(t)
+		results, err := client.Search(ctx, codersdk.SearchRequest{Query: "secret"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		// Members can't read the workspaces of others.
+		results, err = member.Search(ctx, codersdk.SearchRequest{Query: "secret"})
+		require.NoError(t, err)
+		require.Len(t, results, 0)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		for _, req := range []codersdk.SearchRequest{
+			{Query: " "},
+			{Query: "dev", Limit: codersdk.SearchResultLimit + 1},
+		} {
+			ctx, _ := testutil.Context(t)
+			_, err := client.Search(ctx, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+}
@@ -0,0 +1,478 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) templateDeprecation(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	deprecations, err := api.templateDeprecations(ctx, []uuid.UUID{template.ID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template deprecation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	deprecation, ok := deprecations[template.ID]
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "The template isn't deprecated.",
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, deprecation)
+}
+
+func (api *API) putTemplateDeprecation(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplateDeprecationRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.SuccessorID != nil {
+		successor, err := api.Database.GetTemplateByID(ctx, *req.SuccessorID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching successor template.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		switch {
+		case err != nil || successor.Deleted || !api.Authorize(r, rbac.ActionRead, successor):
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "successor_id",
+				Detail: "Template not found.",
+			})
+		case successor.ID == template.ID:
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "successor_id",
+				Detail: "A template can't succeed itself.",
+			})
+		case successor.OrganizationID != template.OrganizationID:
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "successor_id",
+				Detail: "Must be a template of the same organization.",
+			})
+		}
+	}
+	if req.ParameterMapping == nil {
+		req.ParameterMapping = map[string]string{}
+	}
+	if _, ok := req.ParameterMapping[""]; ok {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "parameter_mapping",
+			Detail: "Parameter names must not be empty.",
+		})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid template deprecation.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	mapping, err := json.Marshal(req.ParameterMapping)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	var successorID uuid.NullUUID
+	if req.SuccessorID != nil {
+		successorID = uuid.NullUUID{UUID: *req.SuccessorID, Valid: true}
+	}
+	_, err = api.Database.UpsertTemplateDeprecation(ctx, database.UpsertTemplateDeprecationParams{
+		TemplateID:       template.ID,
+		Message:          req.Message,
+		SuccessorID:      successorID,
+		ParameterMapping: mapping,
+		DeprecatedAt:     database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template deprecation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	deprecations, err := api.templateDeprecations(ctx, []uuid.UUID{template.ID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template deprecation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, deprecations[template.ID])
+}
+
+func (api *API) deleteTemplateDeprecation(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteTemplateDeprecationByTemplateID(ctx, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting template deprecation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Template deprecation deleted.",
+	})
+}
+
+// checkTemplateDeprecation writes an error and returns false if the template
+// is deprecated, so no more workspaces are created from it.
+func (api *API) checkTemplateDeprecation(rw http.ResponseWriter, r *http.Request, template database.Template) bool {
+	ctx := r.Context()
+	deprecations, err := api.templateDeprecations(ctx, []uuid.UUID{template.ID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template deprecation.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	deprecation, ok := deprecations[template.ID]
+	if !ok {
+		return true
+	}
+	message := fmt.Sprintf("Template %q is deprecated.", template.Name)
+	if deprecation.SuccessorName != "" {
+		message = fmt.Sprintf("Template %q is deprecated, use %q instead.", template.Name, deprecation.SuccessorName)
+	}
+	httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+		Message: message,
+		Detail:  deprecation.Message,
+	})
+	return false
+}
+
+// postWorkspaceMigrate moves a workspace of a deprecated template to the
+// successor of the template, and starts it with the active version of the
+// successor. Only owners can migrate their workspaces.
+func (api *API) postWorkspaceMigrate(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if apiKey.UserID != workspace.OwnerID {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Only the owner can migrate the workspace.",
+		})
+		return
+	}
+	if api.organizationDeleting(rw, r, workspace.OrganizationID) {
+		return
+	}
+
+	deprecation, err := api.Database.GetTemplateDeprecationByTemplateID(ctx, workspace.TemplateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template of the workspace isn't deprecated.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template deprecation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	var successor database.Template
+	if deprecation.SuccessorID.Valid {
+		successor, err = api.Database.GetTemplateByID(ctx, deprecation.SuccessorID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching successor template.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	if successor.ID == uuid.Nil || successor.Deleted || !api.Authorize(r, rbac.ActionRead, successor) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template of the workspace doesn't have a successor you can use.",
+		})
+		return
+	}
+
+	priorBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	priorJob, err := api.Database.GetProvisionerJobByID(ctx, priorBuild.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !priorJob.CompletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The workspace can't be migrated while it's being built.",
+		})
+		return
+	}
+
+	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, successor.ActiveVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersionJob, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if convertProvisionerJob(templateVersionJob).Status != codersdk.ProvisionerJobSucceeded {
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: fmt.Sprintf("The active version of template %q didn't import successfully.", successor.Name),
+		})
+		return
+	}
+
+	// The values of the workspace's parameters are kept, under the names the
+	// successor gives them. Parameters mapped to an empty name are dropped.
+	parameterValues, err := api.Database.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{workspace.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	mapping := map[string]string{}
+	_ = json.Unmarshal(deprecation.ParameterMapping, &mapping)
+	createParameters := make([]codersdk.CreateParameterRequest, 0, len(parameterValues))
+	for _, parameterValue := range parameterValues {
+		name, ok := mapping[parameterValue.Name]
+		if !ok {
+			name = parameterValue.Name
+		}
+		if name == "" {
+			continue
+		}
+		createParameters = append(createParameters, codersdk.CreateParameterRequest{
+			Name:        name,
+			SourceValue: parameterValue.SourceValue,
+		})
+	}
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, workspace.OrganizationID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if validErrs := disallowedParameterValues(defaults.AllowedParameterValues, createParameters); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "The migrated parameters of the workspace aren't allowed by the organization.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	var (
+		migrated database.Workspace
+		build    database.WorkspaceBuild
+	)
+	err = api.Database.InTx(func(tx database.Store) error {
+		migrated, err = tx.UpdateWorkspaceOrganization(ctx, database.UpdateWorkspaceOrganizationParams{
+			ID:             workspace.ID,
+			OrganizationID: workspace.OrganizationID,
+			TemplateID:     successor.ID,
+			UpdatedAt:      database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace template: %w", err)
+		}
+		for _, parameterValue := range parameterValues {
+			name, ok := mapping[parameterValue.Name]
+			if !ok || name == parameterValue.Name {
+				continue
+			}
+			err = tx.DeleteParameterValueByID(ctx, parameterValue.ID)
+			if err != nil {
+				return xerrors.Errorf("delete parameter value: %w", err)
+			}
+			if name == "" {
+				continue
+			}
+			_, err = tx.InsertParameterValue(ctx, database.InsertParameterValueParams{
+				ID:                uuid.New(),
+				Name:              name,
+				CreatedAt:         parameterValue.CreatedAt,
+				UpdatedAt:         database.Now(),
+				Scope:             parameterValue.Scope,
+				ScopeID:           parameterValue.ScopeID,
+				SourceScheme:      parameterValue.SourceScheme,
+				SourceValue:       parameterValue.SourceValue,
+				DestinationScheme: parameterValue.DestinationScheme,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert parameter value: %w", err)
+			}
+		}
+		build, err = insertWorkspaceBuild(ctx, tx, apiKey.UserID, migrated, priorBuild, templateVersion.ID, database.WorkspaceTransitionStart, database.BuildReasonInitiator)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusMethodNotAllowed, codersdk.Response{
+			Message: fmt.Sprintf("Workspace %q is deleted and cannot be migrated.", workspace.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error migrating workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = migrated
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, migrated)
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:           codersdk.WebhookEventWorkspaceBuildCreated,
+		OrganizationID: migrated.OrganizationID,
+		ResourceID:     build.ID,
+		ResourceName:   migrated.Name,
+	})
+
+	data, err := api.workspaceData(ctx, []database.Workspace{migrated})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspace(
+		migrated,
+		data.builds[0],
+		data.templates[0],
+		findUser(migrated.OwnerID, data.users),
+		data.maintenanceAt[migrated.ID],
+		data.deprecations[migrated.TemplateID],
+	))
+}
+
+// templateDeprecations returns the deprecations of the templates that are
+// deprecated, by template ID. Successors that were deleted are left out.
+func (api *API) templateDeprecations(ctx context.Context, templateIDs []uuid.UUID) (map[uuid.UUID]*codersdk.TemplateDeprecation, error) {
+	deprecations, err := api.Database.GetTemplateDeprecationsByTemplateIDs(ctx, templateIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get template deprecations: %w", err)
+	}
+	converted := make(map[uuid.UUID]*codersdk.TemplateDeprecation, len(deprecations))
+	if len(deprecations) == 0 {
+		return converted, nil
+	}
+
+	successorIDs := make([]uuid.UUID, 0, len(deprecations))
+	for _, deprecation := range deprecations {
+		if deprecation.SuccessorID.Valid {
+			successorIDs = append(successorIDs, deprecation.SuccessorID.UUID)
+		}
+	}
+	successorNames := make(map[uuid.UUID]string, len(successorIDs))
+	if len(successorIDs) > 0 {
+		successors, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+			IDs: successorIDs,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get successor templates: %w", err)
+		}
+		for _, successor := range successors {
+			successorNames[successor.ID] = successor.Name
+		}
+	}
+
+	for _, deprecation := range deprecations {
+		converted[deprecation.TemplateID] = convertTemplateDeprecation(deprecation, successorNames)
+	}
+	return converted, nil
+}
+
+func convertTemplateDeprecation(deprecation database.TemplateDeprecation, successorNames map[uuid.UUID]string) *codersdk.TemplateDeprecation {
+	converted := &codersdk.TemplateDeprecation{
+		Message:          deprecation.Message,
+		ParameterMapping: map[string]string{},
+		DeprecatedAt:     deprecation.DeprecatedAt,
+	}
+	_ = json.Unmarshal(deprecation.ParameterMapping, &converted.ParameterMapping)
+	if name, ok := successorNames[deprecation.SuccessorID.UUID]; ok && deprecation.SuccessorID.Valid {
+		successorID := deprecation.SuccessorID.UUID
+		converted.SuccessorID = &successorID
+		converted.SuccessorName = name
+	}
+	return converted
+}
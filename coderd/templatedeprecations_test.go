@@ -0,0 +1,242 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateDeprecation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		successor := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.TemplateDeprecation(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		deprecation, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			Message:          "Use the new image.",
+			SuccessorID:      &successor.ID,
+			ParameterMapping: map[string]string{"region": "location"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Use the new image.", deprecation.Message)
+		require.Equal(t, &successor.ID, deprecation.SuccessorID)
+		require.Equal(t, successor.Name, deprecation.SuccessorName)
+		require.Equal(t, map[string]string{"region": "location"}, deprecation.ParameterMapping)
+
+		// Owners of existing workspaces are told about it.
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.NotNil(t, workspace.TemplateDeprecation)
+		require.Equal(t, successor.Name, workspace.TemplateDeprecation.SuccessorName)
+
+		// No more workspaces can be created from the template.
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "new",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Contains(t, apiErr.Message, successor.Name)
+		_, err = client.CloneWorkspace(ctx, workspace.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		err = client.DeleteTemplateDeprecation(ctx, template.ID)
+		require.NoError(t, err)
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Nil(t, workspace.TemplateDeprecation)
+		coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			SuccessorID:      &template.ID,
+			ParameterMapping: map[string]string{"": "region"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+
+		successorID := uuid.New()
+		_, err = client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			SuccessorID: &successorID,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			Message: "Deprecated.",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceMigrate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		versionWithParameter := func(name string) codersdk.TemplateVersion {
+			version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+				Parse: []*proto.Parse_Response{{
+					Type: &proto.Parse_Response_Complete{
+						Complete: &proto.Parse_Complete{
+							ParameterSchemas: []*proto.ParameterSchema{{
+								Name:                name,
+								AllowOverrideSource: true,
+								DefaultSource: &proto.ParameterSource{
+									Scheme: proto.ParameterSource_DATA,
+									Value:  "default",
+								},
+								DefaultDestination: &proto.ParameterDestination{
+									Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+								},
+							}},
+						},
+					},
+				}},
+				Provision: echo.ProvisionComplete,
+			})
+			coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			return version
+		}
+		version := versionWithParameter("region")
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		successorVersion := versionWithParameter("location")
+		successor := coderdtest.CreateTemplate(t, client, user.OrganizationID, successorVersion.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.ParameterValues = []codersdk.CreateParameterRequest{{
+				Name:              "region",
+				SourceValue:       "eu",
+				SourceScheme:      codersdk.ParameterSourceSchemeData,
+				DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
+			}}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.MigrateWorkspace(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			SuccessorID:      &successor.ID,
+			ParameterMapping: map[string]string{"region": "location"},
+		})
+		require.NoError(t, err)
+
+		migrated, err := client.MigrateWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, successor.ID, migrated.TemplateID)
+		require.Equal(t, successorVersion.ID, migrated.LatestBuild.TemplateVersionID)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, migrated.LatestBuild.Transition)
+		require.Equal(t, workspace.LatestBuild.BuildNumber+1, migrated.LatestBuild.BuildNumber)
+		require.Nil(t, migrated.TemplateDeprecation)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, migrated.LatestBuild.ID)
+
+		params, err := client.Parameters(ctx, codersdk.ParameterWorkspace, workspace.ID)
+		require.NoError(t, err)
+		require.Len(t, params, 1)
+		require.Equal(t, "location", params[0].Name)
+
+		// The workspace isn't on a deprecated template anymore.
+		_, err = client.MigrateWorkspace(ctx, workspace.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("NoSuccessor", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			Message: "Ask in #infra.",
+		})
+		require.NoError(t, err)
+		_, err = client.MigrateWorkspace(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("NotOwner", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		successor := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecationRequest{
+			SuccessorID: &successor.ID,
+		})
+		require.NoError(t, err)
+
+		// Admins can't migrate workspaces without the consent of their owners.
+		_, err = client.MigrateWorkspace(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		_, err = member.MigrateWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+	})
+}
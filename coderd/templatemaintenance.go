@@ -0,0 +1,220 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/autobuild/executor"
+	"github.com/coder/coder/coderd/autobuild/schedule"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const minMaintenanceWindowDuration = time.Minute
+
+func (api *API) templateMaintenanceWindow(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	window, err := api.Database.GetTemplateMaintenanceWindowByTemplateID(ctx, template.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.TemplateMaintenanceWindow{
+			ExemptGroupIDs: []uuid.UUID{},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template maintenance window.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateMaintenanceWindow(window))
+}
+
+// putTemplateMaintenanceWindow replaces the maintenance window of a template,
+// or removes it if the schedule is empty.
+func (api *API) putTemplateMaintenanceWindow(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.TemplateMaintenanceWindow
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if req.Schedule == "" {
+		err := api.Database.DeleteTemplateMaintenanceWindowByTemplateID(ctx, template.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error deleting template maintenance window.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.TemplateMaintenanceWindow{
+			ExemptGroupIDs: []uuid.UUID{},
+		})
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	duration := time.Duration(req.DurationMillis) * time.Millisecond
+	sched, err := schedule.Weekly(req.Schedule)
+	if err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "schedule", Detail: err.Error()})
+	} else if duration < minMaintenanceWindowDuration || duration > sched.Min() {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "duration_ms",
+			Detail: fmt.Sprintf("Must be between %s and the time between windows (%s).", minMaintenanceWindowDuration, sched.Min()),
+		})
+	}
+	exemptGroupIDs := make([]uuid.UUID, 0, len(req.ExemptGroupIDs))
+	seen := make(map[uuid.UUID]bool, len(req.ExemptGroupIDs))
+	for _, groupID := range req.ExemptGroupIDs {
+		if seen[groupID] {
+			continue
+		}
+		seen[groupID] = true
+		group, err := api.Database.GetGroupByID(ctx, groupID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching group.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if err != nil || group.DeletedAt.Valid || group.OrganizationID.UUID != template.OrganizationID {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "exempt_group_ids",
+				Detail: fmt.Sprintf("Group %q doesn't exist in the organization of the template.", groupID),
+			})
+			continue
+		}
+		exemptGroupIDs = append(exemptGroupIDs, groupID)
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid maintenance window.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	window, err := api.Database.UpsertTemplateMaintenanceWindow(ctx, database.UpsertTemplateMaintenanceWindowParams{
+		TemplateID:     template.ID,
+		Schedule:       req.Schedule,
+		Duration:       int64(duration),
+		ExemptGroupIds: exemptGroupIDs,
+		UpdatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template maintenance window.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateMaintenanceWindow(window))
+}
+
+// workspacesMaintenanceAt returns when running workspaces on old versions of
+// their template will be updated during its maintenance window. Workspaces
+// that won't be updated aren't included.
+func (api *API) workspacesMaintenanceAt(ctx context.Context, workspaces []database.Workspace, builds []codersdk.WorkspaceBuild, templates []database.Template) (map[uuid.UUID]*time.Time, error) {
+	maintenanceAt := make(map[uuid.UUID]*time.Time)
+	activeVersionIDs := make(map[uuid.UUID]uuid.UUID, len(templates))
+	for _, template := range templates {
+		activeVersionIDs[template.ID] = template.ActiveVersionID
+	}
+	buildByWorkspaceID := make(map[uuid.UUID]codersdk.WorkspaceBuild, len(builds))
+	for _, build := range builds {
+		buildByWorkspaceID[build.WorkspaceID] = build
+	}
+	outdated := make(map[uuid.UUID]bool, len(workspaces))
+	templateIDs := make([]uuid.UUID, 0, len(templates))
+	for _, workspace := range workspaces {
+		build, ok := buildByWorkspaceID[workspace.ID]
+		if !ok || build.Transition != codersdk.WorkspaceTransitionStart ||
+			build.Job.Status != codersdk.ProvisionerJobSucceeded ||
+			build.TemplateVersionID == activeVersionIDs[workspace.TemplateID] {
+			continue
+		}
+		outdated[workspace.ID] = true
+		templateIDs = append(templateIDs, workspace.TemplateID)
+	}
+	if len(templateIDs) == 0 {
+		return maintenanceAt, nil
+	}
+	windows, err := api.Database.GetTemplateMaintenanceWindowsByTemplateIDs(ctx, templateIDs)
+	if err != nil {
+		return nil, xerrors.Errorf("get template maintenance windows: %w", err)
+	}
+
+	now := database.Now()
+	for _, window := range windows {
+		sched, err := schedule.Weekly(window.Schedule)
+		if err != nil {
+			continue
+		}
+		start, _ := sched.Window(now, time.Duration(window.Duration))
+
+		var (
+			windowWorkspaces []database.Workspace
+			ownerIDs         []uuid.UUID
+		)
+		for _, workspace := range workspaces {
+			if workspace.TemplateID == window.TemplateID && outdated[workspace.ID] {
+				windowWorkspaces = append(windowWorkspaces, workspace)
+				ownerIDs = append(ownerIDs, workspace.OwnerID)
+			}
+		}
+		if len(windowWorkspaces) == 0 {
+			continue
+		}
+		exempt, err := executor.MaintenanceExemptUsers(ctx, api.Database, windowWorkspaces[0].OrganizationID, window, ownerIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, workspace := range windowWorkspaces {
+			if !exempt[workspace.OwnerID] {
+				start := start
+				maintenanceAt[workspace.ID] = &start
+			}
+		}
+	}
+	return maintenanceAt, nil
+}
+
+func convertTemplateMaintenanceWindow(window database.TemplateMaintenanceWindow) codersdk.TemplateMaintenanceWindow {
+	exemptGroupIDs := window.ExemptGroupIds
+	if exemptGroupIDs == nil {
+		exemptGroupIDs = []uuid.UUID{}
+	}
+	return codersdk.TemplateMaintenanceWindow{
+		Schedule:       window.Schedule,
+		DurationMillis: time.Duration(window.Duration).Milliseconds(),
+		ExemptGroupIDs: exemptGroupIDs,
+	}
+}
@@ -0,0 +1,146 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		window, err := client.TemplateMaintenanceWindow(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, window.Schedule)
+		require.Empty(t, window.ExemptGroupIDs)
+
+		window, err = client.UpdateTemplateMaintenanceWindow(ctx, template.ID, codersdk.TemplateMaintenanceWindow{
+			Schedule:       "CRON_TZ=UTC 0 2 * * Sun",
+			DurationMillis: (2 * time.Hour).Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "CRON_TZ=UTC 0 2 * * Sun", window.Schedule)
+		require.Equal(t, (2 * time.Hour).Milliseconds(), window.DurationMillis)
+
+		// Workspaces on the active version won't be updated.
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Nil(t, workspace.MaintenanceAt)
+
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err = client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.NotNil(t, workspace.MaintenanceAt)
+		require.Equal(t, time.Sunday, workspace.MaintenanceAt.UTC().Weekday())
+		require.Equal(t, 2, workspace.MaintenanceAt.UTC().Hour())
+
+		// Removing the window.
+		window, err = client.UpdateTemplateMaintenanceWindow(ctx, template.ID, codersdk.TemplateMaintenanceWindow{})
+		require.NoError(t, err)
+		require.Empty(t, window.Schedule)
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Nil(t, workspace.MaintenanceAt)
+	})
+
+	t.Run("Exempt", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		// The Everyone group has the ID of the organization.
+		window, err := client.UpdateTemplateMaintenanceWindow(ctx, template.ID, codersdk.TemplateMaintenanceWindow{
+			Schedule:       "CRON_TZ=UTC 0 2 * * Sun",
+			DurationMillis: time.Hour.Milliseconds(),
+			ExemptGroupIDs: []uuid.UUID{user.OrganizationID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{user.OrganizationID}, window.ExemptGroupIDs)
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Nil(t, workspace.MaintenanceAt)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateMaintenanceWindow(ctx, template.ID, codersdk.TemplateMaintenanceWindow{
+			// The window is longer than the time between windows.
+			Schedule:       "CRON_TZ=UTC 0 * * * *",
+			DurationMillis: (2 * time.Hour).Milliseconds(),
+			ExemptGroupIDs: []uuid.UUID{uuid.New()},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+
+		_, err = client.UpdateTemplateMaintenanceWindow(ctx, template.ID, codersdk.TemplateMaintenanceWindow{
+			Schedule:       "not a schedule",
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.TemplateMaintenanceWindow(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateMaintenanceWindow(ctx, template.ID, codersdk.TemplateMaintenanceWindow{
+			Schedule:       "CRON_TZ=UTC 0 2 * * Sun",
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/moby/moby/pkg/namesgenerator"
+	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/audit"
@@ -49,18 +50,25 @@ func (api *API) template(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workspaceCounts, err := api.Database.GetWorkspaceOwnerCountsByTemplateIDs(ctx, []uuid.UUID{template.ID})
-	if errors.Is(err, sql.ErrNoRows) {
-		err = nil
-	}
+	resp, err := api.templateResponse(ctx, template)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Internal error fetching workspace count.",
+			Message: "Internal error fetching template.",
 			Detail:  err.Error(),
 		})
 		return
 	}
 
+	httpapi.WriteWithETag(ctx, rw, r, resp)
+}
+
+// templateResponse converts a template along with its workspace owner count
+// and the name of its creator, as it's returned by the template endpoint.
+func (api *API) templateResponse(ctx context.Context, template database.Template) (codersdk.Template, error) {
+	workspaceCounts, err := api.Database.GetWorkspaceOwnerCountsByTemplateIDs(ctx, []uuid.UUID{template.ID})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return codersdk.Template{}, xerrors.Errorf("get workspace count: %w", err)
+	}
 	count := uint32(0)
 	if len(workspaceCounts) > 0 {
 		count = uint32(workspaceCounts[0].Count)
@@ -68,14 +76,9 @@ func (api *API) template(rw http.ResponseWriter, r *http.Request) {
 
 	createdByNameMap, err := getCreatedByNamesByTemplateIDs(ctx, api.Database, []database.Template{template})
 	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Internal error fetching creator name.",
-			Detail:  err.Error(),
-		})
-		return
+		return codersdk.Template{}, xerrors.Errorf("get creator name: %w", err)
 	}
-
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template, count, createdByNameMap[template.ID.String()]))
+	return api.convertTemplate(template, count, createdByNameMap[template.ID.String()]), nil
 }
 
 func (api *API) deleteTemplate(rw http.ResponseWriter, r *http.Request) {
@@ -163,6 +166,9 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 	if !httpapi.Read(ctx, rw, r, &createTemplate) {
 		return
 	}
+	if api.organizationDeleting(rw, r, organization.ID) {
+		return
+	}
 	_, err := api.Database.GetTemplateByOrganizationAndName(ctx, database.GetTemplateByOrganizationAndNameParams{
 		OrganizationID: organization.ID,
 		Name:           createTemplate.Name,
@@ -212,7 +218,23 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if validErrs := disallowedParameterValues(defaults.AllowedParameterValues, createTemplate.ParameterValues); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid create template request.",
+			Validations: validErrs,
+		})
+		return
+	}
+
 	maxTTL := maxTTLDefault
+	if defaults.MaxTTLMillis > 0 {
+		maxTTL = time.Duration(defaults.MaxTTLMillis) * time.Millisecond
+	}
 	if createTemplate.MaxTTLMillis != nil {
 		maxTTL = time.Duration(*createTemplate.MaxTTLMillis) * time.Millisecond
 	}
@@ -237,6 +259,9 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 	}
 
 	minAutostartInterval := minAutostartIntervalDefault
+	if defaults.MinAutostartIntervalMillis > 0 {
+		minAutostartInterval = time.Duration(defaults.MinAutostartIntervalMillis) * time.Millisecond
+	}
 	if !ptr.NilOrZero(createTemplate.MinAutostartIntervalMillis) {
 		minAutostartInterval = time.Duration(*createTemplate.MinAutostartIntervalMillis) * time.Millisecond
 	}
@@ -327,14 +352,30 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 		TemplateVersions: []telemetry.TemplateVersion{telemetry.ConvertTemplateVersion(templateVersion)},
 	})
 
+	api.publishOrganizationEvent(dbTemplate.OrganizationID, codersdk.OrganizationWebhookEvent{
+		Type:         codersdk.OrganizationWebhookEventTemplatePublished,
+		ResourceID:   dbTemplate.ID,
+		ResourceName: dbTemplate.Name,
+	})
+
 	httpapi.Write(ctx, rw, http.StatusCreated, template)
 }
 
 func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	organization := httpmw.OrganizationParam(r)
+	page, ok := httpapi.ParseCursorPagination(rw, r)
+	if !ok {
+		return
+	}
+	sortBy, ok := parseListSort(rw, r)
+	if !ok {
+		return
+	}
 	templates, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
 		OrganizationID: organization.ID,
+		AfterID:        page.AfterID,
+		LimitOpt:       page.FetchLimit(),
 	})
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
@@ -346,6 +387,9 @@ func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	templates, nextCursor := httpapi.Page(page, templates, func(template database.Template) uuid.UUID {
+		return template.ID
+	})
 
 	// Filter templates based on rbac permissions
 	templates, err = AuthorizeFilter(api.HTTPAuth, r, rbac.ActionRead, templates)
@@ -383,7 +427,27 @@ func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplates(templates, workspaceCounts, createdByNameMap))
+	apiTemplates := api.convertTemplates(templates, workspaceCounts, createdByNameMap)
+	if sortBy == codersdk.SortFavorites {
+		favorites, err := api.userFavorites(ctx, httpmw.APIKey(r).UserID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching favorites.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		sortTemplatesByFavorites(apiTemplates, favorites.TemplateIDs)
+	}
+
+	if !page.Requested() {
+		httpapi.Write(ctx, rw, http.StatusOK, apiTemplates)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.TemplatesResponse{
+		Templates:  apiTemplates,
+		NextCursor: nextCursor,
+	})
 }
 
 func (api *API) templateByOrganizationAndName(rw http.ResponseWriter, r *http.Request) {
@@ -438,7 +502,7 @@ func (api *API) templateByOrganizationAndName(rw http.ResponseWriter, r *http.Re
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template, count, createdByNameMap[template.ID.String()]))
+	httpapi.WriteWithETag(ctx, rw, r, api.convertTemplate(template, count, createdByNameMap[template.ID.String()]))
 }
 
 func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
@@ -465,6 +529,16 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
+	if r.Header.Get("If-Match") != "" {
+		current, err := api.templateResponse(ctx, template)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		if !httpapi.CheckIfMatch(ctx, rw, r, current) {
+			return
+		}
+	}
 
 	var validErrs []codersdk.ValidationError
 	if req.MaxTTLMillis < 0 {
@@ -476,6 +550,9 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.MaxTTLMillis > maxTTLDefault.Milliseconds() {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: "Cannot be greater than " + maxTTLDefault.String()})
 	}
+	if req.WorkspaceLabels != nil {
+		validErrs = append(validErrs, validateLabels("workspace_labels", *req.WorkspaceLabels)...)
+	}
 
 	if len(validErrs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -501,11 +578,14 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			count = uint32(workspaceCounts[0].Count)
 		}
 
+		labelsChanged := req.WorkspaceLabels != nil &&
+			!maps.Equal(*req.WorkspaceLabels, convertLabels(template.WorkspaceLabels))
 		if req.Name == template.Name &&
 			req.Description == template.Description &&
 			req.Icon == template.Icon &&
 			req.MaxTTLMillis == time.Duration(template.MaxTtl).Milliseconds() &&
-			req.MinAutostartIntervalMillis == time.Duration(template.MinAutostartInterval).Milliseconds() {
+			req.MinAutostartIntervalMillis == time.Duration(template.MinAutostartInterval).Milliseconds() &&
+			!labelsChanged {
 			return nil
 		}
 
@@ -539,6 +619,13 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			return err
 		}
 
+		if labelsChanged {
+			updated, err = updateTemplateWorkspaceLabels(ctx, tx, updated, *req.WorkspaceLabels)
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -562,7 +649,7 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(updated, count, createdByNameMap[updated.ID.String()]))
+	httpapi.WriteWithETag(ctx, rw, r, api.convertTemplate(updated, count, createdByNameMap[updated.ID.String()]))
 }
 
 func (api *API) templateDAUs(rw http.ResponseWriter, r *http.Request) {
@@ -788,5 +875,7 @@ func (api *API) convertTemplate(
 		MinAutostartIntervalMillis: time.Duration(template.MinAutostartInterval).Milliseconds(),
 		CreatedByID:                template.CreatedBy,
 		CreatedByName:              createdByName,
+		QuotaWeight:                template.QuotaWeight,
+		WorkspaceLabels:            convertLabels(template.WorkspaceLabels),
 	}
 }
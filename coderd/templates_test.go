@@ -41,6 +41,42 @@ func TestTemplate(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("ETag", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		get := func(etag string) *http.Response {
+			res, err := client.Request(ctx, http.MethodGet, "/api/v2/templates/"+template.ID.String(), nil, func(r *http.Request) {
+				if etag != "" {
+					r.Header.Set("If-None-Match", etag)
+				}
+			})
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res
+		}
+
+		res := get("")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		etag := res.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+		require.Equal(t, http.StatusNotModified, get(etag).StatusCode)
+
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Description: "changed",
+		})
+		require.NoError(t, err)
+		res = get(etag)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NotEqual(t, etag, res.Header.Get("ETag"))
+	})
+
 	t.Run("WorkspaceCount", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
@@ -260,6 +296,38 @@ func TestTemplatesByOrganization(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, templates, 2)
 	})
+	t.Run("Pagination", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		for i := 0; i < 3; i++ {
+			coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		all, err := client.TemplatesByOrganization(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+
+		page, err := client.TemplatesByOrganizationPage(ctx, user.OrganizationID, codersdk.CursorPagination{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Templates, 2)
+		require.Equal(t, all[0].ID, page.Templates[0].ID)
+		require.Equal(t, all[1].ID, page.Templates[1].ID)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = client.TemplatesByOrganizationPage(ctx, user.OrganizationID, codersdk.CursorPagination{
+			Cursor: page.NextCursor,
+			Limit:  2,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Templates, 1)
+		require.Equal(t, all[2].ID, page.Templates[0].ID)
+		require.Empty(t, page.NextCursor)
+	})
 }
 
 func TestTemplateByOrganizationAndName(t *testing.T) {
@@ -296,6 +364,46 @@ func TestTemplateByOrganizationAndName(t *testing.T) {
 func TestPatchTemplateMeta(t *testing.T) {
 	t.Parallel()
 
+	t.Run("IfMatch", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/templates/"+template.ID.String(), nil)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		etag := res.Header.Get("ETag")
+
+		patch := func(etag, description string) *http.Response {
+			res, err := client.Request(ctx, http.MethodPatch, "/api/v2/templates/"+template.ID.String(), codersdk.UpdateTemplateMeta{
+				Description: description,
+			}, func(r *http.Request) {
+				r.Header.Set("If-Match", etag)
+			})
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res
+		}
+
+		// The first edit succeeds, and returns the ETag to make the next
+		// one with.
+		res = patch(etag, "first")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, http.StatusOK, patch(res.Header.Get("ETag"), "second").StatusCode)
+
+		// A concurrent edit made with the original ETag conflicts.
+		require.Equal(t, http.StatusConflict, patch(etag, "third").StatusCode)
+		got, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, "second", got.Description)
+	})
+
 	t.Run("Modified", func(t *testing.T) {
 		t.Parallel()
 
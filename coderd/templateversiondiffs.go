@@ -0,0 +1,418 @@
+package coderd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/diff"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// templateVersionDiff compares the template version of the path with another
+// version of the organization: their source files, parameter schemas, and the
+// resources their imports planned.
+func (api *API) templateVersionDiff(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx         = r.Context()
+		fromVersion = httpmw.TemplateVersionParam(r)
+		fromTmpl    = httpmw.TemplateParam(r)
+		toVersionID = chi.URLParam(r, "othertemplateversion")
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, fromVersion.RBACObject(fromTmpl)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	toVersionUUID, err := uuid.Parse(toVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template version ID %q must be a valid UUID.", toVersionID),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	toVersion, err := api.Database.GetTemplateVersionByID(ctx, toVersionUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	toTmpl, err := api.Database.GetTemplateByID(ctx, toVersion.TemplateID.UUID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if toVersion.OrganizationID != fromVersion.OrganizationID || !api.Authorize(r, rbac.ActionRead, toVersion.RBACObject(toTmpl)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	fromJob, err := api.Database.GetProvisionerJobByID(ctx, fromVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	toJob, err := api.Database.GetProvisionerJobByID(ctx, toVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !fromJob.CompletedAt.Valid || !toJob.CompletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "Template version jobs haven't completed!",
+		})
+		return
+	}
+
+	files, err := api.diffTemplateVersionFiles(ctx, fromJob, toJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error comparing template version files.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	parameters, err := api.diffTemplateVersionParameters(ctx, fromJob, toJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error comparing template version parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	resources, err := api.diffTemplateVersionResources(ctx, fromJob, toJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error comparing template version resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.TemplateVersionDiff{
+		FromVersionID: fromVersion.ID,
+		ToVersionID:   toVersion.ID,
+		Files:         files,
+		Parameters:    parameters,
+		Resources:     resources,
+	})
+}
+
+func (api *API) diffTemplateVersionFiles(ctx context.Context, fromJob, toJob database.ProvisionerJob) ([]codersdk.TemplateVersionFileDiff, error) {
+	fromFiles, err := api.templateVersionFiles(ctx, fromJob)
+	if err != nil {
+		return nil, xerrors.Errorf("read files of %s: %w", fromJob.ID, err)
+	}
+	toFiles, err := api.templateVersionFiles(ctx, toJob)
+	if err != nil {
+		return nil, xerrors.Errorf("read files of %s: %w", toJob.ID, err)
+	}
+
+	paths := make([]string, 0, len(fromFiles)+len(toFiles))
+	for name := range fromFiles {
+		paths = append(paths, name)
+	}
+	for name := range toFiles {
+		if _, ok := fromFiles[name]; !ok {
+			paths = append(paths, name)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := make([]codersdk.TemplateVersionFileDiff, 0)
+	for _, name := range paths {
+		from, inFrom := fromFiles[name]
+		to, inTo := toFiles[name]
+		var status codersdk.TemplateVersionDiffStatus
+		switch {
+		case !inFrom:
+			status = codersdk.TemplateVersionDiffStatusAdded
+		case !inTo:
+			status = codersdk.TemplateVersionDiffStatusRemoved
+		case !bytes.Equal(from, to):
+			status = codersdk.TemplateVersionDiffStatusModified
+		default:
+			continue
+		}
+		fileDiff := codersdk.TemplateVersionFileDiff{
+			Path:   name,
+			Status: status,
+		}
+		if isTextFile(from) && isTextFile(to) {
+			var buf bytes.Buffer
+			// Strings are passed since nil contents make diff read the files
+			// from disk.
+			err = diff.Text("a/"+name, "b/"+name, string(from), string(to), &buf)
+			if err != nil {
+				return nil, xerrors.Errorf("diff %s: %w", name, err)
+			}
+			fileDiff.Diff = buf.String()
+		}
+		diffs = append(diffs, fileDiff)
+	}
+	return diffs, nil
+}
+
+// templateVersionFiles returns the regular files of the source archive of a
+// template version, by their path in the archive.
+func (api *API) templateVersionFiles(ctx context.Context, job database.ProvisionerJob) (map[string][]byte, error) {
+	if job.StorageMethod != database.ProvisionerStorageMethodFile {
+		return nil, xerrors.Errorf("unsupported storage method: %s", job.StorageMethod)
+	}
+	file, err := api.Database.GetFileByHash(ctx, job.StorageSource)
+	if err != nil {
+		return nil, xerrors.Errorf("get file by hash: %w", err)
+	}
+
+	files := map[string][]byte{}
+	reader := tar.NewReader(bytes.NewReader(file.Data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, xerrors.Errorf("read %s: %w", header.Name, err)
+		}
+		files[path.Clean(header.Name)] = data
+	}
+}
+
+// isTextFile is true for files diffs are shown for.
+func isTextFile(data []byte) bool {
+	return utf8.Valid(data) && !bytes.ContainsRune(data, 0)
+}
+
+func (api *API) diffTemplateVersionParameters(ctx context.Context, fromJob, toJob database.ProvisionerJob) ([]codersdk.TemplateVersionParameterDiff, error) {
+	fromSchemas, err := api.Database.GetParameterSchemasByJobID(ctx, fromJob.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get parameter schemas of %s: %w", fromJob.ID, err)
+	}
+	toSchemas, err := api.Database.GetParameterSchemasByJobID(ctx, toJob.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get parameter schemas of %s: %w", toJob.ID, err)
+	}
+
+	fromByName := make(map[string]database.ParameterSchema, len(fromSchemas))
+	names := make([]string, 0, len(fromSchemas)+len(toSchemas))
+	for _, schema := range fromSchemas {
+		fromByName[schema.Name] = schema
+		names = append(names, schema.Name)
+	}
+	toByName := make(map[string]database.ParameterSchema, len(toSchemas))
+	for _, schema := range toSchemas {
+		toByName[schema.Name] = schema
+		if _, ok := fromByName[schema.Name]; !ok {
+			names = append(names, schema.Name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]codersdk.TemplateVersionParameterDiff, 0)
+	for _, name := range names {
+		from, inFrom := fromByName[name]
+		to, inTo := toByName[name]
+		diff := codersdk.TemplateVersionParameterDiff{Name: name}
+		switch {
+		case !inFrom:
+			diff.Status = codersdk.TemplateVersionDiffStatusAdded
+		case !inTo:
+			diff.Status = codersdk.TemplateVersionDiffStatusRemoved
+		case !sameParameterSchema(from, to):
+			diff.Status = codersdk.TemplateVersionDiffStatusModified
+		default:
+			continue
+		}
+		if inFrom {
+			converted, err := convertParameterSchema(from)
+			if err != nil {
+				return nil, xerrors.Errorf("convert parameter schema %s: %w", name, err)
+			}
+			diff.From = &converted
+		}
+		if inTo {
+			converted, err := convertParameterSchema(to)
+			if err != nil {
+				return nil, xerrors.Errorf("convert parameter schema %s: %w", name, err)
+			}
+			diff.To = &converted
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// sameParameterSchema is true if the schemas only differ in what identifies
+// them and their order.
+func sameParameterSchema(a, b database.ParameterSchema) bool {
+	a.ID, b.ID = uuid.Nil, uuid.Nil
+	a.JobID, b.JobID = uuid.Nil, uuid.Nil
+	a.CreatedAt = b.CreatedAt
+	a.Index, b.Index = 0, 0
+	return a == b
+}
+
+// templateVersionResource is a resource of the plan of a template version
+// import with its agents and metadata.
+type templateVersionResource struct {
+	resource database.WorkspaceResource
+	agents   []string
+	metadata map[string]database.WorkspaceResourceMetadatum
+}
+
+func (api *API) diffTemplateVersionResources(ctx context.Context, fromJob, toJob database.ProvisionerJob) ([]codersdk.TemplateVersionResourceDiff, error) {
+	fromResources, err := api.templateVersionPlannedResources(ctx, fromJob)
+	if err != nil {
+		return nil, xerrors.Errorf("get resources of %s: %w", fromJob.ID, err)
+	}
+	toResources, err := api.templateVersionPlannedResources(ctx, toJob)
+	if err != nil {
+		return nil, xerrors.Errorf("get resources of %s: %w", toJob.ID, err)
+	}
+
+	keys := make([]string, 0, len(fromResources)+len(toResources))
+	for key := range fromResources {
+		keys = append(keys, key)
+	}
+	for key := range toResources {
+		if _, ok := fromResources[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diffs := make([]codersdk.TemplateVersionResourceDiff, 0)
+	for _, key := range keys {
+		from, inFrom := fromResources[key]
+		to, inTo := toResources[key]
+		resource := to.resource
+		if !inTo {
+			resource = from.resource
+		}
+		diff := codersdk.TemplateVersionResourceDiff{
+			Transition:      codersdk.WorkspaceTransition(resource.Transition),
+			Type:            resource.Type,
+			Name:            resource.Name,
+			AgentsAdded:     []string{},
+			AgentsRemoved:   []string{},
+			MetadataChanged: []string{},
+		}
+		for _, agent := range to.agents {
+			if !slices.Contains(from.agents, agent) {
+				diff.AgentsAdded = append(diff.AgentsAdded, agent)
+			}
+		}
+		for _, agent := range from.agents {
+			if !slices.Contains(to.agents, agent) {
+				diff.AgentsRemoved = append(diff.AgentsRemoved, agent)
+			}
+		}
+		for metadataKey, metadatum := range from.metadata {
+			if other, ok := to.metadata[metadataKey]; !ok || other.Value != metadatum.Value {
+				diff.MetadataChanged = append(diff.MetadataChanged, metadataKey)
+			}
+		}
+		for metadataKey := range to.metadata {
+			if _, ok := from.metadata[metadataKey]; !ok {
+				diff.MetadataChanged = append(diff.MetadataChanged, metadataKey)
+			}
+		}
+		sort.Strings(diff.MetadataChanged)
+
+		switch {
+		case !inFrom:
+			diff.Status = codersdk.TemplateVersionDiffStatusAdded
+		case !inTo:
+			diff.Status = codersdk.TemplateVersionDiffStatusRemoved
+		case len(diff.AgentsAdded) > 0 || len(diff.AgentsRemoved) > 0 || len(diff.MetadataChanged) > 0:
+			diff.Status = codersdk.TemplateVersionDiffStatusModified
+		default:
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// templateVersionPlannedResources returns the resources of a template version
+// import by their transition, type, and name.
+func (api *API) templateVersionPlannedResources(ctx context.Context, job database.ProvisionerJob) (map[string]templateVersionResource, error) {
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(ctx, job.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get workspace resources: %w", err)
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get workspace agents: %w", err)
+	}
+	metadata, err := api.Database.GetWorkspaceResourceMetadataByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get workspace resource metadata: %w", err)
+	}
+
+	byKey := make(map[string]templateVersionResource, len(resources))
+	for _, resource := range resources {
+		converted := templateVersionResource{
+			resource: resource,
+			agents:   []string{},
+			metadata: map[string]database.WorkspaceResourceMetadatum{},
+		}
+		for _, agent := range agents {
+			if agent.ResourceID == resource.ID {
+				converted.agents = append(converted.agents, agent.Name)
+			}
+		}
+		for _, metadatum := range metadata {
+			if metadatum.WorkspaceResourceID == resource.ID {
+				converted.metadata[metadatum.Key] = metadatum
+			}
+		}
+		byKey[fmt.Sprintf("%s/%s.%s", resource.Transition, resource.Type, resource.Name)] = converted
+	}
+	return byKey, nil
+}
@@ -0,0 +1,193 @@
+package coderd_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateVersionDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		createVersion := func(parameter, agent string) codersdk.TemplateVersion {
+			version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+				Parse: []*proto.Parse_Response{{
+					Type: &proto.Parse_Response_Complete{
+						Complete: &proto.Parse_Complete{
+							ParameterSchemas: []*proto.ParameterSchema{{
+								Name: parameter,
+								DefaultSource: &proto.ParameterSource{
+									Scheme: proto.ParameterSource_DATA,
+									Value:  "default",
+								},
+								DefaultDestination: &proto.ParameterDestination{
+									Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+								},
+							}},
+						},
+					},
+				}},
+				Provision: []*proto.Provision_Response{{
+					Type: &proto.Provision_Response_Complete{
+						Complete: &proto.Provision_Complete{
+							Resources: []*proto.Resource{{
+								Name: "dev",
+								Type: "docker_container",
+								Agents: []*proto.Agent{{
+									Name: agent,
+									Auth: &proto.Agent_Token{},
+								}},
+							}},
+						},
+					},
+				}},
+			})
+			coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			return version
+		}
+		from := createVersion("region", "main")
+		to := createVersion("location", "dev")
+
+		ctx, _ := testutil.Context(t)
+		diff, err := client.TemplateVersionDiff(ctx, from.ID, to.ID)
+		require.NoError(t, err)
+		require.Equal(t, from.ID, diff.FromVersionID)
+		require.Equal(t, to.ID, diff.ToVersionID)
+
+		require.NotEmpty(t, diff.Files)
+		for _, file := range diff.Files {
+			require.Equal(t, codersdk.TemplateVersionDiffStatusModified, file.Status)
+		}
+
+		require.Len(t, diff.Parameters, 2)
+		require.Equal(t, "location", diff.Parameters[0].Name)
+		require.Equal(t, codersdk.TemplateVersionDiffStatusAdded, diff.Parameters[0].Status)
+		require.Nil(t, diff.Parameters[0].From)
+		require.NotNil(t, diff.Parameters[0].To)
+		require.Equal(t, "region", diff.Parameters[1].Name)
+		require.Equal(t, codersdk.TemplateVersionDiffStatusRemoved, diff.Parameters[1].Status)
+		require.NotNil(t, diff.Parameters[1].From)
+		require.Nil(t, diff.Parameters[1].To)
+
+		require.NotEmpty(t, diff.Resources)
+		for _, resource := range diff.Resources {
+			require.Equal(t, "docker_container", resource.Type)
+			require.Equal(t, "dev", resource.Name)
+			require.Equal(t, codersdk.TemplateVersionDiffStatusModified, resource.Status)
+			require.Equal(t, []string{"dev"}, resource.AgentsAdded)
+			require.Equal(t, []string{"main"}, resource.AgentsRemoved)
+		}
+
+		// Nothing changes from a version to itself.
+		diff, err = client.TemplateVersionDiff(ctx, from.ID, from.ID)
+		require.NoError(t, err)
+		require.Empty(t, diff.Files)
+		require.Empty(t, diff.Parameters)
+		require.Empty(t, diff.Resources)
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		createVersion := func(mainTF string) codersdk.TemplateVersion {
+			ctx, _ := testutil.Context(t)
+			file, err := client.Upload(ctx, codersdk.ContentTypeTar, tarWithFile(t, "main.tf", mainTF))
+			require.NoError(t, err)
+			version, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+				StorageSource: file.Hash,
+				StorageMethod: codersdk.ProvisionerStorageMethodFile,
+				Provisioner:   codersdk.ProvisionerTypeEcho,
+			})
+			require.NoError(t, err)
+			coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			return version
+		}
+		from := createVersion("image = \"ubuntu:20.04\"\n")
+		to := createVersion("image = \"ubuntu:22.04\"\n")
+
+		ctx, _ := testutil.Context(t)
+		diff, err := client.TemplateVersionDiff(ctx, from.ID, to.ID)
+		require.NoError(t, err)
+		require.Len(t, diff.Files, 1)
+		require.Equal(t, "main.tf", diff.Files[0].Path)
+		require.Equal(t, codersdk.TemplateVersionDiffStatusModified, diff.Files[0].Status)
+		require.Contains(t, diff.Files[0].Diff, "--- a/main.tf")
+		require.Contains(t, diff.Files[0].Diff, "-image = \"ubuntu:20.04\"")
+		require.Contains(t, diff.Files[0].Diff, "+image = \"ubuntu:22.04\"")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.TemplateVersionDiff(ctx, version.ID, uuid.New())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		from := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		to := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		// Without a provisioner daemon, the versions are never imported.
+		ctx, _ := testutil.Context(t)
+		_, err := client.TemplateVersionDiff(ctx, from.ID, to.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusPreconditionFailed, apiErr.StatusCode())
+	})
+}
+
+// tarWithFile returns the archive of the default echo responses with
+// another file.
+func tarWithFile(t *testing.T, name, content string) []byte {
+	t.Helper()
+	data, err := echo.Tar(nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteHeader(header))
+		_, err = io.Copy(writer, reader)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(content)),
+	}))
+	_, err = writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
@@ -453,7 +453,7 @@ func (api *API) templateVersionsByTemplate(rw http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	paginationParams, ok := parsePagination(rw, r)
+	paginationParams, ok := ParsePagination(rw, r)
 	if !ok {
 		return
 	}
@@ -659,6 +659,13 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	newTemplate.ActiveVersionID = req.ID
 	aReq.New = newTemplate
 
+	// Promoting a version publishes it to the users of the template.
+	api.publishOrganizationEvent(template.OrganizationID, codersdk.OrganizationWebhookEvent{
+		Type:         codersdk.OrganizationWebhookEventTemplatePublished,
+		ResourceID:   template.ID,
+		ResourceName: template.Name,
+	})
+
 	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
 		Message: "Updated the active template version!",
 	})
@@ -716,6 +723,21 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		return
 	}
 
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	// Values copied from earlier versions were checked when they were set.
+	req.ParameterValues = withDefaultParameterValues(req.ParameterValues, defaults.ParameterValues)
+	if validErrs := disallowedParameterValues(defaults.AllowedParameterValues, req.ParameterValues); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid create template version request.",
+			Validations: validErrs,
+		})
+		return
+	}
+
 	file, err := api.Database.GetFileByHash(ctx, req.StorageSource)
 	if errors.Is(err, sql.ErrNoRows) {
 		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/google/go-github/v43/github"
@@ -181,13 +183,86 @@ type OIDCConfig struct {
 	// EmailDomain is the domain to enforce when a user authenticates.
 	EmailDomain  string
 	AllowSignups bool
+	// EmailField and UsernameField are the claims that hold the email
+	// and username of a user. They default to "email" and
+	// "preferred_username".
+	EmailField    string
+	UsernameField string
+	// GroupField is the claim that lists the groups a user belongs to.
+	// Groups aren't synced when it's empty.
+	GroupField string
+	// GroupFilter drops groups whose names don't match it. All groups
+	// are kept when it's nil.
+	GroupFilter *regexp.Regexp
+	// GroupMapping renames groups from the identity provider to Coder
+	// group names. Groups missing from the mapping keep their name.
+	GroupMapping map[string]string
+}
+
+// Groups returns the Coder group names listed in the group claim after
+// filtering and mapping. The boolean is false when groups shouldn't be
+// synced, either because it's disabled or the claim is missing.
+func (cfg *OIDCConfig) Groups(claims map[string]interface{}) ([]string, bool) {
+	if cfg.GroupField == "" {
+		return nil, false
+	}
+	raw, ok := claims[cfg.GroupField]
+	if !ok {
+		return nil, false
+	}
+
+	var names []string
+	switch value := raw.(type) {
+	case string:
+		// Some providers send a single group as a plain string.
+		names = []string{value}
+	case []interface{}:
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				continue
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, false
+	}
+
+	groups := make([]string, 0, len(names))
+	for _, name := range names {
+		if cfg.GroupFilter != nil && !cfg.GroupFilter.MatchString(name) {
+			continue
+		}
+		if mapped, ok := cfg.GroupMapping[name]; ok {
+			name = mapped
+		}
+		if name == "" {
+			continue
+		}
+		groups = append(groups, name)
+	}
+	return groups, true
 }
 
 func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
+	api.oidcLogin(rw, r, api.OIDCConfig, uuid.Nil)
+}
+
+// oidcLogin signs in the user of the OIDC token in the OAuth2 state. New
+// users join the organization, or the default organization if it's
+// uuid.Nil.
+func (api *API) oidcLogin(rw http.ResponseWriter, r *http.Request, cfg *OIDCConfig, organizationID uuid.UUID) {
 	var (
 		ctx   = r.Context()
 		state = httpmw.OAuth2(r)
 	)
+	if organizationID != uuid.Nil {
+		// Expired tokens are refreshed with the identity provider of the
+		// deployment, which can't refresh tokens of other providers.
+		token := *state.Token
+		token.Expiry = time.Time{}
+		state.Token = &token
+	}
 
 	// See the example here: https://github.com/coreos/go-oidc
 	rawIDToken, ok := state.Token.Extra("id_token").(string)
@@ -198,7 +273,7 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idToken, err := api.OIDCConfig.Verifier.Verify(ctx, rawIDToken)
+	idToken, err := cfg.Verifier.Verify(ctx, rawIDToken)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Failed to verify OIDC token.",
@@ -219,7 +294,11 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	emailRaw, ok := claims["email"]
+	emailField := cfg.EmailField
+	if emailField == "" {
+		emailField = "email"
+	}
+	emailRaw, ok := claims[emailField]
 	if !ok {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "No email found in OIDC payload!",
@@ -243,7 +322,11 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	usernameRaw, ok := claims["preferred_username"]
+	usernameField := cfg.UsernameField
+	if usernameField == "" {
+		usernameField = "preferred_username"
+	}
+	usernameRaw, ok := claims[usernameField]
 	var username string
 	if ok {
 		username, _ = usernameRaw.(string)
@@ -260,10 +343,10 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		}
 		username = httpapi.UsernameFrom(username)
 	}
-	if api.OIDCConfig.EmailDomain != "" {
-		if !strings.HasSuffix(email, api.OIDCConfig.EmailDomain) {
+	if cfg.EmailDomain != "" {
+		if !strings.HasSuffix(email, cfg.EmailDomain) {
 			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
-				Message: fmt.Sprintf("Your email %q is not a part of the %q domain!", email, api.OIDCConfig.EmailDomain),
+				Message: fmt.Sprintf("Your email %q is not a part of the %q domain!", email, cfg.EmailDomain),
 			})
 			return
 		}
@@ -274,14 +357,19 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		picture, _ = pictureRaw.(string)
 	}
 
+	groups, syncGroups := cfg.Groups(claims)
+
 	cookie, err := api.oauthLogin(r, oauthLoginParams{
-		State:        state,
-		LinkedID:     oidcLinkedID(idToken),
-		LoginType:    database.LoginTypeOIDC,
-		AllowSignups: api.OIDCConfig.AllowSignups,
-		Email:        email,
-		Username:     username,
-		AvatarURL:    picture,
+		State:          state,
+		LinkedID:       oidcLinkedID(idToken),
+		LoginType:      database.LoginTypeOIDC,
+		OrganizationID: organizationID,
+		AllowSignups:   cfg.AllowSignups,
+		Email:          email,
+		Username:       username,
+		AvatarURL:      picture,
+		SyncGroups:     syncGroups,
+		Groups:         groups,
 	})
 	var httpErr httpError
 	if xerrors.As(err, &httpErr) {
@@ -312,6 +400,9 @@ type oauthLoginParams struct {
 	State     httpmw.OAuth2State
 	LinkedID  string
 	LoginType database.LoginType
+	// OrganizationID is the organization users join when signing in. New
+	// users join the default organization when it's uuid.Nil.
+	OrganizationID uuid.UUID
 
 	// The following are necessary in order to
 	// create new users.
@@ -319,6 +410,10 @@ type oauthLoginParams struct {
 	Email        string
 	Username     string
 	AvatarURL    string
+
+	// SyncGroups reconciles the user's group memberships with Groups.
+	SyncGroups bool
+	Groups     []string
 }
 
 type httpError struct {
@@ -339,6 +434,11 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 	var (
 		ctx  = r.Context()
 		user database.User
+		// joinedOrganizationID is the organization the user became a
+		// member of by signing in, if any.
+		joinedOrganizationID uuid.UUID
+		// createdUser is whether the user signed up with this login.
+		createdUser bool
 	)
 
 	err := api.Database.InTx(func(tx database.Store) error {
@@ -372,13 +472,13 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 		// This can happen if a user is a built-in user but is signing in
 		// with OIDC for the first time.
 		if user.ID == uuid.Nil {
-			var organizationID uuid.UUID
-			organizations, _ := tx.GetOrganizations(ctx)
-			if len(organizations) > 0 {
-				// Add the user to the first organization. Once multi-organization
-				// support is added, we should enable a configuration map of user
-				// email to organization.
-				organizationID = organizations[0].ID
+			organizationID := params.OrganizationID
+			if organizationID == uuid.Nil {
+				// Add the user to the default organization.
+				organization, err := tx.GetDefaultOrganization(ctx)
+				if err == nil {
+					organizationID = organization.ID
+				}
 			}
 
 			user, _, err = api.CreateUser(ctx, tx, CreateUserRequest{
@@ -392,6 +492,30 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 			if err != nil {
 				return xerrors.Errorf("create user: %w", err)
 			}
+			joinedOrganizationID = organizationID
+			createdUser = true
+		}
+
+		if params.OrganizationID != uuid.Nil {
+			// Existing users join the organization of the identity
+			// provider they signed in with.
+			_, err = tx.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+				OrganizationID: params.OrganizationID,
+				UserID:         user.ID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				_, err = tx.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
+					OrganizationID: params.OrganizationID,
+					UserID:         user.ID,
+					CreatedAt:      database.Now(),
+					UpdatedAt:      database.Now(),
+					Roles:          []string{},
+				})
+				joinedOrganizationID = params.OrganizationID
+			}
+			if err != nil {
+				return xerrors.Errorf("join organization: %w", err)
+			}
 		}
 
 		if link.UserID == uuid.Nil {
@@ -476,11 +600,30 @@ func (api *API) oauthLogin(r *http.Request, params oauthLoginParams) (*http.Cook
 			}
 		}
 
+		if params.SyncGroups {
+			syncer := *api.GroupSyncer.Load()
+			err = syncer.SyncGroups(ctx, tx, user.ID, params.Groups)
+			if err != nil {
+				return xerrors.Errorf("sync groups: %w", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
 		return nil, xerrors.Errorf("in tx: %w", err)
 	}
+	if createdUser {
+		api.PublishUserWebhookEvent(codersdk.WebhookEventUserCreated, user)
+	}
+	if joinedOrganizationID != uuid.Nil {
+		api.publishOrganizationEvent(joinedOrganizationID, codersdk.OrganizationWebhookEvent{
+			Type:         codersdk.OrganizationWebhookEventMemberAdded,
+			ResourceID:   user.ID,
+			ResourceName: user.Username,
+		})
+		api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionCreated, joinedOrganizationID, user.ID)
+	}
 
 	cookie, err := api.createAPIKey(ctx, createAPIKeyParams{
 		UserID:     user.ID,
@@ -8,12 +8,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/go-github/v43/github"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
@@ -21,6 +24,7 @@ import (
 
 	"github.com/coder/coder/coderd"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
@@ -460,6 +464,47 @@ func TestUserOIDC(t *testing.T) {
 		})
 	}
 
+	t.Run("GroupSync", func(t *testing.T) {
+		t.Parallel()
+		config := createOIDCConfig(t, jwt.MapClaims{
+			"email":  "kyle@kwc.io",
+			"groups": []string{"coder-admins", "coder-devs", "marketing"},
+		})
+		config.AllowSignups = true
+		config.GroupField = "groups"
+		config.GroupFilter = regexp.MustCompile("^coder-")
+		config.GroupMapping = map[string]string{"coder-admins": "admins"}
+		syncer := &fakeGroupSyncer{}
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCConfig:  config,
+			GroupSyncer: syncer,
+		})
+		resp := oidcCallback(t, client)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		syncer.mu.Lock()
+		defer syncer.mu.Unlock()
+		require.Equal(t, []string{"admins", "coder-devs"}, syncer.groups)
+	})
+
+	t.Run("GroupSyncMissingClaim", func(t *testing.T) {
+		t.Parallel()
+		config := createOIDCConfig(t, jwt.MapClaims{
+			"email": "kyle@kwc.io",
+		})
+		config.AllowSignups = true
+		config.GroupField = "groups"
+		syncer := &fakeGroupSyncer{}
+		client := coderdtest.New(t, &coderdtest.Options{
+			OIDCConfig:  config,
+			GroupSyncer: syncer,
+		})
+		resp := oidcCallback(t, client)
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		syncer.mu.Lock()
+		defer syncer.mu.Unlock()
+		require.False(t, syncer.called)
+	})
+
 	t.Run("Disabled", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, nil)
@@ -501,6 +546,20 @@ func TestUserOIDC(t *testing.T) {
 	})
 }
 
+type fakeGroupSyncer struct {
+	mu     sync.Mutex
+	called bool
+	groups []string
+}
+
+func (s *fakeGroupSyncer) SyncGroups(_ context.Context, _ database.Store, _ uuid.UUID, groups []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.called = true
+	s.groups = groups
+	return nil
+}
+
 // createOIDCConfig generates a new OIDCConfig that returns a static token
 // with the claims provided.
 func createOIDCConfig(t *testing.T, claims jwt.MapClaims) *coderd.OIDCConfig {
@@ -556,12 +615,17 @@ func oauth2Callback(t *testing.T, client *codersdk.Client) *http.Response {
 }
 
 func oidcCallback(t *testing.T, client *codersdk.Client) *http.Response {
+	t.Helper()
+	return oidcCallbackPath(t, client, "/api/v2/users/oidc/callback")
+}
+
+func oidcCallbackPath(t *testing.T, client *codersdk.Client, path string) *http.Response {
 	t.Helper()
 	client.HTTPClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		return http.ErrUseLastResponse
 	}
 	state := "somestate"
-	oauthURL, err := client.URL.Parse("/api/v2/users/oidc/callback?code=asd&state=" + state)
+	oauthURL, err := client.URL.Parse(path + "?code=asd&state=" + state)
 	require.NoError(t, err)
 	req, err := http.NewRequestWithContext(context.Background(), "GET", oauthURL.String(), nil)
 	require.NoError(t, err)
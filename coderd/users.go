@@ -12,7 +12,6 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
@@ -98,6 +97,8 @@ func (api *API) postFirstUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	api.PublishUserWebhookEvent(codersdk.WebhookEventUserCreated, user)
+
 	telemetryUser := telemetry.ConvertUser(user)
 	// Send the initial users email address!
 	telemetryUser.Email = &user.Email
@@ -193,7 +194,11 @@ func (api *API) users(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	paginationParams, ok := parsePagination(rw, r)
+	paginationParams, ok := ParsePagination(rw, r)
+	if !ok {
+		return
+	}
+	fields, ok := httpapi.ParseFields(rw, r, codersdk.User{})
 	if !ok {
 		return
 	}
@@ -247,8 +252,7 @@ func (api *API) users(rw http.ResponseWriter, r *http.Request) {
 		organizationIDsByUserID[organizationIDsByMemberIDsRow.UserID] = organizationIDsByMemberIDsRow.OrganizationIDs
 	}
 
-	render.Status(r, http.StatusOK)
-	render.JSON(rw, r, convertUsers(users, organizationIDsByUserID))
+	httpapi.WriteFields(ctx, rw, http.StatusOK, convertUsers(users, organizationIDsByUserID), fields, "")
 }
 
 // Creates a new user.
@@ -334,6 +338,13 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 	api.Telemetry.Report(&telemetry.Snapshot{
 		Users: []telemetry.User{telemetry.ConvertUser(user)},
 	})
+	api.publishOrganizationEvent(req.OrganizationID, codersdk.OrganizationWebhookEvent{
+		Type:         codersdk.OrganizationWebhookEventMemberAdded,
+		ResourceID:   user.ID,
+		ResourceName: user.Username,
+	})
+	api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionCreated, req.OrganizationID, user.ID)
+	api.PublishUserWebhookEvent(codersdk.WebhookEventUserCreated, user)
 
 	httpapi.Write(ctx, rw, http.StatusCreated, convertUser(user, []uuid.UUID{req.OrganizationID}))
 }
@@ -386,6 +397,22 @@ func (api *API) deleteUser(rw http.ResponseWriter, r *http.Request) {
 	}
 	user.Deleted = true
 	aReq.New = user
+	api.PublishUserWebhookEvent(codersdk.WebhookEventUserDeleted, user)
+
+	// Deleted users are no longer members of any organization.
+	organizationIDs, err := userOrganizationIDs(ctx, api, user)
+	if err != nil {
+		api.Logger.Warn(ctx, "get organizations of deleted user", slog.Error(err))
+	}
+	for _, organizationID := range organizationIDs {
+		api.publishOrganizationEvent(organizationID, codersdk.OrganizationWebhookEvent{
+			Type:         codersdk.OrganizationWebhookEventMemberRemoved,
+			ResourceID:   user.ID,
+			ResourceName: user.Username,
+		})
+		api.publishOrganizationMemberEvent(ctx, codersdk.ResourceEventActionDeleted, organizationID, user.ID)
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
 		Message: "User has been deleted!",
 	})
@@ -549,6 +576,9 @@ func (api *API) putUserStatus(status database.UserStatus) func(rw http.ResponseW
 			return
 		}
 		aReq.New = suspendedUser
+		if user.Status != status {
+			api.PublishUserWebhookEvent(userStatusWebhookEvent(status), suspendedUser)
+		}
 
 		organizations, err := userOrganizationIDs(ctx, api, user)
 		if err != nil {
@@ -846,6 +876,19 @@ func (api *API) organizationByUserAndName(rw http.ResponseWriter, r *http.Reques
 	ctx := r.Context()
 	organizationName := chi.URLParam(r, "organizationname")
 	organization, err := api.Database.GetOrganizationByName(ctx, organizationName)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The organization may have been renamed.
+		var alias database.OrganizationAlias
+		alias, err = api.Database.GetOrganizationAliasByName(ctx, organizationName)
+		if err == nil {
+			organization, err = api.Database.GetOrganizationByID(ctx, alias.OrganizationID)
+		}
+		if err == nil {
+			rw.Header().Set("Deprecation", "true")
+			rw.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"",
+				strings.Replace(r.URL.Path, "/"+organizationName, "/"+organization.Name, 1)))
+		}
+	}
 	if errors.Is(err, sql.ErrNoRows) {
 		httpapi.ResourceNotFound(rw)
 		return
@@ -1013,19 +1056,29 @@ func (api *API) postLogout(rw http.ResponseWriter, r *http.Request) {
 type CreateUserRequest struct {
 	codersdk.CreateUserRequest
 	LoginType database.LoginType
+	// OrganizationRoles are granted to the user in an existing organization.
+	OrganizationRoles []string
 }
 
 func (api *API) CreateUser(ctx context.Context, store database.Store, req CreateUserRequest) (database.User, uuid.UUID, error) {
 	var user database.User
 	return user, req.OrganizationID, store.InTx(func(tx database.Store) error {
-		orgRoles := make([]string, 0)
+		orgRoles := append(make([]string, 0), req.OrganizationRoles...)
 		// If no organization is provided, create a new one for the user.
 		if req.OrganizationID == uuid.Nil {
+			// The first organization becomes the default one, which new
+			// users join and which can't be deleted.
+			_, err := tx.GetDefaultOrganization(ctx)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get default organization: %w", err)
+			}
+			isDefault := errors.Is(err, sql.ErrNoRows)
 			organization, err := tx.InsertOrganization(ctx, database.InsertOrganizationParams{
 				ID:        uuid.New(),
 				Name:      req.Username,
 				CreatedAt: database.Now(),
 				UpdatedAt: database.Now(),
+				IsDefault: isDefault,
 			})
 			if err != nil {
 				return xerrors.Errorf("create organization: %w", err)
@@ -1199,7 +1252,7 @@ func parseUserStatus(v string) ([]database.UserStatus, error) {
 }
 
 func convertAPIKey(k database.APIKey) codersdk.APIKey {
-	return codersdk.APIKey{
+	key := codersdk.APIKey{
 		ID:              k.ID,
 		UserID:          k.UserID,
 		LastUsed:        k.LastUsed,
@@ -1208,5 +1261,14 @@ func convertAPIKey(k database.APIKey) codersdk.APIKey {
 		UpdatedAt:       k.UpdatedAt,
 		LoginType:       codersdk.LoginType(k.LoginType),
 		LifetimeSeconds: k.LifetimeSeconds,
+		Scope:           codersdk.APIKeyScope(k.Scope),
+	}
+	if k.Scope == database.APIKeyScopeRestricted {
+		key.Permissions = k.ScopePermissions
+		if k.ScopeOrganizationID.Valid {
+			orgID := k.ScopeOrganizationID.UUID
+			key.OrganizationID = &orgID
+		}
 	}
+	return key
 }
@@ -286,7 +286,7 @@ func TestPostLogin(t *testing.T) {
 		require.Equal(t, int64(86400), key.LifetimeSeconds, "default should be 86400")
 
 		// tokens have a longer life
-		token, err := client.CreateToken(ctx, codersdk.Me)
+		token, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
 		require.NoError(t, err, "make new token api key")
 		split = strings.Split(token.Key, "-")
 		apiKey, err := client.GetAPIKey(ctx, admin.UserID.String(), split[0])
@@ -1192,6 +1192,31 @@ func TestGetUsers(t *testing.T) {
 		require.NoError(t, err)
 		require.ElementsMatch(t, active, users)
 	})
+	t.Run("Fields", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		users, err := client.Users(ctx, codersdk.UsersRequest{
+			Fields: []string{"id", "username"},
+		})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, first.UserID, users[0].ID)
+		require.NotEmpty(t, users[0].Username)
+		require.Empty(t, users[0].Email)
+		require.Empty(t, users[0].OrganizationIDs)
+
+		_, err = client.Users(ctx, codersdk.UsersRequest{
+			Fields: []string{"password"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
 }
 
 func TestPostTokens(t *testing.T) {
@@ -1202,7 +1227,7 @@ func TestPostTokens(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
 	defer cancel()
 
-	apiKey, err := client.CreateToken(ctx, codersdk.Me)
+	apiKey, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
 	require.NotNil(t, apiKey)
 	require.GreaterOrEqual(t, len(apiKey.Key), 2)
 	require.NoError(t, err)
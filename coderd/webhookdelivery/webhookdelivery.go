@@ -0,0 +1,377 @@
+// Package webhookdelivery delivers events to deployment, organization, and
+// group webhooks. Deliveries are queued in the database, so pending retries
+// survive a restart and are shared between replicas.
+package webhookdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	// MaxAttempts is how many times an event is sent to a webhook before
+	// it's dropped.
+	MaxAttempts = 5
+	// leaseDuration is how long an item is hidden from other replicas while
+	// it's delivered.
+	leaseDuration = time.Minute
+	// acquireLimit is how many items are acquired at once.
+	acquireLimit = 25
+	// pollInterval is how often the queue is checked for items that weren't
+	// queued by this replica, or whose lease ran out.
+	pollInterval = 10 * time.Second
+)
+
+// Kind is the kind of webhook an item is queued for. It decides the table
+// the webhook is looked up in.
+type Kind string
+
+const (
+	KindDeployment   Kind = "deployment"
+	KindOrganization Kind = "organization"
+	KindGroup        Kind = "group"
+)
+
+// Webhook is the endpoint an event is sent to.
+type Webhook struct {
+	URL    string
+	Secret string
+}
+
+// Event is queued for delivery to webhooks.
+type Event struct {
+	ID      uuid.UUID
+	Type    string
+	Payload []byte
+}
+
+// Attempt is the outcome of sending a queued event to its webhook.
+type Attempt struct {
+	Item database.WebhookQueue
+	// Number counts the attempts at delivering the item, starting at one.
+	Number     int32
+	StatusCode int
+	Err        error
+}
+
+// Handler connects a kind of webhook to the engine.
+type Handler struct {
+	// Webhook returns the webhook with the ID. Items are dropped when it
+	// returns sql.ErrNoRows, since the webhook was deleted.
+	Webhook func(ctx context.Context, id uuid.UUID) (Webhook, error)
+	// Record is called after every attempt, e.g. to keep a delivery log. It's
+	// optional.
+	Record func(ctx context.Context, attempt Attempt) error
+	// RetryInterval is the delay before a failed delivery is retried. It
+	// doubles with every attempt.
+	RetryInterval time.Duration
+}
+
+// Engine sends queued events to webhooks. Failed deliveries are retried with
+// exponential backoff on network errors, 429, and 5xx status codes.
+type Engine struct {
+	db     database.Store
+	logger slog.Logger
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	wake   chan struct{}
+
+	mutex    sync.RWMutex
+	handlers map[Kind]Handler
+}
+
+// New starts an engine. Items are only delivered once a handler for their
+// kind is registered.
+func New(db database.Store, logger slog.Logger) *Engine {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Engine{
+		db:       db,
+		logger:   logger,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		ctx:      ctx,
+		cancel:   cancel,
+		wake:     make(chan struct{}, 1),
+		handlers: map[Kind]Handler{},
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// Register delivers the items of the kind with the handler, including the
+// ones that were queued before the engine was started.
+func (e *Engine) Register(kind Kind, handler Handler) {
+	e.mutex.Lock()
+	e.handlers[kind] = handler
+	e.mutex.Unlock()
+	e.notify()
+}
+
+// Publish queues the event for each of the webhooks that list returns. It
+// runs in the background, so publishing never blocks the caller.
+func (e *Engine) Publish(kind Kind, event Event, list func(ctx context.Context) ([]uuid.UUID, error)) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		webhookIDs, err := list(e.ctx)
+		if err != nil {
+			if e.ctx.Err() == nil {
+				e.logger.Warn(e.ctx, "list webhooks", slog.F("kind", kind), slog.Error(err))
+			}
+			return
+		}
+		err = e.Enqueue(e.ctx, kind, event, webhookIDs)
+		if err != nil && e.ctx.Err() == nil {
+			e.logger.Warn(e.ctx, "queue webhook event",
+				slog.F("kind", kind),
+				slog.F("event_id", event.ID),
+				slog.F("event_type", event.Type),
+				slog.Error(err),
+			)
+		}
+	}()
+}
+
+// Enqueue queues the event for each of the webhooks.
+func (e *Engine) Enqueue(ctx context.Context, kind Kind, event Event, webhookIDs []uuid.UUID) error {
+	if len(webhookIDs) == 0 {
+		return nil
+	}
+	now := database.Now()
+	for _, webhookID := range webhookIDs {
+		_, err := e.db.InsertWebhookQueue(ctx, database.InsertWebhookQueueParams{
+			ID:        uuid.New(),
+			Kind:      string(kind),
+			WebhookID: webhookID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   event.Payload,
+			RunAt:     now,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert webhook queue: %w", err)
+		}
+	}
+	e.notify()
+	return nil
+}
+
+// Send makes a single signed attempt at delivering the payload, and returns
+// the status code of the response, or zero if there was none.
+func (e *Engine) Send(ctx context.Context, webhook Webhook, eventType string, payload []byte) (int, error) {
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	_, _ = mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, permanent(xerrors.Errorf("create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(codersdk.WebhookEventHeader, eventType)
+	req.Header.Set(codersdk.WebhookSignatureHeader, signature)
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return 0, xerrors.Errorf("send request: %w", err)
+	}
+	_ = res.Body.Close()
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return res.StatusCode, nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return res.StatusCode, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	default:
+		return res.StatusCode, permanent(xerrors.Errorf("unexpected status code %d", res.StatusCode))
+	}
+}
+
+// Close stops delivering. Items that are being delivered stay leased, and
+// are retried once the lease ends.
+func (e *Engine) Close() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+func (e *Engine) notify() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (e *Engine) handler(kind Kind) (Handler, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	handler, ok := e.handlers[kind]
+	return handler, ok
+}
+
+func (e *Engine) kinds() []string {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	kinds := make([]string, 0, len(e.handlers))
+	for _, kind := range maps.Keys(e.handlers) {
+		kinds = append(kinds, string(kind))
+	}
+	return kinds
+}
+
+func (e *Engine) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		e.acquire()
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.wake:
+		}
+	}
+}
+
+// acquire delivers the items that are due in the background.
+func (e *Engine) acquire() {
+	kinds := e.kinds()
+	if len(kinds) == 0 {
+		return
+	}
+	for {
+		now := database.Now()
+		items, err := e.db.AcquireWebhookQueue(e.ctx, database.AcquireWebhookQueueParams{
+			LeaseUntil: now.Add(leaseDuration),
+			Now:        now,
+			Kinds:      kinds,
+			LimitOpt:   acquireLimit,
+		})
+		if err != nil {
+			if e.ctx.Err() == nil {
+				e.logger.Warn(e.ctx, "acquire webhook queue", slog.Error(err))
+			}
+			return
+		}
+		for _, item := range items {
+			item := item
+			e.wg.Add(1)
+			go func() {
+				defer e.wg.Done()
+				e.deliver(item)
+			}()
+		}
+		if len(items) < acquireLimit {
+			return
+		}
+	}
+}
+
+// deliver makes an attempt at delivering the item. The item is removed once
+// it's delivered or can't be, and is rescheduled otherwise.
+func (e *Engine) deliver(item database.WebhookQueue) {
+	ctx := e.ctx
+	logger := e.logger.With(
+		slog.F("kind", item.Kind),
+		slog.F("webhook_id", item.WebhookID),
+		slog.F("event_id", item.EventID),
+		slog.F("event_type", item.EventType),
+	)
+	handler, ok := e.handler(Kind(item.Kind))
+	if !ok {
+		return
+	}
+
+	webhook, err := handler.Webhook(ctx, item.WebhookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		e.remove(ctx, item)
+		return
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn(ctx, "get webhook", slog.Error(err))
+		}
+		return
+	}
+
+	attempt := Attempt{
+		Item:   item,
+		Number: item.Attempts + 1,
+	}
+	attempt.StatusCode, attempt.Err = e.Send(ctx, webhook, item.EventType, item.Payload)
+	if ctx.Err() != nil {
+		// The attempt is made again once the lease ends.
+		return
+	}
+	if handler.Record != nil {
+		err = handler.Record(ctx, attempt)
+		if err != nil {
+			logger.Warn(ctx, "record webhook delivery", slog.Error(err))
+		}
+	}
+
+	var perm *permanentError
+	if attempt.Err == nil || errors.As(attempt.Err, &perm) || attempt.Number >= MaxAttempts {
+		if attempt.Err != nil {
+			logger.Warn(ctx, "deliver webhook", slog.F("attempt", attempt.Number), slog.Error(attempt.Err))
+		}
+		e.remove(ctx, item)
+		return
+	}
+
+	delay := handler.RetryInterval << (attempt.Number - 1)
+	err = e.db.UpdateWebhookQueueByID(ctx, database.UpdateWebhookQueueByIDParams{
+		ID:       item.ID,
+		Attempts: attempt.Number,
+		RunAt:    database.Now().Add(delay),
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn(ctx, "reschedule webhook delivery", slog.Error(err))
+		}
+		return
+	}
+	time.AfterFunc(delay, e.notify)
+}
+
+func (e *Engine) remove(ctx context.Context, item database.WebhookQueue) {
+	err := e.db.DeleteWebhookQueueByID(ctx, item.ID)
+	if err != nil && ctx.Err() == nil {
+		e.logger.Warn(ctx, "delete webhook queue item", slog.F("id", item.ID), slog.Error(err))
+	}
+}
+
+// permanentError is a failed delivery that isn't retried.
+type permanentError struct {
+	err error
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func (p *permanentError) Error() string {
+	return p.err.Error()
+}
+
+func (p *permanentError) Unwrap() error {
+	return p.err
+}
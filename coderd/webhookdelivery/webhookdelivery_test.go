@@ -0,0 +1,141 @@
+package webhookdelivery_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/webhookdelivery"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestEngine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Retries", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mutex    sync.Mutex
+			requests int
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			requests++
+			if requests < 3 {
+				rw.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(srv.Close)
+
+		db := databasefake.New()
+		engine := webhookdelivery.New(db, slogtest.Make(t, nil))
+		t.Cleanup(engine.Close)
+		attempts := make(chan webhookdelivery.Attempt, webhookdelivery.MaxAttempts)
+		engine.Register(webhookdelivery.KindDeployment, webhookdelivery.Handler{
+			Webhook: func(_ context.Context, _ uuid.UUID) (webhookdelivery.Webhook, error) {
+				return webhookdelivery.Webhook{URL: srv.URL, Secret: "secret"}, nil
+			},
+			Record: func(_ context.Context, attempt webhookdelivery.Attempt) error {
+				attempts <- attempt
+				return nil
+			},
+			RetryInterval: time.Millisecond,
+		})
+
+		ctx, cancel := testutil.Context(t)
+		defer cancel()
+		err := engine.Enqueue(ctx, webhookdelivery.KindDeployment, webhookdelivery.Event{
+			ID:      uuid.New(),
+			Type:    "workspace.created",
+			Payload: []byte(`{}`),
+		}, []uuid.UUID{uuid.New()})
+		require.NoError(t, err)
+
+		for i := int32(1); i <= 3; i++ {
+			select {
+			case <-ctx.Done():
+				t.Fatal("timed out waiting for attempt")
+			case attempt := <-attempts:
+				require.Equal(t, i, attempt.Number)
+				if i < 3 {
+					require.Equal(t, http.StatusBadGateway, attempt.StatusCode)
+					require.Error(t, attempt.Err)
+				} else {
+					require.Equal(t, http.StatusNoContent, attempt.StatusCode)
+					require.NoError(t, attempt.Err)
+				}
+			}
+		}
+		require.Eventually(t, func() bool {
+			items, err := db.AcquireWebhookQueue(ctx, database.AcquireWebhookQueueParams{
+				Now:      database.Now().Add(time.Hour),
+				Kinds:    []string{string(webhookdelivery.KindDeployment)},
+				LimitOpt: 1,
+			})
+			return err == nil && len(items) == 0
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+
+	t.Run("Resumes", func(t *testing.T) {
+		t.Parallel()
+		delivered := make(chan *http.Request, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			mac := hmac.New(sha256.New, []byte("secret"))
+			_, _ = mac.Write(body)
+			assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(codersdk.WebhookSignatureHeader))
+			delivered <- r
+		}))
+		t.Cleanup(srv.Close)
+
+		// The item was queued by a replica that stopped before delivering it.
+		ctx, cancel := testutil.Context(t)
+		defer cancel()
+		db := databasefake.New()
+		_, err := db.InsertWebhookQueue(ctx, database.InsertWebhookQueueParams{
+			ID:        uuid.New(),
+			Kind:      string(webhookdelivery.KindGroup),
+			WebhookID: uuid.New(),
+			EventID:   uuid.New(),
+			EventType: "group.created",
+			Payload:   []byte(`{}`),
+			RunAt:     database.Now(),
+		})
+		require.NoError(t, err)
+
+		engine := webhookdelivery.New(db, slogtest.Make(t, nil))
+		t.Cleanup(engine.Close)
+		engine.Register(webhookdelivery.KindGroup, webhookdelivery.Handler{
+			Webhook: func(_ context.Context, _ uuid.UUID) (webhookdelivery.Webhook, error) {
+				return webhookdelivery.Webhook{URL: srv.URL, Secret: "secret"}, nil
+			},
+			RetryInterval: time.Millisecond,
+		})
+
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for delivery")
+		case r := <-delivered:
+			require.Equal(t, "group.created", r.Header.Get(codersdk.WebhookEventHeader))
+		}
+	})
+}
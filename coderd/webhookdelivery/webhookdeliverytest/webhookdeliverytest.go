@@ -0,0 +1,73 @@
+// Package webhookdeliverytest receives webhook deliveries in tests.
+package webhookdeliverytest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+// NewReceiver returns the URL of a server that verifies the signature of
+// every delivery and sends the events it accepts on the returned channel.
+// If handler is set, it's called with the attempt number before the event is
+// accepted, and returning false rejects the delivery with whatever it wrote
+// to rw.
+func NewReceiver[E any](t testing.TB, secret string, handler func(attempt int64, rw http.ResponseWriter) bool) (string, <-chan E) {
+	t.Helper()
+
+	var attempts int64
+	events := make(chan E, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(codersdk.WebhookSignatureHeader))
+
+		if handler != nil && !handler(atomic.AddInt64(&attempts, 1), rw) {
+			return
+		}
+		// Every event type has a "type" field that matches the header.
+		var header struct {
+			Type string `json:"type"`
+		}
+		if !assert.NoError(t, json.Unmarshal(body, &header)) {
+			return
+		}
+		assert.Equal(t, header.Type, r.Header.Get(codersdk.WebhookEventHeader))
+		var event E
+		if !assert.NoError(t, json.Unmarshal(body, &event)) {
+			return
+		}
+		events <- event
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, events
+}
+
+// AwaitEvent waits for the next event sent to a receiver.
+func AwaitEvent[E any](t testing.TB, events <-chan E) E {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(testutil.WaitShort):
+		t.Fatal("timed out waiting for webhook event")
+		var event E
+		return event
+	}
+}
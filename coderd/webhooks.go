@@ -0,0 +1,344 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhookdelivery"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+// webhookDeliveriesLimit is how many of the most recent delivery attempts are
+// returned for a webhook.
+const webhookDeliveriesLimit = 100
+
+func (api *API) webhooks(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceWebhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	webhooks, err := api.Database.GetWebhooks(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, convertWebhook(webhook))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) postWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceWebhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateWebhookRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Webhook URL must be an absolute http or https URL.",
+			Validations: []codersdk.ValidationError{
+				{Field: "url", Detail: fmt.Sprintf("invalid webhook URL %q", req.URL)},
+			},
+		})
+		return
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !slices.Contains(codersdk.WebhookEventTypes, event) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Unknown webhook event %q.", event),
+				Validations: []codersdk.ValidationError{
+					{Field: "events", Detail: fmt.Sprintf("must be one of %v", codersdk.WebhookEventTypes)},
+				},
+			})
+			return
+		}
+		events = append(events, string(event))
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = cryptorand.HexString(32)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+
+	webhook, err := api.Database.InsertWebhook(ctx, database.InsertWebhookParams{
+		ID:        uuid.New(),
+		Url:       req.URL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := convertWebhook(webhook)
+	// The secret is only ever returned here so it can be stored by the
+	// receiving end.
+	resp.Secret = webhook.Secret
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+func (api *API) deleteWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook, ok := api.webhookParam(rw, r, rbac.ActionDelete)
+	if !ok {
+		return
+	}
+
+	err := api.Database.DeleteWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully deleted webhook!",
+	})
+}
+
+func (api *API) webhookDeliveries(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook, ok := api.webhookParam(rw, r, rbac.ActionRead)
+	if !ok {
+		return
+	}
+
+	deliveries, err := api.Database.GetWebhookDeliveriesByWebhookID(ctx, database.GetWebhookDeliveriesByWebhookIDParams{
+		WebhookID: webhook.ID,
+		Limit:     webhookDeliveriesLimit,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, convertWebhookDelivery(delivery))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// postWebhookRedelivery sends the payload of a previous delivery to the
+// webhook again. It's a single attempt, so the caller sees the outcome in the
+// response instead of waiting for retries.
+func (api *API) postWebhookRedelivery(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook, ok := api.webhookParam(rw, r, rbac.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "delivery"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid delivery ID.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	previous, err := api.Database.GetWebhookDeliveryByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && previous.WebhookID != webhook.ID) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	statusCode, err := api.WebhookEngine.Send(ctx, webhookdelivery.Webhook{
+		URL:    webhook.Url,
+		Secret: webhook.Secret,
+	}, previous.EventType, previous.Payload)
+	params := database.InsertWebhookDeliveryParams{
+		ID:         uuid.New(),
+		WebhookID:  webhook.ID,
+		EventID:    previous.EventID,
+		EventType:  previous.EventType,
+		Payload:    previous.Payload,
+		Attempt:    1,
+		Redelivery: true,
+		StatusCode: int32(statusCode),
+		CreatedAt:  database.Now(),
+	}
+	if err != nil {
+		params.Error = err.Error()
+	}
+	delivery, err := api.Database.InsertWebhookDelivery(ctx, params)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusCreated, convertWebhookDelivery(delivery))
+}
+
+// webhookParam authorizes the action and returns the webhook in the URL. It
+// writes an error response if it returns false.
+func (api *API) webhookParam(rw http.ResponseWriter, r *http.Request, action rbac.Action) (database.Webhook, bool) {
+	ctx := r.Context()
+	if !api.Authorize(r, action, rbac.ResourceWebhook) {
+		httpapi.ResourceNotFound(rw)
+		return database.Webhook{}, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "webhook"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid webhook ID.",
+			Detail:  err.Error(),
+		})
+		return database.Webhook{}, false
+	}
+
+	webhook, err := api.Database.GetWebhookByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return database.Webhook{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return database.Webhook{}, false
+	}
+	return webhook, true
+}
+
+// PublishWebhookEvent queues the event for every deployment-wide webhook
+// that's subscribed to it.
+func (api *API) PublishWebhookEvent(event codersdk.WebhookEvent) {
+	event.ID = uuid.New()
+	event.CreatedAt = database.Now()
+	body, err := json.Marshal(event)
+	if err != nil {
+		api.Logger.Error(context.Background(), "marshal webhook event", slog.Error(err))
+		return
+	}
+
+	api.WebhookEngine.Publish(webhookdelivery.KindDeployment, webhookdelivery.Event{
+		ID:      event.ID,
+		Type:    string(event.Type),
+		Payload: body,
+	}, func(ctx context.Context) ([]uuid.UUID, error) {
+		webhooks, err := api.Database.GetWebhooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uuid.UUID, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, string(event.Type)) {
+				continue
+			}
+			ids = append(ids, webhook.ID)
+		}
+		return ids, nil
+	})
+}
+
+// PublishUserWebhookEvent delivers an event about the user. Users belong to
+// the deployment rather than an organization.
+func (api *API) PublishUserWebhookEvent(eventType codersdk.WebhookEventType, user database.User) {
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:         eventType,
+		ResourceID:   user.ID,
+		ResourceName: user.Username,
+	})
+}
+
+// userStatusWebhookEvent returns the event of a user changing to the status.
+func userStatusWebhookEvent(status database.UserStatus) codersdk.WebhookEventType {
+	if status == database.UserStatusSuspended {
+		return codersdk.WebhookEventUserSuspended
+	}
+	return codersdk.WebhookEventUserActivated
+}
+
+// webhookHandler delivers events to deployment-wide webhooks. Every attempt
+// is recorded in the delivery log of the webhook.
+func (api *API) webhookHandler() webhookdelivery.Handler {
+	return webhookdelivery.Handler{
+		Webhook: func(ctx context.Context, id uuid.UUID) (webhookdelivery.Webhook, error) {
+			webhook, err := api.Database.GetWebhookByID(ctx, id)
+			if err != nil {
+				return webhookdelivery.Webhook{}, err
+			}
+			return webhookdelivery.Webhook{URL: webhook.Url, Secret: webhook.Secret}, nil
+		},
+		Record: func(ctx context.Context, attempt webhookdelivery.Attempt) error {
+			delivery := database.InsertWebhookDeliveryParams{
+				ID:         uuid.New(),
+				WebhookID:  attempt.Item.WebhookID,
+				EventID:    attempt.Item.EventID,
+				EventType:  attempt.Item.EventType,
+				Payload:    attempt.Item.Payload,
+				Attempt:    attempt.Number,
+				StatusCode: int32(attempt.StatusCode),
+				CreatedAt:  database.Now(),
+			}
+			if attempt.Err != nil {
+				delivery.Error = attempt.Err.Error()
+			}
+			_, err := api.Database.InsertWebhookDelivery(ctx, delivery)
+			return err
+		},
+		RetryInterval: api.WebhookRetryInterval,
+	}
+}
+
+func convertWebhook(webhook database.Webhook) codersdk.Webhook {
+	events := make([]codersdk.WebhookEventType, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, codersdk.WebhookEventType(event))
+	}
+	return codersdk.Webhook{
+		ID:        webhook.ID,
+		URL:       webhook.Url,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func convertWebhookDelivery(delivery database.WebhookDelivery) codersdk.WebhookDelivery {
+	return codersdk.WebhookDelivery{
+		ID:         delivery.ID,
+		WebhookID:  delivery.WebhookID,
+		EventID:    delivery.EventID,
+		EventType:  codersdk.WebhookEventType(delivery.EventType),
+		Attempt:    int(delivery.Attempt),
+		Redelivery: delivery.Redelivery,
+		StatusCode: int(delivery.StatusCode),
+		Error:      delivery.Error,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
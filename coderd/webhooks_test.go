@@ -0,0 +1,184 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/webhookdelivery/webhookdeliverytest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Users", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.WebhookEvent](t, "secret", nil)
+		webhook, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "secret", webhook.Secret)
+		require.Empty(t, webhook.Events)
+
+		webhooks, err := client.Webhooks(ctx)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		require.Equal(t, webhook.ID, webhooks[0].ID)
+		require.Empty(t, webhooks[0].Secret)
+
+		_, member := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.WebhookEventUserCreated, event.Type)
+		require.Equal(t, member.ID, event.ResourceID)
+		require.Equal(t, member.Username, event.ResourceName)
+
+		_, err = client.UpdateUserStatus(ctx, member.ID.String(), codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.WebhookEventUserSuspended, event.Type)
+		require.Equal(t, member.ID, event.ResourceID)
+
+		err = client.DeleteUser(ctx, member.ID)
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.WebhookEventUserDeleted, event.Type)
+		require.Equal(t, member.ID, event.ResourceID)
+
+		err = client.DeleteWebhook(ctx, webhook.ID)
+		require.NoError(t, err)
+		webhooks, err = client.Webhooks(ctx)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 0)
+	})
+
+	t.Run("Workspaces", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.WebhookEvent](t, "secret", nil)
+		_, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+			Events: []codersdk.WebhookEventType{
+				codersdk.WebhookEventWorkspaceCreated,
+				codersdk.WebhookEventWorkspaceDeleted,
+			},
+		})
+		require.NoError(t, err)
+
+		// Users being created aren't delivered since the webhook isn't
+		// subscribed to them.
+		_ = coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.WebhookEventWorkspaceCreated, event.Type)
+		require.Equal(t, workspace.ID, event.ResourceID)
+		require.Equal(t, workspace.Name, event.ResourceName)
+		require.Equal(t, user.OrganizationID, event.OrganizationID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionDelete,
+		})
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.WebhookEventWorkspaceDeleted, event.Type)
+		require.Equal(t, workspace.ID, event.ResourceID)
+	})
+
+	t.Run("RetryAndRedeliver", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			WebhookRetryInterval: time.Millisecond,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.WebhookEvent](t, "secret", func(attempt int64, rw http.ResponseWriter) bool {
+			if attempt < 3 {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return false
+			}
+			return true
+		})
+		webhook, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+
+		_ = coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.WebhookEventUserCreated, event.Type)
+
+		var deliveries []codersdk.WebhookDelivery
+		require.Eventually(t, func() bool {
+			deliveries, err = client.WebhookDeliveries(ctx, webhook.ID)
+			return err == nil && len(deliveries) == 3
+		}, testutil.WaitShort, testutil.IntervalFast)
+		// Deliveries are returned newest first.
+		require.Equal(t, 3, deliveries[0].Attempt)
+		require.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		require.Equal(t, 1, deliveries[2].Attempt)
+		require.Equal(t, http.StatusInternalServerError, deliveries[2].StatusCode)
+		require.NotEmpty(t, deliveries[2].Error)
+		require.Equal(t, event.ID, deliveries[2].EventID)
+
+		redelivery, err := client.RedeliverWebhookDelivery(ctx, webhook.ID, deliveries[2].ID)
+		require.NoError(t, err)
+		require.True(t, redelivery.Redelivery)
+		require.Equal(t, http.StatusOK, redelivery.StatusCode)
+		require.Equal(t, event.ID, redelivery.EventID)
+		// The same event is delivered again.
+		require.Equal(t, event.ID, webhookdeliverytest.AwaitEvent(t, events).ID)
+	})
+
+	t.Run("InvalidEvent", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []codersdk.WebhookEventType{"workspace.renamed"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotCreate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			URL: "https://example.com/hook",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
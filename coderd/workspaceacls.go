@@ -0,0 +1,225 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) workspaceACL(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceACL(workspace))
+}
+
+// patchWorkspaceACL shares the workspace with users and groups. Only those
+// who can update the workspace can share it, so being granted a role never
+// allows passing it on.
+func (api *API) patchWorkspaceACL(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceACL
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	validErrs := validateWorkspaceACLPerms(ctx, api.Database, workspace, req.UserPerms, "user_perms", validateWorkspaceACLUser)
+	validErrs = append(validErrs,
+		validateWorkspaceACLPerms(ctx, api.Database, workspace, req.GroupPerms, "group_perms", validateWorkspaceACLGroup)...)
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update workspace ACL!",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	var newWorkspace database.Workspace
+	err := api.Database.InTx(func(tx database.Store) error {
+		// The ACLs are merged with the latest ones, so concurrent updates
+		// don't overwrite each other.
+		locked, err := tx.GetWorkspaceByIDForUpdate(ctx, workspace.ID)
+		if err != nil {
+			return xerrors.Errorf("get workspace: %w", err)
+		}
+		userACL := locked.UserACL()
+		groupACL := locked.GroupACL()
+
+		if len(req.UserPerms) > 0 {
+			updateWorkspaceACL(userACL, req.UserPerms)
+			err := tx.UpdateWorkspaceUserACLByID(ctx, workspace.ID, userACL)
+			if err != nil {
+				return xerrors.Errorf("update workspace user ACL: %w", err)
+			}
+		}
+
+		if len(req.GroupPerms) > 0 {
+			updateWorkspaceACL(groupACL, req.GroupPerms)
+			err := tx.UpdateWorkspaceGroupACLByID(ctx, workspace.ID, groupACL)
+			if err != nil {
+				return xerrors.Errorf("update workspace group ACL: %w", err)
+			}
+		}
+		newWorkspace = locked.SetUserACL(userACL).SetGroupACL(groupACL)
+		return nil
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	aReq.New = newWorkspace
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, newWorkspace)
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceACL(newWorkspace))
+}
+
+func updateWorkspaceACL(acl database.WorkspaceACL, perms map[string]codersdk.WorkspaceRole) {
+	for id, role := range perms {
+		// An empty role revokes access.
+		if role == codersdk.WorkspaceRoleDeleted {
+			delete(acl, id)
+			continue
+		}
+		acl[id] = convertSDKWorkspaceRole(role)
+	}
+}
+
+type workspaceACLValidator func(ctx context.Context, db database.Store, workspace database.Workspace, id uuid.UUID, role codersdk.WorkspaceRole) error
+
+func validateWorkspaceACLPerms(ctx context.Context, db database.Store, workspace database.Workspace, perms map[string]codersdk.WorkspaceRole, field string, validate workspaceACLValidator) []codersdk.ValidationError {
+	var validErrs []codersdk.ValidationError
+	for k, v := range perms {
+		if convertSDKWorkspaceRole(v) == nil && v != codersdk.WorkspaceRoleDeleted {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("role %q is not a valid workspace role", v)})
+			continue
+		}
+
+		id, err := uuid.Parse(k)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "ID " + k + " must be a valid UUID."})
+			continue
+		}
+
+		err = validate(ctx, db, workspace, id, v)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: err.Error()})
+			continue
+		}
+	}
+
+	return validErrs
+}
+
+func validateWorkspaceACLUser(ctx context.Context, db database.Store, workspace database.Workspace, id uuid.UUID, role codersdk.WorkspaceRole) error {
+	if role == codersdk.WorkspaceRoleDeleted {
+		return nil
+	}
+	if id == workspace.OwnerID {
+		return xerrors.New("The owner of a workspace can't be granted a role on it.")
+	}
+	// Only members of the workspace's organization can be granted a role.
+	_, err := db.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: workspace.OrganizationID,
+		UserID:         id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("User %q is not a member of the workspace's organization.", id)
+	}
+	if err != nil {
+		return xerrors.Errorf("Failed to find user with ID %q: %v", id, err.Error())
+	}
+	return nil
+}
+
+func validateWorkspaceACLGroup(ctx context.Context, db database.Store, workspace database.Workspace, id uuid.UUID, role codersdk.WorkspaceRole) error {
+	if role == codersdk.WorkspaceRoleDeleted {
+		return nil
+	}
+	group, err := db.GetGroupByID(ctx, id)
+	// Deleted groups can be removed from the ACL but not granted a role.
+	if err == nil && group.DeletedAt.Valid {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		return xerrors.Errorf("Failed to find group with ID %q: %v", id, err.Error())
+	}
+	if group.OrganizationID.Valid && group.OrganizationID.UUID != workspace.OrganizationID {
+		return xerrors.Errorf("Group %q is not in the workspace's organization.", id)
+	}
+	return nil
+}
+
+func convertWorkspaceACL(workspace database.Workspace) codersdk.WorkspaceACL {
+	convert := func(acl database.WorkspaceACL) map[string]codersdk.WorkspaceRole {
+		perms := make(map[string]codersdk.WorkspaceRole, len(acl))
+		for id, actions := range acl {
+			perms[id] = convertToWorkspaceRole(actions)
+		}
+		return perms
+	}
+	return codersdk.WorkspaceACL{
+		UserPerms:  convert(workspace.UserACL()),
+		GroupPerms: convert(workspace.GroupACL()),
+	}
+}
+
+func convertToWorkspaceRole(actions []rbac.Action) codersdk.WorkspaceRole {
+	role := codersdk.WorkspaceRoleView
+	for _, action := range actions {
+		switch action {
+		case database.WorkspaceACLActionSSH:
+			return codersdk.WorkspaceRoleSSH
+		case database.WorkspaceACLActionApplicationConnect:
+			role = codersdk.WorkspaceRoleApp
+		}
+	}
+	return role
+}
+
+func convertSDKWorkspaceRole(role codersdk.WorkspaceRole) []rbac.Action {
+	switch role {
+	case codersdk.WorkspaceRoleView:
+		return []rbac.Action{rbac.ActionRead}
+	case codersdk.WorkspaceRoleApp:
+		return []rbac.Action{rbac.ActionRead, database.WorkspaceACLActionApplicationConnect}
+	case codersdk.WorkspaceRoleSSH:
+		return []rbac.Action{rbac.ActionRead, database.WorkspaceACLActionApplicationConnect, database.WorkspaceACLActionSSH}
+	}
+
+	return nil
+}
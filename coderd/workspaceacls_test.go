@@ -0,0 +1,151 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceACL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Share", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		owner := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		other, otherUser := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, owner, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := other.Workspace(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		acl, err := owner.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				otherUser.ID.String(): codersdk.WorkspaceRoleApp,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceRoleApp, acl.UserPerms[otherUser.ID.String()])
+
+		shared, err := other.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, acl, shared.ACL)
+
+		workspaces, err := other.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+
+		// Apps are granted by the role, but the terminal isn't.
+		checks, err := other.CheckAuthorization(ctx, codersdk.AuthorizationRequest{
+			Checks: map[string]codersdk.AuthorizationCheck{
+				"ssh": {
+					Object: codersdk.AuthorizationObject{
+						ResourceType: rbac.ResourceWorkspaceExecution.Type,
+						ResourceID:   workspace.ID.String(),
+					},
+					Action: "create",
+				},
+				"update": {
+					Object: codersdk.AuthorizationObject{
+						ResourceType: rbac.ResourceWorkspace.Type,
+						ResourceID:   workspace.ID.String(),
+					},
+					Action: "update",
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.False(t, checks["ssh"])
+		require.False(t, checks["update"])
+
+		// Shared users can't pass the workspace on.
+		_, err = other.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				otherUser.ID.String(): codersdk.WorkspaceRoleSSH,
+			},
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		_, err = owner.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				otherUser.ID.String(): codersdk.WorkspaceRoleDeleted,
+			},
+		})
+		require.NoError(t, err)
+		_, err = other.Workspace(ctx, workspace.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		// Updates that share the workspace with different users at the same
+		// time must all be kept.
+		ctx, _ := testutil.Context(t)
+		var eg errgroup.Group
+		for i := 0; i < 5; i++ {
+			_, other := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+			eg.Go(func() error {
+				_, err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+					UserPerms: map[string]codersdk.WorkspaceRole{
+						other.ID.String(): codersdk.WorkspaceRoleView,
+					},
+				})
+				return err
+			})
+		}
+		require.NoError(t, eg.Wait())
+
+		workspace, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Len(t, workspace.ACL.UserPerms, 5)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				user.UserID.String(): codersdk.WorkspaceRoleView,
+				"not-a-uuid":         codersdk.WorkspaceRoleView,
+			},
+			GroupPerms: map[string]codersdk.WorkspaceRole{
+				user.OrganizationID.String(): "admin",
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 3)
+	})
+}
@@ -440,6 +440,11 @@ func (api *API) workspaceAgentCoordinate(rw http.ResponseWriter, r *http.Request
 			Valid: true,
 		}
 		_ = updateConnectionTimes()
+		api.recordWorkspaceTimelineEvent(ctx, database.InsertWorkspaceTimelineEventParams{
+			WorkspaceID: build.WorkspaceID,
+			Type:        database.WorkspaceTimelineEventTypeAgentDisconnected,
+			AgentID:     workspaceAgent.ID,
+		})
 	}()
 
 	err = updateConnectionTimes()
@@ -447,6 +452,11 @@ func (api *API) workspaceAgentCoordinate(rw http.ResponseWriter, r *http.Request
 		_ = conn.Close(websocket.StatusGoingAway, err.Error())
 		return
 	}
+	api.recordWorkspaceTimelineEvent(ctx, database.InsertWorkspaceTimelineEventParams{
+		WorkspaceID: build.WorkspaceID,
+		Type:        database.WorkspaceTimelineEventTypeAgentConnected,
+		AgentID:     workspaceAgent.ID,
+	})
 
 	// End span so we don't get long lived trace data.
 	tracing.EndHTTPSpan(r, http.StatusOK, trace.SpanFromContext(ctx))
@@ -716,6 +726,13 @@ func (api *API) workspaceAgentReportStats(rw http.ResponseWriter, r *http.Reques
 			return
 		}
 
+		// Usage changes with every report, so it's stored separately and
+		// doesn't count as activity.
+		if rep.Usage != nil {
+			api.insertWorkspaceAgentUsageSample(ctx, workspaceAgent.ID, *rep.Usage)
+			rep.Usage = nil
+		}
+
 		repJSON, err := json.Marshal(rep)
 		if err != nil {
 			api.Logger.Debug(ctx, "marshal stat json", slog.Error(err))
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
 	jose "gopkg.in/square/go-jose.v2"
@@ -527,6 +528,24 @@ func (api *API) proxyWorkspaceApplication(proxyApp proxyApplication, rw http.Res
 		return
 	}
 
+	// Loading the root of an app is opening it, while other paths are its
+	// assets and API calls.
+	if proxyApp.Path == "/" && r.Method == http.MethodGet {
+		event := database.InsertWorkspaceTimelineEventParams{
+			WorkspaceID: proxyApp.Workspace.ID,
+			Type:        database.WorkspaceTimelineEventTypeAppOpened,
+			AgentID:     proxyApp.Agent.ID,
+			AppName:     proxyApp.AppName,
+		}
+		if event.AppName == "" {
+			event.AppName = strconv.Itoa(int(proxyApp.Port))
+		}
+		if apiKey, ok := httpmw.APIKeyOptional(r); ok {
+			event.UserID = uuid.NullUUID{UUID: apiKey.UserID, Valid: true}
+		}
+		api.recordWorkspaceTimelineEvent(ctx, event)
+	}
+
 	r.URL.Path = proxyApp.Path
 	appURL.RawQuery = ""
 
@@ -0,0 +1,778 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	// archiveKeyParameterName is the parameter templates declare to export
+	// the data of workspaces when they're archived, and to import it when
+	// they're restored. Its value is the prefix of the archive's objects.
+	archiveKeyParameterName = "coder_workspace_archive_key"
+	// workspaceArchiveManifestObject and workspaceArchiveStateObject are
+	// the objects Coder exports to every archive, next to the template's.
+	workspaceArchiveManifestObject = "manifest.json"
+	workspaceArchiveStateObject    = "terraform.tfstate"
+)
+
+// workspaceArchiveManifest describes an archived workspace, so archives can
+// be understood without the deployment's database.
+type workspaceArchiveManifest struct {
+	WorkspaceID       uuid.UUID                   `json:"workspace_id"`
+	WorkspaceName     string                      `json:"workspace_name"`
+	OwnerID           uuid.UUID                   `json:"owner_id"`
+	OrganizationID    uuid.UUID                   `json:"organization_id"`
+	TemplateID        uuid.UUID                   `json:"template_id"`
+	TemplateVersionID uuid.UUID                   `json:"template_version_id"`
+	BuildNumber       int32                       `json:"build_number"`
+	ParameterValues   []workspaceArchiveParameter `json:"parameter_values"`
+	CreatedAt         time.Time                   `json:"created_at"`
+}
+
+type workspaceArchiveParameter struct {
+	Name              string `json:"name"`
+	SourceScheme      string `json:"source_scheme"`
+	SourceValue       string `json:"source_value"`
+	DestinationScheme string `json:"destination_scheme"`
+}
+
+func (api *API) workspaceArchive(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	archive, err := api.Database.GetWorkspaceArchiveByWorkspaceID(ctx, workspace.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "The workspace isn't archived.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace archive.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceArchive(archive))
+}
+
+// postWorkspaceArchive exports the state of a workspace and starts a build
+// that destroys its resources, but keeps the workspace.
+func (api *API) postWorkspaceArchive(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	apiKey := httpmw.APIKey(r)
+
+	if !api.Authorize(r, rbac.ActionDelete, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	archive, code, resp, err := api.archiveWorkspace(ctx, apiKey.UserID, workspace)
+	if err != nil {
+		api.Logger.Info(ctx, "archiving workspace", slog.Error(err))
+		httpapi.Write(ctx, rw, code, resp)
+		return
+	}
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	httpapi.Write(ctx, rw, http.StatusAccepted, convertWorkspaceArchive(archive))
+}
+
+// postWorkspaceArchiveRestore starts an archived workspace, which imports
+// its data from the archive.
+func (api *API) postWorkspaceArchiveRestore(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	apiKey := httpmw.APIKey(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if api.WorkspaceArchiveStore == nil {
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "Workspace archival isn't configured.",
+		})
+		return
+	}
+
+	archive, err := api.Database.GetWorkspaceArchiveByWorkspaceID(ctx, workspace.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && archive.Status != database.WorkspaceArchiveStatusArchived) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The workspace isn't archived.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace archive.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	_, err = api.WorkspaceArchiveStore.Get(ctx, archive.ObjectKey+"/"+workspaceArchiveManifestObject)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error reading workspace archive.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	priorBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	priorJob, err := api.Database.GetProvisionerJobByID(ctx, priorBuild.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if convertProvisionerJob(priorJob).Status.Active() {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "A workspace build is already active.",
+		})
+		return
+	}
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.checkOrganizationQuota(rw, r, template, workspace.ID) {
+		return
+	}
+	schema, err := archiveKeyParameterSchema(ctx, api.Database, priorBuild.TemplateVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching parameter schemas.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if schema == nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template doesn't support restoring archived workspaces.",
+			Detail:  fmt.Sprintf("The template version doesn't declare the %q parameter.", archiveKeyParameterName),
+		})
+		return
+	}
+
+	err = api.Database.InTx(func(db database.Store) error {
+		err := setWorkspaceParameterValue(ctx, db, workspace.ID, archiveKeyParameterName, archive.ObjectKey, schema.DefaultDestinationScheme)
+		if err != nil {
+			return err
+		}
+		build, err := insertWorkspaceBuild(ctx, db, apiKey.UserID, workspace, priorBuild, priorBuild.TemplateVersionID, database.WorkspaceTransitionStart, database.BuildReasonInitiator)
+		if err != nil {
+			return err
+		}
+		archive, err = db.UpdateWorkspaceArchiveByWorkspaceID(ctx, database.UpdateWorkspaceArchiveByWorkspaceIDParams{
+			WorkspaceID: workspace.ID,
+			Status:      database.WorkspaceArchiveStatusRestoring,
+			BuildID:     build.ID,
+			UpdatedAt:   database.Now(),
+			ArchivedAt:  archive.ArchivedAt,
+			ExpiresAt:   archive.ExpiresAt,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace archive: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error restoring workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	httpapi.Write(ctx, rw, http.StatusAccepted, convertWorkspaceArchive(archive))
+}
+
+func (api *API) templateArchivePolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	policy, err := api.Database.GetTemplateArchivePolicyByTemplateID(ctx, template.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template archive policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateArchivePolicy(policy))
+}
+
+func (api *API) putTemplateArchivePolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.TemplateArchivePolicy
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.DormancyThresholdMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "dormancy_threshold_ms",
+			Detail: "Must not be negative.",
+		})
+	}
+	if req.RetentionMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "retention_ms",
+			Detail: "Must not be negative.",
+		})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid archive policy.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	policy, err := api.Database.UpsertTemplateArchivePolicy(ctx, database.UpsertTemplateArchivePolicyParams{
+		TemplateID:        template.ID,
+		DormancyThreshold: int64(time.Duration(req.DormancyThresholdMillis) * time.Millisecond),
+		Retention:         int64(time.Duration(req.RetentionMillis) * time.Millisecond),
+		UpdatedAt:         database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template archive policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateArchivePolicy(policy))
+}
+
+// archiveWorkspace exports the manifest and state of a workspace to the
+// archive store, and starts a build that destroys its resources, during
+// which the template exports the workspace's data. The status code and
+// response describe the error, if any.
+func (api *API) archiveWorkspace(ctx context.Context, initiatorID uuid.UUID, workspace database.Workspace) (database.WorkspaceArchive, int, codersdk.Response, error) {
+	if api.WorkspaceArchiveStore == nil {
+		return database.WorkspaceArchive{}, http.StatusPreconditionFailed, codersdk.Response{
+			Message: "Workspace archival isn't configured.",
+		}, xerrors.New("workspace archival isn't configured")
+	}
+
+	existing, err := api.Database.GetWorkspaceArchiveByWorkspaceID(ctx, workspace.ID)
+	if err == nil && existing.Status != database.WorkspaceArchiveStatusFailed {
+		return database.WorkspaceArchive{}, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("The workspace is %s already.", existing.Status),
+		}, xerrors.Errorf("workspace is %s", existing.Status)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace archive.",
+			Detail:  err.Error(),
+		}, err
+	}
+
+	priorBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		}, err
+	}
+	priorJob, err := api.Database.GetProvisionerJobByID(ctx, priorBuild.JobID)
+	if err != nil {
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		}, err
+	}
+	if convertProvisionerJob(priorJob).Status.Active() {
+		return database.WorkspaceArchive{}, http.StatusConflict, codersdk.Response{
+			Message: "A workspace build is already active.",
+		}, xerrors.New("a workspace build is already active")
+	}
+	schema, err := archiveKeyParameterSchema(ctx, api.Database, priorBuild.TemplateVersionID)
+	if err != nil {
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching parameter schemas.",
+			Detail:  err.Error(),
+		}, err
+	}
+	// Templates that can't export the data of workspaces would lose it.
+	if schema == nil {
+		return database.WorkspaceArchive{}, http.StatusBadRequest, codersdk.Response{
+			Message: "The template doesn't support archiving workspaces.",
+			Detail:  fmt.Sprintf("The template version doesn't declare the %q parameter.", archiveKeyParameterName),
+		}, xerrors.Errorf("template version doesn't declare %q", archiveKeyParameterName)
+	}
+
+	parameterValues, err := api.Database.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{workspace.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace parameters.",
+			Detail:  err.Error(),
+		}, err
+	}
+	manifest := workspaceArchiveManifest{
+		WorkspaceID:       workspace.ID,
+		WorkspaceName:     workspace.Name,
+		OwnerID:           workspace.OwnerID,
+		OrganizationID:    workspace.OrganizationID,
+		TemplateID:        workspace.TemplateID,
+		TemplateVersionID: priorBuild.TemplateVersionID,
+		BuildNumber:       priorBuild.BuildNumber,
+		ParameterValues:   make([]workspaceArchiveParameter, 0, len(parameterValues)),
+		CreatedAt:         database.Now(),
+	}
+	for _, parameterValue := range parameterValues {
+		if parameterValue.Name == archiveKeyParameterName {
+			continue
+		}
+		manifest.ParameterValues = append(manifest.ParameterValues, workspaceArchiveParameter{
+			Name:              parameterValue.Name,
+			SourceScheme:      string(parameterValue.SourceScheme),
+			SourceValue:       parameterValue.SourceValue,
+			DestinationScheme: string(parameterValue.DestinationScheme),
+		})
+	}
+
+	// Every archive has its own key, so retrying a failed archive doesn't
+	// mix its objects with the previous attempt's.
+	objectKey := fmt.Sprintf("workspaces/%s/%s", workspace.ID, uuid.New())
+	err = api.exportWorkspaceArchive(ctx, objectKey, manifest, priorBuild.ProvisionerState)
+	if err != nil {
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error exporting workspace archive.",
+			Detail:  err.Error(),
+		}, err
+	}
+
+	var archive database.WorkspaceArchive
+	err = api.Database.InTx(func(db database.Store) error {
+		err := setWorkspaceParameterValue(ctx, db, workspace.ID, archiveKeyParameterName, objectKey, schema.DefaultDestinationScheme)
+		if err != nil {
+			return err
+		}
+		build, err := insertWorkspaceBuild(ctx, db, initiatorID, workspace, priorBuild, priorBuild.TemplateVersionID, database.WorkspaceTransitionDelete, database.BuildReasonInitiator)
+		if err != nil {
+			return err
+		}
+		archive, err = db.UpsertWorkspaceArchive(ctx, database.UpsertWorkspaceArchiveParams{
+			WorkspaceID: workspace.ID,
+			Status:      database.WorkspaceArchiveStatusArchiving,
+			ObjectKey:   objectKey,
+			BuildID:     build.ID,
+			CreatedAt:   database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("upsert workspace archive: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		purgeErr := api.WorkspaceArchiveStore.DeletePrefix(ctx, objectKey)
+		if purgeErr != nil {
+			api.Logger.Warn(ctx, "purge workspace archive", slog.F("object_key", objectKey), slog.Error(purgeErr))
+		}
+		return database.WorkspaceArchive{}, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error archiving workspace.",
+			Detail:  err.Error(),
+		}, err
+	}
+	return archive, 0, codersdk.Response{}, nil
+}
+
+// exportWorkspaceArchive stores the manifest and the Terraform state of a
+// workspace under the key.
+func (api *API) exportWorkspaceArchive(ctx context.Context, objectKey string, manifest workspaceArchiveManifest, state []byte) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return xerrors.Errorf("marshal manifest: %w", err)
+	}
+	err = api.WorkspaceArchiveStore.Put(ctx, objectKey+"/"+workspaceArchiveManifestObject, data)
+	if err != nil {
+		return xerrors.Errorf("put manifest: %w", err)
+	}
+	if len(state) == 0 {
+		return nil
+	}
+	err = api.WorkspaceArchiveStore.Put(ctx, objectKey+"/"+workspaceArchiveStateObject, state)
+	if err != nil {
+		return xerrors.Errorf("put state: %w", err)
+	}
+	return nil
+}
+
+// workspaceArchived returns whether the resources of a workspace are
+// destroyed, or being destroyed or restored, by its archive.
+func workspaceArchived(ctx context.Context, db database.Store, workspaceID uuid.UUID) (bool, error) {
+	archive, err := db.GetWorkspaceArchiveByWorkspaceID(ctx, workspaceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, xerrors.Errorf("get workspace archive: %w", err)
+	}
+	return archive.Status != database.WorkspaceArchiveStatusFailed, nil
+}
+
+// archiveKeyParameterSchema returns the archive key parameter of the
+// template version, or nil if it doesn't declare it.
+func archiveKeyParameterSchema(ctx context.Context, db database.Store, templateVersionID uuid.UUID) (*database.ParameterSchema, error) {
+	version, err := db.GetTemplateVersionByID(ctx, templateVersionID)
+	if err != nil {
+		return nil, xerrors.Errorf("get template version: %w", err)
+	}
+	schemas, err := db.GetParameterSchemasByJobID(ctx, version.JobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get parameter schemas: %w", err)
+	}
+	for i := range schemas {
+		if schemas[i].Name == archiveKeyParameterName {
+			return &schemas[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// setWorkspaceParameterValue replaces the value of a workspace parameter.
+func setWorkspaceParameterValue(ctx context.Context, db database.Store, workspaceID uuid.UUID, name, value string, destinationScheme database.ParameterDestinationScheme) error {
+	err := deleteWorkspaceParameterValue(ctx, db, workspaceID, name)
+	if err != nil {
+		return err
+	}
+	now := database.Now()
+	_, err = db.InsertParameterValue(ctx, database.InsertParameterValueParams{
+		ID:                uuid.New(),
+		Name:              name,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Scope:             database.ParameterScopeWorkspace,
+		ScopeID:           workspaceID,
+		SourceScheme:      database.ParameterSourceSchemeData,
+		SourceValue:       value,
+		DestinationScheme: destinationScheme,
+	})
+	if err != nil {
+		return xerrors.Errorf("insert parameter value %q: %w", name, err)
+	}
+	return nil
+}
+
+func deleteWorkspaceParameterValue(ctx context.Context, db database.Store, workspaceID uuid.UUID, name string) error {
+	existing, err := db.GetParameterValueByScopeAndName(ctx, database.GetParameterValueByScopeAndNameParams{
+		Scope:   database.ParameterScopeWorkspace,
+		ScopeID: workspaceID,
+		Name:    name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get parameter value %q: %w", name, err)
+	}
+	err = db.DeleteParameterValueByID(ctx, existing.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("delete parameter value %q: %w", name, err)
+	}
+	return nil
+}
+
+// startWorkspaceArchiver completes archives whose builds completed, purges
+// expired archives, and archives dormant workspaces in the background until
+// the API is closed.
+func (api *API) startWorkspaceArchiver() {
+	if api.WorkspaceArchiveStore == nil {
+		return
+	}
+	api.workspaceArchivesWG.Add(1)
+	go func() {
+		defer api.workspaceArchivesWG.Done()
+		ctx := api.workspaceArchivesCtx
+		ticker := time.NewTicker(api.WorkspaceArchivePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := api.updateWorkspaceArchives(ctx, database.Now())
+			if err != nil && ctx.Err() == nil {
+				api.Logger.Warn(ctx, "update workspace archives", slog.Error(err))
+			}
+		}
+	}()
+}
+
+func (api *API) updateWorkspaceArchives(ctx context.Context, now time.Time) error {
+	for _, status := range []database.WorkspaceArchiveStatus{
+		database.WorkspaceArchiveStatusArchiving,
+		database.WorkspaceArchiveStatusRestoring,
+	} {
+		archives, err := api.Database.GetWorkspaceArchivesByStatus(ctx, status)
+		if err != nil {
+			return xerrors.Errorf("get %s workspace archives: %w", status, err)
+		}
+		for _, archive := range archives {
+			err = api.completeWorkspaceArchive(ctx, archive, now)
+			if err != nil {
+				api.Logger.Warn(ctx, "complete workspace archive", slog.F("workspace_id", archive.WorkspaceID), slog.Error(err))
+			}
+		}
+	}
+
+	purgeable, err := api.Database.GetPurgeableWorkspaceArchives(ctx, now)
+	if err != nil {
+		return xerrors.Errorf("get purgeable workspace archives: %w", err)
+	}
+	for _, archive := range purgeable {
+		err = api.purgeWorkspaceArchive(ctx, archive)
+		if err != nil {
+			api.Logger.Warn(ctx, "purge workspace archive", slog.F("workspace_id", archive.WorkspaceID), slog.Error(err))
+		}
+	}
+
+	return api.archiveDormantWorkspaces(ctx, now)
+}
+
+// completeWorkspaceArchive updates an archive whose build completed. The
+// archive key is removed from the workspace's parameters either way, so
+// later builds don't export or import data.
+func (api *API) completeWorkspaceArchive(ctx context.Context, archive database.WorkspaceArchive, now time.Time) error {
+	build, err := api.Database.GetWorkspaceBuildByID(ctx, archive.BuildID)
+	if err != nil {
+		return xerrors.Errorf("get workspace build: %w", err)
+	}
+	job, err := api.Database.GetProvisionerJobByID(ctx, build.JobID)
+	if err != nil {
+		return xerrors.Errorf("get provisioner job: %w", err)
+	}
+	status := convertProvisionerJob(job).Status
+	if status.Active() {
+		return nil
+	}
+	succeeded := status == codersdk.ProvisionerJobSucceeded
+
+	params := database.UpdateWorkspaceArchiveByWorkspaceIDParams{
+		WorkspaceID: archive.WorkspaceID,
+		Status:      database.WorkspaceArchiveStatusArchived,
+		BuildID:     archive.BuildID,
+		UpdatedAt:   now,
+		ArchivedAt:  archive.ArchivedAt,
+		ExpiresAt:   archive.ExpiresAt,
+	}
+	switch {
+	case archive.Status == database.WorkspaceArchiveStatusArchiving && succeeded:
+		workspace, err := api.Database.GetWorkspaceByID(ctx, archive.WorkspaceID)
+		if err != nil {
+			return xerrors.Errorf("get workspace: %w", err)
+		}
+		policy, err := api.Database.GetTemplateArchivePolicyByTemplateID(ctx, workspace.TemplateID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return xerrors.Errorf("get template archive policy: %w", err)
+		}
+		params.ArchivedAt = sql.NullTime{Time: now, Valid: true}
+		if policy.Retention > 0 {
+			params.ExpiresAt = sql.NullTime{Time: now.Add(time.Duration(policy.Retention)), Valid: true}
+		}
+	case archive.Status == database.WorkspaceArchiveStatusArchiving:
+		// Whatever the template exported is incomplete.
+		err = api.WorkspaceArchiveStore.DeletePrefix(ctx, archive.ObjectKey)
+		if err != nil {
+			return xerrors.Errorf("purge archive: %w", err)
+		}
+		params.Status = database.WorkspaceArchiveStatusFailed
+		params.Error = "The build that archives the workspace failed."
+	case succeeded:
+		err = api.WorkspaceArchiveStore.DeletePrefix(ctx, archive.ObjectKey)
+		if err != nil {
+			return xerrors.Errorf("purge archive: %w", err)
+		}
+	default:
+		// The workspace stays archived, so restoring it can be retried.
+		params.Error = "The build that restores the workspace failed."
+	}
+
+	return api.Database.InTx(func(db database.Store) error {
+		err := deleteWorkspaceParameterValue(ctx, db, archive.WorkspaceID, archiveKeyParameterName)
+		if err != nil {
+			return err
+		}
+		if archive.Status == database.WorkspaceArchiveStatusRestoring && succeeded {
+			err = db.DeleteWorkspaceArchiveByWorkspaceID(ctx, archive.WorkspaceID)
+			if err != nil {
+				return xerrors.Errorf("delete workspace archive: %w", err)
+			}
+			return nil
+		}
+		_, err = db.UpdateWorkspaceArchiveByWorkspaceID(ctx, params)
+		if err != nil {
+			return xerrors.Errorf("update workspace archive: %w", err)
+		}
+		return nil
+	})
+}
+
+// purgeWorkspaceArchive deletes the objects of an archive. Workspaces whose
+// archive expired are deleted, since their resources are destroyed already.
+func (api *API) purgeWorkspaceArchive(ctx context.Context, archive database.WorkspaceArchive) error {
+	err := api.WorkspaceArchiveStore.DeletePrefix(ctx, archive.ObjectKey)
+	if err != nil {
+		return xerrors.Errorf("purge archive: %w", err)
+	}
+	workspace, err := api.Database.GetWorkspaceByID(ctx, archive.WorkspaceID)
+	if err != nil {
+		return xerrors.Errorf("get workspace: %w", err)
+	}
+	err = api.Database.InTx(func(db database.Store) error {
+		if !workspace.Deleted {
+			err := db.UpdateWorkspaceDeletedByID(ctx, database.UpdateWorkspaceDeletedByIDParams{
+				ID:      workspace.ID,
+				Deleted: true,
+			})
+			if err != nil {
+				return xerrors.Errorf("update workspace deleted: %w", err)
+			}
+		}
+		err := db.DeleteWorkspaceArchiveByWorkspaceID(ctx, workspace.ID)
+		if err != nil {
+			return xerrors.Errorf("delete workspace archive: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !workspace.Deleted {
+		api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionDeleted, workspace)
+	}
+	return nil
+}
+
+// archiveDormantWorkspaces archives the workspaces of templates with a
+// dormancy threshold that weren't used or built for that long, on behalf of
+// their owners.
+func (api *API) archiveDormantWorkspaces(ctx context.Context, now time.Time) error {
+	policies, err := api.Database.GetTemplateArchivePolicies(ctx)
+	if err != nil {
+		return xerrors.Errorf("get template archive policies: %w", err)
+	}
+	for _, policy := range policies {
+		if policy.DormancyThreshold <= 0 {
+			continue
+		}
+		dormantSince := now.Add(-time.Duration(policy.DormancyThreshold))
+		workspaces, err := api.Database.GetWorkspaces(ctx, database.GetWorkspacesParams{
+			TemplateIds: []uuid.UUID{policy.TemplateID},
+		})
+		if err != nil {
+			return xerrors.Errorf("get workspaces: %w", err)
+		}
+		for _, workspace := range workspaces {
+			if workspace.LastUsedAt.After(dormantSince) {
+				continue
+			}
+			// Failed archives aren't retried automatically.
+			_, err = api.Database.GetWorkspaceArchiveByWorkspaceID(ctx, workspace.ID)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get workspace archive: %w", err)
+			}
+			build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("get latest workspace build: %w", err)
+			}
+			if build.CreatedAt.After(dormantSince) {
+				continue
+			}
+			_, _, resp, err := api.archiveWorkspace(ctx, workspace.OwnerID, workspace)
+			if err != nil {
+				api.Logger.Warn(ctx, "archive dormant workspace",
+					slog.F("workspace_id", workspace.ID),
+					slog.F("reason", resp.Message),
+					slog.Error(err),
+				)
+				continue
+			}
+			api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+		}
+	}
+	return nil
+}
+
+func convertWorkspaceArchive(archive database.WorkspaceArchive) codersdk.WorkspaceArchive {
+	converted := codersdk.WorkspaceArchive{
+		WorkspaceID: archive.WorkspaceID,
+		Status:      codersdk.WorkspaceArchiveStatus(archive.Status),
+		ObjectKey:   archive.ObjectKey,
+		BuildID:     archive.BuildID,
+		Error:       archive.Error,
+		CreatedAt:   archive.CreatedAt,
+	}
+	if archive.ArchivedAt.Valid {
+		converted.ArchivedAt = &archive.ArchivedAt.Time
+	}
+	if archive.ExpiresAt.Valid {
+		converted.ExpiresAt = &archive.ExpiresAt.Time
+	}
+	return converted
+}
+
+func convertTemplateArchivePolicy(policy database.TemplateArchivePolicy) codersdk.TemplateArchivePolicy {
+	return codersdk.TemplateArchivePolicy{
+		DormancyThresholdMillis: time.Duration(policy.DormancyThreshold).Milliseconds(),
+		RetentionMillis:         time.Duration(policy.Retention).Milliseconds(),
+	}
+}
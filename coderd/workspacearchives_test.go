@@ -0,0 +1,247 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/objectstore"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateArchivePolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		policy, err := client.TemplateArchivePolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.Zero(t, policy)
+
+		policy, err = client.UpdateTemplateArchivePolicy(ctx, template.ID, codersdk.TemplateArchivePolicy{
+			DormancyThresholdMillis: 1000,
+			RetentionMillis:         2000,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateArchivePolicy{
+			DormancyThresholdMillis: 1000,
+			RetentionMillis:         2000,
+		}, policy)
+
+		policy, err = client.TemplateArchivePolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 1000, policy.DormancyThresholdMillis)
+		require.EqualValues(t, 2000, policy.RetentionMillis)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateArchivePolicy(ctx, template.ID, codersdk.TemplateArchivePolicy{
+			DormancyThresholdMillis: -1,
+			RetentionMillis:         -1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.TemplateArchivePolicy(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateArchivePolicy(ctx, template.ID, codersdk.TemplateArchivePolicy{
+			RetentionMillis: 1000,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceArchive(t *testing.T) {
+	t.Parallel()
+
+	// archivableVersion declares the archive key, so workspaces of the
+	// template can be archived.
+	archivableVersion := &echo.Responses{
+		Parse: []*proto.Parse_Response{{
+			Type: &proto.Parse_Response_Complete{
+				Complete: &proto.Parse_Complete{
+					ParameterSchemas: []*proto.ParameterSchema{{
+						Name:                "coder_workspace_archive_key",
+						AllowOverrideSource: true,
+						DefaultSource: &proto.ParameterSource{
+							Scheme: proto.ParameterSource_DATA,
+						},
+						DefaultDestination: &proto.ParameterDestination{
+							Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+						},
+					}},
+				},
+			},
+		}},
+		Provision: echo.ProvisionComplete,
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		store := objectstore.NewMemory()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:     true,
+			WorkspaceArchiveStore:        store,
+			WorkspaceArchivePollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, archivableVersion)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.WorkspaceArchive(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		archive, err := client.ArchiveWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceArchiveStatusArchiving, archive.Status)
+		require.Contains(t, store.Keys(), archive.ObjectKey+"/manifest.json")
+		coderdtest.AwaitWorkspaceBuildJob(t, client, archive.BuildID)
+		build, err := client.WorkspaceBuild(ctx, archive.BuildID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceTransitionDelete, build.Transition)
+
+		require.Eventually(t, func() bool {
+			archive, err = client.WorkspaceArchive(ctx, workspace.ID)
+			return err == nil && archive.Status == codersdk.WorkspaceArchiveStatusArchived
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.NotNil(t, archive.ArchivedAt)
+		require.Nil(t, archive.ExpiresAt)
+
+		// The workspace is kept, but can't be started until it's restored.
+		_, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		archive, err = client.RestoreWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceArchiveStatusRestoring, archive.Status)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, archive.BuildID)
+		build, err = client.WorkspaceBuild(ctx, archive.BuildID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, build.Transition)
+
+		// The archive is purged once the workspace started.
+		require.Eventually(t, func() bool {
+			_, err = client.WorkspaceArchive(ctx, workspace.ID)
+			return err != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Empty(t, store.Keys())
+	})
+
+	t.Run("Dormant", func(t *testing.T) {
+		t.Parallel()
+		store := objectstore.NewMemory()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:     true,
+			WorkspaceArchiveStore:        store,
+			WorkspaceArchivePollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, archivableVersion)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateArchivePolicy(ctx, template.ID, codersdk.TemplateArchivePolicy{
+			DormancyThresholdMillis: 1,
+			RetentionMillis:         1,
+		})
+		require.NoError(t, err)
+
+		// The workspace is archived, and then deleted once the archive
+		// expired.
+		require.Eventually(t, func() bool {
+			_, err = client.Workspace(ctx, workspace.ID)
+			return err != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusGone, apiErr.StatusCode())
+		require.Eventually(t, func() bool {
+			return len(store.Keys()) == 0
+		}, testutil.WaitLong, testutil.IntervalFast)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, archivableVersion)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.ArchiveWorkspace(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusPreconditionFailed, apiErr.StatusCode())
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			WorkspaceArchiveStore:    objectstore.NewMemory(),
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.ArchiveWorkspace(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,355 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) templateAutoRebuildPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	policy, err := api.Database.GetTemplateAutoRebuildPolicyByTemplateID(ctx, template.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		policy.Action = database.AutoRebuildActionRestart
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template auto-rebuild policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateAutoRebuildPolicy(policy))
+}
+
+func (api *API) putTemplateAutoRebuildPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.TemplateAutoRebuildPolicy
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.Action == "" {
+		req.Action = codersdk.AutoRebuildActionRestart
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.FailureThreshold < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "failure_threshold",
+			Detail: "Must not be negative.",
+		})
+	}
+	if req.AgentTimeoutMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "agent_timeout_ms",
+			Detail: "Must not be negative.",
+		})
+	}
+	switch req.Action {
+	case codersdk.AutoRebuildActionRestart, codersdk.AutoRebuildActionRebuild:
+	default:
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "action",
+			Detail: `Must be "restart" or "rebuild".`,
+		})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid auto-rebuild policy.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	policy, err := api.Database.UpsertTemplateAutoRebuildPolicy(ctx, database.UpsertTemplateAutoRebuildPolicyParams{
+		TemplateID:       template.ID,
+		FailureThreshold: req.FailureThreshold,
+		AgentTimeout:     int64(time.Duration(req.AgentTimeoutMillis) * time.Millisecond),
+		Action:           database.AutoRebuildAction(req.Action),
+		UpdatedAt:        database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template auto-rebuild policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateAutoRebuildPolicy(policy))
+}
+
+func (api *API) postWorkspaceAgentStartupScript(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+
+	var req codersdk.PostWorkspaceAgentStartupScriptRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	_, err := api.Database.UpsertWorkspaceAgentStartupScriptResult(ctx, database.UpsertWorkspaceAgentStartupScriptResultParams{
+		AgentID:     workspaceAgent.ID,
+		ExitCode:    req.ExitCode,
+		CompletedAt: database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error saving startup script result.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, nil)
+}
+
+// startWorkspaceAutoRebuilder restarts or rebuilds workspaces whose agents
+// fail repeatedly in the background until the API is closed.
+func (api *API) startWorkspaceAutoRebuilder() {
+	api.workspaceAutoRebuildsWG.Add(1)
+	go func() {
+		defer api.workspaceAutoRebuildsWG.Done()
+		ctx := api.workspaceAutoRebuildsCtx
+		ticker := time.NewTicker(api.WorkspaceAutoRebuildPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := api.autoRebuildWorkspaces(ctx, database.Now())
+			if err != nil && ctx.Err() == nil {
+				api.Logger.Warn(ctx, "auto-rebuild workspaces", slog.Error(err))
+			}
+		}
+	}()
+}
+
+// autoRebuildWorkspaces checks the workspaces of templates with an
+// auto-rebuild policy, and restarts or rebuilds those whose agents failed
+// the threshold of consecutive starts.
+func (api *API) autoRebuildWorkspaces(ctx context.Context, now time.Time) error {
+	policies, err := api.Database.GetTemplateAutoRebuildPolicies(ctx)
+	if err != nil {
+		return xerrors.Errorf("get template auto-rebuild policies: %w", err)
+	}
+	for _, policy := range policies {
+		if policy.FailureThreshold <= 0 {
+			continue
+		}
+		workspaces, err := api.Database.GetWorkspaces(ctx, database.GetWorkspacesParams{
+			TemplateIds: []uuid.UUID{policy.TemplateID},
+		})
+		if err != nil {
+			return xerrors.Errorf("get workspaces: %w", err)
+		}
+		for _, workspace := range workspaces {
+			err = api.autoRebuildWorkspace(ctx, policy, workspace, now)
+			if err != nil {
+				api.Logger.Warn(ctx, "auto-rebuild workspace", slog.F("workspace_id", workspace.ID), slog.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// agentsResult is the outcome of the agents of a start build.
+type agentsResult int
+
+const (
+	agentsResultOK agentsResult = iota
+	// agentsResultPending means the agents haven't connected yet, but
+	// they're still within the policy's timeout.
+	agentsResultPending
+	agentsResultFailed
+)
+
+// autoRebuildWorkspace rebuilds the workspace if its agents failed the
+// policy's threshold of consecutive starts, counting back from its latest
+// build. Builds started by the policy itself end the count, so a workspace
+// that keeps failing is rebuilt once per threshold, not in a loop. Stop
+// builds don't end the count, so owners restarting their workspace doesn't
+// hide that it keeps failing.
+func (api *API) autoRebuildWorkspace(ctx context.Context, policy database.TemplateAutoRebuildPolicy, workspace database.Workspace, now time.Time) error {
+	builds, err := api.Database.GetWorkspaceBuildsByWorkspaceID(ctx, database.GetWorkspaceBuildsByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+		LimitOpt:    2*policy.FailureThreshold + 1,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get workspace builds: %w", err)
+	}
+	if len(builds) == 0 {
+		return nil
+	}
+	latest := builds[0]
+	switch {
+	case latest.Transition == database.WorkspaceTransitionStop && latest.Reason == database.BuildReasonAgentFailure:
+		// The first half of a restart. The workspace is started again
+		// once it stopped.
+		job, err := api.Database.GetProvisionerJobByID(ctx, latest.JobID)
+		if err != nil {
+			return xerrors.Errorf("get provisioner job: %w", err)
+		}
+		if convertProvisionerJob(job).Status != codersdk.ProvisionerJobSucceeded {
+			return nil
+		}
+		return api.insertAutoRebuildBuild(ctx, workspace, latest, database.WorkspaceTransitionStart)
+	case latest.Transition != database.WorkspaceTransitionStart:
+		return nil
+	}
+
+	var failures int32
+	for i, build := range builds {
+		if build.Transition == database.WorkspaceTransitionStop {
+			continue
+		}
+		if build.Transition != database.WorkspaceTransitionStart {
+			break
+		}
+		result, err := api.workspaceBuildAgentsResult(ctx, build, time.Duration(policy.AgentTimeout), now)
+		if err != nil {
+			return err
+		}
+		if result == agentsResultPending && i == 0 {
+			return nil
+		}
+		if result != agentsResultFailed {
+			break
+		}
+		if build.Reason == database.BuildReasonAgentFailure {
+			break
+		}
+		failures++
+	}
+	if failures < policy.FailureThreshold {
+		return nil
+	}
+
+	transition := database.WorkspaceTransitionStart
+	if policy.Action == database.AutoRebuildActionRestart {
+		transition = database.WorkspaceTransitionStop
+	}
+	api.Logger.Info(ctx, "auto-rebuilding workspace after agent failures",
+		slog.F("workspace_id", workspace.ID),
+		slog.F("failures", failures),
+		slog.F("action", policy.Action),
+	)
+	return api.insertAutoRebuildBuild(ctx, workspace, latest, transition)
+}
+
+// workspaceBuildAgentsResult returns whether the agents of a start build
+// failed. Agents fail when their startup script exits with an error, or
+// when they don't connect within the timeout after the build completed.
+// A zero timeout only considers startup scripts. Builds that didn't
+// succeed aren't agent failures, so they're OK.
+func (api *API) workspaceBuildAgentsResult(ctx context.Context, build database.WorkspaceBuild, timeout time.Duration, now time.Time) (agentsResult, error) {
+	job, err := api.Database.GetProvisionerJobByID(ctx, build.JobID)
+	if err != nil {
+		return agentsResultOK, xerrors.Errorf("get provisioner job: %w", err)
+	}
+	switch convertProvisionerJob(job).Status {
+	case codersdk.ProvisionerJobSucceeded:
+	case codersdk.ProvisionerJobPending, codersdk.ProvisionerJobRunning:
+		return agentsResultPending, nil
+	default:
+		return agentsResultOK, nil
+	}
+
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(ctx, build.JobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return agentsResultOK, xerrors.Errorf("get workspace resources: %w", err)
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return agentsResultOK, xerrors.Errorf("get workspace agents: %w", err)
+	}
+	if len(agents) == 0 {
+		return agentsResultOK, nil
+	}
+	agentIDs := make([]uuid.UUID, 0, len(agents))
+	for _, agent := range agents {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	scriptResults, err := api.Database.GetWorkspaceAgentStartupScriptResultsByAgentIDs(ctx, agentIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return agentsResultOK, xerrors.Errorf("get workspace agent startup script results: %w", err)
+	}
+	for _, scriptResult := range scriptResults {
+		if scriptResult.ExitCode != 0 {
+			return agentsResultFailed, nil
+		}
+	}
+
+	if timeout <= 0 {
+		return agentsResultOK, nil
+	}
+	for _, agent := range agents {
+		if agent.FirstConnectedAt.Valid {
+			continue
+		}
+		if job.CompletedAt.Time.Add(timeout).After(now) {
+			return agentsResultPending, nil
+		}
+		return agentsResultFailed, nil
+	}
+	return agentsResultOK, nil
+}
+
+// insertAutoRebuildBuild builds the workspace with the version of its prior
+// build on behalf of its owner, and notifies those watching it.
+func (api *API) insertAutoRebuildBuild(ctx context.Context, workspace database.Workspace, priorBuild database.WorkspaceBuild, transition database.WorkspaceTransition) error {
+	build, err := api.insertBackgroundWorkspaceBuild(ctx, workspace.OwnerID, workspace, priorBuild, priorBuild.TemplateVersionID, transition, database.BuildReasonAgentFailure)
+	if err != nil {
+		return xerrors.Errorf("build workspace: %w", err)
+	}
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:           codersdk.WebhookEventWorkspaceBuildCreated,
+		OrganizationID: workspace.OrganizationID,
+		ResourceID:     build.ID,
+		ResourceName:   workspace.Name,
+	})
+	return nil
+}
+
+func convertTemplateAutoRebuildPolicy(policy database.TemplateAutoRebuildPolicy) codersdk.TemplateAutoRebuildPolicy {
+	return codersdk.TemplateAutoRebuildPolicy{
+		FailureThreshold:   policy.FailureThreshold,
+		AgentTimeoutMillis: time.Duration(policy.AgentTimeout).Milliseconds(),
+		Action:             codersdk.AutoRebuildAction(policy.Action),
+	}
+}
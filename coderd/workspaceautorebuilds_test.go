@@ -0,0 +1,206 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateAutoRebuildPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		policy, err := client.TemplateAutoRebuildPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateAutoRebuildPolicy{Action: codersdk.AutoRebuildActionRestart}, policy)
+
+		policy, err = client.UpdateTemplateAutoRebuildPolicy(ctx, template.ID, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold:   3,
+			AgentTimeoutMillis: 60000,
+			Action:             codersdk.AutoRebuildActionRebuild,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold:   3,
+			AgentTimeoutMillis: 60000,
+			Action:             codersdk.AutoRebuildActionRebuild,
+		}, policy)
+
+		// The action defaults to restarting workspaces.
+		policy, err = client.UpdateTemplateAutoRebuildPolicy(ctx, template.ID, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold: 2,
+		})
+		require.NoError(t, err)
+		policy, err = client.TemplateAutoRebuildPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, policy.FailureThreshold)
+		require.Equal(t, codersdk.AutoRebuildActionRestart, policy.Action)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateAutoRebuildPolicy(ctx, template.ID, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold:   -1,
+			AgentTimeoutMillis: -1,
+			Action:             "recreate",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 3)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.TemplateAutoRebuildPolicy(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateAutoRebuildPolicy(ctx, template.ID, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold: 1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceAutoRebuild(t *testing.T) {
+	t.Parallel()
+
+	// setup creates a workspace with an agent that never connects, unless
+	// the test runs one, and returns its agent's token.
+	setup := func(t *testing.T, policy codersdk.TemplateAutoRebuildPolicy) (*codersdk.Client, codersdk.Workspace, string) {
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:         true,
+			WorkspaceAutoRebuildPollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:           echo.ParseComplete,
+			ProvisionDryRun: echo.ProvisionComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id:   uuid.NewString(),
+								Name: "dev",
+								Auth: &proto.Agent_Token{
+									Token: authToken,
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateAutoRebuildPolicy(ctx, template.ID, policy)
+		require.NoError(t, err)
+		return client, workspace, authToken
+	}
+
+	// awaitBuilds waits until the workspace has the number of builds, newest
+	// first, and their jobs completed.
+	awaitBuilds := func(t *testing.T, client *codersdk.Client, workspaceID uuid.UUID, count int) []codersdk.WorkspaceBuild {
+		ctx, _ := testutil.Context(t)
+		var builds []codersdk.WorkspaceBuild
+		require.Eventually(t, func() bool {
+			var err error
+			builds, err = client.WorkspaceBuilds(ctx, codersdk.WorkspaceBuildsRequest{WorkspaceID: workspaceID})
+			return err == nil && len(builds) >= count && !builds[0].Job.Status.Active()
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Len(t, builds, count)
+		return builds
+	}
+
+	t.Run("Rebuild", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, _ := setup(t, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold:   1,
+			AgentTimeoutMillis: 1,
+			Action:             codersdk.AutoRebuildActionRebuild,
+		})
+
+		builds := awaitBuilds(t, client, workspace.ID, 2)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, builds[0].Transition)
+		require.Equal(t, codersdk.BuildReasonAgentFailure, builds[0].Reason)
+		require.Equal(t, workspace.LatestBuild.TemplateVersionID, builds[0].TemplateVersionID)
+
+		// The rebuild failed too, but isn't retried until the workspace
+		// fails the threshold again.
+		time.Sleep(10 * testutil.IntervalFast)
+		awaitBuilds(t, client, workspace.ID, 2)
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, _ := setup(t, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold:   1,
+			AgentTimeoutMillis: 1,
+			Action:             codersdk.AutoRebuildActionRestart,
+		})
+
+		builds := awaitBuilds(t, client, workspace.ID, 3)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, builds[0].Transition)
+		require.Equal(t, codersdk.BuildReasonAgentFailure, builds[0].Reason)
+		require.Equal(t, codersdk.WorkspaceTransitionStop, builds[1].Transition)
+		require.Equal(t, codersdk.BuildReasonAgentFailure, builds[1].Reason)
+	})
+
+	t.Run("StartupScript", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, authToken := setup(t, codersdk.TemplateAutoRebuildPolicy{
+			FailureThreshold:   1,
+			AgentTimeoutMillis: time.Hour.Milliseconds(),
+			Action:             codersdk.AutoRebuildActionRebuild,
+		})
+
+		ctx, _ := testutil.Context(t)
+		agentClient := codersdk.New(client.URL)
+		agentClient.SessionToken = authToken
+		err := agentClient.PostWorkspaceAgentStartupScript(ctx, codersdk.PostWorkspaceAgentStartupScriptRequest{
+			ExitCode: 1,
+		})
+		require.NoError(t, err)
+
+		builds := awaitBuilds(t, client, workspace.ID, 2)
+		require.Equal(t, codersdk.BuildReasonAgentFailure, builds[0].Reason)
+	})
+}
@@ -0,0 +1,469 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/workspacequota"
+	"github.com/coder/coder/codersdk"
+)
+
+// postWorkspaceBatch applies an action to every workspace that matches the
+// filter in the background. Workspaces the user can read but not build, e.g.
+// those shared with them, fail right away.
+func (api *API) postWorkspaceBatch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+	var req codersdk.CreateWorkspaceBatchRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.Filter.Name != "" {
+		_, err := path.Match(req.Filter.Name, "")
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "filter.name", Detail: fmt.Sprintf("%q is not a valid glob pattern.", req.Filter.Name)})
+		}
+	}
+	if req.Filter.DormantMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "filter.dormant_ms", Detail: "Must not be negative."})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace batch filter.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	filter := database.GetWorkspacesParams{
+		OwnerUsername: req.Filter.Owner,
+		TemplateName:  req.Filter.Template,
+	}
+	if filter.OwnerUsername == "me" {
+		filter.OwnerID = apiKey.UserID
+		filter.OwnerUsername = ""
+	}
+	sqlFilter, err := api.HTTPAuth.AuthorizeSQLFilter(r, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error preparing sql filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	workspaces, err := api.Database.GetAuthorizedWorkspaces(ctx, filter, sqlFilter)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var action rbac.Action = rbac.ActionUpdate
+	if req.Action == codersdk.WorkspaceBatchActionDelete {
+		action = rbac.ActionDelete
+	}
+	now := database.Now()
+	dormantSince := now.Add(-time.Duration(req.Filter.DormantMillis) * time.Millisecond)
+	ipAllowed := map[uuid.UUID]bool{}
+
+	// Results are decided before they're inserted, since the checks query
+	// outside of the transaction.
+	var pending []database.InsertWorkspaceBatchResultParams
+	for _, workspace := range workspaces {
+		if req.Filter.Name != "" {
+			match, _ := path.Match(req.Filter.Name, workspace.Name)
+			if !match {
+				continue
+			}
+		}
+		if req.Filter.DormantMillis > 0 && workspace.LastUsedAt.After(dormantSince) {
+			continue
+		}
+
+		allowed, ok := ipAllowed[workspace.OrganizationID]
+		if !ok {
+			allowed, err = api.organizationIPAllowed(r, workspace.OrganizationID)
+			if err != nil {
+				httpapi.InternalServerError(rw, err)
+				return
+			}
+			ipAllowed[workspace.OrganizationID] = allowed
+			if !allowed {
+				api.auditIPAllowlistDenial(ctx, r, workspace.OrganizationID, requestIP(r))
+			}
+		}
+		result := database.InsertWorkspaceBatchResultParams{
+			WorkspaceID:   workspace.ID,
+			WorkspaceName: workspace.Name,
+			Action:        database.WorkspaceBatchAction(req.Action),
+			Status:        database.WorkspaceBatchResultStatusPending,
+			CreatedAt:     now,
+		}
+		switch {
+		case !allowed:
+			result.Status = database.WorkspaceBatchResultStatusFailed
+			result.Error = "Your IP address isn't allowed to access the organization of the workspace."
+		case !api.Authorize(r, action, workspace):
+			result.Status = database.WorkspaceBatchResultStatusFailed
+			result.Error = fmt.Sprintf("You aren't allowed to %s the workspace.", req.Action)
+		}
+		pending = append(pending, result)
+	}
+
+	var (
+		operation database.Operation
+		results   = make([]database.WorkspaceBatchResult, 0, len(pending))
+	)
+	err = api.Database.InTx(func(db database.Store) error {
+		var err error
+		operation, err = db.InsertOperation(ctx, database.InsertOperationParams{
+			ID:          uuid.New(),
+			Type:        string(codersdk.OperationTypeWorkspaceBatch),
+			InitiatorID: apiKey.UserID,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert operation: %w", err)
+		}
+		for _, params := range pending {
+			params.OperationID = operation.ID
+			result, err := db.InsertWorkspaceBatchResult(ctx, params)
+			if err != nil {
+				return xerrors.Errorf("insert workspace batch result: %w", err)
+			}
+			results = append(results, result)
+		}
+
+		operation, err = updateWorkspaceBatchOperation(ctx, db, operation, results)
+		return err
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating workspace batch.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if operation.Status == database.OperationStatusRunning {
+		api.startWorkspaceBatch(operation)
+	}
+
+	writeOperationAccepted(ctx, rw, operation.ID, convertWorkspaceBatch(operation, results))
+}
+
+func (api *API) workspaceBatch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := uuid.Parse(chi.URLParam(r, "operation"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid operation ID.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	operation, err := api.Database.GetOperationByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if operation.Type != string(codersdk.OperationTypeWorkspaceBatch) || !api.Authorize(r, rbac.ActionRead, operation) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	results, err := api.Database.GetWorkspaceBatchResultsByOperationID(ctx, operation.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceBatch(operation, results))
+}
+
+// resumeWorkspaceBatches continues the bulk actions that were running when
+// the API was stopped.
+func (api *API) resumeWorkspaceBatches() {
+	ctx := api.workspaceBatchesCtx
+	operations, err := api.Database.GetOperationsByTypeAndStatus(ctx, database.GetOperationsByTypeAndStatusParams{
+		Type:   string(codersdk.OperationTypeWorkspaceBatch),
+		Status: database.OperationStatusRunning,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "get running workspace batches", slog.Error(err))
+		return
+	}
+	for _, operation := range operations {
+		api.startWorkspaceBatch(operation)
+	}
+}
+
+// startWorkspaceBatch runs the bulk action in the background. If it can't
+// continue, the operation is marked as failed.
+func (api *API) startWorkspaceBatch(operation database.Operation) {
+	api.workspaceBatchesWG.Add(1)
+	go func() {
+		defer api.workspaceBatchesWG.Done()
+		ctx := api.workspaceBatchesCtx
+		logger := api.Logger.With(slog.F("operation_id", operation.ID))
+
+		err := api.runWorkspaceBatch(ctx, operation)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			// The batch is still running and resumes on the next start.
+			return
+		}
+		logger.Warn(ctx, "run workspace batch", slog.Error(err))
+		operation.Status = database.OperationStatusFailed
+		operation.Error = err.Error()
+		operation.CompletedAt = sql.NullTime{Time: database.Now(), Valid: true}
+		_, err = updateOperation(ctx, api.Database, operation)
+		if err != nil {
+			logger.Error(ctx, "mark workspace batch failed", slog.Error(err))
+		}
+	}()
+}
+
+// runWorkspaceBatch starts a build for every pending workspace of the batch,
+// and then waits for the builds to complete. The progress is recorded after
+// every workspace.
+func (api *API) runWorkspaceBatch(ctx context.Context, operation database.Operation) error {
+	results, err := api.Database.GetWorkspaceBatchResultsByOperationID(ctx, operation.ID)
+	if err != nil {
+		return xerrors.Errorf("get workspace batch results: %w", err)
+	}
+
+	for i, result := range results {
+		if result.Status != database.WorkspaceBatchResultStatusPending {
+			continue
+		}
+		results[i], err = api.buildWorkspaceBatchResult(ctx, operation.InitiatorID, result)
+		if err != nil {
+			return err
+		}
+		operation, err = updateWorkspaceBatchOperation(ctx, api.Database, operation, results)
+		if err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(api.WorkspaceBatchPollInterval)
+	defer ticker.Stop()
+	for operation.Status == database.OperationStatusRunning {
+		for i, result := range results {
+			if result.Status != database.WorkspaceBatchResultStatusRunning {
+				continue
+			}
+			build, err := api.Database.GetWorkspaceBuildByID(ctx, result.BuildID.UUID)
+			if err != nil {
+				return xerrors.Errorf("get build of workspace %q: %w", result.WorkspaceName, err)
+			}
+			job, err := api.Database.GetProvisionerJobByID(ctx, build.JobID)
+			if err != nil {
+				return xerrors.Errorf("get provisioner job of workspace %q: %w", result.WorkspaceName, err)
+			}
+			switch status := convertProvisionerJob(job).Status; status {
+			case codersdk.ProvisionerJobSucceeded:
+				result.Status = database.WorkspaceBatchResultStatusSucceeded
+			case codersdk.ProvisionerJobFailed, codersdk.ProvisionerJobCanceled:
+				result.Status = database.WorkspaceBatchResultStatusFailed
+				result.Error = job.Error.String
+				if result.Error == "" {
+					result.Error = fmt.Sprintf("The build was %s.", status)
+				}
+			default:
+				continue
+			}
+			results[i], err = updateWorkspaceBatchResult(ctx, api.Database, result)
+			if err != nil {
+				return err
+			}
+			operation, err = updateWorkspaceBatchOperation(ctx, api.Database, operation, results)
+			if err != nil {
+				return err
+			}
+		}
+		if operation.Status != database.OperationStatusRunning {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// buildWorkspaceBatchResult starts the build of the batch's action for the
+// workspace. Workspaces that are already in the requested state succeed
+// without a build.
+func (api *API) buildWorkspaceBatchResult(ctx context.Context, initiatorID uuid.UUID, result database.WorkspaceBatchResult) (database.WorkspaceBatchResult, error) {
+	fail := func(reason string) (database.WorkspaceBatchResult, error) {
+		result.Status = database.WorkspaceBatchResultStatusFailed
+		result.Error = reason
+		return updateWorkspaceBatchResult(ctx, api.Database, result)
+	}
+
+	workspace, err := api.Database.GetWorkspaceByID(ctx, result.WorkspaceID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && workspace.Deleted) {
+		return fail("The workspace was deleted.")
+	}
+	if err != nil {
+		return result, xerrors.Errorf("get workspace %q: %w", result.WorkspaceName, err)
+	}
+	if result.Action != database.WorkspaceBatchActionDelete {
+		archived, err := workspaceArchived(ctx, api.Database, workspace.ID)
+		if err != nil {
+			return result, xerrors.Errorf("get archive of workspace %q: %w", workspace.Name, err)
+		}
+		if archived {
+			return fail("The workspace is archived.")
+		}
+	}
+	priorBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		return result, xerrors.Errorf("get latest build of workspace %q: %w", workspace.Name, err)
+	}
+	priorJob, err := api.Database.GetProvisionerJobByID(ctx, priorBuild.JobID)
+	if err != nil {
+		return result, xerrors.Errorf("get provisioner job of workspace %q: %w", workspace.Name, err)
+	}
+	priorStatus := convertProvisionerJob(priorJob).Status
+	if priorStatus.Active() {
+		return fail("A workspace build is already active.")
+	}
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return result, xerrors.Errorf("get template of workspace %q: %w", workspace.Name, err)
+	}
+
+	transition := database.WorkspaceTransitionStart
+	versionID := priorBuild.TemplateVersionID
+	switch result.Action {
+	case database.WorkspaceBatchActionStop:
+		transition = database.WorkspaceTransitionStop
+	case database.WorkspaceBatchActionDelete:
+		transition = database.WorkspaceTransitionDelete
+	case database.WorkspaceBatchActionUpdate:
+		versionID = template.ActiveVersionID
+	}
+	if transition != database.WorkspaceTransitionDelete && priorStatus == codersdk.ProvisionerJobSucceeded &&
+		priorBuild.Transition == transition && priorBuild.TemplateVersionID == versionID {
+		result.Status = database.WorkspaceBatchResultStatusSucceeded
+		return updateWorkspaceBatchResult(ctx, api.Database, result)
+	}
+
+	if transition == database.WorkspaceTransitionStart {
+		e := *api.WorkspaceQuotaEnforcer.Load()
+		err = e.CheckOrganizationQuota(ctx, template, workspace.ID)
+		var quotaErr *workspacequota.OrganizationQuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return fail(fmt.Sprintf("Organization quota of %d %s is exceeded.", quotaErr.Allowance, quotaErr.Limit))
+		}
+		if err != nil {
+			return result, xerrors.Errorf("check organization quota: %w", err)
+		}
+	}
+
+	build, err := api.insertBackgroundWorkspaceBuild(ctx, initiatorID, workspace, priorBuild, versionID, transition, database.BuildReasonInitiator)
+	if err != nil {
+		return result, xerrors.Errorf("build workspace %q: %w", workspace.Name, err)
+	}
+	if transition == database.WorkspaceTransitionDelete {
+		api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionDeleted, workspace)
+	} else {
+		api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	}
+
+	result.Status = database.WorkspaceBatchResultStatusRunning
+	result.BuildID = uuid.NullUUID{UUID: build.ID, Valid: true}
+	return updateWorkspaceBatchResult(ctx, api.Database, result)
+}
+
+func updateWorkspaceBatchResult(ctx context.Context, db database.Store, result database.WorkspaceBatchResult) (database.WorkspaceBatchResult, error) {
+	updated, err := db.UpdateWorkspaceBatchResult(ctx, database.UpdateWorkspaceBatchResultParams{
+		OperationID: result.OperationID,
+		WorkspaceID: result.WorkspaceID,
+		Status:      result.Status,
+		BuildID:     result.BuildID,
+		Error:       result.Error,
+		UpdatedAt:   database.Now(),
+	})
+	if err != nil {
+		return result, xerrors.Errorf("update workspace batch result: %w", err)
+	}
+	return updated, nil
+}
+
+// updateWorkspaceBatchOperation records the progress of the batch. It
+// completes once every workspace succeeded or failed, and fails if any of
+// them did.
+func updateWorkspaceBatchOperation(ctx context.Context, db database.Store, operation database.Operation, results []database.WorkspaceBatchResult) (database.Operation, error) {
+	var completed, failed int64
+	for _, result := range results {
+		switch result.Status {
+		case database.WorkspaceBatchResultStatusSucceeded:
+			completed++
+		case database.WorkspaceBatchResultStatusFailed:
+			completed++
+			failed++
+		}
+	}
+	operation.ProgressCompleted = completed
+	operation.ProgressTotal = int64(len(results))
+	if completed == operation.ProgressTotal {
+		operation.Status = database.OperationStatusSucceeded
+		if failed > 0 {
+			operation.Status = database.OperationStatusFailed
+			operation.Error = fmt.Sprintf("%d of %d workspaces failed.", failed, len(results))
+		}
+		operation.CompletedAt = sql.NullTime{Time: database.Now(), Valid: true}
+	}
+	return updateOperation(ctx, db, operation)
+}
+
+func convertWorkspaceBatch(operation database.Operation, results []database.WorkspaceBatchResult) codersdk.WorkspaceBatch {
+	converted := codersdk.WorkspaceBatch{
+		Operation: convertOperation(operation),
+		Results:   make([]codersdk.WorkspaceBatchResult, 0, len(results)),
+	}
+	for _, result := range results {
+		convertedResult := codersdk.WorkspaceBatchResult{
+			WorkspaceID:   result.WorkspaceID,
+			WorkspaceName: result.WorkspaceName,
+			Status:        codersdk.WorkspaceBatchResultStatus(result.Status),
+			Error:         result.Error,
+		}
+		if result.BuildID.Valid {
+			buildID := result.BuildID.UUID
+			convertedResult.BuildID = &buildID
+		}
+		converted.Results = append(converted.Results, convertedResult)
+	}
+	return converted
+}
@@ -0,0 +1,193 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Stop", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:   true,
+			WorkspaceBatchPollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		var workspaces []codersdk.Workspace
+		for _, name := range []string{"dev-a", "dev-b", "other"} {
+			name := name
+			workspace := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID, func(req *codersdk.CreateWorkspaceRequest) {
+				req.Name = name
+			})
+			coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+			workspaces = append(workspaces, workspace)
+		}
+		// The admin's workspace doesn't match the owner.
+		adminWorkspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(req *codersdk.CreateWorkspaceRequest) {
+			req.Name = "dev-admin"
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, adminWorkspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		batch, err := member.CreateWorkspaceBatch(ctx, codersdk.CreateWorkspaceBatchRequest{
+			Action: codersdk.WorkspaceBatchActionStop,
+			Filter: codersdk.WorkspaceBatchFilter{
+				Owner: "me",
+				Name:  "dev-*",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.OperationTypeWorkspaceBatch, batch.Operation.Type)
+		require.Len(t, batch.Results, 2)
+
+		batch = awaitWorkspaceBatch(t, member, batch.Operation.ID)
+		require.Equal(t, codersdk.OperationStatusSucceeded, batch.Operation.Status)
+		require.EqualValues(t, 2, batch.Operation.ProgressCompleted)
+		for i, result := range batch.Results {
+			require.Equal(t, workspaces[i].ID, result.WorkspaceID)
+			require.Equal(t, codersdk.WorkspaceBatchResultStatusSucceeded, result.Status)
+			require.NotNil(t, result.BuildID)
+
+			workspace, err := member.Workspace(ctx, result.WorkspaceID)
+			require.NoError(t, err)
+			require.Equal(t, codersdk.WorkspaceTransitionStop, workspace.LatestBuild.Transition)
+		}
+
+		// Stopped workspaces are left as they are.
+		batch, err = member.CreateWorkspaceBatch(ctx, codersdk.CreateWorkspaceBatchRequest{
+			Action: codersdk.WorkspaceBatchActionStop,
+			Filter: codersdk.WorkspaceBatchFilter{
+				Owner: "me",
+				Name:  "dev-*",
+			},
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(t, member, batch.Operation.ID)
+		require.Equal(t, codersdk.OperationStatusSucceeded, batch.Operation.Status)
+		for _, result := range batch.Results {
+			require.Nil(t, result.BuildID)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:   true,
+			WorkspaceBatchPollInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		batch, err := client.CreateWorkspaceBatch(ctx, codersdk.CreateWorkspaceBatchRequest{
+			Action: codersdk.WorkspaceBatchActionUpdate,
+			Filter: codersdk.WorkspaceBatchFilter{
+				Template: template.Name,
+			},
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(t, client, batch.Operation.ID)
+		require.Equal(t, codersdk.OperationStatusSucceeded, batch.Operation.Status)
+
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, newVersion.ID, workspace.LatestBuild.TemplateVersionID)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+	})
+
+	t.Run("Shared", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		other, otherUser := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				otherUser.ID.String(): codersdk.WorkspaceRoleView,
+			},
+		})
+		require.NoError(t, err)
+
+		// Workspaces shared with the user match, but can't be deleted.
+		batch, err := other.CreateWorkspaceBatch(ctx, codersdk.CreateWorkspaceBatchRequest{
+			Action: codersdk.WorkspaceBatchActionDelete,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.OperationStatusFailed, batch.Operation.Status)
+		require.Len(t, batch.Results, 1)
+		require.Equal(t, codersdk.WorkspaceBatchResultStatusFailed, batch.Results[0].Status)
+		require.Nil(t, batch.Results[0].BuildID)
+
+		// Batches are only visible to those who started them.
+		_, err = client.WorkspaceBatch(ctx, batch.Operation.ID)
+		require.NoError(t, err)
+		_, err = coderdtest.CreateAnotherUser(t, client, user.OrganizationID).WorkspaceBatch(ctx, batch.Operation.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidFilter", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateWorkspaceBatch(ctx, codersdk.CreateWorkspaceBatchRequest{
+			Action: codersdk.WorkspaceBatchActionStart,
+			Filter: codersdk.WorkspaceBatchFilter{
+				Name:          "dev-[",
+				DormantMillis: -1,
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+	})
+}
+
+func awaitWorkspaceBatch(t *testing.T, client *codersdk.Client, operationID uuid.UUID) codersdk.WorkspaceBatch {
+	t.Helper()
+	ctx, _ := testutil.Context(t)
+	var (
+		batch codersdk.WorkspaceBatch
+		err   error
+	)
+	require.Eventually(t, func() bool {
+		batch, err = client.WorkspaceBatch(ctx, operationID)
+		return assert.NoError(t, err) && batch.Operation.Status != codersdk.OperationStatusRunning
+	}, testutil.WaitLong, testutil.IntervalFast)
+	return batch
+}
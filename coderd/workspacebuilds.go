@@ -71,7 +71,7 @@ func (api *API) workspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	paginationParams, ok := parsePagination(rw, r)
+	paginationParams, ok := ParsePagination(rw, r)
 	if !ok {
 		return
 	}
@@ -201,6 +201,9 @@ func (api *API) workspaceBuildByBuildNumber(rw http.ResponseWriter, r *http.Requ
 		httpapi.ResourceNotFound(rw)
 		return
 	}
+	if !api.checkOrganizationIPAllowed(rw, r, workspace.OrganizationID) {
+		return
+	}
 
 	workspaceBuild, err := api.Database.GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx, database.GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams{
 		WorkspaceID: workspace.ID,
@@ -277,6 +280,23 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if createBuild.Transition != codersdk.WorkspaceTransitionDelete {
+		archived, err := workspaceArchived(ctx, api.Database, workspace.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspace archive.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if archived {
+			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+				Message: "The workspace is archived. Restore it first.",
+			})
+			return
+		}
+	}
+
 	if createBuild.TemplateVersionID == uuid.Nil {
 		latestBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
 		if err != nil {
@@ -287,6 +307,28 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 		createBuild.TemplateVersionID = latestBuild.TemplateVersionID
+
+		// Workspaces transferred to another organization switch to the
+		// active version of their new template.
+		latestVersion, err := api.Database.GetTemplateVersionByID(ctx, latestBuild.TemplateVersionID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template version.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if latestVersion.TemplateID.UUID != workspace.TemplateID {
+			workspaceTemplate, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching template.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			createBuild.TemplateVersionID = workspaceTemplate.ActiveVersionID
+		}
 	}
 
 	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, createBuild.TemplateVersionID)
@@ -396,6 +438,10 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if createBuild.Transition == codersdk.WorkspaceTransitionStart && !api.checkOrganizationQuota(rw, r, template, workspace.ID) {
+		return
+	}
+
 	if state == nil {
 		state = priorHistory.ProvisionerState
 	}
@@ -522,9 +568,102 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if createBuild.Transition == codersdk.WorkspaceTransitionDelete {
+		api.publishOrganizationEvent(workspace.OrganizationID, codersdk.OrganizationWebhookEvent{
+			Type:         codersdk.OrganizationWebhookEventWorkspaceDeleted,
+			ResourceID:   workspace.ID,
+			ResourceName: workspace.Name,
+		})
+		api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionDeleted, workspace)
+		api.PublishWebhookEvent(codersdk.WebhookEvent{
+			Type:           codersdk.WebhookEventWorkspaceDeleted,
+			OrganizationID: workspace.OrganizationID,
+			ResourceID:     workspace.ID,
+			ResourceName:   workspace.Name,
+		})
+	} else {
+		api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	}
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:           codersdk.WebhookEventWorkspaceBuildCreated,
+		OrganizationID: workspace.OrganizationID,
+		ResourceID:     workspaceBuild.ID,
+		ResourceName:   workspace.Name,
+	})
+
 	httpapi.Write(ctx, rw, http.StatusCreated, apiBuild)
 }
 
+// insertBackgroundWorkspaceBuild starts a build of the workspace on behalf of
+// the initiator outside of a request, with the state of its prior build.
+func (api *API) insertBackgroundWorkspaceBuild(ctx context.Context, initiatorID uuid.UUID, workspace database.Workspace, priorBuild database.WorkspaceBuild, templateVersionID uuid.UUID, transition database.WorkspaceTransition, reason database.BuildReason) (database.WorkspaceBuild, error) {
+	var build database.WorkspaceBuild
+	err := api.Database.InTx(func(db database.Store) error {
+		var err error
+		build, err = insertWorkspaceBuild(ctx, db, initiatorID, workspace, priorBuild, templateVersionID, transition, reason)
+		return err
+	})
+	return build, err
+}
+
+// insertWorkspaceBuild inserts a build of the workspace and its job with the
+// state of its prior build. It must be called in a transaction.
+func insertWorkspaceBuild(ctx context.Context, db database.Store, initiatorID uuid.UUID, workspace database.Workspace, priorBuild database.WorkspaceBuild, templateVersionID uuid.UUID, transition database.WorkspaceTransition, reason database.BuildReason) (database.WorkspaceBuild, error) {
+	template, err := db.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return database.WorkspaceBuild{}, xerrors.Errorf("get template: %w", err)
+	}
+	version, err := db.GetTemplateVersionByID(ctx, templateVersionID)
+	if err != nil {
+		return database.WorkspaceBuild{}, xerrors.Errorf("get template version: %w", err)
+	}
+	versionJob, err := db.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		return database.WorkspaceBuild{}, xerrors.Errorf("get template version job: %w", err)
+	}
+
+	workspaceBuildID := uuid.New()
+	input, err := json.Marshal(workspaceProvisionJob{
+		WorkspaceBuildID: workspaceBuildID,
+	})
+	if err != nil {
+		return database.WorkspaceBuild{}, xerrors.Errorf("marshal provision job: %w", err)
+	}
+	now := database.Now()
+	job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		InitiatorID:    initiatorID,
+		OrganizationID: template.OrganizationID,
+		Provisioner:    template.Provisioner,
+		Type:           database.ProvisionerJobTypeWorkspaceBuild,
+		StorageMethod:  versionJob.StorageMethod,
+		StorageSource:  versionJob.StorageSource,
+		Input:          input,
+	})
+	if err != nil {
+		return database.WorkspaceBuild{}, xerrors.Errorf("insert provisioner job: %w", err)
+	}
+	build, err := db.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
+		ID:                workspaceBuildID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		WorkspaceID:       workspace.ID,
+		TemplateVersionID: version.ID,
+		BuildNumber:       priorBuild.BuildNumber + 1,
+		ProvisionerState:  priorBuild.ProvisionerState,
+		InitiatorID:       initiatorID,
+		Transition:        transition,
+		JobID:             job.ID,
+		Reason:            reason,
+	})
+	if err != nil {
+		return database.WorkspaceBuild{}, xerrors.Errorf("insert workspace build: %w", err)
+	}
+	return build, nil
+}
+
 func (api *API) patchCancelWorkspaceBuild(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	workspaceBuild := httpmw.WorkspaceBuildParam(r)
@@ -0,0 +1,499 @@
+package coderd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	// workspaceCostReportDefaultRange is the range of cost reports when no
+	// start time is given.
+	workspaceCostReportDefaultRange = 30 * 24 * time.Hour
+	maxTemplateResourceCosts        = 64
+)
+
+func (api *API) templateResourceCosts(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	costs, err := api.Database.GetTemplateResourceCostsByTemplateID(ctx, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template resource costs.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateResourceCosts(costs))
+}
+
+// putTemplateResourceCosts replaces the resource costs of a template. Costs
+// that accrued already are kept as they are.
+func (api *API) putTemplateResourceCosts(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.TemplateResourceCosts
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if len(req.HourlyCosts) > maxTemplateResourceCosts {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "hourly_costs",
+			Detail: fmt.Sprintf("Can have at most %d resource types.", maxTemplateResourceCosts),
+		})
+	}
+	resourceTypes := make([]string, 0, len(req.HourlyCosts))
+	for resourceType, cost := range req.HourlyCosts {
+		switch {
+		case strings.TrimSpace(resourceType) == "":
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "hourly_costs",
+				Detail: "Resource types can't be empty.",
+			})
+		case cost < 0 || math.IsInf(cost, 0) || math.IsNaN(cost):
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "hourly_costs",
+				Detail: fmt.Sprintf("The cost of %q must be a positive number.", resourceType),
+			})
+		}
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid resource costs.",
+			Validations: validErrs,
+		})
+		return
+	}
+	sort.Strings(resourceTypes)
+
+	var costs []database.TemplateResourceCost
+	err := api.Database.InTx(func(tx database.Store) error {
+		err := tx.DeleteTemplateResourceCostsByTemplateID(ctx, template.ID)
+		if err != nil {
+			return xerrors.Errorf("delete resource costs: %w", err)
+		}
+		for _, resourceType := range resourceTypes {
+			err = tx.InsertTemplateResourceCost(ctx, database.InsertTemplateResourceCostParams{
+				TemplateID:   template.ID,
+				ResourceType: resourceType,
+				HourlyCost:   req.HourlyCosts[resourceType],
+			})
+			if err != nil {
+				return xerrors.Errorf("insert resource cost: %w", err)
+			}
+		}
+		costs, err = tx.GetTemplateResourceCostsByTemplateID(ctx, template.ID)
+		return err
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template resource costs.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateResourceCosts(costs))
+}
+
+func (api *API) workspaceCostReport(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	report, ok := api.workspaceCostReportFromRequest(rw, r)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, report)
+}
+
+func (api *API) exportWorkspaceCostReport(rw http.ResponseWriter, r *http.Request) {
+	report, ok := api.workspaceCostReportFromRequest(rw, r)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("costs-%s-%s.csv", report.StartTime.UTC().Format("2006-01-02"), report.EndTime.UTC().Format("2006-01-02"))
+	rw.Header().Set("Content-Type", "text/csv")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	rw.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(rw)
+	_ = writer.Write([]string{"id", "name", "cost", "running_hours"})
+	for _, entry := range report.Entries {
+		err := writer.Write([]string{
+			entry.ID.String(),
+			entry.Name,
+			strconv.FormatFloat(entry.Cost, 'f', -1, 64),
+			strconv.FormatFloat(entry.RunningHours, 'f', 2, 64),
+		})
+		if err != nil {
+			// The client has likely gone away, and the status has
+			// already been written.
+			return
+		}
+	}
+	writer.Flush()
+}
+
+// workspaceCostReportFromRequest sums the costs of the organization's
+// workspaces over the time range of the request. Errors are written to the
+// response.
+func (api *API) workspaceCostReportFromRequest(rw http.ResponseWriter, r *http.Request) (codersdk.WorkspaceCostReport, bool) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+		query        = r.URL.Query()
+	)
+
+	// Costs are only visible to those that manage the organization.
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return codersdk.WorkspaceCostReport{}, false
+	}
+
+	endTime := database.Now()
+	startTime := time.Time{}
+	for param, value := range map[string]*time.Time{
+		"start_time": &startTime,
+		"end_time":   &endTime,
+	} {
+		raw := query.Get(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Query param `" + param + "` must be a valid RFC3339 timestamp.",
+				Detail:  err.Error(),
+			})
+			return codersdk.WorkspaceCostReport{}, false
+		}
+		*value = parsed
+	}
+	if startTime.IsZero() {
+		startTime = endTime.Add(-workspaceCostReportDefaultRange)
+	}
+	// Costs accrue per hour, so partial hours are included.
+	startTime = startTime.Truncate(time.Hour)
+	if rounded := endTime.Truncate(time.Hour); rounded.Before(endTime) {
+		endTime = rounded.Add(time.Hour)
+	}
+	if !startTime.Before(endTime) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The start time must be before the end time.",
+		})
+		return codersdk.WorkspaceCostReport{}, false
+	}
+
+	groupBy := codersdk.WorkspaceCostGroupBy(query.Get("group_by"))
+	switch groupBy {
+	case "":
+		groupBy = codersdk.WorkspaceCostGroupByOrganization
+	case codersdk.WorkspaceCostGroupByOrganization, codersdk.WorkspaceCostGroupByUser,
+		codersdk.WorkspaceCostGroupByGroup, codersdk.WorkspaceCostGroupByTemplate:
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Query param `group_by` must be one of %q, %q, %q, or %q.",
+				codersdk.WorkspaceCostGroupByOrganization, codersdk.WorkspaceCostGroupByUser,
+				codersdk.WorkspaceCostGroupByGroup, codersdk.WorkspaceCostGroupByTemplate),
+		})
+		return codersdk.WorkspaceCostReport{}, false
+	}
+
+	rows, err := api.Database.GetWorkspaceCostsByOrganizationID(ctx, database.GetWorkspaceCostsByOrganizationIDParams{
+		OrganizationID: organization.ID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return codersdk.WorkspaceCostReport{}, false
+	}
+
+	report := codersdk.WorkspaceCostReport{
+		OrganizationID: organization.ID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		GroupBy:        groupBy,
+		Entries:        []codersdk.WorkspaceCostEntry{},
+	}
+	entries := map[uuid.UUID]*codersdk.WorkspaceCostEntry{}
+	add := func(id uuid.UUID, name string, row database.GetWorkspaceCostsByOrganizationIDRow) {
+		entry, ok := entries[id]
+		if !ok {
+			entry = &codersdk.WorkspaceCostEntry{ID: id, Name: name}
+			entries[id] = entry
+		}
+		entry.Cost += row.Cost
+		entry.RunningHours += time.Duration(row.RunningSeconds * int64(time.Second)).Hours()
+	}
+
+	switch groupBy {
+	case codersdk.WorkspaceCostGroupByOrganization:
+		for _, row := range rows {
+			add(organization.ID, organization.Name, row)
+		}
+	case codersdk.WorkspaceCostGroupByUser:
+		userIDs := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			userIDs = append(userIDs, row.OwnerID)
+		}
+		users, err := api.Database.GetUsersByIDs(ctx, userIDs)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return codersdk.WorkspaceCostReport{}, false
+		}
+		usernames := make(map[uuid.UUID]string, len(users))
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+		for _, row := range rows {
+			add(row.OwnerID, usernames[row.OwnerID], row)
+		}
+	case codersdk.WorkspaceCostGroupByGroup:
+		userIDs := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			userIDs = append(userIDs, row.OwnerID)
+		}
+		memberships, err := api.Database.GetGroupMembershipsByUserIDs(ctx, database.GetGroupMembershipsByUserIDsParams{
+			OrganizationID: organization.ID,
+			UserIds:        userIDs,
+		})
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return codersdk.WorkspaceCostReport{}, false
+		}
+		groupsByUserID := make(map[uuid.UUID][]database.GetGroupMembershipsByUserIDsRow)
+		for _, membership := range memberships {
+			groupsByUserID[membership.UserID] = append(groupsByUserID[membership.UserID], membership)
+		}
+		for _, row := range rows {
+			// Every member of the organization is in the Everyone group,
+			// whose ID is the organization's.
+			add(organization.ID, database.AllUsersGroup, row)
+			for _, membership := range groupsByUserID[row.OwnerID] {
+				add(membership.GroupID, membership.GroupName, row)
+			}
+		}
+	case codersdk.WorkspaceCostGroupByTemplate:
+		templateIDs := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			templateIDs = append(templateIDs, row.TemplateID)
+		}
+		templates, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+			IDs: templateIDs,
+		})
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return codersdk.WorkspaceCostReport{}, false
+		}
+		templateNames := make(map[uuid.UUID]string, len(templates))
+		for _, template := range templates {
+			templateNames[template.ID] = template.Name
+		}
+		for _, row := range rows {
+			add(row.TemplateID, templateNames[row.TemplateID], row)
+		}
+	}
+
+	for _, row := range rows {
+		report.TotalCost += row.Cost
+	}
+	for _, entry := range entries {
+		report.Entries = append(report.Entries, *entry)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Cost != report.Entries[j].Cost {
+			return report.Entries[i].Cost > report.Entries[j].Cost
+		}
+		return report.Entries[i].Name < report.Entries[j].Name
+	})
+	return report, true
+}
+
+// startWorkspaceCostAccrual accrues the costs of running workspaces in the
+// background until the API is closed.
+func (api *API) startWorkspaceCostAccrual() {
+	api.workspaceCostsWG.Add(1)
+	go func() {
+		defer api.workspaceCostsWG.Done()
+		ctx := api.workspaceCostsCtx
+		ticker := time.NewTicker(api.WorkspaceCostAccrualInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := accrueWorkspaceCosts(ctx, api.Database, database.Now(), api.WorkspaceCostAccrualInterval)
+			if err != nil && ctx.Err() == nil {
+				api.Logger.Warn(ctx, "accrue workspace costs", slog.Error(err))
+			}
+		}
+	}()
+}
+
+// accrueWorkspaceCosts adds the cost of running the workspaces of templates
+// with resource costs since their costs last accrued. Workspaces run from the
+// end of a successful start build. Costs of workspaces that didn't accrue
+// since they started are only accrued for the last interval, so setting the
+// costs of a template doesn't charge for the past.
+func accrueWorkspaceCosts(ctx context.Context, db database.Store, now time.Time, interval time.Duration) error {
+	resourceCosts, err := db.GetTemplateResourceCosts(ctx)
+	if err != nil {
+		return xerrors.Errorf("get template resource costs: %w", err)
+	}
+	if len(resourceCosts) == 0 {
+		return nil
+	}
+	hourlyCosts := make(map[uuid.UUID]map[string]float64)
+	templateIDs := make([]uuid.UUID, 0)
+	for _, cost := range resourceCosts {
+		if _, ok := hourlyCosts[cost.TemplateID]; !ok {
+			hourlyCosts[cost.TemplateID] = make(map[string]float64)
+			templateIDs = append(templateIDs, cost.TemplateID)
+		}
+		hourlyCosts[cost.TemplateID][cost.ResourceType] = cost.HourlyCost
+	}
+
+	workspaces, err := db.GetWorkspaces(ctx, database.GetWorkspacesParams{
+		TemplateIds: templateIDs,
+	})
+	if err != nil {
+		return xerrors.Errorf("get workspaces: %w", err)
+	}
+	if len(workspaces) == 0 {
+		return nil
+	}
+	workspaceIDs := make([]uuid.UUID, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		workspaceIDs = append(workspaceIDs, workspace.ID)
+	}
+	builds, err := db.GetLatestWorkspaceBuildsByWorkspaceIDs(ctx, workspaceIDs)
+	if err != nil {
+		return xerrors.Errorf("get workspace builds: %w", err)
+	}
+	buildByWorkspaceID := make(map[uuid.UUID]database.WorkspaceBuild, len(builds))
+	jobIDs := make([]uuid.UUID, 0, len(builds))
+	for _, build := range builds {
+		if build.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
+		buildByWorkspaceID[build.WorkspaceID] = build
+		jobIDs = append(jobIDs, build.JobID)
+	}
+	if len(jobIDs) == 0 {
+		return nil
+	}
+	jobs, err := db.GetProvisionerJobsByIDs(ctx, jobIDs)
+	if err != nil {
+		return xerrors.Errorf("get provisioner jobs: %w", err)
+	}
+	jobByID := make(map[uuid.UUID]database.ProvisionerJob, len(jobs))
+	for _, job := range jobs {
+		jobByID[job.ID] = job
+	}
+	resources, err := db.GetWorkspaceResourcesByJobIDs(ctx, jobIDs)
+	if err != nil {
+		return xerrors.Errorf("get workspace resources: %w", err)
+	}
+	resourcesByJobID := make(map[uuid.UUID][]database.WorkspaceResource)
+	for _, resource := range resources {
+		resourcesByJobID[resource.JobID] = append(resourcesByJobID[resource.JobID], resource)
+	}
+	accrued, err := db.GetWorkspaceCostsAccruedUntil(ctx, workspaceIDs)
+	if err != nil {
+		return xerrors.Errorf("get workspace costs: %w", err)
+	}
+	accruedUntil := make(map[uuid.UUID]time.Time, len(accrued))
+	for _, row := range accrued {
+		accruedUntil[row.WorkspaceID] = row.AccruedUntil
+	}
+
+	for _, workspace := range workspaces {
+		build, ok := buildByWorkspaceID[workspace.ID]
+		if !ok {
+			continue
+		}
+		job := jobByID[build.JobID]
+		if !job.CompletedAt.Valid || job.CanceledAt.Valid || job.Error.String != "" {
+			continue
+		}
+		var hourlyCost float64
+		for _, resource := range resourcesByJobID[job.ID] {
+			hourlyCost += hourlyCosts[workspace.TemplateID][resource.Type]
+		}
+
+		from := job.CompletedAt.Time
+		if until, ok := accruedUntil[workspace.ID]; ok && until.After(from) {
+			from = until
+		} else if from.Before(now.Add(-interval)) {
+			from = now.Add(-interval)
+		}
+		// Costs are split into the hours they accrued in.
+		for from.Before(now) {
+			hour := from.Truncate(time.Hour)
+			until := hour.Add(time.Hour)
+			if until.After(now) {
+				until = now
+			}
+			running := until.Sub(from)
+			err = db.AccrueWorkspaceCost(ctx, database.AccrueWorkspaceCostParams{
+				WorkspaceID:    workspace.ID,
+				OwnerID:        workspace.OwnerID,
+				OrganizationID: workspace.OrganizationID,
+				TemplateID:     workspace.TemplateID,
+				StartTime:      hour,
+				Cost:           hourlyCost * running.Hours(),
+				RunningSeconds: int64(running.Seconds()),
+				AccruedUntil:   until,
+			})
+			if err != nil {
+				return xerrors.Errorf("accrue workspace cost: %w", err)
+			}
+			from = until
+		}
+	}
+	return nil
+}
+
+func convertTemplateResourceCosts(costs []database.TemplateResourceCost) codersdk.TemplateResourceCosts {
+	hourlyCosts := make(map[string]float64, len(costs))
+	for _, cost := range costs {
+		hourlyCosts[cost.ResourceType] = cost.HourlyCost
+	}
+	return codersdk.TemplateResourceCosts{
+		HourlyCosts: hourlyCosts,
+	}
+}
@@ -0,0 +1,186 @@
+package coderd_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateResourceCosts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		costs, err := client.TemplateResourceCosts(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, costs.HourlyCosts)
+
+		costs, err = client.UpdateTemplateResourceCosts(ctx, template.ID, codersdk.TemplateResourceCosts{
+			HourlyCosts: map[string]float64{
+				"aws_instance":   0.25,
+				"aws_ebs_volume": 0.01,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{
+			"aws_instance":   0.25,
+			"aws_ebs_volume": 0.01,
+		}, costs.HourlyCosts)
+
+		// The costs are replaced.
+		costs, err = client.UpdateTemplateResourceCosts(ctx, template.ID, codersdk.TemplateResourceCosts{
+			HourlyCosts: map[string]float64{"aws_instance": 0.5},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{"aws_instance": 0.5}, costs.HourlyCosts)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateResourceCosts(ctx, template.ID, codersdk.TemplateResourceCosts{
+			HourlyCosts: map[string]float64{
+				"":             1,
+				"aws_instance": -1,
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.TemplateResourceCosts(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateResourceCosts(ctx, template.ID, codersdk.TemplateResourceCosts{
+			HourlyCosts: map[string]float64{"aws_instance": 1},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceCostReport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:     true,
+			WorkspaceCostAccrualInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "dev",
+							Type: "example",
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateTemplateResourceCosts(ctx, template.ID, codersdk.TemplateResourceCosts{
+			HourlyCosts: map[string]float64{"example": 100},
+		})
+		require.NoError(t, err)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		var report codersdk.WorkspaceCostReport
+		require.Eventually(t, func() bool {
+			report, err = client.WorkspaceCostReport(ctx, user.OrganizationID, codersdk.WorkspaceCostReportRequest{})
+			return err == nil && report.TotalCost > 0
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.WorkspaceCostGroupByOrganization, report.GroupBy)
+		require.Len(t, report.Entries, 1)
+		require.Equal(t, user.OrganizationID, report.Entries[0].ID)
+
+		report, err = client.WorkspaceCostReport(ctx, user.OrganizationID, codersdk.WorkspaceCostReportRequest{
+			GroupBy: codersdk.WorkspaceCostGroupByUser,
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Entries, 1)
+		require.Equal(t, user.UserID, report.Entries[0].ID)
+		require.Equal(t, coderdtest.FirstUserParams.Username, report.Entries[0].Name)
+
+		report, err = client.WorkspaceCostReport(ctx, user.OrganizationID, codersdk.WorkspaceCostReportRequest{
+			GroupBy: codersdk.WorkspaceCostGroupByTemplate,
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Entries, 1)
+		require.Equal(t, template.Name, report.Entries[0].Name)
+
+		data, err := client.ExportWorkspaceCostReport(ctx, user.OrganizationID, codersdk.WorkspaceCostReportRequest{
+			GroupBy: codersdk.WorkspaceCostGroupByGroup,
+		})
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		require.Equal(t, "id,name,cost,running_hours", lines[0])
+		require.True(t, strings.HasPrefix(lines[1], user.OrganizationID.String()+","))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.WorkspaceCostReport(ctx, user.OrganizationID, codersdk.WorkspaceCostReportRequest{
+			GroupBy: "workspace",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.WorkspaceCostReport(ctx, user.OrganizationID, codersdk.WorkspaceCostReportRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
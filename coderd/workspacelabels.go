@@ -0,0 +1,213 @@
+package coderd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	maxLabelKeyLength   = 64
+	maxLabelValueLength = 256
+)
+
+// putWorkspaceLabels replaces the labels the owner set on a workspace. The
+// labels of the template are kept as they are.
+func (api *API) putWorkspaceLabels(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceLabelsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	validErrs := validateLabels("labels", req.Labels)
+	// Labels of the template may be sent back as they are, e.g. when the
+	// labels of the workspace are edited as a whole.
+	labels := convertLabels(template.WorkspaceLabels)
+	for key, value := range req.Labels {
+		if templateValue, ok := labels[key]; ok && templateValue != value {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  "labels",
+				Detail: fmt.Sprintf("%q is set by the template and can't be changed.", key),
+			})
+		}
+	}
+	for key, value := range req.Labels {
+		labels[key] = value
+	}
+	if len(validErrs) == 0 && len(labels) > codersdk.MaxWorkspaceLabels {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  "labels",
+			Detail: fmt.Sprintf("A workspace can have at most %d labels, including those of its template.", codersdk.MaxWorkspaceLabels),
+		})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace labels.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	raw, err := json.Marshal(labels)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	updated, err := api.Database.UpdateWorkspaceLabels(ctx, database.UpdateWorkspaceLabelsParams{
+		ID:        workspace.ID,
+		Labels:    raw,
+		UpdatedAt: database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating workspace labels.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = updated
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, updated)
+
+	data, err := api.workspaceData(ctx, []database.Workspace{updated})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspace(
+		updated,
+		data.builds[0],
+		data.templates[0],
+		findUser(updated.OwnerID, data.users),
+		data.maintenanceAt[updated.ID],
+		data.deprecations[updated.TemplateID],
+	))
+}
+
+// updateTemplateWorkspaceLabels replaces the labels a template applies to its
+// workspaces, and applies them to the existing workspaces.
+func updateTemplateWorkspaceLabels(ctx context.Context, db database.Store, template database.Template, labels map[string]string) (database.Template, error) {
+	raw, err := json.Marshal(labels)
+	if err != nil {
+		return database.Template{}, xerrors.Errorf("marshal labels: %w", err)
+	}
+	removedKeys := []string{}
+	for key := range convertLabels(template.WorkspaceLabels) {
+		if _, ok := labels[key]; !ok {
+			removedKeys = append(removedKeys, key)
+		}
+	}
+	sort.Strings(removedKeys)
+
+	updated, err := db.UpdateTemplateWorkspaceLabelsByID(ctx, database.UpdateTemplateWorkspaceLabelsByIDParams{
+		ID:              template.ID,
+		WorkspaceLabels: raw,
+		UpdatedAt:       database.Now(),
+	})
+	if err != nil {
+		return database.Template{}, xerrors.Errorf("update template workspace labels: %w", err)
+	}
+	err = db.UpdateWorkspaceLabelsByTemplateID(ctx, database.UpdateWorkspaceLabelsByTemplateIDParams{
+		RemovedKeys: removedKeys,
+		Labels:      raw,
+		TemplateID:  template.ID,
+	})
+	if err != nil {
+		return database.Template{}, xerrors.Errorf("update workspace labels: %w", err)
+	}
+	return updated, nil
+}
+
+// validateLabels checks that labels can be matched by the workspaces filter,
+// which is case-insensitive and splits terms on "=".
+func validateLabels(field string, labels map[string]string) []codersdk.ValidationError {
+	var validErrs []codersdk.ValidationError
+	if len(labels) > codersdk.MaxWorkspaceLabels {
+		validErrs = append(validErrs, codersdk.ValidationError{
+			Field:  field,
+			Detail: fmt.Sprintf("Can have at most %d labels.", codersdk.MaxWorkspaceLabels),
+		})
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := labels[key]
+		switch {
+		case key == "" || len(key) > maxLabelKeyLength:
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  field,
+				Detail: fmt.Sprintf("Keys must be between 1 and %d characters, got %q.", maxLabelKeyLength, key),
+			})
+		case strings.ContainsAny(key, "=:\"") || strings.IndexFunc(key, unicode.IsSpace) >= 0:
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  field,
+				Detail: fmt.Sprintf("Key %q can't contain spaces, quotes, '=' or ':'.", key),
+			})
+		case len(value) > maxLabelValueLength:
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  field,
+				Detail: fmt.Sprintf("The value of %q can be at most %d characters.", key, maxLabelValueLength),
+			})
+		case strings.ToLower(key) != key || strings.ToLower(value) != value:
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  field,
+				Detail: fmt.Sprintf("Label %q must be lowercase.", key),
+			})
+		}
+	}
+	return validErrs
+}
+
+// convertLabels returns the labels stored in a jsonb column. It never returns
+// nil, so labels are always serialized as an object.
+func convertLabels(raw json.RawMessage) map[string]string {
+	labels := map[string]string{}
+	_ = json.Unmarshal(raw, &labels)
+	return labels
+}
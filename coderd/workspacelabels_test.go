@@ -0,0 +1,126 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceLabels(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Owner", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:            template.Name,
+			MaxTTLMillis:    template.MaxTTLMillis,
+			WorkspaceLabels: &map[string]string{"cost-center": "r&d"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"cost-center": "r&d"}, template.WorkspaceLabels)
+
+		workspace := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		other := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, other.LatestBuild.ID)
+		require.Equal(t, map[string]string{"cost-center": "r&d"}, workspace.Labels)
+
+		// Labels of the template can be sent back, but not changed.
+		workspace, err = member.UpdateWorkspaceLabels(ctx, workspace.ID, codersdk.UpdateWorkspaceLabelsRequest{
+			Labels: map[string]string{"team": "payments", "cost-center": "r&d"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "payments", "cost-center": "r&d"}, workspace.Labels)
+		_, err = member.UpdateWorkspaceLabels(ctx, workspace.ID, codersdk.UpdateWorkspaceLabelsRequest{
+			Labels: map[string]string{"cost-center": "sales"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		workspaces, err := member.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "label:team=payments label:cost-center=r&d",
+		})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+		require.Equal(t, workspace.ID, workspaces[0].ID)
+		workspaces, err = member.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "label:cost-center=r&d",
+		})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 2)
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		require.Empty(t, workspace.Labels)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateWorkspaceLabels(ctx, workspace.ID, codersdk.UpdateWorkspaceLabelsRequest{
+			Labels: map[string]string{"team": "payments"},
+		})
+		require.NoError(t, err)
+
+		// Existing workspaces get the labels of the template.
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:            template.Name,
+			MaxTTLMillis:    template.MaxTTLMillis,
+			WorkspaceLabels: &map[string]string{"cost-center": "r&d", "env": "dev"},
+		})
+		require.NoError(t, err)
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "payments", "cost-center": "r&d", "env": "dev"}, workspace.Labels)
+
+		// Labels the template no longer sets are removed.
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:            template.Name,
+			MaxTTLMillis:    template.MaxTTLMillis,
+			WorkspaceLabels: &map[string]string{"env": "dev"},
+		})
+		require.NoError(t, err)
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "payments", "env": "dev"}, workspace.Labels)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateWorkspaceLabels(ctx, workspace.ID, codersdk.UpdateWorkspaceLabelsRequest{
+			Labels: map[string]string{"": "empty", "Team": "payments", "a=b": "c"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 3)
+	})
+}
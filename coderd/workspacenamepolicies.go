@@ -0,0 +1,268 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+var (
+	workspaceNamePrefixPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+	workspaceNamePrefixLiteral     = regexp.MustCompile(`^[a-zA-Z0-9-]*$`)
+)
+
+func (api *API) organizationWorkspaceNamePolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	// Members need to know the policy to name their workspaces.
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	policy, err := api.Database.GetOrganizationWorkspaceNamePolicy(ctx, organization.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization workspace name policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceNamePolicy(policy.Pattern, policy.Prefix, policy.ReservedNames))
+}
+
+func (api *API) putOrganizationWorkspaceNamePolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(organization.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.WorkspaceNamePolicy
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if validErrs := validateWorkspaceNamePolicy(req); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace name policy.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	policy, err := api.Database.UpsertOrganizationWorkspaceNamePolicy(ctx, database.UpsertOrganizationWorkspaceNamePolicyParams{
+		OrganizationID: organization.ID,
+		Pattern:        req.Pattern,
+		Prefix:         req.Prefix,
+		ReservedNames:  reservedWorkspaceNames(req.ReservedNames),
+		UpdatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization workspace name policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceNamePolicy(policy.Pattern, policy.Prefix, policy.ReservedNames))
+}
+
+func (api *API) templateWorkspaceNamePolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	policy, err := api.Database.GetTemplateWorkspaceNamePolicyByTemplateID(ctx, template.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template workspace name policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceNamePolicy(policy.Pattern, policy.Prefix, policy.ReservedNames))
+}
+
+func (api *API) putTemplateWorkspaceNamePolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.WorkspaceNamePolicy
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if validErrs := validateWorkspaceNamePolicy(req); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace name policy.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	policy, err := api.Database.UpsertTemplateWorkspaceNamePolicy(ctx, database.UpsertTemplateWorkspaceNamePolicyParams{
+		TemplateID:    template.ID,
+		Pattern:       req.Pattern,
+		Prefix:        req.Prefix,
+		ReservedNames: reservedWorkspaceNames(req.ReservedNames),
+		UpdatedAt:     database.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template workspace name policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceNamePolicy(policy.Pattern, policy.Prefix, policy.ReservedNames))
+}
+
+// checkWorkspaceNamePolicy writes an error and returns false if the name
+// breaks the name policy of the template or of its organization.
+func (api *API) checkWorkspaceNamePolicy(rw http.ResponseWriter, r *http.Request, ownerID uuid.UUID, name string, template database.Template) bool {
+	ctx := r.Context()
+	organizationPolicy, err := api.Database.GetOrganizationWorkspaceNamePolicy(ctx, template.OrganizationID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization workspace name policy.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	templatePolicy, err := api.Database.GetTemplateWorkspaceNamePolicyByTemplateID(ctx, template.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template workspace name policy.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	owner, err := api.Database.GetUserByID(ctx, ownerID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace owner.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+
+	var validErrs []codersdk.ValidationError
+	for _, detail := range workspaceNamePolicyViolations(
+		convertWorkspaceNamePolicy(organizationPolicy.Pattern, organizationPolicy.Prefix, organizationPolicy.ReservedNames),
+		"organization", name, owner.Username, template.Name,
+	) {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "name", Detail: detail})
+	}
+	for _, detail := range workspaceNamePolicyViolations(
+		convertWorkspaceNamePolicy(templatePolicy.Pattern, templatePolicy.Prefix, templatePolicy.ReservedNames),
+		"template", name, owner.Username, template.Name,
+	) {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "name", Detail: detail})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     fmt.Sprintf("Workspace name %q doesn't follow the naming policy.", name),
+			Validations: validErrs,
+		})
+		return false
+	}
+	return true
+}
+
+// workspaceNamePolicyViolations describes how the name breaks the policy of
+// the scope, e.g. "template".
+func workspaceNamePolicyViolations(policy codersdk.WorkspaceNamePolicy, scope, name, username, templateName string) []string {
+	var violations []string
+	if policy.Pattern != "" {
+		// The policy was validated when it was set.
+		pattern, err := regexp.Compile("^(?:" + policy.Pattern + ")$")
+		if err == nil && !pattern.MatchString(name) {
+			violations = append(violations, fmt.Sprintf("The %s's naming policy requires names to match %q.", scope, policy.Pattern))
+		}
+	}
+	if policy.Prefix != "" {
+		prefix := expandWorkspaceNamePrefix(policy.Prefix, username, templateName)
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			violations = append(violations, fmt.Sprintf("The %s's naming policy requires names to start with %q.", scope, prefix))
+		}
+	}
+	for _, reserved := range policy.ReservedNames {
+		if strings.EqualFold(name, reserved) {
+			violations = append(violations, fmt.Sprintf("The %s's naming policy reserves the name %q.", scope, reserved))
+		}
+	}
+	return violations
+}
+
+func expandWorkspaceNamePrefix(prefix, username, templateName string) string {
+	return strings.NewReplacer("{username}", username, "{template}", templateName).Replace(prefix)
+}
+
+func validateWorkspaceNamePolicy(policy codersdk.WorkspaceNamePolicy) []codersdk.ValidationError {
+	var validErrs []codersdk.ValidationError
+	if policy.Pattern != "" {
+		_, err := regexp.Compile(policy.Pattern)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "pattern", Detail: fmt.Sprintf("Must be a valid regular expression: %s", err)})
+		}
+	}
+	for _, match := range workspaceNamePrefixPlaceholder.FindAllStringSubmatch(policy.Prefix, -1) {
+		if match[1] != "username" && match[1] != "template" {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "prefix", Detail: fmt.Sprintf("Unknown placeholder %q, only {username} and {template} are supported.", match[0])})
+		}
+	}
+	if !workspaceNamePrefixLiteral.MatchString(workspaceNamePrefixPlaceholder.ReplaceAllString(policy.Prefix, "")) {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "prefix", Detail: "Must only contain letters, digits, hyphens, and placeholders."})
+	}
+	for i, name := range policy.ReservedNames {
+		if strings.TrimSpace(name) == "" {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: fmt.Sprintf("reserved_names[%d]", i), Detail: "Must not be empty."})
+		}
+	}
+	return validErrs
+}
+
+func reservedWorkspaceNames(names []string) []string {
+	reserved := make([]string, 0, len(names))
+	for _, name := range names {
+		reserved = append(reserved, strings.TrimSpace(name))
+	}
+	return reserved
+}
+
+func convertWorkspaceNamePolicy(pattern, prefix string, reservedNames []string) codersdk.WorkspaceNamePolicy {
+	if reservedNames == nil {
+		reservedNames = []string{}
+	}
+	return codersdk.WorkspaceNamePolicy{
+		Pattern:       pattern,
+		Prefix:        prefix,
+		ReservedNames: reservedNames,
+	}
+}
@@ -0,0 +1,177 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationWorkspaceNamePolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		policy, err := client.OrganizationWorkspaceNamePolicy(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceNamePolicy{ReservedNames: []string{}}, policy)
+
+		policy, err = client.UpdateOrganizationWorkspaceNamePolicy(ctx, user.OrganizationID, codersdk.WorkspaceNamePolicy{
+			Pattern:       "[a-z0-9-]+",
+			Prefix:        "{username}-",
+			ReservedNames: []string{" testuser-admin "},
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceNamePolicy{
+			Pattern:       "[a-z0-9-]+",
+			Prefix:        "{username}-",
+			ReservedNames: []string{"testuser-admin"},
+		}, policy)
+
+		for _, name := range []string{"dev", "testuser-Dev", "testuser-admin"} {
+			_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+				TemplateID: template.ID,
+				Name:       name,
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr, name)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), name)
+			require.Len(t, apiErr.Validations, 1, name)
+			require.Equal(t, "name", apiErr.Validations[0].Field, name)
+		}
+		workspace, err := client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "testuser-dev",
+		})
+		require.NoError(t, err)
+
+		// Renames follow the policy too.
+		err = client.UpdateWorkspace(ctx, workspace.ID, codersdk.UpdateWorkspaceRequest{Name: "dev"})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		err = client.UpdateWorkspace(ctx, workspace.ID, codersdk.UpdateWorkspaceRequest{Name: "testuser-prod"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateOrganizationWorkspaceNamePolicy(ctx, user.OrganizationID, codersdk.WorkspaceNamePolicy{
+			Pattern:       "[a-z",
+			Prefix:        "{owner}_",
+			ReservedNames: []string{""},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 4)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.OrganizationWorkspaceNamePolicy(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		_, err = member.UpdateOrganizationWorkspaceNamePolicy(ctx, user.OrganizationID, codersdk.WorkspaceNamePolicy{
+			Prefix: "{username}-",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestTemplateWorkspaceNamePolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(req *codersdk.CreateTemplateRequest) {
+			req.Name = "docker"
+		})
+
+		ctx, _ := testutil.Context(t)
+		policy, err := client.UpdateTemplateWorkspaceNamePolicy(ctx, template.ID, codersdk.WorkspaceNamePolicy{
+			Prefix: "{username}-{template}",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "{username}-{template}", policy.Prefix)
+		policy, err = client.TemplateWorkspaceNamePolicy(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, "{username}-{template}", policy.Prefix)
+
+		// The organization's policy applies as well.
+		_, err = client.UpdateOrganizationWorkspaceNamePolicy(ctx, user.OrganizationID, codersdk.WorkspaceNamePolicy{
+			ReservedNames: []string{"testuser-docker-admin"},
+		})
+		require.NoError(t, err)
+
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "dev",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, []codersdk.ValidationError{{
+			Field:  "name",
+			Detail: `The template's naming policy requires names to start with "testuser-docker".`,
+		}}, apiErr.Validations)
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "testuser-docker-admin",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, []codersdk.ValidationError{{
+			Field:  "name",
+			Detail: `The organization's naming policy reserves the name "testuser-docker-admin".`,
+		}}, apiErr.Validations)
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "testuser-docker-1",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.TemplateWorkspaceNamePolicy(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = member.UpdateTemplateWorkspaceNamePolicy(ctx, template.ID, codersdk.WorkspaceNamePolicy{
+			Prefix: "{username}-",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,310 @@
+package coderd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// putWorkspaceOwner transfers a workspace to another member of its
+// organization, e.g. when the owner leaves. The tokens of the agents are
+// rotated, so the previous owner can't impersonate them.
+func (api *API) putWorkspaceOwner(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceOwnerRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// Only those who can create workspaces for the new owner, i.e. admins,
+	// can transfer workspaces to them.
+	if !api.Authorize(r, rbac.ActionCreate,
+		rbac.ResourceWorkspace.InOrg(workspace.OrganizationID).WithOwner(req.OwnerID.String())) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Only admins can transfer workspaces to other users.",
+		})
+		return
+	}
+	if req.OwnerID == workspace.OwnerID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The user already owns the workspace.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "owner_id",
+				Detail: "Must be another user.",
+			}},
+		})
+		return
+	}
+
+	owner, err := api.Database.GetAuthorizationUserRoles(ctx, req.OwnerID)
+	if err == nil && owner.Status != database.UserStatusActive {
+		err = sql.ErrNoRows
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "User not found.",
+			Validations: []codersdk.ValidationError{{Field: "owner_id", Detail: "Must be the ID of an active user."}},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	_, err = api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: workspace.OrganizationID,
+		UserID:         req.OwnerID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The user isn't a member of the workspace's organization.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization member.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// The new owner must be allowed to use the template, as if they created
+	// the workspace.
+	err = api.Authorizer.ByRoleName(ctx, owner.ID.String(), owner.Roles, rbac.ScopeAll, owner.Groups, owner.EveryoneExcludedOrgs, rbac.ActionRead, template.RBACObject())
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("User %q can't use the template of the workspace.", owner.Username),
+		})
+		return
+	}
+
+	build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	job, err := api.Database.GetProvisionerJobByID(ctx, build.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !job.CompletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The workspace can't be transferred while it's being built.",
+		})
+		return
+	}
+
+	// The workspace counts against the quotas of the new owner as if they
+	// created it.
+	if !api.checkCanCreateWorkspace(rw, r, req.OwnerID, workspace.Name, template) {
+		return
+	}
+
+	var transferred database.Workspace
+	err = api.Database.InTx(func(tx database.Store) error {
+		e := *api.WorkspaceQuotaEnforcer.Load()
+		err := e.CheckBudget(ctx, tx, req.OwnerID, template)
+		if err != nil {
+			return xerrors.Errorf("check budget: %w", err)
+		}
+		transferred, err = tx.UpdateWorkspaceOwner(ctx, database.UpdateWorkspaceOwnerParams{
+			ID:        workspace.ID,
+			OwnerID:   req.OwnerID,
+			UpdatedAt: database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace owner: %w", err)
+		}
+
+		// Owners can't be granted a role on their own workspace.
+		userACL := transferred.UserACL()
+		if _, ok := userACL[req.OwnerID.String()]; ok {
+			delete(userACL, req.OwnerID.String())
+			err = tx.UpdateWorkspaceUserACLByID(ctx, workspace.ID, userACL)
+			if err != nil {
+				return xerrors.Errorf("update workspace user ACL: %w", err)
+			}
+			transferred = transferred.SetUserACL(userACL)
+		}
+
+		return rotateWorkspaceAgentTokens(ctx, tx, build)
+	})
+	if writeBudgetExceeded(ctx, rw, err) {
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusMethodNotAllowed, codersdk.Response{
+			Message: fmt.Sprintf("Workspace %q is deleted and cannot be transferred.", workspace.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error transferring workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = transferred
+	// The previous owner is notified too, so they find out the workspace is
+	// gone.
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, transferred)
+
+	data, err := api.workspaceData(ctx, []database.Workspace{transferred})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspace(
+		transferred,
+		data.builds[0],
+		data.templates[0],
+		findUser(transferred.OwnerID, data.users),
+		data.maintenanceAt[transferred.ID],
+		data.deprecations[transferred.TemplateID],
+	))
+}
+
+// rotateWorkspaceAgentTokens replaces the tokens of the agents of the build,
+// both in the database and in the Terraform state the next build starts
+// from. Running agents that authenticate with a token lose access until the
+// workspace is restarted.
+func rotateWorkspaceAgentTokens(ctx context.Context, db database.Store, build database.WorkspaceBuild) error {
+	resources, err := db.GetWorkspaceResourcesByJobID(ctx, build.JobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get workspace resources: %w", err)
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := db.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get workspace agents: %w", err)
+	}
+
+	tokens := map[uuid.UUID]uuid.UUID{}
+	for _, agent := range agents {
+		tokens[agent.AuthToken] = uuid.New()
+	}
+	state, err := rotateStateAgentTokens(build.ProvisionerState, tokens)
+	if err != nil {
+		return xerrors.Errorf("rotate agent tokens in state: %w", err)
+	}
+	err = db.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
+		ID:               build.ID,
+		UpdatedAt:        database.Now(),
+		ProvisionerState: state,
+		Deadline:         build.Deadline,
+	})
+	if err != nil {
+		return xerrors.Errorf("update workspace build: %w", err)
+	}
+
+	for token, rotated := range tokens {
+		err = db.UpdateWorkspaceAgentAuthToken(ctx, database.UpdateWorkspaceAgentAuthTokenParams{
+			NewAuthToken: rotated,
+			UpdatedAt:    database.Now(),
+			AuthToken:    token,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace agent auth token: %w", err)
+		}
+	}
+	return nil
+}
+
+// rotateStateAgentTokens replaces the tokens of the coder_agent resources in
+// Terraform state with the rotated tokens, so the next build hands them to
+// the agents. Tokens that aren't in the map yet are added to it. State of
+// other provisioners is returned as is.
+func rotateStateAgentTokens(state []byte, tokens map[uuid.UUID]uuid.UUID) ([]byte, error) {
+	var tfState map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(state))
+	// Numbers are kept as they are, e.g. the serial of the state.
+	decoder.UseNumber()
+	err := decoder.Decode(&tfState)
+	if err != nil {
+		return state, nil
+	}
+	resources, _ := tfState["resources"].([]any)
+	if len(resources) == 0 {
+		return state, nil
+	}
+	for _, rawResource := range resources {
+		resource, _ := rawResource.(map[string]any)
+		if resource["type"] != "coder_agent" {
+			continue
+		}
+		instances, _ := resource["instances"].([]any)
+		for _, rawInstance := range instances {
+			instance, _ := rawInstance.(map[string]any)
+			attributes, _ := instance["attributes"].(map[string]any)
+			rawToken, _ := attributes["token"].(string)
+			token, err := uuid.Parse(rawToken)
+			if err != nil {
+				continue
+			}
+			rotated, ok := tokens[token]
+			if !ok {
+				rotated = uuid.New()
+				tokens[token] = rotated
+			}
+			attributes["token"] = rotated.String()
+		}
+	}
+	return json.Marshal(tfState)
+}
@@ -1,8 +1,52 @@
 package workspacequota
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+)
+
 type Enforcer interface {
 	UserWorkspaceLimit() int
 	CanCreateWorkspace(count int) bool
+	// CheckBudget returns a *BudgetExceededError if creating a workspace
+	// from the template would exceed the owner's group quota budget. It must
+	// be called in the transaction that gives the owner the workspace, which
+	// it locks the owner's budget for, so concurrent requests can't both
+	// spend the rest of it.
+	CheckBudget(ctx context.Context, db database.Store, ownerID uuid.UUID, template database.Template) error
+	// CheckOrganizationQuota returns an *OrganizationQuotaExceededError if
+	// starting a workspace from the template would exceed the quota of the
+	// template's organization. workspaceID is uuid.Nil for new workspaces.
+	CheckOrganizationQuota(ctx context.Context, template database.Template, workspaceID uuid.UUID) error
+}
+
+// BudgetExceededError is returned when a workspace costs more than is left
+// of the owner's group quota budget.
+type BudgetExceededError struct {
+	Allowance int64
+	Consumed  int64
+	Weight    int32
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("workspace quota budget of %d is exceeded: %d is consumed and the template weighs %d", e.Allowance, e.Consumed, e.Weight)
+}
+
+// OrganizationQuotaExceededError is returned when a workspace would exceed
+// a limit of its organization's quota.
+type OrganizationQuotaExceededError struct {
+	// Limit is the name of the exceeded limit, e.g. "running workspaces".
+	Limit     string
+	Allowance int64
+	Consumed  int64
+}
+
+func (e *OrganizationQuotaExceededError) Error() string {
+	return fmt.Sprintf("organization quota of %d %s is exceeded: %d are consumed", e.Allowance, e.Limit, e.Consumed)
 }
 
 type nop struct{}
@@ -17,3 +61,11 @@ func (*nop) UserWorkspaceLimit() int {
 func (*nop) CanCreateWorkspace(_ int) bool {
 	return true
 }
+
+func (*nop) CheckBudget(_ context.Context, _ database.Store, _ uuid.UUID, _ database.Template) error {
+	return nil
+}
+
+func (*nop) CheckOrganizationQuota(_ context.Context, _ database.Template, _ uuid.UUID) error {
+	return nil
+}
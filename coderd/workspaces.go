@@ -29,6 +29,7 @@ import (
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/coderd/workspacequota"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -41,6 +42,7 @@ var (
 	errDeadlineTooSoon         = xerrors.New("new deadline must be at least 30 minutes in the future")
 	errDeadlineBeforeStart     = xerrors.New("new deadline must be before workspace start time")
 	errDeadlineOverTemplateMax = xerrors.New("new deadline is greater than template allows")
+	errDeadlineOverGroupMax    = xerrors.New("new deadline is later than the autostop policy of one of your groups allows")
 )
 
 func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
@@ -89,6 +91,8 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 		data.builds[0],
 		data.templates[0],
 		findUser(workspace.OwnerID, data.users),
+		data.maintenanceAt[workspace.ID],
+		data.deprecations[workspace.TemplateID],
 	))
 }
 
@@ -107,6 +111,14 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	fields, ok := httpapi.ParseFields(rw, r, codersdk.Workspace{})
+	if !ok {
+		return
+	}
+	sortBy, ok := parseListSort(rw, r)
+	if !ok {
+		return
+	}
 
 	if filter.OwnerUsername == "me" {
 		filter.OwnerID = apiKey.UserID
@@ -130,6 +142,11 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	workspaces, err = api.filterIPAllowedWorkspaces(r, workspaces)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
 
 	data, err := api.workspaceData(ctx, workspaces)
 	if err != nil {
@@ -148,8 +165,19 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if sortBy == codersdk.SortFavorites {
+		favorites, err := api.userFavorites(ctx, apiKey.UserID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching favorites.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		sortWorkspacesByFavorites(wss, favorites.WorkspaceIDs)
+	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, wss)
+	httpapi.WriteFields(ctx, rw, http.StatusOK, wss, fields, "")
 }
 
 func (api *API) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request) {
@@ -198,6 +226,9 @@ func (api *API) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request)
 		httpapi.ResourceNotFound(rw)
 		return
 	}
+	if !api.checkOrganizationIPAllowed(rw, r, workspace.OrganizationID) {
+		return
+	}
 
 	data, err := api.workspaceData(ctx, []database.Workspace{workspace})
 	if err != nil {
@@ -213,6 +244,8 @@ func (api *API) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request)
 		data.builds[0],
 		data.templates[0],
 		findUser(workspace.OwnerID, data.users),
+		data.maintenanceAt[workspace.ID],
+		data.deprecations[workspace.TemplateID],
 	))
 }
 
@@ -243,6 +276,9 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 	if !httpapi.Read(ctx, rw, r, &createWorkspace) {
 		return
 	}
+	if api.organizationDeleting(rw, r, organization.ID) {
+		return
+	}
 
 	template, err := api.Database.GetTemplateByID(ctx, createWorkspace.TemplateID)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -284,6 +320,27 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if validErrs := disallowedParameterValues(defaults.AllowedParameterValues, createWorkspace.ParameterValues); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid create workspace request.",
+			Validations: validErrs,
+		})
+		return
+	}
+	if createWorkspace.TTLMillis == nil && defaults.DefaultTTLMillis > 0 {
+		defaultTTL := defaults.DefaultTTLMillis
+		// The template can be stricter than the organization.
+		if template.MaxTtl > 0 && time.Duration(defaultTTL)*time.Millisecond > time.Duration(template.MaxTtl) {
+			defaultTTL = time.Duration(template.MaxTtl).Milliseconds()
+		}
+		createWorkspace.TTLMillis = &defaultTTL
+	}
+
 	dbTTL, err := validWorkspaceTTLMillis(createWorkspace.TTLMillis, time.Duration(template.MaxTtl))
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -293,45 +350,16 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
-	workspace, err := api.Database.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
-		OwnerID: user.ID,
-		Name:    createWorkspace.Name,
-	})
-	if err == nil {
-		// If the workspace already exists, don't allow creation.
-		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
-			Message: fmt.Sprintf("Workspace %q already exists.", createWorkspace.Name),
-			Validations: []codersdk.ValidationError{{
-				Field:  "name",
-				Detail: "This value is already in use and should be unique.",
-			}},
-		})
+	if !api.checkTemplateDeprecation(rw, r, template) {
 		return
 	}
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: fmt.Sprintf("Internal error fetching workspace by name %q.", createWorkspace.Name),
-			Detail:  err.Error(),
-		})
+	if !api.checkWorkspaceNamePolicy(rw, r, user.ID, createWorkspace.Name, template) {
 		return
 	}
-
-	workspaceCount, err := api.Database.GetWorkspaceCountByUserID(ctx, user.ID)
-	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Internal error fetching workspace count.",
-			Detail:  err.Error(),
-		})
+	if !api.checkCanCreateWorkspace(rw, r, user.ID, createWorkspace.Name, template) {
 		return
 	}
-
-	// make sure the user has not hit their quota limit
-	e := *api.WorkspaceQuotaEnforcer.Load()
-	canCreate := e.CanCreateWorkspace(int(workspaceCount))
-	if !canCreate {
-		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message: fmt.Sprintf("User workspace limit of %d is already reached.", e.UserWorkspaceLimit()),
-		})
+	if !api.checkOrganizationQuota(rw, r, template, uuid.Nil) {
 		return
 	}
 
@@ -370,11 +398,112 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	workspace, workspaceBuild, provisionerJob, err := insertWorkspace(ctx, api.Database, insertWorkspaceParams{
+		OwnerID:            user.ID,
+		InitiatorID:        apiKey.UserID,
+		Template:           template,
+		TemplateVersion:    templateVersion,
+		TemplateVersionJob: templateVersionJob,
+		Name:               createWorkspace.Name,
+		AutostartSchedule:  dbAutostartSchedule,
+		Ttl:                dbTTL,
+		ParameterValues:    createWorkspace.ParameterValues,
+		Enforcer:           *api.WorkspaceQuotaEnforcer.Load(),
+	})
+	if writeBudgetExceeded(ctx, rw, err) {
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = workspace
+
+	api.writeCreatedWorkspace(rw, r, workspace, workspaceBuild, provisionerJob, template)
+}
+
+// checkCanCreateWorkspace writes an error and returns false if the name is
+// taken or the owner has reached their workspace limit. The budget of the
+// owner is checked by the transaction that gives them the workspace, and the
+// quota of the organization separately.
+func (api *API) checkCanCreateWorkspace(rw http.ResponseWriter, r *http.Request, ownerID uuid.UUID, name string, template database.Template) bool {
+	ctx := r.Context()
+	_, err := api.Database.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+		OwnerID: ownerID,
+		Name:    name,
+	})
+	if err == nil {
+		// If the workspace already exists, don't allow creation.
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Workspace %q already exists.", name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "name",
+				Detail: "This value is already in use and should be unique.",
+			}},
+		})
+		return false
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: fmt.Sprintf("Internal error fetching workspace by name %q.", name),
+			Detail:  err.Error(),
+		})
+		return false
+	}
+
+	workspaceCount, err := api.Database.GetWorkspaceCountByUserID(ctx, ownerID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace count.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+
+	// make sure the user has not hit their quota limit
+	e := *api.WorkspaceQuotaEnforcer.Load()
+	canCreate := e.CanCreateWorkspace(int(workspaceCount))
+	if !canCreate {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("User workspace limit of %d is already reached.", e.UserWorkspaceLimit()),
+			Code:    codersdk.ErrorCodeQuotaExceeded,
+		})
+		return false
+	}
+	return true
+}
+
+type insertWorkspaceParams struct {
+	OwnerID            uuid.UUID
+	InitiatorID        uuid.UUID
+	Template           database.Template
+	TemplateVersion    database.TemplateVersion
+	TemplateVersionJob database.ProvisionerJob
+	Name               string
+	AutostartSchedule  sql.NullString
+	Ttl                sql.NullInt64
+	ParameterValues    []codersdk.CreateParameterRequest
+	// Enforcer checks the budget of the owner in the transaction.
+	Enforcer workspacequota.Enforcer
+}
+
+// insertWorkspace inserts a workspace with its parameter values and queues
+// the first build of it.
+func insertWorkspace(ctx context.Context, store database.Store, arg insertWorkspaceParams) (database.Workspace, database.WorkspaceBuild, database.ProvisionerJob, error) {
 	var (
+		workspace      database.Workspace
 		provisionerJob database.ProvisionerJob
 		workspaceBuild database.WorkspaceBuild
 	)
-	err = api.Database.InTx(func(db database.Store) error {
+	err := store.InTx(func(db database.Store) error {
+		err := arg.Enforcer.CheckBudget(ctx, db, arg.OwnerID, arg.Template)
+		if err != nil {
+			return xerrors.Errorf("check budget: %w", err)
+		}
+
 		now := database.Now()
 		workspaceBuildID := uuid.New()
 		// Workspaces are created without any versions.
@@ -382,17 +511,28 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			ID:                uuid.New(),
 			CreatedAt:         now,
 			UpdatedAt:         now,
-			OwnerID:           user.ID,
-			OrganizationID:    template.OrganizationID,
-			TemplateID:        template.ID,
-			Name:              createWorkspace.Name,
-			AutostartSchedule: dbAutostartSchedule,
-			Ttl:               dbTTL,
+			OwnerID:           arg.OwnerID,
+			OrganizationID:    arg.Template.OrganizationID,
+			TemplateID:        arg.Template.ID,
+			Name:              arg.Name,
+			AutostartSchedule: arg.AutostartSchedule,
+			Ttl:               arg.Ttl,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert workspace: %w", err)
 		}
-		for _, parameterValue := range createWorkspace.ParameterValues {
+		// The labels of the template apply from the start.
+		if len(convertLabels(arg.Template.WorkspaceLabels)) > 0 {
+			workspace, err = db.UpdateWorkspaceLabels(ctx, database.UpdateWorkspaceLabelsParams{
+				ID:        workspace.ID,
+				Labels:    arg.Template.WorkspaceLabels,
+				UpdatedAt: now,
+			})
+			if err != nil {
+				return xerrors.Errorf("update workspace labels: %w", err)
+			}
+		}
+		for _, parameterValue := range arg.ParameterValues {
 			// If the value is empty, we don't want to save it on database so
 			// Terraform can use the default value
 			if parameterValue.SourceValue == "" {
@@ -425,12 +565,12 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			ID:             uuid.New(),
 			CreatedAt:      now,
 			UpdatedAt:      now,
-			InitiatorID:    apiKey.UserID,
-			OrganizationID: template.OrganizationID,
-			Provisioner:    template.Provisioner,
+			InitiatorID:    arg.InitiatorID,
+			OrganizationID: arg.Template.OrganizationID,
+			Provisioner:    arg.Template.Provisioner,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
-			StorageMethod:  templateVersionJob.StorageMethod,
-			StorageSource:  templateVersionJob.StorageSource,
+			StorageMethod:  arg.TemplateVersionJob.StorageMethod,
+			StorageSource:  arg.TemplateVersionJob.StorageSource,
 			Input:          input,
 		})
 		if err != nil {
@@ -441,8 +581,8 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			CreatedAt:         now,
 			UpdatedAt:         now,
 			WorkspaceID:       workspace.ID,
-			TemplateVersionID: templateVersion.ID,
-			InitiatorID:       apiKey.UserID,
+			TemplateVersionID: arg.TemplateVersion.ID,
+			InitiatorID:       arg.InitiatorID,
 			Transition:        database.WorkspaceTransitionStart,
 			JobID:             provisionerJob.ID,
 			BuildNumber:       1,           // First build!
@@ -454,16 +594,14 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		}
 		return nil
 	})
-	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Internal error creating workspace.",
-			Detail:  err.Error(),
-		})
-		return
-	}
-	aReq.New = workspace
+	return workspace, workspaceBuild, provisionerJob, err
+}
 
-	users, err := api.Database.GetUsersByIDs(ctx, []uuid.UUID{user.ID, workspaceBuild.InitiatorID})
+// writeCreatedWorkspace reports and publishes a workspace created by
+// insertWorkspace, and writes it as the response.
+func (api *API) writeCreatedWorkspace(rw http.ResponseWriter, r *http.Request, workspace database.Workspace, workspaceBuild database.WorkspaceBuild, provisionerJob database.ProvisionerJob, template database.Template) {
+	ctx := r.Context()
+	users, err := api.Database.GetUsersByIDs(ctx, []uuid.UUID{workspace.OwnerID, workspaceBuild.InitiatorID})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error fetching user.",
@@ -495,11 +633,32 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	api.publishOrganizationEvent(workspace.OrganizationID, codersdk.OrganizationWebhookEvent{
+		Type:         codersdk.OrganizationWebhookEventWorkspaceCreated,
+		ResourceID:   workspace.ID,
+		ResourceName: workspace.Name,
+	})
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionCreated, workspace)
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:           codersdk.WebhookEventWorkspaceCreated,
+		OrganizationID: workspace.OrganizationID,
+		ResourceID:     workspace.ID,
+		ResourceName:   workspace.Name,
+	})
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:           codersdk.WebhookEventWorkspaceBuildCreated,
+		OrganizationID: workspace.OrganizationID,
+		ResourceID:     workspaceBuild.ID,
+		ResourceName:   workspace.Name,
+	})
+
 	httpapi.Write(ctx, rw, http.StatusCreated, convertWorkspace(
 		workspace,
 		apiBuild,
 		template,
-		findUser(user.ID, users),
+		findUser(workspace.OwnerID, users),
+		nil,
+		nil,
 	))
 }
 
@@ -542,6 +701,18 @@ func (api *API) patchWorkspace(rw http.ResponseWriter, r *http.Request) {
 		name = req.Name
 	}
 
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.checkWorkspaceNamePolicy(rw, r, workspace.OwnerID, name, template) {
+		return
+	}
+
 	newWorkspace, err := api.Database.UpdateWorkspace(ctx, database.UpdateWorkspaceParams{
 		ID:   workspace.ID,
 		Name: name,
@@ -578,6 +749,7 @@ func (api *API) patchWorkspace(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	aReq.New = newWorkspace
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, newWorkspace)
 	rw.WriteHeader(http.StatusNoContent)
 }
 
@@ -639,6 +811,7 @@ func (api *API) putWorkspaceAutostart(rw http.ResponseWriter, r *http.Request) {
 	newWorkspace := workspace
 	newWorkspace.AutostartSchedule = dbSched
 	aReq.New = newWorkspace
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, newWorkspace)
 
 	rw.WriteHeader(http.StatusNoContent)
 }
@@ -711,6 +884,7 @@ func (api *API) putWorkspaceTTL(rw http.ResponseWriter, r *http.Request) {
 	newWorkspace := workspace
 	newWorkspace.Ttl = dbTTL
 	aReq.New = newWorkspace
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, newWorkspace)
 
 	rw.WriteHeader(http.StatusNoContent)
 }
@@ -718,6 +892,7 @@ func (api *API) putWorkspaceTTL(rw http.ResponseWriter, r *http.Request) {
 func (api *API) putExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	workspace := httpmw.WorkspaceParam(r)
+	apiKey := httpmw.APIKey(r)
 
 	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
 		httpapi.ResourceNotFound(rw)
@@ -729,77 +904,479 @@ func (api *API) putExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	code := http.StatusOK
-	resp := codersdk.Response{}
+	_, code, resp, err := api.extendWorkspaceDeadline(ctx, workspace, apiKey.UserID, func(time.Time) time.Time {
+		return req.Deadline
+	})
+	if err != nil {
+		api.Logger.Info(ctx, "extending workspace", slog.Error(err))
+	} else {
+		api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	}
+	httpapi.Write(ctx, rw, code, resp)
+}
 
-	err := api.Database.InTx(func(s database.Store) error {
-		template, err := s.GetTemplateByID(ctx, workspace.TemplateID)
+// postWorkspaceTransfer moves a workspace to a template of another
+// organization, and rebuilds it with the active version of the template.
+func (api *API) postWorkspaceTransfer(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.TransferWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(workspace.OrganizationID)) ||
+		!api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization.InOrg(req.OrganizationID)) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Only admins of both organizations can transfer workspaces.",
+		})
+		return
+	}
+	if api.organizationDeleting(rw, r, req.OrganizationID) {
+		return
+	}
+	if req.OrganizationID == workspace.OrganizationID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The workspace is already in the organization.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "organization_id",
+				Detail: "Must be another organization.",
+			}},
+		})
+		return
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, req.TemplateID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if err != nil || template.Deleted || template.OrganizationID != req.OrganizationID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template %q isn't available in the organization.", req.TemplateID.String()),
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_id",
+				Detail: "template not found",
+			}},
+		})
+		return
+	}
+	if !api.checkTemplateDeprecation(rw, r, template) {
+		return
+	}
+
+	_, err = api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: req.OrganizationID,
+		UserID:         workspace.OwnerID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The owner of the workspace isn't a member of the organization.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization member.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// The owner must be allowed to use the template, as if they created the
+	// workspace from it.
+	owner, err := api.Database.GetAuthorizationUserRoles(ctx, workspace.OwnerID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	err = api.Authorizer.ByRoleName(ctx, owner.ID.String(), owner.Roles, rbac.ScopeAll, owner.Groups, owner.EveryoneExcludedOrgs, rbac.ActionRead, template.RBACObject())
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("The owner of the workspace can't use template %q.", template.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_id",
+				Detail: "The owner must be allowed to use the template.",
+			}},
+		})
+		return
+	}
+
+	build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	job, err := api.Database.GetProvisionerJobByID(ctx, build.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !job.CompletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The workspace can't be transferred while it's being built.",
+		})
+		return
+	}
+
+	// The workspace is rebuilt with the active version of the template, so
+	// its resources match what the template defines.
+	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersionJob, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if convertProvisionerJob(templateVersionJob).Status != codersdk.ProvisionerJobSucceeded {
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: fmt.Sprintf("The active version of template %q didn't import successfully.", template.Name),
+		})
+		return
+	}
+
+	// The parameters of the workspace must be allowed in the organization.
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, req.OrganizationID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	parameterValues, err := api.Database.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{workspace.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	createParameters := make([]codersdk.CreateParameterRequest, 0, len(parameterValues))
+	for _, parameterValue := range parameterValues {
+		createParameters = append(createParameters, codersdk.CreateParameterRequest{
+			Name:        parameterValue.Name,
+			SourceValue: parameterValue.SourceValue,
+		})
+	}
+	if validErrs := disallowedParameterValues(defaults.AllowedParameterValues, createParameters); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "The workspace has parameters the organization doesn't allow.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	// The workspace counts against the quotas of the organization as if it
+	// was created there.
+	if !api.checkOrganizationQuota(rw, r, template, uuid.Nil) {
+		return
+	}
+
+	var (
+		transferred database.Workspace
+		newBuild    database.WorkspaceBuild
+	)
+	err = api.Database.InTx(func(tx database.Store) error {
+		e := *api.WorkspaceQuotaEnforcer.Load()
+		err := e.CheckBudget(ctx, tx, workspace.OwnerID, template)
 		if err != nil {
-			code = http.StatusInternalServerError
-			resp.Message = "Error fetching workspace template!"
-			return xerrors.Errorf("get workspace template: %w", err)
+			return xerrors.Errorf("check budget: %w", err)
 		}
-
-		build, err := s.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+		transferred, err = tx.UpdateWorkspaceOrganization(ctx, database.UpdateWorkspaceOrganizationParams{
+			ID:             workspace.ID,
+			OrganizationID: req.OrganizationID,
+			TemplateID:     template.ID,
+			UpdatedAt:      database.Now(),
+		})
 		if err != nil {
-			code = http.StatusInternalServerError
-			resp.Message = "Error fetching workspace build."
-			return xerrors.Errorf("get latest workspace build: %w", err)
+			return xerrors.Errorf("update workspace organization: %w", err)
 		}
-
-		job, err := s.GetProvisionerJobByID(ctx, build.JobID)
+		err = tx.UpdateProvisionerJobsOrganizationByWorkspaceID(ctx, database.UpdateProvisionerJobsOrganizationByWorkspaceIDParams{
+			OrganizationID: req.OrganizationID,
+			WorkspaceID:    workspace.ID,
+		})
 		if err != nil {
-			code = http.StatusInternalServerError
-			resp.Message = "Error fetching workspace provisioner job."
-			return xerrors.Errorf("get provisioner job: %w", err)
+			return xerrors.Errorf("update provisioner jobs organization: %w", err)
 		}
+		// The workspace keeps its transition, so a stopped workspace stays
+		// stopped.
+		newBuild, err = insertWorkspaceBuild(ctx, tx, apiKey.UserID, transferred, build, templateVersion.ID, build.Transition, database.BuildReasonInitiator)
+		return err
+	})
+	if writeBudgetExceeded(ctx, rw, err) {
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusMethodNotAllowed, codersdk.Response{
+			Message: fmt.Sprintf("Workspace %q is deleted and cannot be transferred.", workspace.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error transferring workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = transferred
+	// Readers of the previous organization are notified too, so they find out
+	// the workspace is gone.
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, workspace)
+	api.publishWorkspaceEvent(ctx, codersdk.ResourceEventActionUpdated, transferred)
+	api.PublishWebhookEvent(codersdk.WebhookEvent{
+		Type:           codersdk.WebhookEventWorkspaceBuildCreated,
+		OrganizationID: transferred.OrganizationID,
+		ResourceID:     newBuild.ID,
+		ResourceName:   transferred.Name,
+	})
 
-		if build.Transition != database.WorkspaceTransitionStart {
-			code = http.StatusConflict
-			resp.Message = "Workspace must be started, current status: " + string(build.Transition)
-			return xerrors.Errorf("workspace must be started, current status: %s", build.Transition)
-		}
+	data, err := api.workspaceData(ctx, []database.Workspace{transferred})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspace(
+		transferred,
+		data.builds[0],
+		data.templates[0],
+		findUser(transferred.OwnerID, data.users),
+		data.maintenanceAt[transferred.ID],
+		data.deprecations[transferred.TemplateID],
+	))
+}
 
-		if !job.CompletedAt.Valid {
-			code = http.StatusConflict
-			resp.Message = "Workspace is still building!"
-			return xerrors.Errorf("workspace is still building")
-		}
+// cloneSourceParameterName is the parameter templates declare to copy the
+// persistent data of the workspace a clone is created from.
+const cloneSourceParameterName = "coder_workspace_clone_source"
 
-		if build.Deadline.IsZero() {
-			code = http.StatusConflict
-			resp.Message = "Workspace shutdown is manual."
-			return xerrors.Errorf("workspace shutdown is manual")
-		}
+// postWorkspaceClone creates a workspace for the requester from the template
+// version and parameter values of the latest build of another workspace.
+func (api *API) postWorkspaceClone(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		source            = httpmw.WorkspaceParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	if !api.Authorize(r, rbac.ActionRead, source) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionCreate,
+		rbac.ResourceWorkspace.InOrg(source.OrganizationID).WithOwner(apiKey.UserID.String())) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.CloneWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if api.organizationDeleting(rw, r, source.OrganizationID) {
+		return
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, source.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if template.Deleted || !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template of the workspace isn't available.",
+		})
+		return
+	}
 
-		newDeadline := req.Deadline.UTC()
-		if err := validWorkspaceDeadline(job.CompletedAt.Time, newDeadline, time.Duration(template.MaxTtl)); err != nil {
-			// NOTE(Cian): Putting the error in the Message field on request from the FE folks.
-			// Normally, we would put the validation error in Validations, but this endpoint is
-			// not tied to a form or specific named user input on the FE.
-			code = http.StatusBadRequest
-			resp.Message = "Cannot extend workspace: " + err.Error()
-			return err
+	build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, source.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, build.TemplateVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersionJob, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	parameterValues, err := api.Database.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{source.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	createParameters := make([]codersdk.CreateParameterRequest, 0, len(parameterValues)+1)
+	for _, parameterValue := range parameterValues {
+		// A clone of a clone copies from the workspace it's cloned from.
+		if parameterValue.Name == cloneSourceParameterName {
+			continue
 		}
+		createParameters = append(createParameters, codersdk.CreateParameterRequest{
+			Name:              parameterValue.Name,
+			SourceValue:       parameterValue.SourceValue,
+			SourceScheme:      codersdk.ParameterSourceScheme(parameterValue.SourceScheme),
+			DestinationScheme: codersdk.ParameterDestinationScheme(parameterValue.DestinationScheme),
+		})
+	}
 
-		if err := s.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
-			ID:               build.ID,
-			UpdatedAt:        build.UpdatedAt,
-			ProvisionerState: build.ProvisionerState,
-			Deadline:         newDeadline,
-		}); err != nil {
-			code = http.StatusInternalServerError
-			resp.Message = "Failed to extend workspace deadline."
-			return xerrors.Errorf("update workspace build: %w", err)
+	if req.CopyData {
+		schemas, err := api.Database.GetParameterSchemasByJobID(ctx, templateVersionJob.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching parameter schemas.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		var schema *database.ParameterSchema
+		for i := range schemas {
+			if schemas[i].Name == cloneSourceParameterName {
+				schema = &schemas[i]
+				break
+			}
 		}
-		resp.Message = "Deadline updated to " + newDeadline.Format(time.RFC3339) + "."
+		if schema == nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "The template doesn't support copying the data of workspaces.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "copy_data",
+					Detail: fmt.Sprintf("The template version doesn't declare the %q parameter.", cloneSourceParameterName),
+				}},
+			})
+			return
+		}
+		createParameters = append(createParameters, codersdk.CreateParameterRequest{
+			Name:              cloneSourceParameterName,
+			SourceValue:       source.ID.String(),
+			SourceScheme:      codersdk.ParameterSourceSchemeData,
+			DestinationScheme: codersdk.ParameterDestinationScheme(schema.DefaultDestinationScheme),
+		})
+	}
 
-		return nil
+	// The values were allowed when the source was created, but the
+	// organization may have restricted them since.
+	defaults, err := getOrganizationTemplateDefaults(ctx, api.Database, source.OrganizationID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if validErrs := disallowedParameterValues(defaults.AllowedParameterValues, createParameters); len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "The workspace has parameters the organization doesn't allow.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	if !api.checkTemplateDeprecation(rw, r, template) {
+		return
+	}
+	if !api.checkWorkspaceNamePolicy(rw, r, apiKey.UserID, req.Name, template) {
+		return
+	}
+	if !api.checkCanCreateWorkspace(rw, r, apiKey.UserID, req.Name, template) {
+		return
+	}
+	if !api.checkOrganizationQuota(rw, r, template, uuid.Nil) {
+		return
+	}
+
+	workspace, workspaceBuild, provisionerJob, err := insertWorkspace(ctx, api.Database, insertWorkspaceParams{
+		OwnerID:            apiKey.UserID,
+		InitiatorID:        apiKey.UserID,
+		Template:           template,
+		TemplateVersion:    templateVersion,
+		TemplateVersionJob: templateVersionJob,
+		Name:               req.Name,
+		AutostartSchedule:  source.AutostartSchedule,
+		Ttl:                source.Ttl,
+		ParameterValues:    createParameters,
+		Enforcer:           *api.WorkspaceQuotaEnforcer.Load(),
 	})
+	if writeBudgetExceeded(ctx, rw, err) {
+		return
+	}
 	if err != nil {
-		api.Logger.Info(ctx, "extending workspace", slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error cloning workspace.",
+			Detail:  err.Error(),
+		})
+		return
 	}
-	httpapi.Write(ctx, rw, code, resp)
+	aReq.New = workspace
+
+	api.writeCreatedWorkspace(rw, r, workspace, workspaceBuild, provisionerJob, template)
 }
 
 func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
@@ -860,6 +1437,8 @@ func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
 					data.builds[0],
 					data.templates[0],
 					findUser(workspace.OwnerID, data.users),
+					data.maintenanceAt[workspace.ID],
+					data.deprecations[workspace.TemplateID],
 				),
 			})
 		}
@@ -867,9 +1446,12 @@ func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
 }
 
 type workspaceData struct {
-	templates []database.Template
-	builds    []codersdk.WorkspaceBuild
-	users     []database.User
+	templates     []database.Template
+	builds        []codersdk.WorkspaceBuild
+	users         []database.User
+	maintenanceAt map[uuid.UUID]*time.Time
+	// deprecations are by template ID.
+	deprecations map[uuid.UUID]*codersdk.TemplateDeprecation
 }
 
 func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspace) (workspaceData, error) {
@@ -911,10 +1493,22 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		return workspaceData{}, xerrors.Errorf("convert workspace builds: %w", err)
 	}
 
+	maintenanceAt, err := api.workspacesMaintenanceAt(ctx, workspaces, apiBuilds, templates)
+	if err != nil {
+		return workspaceData{}, xerrors.Errorf("get workspaces maintenance: %w", err)
+	}
+
+	deprecations, err := api.templateDeprecations(ctx, templateIDs)
+	if err != nil {
+		return workspaceData{}, xerrors.Errorf("get template deprecations: %w", err)
+	}
+
 	return workspaceData{
-		templates: templates,
-		builds:    apiBuilds,
-		users:     data.users,
+		templates:     templates,
+		builds:        apiBuilds,
+		users:         data.users,
+		maintenanceAt: maintenanceAt,
+		deprecations:  deprecations,
 	}, nil
 }
 
@@ -952,6 +1546,8 @@ func convertWorkspaces(workspaces []database.Workspace, data workspaceData) ([]c
 			build,
 			template,
 			&owner,
+			data.maintenanceAt[workspace.ID],
+			data.deprecations[workspace.TemplateID],
 		))
 	}
 	sort.Slice(apiWorkspaces, func(i, j int) bool {
@@ -971,6 +1567,8 @@ func convertWorkspace(
 	workspaceBuild codersdk.WorkspaceBuild,
 	template database.Template,
 	owner *database.User,
+	maintenanceAt *time.Time,
+	deprecation *codersdk.TemplateDeprecation,
 ) codersdk.Workspace {
 	var autostartSchedule *string
 	if workspace.AutostartSchedule.Valid {
@@ -979,20 +1577,24 @@ func convertWorkspace(
 
 	ttlMillis := convertWorkspaceTTLMillis(workspace.Ttl)
 	return codersdk.Workspace{
-		ID:                workspace.ID,
-		CreatedAt:         workspace.CreatedAt,
-		UpdatedAt:         workspace.UpdatedAt,
-		OwnerID:           workspace.OwnerID,
-		OwnerName:         owner.Username,
-		TemplateID:        workspace.TemplateID,
-		LatestBuild:       workspaceBuild,
-		TemplateName:      template.Name,
-		TemplateIcon:      template.Icon,
-		Outdated:          workspaceBuild.TemplateVersionID.String() != template.ActiveVersionID.String(),
-		Name:              workspace.Name,
-		AutostartSchedule: autostartSchedule,
-		TTLMillis:         ttlMillis,
-		LastUsedAt:        workspace.LastUsedAt,
+		ID:                  workspace.ID,
+		CreatedAt:           workspace.CreatedAt,
+		UpdatedAt:           workspace.UpdatedAt,
+		OwnerID:             workspace.OwnerID,
+		OwnerName:           owner.Username,
+		TemplateID:          workspace.TemplateID,
+		LatestBuild:         workspaceBuild,
+		TemplateName:        template.Name,
+		TemplateIcon:        template.Icon,
+		Outdated:            workspaceBuild.TemplateVersionID.String() != template.ActiveVersionID.String(),
+		Name:                workspace.Name,
+		AutostartSchedule:   autostartSchedule,
+		TTLMillis:           ttlMillis,
+		LastUsedAt:          workspace.LastUsedAt,
+		ACL:                 convertWorkspaceACL(workspace),
+		Labels:              convertLabels(workspace.Labels),
+		MaintenanceAt:       maintenanceAt,
+		TemplateDeprecation: deprecation,
 	}
 }
 
@@ -1050,6 +1652,39 @@ func validWorkspaceDeadline(startedAt, newDeadline time.Time, max time.Duration)
 	return nil
 }
 
+// groupAutostopDeadline returns the earliest time a workspace build that
+// started at startedAt must stop to satisfy the autostop policies of the
+// owner's groups, or the zero time if none of the groups have a policy.
+// groups are those GetUserEffectiveGroups returns for the workspace's
+// organization, so policies of other organizations don't apply.
+//
+// Group policies take precedence over the workspace TTL and template max TTL,
+// but only ever shorten a build. See docs/workspaces.md.
+func groupAutostopDeadline(groups []database.Group, startedAt time.Time) time.Time {
+	var deadline time.Time
+	for _, group := range groups {
+		if group.MaxTtl > 0 {
+			limit := startedAt.Add(time.Duration(group.MaxTtl))
+			if deadline.IsZero() || limit.Before(deadline) {
+				deadline = limit
+			}
+		}
+		if group.AutostopSchedule == "" {
+			continue
+		}
+		sched, err := schedule.Weekly(group.AutostopSchedule)
+		if err != nil {
+			// Schedules are validated when they're set.
+			continue
+		}
+		limit := sched.Next(startedAt)
+		if deadline.IsZero() || limit.Before(deadline) {
+			deadline = limit
+		}
+	}
+	return deadline
+}
+
 func validWorkspaceSchedule(s *string, min time.Duration) (sql.NullString, error) {
 	if ptr.NilOrEmpty(s) {
 		return sql.NullString{}, nil
@@ -1101,6 +1736,11 @@ func workspaceSearchQuery(query string) (database.GetWorkspacesParams, []codersd
 				}
 			}
 		case 2:
+			// Workspaces must match every label.
+			if parts[0] == "label" {
+				searchParams.Add(parts[0], parts[1])
+				continue
+			}
 			searchParams.Set(parts[0], parts[1])
 		default:
 			return database.GetWorkspacesParams{}, []codersdk.ValidationError{
@@ -1119,6 +1759,26 @@ func workspaceSearchQuery(query string) (database.GetWorkspacesParams, []codersd
 		Name:          parser.String(searchParams, "", "name"),
 	}
 
+	if len(searchParams["label"]) > 0 {
+		labels := map[string]string{}
+		for _, label := range searchParams["label"] {
+			key, value, ok := strings.Cut(label, "=")
+			if !ok || key == "" {
+				return database.GetWorkspacesParams{}, []codersdk.ValidationError{
+					{Field: "q", Detail: fmt.Sprintf("Label %q must be in the form key=value", label)},
+				}
+			}
+			labels[key] = value
+		}
+		raw, err := json.Marshal(labels)
+		if err != nil {
+			return database.GetWorkspacesParams{}, []codersdk.ValidationError{
+				{Field: "q", Detail: err.Error()},
+			}
+		}
+		filter.Labels = raw
+	}
+
 	return filter, parser.Errors
 }
 
@@ -1148,3 +1808,43 @@ func splitQueryParameterByDelimiter(query string, delimiter rune, maintainQuotes
 
 	return parts
 }
+
+// writeBudgetExceeded writes an error and returns true if err is caused by
+// the owner not being able to afford another workspace from the template.
+func writeBudgetExceeded(ctx context.Context, rw http.ResponseWriter, err error) bool {
+	var budgetErr *workspacequota.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		return false
+	}
+	httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+		Message: fmt.Sprintf("Workspace quota budget of %d is exceeded.", budgetErr.Allowance),
+		Detail:  budgetErr.Error(),
+		Code:    codersdk.ErrorCodeQuotaExceeded,
+	})
+	return true
+}
+
+// checkOrganizationQuota writes an error and returns false if starting the
+// workspace would exceed the quota of the template's organization.
+func (api *API) checkOrganizationQuota(rw http.ResponseWriter, r *http.Request, template database.Template, workspaceID uuid.UUID) bool {
+	ctx := r.Context()
+	e := *api.WorkspaceQuotaEnforcer.Load()
+	err := e.CheckOrganizationQuota(ctx, template, workspaceID)
+	var quotaErr *workspacequota.OrganizationQuotaExceededError
+	if errors.As(err, &quotaErr) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Organization quota of %d %s is exceeded.", quotaErr.Allowance, quotaErr.Limit),
+			Detail:  quotaErr.Error(),
+			Code:    codersdk.ErrorCodeQuotaExceeded,
+		})
+		return false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error checking organization quota.",
+			Detail:  err.Error(),
+		})
+		return false
+	}
+	return true
+}
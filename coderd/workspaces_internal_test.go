@@ -1,9 +1,11 @@
 package coderd
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/coder/coder/coderd/database"
 
@@ -117,6 +119,13 @@ func TestSearchWorkspace(t *testing.T) {
 				OwnerUsername: "foo",
 			},
 		},
+		{
+			Name:  "Labels",
+			Query: `label:team=payments LABEL:Env=prod label:"dept=r&d 1"`,
+			Expected: database.GetWorkspacesParams{
+				Labels: json.RawMessage(`{"dept":"r\u0026d 1","env":"prod","team":"payments"}`),
+			},
+		},
 
 		// Failures
 		{
@@ -129,6 +138,11 @@ func TestSearchWorkspace(t *testing.T) {
 			Query:                 `owner:name:extra`,
 			ExpectedErrorContains: "can only contain 1 ':'",
 		},
+		{
+			Name:                  "LabelWithoutValue",
+			Query:                 `label:team`,
+			ExpectedErrorContains: "must be in the form key=value",
+		},
 	}
 
 	for _, c := range testCases {
@@ -150,3 +164,48 @@ func TestSearchWorkspace(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupAutostopDeadline(t *testing.T) {
+	t.Parallel()
+
+	// A Monday.
+	startedAt := time.Date(2022, 10, 3, 9, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		Name     string
+		Groups   []database.Group
+		Expected time.Time
+	}{
+		{
+			Name:     "NoPolicy",
+			Groups:   []database.Group{{}},
+			Expected: time.Time{},
+		},
+		{
+			Name:     "MaxTTL",
+			Groups:   []database.Group{{MaxTtl: int64(time.Hour)}},
+			Expected: startedAt.Add(time.Hour),
+		},
+		{
+			Name:     "Schedule",
+			Groups:   []database.Group{{AutostopSchedule: "0 18 * * 1-5"}},
+			Expected: time.Date(2022, 10, 3, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			Name: "Earliest",
+			Groups: []database.Group{
+				{MaxTtl: int64(12 * time.Hour)},
+				{AutostopSchedule: "0 18 * * 1-5"},
+				{MaxTtl: int64(10 * time.Hour)},
+			},
+			Expected: time.Date(2022, 10, 3, 18, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.Expected, groupAutostopDeadline(c.Groups, startedAt))
+		})
+	}
+}
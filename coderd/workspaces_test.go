@@ -16,6 +16,7 @@ import (
 	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
@@ -683,6 +684,18 @@ func TestWorkspaceFilterManual(t *testing.T) {
 		})
 		require.NoError(t, err)
 		require.Len(t, ws, 0)
+
+		// only the requested fields
+		ws, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{
+			Name:   workspace.Name,
+			Fields: []string{"id", "name"},
+		})
+		require.NoError(t, err)
+		require.Len(t, ws, 1)
+		require.Equal(t, workspace.ID, ws[0].ID)
+		require.Equal(t, workspace.Name, ws[0].Name)
+		require.Empty(t, ws[0].TemplateName)
+		require.Empty(t, ws[0].LatestBuild.ID)
 	})
 	t.Run("Template", func(t *testing.T) {
 		t.Parallel()
@@ -882,6 +895,41 @@ func TestPostWorkspaceBuild(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, workspaces, 0)
 	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			WorkspaceBuildRateLimit:  httpmw.RateLimitConfig{PerMinute: 1, Burst: 2},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		// Creating the workspace uses the first request.
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			TemplateVersionID: uuid.New(),
+			Transition:        codersdk.WorkspaceTransitionStart,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode())
+
+		// Other routes aren't affected.
+		_, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+	})
 }
 
 func TestWorkspaceUpdateAutostart(t *testing.T) {
@@ -1231,6 +1279,218 @@ func TestWorkspaceExtend(t *testing.T) {
 	require.WithinDuration(t, oldDeadline.Add(-time.Hour), updated.LatestBuild.Deadline.Time, time.Minute)
 }
 
+func TestWorkspaceClone(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		schema := func(name string) *proto.ParameterSchema {
+			return &proto.ParameterSchema{
+				Name:                name,
+				AllowOverrideSource: true,
+				DefaultSource: &proto.ParameterSource{
+					Scheme: proto.ParameterSource_DATA,
+					Value:  "default",
+				},
+				DefaultDestination: &proto.ParameterDestination{
+					Scheme: proto.ParameterDestination_PROVISIONER_VARIABLE,
+				},
+			}
+		}
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: []*proto.Parse_Response{{
+				Type: &proto.Parse_Response_Complete{
+					Complete: &proto.Parse_Complete{
+						ParameterSchemas: []*proto.ParameterSchema{
+							schema("region"),
+							schema("coder_workspace_clone_source"),
+						},
+					},
+				},
+			}},
+			Provision: echo.ProvisionComplete,
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.ParameterValues = []codersdk.CreateParameterRequest{{
+				Name:              "region",
+				SourceValue:       "eu",
+				SourceScheme:      codersdk.ParameterSourceSchemeData,
+				DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
+			}}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		clone, err := client.CloneWorkspace(ctx, workspace.ID, codersdk.CloneWorkspaceRequest{
+			Name:     "clone",
+			CopyData: true,
+		})
+		require.NoError(t, err)
+		require.NotEqual(t, workspace.ID, clone.ID)
+		require.Equal(t, version.ID, clone.LatestBuild.TemplateVersionID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+
+		params, err := api.Database.ParameterValues(ctx, database.ParameterValuesParams{
+			Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+			ScopeIds: []uuid.UUID{clone.ID},
+		})
+		require.NoError(t, err)
+		values := map[string]string{}
+		for _, param := range params {
+			values[param.Name] = param.SourceValue
+		}
+		require.Equal(t, map[string]string{
+			"region":                       "eu",
+			"coder_workspace_clone_source": workspace.ID.String(),
+		}, values)
+
+		// The name is unique per owner.
+		_, err = client.CloneWorkspace(ctx, workspace.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("CopyDataUnsupported", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CloneWorkspace(ctx, workspace.ID, codersdk.CloneWorkspaceRequest{
+			Name:     "clone",
+			CopyData: true,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "copy_data", apiErr.Validations[0].Field)
+	})
+}
+
+func TestWorkspaceTransfer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		otherVersion := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, otherVersion.ID)
+		otherTemplate := coderdtest.CreateTemplate(t, client, org.ID, otherVersion.ID)
+
+		transferred, err := client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			OrganizationID: org.ID,
+			TemplateID:     otherTemplate.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, workspace.ID, transferred.ID)
+		require.Equal(t, otherTemplate.ID, transferred.TemplateID)
+
+		// The workspace is rebuilt with the active version of the new
+		// template.
+		require.NotEqual(t, workspace.LatestBuild.ID, transferred.LatestBuild.ID)
+		require.Equal(t, otherVersion.ID, transferred.LatestBuild.TemplateVersionID)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, transferred.LatestBuild.Transition)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, transferred.LatestBuild.ID)
+
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		require.Equal(t, otherVersion.ID, build.TemplateVersionID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+	})
+
+	t.Run("OwnerNotMember", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		otherVersion := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, otherVersion.ID)
+		otherTemplate := coderdtest.CreateTemplate(t, client, org.ID, otherVersion.ID)
+
+		_, err = client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			OrganizationID: org.ID,
+			TemplateID:     otherTemplate.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("TemplateNotInOrganization", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+
+		_, err = client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			OrganizationID: org.ID,
+			TemplateID:     template.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "template_id", apiErr.Validations[0].Field)
+	})
+}
+
 func TestWorkspaceWatcher(t *testing.T) {
 	t.Parallel()
 	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
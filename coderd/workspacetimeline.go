@@ -0,0 +1,281 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	workspaceTimelineDefaultRange = 24 * time.Hour
+	workspaceTimelineDefaultLimit = 100
+	workspaceTimelineMaxLimit     = 1000
+)
+
+// workspaceTimeline merges the builds of a workspace, the extensions of its
+// autostop deadline, and its timeline events, newest first.
+func (api *API) workspaceTimeline(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+		query     = r.URL.Query()
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	parseTime := func(v string) (time.Time, error) {
+		return time.Parse(time.RFC3339, v)
+	}
+	parser := httpapi.NewQueryParamParser()
+	endTime := httpapi.ParseCustom(parser, query, database.Now(), "end_time", parseTime)
+	startTime := httpapi.ParseCustom(parser, query, endTime.Add(-workspaceTimelineDefaultRange), "start_time", parseTime)
+	limit := parser.Int(query, workspaceTimelineDefaultLimit, "limit")
+	if limit < 1 || limit > workspaceTimelineMaxLimit {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "limit",
+			Detail: fmt.Sprintf("Query param %q must be between 1 and %d", "limit", workspaceTimelineMaxLimit),
+		})
+	}
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+	if !startTime.Before(endTime) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The start time must be before the end time.",
+		})
+		return
+	}
+
+	builds, err := api.Database.GetWorkspaceBuildsByWorkspaceID(ctx, database.GetWorkspaceBuildsByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+		Since:       startTime,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	jobIDs := make([]uuid.UUID, 0, len(builds))
+	for _, build := range builds {
+		jobIDs = append(jobIDs, build.JobID)
+	}
+	jobs, err := api.Database.GetProvisionerJobsByIDs(ctx, jobIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	jobsByID := map[uuid.UUID]database.ProvisionerJob{}
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+	extensions, err := api.Database.GetWorkspaceAutostopExtensionsByWorkspaceID(ctx, database.GetWorkspaceAutostopExtensionsByWorkspaceIDParams{
+		WorkspaceID:  workspace.ID,
+		CreatedAfter: startTime,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	events, err := api.Database.GetWorkspaceTimelineEventsByWorkspaceID(ctx, database.GetWorkspaceTimelineEventsByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		LimitOpt:    int32(limit),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0)
+	agentNames := map[uuid.UUID]string{}
+	for _, build := range builds {
+		userIDs = append(userIDs, build.InitiatorID)
+	}
+	for _, extension := range extensions {
+		userIDs = append(userIDs, extension.UserID)
+	}
+	for _, event := range events {
+		if event.UserID.Valid {
+			userIDs = append(userIDs, event.UserID.UUID)
+		}
+		if _, ok := agentNames[event.AgentID]; ok {
+			continue
+		}
+		agent, err := api.Database.GetWorkspaceAgentByID(ctx, event.AgentID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		agentNames[event.AgentID] = agent.Name
+	}
+	users, err := api.Database.GetUsersByIDs(ctx, userIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	usernames := map[uuid.UUID]string{}
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	timeline := codersdk.WorkspaceTimeline{
+		WorkspaceID: workspace.ID,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Entries:     make([]codersdk.WorkspaceTimelineEntry, 0, len(builds)+len(extensions)+len(events)),
+	}
+	for _, build := range builds {
+		if !build.CreatedAt.Before(endTime) {
+			continue
+		}
+		timeline.Entries = append(timeline.Entries, convertWorkspaceTimelineBuild(build, jobsByID[build.JobID], usernames[build.InitiatorID]))
+	}
+	for _, extension := range extensions {
+		if !extension.CreatedAt.Before(endTime) {
+			continue
+		}
+		userID := extension.UserID
+		extended := time.Duration(extension.Extension)
+		description := fmt.Sprintf("The autostop deadline was extended by %s.", extended)
+		if username := usernames[userID]; username != "" {
+			description = fmt.Sprintf("%s extended the autostop deadline by %s.", username, extended)
+		}
+		timeline.Entries = append(timeline.Entries, codersdk.WorkspaceTimelineEntry{
+			Time:                    extension.CreatedAt,
+			Type:                    codersdk.WorkspaceTimelineEntryTypeAutostopExtended,
+			Description:             description,
+			UserID:                  &userID,
+			Username:                usernames[userID],
+			AutostopExtensionMillis: extended.Milliseconds(),
+		})
+	}
+	for _, event := range events {
+		timeline.Entries = append(timeline.Entries, convertWorkspaceTimelineEvent(event, agentNames[event.AgentID], usernames))
+	}
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		return timeline.Entries[i].Time.After(timeline.Entries[j].Time)
+	})
+	if len(timeline.Entries) > limit {
+		timeline.Entries = timeline.Entries[:limit]
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, timeline)
+}
+
+// recordWorkspaceTimelineEvent adds an event to the timeline of a workspace.
+// Failing to record an event doesn't fail what it records, so errors are
+// only logged.
+func (api *API) recordWorkspaceTimelineEvent(ctx context.Context, event database.InsertWorkspaceTimelineEventParams) {
+	event.ID = uuid.New()
+	event.CreatedAt = database.Now()
+	_, err := api.Database.InsertWorkspaceTimelineEvent(ctx, event)
+	if err != nil {
+		api.Logger.Warn(ctx, "record workspace timeline event",
+			slog.F("workspace_id", event.WorkspaceID),
+			slog.F("type", event.Type),
+			slog.Error(err),
+		)
+	}
+}
+
+func convertWorkspaceTimelineBuild(build database.WorkspaceBuild, job database.ProvisionerJob, username string) codersdk.WorkspaceTimelineEntry {
+	buildID := build.ID
+	initiatorID := build.InitiatorID
+	status := convertProvisionerJob(job).Status
+
+	verb := map[database.WorkspaceTransition]string{
+		database.WorkspaceTransitionStart:  "started",
+		database.WorkspaceTransitionStop:   "stopped",
+		database.WorkspaceTransitionDelete: "deleted",
+	}[build.Transition]
+	description := fmt.Sprintf("Build #%d %s the workspace", build.BuildNumber, verb)
+	switch build.Reason {
+	case database.BuildReasonAutostart:
+		description += " on its autostart schedule"
+	case database.BuildReasonAutostop:
+		description += " when its autostop deadline passed"
+	case database.BuildReasonMaintenance:
+		description += " during a maintenance window"
+	case database.BuildReasonAgentFailure:
+		description += " because its agents failed repeatedly"
+	default:
+		if username != "" {
+			description += " for " + username
+		}
+	}
+	description += "."
+	switch status {
+	case codersdk.ProvisionerJobFailed:
+		description += " The build failed."
+	case codersdk.ProvisionerJobCanceled, codersdk.ProvisionerJobCanceling:
+		description += " The build was canceled."
+	case codersdk.ProvisionerJobPending, codersdk.ProvisionerJobRunning:
+		description += " The build is in progress."
+	}
+
+	return codersdk.WorkspaceTimelineEntry{
+		Time:        build.CreatedAt,
+		Type:        codersdk.WorkspaceTimelineEntryTypeBuild,
+		Description: description,
+		UserID:      &initiatorID,
+		Username:    username,
+		BuildID:     &buildID,
+		BuildNumber: build.BuildNumber,
+		Transition:  codersdk.WorkspaceTransition(build.Transition),
+		BuildReason: codersdk.BuildReason(build.Reason),
+		JobStatus:   status,
+	}
+}
+
+func convertWorkspaceTimelineEvent(event database.WorkspaceTimelineEvent, agentName string, usernames map[uuid.UUID]string) codersdk.WorkspaceTimelineEntry {
+	agentID := event.AgentID
+	entry := codersdk.WorkspaceTimelineEntry{
+		Time:      event.CreatedAt,
+		Type:      codersdk.WorkspaceTimelineEntryType(event.Type),
+		AgentID:   &agentID,
+		AgentName: agentName,
+		AppName:   event.AppName,
+	}
+	if event.UserID.Valid {
+		userID := event.UserID.UUID
+		entry.UserID = &userID
+		entry.Username = usernames[userID]
+	}
+
+	agent := "An agent"
+	if agentName != "" {
+		agent = fmt.Sprintf("Agent %q", agentName)
+	}
+	switch event.Type {
+	case database.WorkspaceTimelineEventTypeAgentConnected:
+		entry.Description = agent + " connected."
+	case database.WorkspaceTimelineEventTypeAgentDisconnected:
+		entry.Description = agent + " disconnected."
+	case database.WorkspaceTimelineEventTypeAppOpened:
+		entry.Description = fmt.Sprintf("App %q was opened.", event.AppName)
+		if entry.Username != "" {
+			entry.Description = fmt.Sprintf("%s opened app %q.", entry.Username, event.AppName)
+		}
+	}
+	return entry
+}
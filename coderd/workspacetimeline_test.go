@@ -0,0 +1,169 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceTimeline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:           echo.ParseComplete,
+			ProvisionDryRun: echo.ProvisionComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id:   uuid.NewString(),
+								Name: "dev",
+								Auth: &proto.Agent_Token{
+									Token: authToken,
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		agentClient := codersdk.New(client.URL)
+		agentClient.SessionToken = authToken
+		agentCloser := agent.New(agent.Options{
+			FetchMetadata:     agentClient.WorkspaceAgentMetadata,
+			CoordinatorDialer: agentClient.ListenWorkspaceAgentTailnet,
+			Logger:            slogtest.Make(t, nil).Named("agent").Leveled(slog.LevelDebug),
+		})
+		defer func() {
+			_ = agentCloser.Close()
+		}()
+		resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+		agentID := resources[0].Agents[0].ID
+
+		ctx, _ := testutil.Context(t)
+		_, err := api.Database.InsertWorkspaceAutostopExtension(ctx, database.InsertWorkspaceAutostopExtensionParams{
+			ID:          uuid.New(),
+			WorkspaceID: workspace.ID,
+			UserID:      user.UserID,
+			Extension:   int64(time.Hour),
+			CreatedAt:   database.Now(),
+		})
+		require.NoError(t, err)
+
+		var timeline codersdk.WorkspaceTimeline
+		entries := map[codersdk.WorkspaceTimelineEntryType]codersdk.WorkspaceTimelineEntry{}
+		require.Eventually(t, func() bool {
+			timeline, err = client.WorkspaceTimeline(ctx, workspace.ID, codersdk.WorkspaceTimelineRequest{})
+			if err != nil {
+				return false
+			}
+			for _, entry := range timeline.Entries {
+				entries[entry.Type] = entry
+			}
+			_, ok := entries[codersdk.WorkspaceTimelineEntryTypeAgentConnected]
+			return ok
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, workspace.ID, timeline.WorkspaceID)
+		require.Len(t, timeline.Entries, 3)
+		for i := 1; i < len(timeline.Entries); i++ {
+			require.False(t, timeline.Entries[i].Time.After(timeline.Entries[i-1].Time), "entries are newest first")
+		}
+
+		build := entries[codersdk.WorkspaceTimelineEntryTypeBuild]
+		require.Equal(t, workspace.LatestBuild.ID, *build.BuildID)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, build.Transition)
+		require.Equal(t, codersdk.BuildReasonInitiator, build.BuildReason)
+		require.Equal(t, codersdk.ProvisionerJobSucceeded, build.JobStatus)
+		require.Equal(t, coderdtest.FirstUserParams.Username, build.Username)
+
+		extended := entries[codersdk.WorkspaceTimelineEntryTypeAutostopExtended]
+		require.EqualValues(t, time.Hour.Milliseconds(), extended.AutostopExtensionMillis)
+		require.Equal(t, user.UserID, *extended.UserID)
+
+		connected := entries[codersdk.WorkspaceTimelineEntryTypeAgentConnected]
+		require.Equal(t, agentID, *connected.AgentID)
+		require.Equal(t, "dev", connected.AgentName)
+		require.Equal(t, `Agent "dev" connected.`, connected.Description)
+
+		// Only the newest entries up to the limit are returned.
+		newest := timeline.Entries[0]
+		timeline, err = client.WorkspaceTimeline(ctx, workspace.ID, codersdk.WorkspaceTimelineRequest{
+			Limit: 1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.WorkspaceTimelineEntry{newest}, timeline.Entries)
+
+		// Entries outside of the range aren't returned.
+		timeline, err = client.WorkspaceTimeline(ctx, workspace.ID, codersdk.WorkspaceTimelineRequest{
+			StartTime: time.Now().Add(-48 * time.Hour),
+			EndTime:   time.Now().Add(-24 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Empty(t, timeline.Entries)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		for _, req := range []codersdk.WorkspaceTimelineRequest{
+			{Limit: 1001},
+			{StartTime: time.Now(), EndTime: time.Now().Add(-time.Hour)},
+		} {
+			_, err := client.WorkspaceTimeline(ctx, workspace.ID, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.WorkspaceTimeline(ctx, workspace.ID, codersdk.WorkspaceTimelineRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,145 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// workspaceAgentUsageRetention is how long usage samples are kept.
+const workspaceAgentUsageRetention = 24 * time.Hour
+
+// workspaceUsage returns the usage samples of the agents of the latest build
+// of a workspace.
+func (api *API) workspaceUsage(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+		query     = r.URL.Query()
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	parser := httpapi.NewQueryParamParser()
+	startTime := httpapi.ParseCustom(parser, query, database.Now().Add(-workspaceAgentUsageRetention), "start_time", func(v string) (time.Time, error) {
+		return time.Parse(time.RFC3339, v)
+	})
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+
+	build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(ctx, build.JobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	agentIDs := make([]uuid.UUID, 0, len(agents))
+	for _, agent := range agents {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	samples, err := api.Database.GetWorkspaceAgentUsageSamplesByAgentIDs(ctx, database.GetWorkspaceAgentUsageSamplesByAgentIDsParams{
+		IDs:          agentIDs,
+		CreatedAfter: startTime,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	samplesByAgentID := map[uuid.UUID][]codersdk.WorkspaceAgentUsageSample{}
+	for _, sample := range samples {
+		samplesByAgentID[sample.AgentID] = append(samplesByAgentID[sample.AgentID], convertWorkspaceAgentUsageSample(sample))
+	}
+
+	usage := codersdk.WorkspaceUsage{
+		WorkspaceID: workspace.ID,
+		Agents:      make([]codersdk.WorkspaceAgentUsage, 0, len(agents)),
+	}
+	for _, agent := range agents {
+		agentSamples := samplesByAgentID[agent.ID]
+		if agentSamples == nil {
+			agentSamples = []codersdk.WorkspaceAgentUsageSample{}
+		}
+		usage.Agents = append(usage.Agents, codersdk.WorkspaceAgentUsage{
+			AgentID:   agent.ID,
+			AgentName: agent.Name,
+			Samples:   agentSamples,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, usage)
+}
+
+// insertWorkspaceAgentUsageSample stores a usage sample reported by an agent,
+// and purges its samples that are no longer retained. Failing to store a
+// sample doesn't fail the stats report, so errors are only logged.
+func (api *API) insertWorkspaceAgentUsageSample(ctx context.Context, agentID uuid.UUID, usage codersdk.AgentUsage) {
+	now := database.Now()
+	_, err := api.Database.InsertWorkspaceAgentUsageSample(ctx, database.InsertWorkspaceAgentUsageSampleParams{
+		ID:          uuid.New(),
+		AgentID:     agentID,
+		CreatedAt:   now,
+		CPUUsed:     usage.CPUUsed,
+		CPUTotal:    usage.CPUTotal,
+		MemoryUsed:  usage.MemoryUsedBytes,
+		MemoryTotal: usage.MemoryTotalBytes,
+		DiskUsed:    usage.DiskUsedBytes,
+		DiskTotal:   usage.DiskTotalBytes,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "insert workspace agent usage sample", slog.F("agent_id", agentID), slog.Error(err))
+		return
+	}
+	err = api.Database.DeleteWorkspaceAgentUsageSamplesBefore(ctx, database.DeleteWorkspaceAgentUsageSamplesBeforeParams{
+		AgentID:       agentID,
+		CreatedBefore: now.Add(-workspaceAgentUsageRetention),
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "delete old workspace agent usage samples", slog.F("agent_id", agentID), slog.Error(err))
+	}
+}
+
+func convertWorkspaceAgentUsageSample(sample database.WorkspaceAgentUsageSample) codersdk.WorkspaceAgentUsageSample {
+	return codersdk.WorkspaceAgentUsageSample{
+		Time: sample.CreatedAt,
+		AgentUsage: codersdk.AgentUsage{
+			CPUUsed:          sample.CPUUsed,
+			CPUTotal:         sample.CPUTotal,
+			MemoryUsedBytes:  sample.MemoryUsed,
+			MemoryTotalBytes: sample.MemoryTotal,
+			DiskUsedBytes:    sample.DiskUsed,
+			DiskTotalBytes:   sample.DiskTotal,
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package coderd_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceUsage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+			IncludeProvisionerDaemon:  true,
+			AgentStatsRefreshInterval: testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:           echo.ParseComplete,
+			ProvisionDryRun: echo.ProvisionComplete,
+			Provision: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id:   uuid.NewString(),
+								Name: "dev",
+								Auth: &proto.Agent_Token{
+									Token: authToken,
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		ctx, _ := testutil.Context(t)
+		build, err := client.WorkspaceBuild(ctx, workspace.LatestBuild.ID)
+		require.NoError(t, err)
+		agentID := build.Resources[0].Agents[0].ID
+
+		// A sample older than the retained window is purged once the agent
+		// reports its usage.
+		_, err = api.Database.InsertWorkspaceAgentUsageSample(ctx, database.InsertWorkspaceAgentUsageSampleParams{
+			ID:        uuid.New(),
+			AgentID:   agentID,
+			CreatedAt: database.Now().Add(-25 * time.Hour),
+		})
+		require.NoError(t, err)
+		usage, err := client.WorkspaceUsage(ctx, workspace.ID, codersdk.WorkspaceUsageRequest{
+			StartTime: time.Now().Add(-48 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Len(t, usage.Agents, 1)
+		require.Len(t, usage.Agents[0].Samples, 1)
+
+		agentClient := codersdk.New(client.URL)
+		agentClient.SessionToken = authToken
+		agentCloser := agent.New(agent.Options{
+			FetchMetadata:     agentClient.WorkspaceAgentMetadata,
+			CoordinatorDialer: agentClient.ListenWorkspaceAgentTailnet,
+			StatsReporter:     agentClient.AgentReportStats,
+			Logger:            slogtest.Make(t, nil).Named("agent").Leveled(slog.LevelDebug),
+		})
+		defer func() {
+			_ = agentCloser.Close()
+		}()
+
+		require.Eventually(t, func() bool {
+			usage, err = client.WorkspaceUsage(ctx, workspace.ID, codersdk.WorkspaceUsageRequest{
+				StartTime: time.Now().Add(-48 * time.Hour),
+			})
+			if err != nil || len(usage.Agents) != 1 {
+				return false
+			}
+			samples := usage.Agents[0].Samples
+			return len(samples) >= 2 && samples[0].CPUTotal > 0
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, workspace.ID, usage.WorkspaceID)
+		require.Equal(t, agentID, usage.Agents[0].AgentID)
+		require.Equal(t, "dev", usage.Agents[0].AgentName)
+		samples := usage.Agents[0].Samples
+		for i, sample := range samples {
+			require.WithinDuration(t, time.Now(), sample.Time, time.Hour)
+			require.Positive(t, sample.MemoryTotalBytes)
+			require.LessOrEqual(t, sample.MemoryUsedBytes, sample.MemoryTotalBytes)
+			require.Positive(t, sample.DiskTotalBytes)
+			require.LessOrEqual(t, sample.DiskUsedBytes, sample.DiskTotalBytes)
+			require.LessOrEqual(t, sample.CPUUsed, sample.CPUTotal)
+			if i > 0 {
+				require.False(t, sample.Time.Before(samples[i-1].Time), "samples are oldest first")
+			}
+		}
+
+		// Only samples after the start time are returned.
+		usage, err = client.WorkspaceUsage(ctx, workspace.ID, codersdk.WorkspaceUsageRequest{
+			StartTime: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Len(t, usage.Agents, 1)
+		require.Empty(t, usage.Agents[0].Samples)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		res, err := client.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/usage?start_time=yesterday", workspace.ID), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.WorkspaceUsage(ctx, workspace.ID, codersdk.WorkspaceUsageRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
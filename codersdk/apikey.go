@@ -13,13 +13,42 @@ import (
 type APIKey struct {
 	ID string `json:"id" validate:"required"`
 	// NOTE: do not ever return the HashedSecret
-	UserID          uuid.UUID `json:"user_id" validate:"required"`
-	LastUsed        time.Time `json:"last_used" validate:"required"`
-	ExpiresAt       time.Time `json:"expires_at" validate:"required"`
-	CreatedAt       time.Time `json:"created_at" validate:"required"`
-	UpdatedAt       time.Time `json:"updated_at" validate:"required"`
-	LoginType       LoginType `json:"login_type" validate:"required"`
-	LifetimeSeconds int64     `json:"lifetime_seconds" validate:"required"`
+	UserID          uuid.UUID   `json:"user_id" validate:"required"`
+	LastUsed        time.Time   `json:"last_used" validate:"required"`
+	ExpiresAt       time.Time   `json:"expires_at" validate:"required"`
+	CreatedAt       time.Time   `json:"created_at" validate:"required"`
+	UpdatedAt       time.Time   `json:"updated_at" validate:"required"`
+	LoginType       LoginType   `json:"login_type" validate:"required"`
+	LifetimeSeconds int64       `json:"lifetime_seconds" validate:"required"`
+	Scope           APIKeyScope `json:"scope" validate:"required"`
+	// Permissions and OrganizationID are only set for restricted keys.
+	Permissions    []string   `json:"permissions,omitempty"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+}
+
+type APIKeyScope string
+
+const (
+	// APIKeyScopeAll allows the key to do everything the user can.
+	APIKeyScopeAll APIKeyScope = "all"
+	// APIKeyScopeApplicationConnect only allows the key to connect to
+	// workspace applications.
+	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
+	// APIKeyScopeRestricted only allows the key to use the permissions it was
+	// created with.
+	APIKeyScopeRestricted APIKeyScope = "restricted"
+)
+
+type CreateTokenRequest struct {
+	// Scope defaults to "all", or "restricted" if Permissions is set.
+	Scope APIKeyScope `json:"scope,omitempty"`
+	// Permissions are "<resource>:<action>" pairs a restricted token is
+	// allowed to use, e.g. "workspace:read" or "template:*". The user's roles
+	// must still allow the action.
+	Permissions []string `json:"permissions,omitempty"`
+	// OrganizationID limits the permissions of a restricted token to objects
+	// in a single organization.
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
 }
 
 type LoginType string
@@ -32,8 +61,8 @@ const (
 )
 
 // CreateToken generates an API key that doesn't expire.
-func (c *Client) CreateToken(ctx context.Context, userID string) (*GenerateAPIKeyResponse, error) {
-	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/keys/tokens", userID), nil)
+func (c *Client) CreateToken(ctx context.Context, userID string, req CreateTokenRequest) (*GenerateAPIKeyResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/keys/tokens", userID), req)
 	if err != nil {
 		return nil, err
 	}
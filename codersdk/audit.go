@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/netip"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,13 +15,15 @@ import (
 type ResourceType string
 
 const (
-	ResourceTypeOrganization    ResourceType = "organization"
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeGitSSHKey       ResourceType = "git_ssh_key"
-	ResourceTypeAPIKey          ResourceType = "api_key"
+	ResourceTypeOrganization       ResourceType = "organization"
+	ResourceTypeTemplate           ResourceType = "template"
+	ResourceTypeTemplateVersion    ResourceType = "template_version"
+	ResourceTypeUser               ResourceType = "user"
+	ResourceTypeWorkspace          ResourceType = "workspace"
+	ResourceTypeGitSSHKey          ResourceType = "git_ssh_key"
+	ResourceTypeAPIKey             ResourceType = "api_key"
+	ResourceTypeGroupMember        ResourceType = "group_member"
+	ResourceTypeOrganizationMember ResourceType = "organization_member"
 )
 
 func (r ResourceType) FriendlyString() string {
@@ -39,6 +42,10 @@ func (r ResourceType) FriendlyString() string {
 		return "git ssh key"
 	case ResourceTypeAPIKey:
 		return "api key"
+	case ResourceTypeGroupMember:
+		return "group member"
+	case ResourceTypeOrganizationMember:
+		return "organization member"
 	default:
 		return "unknown"
 	}
@@ -50,6 +57,7 @@ const (
 	AuditActionCreate AuditAction = "create"
 	AuditActionWrite  AuditAction = "write"
 	AuditActionDelete AuditAction = "delete"
+	AuditActionDeny   AuditAction = "deny"
 )
 
 func (a AuditAction) FriendlyString() string {
@@ -60,6 +68,8 @@ func (a AuditAction) FriendlyString() string {
 		return "updated"
 	case AuditActionDelete:
 		return "deleted"
+	case AuditActionDeny:
+		return "was denied access to"
 	default:
 		return "unknown"
 	}
@@ -96,11 +106,16 @@ type AuditLog struct {
 
 type AuditLogsRequest struct {
 	SearchQuery string `json:"q,omitempty"`
-	Pagination
+	CursorPagination
+	// Offset skips the given number of audit logs. It's ignored when Cursor
+	// is set, which is faster for deep pages.
+	Offset int `json:"offset,omitempty"`
 }
 
 type AuditLogResponse struct {
 	AuditLogs []AuditLog `json:"audit_logs"`
+	// NextCursor requests the next page. It's empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type AuditLogCountRequest struct {
@@ -115,17 +130,22 @@ type CreateTestAuditLogRequest struct {
 	Action       AuditAction  `json:"action,omitempty"`
 	ResourceType ResourceType `json:"resource_type,omitempty"`
 	ResourceID   uuid.UUID    `json:"resource_id,omitempty"`
+	// OrganizationID is the organization the audit log belongs to.
+	OrganizationID uuid.UUID `json:"organization_id,omitempty"`
 }
 
 // AuditLogs retrieves audit logs from the given page.
 func (c *Client) AuditLogs(ctx context.Context, req AuditLogsRequest) (AuditLogResponse, error) {
-	res, err := c.Request(ctx, http.MethodGet, "/api/v2/audit", nil, req.Pagination.asRequestOption(), func(r *http.Request) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/audit", nil, req.CursorPagination.asRequestOption(), func(r *http.Request) {
 		q := r.URL.Query()
 		var params []string
 		if req.SearchQuery != "" {
 			params = append(params, req.SearchQuery)
 		}
 		q.Set("q", strings.Join(params, " "))
+		if req.Offset > 0 {
+			q.Set("offset", strconv.Itoa(req.Offset))
+		}
 		r.URL.RawQuery = q.Encode()
 	})
 	if err != nil {
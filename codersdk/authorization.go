@@ -68,3 +68,32 @@ func (c *Client) CheckAuthorization(ctx context.Context, req AuthorizationReques
 	var resp AuthorizationResponse
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
+
+// AuthorizationPermissions are the actions the authenticated user can
+// perform on an object.
+type AuthorizationPermissions struct {
+	Object AuthorizationObject `json:"object"`
+	// Actions is a subset of 'create', 'read', 'update', and 'delete'.
+	Actions []string `json:"actions"`
+}
+
+// AuthorizationPermissions returns the actions the authenticated user can
+// perform on the object, factoring in their roles, groups, and the API key
+// scope. Objects referenced by a missing resource ID have no actions.
+func (c *Client) AuthorizationPermissions(ctx context.Context, object AuthorizationObject) (AuthorizationPermissions, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/authcheck/permissions", nil,
+		WithQueryParam("resource_type", object.ResourceType),
+		WithQueryParam("owner_id", object.OwnerID),
+		WithQueryParam("organization_id", object.OrganizationID),
+		WithQueryParam("resource_id", object.ResourceID),
+	)
+	if err != nil {
+		return AuthorizationPermissions{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AuthorizationPermissions{}, readBodyAsError(res)
+	}
+	var resp AuthorizationPermissions
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
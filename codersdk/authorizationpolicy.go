@@ -0,0 +1,100 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// AuthorizationPolicy is the set of roles and the policy that authorize
+// requests, for reviewing who can do what.
+type AuthorizationPolicy struct {
+	// Rego is the source of the policy that evaluates roles.
+	Rego string `json:"rego"`
+	// Roles are the site roles and the roles of every organization.
+	Roles []AuthorizationRole `json:"roles"`
+	// Scopes limit what an API key can do, regardless of the roles of its
+	// user.
+	Scopes []AuthorizationRole `json:"scopes"`
+	// GroupRoles are the groups that grant roles to their members.
+	GroupRoles []AuthorizationGroupRoles `json:"group_roles"`
+}
+
+// AuthorizationRole is a role with the permissions it grants.
+type AuthorizationRole struct {
+	Name        string                    `json:"name"`
+	DisplayName string                    `json:"display_name"`
+	Site        []AuthorizationPermission `json:"site"`
+	// Org maps organization IDs to the permissions granted in them.
+	Org  map[string][]AuthorizationPermission `json:"org"`
+	User []AuthorizationPermission            `json:"user"`
+}
+
+// AuthorizationPermission allows an action on a resource type, or denies it
+// when Negate is set.
+type AuthorizationPermission struct {
+	Negate       bool   `json:"negate"`
+	ResourceType string `json:"resource_type"`
+	Action       string `json:"action"`
+}
+
+type AuthorizationGroupRoles struct {
+	GroupID   uuid.UUID `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	// OrganizationID is empty for deployment-wide groups.
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Roles          []string  `json:"roles"`
+}
+
+// AuthorizationSimulateRequest evaluates permission checks as another user.
+type AuthorizationSimulateRequest struct {
+	UserID uuid.UUID                     `json:"user_id" validate:"required"`
+	Checks map[string]AuthorizationCheck `json:"checks"`
+}
+
+// AuthorizationSimulateResponse has the results of the checks, and the
+// roles and groups of the user they were evaluated with.
+type AuthorizationSimulateResponse struct {
+	UserID   uuid.UUID  `json:"user_id"`
+	Username string     `json:"username"`
+	Status   UserStatus `json:"status"`
+	// Roles include the implied roles, and the roles granted by groups.
+	Roles []string `json:"roles"`
+	// Groups are the IDs of the groups of the user, including the groups
+	// their groups are nested in.
+	Groups []string `json:"groups"`
+	// Checks are all false for users that aren't active.
+	Checks AuthorizationResponse `json:"checks"`
+}
+
+// AuthorizationPolicy returns the roles, group role assignments, and rego
+// policy that authorize requests.
+func (c *Client) AuthorizationPolicy(ctx context.Context) (AuthorizationPolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/authcheck/policy", nil)
+	if err != nil {
+		return AuthorizationPolicy{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AuthorizationPolicy{}, readBodyAsError(res)
+	}
+	var policy AuthorizationPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// SimulateAuthorization evaluates whether a user would be allowed to perform
+// the checks.
+func (c *Client) SimulateAuthorization(ctx context.Context, req AuthorizationSimulateRequest) (AuthorizationSimulateResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/authcheck/simulate", req)
+	if err != nil {
+		return AuthorizationSimulateResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AuthorizationSimulateResponse{}, readBodyAsError(res)
+	}
+	var resp AuthorizationSimulateResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -0,0 +1,60 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// BatchRequestLimit is the most sub-requests a batch request can contain.
+const BatchRequestLimit = 25
+
+// BatchSubRequest is an API request executed as part of a batch request.
+type BatchSubRequest struct {
+	Method string `json:"method" validate:"required"`
+	// Path is relative to the access URL and must start with "/api/v2/". It
+	// can include a query string.
+	Path string `json:"path" validate:"required"`
+	// Headers are added to the sub-request, e.g. "If-Match". The caller's
+	// authentication is always used.
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchRequest executes up to BatchRequestLimit sub-requests in order, with
+// the caller's authentication.
+type BatchRequest struct {
+	Requests []BatchSubRequest `json:"requests" validate:"required"`
+}
+
+// BatchSubResponse is the response to a sub-request. Bodies that aren't JSON
+// are returned as a string.
+type BatchSubResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse contains a response for every sub-request, in the same order
+// as the request.
+type BatchResponse struct {
+	Responses []BatchSubResponse `json:"responses"`
+}
+
+// Batch executes multiple API requests in one round trip. Sub-requests that
+// fail don't fail the batch, so check the status code of every response.
+func (c *Client) Batch(ctx context.Context, req BatchRequest) (BatchResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/batch", req)
+	if err != nil {
+		return BatchResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return BatchResponse{}, readBodyAsError(res)
+	}
+	var resp BatchResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -26,6 +26,11 @@ const (
 	OAuth2RedirectKey   = "oauth_redirect"
 )
 
+// RequestIDHeader identifies a request in server logs and audit logs. Clients
+// can set it to a UUID to correlate requests with their own logs; the server
+// generates one otherwise. Responses always include it.
+const RequestIDHeader = "X-Request-Id"
+
 // New creates a Coder client for the provided URL.
 func New(serverURL *url.URL) *Client {
 	return &Client{
@@ -40,6 +45,13 @@ type Client struct {
 	HTTPClient   *http.Client
 	SessionToken string
 	URL          *url.URL
+	// Retry retries requests that failed because the deployment was
+	// overloaded or unavailable. Requests aren't retried by default.
+	Retry RetryPolicy
+	// BeforeRequest and AfterResponse run in order around every attempt of
+	// a request, including retries.
+	BeforeRequest []BeforeRequestHook
+	AfterResponse []AfterResponseHook
 }
 
 type RequestOption func(*http.Request)
@@ -91,7 +103,7 @@ func (c *Client) Request(ctx context.Context, method, path string, body interfac
 		opt(req)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, xerrors.Errorf("do: %w", err)
 	}
@@ -151,6 +163,7 @@ func readBodyAsError(res *http.Response) error {
 		statusCode: res.StatusCode,
 		method:     method,
 		url:        u,
+		requestID:  res.Header.Get(RequestIDHeader),
 		Helper:     helper,
 	}
 }
@@ -163,6 +176,7 @@ type Error struct {
 	statusCode int
 	method     string
 	url        string
+	requestID  string
 
 	Helper string
 }
@@ -171,6 +185,12 @@ func (e *Error) StatusCode() int {
 	return e.statusCode
 }
 
+// RequestID returns the ID of the request that failed, to quote in support
+// tickets.
+func (e *Error) RequestID() string {
+	return e.requestID
+}
+
 func (e *Error) Friendly() string {
 	var sb strings.Builder
 	_, _ = fmt.Fprintf(&sb, "%s. %s", strings.TrimSuffix(e.Message, "."), e.Helper)
@@ -0,0 +1,688 @@
+// Package codersdkfake serves an in-memory fake of the Coder API, so programs
+// built on codersdk can be unit tested without running coderd.
+//
+// The fake covers users, organizations, groups, and workspaces. Requests to
+// other routes fail with a 404 and the route_not_found error code. Every
+// request is authenticated as the owner the server is created with.
+package codersdkfake
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+// Server is an in-memory Coder API.
+type Server struct {
+	mutex         sync.RWMutex
+	owner         uuid.UUID
+	users         map[uuid.UUID]codersdk.User
+	organizations map[uuid.UUID]codersdk.Organization
+	groups        map[uuid.UUID]group
+	workspaces    map[uuid.UUID]codersdk.Workspace
+
+	url *url.URL
+}
+
+// group is a codersdk.Group with the IDs of its members, so members reflect
+// changes to users.
+type group struct {
+	codersdk.Group
+	memberIDs []uuid.UUID
+}
+
+// New starts a fake API with a default organization and an owner that's a
+// member of it. The server is closed when the test finishes.
+func New(t testing.TB) *Server {
+	t.Helper()
+
+	now := time.Now()
+	organization := codersdk.Organization{
+		ID:        uuid.New(),
+		Name:      "coder",
+		CreatedAt: now,
+		UpdatedAt: now,
+		IsDefault: true,
+	}
+	owner := codersdk.User{
+		ID:              uuid.New(),
+		Username:        "admin",
+		Email:           "admin@coder.com",
+		CreatedAt:       now,
+		LastSeenAt:      now,
+		Status:          codersdk.UserStatusActive,
+		OrganizationIDs: []uuid.UUID{organization.ID},
+		Roles:           []codersdk.Role{{Name: "owner", DisplayName: "Owner"}},
+	}
+	s := &Server{
+		owner:         owner.ID,
+		users:         map[uuid.UUID]codersdk.User{owner.ID: owner},
+		organizations: map[uuid.UUID]codersdk.Organization{organization.ID: organization},
+		groups:        map[uuid.UUID]group{},
+		workspaces:    map[uuid.UUID]codersdk.Workspace{},
+	}
+
+	srv := httptest.NewServer(s.handler())
+	t.Cleanup(srv.Close)
+	serverURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	s.url = serverURL
+	return s
+}
+
+// URL is the address of the fake API.
+func (s *Server) URL() *url.URL {
+	return s.url
+}
+
+// Client returns a client for the fake API, authenticated as the owner.
+func (s *Server) Client() *codersdk.Client {
+	client := codersdk.New(s.url)
+	client.SessionToken = "fake-session-token"
+	return client
+}
+
+// Owner returns the user that requests are authenticated as.
+func (s *Server) Owner() codersdk.User {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.users[s.owner]
+}
+
+// DefaultOrganization returns the organization the server is created with.
+func (s *Server) DefaultOrganization() codersdk.Organization {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, organization := range s.organizations {
+		if organization.IsDefault {
+			return organization
+		}
+	}
+	return codersdk.Organization{}
+}
+
+// AddUser stores a user. The ID, creation time, and status are set if they're
+// empty, and the user joins the default organization if it doesn't belong to
+// any.
+func (s *Server) AddUser(user codersdk.User) codersdk.User {
+	defaultOrganization := s.DefaultOrganization()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	if user.Status == "" {
+		user.Status = codersdk.UserStatusActive
+	}
+	if len(user.OrganizationIDs) == 0 {
+		user.OrganizationIDs = []uuid.UUID{defaultOrganization.ID}
+	}
+	if user.Roles == nil {
+		user.Roles = []codersdk.Role{}
+	}
+	s.users[user.ID] = user
+	return user
+}
+
+// AddOrganization stores an organization. The owner joins it.
+func (s *Server) AddOrganization(organization codersdk.Organization) codersdk.Organization {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.addOrganization(organization)
+}
+
+func (s *Server) addOrganization(organization codersdk.Organization) codersdk.Organization {
+	if organization.ID == uuid.Nil {
+		organization.ID = uuid.New()
+	}
+	if organization.CreatedAt.IsZero() {
+		organization.CreatedAt = time.Now()
+		organization.UpdatedAt = organization.CreatedAt
+	}
+	s.organizations[organization.ID] = organization
+	owner := s.users[s.owner]
+	owner.OrganizationIDs = append(owner.OrganizationIDs, organization.ID)
+	s.users[owner.ID] = owner
+	return organization
+}
+
+// AddGroup stores a group with the users of the IDs as members.
+func (s *Server) AddGroup(g codersdk.Group, memberIDs ...uuid.UUID) codersdk.Group {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	if g.Source == "" {
+		g.Source = codersdk.GroupSourceUser
+	}
+	s.groups[g.ID] = group{Group: g, memberIDs: memberIDs}
+	return s.convertGroup(s.groups[g.ID])
+}
+
+// AddWorkspace stores a workspace. The ID and creation time are set if
+// they're empty, and the owner name is set from the owner ID.
+func (s *Server) AddWorkspace(workspace codersdk.Workspace) codersdk.Workspace {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.addWorkspace(workspace)
+}
+
+func (s *Server) addWorkspace(workspace codersdk.Workspace) codersdk.Workspace {
+	if workspace.ID == uuid.Nil {
+		workspace.ID = uuid.New()
+	}
+	if workspace.CreatedAt.IsZero() {
+		workspace.CreatedAt = time.Now()
+		workspace.UpdatedAt = workspace.CreatedAt
+	}
+	if workspace.OwnerID == uuid.Nil {
+		workspace.OwnerID = s.owner
+	}
+	workspace.OwnerName = s.users[workspace.OwnerID].Username
+	s.workspaces[workspace.ID] = workspace
+	return workspace
+}
+
+func (s *Server) handler() http.Handler {
+	r := chi.NewRouter()
+	r.NotFound(func(rw http.ResponseWriter, r *http.Request) {
+		httpapi.RouteNotFound(rw)
+	})
+	r.Route("/api/v2", func(r chi.Router) {
+		r.Route("/users", func(r chi.Router) {
+			r.Get("/", s.listUsers)
+			r.Post("/", s.postUser)
+			r.Route("/{user}", func(r chi.Router) {
+				r.Get("/", s.getUser)
+				r.Get("/organizations", s.userOrganizations)
+				r.Get("/organizations/{name}", s.userOrganizationByName)
+				r.Get("/workspace/{name}", s.workspaceByOwnerAndName)
+			})
+		})
+		r.Route("/organizations", func(r chi.Router) {
+			r.Post("/", s.postOrganization)
+			r.Route("/{organization}", func(r chi.Router) {
+				r.Get("/", s.getOrganization)
+				r.Get("/groups", s.listGroups)
+				r.Post("/groups", s.postGroup)
+				r.Get("/groups/{name}", s.groupByName)
+				r.Post("/members/{user}/workspaces", s.postWorkspace)
+			})
+		})
+		r.Route("/groups/{group}", func(r chi.Router) {
+			r.Get("/", s.getGroup)
+			r.Patch("/", s.patchGroup)
+			r.Delete("/", s.deleteGroup)
+		})
+		r.Route("/workspaces", func(r chi.Router) {
+			r.Get("/", s.listWorkspaces)
+			r.Get("/{workspace}", s.getWorkspace)
+		})
+	})
+	return r
+}
+
+// findUser resolves "me", a user ID, a username, or an email. It must be
+// called with the mutex held.
+func (s *Server) findUser(ident string) (codersdk.User, bool) {
+	if ident == codersdk.Me {
+		ident = s.owner.String()
+	}
+	for _, user := range s.users {
+		if user.ID.String() == ident || strings.EqualFold(user.Username, ident) || strings.EqualFold(user.Email, ident) {
+			return user, true
+		}
+	}
+	return codersdk.User{}, false
+}
+
+func (s *Server) findOrganization(r *http.Request) (codersdk.Organization, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "organization"))
+	if err != nil {
+		return codersdk.Organization{}, false
+	}
+	organization, ok := s.organizations[id]
+	return organization, ok
+}
+
+func (s *Server) findGroup(r *http.Request) (group, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "group"))
+	if err != nil {
+		return group{}, false
+	}
+	g, ok := s.groups[id]
+	return g, ok
+}
+
+func (s *Server) convertGroup(g group) codersdk.Group {
+	converted := g.Group
+	converted.Members = make([]codersdk.GroupMember, 0, len(g.memberIDs))
+	for _, id := range g.memberIDs {
+		if user, ok := s.users[id]; ok {
+			converted.Members = append(converted.Members, codersdk.GroupMember{User: user})
+		}
+	}
+	converted.MembersCount = len(converted.Members)
+	return converted
+}
+
+func (s *Server) listUsers(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	search := strings.ToLower(r.URL.Query().Get("q"))
+	users := make([]codersdk.User, 0, len(s.users))
+	for _, user := range s.users {
+		if search != "" && !strings.Contains(strings.ToLower(user.Username), search) && !strings.Contains(strings.ToLower(user.Email), search) {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	httpapi.Write(r.Context(), rw, http.StatusOK, paginate(r, users))
+}
+
+func (s *Server) postUser(rw http.ResponseWriter, r *http.Request) {
+	var req codersdk.CreateUserRequest
+	if !httpapi.Read(r.Context(), rw, r, &req) {
+		return
+	}
+	s.mutex.Lock()
+	_, usernameTaken := s.findUser(req.Username)
+	_, emailTaken := s.findUser(req.Email)
+	_, organizationExists := s.organizations[req.OrganizationID]
+	s.mutex.Unlock()
+	if usernameTaken || emailTaken {
+		httpapi.Write(r.Context(), rw, http.StatusConflict, codersdk.Response{
+			Message: "User already exists.",
+		})
+		return
+	}
+	if !organizationExists {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	user := s.AddUser(codersdk.User{
+		Username:        req.Username,
+		Email:           req.Email,
+		OrganizationIDs: []uuid.UUID{req.OrganizationID},
+	})
+	httpapi.Write(r.Context(), rw, http.StatusCreated, user)
+}
+
+func (s *Server) getUser(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	user, ok := s.findUser(chi.URLParam(r, "user"))
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, user)
+}
+
+func (s *Server) userOrganizations(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	user, ok := s.findUser(chi.URLParam(r, "user"))
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	organizations := make([]codersdk.Organization, 0, len(user.OrganizationIDs))
+	for _, id := range user.OrganizationIDs {
+		if organization, ok := s.organizations[id]; ok {
+			organizations = append(organizations, organization)
+		}
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, organizations)
+}
+
+func (s *Server) userOrganizationByName(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	user, ok := s.findUser(chi.URLParam(r, "user"))
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	for _, id := range user.OrganizationIDs {
+		if organization, ok := s.organizations[id]; ok && organization.Name == chi.URLParam(r, "name") {
+			httpapi.Write(r.Context(), rw, http.StatusOK, organization)
+			return
+		}
+	}
+	httpapi.ResourceNotFound(rw)
+}
+
+func (s *Server) postOrganization(rw http.ResponseWriter, r *http.Request) {
+	var req codersdk.CreateOrganizationRequest
+	if !httpapi.Read(r.Context(), rw, r, &req) {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, organization := range s.organizations {
+		if organization.Name == req.Name {
+			httpapi.Write(r.Context(), rw, http.StatusConflict, codersdk.Response{
+				Message: "Organization already exists with that name.",
+			})
+			return
+		}
+	}
+	organization := s.addOrganization(codersdk.Organization{Name: req.Name})
+	httpapi.Write(r.Context(), rw, http.StatusCreated, organization)
+}
+
+func (s *Server) getOrganization(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	organization, ok := s.findOrganization(r)
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, organization)
+}
+
+func (s *Server) listGroups(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	organization, ok := s.findOrganization(r)
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	search := strings.ToLower(r.URL.Query().Get("q"))
+	groups := make([]codersdk.Group, 0)
+	for _, g := range s.groups {
+		if g.OrganizationID != organization.ID {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(g.Name), search) {
+			continue
+		}
+		groups = append(groups, s.convertGroup(g))
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.GroupsResponse{
+		Groups: groups,
+		Count:  len(groups),
+	})
+}
+
+func (s *Server) postGroup(rw http.ResponseWriter, r *http.Request) {
+	var req codersdk.CreateGroupRequest
+	if !httpapi.Read(r.Context(), rw, r, &req) {
+		return
+	}
+	s.mutex.RLock()
+	organization, ok := s.findOrganization(r)
+	conflict := false
+	for _, g := range s.groups {
+		if g.OrganizationID == organization.ID && g.Name == req.Name {
+			conflict = true
+		}
+	}
+	s.mutex.RUnlock()
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if conflict {
+		httpapi.Write(r.Context(), rw, http.StatusConflict, codersdk.Response{
+			Message: "Group name is already taken.",
+		})
+		return
+	}
+	created := s.AddGroup(codersdk.Group{
+		Name:           req.Name,
+		DisplayName:    req.DisplayName,
+		AvatarURL:      req.AvatarURL,
+		Description:    req.Description,
+		OrganizationID: organization.ID,
+		ParentID:       req.ParentID,
+	})
+	httpapi.Write(r.Context(), rw, http.StatusCreated, created)
+}
+
+func (s *Server) groupByName(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	organization, ok := s.findOrganization(r)
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	for _, g := range s.groups {
+		if g.OrganizationID == organization.ID && g.Name == chi.URLParam(r, "name") {
+			httpapi.Write(r.Context(), rw, http.StatusOK, s.convertGroup(g))
+			return
+		}
+	}
+	httpapi.ResourceNotFound(rw)
+}
+
+func (s *Server) getGroup(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	g, ok := s.findGroup(r)
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, s.convertGroup(g))
+}
+
+func (s *Server) patchGroup(rw http.ResponseWriter, r *http.Request) {
+	var req codersdk.PatchGroupRequest
+	if !httpapi.Read(r.Context(), rw, r, &req) {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g, ok := s.findGroup(r)
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if req.Name != "" {
+		g.Name = req.Name
+	}
+	if req.DisplayName != nil {
+		g.DisplayName = *req.DisplayName
+	}
+	if req.AvatarURL != nil {
+		g.AvatarURL = *req.AvatarURL
+	}
+	if req.Description != nil {
+		g.Description = *req.Description
+	}
+	for _, ident := range req.AddUsers {
+		user, ok := s.findUser(ident)
+		if !ok {
+			httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+				Message: "User not found.",
+				Detail:  ident,
+			})
+			return
+		}
+		if !containsID(g.memberIDs, user.ID) {
+			g.memberIDs = append(g.memberIDs, user.ID)
+		}
+	}
+	for _, ident := range req.RemoveUsers {
+		user, ok := s.findUser(ident)
+		if !ok {
+			continue
+		}
+		memberIDs := make([]uuid.UUID, 0, len(g.memberIDs))
+		for _, id := range g.memberIDs {
+			if id != user.ID {
+				memberIDs = append(memberIDs, id)
+			}
+		}
+		g.memberIDs = memberIDs
+	}
+	s.groups[g.ID] = g
+	httpapi.Write(r.Context(), rw, http.StatusOK, s.convertGroup(g))
+}
+
+func (s *Server) deleteGroup(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g, ok := s.findGroup(r)
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	delete(s.groups, g.ID)
+	httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully deleted group!",
+	})
+}
+
+func (s *Server) postWorkspace(rw http.ResponseWriter, r *http.Request) {
+	var req codersdk.CreateWorkspaceRequest
+	if !httpapi.Read(r.Context(), rw, r, &req) {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.findOrganization(r); !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	owner, ok := s.findUser(chi.URLParam(r, "user"))
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	for _, workspace := range s.workspaces {
+		if workspace.OwnerID == owner.ID && workspace.Name == req.Name {
+			httpapi.Write(r.Context(), rw, http.StatusConflict, codersdk.Response{
+				Message: "Workspace already exists with that name.",
+			})
+			return
+		}
+	}
+	workspace := s.addWorkspace(codersdk.Workspace{
+		OwnerID:           owner.ID,
+		TemplateID:        req.TemplateID,
+		Name:              req.Name,
+		AutostartSchedule: req.AutostartSchedule,
+		TTLMillis:         req.TTLMillis,
+	})
+	httpapi.Write(r.Context(), rw, http.StatusCreated, workspace)
+}
+
+func (s *Server) listWorkspaces(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	filter := parseFilter(r.URL.Query().Get("q"))
+	if filter["owner"] == codersdk.Me {
+		filter["owner"] = s.users[s.owner].Username
+	}
+	workspaces := make([]codersdk.Workspace, 0)
+	for _, workspace := range s.workspaces {
+		if owner, ok := filter["owner"]; ok && !strings.EqualFold(workspace.OwnerName, owner) {
+			continue
+		}
+		if name, ok := filter["name"]; ok && !strings.Contains(workspace.Name, name) {
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Name < workspaces[j].Name
+	})
+	httpapi.Write(r.Context(), rw, http.StatusOK, workspaces)
+}
+
+func (s *Server) getWorkspace(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	id, err := uuid.Parse(chi.URLParam(r, "workspace"))
+	workspace, ok := s.workspaces[id]
+	if err != nil || !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	httpapi.Write(r.Context(), rw, http.StatusOK, workspace)
+}
+
+func (s *Server) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	owner, ok := s.findUser(chi.URLParam(r, "user"))
+	if !ok {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	for _, workspace := range s.workspaces {
+		if workspace.OwnerID == owner.ID && workspace.Name == chi.URLParam(r, "name") {
+			httpapi.Write(r.Context(), rw, http.StatusOK, workspace)
+			return
+		}
+	}
+	httpapi.ResourceNotFound(rw)
+}
+
+// parseFilter parses search queries like `owner:"me" name:"dev"`.
+func parseFilter(query string) map[string]string {
+	filter := map[string]string{}
+	for _, term := range strings.Fields(query) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		filter[key] = value
+	}
+	return filter
+}
+
+// paginate applies the offset and limit query parameters.
+func paginate[T any](r *http.Request, values []T) []T {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset > len(values) {
+		offset = len(values)
+	}
+	values = values[offset:]
+	if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && limit < len(values) {
+		values = values[:limit]
+	}
+	return values
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package codersdkfake_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/codersdkfake"
+	"github.com/coder/coder/testutil"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Users", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		fake := codersdkfake.New(t)
+		client := fake.Client()
+
+		me, err := client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, fake.Owner().ID, me.ID)
+
+		created, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "alice@coder.com",
+			Username:       "alice",
+			Password:       "SomeSecurePassword!",
+			OrganizationID: fake.DefaultOrganization().ID,
+		})
+		require.NoError(t, err)
+		_, err = client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "alice@coder.com",
+			Username:       "alice",
+			Password:       "SomeSecurePassword!",
+			OrganizationID: fake.DefaultOrganization().ID,
+		})
+		require.True(t, errors.As(err, &codersdk.ConflictError{}))
+
+		user, err := client.User(ctx, "alice")
+		require.NoError(t, err)
+		require.Equal(t, created.ID, user.ID)
+
+		users, err := client.Users(ctx, codersdk.UsersRequest{Search: "ali"})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+
+		_, err = client.User(ctx, "bob")
+		require.True(t, errors.As(err, &codersdk.NotFoundError{}))
+	})
+
+	t.Run("Organizations", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		fake := codersdkfake.New(t)
+		client := fake.Client()
+
+		created, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "acme"})
+		require.NoError(t, err)
+		organization, err := client.Organization(ctx, created.ID)
+		require.NoError(t, err)
+		require.Equal(t, "acme", organization.Name)
+
+		organizations, err := client.OrganizationsByUser(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, organizations, 2)
+		organization, err = client.OrganizationByName(ctx, codersdk.Me, "acme")
+		require.NoError(t, err)
+		require.Equal(t, created.ID, organization.ID)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		fake := codersdkfake.New(t)
+		client := fake.Client()
+		organizationID := fake.DefaultOrganization().ID
+		user := fake.AddUser(codersdk.User{Username: "alice", Email: "alice@coder.com"})
+
+		group, err := client.CreateGroup(ctx, organizationID, codersdk.CreateGroupRequest{Name: "developers"})
+		require.NoError(t, err)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{AddUsers: []string{user.Username}})
+		require.NoError(t, err)
+		require.Len(t, group.Members, 1)
+		require.Equal(t, user.ID, group.Members[0].ID)
+
+		groups, err := client.GroupsByOrganization(ctx, organizationID, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Len(t, groups.Groups, 1)
+		byName, err := client.GroupByOrgAndName(ctx, organizationID, "developers")
+		require.NoError(t, err)
+		require.Equal(t, group.ID, byName.ID)
+
+		require.NoError(t, client.DeleteGroup(ctx, group.ID))
+		_, err = client.Group(ctx, group.ID)
+		require.True(t, errors.As(err, &codersdk.NotFoundError{}))
+	})
+
+	t.Run("Workspaces", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		fake := codersdkfake.New(t)
+		client := fake.Client()
+		user := fake.AddUser(codersdk.User{Username: "alice", Email: "alice@coder.com"})
+		fake.AddWorkspace(codersdk.Workspace{OwnerID: user.ID, Name: "other"})
+
+		created, err := client.CreateWorkspace(ctx, fake.DefaultOrganization().ID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: uuid.New(),
+			Name:       "dev",
+		})
+		require.NoError(t, err)
+		require.Equal(t, fake.Owner().Username, created.OwnerName)
+
+		workspace, err := client.Workspace(ctx, created.ID)
+		require.NoError(t, err)
+		require.Equal(t, "dev", workspace.Name)
+		workspace, err = client.WorkspaceByOwnerAndName(ctx, codersdk.Me, "dev", codersdk.WorkspaceOptions{})
+		require.NoError(t, err)
+		require.Equal(t, created.ID, workspace.ID)
+
+		workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{Owner: codersdk.Me})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+		workspaces, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Len(t, workspaces, 2)
+	})
+
+	t.Run("UnsupportedRoute", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		_, err := codersdkfake.New(t).Client().BuildInfo(ctx)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeRouteNotFound))
+	})
+}
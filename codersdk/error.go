@@ -3,6 +3,7 @@ package codersdk
 import (
 	"fmt"
 	"net"
+	"net/http"
 
 	"golang.org/x/xerrors"
 )
@@ -25,8 +26,39 @@ type Response struct {
 	// shown on a form field in the UI. These can also be used to add additional
 	// context if there is a set of errors in the primary 'Message'.
 	Validations []ValidationError `json:"validations,omitempty"`
+	// Code is a stable, machine-readable identifier for the error. Unlike
+	// Message, codes do not change between releases, so clients should
+	// branch on Code rather than on the human-readable text.
+	Code ErrorCode `json:"code,omitempty"`
 }
 
+// ErrorCode is a stable identifier for a class of API error.
+type ErrorCode string
+
+const (
+	ErrorCodeResourceNotFound       ErrorCode = "resource_not_found"
+	ErrorCodeRouteNotFound          ErrorCode = "route_not_found"
+	ErrorCodeForbidden              ErrorCode = "forbidden"
+	ErrorCodeInternalError          ErrorCode = "internal_error"
+	ErrorCodeInvalidRequestBody     ErrorCode = "invalid_request_body"
+	ErrorCodeValidationFailed       ErrorCode = "validation_failed"
+	ErrorCodeGroupNameReserved      ErrorCode = "group_name_reserved"
+	ErrorCodeQuotaExceeded          ErrorCode = "quota_exceeded"
+	ErrorCodeOrgMemberRequired      ErrorCode = "org_member_required"
+	ErrorCodeGroupCycle             ErrorCode = "group_cycle"
+	ErrorCodeGroupManaged           ErrorCode = "group_managed"
+	ErrorCodeEditConflict           ErrorCode = "edit_conflict"
+	ErrorCodeInvalidQueryParameter  ErrorCode = "invalid_query_parameter"
+	ErrorCodeGroupNameTaken         ErrorCode = "group_name_taken"
+	ErrorCodeGroupMemberExists      ErrorCode = "group_member_exists"
+	ErrorCodeGroupMemberNotFound    ErrorCode = "group_member_not_found"
+	ErrorCodeGroupJoinRequestExists ErrorCode = "group_join_request_exists"
+	ErrorCodeIPNotAllowed           ErrorCode = "ip_not_allowed"
+	ErrorCodeMaintenance            ErrorCode = "maintenance"
+	ErrorCodeRequestTooLarge        ErrorCode = "request_too_large"
+	ErrorCodeRequestTimeout         ErrorCode = "request_timeout"
+)
+
 // ValidationError represents a scoped error to a user input.
 type ValidationError struct {
 	Field  string `json:"field" validate:"required"`
@@ -56,3 +88,100 @@ func AsError(err error) (*Error, bool) {
 	var e *Error
 	return e, xerrors.As(err, &e)
 }
+
+// ErrorCodeOf returns the code attached to an API error. An empty code is
+// returned if err is not an *Error or the server did not provide a code.
+func ErrorCodeOf(err error) ErrorCode {
+	apiErr, ok := AsError(err)
+	if !ok {
+		return ""
+	}
+	return apiErr.Code
+}
+
+// IsErrorCode returns true if err is an API error with the provided code.
+func IsErrorCode(err error, code ErrorCode) bool {
+	return code != "" && ErrorCodeOf(err) == code
+}
+
+// statusError is the API error behind a typed error. The Response of the API
+// error is promoted, so callers can read the message of typed errors.
+// @typescript-ignore statusError
+type statusError struct {
+	Response
+	err *Error
+}
+
+func (e statusError) Error() string {
+	if e.err == nil {
+		return e.Message
+	}
+	return e.err.Error()
+}
+
+func (e statusError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// StatusCode returns the HTTP status code of the API error.
+func (e statusError) StatusCode() int {
+	if e.err == nil {
+		return 0
+	}
+	return e.err.StatusCode()
+}
+
+// NotFoundError is an API error with the 404 Not Found status code. Callers
+// can match it with errors.As:
+//
+//	var notFound codersdk.NotFoundError
+//	if errors.As(err, &notFound) { ... }
+//
+// @typescript-ignore NotFoundError
+type NotFoundError struct{ statusError }
+
+// ConflictError is an API error with the 409 Conflict status code.
+// @typescript-ignore ConflictError
+type ConflictError struct{ statusError }
+
+// PreconditionFailedError is an API error with the 412 Precondition Failed
+// status code.
+// @typescript-ignore PreconditionFailedError
+type PreconditionFailedError struct{ statusError }
+
+// UnauthorizedError is an API error with the 401 Unauthorized status code,
+// e.g. because the session token expired.
+// @typescript-ignore UnauthorizedError
+type UnauthorizedError struct{ statusError }
+
+// As matches the typed error of the status code, so callers don't have to
+// compare status codes.
+func (e *Error) As(target interface{}) bool {
+	typed := statusError{Response: e.Response, err: e}
+	switch target := target.(type) {
+	case *NotFoundError:
+		if e.statusCode == http.StatusNotFound {
+			*target = NotFoundError{typed}
+			return true
+		}
+	case *ConflictError:
+		if e.statusCode == http.StatusConflict {
+			*target = ConflictError{typed}
+			return true
+		}
+	case *PreconditionFailedError:
+		if e.statusCode == http.StatusPreconditionFailed {
+			*target = PreconditionFailedError{typed}
+			return true
+		}
+	case *UnauthorizedError:
+		if e.statusCode == http.StatusUnauthorized {
+			*target = UnauthorizedError{typed}
+			return true
+		}
+	}
+	return false
+}
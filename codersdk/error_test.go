@@ -1,14 +1,21 @@
 package codersdk_test
 
 import (
+	"errors"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
 )
 
 func TestIsConnectionErr(t *testing.T) {
@@ -63,3 +70,72 @@ func TestIsConnectionErr(t *testing.T) {
 		})
 	}
 }
+
+func TestIsErrorCode(t *testing.T) {
+	t.Parallel()
+
+	apiErr := &codersdk.Error{
+		Response: codersdk.Response{
+			Message: "Group name is reserved.",
+			Code:    codersdk.ErrorCodeGroupNameReserved,
+		},
+	}
+	wrapped := xerrors.Errorf("create group: %w", apiErr)
+
+	require.Equal(t, codersdk.ErrorCodeGroupNameReserved, codersdk.ErrorCodeOf(wrapped))
+	require.True(t, codersdk.IsErrorCode(wrapped, codersdk.ErrorCodeGroupNameReserved))
+	require.False(t, codersdk.IsErrorCode(wrapped, codersdk.ErrorCodeQuotaExceeded))
+	require.False(t, codersdk.IsErrorCode(xerrors.New("opaque"), ""))
+	require.Equal(t, codersdk.ErrorCode(""), codersdk.ErrorCodeOf(xerrors.New("opaque")))
+}
+
+func TestTypedErrors(t *testing.T) {
+	t.Parallel()
+
+	serve := func(t *testing.T, status int) error {
+		t.Helper()
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), rw, status, codersdk.Response{
+				Message: "Request failed.",
+			})
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		ctx, _ := testutil.Context(t)
+		_, err = codersdk.New(serverURL).Organization(ctx, uuid.New())
+		require.Error(t, err)
+		return err
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		err := xerrors.Errorf("get organization: %w", serve(t, http.StatusNotFound))
+		var notFound codersdk.NotFoundError
+		require.True(t, errors.As(err, &notFound))
+		require.Equal(t, "Request failed.", notFound.Message)
+		require.Equal(t, http.StatusNotFound, notFound.StatusCode())
+		require.False(t, errors.As(err, &codersdk.ConflictError{}))
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		err := serve(t, http.StatusConflict)
+		require.True(t, errors.As(err, &codersdk.ConflictError{}))
+		require.False(t, errors.As(err, &codersdk.NotFoundError{}))
+	})
+
+	t.Run("PreconditionFailed", func(t *testing.T) {
+		t.Parallel()
+		require.True(t, errors.As(serve(t, http.StatusPreconditionFailed), &codersdk.PreconditionFailedError{}))
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+		err := serve(t, http.StatusUnauthorized)
+		require.True(t, errors.As(err, &codersdk.UnauthorizedError{}))
+		// The untyped error is still available.
+		_, ok := codersdk.AsError(err)
+		require.True(t, ok)
+	})
+}
@@ -0,0 +1,87 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ResourceEventType string
+
+const (
+	ResourceEventTypeWorkspace          ResourceEventType = "workspace"
+	ResourceEventTypeGroup              ResourceEventType = "group"
+	ResourceEventTypeOrganizationMember ResourceEventType = "organization_member"
+)
+
+type ResourceEventAction string
+
+const (
+	ResourceEventActionCreated ResourceEventAction = "created"
+	ResourceEventActionUpdated ResourceEventAction = "updated"
+	ResourceEventActionDeleted ResourceEventAction = "deleted"
+)
+
+// ResourceEvent is sent by the events stream when a resource the user can
+// read changes. It only identifies the resource, so clients fetch it again
+// to get its current state. Events may arrive out of order.
+type ResourceEvent struct {
+	ID             uuid.UUID           `json:"id"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Type           ResourceEventType   `json:"type"`
+	Action         ResourceEventAction `json:"action"`
+	OrganizationID uuid.UUID           `json:"organization_id"`
+	// ResourceID is the ID of the workspace or group, or the user ID of the
+	// organization member.
+	ResourceID uuid.UUID `json:"resource_id"`
+}
+
+// WatchEvents streams events about workspaces, groups, and organization
+// members the user can read. The channel is closed when the context is
+// canceled or the connection is lost.
+func (c *Client) WatchEvents(ctx context.Context) (<-chan ResourceEvent, error) {
+	//nolint:bodyclose
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/events", nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	nextEvent := ServerSentEventReader(res.Body)
+
+	events := make(chan ResourceEvent, 256)
+	go func() {
+		defer close(events)
+		defer res.Body.Close()
+
+		for {
+			sse, err := nextEvent()
+			if err != nil {
+				return
+			}
+			if sse.Type != ServerSentEventTypeData {
+				continue
+			}
+			var event ResourceEvent
+			b, ok := sse.Data.([]byte)
+			if !ok {
+				return
+			}
+			err = json.Unmarshal(b, &event)
+			if err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- event:
+			}
+		}
+	}()
+
+	return events, nil
+}
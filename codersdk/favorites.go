@@ -0,0 +1,55 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// SortFavorites lists the favorites of the authenticated user first, in their
+// order, followed by the rest in the default order. It's a value of the "sort"
+// query parameter of the workspaces and templates lists.
+const SortFavorites = "favorites"
+
+// Favorites are the workspaces and templates a user pinned to the top of their
+// lists. They're stored server-side, so they follow the user across devices.
+type Favorites struct {
+	// WorkspaceIDs are ordered, the first is shown at the top.
+	WorkspaceIDs []uuid.UUID `json:"workspace_ids"`
+	// TemplateIDs are ordered, the first is shown at the top.
+	TemplateIDs []uuid.UUID `json:"template_ids"`
+}
+
+// Favorites returns the favorite workspaces and templates of a user.
+// Favorites that were deleted are left out.
+func (c *Client) Favorites(ctx context.Context, user string) (Favorites, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/favorites", user), nil)
+	if err != nil {
+		return Favorites{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Favorites{}, readBodyAsError(res)
+	}
+	var favorites Favorites
+	return favorites, json.NewDecoder(res.Body).Decode(&favorites)
+}
+
+// UpdateFavorites replaces the favorite workspaces and templates of a user.
+// The order of the IDs is the order they're listed in.
+func (c *Client) UpdateFavorites(ctx context.Context, user string, req Favorites) (Favorites, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/users/%s/favorites", user), req)
+	if err != nil {
+		return Favorites{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Favorites{}, readBodyAsError(res)
+	}
+	var favorites Favorites
+	return favorites, json.NewDecoder(res.Body).Decode(&favorites)
+}
@@ -25,8 +25,10 @@ type DeploymentFlags struct {
 	PromAddress                      StringFlag      `json:"prom_address"`
 	PprofEnabled                     BoolFlag        `json:"pprof_enabled"`
 	PprofAddress                     StringFlag      `json:"pprof_address"`
+	GRPCAddress                      StringFlag      `json:"grpc_address"`
 	CacheDir                         StringFlag      `json:"cache_dir"`
 	InMemoryDatabase                 BoolFlag        `json:"in_memory_database"`
+	InMemoryDatabasePath             StringFlag      `json:"in_memory_database_path"`
 	ProvisionerDaemonCount           IntFlag         `json:"provisioner_daemon_count"`
 	PostgresURL                      StringFlag      `json:"postgres_url"`
 	OAuth2GithubClientID             StringFlag      `json:"oauth2_github_client_id"`
@@ -41,6 +43,9 @@ type DeploymentFlags struct {
 	OIDCEmailDomain                  StringFlag      `json:"oidc_email_domain"`
 	OIDCIssuerURL                    StringFlag      `json:"oidc_issuer_url"`
 	OIDCScopes                       StringArrayFlag `json:"oidc_scopes"`
+	OIDCGroupField                   StringFlag      `json:"oidc_group_field"`
+	OIDCGroupRegexFilter             StringFlag      `json:"oidc_group_regex_filter"`
+	OIDCGroupMapping                 StringFlag      `json:"oidc_group_mapping"`
 	TelemetryEnable                  BoolFlag        `json:"telemetry_enable"`
 	TelemetryTraceEnable             BoolFlag        `json:"telemetry_trace_enable"`
 	TelemetryURL                     StringFlag      `json:"telemetry_url"`
@@ -50,6 +55,8 @@ type DeploymentFlags struct {
 	TLSClientAuth                    StringFlag      `json:"tls_client_auth"`
 	TLSKeyFiles                      StringArrayFlag `json:"tls_key_tiles"`
 	TLSMinVersion                    StringFlag      `json:"tls_min_version"`
+	TLSClientCertAuthEmail           BoolFlag        `json:"tls_client_cert_auth_email"`
+	TLSClientCertIdentities          StringArrayFlag `json:"tls_client_cert_identities"`
 	TraceEnable                      BoolFlag        `json:"trace_enable"`
 	SecureAuthCookie                 BoolFlag        `json:"secure_auth_cookie"`
 	SSHKeygenAlgorithm               StringFlag      `json:"ssh_keygen_algorithm"`
@@ -57,10 +64,29 @@ type DeploymentFlags struct {
 	MetricsCacheRefreshInterval      DurationFlag    `json:"metrics_cache_refresh_interval"`
 	AgentStatRefreshInterval         DurationFlag    `json:"agent_stat_refresh_interval"`
 	Verbose                          BoolFlag        `json:"verbose"`
+	AuthzDenialLogPercent            IntFlag         `json:"authz_denial_log_percent"`
+	APIKeyRateLimit                  IntFlag         `json:"api_key_rate_limit"`
+	WorkspaceBuildRateLimit          IntFlag         `json:"workspace_build_rate_limit"`
+	WorkspaceBuildRateLimitPerOrg    BoolFlag        `json:"workspace_build_rate_limit_by_organization"`
 	AuditLogging                     BoolFlag        `json:"audit_logging"`
 	BrowserOnly                      BoolFlag        `json:"browser_only"`
 	SCIMAuthHeader                   StringFlag      `json:"scim_auth_header"`
+	SCIMOrganization                 StringFlag      `json:"scim_organization"`
 	UserWorkspaceQuota               IntFlag         `json:"user_workspace_quota"`
+	GroupWorkspaceQuota              BoolFlag        `json:"group_workspace_quota"`
+	OrganizationWorkspaceQuota       BoolFlag        `json:"organization_workspace_quota"`
+	DeletedGroupRetention            DurationFlag    `json:"deleted_group_retention"`
+	OIDCGroupMetadataProvider        StringFlag      `json:"oidc_group_metadata_provider"`
+	OIDCGroupMetadataURL             StringFlag      `json:"oidc_group_metadata_url"`
+	OIDCGroupMetadataToken           StringFlag      `json:"oidc_group_metadata_token"`
+	OIDCGroupMetadataSyncInterval    DurationFlag    `json:"oidc_group_metadata_sync_interval"`
+	AutostopMaxExtensionsPerDay      IntFlag         `json:"autostop_max_extensions_per_day"`
+	AutostopMaxExtensionPerDay       DurationFlag    `json:"autostop_max_extension_per_day"`
+	ArchiveS3Endpoint                StringFlag      `json:"archive_s3_endpoint"`
+	ArchiveS3Region                  StringFlag      `json:"archive_s3_region"`
+	ArchiveS3Bucket                  StringFlag      `json:"archive_s3_bucket"`
+	ArchiveS3AccessKeyID             StringFlag      `json:"archive_s3_access_key_id"`
+	ArchiveS3SecretAccessKey         StringFlag      `json:"archive_s3_secret_access_key"`
 }
 
 type StringFlag struct {
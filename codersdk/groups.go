@@ -4,28 +4,92 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 )
 
 type CreateGroupRequest struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty" validate:"omitempty,url"`
+	Description string `json:"description,omitempty"`
+	// ParentID nests the group under another group in the same
+	// organization, or another deployment-wide group. Members of the group inherit the permissions granted
+	// to every group above it.
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
 }
 
+// GroupSource describes who manages a group's membership.
+type GroupSource string
+
+const (
+	// GroupSourceUser groups are created and managed through the API.
+	GroupSourceUser GroupSource = "user"
+	// GroupSourceOIDC groups are synced from an identity provider and
+	// their membership can't be modified manually.
+	GroupSourceOIDC GroupSource = "oidc"
+)
+
 type Group struct {
-	ID             uuid.UUID `json:"id"`
-	Name           string    `json:"name"`
-	OrganizationID uuid.UUID `json:"organization_id"`
-	Members        []User    `json:"members"`
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Description string    `json:"description"`
+	// OrganizationID is uuid.Nil for deployment-wide groups, whose members
+	// can come from any organization.
+	OrganizationID uuid.UUID     `json:"organization_id"`
+	ParentID       *uuid.UUID    `json:"parent_id,omitempty"`
+	Source         GroupSource   `json:"source"`
+	Members        []GroupMember `json:"members"`
+	// MembersCount is the number of members in the group. It's set even if
+	// members were excluded from the response.
+	MembersCount int `json:"members_count"`
+	// Metadata is arbitrary business context attached to the group, such
+	// as a cost center or a Slack channel.
+	Metadata map[string]string `json:"metadata"`
+	// AutostopSchedule is a weekly cron schedule at which workspaces owned
+	// by members are stopped, e.g. "CRON_TZ=Europe/London 0 18 * * 1-5".
+	AutostopSchedule string `json:"autostop_schedule"`
+	// MaxTTLMillis is the longest a workspace owned by a member can run
+	// before it's stopped. Zero means the group doesn't limit it.
+	MaxTTLMillis int64 `json:"max_ttl_ms"`
+	// QuotaAllowance is the budget of template weight each member can
+	// spend on workspaces in the group's organization. Allowances of the
+	// groups a user belongs to are added up.
+	QuotaAllowance int32 `json:"quota_allowance"`
+	// Roles are site and organization roles granted to every member of
+	// the group and of the groups nested beneath it.
+	Roles []string `json:"roles"`
+}
+
+type GroupMember struct {
+	User
+	// ExpiresAt is when the membership is removed. Memberships without
+	// an expiry are permanent.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// GroupRoles are the roles the user holds within the group, such as
+	// "group-admin".
+	GroupRoles []string `json:"group_roles,omitempty"`
 }
 
 func (c *Client) CreateGroup(ctx context.Context, orgID uuid.UUID, req CreateGroupRequest) (Group, error) {
-	res, err := c.Request(ctx, http.MethodPost,
-		fmt.Sprintf("/api/v2/organizations/%s/groups", orgID.String()),
-		req,
-	)
+	return c.createGroup(ctx, fmt.Sprintf("/api/v2/organizations/%s/groups", orgID.String()), req)
+}
+
+// CreateDeploymentGroup creates a group that spans every organization.
+func (c *Client) CreateDeploymentGroup(ctx context.Context, req CreateGroupRequest) (Group, error) {
+	return c.createGroup(ctx, "/api/v2/groups", req)
+}
+
+func (c *Client) createGroup(ctx context.Context, path string, req CreateGroupRequest) (Group, error) {
+	res, err := c.Request(ctx, http.MethodPost, path, req)
 	if err != nil {
 		return Group{}, xerrors.Errorf("make request: %w", err)
 	}
@@ -38,9 +102,131 @@ func (c *Client) CreateGroup(ctx context.Context, orgID uuid.UUID, req CreateGro
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
-func (c *Client) GroupsByOrganization(ctx context.Context, orgID uuid.UUID) ([]Group, error) {
+type GroupsRequest struct {
+	// SearchQuery filters groups by a case-insensitive substring of their
+	// name.
+	SearchQuery string `json:"q,omitempty"`
+	// IncludeMembers defaults to true. Large groups can be listed without
+	// their members by setting it to false, in which case only
+	// MembersCount is returned.
+	IncludeMembers *bool `json:"include_members,omitempty"`
+	// Fields limits the groups to the given JSON fields. Members are only
+	// fetched if "members" is one of them.
+	Fields []string `json:"fields,omitempty"`
+	CursorPagination
+}
+
+type GroupsResponse struct {
+	Groups []Group `json:"groups"`
+	// Count is the number of groups matching the search query, ignoring the
+	// cursor and limit.
+	Count int `json:"count"`
+	// NextCursor requests the next page. It's empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (c *Client) GroupsByOrganization(ctx context.Context, orgID uuid.UUID, req GroupsRequest) (GroupsResponse, error) {
+	return c.groups(ctx, fmt.Sprintf("/api/v2/organizations/%s/groups", orgID.String()), req)
+}
+
+// DeploymentGroups lists the groups that span every organization.
+func (c *Client) DeploymentGroups(ctx context.Context, req GroupsRequest) (GroupsResponse, error) {
+	return c.groups(ctx, "/api/v2/groups", req)
+}
+
+func (c *Client) groups(ctx context.Context, path string, req GroupsRequest) (GroupsResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet, path,
+		nil,
+		req.CursorPagination.asRequestOption(),
+		func(r *http.Request) {
+			q := r.URL.Query()
+			if req.SearchQuery != "" {
+				q.Set("q", req.SearchQuery)
+			}
+			if req.IncludeMembers != nil {
+				q.Set("include_members", strconv.FormatBool(*req.IncludeMembers))
+			}
+			if len(req.Fields) > 0 {
+				q.Set("fields", strings.Join(req.Fields, ","))
+			}
+			r.URL.RawQuery = q.Encode()
+		},
+	)
+	if err != nil {
+		return GroupsResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GroupsResponse{}, readBodyAsError(res)
+	}
+
+	var resp GroupsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+type GroupMembersResponse struct {
+	Members []GroupMember `json:"members"`
+	// Count is the number of members in the group, ignoring the cursor and
+	// limit.
+	Count int `json:"count"`
+	// NextCursor requests the next page. It's empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GroupMembers lists the members of a group ordered by username.
+func (c *Client) GroupMembers(ctx context.Context, group uuid.UUID, pagination CursorPagination) (GroupMembersResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/groups/%s/members", group.String()),
+		nil,
+		pagination.asRequestOption(),
+	)
+	if err != nil {
+		return GroupMembersResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GroupMembersResponse{}, readBodyAsError(res)
+	}
+
+	var resp GroupMembersResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupsIterator pages through the groups of an organization ordered by
+// name. Groups are returned without their members, which can be listed with
+// GroupMembersIterator.
+func (c *Client) GroupsIterator(ctx context.Context, orgID uuid.UUID) *Iterator[Group] {
+	return newIterator(ctx, func(ctx context.Context, cursor string) ([]Group, string, error) {
+		includeMembers := false
+		resp, err := c.GroupsByOrganization(ctx, orgID, GroupsRequest{
+			IncludeMembers: &includeMembers,
+			CursorPagination: CursorPagination{
+				Cursor: cursor,
+				Limit:  IteratorPageSize,
+			},
+		})
+		return resp.Groups, resp.NextCursor, err
+	})
+}
+
+// GroupMembersIterator pages through the members of a group ordered by
+// username.
+func (c *Client) GroupMembersIterator(ctx context.Context, group uuid.UUID) *Iterator[GroupMember] {
+	return newIterator(ctx, func(ctx context.Context, cursor string) ([]GroupMember, string, error) {
+		resp, err := c.GroupMembers(ctx, group, CursorPagination{
+			Cursor: cursor,
+			Limit:  IteratorPageSize,
+		})
+		return resp.Members, resp.NextCursor, err
+	})
+}
+
+// UserGroups returns the groups the user is a direct member of.
+func (c *Client) UserGroups(ctx context.Context, userIdent string) ([]Group, error) {
 	res, err := c.Request(ctx, http.MethodGet,
-		fmt.Sprintf("/api/v2/organizations/%s/groups", orgID.String()),
+		fmt.Sprintf("/api/v2/users/%s/groups", userIdent),
 		nil,
 	)
 	if err != nil {
@@ -51,9 +237,57 @@ func (c *Client) GroupsByOrganization(ctx context.Context, orgID uuid.UUID) ([]G
 	if res.StatusCode != http.StatusOK {
 		return nil, readBodyAsError(res)
 	}
+	var resp []Group
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+type GroupSyncDryRunRequest struct {
+	// User is the ID, username, or email of an existing user whose current
+	// memberships are compared with the claims. When empty, the user is
+	// assumed to belong to no groups.
+	User string `json:"user,omitempty"`
+	// Claims are the claims the identity provider would return for the
+	// user at login.
+	Claims map[string]interface{} `json:"claims"`
+}
+
+type GroupSyncChange struct {
+	// GroupID is empty for groups that would be created.
+	GroupID *uuid.UUID `json:"group_id,omitempty"`
+	Name    string     `json:"name"`
+}
+
+type GroupSyncDryRunResponse struct {
+	// Enabled is false when group sync isn't configured or the group
+	// claim is missing, in which case no groups would change.
+	Enabled bool `json:"enabled"`
+	// Groups are the group names read from the claims after filtering
+	// and mapping.
+	Groups []string          `json:"groups"`
+	Add    []GroupSyncChange `json:"add"`
+	Remove []GroupSyncChange `json:"remove"`
+	// Skipped lists manually managed groups whose names match the claims.
+	// Group sync never changes them.
+	Skipped []GroupSyncChange `json:"skipped"`
+}
+
+// GroupSyncDryRun reports the group memberships a login with the given
+// claims would change in an organization without changing anything.
+func (c *Client) GroupSyncDryRun(ctx context.Context, orgID uuid.UUID, req GroupSyncDryRunRequest) (GroupSyncDryRunResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/organizations/%s/groups/sync/dry-run", orgID.String()),
+		req,
+	)
+	if err != nil {
+		return GroupSyncDryRunResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
 
-	var groups []Group
-	return groups, json.NewDecoder(res.Body).Decode(&groups)
+	if res.StatusCode != http.StatusOK {
+		return GroupSyncDryRunResponse{}, readBodyAsError(res)
+	}
+	var resp GroupSyncDryRunResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
 func (c *Client) Group(ctx context.Context, group uuid.UUID) (Group, error) {
@@ -73,10 +307,79 @@ func (c *Client) Group(ctx context.Context, group uuid.UUID) (Group, error) {
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// GroupByOrgAndName returns the group with the given name in an organization.
+func (c *Client) GroupByOrgAndName(ctx context.Context, orgID uuid.UUID, name string) (Group, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/organizations/%s/groups/%s", orgID.String(), name),
+		nil,
+	)
+	if err != nil {
+		return Group{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Group{}, readBodyAsError(res)
+	}
+	var resp Group
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupTemplate is a template that a group has been granted a role on.
+type GroupTemplate struct {
+	ID             uuid.UUID    `json:"id"`
+	Name           string       `json:"name"`
+	Icon           string       `json:"icon"`
+	OrganizationID uuid.UUID    `json:"organization_id"`
+	Role           TemplateRole `json:"role"`
+}
+
+// GroupTemplates lists the templates the group has a role on. Roles are
+// granted and revoked with UpdateTemplateACL.
+func (c *Client) GroupTemplates(ctx context.Context, group uuid.UUID) ([]GroupTemplate, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/groups/%s/templates", group.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []GroupTemplate
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 type PatchGroupRequest struct {
+	// AddUsers and RemoveUsers accept user IDs, usernames, or emails.
 	AddUsers    []string `json:"add_users"`
 	RemoveUsers []string `json:"remove_users"`
-	Name        string   `json:"name"`
+	// AddUsersExpireAt makes the memberships in AddUsers temporary.
+	AddUsersExpireAt *time.Time `json:"add_users_expire_at,omitempty"`
+	Name             string     `json:"name"`
+	// DisplayName, AvatarURL, and Description are left unchanged when nil.
+	// An empty string clears them.
+	DisplayName *string `json:"display_name,omitempty"`
+	AvatarURL   *string `json:"avatar_url,omitempty" validate:"omitempty,url"`
+	Description *string `json:"description,omitempty"`
+	// ParentID moves the group under another group. Setting it to
+	// uuid.Nil makes the group a top-level group.
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	// Metadata replaces all of the group's metadata when set. An empty map
+	// clears it.
+	Metadata *map[string]string `json:"metadata,omitempty"`
+	// AutostopSchedule and MaxTTLMillis are left unchanged when nil. An
+	// empty schedule or zero TTL removes the policy.
+	AutostopSchedule *string `json:"autostop_schedule,omitempty"`
+	MaxTTLMillis     *int64  `json:"max_ttl_ms,omitempty"`
+	// QuotaAllowance is left unchanged when nil. Zero removes the budget.
+	QuotaAllowance *int32 `json:"quota_allowance,omitempty"`
+	// Roles replaces all of the roles granted to the group's members when
+	// set. An empty list removes them.
+	Roles *[]string `json:"roles,omitempty"`
 }
 
 func (c *Client) PatchGroup(ctx context.Context, group uuid.UUID, req PatchGroupRequest) (Group, error) {
@@ -96,6 +399,116 @@ func (c *Client) PatchGroup(ctx context.Context, group uuid.UUID, req PatchGroup
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// MaxGroupMetadataEntries is the maximum number of metadata entries a group
+// can have.
+const MaxGroupMetadataEntries = 64
+
+// MaxGroupMembersPerRequest is the maximum number of user IDs accepted by
+// PutGroupMembers.
+const MaxGroupMembersPerRequest = 10000
+
+type PutGroupMembersRequest struct {
+	// UserIDs is the complete list of users that should belong to the
+	// group. Existing members that are not listed are removed.
+	UserIDs []string `json:"user_ids"`
+}
+
+type GroupMemberSkipReason string
+
+const (
+	GroupMemberSkipReasonInvalidID    GroupMemberSkipReason = "invalid_id"
+	GroupMemberSkipReasonDuplicate    GroupMemberSkipReason = "duplicate"
+	GroupMemberSkipReasonNotOrgMember GroupMemberSkipReason = "not_org_member"
+	GroupMemberSkipReasonUnknownUser  GroupMemberSkipReason = "unknown_user"
+)
+
+type SkippedGroupMember struct {
+	UserID string                `json:"user_id"`
+	Reason GroupMemberSkipReason `json:"reason"`
+}
+
+type PutGroupMembersResponse struct {
+	Group   Group                `json:"group"`
+	Added   []uuid.UUID          `json:"added"`
+	Removed []uuid.UUID          `json:"removed"`
+	Skipped []SkippedGroupMember `json:"skipped"`
+}
+
+// PutGroupMembers replaces the members of a group in a single request.
+// Entries that can't be applied are reported in the response rather than
+// failing the whole request.
+func (c *Client) PutGroupMembers(ctx context.Context, group uuid.UUID, req PutGroupMembersRequest) (PutGroupMembersResponse, error) {
+	res, err := c.Request(ctx, http.MethodPut,
+		fmt.Sprintf("/api/v2/groups/%s/members", group.String()),
+		req,
+	)
+	if err != nil {
+		return PutGroupMembersResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return PutGroupMembersResponse{}, readBodyAsError(res)
+	}
+	var resp PutGroupMembersResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupMemberImportError describes a CSV row that couldn't be imported.
+type GroupMemberImportError struct {
+	// Row is the 1-indexed line of the CSV file the row starts on.
+	Row    int                   `json:"row"`
+	Value  string                `json:"value"`
+	Reason GroupMemberSkipReason `json:"reason"`
+}
+
+type ImportGroupMembersResponse struct {
+	Group  Group                    `json:"group"`
+	Added  []uuid.UUID              `json:"added"`
+	Errors []GroupMemberImportError `json:"errors"`
+}
+
+// ImportGroupMembers adds the users listed in a CSV file to a group. The
+// first column of each row is a username or email; a leading header row
+// is ignored. Rows that can't be imported are reported in the response
+// rather than failing the whole request.
+func (c *Client) ImportGroupMembers(ctx context.Context, group uuid.UUID, data []byte) (ImportGroupMembersResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/groups/%s/members/import", group.String()),
+		data, func(r *http.Request) {
+			r.Header.Set("Content-Type", "text/csv")
+		},
+	)
+	if err != nil {
+		return ImportGroupMembersResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ImportGroupMembersResponse{}, readBodyAsError(res)
+	}
+	var resp ImportGroupMembersResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ExportGroupMembers returns the members of a group as a CSV file with
+// the columns id, username, email, and expires_at.
+func (c *Client) ExportGroupMembers(ctx context.Context, group uuid.UUID) ([]byte, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/groups/%s/members/export", group.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	return io.ReadAll(res.Body)
+}
+
 func (c *Client) DeleteGroup(ctx context.Context, group uuid.UUID) error {
 	res, err := c.Request(ctx, http.MethodDelete,
 		fmt.Sprintf("/api/v2/groups/%s", group.String()),
@@ -111,3 +524,335 @@ func (c *Client) DeleteGroup(ctx context.Context, group uuid.UUID) error {
 	}
 	return nil
 }
+
+// RestoreGroup undoes the deletion of a group.
+func (c *Client) RestoreGroup(ctx context.Context, group uuid.UUID) (Group, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/groups/%s/restore", group.String()),
+		nil,
+	)
+	if err != nil {
+		return Group{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Group{}, readBodyAsError(res)
+	}
+	var resp Group
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// UpdateGroupMemberRoles grants the user the specified roles in a group.
+// Include ALL roles the user has in the group.
+func (c *Client) UpdateGroupMemberRoles(ctx context.Context, group uuid.UUID, user string, req UpdateRoles) (GroupMember, error) {
+	res, err := c.Request(ctx, http.MethodPut,
+		fmt.Sprintf("/api/v2/groups/%s/members/%s/roles", group.String(), user),
+		req,
+	)
+	if err != nil {
+		return GroupMember{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GroupMember{}, readBodyAsError(res)
+	}
+	var resp GroupMember
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupDeletionImpact describes what would lose access if a group were
+// deleted.
+type GroupDeletionImpact struct {
+	// Templates are the templates that grant the group access.
+	Templates []GroupDeletionImpactTemplate `json:"templates"`
+	// Workspaces are built from those templates and owned by members of
+	// the group or of any group nested beneath it. Their owners may lose
+	// access unless it's granted some other way.
+	Workspaces []GroupDeletionImpactWorkspace `json:"workspaces"`
+	// ChildGroups are nested directly under the group and would no longer
+	// inherit its access.
+	ChildGroups []uuid.UUID `json:"child_groups"`
+	// QuotaAllowance is the quota allowance each member of the group, or of
+	// any group nested beneath it, would lose.
+	QuotaAllowance int32 `json:"quota_allowance"`
+	// QuotaAllowanceTotal is QuotaAllowance summed across those members.
+	QuotaAllowanceTotal int64 `json:"quota_allowance_total"`
+}
+
+type GroupDeletionImpactTemplate struct {
+	ID   uuid.UUID    `json:"id"`
+	Name string       `json:"name"`
+	Role TemplateRole `json:"role"`
+}
+
+type GroupDeletionImpactWorkspace struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	OwnerID    uuid.UUID `json:"owner_id"`
+	TemplateID uuid.UUID `json:"template_id"`
+}
+
+// GroupDeletionImpact reports what deleting the group would affect without
+// deleting it.
+func (c *Client) GroupDeletionImpact(ctx context.Context, group uuid.UUID) (GroupDeletionImpact, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/groups/%s/deletion-impact", group.String()),
+		nil,
+	)
+	if err != nil {
+		return GroupDeletionImpact{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return GroupDeletionImpact{}, readBodyAsError(res)
+	}
+	var resp GroupDeletionImpact
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupJoinRequest is a pending request from a user to join a group.
+type GroupJoinRequest struct {
+	ID        uuid.UUID `json:"id"`
+	GroupID   uuid.UUID `json:"group_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateGroupJoinRequest asks the group's admins to add the authenticated
+// user to the group.
+func (c *Client) CreateGroupJoinRequest(ctx context.Context, group uuid.UUID) (GroupJoinRequest, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/groups/%s/join-requests", group.String()),
+		nil,
+	)
+	if err != nil {
+		return GroupJoinRequest{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return GroupJoinRequest{}, readBodyAsError(res)
+	}
+	var resp GroupJoinRequest
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupJoinRequests lists the pending join requests of a group, oldest first.
+func (c *Client) GroupJoinRequests(ctx context.Context, group uuid.UUID) ([]GroupJoinRequest, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/groups/%s/join-requests", group.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []GroupJoinRequest
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ApproveGroupJoinRequest adds the requesting user to the group and removes
+// the join request.
+func (c *Client) ApproveGroupJoinRequest(ctx context.Context, group, request uuid.UUID) (Group, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/groups/%s/join-requests/%s/approve", group.String(), request.String()),
+		nil,
+	)
+	if err != nil {
+		return Group{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Group{}, readBodyAsError(res)
+	}
+	var resp Group
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// DenyGroupJoinRequest removes the join request without adding the user to
+// the group.
+func (c *Client) DenyGroupJoinRequest(ctx context.Context, group, request uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/groups/%s/join-requests/%s/deny", group.String(), request.String()),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// GroupWebhookEventType is the kind of group change a webhook is notified
+// about.
+type GroupWebhookEventType string
+
+const (
+	GroupWebhookEventGroupCreated   GroupWebhookEventType = "group.created"
+	GroupWebhookEventGroupDeleted   GroupWebhookEventType = "group.deleted"
+	GroupWebhookEventGroupRenamed   GroupWebhookEventType = "group.renamed"
+	GroupWebhookEventMembersAdded   GroupWebhookEventType = "group.members_added"
+	GroupWebhookEventMembersRemoved GroupWebhookEventType = "group.members_removed"
+)
+
+const (
+	// Deprecated: use WebhookEventHeader.
+	GroupWebhookEventHeader = WebhookEventHeader
+	// Deprecated: use WebhookSignatureHeader.
+	GroupWebhookSignatureHeader = WebhookSignatureHeader
+)
+
+// GroupWebhookEvent is the JSON body delivered to group webhooks.
+type GroupWebhookEvent struct {
+	ID                uuid.UUID             `json:"id"`
+	Type              GroupWebhookEventType `json:"type"`
+	CreatedAt         time.Time             `json:"created_at"`
+	OrganizationID    uuid.UUID             `json:"organization_id"`
+	GroupID           uuid.UUID             `json:"group_id"`
+	GroupName         string                `json:"group_name"`
+	PreviousGroupName string                `json:"previous_group_name,omitempty"`
+	UserIDs           []uuid.UUID           `json:"user_ids,omitempty"`
+}
+
+// GroupWebhook is an endpoint that receives group change events for an
+// organization.
+type GroupWebhook struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	URL            string    `json:"url"`
+	// Secret is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateGroupWebhookRequest struct {
+	URL string `json:"url" validate:"required,url"`
+	// Secret is used to sign deliveries. One is generated if empty.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (c *Client) CreateGroupWebhook(ctx context.Context, orgID uuid.UUID, req CreateGroupWebhookRequest) (GroupWebhook, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/organizations/%s/group-webhooks", orgID.String()),
+		req,
+	)
+	if err != nil {
+		return GroupWebhook{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return GroupWebhook{}, readBodyAsError(res)
+	}
+	var resp GroupWebhook
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// GroupWebhooks lists the group webhooks of an organization, oldest first.
+func (c *Client) GroupWebhooks(ctx context.Context, orgID uuid.UUID) ([]GroupWebhook, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/organizations/%s/group-webhooks", orgID.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []GroupWebhook
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+func (c *Client) DeleteGroupWebhook(ctx context.Context, orgID, webhook uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete,
+		fmt.Sprintf("/api/v2/organizations/%s/group-webhooks/%s", orgID.String(), webhook.String()),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// EveryoneGroupExclusion is an organization member that is left out of the
+// organization's "Everyone" group. Excluded members aren't granted the
+// permissions given to the group.
+type EveryoneGroupExclusion struct {
+	User      User      `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EveryoneGroupExclusions lists the members excluded from the organization's
+// "Everyone" group, oldest first.
+func (c *Client) EveryoneGroupExclusions(ctx context.Context, orgID uuid.UUID) ([]EveryoneGroupExclusion, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/organizations/%s/everyone-exclusions", orgID.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []EveryoneGroupExclusion
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ExcludeFromEveryoneGroup leaves the user out of the organization's
+// "Everyone" group. The user can be a username or ID.
+func (c *Client) ExcludeFromEveryoneGroup(ctx context.Context, orgID uuid.UUID, user string) error {
+	res, err := c.Request(ctx, http.MethodPut,
+		fmt.Sprintf("/api/v2/organizations/%s/everyone-exclusions/%s", orgID.String(), user),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// IncludeInEveryoneGroup reverts ExcludeFromEveryoneGroup.
+func (c *Client) IncludeInEveryoneGroup(ctx context.Context, orgID uuid.UUID, user string) error {
+	res, err := c.Request(ctx, http.MethodDelete,
+		fmt.Sprintf("/api/v2/organizations/%s/everyone-exclusions/%s", orgID.String(), user),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
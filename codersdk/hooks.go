@@ -0,0 +1,47 @@
+package codersdk
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// RequestAttempt describes an attempt to send a request with
+// (*Client).Request.
+// @typescript-ignore RequestAttempt
+type RequestAttempt struct {
+	// Attempt starts at 1 and increases with every retry of the request.
+	Attempt int
+	// Latency is how long the server took to respond. It's zero before the
+	// request is sent.
+	Latency time.Duration
+}
+
+// BeforeRequestHook runs before every attempt of a request, e.g. to add
+// authentication headers. Returning an error cancels the request.
+type BeforeRequestHook func(req *http.Request, attempt RequestAttempt) error
+
+// AfterResponseHook runs after every attempt of a request, e.g. to record
+// metrics or log requests. err is set if no response was received. Hooks
+// must not read or close the response body.
+type AfterResponseHook func(req *http.Request, res *http.Response, err error, attempt RequestAttempt)
+
+// doAttempt sends the request once, running the hooks of the client around
+// it.
+func (c *Client) doAttempt(req *http.Request, attempt int) (*http.Response, error) {
+	info := RequestAttempt{Attempt: attempt}
+	for _, hook := range c.BeforeRequest {
+		err := hook(req, info)
+		if err != nil {
+			return nil, xerrors.Errorf("before request hook: %w", err)
+		}
+	}
+	start := time.Now()
+	res, err := c.HTTPClient.Do(req)
+	info.Latency = time.Since(start)
+	for _, hook := range c.AfterResponse {
+		hook(req, res, err, info)
+	}
+	return res, err
+}
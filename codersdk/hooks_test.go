@@ -0,0 +1,85 @@
+package codersdk_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(t *testing.T) (*codersdk.Client, *int64) {
+		t.Helper()
+		var requests int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt64(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("X-Echo", r.Header.Get("X-Custom"))
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := codersdk.New(serverURL)
+		client.Retry = codersdk.RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}
+		return client, &requests
+	}
+
+	t.Run("Attempts", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, _ := newClient(t)
+
+		var (
+			before   []int
+			after    []int
+			statuses []int
+		)
+		client.BeforeRequest = append(client.BeforeRequest, func(req *http.Request, attempt codersdk.RequestAttempt) error {
+			before = append(before, attempt.Attempt)
+			require.Zero(t, attempt.Latency)
+			req.Header.Set("X-Custom", "value")
+			return nil
+		})
+		client.AfterResponse = append(client.AfterResponse, func(req *http.Request, res *http.Response, err error, attempt codersdk.RequestAttempt) {
+			require.NoError(t, err)
+			after = append(after, attempt.Attempt)
+			statuses = append(statuses, res.StatusCode)
+			require.Positive(t, attempt.Latency)
+		})
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, "value", res.Header.Get("X-Echo"))
+		require.Equal(t, []int{1, 2}, before)
+		require.Equal(t, []int{1, 2}, after)
+		require.Equal(t, []int{http.StatusServiceUnavailable, http.StatusOK}, statuses)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, requests := newClient(t)
+		client.BeforeRequest = append(client.BeforeRequest, func(*http.Request, codersdk.RequestAttempt) error {
+			return xerrors.New("no token")
+		})
+
+		//nolint:bodyclose
+		_, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.ErrorContains(t, err, "no token")
+		require.Zero(t, atomic.LoadInt64(requests))
+	})
+}
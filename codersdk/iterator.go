@@ -0,0 +1,82 @@
+package codersdk
+
+import (
+	"context"
+)
+
+// IteratorPageSize is the number of results an Iterator fetches per request.
+const IteratorPageSize = 100
+
+// Iterator pages through the results of a list endpoint that supports
+// cursor pagination, fetching the next page as it's needed.
+//
+//	it := client.GroupsIterator(ctx, orgID)
+//	for it.Next() {
+//		group := it.Value()
+//	}
+//	if it.Err() != nil {
+//		...
+//	}
+//
+// @typescript-ignore Iterator
+type Iterator[T any] struct {
+	ctx context.Context
+	// fetch returns the page that starts at cursor, and the cursor of the
+	// next page, which is empty on the last page.
+	fetch func(ctx context.Context, cursor string) ([]T, string, error)
+
+	page   []T
+	index  int
+	cursor string
+	done   bool
+	err    error
+}
+
+func newIterator[T any](ctx context.Context, fetch func(ctx context.Context, cursor string) ([]T, string, error)) *Iterator[T] {
+	return &Iterator[T]{
+		ctx:   ctx,
+		fetch: fetch,
+		index: -1,
+	}
+}
+
+// Next advances to the next result, fetching another page if required. It
+// returns false once every result has been returned or an error occurs.
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.page) {
+		it.index++
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	page, next, err := it.fetch(it.ctx, it.cursor)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.done = next == ""
+	it.cursor = next
+	it.page = page
+	it.index = 0
+	// A page can be empty without being the last one, if none of its
+	// results could be read.
+	if len(it.page) == 0 && !it.done {
+		return it.Next()
+	}
+	return len(it.page) > 0
+}
+
+// Value returns the current result. It's only valid after Next returns true.
+func (it *Iterator[T]) Value() T {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
@@ -0,0 +1,99 @@
+//nolint:testpackage
+package codersdk
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestIterator(t *testing.T) {
+	t.Parallel()
+
+	ids := make([]uuid.UUID, IteratorPageSize*2+1)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	fetch := func(_ context.Context, cursor string) ([]uuid.UUID, string, error) {
+		start := 0
+		if cursor != "" {
+			var err error
+			start, err = strconv.Atoi(cursor)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		end := start + IteratorPageSize
+		if end >= len(ids) {
+			return ids[start:], "", nil
+		}
+		return ids[start:end], strconv.Itoa(end), nil
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		t.Parallel()
+
+		it := newIterator(context.Background(), fetch)
+		var got []uuid.UUID
+		for it.Next() {
+			got = append(got, it.Value())
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, ids, got)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		it := newIterator(context.Background(), func(context.Context, string) ([]uuid.UUID, string, error) {
+			return nil, "", nil
+		})
+		require.False(t, it.Next())
+		require.NoError(t, it.Err())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		it := newIterator(context.Background(), func(ctx context.Context, cursor string) ([]uuid.UUID, string, error) {
+			calls++
+			if calls > 1 {
+				return nil, "", xerrors.New("boom")
+			}
+			return fetch(ctx, cursor)
+		})
+		count := 0
+		for it.Next() {
+			count++
+		}
+		require.Equal(t, IteratorPageSize, count)
+		require.ErrorContains(t, it.Err(), "boom")
+		require.False(t, it.Next())
+	})
+
+	t.Run("EmptyPage", func(t *testing.T) {
+		t.Parallel()
+
+		// Pages can be empty when none of their results are readable, but
+		// still have a next page.
+		calls := 0
+		it := newIterator(context.Background(), func(ctx context.Context, cursor string) ([]uuid.UUID, string, error) {
+			calls++
+			if calls == 1 {
+				return nil, "0", nil
+			}
+			return fetch(ctx, cursor)
+		})
+		var got []uuid.UUID
+		for it.Next() {
+			got = append(got, it.Value())
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, ids, got)
+	})
+}
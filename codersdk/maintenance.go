@@ -0,0 +1,61 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Maintenance describes whether the deployment is in maintenance mode. While
+// it is, the API rejects requests from everyone but owners with a 503 and
+// the ErrorCodeMaintenance code, so upgrades and database migrations don't
+// cause unrelated errors.
+type Maintenance struct {
+	Enabled bool `json:"enabled"`
+	// Message is shown to users, e.g. the reason and expected duration.
+	Message string `json:"message"`
+	// RetryAfterSeconds is sent in the Retry-After header of rejected
+	// requests.
+	RetryAfterSeconds int64     `json:"retry_after_seconds"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type UpdateMaintenanceRequest struct {
+	Enabled           bool   `json:"enabled"`
+	Message           string `json:"message" validate:"max=1024"`
+	RetryAfterSeconds int64  `json:"retry_after_seconds" validate:"min=0"`
+}
+
+// Maintenance returns whether the deployment is in maintenance mode. It
+// doesn't require authentication.
+func (c *Client) Maintenance(ctx context.Context) (Maintenance, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/maintenance", nil)
+	if err != nil {
+		return Maintenance{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Maintenance{}, readBodyAsError(res)
+	}
+	var maintenance Maintenance
+	return maintenance, json.NewDecoder(res.Body).Decode(&maintenance)
+}
+
+// UpdateMaintenance enables or disables maintenance mode.
+func (c *Client) UpdateMaintenance(ctx context.Context, req UpdateMaintenanceRequest) (Maintenance, error) {
+	res, err := c.Request(ctx, http.MethodPut, "/api/v2/maintenance", req)
+	if err != nil {
+		return Maintenance{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Maintenance{}, readBodyAsError(res)
+	}
+	var maintenance Maintenance
+	return maintenance, json.NewDecoder(res.Body).Decode(&maintenance)
+}
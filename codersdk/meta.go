@@ -0,0 +1,124 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// APIVersion is the version of the REST API served under /api/<version>.
+const APIVersion = "v2"
+
+// Capability is a named API feature that a server supports. Clients should
+// check for capabilities instead of comparing server versions.
+type Capability string
+
+const (
+	// CapabilityErrorCodes indicates responses carry a machine-readable
+	// Code field.
+	CapabilityErrorCodes Capability = "error_codes"
+	// CapabilityOrganizationMembers indicates the organization members
+	// listing endpoint is available.
+	CapabilityOrganizationMembers Capability = "organization_members"
+	// CapabilityDeprecationHeaders indicates deprecated routes are marked
+	// with Deprecation and Sunset response headers.
+	CapabilityDeprecationHeaders Capability = "deprecation_headers"
+	// CapabilityOpenAPI indicates an OpenAPI document is served at
+	// /api/v2/openapi.json.
+	CapabilityOpenAPI Capability = "openapi"
+	// CapabilityGroups indicates the groups API is available.
+	CapabilityGroups Capability = "groups"
+	// CapabilityRPC indicates the dRPC service defined in codersdk/proto is
+	// served at /api/v2/rpc.
+	CapabilityRPC Capability = "rpc"
+)
+
+// APIMeta describes the API surface supported by a server.
+type APIMeta struct {
+	// APIVersion is the REST API version, e.g. "v2".
+	APIVersion string `json:"api_version"`
+	// Version is the semantic version of the server build.
+	Version string `json:"version"`
+	// Capabilities are the API features supported by this server.
+	Capabilities []Capability `json:"capabilities"`
+	// Experimental is true if experimental features are enabled.
+	Experimental bool `json:"experimental"`
+	// Deprecations lists routes that are scheduled for removal.
+	Deprecations []APIDeprecation `json:"deprecations"`
+}
+
+// APIDeprecation describes a deprecated route.
+type APIDeprecation struct {
+	Method    string     `json:"method"`
+	Path      string     `json:"path"`
+	Since     *time.Time `json:"since,omitempty"`
+	Sunset    *time.Time `json:"sunset,omitempty"`
+	Successor string     `json:"successor,omitempty"`
+}
+
+// DeprecationReport is the usage of deprecated routes on the replica that
+// served the request.
+type DeprecationReport struct {
+	// Since is when the replica started counting.
+	Since  time.Time              `json:"since"`
+	Routes []DeprecatedRouteUsage `json:"routes"`
+}
+
+// DeprecatedRouteUsage is the usage of a deprecated route.
+type DeprecatedRouteUsage struct {
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Requests   int64     `json:"requests"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// UserAgents are the first distinct user agents that used the route.
+	UserAgents []string `json:"user_agents"`
+}
+
+// HasCapability returns true if the server advertised the capability.
+func (m APIMeta) HasCapability(capability Capability) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// APIMeta returns the API capabilities of the server.
+func (c *Client) APIMeta(ctx context.Context) (APIMeta, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/meta", nil)
+	if err != nil {
+		return APIMeta{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return APIMeta{}, readBodyAsError(res)
+	}
+
+	var meta APIMeta
+	return meta, json.NewDecoder(res.Body).Decode(&meta)
+}
+
+// DeprecationReport returns the usage of deprecated routes.
+func (c *Client) DeprecationReport(ctx context.Context) (DeprecationReport, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/meta/deprecations", nil)
+	if err != nil {
+		return DeprecationReport{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return DeprecationReport{}, readBodyAsError(res)
+	}
+
+	var report DeprecationReport
+	return report, json.NewDecoder(res.Body).Decode(&report)
+}
+
+// IsDeprecated returns true if the response was served by a deprecated
+// route.
+func IsDeprecated(res *http.Response) bool {
+	return res.Header.Get("Deprecation") != ""
+}
@@ -0,0 +1,270 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OAuth2ProviderApp is an application that users can sign in to with Coder.
+// Its ID is the client ID of the application.
+type OAuth2ProviderApp struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Icon        string    `json:"icon"`
+	CallbackURL string    `json:"callback_url"`
+	// ClientSecret is only set in the responses to creating the app and
+	// regenerating its secret.
+	ClientSecret string    `json:"client_secret,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type CreateOAuth2ProviderAppRequest struct {
+	Name string `json:"name" validate:"required,username"`
+	Icon string `json:"icon,omitempty"`
+	// CallbackURL is where users are sent after they authorize the app.
+	// Redirect URIs of authorization requests must be this URL or below it.
+	CallbackURL string `json:"callback_url" validate:"required,url"`
+}
+
+type UpdateOAuth2ProviderAppRequest struct {
+	Name        string `json:"name" validate:"required,username"`
+	Icon        string `json:"icon,omitempty"`
+	CallbackURL string `json:"callback_url" validate:"required,url"`
+}
+
+// OAuth2AuthorizeRequest holds the query parameters an application sends
+// users to the consent screen with.
+type OAuth2AuthorizeRequest struct {
+	ClientID uuid.UUID `json:"client_id"`
+	// RedirectURI defaults to the callback URL of the app.
+	RedirectURI string `json:"redirect_uri,omitempty"`
+	// Scope is a space separated list. It's "all", "application_connect", or
+	// "<resource>:<action>" permissions, and defaults to "all".
+	Scope string `json:"scope,omitempty"`
+	State string `json:"state,omitempty"`
+	// CodeChallenge and CodeChallengeMethod enable PKCE, as defined by RFC
+	// 7636. The method is "S256" or "plain", and defaults to "plain".
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+}
+
+// OAuth2Authorization is what the consent screen asks the user to allow.
+type OAuth2Authorization struct {
+	App         OAuth2ProviderApp `json:"app"`
+	Scopes      []string          `json:"scopes"`
+	RedirectURI string            `json:"redirect_uri"`
+}
+
+// OAuth2AuthorizeResponse is where the consent screen sends the user after
+// they allowed the app. The URL includes the authorization code and state.
+type OAuth2AuthorizeResponse struct {
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// OAuth2TokenResponse is the response of the token endpoint. The access token
+// is an API key of the user that authorized the app.
+type OAuth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// OAuth2Error is the response of the token and introspection endpoints when
+// a request fails, as defined by RFC 6749.
+type OAuth2Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuth2Introspection describes a token, as defined by RFC 7662. Inactive
+// tokens only report that they're inactive.
+type OAuth2Introspection struct {
+	Active bool   `json:"active"`
+	Scope  string `json:"scope,omitempty"`
+	// ClientID is set if the token was issued to an app.
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	// Subject is the ID of the user the token belongs to.
+	Subject string `json:"sub,omitempty"`
+}
+
+// OAuth2UserInfo identifies the user of a token, with the standard OpenID
+// Connect claims.
+type OAuth2UserInfo struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email"`
+	Picture           string `json:"picture,omitempty"`
+}
+
+// OAuth2ProviderApps lists the apps users can sign in to with Coder.
+func (c *Client) OAuth2ProviderApps(ctx context.Context) ([]OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/oauth2-provider/apps", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var apps []OAuth2ProviderApp
+	return apps, json.NewDecoder(res.Body).Decode(&apps)
+}
+
+func (c *Client) OAuth2ProviderApp(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s", id), nil)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OAuth2ProviderApp{}, readBodyAsError(res)
+	}
+	var app OAuth2ProviderApp
+	return app, json.NewDecoder(res.Body).Decode(&app)
+}
+
+func (c *Client) CreateOAuth2ProviderApp(ctx context.Context, req CreateOAuth2ProviderAppRequest) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/oauth2-provider/apps", req)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return OAuth2ProviderApp{}, readBodyAsError(res)
+	}
+	var app OAuth2ProviderApp
+	return app, json.NewDecoder(res.Body).Decode(&app)
+}
+
+func (c *Client) UpdateOAuth2ProviderApp(ctx context.Context, id uuid.UUID, req UpdateOAuth2ProviderAppRequest) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s", id), req)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OAuth2ProviderApp{}, readBodyAsError(res)
+	}
+	var app OAuth2ProviderApp
+	return app, json.NewDecoder(res.Body).Decode(&app)
+}
+
+// DeleteOAuth2ProviderApp deletes the app along with the tokens that were
+// issued to it.
+func (c *Client) DeleteOAuth2ProviderApp(ctx context.Context, id uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s", id), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// RegenerateOAuth2ProviderAppSecret replaces the client secret of the app.
+// The previous secret stops working immediately.
+func (c *Client) RegenerateOAuth2ProviderAppSecret(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/oauth2-provider/apps/%s/secret", id), nil)
+	if err != nil {
+		return OAuth2ProviderApp{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OAuth2ProviderApp{}, readBodyAsError(res)
+	}
+	var app OAuth2ProviderApp
+	return app, json.NewDecoder(res.Body).Decode(&app)
+}
+
+// OAuth2Authorization returns what the consent screen for the request asks
+// the user to allow.
+func (c *Client) OAuth2Authorization(ctx context.Context, req OAuth2AuthorizeRequest) (OAuth2Authorization, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/oauth2/authorize", nil, req.asRequestOption())
+	if err != nil {
+		return OAuth2Authorization{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OAuth2Authorization{}, readBodyAsError(res)
+	}
+	var authorization OAuth2Authorization
+	return authorization, json.NewDecoder(res.Body).Decode(&authorization)
+}
+
+// OAuth2Authorize allows the app to act on behalf of the user. The user
+// should be sent to the redirect URI of the response, where the app exchanges
+// the code for a token.
+func (c *Client) OAuth2Authorize(ctx context.Context, req OAuth2AuthorizeRequest) (OAuth2AuthorizeResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/oauth2/authorize", nil, req.asRequestOption())
+	if err != nil {
+		return OAuth2AuthorizeResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OAuth2AuthorizeResponse{}, readBodyAsError(res)
+	}
+	var resp OAuth2AuthorizeResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// OAuth2UserInfo returns the user the session token belongs to.
+func (c *Client) OAuth2UserInfo(ctx context.Context) (OAuth2UserInfo, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/oauth2/userinfo", nil)
+	if err != nil {
+		return OAuth2UserInfo{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OAuth2UserInfo{}, readBodyAsError(res)
+	}
+	var info OAuth2UserInfo
+	return info, json.NewDecoder(res.Body).Decode(&info)
+}
+
+func (req OAuth2AuthorizeRequest) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := url.Values{}
+		q.Set("response_type", "code")
+		q.Set("client_id", req.ClientID.String())
+		if req.RedirectURI != "" {
+			q.Set("redirect_uri", req.RedirectURI)
+		}
+		if req.Scope != "" {
+			q.Set("scope", req.Scope)
+		}
+		if req.State != "" {
+			q.Set("state", req.State)
+		}
+		if req.CodeChallenge != "" {
+			q.Set("code_challenge", req.CodeChallenge)
+		}
+		if req.CodeChallengeMethod != "" {
+			q.Set("code_challenge_method", req.CodeChallengeMethod)
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
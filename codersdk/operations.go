@@ -0,0 +1,68 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type OperationType string
+
+const (
+	OperationTypeOrganizationDeletion OperationType = "organization_deletion"
+	OperationTypeWorkspaceBatch       OperationType = "workspace_batch"
+)
+
+type OperationStatus string
+
+const (
+	OperationStatusRunning   OperationStatus = "running"
+	OperationStatusSucceeded OperationStatus = "succeeded"
+	OperationStatusFailed    OperationStatus = "failed"
+)
+
+// Operation is a long-running action that continues in the background after
+// the request that started it returned 202 Accepted. Poll it until it's no
+// longer running.
+type Operation struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        OperationType   `json:"type"`
+	Status      OperationStatus `json:"status"`
+	InitiatorID uuid.UUID       `json:"initiator_id"`
+	// OrganizationID is empty for deployment-wide operations.
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// ResourceID is the resource the operation acts on, e.g. the
+	// organization being deleted. It's empty for operations that act on
+	// many resources.
+	ResourceID uuid.UUID `json:"resource_id"`
+	// ProgressCompleted counts the steps that are done out of
+	// ProgressTotal. The total can grow while the operation runs.
+	ProgressCompleted int64 `json:"progress_completed"`
+	ProgressTotal     int64 `json:"progress_total"`
+	// Error is set when the operation failed.
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Operation returns the progress of a long-running action. It's still
+// available after the action finished.
+func (c *Client) Operation(ctx context.Context, id uuid.UUID) (Operation, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/operations/%s", id.String()), nil)
+	if err != nil {
+		return Operation{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Operation{}, readBodyAsError(res)
+	}
+	var operation Operation
+	return operation, json.NewDecoder(res.Body).Decode(&operation)
+}
@@ -0,0 +1,60 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type OrganizationDeletionStatus string
+
+const (
+	OrganizationDeletionStatusRunning   OrganizationDeletionStatus = "running"
+	OrganizationDeletionStatusSucceeded OrganizationDeletionStatus = "succeeded"
+	OrganizationDeletionStatusFailed    OrganizationDeletionStatus = "failed"
+)
+
+// OrganizationDeletion is the progress of deleting an organization in the
+// background. Workspaces are deleted with builds first, then templates and
+// members, and then the organization itself.
+type OrganizationDeletion struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	InitiatorID    uuid.UUID `json:"initiator_id"`
+	// OperationID is the operation that reports the progress of the
+	// deletion. It's empty for deletions started before operations existed.
+	OperationID uuid.UUID                  `json:"operation_id"`
+	Status      OrganizationDeletionStatus `json:"status"`
+	// Error is set when the deletion failed, for example because a workspace
+	// failed to delete. Deleting the organization again resumes it.
+	Error             string     `json:"error,omitempty"`
+	WorkspacesTotal   int64      `json:"workspaces_total"`
+	WorkspacesDeleted int64      `json:"workspaces_deleted"`
+	TemplatesTotal    int64      `json:"templates_total"`
+	TemplatesDeleted  int64      `json:"templates_deleted"`
+	MembersTotal      int64      `json:"members_total"`
+	MembersDeleted    int64      `json:"members_deleted"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+}
+
+// OrganizationDeletionStatus returns the progress of deleting an
+// organization. It's still available after the organization is gone.
+func (c *Client) OrganizationDeletionStatus(ctx context.Context, organizationID uuid.UUID) (OrganizationDeletion, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/deletion-status", organizationID.String()), nil)
+	if err != nil {
+		return OrganizationDeletion{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationDeletion{}, readBodyAsError(res)
+	}
+	var deletion OrganizationDeletion
+	return deletion, json.NewDecoder(res.Body).Decode(&deletion)
+}
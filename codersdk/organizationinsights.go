@@ -0,0 +1,75 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type OrganizationInsightsRequest struct {
+	// StartTime defaults to 30 days before the end time.
+	StartTime time.Time `json:"start_time,omitempty"`
+	// EndTime defaults to now.
+	EndTime time.Time `json:"end_time,omitempty"`
+}
+
+// OrganizationInsights summarizes how an organization used the deployment
+// over a time range.
+type OrganizationInsights struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	// ActiveUsers is the number of users that connected to a workspace.
+	// Connections are only kept for 30 days, so older ranges report fewer
+	// users than were active.
+	ActiveUsers int64 `json:"active_users"`
+	// WorkspaceHours is the time workspaces were running, summed over every
+	// workspace.
+	WorkspaceHours float64 `json:"workspace_hours"`
+	Builds         int64   `json:"builds"`
+	FailedBuilds   int64   `json:"failed_builds"`
+	// Templates breaks the usage down by template. Templates without usage
+	// in the range are omitted.
+	Templates []OrganizationTemplateInsights `json:"templates"`
+}
+
+type OrganizationTemplateInsights struct {
+	TemplateID     uuid.UUID `json:"template_id"`
+	TemplateName   string    `json:"template_name"`
+	ActiveUsers    int64     `json:"active_users"`
+	WorkspaceHours float64   `json:"workspace_hours"`
+	Builds         int64     `json:"builds"`
+	FailedBuilds   int64     `json:"failed_builds"`
+}
+
+// OrganizationInsights returns the usage of an organization over a time
+// range.
+func (c *Client) OrganizationInsights(ctx context.Context, organizationID uuid.UUID, req OrganizationInsightsRequest) (OrganizationInsights, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/insights", organizationID.String()), nil,
+		func(r *http.Request) {
+			q := r.URL.Query()
+			if !req.StartTime.IsZero() {
+				q.Set("start_time", req.StartTime.Format(time.RFC3339))
+			}
+			if !req.EndTime.IsZero() {
+				q.Set("end_time", req.EndTime.Format(time.RFC3339))
+			}
+			r.URL.RawQuery = q.Encode()
+		},
+	)
+	if err != nil {
+		return OrganizationInsights{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationInsights{}, readBodyAsError(res)
+	}
+	var insights OrganizationInsights
+	return insights, json.NewDecoder(res.Body).Decode(&insights)
+}
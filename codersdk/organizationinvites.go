@@ -0,0 +1,114 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OrganizationInvite lets users join an organization without an admin
+// adding them.
+type OrganizationInvite struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// EmailDomain restricts the invite to users with an email address in the
+	// domain. It's empty if anyone can redeem the invite. Only users that
+	// signed in with GitHub or OIDC can redeem invites restricted to a domain,
+	// since the email addresses of other users aren't verified.
+	EmailDomain string `json:"email_domain"`
+	// Roles are the organization roles granted to users that redeem the
+	// invite, in addition to the organization member role.
+	Roles     []string   `json:"roles"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// MaxUses is the number of times the invite can be redeemed. It's 0 if
+	// the invite can be redeemed any number of times.
+	MaxUses int32 `json:"max_uses"`
+	Uses    int32 `json:"uses"`
+	// Token is only returned when the invite is created.
+	Token string `json:"token,omitempty"`
+}
+
+type CreateOrganizationInviteRequest struct {
+	EmailDomain string     `json:"email_domain"`
+	Roles       []string   `json:"roles"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxUses     int32      `json:"max_uses" validate:"min=0"`
+}
+
+// RedeemOrganizationInviteRequest joins the organization of an invite.
+// Authenticated users join as themselves. Otherwise, a user is created with
+// the email, username, and password, unless the invite is restricted to an
+// email domain.
+type RedeemOrganizationInviteRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+	Username string `json:"username,omitempty" validate:"omitempty,username"`
+	Password string `json:"password,omitempty"`
+}
+
+// OrganizationInvites lists the invites of an organization.
+func (c *Client) OrganizationInvites(ctx context.Context, organizationID uuid.UUID) ([]OrganizationInvite, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/invites", organizationID.String()), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var invites []OrganizationInvite
+	return invites, json.NewDecoder(res.Body).Decode(&invites)
+}
+
+// CreateOrganizationInvite creates an invite to an organization. The token
+// of the returned invite is what users redeem.
+func (c *Client) CreateOrganizationInvite(ctx context.Context, organizationID uuid.UUID, req CreateOrganizationInviteRequest) (OrganizationInvite, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/organizations/%s/invites", organizationID.String()), req)
+	if err != nil {
+		return OrganizationInvite{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return OrganizationInvite{}, readBodyAsError(res)
+	}
+	var invite OrganizationInvite
+	return invite, json.NewDecoder(res.Body).Decode(&invite)
+}
+
+// DeleteOrganizationInvite revokes an invite.
+func (c *Client) DeleteOrganizationInvite(ctx context.Context, organizationID, inviteID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s/invites/%s", organizationID.String(), inviteID.String()), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// RedeemOrganizationInvite joins the organization of an invite.
+func (c *Client) RedeemOrganizationInvite(ctx context.Context, req RedeemOrganizationInviteRequest) (OrganizationMember, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/invites/redeem", req)
+	if err != nil {
+		return OrganizationMember{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return OrganizationMember{}, readBodyAsError(res)
+	}
+	var member OrganizationMember
+	return member, json.NewDecoder(res.Body).Decode(&member)
+}
@@ -0,0 +1,58 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OrganizationIPAllowlist restricts the networks the API and workspace apps
+// of an organization can be used from. An empty list allows all networks.
+type OrganizationIPAllowlist struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// CIDRs are the networks that are allowed, e.g. "10.0.0.0/8".
+	CIDRs     []string  `json:"cidrs"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpdateOrganizationIPAllowlistRequest struct {
+	CIDRs []string `json:"cidrs"`
+}
+
+// OrganizationIPAllowlist returns the networks the resources of an
+// organization can be used from.
+func (c *Client) OrganizationIPAllowlist(ctx context.Context, organizationID uuid.UUID) (OrganizationIPAllowlist, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/ip-allowlist", organizationID.String()), nil)
+	if err != nil {
+		return OrganizationIPAllowlist{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationIPAllowlist{}, readBodyAsError(res)
+	}
+	var allowlist OrganizationIPAllowlist
+	return allowlist, json.NewDecoder(res.Body).Decode(&allowlist)
+}
+
+// UpdateOrganizationIPAllowlist replaces the networks the resources of an
+// organization can be used from. The list must include the network of the
+// request, so admins can't lock themselves out.
+func (c *Client) UpdateOrganizationIPAllowlist(ctx context.Context, organizationID uuid.UUID, req UpdateOrganizationIPAllowlistRequest) (OrganizationIPAllowlist, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/ip-allowlist", organizationID.String()), req)
+	if err != nil {
+		return OrganizationIPAllowlist{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationIPAllowlist{}, readBodyAsError(res)
+	}
+	var allowlist OrganizationIPAllowlist
+	return allowlist, json.NewDecoder(res.Body).Decode(&allowlist)
+}
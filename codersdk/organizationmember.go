@@ -1,6 +1,11 @@
 package codersdk
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,3 +18,81 @@ type OrganizationMember struct {
 	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
 	Roles          []Role    `db:"roles" json:"roles"`
 }
+
+// OrganizationMemberWithUser is an organization membership enriched with
+// the user's profile and the organization groups they belong to.
+type OrganizationMemberWithUser struct {
+	UserID         uuid.UUID                 `json:"user_id"`
+	OrganizationID uuid.UUID                 `json:"organization_id"`
+	Username       string                    `json:"username"`
+	Email          string                    `json:"email"`
+	AvatarURL      string                    `json:"avatar_url"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	UpdatedAt      time.Time                 `json:"updated_at"`
+	Roles          []Role                    `json:"roles"`
+	Groups         []OrganizationMemberGroup `json:"groups"`
+}
+
+// OrganizationMemberGroup is a minimal reference to a group a member
+// belongs to.
+type OrganizationMemberGroup struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type OrganizationMembersRequest struct {
+	Search string `json:"search,omitempty" typescript:"-"`
+	// Filter members that have the given organization role.
+	Role string `json:"role,omitempty" typescript:"-"`
+	// Filter members that belong to the given group.
+	GroupID uuid.UUID `json:"group_id,omitempty" typescript:"-"`
+
+	SearchQuery string `json:"q,omitempty"`
+	CursorPagination
+}
+
+type OrganizationMembersResponse struct {
+	Members []OrganizationMemberWithUser `json:"members"`
+	// NextCursor requests the next page. It's empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// OrganizationMembers returns the members of an organization, filtered and
+// paginated according to the request.
+func (c *Client) OrganizationMembers(ctx context.Context, organizationID uuid.UUID, req OrganizationMembersRequest) (OrganizationMembersResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/members", organizationID.String()), nil,
+		req.CursorPagination.asRequestOption(),
+		func(r *http.Request) {
+			q := r.URL.Query()
+			var params []string
+			if req.Search != "" {
+				params = append(params, req.Search)
+			}
+			if req.Role != "" {
+				params = append(params, fmt.Sprintf("role:%q", req.Role))
+			}
+			if req.GroupID != uuid.Nil {
+				params = append(params, "group:"+req.GroupID.String())
+			}
+			if req.SearchQuery != "" {
+				params = append(params, req.SearchQuery)
+			}
+			q.Set("q", strings.Join(params, " "))
+			r.URL.RawQuery = q.Encode()
+		},
+	)
+	if err != nil {
+		return OrganizationMembersResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationMembersResponse{}, readBodyAsError(res)
+	}
+
+	var resp OrganizationMembersResponse
+	if !req.CursorPagination.paged() {
+		return resp, json.NewDecoder(res.Body).Decode(&resp.Members)
+	}
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -0,0 +1,117 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OrganizationOIDCConfig lets users sign in with an identity provider of the
+// organization instead of the one configured for the deployment.
+type OrganizationOIDCConfig struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	IssuerURL      string    `json:"issuer_url"`
+	ClientID       string    `json:"client_id"`
+	Scopes         []string  `json:"scopes"`
+	// EmailDomains route users with an email address in the domains to the
+	// identity provider when they sign in.
+	EmailDomains []string `json:"email_domains"`
+	// UsernameField and EmailField are the claims that hold the username
+	// and email of a user.
+	UsernameField string `json:"username_field"`
+	EmailField    string `json:"email_field"`
+	// AllowSignups creates users that sign in for the first time. They
+	// join the organization.
+	AllowSignups bool      `json:"allow_signups"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type UpdateOrganizationOIDCConfigRequest struct {
+	IssuerURL string `json:"issuer_url" validate:"required,url"`
+	ClientID  string `json:"client_id" validate:"required"`
+	// ClientSecret is kept unchanged when it's empty.
+	ClientSecret  string   `json:"client_secret,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
+	EmailDomains  []string `json:"email_domains,omitempty"`
+	UsernameField string   `json:"username_field,omitempty"`
+	EmailField    string   `json:"email_field,omitempty"`
+	AllowSignups  bool     `json:"allow_signups"`
+}
+
+// OIDCLoginRoute is where users are sent to sign in with OIDC.
+type OIDCLoginRoute struct {
+	// OrganizationID is the organization that owns the identity provider.
+	// It's uuid.Nil for the identity provider of the deployment.
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// URL redirects to the identity provider.
+	URL string `json:"url"`
+}
+
+// OrganizationOIDCConfig returns the OIDC config of an organization.
+func (c *Client) OrganizationOIDCConfig(ctx context.Context, organizationID uuid.UUID) (OrganizationOIDCConfig, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/oidc", organizationID.String()), nil)
+	if err != nil {
+		return OrganizationOIDCConfig{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationOIDCConfig{}, readBodyAsError(res)
+	}
+	var config OrganizationOIDCConfig
+	return config, json.NewDecoder(res.Body).Decode(&config)
+}
+
+// UpdateOrganizationOIDCConfig configures the identity provider of an
+// organization.
+func (c *Client) UpdateOrganizationOIDCConfig(ctx context.Context, organizationID uuid.UUID, req UpdateOrganizationOIDCConfigRequest) (OrganizationOIDCConfig, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/oidc", organizationID.String()), req)
+	if err != nil {
+		return OrganizationOIDCConfig{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationOIDCConfig{}, readBodyAsError(res)
+	}
+	var config OrganizationOIDCConfig
+	return config, json.NewDecoder(res.Body).Decode(&config)
+}
+
+// DeleteOrganizationOIDCConfig removes the identity provider of an
+// organization. Its users sign in with the deployment's methods afterwards.
+func (c *Client) DeleteOrganizationOIDCConfig(ctx context.Context, organizationID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s/oidc", organizationID.String()), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// OIDCLoginRoute returns where a user with the email address signs in with
+// OIDC.
+func (c *Client) OIDCLoginRoute(ctx context.Context, email string) (OIDCLoginRoute, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/users/oidc/route?email="+url.QueryEscape(email), nil)
+	if err != nil {
+		return OIDCLoginRoute{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OIDCLoginRoute{}, readBodyAsError(res)
+	}
+	var route OIDCLoginRoute
+	return route, json.NewDecoder(res.Body).Decode(&route)
+}
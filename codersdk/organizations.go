@@ -30,6 +30,27 @@ type Organization struct {
 	Name      string    `json:"name" validate:"required"`
 	CreatedAt time.Time `json:"created_at" validate:"required"`
 	UpdatedAt time.Time `json:"updated_at" validate:"required"`
+	// IsDefault is set for the organization created with the first user.
+	// New users join it, and it can't be deleted.
+	IsDefault bool `json:"is_default"`
+	// DisplayName, Icon, and Description are shown in place of the name
+	// when set.
+	DisplayName string `json:"display_name"`
+	Icon        string `json:"icon"`
+	Description string `json:"description"`
+}
+
+// UpdateOrganizationRequest changes the settings of an organization. Fields
+// are left unchanged when nil, and an empty string clears them.
+type UpdateOrganizationRequest struct {
+	// Name renames the organization. The old name continues to resolve to
+	// the organization until another organization takes it.
+	Name        *string `json:"name,omitempty" validate:"omitempty,username"`
+	DisplayName *string `json:"display_name,omitempty"`
+	// Icon is a relative path or external URL that specifies an icon to be
+	// displayed in the dashboard.
+	Icon        *string `json:"icon,omitempty"`
+	Description *string `json:"description,omitempty" validate:"omitempty,lt=128"`
 }
 
 // CreateTemplateVersionRequest enables callers to create a new Template Version.
@@ -102,6 +123,39 @@ func (c *Client) Organization(ctx context.Context, id uuid.UUID) (Organization,
 	return organization, json.NewDecoder(res.Body).Decode(&organization)
 }
 
+// UpdateOrganization changes the name, display name, icon, or description of
+// an organization.
+func (c *Client) UpdateOrganization(ctx context.Context, id uuid.UUID, req UpdateOrganizationRequest) (Organization, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/organizations/%s", id.String()), req)
+	if err != nil {
+		return Organization{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Organization{}, readBodyAsError(res)
+	}
+	var organization Organization
+	return organization, json.NewDecoder(res.Body).Decode(&organization)
+}
+
+// DeleteOrganization starts deleting an organization along with its
+// workspaces, templates, and members in the background. Use
+// OrganizationDeletionStatus to follow the progress.
+func (c *Client) DeleteOrganization(ctx context.Context, id uuid.UUID) (OrganizationDeletion, error) {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s", id.String()), nil)
+	if err != nil {
+		return OrganizationDeletion{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		return OrganizationDeletion{}, readBodyAsError(res)
+	}
+	var deletion OrganizationDeletion
+	return deletion, json.NewDecoder(res.Body).Decode(&deletion)
+}
+
 // ProvisionerDaemonsByOrganization returns provisioner daemons available for an organization.
 func (c *Client) ProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
 	res, err := c.Request(ctx, http.MethodGet,
@@ -160,23 +214,41 @@ func (c *Client) CreateTemplate(ctx context.Context, organizationID uuid.UUID, r
 	return template, json.NewDecoder(res.Body).Decode(&template)
 }
 
+type TemplatesResponse struct {
+	Templates []Template `json:"templates"`
+	// NextCursor requests the next page. It's empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 // TemplatesByOrganization lists all templates inside of an organization.
-func (c *Client) TemplatesByOrganization(ctx context.Context, organizationID uuid.UUID) ([]Template, error) {
+// Pass WithQueryParam("sort", SortFavorites) to list favorites first.
+func (c *Client) TemplatesByOrganization(ctx context.Context, organizationID uuid.UUID, opts ...RequestOption) ([]Template, error) {
+	resp, err := c.TemplatesByOrganizationPage(ctx, organizationID, CursorPagination{}, opts...)
+	return resp.Templates, err
+}
+
+// TemplatesByOrganizationPage lists a page of the templates inside of an
+// organization ordered by name.
+func (c *Client) TemplatesByOrganizationPage(ctx context.Context, organizationID uuid.UUID, pagination CursorPagination, opts ...RequestOption) (TemplatesResponse, error) {
 	res, err := c.Request(ctx, http.MethodGet,
 		fmt.Sprintf("/api/v2/organizations/%s/templates", organizationID.String()),
 		nil,
+		append([]RequestOption{pagination.asRequestOption()}, opts...)...,
 	)
 	if err != nil {
-		return nil, xerrors.Errorf("execute request: %w", err)
+		return TemplatesResponse{}, xerrors.Errorf("execute request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, readBodyAsError(res)
+		return TemplatesResponse{}, readBodyAsError(res)
 	}
 
-	var templates []Template
-	return templates, json.NewDecoder(res.Body).Decode(&templates)
+	var resp TemplatesResponse
+	if !pagination.paged() {
+		return resp, json.NewDecoder(res.Body).Decode(&resp.Templates)
+	}
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
 // TemplateByName finds a template inside the organization provided with a case-insensitive name.
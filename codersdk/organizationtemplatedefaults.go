@@ -0,0 +1,74 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OrganizationTemplateDefaults are inherited by the templates and workspaces
+// of an organization when they're created, so policy doesn't have to be
+// repeated in every template. Zero values leave the deployment defaults in
+// place.
+type OrganizationTemplateDefaults struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// DefaultTTLMillis is the TTL of new workspaces that don't set one.
+	DefaultTTLMillis int64 `json:"default_ttl_ms"`
+	// MaxTTLMillis and MinAutostartIntervalMillis apply to new templates that
+	// don't set them.
+	MaxTTLMillis               int64 `json:"max_ttl_ms"`
+	MinAutostartIntervalMillis int64 `json:"min_autostart_interval_ms"`
+	// ParameterValues are added to template imports that don't set them.
+	ParameterValues []CreateParameterRequest `json:"parameter_values"`
+	// AllowedParameterValues maps parameter names to the only values they
+	// can be set to, for example the regions workspaces can be deployed to.
+	// It applies to template imports and new workspaces.
+	AllowedParameterValues map[string][]string `json:"allowed_parameter_values"`
+	UpdatedAt              time.Time           `json:"updated_at"`
+}
+
+type UpdateOrganizationTemplateDefaultsRequest struct {
+	DefaultTTLMillis           int64                    `json:"default_ttl_ms" validate:"min=0"`
+	MaxTTLMillis               int64                    `json:"max_ttl_ms" validate:"min=0"`
+	MinAutostartIntervalMillis int64                    `json:"min_autostart_interval_ms" validate:"min=0"`
+	ParameterValues            []CreateParameterRequest `json:"parameter_values,omitempty" validate:"dive"`
+	AllowedParameterValues     map[string][]string      `json:"allowed_parameter_values,omitempty"`
+}
+
+// OrganizationTemplateDefaults returns the defaults that templates and
+// workspaces of an organization inherit.
+func (c *Client) OrganizationTemplateDefaults(ctx context.Context, organizationID uuid.UUID) (OrganizationTemplateDefaults, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/templatedefaults", organizationID.String()), nil)
+	if err != nil {
+		return OrganizationTemplateDefaults{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationTemplateDefaults{}, readBodyAsError(res)
+	}
+	var defaults OrganizationTemplateDefaults
+	return defaults, json.NewDecoder(res.Body).Decode(&defaults)
+}
+
+// UpdateOrganizationTemplateDefaults replaces the defaults that templates
+// and workspaces of an organization inherit. Existing templates and
+// workspaces aren't changed.
+func (c *Client) UpdateOrganizationTemplateDefaults(ctx context.Context, organizationID uuid.UUID, req UpdateOrganizationTemplateDefaultsRequest) (OrganizationTemplateDefaults, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/templatedefaults", organizationID.String()), req)
+	if err != nil {
+		return OrganizationTemplateDefaults{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationTemplateDefaults{}, readBodyAsError(res)
+	}
+	var defaults OrganizationTemplateDefaults
+	return defaults, json.NewDecoder(res.Body).Decode(&defaults)
+}
@@ -0,0 +1,152 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// OrganizationWebhookEventType is the kind of organization change a webhook
+// is notified about.
+type OrganizationWebhookEventType string
+
+const (
+	OrganizationWebhookEventWorkspaceCreated  OrganizationWebhookEventType = "workspace.created"
+	OrganizationWebhookEventWorkspaceDeleted  OrganizationWebhookEventType = "workspace.deleted"
+	OrganizationWebhookEventMemberAdded       OrganizationWebhookEventType = "member.added"
+	OrganizationWebhookEventMemberRemoved     OrganizationWebhookEventType = "member.removed"
+	OrganizationWebhookEventTemplatePublished OrganizationWebhookEventType = "template.published"
+)
+
+// OrganizationWebhookEventTypes are the events organization webhooks can
+// subscribe to.
+var OrganizationWebhookEventTypes = []OrganizationWebhookEventType{
+	OrganizationWebhookEventWorkspaceCreated,
+	OrganizationWebhookEventWorkspaceDeleted,
+	OrganizationWebhookEventMemberAdded,
+	OrganizationWebhookEventMemberRemoved,
+	OrganizationWebhookEventTemplatePublished,
+}
+
+// OrganizationWebhookEvent is the JSON body delivered to organization
+// webhooks. Deliveries carry the same headers as group webhooks.
+type OrganizationWebhookEvent struct {
+	ID             uuid.UUID                    `json:"id"`
+	Type           OrganizationWebhookEventType `json:"type"`
+	CreatedAt      time.Time                    `json:"created_at"`
+	OrganizationID uuid.UUID                    `json:"organization_id"`
+	// ResourceID and ResourceName identify the workspace, user, or template
+	// the event is about.
+	ResourceID   uuid.UUID `json:"resource_id"`
+	ResourceName string    `json:"resource_name"`
+}
+
+// OrganizationWebhook is an endpoint that receives events about workspaces,
+// members, and templates of an organization.
+type OrganizationWebhook struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	URL            string    `json:"url"`
+	// Events the webhook is subscribed to. Empty means every event.
+	Events []OrganizationWebhookEventType `json:"events"`
+	// Secret is only set in the response to creating the webhook.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateOrganizationWebhookRequest struct {
+	URL string `json:"url" validate:"required,url"`
+	// Secret is used to sign deliveries. One is generated if empty.
+	Secret string                         `json:"secret,omitempty"`
+	Events []OrganizationWebhookEventType `json:"events,omitempty"`
+}
+
+// OrganizationWebhookDelivery is a single attempt at delivering an event to
+// a webhook.
+type OrganizationWebhookDelivery struct {
+	ID        uuid.UUID                    `json:"id"`
+	WebhookID uuid.UUID                    `json:"webhook_id"`
+	EventID   uuid.UUID                    `json:"event_id"`
+	EventType OrganizationWebhookEventType `json:"event_type"`
+	// Attempt starts at 1 and increases with every retry of the event.
+	Attempt int `json:"attempt"`
+	// StatusCode is zero when the endpoint couldn't be reached.
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c *Client) CreateOrganizationWebhook(ctx context.Context, orgID uuid.UUID, req CreateOrganizationWebhookRequest) (OrganizationWebhook, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/organizations/%s/webhooks", orgID.String()),
+		req,
+	)
+	if err != nil {
+		return OrganizationWebhook{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return OrganizationWebhook{}, readBodyAsError(res)
+	}
+	var resp OrganizationWebhook
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// OrganizationWebhooks lists the webhooks of an organization, oldest first.
+func (c *Client) OrganizationWebhooks(ctx context.Context, orgID uuid.UUID) ([]OrganizationWebhook, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/organizations/%s/webhooks", orgID.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []OrganizationWebhook
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+func (c *Client) DeleteOrganizationWebhook(ctx context.Context, orgID, webhook uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete,
+		fmt.Sprintf("/api/v2/organizations/%s/webhooks/%s", orgID.String(), webhook.String()),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// OrganizationWebhookDeliveries returns the most recent delivery attempts of
+// a webhook, newest first.
+func (c *Client) OrganizationWebhookDeliveries(ctx context.Context, orgID, webhook uuid.UUID) ([]OrganizationWebhookDelivery, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/organizations/%s/webhooks/%s/deliveries", orgID.String(), webhook.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []OrganizationWebhookDelivery
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -43,3 +43,37 @@ func (p Pagination) asRequestOption() RequestOption {
 		r.URL.RawQuery = q.Encode()
 	}
 }
+
+// CursorPagination sets pagination options for the list endpoints that
+// return a "next_cursor".
+type CursorPagination struct {
+	// Cursor returns the results after the end of a previous page. Set it to
+	// the NextCursor of the previous page, or leave it empty for the first
+	// page.
+	Cursor string `json:"cursor,omitempty"`
+	// Limit sets the maximum number of results to be returned in a single
+	// page. If the limit is <= 0, there is no limit and all results are
+	// returned.
+	Limit int `json:"limit,omitempty"`
+}
+
+// paged returns whether a page is requested. Endpoints that returned a bare
+// array before they were paginated still do without a cursor or limit.
+func (p CursorPagination) paged() bool {
+	return p.Cursor != "" || p.Limit > 0
+}
+
+// asRequestOption returns a function that can be used in (*Client).Request.
+// It modifies the request query parameters.
+func (p CursorPagination) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		if p.Cursor != "" {
+			q.Set("cursor", p.Cursor)
+		}
+		if p.Limit > 0 {
+			q.Set("limit", strconv.Itoa(p.Limit))
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +16,8 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
+
+	"github.com/coder/retry"
 )
 
 type LogSource string
@@ -104,8 +107,104 @@ func (c *Client) provisionerJobLogsBefore(ctx context.Context, path string, befo
 	return logs, json.NewDecoder(res.Body).Decode(&logs)
 }
 
+// logsReconnectTimeout is how long streamed logs try to reconnect after the
+// connection drops before giving up.
+const logsReconnectTimeout = time.Minute
+
 // provisionerJobLogsAfter streams logs that occurred after a specific time.
+// Connections that drop before the server finishes the stream, e.g. during a
+// network blip or a coderd restart, are resumed from the last log received,
+// so consumers get every log exactly once.
 func (c *Client) provisionerJobLogsAfter(ctx context.Context, path string, after time.Time) (<-chan ProvisionerJobLog, io.Closer, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	conn, res, err := c.dialProvisionerJobLogs(ctx, path, after)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	// The cursor is the creation time of the last log received. Without logs
+	// or an explicit time, the server starts at the time of the connection.
+	cursor := after
+	if cursor.IsZero() {
+		if date, err := http.ParseTime(res.Header.Get("Date")); err == nil {
+			cursor = date
+		}
+	}
+
+	logs := make(chan ProvisionerJobLog)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		defer close(logs)
+		// The server resumes from the millisecond of the cursor, so logs
+		// received in that millisecond are sent again after reconnecting.
+		seen := map[uuid.UUID]struct{}{}
+		for {
+			decoder := json.NewDecoder(websocket.NetConn(ctx, conn, websocket.MessageText))
+			var err error
+			for {
+				var log ProvisionerJobLog
+				err = decoder.Decode(&log)
+				if err != nil {
+					break
+				}
+				if _, ok := seen[log.ID]; ok {
+					continue
+				}
+				switch created := log.CreatedAt.UnixMilli(); {
+				case created > cursor.UnixMilli():
+					cursor = log.CreatedAt
+					seen = map[uuid.UUID]struct{}{log.ID: {}}
+				case created == cursor.UnixMilli():
+					seen[log.ID] = struct{}{}
+				}
+				select {
+				case <-ctx.Done():
+					_ = conn.Close(websocket.StatusGoingAway, "")
+					return
+				case logs <- log:
+				}
+			}
+			_ = conn.Close(websocket.StatusGoingAway, "")
+			// The server closes the connection normally once the job
+			// completes.
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return
+			}
+			conn = c.redialProvisionerJobLogs(ctx, path, cursor)
+			if conn == nil {
+				return
+			}
+		}
+	}()
+	return logs, closeFunc(func() error {
+		cancel()
+		<-closed
+		return nil
+	}), nil
+}
+
+// redialProvisionerJobLogs reconnects to the logs after the cursor until it
+// succeeds or logsReconnectTimeout passes. It returns nil if it gives up.
+func (c *Client) redialProvisionerJobLogs(ctx context.Context, path string, cursor time.Time) *websocket.Conn {
+	ctx, cancel := context.WithTimeout(ctx, logsReconnectTimeout)
+	defer cancel()
+	for retrier := retry.New(250*time.Millisecond, 5*time.Second); retrier.Wait(ctx); {
+		// nolint:bodyclose
+		conn, _, err := c.dialProvisionerJobLogs(ctx, path, cursor)
+		if err == nil {
+			return conn
+		}
+		// The server rejected the request, so retrying won't help. Proxies
+		// respond with 5xx status codes while coderd restarts.
+		if apiErr, ok := AsError(err); ok && apiErr.StatusCode() < http.StatusInternalServerError && apiErr.StatusCode() != http.StatusTooManyRequests {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *Client) dialProvisionerJobLogs(ctx context.Context, path string, after time.Time) (*websocket.Conn, *http.Response, error) {
 	afterQuery := ""
 	if !after.IsZero() {
 		afterQuery = fmt.Sprintf("&after=%d", after.UTC().UnixMilli())
@@ -135,29 +234,5 @@ func (c *Client) provisionerJobLogsAfter(ctx context.Context, path string, after
 		}
 		return nil, nil, readBodyAsError(res)
 	}
-	logs := make(chan ProvisionerJobLog)
-	decoder := json.NewDecoder(websocket.NetConn(ctx, conn, websocket.MessageText))
-	closed := make(chan struct{})
-	go func() {
-		defer close(closed)
-		defer close(logs)
-		defer conn.Close(websocket.StatusGoingAway, "")
-		var log ProvisionerJobLog
-		for {
-			err = decoder.Decode(&log)
-			if err != nil {
-				return
-			}
-			select {
-			case <-ctx.Done():
-				return
-			case logs <- log:
-			}
-		}
-	}()
-	return logs, closeFunc(func() error {
-		_ = conn.Close(websocket.StatusNormalClosure, "")
-		<-closed
-		return nil
-	}), nil
+	return conn, res, nil
 }
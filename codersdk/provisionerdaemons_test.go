@@ -0,0 +1,120 @@
+package codersdk_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestProvisionerJobLogsAfter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Reconnect", func(t *testing.T) {
+		t.Parallel()
+		start := time.Now().Truncate(time.Millisecond)
+		logs := []codersdk.ProvisionerJobLog{
+			{ID: uuid.New(), CreatedAt: start, Output: "first"},
+			// Logs in the same millisecond as the last log received are
+			// sent again when resuming.
+			{ID: uuid.New(), CreatedAt: start.Add(time.Millisecond), Output: "second"},
+			{ID: uuid.New(), CreatedAt: start.Add(time.Millisecond), Output: "third"},
+			{ID: uuid.New(), CreatedAt: start.Add(2 * time.Millisecond), Output: "fourth"},
+		}
+		var (
+			connections int64
+			resumedFrom = make(chan string, 1)
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			conn, err := websocket.Accept(rw, r, nil)
+			if !assertNoError(t, err) {
+				return
+			}
+			send := func(logs ...codersdk.ProvisionerJobLog) {
+				for _, log := range logs {
+					data, _ := json.Marshal(log)
+					_ = conn.Write(r.Context(), websocket.MessageText, data)
+				}
+			}
+			if atomic.AddInt64(&connections, 1) == 1 {
+				send(logs[:2]...)
+				// Drop the connection without finishing the stream.
+				_ = conn.Close(websocket.StatusInternalError, "")
+				return
+			}
+			resumedFrom <- r.URL.Query().Get("after")
+			after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+			for _, log := range logs {
+				if log.CreatedAt.UnixMilli() >= after {
+					send(log)
+				}
+			}
+			_ = conn.Close(websocket.StatusNormalClosure, "")
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+
+		ctx, _ := testutil.Context(t)
+		received, closer, err := codersdk.New(serverURL).WorkspaceBuildLogsAfter(ctx, uuid.New(), start)
+		require.NoError(t, err)
+		defer closer.Close()
+
+		var outputs []string
+		for log := range received {
+			outputs = append(outputs, log.Output)
+		}
+		require.Equal(t, []string{"first", "second", "third", "fourth"}, outputs)
+		require.Equal(t, strconv.FormatInt(logs[1].CreatedAt.UnixMilli(), 10), <-resumedFrom)
+		require.EqualValues(t, 2, atomic.LoadInt64(&connections))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		t.Parallel()
+		var connections int64
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt64(&connections, 1) > 1 {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			conn, err := websocket.Accept(rw, r, nil)
+			if !assertNoError(t, err) {
+				return
+			}
+			_ = conn.Close(websocket.StatusInternalError, "")
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+
+		ctx, _ := testutil.Context(t)
+		received, closer, err := codersdk.New(serverURL).WorkspaceBuildLogsAfter(ctx, uuid.New(), time.Now())
+		require.NoError(t, err)
+		defer closer.Close()
+
+		// Reconnecting gives up once the server rejects the request.
+		for range received {
+		}
+		require.EqualValues(t, 2, atomic.LoadInt64(&connections))
+	})
+}
+
+func assertNoError(t *testing.T, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package codersdk
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryPolicy retries idempotent requests a few times over roughly ten
+// seconds, which rides out restarts of a deployment and short rate limits.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// RetryPolicy retries idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE)
+// that fail with 429 Too Many Requests, 502 Bad Gateway, or 503 Service
+// Unavailable. The zero value doesn't retry.
+// @typescript-ignore RetryPolicy
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried after the first
+	// attempt.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry. It doubles for every
+	// retry after that, up to MaxBackoff. Waits are jittered, so clients that
+	// failed together don't retry together.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// @typescript-ignore noRetryKey
+type noRetryKey struct{}
+
+// WithoutRetry disables the retry policy of the client for a request, e.g.
+// for calls that should fail fast.
+func WithoutRetry() RequestOption {
+	return func(r *http.Request) {
+		*r = *r.WithContext(context.WithValue(r.Context(), noRetryKey{}, true))
+	}
+}
+
+// shouldRetry returns true if a request can be retried after receiving res.
+func (p RetryPolicy) shouldRetry(req *http.Request, res *http.Response, attempt int) bool {
+	if attempt >= p.MaxRetries {
+		return false
+	}
+	if disabled, _ := req.Context().Value(noRetryKey{}).(bool); disabled {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns how long to wait before the retry following attempt. The
+// Retry-After header of the response takes precedence over the policy.
+func (p RetryPolicy) backoff(res *http.Response, attempt int) time.Duration {
+	if wait, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+		return wait
+	}
+	wait := p.InitialBackoff
+	for i := 0; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// Wait between half and all of the backoff.
+	//nolint:gosec // The jitter doesn't need to be cryptographically secure.
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or as an HTTP date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// do sends the request, retrying it according to the retry policy of the
+// client.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := c.doAttempt(req, attempt+1)
+		if err != nil || !c.Retry.shouldRetry(req, res, attempt) {
+			return res, err
+		}
+		wait := c.Retry.backoff(res, attempt)
+		_ = res.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			retry.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+		req = retry
+	}
+}
@@ -0,0 +1,121 @@
+package codersdk_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := codersdk.RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}
+	// serve fails requests with status until they've been attempted
+	// failures times.
+	serve := func(t *testing.T, status, failures int, header http.Header) (*codersdk.Client, *int64) {
+		t.Helper()
+		var attempts int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if atomic.AddInt64(&attempts, 1) <= int64(failures) {
+				for key, values := range header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := codersdk.New(serverURL)
+		client.Retry = policy
+		return client, &attempts
+	}
+
+	t.Run("Retried", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, attempts := serve(t, http.StatusServiceUnavailable, 2, nil)
+		res, err := client.Request(ctx, http.MethodPut, "/", []byte("body"))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.EqualValues(t, 3, atomic.LoadInt64(attempts))
+		// The body is sent again with every attempt.
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "body", string(body))
+	})
+
+	t.Run("GivesUp", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, attempts := serve(t, http.StatusBadGateway, 10, nil)
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadGateway, res.StatusCode)
+		require.EqualValues(t, 4, atomic.LoadInt64(attempts))
+	})
+
+	t.Run("NotIdempotent", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, attempts := serve(t, http.StatusServiceUnavailable, 1, nil)
+		res, err := client.Request(ctx, http.MethodPost, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.EqualValues(t, 1, atomic.LoadInt64(attempts))
+	})
+
+	t.Run("OtherStatus", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, attempts := serve(t, http.StatusInternalServerError, 1, nil)
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		require.EqualValues(t, 1, atomic.LoadInt64(attempts))
+	})
+
+	t.Run("WithoutRetry", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, attempts := serve(t, http.StatusTooManyRequests, 1, nil)
+		res, err := client.Request(ctx, http.MethodGet, "/", nil, codersdk.WithoutRetry())
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.EqualValues(t, 1, atomic.LoadInt64(attempts))
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		t.Parallel()
+		ctx, _ := testutil.Context(t)
+		client, attempts := serve(t, http.StatusTooManyRequests, 1, http.Header{"Retry-After": []string{"1"}})
+		start := time.Now()
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.EqualValues(t, 2, atomic.LoadInt64(attempts))
+		require.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,114 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type RoleRequestStatus string
+
+const (
+	RoleRequestStatusPending  RoleRequestStatus = "pending"
+	RoleRequestStatusApproved RoleRequestStatus = "approved"
+	RoleRequestStatusDenied   RoleRequestStatus = "denied"
+)
+
+// RoleRequest is a request from a user to be granted a site or organization
+// role.
+type RoleRequest struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	// OrganizationID is set when the role is an organization role.
+	OrganizationID *uuid.UUID        `json:"organization_id,omitempty"`
+	Role           Role              `json:"role"`
+	Reason         string            `json:"reason"`
+	Status         RoleRequestStatus `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	ReviewedBy     *uuid.UUID        `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time        `json:"reviewed_at,omitempty"`
+}
+
+type CreateRoleRequestRequest struct {
+	// Role is the name of the role, e.g. "template-admin" or
+	// "organization-admin:<organization id>".
+	Role   string `json:"role" validate:"required"`
+	Reason string `json:"reason"`
+}
+
+// CreateRoleRequest asks for the authenticated user to be granted a role.
+// Users can only request roles for themselves.
+func (c *Client) CreateRoleRequest(ctx context.Context, user string, req CreateRoleRequestRequest) (RoleRequest, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/role-requests", user), req)
+	if err != nil {
+		return RoleRequest{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return RoleRequest{}, readBodyAsError(res)
+	}
+	var resp RoleRequest
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// RoleRequests lists the role requests made by a user, newest first.
+func (c *Client) RoleRequests(ctx context.Context, user string) ([]RoleRequest, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/role-requests", user), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []RoleRequest
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// PendingRoleRequests lists the pending role requests the authenticated user
+// can approve, oldest first.
+func (c *Client) PendingRoleRequests(ctx context.Context) ([]RoleRequest, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/role-requests", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []RoleRequest
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ApproveRoleRequest grants the requested role to the requesting user.
+func (c *Client) ApproveRoleRequest(ctx context.Context, request uuid.UUID) (RoleRequest, error) {
+	return c.reviewRoleRequest(ctx, request, "approve")
+}
+
+// DenyRoleRequest closes the role request without granting the role.
+func (c *Client) DenyRoleRequest(ctx context.Context, request uuid.UUID) (RoleRequest, error) {
+	return c.reviewRoleRequest(ctx, request, "deny")
+}
+
+func (c *Client) reviewRoleRequest(ctx context.Context, request uuid.UUID, review string) (RoleRequest, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/role-requests/%s/%s", request, review), nil)
+	if err != nil {
+		return RoleRequest{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return RoleRequest{}, readBodyAsError(res)
+	}
+	var resp RoleRequest
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -0,0 +1,60 @@
+package codersdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+
+	"github.com/hashicorp/yamux"
+	"golang.org/x/xerrors"
+	"nhooyr.io/websocket"
+
+	"github.com/coder/coder/codersdk/proto"
+	"github.com/coder/coder/provisionersdk"
+)
+
+// DialRPC connects to the dRPC service defined in codersdk/proto. Calls are
+// authorized as the user of the session token, and fail with the HTTP status
+// code of the REST API as the dRPC error code. The connection is closed when
+// ctx is canceled, or by closing the returned client's connection.
+func (c *Client) DialRPC(ctx context.Context) (proto.DRPCCoderClient, error) {
+	serverURL, err := c.URL.Parse("/api/v2/rpc")
+	if err != nil {
+		return nil, xerrors.Errorf("parse url: %w", err)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, xerrors.Errorf("create cookie jar: %w", err)
+	}
+	jar.SetCookies(serverURL, []*http.Cookie{{
+		Name:  SessionTokenKey,
+		Value: c.SessionToken,
+	}})
+	httpClient := &http.Client{
+		Jar:       jar,
+		Transport: c.HTTPClient.Transport,
+	}
+	// nolint:bodyclose
+	conn, res, err := websocket.Dial(ctx, serverURL.String(), &websocket.DialOptions{
+		HTTPClient:      httpClient,
+		CompressionMode: websocket.CompressionDisabled,
+	})
+	if err != nil {
+		if res == nil {
+			return nil, err
+		}
+		return nil, readBodyAsError(res)
+	}
+	// Messages can be larger than the default limit of 32KiB.
+	conn.SetReadLimit(provisionersdk.MaxMessageSize)
+
+	config := yamux.DefaultConfig()
+	config.LogOutput = io.Discard
+	session, err := yamux.Client(websocket.NetConn(ctx, conn, websocket.MessageBinary), config)
+	if err != nil {
+		_ = conn.Close(websocket.StatusGoingAway, "")
+		return nil, xerrors.Errorf("multiplex client: %w", err)
+	}
+	return proto.NewDRPCCoderClient(provisionersdk.Conn(session)), nil
+}
@@ -0,0 +1,65 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// SearchResultLimit is the most results a search can return.
+const SearchResultLimit = 50
+
+type SearchResultType string
+
+const (
+	SearchResultTypeWorkspace SearchResultType = "workspace"
+	SearchResultTypeTemplate  SearchResultType = "template"
+	SearchResultTypeUser      SearchResultType = "user"
+	SearchResultTypeGroup     SearchResultType = "group"
+)
+
+// SearchResult is a resource whose name matches a search.
+type SearchResult struct {
+	Type SearchResultType `json:"type"`
+	ID   uuid.UUID        `json:"id"`
+	Name string           `json:"name"`
+	// DisplayName is the group's display name, or the workspace's owner
+	// and name.
+	DisplayName string `json:"display_name,omitempty"`
+	// OrganizationID is empty for users and deployment-wide groups.
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+type SearchRequest struct {
+	Query string `json:"q"`
+	// Limit defaults to 10, and can be at most SearchResultLimit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Search returns the workspaces, templates, users, and groups the user can
+// read that match the query, best matches first. Exact names rank above
+// prefixes, and prefixes above any other match.
+func (c *Client) Search(ctx context.Context, req SearchRequest) ([]SearchResult, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/search", nil, func(r *http.Request) {
+		q := r.URL.Query()
+		q.Set("q", req.Query)
+		if req.Limit > 0 {
+			q.Set("limit", strconv.Itoa(req.Limit))
+		}
+		r.URL.RawQuery = q.Encode()
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var results []SearchResult
+	return results, json.NewDecoder(res.Body).Decode(&results)
+}
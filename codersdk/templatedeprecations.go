@@ -0,0 +1,95 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// TemplateDeprecation blocks new workspaces from being created from a
+// template, and asks the owners of its workspaces to migrate them to a
+// successor.
+type TemplateDeprecation struct {
+	// Message is shown to the owners of the template's workspaces, e.g. why
+	// it's deprecated.
+	Message string `json:"message"`
+	// SuccessorID is the template workspaces are migrated to. Workspaces of
+	// templates without a successor can't be migrated.
+	SuccessorID   *uuid.UUID `json:"successor_id,omitempty"`
+	SuccessorName string     `json:"successor_name,omitempty"`
+	// ParameterMapping maps the names of the template's parameters to the
+	// names of the successor's parameters their values are migrated to.
+	// Parameters that aren't mapped keep their names, and those mapped to an
+	// empty name are dropped.
+	ParameterMapping map[string]string `json:"parameter_mapping"`
+	DeprecatedAt     time.Time         `json:"deprecated_at"`
+}
+
+// UpdateTemplateDeprecationRequest deprecates a template.
+type UpdateTemplateDeprecationRequest struct {
+	Message          string            `json:"message"`
+	SuccessorID      *uuid.UUID        `json:"successor_id,omitempty"`
+	ParameterMapping map[string]string `json:"parameter_mapping,omitempty"`
+}
+
+// TemplateDeprecation returns the deprecation of a template.
+func (c *Client) TemplateDeprecation(ctx context.Context, templateID uuid.UUID) (TemplateDeprecation, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/deprecation", templateID), nil)
+	if err != nil {
+		return TemplateDeprecation{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateDeprecation{}, readBodyAsError(res)
+	}
+	var deprecation TemplateDeprecation
+	return deprecation, json.NewDecoder(res.Body).Decode(&deprecation)
+}
+
+// UpdateTemplateDeprecation deprecates a template, or updates its
+// deprecation.
+func (c *Client) UpdateTemplateDeprecation(ctx context.Context, templateID uuid.UUID, req UpdateTemplateDeprecationRequest) (TemplateDeprecation, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/deprecation", templateID), req)
+	if err != nil {
+		return TemplateDeprecation{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateDeprecation{}, readBodyAsError(res)
+	}
+	var deprecation TemplateDeprecation
+	return deprecation, json.NewDecoder(res.Body).Decode(&deprecation)
+}
+
+// DeleteTemplateDeprecation undeprecates a template.
+func (c *Client) DeleteTemplateDeprecation(ctx context.Context, templateID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/templates/%s/deprecation", templateID), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// MigrateWorkspace moves a workspace of a deprecated template to the
+// template's successor, and starts it with the successor's active version.
+func (c *Client) MigrateWorkspace(ctx context.Context, workspaceID uuid.UUID) (Workspace, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/migrate", workspaceID), nil)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Workspace{}, readBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
@@ -30,6 +30,12 @@ type Template struct {
 	MinAutostartIntervalMillis int64     `json:"min_autostart_interval_ms"`
 	CreatedByID                uuid.UUID `json:"created_by_id"`
 	CreatedByName              string    `json:"created_by_name"`
+	// QuotaWeight is how much of a group quota budget each workspace
+	// created from the template consumes.
+	QuotaWeight int32 `json:"quota_weight"`
+	// WorkspaceLabels are applied to every workspace created from the
+	// template. Owners can't change them.
+	WorkspaceLabels map[string]string `json:"workspace_labels"`
 }
 
 type UpdateActiveTemplateVersion struct {
@@ -59,6 +65,30 @@ type TemplateUser struct {
 	Role TemplateRole `json:"role"`
 }
 
+// TemplateEffectiveAccess lists every user the ACL of a template grants a
+// role, directly or through their groups.
+type TemplateEffectiveAccess struct {
+	Users []TemplateEffectiveUser `json:"users"`
+}
+
+type TemplateEffectiveUser struct {
+	User
+	// Role is the highest role the user is granted by any source.
+	Role    TemplateRole           `json:"role"`
+	Sources []TemplateAccessSource `json:"sources"`
+}
+
+// TemplateAccessSource is an entry of a template's ACL that grants a user a
+// role.
+type TemplateAccessSource struct {
+	Role TemplateRole `json:"role"`
+	// GroupID is the group that grants the role, or nil when the user is
+	// granted it directly. Members of groups nested beneath the group are
+	// granted the role too.
+	GroupID   *uuid.UUID `json:"group_id,omitempty"`
+	GroupName string     `json:"group_name,omitempty"`
+}
+
 type UpdateTemplateACL struct {
 	UserPerms  map[string]TemplateRole `json:"user_perms,omitempty"`
 	GroupPerms map[string]TemplateRole `json:"group_perms,omitempty"`
@@ -70,6 +100,10 @@ type UpdateTemplateMeta struct {
 	Icon                       string `json:"icon,omitempty"`
 	MaxTTLMillis               int64  `json:"max_ttl_ms,omitempty"`
 	MinAutostartIntervalMillis int64  `json:"min_autostart_interval_ms,omitempty"`
+	// WorkspaceLabels replaces the labels applied to the workspaces of the
+	// template when set, including existing workspaces. An empty map removes
+	// them.
+	WorkspaceLabels *map[string]string `json:"workspace_labels,omitempty"`
 }
 
 // Template returns a single template.
@@ -126,6 +160,125 @@ func (c *Client) UpdateTemplateACL(ctx context.Context, templateID uuid.UUID, re
 	return nil
 }
 
+type UpdateTemplateQuotaRequest struct {
+	// Weight is how much of a group quota budget each workspace created
+	// from the template consumes. Zero makes the template free.
+	Weight int32 `json:"weight" validate:"min=0"`
+}
+
+// UpdateTemplateQuota sets the weight of the template in group quota
+// budgets.
+func (c *Client) UpdateTemplateQuota(ctx context.Context, templateID uuid.UUID, req UpdateTemplateQuotaRequest) error {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templates/%s/quota", templateID), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// TemplateMaintenanceWindow is when running workspaces on old versions of a
+// template are updated to its active version and restarted.
+type TemplateMaintenanceWindow struct {
+	// Schedule is a weekly cron expression of when the window opens, e.g.
+	// "CRON_TZ=Europe/Dublin 0 2 * * Sun". An empty schedule removes the
+	// window.
+	Schedule       string      `json:"schedule"`
+	DurationMillis int64       `json:"duration_ms"`
+	ExemptGroupIDs []uuid.UUID `json:"exempt_group_ids"`
+}
+
+// TemplateMaintenanceWindow returns the maintenance window of a template. The
+// schedule is empty if the template has none.
+func (c *Client) TemplateMaintenanceWindow(ctx context.Context, templateID uuid.UUID) (TemplateMaintenanceWindow, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/maintenance", templateID), nil)
+	if err != nil {
+		return TemplateMaintenanceWindow{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateMaintenanceWindow{}, readBodyAsError(res)
+	}
+	var window TemplateMaintenanceWindow
+	return window, json.NewDecoder(res.Body).Decode(&window)
+}
+
+// UpdateTemplateMaintenanceWindow replaces the maintenance window of a
+// template.
+func (c *Client) UpdateTemplateMaintenanceWindow(ctx context.Context, templateID uuid.UUID, req TemplateMaintenanceWindow) (TemplateMaintenanceWindow, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/maintenance", templateID), req)
+	if err != nil {
+		return TemplateMaintenanceWindow{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateMaintenanceWindow{}, readBodyAsError(res)
+	}
+	var window TemplateMaintenanceWindow
+	return window, json.NewDecoder(res.Body).Decode(&window)
+}
+
+// AutostopExtensionPolicy limits how far users can extend the deadlines of
+// running workspaces past their TTL. Limits apply to each workspace over the
+// last 24 hours, and zero is unlimited.
+type AutostopExtensionPolicy struct {
+	MaxExtensionsPerDay      int32 `json:"max_extensions_per_day"`
+	MaxExtensionPerDayMillis int64 `json:"max_extension_per_day_ms"`
+}
+
+type TemplateAutostopExtensionPolicy struct {
+	AutostopExtensionPolicy
+	// Inherited is true when the template uses the deployment's policy.
+	Inherited bool `json:"inherited"`
+}
+
+// TemplateAutostopExtensionPolicy returns the autostop extension policy that
+// applies to the workspaces of a template.
+func (c *Client) TemplateAutostopExtensionPolicy(ctx context.Context, templateID uuid.UUID) (TemplateAutostopExtensionPolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/extension-policy", templateID), nil)
+	if err != nil {
+		return TemplateAutostopExtensionPolicy{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateAutostopExtensionPolicy{}, readBodyAsError(res)
+	}
+	var policy TemplateAutostopExtensionPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// UpdateTemplateAutostopExtensionPolicy sets the autostop extension policy of
+// a template, overriding the deployment's.
+func (c *Client) UpdateTemplateAutostopExtensionPolicy(ctx context.Context, templateID uuid.UUID, req AutostopExtensionPolicy) (TemplateAutostopExtensionPolicy, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/extension-policy", templateID), req)
+	if err != nil {
+		return TemplateAutostopExtensionPolicy{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateAutostopExtensionPolicy{}, readBodyAsError(res)
+	}
+	var policy TemplateAutostopExtensionPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// DeleteTemplateAutostopExtensionPolicy removes the autostop extension policy
+// of a template. Its workspaces use the deployment's policy afterwards.
+func (c *Client) DeleteTemplateAutostopExtensionPolicy(ctx context.Context, templateID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/templates/%s/extension-policy", templateID), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
 func (c *Client) TemplateACL(ctx context.Context, templateID uuid.UUID) (TemplateACL, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/acl", templateID), nil)
 	if err != nil {
@@ -139,6 +292,21 @@ func (c *Client) TemplateACL(ctx context.Context, templateID uuid.UUID) (Templat
 	return acl, json.NewDecoder(res.Body).Decode(&acl)
 }
 
+// TemplateEffectiveAccess returns the users the ACL of a template grants a
+// role, with the groups of the ACL expanded into their members.
+func (c *Client) TemplateEffectiveAccess(ctx context.Context, templateID uuid.UUID) (TemplateEffectiveAccess, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/effective-access", templateID), nil)
+	if err != nil {
+		return TemplateEffectiveAccess{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateEffectiveAccess{}, readBodyAsError(res)
+	}
+	var access TemplateEffectiveAccess
+	return access, json.NewDecoder(res.Body).Decode(&access)
+}
+
 // UpdateActiveTemplateVersion updates the active template version to the ID provided.
 // The template version must be attached to the template.
 func (c *Client) UpdateActiveTemplateVersion(ctx context.Context, template uuid.UUID, req UpdateActiveTemplateVersion) error {
@@ -227,4 +395,7 @@ type AgentStatsReportResponse struct {
 	RxBytes int64 `json:"rx_bytes"`
 	// TxBytes is the number of received bytes.
 	TxBytes int64 `json:"tx_bytes"`
+	// Usage is omitted by agents that can't sample the resource usage of
+	// their machine.
+	Usage *AgentUsage `json:"usage,omitempty"`
 }
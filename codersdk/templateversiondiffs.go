@@ -0,0 +1,80 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type TemplateVersionDiffStatus string
+
+const (
+	TemplateVersionDiffStatusAdded    TemplateVersionDiffStatus = "added"
+	TemplateVersionDiffStatusRemoved  TemplateVersionDiffStatus = "removed"
+	TemplateVersionDiffStatusModified TemplateVersionDiffStatus = "modified"
+)
+
+// TemplateVersionDiff is what changes between two template versions, so
+// admins can review an upgrade before pushing it to workspaces. Only what
+// changed is listed.
+type TemplateVersionDiff struct {
+	FromVersionID uuid.UUID                      `json:"from_version_id"`
+	ToVersionID   uuid.UUID                      `json:"to_version_id"`
+	Files         []TemplateVersionFileDiff      `json:"files"`
+	Parameters    []TemplateVersionParameterDiff `json:"parameters"`
+	Resources     []TemplateVersionResourceDiff  `json:"resources"`
+}
+
+// TemplateVersionFileDiff is a file of the template's source, e.g. main.tf.
+type TemplateVersionFileDiff struct {
+	Path   string                    `json:"path"`
+	Status TemplateVersionDiffStatus `json:"status"`
+	// Diff is a unified diff of the file. It's empty for binary files.
+	Diff string `json:"diff"`
+}
+
+// TemplateVersionParameterDiff is a parameter whose schema was added,
+// removed, or changed.
+type TemplateVersionParameterDiff struct {
+	Name   string                    `json:"name"`
+	Status TemplateVersionDiffStatus `json:"status"`
+	// From is unset for added parameters.
+	From *ParameterSchema `json:"from,omitempty"`
+	// To is unset for removed parameters.
+	To *ParameterSchema `json:"to,omitempty"`
+}
+
+// TemplateVersionResourceDiff is a resource in the plan of a workspace
+// transition that was added, removed, or whose agents or metadata changed.
+type TemplateVersionResourceDiff struct {
+	Transition WorkspaceTransition       `json:"workspace_transition"`
+	Type       string                    `json:"type"`
+	Name       string                    `json:"name"`
+	Status     TemplateVersionDiffStatus `json:"status"`
+	// AgentsAdded and AgentsRemoved are the names of the agents of the
+	// resource that changed.
+	AgentsAdded   []string `json:"agents_added"`
+	AgentsRemoved []string `json:"agents_removed"`
+	// MetadataChanged are the keys of the metadata of the resource that were
+	// added, removed, or whose value changed.
+	MetadataChanged []string `json:"metadata_changed"`
+}
+
+// TemplateVersionDiff returns what changes from one template version to
+// another.
+func (c *Client) TemplateVersionDiff(ctx context.Context, from, to uuid.UUID) (TemplateVersionDiff, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/diff/%s", from, to), nil)
+	if err != nil {
+		return TemplateVersionDiff{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionDiff{}, readBodyAsError(res)
+	}
+	var diff TemplateVersionDiff
+	return diff, json.NewDecoder(res.Body).Decode(&diff)
+}
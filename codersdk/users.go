@@ -30,6 +30,9 @@ type UsersRequest struct {
 	Role string `json:"role,omitempty" typescript:"-"`
 
 	SearchQuery string `json:"q,omitempty"`
+	// Fields only returns the given JSON fields of each user, e.g. "id" and
+	// "username". The other fields are left empty.
+	Fields []string `json:"fields,omitempty"`
 	Pagination
 }
 
@@ -80,6 +83,18 @@ type UpdateRoles struct {
 	Roles []string `json:"roles" validate:""`
 }
 
+// UpdateOrganizationMembersRolesRequest replaces the organization roles of
+// many members at once. Either all updates are applied or none are.
+type UpdateOrganizationMembersRolesRequest struct {
+	Updates []OrganizationMemberRolesUpdate `json:"updates" validate:"required,min=1,dive"`
+}
+
+type OrganizationMemberRolesUpdate struct {
+	// User is the ID or username of the member.
+	User  string   `json:"user" validate:"required"`
+	Roles []string `json:"roles"`
+}
+
 type UserRoles struct {
 	Roles             []string               `json:"roles"`
 	OrganizationRoles map[uuid.UUID][]string `json:"organization_roles"`
@@ -253,6 +268,22 @@ func (c *Client) UpdateOrganizationMemberRoles(ctx context.Context, organization
 	return member, json.NewDecoder(res.Body).Decode(&member)
 }
 
+// UpdateOrganizationMembersRoles replaces the roles of many members of an
+// org in one transaction. The members are returned in the order of the
+// updates.
+func (c *Client) UpdateOrganizationMembersRoles(ctx context.Context, organizationID uuid.UUID, req UpdateOrganizationMembersRolesRequest) ([]OrganizationMember, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/organizations/%s/members/roles", organizationID), req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var members []OrganizationMember
+	return members, json.NewDecoder(res.Body).Decode(&members)
+}
+
 // GetUserRoles returns all roles the user has
 func (c *Client) GetUserRoles(ctx context.Context, user string) (UserRoles, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/roles", user), nil)
@@ -334,6 +365,9 @@ func (c *Client) Users(ctx context.Context, req UsersRequest) ([]User, error) {
 				params = append(params, req.SearchQuery)
 			}
 			q.Set("q", strings.Join(params, " "))
+			if len(req.Fields) > 0 {
+				q.Set("fields", strings.Join(req.Fields, ","))
+			}
 			r.URL.RawQuery = q.Encode()
 		},
 	)
@@ -0,0 +1,174 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// WebhookEventHeader contains the event type of a delivery. It's sent to
+	// deployment, organization, and group webhooks.
+	WebhookEventHeader = "Coder-Webhook-Event"
+	// WebhookSignatureHeader contains "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of the request body, keyed with the webhook's secret.
+	WebhookSignatureHeader = "Coder-Webhook-Signature"
+)
+
+// WebhookEventType is the kind of change a deployment-wide webhook is
+// notified about.
+type WebhookEventType string
+
+const (
+	WebhookEventWorkspaceCreated      WebhookEventType = "workspace.created"
+	WebhookEventWorkspaceDeleted      WebhookEventType = "workspace.deleted"
+	WebhookEventWorkspaceBuildCreated WebhookEventType = "workspace_build.created"
+	WebhookEventUserCreated           WebhookEventType = "user.created"
+	WebhookEventUserDeleted           WebhookEventType = "user.deleted"
+	WebhookEventUserSuspended         WebhookEventType = "user.suspended"
+	WebhookEventUserActivated         WebhookEventType = "user.activated"
+	WebhookEventGroupCreated          WebhookEventType = "group.created"
+	WebhookEventGroupDeleted          WebhookEventType = "group.deleted"
+)
+
+// WebhookEventTypes are the events webhooks can subscribe to.
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventWorkspaceCreated,
+	WebhookEventWorkspaceDeleted,
+	WebhookEventWorkspaceBuildCreated,
+	WebhookEventUserCreated,
+	WebhookEventUserDeleted,
+	WebhookEventUserSuspended,
+	WebhookEventUserActivated,
+	WebhookEventGroupCreated,
+	WebhookEventGroupDeleted,
+}
+
+// WebhookEvent is the JSON body delivered to webhooks.
+type WebhookEvent struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	// OrganizationID is empty for users and deployment-wide groups.
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// ResourceID and ResourceName identify the workspace, build, user, or
+	// group the event is about. Builds are named after their workspace.
+	ResourceID   uuid.UUID `json:"resource_id"`
+	ResourceName string    `json:"resource_name"`
+}
+
+// Webhook is a deployment-wide endpoint that receives events.
+type Webhook struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Events the webhook is subscribed to. Empty means every event.
+	Events []WebhookEventType `json:"events"`
+	// Secret is only set in the response to creating the webhook.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url"`
+	// Secret is used to sign deliveries. One is generated if empty.
+	Secret string             `json:"secret,omitempty"`
+	Events []WebhookEventType `json:"events,omitempty"`
+}
+
+// WebhookDelivery is a single attempt at delivering an event to a webhook.
+type WebhookDelivery struct {
+	ID        uuid.UUID        `json:"id"`
+	WebhookID uuid.UUID        `json:"webhook_id"`
+	EventID   uuid.UUID        `json:"event_id"`
+	EventType WebhookEventType `json:"event_type"`
+	// Attempt starts at 1 and increases with every automatic retry of the
+	// event. Redeliveries are a single attempt.
+	Attempt    int  `json:"attempt"`
+	Redelivery bool `json:"redelivery"`
+	// StatusCode is zero when the endpoint couldn't be reached.
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (Webhook, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/webhooks", req)
+	if err != nil {
+		return Webhook{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return Webhook{}, readBodyAsError(res)
+	}
+	var resp Webhook
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// Webhooks lists the deployment-wide webhooks, oldest first.
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/webhooks", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []Webhook
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhook uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/webhooks/%s", webhook.String()), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
+
+// WebhookDeliveries returns the most recent delivery attempts of a webhook,
+// newest first.
+func (c *Client) WebhookDeliveries(ctx context.Context, webhook uuid.UUID) ([]WebhookDelivery, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/webhooks/%s/deliveries", webhook.String()), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	var resp []WebhookDelivery
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// RedeliverWebhookDelivery sends the event of a delivery to its webhook
+// again, and returns the new attempt.
+func (c *Client) RedeliverWebhookDelivery(ctx context.Context, webhook, delivery uuid.UUID) (WebhookDelivery, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/webhooks/%s/deliveries/%s/redeliver", webhook.String(), delivery.String()),
+		nil,
+	)
+	if err != nil {
+		return WebhookDelivery{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return WebhookDelivery{}, readBodyAsError(res)
+	}
+	var resp WebhookDelivery
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
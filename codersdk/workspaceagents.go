@@ -543,6 +543,8 @@ type AgentStats struct {
 	NumConns int64 `json:"num_comms"`
 	RxBytes  int64 `json:"rx_bytes"`
 	TxBytes  int64 `json:"tx_bytes"`
+	// Usage isn't a counter, so it's sampled when stats are copied.
+	Usage *AgentUsage `json:"usage,omitempty"`
 }
 
 // AgentReportStats begins a stat streaming connection with the Coder server.
@@ -609,6 +611,7 @@ func (c *Client) AgentReportStats(
 						NumConns: s.NumConns,
 						RxBytes:  s.RxBytes,
 						TxBytes:  s.TxBytes,
+						Usage:    s.Usage,
 					}
 
 					err = wsjson.Write(ctx, conn, resp)
@@ -0,0 +1,122 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type WorkspaceArchiveStatus string
+
+const (
+	WorkspaceArchiveStatusArchiving WorkspaceArchiveStatus = "archiving"
+	WorkspaceArchiveStatusArchived  WorkspaceArchiveStatus = "archived"
+	WorkspaceArchiveStatusRestoring WorkspaceArchiveStatus = "restoring"
+	WorkspaceArchiveStatusFailed    WorkspaceArchiveStatus = "failed"
+)
+
+// WorkspaceArchive is the archive of a workspace whose resources were
+// destroyed after its data and state were exported to object storage.
+type WorkspaceArchive struct {
+	WorkspaceID uuid.UUID              `json:"workspace_id"`
+	Status      WorkspaceArchiveStatus `json:"status"`
+	// ObjectKey is the prefix of the archive's objects in the bucket.
+	ObjectKey string `json:"object_key"`
+	// BuildID is the build that archives or restores the workspace.
+	BuildID uuid.UUID `json:"build_id"`
+	// Error is why archiving or restoring the workspace failed.
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// ExpiresAt is when the archive is purged and the workspace deleted.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// TemplateArchivePolicy archives the dormant workspaces of a template and
+// limits how long their archives are kept.
+type TemplateArchivePolicy struct {
+	// DormancyThresholdMillis archives workspaces that weren't used or built
+	// for this long. Zero never archives workspaces automatically.
+	DormancyThresholdMillis int64 `json:"dormancy_threshold_ms"`
+	// RetentionMillis purges archives and deletes their workspaces after
+	// this long. Zero keeps archives forever.
+	RetentionMillis int64 `json:"retention_ms"`
+}
+
+// WorkspaceArchive returns the archive of a workspace.
+func (c *Client) WorkspaceArchive(ctx context.Context, workspaceID uuid.UUID) (WorkspaceArchive, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/archive", workspaceID), nil)
+	if err != nil {
+		return WorkspaceArchive{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceArchive{}, readBodyAsError(res)
+	}
+	var archive WorkspaceArchive
+	return archive, json.NewDecoder(res.Body).Decode(&archive)
+}
+
+// ArchiveWorkspace exports the state of a workspace to object storage and
+// destroys its resources. Poll the archive until it's archived.
+func (c *Client) ArchiveWorkspace(ctx context.Context, workspaceID uuid.UUID) (WorkspaceArchive, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/archive", workspaceID), nil)
+	if err != nil {
+		return WorkspaceArchive{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return WorkspaceArchive{}, readBodyAsError(res)
+	}
+	var archive WorkspaceArchive
+	return archive, json.NewDecoder(res.Body).Decode(&archive)
+}
+
+// RestoreWorkspace starts an archived workspace from its archive. The
+// archive is purged once the workspace started.
+func (c *Client) RestoreWorkspace(ctx context.Context, workspaceID uuid.UUID) (WorkspaceArchive, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/archive/restore", workspaceID), nil)
+	if err != nil {
+		return WorkspaceArchive{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return WorkspaceArchive{}, readBodyAsError(res)
+	}
+	var archive WorkspaceArchive
+	return archive, json.NewDecoder(res.Body).Decode(&archive)
+}
+
+// TemplateArchivePolicy returns the archive policy of a template.
+func (c *Client) TemplateArchivePolicy(ctx context.Context, templateID uuid.UUID) (TemplateArchivePolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/archive-policy", templateID), nil)
+	if err != nil {
+		return TemplateArchivePolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateArchivePolicy{}, readBodyAsError(res)
+	}
+	var policy TemplateArchivePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// UpdateTemplateArchivePolicy sets the archive policy of a template.
+// Workspaces that are archived already keep their expiry.
+func (c *Client) UpdateTemplateArchivePolicy(ctx context.Context, templateID uuid.UUID, req TemplateArchivePolicy) (TemplateArchivePolicy, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/archive-policy", templateID), req)
+	if err != nil {
+		return TemplateArchivePolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateArchivePolicy{}, readBodyAsError(res)
+	}
+	var policy TemplateArchivePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
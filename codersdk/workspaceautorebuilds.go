@@ -0,0 +1,83 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// AutoRebuildAction is what Coder does to the workspaces of a template whose
+// agents fail repeatedly.
+type AutoRebuildAction string
+
+const (
+	// AutoRebuildActionRestart stops the workspace, then starts it again.
+	AutoRebuildActionRestart AutoRebuildAction = "restart"
+	// AutoRebuildActionRebuild starts the workspace again without stopping
+	// it first, which re-creates only the resources that changed.
+	AutoRebuildActionRebuild AutoRebuildAction = "rebuild"
+)
+
+// TemplateAutoRebuildPolicy restarts or rebuilds the workspaces of a template
+// after their agents fail a number of consecutive starts, on behalf of their
+// owners.
+type TemplateAutoRebuildPolicy struct {
+	// FailureThreshold is the number of consecutive starts whose agents
+	// failed before the workspace is rebuilt. Zero disables the policy.
+	FailureThreshold int32 `json:"failure_threshold"`
+	// AgentTimeoutMillis is how long agents have to connect after a start
+	// before it counts as failed.
+	AgentTimeoutMillis int64             `json:"agent_timeout_ms"`
+	Action             AutoRebuildAction `json:"action"`
+}
+
+// @typescript-ignore PostWorkspaceAgentStartupScriptRequest
+type PostWorkspaceAgentStartupScriptRequest struct {
+	ExitCode int32 `json:"exit_code"`
+}
+
+// TemplateAutoRebuildPolicy returns the auto-rebuild policy of a template.
+func (c *Client) TemplateAutoRebuildPolicy(ctx context.Context, templateID uuid.UUID) (TemplateAutoRebuildPolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/auto-rebuild-policy", templateID), nil)
+	if err != nil {
+		return TemplateAutoRebuildPolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateAutoRebuildPolicy{}, readBodyAsError(res)
+	}
+	var policy TemplateAutoRebuildPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// UpdateTemplateAutoRebuildPolicy sets the auto-rebuild policy of a template.
+func (c *Client) UpdateTemplateAutoRebuildPolicy(ctx context.Context, templateID uuid.UUID, req TemplateAutoRebuildPolicy) (TemplateAutoRebuildPolicy, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/auto-rebuild-policy", templateID), req)
+	if err != nil {
+		return TemplateAutoRebuildPolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateAutoRebuildPolicy{}, readBodyAsError(res)
+	}
+	var policy TemplateAutoRebuildPolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// PostWorkspaceAgentStartupScript reports the exit code of the agent's
+// startup script.
+func (c *Client) PostWorkspaceAgentStartupScript(ctx context.Context, req PostWorkspaceAgentStartupScriptRequest) error {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/startup-script", req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return readBodyAsError(res)
+	}
+	return nil
+}
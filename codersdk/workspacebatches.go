@@ -0,0 +1,103 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type WorkspaceBatchAction string
+
+const (
+	WorkspaceBatchActionStart  WorkspaceBatchAction = "start"
+	WorkspaceBatchActionStop   WorkspaceBatchAction = "stop"
+	WorkspaceBatchActionDelete WorkspaceBatchAction = "delete"
+	// WorkspaceBatchActionUpdate starts workspaces with the active version
+	// of their template.
+	WorkspaceBatchActionUpdate WorkspaceBatchAction = "update"
+)
+
+type WorkspaceBatchResultStatus string
+
+const (
+	WorkspaceBatchResultStatusPending   WorkspaceBatchResultStatus = "pending"
+	WorkspaceBatchResultStatusRunning   WorkspaceBatchResultStatus = "running"
+	WorkspaceBatchResultStatusSucceeded WorkspaceBatchResultStatus = "succeeded"
+	WorkspaceBatchResultStatusFailed    WorkspaceBatchResultStatus = "failed"
+)
+
+// WorkspaceBatchFilter selects the workspaces a bulk action applies to. Only
+// workspaces the user can read match, and empty fields match all of them.
+type WorkspaceBatchFilter struct {
+	// Owner is the username of the owner, or "me".
+	Owner string `json:"owner,omitempty"`
+	// Template is the name of the template.
+	Template string `json:"template,omitempty"`
+	// Name is a glob pattern matching workspace names, e.g. "dev-*".
+	Name string `json:"name,omitempty"`
+	// DormantMillis matches workspaces that weren't used for at least this
+	// long.
+	DormantMillis int64 `json:"dormant_ms,omitempty"`
+}
+
+// CreateWorkspaceBatchRequest applies an action to every workspace that
+// matches the filter.
+type CreateWorkspaceBatchRequest struct {
+	Action WorkspaceBatchAction `json:"action" validate:"required,oneof=start stop delete update"`
+	Filter WorkspaceBatchFilter `json:"filter"`
+}
+
+// WorkspaceBatch is a bulk action on workspaces. It runs in the background,
+// and its operation reports the overall progress.
+type WorkspaceBatch struct {
+	Operation Operation              `json:"operation"`
+	Results   []WorkspaceBatchResult `json:"results"`
+}
+
+// WorkspaceBatchResult is the outcome of a bulk action for one workspace.
+type WorkspaceBatchResult struct {
+	WorkspaceID   uuid.UUID                  `json:"workspace_id"`
+	WorkspaceName string                     `json:"workspace_name"`
+	Status        WorkspaceBatchResultStatus `json:"status"`
+	// BuildID is the build started for the workspace. It's empty when the
+	// workspace already was in the requested state.
+	BuildID *uuid.UUID `json:"build_id,omitempty"`
+	// Error is why the action failed for the workspace.
+	Error string `json:"error,omitempty"`
+}
+
+// CreateWorkspaceBatch starts an action on many workspaces at once. Poll the
+// operation or the batch for its progress.
+func (c *Client) CreateWorkspaceBatch(ctx context.Context, req CreateWorkspaceBatchRequest) (WorkspaceBatch, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/workspaces/batch", req)
+	if err != nil {
+		return WorkspaceBatch{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		return WorkspaceBatch{}, readBodyAsError(res)
+	}
+	var batch WorkspaceBatch
+	return batch, json.NewDecoder(res.Body).Decode(&batch)
+}
+
+// WorkspaceBatch returns the results of a bulk action on workspaces by the ID
+// of its operation.
+func (c *Client) WorkspaceBatch(ctx context.Context, operationID uuid.UUID) (WorkspaceBatch, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/batch/%s", operationID.String()), nil)
+	if err != nil {
+		return WorkspaceBatch{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceBatch{}, readBodyAsError(res)
+	}
+	var batch WorkspaceBatch
+	return batch, json.NewDecoder(res.Body).Decode(&batch)
+}
@@ -46,6 +46,14 @@ const (
 	// "autostop" is used when a build to stop a workspace is triggered by Autostop.
 	// The initiator id/username in this case is the workspace owner and can be ignored.
 	BuildReasonAutostop BuildReason = "autostop"
+	// "maintenance" is used when a workspace is updated to the active version of its
+	// template during the template's maintenance window.
+	// The initiator id/username in this case is the workspace owner and can be ignored.
+	BuildReasonMaintenance BuildReason = "maintenance"
+	// "agent_failure" is used when a workspace is restarted or rebuilt because its
+	// agents failed repeatedly, following the template's auto-rebuild policy.
+	// The initiator id/username in this case is the workspace owner and can be ignored.
+	BuildReasonAgentFailure BuildReason = "agent_failure"
 )
 
 // WorkspaceBuild is an at-point representation of a workspace state.
@@ -0,0 +1,143 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// TemplateResourceCosts is what running the resources of a template's
+// workspaces costs.
+type TemplateResourceCosts struct {
+	// HourlyCosts maps resource types, e.g. "aws_instance", to what a
+	// running resource of the type costs per hour, in the organization's
+	// currency. Resources of other types are free.
+	HourlyCosts map[string]float64 `json:"hourly_costs"`
+}
+
+// TemplateResourceCosts returns the resource costs of a template.
+func (c *Client) TemplateResourceCosts(ctx context.Context, templateID uuid.UUID) (TemplateResourceCosts, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/costs", templateID), nil)
+	if err != nil {
+		return TemplateResourceCosts{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateResourceCosts{}, readBodyAsError(res)
+	}
+	var costs TemplateResourceCosts
+	return costs, json.NewDecoder(res.Body).Decode(&costs)
+}
+
+// UpdateTemplateResourceCosts replaces the resource costs of a template. The
+// costs of workspaces that already ran are kept as they are.
+func (c *Client) UpdateTemplateResourceCosts(ctx context.Context, templateID uuid.UUID, req TemplateResourceCosts) (TemplateResourceCosts, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/costs", templateID), req)
+	if err != nil {
+		return TemplateResourceCosts{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateResourceCosts{}, readBodyAsError(res)
+	}
+	var costs TemplateResourceCosts
+	return costs, json.NewDecoder(res.Body).Decode(&costs)
+}
+
+type WorkspaceCostGroupBy string
+
+const (
+	WorkspaceCostGroupByOrganization WorkspaceCostGroupBy = "organization"
+	WorkspaceCostGroupByUser         WorkspaceCostGroupBy = "user"
+	WorkspaceCostGroupByGroup        WorkspaceCostGroupBy = "group"
+	WorkspaceCostGroupByTemplate     WorkspaceCostGroupBy = "template"
+)
+
+type WorkspaceCostReportRequest struct {
+	// StartTime defaults to 30 days before the end time.
+	StartTime time.Time `json:"start_time,omitempty"`
+	// EndTime defaults to now.
+	EndTime time.Time `json:"end_time,omitempty"`
+	// GroupBy defaults to the organization.
+	GroupBy WorkspaceCostGroupBy `json:"group_by,omitempty"`
+}
+
+// WorkspaceCostReport is what running the workspaces of an organization cost
+// over a time range. Costs accrue per hour, so the range is rounded to whole
+// hours.
+type WorkspaceCostReport struct {
+	OrganizationID uuid.UUID            `json:"organization_id"`
+	StartTime      time.Time            `json:"start_time"`
+	EndTime        time.Time            `json:"end_time"`
+	GroupBy        WorkspaceCostGroupBy `json:"group_by"`
+	TotalCost      float64              `json:"total_cost"`
+	// Entries are sorted by cost, highest first. Users are counted in every
+	// group they're a member of, so the costs of groups can add up to more
+	// than the total.
+	Entries []WorkspaceCostEntry `json:"entries"`
+}
+
+type WorkspaceCostEntry struct {
+	// ID is the ID of the organization, user, group, or template.
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Cost         float64   `json:"cost"`
+	RunningHours float64   `json:"running_hours"`
+}
+
+// WorkspaceCostReport returns the costs of an organization's workspaces over
+// a time range.
+func (c *Client) WorkspaceCostReport(ctx context.Context, organizationID uuid.UUID, req WorkspaceCostReportRequest) (WorkspaceCostReport, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/costs", organizationID.String()), nil,
+		req.asRequestOption(),
+	)
+	if err != nil {
+		return WorkspaceCostReport{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceCostReport{}, readBodyAsError(res)
+	}
+	var report WorkspaceCostReport
+	return report, json.NewDecoder(res.Body).Decode(&report)
+}
+
+// ExportWorkspaceCostReport returns the costs of an organization's workspaces
+// as a CSV file with the columns id, name, cost, and running_hours.
+func (c *Client) ExportWorkspaceCostReport(ctx context.Context, organizationID uuid.UUID, req WorkspaceCostReportRequest) ([]byte, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/costs/export", organizationID.String()), nil,
+		req.asRequestOption(),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, readBodyAsError(res)
+	}
+	return io.ReadAll(res.Body)
+}
+
+func (req WorkspaceCostReportRequest) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		if !req.StartTime.IsZero() {
+			q.Set("start_time", req.StartTime.Format(time.RFC3339))
+		}
+		if !req.EndTime.IsZero() {
+			q.Set("end_time", req.EndTime.Format(time.RFC3339))
+		}
+		if req.GroupBy != "" {
+			q.Set("group_by", string(req.GroupBy))
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
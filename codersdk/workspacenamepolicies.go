@@ -0,0 +1,86 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// WorkspaceNamePolicy restricts the names of workspaces, so fleets get
+// predictable names. It's enforced when workspaces are created or renamed,
+// and the policies of a workspace's organization and template both apply.
+// Zero values don't restrict names.
+type WorkspaceNamePolicy struct {
+	// Pattern is a regular expression that names must match in full.
+	Pattern string `json:"pattern"`
+	// Prefix is what names must start with. The placeholders {username} and
+	// {template} are replaced with the username of the workspace's owner and
+	// the name of its template.
+	Prefix string `json:"prefix"`
+	// ReservedNames can't be used as names, regardless of case.
+	ReservedNames []string `json:"reserved_names"`
+}
+
+// OrganizationWorkspaceNamePolicy returns the name policy of the workspaces
+// of an organization.
+func (c *Client) OrganizationWorkspaceNamePolicy(ctx context.Context, organizationID uuid.UUID) (WorkspaceNamePolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/workspace-name-policy", organizationID), nil)
+	if err != nil {
+		return WorkspaceNamePolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceNamePolicy{}, readBodyAsError(res)
+	}
+	var policy WorkspaceNamePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// UpdateOrganizationWorkspaceNamePolicy sets the name policy of the
+// workspaces of an organization. Existing workspaces keep their names.
+func (c *Client) UpdateOrganizationWorkspaceNamePolicy(ctx context.Context, organizationID uuid.UUID, req WorkspaceNamePolicy) (WorkspaceNamePolicy, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/workspace-name-policy", organizationID), req)
+	if err != nil {
+		return WorkspaceNamePolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceNamePolicy{}, readBodyAsError(res)
+	}
+	var policy WorkspaceNamePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// TemplateWorkspaceNamePolicy returns the name policy of the workspaces of a
+// template.
+func (c *Client) TemplateWorkspaceNamePolicy(ctx context.Context, templateID uuid.UUID) (WorkspaceNamePolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/workspace-name-policy", templateID), nil)
+	if err != nil {
+		return WorkspaceNamePolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceNamePolicy{}, readBodyAsError(res)
+	}
+	var policy WorkspaceNamePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// UpdateTemplateWorkspaceNamePolicy sets the name policy of the workspaces of
+// a template. Existing workspaces keep their names.
+func (c *Client) UpdateTemplateWorkspaceNamePolicy(ctx context.Context, templateID uuid.UUID, req WorkspaceNamePolicy) (WorkspaceNamePolicy, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/workspace-name-policy", templateID), req)
+	if err != nil {
+		return WorkspaceNamePolicy{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceNamePolicy{}, readBodyAsError(res)
+	}
+	var policy WorkspaceNamePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
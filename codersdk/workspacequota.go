@@ -5,11 +5,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 type WorkspaceQuota struct {
 	UserWorkspaceCount int `json:"user_workspace_count"`
 	UserWorkspaceLimit int `json:"user_workspace_limit"`
+	// Budgets are the user's group quota budgets, one per organization the
+	// user is a member of. They're only set if group quotas are enabled.
+	Budgets []WorkspaceQuotaBudget `json:"budgets,omitempty"`
+}
+
+// WorkspaceQuotaBudget is how much template weight a user can spend on
+// workspaces in an organization. The allowance is the sum of the
+// allowances of the user's groups. Users without an allowance aren't
+// limited.
+type WorkspaceQuotaBudget struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Allowance      int64     `json:"allowance"`
+	Consumed       int64     `json:"consumed"`
+	// Groups attributes the consumption to the groups that grant the
+	// allowance, filling them in order of name.
+	Groups    []WorkspaceQuotaGroup    `json:"groups"`
+	Templates []WorkspaceQuotaTemplate `json:"templates"`
+}
+
+type WorkspaceQuotaGroup struct {
+	GroupID   uuid.UUID `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Allowance int64     `json:"allowance"`
+	Consumed  int64     `json:"consumed"`
+}
+
+type WorkspaceQuotaTemplate struct {
+	TemplateID     uuid.UUID `json:"template_id"`
+	TemplateName   string    `json:"template_name"`
+	Weight         int32     `json:"weight"`
+	WorkspaceCount int64     `json:"workspace_count"`
+	Consumed       int64     `json:"consumed"`
 }
 
 func (c *Client) WorkspaceQuota(ctx context.Context, userID string) (WorkspaceQuota, error) {
@@ -24,3 +58,61 @@ func (c *Client) WorkspaceQuota(ctx context.Context, userID string) (WorkspaceQu
 	var quota WorkspaceQuota
 	return quota, json.NewDecoder(res.Body).Decode(&quota)
 }
+
+// OrganizationQuota caps the workspaces of an organization. A limit of 0
+// means the organization is unlimited.
+type OrganizationQuota struct {
+	MaxWorkspaces        int32 `json:"max_workspaces" validate:"min=0"`
+	MaxRunningWorkspaces int32 `json:"max_running_workspaces" validate:"min=0"`
+	// ComputeCredits limits the sum of the template quota weights of the
+	// organization's running workspaces.
+	ComputeCredits int32 `json:"compute_credits" validate:"min=0"`
+}
+
+// OrganizationQuotaConsumption reports an organization's quota and how much
+// of it its workspaces consume.
+type OrganizationQuotaConsumption struct {
+	OrganizationID         uuid.UUID                   `json:"organization_id"`
+	Quota                  OrganizationQuota           `json:"quota"`
+	WorkspaceCount         int64                       `json:"workspace_count"`
+	RunningWorkspaceCount  int64                       `json:"running_workspace_count"`
+	ComputeCreditsConsumed int64                       `json:"compute_credits_consumed"`
+	Templates              []OrganizationQuotaTemplate `json:"templates"`
+}
+
+type OrganizationQuotaTemplate struct {
+	TemplateID             uuid.UUID `json:"template_id"`
+	TemplateName           string    `json:"template_name"`
+	Weight                 int32     `json:"weight"`
+	WorkspaceCount         int64     `json:"workspace_count"`
+	RunningWorkspaceCount  int64     `json:"running_workspace_count"`
+	ComputeCreditsConsumed int64     `json:"compute_credits_consumed"`
+}
+
+// OrganizationQuota returns the quota of an organization and its consumption.
+func (c *Client) OrganizationQuota(ctx context.Context, organizationID uuid.UUID) (OrganizationQuotaConsumption, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/quota", organizationID), nil)
+	if err != nil {
+		return OrganizationQuotaConsumption{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OrganizationQuotaConsumption{}, readBodyAsError(res)
+	}
+	var consumption OrganizationQuotaConsumption
+	return consumption, json.NewDecoder(res.Body).Decode(&consumption)
+}
+
+// UpdateOrganizationQuota sets the quota of an organization.
+func (c *Client) UpdateOrganizationQuota(ctx context.Context, organizationID uuid.UUID, req OrganizationQuota) (OrganizationQuota, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/quota", organizationID), req)
+	if err != nil {
+		return OrganizationQuota{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OrganizationQuota{}, readBodyAsError(res)
+	}
+	var quota OrganizationQuota
+	return quota, json.NewDecoder(res.Body).Decode(&quota)
+}
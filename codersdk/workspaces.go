@@ -29,6 +29,18 @@ type Workspace struct {
 	AutostartSchedule *string        `json:"autostart_schedule,omitempty"`
 	TTLMillis         *int64         `json:"ttl_ms,omitempty"`
 	LastUsedAt        time.Time      `json:"last_used_at"`
+	// ACL lists the users and groups the owner shared the workspace with.
+	ACL WorkspaceACL `json:"acl"`
+	// Labels are set by the owner and the template, e.g. to filter
+	// workspaces by team with "label:team=payments".
+	Labels map[string]string `json:"labels"`
+	// MaintenanceAt is when the workspace will be updated and restarted
+	// during the maintenance window of its template. It's only set while the
+	// workspace is running an old version of the template.
+	MaintenanceAt *time.Time `json:"maintenance_at,omitempty"`
+	// TemplateDeprecation is set while the template of the workspace is
+	// deprecated, so the owner can migrate the workspace to its successor.
+	TemplateDeprecation *TemplateDeprecation `json:"template_deprecation,omitempty"`
 }
 
 // CreateWorkspaceBuildRequest provides options to update the latest workspace build.
@@ -245,6 +257,192 @@ func (c *Client) PutExtendWorkspace(ctx context.Context, id uuid.UUID, req PutEx
 	return nil
 }
 
+// ExtendWorkspaceRequest is a request to push back the deadline of the active
+// workspace build.
+type ExtendWorkspaceRequest struct {
+	DurationMillis int64 `json:"duration_ms" validate:"required,gt=0"`
+}
+
+// ExtendWorkspaceResponse is the new deadline of the active workspace build
+// and how much more it can be extended under the autostop extension policy.
+type ExtendWorkspaceResponse struct {
+	Deadline time.Time `json:"deadline"`
+	// ExtensionsLeft is how many more times the deadline can be extended in
+	// the next 24 hours, or nil if unlimited.
+	ExtensionsLeft *int32 `json:"extensions_left,omitempty"`
+	// ExtensionLeftMillis is how much further the deadline can be extended
+	// in the next 24 hours, or nil if unlimited.
+	ExtensionLeftMillis *int64 `json:"extension_left_ms,omitempty"`
+}
+
+// ExtendWorkspace pushes back the deadline of the active workspace build by a
+// duration, within the limits of the autostop extension policy.
+func (c *Client) ExtendWorkspace(ctx context.Context, id uuid.UUID, req ExtendWorkspaceRequest) (ExtendWorkspaceResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/extend", id.String()), req)
+	if err != nil {
+		return ExtendWorkspaceResponse{}, xerrors.Errorf("extend workspace time until shutdown: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ExtendWorkspaceResponse{}, readBodyAsError(res)
+	}
+	var extended ExtendWorkspaceResponse
+	return extended, json.NewDecoder(res.Body).Decode(&extended)
+}
+
+// TransferWorkspaceRequest moves a workspace to a template of another
+// organization.
+type TransferWorkspaceRequest struct {
+	OrganizationID uuid.UUID `json:"organization_id" validate:"required"`
+	TemplateID     uuid.UUID `json:"template_id" validate:"required"`
+}
+
+// TransferWorkspace moves a workspace and its builds to another
+// organization, and rebuilds it with the active version of the new template.
+func (c *Client) TransferWorkspace(ctx context.Context, id uuid.UUID, req TransferWorkspaceRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/transfer", id.String())
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("transfer workspace: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Workspace{}, readBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
+// UpdateWorkspaceOwnerRequest transfers a workspace to another member of its
+// organization.
+type UpdateWorkspaceOwnerRequest struct {
+	OwnerID uuid.UUID `json:"owner_id" validate:"required"`
+}
+
+// UpdateWorkspaceOwner transfers a workspace to another user. The tokens of
+// its agents are rotated, so running workspaces must be restarted for agents
+// that authenticate with a token to reconnect.
+func (c *Client) UpdateWorkspaceOwner(ctx context.Context, id uuid.UUID, req UpdateWorkspaceOwnerRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/owner", id.String())
+	res, err := c.Request(ctx, http.MethodPut, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("update workspace owner: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Workspace{}, readBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
+// UpdateWorkspaceLabelsRequest replaces the labels the owner set on a
+// workspace. Labels set by the template can't be changed.
+type UpdateWorkspaceLabelsRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// UpdateWorkspaceLabels replaces the labels of a workspace.
+func (c *Client) UpdateWorkspaceLabels(ctx context.Context, id uuid.UUID, req UpdateWorkspaceLabelsRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/labels", id.String())
+	res, err := c.Request(ctx, http.MethodPut, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("update workspace labels: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Workspace{}, readBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
+// MaxWorkspaceLabels is the maximum number of labels a workspace can have,
+// including those set by its template.
+const MaxWorkspaceLabels = 64
+
+// CloneWorkspaceRequest creates a workspace for the requester from the
+// template version and parameters of another workspace.
+type CloneWorkspaceRequest struct {
+	Name string `json:"name" validate:"workspace_name,required"`
+	// CopyData passes the ID of the source workspace to the template in the
+	// coder_workspace_clone_source parameter, so templates that support it
+	// can copy persistent data such as the home volume. Templates that
+	// don't declare the parameter can't be cloned with data.
+	CopyData bool `json:"copy_data,omitempty"`
+}
+
+// CloneWorkspace creates an identical workspace owned by the requester.
+func (c *Client) CloneWorkspace(ctx context.Context, id uuid.UUID, req CloneWorkspaceRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/clone", id.String())
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("clone workspace: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return Workspace{}, readBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
+// WorkspaceRole is the level of access a user or group is granted on a
+// workspace someone else owns. Each role includes the ones before it.
+type WorkspaceRole string
+
+const (
+	// WorkspaceRoleView grants reading the workspace and its builds.
+	WorkspaceRoleView WorkspaceRole = "view"
+	// WorkspaceRoleApp grants access to the apps of the workspace.
+	WorkspaceRoleApp WorkspaceRole = "app"
+	// WorkspaceRoleSSH grants SSH and terminal access to the workspace.
+	WorkspaceRoleSSH     WorkspaceRole = "ssh"
+	WorkspaceRoleDeleted WorkspaceRole = ""
+)
+
+// WorkspaceACL maps user and group IDs to their role on a workspace.
+type WorkspaceACL struct {
+	UserPerms  map[string]WorkspaceRole `json:"user_perms"`
+	GroupPerms map[string]WorkspaceRole `json:"group_perms"`
+}
+
+// UpdateWorkspaceACL grants users and groups a role on a workspace. A
+// deleted role revokes their access.
+type UpdateWorkspaceACL struct {
+	UserPerms  map[string]WorkspaceRole `json:"user_perms,omitempty"`
+	GroupPerms map[string]WorkspaceRole `json:"group_perms,omitempty"`
+}
+
+// WorkspaceACL returns the users and groups the workspace is shared with.
+func (c *Client) WorkspaceACL(ctx context.Context, id uuid.UUID) (WorkspaceACL, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/acl", id), nil)
+	if err != nil {
+		return WorkspaceACL{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceACL{}, readBodyAsError(res)
+	}
+	var acl WorkspaceACL
+	return acl, json.NewDecoder(res.Body).Decode(&acl)
+}
+
+// UpdateWorkspaceACL shares the workspace with, or revokes access from, the
+// users and groups in the request. Entries not in the request are kept.
+func (c *Client) UpdateWorkspaceACL(ctx context.Context, id uuid.UUID, req UpdateWorkspaceACL) (WorkspaceACL, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/workspaces/%s/acl", id), req)
+	if err != nil {
+		return WorkspaceACL{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceACL{}, readBodyAsError(res)
+	}
+	var acl WorkspaceACL
+	return acl, json.NewDecoder(res.Body).Decode(&acl)
+}
+
 type WorkspaceFilter struct {
 	// Owner can be "me" or a username
 	Owner string `json:"owner,omitempty" typescript:"-"`
@@ -254,6 +452,11 @@ type WorkspaceFilter struct {
 	Name string `json:"name,omitempty" typescript:"-"`
 	// FilterQuery supports a raw filter query string
 	FilterQuery string `json:"q,omitempty"`
+	// Sort changes the order of the workspaces, e.g. SortFavorites.
+	Sort string `json:"sort,omitempty"`
+	// Fields limits the workspaces to the given JSON fields, e.g. "id" and
+	// "name". The other fields are left empty.
+	Fields []string `json:"fields,omitempty"`
 }
 
 // asRequestOption returns a function that can be used in (*Client).Request.
@@ -279,6 +482,12 @@ func (f WorkspaceFilter) asRequestOption() RequestOption {
 
 		q := r.URL.Query()
 		q.Set("q", strings.Join(params, " "))
+		if len(f.Fields) > 0 {
+			q.Set("fields", strings.Join(f.Fields, ","))
+		}
+		if f.Sort != "" {
+			q.Set("sort", f.Sort)
+		}
 		r.URL.RawQuery = q.Encode()
 	}
 }
@@ -0,0 +1,99 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type WorkspaceTimelineEntryType string
+
+const (
+	WorkspaceTimelineEntryTypeBuild             WorkspaceTimelineEntryType = "build"
+	WorkspaceTimelineEntryTypeAutostopExtended  WorkspaceTimelineEntryType = "autostop_extended"
+	WorkspaceTimelineEntryTypeAgentConnected    WorkspaceTimelineEntryType = "agent_connected"
+	WorkspaceTimelineEntryTypeAgentDisconnected WorkspaceTimelineEntryType = "agent_disconnected"
+	WorkspaceTimelineEntryTypeAppOpened         WorkspaceTimelineEntryType = "app_opened"
+)
+
+type WorkspaceTimelineRequest struct {
+	// StartTime defaults to 24 hours before the end time.
+	StartTime time.Time `json:"start_time,omitempty"`
+	// EndTime defaults to now.
+	EndTime time.Time `json:"end_time,omitempty"`
+	// Limit defaults to 100, and can't be more than 1000.
+	Limit int `json:"limit,omitempty"`
+}
+
+// WorkspaceTimeline is what happened to a workspace over a time range.
+type WorkspaceTimeline struct {
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	// Entries are sorted by time, newest first. Only the newest entries up to
+	// the limit are returned, so older entries are fetched by setting the end
+	// time to the time of the oldest entry.
+	Entries []WorkspaceTimelineEntry `json:"entries"`
+}
+
+// WorkspaceTimelineEntry is a build, an extension of the autostop deadline,
+// an agent connecting or disconnecting, or an app being opened. Fields that
+// don't apply to the type are omitted.
+type WorkspaceTimelineEntry struct {
+	Time        time.Time                  `json:"time"`
+	Type        WorkspaceTimelineEntryType `json:"type"`
+	Description string                     `json:"description"`
+	// UserID is who started the build, extended the deadline, or opened the
+	// app.
+	UserID      *uuid.UUID           `json:"user_id,omitempty"`
+	Username    string               `json:"username,omitempty"`
+	BuildID     *uuid.UUID           `json:"build_id,omitempty"`
+	BuildNumber int32                `json:"build_number,omitempty"`
+	Transition  WorkspaceTransition  `json:"transition,omitempty"`
+	BuildReason BuildReason          `json:"build_reason,omitempty"`
+	JobStatus   ProvisionerJobStatus `json:"job_status,omitempty"`
+	// AutostopExtensionMillis is how far the autostop deadline was extended.
+	AutostopExtensionMillis int64      `json:"autostop_extension_ms,omitempty"`
+	AgentID                 *uuid.UUID `json:"agent_id,omitempty"`
+	AgentName               string     `json:"agent_name,omitempty"`
+	AppName                 string     `json:"app_name,omitempty"`
+}
+
+// WorkspaceTimeline returns what happened to a workspace over a time range.
+func (c *Client) WorkspaceTimeline(ctx context.Context, workspaceID uuid.UUID, req WorkspaceTimelineRequest) (WorkspaceTimeline, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/timeline", workspaceID), nil,
+		req.asRequestOption(),
+	)
+	if err != nil {
+		return WorkspaceTimeline{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceTimeline{}, readBodyAsError(res)
+	}
+	var timeline WorkspaceTimeline
+	return timeline, json.NewDecoder(res.Body).Decode(&timeline)
+}
+
+func (req WorkspaceTimelineRequest) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		if !req.StartTime.IsZero() {
+			q.Set("start_time", req.StartTime.Format(time.RFC3339Nano))
+		}
+		if !req.EndTime.IsZero() {
+			q.Set("end_time", req.EndTime.Format(time.RFC3339Nano))
+		}
+		if req.Limit > 0 {
+			q.Set("limit", strconv.Itoa(req.Limit))
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
@@ -0,0 +1,80 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// AgentUsage is the resource usage of the machine an agent runs on.
+type AgentUsage struct {
+	// CPUUsed is the number of cores in use, averaged since the previous
+	// sample.
+	CPUUsed  float64 `json:"cpu_used"`
+	CPUTotal float64 `json:"cpu_total"`
+	// MemoryUsedBytes excludes memory that can be reclaimed, like the page
+	// cache.
+	MemoryUsedBytes  int64 `json:"memory_used_bytes"`
+	MemoryTotalBytes int64 `json:"memory_total_bytes"`
+	// DiskUsedBytes and DiskTotalBytes are of the filesystem holding the
+	// agent's directory.
+	DiskUsedBytes  int64 `json:"disk_used_bytes"`
+	DiskTotalBytes int64 `json:"disk_total_bytes"`
+}
+
+type WorkspaceUsageRequest struct {
+	// StartTime defaults to the start of the retained window of 24 hours.
+	StartTime time.Time `json:"start_time,omitempty"`
+}
+
+// WorkspaceUsage is the resource usage of the agents of the latest build of a
+// workspace.
+type WorkspaceUsage struct {
+	WorkspaceID uuid.UUID             `json:"workspace_id"`
+	Agents      []WorkspaceAgentUsage `json:"agents"`
+}
+
+type WorkspaceAgentUsage struct {
+	AgentID   uuid.UUID `json:"agent_id"`
+	AgentName string    `json:"agent_name"`
+	// Samples are sorted by time, oldest first. They're taken each time the
+	// agent reports its stats.
+	Samples []WorkspaceAgentUsageSample `json:"samples"`
+}
+
+type WorkspaceAgentUsageSample struct {
+	Time time.Time `json:"time"`
+	AgentUsage
+}
+
+// WorkspaceUsage returns the resource usage of the agents of a workspace.
+func (c *Client) WorkspaceUsage(ctx context.Context, workspaceID uuid.UUID, req WorkspaceUsageRequest) (WorkspaceUsage, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/usage", workspaceID), nil,
+		req.asRequestOption(),
+	)
+	if err != nil {
+		return WorkspaceUsage{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceUsage{}, readBodyAsError(res)
+	}
+	var usage WorkspaceUsage
+	return usage, json.NewDecoder(res.Body).Decode(&usage)
+}
+
+func (req WorkspaceUsageRequest) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		if !req.StartTime.IsZero() {
+			q.Set("start_time", req.StartTime.Format(time.RFC3339Nano))
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
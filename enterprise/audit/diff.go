@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -122,6 +123,19 @@ func convertDiffType(left, right any) (newLeft, newRight any, changed bool) {
 
 		return leftStr, rightStr, true
 
+	case sql.NullTime:
+		var leftTimePtr *time.Time
+		if typedLeft.Valid {
+			leftTimePtr = ptr(typedLeft.Time)
+		}
+
+		var rightTimePtr *time.Time
+		if right.(sql.NullTime).Valid {
+			rightTimePtr = ptr(right.(sql.NullTime).Time)
+		}
+
+		return leftTimePtr, rightTimePtr, true
+
 	case sql.NullInt64:
 		var leftInt64Ptr *int64
 		var rightInt64Ptr *int64
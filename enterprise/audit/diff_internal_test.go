@@ -199,6 +199,23 @@ func Test_diff(t *testing.T) {
 		},
 	})
 
+	runDiffTests(t, []diffTest{
+		{
+			name: "Create",
+			left: audit.Empty[database.GroupMember](),
+			right: database.GroupMember{
+				UserID:    uuid.UUID{1},
+				GroupID:   uuid.UUID{2},
+				ExpiresAt: sql.NullTime{Time: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			},
+			exp: audit.Map{
+				"user_id":    audit.OldNew{Old: "", New: uuid.UUID{1}.String()},
+				"group_id":   audit.OldNew{Old: "", New: uuid.UUID{2}.String()},
+				"expires_at": audit.OldNew{Old: time.Time{}, New: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	})
+
 	runDiffTests(t, []diffTest{
 		{
 			name: "Create",
@@ -33,6 +33,12 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"private_key": ActionSecret, // We don't want to expose private keys in diffs.
 		"public_key":  ActionTrack,  // Public keys are ok to expose in a diff.
 	},
+	&database.GroupMember{}: {
+		"user_id":    ActionTrack,
+		"group_id":   ActionTrack,
+		"expires_at": ActionTrack,
+		"roles":      ActionTrack,
+	},
 	&database.OrganizationMember{}: {
 		"user_id":         ActionTrack,
 		"organization_id": ActionTrack,
@@ -41,11 +47,14 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"roles":           ActionTrack,
 	},
 	&database.Organization{}: {
-		"id":          ActionTrack,
-		"name":        ActionTrack,
-		"description": ActionTrack,
-		"created_at":  ActionIgnore, // Never changes, but is implicit and not helpful in a diff.
-		"updated_at":  ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"id":           ActionTrack,
+		"name":         ActionTrack,
+		"description":  ActionTrack,
+		"created_at":   ActionIgnore, // Never changes, but is implicit and not helpful in a diff.
+		"updated_at":   ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"is_default":   ActionTrack,
+		"display_name": ActionTrack,
+		"icon":         ActionTrack,
 	},
 	&database.Template{}: {
 		"id":                     ActionTrack,
@@ -62,6 +71,8 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"min_autostart_interval": ActionTrack,
 		"created_by":             ActionTrack,
 		"is_private":             ActionTrack,
+		"quota_weight":           ActionTrack,
+		"workspace_labels":       ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":              ActionTrack,
@@ -100,6 +111,7 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"autostart_schedule": ActionTrack,
 		"ttl":                ActionTrack,
 		"last_used_at":       ActionIgnore,
+		"labels":             ActionTrack,
 	},
 })
 
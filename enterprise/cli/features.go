@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -47,8 +46,7 @@ func featuresList() *cobra.Command {
 				return err
 			}
 			entitlements, err := client.Entitlements(cmd.Context())
-			var apiError *codersdk.Error
-			if errors.As(err, &apiError) && apiError.StatusCode() == http.StatusNotFound {
+			if errors.As(err, &codersdk.NotFoundError{}) {
 				return xerrors.New("You are on the AGPL licensed version of Coder that does not have Enterprise functionality!")
 			}
 			if err != nil {
@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	agpl "github.com/coder/coder/cli"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func groups() *cobra.Command {
+	cmd := &cobra.Command{
+		Short:   "Create, delete, and manage the members of groups",
+		Use:     "groups",
+		Aliases: []string{"group"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		groupsList(),
+		groupCreate(),
+		groupDelete(),
+		groupShow(),
+		groupAddMember(),
+		groupRemoveMember(),
+	)
+	return cmd
+}
+
+type groupRow struct {
+	ID          string `table:"id"`
+	Name        string `table:"name"`
+	DisplayName string `table:"display_name"`
+	Source      string `table:"source"`
+	Members     int    `table:"members"`
+}
+
+type groupMemberRow struct {
+	ID        string `table:"id"`
+	Username  string `table:"username"`
+	Email     string `table:"email"`
+	Roles     string `table:"roles"`
+	ExpiresAt string `table:"expires_at"`
+}
+
+func groupsList() *cobra.Command {
+	var (
+		columns      []string
+		outputFormat string
+	)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the groups of the current organization",
+		Aliases: []string{"ls"},
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := agpl.CurrentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+
+			groups := make([]codersdk.Group, 0)
+			it := client.GroupsIterator(cmd.Context(), organization.ID)
+			for it.Next() {
+				groups = append(groups, it.Value())
+			}
+			if it.Err() != nil {
+				return xerrors.Errorf("list groups: %w", it.Err())
+			}
+
+			out := ""
+			switch outputFormat {
+			case "table", "":
+				rows := make([]groupRow, 0, len(groups))
+				for _, group := range groups {
+					rows = append(rows, groupRow{
+						ID:          group.ID.String(),
+						Name:        group.Name,
+						DisplayName: group.DisplayName,
+						Source:      string(group.Source),
+						Members:     group.MembersCount,
+					})
+				}
+				out, err = cliui.DisplayTable(rows, "", columns)
+				if err != nil {
+					return xerrors.Errorf("render table: %w", err)
+				}
+			case "json":
+				outBytes, err := json.MarshalIndent(groups, "", "  ")
+				if err != nil {
+					return xerrors.Errorf("marshal groups to JSON: %w", err)
+				}
+				out = string(outBytes)
+			default:
+				return xerrors.Errorf(`unknown output format %q, only "table" and "json" are supported`, outputFormat)
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", []string{"name", "display_name", "source", "members"},
+		"Specify a column to filter in the table. Available columns are: id, name, display_name, source, members.")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format. Available formats are: table, json.")
+	return cmd
+}
+
+func groupCreate() *cobra.Command {
+	var (
+		displayName string
+		avatarURL   string
+		description string
+	)
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a group in the current organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			organization, err := agpl.CurrentOrganization(cmd, client)
+			if err != nil {
+				return err
+			}
+
+			group, err := client.CreateGroup(cmd.Context(), organization.ID, codersdk.CreateGroupRequest{
+				Name:        args[0],
+				DisplayName: displayName,
+				AvatarURL:   avatarURL,
+				Description: description,
+			})
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Group %s created\n", cliui.Styles.Keyword.Render(group.Name))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&displayName, "display-name", "", "Name of the group shown in the UI.")
+	cmd.Flags().StringVar(&avatarURL, "avatar-url", "", "URL of an image shown next to the group.")
+	cmd.Flags().StringVar(&description, "description", "", "Description of the group.")
+	return cmd
+}
+
+func groupDelete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete <name>",
+		Short:   "Delete a group from the current organization",
+		Aliases: []string{"rm"},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, group, err := namedGroup(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Delete group %s?", cliui.Styles.Code.Render(group.Name)),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = client.DeleteGroup(cmd.Context(), group.ID)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Group %s deleted\n", cliui.Styles.Keyword.Render(group.Name))
+			return nil
+		},
+	}
+	cliui.AllowSkipPrompt(cmd)
+	return cmd
+}
+
+func groupShow() *cobra.Command {
+	var (
+		columns      []string
+		outputFormat string
+	)
+	cmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a group and its members",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, group, err := namedGroup(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			out := ""
+			switch outputFormat {
+			case "table", "":
+				rows := make([]groupMemberRow, 0, len(group.Members))
+				for _, member := range group.Members {
+					var expiresAt string
+					if member.ExpiresAt != nil {
+						expiresAt = member.ExpiresAt.Format(time.RFC3339)
+					}
+					rows = append(rows, groupMemberRow{
+						ID:        member.ID.String(),
+						Username:  member.Username,
+						Email:     member.Email,
+						Roles:     strings.Join(member.GroupRoles, ", "),
+						ExpiresAt: expiresAt,
+					})
+				}
+				out, err = cliui.DisplayTable(rows, "username", columns)
+				if err != nil {
+					return xerrors.Errorf("render table: %w", err)
+				}
+				name := group.Name
+				if group.DisplayName != "" {
+					name = fmt.Sprintf("%s (%s)", group.Name, group.DisplayName)
+				}
+				out = fmt.Sprintf("%s\n%d members\n\n%s", cliui.Styles.Keyword.Render(name), group.MembersCount, out)
+			case "json":
+				outBytes, err := json.MarshalIndent(group, "", "  ")
+				if err != nil {
+					return xerrors.Errorf("marshal group to JSON: %w", err)
+				}
+				out = string(outBytes)
+			default:
+				return xerrors.Errorf(`unknown output format %q, only "table" and "json" are supported`, outputFormat)
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+	cmd.Flags().StringArrayVarP(&columns, "column", "c", []string{"username", "email", "roles", "expires_at"},
+		"Specify a column to filter in the members table. Available columns are: id, username, email, roles, expires_at.")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format. Available formats are: table, json.")
+	return cmd
+}
+
+func groupAddMember() *cobra.Command {
+	var expiresIn time.Duration
+	cmd := &cobra.Command{
+		Use:   "add-member <group> <user|@file>...",
+		Short: "Add users to a group",
+		Long: "Add users to a group. Users are identified by username, email, or ID. " +
+			"An argument starting with @ reads users from a file, one per line, with - reading from stdin.",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			users, err := readGroupMemberArgs(cmd, args[1:])
+			if err != nil {
+				return err
+			}
+			client, group, err := namedGroup(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			req := codersdk.PatchGroupRequest{
+				AddUsers: users,
+			}
+			if expiresIn > 0 {
+				expiresAt := time.Now().Add(expiresIn)
+				req.AddUsersExpireAt = &expiresAt
+			}
+			_, err = client.PatchGroup(cmd.Context(), group.ID, req)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added %d users to group %s\n", len(users), cliui.Styles.Keyword.Render(group.Name))
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&expiresIn, "expires-in", 0, "Remove the users from the group after this duration, e.g. 72h.")
+	return cmd
+}
+
+func groupRemoveMember() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-member <group> <user|@file>...",
+		Short: "Remove users from a group",
+		Long: "Remove users from a group. Users are identified by username, email, or ID. " +
+			"An argument starting with @ reads users from a file, one per line, with - reading from stdin.",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			users, err := readGroupMemberArgs(cmd, args[1:])
+			if err != nil {
+				return err
+			}
+			client, group, err := namedGroup(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = client.PatchGroup(cmd.Context(), group.ID, codersdk.PatchGroupRequest{
+				RemoveUsers: users,
+			})
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %d users from group %s\n", len(users), cliui.Styles.Keyword.Render(group.Name))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// namedGroup fetches a group of the current organization by name.
+func namedGroup(cmd *cobra.Command, name string) (*codersdk.Client, codersdk.Group, error) {
+	client, err := agpl.CreateClient(cmd)
+	if err != nil {
+		return nil, codersdk.Group{}, err
+	}
+	organization, err := agpl.CurrentOrganization(cmd, client)
+	if err != nil {
+		return nil, codersdk.Group{}, err
+	}
+	group, err := client.GroupByOrgAndName(cmd.Context(), organization.ID, name)
+	if err != nil {
+		return nil, codersdk.Group{}, xerrors.Errorf("get group %q: %w", name, err)
+	}
+	return client, group, nil
+}
+
+// readGroupMemberArgs expands arguments of the form @file into the users
+// listed in the file, one per line. Blank lines and lines starting with #
+// are ignored.
+func readGroupMemberArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	users := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "@") {
+			users = append(users, arg)
+			continue
+		}
+
+		filename := strings.TrimPrefix(arg, "@")
+		var scanner *bufio.Scanner
+		if filename == "-" {
+			scanner = bufio.NewScanner(cmd.InOrStdin())
+		} else {
+			f, err := os.Open(filename)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			scanner = bufio.NewScanner(f)
+		}
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			users = append(users, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, xerrors.Errorf("read %q: %w", filename, err)
+		}
+	}
+	if len(users) == 0 {
+		return nil, xerrors.New("no users were provided")
+	}
+	return users, nil
+}
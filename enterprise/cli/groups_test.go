@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/cli"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGroups(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+	_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+	run := func(t *testing.T, args ...string) string {
+		t.Helper()
+		ctx, _ := testutil.Context(t)
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), args...)
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	out := run(t, "groups", "create", "engineering", "--display-name", "Engineering")
+	require.Contains(t, out, "engineering")
+
+	filename := filepath.Join(t.TempDir(), "members.txt")
+	err := os.WriteFile(filename, []byte("# on call\n"+user2.Email+"\n\n"), 0o600)
+	require.NoError(t, err)
+	out = run(t, "groups", "add-member", "engineering", user1.Username, "@"+filename)
+	require.Contains(t, out, "Added 2 users")
+
+	var groups []codersdk.Group
+	err = json.Unmarshal([]byte(run(t, "groups", "list", "-o", "json")), &groups)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, "engineering", groups[0].Name)
+	require.Equal(t, 2, groups[0].MembersCount)
+
+	out = run(t, "groups", "show", "engineering")
+	require.Contains(t, out, user1.Username)
+	require.Contains(t, out, user2.Username)
+
+	out = run(t, "groups", "remove-member", "engineering", user1.ID.String())
+	require.Contains(t, out, "Removed 1 users")
+
+	var group codersdk.Group
+	err = json.Unmarshal([]byte(run(t, "groups", "show", "engineering", "-o", "json")), &group)
+	require.NoError(t, err)
+	require.Len(t, group.Members, 1)
+	require.Equal(t, user2.ID, group.Members[0].ID)
+
+	out = run(t, "groups", "delete", "engineering", "-y")
+	require.Contains(t, out, "deleted")
+	require.Contains(t, run(t, "groups", "list"), "NAME")
+}
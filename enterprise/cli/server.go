@@ -2,12 +2,21 @@ package cli
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/cli/deployment"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd"
+	"github.com/coder/coder/enterprise/coderd/groupmetadata"
 
 	agpl "github.com/coder/coder/cli"
 	agplcoderd "github.com/coder/coder/coderd"
@@ -18,12 +27,24 @@ func server() *cobra.Command {
 	cmd := agpl.Server(dflags, func(ctx context.Context, options *agplcoderd.Options) (*agplcoderd.API, error) {
 		options.DeploymentFlags = &dflags
 		o := &coderd.Options{
-			AuditLogging:       dflags.AuditLogging.Value,
-			BrowserOnly:        dflags.BrowserOnly.Value,
-			SCIMAPIKey:         []byte(dflags.SCIMAuthHeader.Value),
-			UserWorkspaceQuota: dflags.UserWorkspaceQuota.Value,
-			RBACEnabled:        true,
-			Options:            options,
+			AuditLogging:        dflags.AuditLogging.Value,
+			BrowserOnly:         dflags.BrowserOnly.Value,
+			SCIMAPIKey:          []byte(dflags.SCIMAuthHeader.Value),
+			SCIMOrganization:    dflags.SCIMOrganization.Value,
+			UserWorkspaceQuota:  dflags.UserWorkspaceQuota.Value,
+			GroupWorkspaceQuota: dflags.GroupWorkspaceQuota.Value,
+			RBACEnabled:         true,
+			Options:             options,
+
+			OrganizationWorkspaceQuota: dflags.OrganizationWorkspaceQuota.Value,
+
+			DeletedGroupRetention:     dflags.DeletedGroupRetention.Value,
+			GroupMetadataSyncInterval: dflags.OIDCGroupMetadataSyncInterval.Value,
+		}
+		var err error
+		o.GroupMetadataFetcher, err = groupMetadataFetcher(ctx, dflags)
+		if err != nil {
+			return nil, err
 		}
 		api, err := coderd.New(ctx, o)
 		if err != nil {
@@ -37,12 +58,73 @@ func server() *cobra.Command {
 	dflags.AuditLogging.Description += enterpriseOnly
 	dflags.BrowserOnly.Description += enterpriseOnly
 	dflags.SCIMAuthHeader.Description += enterpriseOnly
+	dflags.SCIMOrganization.Description += enterpriseOnly
 	dflags.UserWorkspaceQuota.Description += enterpriseOnly
+	dflags.GroupWorkspaceQuota.Description += enterpriseOnly
+	dflags.OrganizationWorkspaceQuota.Description += enterpriseOnly
+	dflags.DeletedGroupRetention.Description += enterpriseOnly
+	dflags.OIDCGroupMetadataProvider.Description += enterpriseOnly
+	dflags.OIDCGroupMetadataURL.Description += enterpriseOnly
+	dflags.OIDCGroupMetadataToken.Description += enterpriseOnly
+	dflags.OIDCGroupMetadataSyncInterval.Description += enterpriseOnly
 
 	deployment.BoolFlag(cmd.Flags(), &dflags.AuditLogging)
 	deployment.BoolFlag(cmd.Flags(), &dflags.BrowserOnly)
 	deployment.StringFlag(cmd.Flags(), &dflags.SCIMAuthHeader)
+	deployment.StringFlag(cmd.Flags(), &dflags.SCIMOrganization)
 	deployment.IntFlag(cmd.Flags(), &dflags.UserWorkspaceQuota)
+	deployment.BoolFlag(cmd.Flags(), &dflags.GroupWorkspaceQuota)
+	deployment.BoolFlag(cmd.Flags(), &dflags.OrganizationWorkspaceQuota)
+	deployment.DurationFlag(cmd.Flags(), &dflags.DeletedGroupRetention)
+	deployment.StringFlag(cmd.Flags(), &dflags.OIDCGroupMetadataProvider)
+	deployment.StringFlag(cmd.Flags(), &dflags.OIDCGroupMetadataURL)
+	deployment.StringFlag(cmd.Flags(), &dflags.OIDCGroupMetadataToken)
+	deployment.DurationFlag(cmd.Flags(), &dflags.OIDCGroupMetadataSyncInterval)
 
 	return cmd
 }
+
+// groupMetadataFetcher returns the fetcher for the configured group metadata
+// provider, or nil if none is configured.
+func groupMetadataFetcher(ctx context.Context, dflags codersdk.DeploymentFlags) (groupmetadata.Fetcher, error) {
+	provider := dflags.OIDCGroupMetadataProvider.Value
+	if provider == "" {
+		return nil, nil
+	}
+	if dflags.OIDCGroupField.Value == "" {
+		return nil, xerrors.Errorf("--%s requires --%s to be set", dflags.OIDCGroupMetadataProvider.Flag, dflags.OIDCGroupField.Flag)
+	}
+
+	rawURL := dflags.OIDCGroupMetadataURL.Value
+	if rawURL == "" && provider == "azure-ad" {
+		rawURL = "https://graph.microsoft.com"
+	}
+	baseURL, err := url.Parse(rawURL)
+	if err != nil || baseURL.Host == "" {
+		return nil, xerrors.Errorf("--%s must be a valid URL: %q", dflags.OIDCGroupMetadataURL.Flag, rawURL)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	switch provider {
+	case "azure-ad":
+		// Azure AD issuers look like
+		// https://login.microsoftonline.com/<tenant>/v2.0 and the token
+		// endpoint is next to them.
+		issuer := strings.TrimSuffix(strings.TrimSuffix(dflags.OIDCIssuerURL.Value, "/"), "/v2.0")
+		cfg := clientcredentials.Config{
+			ClientID:     dflags.OIDCClientID.Value,
+			ClientSecret: dflags.OIDCClientSecret.Value,
+			TokenURL:     issuer + "/oauth2/v2.0/token",
+			Scopes:       []string{baseURL.String() + "/.default"},
+		}
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+		return groupmetadata.NewAzureAD(cfg.Client(ctx), baseURL), nil
+	case "okta":
+		if dflags.OIDCGroupMetadataToken.Value == "" {
+			return nil, xerrors.Errorf("--%s is required for Okta", dflags.OIDCGroupMetadataToken.Flag)
+		}
+		return groupmetadata.NewOkta(client, baseURL, dflags.OIDCGroupMetadataToken.Value), nil
+	default:
+		return nil, xerrors.Errorf(`--%s must be "azure-ad" or "okta", got %q`, dflags.OIDCGroupMetadataProvider.Flag, provider)
+	}
+}
@@ -11,17 +11,21 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd"
 	agplaudit "github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/groupsync"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhookdelivery"
 	"github.com/coder/coder/coderd/workspacequota"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/audit"
 	"github.com/coder/coder/enterprise/audit/backends"
+	"github.com/coder/coder/enterprise/coderd/groupmetadata"
 	"github.com/coder/coder/enterprise/coderd/license"
 )
 
@@ -35,21 +39,51 @@ func New(ctx context.Context, options *Options) (*API, error) {
 	if options.Keys == nil {
 		options.Keys = Keys
 	}
+	if options.GroupMemberReapInterval == 0 {
+		options.GroupMemberReapInterval = time.Minute
+	}
+	if options.DeletedGroupRetention == 0 {
+		options.DeletedGroupRetention = 30 * 24 * time.Hour
+	}
+	if options.DeletedGroupReapInterval == 0 {
+		options.DeletedGroupReapInterval = time.Hour
+	}
+	if options.GroupWebhookRetryInterval == 0 {
+		options.GroupWebhookRetryInterval = time.Second
+	}
+	if options.GroupMetadataSyncInterval == 0 {
+		options.GroupMetadataSyncInterval = time.Hour
+	}
 	ctx, cancelFunc := context.WithCancel(ctx)
 	api := &API{
 		AGPL:                   coderd.New(options.Options),
 		Options:                options,
 		cancelEntitlementsLoop: cancelFunc,
 	}
+	api.AGPL.WebhookEngine.Register(webhookdelivery.KindGroup, api.groupWebhookHandler())
 	oauthConfigs := &httpmw.OAuth2Configs{
 		Github: options.GithubOAuth2Config,
 		OIDC:   options.OIDCConfig,
 	}
-	apiKeyMiddleware := httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
-		DB:              options.Database,
-		OAuth2Configs:   oauthConfigs,
-		RedirectToLogin: false,
+	extractAPIKey := httpmw.ExtractAPIKey(httpmw.ExtractAPIKeyConfig{
+		DB:                 options.Database,
+		OAuth2Configs:      oauthConfigs,
+		RedirectToLogin:    false,
+		ClientCertificates: options.ClientCertificates,
+	})
+	apiKeyMiddleware := func(next http.Handler) http.Handler {
+		return extractAPIKey(api.AGPL.APIKeyRateLimiter(api.AGPL.EnforceMaintenance(next)))
+	}
+	organizationIPAllowlist := api.AGPL.EnforceOrganizationIPAllowlist(func(r *http.Request) uuid.UUID {
+		return httpmw.OrganizationParam(r).ID
 	})
+	templateIPAllowlist := api.AGPL.EnforceOrganizationIPAllowlist(func(r *http.Request) uuid.UUID {
+		return httpmw.TemplateParam(r).OrganizationID
+	})
+	api.AGPL.Capabilities = append(api.AGPL.Capabilities, codersdk.CapabilityGroups)
+	for key, spec := range openAPISpecs() {
+		api.AGPL.OpenAPISpecs[key] = spec
+	}
 
 	api.AGPL.APIHandler.Group(func(r chi.Router) {
 		r.Get("/entitlements", api.serveEntitlements)
@@ -62,10 +96,38 @@ func New(ctx context.Context, options *Options) (*API, error) {
 		r.Route("/organizations/{organization}/groups", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
-				httpmw.ExtractOrganizationParam(api.Database),
+				httpmw.ExtractOrganizationParam(api.AGPL.OrganizationCache),
+				organizationIPAllowlist,
 			)
 			r.Post("/", api.postGroupByOrganization)
 			r.Get("/", api.groups)
+			r.Post("/sync/dry-run", api.groupSyncDryRun)
+			r.With(httpmw.ExtractGroupByNameParam(api.Database)).Get("/{groupname}", api.group)
+		})
+		r.Route("/organizations/{organization}/group-webhooks", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.AGPL.OrganizationCache),
+				organizationIPAllowlist,
+			)
+			r.Get("/", api.groupWebhooks)
+			r.Post("/", api.postGroupWebhook)
+			r.Delete("/{webhook}", api.deleteGroupWebhook)
+		})
+
+		r.Route("/organizations/{organization}/everyone-exclusions", func(r chi.Router) {
+			r.Use(
+				api.rbacEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.AGPL.OrganizationCache),
+				organizationIPAllowlist,
+			)
+			r.Get("/", api.everyoneGroupExclusions)
+			r.Route("/{user}", func(r chi.Router) {
+				r.Use(httpmw.ExtractUserParam(api.Database))
+				r.Put("/", api.putEveryoneGroupExclusion)
+				r.Delete("/", api.deleteEveryoneGroupExclusion)
+			})
 		})
 
 		r.Route("/templates/{template}/acl", func(r chi.Router) {
@@ -73,20 +135,91 @@ func New(ctx context.Context, options *Options) (*API, error) {
 				api.rbacEnabledMW,
 				apiKeyMiddleware,
 				httpmw.ExtractTemplateParam(api.Database),
+				templateIPAllowlist,
 			)
 			r.Get("/", api.templateACL)
 			r.Patch("/", api.patchTemplateACL)
 		})
+		r.Route("/templates/{template}/effective-access", func(r chi.Router) {
+			r.Use(
+				api.rbacEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractTemplateParam(api.Database),
+				templateIPAllowlist,
+			)
+			r.Get("/", api.templateEffectiveAccess)
+		})
 
-		r.Route("/groups/{group}", func(r chi.Router) {
+		r.Route("/groups", func(r chi.Router) {
 			r.Use(
 				api.rbacEnabledMW,
 				apiKeyMiddleware,
-				httpmw.ExtractGroupParam(api.Database),
 			)
-			r.Get("/", api.group)
-			r.Patch("/", api.patchGroup)
-			r.Delete("/", api.deleteGroup)
+			r.Post("/", api.postDeploymentGroup)
+			r.Get("/", api.deploymentGroups)
+			r.Route("/{group}", func(r chi.Router) {
+				r.With(httpmw.ExtractDeletedGroupParam(api.Database)).Post("/restore", api.restoreGroup)
+				r.Group(func(r chi.Router) {
+					r.Use(
+						httpmw.ExtractGroupParam(api.Database),
+						api.AGPL.EnforceOrganizationIPAllowlist(func(r *http.Request) uuid.UUID {
+							// Deployment groups don't belong to an organization,
+							// so they don't have an allowlist.
+							return httpmw.GroupParam(r).OrganizationID.UUID
+						}),
+					)
+					r.Get("/", api.group)
+					r.With(httpmw.Limit(httpmw.RouteLimit{
+						Timeout:      30 * time.Second,
+						MaxBodyBytes: 1 << 20,
+					})).Patch("/", api.patchGroup)
+					r.Delete("/", api.deleteGroup)
+					r.Get("/deletion-impact", api.groupDeletionImpact)
+					r.Get("/templates", api.groupTemplates)
+					r.Get("/members", api.groupMembersPage)
+					r.Put("/members", api.putGroupMembers)
+					r.With(httpmw.Limit(httpmw.RouteLimit{MaxBodyBytes: 10 << 20})).Post("/members/import", api.importGroupMembers)
+					r.Get("/members/export", api.exportGroupMembers)
+					r.With(httpmw.ExtractUserParam(api.Database)).Put("/members/{user}/roles", api.putGroupMemberRoles)
+					r.Route("/join-requests", func(r chi.Router) {
+						r.Post("/", api.postGroupJoinRequest)
+						r.Get("/", api.groupJoinRequests)
+						r.Route("/{request}", func(r chi.Router) {
+							r.Use(httpmw.ExtractGroupJoinRequestParam(api.Database))
+							r.Post("/approve", api.approveGroupJoinRequest)
+							r.Post("/deny", api.denyGroupJoinRequest)
+						})
+					})
+				})
+			})
+		})
+
+		r.Route("/users/{user}/groups", func(r chi.Router) {
+			r.Use(
+				api.rbacEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractUserParam(api.Database),
+			)
+			r.Get("/", api.userGroups)
+		})
+
+		r.Route("/organizations/{organization}/quota", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.AGPL.OrganizationCache),
+				organizationIPAllowlist,
+			)
+			r.Get("/", api.organizationQuota)
+			r.Put("/", api.putOrganizationQuota)
+		})
+
+		r.Route("/templates/{template}/quota", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				httpmw.ExtractTemplateParam(api.Database),
+				templateIPAllowlist,
+			)
+			r.Patch("/", api.patchTemplateQuota)
 		})
 
 		r.Route("/workspace-quota", func(r chi.Router) {
@@ -108,6 +241,14 @@ func New(ctx context.Context, options *Options) (*API, error) {
 				r.Get("/{id}", api.scimGetUser)
 				r.Patch("/{id}", api.scimPatchUser)
 			})
+			r.Route("/Groups", func(r chi.Router) {
+				r.Get("/", api.scimGetGroups)
+				r.Post("/", api.scimPostGroup)
+				r.Get("/{id}", api.scimGetGroup)
+				r.Put("/{id}", api.scimPutGroup)
+				r.Patch("/{id}", api.scimPatchGroup)
+				r.Delete("/{id}", api.scimDeleteGroup)
+			})
 		})
 	}
 
@@ -116,6 +257,11 @@ func New(ctx context.Context, options *Options) (*API, error) {
 		return nil, xerrors.Errorf("update entitlements: %w", err)
 	}
 	go api.runEntitlementsLoop(ctx)
+	go api.runGroupMemberReaper(ctx)
+	go api.runDeletedGroupReaper(ctx)
+	if options.GroupMetadataFetcher != nil {
+		go api.runGroupMetadataSync(ctx)
+	}
 
 	return api, nil
 }
@@ -126,12 +272,37 @@ type Options struct {
 	RBACEnabled  bool
 	AuditLogging bool
 	// Whether to block non-browser connections.
-	BrowserOnly        bool
-	SCIMAPIKey         []byte
+	BrowserOnly bool
+	SCIMAPIKey  []byte
+	// SCIMOrganization is the name or ID of the organization groups pushed
+	// over SCIM are created in. The first organization is used when it's
+	// empty.
+	SCIMOrganization   string
 	UserWorkspaceQuota int
+	// GroupWorkspaceQuota enables budgets from group quota allowances.
+	GroupWorkspaceQuota bool
+	// OrganizationWorkspaceQuota enables the quotas of organizations.
+	OrganizationWorkspaceQuota bool
 
 	EntitlementsUpdateInterval time.Duration
-	Keys                       map[string]ed25519.PublicKey
+	// GroupMemberReapInterval is how often expired group memberships are
+	// removed.
+	GroupMemberReapInterval time.Duration
+	// DeletedGroupRetention is how long deleted groups can be restored
+	// before they're removed permanently.
+	DeletedGroupRetention time.Duration
+	// DeletedGroupReapInterval is how often deleted groups past their
+	// retention are removed.
+	DeletedGroupReapInterval time.Duration
+	// GroupWebhookRetryInterval is the initial delay before a failed group
+	// webhook delivery is retried. The delay doubles with every attempt.
+	GroupWebhookRetryInterval time.Duration
+	// GroupMetadataFetcher updates the display name and picture of groups
+	// synced from the identity provider every GroupMetadataSyncInterval.
+	// It's disabled when nil.
+	GroupMetadataFetcher      groupmetadata.Fetcher
+	GroupMetadataSyncInterval time.Duration
+	Keys                      map[string]ed25519.PublicKey
 }
 
 type API struct {
@@ -156,7 +327,7 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 		codersdk.FeatureAuditLog:       api.AuditLogging,
 		codersdk.FeatureBrowserOnly:    api.BrowserOnly,
 		codersdk.FeatureSCIM:           len(api.SCIMAPIKey) != 0,
-		codersdk.FeatureWorkspaceQuota: api.UserWorkspaceQuota != 0,
+		codersdk.FeatureWorkspaceQuota: api.UserWorkspaceQuota != 0 || api.GroupWorkspaceQuota || api.OrganizationWorkspaceQuota,
 		codersdk.FeatureRBAC:           api.RBACEnabled,
 	})
 	if err != nil {
@@ -198,11 +369,19 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 	if changed, enabled := featureChanged(codersdk.FeatureWorkspaceQuota); changed {
 		enforcer := workspacequota.NewNop()
 		if enabled {
-			enforcer = NewEnforcer(api.Options.UserWorkspaceQuota)
+			enforcer = NewEnforcer(api.Database, api.Options.UserWorkspaceQuota, api.Options.GroupWorkspaceQuota, api.Options.OrganizationWorkspaceQuota)
 		}
 		api.AGPL.WorkspaceQuotaEnforcer.Store(&enforcer)
 	}
 
+	if changed, enabled := featureChanged(codersdk.FeatureRBAC); changed {
+		syncer := groupsync.NewNop()
+		if enabled {
+			syncer = NewGroupSyncer(api.Logger.Named("group_sync"))
+		}
+		api.AGPL.GroupSyncer.Store(&syncer)
+	}
+
 	api.entitlements = entitlements
 
 	return nil
@@ -2,6 +2,8 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -13,6 +15,7 @@ import (
 	agplaudit "github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/openapi"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/audit"
 	"github.com/coder/coder/enterprise/coderd"
@@ -152,6 +155,30 @@ func TestEntitlements(t *testing.T) {
 	})
 }
 
+func TestAPIMetaCapabilities(t *testing.T) {
+	t.Parallel()
+	client := coderdenttest.New(t, nil)
+	meta, err := client.APIMeta(context.Background())
+	require.NoError(t, err)
+	require.True(t, meta.HasCapability(codersdk.CapabilityGroups))
+}
+
+func TestOpenAPIDocumentIncludesEnterpriseRoutes(t *testing.T) {
+	t.Parallel()
+	client := coderdenttest.New(t, nil)
+	res, err := client.Request(context.Background(), http.MethodGet, "/api/v2/openapi.json", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var doc openapi.Document
+	err = json.NewDecoder(res.Body).Decode(&doc)
+	require.NoError(t, err)
+	require.Contains(t, doc.Paths["/organizations/{organization}/groups"], "post")
+	require.Contains(t, doc.Paths["/groups/{group}"], "patch")
+	require.Contains(t, doc.Components.Schemas, "Group")
+}
+
 func TestAuditLogging(t *testing.T) {
 	t.Parallel()
 	t.Run("Enabled", func(t *testing.T) {
@@ -15,6 +15,7 @@ import (
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd"
+	"github.com/coder/coder/enterprise/coderd/groupmetadata"
 	"github.com/coder/coder/enterprise/coderd/license"
 )
 
@@ -42,9 +43,18 @@ type Options struct {
 	*coderdtest.Options
 	AuditLogging               bool
 	BrowserOnly                bool
+	DeletedGroupRetention      time.Duration
+	DeletedGroupReapInterval   time.Duration
 	EntitlementsUpdateInterval time.Duration
+	GroupMemberReapInterval    time.Duration
+	GroupWebhookRetryInterval  time.Duration
+	GroupMetadataFetcher       groupmetadata.Fetcher
+	GroupMetadataSyncInterval  time.Duration
 	SCIMAPIKey                 []byte
+	SCIMOrganization           string
 	UserWorkspaceQuota         int
+	GroupWorkspaceQuota        bool
+	OrganizationWorkspaceQuota bool
 }
 
 // New constructs a codersdk client connected to an in-memory Enterprise API instance.
@@ -66,9 +76,18 @@ func NewWithAPI(t *testing.T, options *Options) (*codersdk.Client, io.Closer, *c
 		AuditLogging:               options.AuditLogging,
 		BrowserOnly:                options.BrowserOnly,
 		SCIMAPIKey:                 options.SCIMAPIKey,
+		SCIMOrganization:           options.SCIMOrganization,
 		UserWorkspaceQuota:         options.UserWorkspaceQuota,
+		GroupWorkspaceQuota:        options.GroupWorkspaceQuota,
+		OrganizationWorkspaceQuota: options.OrganizationWorkspaceQuota,
 		Options:                    oop,
 		EntitlementsUpdateInterval: options.EntitlementsUpdateInterval,
+		GroupMemberReapInterval:    options.GroupMemberReapInterval,
+		DeletedGroupRetention:      options.DeletedGroupRetention,
+		DeletedGroupReapInterval:   options.DeletedGroupReapInterval,
+		GroupWebhookRetryInterval:  options.GroupWebhookRetryInterval,
+		GroupMetadataFetcher:       options.GroupMetadataFetcher,
+		GroupMetadataSyncInterval:  options.GroupMetadataSyncInterval,
 		Keys:                       Keys,
 	})
 	assert.NoError(t, err)
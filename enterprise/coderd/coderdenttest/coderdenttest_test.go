@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
@@ -39,11 +40,21 @@ func TestAuthorizeAllEndpoints(t *testing.T) {
 		Name: "testgroup",
 	})
 	require.NoError(t, err)
+	joinRequest, err := client.CreateGroupJoinRequest(ctx, group.ID)
+	require.NoError(t, err)
+	_, err = client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+		Name: "testdeploymentgroup",
+	})
+	require.NoError(t, err)
 
 	groupObj := rbac.ResourceGroup.InOrg(admin.OrganizationID)
+	groupMemberObj := rbac.ResourceGroupMember.InOrg(admin.OrganizationID)
 	a := coderdtest.NewAuthTester(ctx, t, client, api.AGPL, admin)
 	a.URLParams["licenses/{id}"] = fmt.Sprintf("licenses/%d", license.ID)
 	a.URLParams["groups/{group}"] = fmt.Sprintf("groups/%s", group.ID.String())
+	a.URLParams["groups/{groupname}"] = fmt.Sprintf("groups/%s", group.Name)
+	a.URLParams["join-requests/{request}"] = fmt.Sprintf("join-requests/%s", joinRequest.ID.String())
+	a.URLParams["group-webhooks/{webhook}"] = fmt.Sprintf("group-webhooks/%s", uuid.NewString())
 
 	skipRoutes, assertRoute := coderdtest.AGPLRoutes(a)
 	assertRoute["GET:/api/v2/entitlements"] = coderdtest.RouteCheck{
@@ -70,23 +81,76 @@ func TestAuthorizeAllEndpoints(t *testing.T) {
 		AssertAction: rbac.ActionCreate,
 		AssertObject: rbac.ResourceTemplate,
 	}
+	assertRoute["GET:/api/v2/templates/{template}/effective-access"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionRead,
+		AssertObject: rbac.ResourceTemplate,
+	}
+	assertRoute["PATCH:/api/v2/templates/{template}/quota"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionUpdate,
+		AssertObject: rbac.ResourceTemplate,
+	}
+	assertRoute["GET:/api/v2/organizations/{organization}/quota"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionRead,
+		AssertObject: rbac.ResourceOrganization,
+	}
+	assertRoute["PUT:/api/v2/organizations/{organization}/quota"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionUpdate,
+		AssertObject: rbac.ResourceOrganization,
+	}
+	// Endpoints that use the SQLQuery filter.
 	assertRoute["GET:/api/v2/organizations/{organization}/groups"] = coderdtest.RouteCheck{
-		StatusCode:   http.StatusOK,
+		StatusCode:  http.StatusOK,
+		NoAuthorize: true,
+	}
+	assertRoute["GET:/api/v2/organizations/{organization}/groups/{groupname}"] = coderdtest.RouteCheck{
 		AssertAction: rbac.ActionRead,
 		AssertObject: groupObj,
 	}
-	assertRoute["PATCH:/api/v2/groups/{group}"] = coderdtest.RouteCheck{
+	assertRoute["GET:/api/v2/organizations/{organization}/group-webhooks"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionUpdate,
+		AssertObject: groupObj,
+	}
+	assertRoute["POST:/api/v2/organizations/{organization}/group-webhooks"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionUpdate,
+		AssertObject: groupObj,
+	}
+	assertRoute["DELETE:/api/v2/organizations/{organization}/group-webhooks/{webhook}"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionUpdate,
+		AssertObject: groupObj,
+	}
+	assertRoute["GET:/api/v2/organizations/{organization}/everyone-exclusions"] = coderdtest.RouteCheck{
 		AssertAction: rbac.ActionRead,
 		AssertObject: groupObj,
 	}
-	assertRoute["PATCH:/api/v2/groups/{group}"] = coderdtest.RouteCheck{
+	assertRoute["PUT:/api/v2/organizations/{organization}/everyone-exclusions/{user}"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionUpdate,
+		AssertObject: groupObj,
+	}
+	assertRoute["DELETE:/api/v2/organizations/{organization}/everyone-exclusions/{user}"] = coderdtest.RouteCheck{
 		AssertAction: rbac.ActionUpdate,
 		AssertObject: groupObj,
 	}
+	assertRoute["GET:/api/v2/groups/"] = coderdtest.RouteCheck{
+		StatusCode:  http.StatusOK,
+		NoAuthorize: true,
+	}
+	assertRoute["PATCH:/api/v2/groups/{group}"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionRead,
+		AssertObject: groupMemberObj,
+	}
+	assertRoute["GET:/api/v2/groups/{group}/members"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionRead,
+		AssertObject: groupMemberObj,
+	}
 	assertRoute["DELETE:/api/v2/groups/{group}"] = coderdtest.RouteCheck{
 		AssertAction: rbac.ActionDelete,
 		AssertObject: groupObj,
 	}
+	// The group hasn't been deleted, so there's nothing to restore.
+	assertRoute["POST:/api/v2/groups/{group}/restore"] = coderdtest.RouteCheck{
+		StatusCode:  http.StatusNotFound,
+		NoAuthorize: true,
+	}
 
 	a.Test(context.Background(), assertRoute, skipRoutes)
 }
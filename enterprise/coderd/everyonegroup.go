@@ -0,0 +1,132 @@
+package coderd
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) everyoneGroupExclusions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	exclusions, err := api.Database.GetEveryoneGroupExclusionsByOrganizationID(ctx, org.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(exclusions))
+	for _, exclusion := range exclusions {
+		userIDs = append(userIDs, exclusion.UserID)
+	}
+	users := make(map[uuid.UUID]database.User, len(userIDs))
+	if len(userIDs) > 0 {
+		dbUsers, err := api.Database.GetUsersByIDs(ctx, userIDs)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		for _, user := range dbUsers {
+			users[user.ID] = user
+		}
+	}
+
+	resp := make([]codersdk.EveryoneGroupExclusion, 0, len(exclusions))
+	for _, exclusion := range exclusions {
+		user, ok := users[exclusion.UserID]
+		if !ok {
+			continue
+		}
+		resp = append(resp, codersdk.EveryoneGroupExclusion{
+			User:      convertUser(user, []uuid.UUID{org.ID}),
+			CreatedAt: exclusion.CreatedAt,
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) putEveryoneGroupExclusion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		org  = httpmw.OrganizationParam(r)
+		user = httpmw.UserParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	_, err := api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+	})
+	if xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("User %q is not a member of the organization.", user.Username),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	err = api.Database.InsertEveryoneGroupExclusion(ctx, database.InsertEveryoneGroupExclusionParams{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		CreatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: fmt.Sprintf("Excluded %q from the %q group.", user.Username, database.AllUsersGroup),
+	})
+}
+
+func (api *API) deleteEveryoneGroupExclusion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		org  = httpmw.OrganizationParam(r)
+		user = httpmw.UserParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteEveryoneGroupExclusion(ctx, database.DeleteEveryoneGroupExclusionParams{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: fmt.Sprintf("Included %q in the %q group.", user.Username, database.AllUsersGroup),
+	})
+}
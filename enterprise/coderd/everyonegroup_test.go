@@ -0,0 +1,88 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestEveryoneGroupExclusions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TemplateACL", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client1.Template(ctx, template.ID)
+		require.NoError(t, err)
+
+		err = client.ExcludeFromEveryoneGroup(ctx, user.OrganizationID, user1.Username)
+		require.NoError(t, err)
+		// Excluding twice is a no-op.
+		err = client.ExcludeFromEveryoneGroup(ctx, user.OrganizationID, user1.ID.String())
+		require.NoError(t, err)
+
+		exclusions, err := client.EveryoneGroupExclusions(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, exclusions, 1)
+		require.Equal(t, user1.ID, exclusions[0].User.ID)
+
+		// The template is only shared with the Everyone group.
+		_, err = client1.Template(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		acl, err := client.TemplateACL(ctx, template.ID)
+		require.NoError(t, err)
+		require.Len(t, acl.Groups, 1)
+		require.Len(t, acl.Groups[0].Members, 1)
+		require.Equal(t, user.UserID, acl.Groups[0].Members[0].ID)
+
+		err = client.IncludeInEveryoneGroup(ctx, user.OrganizationID, user1.Username)
+		require.NoError(t, err)
+		_, err = client1.Template(ctx, template.ID)
+		require.NoError(t, err)
+
+		exclusions, err = client.EveryoneGroupExclusions(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, exclusions, 0)
+	})
+
+	t.Run("NotMember", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "other",
+		})
+		require.NoError(t, err)
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, org.ID)
+
+		err = client.ExcludeFromEveryoneGroup(ctx, user.OrganizationID, user1.Username)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
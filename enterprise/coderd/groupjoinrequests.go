@@ -0,0 +1,253 @@
+package coderd
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) postGroupJoinRequest(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		group  = httpmw.GroupParam(r)
+		apiKey = httpmw.APIKey(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	if group.Name == database.AllUsersGroup {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Members of the %q group cannot be changed!", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
+		})
+		return
+	}
+	if !writeGroupMembersAllowed(ctx, rw, group) {
+		return
+	}
+
+	members, err := api.groupMembers(ctx, group.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if slices.IndexFunc(members, func(member groupMember) bool {
+		return member.ID == apiKey.UserID
+	}) >= 0 {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("You are already a member of group %q.", group.Name),
+			Code:    codersdk.ErrorCodeGroupMemberExists,
+		})
+		return
+	}
+
+	joinRequest, err := api.Database.InsertGroupJoinRequest(ctx, database.InsertGroupJoinRequestParams{
+		ID:        uuid.New(),
+		GroupID:   group.ID,
+		UserID:    apiKey.UserID,
+		CreatedAt: database.Now(),
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("You have already requested to join group %q.", group.Name),
+			Code:    codersdk.ErrorCodeGroupJoinRequestExists,
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	joinRequests, err := api.convertGroupJoinRequests(r, []database.GroupJoinRequest{joinRequest})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, joinRequests[0])
+}
+
+func (api *API) groupJoinRequests(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionRead, membersObj) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	joinRequests, err := api.Database.GetGroupJoinRequestsByGroupID(ctx, group.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp, err := api.convertGroupJoinRequests(r, joinRequests)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) approveGroupJoinRequest(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx         = r.Context()
+		group       = httpmw.GroupParam(r)
+		joinRequest = httpmw.GroupJoinRequestParam(r)
+	)
+
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, membersObj) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !writeGroupMembersAllowed(ctx, rw, group) {
+		return
+	}
+
+	// The user may have left the organization since making the request.
+	allowed, err := api.allowedGroupMembers(ctx, group, []uuid.UUID{joinRequest.UserID})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if _, ok := allowed[joinRequest.UserID]; !ok {
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: fmt.Sprintf("User %q must be a member of organization %q", joinRequest.UserID, group.OrganizationID.UUID),
+			Code:    codersdk.ErrorCodeOrgMemberRequired,
+		})
+		return
+	}
+	if !api.authorizeGroupMembersAdded(rw, r, group, []uuid.UUID{joinRequest.UserID}) {
+		return
+	}
+
+	var added []uuid.UUID
+	err = api.Database.InTx(func(tx database.Store) error {
+		var err error
+		// Users added to the group since requesting to join are
+		// skipped rather than failing the approval.
+		added, err = tx.InsertGroupMembers(ctx, database.InsertGroupMembersParams{
+			GroupID: group.ID,
+			UserIds: []uuid.UUID{joinRequest.UserID},
+		})
+		if err != nil {
+			return xerrors.Errorf("insert group member: %w", err)
+		}
+		err = tx.DeleteGroupJoinRequestByID(ctx, joinRequest.ID)
+		if err != nil {
+			return xerrors.Errorf("delete group join request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	addedMembers := make([]database.GroupMember, 0, len(added))
+	for _, id := range added {
+		addedMembers = append(addedMembers, database.GroupMember{
+			GroupID: group.ID,
+			UserID:  id,
+		})
+	}
+	commitAudit := api.auditGroupMembers(rw, r, group, addedMembers, nil)
+	defer commitAudit()
+
+	members, err := api.groupMembers(ctx, group.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertGroup(group, members))
+}
+
+func (api *API) denyGroupJoinRequest(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx         = r.Context()
+		group       = httpmw.GroupParam(r)
+		joinRequest = httpmw.GroupJoinRequestParam(r)
+	)
+
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, membersObj) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err = api.Database.DeleteGroupJoinRequestByID(ctx, joinRequest.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully denied group join request!",
+	})
+}
+
+// convertGroupJoinRequests converts join requests and fills in the
+// requesting users' usernames with a single query.
+func (api *API) convertGroupJoinRequests(r *http.Request, joinRequests []database.GroupJoinRequest) ([]codersdk.GroupJoinRequest, error) {
+	resp := make([]codersdk.GroupJoinRequest, 0, len(joinRequests))
+	if len(joinRequests) == 0 {
+		return resp, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(joinRequests))
+	for _, joinRequest := range joinRequests {
+		userIDs = append(userIDs, joinRequest.UserID)
+	}
+	users, err := api.Database.GetUsersByIDs(r.Context(), userIDs)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get users: %w", err)
+	}
+	usernames := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	for _, joinRequest := range joinRequests {
+		resp = append(resp, codersdk.GroupJoinRequest{
+			ID:        joinRequest.ID,
+			GroupID:   joinRequest.GroupID,
+			UserID:    joinRequest.UserID,
+			Username:  usernames[joinRequest.UserID],
+			CreatedAt: joinRequest.CreatedAt,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,159 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGroupJoinRequests(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.Group, *codersdk.Client, codersdk.User) {
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		return client, group, client1, user1
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, client1, user1 := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		joinRequest, err := client1.CreateGroupJoinRequest(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, user1.ID, joinRequest.UserID)
+		require.Equal(t, user1.Username, joinRequest.Username)
+
+		joinRequests, err := client.GroupJoinRequests(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.GroupJoinRequest{joinRequest}, joinRequests)
+
+		group, err = client.ApproveGroupJoinRequest(ctx, group.ID, joinRequest.ID)
+		require.NoError(t, err)
+		require.Len(t, group.Members, 1)
+		require.Equal(t, user1.ID, group.Members[0].ID)
+
+		joinRequests, err = client.GroupJoinRequests(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, joinRequests, 0)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, client1, _ := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		joinRequest, err := client1.CreateGroupJoinRequest(ctx, group.ID)
+		require.NoError(t, err)
+
+		err = client.DenyGroupJoinRequest(ctx, group.ID, joinRequest.ID)
+		require.NoError(t, err)
+
+		joinRequests, err := client.GroupJoinRequests(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, joinRequests, 0)
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, group.Members, 0)
+	})
+
+	t.Run("GroupAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, client1, _ := setup(t)
+		adminClient, admin := coderdtest.CreateAnotherUserWithUser(t, client, group.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{admin.ID.String()},
+		})
+		require.NoError(t, err)
+		_, err = client.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"group-admin"},
+		})
+		require.NoError(t, err)
+
+		joinRequest, err := client1.CreateGroupJoinRequest(ctx, group.ID)
+		require.NoError(t, err)
+
+		// The requester can't approve their own request.
+		_, err = client1.GroupJoinRequests(ctx, group.ID)
+		require.Error(t, err)
+		_, err = client1.ApproveGroupJoinRequest(ctx, group.ID, joinRequest.ID)
+		require.Error(t, err)
+
+		joinRequests, err := adminClient.GroupJoinRequests(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, joinRequests, 1)
+
+		group, err = adminClient.ApproveGroupJoinRequest(ctx, group.ID, joinRequest.ID)
+		require.NoError(t, err)
+		require.Len(t, group.Members, 2)
+	})
+
+	t.Run("AlreadyRequested", func(t *testing.T) {
+		t.Parallel()
+
+		_, group, client1, _ := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client1.CreateGroupJoinRequest(ctx, group.ID)
+		require.NoError(t, err)
+
+		_, err = client1.CreateGroupJoinRequest(ctx, group.ID)
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, cerr.StatusCode())
+	})
+
+	t.Run("AlreadyMember", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, client1, user1 := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+
+		_, err = client1.CreateGroupJoinRequest(ctx, group.ID)
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, cerr.StatusCode())
+	})
+
+	t.Run("Everyone", func(t *testing.T) {
+		t.Parallel()
+
+		_, group, client1, _ := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client1.CreateGroupJoinRequest(ctx, group.OrganizationID)
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+}
@@ -0,0 +1,231 @@
+package coderd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+func (api *API) importGroupMembers(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, membersObj) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	if group.Name == database.AllUsersGroup {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Members of the %q group cannot be changed!", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
+		})
+		return
+	}
+	if !writeGroupMembersAllowed(ctx, rw, group) {
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != "text/csv" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Unsupported content type header %q.", r.Header.Get("Content-Type")),
+			Code:    codersdk.ErrorCodeInvalidRequestBody,
+		})
+		return
+	}
+
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	type row struct {
+		line  int
+		value string
+	}
+	var (
+		rows        []row
+		identifiers []string
+		header      = true
+	)
+	for {
+		record, err := reader.Read()
+		if xerrors.Is(err, io.EOF) {
+			break
+		}
+		if httpapi.IsRequestTooLarge(rw, err) {
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Failed to read CSV from request.",
+				Detail:  err.Error(),
+				Code:    codersdk.ErrorCodeInvalidRequestBody,
+			})
+			return
+		}
+		value := strings.TrimSpace(record[0])
+		if value == "" {
+			continue
+		}
+		// Line numbers are reported rather than record indexes, since the
+		// reader skips blank lines.
+		line, _ := reader.FieldPos(0)
+		// Exports and most spreadsheets start with a header row.
+		if header {
+			header = false
+			switch strings.ToLower(value) {
+			case "id", "username", "email":
+				continue
+			}
+		}
+		if len(rows) == codersdk.MaxGroupMembersPerRequest {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("A maximum of %d users can be imported at once.", codersdk.MaxGroupMembersPerRequest),
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
+			return
+		}
+		rows = append(rows, row{line: line, value: value})
+		identifiers = append(identifiers, value)
+	}
+
+	userIDs, _, err := api.resolveUserIdentifiers(ctx, identifiers)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	resolved := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		resolved = append(resolved, id)
+	}
+
+	orgMembers, err := api.allowedGroupMembers(ctx, group, resolved)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	importErrors := make([]codersdk.GroupMemberImportError, 0)
+	members := make([]uuid.UUID, 0, len(rows))
+	seen := make(map[uuid.UUID]struct{}, len(rows))
+	for _, row := range rows {
+		var reason codersdk.GroupMemberSkipReason
+		id, ok := userIDs[row.value]
+		_, duplicate := seen[id]
+		_, orgMember := orgMembers[id]
+		switch {
+		case !ok:
+			reason = codersdk.GroupMemberSkipReasonUnknownUser
+		case duplicate:
+			reason = codersdk.GroupMemberSkipReasonDuplicate
+		case !orgMember:
+			reason = codersdk.GroupMemberSkipReasonNotOrgMember
+		}
+		if reason != "" {
+			importErrors = append(importErrors, codersdk.GroupMemberImportError{
+				Row:    row.line,
+				Value:  row.value,
+				Reason: reason,
+			})
+			continue
+		}
+		seen[id] = struct{}{}
+		members = append(members, id)
+	}
+	if !api.authorizeGroupMembersAdded(rw, r, group, members) {
+		return
+	}
+
+	added, err := api.Database.InsertGroupMembers(ctx, database.InsertGroupMembersParams{
+		GroupID: group.ID,
+		UserIds: members,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	addedMembers := make([]database.GroupMember, 0, len(added))
+	for _, id := range added {
+		addedMembers = append(addedMembers, database.GroupMember{
+			GroupID: group.ID,
+			UserID:  id,
+		})
+	}
+	commitAudit := api.auditGroupMembers(rw, r, group, addedMembers, nil)
+	defer commitAudit()
+
+	users, err := api.groupMembers(ctx, group.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if added == nil {
+		added = []uuid.UUID{}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.ImportGroupMembersResponse{
+		Group:  convertGroup(group, users),
+		Added:  added,
+		Errors: importErrors,
+	})
+}
+
+func (api *API) exportGroupMembers(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	members, err := api.groupMembers(ctx, group.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/csv")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", group.Name+"-members.csv"))
+	rw.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(rw)
+	_ = writer.Write([]string{"id", "username", "email", "expires_at"})
+	for _, member := range members {
+		var expiresAt string
+		if member.ExpiresAt.Valid {
+			expiresAt = member.ExpiresAt.Time.UTC().Format(time.RFC3339)
+		}
+		err = writer.Write([]string{member.ID.String(), member.Username, member.Email, expiresAt})
+		if err != nil {
+			// The client has likely gone away, and the status has
+			// already been written.
+			return
+		}
+	}
+	writer.Flush()
+}
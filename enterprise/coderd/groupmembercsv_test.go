@@ -0,0 +1,157 @@
+package coderd_test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGroupMembersCSV(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Import", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		data := strings.Join([]string{
+			"email",
+			user1.Email,
+			"",
+			strings.ToUpper(user2.Username),
+			user1.Username,
+			"nobody@coder.com",
+		}, "\n")
+		resp, err := client.ImportGroupMembers(ctx, group.ID, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, resp.Added, 2)
+		require.Contains(t, resp.Group.Members, codersdk.GroupMember{User: user1})
+		require.Contains(t, resp.Group.Members, codersdk.GroupMember{User: user2})
+		require.Equal(t, []codersdk.GroupMemberImportError{{
+			Row:    5,
+			Value:  user1.Username,
+			Reason: codersdk.GroupMemberSkipReasonDuplicate,
+		}, {
+			Row:    6,
+			Value:  "nobody@coder.com",
+			Reason: codersdk.GroupMemberSkipReasonUnknownUser,
+		}}, resp.Errors)
+
+		// Importing existing members is a no-op.
+		resp, err = client.ImportGroupMembers(ctx, group.ID, []byte(user1.Email))
+		require.NoError(t, err)
+		require.Len(t, resp.Added, 0)
+		require.Len(t, resp.Errors, 0)
+		require.Len(t, resp.Group.Members, 2)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+
+		data, err := client.ExportGroupMembers(ctx, group.ID)
+		require.NoError(t, err)
+		records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"id", "username", "email", "expires_at"},
+			{user1.ID.String(), user1.Username, user1.Email, ""},
+		}, records)
+
+		// An export can be imported into another group.
+		other, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "other",
+		})
+		require.NoError(t, err)
+		resp, err := client.ImportGroupMembers(ctx, other.ID, data)
+		require.NoError(t, err)
+		require.Len(t, resp.Errors, 0)
+		require.Equal(t, []codersdk.GroupMember{{User: user1}}, resp.Group.Members)
+	})
+
+	t.Run("NotOrgMember", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "other",
+		})
+		require.NoError(t, err)
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, org.ID)
+
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		resp, err := client.ImportGroupMembers(ctx, group.ID, []byte(fmt.Sprintf("username\n%s\n", user1.Username)))
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.GroupMemberImportError{{
+			Row:    2,
+			Value:  user1.Username,
+			Reason: codersdk.GroupMemberSkipReasonNotOrgMember,
+		}}, resp.Errors)
+		require.Len(t, resp.Group.Members, 0)
+	})
+
+	t.Run("InvalidCSV", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		_, err = client.ImportGroupMembers(ctx, group.ID, []byte(`"unterminated`))
+		require.Error(t, err)
+	})
+}
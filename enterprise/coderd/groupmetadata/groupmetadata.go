@@ -0,0 +1,229 @@
+// Package groupmetadata fetches the display name and picture of groups from
+// identity provider directory APIs.
+package groupmetadata
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// maxAvatarSize is the largest group picture that's inlined as a data URL.
+const maxAvatarSize = 64 << 10
+
+// Metadata is how the identity provider presents a group.
+type Metadata struct {
+	DisplayName string
+	// AvatarURL is empty if the group doesn't have a picture.
+	AvatarURL string
+}
+
+// Fetcher looks up groups in an identity provider's directory.
+type Fetcher interface {
+	// Fetch returns the metadata of the named groups. Groups the identity
+	// provider doesn't know about are omitted.
+	Fetch(ctx context.Context, names []string) (map[string]Metadata, error)
+}
+
+// NewAzureAD returns a fetcher that reads groups from the Microsoft Graph API
+// at baseURL, e.g. https://graph.microsoft.com. client must authenticate
+// requests, typically with the client credentials flow. Groups are matched
+// by object ID, which is what Azure AD reports in the groups claim, or by
+// display name otherwise.
+func NewAzureAD(client *http.Client, baseURL *url.URL) Fetcher {
+	return &azureAD{
+		client:  client,
+		baseURL: baseURL,
+	}
+}
+
+type azureAD struct {
+	client  *http.Client
+	baseURL *url.URL
+}
+
+type azureADGroup struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+func (a *azureAD) Fetch(ctx context.Context, names []string) (map[string]Metadata, error) {
+	metadata := make(map[string]Metadata, len(names))
+	for _, name := range names {
+		group, ok, err := a.group(ctx, name)
+		if err != nil {
+			return nil, xerrors.Errorf("get group %q: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+		avatarURL, err := a.photo(ctx, group.ID)
+		if err != nil {
+			return nil, xerrors.Errorf("get photo of group %q: %w", name, err)
+		}
+		metadata[name] = Metadata{
+			DisplayName: group.DisplayName,
+			AvatarURL:   avatarURL,
+		}
+	}
+	return metadata, nil
+}
+
+func (a *azureAD) group(ctx context.Context, name string) (azureADGroup, bool, error) {
+	if _, err := uuid.Parse(name); err == nil {
+		var group azureADGroup
+		ok, err := getJSON(ctx, a.client, a.url("/v1.0/groups/"+url.PathEscape(name), url.Values{
+			"$select": {"id,displayName"},
+		}), nil, &group)
+		return group, ok, err
+	}
+
+	var resp struct {
+		Value []azureADGroup `json:"value"`
+	}
+	_, err := getJSON(ctx, a.client, a.url("/v1.0/groups", url.Values{
+		"$select": {"id,displayName"},
+		"$filter": {fmt.Sprintf("displayName eq '%s'", strings.ReplaceAll(name, "'", "''"))},
+	}), nil, &resp)
+	if err != nil || len(resp.Value) == 0 {
+		return azureADGroup{}, false, err
+	}
+	return resp.Value[0], true, nil
+}
+
+// photo returns the group's picture as a data URL, since Graph only serves
+// it to authenticated clients.
+func (a *azureAD) photo(ctx context.Context, id string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url("/v1.0/groups/"+url.PathEscape(id)+"/photos/48x48/$value", nil), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxAvatarSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxAvatarSize {
+		return "", nil
+	}
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func (a *azureAD) url(path string, query url.Values) string {
+	u := *a.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// NewOkta returns a fetcher that reads groups from the Okta API of the
+// organization at baseURL, e.g. https://example.okta.com, authenticating
+// with an API token. Groups are matched by name.
+func NewOkta(client *http.Client, baseURL *url.URL, token string) Fetcher {
+	return &okta{
+		client:  client,
+		baseURL: baseURL,
+		token:   token,
+	}
+}
+
+type okta struct {
+	client  *http.Client
+	baseURL *url.URL
+	token   string
+}
+
+type oktaGroup struct {
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	Links struct {
+		Logo []struct {
+			Name string `json:"name"`
+			Href string `json:"href"`
+		} `json:"logo"`
+	} `json:"_links"`
+}
+
+func (o *okta) Fetch(ctx context.Context, names []string) (map[string]Metadata, error) {
+	metadata := make(map[string]Metadata, len(names))
+	for _, name := range names {
+		u := *o.baseURL
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v1/groups"
+		u.RawQuery = url.Values{"q": {name}}.Encode()
+
+		var groups []oktaGroup
+		_, err := getJSON(ctx, o.client, u.String(), http.Header{
+			"Authorization": {"SSWS " + o.token},
+		}, &groups)
+		if err != nil {
+			return nil, xerrors.Errorf("search group %q: %w", name, err)
+		}
+		// The search matches name prefixes, so only exact matches count.
+		for _, group := range groups {
+			if group.Profile.Name != name {
+				continue
+			}
+			var avatarURL string
+			for _, logo := range group.Links.Logo {
+				avatarURL = logo.Href
+				if logo.Name == "medium" {
+					break
+				}
+			}
+			metadata[name] = Metadata{
+				DisplayName: group.Profile.Name,
+				AvatarURL:   avatarURL,
+			}
+			break
+		}
+	}
+	return metadata, nil
+}
+
+// getJSON decodes the response to a GET request into v. It returns false if
+// the resource doesn't exist.
+func getJSON(ctx context.Context, client *http.Client, rawURL string, header http.Header, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return false, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return true, json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,84 @@
+package groupmetadata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/enterprise/coderd/groupmetadata"
+)
+
+func TestAzureAD(t *testing.T) {
+	t.Parallel()
+
+	withPhoto := uuid.NewString()
+	withoutPhoto := uuid.NewString()
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/groups/" + withPhoto:
+			_ = json.NewEncoder(rw).Encode(map[string]string{"id": withPhoto, "displayName": "Platform Team"})
+		case "/v1.0/groups/" + withPhoto + "/photos/48x48/$value":
+			rw.Header().Set("Content-Type", "image/png")
+			_, _ = rw.Write([]byte("png"))
+		case "/v1.0/groups":
+			assert.Equal(t, "displayName eq 'O''Brien'", r.URL.Query().Get("$filter"))
+			_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+				"value": []map[string]string{{"id": withoutPhoto, "displayName": "O'Brien"}},
+			})
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	baseURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	fetcher := groupmetadata.NewAzureAD(srv.Client(), baseURL)
+	metadata, err := fetcher.Fetch(context.Background(), []string{withPhoto, "O'Brien", uuid.NewString()})
+	require.NoError(t, err)
+	require.Equal(t, map[string]groupmetadata.Metadata{
+		withPhoto: {DisplayName: "Platform Team", AvatarURL: "data:image/png;base64,cG5n"},
+		"O'Brien": {DisplayName: "O'Brien"},
+	}, metadata)
+}
+
+func TestOkta(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "SSWS token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v1/groups", r.URL.Path)
+		groups := []map[string]interface{}{}
+		if r.URL.Query().Get("q") == "eng" {
+			groups = append(groups, map[string]interface{}{
+				"profile": map[string]string{"name": "engineering"},
+			}, map[string]interface{}{
+				"profile": map[string]string{"name": "eng"},
+				"_links": map[string]interface{}{
+					"logo": []map[string]string{
+						{"name": "medium", "href": "https://example.com/medium.png"},
+						{"name": "large", "href": "https://example.com/large.png"},
+					},
+				},
+			})
+		}
+		_ = json.NewEncoder(rw).Encode(groups)
+	}))
+	t.Cleanup(srv.Close)
+	baseURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	fetcher := groupmetadata.NewOkta(srv.Client(), baseURL, "token")
+	metadata, err := fetcher.Fetch(context.Background(), []string{"eng", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]groupmetadata.Metadata{
+		"eng": {DisplayName: "eng", AvatarURL: "https://example.com/medium.png"},
+	}, metadata)
+}
@@ -1,14 +1,23 @@
 package coderd
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
@@ -17,10 +26,17 @@ import (
 )
 
 func (api *API) postGroupByOrganization(rw http.ResponseWriter, r *http.Request) {
-	var (
-		ctx = r.Context()
-		org = httpmw.OrganizationParam(r)
-	)
+	org := httpmw.OrganizationParam(r)
+	api.postGroup(rw, r, uuid.NullUUID{UUID: org.ID, Valid: true})
+}
+
+// postDeploymentGroup creates a group that spans every organization.
+func (api *API) postDeploymentGroup(rw http.ResponseWriter, r *http.Request) {
+	api.postGroup(rw, r, uuid.NullUUID{})
+}
+
+func (api *API) postGroup(rw http.ResponseWriter, r *http.Request, organizationID uuid.NullUUID) {
+	ctx := r.Context()
 
 	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceGroup) {
 		http.NotFound(rw, r)
@@ -35,18 +51,30 @@ func (api *API) postGroupByOrganization(rw http.ResponseWriter, r *http.Request)
 	if req.Name == database.AllUsersGroup {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: fmt.Sprintf("%q is a reserved keyword and cannot be used for a group name.", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
 		})
 		return
 	}
 
+	parentID, ok := api.parseGroupParent(ctx, rw, organizationID, uuid.Nil, req.ParentID)
+	if !ok {
+		return
+	}
+
 	group, err := api.Database.InsertGroup(ctx, database.InsertGroupParams{
 		ID:             uuid.New(),
 		Name:           req.Name,
-		OrganizationID: org.ID,
+		OrganizationID: organizationID,
+		ParentID:       parentID,
+		DisplayName:    req.DisplayName,
+		AvatarURL:      req.AvatarURL,
+		Description:    req.Description,
+		Source:         database.GroupSourceUser,
 	})
 	if database.IsUniqueViolation(err) {
 		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
 			Message: fmt.Sprintf("Group with name %q already exists.", req.Name),
+			Code:    codersdk.ErrorCodeGroupNameTaken,
 		})
 		return
 	}
@@ -55,6 +83,11 @@ func (api *API) postGroupByOrganization(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+		Type: codersdk.GroupWebhookEventGroupCreated,
+	})
+	api.publishGroupResourceEvent(ctx, codersdk.ResourceEventActionCreated, group)
+
 	httpapi.Write(ctx, rw, http.StatusCreated, convertGroup(group, nil))
 }
 
@@ -64,7 +97,12 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		group = httpmw.GroupParam(r)
 	)
 
-	if !api.Authorize(r, rbac.ActionUpdate, group) {
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionRead, group) && !api.Authorize(r, rbac.ActionRead, membersObj) {
 		http.NotFound(rw, r)
 		return
 	}
@@ -73,41 +111,229 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
+	previousName := group.Name
+
+	// Changing the group and changing its members are authorized separately,
+	// so a role (or a group admin) can be allowed to add and remove members
+	// without being allowed to rename or reconfigure the group.
+	updateGroup := req.Name != "" || req.ParentID != nil || req.DisplayName != nil || req.AvatarURL != nil || req.Description != nil || req.Metadata != nil ||
+		req.AutostopSchedule != nil || req.MaxTTLMillis != nil || req.QuotaAllowance != nil || req.Roles != nil
+	if updateGroup && !api.Authorize(r, rbac.ActionUpdate, group) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if len(req.AddUsers) > 0 && !api.Authorize(r, rbac.ActionCreate, membersObj) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if len(req.RemoveUsers) > 0 && !api.Authorize(r, rbac.ActionDelete, membersObj) {
+		httpapi.Forbidden(rw)
+		return
+	}
 
 	if req.Name != "" && req.Name == database.AllUsersGroup {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: fmt.Sprintf("%q is a reserved group name!", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
 		})
 		return
 	}
 
-	users := make([]string, 0, len(req.AddUsers)+len(req.RemoveUsers))
-	users = append(users, req.AddUsers...)
-	users = append(users, req.RemoveUsers...)
+	if r.Header.Get("If-Match") != "" {
+		// The group can have been read with or without its members.
+		withMembers, err := api.convertGroups(ctx, []database.Group{group}, true)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		withoutMembers, err := api.convertGroups(ctx, []database.Group{group}, false)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		if !httpapi.CheckIfMatch(ctx, rw, r, withMembers[0], withoutMembers[0]) {
+			return
+		}
+	}
+
+	parentID := group.ParentID
+	if req.ParentID != nil {
+		if group.Name == database.AllUsersGroup {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("%q is a reserved group and cannot be nested!", database.AllUsersGroup),
+				Code:    codersdk.ErrorCodeGroupNameReserved,
+			})
+			return
+		}
+		var ok bool
+		parentID, ok = api.parseGroupParent(ctx, rw, group.OrganizationID, group.ID, req.ParentID)
+		if !ok {
+			return
+		}
+	}
+
+	if (len(req.AddUsers) > 0 || len(req.RemoveUsers) > 0) && !writeGroupMembersAllowed(ctx, rw, group) {
+		return
+	}
+
+	metadata := group.Metadata
+	if req.Metadata != nil {
+		if len(*req.Metadata) > codersdk.MaxGroupMetadataEntries {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("A group can have at most %d metadata entries.", codersdk.MaxGroupMetadataEntries),
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
+			return
+		}
+		if _, ok := (*req.Metadata)[""]; ok {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Metadata keys cannot be empty.",
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
+			return
+		}
+		metadata, err = json.Marshal(*req.Metadata)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
 
-	for _, id := range users {
-		if _, err := uuid.Parse(id); err != nil {
+	if req.AutostopSchedule != nil && *req.AutostopSchedule != "" {
+		_, err := schedule.Weekly(*req.AutostopSchedule)
+		if err != nil {
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-				Message: fmt.Sprintf("ID %q must be a valid user UUID.", id),
+				Message: "Invalid autostop schedule.",
+				Validations: []codersdk.ValidationError{
+					{Field: "autostop_schedule", Detail: err.Error()},
+				},
+				Code: codersdk.ErrorCodeValidationFailed,
 			})
 			return
 		}
-		// TODO: It would be nice to enforce this at the schema level
-		// but unfortunately our org_members table does not have an ID.
-		_, err := api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
-			OrganizationID: group.OrganizationID,
-			UserID:         uuid.MustParse(id),
+	}
+	if req.MaxTTLMillis != nil && *req.MaxTTLMillis < 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid max TTL.",
+			Validations: []codersdk.ValidationError{
+				{Field: "max_ttl_ms", Detail: "Must not be negative."},
+			},
+			Code: codersdk.ErrorCodeValidationFailed,
+		})
+		return
+	}
+	if req.QuotaAllowance != nil && *req.QuotaAllowance < 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid quota allowance.",
+			Validations: []codersdk.ValidationError{
+				{Field: "quota_allowance", Detail: "Must not be negative."},
+			},
+			Code: codersdk.ErrorCodeValidationFailed,
 		})
-		if xerrors.Is(err, sql.ErrNoRows) {
-			httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
-				Message: fmt.Sprintf("User %q must be a member of organization %q", id, group.ID),
+		return
+	}
+
+	roles := group.Roles
+	if req.Roles != nil {
+		if group.Name == database.AllUsersGroup {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("%q is a reserved group and cannot be granted roles! Assign roles to organization members instead.", database.AllUsersGroup),
+				Code:    codersdk.ErrorCodeGroupNameReserved,
+			})
+			return
+		}
+		err := validateGroupRoles(group, *req.Roles)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid group roles.",
+				Validations: []codersdk.ValidationError{
+					{Field: "roles", Detail: err.Error()},
+				},
+				Code: codersdk.ErrorCodeValidationFailed,
 			})
 			return
 		}
+		roles = *req.Roles
+	}
+
+	// Members of the group, and of the groups beneath it, are granted the
+	// roles of every group above it too, so moving the group is a role
+	// change as well.
+	if req.Roles != nil || req.ParentID != nil || len(req.AddUsers) > 0 {
+		from, err := api.groupEffectiveRoles(ctx, group.ParentID, group.Roles)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		to, err := api.groupEffectiveRoles(ctx, parentID, roles)
 		if err != nil {
 			httpapi.InternalServerError(rw, err)
 			return
 		}
+		if !api.authorizeGroupRoleChange(r, from, to) {
+			httpapi.Forbidden(rw)
+			return
+		}
+		// Added members are granted every role of the group.
+		if len(req.AddUsers) > 0 && !api.authorizeGroupRoleChange(r, nil, to) {
+			httpapi.Forbidden(rw)
+			return
+		}
+	}
+
+	var expiresAt sql.NullTime
+	if req.AddUsersExpireAt != nil {
+		if !req.AddUsersExpireAt.After(database.Now()) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Membership expiry must be in the future.",
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
+			return
+		}
+		expiresAt = sql.NullTime{Time: *req.AddUsersExpireAt, Valid: true}
+	}
+
+	identifiers := make([]string, 0, len(req.AddUsers)+len(req.RemoveUsers))
+	identifiers = append(identifiers, req.AddUsers...)
+	identifiers = append(identifiers, req.RemoveUsers...)
+	userIDs, unresolved, err := api.resolveUserIdentifiers(ctx, identifiers)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if len(unresolved) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("%q must be a valid user ID, username, or email.", unresolved[0]),
+			Code:    codersdk.ErrorCodeValidationFailed,
+		})
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		ids = append(ids, id)
+	}
+	allowed, err := api.allowedGroupMembers(ctx, group, ids)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	for _, identifier := range identifiers {
+		if _, ok := allowed[userIDs[identifier]]; ok {
+			continue
+		}
+		if !group.OrganizationID.Valid {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("%q must be a valid user ID, username, or email.", identifier),
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
+			Message: fmt.Sprintf("User %q must be a member of organization %q", identifier, group.OrganizationID.UUID),
+			Code:    codersdk.ErrorCodeOrgMemberRequired,
+		})
+		return
 	}
 	if req.Name != "" {
 		_, err := api.Database.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
@@ -117,35 +343,86 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		if err == nil {
 			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
 				Message: fmt.Sprintf("A group with name %q already exists.", req.Name),
+				Code:    codersdk.ErrorCodeGroupNameTaken,
 			})
 			return
 		}
 	}
 
-	err := api.Database.InTx(func(tx database.Store) error {
-		if req.Name != "" {
+	var (
+		added   []database.GroupMember
+		removed []uuid.UUID
+	)
+	err = api.Database.InTx(func(tx database.Store) error {
+		if updateGroup {
+			params := database.UpdateGroupByIDParams{
+				ID:               group.ID,
+				Name:             group.Name,
+				ParentID:         parentID,
+				DisplayName:      group.DisplayName,
+				AvatarURL:        group.AvatarURL,
+				Description:      group.Description,
+				Metadata:         metadata,
+				AutostopSchedule: group.AutostopSchedule,
+				MaxTtl:           group.MaxTtl,
+				QuotaAllowance:   group.QuotaAllowance,
+				Roles:            roles,
+			}
+			if req.Name != "" {
+				params.Name = req.Name
+			}
+			if req.DisplayName != nil {
+				params.DisplayName = *req.DisplayName
+			}
+			if req.AvatarURL != nil {
+				params.AvatarURL = *req.AvatarURL
+			}
+			if req.Description != nil {
+				params.Description = *req.Description
+			}
+			if req.AutostopSchedule != nil {
+				params.AutostopSchedule = *req.AutostopSchedule
+			}
+			if req.MaxTTLMillis != nil {
+				params.MaxTtl = int64(time.Duration(*req.MaxTTLMillis) * time.Millisecond)
+			}
+			if req.QuotaAllowance != nil {
+				params.QuotaAllowance = *req.QuotaAllowance
+			}
 			var err error
-			group, err = tx.UpdateGroupByID(ctx, database.UpdateGroupByIDParams{
-				ID:   group.ID,
-				Name: req.Name,
-			})
+			group, err = tx.UpdateGroupByID(ctx, params)
 			if err != nil {
 				return xerrors.Errorf("update group by ID: %w", err)
 			}
 		}
 		for _, id := range req.AddUsers {
+			member := database.GroupMember{
+				GroupID:   group.ID,
+				UserID:    userIDs[id],
+				ExpiresAt: expiresAt,
+			}
 			err := tx.InsertGroupMember(ctx, database.InsertGroupMemberParams{
-				GroupID: group.ID,
-				UserID:  uuid.MustParse(id),
+				GroupID:   member.GroupID,
+				UserID:    member.UserID,
+				ExpiresAt: member.ExpiresAt,
 			})
 			if err != nil {
 				return xerrors.Errorf("insert group member %q: %w", id, err)
 			}
+			added = append(added, member)
 		}
 		for _, id := range req.RemoveUsers {
-			err := tx.DeleteGroupMember(ctx, uuid.MustParse(id))
+			groupIDs, err := tx.DeleteUserFromGroups(ctx, database.DeleteUserFromGroupsParams{
+				UserID:   userIDs[id],
+				GroupIds: []uuid.UUID{group.ID},
+			})
 			if err != nil {
-				return xerrors.Errorf("insert group member %q: %w", id, err)
+				return xerrors.Errorf("delete group member %q: %w", id, err)
+			}
+			// Removing users that aren't members changes nothing, so
+			// they aren't audited.
+			if len(groupIDs) > 0 {
+				removed = append(removed, userIDs[id])
 			}
 		}
 		return nil
@@ -154,6 +431,7 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
 			Message: "Cannot add the same user to a group twice!",
 			Detail:  err.Error(),
+			Code:    codersdk.ErrorCodeGroupMemberExists,
 		})
 		return
 	}
@@ -161,6 +439,7 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		httpapi.Write(ctx, rw, http.StatusPreconditionFailed, codersdk.Response{
 			Message: "Failed to add or remove non-existent group member",
 			Detail:  err.Error(),
+			Code:    codersdk.ErrorCodeGroupMemberNotFound,
 		})
 		return
 	}
@@ -169,145 +448,1298 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	members, err := api.Database.GetGroupMembers(ctx, group.ID)
+	if group.Name != previousName {
+		api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+			Type:              codersdk.GroupWebhookEventGroupRenamed,
+			PreviousGroupName: previousName,
+		})
+	}
+	api.publishGroupResourceEvent(ctx, codersdk.ResourceEventActionUpdated, group)
+
+	commitAudit := api.auditGroupMembers(rw, r, group, added, removed)
+	defer commitAudit()
+
+	members, err := api.groupMembers(ctx, group.ID)
 	if err != nil {
 		httpapi.InternalServerError(rw, err)
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, convertGroup(group, members))
+	httpapi.WriteWithETag(ctx, rw, r, convertGroup(group, members))
 }
 
-func (api *API) deleteGroup(rw http.ResponseWriter, r *http.Request) {
+func (api *API) putGroupMembers(rw http.ResponseWriter, r *http.Request) {
 	var (
 		ctx   = r.Context()
 		group = httpmw.GroupParam(r)
 	)
 
-	if !api.Authorize(r, rbac.ActionDelete, group) {
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionRead, group) && !api.Authorize(r, rbac.ActionRead, membersObj) {
 		httpapi.ResourceNotFound(rw)
 		return
 	}
 
 	if group.Name == database.AllUsersGroup {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message: fmt.Sprintf("%q is a reserved group and cannot be deleted!", database.AllUsersGroup),
+			Message: fmt.Sprintf("Members of the %q group cannot be changed!", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
+		})
+		return
+	}
+	if !writeGroupMembersAllowed(ctx, rw, group) {
+		return
+	}
+
+	var req codersdk.PutGroupMembersRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if len(req.UserIDs) > codersdk.MaxGroupMembersPerRequest {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("A maximum of %d users can be set at once.", codersdk.MaxGroupMembersPerRequest),
+			Code:    codersdk.ErrorCodeValidationFailed,
 		})
 		return
 	}
 
-	err := api.Database.DeleteGroupByID(ctx, group.ID)
+	skipped := make([]codersdk.SkippedGroupMember, 0)
+	userIDs := make([]uuid.UUID, 0, len(req.UserIDs))
+	seen := make(map[uuid.UUID]struct{}, len(req.UserIDs))
+	for _, raw := range req.UserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			skipped = append(skipped, codersdk.SkippedGroupMember{
+				UserID: raw,
+				Reason: codersdk.GroupMemberSkipReasonInvalidID,
+			})
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			skipped = append(skipped, codersdk.SkippedGroupMember{
+				UserID: raw,
+				Reason: codersdk.GroupMemberSkipReasonDuplicate,
+			})
+			continue
+		}
+		seen[id] = struct{}{}
+		userIDs = append(userIDs, id)
+	}
+
+	orgMembers, err := api.allowedGroupMembers(ctx, group, userIDs)
 	if err != nil {
 		httpapi.InternalServerError(rw, err)
 		return
 	}
+	members := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := orgMembers[id]; !ok {
+			skipped = append(skipped, codersdk.SkippedGroupMember{
+				UserID: id.String(),
+				Reason: codersdk.GroupMemberSkipReasonNotOrgMember,
+			})
+			continue
+		}
+		members = append(members, id)
+	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
-		Message: "Successfully deleted group!",
-	})
-}
-
-func (api *API) group(rw http.ResponseWriter, r *http.Request) {
-	var (
-		ctx   = r.Context()
-		group = httpmw.GroupParam(r)
-	)
+	// Like patching the group, adding members and removing them are
+	// authorized separately.
+	existing, err := api.groupMembers(ctx, group.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	existingIDs := make(map[uuid.UUID]struct{}, len(existing))
+	for _, member := range existing {
+		existingIDs[member.ID] = struct{}{}
+	}
+	adds, removes := false, false
+	for _, id := range members {
+		if _, ok := existingIDs[id]; !ok {
+			adds = true
+		}
+		delete(existingIDs, id)
+	}
+	removes = len(existingIDs) > 0
+	if adds && !api.Authorize(r, rbac.ActionCreate, membersObj) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if removes && !api.Authorize(r, rbac.ActionDelete, membersObj) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if !api.authorizeGroupMembersAdded(rw, r, group, members) {
+		return
+	}
 
-	if !api.Authorize(r, rbac.ActionRead, group) {
-		httpapi.ResourceNotFound(rw)
+	var added, removed []uuid.UUID
+	err = api.Database.InTx(func(tx database.Store) error {
+		var err error
+		removed, err = tx.DeleteGroupMembersExceptUserIDs(ctx, database.DeleteGroupMembersExceptUserIDsParams{
+			GroupID: group.ID,
+			UserIds: members,
+		})
+		if err != nil {
+			return xerrors.Errorf("delete group members: %w", err)
+		}
+		added, err = tx.InsertGroupMembers(ctx, database.InsertGroupMembersParams{
+			GroupID: group.ID,
+			UserIds: members,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert group members: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
 		return
 	}
 
-	users, err := api.Database.GetGroupMembers(ctx, group.ID)
+	addedMembers := make([]database.GroupMember, 0, len(added))
+	for _, id := range added {
+		addedMembers = append(addedMembers, database.GroupMember{
+			GroupID: group.ID,
+			UserID:  id,
+		})
+	}
+	commitAudit := api.auditGroupMembers(rw, r, group, addedMembers, removed)
+	defer commitAudit()
+	api.publishGroupResourceEvent(ctx, codersdk.ResourceEventActionUpdated, group)
+
+	users, err := api.groupMembers(ctx, group.ID)
 	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 		httpapi.InternalServerError(rw, err)
 		return
 	}
+	if added == nil {
+		added = []uuid.UUID{}
+	}
+	if removed == nil {
+		removed = []uuid.UUID{}
+	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, convertGroup(group, users))
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.PutGroupMembersResponse{
+		Group:   convertGroup(group, users),
+		Added:   added,
+		Removed: removed,
+		Skipped: skipped,
+	})
 }
 
-func (api *API) groups(rw http.ResponseWriter, r *http.Request) {
+func (api *API) putGroupMemberRoles(rw http.ResponseWriter, r *http.Request) {
 	var (
-		ctx = r.Context()
-		org = httpmw.OrganizationParam(r)
+		ctx     = r.Context()
+		group   = httpmw.GroupParam(r)
+		user    = httpmw.UserParam(r)
+		auditor = *api.AGPL.Auditor.Load()
 	)
 
-	groups, err := api.Database.GetGroupsByOrganizationID(ctx, org.ID)
-	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
-		httpapi.InternalServerError(rw, err)
+	// Group roles grant access to the group's members, so only those that
+	// can update the group itself may assign them.
+	if !api.Authorize(r, rbac.ActionUpdate, group) {
+		httpapi.ResourceNotFound(rw)
 		return
 	}
 
-	// Filter groups based on rbac permissions
-	groups, err = coderd.AuthorizeFilter(api.AGPL.HTTPAuth, r, rbac.ActionRead, groups)
-	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Internal error fetching groups.",
-			Detail:  err.Error(),
-		})
+	var req codersdk.UpdateRoles
+	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
-
-	resp := make([]codersdk.Group, 0, len(groups))
-	for _, group := range groups {
-		members, err := api.Database.GetGroupMembers(ctx, group.ID)
-		if err != nil {
-			httpapi.InternalServerError(rw, err)
+	for _, role := range req.Roles {
+		if !slices.Contains(rbac.GroupRoles(), role) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Role %q cannot be assigned to group members.", role),
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
 			return
 		}
-
-		resp = append(resp, convertGroup(group, members))
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, resp)
-}
-
-func convertGroup(g database.Group, users []database.User) codersdk.Group {
-	// It's ridiculous to query all the orgs of a user here
-	// especially since as of the writing of this comment there
-	// is only one org. So we pretend everyone is only part of
-	// the group's organization.
-	orgs := make(map[uuid.UUID][]uuid.UUID)
-	for _, user := range users {
-		orgs[user.ID] = []uuid.UUID{g.OrganizationID}
+	members, err := api.groupMembers(ctx, group.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
 	}
-	return codersdk.Group{
-		ID:             g.ID,
-		Name:           g.Name,
-		OrganizationID: g.OrganizationID,
-		Members:        convertUsers(users, orgs),
+	idx := slices.IndexFunc(members, func(member groupMember) bool {
+		return member.ID == user.ID
+	})
+	if idx < 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("User %q must be a member of group %q.", user.Username, group.Name),
+			Code:    codersdk.ErrorCodeValidationFailed,
+		})
+		return
 	}
-}
+	member := members[idx]
 
-func convertUser(user database.User, organizationIDs []uuid.UUID) codersdk.User {
-	convertedUser := codersdk.User{
-		ID:              user.ID,
-		Email:           user.Email,
-		CreatedAt:       user.CreatedAt,
-		LastSeenAt:      user.LastSeenAt,
-		Username:        user.Username,
-		Status:          codersdk.UserStatus(user.Status),
-		OrganizationIDs: organizationIDs,
-		Roles:           make([]codersdk.Role, 0, len(user.RBACRoles)),
-		AvatarURL:       user.AvatarURL.String,
+	additionalFields, err := json.Marshal(map[string]string{
+		"group_name": group.Name,
+		"username":   user.Username,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "marshal group member audit fields", slog.Error(err))
+	}
+	aReq, commitAudit := audit.InitRequest[database.GroupMember](rw, &audit.RequestParams{
+		Audit:            auditor,
+		Log:              api.Logger,
+		Request:          r,
+		Action:           database.AuditActionWrite,
+		AdditionalFields: additionalFields,
+	})
+	defer commitAudit()
+	aReq.Old = database.GroupMember{
+		UserID:    user.ID,
+		GroupID:   group.ID,
+		ExpiresAt: member.ExpiresAt,
+		Roles:     member.Roles,
 	}
 
-	for _, roleName := range user.RBACRoles {
-		rbacRole, _ := rbac.RoleByName(roleName)
-		convertedUser.Roles = append(convertedUser.Roles, convertRole(rbacRole))
+	updated, err := api.Database.UpdateGroupMemberRoles(ctx, database.UpdateGroupMemberRolesParams{
+		GrantedRoles: req.Roles,
+		UserID:       user.ID,
+		GroupID:      group.ID,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
 	}
+	aReq.New = updated
+	api.publishGroupResourceEvent(ctx, codersdk.ResourceEventActionUpdated, group)
 
-	return convertedUser
+	member.Roles = updated.Roles
+	httpapi.Write(ctx, rw, http.StatusOK, convertGroupMember(member, groupOrganizationIDs(group)))
+}
+
+func (api *API) deleteGroup(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionDelete, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	if group.Name == database.AllUsersGroup {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("%q is a reserved group and cannot be deleted!", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
+		})
+		return
+	}
+
+	// Groups are soft deleted so they can be restored until the retention
+	// sweep removes them.
+	_, err := api.Database.UpdateGroupDeletedAtByID(ctx, database.UpdateGroupDeletedAtByIDParams{
+		ID:        group.ID,
+		DeletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+		Type: codersdk.GroupWebhookEventGroupDeleted,
+	})
+	api.publishGroupResourceEvent(ctx, codersdk.ResourceEventActionDeleted, group)
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully deleted group!",
+	})
+}
+
+// restoreGroup undoes the deletion of a group along with its members and
+// template ACL entries.
+func (api *API) restoreGroup(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	restored, err := api.Database.UpdateGroupDeletedAtByID(ctx, database.UpdateGroupDeletedAtByIDParams{
+		ID: group.ID,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Group with name %q already exists.", group.Name),
+			Code:    codersdk.ErrorCodeGroupNameTaken,
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	// Receivers mirroring groups saw the group deleted, so it's announced
+	// again when restored.
+	api.publishGroupEvent(restored, codersdk.GroupWebhookEvent{
+		Type: codersdk.GroupWebhookEventGroupCreated,
+	})
+	api.publishGroupResourceEvent(ctx, codersdk.ResourceEventActionCreated, restored)
+
+	members, err := api.groupMembers(ctx, restored.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertGroup(restored, members))
+}
+
+// groupDeletionImpact reports which template ACL entries and workspaces
+// would be affected if the group were deleted, without deleting it.
+func (api *API) groupDeletionImpact(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionDelete, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	if group.Name == database.AllUsersGroup {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("%q is a reserved group and cannot be deleted!", database.AllUsersGroup),
+			Code:    codersdk.ErrorCodeGroupNameReserved,
+		})
+		return
+	}
+
+	// Groups can only be nested under groups in the same scope.
+	scopeGroups, err := api.Database.GetGroups(ctx, database.GetGroupsParams{
+		OrganizationID: group.OrganizationID,
+	})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	// Members of nested groups inherit the group's access, so they're
+	// affected too.
+	childGroups := make([]uuid.UUID, 0)
+	groupIDs := []uuid.UUID{group.ID}
+	for i := 0; i < len(groupIDs); i++ {
+		for _, scopeGroup := range scopeGroups {
+			if !scopeGroup.ParentID.Valid || scopeGroup.ParentID.UUID != groupIDs[i] {
+				continue
+			}
+			if groupIDs[i] == group.ID {
+				childGroups = append(childGroups, scopeGroup.ID)
+			}
+			groupIDs = append(groupIDs, scopeGroup.ID)
+		}
+	}
+	membersByGroupID, err := api.groupMembersByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	memberIDs := make(map[uuid.UUID]struct{})
+	for _, members := range membersByGroupID {
+		for _, member := range members {
+			memberIDs[member.ID] = struct{}{}
+		}
+	}
+
+	// Deployment-wide groups can be granted access to templates in any
+	// organization, and uuid.Nil doesn't filter by organization.
+	templates, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+		OrganizationID: group.OrganizationID.UUID,
+	})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	templates, err = coderd.AuthorizeFilter(api.AGPL.HTTPAuth, r, rbac.ActionRead, templates)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	impact := codersdk.GroupDeletionImpact{
+		Templates:           make([]codersdk.GroupDeletionImpactTemplate, 0),
+		Workspaces:          make([]codersdk.GroupDeletionImpactWorkspace, 0),
+		ChildGroups:         childGroups,
+		QuotaAllowance:      group.QuotaAllowance,
+		QuotaAllowanceTotal: int64(group.QuotaAllowance) * int64(len(memberIDs)),
+	}
+	templateIDs := make([]uuid.UUID, 0)
+	for _, template := range templates {
+		actions, ok := template.GroupACL()[group.ID.String()]
+		if !ok {
+			continue
+		}
+		templateIDs = append(templateIDs, template.ID)
+		impact.Templates = append(impact.Templates, codersdk.GroupDeletionImpactTemplate{
+			ID:   template.ID,
+			Name: template.Name,
+			Role: convertToTemplateRole(actions),
+		})
+	}
+
+	if len(templateIDs) > 0 && len(memberIDs) > 0 {
+		workspaces, err := api.Database.GetWorkspaces(ctx, database.GetWorkspacesParams{
+			TemplateIds: templateIDs,
+		})
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		workspaces, err = coderd.AuthorizeFilter(api.AGPL.HTTPAuth, r, rbac.ActionRead, workspaces)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		for _, workspace := range workspaces {
+			if _, ok := memberIDs[workspace.OwnerID]; !ok {
+				continue
+			}
+			impact.Workspaces = append(impact.Workspaces, codersdk.GroupDeletionImpactWorkspace{
+				ID:         workspace.ID,
+				Name:       workspace.Name,
+				OwnerID:    workspace.OwnerID,
+				TemplateID: workspace.TemplateID,
+			})
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, impact)
 }
 
-func convertUsers(users []database.User, organizationIDsByUserID map[uuid.UUID][]uuid.UUID) []codersdk.User {
-	converted := make([]codersdk.User, 0, len(users))
-	for _, u := range users {
-		userOrganizationIDs := organizationIDsByUserID[u.ID]
-		converted = append(converted, convertUser(u, userOrganizationIDs))
+func (api *API) group(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	includeMembers, ok := parseIncludeMembers(rw, r)
+	if !ok {
+		return
 	}
-	return converted
+
+	groups, err := api.convertGroups(ctx, []database.Group{group}, includeMembers)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.WriteWithETag(ctx, rw, r, groups[0])
+}
+
+// groupMembersPage lists the members of a group ordered by username, a page
+// at a time.
+func (api *API) groupMembersPage(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	membersObj, err := api.groupMembersRBACObject(ctx, group)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if !api.Authorize(r, rbac.ActionRead, membersObj) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	page, ok := httpapi.ParseCursorPagination(rw, r)
+	if !ok {
+		return
+	}
+
+	rows, err := api.Database.GetGroupMembersPage(ctx, database.GetGroupMembersPageParams{
+		GroupID:  group.ID,
+		AfterID:  page.AfterID,
+		LimitOpt: page.FetchLimit(),
+	})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	rows, nextCursor := httpapi.Page(page, rows, func(row database.GetGroupMembersPageRow) uuid.UUID {
+		return row.ID
+	})
+
+	counts, err := api.Database.GetGroupMemberCountsByGroupIDs(ctx, []uuid.UUID{group.ID})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	var count int64
+	if len(counts) > 0 {
+		count = counts[0].Count
+	}
+
+	orgs := groupOrganizationIDs(group)
+	resp := make([]codersdk.GroupMember, 0, len(rows))
+	for _, row := range rows {
+		resp = append(resp, convertGroupMember(groupMember{
+			User:      row.User(),
+			ExpiresAt: row.ExpiresAt,
+			Roles:     row.GroupRoles,
+		}, orgs))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.GroupMembersResponse{
+		Members:    resp,
+		Count:      int(count),
+		NextCursor: nextCursor,
+	})
+}
+
+func (api *API) groups(rw http.ResponseWriter, r *http.Request) {
+	org := httpmw.OrganizationParam(r)
+	api.writeGroups(rw, r, uuid.NullUUID{UUID: org.ID, Valid: true})
+}
+
+// deploymentGroups lists the groups that span every organization.
+func (api *API) deploymentGroups(rw http.ResponseWriter, r *http.Request) {
+	api.writeGroups(rw, r, uuid.NullUUID{})
+}
+
+// writeGroups writes the groups of an organization, or the deployment-wide
+// groups if organizationID is null.
+func (api *API) writeGroups(rw http.ResponseWriter, r *http.Request, organizationID uuid.NullUUID) {
+	ctx := r.Context()
+
+	page, ok := httpapi.ParseCursorPagination(rw, r)
+	if !ok {
+		return
+	}
+	includeMembers, ok := parseIncludeMembers(rw, r)
+	if !ok {
+		return
+	}
+	fields, ok := httpapi.ParseFields(rw, r, codersdk.Group{})
+	if !ok {
+		return
+	}
+	// Members are the most expensive part of the response, so they aren't
+	// fetched unless they're asked for.
+	includeMembers = includeMembers && fields.Has("members")
+
+	sqlFilter, err := api.AGPL.HTTPAuth.AuthorizeSQLFilter(r, rbac.ActionRead, rbac.ResourceGroup.Type)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error preparing sql filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Groups are filtered in the query, so the count only includes the
+	// groups the requester can read.
+	rows, err := api.Database.GetAuthorizedGroups(ctx, database.GetGroupsParams{
+		OrganizationID: organizationID,
+		AfterID:        page.AfterID,
+		Search:         r.URL.Query().Get("q"),
+		LimitOpt:       page.FetchLimit(),
+	}, sqlFilter)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	// Every row carries the count of all matching groups.
+	var count int64
+	if len(rows) > 0 {
+		count = rows[0].Count
+	}
+	rows, nextCursor := httpapi.Page(page, rows, func(row database.GetGroupsRow) uuid.UUID {
+		return row.ID
+	})
+
+	groups := make([]database.Group, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, database.Group{
+			ID:               row.ID,
+			Name:             row.Name,
+			OrganizationID:   row.OrganizationID,
+			ParentID:         row.ParentID,
+			DisplayName:      row.DisplayName,
+			AvatarURL:        row.AvatarURL,
+			Description:      row.Description,
+			Source:           row.Source,
+			DeletedAt:        row.DeletedAt,
+			Metadata:         row.Metadata,
+			AutostopSchedule: row.AutostopSchedule,
+			MaxTtl:           row.MaxTtl,
+			QuotaAllowance:   row.QuotaAllowance,
+			Roles:            row.Roles,
+		})
+	}
+
+	resp, err := api.convertGroups(ctx, groups, includeMembers)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.WriteFields(ctx, rw, http.StatusOK, codersdk.GroupsResponse{
+		Groups:     resp,
+		Count:      int(count),
+		NextCursor: nextCursor,
+	}, fields, "groups")
+}
+
+// userGroups returns the groups the user is a direct member of across all of
+// their organizations, filtered to those the requester can read.
+func (api *API) userGroups(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceUser) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	includeMembers, ok := parseIncludeMembers(rw, r)
+	if !ok {
+		return
+	}
+
+	groups, err := api.Database.GetUserGroups(ctx, user.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	groups, err = coderd.AuthorizeFilter(api.AGPL.HTTPAuth, r, rbac.ActionRead, groups)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching groups.",
+			Detail:  err.Error(),
+			Code:    codersdk.ErrorCodeInternalError,
+		})
+		return
+	}
+
+	resp, err := api.convertGroups(ctx, groups, includeMembers)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// auditGroupMembers records an audit log for every member added to or
+// removed from the group and notifies the group's webhooks. The returned
+// function commits the logs and should be deferred so they include the final
+// response status.
+func (api *API) auditGroupMembers(rw http.ResponseWriter, r *http.Request, group database.Group, added []database.GroupMember, removed []uuid.UUID) func() {
+	var (
+		ctx     = r.Context()
+		auditor = *api.AGPL.Auditor.Load()
+	)
+
+	userIDs := make([]uuid.UUID, 0, len(added)+len(removed))
+	for _, member := range added {
+		userIDs = append(userIDs, member.UserID)
+	}
+	api.publishGroupMembersEvents(group, userIDs, removed)
+	userIDs = append(userIDs, removed...)
+	if len(userIDs) == 0 {
+		return func() {}
+	}
+	users, err := api.Database.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		api.Logger.Warn(ctx, "get users for group member audit", slog.Error(err))
+	}
+	usernames := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	commits := make([]func(), 0, len(userIDs))
+	auditMember := func(action database.AuditAction, old, new database.GroupMember) {
+		userID := new.UserID
+		if action == database.AuditActionDelete {
+			userID = old.UserID
+		}
+		additionalFields, err := json.Marshal(map[string]string{
+			"group_name": group.Name,
+			"username":   usernames[userID],
+		})
+		if err != nil {
+			api.Logger.Warn(ctx, "marshal group member audit fields", slog.Error(err))
+		}
+		aReq, commit := audit.InitRequest[database.GroupMember](rw, &audit.RequestParams{
+			Audit:            auditor,
+			Log:              api.Logger,
+			Request:          r,
+			Action:           action,
+			AdditionalFields: additionalFields,
+			ResourceTarget:   usernames[userID],
+		})
+		aReq.Old = old
+		aReq.New = new
+		commits = append(commits, commit)
+	}
+	for _, member := range added {
+		auditMember(database.AuditActionCreate, database.GroupMember{}, member)
+	}
+	for _, userID := range removed {
+		auditMember(database.AuditActionDelete, database.GroupMember{
+			GroupID: group.ID,
+			UserID:  userID,
+		}, database.GroupMember{})
+	}
+
+	return func() {
+		for _, commit := range commits {
+			commit()
+		}
+	}
+}
+
+// publishGroupResourceEvent notifies the events streams of a change to the
+// group or its members. Deployment-wide groups have no organization ID.
+func (api *API) publishGroupResourceEvent(ctx context.Context, action codersdk.ResourceEventAction, group database.Group) {
+	api.AGPL.PublishResourceEvent(ctx, codersdk.ResourceEvent{
+		Type:           codersdk.ResourceEventTypeGroup,
+		Action:         action,
+		OrganizationID: group.OrganizationID.UUID,
+		ResourceID:     group.ID,
+	}, group)
+}
+
+// groupMembersRBACObject returns the object used to authorize changes to the
+// members of the group, which includes the group's admins.
+func (api *API) groupMembersRBACObject(ctx context.Context, group database.Group) (rbac.Object, error) {
+	admins, err := api.Database.GetGroupMemberIDsWithRole(ctx, database.GetGroupMemberIDsWithRoleParams{
+		GroupID: group.ID,
+		Role:    rbac.RoleGroupAdmin(),
+	})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return rbac.Object{}, xerrors.Errorf("get group admins: %w", err)
+	}
+	return group.MembersRBACObject(admins), nil
+}
+
+// writeGroupMembersAllowed writes an error and returns false if the group's
+// membership is managed by an identity provider.
+func writeGroupMembersAllowed(ctx context.Context, rw http.ResponseWriter, group database.Group) bool {
+	if group.Source == database.GroupSourceUser {
+		return true
+	}
+	httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+		Message: fmt.Sprintf("Members of group %q are managed by %q and cannot be changed manually.", group.Name, group.Source),
+		Code:    codersdk.ErrorCodeGroupManaged,
+	})
+	return false
+}
+
+// parseGroupParent validates that parentID can be used as the parent of the
+// group with groupID, which is uuid.Nil for groups that are being created.
+// A nil or uuid.Nil parentID means the group has no parent. If the parent is
+// invalid, an error is written to rw and ok is false.
+func (api *API) parseGroupParent(ctx context.Context, rw http.ResponseWriter, organizationID uuid.NullUUID, groupID uuid.UUID, parentID *uuid.UUID) (uuid.NullUUID, bool) {
+	if parentID == nil || *parentID == uuid.Nil {
+		return uuid.NullUUID{}, true
+	}
+
+	parent, err := api.Database.GetGroupByID(ctx, *parentID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return uuid.NullUUID{}, false
+	}
+	if err != nil || parent.OrganizationID != organizationID || parent.Name == database.AllUsersGroup || parent.DeletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Parent group %q must be a group in the same organization, or deployment-wide if the group is.", parentID.String()),
+			Code:    codersdk.ErrorCodeValidationFailed,
+		})
+		return uuid.NullUUID{}, false
+	}
+
+	if groupID != uuid.Nil {
+		// The new parent can't be the group itself or one of its
+		// descendants, otherwise the hierarchy would contain a cycle.
+		ancestors, err := api.Database.GetGroupAncestorIDs(ctx, parent.ID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			httpapi.InternalServerError(rw, err)
+			return uuid.NullUUID{}, false
+		}
+		if slices.Contains(ancestors, groupID) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Group %q cannot be nested under itself or one of its subgroups.", groupID.String()),
+				Code:    codersdk.ErrorCodeGroupCycle,
+			})
+			return uuid.NullUUID{}, false
+		}
+	}
+
+	return uuid.NullUUID{UUID: parent.ID, Valid: true}, true
+}
+
+// resolveUserIdentifiers maps each identifier, which may be a user ID,
+// username, or email, to a user ID. Usernames and emails are resolved with a
+// single query. Identifiers that don't match any user are returned in
+// unresolved.
+func (api *API) resolveUserIdentifiers(ctx context.Context, identifiers []string) (map[string]uuid.UUID, []string, error) {
+	userIDs := make(map[string]uuid.UUID, len(identifiers))
+	names := make([]string, 0)
+	for _, identifier := range identifiers {
+		id, err := uuid.Parse(identifier)
+		if err == nil {
+			userIDs[identifier] = id
+			continue
+		}
+		names = append(names, strings.ToLower(identifier))
+	}
+	if len(names) == 0 {
+		return userIDs, nil, nil
+	}
+
+	users, err := api.Database.GetUsersByUsernamesOrEmails(ctx, names)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return nil, nil, xerrors.Errorf("get users by usernames or emails: %w", err)
+	}
+
+	var unresolved []string
+	for _, identifier := range identifiers {
+		if _, ok := userIDs[identifier]; ok {
+			continue
+		}
+		for _, user := range users {
+			if strings.EqualFold(user.Username, identifier) || strings.EqualFold(user.Email, identifier) {
+				userIDs[identifier] = user.ID
+				break
+			}
+		}
+		if _, ok := userIDs[identifier]; !ok {
+			unresolved = append(unresolved, identifier)
+		}
+	}
+	return userIDs, unresolved, nil
+}
+
+// runGroupMemberReaper removes expired group memberships until ctx is
+// canceled.
+func (api *API) runGroupMemberReaper(ctx context.Context) {
+	ticker := time.NewTicker(api.GroupMemberReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		removed, err := api.Database.DeleteExpiredGroupMembers(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				api.Logger.Warn(ctx, "failed to remove expired group members", slog.Error(err))
+			}
+			continue
+		}
+		removedByGroup := make(map[uuid.UUID][]uuid.UUID)
+		for _, member := range removed {
+			api.Logger.Debug(ctx, "removed expired group member",
+				slog.F("group_id", member.GroupID),
+				slog.F("user_id", member.UserID),
+			)
+			removedByGroup[member.GroupID] = append(removedByGroup[member.GroupID], member.UserID)
+		}
+		for groupID, userIDs := range removedByGroup {
+			group, err := api.Database.GetGroupByID(ctx, groupID)
+			if err != nil {
+				api.Logger.Warn(ctx, "get group of expired members", slog.F("group_id", groupID), slog.Error(err))
+				continue
+			}
+			api.publishGroupMembersEvents(group, nil, userIDs)
+		}
+	}
+}
+
+// runDeletedGroupReaper permanently removes groups that were deleted longer
+// than the retention period ago until ctx is canceled.
+func (api *API) runDeletedGroupReaper(ctx context.Context) {
+	ticker := time.NewTicker(api.DeletedGroupReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		removed, err := api.Database.DeleteGroupsDeletedBefore(ctx, database.Now().Add(-api.DeletedGroupRetention))
+		if err != nil {
+			if ctx.Err() == nil {
+				api.Logger.Warn(ctx, "failed to remove deleted groups", slog.Error(err))
+			}
+			continue
+		}
+		for _, group := range removed {
+			api.Logger.Debug(ctx, "removed deleted group",
+				slog.F("group_id", group.ID),
+				slog.F("group_name", group.Name),
+			)
+		}
+	}
+}
+
+// groupMember is a member of a group along with when their membership
+// expires, if ever, and their roles within the group.
+type groupMember struct {
+	database.User
+	ExpiresAt sql.NullTime
+	Roles     []string
+}
+
+// groupMembers fetches the members of a single group.
+// allowedGroupMembers returns the users among userIDs that can be added to
+// the group. Members of organization groups must belong to the organization,
+// while any user can belong to a deployment-wide group.
+func (api *API) allowedGroupMembers(ctx context.Context, group database.Group, userIDs []uuid.UUID) (map[uuid.UUID]struct{}, error) {
+	allowed := make(map[uuid.UUID]struct{}, len(userIDs))
+	if len(userIDs) == 0 {
+		return allowed, nil
+	}
+
+	if !group.OrganizationID.Valid {
+		users, err := api.Database.GetUsersByIDs(ctx, userIDs)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get users: %w", err)
+		}
+		for _, user := range users {
+			if !user.Deleted {
+				allowed[user.ID] = struct{}{}
+			}
+		}
+		return allowed, nil
+	}
+
+	// Validate organization membership of every user with a single query.
+	orgIDsByMemberIDs, err := api.Database.GetOrganizationIDsByMemberIDs(ctx, userIDs)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get organization memberships: %w", err)
+	}
+	for _, row := range orgIDsByMemberIDs {
+		if slices.Contains(row.OrganizationIDs, group.OrganizationID.UUID) {
+			allowed[row.UserID] = struct{}{}
+		}
+	}
+	return allowed, nil
+}
+
+func (api *API) groupMembers(ctx context.Context, groupID uuid.UUID) ([]groupMember, error) {
+	membersByGroupID, err := api.groupMembersByGroupIDs(ctx, []uuid.UUID{groupID})
+	if err != nil {
+		return nil, err
+	}
+	return membersByGroupID[groupID], nil
+}
+
+// groupMembersByGroupIDs fetches the members of every group in a single
+// query, keyed by group ID.
+func (api *API) groupMembersByGroupIDs(ctx context.Context, groupIDs []uuid.UUID) (map[uuid.UUID][]groupMember, error) {
+	membersByGroupID := make(map[uuid.UUID][]groupMember, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return membersByGroupID, nil
+	}
+
+	rows, err := api.Database.GetGroupMembersByGroupIDs(ctx, groupIDs)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get group members: %w", err)
+	}
+	for _, row := range rows {
+		membersByGroupID[row.GroupID] = append(membersByGroupID[row.GroupID], groupMember{
+			User:      row.User(),
+			ExpiresAt: row.ExpiresAt,
+			Roles:     row.GroupRoles,
+		})
+	}
+	return membersByGroupID, nil
+}
+
+// parseIncludeMembers parses the include_members query parameter, which
+// defaults to true. An error response is written if it's invalid.
+func parseIncludeMembers(rw http.ResponseWriter, r *http.Request) (bool, bool) {
+	s := r.URL.Query().Get("include_members")
+	if s == "" {
+		return true, true
+	}
+	includeMembers, err := strconv.ParseBool(s)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid boolean value %q for \"include_members\" query param.", s),
+			Validations: []codersdk.ValidationError{
+				{Field: "include_members", Detail: "Must be a valid boolean"},
+			},
+			Code: codersdk.ErrorCodeInvalidQueryParameter,
+		})
+		return false, false
+	}
+	return includeMembers, true
+}
+
+// convertGroups converts groups along with their members. If includeMembers
+// is false, members are counted rather than fetched so large groups stay
+// cheap to list.
+func (api *API) convertGroups(ctx context.Context, groups []database.Group, includeMembers bool) ([]codersdk.Group, error) {
+	groupIDs := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	resp := make([]codersdk.Group, 0, len(groups))
+	if includeMembers {
+		membersByGroupID, err := api.groupMembersByGroupIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			resp = append(resp, convertGroup(group, membersByGroupID[group.ID]))
+		}
+		return resp, nil
+	}
+
+	countsByGroupID := make(map[uuid.UUID]int, len(groupIDs))
+	if len(groupIDs) > 0 {
+		counts, err := api.Database.GetGroupMemberCountsByGroupIDs(ctx, groupIDs)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get group member counts: %w", err)
+		}
+		for _, count := range counts {
+			countsByGroupID[count.GroupID] = int(count.Count)
+		}
+	}
+	for _, group := range groups {
+		converted := convertGroup(group, nil)
+		converted.MembersCount = countsByGroupID[group.ID]
+		resp = append(resp, converted)
+	}
+	return resp, nil
+}
+
+// validateGroupRoles ensures a group is only granted roles that can be
+// assigned to users. Deployment-wide groups can only be granted site roles,
+// and organization groups the roles of their own organization, so admins of
+// an organization can't grant access to the whole deployment.
+func validateGroupRoles(group database.Group, roles []string) error {
+	allowed := make(map[string]struct{})
+	if group.OrganizationID.Valid {
+		for _, role := range rbac.OrganizationRoles(group.OrganizationID.UUID) {
+			allowed[role.Name] = struct{}{}
+		}
+		// Every member of the organization already has the role.
+		delete(allowed, rbac.RoleOrgMember(group.OrganizationID.UUID))
+	} else {
+		for _, role := range rbac.SiteRoles() {
+			allowed[role.Name] = struct{}{}
+		}
+		// Every user already has the role.
+		delete(allowed, rbac.RoleMember())
+	}
+
+	for _, role := range roles {
+		if _, ok := allowed[role]; ok {
+			continue
+		}
+		_, orgRole := rbac.IsOrgRole(role)
+		if orgRole && !group.OrganizationID.Valid {
+			return xerrors.Errorf("%q is an organization role, deployment-wide groups can only be granted site roles", role)
+		}
+		if _, err := rbac.RoleByName(role); err == nil && !orgRole && group.OrganizationID.Valid {
+			return xerrors.Errorf("%q is a site role, organization groups can only be granted the roles of their organization", role)
+		}
+		return xerrors.Errorf("%q cannot be granted to this group", role)
+	}
+	return nil
+}
+
+// groupEffectiveRoles returns the roles members of a group with the parent
+// and roles are granted, which include the roles of every group above it.
+// Like GetAuthorizationUserRoles, deleted groups grant nothing, including to
+// the groups beneath them.
+func (api *API) groupEffectiveRoles(ctx context.Context, parentID uuid.NullUUID, roles []string) ([]string, error) {
+	effective := slices.Clone(roles)
+	seen := make(map[uuid.UUID]struct{})
+	for parentID.Valid {
+		if _, ok := seen[parentID.UUID]; ok {
+			break
+		}
+		seen[parentID.UUID] = struct{}{}
+		parent, err := api.Database.GetGroupByID(ctx, parentID.UUID)
+		if err != nil {
+			return nil, xerrors.Errorf("get group %q: %w", parentID.UUID, err)
+		}
+		if parent.DeletedAt.Valid {
+			break
+		}
+		effective = append(effective, parent.Roles...)
+		parentID = parent.ParentID
+	}
+	return effective, nil
+}
+
+// authorizeGroupMembersAdded writes an error and returns false if the actor
+// can't grant the roles of the group to users that become its members. Users
+// that are members already are ignored.
+func (api *API) authorizeGroupMembersAdded(rw http.ResponseWriter, r *http.Request, group database.Group, userIDs []uuid.UUID) bool {
+	ctx := r.Context()
+	members, err := api.groupMembers(ctx, group.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return false
+	}
+	existing := make(map[uuid.UUID]struct{}, len(members))
+	for _, member := range members {
+		existing[member.ID] = struct{}{}
+	}
+	added := false
+	for _, id := range userIDs {
+		if _, ok := existing[id]; !ok {
+			added = true
+			break
+		}
+	}
+	if !added {
+		return true
+	}
+
+	roles, err := api.groupEffectiveRoles(ctx, group.ParentID, group.Roles)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return false
+	}
+	if !api.authorizeGroupRoleChange(r, nil, roles) {
+		httpapi.Forbidden(rw)
+		return false
+	}
+	return true
+}
+
+// authorizeGroupRoleChange checks the actor can assign and remove the roles
+// that change between from and to, the same as when changing the roles of a
+// user or organization member.
+func (api *API) authorizeGroupRoleChange(r *http.Request, from []string, to []string) bool {
+	actorRoles := httpmw.UserAuthorization(r)
+	added, removed := rbac.ChangeRoleSet(from, to)
+	for action, changed := range map[rbac.Action][]string{
+		rbac.ActionCreate: added,
+		rbac.ActionDelete: removed,
+	} {
+		for _, role := range changed {
+			object := rbac.ResourceRoleAssignment
+			if orgID, ok := rbac.IsOrgRole(role); ok {
+				object = rbac.ResourceOrgRoleAssignment.InOrg(uuid.MustParse(orgID))
+			}
+			if !api.Authorize(r, action, object) {
+				return false
+			}
+			if !rbac.CanAssignRole(actorRoles.Roles, role) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func convertGroup(g database.Group, members []groupMember) codersdk.Group {
+	// It's ridiculous to query all the orgs of a user here
+	// especially since as of the writing of this comment there
+	// is only one org. So we pretend everyone is only part of
+	// the group's organization.
+	orgs := groupOrganizationIDs(g)
+	convertedMembers := make([]codersdk.GroupMember, 0, len(members))
+	for _, member := range members {
+		convertedMembers = append(convertedMembers, convertGroupMember(member, orgs))
+	}
+	var parentID *uuid.UUID
+	if g.ParentID.Valid {
+		parentID = &g.ParentID.UUID
+	}
+	metadata := make(map[string]string)
+	// Metadata is only ever written as a JSON object of strings, and
+	// is left empty if the column hasn't been set.
+	_ = json.Unmarshal(g.Metadata, &metadata)
+	return codersdk.Group{
+		ID:               g.ID,
+		Name:             g.Name,
+		DisplayName:      g.DisplayName,
+		AvatarURL:        g.AvatarURL,
+		Description:      g.Description,
+		OrganizationID:   g.OrganizationID.UUID,
+		ParentID:         parentID,
+		Source:           codersdk.GroupSource(g.Source),
+		Members:          convertedMembers,
+		MembersCount:     len(members),
+		Metadata:         metadata,
+		AutostopSchedule: g.AutostopSchedule,
+		MaxTTLMillis:     time.Duration(g.MaxTtl).Milliseconds(),
+		QuotaAllowance:   g.QuotaAllowance,
+		Roles:            g.Roles,
+	}
+}
+
+// groupOrganizationIDs returns the organization of the group, which is
+// empty for deployment-wide groups.
+func groupOrganizationIDs(g database.Group) []uuid.UUID {
+	if !g.OrganizationID.Valid {
+		return []uuid.UUID{}
+	}
+	return []uuid.UUID{g.OrganizationID.UUID}
+}
+
+func convertGroupMember(member groupMember, organizationIDs []uuid.UUID) codersdk.GroupMember {
+	var expiresAt *time.Time
+	if member.ExpiresAt.Valid {
+		t := member.ExpiresAt.Time
+		expiresAt = &t
+	}
+	return codersdk.GroupMember{
+		User:       convertUser(member.User, organizationIDs),
+		ExpiresAt:  expiresAt,
+		GroupRoles: member.Roles,
+	}
+}
+
+func convertUser(user database.User, organizationIDs []uuid.UUID) codersdk.User {
+	convertedUser := codersdk.User{
+		ID:              user.ID,
+		Email:           user.Email,
+		CreatedAt:       user.CreatedAt,
+		LastSeenAt:      user.LastSeenAt,
+		Username:        user.Username,
+		Status:          codersdk.UserStatus(user.Status),
+		OrganizationIDs: organizationIDs,
+		Roles:           make([]codersdk.Role, 0, len(user.RBACRoles)),
+		AvatarURL:       user.AvatarURL.String,
+	}
+
+	for _, roleName := range user.RBACRoles {
+		rbacRole, _ := rbac.RoleByName(roleName)
+		convertedUser.Roles = append(convertedUser.Roles, convertRole(rbacRole))
+	}
+
+	return convertedUser
 }
 
 func convertRole(role rbac.Role) codersdk.Role {
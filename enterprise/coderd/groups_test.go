@@ -1,14 +1,23 @@
 package coderd_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/testutil"
@@ -36,6 +45,36 @@ func TestCreateGroup(t *testing.T) {
 		require.NotEqual(t, uuid.Nil.String(), group.ID.String())
 	})
 
+	t.Run("Events", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		events, err := client.WatchEvents(ctx)
+		require.NoError(t, err)
+
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		event := <-events
+		require.Equal(t, codersdk.ResourceEventTypeGroup, event.Type)
+		require.Equal(t, codersdk.ResourceEventActionCreated, event.Action)
+		require.Equal(t, group.ID, event.ResourceID)
+		require.Equal(t, user.OrganizationID, event.OrganizationID)
+
+		err = client.DeleteGroup(ctx, group.ID)
+		require.NoError(t, err)
+		event = <-events
+		require.Equal(t, codersdk.ResourceEventActionDeleted, event.Action)
+		require.Equal(t, group.ID, event.ResourceID)
+	})
+
 	t.Run("Conflict", func(t *testing.T) {
 		t.Parallel()
 
@@ -58,6 +97,7 @@ func TestCreateGroup(t *testing.T) {
 		cerr, ok := codersdk.AsError(err)
 		require.True(t, ok)
 		require.Equal(t, http.StatusConflict, cerr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeGroupNameTaken, cerr.Code)
 	})
 
 	t.Run("allUsers", func(t *testing.T) {
@@ -77,73 +117,128 @@ func TestCreateGroup(t *testing.T) {
 		cerr, ok := codersdk.AsError(err)
 		require.True(t, ok)
 		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeGroupNameReserved, cerr.Code)
 	})
 }
 
-func TestPatchGroup(t *testing.T) {
+func TestGroupHierarchy(t *testing.T) {
 	t.Parallel()
 
-	t.Run("Name", func(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
 		ctx, _ := testutil.Context(t)
-		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
-			Name: "hi",
+		parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "engineering",
 		})
 		require.NoError(t, err)
+		require.Nil(t, parent.ParentID)
 
-		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			Name: "bye",
+		child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "platform",
+			ParentID: &parent.ID,
 		})
 		require.NoError(t, err)
-		require.Equal(t, "bye", group.Name)
+		require.NotNil(t, child.ParentID)
+		require.Equal(t, parent.ID, *child.ParentID)
+
+		child, err = client.Group(ctx, child.ID)
+		require.NoError(t, err)
+		require.Equal(t, parent.ID, *child.ParentID)
+
+		// Renaming a group keeps its parent.
+		child, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+			Name: "sre",
+		})
+		require.NoError(t, err)
+		require.Equal(t, parent.ID, *child.ParentID)
 	})
 
-	t.Run("AddUsers", func(t *testing.T) {
+	t.Run("Cycle", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-
 		ctx, _ := testutil.Context(t)
-		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
-			Name: "hi",
+		parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "engineering",
 		})
 		require.NoError(t, err)
-
-		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "platform",
+			ParentID: &parent.ID,
+		})
+		require.NoError(t, err)
+		grandchild, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "sre",
+			ParentID: &child.ID,
 		})
 		require.NoError(t, err)
-		require.Contains(t, group.Members, user2)
-		require.Contains(t, group.Members, user3)
+
+		_, err = client.PatchGroup(ctx, parent.ID, codersdk.PatchGroupRequest{
+			ParentID: &grandchild.ID,
+		})
+		require.Error(t, err)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeGroupCycle))
+
+		_, err = client.PatchGroup(ctx, parent.ID, codersdk.PatchGroupRequest{
+			ParentID: &parent.ID,
+		})
+		require.Error(t, err)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeGroupCycle))
 	})
 
-	t.Run("RemoveUsers", func(t *testing.T) {
+	t.Run("InvalidParent", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-		_, user4 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		ctx, _ := testutil.Context(t)
+		parentID := uuid.New()
+		_, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "engineering",
+			ParentID: &parentID,
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+
+		// The allUsers group can't be used as a parent.
+		_, err = client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "engineering",
+			ParentID: &user.OrganizationID,
+		})
+		require.Error(t, err)
+		cerr, ok = codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+}
+
+func TestPatchGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Name", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
 
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
 			Name: "hi",
@@ -151,62 +246,94 @@ func TestPatchGroup(t *testing.T) {
 		require.NoError(t, err)
 
 		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user2.ID.String(), user3.ID.String(), user4.ID.String()},
-		})
-		require.NoError(t, err)
-		require.Contains(t, group.Members, user2)
-		require.Contains(t, group.Members, user3)
-
-		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			RemoveUsers: []string{user2.ID.String(), user3.ID.String()},
+			Name: "bye",
 		})
 		require.NoError(t, err)
-		require.NotContains(t, group.Members, user2)
-		require.NotContains(t, group.Members, user3)
-		require.Contains(t, group.Members, user4)
+		require.Equal(t, "bye", group.Name)
 	})
 
-	t.Run("UserNotExist", func(t *testing.T) {
+	t.Run("IfMatch", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
 			Name: "hi",
 		})
 		require.NoError(t, err)
 
-		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{uuid.NewString()},
-		})
-		require.Error(t, err)
-		cerr, ok := codersdk.AsError(err)
-		require.True(t, ok)
-		require.Equal(t, http.StatusPreconditionFailed, cerr.StatusCode())
+		read := func(query string) string {
+			res, err := client.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/groups/%s%s", group.ID, query), nil)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res.Header.Get("ETag")
+		}
+		patch := func(etag string, req codersdk.PatchGroupRequest) int {
+			res, err := client.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/groups/%s", group.ID), req, func(r *http.Request) {
+				r.Header.Set("If-Match", etag)
+			})
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res.StatusCode
+		}
+
+		// The ETag of the group read with or without members can be used.
+		require.Equal(t, http.StatusOK, patch(read("?include_members=false"), codersdk.PatchGroupRequest{
+			DisplayName: ptr.Ref("Hi"),
+		}))
+		etag := read("")
+		require.Equal(t, http.StatusOK, patch(etag, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String()},
+		}))
+		require.Equal(t, http.StatusConflict, patch(etag, codersdk.PatchGroupRequest{
+			DisplayName: ptr.Ref("Hello"),
+		}))
 	})
 
-	t.Run("MalformedUUID", func(t *testing.T) {
+	t.Run("DisplayFields", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
-			Name: "hi",
+			Name:        "platform",
+			DisplayName: "Platform Team",
+			AvatarURL:   "https://example.com/platform.png",
+			Description: "Keeps the lights on.",
 		})
 		require.NoError(t, err)
+		require.Equal(t, "Platform Team", group.DisplayName)
+		require.Equal(t, "https://example.com/platform.png", group.AvatarURL)
+		require.Equal(t, "Keeps the lights on.", group.Description)
 
+		displayName := "Platform"
+		description := ""
 		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{"yeet"},
+			DisplayName: &displayName,
+			Description: &description,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "platform", group.Name)
+		require.Equal(t, "Platform", group.DisplayName)
+		require.Equal(t, "https://example.com/platform.png", group.AvatarURL)
+		require.Empty(t, group.Description)
+
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Platform", group.DisplayName)
+
+		avatarURL := "not a url"
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AvatarURL: &avatarURL,
 		})
 		require.Error(t, err)
 		cerr, ok := codersdk.AsError(err)
@@ -214,38 +341,64 @@ func TestPatchGroup(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
 	})
 
-	t.Run("AddDuplicateUser", func(t *testing.T) {
+	t.Run("Metadata", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
 			Name: "hi",
 		})
 		require.NoError(t, err)
+		require.Empty(t, group.Metadata)
 
+		metadata := map[string]string{
+			"cost_center":   "1234",
+			"slack_channel": "#platform",
+		}
 		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user2.ID.String(), user2.ID.String()},
+			Metadata: &metadata,
+		})
+		require.NoError(t, err)
+		require.Equal(t, metadata, group.Metadata)
+
+		// Patching other fields leaves the metadata alone.
+		description := "hello"
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Description: &description,
+		})
+		require.NoError(t, err)
+		require.Equal(t, metadata, group.Metadata)
+
+		groups, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Len(t, groups.Groups, 1)
+		require.Equal(t, metadata, groups.Groups[0].Metadata)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Metadata: &map[string]string{},
+		})
+		require.NoError(t, err)
+		require.Empty(t, group.Metadata)
+
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Metadata: &map[string]string{"": "empty"},
 		})
 		require.Error(t, err)
 		cerr, ok := codersdk.AsError(err)
 		require.True(t, ok)
-
-		require.Equal(t, http.StatusPreconditionFailed, cerr.StatusCode())
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
 	})
 
-	t.Run("allUsers", func(t *testing.T) {
+	t.Run("Autostop", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
@@ -256,70 +409,167 @@ func TestPatchGroup(t *testing.T) {
 		require.NoError(t, err)
 
 		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			Name: database.AllUsersGroup,
+			AutostopSchedule: ptr.Ref("CRON_TZ=Europe/London 0 18 * * 1-5"),
+			MaxTTLMillis:     ptr.Ref(time.Hour.Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "CRON_TZ=Europe/London 0 18 * * 1-5", group.AutostopSchedule)
+		require.Equal(t, time.Hour.Milliseconds(), group.MaxTTLMillis)
+
+		// Other changes leave the policy alone.
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Name: "bye",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "CRON_TZ=Europe/London 0 18 * * 1-5", group.AutostopSchedule)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AutostopSchedule: ptr.Ref(""),
+			MaxTTLMillis:     ptr.Ref(int64(0)),
+		})
+		require.NoError(t, err)
+		require.Empty(t, group.AutostopSchedule)
+		require.Zero(t, group.MaxTTLMillis)
+
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AutostopSchedule: ptr.Ref("every evening"),
 		})
 		require.Error(t, err)
 		cerr, ok := codersdk.AsError(err)
 		require.True(t, ok)
 		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
 	})
-}
-
-// TODO: test auth.
-func TestGroup(t *testing.T) {
-	t.Parallel()
 
-	t.Run("OK", func(t *testing.T) {
+	t.Run("Roles", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
+		client2, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
 		ctx, _ := testutil.Context(t)
+		canCreateTemplate := func() bool {
+			resp, err := client2.CheckAuthorization(ctx, codersdk.AuthorizationRequest{
+				Checks: map[string]codersdk.AuthorizationCheck{
+					"create-template": {
+						Object: codersdk.AuthorizationObject{
+							ResourceType:   "template",
+							OrganizationID: user.OrganizationID.String(),
+						},
+						Action: "create",
+					},
+				},
+			})
+			require.NoError(t, err)
+			return resp["create-template"]
+		}
+
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
-			Name: "hi",
+			Name: "sre",
 		})
 		require.NoError(t, err)
+		require.Empty(t, group.Roles)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String()},
+		})
+		require.NoError(t, err)
+		require.False(t, canCreateTemplate())
 
-		ggroup, err := client.Group(ctx, group.ID)
+		// Members get the roles of the group without logging in again.
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Roles: &[]string{rbac.RoleOrgAdmin(user.OrganizationID), rbac.RoleOrgAuditor(user.OrganizationID)},
+		})
 		require.NoError(t, err)
-		require.Equal(t, group, ggroup)
+		require.ElementsMatch(t, []string{rbac.RoleOrgAdmin(user.OrganizationID), rbac.RoleOrgAuditor(user.OrganizationID)}, group.Roles)
+		require.True(t, canCreateTemplate())
+
+		policy, err := client.AuthorizationPolicy(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.AuthorizationGroupRoles{{
+			GroupID:        group.ID,
+			GroupName:      group.Name,
+			OrganizationID: user.OrganizationID,
+			Roles:          group.Roles,
+		}}, policy.GroupRoles)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Roles: &[]string{},
+		})
+		require.NoError(t, err)
+		require.Empty(t, group.Roles)
+		require.False(t, canCreateTemplate())
+
+		for _, role := range []string{
+			rbac.RoleMember(),
+			rbac.RoleOrgMember(user.OrganizationID),
+			rbac.RoleOrgAdmin(uuid.New()),
+			rbac.RoleGroupAdmin(),
+			// Organization groups can't be granted site roles.
+			rbac.RoleTemplateAdmin(),
+			rbac.RoleOwner(),
+			"not-a-role",
+		} {
+			_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+				Roles: &[]string{role},
+			})
+			require.Error(t, err, role)
+			cerr, ok := codersdk.AsError(err)
+			require.True(t, ok)
+			require.Equal(t, http.StatusBadRequest, cerr.StatusCode(), role)
+		}
 	})
 
-	t.Run("WithUsers", func(t *testing.T) {
+	t.Run("RolesNotAssignable", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		orgAdmin, orgAdminUser := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID, rbac.RoleOrgAdmin(user.OrganizationID))
 
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
-			Name: "hi",
+			Name: "sre",
 		})
 		require.NoError(t, err)
 
-		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		// Organization admins can grant organization roles, but not site roles.
+		group, err = orgAdmin.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Roles: &[]string{rbac.RoleOrgAdmin(user.OrganizationID)},
 		})
 		require.NoError(t, err)
-		require.Contains(t, group.Members, user2)
-		require.Contains(t, group.Members, user3)
+		require.Equal(t, []string{rbac.RoleOrgAdmin(user.OrganizationID)}, group.Roles)
 
-		ggroup, err := client.Group(ctx, group.ID)
+		_, err = orgAdmin.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Roles: &[]string{rbac.RoleOwner()},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+
+		// Nor can they add members to a group that would be granted roles
+		// they can't assign.
+		deployment, err := client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "owners",
+		})
 		require.NoError(t, err)
-		require.Equal(t, group, ggroup)
+		_, err = client.PatchGroup(ctx, deployment.ID, codersdk.PatchGroupRequest{
+			Roles: &[]string{rbac.RoleOwner()},
+		})
+		require.NoError(t, err)
+		_, err = orgAdmin.PatchGroup(ctx, deployment.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{orgAdminUser.ID.String()},
+		})
+		require.Error(t, err)
 	})
 
-	t.Run("RegularUserReadGroup", func(t *testing.T) {
+	t.Run("AddUsers", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
@@ -328,7 +578,8 @@ func TestGroup(t *testing.T) {
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-		client1, _ := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
@@ -336,22 +587,26 @@ func TestGroup(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		ggroup, err := client1.Group(ctx, group.ID)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		})
 		require.NoError(t, err)
-		require.Equal(t, group, ggroup)
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user3})
 	})
 
-	t.Run("FilterDeletedUsers", func(t *testing.T) {
+	t.Run("RemoveUsers", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
+
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-
-		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user4 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
@@ -360,21 +615,22 @@ func TestGroup(t *testing.T) {
 		require.NoError(t, err)
 
 		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user1.ID.String(), user2.ID.String()},
+			AddUsers: []string{user2.ID.String(), user3.ID.String(), user4.ID.String()},
 		})
 		require.NoError(t, err)
-		require.Contains(t, group.Members, user1)
-		require.Contains(t, group.Members, user2)
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user3})
 
-		err = client.DeleteUser(ctx, user1.ID)
-		require.NoError(t, err)
-
-		group, err = client.Group(ctx, group.ID)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			RemoveUsers: []string{user2.ID.String(), user3.ID.String()},
+		})
 		require.NoError(t, err)
-		require.NotContains(t, group.Members, user1)
+		require.NotContains(t, group.Members, codersdk.GroupMember{User: user2})
+		require.NotContains(t, group.Members, codersdk.GroupMember{User: user3})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user4})
 	})
 
-	t.Run("FilterSuspendedUsers", func(t *testing.T) {
+	t.Run("AddUsersWithExpiry", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
@@ -382,40 +638,32 @@ func TestGroup(t *testing.T) {
 		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
 			RBACEnabled: true,
 		})
-
-		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-
 		ctx, _ := testutil.Context(t)
 		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
 			Name: "hi",
 		})
 		require.NoError(t, err)
 
-		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user1.ID.String(), user2.ID.String()},
+		past := time.Now().Add(-time.Hour)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers:         []string{user2.ID.String()},
+			AddUsersExpireAt: &past,
 		})
-		require.NoError(t, err)
-		require.Len(t, group.Members, 2)
-		require.Contains(t, group.Members, user1)
-		require.Contains(t, group.Members, user2)
-
-		user1, err = client.UpdateUserStatus(ctx, user1.ID.String(), codersdk.UserStatusSuspended)
-		require.NoError(t, err)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeValidationFailed))
 
-		group, err = client.Group(ctx, group.ID)
+		future := time.Now().Add(time.Hour)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers:         []string{user2.ID.String()},
+			AddUsersExpireAt: &future,
+		})
 		require.NoError(t, err)
 		require.Len(t, group.Members, 1)
-		require.NotContains(t, group.Members, user1)
-		require.Contains(t, group.Members, user2)
+		require.NotNil(t, group.Members[0].ExpiresAt)
+		require.WithinDuration(t, future, *group.Members[0].ExpiresAt, time.Second)
 	})
-}
-
-// TODO: test auth.
-func TestGroups(t *testing.T) {
-	t.Parallel()
 
-	t.Run("OK", func(t *testing.T) {
+	t.Run("AddUsersByUsernameAndEmail", func(t *testing.T) {
 		t.Parallel()
 
 		client := coderdenttest.New(t, nil)
@@ -425,39 +673,1214 @@ func TestGroups(t *testing.T) {
 		})
 		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
 		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-		_, user4 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-		_, user5 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
-
 		ctx, _ := testutil.Context(t)
-		group1, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
 			Name: "hi",
 		})
 		require.NoError(t, err)
 
-		group2, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
-			Name: "hey",
-		})
-		require.NoError(t, err)
-
-		group1, err = client.PatchGroup(ctx, group1.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.Username, strings.ToUpper(user3.Email)},
 		})
 		require.NoError(t, err)
+		require.Len(t, group.Members, 2)
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user3})
 
-		group2, err = client.PatchGroup(ctx, group2.ID, codersdk.PatchGroupRequest{
-			AddUsers: []string{user4.ID.String(), user5.ID.String()},
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			RemoveUsers: []string{user2.Email},
 		})
 		require.NoError(t, err)
-
-		groups, err := client.GroupsByOrganization(ctx, user.OrganizationID)
-		require.NoError(t, err)
-		require.Len(t, groups, 2)
-		require.Contains(t, groups, group1)
-		require.Contains(t, groups, group2)
+		require.NotContains(t, group.Members, codersdk.GroupMember{User: user2})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user3})
 	})
-}
 
-func TestDeleteGroup(t *testing.T) {
+	t.Run("UserNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{uuid.NewString()},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusPreconditionFailed, cerr.StatusCode())
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeOrgMemberRequired))
+	})
+
+	t.Run("MalformedUUID", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{"yeet"},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+
+	t.Run("AddDuplicateUser", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String(), user2.ID.String()},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+
+		require.Equal(t, http.StatusPreconditionFailed, cerr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeGroupMemberExists, cerr.Code)
+	})
+
+	t.Run("allUsers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Name: database.AllUsersGroup,
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeGroupNameReserved, cerr.Code)
+	})
+
+	t.Run("Managed", func(t *testing.T) {
+		t.Parallel()
+
+		client, _, api := coderdenttest.NewWithAPI(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		dbGroup, err := api.Database.InsertGroup(ctx, database.InsertGroupParams{
+			ID:             uuid.New(),
+			Name:           "synced",
+			OrganizationID: uuid.NullUUID{UUID: user.OrganizationID, Valid: true},
+			Source:         database.GroupSourceOIDC,
+		})
+		require.NoError(t, err)
+
+		group, err := client.Group(ctx, dbGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.GroupSourceOIDC, group.Source)
+
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String()},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeGroupManaged, cerr.Code)
+
+		_, err = client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{user2.ID.String()},
+		})
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeGroupManaged))
+
+		// Display fields can still be edited.
+		description := "Synced from the identity provider"
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Description: &description,
+		})
+		require.NoError(t, err)
+		require.Equal(t, description, group.Description)
+		require.Empty(t, group.Members)
+	})
+}
+
+// TODO: test auth.
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		ggroup, err := client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, group, ggroup)
+	})
+
+	t.Run("ByName", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		ggroup, err := client.GroupByOrgAndName(ctx, user.OrganizationID, group.Name)
+		require.NoError(t, err)
+		require.Equal(t, group, ggroup)
+
+		_, err = client.GroupByOrgAndName(ctx, user.OrganizationID, "nope")
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusNotFound, cerr.StatusCode())
+	})
+
+	t.Run("WithUsers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user3})
+
+		ggroup, err := client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, group, ggroup)
+	})
+
+	t.Run("RegularUserReadGroup", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		client1, _ := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		ggroup, err := client1.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, group, ggroup)
+	})
+
+	t.Run("FilterDeletedUsers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String(), user2.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user1})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+
+		err = client.DeleteUser(ctx, user1.ID)
+		require.NoError(t, err)
+
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.NotContains(t, group.Members, codersdk.GroupMember{User: user1})
+	})
+
+	t.Run("FilterSuspendedUsers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String(), user2.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Len(t, group.Members, 2)
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user1})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+
+		user1, err = client.UpdateUserStatus(ctx, user1.ID.String(), codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, group.Members, 1)
+		require.NotContains(t, group.Members, codersdk.GroupMember{User: user1})
+		require.Contains(t, group.Members, codersdk.GroupMember{User: user2})
+	})
+}
+
+// TODO: test auth.
+func TestGroups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user4 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user5 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group1, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		group2, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hey",
+		})
+		require.NoError(t, err)
+
+		group1, err = client.PatchGroup(ctx, group1.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		})
+		require.NoError(t, err)
+
+		group2, err = client.PatchGroup(ctx, group2.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user4.ID.String(), user5.ID.String()},
+		})
+		require.NoError(t, err)
+
+		groups, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 2, groups.Count)
+		require.Len(t, groups.Groups, 2)
+		require.Contains(t, groups.Groups, group1)
+		require.Contains(t, groups.Groups, group2)
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		for _, name := range []string{"alpha", "bravo", "charlie", "delta"} {
+			_, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+				Name: name,
+			})
+			require.NoError(t, err)
+		}
+
+		page, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{
+			CursorPagination: codersdk.CursorPagination{Limit: 2},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 4, page.Count)
+		require.Len(t, page.Groups, 2)
+		require.Equal(t, "alpha", page.Groups[0].Name)
+		require.Equal(t, "bravo", page.Groups[1].Name)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{
+			CursorPagination: codersdk.CursorPagination{Cursor: page.NextCursor, Limit: 2},
+		})
+		require.NoError(t, err)
+		// The count covers every matching group, not just those after the
+		// cursor.
+		require.Equal(t, 4, page.Count)
+		require.Len(t, page.Groups, 2)
+		require.Equal(t, "charlie", page.Groups[0].Name)
+		require.Equal(t, "delta", page.Groups[1].Name)
+		require.Empty(t, page.NextCursor)
+
+		_, err = client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{
+			CursorPagination: codersdk.CursorPagination{Cursor: "not-a-cursor"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		page, err = client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{
+			SearchQuery: "RAV",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, page.Count)
+		require.Len(t, page.Groups, 1)
+		require.Equal(t, "bravo", page.Groups[0].Name)
+	})
+
+	t.Run("WithoutMembers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		_, err = client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "empty",
+		})
+		require.NoError(t, err)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String(), user3.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, group.MembersCount)
+
+		page, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{
+			IncludeMembers: ptr.Ref(false),
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Groups, 2)
+		for _, g := range page.Groups {
+			require.Len(t, g.Members, 0)
+			if g.ID == group.ID {
+				require.Equal(t, 2, g.MembersCount)
+			} else {
+				require.Equal(t, 0, g.MembersCount)
+			}
+		}
+
+		page, err = client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{
+			Fields: []string{"id", "name"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, page.Count)
+		require.Len(t, page.Groups, 2)
+		for _, g := range page.Groups {
+			require.NotEmpty(t, g.Name)
+			require.Nil(t, g.Members)
+			require.Zero(t, g.MembersCount)
+		}
+
+		res, err := client.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/groups/%s?include_members=nope", group.ID), nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestDeploymentGroups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "other",
+		})
+		require.NoError(t, err)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, org.ID)
+
+		group, err := client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "platform",
+		})
+		require.NoError(t, err)
+		require.Equal(t, uuid.Nil, group.OrganizationID)
+
+		// Members can come from any organization.
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String(), user2.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Len(t, group.Members, 2)
+
+		groups, err := client.DeploymentGroups(ctx, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 1, groups.Count)
+		require.Equal(t, []codersdk.Group{group}, groups.Groups)
+
+		orgGroups, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Len(t, orgGroups.Groups, 0)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "platform",
+		})
+		require.NoError(t, err)
+
+		// Organization groups don't conflict with deployment-wide groups.
+		_, err = client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "platform",
+		})
+		require.NoError(t, err)
+
+		_, err = client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "platform",
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, cerr.StatusCode())
+		require.Equal(t, codersdk.ErrorCodeGroupNameTaken, cerr.Code)
+	})
+
+	t.Run("ParentInOrganization", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "parent",
+		})
+		require.NoError(t, err)
+
+		_, err = client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name:     "platform",
+			ParentID: &parent.ID,
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+
+	t.Run("OrgMemberCannotRead", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		client1, _ := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "platform",
+		})
+		require.NoError(t, err)
+
+		_, err = client1.Group(ctx, group.ID)
+		require.Error(t, err)
+		groups, err := client1.DeploymentGroups(ctx, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Len(t, groups.Groups, 0)
+	})
+}
+
+func TestGroupMemberAudit(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, &coderdenttest.Options{
+		AuditLogging: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		AuditLog:    true,
+		RBACEnabled: true,
+	})
+	_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "hi",
+	})
+	require.NoError(t, err)
+	_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+		AddUsers: []string{user2.ID.String(), user3.Username},
+	})
+	require.NoError(t, err)
+	_, err = client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+		UserIDs: []string{user2.ID.String()},
+	})
+	require.NoError(t, err)
+	// Removing a user that isn't a member changes nothing to audit.
+	_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+		RemoveUsers: []string{user3.ID.String()},
+	})
+	require.NoError(t, err)
+
+	res, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+		SearchQuery: "resource_type:group_member",
+		CursorPagination: codersdk.CursorPagination{
+			Limit: 10,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.AuditLogs, 3)
+	for _, alog := range res.AuditLogs {
+		require.Equal(t, codersdk.ResourceTypeGroupMember, alog.ResourceType)
+		require.Equal(t, group.ID, alog.ResourceID)
+		require.Equal(t, user.UserID, alog.User.ID)
+	}
+
+	added := make([]string, 0, 2)
+	for _, alog := range res.AuditLogs {
+		if alog.Action == codersdk.AuditActionCreate {
+			added = append(added, alog.ResourceTarget)
+			continue
+		}
+		require.Equal(t, codersdk.AuditActionDelete, alog.Action)
+		require.Equal(t, user3.Username, alog.ResourceTarget)
+		require.JSONEq(t, fmt.Sprintf(`{"group_name":"hi","username":%q}`, user3.Username), string(alog.AdditionalFields))
+	}
+	require.ElementsMatch(t, []string{user2.Username, user3.Username}, added)
+}
+
+func TestGroupMemberReaper(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		GroupMemberReapInterval: testutil.IntervalFast,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+	_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "hi",
+	})
+	require.NoError(t, err)
+
+	err = api.Database.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+		UserID:  user2.ID,
+		GroupID: group.ID,
+		ExpiresAt: sql.NullTime{
+			Time:  database.Now().Add(-time.Second),
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		group, err := client.Group(ctx, group.ID)
+		return err == nil && len(group.Members) == 0
+	}, testutil.WaitShort, testutil.IntervalFast)
+}
+
+func TestUserGroups(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+	client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+	ctx, _ := testutil.Context(t)
+	var groups []codersdk.Group
+	for _, name := range []string{"zeta", "alpha", "other"} {
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: name,
+		})
+		require.NoError(t, err)
+		if name == "other" {
+			continue
+		}
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+		groups = append(groups, group)
+	}
+
+	userGroups, err := client.UserGroups(ctx, user1.ID.String())
+	require.NoError(t, err)
+	require.Equal(t, []codersdk.Group{groups[1], groups[0]}, userGroups)
+
+	userGroups, err = client1.UserGroups(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.Len(t, userGroups, 2)
+
+	userGroups, err = client.UserGroups(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.Empty(t, userGroups)
+}
+
+func TestGroupMembers(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "hi",
+	})
+	require.NoError(t, err)
+	var userIDs []string
+	for i := 0; i < 3; i++ {
+		_, member := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		userIDs = append(userIDs, member.ID.String())
+	}
+	_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+		AddUsers: userIDs,
+	})
+	require.NoError(t, err)
+
+	all, err := client.GroupMembers(ctx, group.ID, codersdk.CursorPagination{})
+	require.NoError(t, err)
+	require.Equal(t, 3, all.Count)
+	require.Len(t, all.Members, 3)
+	require.Empty(t, all.NextCursor)
+	require.True(t, sort.SliceIsSorted(all.Members, func(i, j int) bool {
+		return all.Members[i].Username < all.Members[j].Username
+	}))
+
+	page, err := client.GroupMembers(ctx, group.ID, codersdk.CursorPagination{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Count)
+	require.Equal(t, all.Members[:1], page.Members)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = client.GroupMembers(ctx, group.ID, codersdk.CursorPagination{
+		Cursor: page.NextCursor,
+		Limit:  1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Count)
+	require.Equal(t, all.Members[1:2], page.Members)
+
+	_, err = client.GroupMembers(ctx, group.ID, codersdk.CursorPagination{
+		Cursor: "not-a-cursor",
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+	t.Run("MemberCantRead", func(t *testing.T) {
+		t.Parallel()
+
+		// Members can read the group, but only admins of the organization
+		// or group can list its members.
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		ctx, _ := testutil.Context(t)
+		_, err := member.GroupMembers(ctx, group.ID, codersdk.CursorPagination{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Iterators", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, _ := testutil.Context(t)
+		groups := client.GroupsIterator(ctx, user.OrganizationID)
+		var names []string
+		for groups.Next() {
+			names = append(names, groups.Value().Name)
+		}
+		require.NoError(t, groups.Err())
+		require.Equal(t, []string{"hi"}, names)
+
+		members := client.GroupMembersIterator(ctx, group.ID)
+		var got []codersdk.GroupMember
+		for members.Next() {
+			got = append(got, members.Value())
+		}
+		require.NoError(t, members.Err())
+		require.Equal(t, all.Members, got)
+	})
+}
+
+func TestPutGroupMembers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		_, user4 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		nonMember := uuid.New()
+		resp, err := client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{
+				user2.ID.String(),
+				user3.ID.String(),
+				user3.ID.String(),
+				"not-a-uuid",
+				nonMember.String(),
+			},
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uuid.UUID{user2.ID, user3.ID}, resp.Added)
+		require.Empty(t, resp.Removed)
+		require.ElementsMatch(t, []codersdk.SkippedGroupMember{
+			{UserID: user3.ID.String(), Reason: codersdk.GroupMemberSkipReasonDuplicate},
+			{UserID: "not-a-uuid", Reason: codersdk.GroupMemberSkipReasonInvalidID},
+			{UserID: nonMember.String(), Reason: codersdk.GroupMemberSkipReasonNotOrgMember},
+		}, resp.Skipped)
+		require.Len(t, resp.Group.Members, 2)
+
+		resp, err = client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{user3.ID.String(), user4.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{user4.ID}, resp.Added)
+		require.Equal(t, []uuid.UUID{user2.ID}, resp.Removed)
+		require.Empty(t, resp.Skipped)
+		require.ElementsMatch(t, []codersdk.GroupMember{{User: user3}, {User: user4}}, resp.Group.Members)
+	})
+
+	t.Run("TooMany", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		userIDs := make([]string, codersdk.MaxGroupMembersPerRequest+1)
+		for i := range userIDs {
+			userIDs[i] = uuid.NewString()
+		}
+		_, err = client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: userIDs,
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+
+	t.Run("allUsers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.PutGroupMembers(ctx, user.OrganizationID, codersdk.PutGroupMembersRequest{})
+		require.Error(t, err)
+		require.True(t, codersdk.IsErrorCode(err, codersdk.ErrorCodeGroupNameReserved))
+	})
+}
+
+func TestGroupAdmin(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.Group, *codersdk.Client, codersdk.User) {
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		adminClient, admin := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{admin.ID.String()},
+		})
+		require.NoError(t, err)
+		return client, group, adminClient, admin
+	}
+
+	t.Run("ManageMembers", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, adminClient, admin := setup(t)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, group.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		// Members can see the group, but can't change its members until
+		// they're made an admin.
+		_, err := adminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String()},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+
+		member, err := client.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"group-admin"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"group-admin"}, member.GroupRoles)
+
+		group, err = adminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Len(t, group.Members, 2)
+
+		group, err = adminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			RemoveUsers: []string{user2.ID.String()},
+		})
+		require.NoError(t, err)
+		require.Len(t, group.Members, 1)
+		require.Equal(t, []string{"group-admin"}, group.Members[0].GroupRoles)
+	})
+
+	t.Run("CannotUpdateGroup", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, adminClient, admin := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"group-admin"},
+		})
+		require.NoError(t, err)
+
+		_, err = adminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Name: "bye",
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+
+		// Membership and group changes are checked separately, so a request
+		// that also renames the group fails as a whole.
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, group.OrganizationID)
+		_, err = adminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Name:     "bye",
+			AddUsers: []string{user2.ID.String()},
+		})
+		require.Error(t, err)
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, "hi", group.Name)
+		require.Len(t, group.Members, 1)
+
+		err = adminClient.DeleteGroup(ctx, group.ID)
+		require.Error(t, err)
+
+		// Group admins can't appoint other group admins.
+		_, err = adminClient.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("CannotGrantGroupRoles", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, adminClient, admin := setup(t)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, group.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"group-admin"},
+		})
+		require.NoError(t, err)
+
+		// Members are granted the roles of the groups above theirs too, so
+		// adding them requires being able to grant those roles.
+		parent, err := client.CreateGroup(ctx, group.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "leads",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, parent.ID, codersdk.PatchGroupRequest{
+			Roles: &[]string{rbac.RoleOrgAuditor(group.OrganizationID)},
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			ParentID: &parent.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = adminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user2.ID.String()},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+
+		_, err = adminClient.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{admin.ID.String(), user2.ID.String()},
+		})
+		require.Error(t, err)
+		cerr, ok = codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+
+		// Keeping the members the group has is allowed.
+		_, err = adminClient.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{admin.ID.String()},
+		})
+		require.NoError(t, err)
+
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, group.Members, 1)
+	})
+
+	t.Run("OtherGroup", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, adminClient, admin := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"group-admin"},
+		})
+		require.NoError(t, err)
+
+		other, err := client.CreateGroup(ctx, group.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "other",
+		})
+		require.NoError(t, err)
+		_, err = adminClient.PatchGroup(ctx, other.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{admin.ID.String()},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, _, admin := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateGroupMemberRoles(ctx, group.ID, admin.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"owner"},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+
+	t.Run("NotMember", func(t *testing.T) {
+		t.Parallel()
+
+		client, group, _, _ := setup(t)
+		_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, group.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateGroupMemberRoles(ctx, group.ID, user2.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{"group-admin"},
+		})
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+
+	t.Run("SeparateAddAndRemove", func(t *testing.T) {
+		t.Parallel()
+
+		setup := func(t *testing.T, denied rbac.Action) (*codersdk.Client, codersdk.Group, codersdk.User) {
+			client := coderdenttest.New(t, &coderdenttest.Options{
+				Options: &coderdtest.Options{
+					Authorizer: &groupMemberAuthorizer{Authorizer: rbac.NewAuthorizer(), denied: denied},
+				},
+			})
+			user := coderdtest.CreateFirstUser(t, client)
+			_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				RBACEnabled: true,
+			})
+			_, member := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+			ctx, _ := testutil.Context(t)
+			group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+				Name: "hi",
+			})
+			require.NoError(t, err)
+			return client, group, member
+		}
+
+		// Replacing the members needs the same permissions as adding and
+		// removing them one by one.
+		client, group, member := setup(t, rbac.ActionDelete)
+		ctx, _ := testutil.Context(t)
+		_, err := client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{member.ID.String()},
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			RemoveUsers: []string{member.ID.String()},
+		})
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+		_, err = client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{},
+		})
+		cerr, ok = codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+
+		client, group, member = setup(t, rbac.ActionCreate)
+		_, err = client.PutGroupMembers(ctx, group.ID, codersdk.PutGroupMembersRequest{
+			UserIDs: []string{member.ID.String()},
+		})
+		cerr, ok = codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusForbidden, cerr.StatusCode())
+		group, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+		require.Empty(t, group.Members)
+	})
+}
+
+func TestDeleteGroup(t *testing.T) {
 	t.Parallel()
 
 	t.Run("OK", func(t *testing.T) {
@@ -502,3 +1925,317 @@ func TestDeleteGroup(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
 	})
 }
+
+func TestRestoreGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+
+		// Only grant access through the group.
+		acl, err := client.TemplateACL(ctx, template.ID)
+		require.NoError(t, err)
+		err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+			GroupPerms: map[string]codersdk.TemplateRole{
+				acl.Groups[0].ID.String(): codersdk.TemplateRoleDeleted,
+				group.ID.String():         codersdk.TemplateRoleView,
+			},
+		})
+		require.NoError(t, err)
+
+		err = client.DeleteGroup(ctx, group.ID)
+		require.NoError(t, err)
+
+		// Deleted groups grant nothing and are hidden.
+		_, err = client1.Template(ctx, template.ID)
+		require.Error(t, err)
+		acl, err = client.TemplateACL(ctx, template.ID)
+		require.NoError(t, err)
+		require.Len(t, acl.Groups, 0)
+		groups, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Len(t, groups.Groups, 0)
+
+		restored, err := client.RestoreGroup(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, group.ID, restored.ID)
+		require.Len(t, restored.Members, 1)
+		require.Equal(t, user1.ID, restored.Members[0].ID)
+
+		_, err = client1.Template(ctx, template.ID)
+		require.NoError(t, err)
+		acl, err = client.TemplateACL(ctx, template.ID)
+		require.NoError(t, err)
+		require.Len(t, acl.Groups, 1)
+		require.Equal(t, group.ID, acl.Groups[0].ID)
+	})
+
+	t.Run("NotDeleted", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		_, err = client.RestoreGroup(ctx, group.ID)
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusNotFound, cerr.StatusCode())
+	})
+
+	t.Run("NameTaken", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		err = client.DeleteGroup(ctx, group.ID)
+		require.NoError(t, err)
+
+		// The name of a deleted group can be reused.
+		_, err = client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+
+		_, err = client.RestoreGroup(ctx, group.ID)
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusConflict, cerr.StatusCode())
+		require.Contains(t, cerr.Message, `"hi"`)
+	})
+}
+
+func TestDeletedGroupReaper(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		DeletedGroupRetention:    time.Nanosecond,
+		DeletedGroupReapInterval: testutil.IntervalFast,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "hi",
+	})
+	require.NoError(t, err)
+	err = client.DeleteGroup(ctx, group.ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := api.Database.GetGroupByID(ctx, group.ID)
+		return errors.Is(err, sql.ErrNoRows)
+	}, testutil.WaitShort, testutil.IntervalFast)
+}
+
+func TestGroupDeletionImpact(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		client2, _ := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "parent",
+		})
+		require.NoError(t, err)
+		child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "child",
+			ParentID: &group.ID,
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+		quotaAllowance := int32(5)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			QuotaAllowance: &quotaAllowance,
+		})
+		require.NoError(t, err)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		other := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+			GroupPerms: map[string]codersdk.TemplateRole{
+				group.ID.String(): codersdk.TemplateRoleView,
+			},
+		})
+		require.NoError(t, err)
+
+		// Only workspaces owned by members on templates the group grants
+		// access to are affected.
+		workspace := coderdtest.CreateWorkspace(t, client1, user.OrganizationID, template.ID)
+		_ = coderdtest.CreateWorkspace(t, client1, user.OrganizationID, other.ID)
+		_ = coderdtest.CreateWorkspace(t, client2, user.OrganizationID, template.ID)
+
+		impact, err := client.GroupDeletionImpact(ctx, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.GroupDeletionImpactTemplate{{
+			ID:   template.ID,
+			Name: template.Name,
+			Role: codersdk.TemplateRoleView,
+		}}, impact.Templates)
+		require.Equal(t, []codersdk.GroupDeletionImpactWorkspace{{
+			ID:         workspace.ID,
+			Name:       workspace.Name,
+			OwnerID:    user1.ID,
+			TemplateID: template.ID,
+		}}, impact.Workspaces)
+		require.Equal(t, []uuid.UUID{child.ID}, impact.ChildGroups)
+		require.Equal(t, quotaAllowance, impact.QuotaAllowance)
+		require.EqualValues(t, quotaAllowance, impact.QuotaAllowanceTotal)
+
+		// The group is left intact.
+		_, err = client.Group(ctx, group.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("allUsers", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		ctx, _ := testutil.Context(t)
+		_, err := client.GroupDeletionImpact(ctx, user.OrganizationID)
+		require.Error(t, err)
+		cerr, ok := codersdk.AsError(err)
+		require.True(t, ok)
+		require.Equal(t, http.StatusBadRequest, cerr.StatusCode())
+	})
+}
+
+func TestOrganizationMembersGroups(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+	_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "hi",
+	})
+	require.NoError(t, err)
+
+	_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+		AddUsers: []string{user2.ID.String()},
+	})
+	require.NoError(t, err)
+
+	res, err := client.OrganizationMembers(ctx, user.OrganizationID, codersdk.OrganizationMembersRequest{
+		GroupID: group.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Members, 1)
+	require.Equal(t, user2.ID, res.Members[0].UserID)
+	require.Equal(t, []codersdk.OrganizationMemberGroup{{ID: group.ID, Name: group.Name}}, res.Members[0].Groups)
+}
+
+func TestSearchGroups(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name:        "platform",
+		DisplayName: "Platform Team",
+	})
+	require.NoError(t, err)
+
+	results, err := client.Search(ctx, codersdk.SearchRequest{Query: "platform team"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, codersdk.SearchResultTypeGroup, results[0].Type)
+	require.Equal(t, group.ID, results[0].ID)
+	require.Equal(t, "Platform Team", results[0].DisplayName)
+	require.Equal(t, user.OrganizationID, results[0].OrganizationID)
+
+	// The "Everyone" group isn't a result.
+	results, err = client.Search(ctx, codersdk.SearchRequest{Query: "everyone"})
+	require.NoError(t, err)
+	require.Len(t, results, 0)
+}
+
+// groupMemberAuthorizer denies an action on group members to everyone, like
+// a role that can only add or only remove members.
+type groupMemberAuthorizer struct {
+	rbac.Authorizer
+	denied rbac.Action
+}
+
+func (a *groupMemberAuthorizer) ByRoleName(ctx context.Context, subjectID string, roleNames []string, scope rbac.Scope, groups []string, everyoneExcluded []string, action rbac.Action, object rbac.Object) error {
+	if object.Type == rbac.ResourceGroupMember.Type && action == a.denied {
+		return errors.New("denied")
+	}
+	return a.Authorizer.ByRoleName(ctx, subjectID, roleNames, scope, groups, everyoneExcluded, action, object)
+}
@@ -0,0 +1,362 @@
+package coderd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/groupsync"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+type groupSyncer struct {
+	logger slog.Logger
+}
+
+// NewGroupSyncer returns a syncer that mirrors identity provider groups into
+// every organization the user belongs to. Missing groups are created with
+// the OIDC source, and only groups with that source are ever modified.
+func NewGroupSyncer(logger slog.Logger) groupsync.Syncer {
+	return &groupSyncer{
+		logger: logger,
+	}
+}
+
+func (s *groupSyncer) SyncGroups(ctx context.Context, db database.Store, userID uuid.UUID, groupNames []string) error {
+	memberships, err := db.GetOrganizationIDsByMemberIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return xerrors.Errorf("get user organizations: %w", err)
+	}
+	var organizationIDs []uuid.UUID
+	if len(memberships) > 0 {
+		organizationIDs = memberships[0].OrganizationIDs
+	}
+
+	current, err := db.GetUserGroups(ctx, userID)
+	if err != nil {
+		return xerrors.Errorf("get user groups: %w", err)
+	}
+
+	removeIDs := make([]uuid.UUID, 0)
+	for _, organizationID := range organizationIDs {
+		groups, err := db.GetGroupsByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return xerrors.Errorf("get organization groups: %w", err)
+		}
+
+		plan := planGroupSync(groups, current, groupNames)
+		for _, group := range plan.skipped {
+			// Manually managed groups are never touched by the sync,
+			// even when the names collide.
+			s.logger.Warn(ctx, "skipping sync of manually managed group",
+				slog.F("group_id", group.ID),
+				slog.F("group_name", group.Name),
+			)
+		}
+		for _, name := range plan.create {
+			group, err := db.InsertGroup(ctx, database.InsertGroupParams{
+				ID:             uuid.New(),
+				Name:           name,
+				OrganizationID: uuid.NullUUID{UUID: organizationID, Valid: true},
+				Source:         database.GroupSourceOIDC,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert group %q: %w", name, err)
+			}
+			plan.add = append(plan.add, group)
+		}
+		for _, group := range plan.add {
+			err = db.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+				UserID:  userID,
+				GroupID: group.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("add user to group: %w", err)
+			}
+		}
+		for _, group := range plan.remove {
+			removeIDs = append(removeIDs, group.ID)
+		}
+	}
+
+	if len(removeIDs) > 0 {
+		_, err = db.DeleteUserFromGroups(ctx, database.DeleteUserFromGroupsParams{
+			UserID:   userID,
+			GroupIds: removeIDs,
+		})
+		if err != nil {
+			return xerrors.Errorf("remove user from groups: %w", err)
+		}
+	}
+	return nil
+}
+
+// groupSyncPlan is the set of changes a group sync makes in a single
+// organization.
+type groupSyncPlan struct {
+	// create lists the names of wanted groups that don't exist yet.
+	create []string
+	add    []database.Group
+	remove []database.Group
+	// skipped lists manually managed groups whose names match a wanted
+	// group.
+	skipped []database.Group
+}
+
+// planGroupSync compares the groups a user belongs to with the group names
+// reported by the identity provider. groups are all the groups of an
+// organization; current groups in other organizations are ignored.
+func planGroupSync(groups, current []database.Group, groupNames []string) groupSyncPlan {
+	wanted := make(map[string]struct{}, len(groupNames))
+	names := make([]string, 0, len(groupNames))
+	for _, name := range groupNames {
+		if _, ok := wanted[name]; ok || name == database.AllUsersGroup {
+			continue
+		}
+		wanted[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byName := make(map[string]database.Group, len(groups))
+	inOrganization := make(map[uuid.UUID]struct{}, len(groups))
+	for _, group := range groups {
+		byName[group.Name] = group
+		inOrganization[group.ID] = struct{}{}
+	}
+	currentIDs := make(map[uuid.UUID]struct{}, len(current))
+	for _, group := range current {
+		currentIDs[group.ID] = struct{}{}
+	}
+
+	var plan groupSyncPlan
+	wantedIDs := make(map[uuid.UUID]struct{}, len(names))
+	for _, name := range names {
+		group, ok := byName[name]
+		if !ok {
+			plan.create = append(plan.create, name)
+			continue
+		}
+		if group.Source != database.GroupSourceOIDC {
+			plan.skipped = append(plan.skipped, group)
+			continue
+		}
+		wantedIDs[group.ID] = struct{}{}
+		if _, ok := currentIDs[group.ID]; !ok {
+			plan.add = append(plan.add, group)
+		}
+	}
+	for _, group := range current {
+		if _, ok := inOrganization[group.ID]; !ok || group.Source != database.GroupSourceOIDC {
+			continue
+		}
+		if _, ok := wantedIDs[group.ID]; !ok {
+			plan.remove = append(plan.remove, group)
+		}
+	}
+	return plan
+}
+
+func (api *API) groupSyncDryRun(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	// Only users that can manage the organization's groups can see how
+	// they'd change.
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.GroupSyncDryRunRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var current []database.Group
+	if req.User != "" {
+		userIDs, _, err := api.resolveUserIdentifiers(ctx, []string{req.User})
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		userID, ok := userIDs[req.User]
+		if ok {
+			_, err = api.Database.GetUserByID(ctx, userID)
+			ok = err == nil
+		}
+		if !ok {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("User %q not found.", req.User),
+				Code:    codersdk.ErrorCodeValidationFailed,
+			})
+			return
+		}
+		current, err = api.Database.GetUserGroups(ctx, userID)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+
+	resp := codersdk.GroupSyncDryRunResponse{
+		Groups:  []string{},
+		Add:     []codersdk.GroupSyncChange{},
+		Remove:  []codersdk.GroupSyncChange{},
+		Skipped: []codersdk.GroupSyncChange{},
+	}
+	var groupNames []string
+	if api.AGPL.OIDCConfig != nil {
+		groupNames, resp.Enabled = api.AGPL.OIDCConfig.Groups(req.Claims)
+	}
+	if !resp.Enabled {
+		httpapi.Write(ctx, rw, http.StatusOK, resp)
+		return
+	}
+	resp.Groups = append(resp.Groups, groupNames...)
+
+	groups, err := api.Database.GetGroupsByOrganizationID(ctx, org.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	plan := planGroupSync(groups, current, groupNames)
+	for _, name := range plan.create {
+		resp.Add = append(resp.Add, codersdk.GroupSyncChange{Name: name})
+	}
+	for _, group := range plan.add {
+		resp.Add = append(resp.Add, convertGroupSyncChange(group))
+	}
+	for _, group := range plan.remove {
+		resp.Remove = append(resp.Remove, convertGroupSyncChange(group))
+	}
+	for _, group := range plan.skipped {
+		resp.Skipped = append(resp.Skipped, convertGroupSyncChange(group))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func convertGroupSyncChange(group database.Group) codersdk.GroupSyncChange {
+	id := group.ID
+	return codersdk.GroupSyncChange{
+		GroupID: &id,
+		Name:    group.Name,
+	}
+}
+
+// runGroupMetadataSync updates the display name and picture of groups synced
+// from the identity provider with those in its directory until ctx is
+// canceled.
+func (api *API) runGroupMetadataSync(ctx context.Context) {
+	ticker := time.NewTicker(api.GroupMetadataSyncInterval)
+	defer ticker.Stop()
+	for {
+		api.entitlementsMu.RLock()
+		enabled := api.entitlements.Features[codersdk.FeatureRBAC].Enabled
+		api.entitlementsMu.RUnlock()
+		if enabled {
+			err := api.syncGroupMetadata(ctx)
+			if err != nil && ctx.Err() == nil {
+				api.Logger.Warn(ctx, "failed to sync group metadata", slog.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (api *API) syncGroupMetadata(ctx context.Context) error {
+	organizations, err := api.Database.GetOrganizations(ctx)
+	if err != nil {
+		return xerrors.Errorf("get organizations: %w", err)
+	}
+	var groups []database.Group
+	for _, organization := range organizations {
+		organizationGroups, err := api.Database.GetGroupsByOrganizationID(ctx, organization.ID)
+		if err != nil {
+			return xerrors.Errorf("get organization groups: %w", err)
+		}
+		for _, group := range organizationGroups {
+			// Manually managed groups keep whatever was set in Coder.
+			if group.Source == database.GroupSourceOIDC {
+				groups = append(groups, group)
+			}
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	// Groups are looked up by the name the identity provider knows them
+	// by, which differs from the Coder name if they were mapped.
+	idpNames := make(map[string]string)
+	if api.AGPL.OIDCConfig != nil {
+		for idpName, name := range api.AGPL.OIDCConfig.GroupMapping {
+			if existing, ok := idpNames[name]; !ok || idpName < existing {
+				idpNames[name] = idpName
+			}
+		}
+	}
+	idpName := func(group database.Group) string {
+		if name, ok := idpNames[group.Name]; ok {
+			return name
+		}
+		return group.Name
+	}
+	names := make([]string, 0, len(groups))
+	seen := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		name := idpName(group)
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+
+	metadata, err := api.GroupMetadataFetcher.Fetch(ctx, names)
+	if err != nil {
+		return xerrors.Errorf("fetch group metadata: %w", err)
+	}
+	for _, group := range groups {
+		md, ok := metadata[idpName(group)]
+		if !ok || (md.DisplayName == group.DisplayName && md.AvatarURL == group.AvatarURL) {
+			continue
+		}
+		_, err = api.Database.UpdateGroupByID(ctx, database.UpdateGroupByIDParams{
+			ID:               group.ID,
+			Name:             group.Name,
+			ParentID:         group.ParentID,
+			DisplayName:      md.DisplayName,
+			AvatarURL:        md.AvatarURL,
+			Description:      group.Description,
+			Metadata:         group.Metadata,
+			AutostopSchedule: group.AutostopSchedule,
+			MaxTtl:           group.MaxTtl,
+			QuotaAllowance:   group.QuotaAllowance,
+			Roles:            group.Roles,
+		})
+		if err != nil {
+			return xerrors.Errorf("update group %q: %w", group.Name, err)
+		}
+	}
+	return nil
+}
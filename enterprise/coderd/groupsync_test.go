@@ -0,0 +1,249 @@
+package coderd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	agplcoderd "github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/groupmetadata"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGroupSyncer(t *testing.T) {
+	t.Parallel()
+
+	ctx, _ := testutil.Context(t)
+	db := databasefake.New()
+	org, err := db.InsertOrganization(ctx, database.InsertOrganizationParams{
+		ID:        uuid.New(),
+		Name:      "org",
+		CreatedAt: database.Now(),
+		UpdatedAt: database.Now(),
+	})
+	require.NoError(t, err)
+	user, err := db.InsertUser(ctx, database.InsertUserParams{
+		ID:        uuid.New(),
+		Email:     "kyle@coder.com",
+		Username:  "kyle",
+		LoginType: database.LoginTypeOIDC,
+		CreatedAt: database.Now(),
+		UpdatedAt: database.Now(),
+	})
+	require.NoError(t, err)
+	_, err = db.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		CreatedAt:      database.Now(),
+		UpdatedAt:      database.Now(),
+		Roles:          []string{},
+	})
+	require.NoError(t, err)
+	manual, err := db.InsertGroup(ctx, database.InsertGroupParams{
+		ID:             uuid.New(),
+		Name:           "manual",
+		OrganizationID: uuid.NullUUID{UUID: org.ID, Valid: true},
+		Source:         database.GroupSourceUser,
+	})
+	require.NoError(t, err)
+
+	groupNames := func() map[string]database.GroupSource {
+		groups, err := db.GetUserGroups(ctx, user.ID)
+		require.NoError(t, err)
+		names := make(map[string]database.GroupSource, len(groups))
+		for _, group := range groups {
+			names[group.Name] = group.Source
+		}
+		return names
+	}
+
+	syncer := coderd.NewGroupSyncer(slogtest.Make(t, nil))
+
+	// Missing groups are created, and manual groups are left alone even
+	// when the names match.
+	err = syncer.SyncGroups(ctx, db, user.ID, []string{"admins", "devs", "manual"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]database.GroupSource{
+		"admins": database.GroupSourceOIDC,
+		"devs":   database.GroupSourceOIDC,
+	}, groupNames())
+
+	// Manual memberships survive a sync that drops groups.
+	err = db.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+		UserID:  user.ID,
+		GroupID: manual.ID,
+	})
+	require.NoError(t, err)
+	err = syncer.SyncGroups(ctx, db, user.ID, []string{"devs"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]database.GroupSource{
+		"devs":   database.GroupSourceOIDC,
+		"manual": database.GroupSourceUser,
+	}, groupNames())
+
+	groups, err := db.GetGroupsByOrganizationID(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 3, "removed groups are kept")
+}
+
+func TestGroupSyncDryRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				OIDCConfig: &agplcoderd.OIDCConfig{
+					GroupField: "groups",
+					GroupMapping: map[string]string{
+						"idp-devs": "devs",
+					},
+				},
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		manual, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "manual",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, manual.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+
+		resp, err := client.GroupSyncDryRun(ctx, user.OrganizationID, codersdk.GroupSyncDryRunRequest{
+			User: user1.Username,
+			Claims: map[string]interface{}{
+				"groups": []string{"idp-devs", "manual"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Enabled)
+		require.Equal(t, []string{"devs", "manual"}, resp.Groups)
+		require.Equal(t, []codersdk.GroupSyncChange{{Name: "devs"}}, resp.Add)
+		require.Len(t, resp.Remove, 0)
+		require.Equal(t, []codersdk.GroupSyncChange{{GroupID: &manual.ID, Name: "manual"}}, resp.Skipped)
+
+		// Nothing was changed.
+		groups, err := client.GroupsByOrganization(ctx, user.OrganizationID, codersdk.GroupsRequest{})
+		require.NoError(t, err)
+		require.Len(t, groups.Groups, 1)
+	})
+
+	t.Run("MissingClaim", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				OIDCConfig: &agplcoderd.OIDCConfig{
+					GroupField: "groups",
+				},
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		resp, err := client.GroupSyncDryRun(ctx, user.OrganizationID, codersdk.GroupSyncDryRunRequest{
+			Claims: map[string]interface{}{
+				"email": "kyle@coder.com",
+			},
+		})
+		require.NoError(t, err)
+		require.False(t, resp.Enabled)
+		require.Len(t, resp.Add, 0)
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.GroupSyncDryRun(ctx, user.OrganizationID, codersdk.GroupSyncDryRunRequest{
+			User: "nobody",
+		})
+		require.Error(t, err)
+	})
+}
+
+type fakeGroupMetadataFetcher map[string]groupmetadata.Metadata
+
+func (f fakeGroupMetadataFetcher) Fetch(_ context.Context, names []string) (map[string]groupmetadata.Metadata, error) {
+	metadata := make(map[string]groupmetadata.Metadata)
+	for _, name := range names {
+		if md, ok := f[name]; ok {
+			metadata[name] = md
+		}
+	}
+	return metadata, nil
+}
+
+func TestGroupMetadataSync(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			OIDCConfig: &agplcoderd.OIDCConfig{
+				GroupField: "groups",
+				GroupMapping: map[string]string{
+					"idp-devs": "devs",
+				},
+			},
+		},
+		GroupMetadataFetcher: fakeGroupMetadataFetcher{
+			"idp-devs": {DisplayName: "Developers", AvatarURL: "https://example.com/devs.png"},
+			"manual":   {DisplayName: "Manual"},
+		},
+		GroupMetadataSyncInterval: testutil.IntervalFast,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	ctx, _ := testutil.Context(t)
+	synced, err := api.Database.InsertGroup(ctx, database.InsertGroupParams{
+		ID:             uuid.New(),
+		Name:           "devs",
+		OrganizationID: uuid.NullUUID{UUID: user.OrganizationID, Valid: true},
+		Source:         database.GroupSourceOIDC,
+	})
+	require.NoError(t, err)
+	manual, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "manual",
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		group, err := client.Group(ctx, synced.ID)
+		return err == nil && group.DisplayName == "Developers" && group.AvatarURL == "https://example.com/devs.png"
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// Manually managed groups are left alone.
+	manual, err = client.Group(ctx, manual.ID)
+	require.NoError(t, err)
+	require.Empty(t, manual.DisplayName)
+}
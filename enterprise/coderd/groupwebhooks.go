@@ -0,0 +1,259 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhookdelivery"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+func (api *API) groupWebhooks(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	webhooks, err := api.Database.GetGroupWebhooksByOrganizationID(ctx, org.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := make([]codersdk.GroupWebhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, convertGroupWebhook(webhook))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) postGroupWebhook(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateGroupWebhookRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Webhook URL must be an absolute http or https URL.",
+			Validations: []codersdk.ValidationError{
+				{Field: "url", Detail: fmt.Sprintf("invalid webhook URL %q", req.URL)},
+			},
+			Code: codersdk.ErrorCodeValidationFailed,
+		})
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = cryptorand.HexString(32)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+
+	webhook, err := api.Database.InsertGroupWebhook(ctx, database.InsertGroupWebhookParams{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Url:            req.URL,
+		Secret:         secret,
+		CreatedAt:      database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := convertGroupWebhook(webhook)
+	// The secret is only ever returned here so it can be stored by the
+	// receiving end.
+	resp.Secret = webhook.Secret
+	httpapi.Write(ctx, rw, http.StatusCreated, resp)
+}
+
+func (api *API) deleteGroupWebhook(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceGroup.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "webhook"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid webhook ID.",
+			Detail:  err.Error(),
+			Code:    codersdk.ErrorCodeValidationFailed,
+		})
+		return
+	}
+
+	webhook, err := api.Database.GetGroupWebhookByID(ctx, id)
+	if xerrors.Is(err, sql.ErrNoRows) || (err == nil && webhook.OrganizationID != org.ID) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	err = api.Database.DeleteGroupWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully deleted group webhook!",
+	})
+}
+
+// publishGroupEvent delivers the event to every webhook of the group's
+// organization in the background. Deployment-wide groups don't belong to an
+// organization, so changes to them only reach deployment-wide webhooks.
+func (api *API) publishGroupEvent(group database.Group, event codersdk.GroupWebhookEvent) {
+	switch event.Type {
+	case codersdk.GroupWebhookEventGroupCreated:
+		api.AGPL.PublishWebhookEvent(codersdk.WebhookEvent{
+			Type:           codersdk.WebhookEventGroupCreated,
+			OrganizationID: group.OrganizationID.UUID,
+			ResourceID:     group.ID,
+			ResourceName:   group.Name,
+		})
+	case codersdk.GroupWebhookEventGroupDeleted:
+		api.AGPL.PublishWebhookEvent(codersdk.WebhookEvent{
+			Type:           codersdk.WebhookEventGroupDeleted,
+			OrganizationID: group.OrganizationID.UUID,
+			ResourceID:     group.ID,
+			ResourceName:   group.Name,
+		})
+	}
+	if !group.OrganizationID.Valid {
+		return
+	}
+	event.ID = uuid.New()
+	event.CreatedAt = database.Now()
+	event.OrganizationID = group.OrganizationID.UUID
+	event.GroupID = group.ID
+	event.GroupName = group.Name
+	body, err := json.Marshal(event)
+	if err != nil {
+		api.Logger.Error(context.Background(), "marshal group webhook event", slog.Error(err))
+		return
+	}
+
+	api.AGPL.WebhookEngine.Publish(webhookdelivery.KindGroup, webhookdelivery.Event{
+		ID:      event.ID,
+		Type:    string(event.Type),
+		Payload: body,
+	}, func(ctx context.Context) ([]uuid.UUID, error) {
+		webhooks, err := api.Database.GetGroupWebhooksByOrganizationID(ctx, event.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uuid.UUID, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			ids = append(ids, webhook.ID)
+		}
+		return ids, nil
+	})
+}
+
+// groupWebhookHandler delivers events to group webhooks.
+func (api *API) groupWebhookHandler() webhookdelivery.Handler {
+	return webhookdelivery.Handler{
+		Webhook: func(ctx context.Context, id uuid.UUID) (webhookdelivery.Webhook, error) {
+			webhook, err := api.Database.GetGroupWebhookByID(ctx, id)
+			if err != nil {
+				return webhookdelivery.Webhook{}, err
+			}
+			return webhookdelivery.Webhook{URL: webhook.Url, Secret: webhook.Secret}, nil
+		},
+		RetryInterval: api.GroupWebhookRetryInterval,
+	}
+}
+
+// publishGroupMembersEvents notifies webhooks of members added to or removed
+// from the group.
+func (api *API) publishGroupMembersEvents(group database.Group, added, removed []uuid.UUID) {
+	if len(added) > 0 {
+		api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+			Type:    codersdk.GroupWebhookEventMembersAdded,
+			UserIDs: added,
+		})
+	}
+	if len(removed) > 0 {
+		api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+			Type:    codersdk.GroupWebhookEventMembersRemoved,
+			UserIDs: removed,
+		})
+	}
+}
+
+// diffGroupMembers returns the IDs of the users that were added and removed
+// between two snapshots of a group's members.
+func diffGroupMembers(before, after []database.User) (added, removed []uuid.UUID) {
+	previous := make(map[uuid.UUID]struct{}, len(before))
+	for _, user := range before {
+		previous[user.ID] = struct{}{}
+	}
+	for _, user := range after {
+		if _, ok := previous[user.ID]; ok {
+			delete(previous, user.ID)
+			continue
+		}
+		added = append(added, user.ID)
+	}
+	for _, user := range before {
+		if _, ok := previous[user.ID]; ok {
+			removed = append(removed, user.ID)
+		}
+	}
+	return added, removed
+}
+
+func convertGroupWebhook(webhook database.GroupWebhook) codersdk.GroupWebhook {
+	return codersdk.GroupWebhook{
+		ID:             webhook.ID,
+		OrganizationID: webhook.OrganizationID,
+		URL:            webhook.Url,
+		CreatedAt:      webhook.CreatedAt,
+	}
+}
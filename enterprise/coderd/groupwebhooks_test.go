@@ -0,0 +1,210 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/webhookdelivery/webhookdeliverytest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGroupWebhooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.GroupWebhookEvent](t, "secret", nil)
+		webhook, err := client.CreateGroupWebhook(ctx, user.OrganizationID, codersdk.CreateGroupWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "secret", webhook.Secret)
+
+		webhooks, err := client.GroupWebhooks(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		require.Equal(t, webhook.ID, webhooks[0].ID)
+		require.Empty(t, webhooks[0].Secret)
+
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.GroupWebhookEventGroupCreated, event.Type)
+		require.Equal(t, group.ID, event.GroupID)
+		require.Equal(t, user.OrganizationID, event.OrganizationID)
+
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.GroupWebhookEventMembersAdded, event.Type)
+		require.Equal(t, []uuid.UUID{user1.ID}, event.UserIDs)
+
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			Name:        "bye",
+			RemoveUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+		seen := map[codersdk.GroupWebhookEventType]codersdk.GroupWebhookEvent{}
+		for i := 0; i < 2; i++ {
+			event = webhookdeliverytest.AwaitEvent(t, events)
+			seen[event.Type] = event
+		}
+		require.Equal(t, "hi", seen[codersdk.GroupWebhookEventGroupRenamed].PreviousGroupName)
+		require.Equal(t, "bye", seen[codersdk.GroupWebhookEventGroupRenamed].GroupName)
+		require.Equal(t, []uuid.UUID{user1.ID}, seen[codersdk.GroupWebhookEventMembersRemoved].UserIDs)
+
+		err = client.DeleteGroup(ctx, group.ID)
+		require.NoError(t, err)
+		event = webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.GroupWebhookEventGroupDeleted, event.Type)
+
+		err = client.DeleteGroupWebhook(ctx, user.OrganizationID, webhook.ID)
+		require.NoError(t, err)
+		webhooks, err = client.GroupWebhooks(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 0)
+	})
+
+	t.Run("DeploymentWebhook", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		events := make(chan codersdk.WebhookEvent, 16)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var event codersdk.WebhookEvent
+			if assert.NoError(t, json.NewDecoder(r.Body).Decode(&event)) {
+				events <- event
+			}
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			URL: srv.URL,
+			Events: []codersdk.WebhookEventType{
+				codersdk.WebhookEventGroupCreated,
+				codersdk.WebhookEventGroupDeleted,
+			},
+		})
+		require.NoError(t, err)
+
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		err = client.DeleteGroup(ctx, group.ID)
+		require.NoError(t, err)
+
+		// Events are delivered concurrently, so they may arrive in any order.
+		seen := map[codersdk.WebhookEventType]bool{}
+		for i := 0; i < 2; i++ {
+			select {
+			case event := <-events:
+				require.Equal(t, group.ID, event.ResourceID)
+				require.Equal(t, "hi", event.ResourceName)
+				seen[event.Type] = true
+			case <-time.After(testutil.WaitShort):
+				t.Fatal("timed out waiting for webhook event")
+			}
+		}
+		require.True(t, seen[codersdk.WebhookEventGroupCreated])
+		require.True(t, seen[codersdk.WebhookEventGroupDeleted])
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			GroupWebhookRetryInterval: time.Millisecond,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		url, events := webhookdeliverytest.NewReceiver[codersdk.GroupWebhookEvent](t, "secret", func(attempt int64, rw http.ResponseWriter) bool {
+			if attempt < 3 {
+				rw.WriteHeader(http.StatusInternalServerError)
+				return false
+			}
+			return true
+		})
+		_, err := client.CreateGroupWebhook(ctx, user.OrganizationID, codersdk.CreateGroupWebhookRequest{
+			URL:    url,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+
+		_, err = client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "hi",
+		})
+		require.NoError(t, err)
+		event := webhookdeliverytest.AwaitEvent(t, events)
+		require.Equal(t, codersdk.GroupWebhookEventGroupCreated, event.Type)
+	})
+
+	t.Run("GeneratedSecret", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		webhook, err := client.CreateGroupWebhook(ctx, user.OrganizationID, codersdk.CreateGroupWebhookRequest{
+			URL: "https://example.com/hook",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, webhook.Secret)
+	})
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateGroupWebhook(ctx, user.OrganizationID, codersdk.CreateGroupWebhookRequest{
+			URL: "ftp://example.com/hook",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
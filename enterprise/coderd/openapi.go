@@ -0,0 +1,180 @@
+package coderd
+
+import (
+	"net/http"
+
+	"github.com/coder/coder/coderd/openapi"
+	"github.com/coder/coder/codersdk"
+)
+
+// openAPISpecs describes the payloads of enterprise routes.
+func openAPISpecs() map[string]openapi.Spec {
+	return map[string]openapi.Spec{
+		openapi.Key(http.MethodGet, "/entitlements"): {
+			Summary:  "Get entitlements",
+			Response: codersdk.Entitlements{},
+		},
+		openapi.Key(http.MethodGet, "/licenses"): {
+			Summary:  "List licenses",
+			Response: []codersdk.License{},
+		},
+		openapi.Key(http.MethodPost, "/licenses"): {
+			Summary:  "Add a license",
+			Request:  codersdk.AddLicenseRequest{},
+			Response: codersdk.License{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/groups"): {
+			Summary:  "List groups of an organization",
+			Response: codersdk.GroupsResponse{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/groups"): {
+			Summary:  "Create a group",
+			Request:  codersdk.CreateGroupRequest{},
+			Response: codersdk.Group{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/groups/{groupname}"): {
+			Summary:  "Get a group by name",
+			Response: codersdk.Group{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/group-webhooks"): {
+			Summary:  "List group webhooks of an organization",
+			Response: []codersdk.GroupWebhook{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/group-webhooks"): {
+			Summary:  "Create a group webhook",
+			Request:  codersdk.CreateGroupWebhookRequest{},
+			Response: codersdk.GroupWebhook{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodDelete, "/organizations/{organization}/group-webhooks/{webhook}"): {
+			Summary:  "Delete a group webhook",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/everyone-exclusions"): {
+			Summary:  "List the members excluded from the Everyone group",
+			Response: []codersdk.EveryoneGroupExclusion{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/everyone-exclusions/{user}"): {
+			Summary:  "Exclude a member from the Everyone group",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodDelete, "/organizations/{organization}/everyone-exclusions/{user}"): {
+			Summary:  "Include a member in the Everyone group again",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodPost, "/organizations/{organization}/groups/sync/dry-run"): {
+			Summary:  "Preview the group changes a login would make",
+			Request:  codersdk.GroupSyncDryRunRequest{},
+			Response: codersdk.GroupSyncDryRunResponse{},
+		},
+		openapi.Key(http.MethodGet, "/groups"): {
+			Summary:  "List deployment-wide groups",
+			Response: codersdk.GroupsResponse{},
+		},
+		openapi.Key(http.MethodPost, "/groups"): {
+			Summary:  "Create a deployment-wide group",
+			Request:  codersdk.CreateGroupRequest{},
+			Response: codersdk.Group{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/groups/{group}"): {
+			Summary:  "Get a group",
+			Response: codersdk.Group{},
+		},
+		openapi.Key(http.MethodPatch, "/groups/{group}"): {
+			Summary:  "Update a group",
+			Request:  codersdk.PatchGroupRequest{},
+			Response: codersdk.Group{},
+		},
+		openapi.Key(http.MethodDelete, "/groups/{group}"): {
+			Summary:  "Delete a group",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/groups/{group}/deletion-impact"): {
+			Summary:  "Report what deleting a group would affect",
+			Response: codersdk.GroupDeletionImpact{},
+		},
+		openapi.Key(http.MethodGet, "/groups/{group}/templates"): {
+			Summary:  "List the templates a group has a role on",
+			Response: []codersdk.GroupTemplate{},
+		},
+		openapi.Key(http.MethodPost, "/groups/{group}/restore"): {
+			Summary:  "Restore a deleted group",
+			Response: codersdk.Group{},
+		},
+		openapi.Key(http.MethodGet, "/groups/{group}/members"): {
+			Summary:  "List the members of a group",
+			Response: codersdk.GroupMembersResponse{},
+		},
+		openapi.Key(http.MethodPut, "/groups/{group}/members"): {
+			Summary:  "Replace the members of a group",
+			Request:  codersdk.PutGroupMembersRequest{},
+			Response: codersdk.PutGroupMembersResponse{},
+		},
+		openapi.Key(http.MethodPost, "/groups/{group}/members/import"): {
+			Summary:  "Add the users listed in a CSV file to a group",
+			Response: codersdk.ImportGroupMembersResponse{},
+		},
+		openapi.Key(http.MethodGet, "/groups/{group}/members/export"): {
+			Summary: "Export the members of a group as CSV",
+		},
+		openapi.Key(http.MethodPut, "/groups/{group}/members/{user}/roles"): {
+			Summary:  "Assign roles to a member of a group",
+			Request:  codersdk.UpdateRoles{},
+			Response: codersdk.GroupMember{},
+		},
+		openapi.Key(http.MethodPost, "/groups/{group}/join-requests"): {
+			Summary:  "Request to join a group",
+			Response: codersdk.GroupJoinRequest{},
+		},
+		openapi.Key(http.MethodGet, "/groups/{group}/join-requests"): {
+			Summary:  "List pending requests to join a group",
+			Response: []codersdk.GroupJoinRequest{},
+		},
+		openapi.Key(http.MethodPost, "/groups/{group}/join-requests/{request}/approve"): {
+			Summary:  "Approve a request to join a group",
+			Response: codersdk.Group{},
+		},
+		openapi.Key(http.MethodPost, "/groups/{group}/join-requests/{request}/deny"): {
+			Summary:  "Deny a request to join a group",
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/acl"): {
+			Summary:  "Get template access control",
+			Response: codersdk.TemplateACL{},
+		},
+		openapi.Key(http.MethodPatch, "/templates/{template}/acl"): {
+			Summary:  "Update template access control",
+			Request:  codersdk.UpdateTemplateACL{},
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/templates/{template}/effective-access"): {
+			Summary:  "Get the users a template's access control grants a role, with groups expanded",
+			Response: codersdk.TemplateEffectiveAccess{},
+		},
+		openapi.Key(http.MethodGet, "/organizations/{organization}/quota"): {
+			Summary:  "Get the quota of an organization and its consumption",
+			Response: codersdk.OrganizationQuotaConsumption{},
+		},
+		openapi.Key(http.MethodPut, "/organizations/{organization}/quota"): {
+			Summary:  "Set the quota of an organization",
+			Request:  codersdk.OrganizationQuota{},
+			Response: codersdk.OrganizationQuota{},
+		},
+		openapi.Key(http.MethodPatch, "/templates/{template}/quota"): {
+			Summary:  "Update the quota weight of a template",
+			Request:  codersdk.UpdateTemplateQuotaRequest{},
+			Response: codersdk.Response{},
+		},
+		openapi.Key(http.MethodGet, "/users/{user}/groups"): {
+			Summary:  "List groups a user belongs to",
+			Response: []codersdk.Group{},
+		},
+		openapi.Key(http.MethodGet, "/workspace-quota/{user}"): {
+			Summary:  "Get workspace quota of a user",
+			Response: codersdk.WorkspaceQuota{},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package coderd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/proto"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRPCGroups(t *testing.T) {
+	t.Parallel()
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "developers",
+	})
+	require.NoError(t, err)
+	_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+		AddUsers: []string{user.UserID.String()},
+	})
+	require.NoError(t, err)
+
+	rpc, err := client.DialRPC(ctx)
+	require.NoError(t, err)
+	defer rpc.DRPCConn().Close()
+
+	groups, err := rpc.ListGroups(ctx, &proto.ListGroupsRequest{OrganizationId: user.OrganizationID.String()})
+	require.NoError(t, err)
+	// The organization's "Everyone" group is listed too.
+	var found *proto.Group
+	for _, g := range groups.Groups {
+		if g.Id == group.ID.String() {
+			found = g
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, "developers", found.Name)
+	require.Equal(t, user.OrganizationID.String(), found.OrganizationId)
+
+	groups, err = rpc.ListGroups(ctx, &proto.ListGroupsRequest{
+		OrganizationId: user.OrganizationID.String(),
+		Query:          "develop",
+	})
+	require.NoError(t, err)
+	require.Len(t, groups.Groups, 1)
+	require.Empty(t, groups.NextCursor)
+
+	fetched, err := rpc.GetGroup(ctx, &proto.GetGroupRequest{Id: group.ID.String()})
+	require.NoError(t, err)
+	require.Equal(t, []string{user.UserID.String()}, fetched.MemberIds)
+}
@@ -1,9 +1,14 @@
 package coderd
 
 import (
+	"context"
 	"crypto/subtle"
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -11,10 +16,13 @@ import (
 	scimjson "github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/service"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
 
 	agpl "github.com/coder/coder/coderd"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/util/slice"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -137,6 +145,8 @@ func (api *API) scimPostUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	api.AGPL.PublishUserWebhookEvent(codersdk.WebhookEventUserCreated, user)
+
 	sUser.ID = user.ID.String()
 	sUser.UserName = user.Username
 
@@ -173,14 +183,19 @@ func (api *API) scimPatchUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var status database.UserStatus
+	var (
+		status    database.UserStatus
+		eventType codersdk.WebhookEventType
+	)
 	if sUser.Active {
 		status = database.UserStatusActive
+		eventType = codersdk.WebhookEventUserActivated
 	} else {
 		status = database.UserStatusSuspended
+		eventType = codersdk.WebhookEventUserSuspended
 	}
 
-	_, err = api.Database.UpdateUserStatus(r.Context(), database.UpdateUserStatusParams{
+	updatedUser, err := api.Database.UpdateUserStatus(r.Context(), database.UpdateUserStatusParams{
 		ID:        dbUser.ID,
 		Status:    status,
 		UpdatedAt: database.Now(),
@@ -189,6 +204,635 @@ func (api *API) scimPatchUser(rw http.ResponseWriter, r *http.Request) {
 		_ = handlerutil.WriteError(rw, err)
 		return
 	}
+	if dbUser.Status != status {
+		api.AGPL.PublishUserWebhookEvent(eventType, updatedUser)
+	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, sUser)
 }
+
+// SCIMGroup is the subset of the SCIM group schema that identity providers
+// send when pushing groups. Groups are created in the SCIM organization and
+// are managed exclusively by the identity provider.
+type SCIMGroup struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMGroupMember `json:"members,omitempty"`
+	Meta        struct {
+		ResourceType string `json:"resourceType"`
+	} `json:"meta"`
+}
+
+type SCIMGroupMember struct {
+	// Value is the ID of the member as returned by the Users resource.
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMPatchOp is a SCIM PATCH request body.
+type SCIMPatchOp struct {
+	Schemas    []string               `json:"schemas"`
+	Operations []SCIMPatchOpOperation `json:"Operations"`
+}
+
+type SCIMPatchOpOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// SCIMListResponse is a page of groups.
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    []SCIMGroup `json:"Resources"`
+}
+
+const (
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+)
+
+var (
+	// scimMemberFilter matches the path Okta sends when removing a single
+	// member, e.g. `members[value eq "<id>"]`.
+	scimMemberFilter = regexp.MustCompile(`^members\[value eq "([^"]+)"\]$`)
+	// scimDisplayNameFilter matches the filter identity providers send to
+	// look up a group before pushing it, e.g. `displayName eq "<name>"`.
+	scimDisplayNameFilter = regexp.MustCompile(`^(?i:displayName\s+eq)\s+"([^"]*)"$`)
+)
+
+// scimGetGroups lists the groups managed by the identity provider. Only the
+// `displayName eq` filter is supported, which is what identity providers use
+// to find a group before pushing it.
+func (api *API) scimGetGroups(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}))
+		return
+	}
+
+	query := r.URL.Query()
+	startIndex, count := 1, -1
+	if v := query.Get("startIndex"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+			return
+		}
+		// Values below one are interpreted as one.
+		if i > 1 {
+			startIndex = i
+		}
+	}
+	if v := query.Get("count"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+			return
+		}
+		count = i
+		if count < 0 {
+			count = 0
+		}
+	}
+
+	organization, err := api.scimOrganization(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	var groups []database.Group
+	if filter := query.Get("filter"); filter != "" {
+		match := scimDisplayNameFilter.FindStringSubmatch(filter)
+		if match == nil {
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidFilter))
+			return
+		}
+		group, err := api.Database.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+			OrganizationID: uuid.NullUUID{UUID: organization.ID, Valid: true},
+			Name:           match[1],
+		})
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		if err == nil {
+			groups = append(groups, group)
+		}
+	} else {
+		groups, err = api.Database.GetGroupsByOrganizationID(ctx, organization.ID)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+	}
+	managed := make([]database.Group, 0, len(groups))
+	for _, group := range groups {
+		if group.Source == database.GroupSourceOIDC {
+			managed = append(managed, group)
+		}
+	}
+	slices.SortFunc(managed, func(a, b database.Group) bool {
+		return a.Name < b.Name
+	})
+
+	resp := SCIMListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(managed),
+		StartIndex:   startIndex,
+		Resources:    []SCIMGroup{},
+	}
+	page := managed[:0]
+	if startIndex <= len(managed) {
+		page = managed[startIndex-1:]
+	}
+	if count >= 0 && len(page) > count {
+		page = page[:count]
+	}
+	excludeMembers := strings.Contains(strings.ToLower(query.Get("excludedAttributes")), "members")
+	for _, group := range page {
+		var sGroup SCIMGroup
+		if excludeMembers {
+			sGroup = newSCIMGroup(group)
+		} else {
+			sGroup, err = api.convertSCIMGroup(ctx, group)
+			if err != nil {
+				_ = handlerutil.WriteError(rw, err)
+				return
+			}
+		}
+		resp.Resources = append(resp.Resources, sGroup)
+	}
+	resp.ItemsPerPage = len(resp.Resources)
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func (api *API) scimGetGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}))
+		return
+	}
+
+	group, ok := api.scimGroupParam(rw, r)
+	if !ok {
+		return
+	}
+	sGroup, err := api.convertSCIMGroup(ctx, group)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sGroup)
+}
+
+// scimPostGroup creates a group managed by the identity provider. Pushing a
+// group that already exists replaces its members, so identity providers can
+// retry pushes.
+func (api *API) scimPostGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}))
+		return
+	}
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	if sGroup.DisplayName == "" || sGroup.DisplayName == database.AllUsersGroup {
+		_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+		return
+	}
+
+	organization, err := api.scimOrganization(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	organizationID := organization.ID
+
+	memberIDs, ok := api.scimGroupMemberIDs(rw, r, organizationID, sGroup.Members)
+	if !ok {
+		return
+	}
+
+	existing, err := api.Database.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+		OrganizationID: uuid.NullUUID{UUID: organizationID, Valid: true},
+		Name:           sGroup.DisplayName,
+	})
+	if err == nil {
+		if existing.Source != database.GroupSourceOIDC {
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrUniqueness))
+			return
+		}
+		api.scimUpdateGroup(rw, r, existing, func(tx database.Store) error {
+			return scimReplaceGroupMembers(ctx, tx, existing.ID, memberIDs)
+		})
+		return
+	}
+	if !xerrors.Is(err, sql.ErrNoRows) {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	var group database.Group
+	err = api.Database.InTx(func(tx database.Store) error {
+		group, err = tx.InsertGroup(ctx, database.InsertGroupParams{
+			ID:             uuid.New(),
+			Name:           sGroup.DisplayName,
+			OrganizationID: uuid.NullUUID{UUID: organizationID, Valid: true},
+			Source:         database.GroupSourceOIDC,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tx.InsertGroupMembers(ctx, database.InsertGroupMembersParams{
+			UserIds: memberIDs,
+			GroupID: group.ID,
+		})
+		return err
+	})
+	if database.IsUniqueViolation(err) {
+		_ = handlerutil.WriteError(rw, scimError(spec.ErrUniqueness))
+		return
+	}
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+		Type: codersdk.GroupWebhookEventGroupCreated,
+	})
+	api.publishGroupMembersEvents(group, memberIDs, nil)
+
+	sGroup, err = api.convertSCIMGroup(ctx, group)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusCreated, sGroup)
+}
+
+// scimPutGroup replaces the name and members of a group.
+func (api *API) scimPutGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}))
+		return
+	}
+
+	group, ok := api.scimGroupParam(rw, r)
+	if !ok {
+		return
+	}
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	if sGroup.DisplayName == "" || sGroup.DisplayName == database.AllUsersGroup {
+		_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+		return
+	}
+	memberIDs, ok := api.scimGroupMemberIDs(rw, r, group.OrganizationID.UUID, sGroup.Members)
+	if !ok {
+		return
+	}
+
+	group.Name = sGroup.DisplayName
+	api.scimUpdateGroup(rw, r, group, func(tx database.Store) error {
+		return scimReplaceGroupMembers(ctx, tx, group.ID, memberIDs)
+	})
+}
+
+// scimPatchGroup renames a group and adds, removes, or replaces its members.
+func (api *API) scimPatchGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}))
+		return
+	}
+
+	group, ok := api.scimGroupParam(rw, r)
+	if !ok {
+		return
+	}
+
+	var patch SCIMPatchOp
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	// Operations are validated up front and applied together so a bad
+	// operation doesn't leave the group half updated.
+	var changes []func(tx database.Store) error
+	for _, operation := range patch.Operations {
+		op := strings.ToLower(operation.Op)
+		path := operation.Path
+
+		switch {
+		case op == "replace" && (path == "" || path == "displayName"):
+			var name string
+			if path == "" {
+				var value SCIMGroup
+				err = json.Unmarshal(operation.Value, &value)
+				name = value.DisplayName
+			} else {
+				err = json.Unmarshal(operation.Value, &name)
+			}
+			if err != nil || name == "" || name == database.AllUsersGroup {
+				_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+				return
+			}
+			group.Name = name
+		case path == "members" && (op == "add" || op == "remove" || op == "replace"):
+			var members []SCIMGroupMember
+			err = json.Unmarshal(operation.Value, &members)
+			if err != nil {
+				_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+				return
+			}
+			memberIDs, ok := api.scimGroupMemberIDs(rw, r, group.OrganizationID.UUID, members)
+			if !ok {
+				return
+			}
+			changes = append(changes, scimGroupMembersChange(ctx, op, group.ID, memberIDs))
+		case op == "remove" && scimMemberFilter.MatchString(path):
+			memberID, err := uuid.Parse(scimMemberFilter.FindStringSubmatch(path)[1])
+			if err != nil {
+				_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+				return
+			}
+			changes = append(changes, scimGroupMembersChange(ctx, op, group.ID, []uuid.UUID{memberID}))
+		default:
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidPath))
+			return
+		}
+	}
+
+	api.scimUpdateGroup(rw, r, group, func(tx database.Store) error {
+		for _, change := range changes {
+			err := change(tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (api *API) scimDeleteGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}))
+		return
+	}
+
+	group, ok := api.scimGroupParam(rw, r)
+	if !ok {
+		return
+	}
+	_, err := api.Database.UpdateGroupDeletedAtByID(ctx, database.UpdateGroupDeletedAtByIDParams{
+		ID:        group.ID,
+		DeletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+	})
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+		Type: codersdk.GroupWebhookEventGroupDeleted,
+	})
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// scimError wraps err so handlerutil.WriteError, which only inspects the
+// unwrapped cause, responds with its status.
+// scimOrganization returns the organization groups pushed over SCIM are
+// created in.
+func (api *API) scimOrganization(ctx context.Context) (database.Organization, error) {
+	var (
+		organization database.Organization
+		err          error
+	)
+	switch id, parseErr := uuid.Parse(api.SCIMOrganization); {
+	case api.SCIMOrganization == "":
+		organization, err = api.Database.GetDefaultOrganization(ctx)
+	case parseErr == nil:
+		organization, err = api.Database.GetOrganizationByID(ctx, id)
+	default:
+		organization, err = api.Database.GetOrganizationByName(ctx, api.SCIMOrganization)
+	}
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return database.Organization{}, scimError(spec.ErrInternal)
+	}
+	return organization, err
+}
+
+func scimError(err *spec.Error) error {
+	return xerrors.Errorf("scim: %w", err)
+}
+
+// scimGroupParam fetches the group in the URL. Only groups managed by the
+// identity provider are visible over SCIM.
+func (api *API) scimGroupParam(rw http.ResponseWriter, r *http.Request) (database.Group, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = handlerutil.WriteError(rw, scimError(&spec.Error{Status: http.StatusBadRequest, Type: "invalidId"}))
+		return database.Group{}, false
+	}
+	group, err := api.Database.GetGroupByID(r.Context(), id)
+	if xerrors.Is(err, sql.ErrNoRows) || (err == nil && (group.Source != database.GroupSourceOIDC || group.DeletedAt.Valid)) {
+		_ = handlerutil.WriteError(rw, scimError(spec.ErrNotFound))
+		return database.Group{}, false
+	}
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return database.Group{}, false
+	}
+	return group, true
+}
+
+// scimGroupMemberIDs parses member IDs and ensures every member belongs to
+// the group's organization.
+func (api *API) scimGroupMemberIDs(rw http.ResponseWriter, r *http.Request, organizationID uuid.UUID, members []SCIMGroupMember) ([]uuid.UUID, bool) {
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member.Value)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return ids, true
+	}
+
+	rows, err := api.Database.GetOrganizationIDsByMemberIDs(r.Context(), ids)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return nil, false
+	}
+	inOrganization := make(map[uuid.UUID]bool, len(rows))
+	for _, row := range rows {
+		inOrganization[row.UserID] = slice.Contains(row.OrganizationIDs, organizationID)
+	}
+	for _, id := range ids {
+		if !inOrganization[id] {
+			_ = handlerutil.WriteError(rw, scimError(spec.ErrInvalidValue))
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// scimUpdateGroup saves the group name and applies membership changes in a
+// single transaction, then writes the updated group.
+func (api *API) scimUpdateGroup(rw http.ResponseWriter, r *http.Request, group database.Group, updateMembers func(tx database.Store) error) {
+	var (
+		ctx             = r.Context()
+		previousName    string
+		previousMembers []database.User
+		members         []database.User
+	)
+	err := api.Database.InTx(func(tx database.Store) error {
+		previous, err := tx.GetGroupByID(ctx, group.ID)
+		if err != nil {
+			return err
+		}
+		previousName = previous.Name
+		previousMembers, err = tx.GetGroupMembers(ctx, group.ID)
+		if err != nil {
+			return err
+		}
+		group, err = tx.UpdateGroupByID(ctx, database.UpdateGroupByIDParams{
+			ID:               group.ID,
+			Name:             group.Name,
+			ParentID:         group.ParentID,
+			DisplayName:      group.DisplayName,
+			AvatarURL:        group.AvatarURL,
+			Description:      group.Description,
+			Metadata:         group.Metadata,
+			AutostopSchedule: group.AutostopSchedule,
+			MaxTtl:           group.MaxTtl,
+			QuotaAllowance:   group.QuotaAllowance,
+			Roles:            group.Roles,
+		})
+		if err != nil {
+			return err
+		}
+		err = updateMembers(tx)
+		if err != nil {
+			return err
+		}
+		members, err = tx.GetGroupMembers(ctx, group.ID)
+		return err
+	})
+	if database.IsUniqueViolation(err) {
+		_ = handlerutil.WriteError(rw, scimError(spec.ErrUniqueness))
+		return
+	}
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	if group.Name != previousName {
+		api.publishGroupEvent(group, codersdk.GroupWebhookEvent{
+			Type:              codersdk.GroupWebhookEventGroupRenamed,
+			PreviousGroupName: previousName,
+		})
+	}
+	added, removed := diffGroupMembers(previousMembers, members)
+	api.publishGroupMembersEvents(group, added, removed)
+
+	sGroup, err := api.convertSCIMGroup(ctx, group)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sGroup)
+}
+
+func scimGroupMembersChange(ctx context.Context, op string, groupID uuid.UUID, memberIDs []uuid.UUID) func(tx database.Store) error {
+	return func(tx database.Store) error {
+		switch op {
+		case "add":
+			_, err := tx.InsertGroupMembers(ctx, database.InsertGroupMembersParams{
+				UserIds: memberIDs,
+				GroupID: groupID,
+			})
+			return err
+		case "remove":
+			for _, memberID := range memberIDs {
+				_, err := tx.DeleteUserFromGroups(ctx, database.DeleteUserFromGroupsParams{
+					UserID:   memberID,
+					GroupIds: []uuid.UUID{groupID},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			return scimReplaceGroupMembers(ctx, tx, groupID, memberIDs)
+		}
+	}
+}
+
+func scimReplaceGroupMembers(ctx context.Context, tx database.Store, groupID uuid.UUID, memberIDs []uuid.UUID) error {
+	_, err := tx.DeleteGroupMembersExceptUserIDs(ctx, database.DeleteGroupMembersExceptUserIDsParams{
+		GroupID: groupID,
+		UserIds: memberIDs,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tx.InsertGroupMembers(ctx, database.InsertGroupMembersParams{
+		UserIds: memberIDs,
+		GroupID: groupID,
+	})
+	return err
+}
+
+func (api *API) convertSCIMGroup(ctx context.Context, group database.Group) (SCIMGroup, error) {
+	members, err := api.Database.GetGroupMembers(ctx, group.ID)
+	if err != nil {
+		return SCIMGroup{}, err
+	}
+
+	sGroup := newSCIMGroup(group)
+	sGroup.Members = make([]SCIMGroupMember, 0, len(members))
+	for _, member := range members {
+		sGroup.Members = append(sGroup.Members, SCIMGroupMember{
+			Value:   member.ID.String(),
+			Display: member.Username,
+		})
+	}
+	return sGroup, nil
+}
+
+// newSCIMGroup converts the group without its members.
+func newSCIMGroup(group database.Group) SCIMGroup {
+	sGroup := SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          group.ID.String(),
+		DisplayName: group.Name,
+	}
+	sGroup.Meta.ResourceType = "Group"
+	return sGroup
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -200,4 +202,159 @@ func TestScim(t *testing.T) {
 			assert.Equal(t, codersdk.UserStatusSuspended, users[0].Status)
 		})
 	})
+
+	t.Run("groups", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("noAuth", func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+			defer cancel()
+
+			client := coderdenttest.New(t, &coderdenttest.Options{SCIMAPIKey: []byte("hi")})
+			_ = coderdtest.CreateFirstUser(t, client)
+			coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				AccountID: "coolin",
+				SCIM:      true,
+			})
+
+			res, err := client.Request(ctx, "POST", "/scim/v2/Groups", coderd.SCIMGroup{DisplayName: "hi"})
+			require.NoError(t, err)
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		})
+
+		t.Run("OK", func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+			defer cancel()
+
+			scimAPIKey := []byte("hi")
+			client := coderdenttest.New(t, &coderdenttest.Options{SCIMAPIKey: scimAPIKey})
+			first := coderdtest.CreateFirstUser(t, client)
+			coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				AccountID: "coolin",
+				SCIM:      true,
+			})
+			_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+			_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, first.OrganizationID)
+
+			do := func(method, path string, body interface{}, status int) coderd.SCIMGroup {
+				t.Helper()
+				res, err := client.Request(ctx, method, path, body, setScimAuth(scimAPIKey))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, status, res.StatusCode)
+				var sGroup coderd.SCIMGroup
+				if status != http.StatusNoContent && status < http.StatusBadRequest {
+					require.NoError(t, json.NewDecoder(res.Body).Decode(&sGroup))
+				}
+				return sGroup
+			}
+			memberIDs := func(sGroup coderd.SCIMGroup) []string {
+				ids := make([]string, 0, len(sGroup.Members))
+				for _, member := range sGroup.Members {
+					ids = append(ids, member.Value)
+				}
+				return ids
+			}
+
+			sGroup := do("POST", "/scim/v2/Groups", coderd.SCIMGroup{
+				DisplayName: "engineering",
+				Members:     []coderd.SCIMGroupMember{{Value: user1.ID.String()}},
+			}, http.StatusCreated)
+			require.Equal(t, "engineering", sGroup.DisplayName)
+			require.Equal(t, []string{user1.ID.String()}, memberIDs(sGroup))
+			path := "/scim/v2/Groups/" + sGroup.ID
+
+			// Pushing the group again replaces its members.
+			repushed := do("POST", "/scim/v2/Groups", coderd.SCIMGroup{
+				DisplayName: "engineering",
+				Members:     []coderd.SCIMGroupMember{{Value: user2.ID.String()}},
+			}, http.StatusOK)
+			require.Equal(t, sGroup.ID, repushed.ID)
+			require.Equal(t, []string{user2.ID.String()}, memberIDs(repushed))
+
+			list := func(query string) coderd.SCIMListResponse {
+				t.Helper()
+				res, err := client.Request(ctx, "GET", "/scim/v2/Groups"+query, nil, setScimAuth(scimAPIKey))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				var resp coderd.SCIMListResponse
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+				return resp
+			}
+			found := list("?filter=" + url.QueryEscape(`displayName eq "engineering"`))
+			require.Equal(t, 1, found.TotalResults)
+			require.Equal(t, sGroup.ID, found.Resources[0].ID)
+			require.Equal(t, []string{user2.ID.String()}, memberIDs(found.Resources[0]))
+			require.Zero(t, list("?filter="+url.QueryEscape(`displayName eq "missing"`)).TotalResults)
+			all := list("?startIndex=2&count=10")
+			require.Equal(t, 1, all.TotalResults)
+			require.Empty(t, all.Resources)
+
+			members, err := json.Marshal([]coderd.SCIMGroupMember{{Value: user2.ID.String()}})
+			require.NoError(t, err)
+			name, err := json.Marshal("eng")
+			require.NoError(t, err)
+			sGroup = do("PATCH", path, coderd.SCIMPatchOp{
+				Operations: []coderd.SCIMPatchOpOperation{
+					{Op: "add", Path: "members", Value: members},
+					{Op: "remove", Path: fmt.Sprintf("members[value eq %q]", user1.ID.String())},
+					{Op: "replace", Path: "displayName", Value: name},
+				},
+			}, http.StatusOK)
+			require.Equal(t, "eng", sGroup.DisplayName)
+			require.Equal(t, []string{user2.ID.String()}, memberIDs(sGroup))
+
+			_ = do("PATCH", path, coderd.SCIMPatchOp{
+				Operations: []coderd.SCIMPatchOpOperation{{Op: "replace", Path: "emails"}},
+			}, http.StatusBadRequest)
+
+			sGroup = do("PUT", path, coderd.SCIMGroup{
+				DisplayName: "engineering",
+				Members:     []coderd.SCIMGroupMember{{Value: user1.ID.String()}},
+			}, http.StatusOK)
+			require.Equal(t, "engineering", sGroup.DisplayName)
+			require.Equal(t, []string{user1.ID.String()}, memberIDs(sGroup))
+
+			_ = do("DELETE", path, nil, http.StatusNoContent)
+			_ = do("GET", path, nil, http.StatusNotFound)
+		})
+
+		t.Run("Organization", func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+			defer cancel()
+
+			scimAPIKey := []byte("hi")
+			client := coderdenttest.New(t, &coderdenttest.Options{
+				SCIMAPIKey:       scimAPIKey,
+				SCIMOrganization: "pushed",
+			})
+			_ = coderdtest.CreateFirstUser(t, client)
+			coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+				AccountID:   "coolin",
+				SCIM:        true,
+				RBACEnabled: true,
+			})
+			organization, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "pushed"})
+			require.NoError(t, err)
+
+			res, err := client.Request(ctx, "POST", "/scim/v2/Groups", coderd.SCIMGroup{DisplayName: "engineering"}, setScimAuth(scimAPIKey))
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusCreated, res.StatusCode)
+			var sGroup coderd.SCIMGroup
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&sGroup))
+
+			group, err := client.Group(ctx, uuid.MustParse(sGroup.ID))
+			require.NoError(t, err)
+			require.Equal(t, organization.ID, group.OrganizationID)
+		})
+	})
 }
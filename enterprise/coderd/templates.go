@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
@@ -62,18 +63,31 @@ func (api *API) templateACL(rw http.ResponseWriter, r *http.Request) {
 		organizationIDsByUserID[organizationIDsByMemberIDsRow.UserID] = organizationIDsByMemberIDsRow.OrganizationIDs
 	}
 
-	groups := make([]codersdk.TemplateGroup, 0, len(dbGroups))
+	groupIDs := make([]uuid.UUID, 0, len(dbGroups))
 	for _, group := range dbGroups {
-		var members []database.User
+		if group.Name != database.AllUsersGroup {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+	membersByGroupID, err := api.groupMembersByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
 
+	groups := make([]codersdk.TemplateGroup, 0, len(dbGroups))
+	for _, group := range dbGroups {
+		members := membersByGroupID[group.ID]
 		if group.Name == database.AllUsersGroup {
-			members, err = api.Database.GetAllOrganizationMembers(ctx, group.OrganizationID)
-		} else {
-			members, err = api.Database.GetGroupMembers(ctx, group.ID)
-		}
-		if err != nil {
-			httpapi.InternalServerError(rw, err)
-			return
+			users, err := api.Database.GetEveryoneGroupMembers(ctx, group.OrganizationID.UUID)
+			if err != nil {
+				httpapi.InternalServerError(rw, err)
+				return
+			}
+			members = make([]groupMember, 0, len(users))
+			for _, user := range users {
+				members = append(members, groupMember{User: user})
+			}
 		}
 
 		groups = append(groups, codersdk.TemplateGroup{
@@ -88,6 +102,188 @@ func (api *API) templateACL(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// templateEffectiveAccess expands the groups in the ACL of a template into
+// their members, including the members of groups nested beneath them, so
+// admins don't have to union group memberships to review who can use the
+// template. Groups the requester can't read are left out, like in the ACL.
+func (api *API) templateEffectiveAccess(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	users, err := api.Database.GetTemplateUserRoles(ctx, template.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	dbGroups, err := api.Database.GetTemplateGroupRoles(ctx, template.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	dbGroups, err = coderd.AuthorizeFilter(api.AGPL.HTTPAuth, r, rbac.ActionRead, dbGroups)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching groups.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	accessByUserID := map[uuid.UUID]*codersdk.TemplateEffectiveUser{}
+	dbUsers := map[uuid.UUID]database.User{}
+	grant := func(user database.User, source codersdk.TemplateAccessSource) {
+		// Suspended users can't use templates, whatever their role.
+		if user.Deleted || user.Status != database.UserStatusActive {
+			return
+		}
+		access, ok := accessByUserID[user.ID]
+		if !ok {
+			access = &codersdk.TemplateEffectiveUser{}
+			accessByUserID[user.ID] = access
+			dbUsers[user.ID] = user
+		}
+		access.Sources = append(access.Sources, source)
+		if access.Role != codersdk.TemplateRoleAdmin {
+			access.Role = source.Role
+		}
+	}
+	for _, user := range users {
+		grant(user.User, codersdk.TemplateAccessSource{
+			Role: convertToTemplateRole(user.Actions),
+		})
+	}
+
+	now := database.Now()
+	groupsByScope := map[uuid.NullUUID][]database.GetGroupsRow{}
+	for _, group := range dbGroups {
+		groupID := group.ID
+		source := codersdk.TemplateAccessSource{
+			Role:      convertToTemplateRole(group.Actions),
+			GroupID:   &groupID,
+			GroupName: group.Name,
+		}
+		if group.Name == database.AllUsersGroup {
+			members, err := api.Database.GetEveryoneGroupMembers(ctx, group.OrganizationID.UUID)
+			if err != nil {
+				httpapi.InternalServerError(rw, err)
+				return
+			}
+			for _, member := range members {
+				grant(member, source)
+			}
+			continue
+		}
+
+		// Members of nested groups inherit the roles of the groups above
+		// them, and nested groups are in the same organization.
+		scopeGroups, ok := groupsByScope[group.OrganizationID]
+		if !ok {
+			scopeGroups, err = api.Database.GetGroups(ctx, database.GetGroupsParams{
+				OrganizationID: group.OrganizationID,
+			})
+			if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+				httpapi.InternalServerError(rw, err)
+				return
+			}
+			groupsByScope[group.OrganizationID] = scopeGroups
+		}
+		groupIDs := []uuid.UUID{group.ID}
+		for i := 0; i < len(groupIDs); i++ {
+			for _, scopeGroup := range scopeGroups {
+				if scopeGroup.ParentID.Valid && scopeGroup.ParentID.UUID == groupIDs[i] {
+					groupIDs = append(groupIDs, scopeGroup.ID)
+				}
+			}
+		}
+		membersByGroupID, err := api.groupMembersByGroupIDs(ctx, groupIDs)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		granted := map[uuid.UUID]bool{}
+		for _, groupID := range groupIDs {
+			for _, member := range membersByGroupID[groupID] {
+				if granted[member.ID] || (member.ExpiresAt.Valid && !member.ExpiresAt.Time.After(now)) {
+					continue
+				}
+				granted[member.ID] = true
+				grant(member.User, source)
+			}
+		}
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(accessByUserID))
+	for userID := range accessByUserID {
+		userIDs = append(userIDs, userID)
+	}
+	orgIDsByMemberIDsRows, err := api.Database.GetOrganizationIDsByMemberIDs(ctx, userIDs)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	organizationIDsByUserID := map[uuid.UUID][]uuid.UUID{}
+	for _, row := range orgIDsByMemberIDsRows {
+		organizationIDsByUserID[row.UserID] = row.OrganizationIDs
+	}
+
+	resp := codersdk.TemplateEffectiveAccess{
+		Users: make([]codersdk.TemplateEffectiveUser, 0, len(accessByUserID)),
+	}
+	for userID, access := range accessByUserID {
+		access.User = convertUser(dbUsers[userID], organizationIDsByUserID[userID])
+		resp.Users = append(resp.Users, *access)
+	}
+	sort.Slice(resp.Users, func(i, j int) bool {
+		return resp.Users[i].Username < resp.Users[j].Username
+	})
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// groupTemplates lists the templates the group has a role on, filtered to
+// those the requester can read.
+func (api *API) groupTemplates(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		group = httpmw.GroupParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, group) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	templates, err := api.Database.GetGroupTemplateRoles(ctx, group.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	templates, err = coderd.AuthorizeFilter(api.AGPL.HTTPAuth, r, rbac.ActionRead, templates)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching templates.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	resp := make([]codersdk.GroupTemplate, 0, len(templates))
+	for _, template := range templates {
+		resp = append(resp, codersdk.GroupTemplate{
+			ID:             template.ID,
+			Name:           template.Name,
+			Icon:           template.Icon,
+			OrganizationID: template.OrganizationID,
+			Role:           convertToTemplateRole(template.Actions),
+		})
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
 func (api *API) patchTemplateACL(rw http.ResponseWriter, r *http.Request) {
 	var (
 		ctx      = r.Context()
@@ -190,7 +386,12 @@ func validateTemplateACLPerms(ctx context.Context, db database.Store, perms map[
 			}
 		} else {
 			// This could get slow if we get a ton of group perm updates.
-			_, err = db.GetGroupByID(ctx, id)
+			group, err := db.GetGroupByID(ctx, id)
+			// Deleted groups can be removed from the ACL but not granted
+			// a role.
+			if err == nil && group.DeletedAt.Valid && v != codersdk.TemplateRoleDeleted {
+				err = sql.ErrNoRows
+			}
 			if err != nil {
 				validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("Failed to find resource with ID %q: %v", k, err.Error())})
 				continue
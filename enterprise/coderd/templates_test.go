@@ -79,7 +79,7 @@ func TestTemplateACL(t *testing.T) {
 
 		require.Len(t, acl.Groups, 1)
 		require.Len(t, acl.Groups[0].Members, 2)
-		require.Contains(t, acl.Groups[0].Members, user1)
+		require.Contains(t, acl.Groups[0].Members, codersdk.GroupMember{User: user1})
 		require.Len(t, acl.Users, 0)
 	})
 
@@ -310,6 +310,76 @@ func TestTemplateACL(t *testing.T) {
 	})
 }
 
+func TestTemplateEffectiveAccess(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	_, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	_, user2 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	_, user3 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+	ctx, _ := testutil.Context(t)
+
+	parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "engineering",
+	})
+	require.NoError(t, err)
+	child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name:     "platform",
+		ParentID: &parent.ID,
+	})
+	require.NoError(t, err)
+	_, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+		AddUsers: []string{user1.ID.String()},
+	})
+	require.NoError(t, err)
+
+	err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+		UserPerms: map[string]codersdk.TemplateRole{
+			user2.ID.String(): codersdk.TemplateRoleAdmin,
+		},
+		GroupPerms: map[string]codersdk.TemplateRole{
+			parent.ID.String(): codersdk.TemplateRoleAdmin,
+		},
+	})
+	require.NoError(t, err)
+	_, err = client.UpdateUserStatus(ctx, user3.ID.String(), codersdk.UserStatusSuspended)
+	require.NoError(t, err)
+
+	access, err := client.TemplateEffectiveAccess(ctx, template.ID)
+	require.NoError(t, err)
+	roles := map[uuid.UUID]codersdk.TemplateRole{}
+	sources := map[uuid.UUID][]codersdk.TemplateAccessSource{}
+	for _, accessUser := range access.Users {
+		roles[accessUser.ID] = accessUser.Role
+		sources[accessUser.ID] = accessUser.Sources
+	}
+	// Everyone can view the template through the "Everyone" group, except
+	// suspended users.
+	require.Equal(t, map[uuid.UUID]codersdk.TemplateRole{
+		user.UserID: codersdk.TemplateRoleView,
+		user1.ID:    codersdk.TemplateRoleAdmin,
+		user2.ID:    codersdk.TemplateRoleAdmin,
+	}, roles)
+	// Members of the child group inherit the role of the parent.
+	require.Contains(t, sources[user1.ID], codersdk.TemplateAccessSource{
+		Role:      codersdk.TemplateRoleAdmin,
+		GroupID:   &parent.ID,
+		GroupName: parent.Name,
+	})
+	require.Len(t, sources[user2.ID], 2)
+	require.Contains(t, sources[user2.ID], codersdk.TemplateAccessSource{
+		Role: codersdk.TemplateRoleAdmin,
+	})
+}
+
 func TestUpdateTemplateACL(t *testing.T) {
 	t.Parallel()
 
@@ -657,6 +727,95 @@ func TestUpdateTemplateACL(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("ParentGroupHasAccess", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+
+		parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "engineering",
+		})
+		require.NoError(t, err)
+		child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "platform",
+			ParentID: &parent.ID,
+		})
+		require.NoError(t, err)
+
+		err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+			GroupPerms: map[string]codersdk.TemplateRole{
+				// The allUsers group shares the same ID as the organization.
+				user.OrganizationID.String(): codersdk.TemplateRoleDeleted,
+				parent.ID.String():           codersdk.TemplateRoleView,
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+
+		// Members of the child group inherit access granted to the parent.
+		_, err = client1.Template(ctx, template.ID)
+		require.NoError(t, err)
+
+		// Moving the child to the top level revokes the inherited access.
+		_, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+			ParentID: &uuid.Nil,
+		})
+		require.NoError(t, err)
+		_, err = client1.Template(ctx, template.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("DeploymentGroupHasAccess", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+
+		client1, user1 := coderdtest.CreateAnotherUserWithUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, _ := testutil.Context(t)
+
+		group, err := client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "platform",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user1.ID.String()},
+		})
+		require.NoError(t, err)
+
+		err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+			GroupPerms: map[string]codersdk.TemplateRole{
+				// The allUsers group shares the same ID as the organization.
+				user.OrganizationID.String(): codersdk.TemplateRoleDeleted,
+				group.ID.String():            codersdk.TemplateRoleView,
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = client1.Template(ctx, template.ID)
+		require.NoError(t, err)
+	})
+
 	t.Run("NoAccess", func(t *testing.T) {
 		t.Parallel()
 		client := coderdenttest.New(t, nil)
@@ -705,3 +864,59 @@ func TestUpdateTemplateACL(t *testing.T) {
 		require.Equal(t, http.StatusNotFound, cerr.StatusCode())
 	})
 }
+
+func TestGroupTemplates(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+	ctx, _ := testutil.Context(t)
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "hi",
+	})
+	require.NoError(t, err)
+
+	templates, err := client.GroupTemplates(ctx, group.ID)
+	require.NoError(t, err)
+	require.Len(t, templates, 0)
+
+	err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+		GroupPerms: map[string]codersdk.TemplateRole{
+			group.ID.String(): codersdk.TemplateRoleAdmin,
+		},
+	})
+	require.NoError(t, err)
+
+	templates, err = client.GroupTemplates(ctx, group.ID)
+	require.NoError(t, err)
+	require.Equal(t, []codersdk.GroupTemplate{{
+		ID:             template.ID,
+		Name:           template.Name,
+		Icon:           template.Icon,
+		OrganizationID: template.OrganizationID,
+		Role:           codersdk.TemplateRoleAdmin,
+	}}, templates)
+
+	// The Everyone group can use new templates by default.
+	templates, err = client.GroupTemplates(ctx, user.OrganizationID)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, codersdk.TemplateRoleView, templates[0].Role)
+
+	err = client.UpdateTemplateACL(ctx, template.ID, codersdk.UpdateTemplateACL{
+		GroupPerms: map[string]codersdk.TemplateRole{
+			group.ID.String(): codersdk.TemplateRoleDeleted,
+		},
+	})
+	require.NoError(t, err)
+
+	templates, err = client.GroupTemplates(ctx, group.ID)
+	require.NoError(t, err)
+	require.Len(t, templates, 0)
+}
@@ -1,8 +1,14 @@
 package coderd
 
 import (
+	"context"
+	"database/sql"
+	"hash/fnv"
 	"net/http"
 
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
@@ -12,12 +18,18 @@ import (
 )
 
 type enforcer struct {
+	db                 database.Store
 	userWorkspaceLimit int
+	groupBudgets       bool
+	organizationQuotas bool
 }
 
-func NewEnforcer(userWorkspaceLimit int) workspacequota.Enforcer {
+func NewEnforcer(db database.Store, userWorkspaceLimit int, groupBudgets, organizationQuotas bool) workspacequota.Enforcer {
 	return &enforcer{
+		db:                 db,
 		userWorkspaceLimit: userWorkspaceLimit,
+		groupBudgets:       groupBudgets,
+		organizationQuotas: organizationQuotas,
 	}
 }
 
@@ -33,19 +45,203 @@ func (e *enforcer) CanCreateWorkspace(count int) bool {
 	return count < e.userWorkspaceLimit
 }
 
+func (e *enforcer) CheckBudget(ctx context.Context, db database.Store, ownerID uuid.UUID, template database.Template) error {
+	if !e.groupBudgets {
+		return nil
+	}
+	err := db.AcquireLock(ctx, budgetLockID(ownerID))
+	if err != nil {
+		return xerrors.Errorf("acquire budget lock: %w", err)
+	}
+	consumption, err := db.GetWorkspaceQuotaConsumptionByOwnerID(ctx, ownerID)
+	if err != nil {
+		return xerrors.Errorf("get workspace quota consumption: %w", err)
+	}
+	budget, err := quotaBudget(ctx, db, ownerID, template.OrganizationID, consumption)
+	if err != nil {
+		return err
+	}
+	// Users that none of their groups grant an allowance to aren't limited.
+	if budget.Allowance == 0 {
+		return nil
+	}
+	if budget.Consumed+int64(template.QuotaWeight) > budget.Allowance {
+		return &workspacequota.BudgetExceededError{
+			Allowance: budget.Allowance,
+			Consumed:  budget.Consumed,
+			Weight:    template.QuotaWeight,
+		}
+	}
+	return nil
+}
+
+// budgetLockID returns the ID of the advisory lock that serializes the
+// budget checks of the owner.
+func budgetLockID(ownerID uuid.UUID) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte("workspace-quota-budget"))
+	_, _ = hash.Write(ownerID[:])
+	return int64(hash.Sum64())
+}
+
+func (e *enforcer) CheckOrganizationQuota(ctx context.Context, template database.Template, workspaceID uuid.UUID) error {
+	if !e.organizationQuotas {
+		return nil
+	}
+	quota, err := e.db.GetOrganizationQuotaByOrganizationID(ctx, template.OrganizationID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get organization quota: %w", err)
+	}
+
+	running := false
+	if workspaceID != uuid.Nil {
+		build, err := e.db.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspaceID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return xerrors.Errorf("get latest workspace build: %w", err)
+		}
+		// Restarting a running workspace doesn't consume more of the quota.
+		running = err == nil && build.Transition == database.WorkspaceTransitionStart
+	}
+	if running {
+		return nil
+	}
+
+	consumption, err := e.db.GetOrganizationQuotaConsumption(ctx, template.OrganizationID)
+	if err != nil {
+		return xerrors.Errorf("get organization quota consumption: %w", err)
+	}
+	resp := organizationQuotaConsumption(template.OrganizationID, quota, consumption)
+
+	if workspaceID == uuid.Nil && quota.MaxWorkspaces > 0 && resp.WorkspaceCount+1 > int64(quota.MaxWorkspaces) {
+		return &workspacequota.OrganizationQuotaExceededError{
+			Limit:     "workspaces",
+			Allowance: int64(quota.MaxWorkspaces),
+			Consumed:  resp.WorkspaceCount,
+		}
+	}
+	if quota.MaxRunningWorkspaces > 0 && resp.RunningWorkspaceCount+1 > int64(quota.MaxRunningWorkspaces) {
+		return &workspacequota.OrganizationQuotaExceededError{
+			Limit:     "running workspaces",
+			Allowance: int64(quota.MaxRunningWorkspaces),
+			Consumed:  resp.RunningWorkspaceCount,
+		}
+	}
+	if quota.ComputeCredits > 0 && resp.ComputeCreditsConsumed+int64(template.QuotaWeight) > int64(quota.ComputeCredits) {
+		return &workspacequota.OrganizationQuotaExceededError{
+			Limit:     "compute credits",
+			Allowance: int64(quota.ComputeCredits),
+			Consumed:  resp.ComputeCreditsConsumed,
+		}
+	}
+	return nil
+}
+
+// organizationQuotaConsumption sums the consumption of an organization's
+// workspaces per template.
+func organizationQuotaConsumption(orgID uuid.UUID, quota database.OrganizationQuota, rows []database.GetOrganizationQuotaConsumptionRow) codersdk.OrganizationQuotaConsumption {
+	resp := codersdk.OrganizationQuotaConsumption{
+		OrganizationID: orgID,
+		Quota: codersdk.OrganizationQuota{
+			MaxWorkspaces:        quota.MaxWorkspaces,
+			MaxRunningWorkspaces: quota.MaxRunningWorkspaces,
+			ComputeCredits:       quota.ComputeCredits,
+		},
+		Templates: make([]codersdk.OrganizationQuotaTemplate, 0, len(rows)),
+	}
+	for _, row := range rows {
+		credits := int64(row.QuotaWeight) * row.RunningWorkspaceCount
+		resp.WorkspaceCount += row.WorkspaceCount
+		resp.RunningWorkspaceCount += row.RunningWorkspaceCount
+		resp.ComputeCreditsConsumed += credits
+		resp.Templates = append(resp.Templates, codersdk.OrganizationQuotaTemplate{
+			TemplateID:             row.TemplateID,
+			TemplateName:           row.TemplateName,
+			Weight:                 row.QuotaWeight,
+			WorkspaceCount:         row.WorkspaceCount,
+			RunningWorkspaceCount:  row.RunningWorkspaceCount,
+			ComputeCreditsConsumed: credits,
+		})
+	}
+	return resp
+}
+
+// quotaBudget computes the user's budget in the organization from the
+// allowances of their groups and the workspaces they own. The groups are
+// those GetUserEffectiveGroups returns, so groups above the user's groups and
+// deployment-wide groups grant their allowance too.
+func quotaBudget(ctx context.Context, db database.Store, userID, orgID uuid.UUID, consumption []database.GetWorkspaceQuotaConsumptionByOwnerIDRow) (codersdk.WorkspaceQuotaBudget, error) {
+	effective, err := db.GetUserEffectiveGroups(ctx, database.GetUserEffectiveGroupsParams{
+		UserID:         userID,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return codersdk.WorkspaceQuotaBudget{}, xerrors.Errorf("get user effective groups: %w", err)
+	}
+	groups := make([]database.Group, 0, len(effective))
+	for _, group := range effective {
+		if group.QuotaAllowance > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	budget := codersdk.WorkspaceQuotaBudget{
+		OrganizationID: orgID,
+		Groups:         make([]codersdk.WorkspaceQuotaGroup, 0, len(groups)),
+		Templates:      make([]codersdk.WorkspaceQuotaTemplate, 0),
+	}
+	for _, row := range consumption {
+		if row.OrganizationID != orgID {
+			continue
+		}
+		consumed := int64(row.QuotaWeight) * row.WorkspaceCount
+		budget.Consumed += consumed
+		budget.Templates = append(budget.Templates, codersdk.WorkspaceQuotaTemplate{
+			TemplateID:     row.TemplateID,
+			TemplateName:   row.TemplateName,
+			Weight:         row.QuotaWeight,
+			WorkspaceCount: row.WorkspaceCount,
+			Consumed:       consumed,
+		})
+	}
+
+	remaining := budget.Consumed
+	for _, group := range groups {
+		allowance := int64(group.QuotaAllowance)
+		consumed := remaining
+		if consumed > allowance {
+			consumed = allowance
+		}
+		remaining -= consumed
+		budget.Allowance += allowance
+		budget.Groups = append(budget.Groups, codersdk.WorkspaceQuotaGroup{
+			GroupID:   group.ID,
+			GroupName: group.Name,
+			Allowance: allowance,
+			Consumed:  consumed,
+		})
+	}
+	return budget, nil
+}
+
 func (api *API) workspaceQuota(rw http.ResponseWriter, r *http.Request) {
-	user := httpmw.UserParam(r)
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
 
 	if !api.AGPL.Authorize(r, rbac.ActionRead, rbac.ResourceUser) {
 		httpapi.ResourceNotFound(rw)
 		return
 	}
 
-	workspaces, err := api.Database.GetWorkspaces(r.Context(), database.GetWorkspacesParams{
+	workspaces, err := api.Database.GetWorkspaces(ctx, database.GetWorkspacesParams{
 		OwnerID: user.ID,
 	})
 	if err != nil {
-		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error fetching workspaces.",
 			Detail:  err.Error(),
 		})
@@ -53,8 +249,133 @@ func (api *API) workspaceQuota(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	e := *api.AGPL.WorkspaceQuotaEnforcer.Load()
-	httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.WorkspaceQuota{
+	resp := codersdk.WorkspaceQuota{
 		UserWorkspaceCount: len(workspaces),
 		UserWorkspaceLimit: e.UserWorkspaceLimit(),
+	}
+	if ent, ok := e.(*enforcer); ok && ent.groupBudgets {
+		resp.Budgets, err = api.workspaceQuotaBudgets(ctx, user.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspace quota budgets.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// workspaceQuotaBudgets returns the user's budget in every organization
+// they're a member of.
+func (api *API) workspaceQuotaBudgets(ctx context.Context, userID uuid.UUID) ([]codersdk.WorkspaceQuotaBudget, error) {
+	orgs, err := api.Database.GetOrganizationsByUserID(ctx, userID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return nil, xerrors.Errorf("get organizations: %w", err)
+	}
+	consumption, err := api.Database.GetWorkspaceQuotaConsumptionByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace quota consumption: %w", err)
+	}
+	budgets := make([]codersdk.WorkspaceQuotaBudget, 0, len(orgs))
+	for _, org := range orgs {
+		budget, err := quotaBudget(ctx, api.Database, userID, org.ID, consumption)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
+
+func (api *API) patchTemplateQuota(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplateQuotaRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	err := api.Database.UpdateTemplateQuotaWeightByID(ctx, database.UpdateTemplateQuotaWeightByIDParams{
+		ID:          template.ID,
+		QuotaWeight: req.Weight,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully updated template quota weight!",
+	})
+}
+
+func (api *API) organizationQuota(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceOrganization.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	quota, err := api.Database.GetOrganizationQuotaByOrganizationID(ctx, org.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	consumption, err := api.Database.GetOrganizationQuotaConsumption(ctx, org.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, organizationQuotaConsumption(org.ID, quota, consumption))
+}
+
+func (api *API) putOrganizationQuota(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	// Organization admins can't raise their own quota, so this requires the
+	// site-wide permission.
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceOrganization) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.OrganizationQuota
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	quota, err := api.Database.UpsertOrganizationQuota(ctx, database.UpsertOrganizationQuotaParams{
+		OrganizationID:       org.ID,
+		MaxWorkspaces:        req.MaxWorkspaces,
+		MaxRunningWorkspaces: req.MaxRunningWorkspaces,
+		ComputeCredits:       req.ComputeCredits,
+		UpdatedAt:            database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.OrganizationQuota{
+		MaxWorkspaces:        quota.MaxWorkspaces,
+		MaxRunningWorkspaces: quota.MaxRunningWorkspaces,
+		ComputeCredits:       quota.ComputeCredits,
 	})
 }
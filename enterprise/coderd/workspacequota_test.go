@@ -119,4 +119,175 @@ func TestWorkspaceQuota(t *testing.T) {
 		require.EqualValues(t, q1.UserWorkspaceCount, 1)
 		require.EqualValues(t, q1.UserWorkspaceLimit, max)
 	})
+	t.Run("GroupBudget", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			GroupWorkspaceQuota: true,
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			WorkspaceQuota: true,
+			RBACEnabled:    true,
+		})
+
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "gpu",
+		})
+		require.NoError(t, err)
+		group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers:       []string{user.UserID.String()},
+			QuotaAllowance: ptr.Ref[int32](5),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 5, group.QuotaAllowance)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.EqualValues(t, 1, template.QuotaWeight)
+		err = client.UpdateTemplateQuota(ctx, template.ID, codersdk.UpdateTemplateQuotaRequest{
+			Weight: 3,
+		})
+		require.NoError(t, err)
+
+		_ = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "second",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, codersdk.ErrorCodeQuotaExceeded, apiErr.Code)
+
+		quota, err := client.WorkspaceQuota(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, quota.Budgets, 1)
+		budget := quota.Budgets[0]
+		require.Equal(t, user.OrganizationID, budget.OrganizationID)
+		require.EqualValues(t, 5, budget.Allowance)
+		require.EqualValues(t, 3, budget.Consumed)
+		require.Len(t, budget.Groups, 1)
+		require.Equal(t, group.ID, budget.Groups[0].GroupID)
+		require.EqualValues(t, 3, budget.Groups[0].Consumed)
+		require.Len(t, budget.Templates, 1)
+		require.Equal(t, template.ID, budget.Templates[0].TemplateID)
+		require.EqualValues(t, 3, budget.Templates[0].Weight)
+		require.EqualValues(t, 1, budget.Templates[0].WorkspaceCount)
+	})
+	t.Run("GroupBudgetInherited", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			GroupWorkspaceQuota: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			WorkspaceQuota: true,
+			RBACEnabled:    true,
+		})
+
+		// The user is only a member of the child, but the allowances of the
+		// group above it and of deployment-wide groups count too.
+		parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "engineering",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, parent.ID, codersdk.PatchGroupRequest{
+			QuotaAllowance: ptr.Ref[int32](4),
+		})
+		require.NoError(t, err)
+		child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name:     "frontend",
+			ParentID: &parent.ID,
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{user.UserID.String()},
+		})
+		require.NoError(t, err)
+		deployment, err := client.CreateDeploymentGroup(ctx, codersdk.CreateGroupRequest{
+			Name: "contractors",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, deployment.ID, codersdk.PatchGroupRequest{
+			AddUsers:       []string{user.UserID.String()},
+			QuotaAllowance: ptr.Ref[int32](2),
+		})
+		require.NoError(t, err)
+
+		quota, err := client.WorkspaceQuota(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, quota.Budgets, 1)
+		budget := quota.Budgets[0]
+		require.EqualValues(t, 6, budget.Allowance)
+		require.Len(t, budget.Groups, 2)
+		require.Equal(t, deployment.ID, budget.Groups[0].GroupID)
+		require.Equal(t, parent.ID, budget.Groups[1].GroupID)
+	})
+	t.Run("OrganizationQuota", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			OrganizationWorkspaceQuota: true,
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			WorkspaceQuota: true,
+		})
+
+		quota, err := client.UpdateOrganizationQuota(ctx, user.OrganizationID, codersdk.OrganizationQuota{
+			MaxWorkspaces:        2,
+			MaxRunningWorkspaces: 1,
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, quota.MaxRunningWorkspaces)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		first := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, first.LatestBuild.ID)
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "second",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, codersdk.ErrorCodeQuotaExceeded, apiErr.Code)
+		require.Contains(t, apiErr.Message, "running workspaces")
+
+		build, err := client.CreateWorkspaceBuild(ctx, first.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+		second := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, second.LatestBuild.ID)
+
+		// The first workspace can't start while the second one runs.
+		_, err = client.CreateWorkspaceBuild(ctx, first.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, codersdk.ErrorCodeQuotaExceeded, apiErr.Code)
+
+		consumption, err := client.OrganizationQuota(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, consumption.WorkspaceCount)
+		require.EqualValues(t, 1, consumption.RunningWorkspaceCount)
+		require.EqualValues(t, 1, consumption.ComputeCreditsConsumed)
+		require.Len(t, consumption.Templates, 1)
+		require.Equal(t, template.ID, consumption.Templates[0].TemplateID)
+	})
 }
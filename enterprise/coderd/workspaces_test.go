@@ -67,3 +67,169 @@ func TestCreateWorkspace(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestWorkspaceGroupAutostop(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		},
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "interns",
+	})
+	require.NoError(t, err)
+	group, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+		AddUsers:     []string{user.UserID.String()},
+		MaxTTLMillis: ptr.Ref(time.Hour.Milliseconds()),
+	})
+	require.NoError(t, err)
+	require.Equal(t, time.Hour.Milliseconds(), group.MaxTTLMillis)
+
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	// The workspace TTL is longer than the group allows.
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	build, err := client.WorkspaceBuild(ctx, workspace.LatestBuild.ID)
+	require.NoError(t, err)
+
+	require.True(t, build.Deadline.Valid)
+	require.WithinDuration(t, time.Now().Add(time.Hour), build.Deadline.Time, time.Minute)
+
+	// The deadline can't be extended past the group's limit either.
+	err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+		Deadline: time.Now().Add(4 * time.Hour),
+	})
+	require.ErrorContains(t, err, "autostop policy")
+}
+
+func TestWorkspaceGroupAutostopScope(t *testing.T) {
+	t.Parallel()
+
+	client := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		},
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		RBACEnabled: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	// The policy of a group applies to members of the groups nested
+	// beneath it.
+	parent, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "engineering",
+	})
+	require.NoError(t, err)
+	_, err = client.PatchGroup(ctx, parent.ID, codersdk.PatchGroupRequest{
+		MaxTTLMillis: ptr.Ref(2 * time.Hour.Milliseconds()),
+	})
+	require.NoError(t, err)
+	child, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+		Name:     "interns",
+		ParentID: &parent.ID,
+	})
+	require.NoError(t, err)
+	_, err = client.PatchGroup(ctx, child.ID, codersdk.PatchGroupRequest{
+		AddUsers: []string{user.UserID.String()},
+	})
+	require.NoError(t, err)
+
+	// Policies of groups in other organizations don't apply.
+	other, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+		Name: "other",
+	})
+	require.NoError(t, err)
+	otherGroup, err := client.CreateGroup(ctx, other.ID, codersdk.CreateGroupRequest{
+		Name: "contractors",
+	})
+	require.NoError(t, err)
+	_, err = client.PatchGroup(ctx, otherGroup.ID, codersdk.PatchGroupRequest{
+		AddUsers:     []string{user.UserID.String()},
+		MaxTTLMillis: ptr.Ref(time.Hour.Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	build, err := client.WorkspaceBuild(ctx, workspace.LatestBuild.ID)
+	require.NoError(t, err)
+
+	require.True(t, build.Deadline.Valid)
+	require.WithinDuration(t, time.Now().Add(2*time.Hour), build.Deadline.Time, time.Minute)
+}
+
+func TestWorkspaceTransfer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TemplateNotAllowed", func(t *testing.T) {
+		t.Parallel()
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{IncludeProvisionerDaemon: true},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			RBACEnabled: true,
+		})
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, member, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+			Name: "another",
+		})
+		require.NoError(t, err)
+		invite, err := client.CreateOrganizationInvite(ctx, org.ID, codersdk.CreateOrganizationInviteRequest{})
+		require.NoError(t, err)
+		_, err = member.RedeemOrganizationInvite(ctx, codersdk.RedeemOrganizationInviteRequest{
+			Token: invite.Token,
+		})
+		require.NoError(t, err)
+
+		otherVersion := coderdtest.CreateTemplateVersion(t, client, org.ID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, otherVersion.ID)
+		otherTemplate := coderdtest.CreateTemplate(t, client, org.ID, otherVersion.ID)
+		// Only the Everyone group can use the template, so removing it leaves
+		// the owner of the workspace without access.
+		err = client.UpdateTemplateACL(ctx, otherTemplate.ID, codersdk.UpdateTemplateACL{
+			GroupPerms: map[string]codersdk.TemplateRole{
+				org.ID.String(): codersdk.TemplateRoleDeleted,
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			OrganizationID: org.ID,
+			TemplateID:     otherTemplate.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "template_id", apiErr.Validations[0].Field)
+	})
+}
@@ -591,10 +591,10 @@ export const getApplicationsHost =
 export const getGroups = async (
   organizationId: string,
 ): Promise<TypesGen.Group[]> => {
-  const response = await axios.get(
+  const response = await axios.get<TypesGen.GroupsResponse>(
     `/api/v2/organizations/${organizationId}/groups`,
   )
-  return response.data
+  return response.data.groups
 }
 
 export const createGroup = async (
@@ -1,5 +1,14 @@
 // Code generated by 'make coder/scripts/apitypings/main.go'. DO NOT EDIT.
 
+// From codersdk/meta.go
+export interface APIDeprecation {
+  readonly method: string
+  readonly path: string
+  readonly since?: string
+  readonly sunset?: string
+  readonly successor?: string
+}
+
 // From codersdk/apikey.go
 export interface APIKey {
   readonly id: string
@@ -10,6 +19,18 @@ export interface APIKey {
   readonly updated_at: string
   readonly login_type: LoginType
   readonly lifetime_seconds: number
+  readonly scope: APIKeyScope
+  readonly permissions?: string[]
+  readonly organization_id?: string
+}
+
+// From codersdk/meta.go
+export interface APIMeta {
+  readonly api_version: string
+  readonly version: string
+  readonly capabilities: Capability[]
+  readonly experimental: boolean
+  readonly deprecations: APIDeprecation[]
 }
 
 // From codersdk/licenses.go
@@ -28,6 +49,17 @@ export interface AgentStatsReportResponse {
   readonly num_comms: number
   readonly rx_bytes: number
   readonly tx_bytes: number
+  readonly usage?: AgentUsage
+}
+
+// From codersdk/workspaceusage.go
+export interface AgentUsage {
+  readonly cpu_used: number
+  readonly cpu_total: number
+  readonly memory_used_bytes: number
+  readonly memory_total_bytes: number
+  readonly disk_used_bytes: number
+  readonly disk_total_bytes: number
 }
 
 // From codersdk/roles.go
@@ -83,11 +115,13 @@ export interface AuditLogCountResponse {
 // From codersdk/audit.go
 export interface AuditLogResponse {
   readonly audit_logs: AuditLog[]
+  readonly next_cursor?: string
 }
 
 // From codersdk/audit.go
-export interface AuditLogsRequest extends Pagination {
+export interface AuditLogsRequest extends CursorPagination {
   readonly q?: string
+  readonly offset?: number
 }
 
 // From codersdk/users.go
@@ -103,6 +137,14 @@ export interface AuthorizationCheck {
   readonly action: string
 }
 
+// From codersdk/authorizationpolicy.go
+export interface AuthorizationGroupRoles {
+  readonly group_id: string
+  readonly group_name: string
+  readonly organization_id: string
+  readonly roles: string[]
+}
+
 // From codersdk/authorization.go
 export interface AuthorizationObject {
   readonly resource_type: string
@@ -111,6 +153,27 @@ export interface AuthorizationObject {
   readonly resource_id?: string
 }
 
+// From codersdk/authorizationpolicy.go
+export interface AuthorizationPermission {
+  readonly negate: boolean
+  readonly resource_type: string
+  readonly action: string
+}
+
+// From codersdk/authorization.go
+export interface AuthorizationPermissions {
+  readonly object: AuthorizationObject
+  readonly actions: string[]
+}
+
+// From codersdk/authorizationpolicy.go
+export interface AuthorizationPolicy {
+  readonly rego: string
+  readonly roles: AuthorizationRole[]
+  readonly scopes: AuthorizationRole[]
+  readonly group_roles: AuthorizationGroupRoles[]
+}
+
 // From codersdk/authorization.go
 export interface AuthorizationRequest {
   readonly checks: Record<string, AuthorizationCheck>
@@ -119,12 +182,70 @@ export interface AuthorizationRequest {
 // From codersdk/authorization.go
 export type AuthorizationResponse = Record<string, boolean>
 
+// From codersdk/authorizationpolicy.go
+export interface AuthorizationRole {
+  readonly name: string
+  readonly display_name: string
+  readonly site: AuthorizationPermission[]
+  readonly org: Record<string, AuthorizationPermission[]>
+  readonly user: AuthorizationPermission[]
+}
+
+// From codersdk/authorizationpolicy.go
+export interface AuthorizationSimulateRequest {
+  readonly user_id: string
+  readonly checks: Record<string, AuthorizationCheck>
+}
+
+// From codersdk/authorizationpolicy.go
+export interface AuthorizationSimulateResponse {
+  readonly user_id: string
+  readonly username: string
+  readonly status: UserStatus
+  readonly roles: string[]
+  readonly groups: string[]
+  readonly checks: AuthorizationResponse
+}
+
+// From codersdk/templates.go
+export interface AutostopExtensionPolicy {
+  readonly max_extensions_per_day: number
+  readonly max_extension_per_day_ms: number
+}
+
 // From codersdk/workspaceagents.go
 export interface AzureInstanceIdentityToken {
   readonly signature: string
   readonly encoding: string
 }
 
+// From codersdk/batch.go
+export interface BatchRequest {
+  readonly requests: BatchSubRequest[]
+}
+
+// From codersdk/batch.go
+export interface BatchResponse {
+  readonly responses: BatchSubResponse[]
+}
+
+// From codersdk/batch.go
+export interface BatchSubRequest {
+  readonly method: string
+  readonly path: string
+  readonly headers?: Record<string, string>
+  // This is likely an enum in an external package ("encoding/json.RawMessage")
+  readonly body?: string
+}
+
+// From codersdk/batch.go
+export interface BatchSubResponse {
+  readonly status_code: number
+  readonly headers: Record<string, string>
+  // This is likely an enum in an external package ("encoding/json.RawMessage")
+  readonly body?: string
+}
+
 // From codersdk/flags.go
 export interface BoolFlag {
   readonly name: string
@@ -143,6 +264,12 @@ export interface BuildInfoResponse {
   readonly version: string
 }
 
+// From codersdk/workspaces.go
+export interface CloneWorkspaceRequest {
+  readonly name: string
+  readonly copy_data?: boolean
+}
+
 // From codersdk/parameters.go
 export interface ComputedParameter extends Parameter {
   readonly source_value: string
@@ -167,6 +294,31 @@ export interface CreateFirstUserResponse {
 // From codersdk/groups.go
 export interface CreateGroupRequest {
   readonly name: string
+  readonly display_name?: string
+  readonly avatar_url?: string
+  readonly description?: string
+  readonly parent_id?: string
+}
+
+// From codersdk/groups.go
+export interface CreateGroupWebhookRequest {
+  readonly url: string
+  readonly secret?: string
+}
+
+// From codersdk/oauth2provider.go
+export interface CreateOAuth2ProviderAppRequest {
+  readonly name: string
+  readonly icon?: string
+  readonly callback_url: string
+}
+
+// From codersdk/organizationinvites.go
+export interface CreateOrganizationInviteRequest {
+  readonly email_domain: string
+  readonly roles: string[]
+  readonly expires_at?: string
+  readonly max_uses: number
 }
 
 // From codersdk/users.go
@@ -174,6 +326,13 @@ export interface CreateOrganizationRequest {
   readonly name: string
 }
 
+// From codersdk/organizationwebhooks.go
+export interface CreateOrganizationWebhookRequest {
+  readonly url: string
+  readonly secret?: string
+  readonly events?: OrganizationWebhookEventType[]
+}
+
 // From codersdk/parameters.go
 export interface CreateParameterRequest {
   readonly copy_from_parameter?: string
@@ -183,6 +342,12 @@ export interface CreateParameterRequest {
   readonly destination_scheme: ParameterDestinationScheme
 }
 
+// From codersdk/rolerequests.go
+export interface CreateRoleRequestRequest {
+  readonly role: string
+  readonly reason: string
+}
+
 // From codersdk/organizations.go
 export interface CreateTemplateRequest {
   readonly name: string
@@ -215,6 +380,14 @@ export interface CreateTestAuditLogRequest {
   readonly action?: AuditAction
   readonly resource_type?: ResourceType
   readonly resource_id?: string
+  readonly organization_id?: string
+}
+
+// From codersdk/apikey.go
+export interface CreateTokenRequest {
+  readonly scope?: APIKeyScope
+  readonly permissions?: string[]
+  readonly organization_id?: string
 }
 
 // From codersdk/users.go
@@ -225,6 +398,19 @@ export interface CreateUserRequest {
   readonly organization_id: string
 }
 
+// From codersdk/webhooks.go
+export interface CreateWebhookRequest {
+  readonly url: string
+  readonly secret?: string
+  readonly events?: WebhookEventType[]
+}
+
+// From codersdk/workspacebatches.go
+export interface CreateWorkspaceBatchRequest {
+  readonly action: WorkspaceBatchAction
+  readonly filter: WorkspaceBatchFilter
+}
+
 // From codersdk/workspaces.go
 export interface CreateWorkspaceBuildRequest {
   readonly template_version_id?: string
@@ -244,6 +430,12 @@ export interface CreateWorkspaceRequest {
   readonly parameter_values?: CreateParameterRequest[]
 }
 
+// From codersdk/pagination.go
+export interface CursorPagination {
+  readonly cursor?: string
+  readonly limit?: number
+}
+
 // From codersdk/templates.go
 export interface DAUEntry {
   readonly date: string
@@ -273,8 +465,10 @@ export interface DeploymentFlags {
   readonly prom_address: StringFlag
   readonly pprof_enabled: BoolFlag
   readonly pprof_address: StringFlag
+  readonly grpc_address: StringFlag
   readonly cache_dir: StringFlag
   readonly in_memory_database: BoolFlag
+  readonly in_memory_database_path: StringFlag
   readonly provisioner_daemon_count: IntFlag
   readonly postgres_url: StringFlag
   readonly oauth2_github_client_id: StringFlag
@@ -289,6 +483,9 @@ export interface DeploymentFlags {
   readonly oidc_email_domain: StringFlag
   readonly oidc_issuer_url: StringFlag
   readonly oidc_scopes: StringArrayFlag
+  readonly oidc_group_field: StringFlag
+  readonly oidc_group_regex_filter: StringFlag
+  readonly oidc_group_mapping: StringFlag
   readonly telemetry_enable: BoolFlag
   readonly telemetry_trace_enable: BoolFlag
   readonly telemetry_url: StringFlag
@@ -298,6 +495,8 @@ export interface DeploymentFlags {
   readonly tls_client_auth: StringFlag
   readonly tls_key_tiles: StringArrayFlag
   readonly tls_min_version: StringFlag
+  readonly tls_client_cert_auth_email: BoolFlag
+  readonly tls_client_cert_identities: StringArrayFlag
   readonly trace_enable: BoolFlag
   readonly secure_auth_cookie: BoolFlag
   readonly ssh_keygen_algorithm: StringFlag
@@ -305,10 +504,44 @@ export interface DeploymentFlags {
   readonly metrics_cache_refresh_interval: DurationFlag
   readonly agent_stat_refresh_interval: DurationFlag
   readonly verbose: BoolFlag
+  readonly authz_denial_log_percent: IntFlag
+  readonly api_key_rate_limit: IntFlag
+  readonly workspace_build_rate_limit: IntFlag
+  readonly workspace_build_rate_limit_by_organization: BoolFlag
   readonly audit_logging: BoolFlag
   readonly browser_only: BoolFlag
   readonly scim_auth_header: StringFlag
+  readonly scim_organization: StringFlag
   readonly user_workspace_quota: IntFlag
+  readonly group_workspace_quota: BoolFlag
+  readonly organization_workspace_quota: BoolFlag
+  readonly deleted_group_retention: DurationFlag
+  readonly oidc_group_metadata_provider: StringFlag
+  readonly oidc_group_metadata_url: StringFlag
+  readonly oidc_group_metadata_token: StringFlag
+  readonly oidc_group_metadata_sync_interval: DurationFlag
+  readonly autostop_max_extensions_per_day: IntFlag
+  readonly autostop_max_extension_per_day: DurationFlag
+  readonly archive_s3_endpoint: StringFlag
+  readonly archive_s3_region: StringFlag
+  readonly archive_s3_bucket: StringFlag
+  readonly archive_s3_access_key_id: StringFlag
+  readonly archive_s3_secret_access_key: StringFlag
+}
+
+// From codersdk/meta.go
+export interface DeprecatedRouteUsage {
+  readonly method: string
+  readonly path: string
+  readonly requests: number
+  readonly last_used_at: string
+  readonly user_agents: string[]
+}
+
+// From codersdk/meta.go
+export interface DeprecationReport {
+  readonly since: string
+  readonly routes: DeprecatedRouteUsage[]
 }
 
 // From codersdk/flags.go
@@ -334,6 +567,30 @@ export interface Entitlements {
   readonly trial: boolean
 }
 
+// From codersdk/groups.go
+export interface EveryoneGroupExclusion {
+  readonly user: User
+  readonly created_at: string
+}
+
+// From codersdk/workspaces.go
+export interface ExtendWorkspaceRequest {
+  readonly duration_ms: number
+}
+
+// From codersdk/workspaces.go
+export interface ExtendWorkspaceResponse {
+  readonly deadline: string
+  readonly extensions_left?: number
+  readonly extension_left_ms?: number
+}
+
+// From codersdk/favorites.go
+export interface Favorites {
+  readonly workspace_ids: string[]
+  readonly template_ids: string[]
+}
+
 // From codersdk/features.go
 export interface Feature {
   readonly entitlement: Entitlement
@@ -364,8 +621,138 @@ export interface GitSSHKey {
 export interface Group {
   readonly id: string
   readonly name: string
+  readonly display_name: string
+  readonly avatar_url: string
+  readonly description: string
+  readonly organization_id: string
+  readonly parent_id?: string
+  readonly source: GroupSource
+  readonly members: GroupMember[]
+  readonly members_count: number
+  readonly metadata: Record<string, string>
+  readonly autostop_schedule: string
+  readonly max_ttl_ms: number
+  readonly quota_allowance: number
+  readonly roles: string[]
+}
+
+// From codersdk/groups.go
+export interface GroupDeletionImpact {
+  readonly templates: GroupDeletionImpactTemplate[]
+  readonly workspaces: GroupDeletionImpactWorkspace[]
+  readonly child_groups: string[]
+  readonly quota_allowance: number
+  readonly quota_allowance_total: number
+}
+
+// From codersdk/groups.go
+export interface GroupDeletionImpactTemplate {
+  readonly id: string
+  readonly name: string
+  readonly role: TemplateRole
+}
+
+// From codersdk/groups.go
+export interface GroupDeletionImpactWorkspace {
+  readonly id: string
+  readonly name: string
+  readonly owner_id: string
+  readonly template_id: string
+}
+
+// From codersdk/groups.go
+export interface GroupJoinRequest {
+  readonly id: string
+  readonly group_id: string
+  readonly user_id: string
+  readonly username: string
+  readonly created_at: string
+}
+
+// From codersdk/groups.go
+export interface GroupMember extends User {
+  readonly expires_at?: string
+  readonly group_roles?: string[]
+}
+
+// From codersdk/groups.go
+export interface GroupMemberImportError {
+  readonly row: number
+  readonly value: string
+  readonly reason: GroupMemberSkipReason
+}
+
+// From codersdk/groups.go
+export interface GroupMembersResponse {
+  readonly members: GroupMember[]
+  readonly count: number
+  readonly next_cursor?: string
+}
+
+// From codersdk/groups.go
+export interface GroupSyncChange {
+  readonly group_id?: string
+  readonly name: string
+}
+
+// From codersdk/groups.go
+export interface GroupSyncDryRunRequest {
+  readonly user?: string
+  // eslint-disable-next-line
+  readonly claims: Record<string, any>
+}
+
+// From codersdk/groups.go
+export interface GroupSyncDryRunResponse {
+  readonly enabled: boolean
+  readonly groups: string[]
+  readonly add: GroupSyncChange[]
+  readonly remove: GroupSyncChange[]
+  readonly skipped: GroupSyncChange[]
+}
+
+// From codersdk/groups.go
+export interface GroupTemplate {
+  readonly id: string
+  readonly name: string
+  readonly icon: string
+  readonly organization_id: string
+  readonly role: TemplateRole
+}
+
+// From codersdk/groups.go
+export interface GroupWebhook {
+  readonly id: string
   readonly organization_id: string
-  readonly members: User[]
+  readonly url: string
+  readonly secret?: string
+  readonly created_at: string
+}
+
+// From codersdk/groups.go
+export interface GroupWebhookEvent {
+  readonly id: string
+  readonly type: GroupWebhookEventType
+  readonly created_at: string
+  readonly organization_id: string
+  readonly group_id: string
+  readonly group_name: string
+  readonly previous_group_name?: string
+  readonly user_ids?: string[]
+}
+
+// From codersdk/groups.go
+export interface GroupsRequest extends CursorPagination {
+  readonly q?: string
+  readonly include_members?: boolean
+  readonly fields?: string[]
+}
+
+// From codersdk/groups.go
+export interface GroupsResponse {
+  readonly groups: Group[]
+  readonly count: number
+  readonly next_cursor?: string
 }
 
 // From codersdk/workspaceapps.go
@@ -375,6 +762,13 @@ export interface Healthcheck {
   readonly threshold: number
 }
 
+// From codersdk/groups.go
+export interface ImportGroupMembersResponse {
+  readonly group: Group
+  readonly added: string[]
+  readonly errors: GroupMemberImportError[]
+}
+
 // From codersdk/flags.go
 export interface IntFlag {
   readonly name: string
@@ -418,21 +812,309 @@ export interface LoginWithPasswordResponse {
   readonly session_token: string
 }
 
-// From codersdk/organizations.go
-export interface Organization {
+// From codersdk/maintenance.go
+export interface Maintenance {
+  readonly enabled: boolean
+  readonly message: string
+  readonly retry_after_seconds: number
+  readonly updated_at: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2Authorization {
+  readonly app: OAuth2ProviderApp
+  readonly scopes: string[]
+  readonly redirect_uri: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2AuthorizeRequest {
+  readonly client_id: string
+  readonly redirect_uri?: string
+  readonly scope?: string
+  readonly state?: string
+  readonly code_challenge?: string
+  readonly code_challenge_method?: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2AuthorizeResponse {
+  readonly redirect_uri: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2Error {
+  readonly error: string
+  readonly error_description?: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2Introspection {
+  readonly active: boolean
+  readonly scope?: string
+  readonly client_id?: string
+  readonly username?: string
+  readonly token_type?: string
+  readonly exp?: number
+  readonly iat?: number
+  readonly sub?: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2ProviderApp {
+  readonly id: string
+  readonly name: string
+  readonly icon: string
+  readonly callback_url: string
+  readonly client_secret?: string
+  readonly created_at: string
+  readonly updated_at: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2TokenResponse {
+  readonly access_token: string
+  readonly token_type: string
+  readonly expires_in: number
+  readonly scope: string
+}
+
+// From codersdk/oauth2provider.go
+export interface OAuth2UserInfo {
+  readonly sub: string
+  readonly preferred_username: string
+  readonly email: string
+  readonly picture?: string
+}
+
+// From codersdk/organizationoidc.go
+export interface OIDCLoginRoute {
+  readonly organization_id: string
+  readonly url: string
+}
+
+// From codersdk/operations.go
+export interface Operation {
+  readonly id: string
+  readonly type: OperationType
+  readonly status: OperationStatus
+  readonly initiator_id: string
+  readonly organization_id: string
+  readonly resource_id: string
+  readonly progress_completed: number
+  readonly progress_total: number
+  readonly error?: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly completed_at?: string
+}
+
+// From codersdk/organizations.go
+export interface Organization {
+  readonly id: string
+  readonly name: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly is_default: boolean
+  readonly display_name: string
+  readonly icon: string
+  readonly description: string
+}
+
+// From codersdk/organizationdeletions.go
+export interface OrganizationDeletion {
+  readonly organization_id: string
+  readonly initiator_id: string
+  readonly operation_id: string
+  readonly status: OrganizationDeletionStatus
+  readonly error?: string
+  readonly workspaces_total: number
+  readonly workspaces_deleted: number
+  readonly templates_total: number
+  readonly templates_deleted: number
+  readonly members_total: number
+  readonly members_deleted: number
+  readonly created_at: string
+  readonly updated_at: string
+  readonly completed_at?: string
+}
+
+// From codersdk/organizationipallowlists.go
+export interface OrganizationIPAllowlist {
+  readonly organization_id: string
+  readonly cidrs: string[]
+  readonly updated_at: string
+}
+
+// From codersdk/organizationinsights.go
+export interface OrganizationInsights {
+  readonly organization_id: string
+  readonly start_time: string
+  readonly end_time: string
+  readonly active_users: number
+  readonly workspace_hours: number
+  readonly builds: number
+  readonly failed_builds: number
+  readonly templates: OrganizationTemplateInsights[]
+}
+
+// From codersdk/organizationinsights.go
+export interface OrganizationInsightsRequest {
+  readonly start_time?: string
+  readonly end_time?: string
+}
+
+// From codersdk/organizationinvites.go
+export interface OrganizationInvite {
+  readonly id: string
+  readonly organization_id: string
+  readonly email_domain: string
+  readonly roles: string[]
+  readonly created_by: string
+  readonly created_at: string
+  readonly expires_at?: string
+  readonly max_uses: number
+  readonly uses: number
+  readonly token?: string
+}
+
+// From codersdk/organizationmember.go
+export interface OrganizationMember {
+  readonly user_id: string
+  readonly organization_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly roles: Role[]
+}
+
+// From codersdk/organizationmember.go
+export interface OrganizationMemberGroup {
+  readonly id: string
+  readonly name: string
+}
+
+// From codersdk/users.go
+export interface OrganizationMemberRolesUpdate {
+  readonly user: string
+  readonly roles: string[]
+}
+
+// From codersdk/organizationmember.go
+export interface OrganizationMemberWithUser {
+  readonly user_id: string
+  readonly organization_id: string
+  readonly username: string
+  readonly email: string
+  readonly avatar_url: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly roles: Role[]
+  readonly groups: OrganizationMemberGroup[]
+}
+
+// From codersdk/organizationmember.go
+export interface OrganizationMembersRequest extends CursorPagination {
+  readonly q?: string
+}
+
+// From codersdk/organizationmember.go
+export interface OrganizationMembersResponse {
+  readonly members: OrganizationMemberWithUser[]
+  readonly next_cursor?: string
+}
+
+// From codersdk/organizationoidc.go
+export interface OrganizationOIDCConfig {
+  readonly organization_id: string
+  readonly issuer_url: string
+  readonly client_id: string
+  readonly scopes: string[]
+  readonly email_domains: string[]
+  readonly username_field: string
+  readonly email_field: string
+  readonly allow_signups: boolean
+  readonly created_at: string
+  readonly updated_at: string
+}
+
+// From codersdk/workspacequota.go
+export interface OrganizationQuota {
+  readonly max_workspaces: number
+  readonly max_running_workspaces: number
+  readonly compute_credits: number
+}
+
+// From codersdk/workspacequota.go
+export interface OrganizationQuotaConsumption {
+  readonly organization_id: string
+  readonly quota: OrganizationQuota
+  readonly workspace_count: number
+  readonly running_workspace_count: number
+  readonly compute_credits_consumed: number
+  readonly templates: OrganizationQuotaTemplate[]
+}
+
+// From codersdk/workspacequota.go
+export interface OrganizationQuotaTemplate {
+  readonly template_id: string
+  readonly template_name: string
+  readonly weight: number
+  readonly workspace_count: number
+  readonly running_workspace_count: number
+  readonly compute_credits_consumed: number
+}
+
+// From codersdk/organizationtemplatedefaults.go
+export interface OrganizationTemplateDefaults {
+  readonly organization_id: string
+  readonly default_ttl_ms: number
+  readonly max_ttl_ms: number
+  readonly min_autostart_interval_ms: number
+  readonly parameter_values: CreateParameterRequest[]
+  readonly allowed_parameter_values: Record<string, string[]>
+  readonly updated_at: string
+}
+
+// From codersdk/organizationinsights.go
+export interface OrganizationTemplateInsights {
+  readonly template_id: string
+  readonly template_name: string
+  readonly active_users: number
+  readonly workspace_hours: number
+  readonly builds: number
+  readonly failed_builds: number
+}
+
+// From codersdk/organizationwebhooks.go
+export interface OrganizationWebhook {
   readonly id: string
-  readonly name: string
+  readonly organization_id: string
+  readonly url: string
+  readonly events: OrganizationWebhookEventType[]
+  readonly secret?: string
   readonly created_at: string
-  readonly updated_at: string
 }
 
-// From codersdk/organizationmember.go
-export interface OrganizationMember {
-  readonly user_id: string
-  readonly organization_id: string
+// From codersdk/organizationwebhooks.go
+export interface OrganizationWebhookDelivery {
+  readonly id: string
+  readonly webhook_id: string
+  readonly event_id: string
+  readonly event_type: OrganizationWebhookEventType
+  readonly attempt: number
+  readonly status_code: number
+  readonly error?: string
   readonly created_at: string
-  readonly updated_at: string
-  readonly roles: Role[]
+}
+
+// From codersdk/organizationwebhooks.go
+export interface OrganizationWebhookEvent {
+  readonly id: string
+  readonly type: OrganizationWebhookEventType
+  readonly created_at: string
+  readonly organization_id: string
+  readonly resource_id: string
+  readonly resource_name: string
 }
 
 // From codersdk/pagination.go
@@ -479,7 +1161,17 @@ export interface ParameterSchema {
 export interface PatchGroupRequest {
   readonly add_users: string[]
   readonly remove_users: string[]
+  readonly add_users_expire_at?: string
   readonly name: string
+  readonly display_name?: string
+  readonly avatar_url?: string
+  readonly description?: string
+  readonly parent_id?: string
+  readonly metadata?: Record<string, string>
+  readonly autostop_schedule?: string
+  readonly max_ttl_ms?: number
+  readonly quota_allowance?: number
+  readonly roles?: string[]
 }
 
 // From codersdk/provisionerdaemons.go
@@ -518,11 +1210,43 @@ export interface PutExtendWorkspaceRequest {
   readonly deadline: string
 }
 
+// From codersdk/groups.go
+export interface PutGroupMembersRequest {
+  readonly user_ids: string[]
+}
+
+// From codersdk/groups.go
+export interface PutGroupMembersResponse {
+  readonly group: Group
+  readonly added: string[]
+  readonly removed: string[]
+  readonly skipped: SkippedGroupMember[]
+}
+
+// From codersdk/organizationinvites.go
+export interface RedeemOrganizationInviteRequest {
+  readonly token: string
+  readonly email?: string
+  readonly username?: string
+  readonly password?: string
+}
+
+// From codersdk/events.go
+export interface ResourceEvent {
+  readonly id: string
+  readonly created_at: string
+  readonly type: ResourceEventType
+  readonly action: ResourceEventAction
+  readonly organization_id: string
+  readonly resource_id: string
+}
+
 // From codersdk/error.go
 export interface Response {
   readonly message: string
   readonly detail?: string
   readonly validations?: ValidationError[]
+  readonly code?: ErrorCode
 }
 
 // From codersdk/roles.go
@@ -531,6 +1255,35 @@ export interface Role {
   readonly display_name: string
 }
 
+// From codersdk/rolerequests.go
+export interface RoleRequest {
+  readonly id: string
+  readonly user_id: string
+  readonly username: string
+  readonly organization_id?: string
+  readonly role: Role
+  readonly reason: string
+  readonly status: RoleRequestStatus
+  readonly created_at: string
+  readonly reviewed_by?: string
+  readonly reviewed_at?: string
+}
+
+// From codersdk/search.go
+export interface SearchRequest {
+  readonly q: string
+  readonly limit?: number
+}
+
+// From codersdk/search.go
+export interface SearchResult {
+  readonly type: SearchResultType
+  readonly id: string
+  readonly name: string
+  readonly display_name?: string
+  readonly organization_id: string
+}
+
 // From codersdk/sse.go
 export interface ServerSentEvent {
   readonly type: ServerSentEventType
@@ -538,6 +1291,12 @@ export interface ServerSentEvent {
   readonly data: any
 }
 
+// From codersdk/groups.go
+export interface SkippedGroupMember {
+  readonly user_id: string
+  readonly reason: GroupMemberSkipReason
+}
+
 // From codersdk/flags.go
 export interface StringArrayFlag {
   readonly name: string
@@ -580,6 +1339,8 @@ export interface Template {
   readonly min_autostart_interval_ms: number
   readonly created_by_id: string
   readonly created_by_name: string
+  readonly quota_weight: number
+  readonly workspace_labels: Record<string, string>
 }
 
 // From codersdk/templates.go
@@ -588,16 +1349,74 @@ export interface TemplateACL {
   readonly group: TemplateGroup[]
 }
 
+// From codersdk/templates.go
+export interface TemplateAccessSource {
+  readonly role: TemplateRole
+  readonly group_id?: string
+  readonly group_name?: string
+}
+
+// From codersdk/workspacearchives.go
+export interface TemplateArchivePolicy {
+  readonly dormancy_threshold_ms: number
+  readonly retention_ms: number
+}
+
+// From codersdk/workspaceautorebuilds.go
+export interface TemplateAutoRebuildPolicy {
+  readonly failure_threshold: number
+  readonly agent_timeout_ms: number
+  readonly action: AutoRebuildAction
+}
+
+// From codersdk/templates.go
+export interface TemplateAutostopExtensionPolicy
+  extends AutostopExtensionPolicy {
+  readonly inherited: boolean
+}
+
 // From codersdk/templates.go
 export interface TemplateDAUsResponse {
   readonly entries: DAUEntry[]
 }
 
+// From codersdk/templatedeprecations.go
+export interface TemplateDeprecation {
+  readonly message: string
+  readonly successor_id?: string
+  readonly successor_name?: string
+  readonly parameter_mapping: Record<string, string>
+  readonly deprecated_at: string
+}
+
+// From codersdk/templates.go
+export interface TemplateEffectiveAccess {
+  readonly users: TemplateEffectiveUser[]
+}
+
+// From codersdk/templates.go
+export interface TemplateEffectiveUser extends User {
+  readonly role: TemplateRole
+  readonly sources: TemplateAccessSource[]
+}
+
 // From codersdk/templates.go
 export interface TemplateGroup extends Group {
   readonly role: TemplateRole
 }
 
+// From codersdk/templates.go
+export interface TemplateMaintenanceWindow {
+  readonly schedule: string
+  readonly duration_ms: number
+  readonly exempt_group_ids: string[]
+}
+
+// From codersdk/workspacecosts.go
+export interface TemplateResourceCosts {
+  readonly hourly_costs: Record<string, number>
+}
+
 // From codersdk/templates.go
 export interface TemplateUser extends User {
   readonly role: TemplateRole
@@ -617,16 +1436,116 @@ export interface TemplateVersion {
   readonly created_by_name: string
 }
 
+// From codersdk/templateversiondiffs.go
+export interface TemplateVersionDiff {
+  readonly from_version_id: string
+  readonly to_version_id: string
+  readonly files: TemplateVersionFileDiff[]
+  readonly parameters: TemplateVersionParameterDiff[]
+  readonly resources: TemplateVersionResourceDiff[]
+}
+
+// From codersdk/templateversiondiffs.go
+export interface TemplateVersionFileDiff {
+  readonly path: string
+  readonly status: TemplateVersionDiffStatus
+  readonly diff: string
+}
+
+// From codersdk/templateversiondiffs.go
+export interface TemplateVersionParameterDiff {
+  readonly name: string
+  readonly status: TemplateVersionDiffStatus
+  readonly from?: ParameterSchema
+  readonly to?: ParameterSchema
+}
+
+// From codersdk/templateversiondiffs.go
+export interface TemplateVersionResourceDiff {
+  readonly workspace_transition: WorkspaceTransition
+  readonly type: string
+  readonly name: string
+  readonly status: TemplateVersionDiffStatus
+  readonly agents_added: string[]
+  readonly agents_removed: string[]
+  readonly metadata_changed: string[]
+}
+
 // From codersdk/templates.go
 export interface TemplateVersionsByTemplateRequest extends Pagination {
   readonly template_id: string
 }
 
+// From codersdk/organizations.go
+export interface TemplatesResponse {
+  readonly templates: Template[]
+  readonly next_cursor?: string
+}
+
+// From codersdk/workspaces.go
+export interface TransferWorkspaceRequest {
+  readonly organization_id: string
+  readonly template_id: string
+}
+
 // From codersdk/templates.go
 export interface UpdateActiveTemplateVersion {
   readonly id: string
 }
 
+// From codersdk/maintenance.go
+export interface UpdateMaintenanceRequest {
+  readonly enabled: boolean
+  readonly message: string
+  readonly retry_after_seconds: number
+}
+
+// From codersdk/oauth2provider.go
+export interface UpdateOAuth2ProviderAppRequest {
+  readonly name: string
+  readonly icon?: string
+  readonly callback_url: string
+}
+
+// From codersdk/organizationipallowlists.go
+export interface UpdateOrganizationIPAllowlistRequest {
+  readonly cidrs: string[]
+}
+
+// From codersdk/users.go
+export interface UpdateOrganizationMembersRolesRequest {
+  readonly updates: OrganizationMemberRolesUpdate[]
+}
+
+// From codersdk/organizationoidc.go
+export interface UpdateOrganizationOIDCConfigRequest {
+  readonly issuer_url: string
+  readonly client_id: string
+  readonly client_secret?: string
+  readonly scopes?: string[]
+  readonly email_domains?: string[]
+  readonly username_field?: string
+  readonly email_field?: string
+  readonly allow_signups: boolean
+}
+
+// From codersdk/organizations.go
+export interface UpdateOrganizationRequest {
+  readonly name?: string
+  readonly display_name?: string
+  readonly icon?: string
+  readonly description?: string
+}
+
+// From codersdk/organizationtemplatedefaults.go
+export interface UpdateOrganizationTemplateDefaultsRequest {
+  readonly default_ttl_ms: number
+  readonly max_ttl_ms: number
+  readonly min_autostart_interval_ms: number
+  readonly parameter_values?: CreateParameterRequest[]
+  readonly allowed_parameter_values?: Record<string, string[]>
+}
+
 // From codersdk/users.go
 export interface UpdateRoles {
   readonly roles: string[]
@@ -638,6 +1557,13 @@ export interface UpdateTemplateACL {
   readonly group_perms?: Record<string, TemplateRole>
 }
 
+// From codersdk/templatedeprecations.go
+export interface UpdateTemplateDeprecationRequest {
+  readonly message: string
+  readonly successor_id?: string
+  readonly parameter_mapping?: Record<string, string>
+}
+
 // From codersdk/templates.go
 export interface UpdateTemplateMeta {
   readonly name?: string
@@ -645,6 +1571,12 @@ export interface UpdateTemplateMeta {
   readonly icon?: string
   readonly max_ttl_ms?: number
   readonly min_autostart_interval_ms?: number
+  readonly workspace_labels?: Record<string, string>
+}
+
+// From codersdk/templates.go
+export interface UpdateTemplateQuotaRequest {
+  readonly weight: number
 }
 
 // From codersdk/users.go
@@ -658,11 +1590,27 @@ export interface UpdateUserProfileRequest {
   readonly username: string
 }
 
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceACL {
+  readonly user_perms?: Record<string, WorkspaceRole>
+  readonly group_perms?: Record<string, WorkspaceRole>
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceAutostartRequest {
   readonly schedule?: string
 }
 
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceLabelsRequest {
+  readonly labels: Record<string, string>
+}
+
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceOwnerRequest {
+  readonly owner_id: string
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceRequest {
   readonly name?: string
@@ -700,6 +1648,7 @@ export interface UserRoles {
 // From codersdk/users.go
 export interface UsersRequest extends Pagination {
   readonly q?: string
+  readonly fields?: string[]
 }
 
 // From codersdk/error.go
@@ -708,6 +1657,38 @@ export interface ValidationError {
   readonly detail: string
 }
 
+// From codersdk/webhooks.go
+export interface Webhook {
+  readonly id: string
+  readonly url: string
+  readonly events: WebhookEventType[]
+  readonly secret?: string
+  readonly created_at: string
+}
+
+// From codersdk/webhooks.go
+export interface WebhookDelivery {
+  readonly id: string
+  readonly webhook_id: string
+  readonly event_id: string
+  readonly event_type: WebhookEventType
+  readonly attempt: number
+  readonly redelivery: boolean
+  readonly status_code: number
+  readonly error?: string
+  readonly created_at: string
+}
+
+// From codersdk/webhooks.go
+export interface WebhookEvent {
+  readonly id: string
+  readonly type: WebhookEventType
+  readonly created_at: string
+  readonly organization_id: string
+  readonly resource_id: string
+  readonly resource_name: string
+}
+
 // From codersdk/workspaces.go
 export interface Workspace {
   readonly id: string
@@ -724,6 +1705,16 @@ export interface Workspace {
   readonly autostart_schedule?: string
   readonly ttl_ms?: number
   readonly last_used_at: string
+  readonly acl: WorkspaceACL
+  readonly labels: Record<string, string>
+  readonly maintenance_at?: string
+  readonly template_deprecation?: TemplateDeprecation
+}
+
+// From codersdk/workspaces.go
+export interface WorkspaceACL {
+  readonly user_perms: Record<string, WorkspaceRole>
+  readonly group_perms: Record<string, WorkspaceRole>
 }
 
 // From codersdk/workspaceagents.go
@@ -770,6 +1761,18 @@ export interface WorkspaceAgentResourceMetadata {
   readonly cpu_mhz: number
 }
 
+// From codersdk/workspaceusage.go
+export interface WorkspaceAgentUsage {
+  readonly agent_id: string
+  readonly agent_name: string
+  readonly samples: WorkspaceAgentUsageSample[]
+}
+
+// From codersdk/workspaceusage.go
+export interface WorkspaceAgentUsageSample extends AgentUsage {
+  readonly time: string
+}
+
 // From codersdk/workspaceapps.go
 export interface WorkspaceApp {
   readonly id: string
@@ -781,6 +1784,41 @@ export interface WorkspaceApp {
   readonly health: WorkspaceAppHealth
 }
 
+// From codersdk/workspacearchives.go
+export interface WorkspaceArchive {
+  readonly workspace_id: string
+  readonly status: WorkspaceArchiveStatus
+  readonly object_key: string
+  readonly build_id: string
+  readonly error?: string
+  readonly created_at: string
+  readonly archived_at?: string
+  readonly expires_at?: string
+}
+
+// From codersdk/workspacebatches.go
+export interface WorkspaceBatch {
+  readonly operation: Operation
+  readonly results: WorkspaceBatchResult[]
+}
+
+// From codersdk/workspacebatches.go
+export interface WorkspaceBatchFilter {
+  readonly owner?: string
+  readonly template?: string
+  readonly name?: string
+  readonly dormant_ms?: number
+}
+
+// From codersdk/workspacebatches.go
+export interface WorkspaceBatchResult {
+  readonly workspace_id: string
+  readonly workspace_name: string
+  readonly status: WorkspaceBatchResultStatus
+  readonly build_id?: string
+  readonly error?: string
+}
+
 // From codersdk/workspacebuilds.go
 export interface WorkspaceBuild {
   readonly id: string
@@ -808,9 +1846,43 @@ export interface WorkspaceBuildsRequest extends Pagination {
   readonly Since: string
 }
 
+// From codersdk/workspacecosts.go
+export interface WorkspaceCostEntry {
+  readonly id: string
+  readonly name: string
+  readonly cost: number
+  readonly running_hours: number
+}
+
+// From codersdk/workspacecosts.go
+export interface WorkspaceCostReport {
+  readonly organization_id: string
+  readonly start_time: string
+  readonly end_time: string
+  readonly group_by: WorkspaceCostGroupBy
+  readonly total_cost: number
+  readonly entries: WorkspaceCostEntry[]
+}
+
+// From codersdk/workspacecosts.go
+export interface WorkspaceCostReportRequest {
+  readonly start_time?: string
+  readonly end_time?: string
+  readonly group_by?: WorkspaceCostGroupBy
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceFilter {
   readonly q?: string
+  readonly fields?: string[]
+  readonly sort?: string
+}
+
+// From codersdk/workspacenamepolicies.go
+export interface WorkspaceNamePolicy {
+  readonly pattern: string
+  readonly prefix: string
+  readonly reserved_names: string[]
 }
 
 // From codersdk/workspaces.go
@@ -822,6 +1894,33 @@ export interface WorkspaceOptions {
 export interface WorkspaceQuota {
   readonly user_workspace_count: number
   readonly user_workspace_limit: number
+  readonly budgets?: WorkspaceQuotaBudget[]
+}
+
+// From codersdk/workspacequota.go
+export interface WorkspaceQuotaBudget {
+  readonly organization_id: string
+  readonly allowance: number
+  readonly consumed: number
+  readonly groups: WorkspaceQuotaGroup[]
+  readonly templates: WorkspaceQuotaTemplate[]
+}
+
+// From codersdk/workspacequota.go
+export interface WorkspaceQuotaGroup {
+  readonly group_id: string
+  readonly group_name: string
+  readonly allowance: number
+  readonly consumed: number
+}
+
+// From codersdk/workspacequota.go
+export interface WorkspaceQuotaTemplate {
+  readonly template_id: string
+  readonly template_name: string
+  readonly weight: number
+  readonly workspace_count: number
+  readonly consumed: number
 }
 
 // From codersdk/workspacebuilds.go
@@ -845,15 +1944,121 @@ export interface WorkspaceResourceMetadata {
   readonly sensitive: boolean
 }
 
+// From codersdk/workspacetimeline.go
+export interface WorkspaceTimeline {
+  readonly workspace_id: string
+  readonly start_time: string
+  readonly end_time: string
+  readonly entries: WorkspaceTimelineEntry[]
+}
+
+// From codersdk/workspacetimeline.go
+export interface WorkspaceTimelineEntry {
+  readonly time: string
+  readonly type: WorkspaceTimelineEntryType
+  readonly description: string
+  readonly user_id?: string
+  readonly username?: string
+  readonly build_id?: string
+  readonly build_number?: number
+  readonly transition?: WorkspaceTransition
+  readonly build_reason?: BuildReason
+  readonly job_status?: ProvisionerJobStatus
+  readonly autostop_extension_ms?: number
+  readonly agent_id?: string
+  readonly agent_name?: string
+  readonly app_name?: string
+}
+
+// From codersdk/workspacetimeline.go
+export interface WorkspaceTimelineRequest {
+  readonly start_time?: string
+  readonly end_time?: string
+  readonly limit?: number
+}
+
+// From codersdk/workspaceusage.go
+export interface WorkspaceUsage {
+  readonly workspace_id: string
+  readonly agents: WorkspaceAgentUsage[]
+}
+
+// From codersdk/workspaceusage.go
+export interface WorkspaceUsageRequest {
+  readonly start_time?: string
+}
+
+// From codersdk/apikey.go
+export type APIKeyScope = "all" | "application_connect" | "restricted"
+
 // From codersdk/audit.go
-export type AuditAction = "create" | "delete" | "write"
+export type AuditAction = "create" | "delete" | "deny" | "write"
+
+// From codersdk/workspaceautorebuilds.go
+export type AutoRebuildAction = "rebuild" | "restart"
 
 // From codersdk/workspacebuilds.go
-export type BuildReason = "autostart" | "autostop" | "initiator"
+export type BuildReason =
+  | "agent_failure"
+  | "autostart"
+  | "autostop"
+  | "initiator"
+  | "maintenance"
+
+// From codersdk/meta.go
+export type Capability =
+  | "deprecation_headers"
+  | "error_codes"
+  | "groups"
+  | "openapi"
+  | "organization_members"
+  | "rpc"
 
 // From codersdk/features.go
 export type Entitlement = "entitled" | "grace_period" | "not_entitled"
 
+// From codersdk/error.go
+export type ErrorCode =
+  | "edit_conflict"
+  | "forbidden"
+  | "group_cycle"
+  | "group_join_request_exists"
+  | "group_managed"
+  | "group_member_exists"
+  | "group_member_not_found"
+  | "group_name_reserved"
+  | "group_name_taken"
+  | "internal_error"
+  | "invalid_query_parameter"
+  | "invalid_request_body"
+  | "ip_not_allowed"
+  | "maintenance"
+  | "org_member_required"
+  | "quota_exceeded"
+  | "request_timeout"
+  | "request_too_large"
+  | "resource_not_found"
+  | "route_not_found"
+  | "validation_failed"
+
+// From codersdk/groups.go
+export type GroupMemberSkipReason =
+  | "duplicate"
+  | "invalid_id"
+  | "not_org_member"
+  | "unknown_user"
+
+// From codersdk/groups.go
+export type GroupSource = "oidc" | "user"
+
+// From codersdk/groups.go
+export type GroupWebhookEventType =
+  | "group.created"
+  | "group.deleted"
+  | "group.members_added"
+  | "group.members_removed"
+  | "group.renamed"
+
 // From codersdk/agentconn.go
 export type ListeningPortNetwork = "tcp"
 
@@ -866,6 +2071,23 @@ export type LogSource = "provisioner" | "provisioner_daemon"
 // From codersdk/apikey.go
 export type LoginType = "github" | "oidc" | "password" | "token"
 
+// From codersdk/operations.go
+export type OperationStatus = "failed" | "running" | "succeeded"
+
+// From codersdk/operations.go
+export type OperationType = "organization_deletion" | "workspace_batch"
+
+// From codersdk/organizationdeletions.go
+export type OrganizationDeletionStatus = "failed" | "running" | "succeeded"
+
+// From codersdk/organizationwebhooks.go
+export type OrganizationWebhookEventType =
+  | "member.added"
+  | "member.removed"
+  | "template.published"
+  | "workspace.created"
+  | "workspace.deleted"
+
 // From codersdk/parameters.go
 export type ParameterDestinationScheme =
   | "environment_variable"
@@ -896,25 +2118,54 @@ export type ProvisionerStorageMethod = "file"
 // From codersdk/organizations.go
 export type ProvisionerType = "echo" | "terraform"
 
+// From codersdk/events.go
+export type ResourceEventAction = "created" | "deleted" | "updated"
+
+// From codersdk/events.go
+export type ResourceEventType = "group" | "organization_member" | "workspace"
+
 // From codersdk/audit.go
 export type ResourceType =
   | "api_key"
   | "git_ssh_key"
+  | "group_member"
   | "organization"
+  | "organization_member"
   | "template"
   | "template_version"
   | "user"
   | "workspace"
 
+// From codersdk/rolerequests.go
+export type RoleRequestStatus = "approved" | "denied" | "pending"
+
+// From codersdk/search.go
+export type SearchResultType = "group" | "template" | "user" | "workspace"
+
 // From codersdk/sse.go
 export type ServerSentEventType = "data" | "error" | "ping"
 
 // From codersdk/templates.go
 export type TemplateRole = "" | "admin" | "view"
 
+// From codersdk/templateversiondiffs.go
+export type TemplateVersionDiffStatus = "added" | "modified" | "removed"
+
 // From codersdk/users.go
 export type UserStatus = "active" | "suspended"
 
+// From codersdk/webhooks.go
+export type WebhookEventType =
+  | "group.created"
+  | "group.deleted"
+  | "user.activated"
+  | "user.created"
+  | "user.deleted"
+  | "user.suspended"
+  | "workspace.created"
+  | "workspace.deleted"
+  | "workspace_build.created"
+
 // From codersdk/workspaceagents.go
 export type WorkspaceAgentStatus = "connected" | "connecting" | "disconnected"
 
@@ -925,6 +2176,33 @@ export type WorkspaceAppHealth =
   | "initializing"
   | "unhealthy"
 
+// From codersdk/workspacearchives.go
+export type WorkspaceArchiveStatus =
+  | "archived"
+  | "archiving"
+  | "failed"
+  | "restoring"
+
+// From codersdk/workspacebatches.go
+export type WorkspaceBatchAction = "delete" | "start" | "stop" | "update"
+
+// From codersdk/workspacebatches.go
+export type WorkspaceBatchResultStatus =
+  | "failed"
+  | "pending"
+  | "running"
+  | "succeeded"
+
+// From codersdk/workspacecosts.go
+export type WorkspaceCostGroupBy =
+  | "group"
+  | "organization"
+  | "template"
+  | "user"
+
+// From codersdk/workspaces.go
+export type WorkspaceRole = "" | "app" | "ssh" | "view"
+
 // From codersdk/workspacebuilds.go
 export type WorkspaceStatus =
   | "canceled"
@@ -938,5 +2216,13 @@ export type WorkspaceStatus =
   | "stopped"
   | "stopping"
 
+// From codersdk/workspacetimeline.go
+export type WorkspaceTimelineEntryType =
+  | "agent_connected"
+  | "agent_disconnected"
+  | "app_opened"
+  | "autostop_extended"
+  | "build"
+
 // From codersdk/workspacebuilds.go
 export type WorkspaceTransition = "delete" | "start" | "stop"
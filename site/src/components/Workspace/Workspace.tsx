@@ -110,6 +110,23 @@ export const Workspace: FC<React.PropsWithChildren<WorkspaceProps>> = ({
     />
   )
 
+  const deprecation = workspace.template_deprecation
+  const templateDeprecatedWarning = deprecation && (
+    <AlertBanner
+      severity="warning"
+      text={[
+        deprecation.successor_name
+          ? t("warningsAndErrors.templateDeprecatedWarning", {
+              successor: deprecation.successor_name,
+            })
+          : t("warningsAndErrors.templateDeprecatedNoSuccessorWarning"),
+        deprecation.message,
+      ]
+        .filter(Boolean)
+        .join(" ")}
+    />
+  )
+
   return (
     <Margins>
       <PageHeader
@@ -165,6 +182,7 @@ export const Workspace: FC<React.PropsWithChildren<WorkspaceProps>> = ({
         {buildError}
         {cancellationError}
         {workspaceRefreshWarning}
+        {templateDeprecatedWarning}
 
         <WorkspaceScheduleBanner
           isLoading={bannerProps.isLoading}
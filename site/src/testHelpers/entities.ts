@@ -118,6 +118,10 @@ export const MockOrganization: TypesGen.Organization = {
   name: "Test Organization",
   created_at: "",
   updated_at: "",
+  is_default: true,
+  display_name: "",
+  icon: "",
+  description: "",
 }
 
 export const MockProvisioner: TypesGen.ProvisionerDaemon = {
@@ -190,6 +194,7 @@ export const MockTemplate: TypesGen.Template = {
   created_by_id: "test-creator-id",
   created_by_name: "test_creator",
   icon: "/icon/code.svg",
+  quota_weight: 1,
 }
 
 export const MockWorkspaceApp: TypesGen.WorkspaceApp = {
@@ -913,8 +918,17 @@ export const MockWorkspaceQuota: TypesGen.WorkspaceQuota = {
 export const MockGroup: TypesGen.Group = {
   id: "fbd2116a-8961-4954-87ae-e4575bd29ce0",
   name: "Front-End",
+  display_name: "Front-End",
+  avatar_url: "",
+  description: "",
   organization_id: MockOrganization.id,
+  source: "user",
   members: [MockUser, MockUser2],
+  members_count: 2,
+  metadata: {},
+  autostop_schedule: "",
+  max_ttl_ms: 0,
+  quota_allowance: 0,
 }
 
 export const MockTemplateACL: TypesGen.TemplateACL = {
@@ -222,7 +222,7 @@ export const handlers = [
 
   // Groups
   rest.get("/api/v2/organizations/:organizationId/groups", (req, res, ctx) => {
-    return res(ctx.status(200), ctx.json([MockGroup]))
+    return res(ctx.status(200), ctx.json({ groups: [MockGroup], count: 1 }))
   }),
 
   rest.post(
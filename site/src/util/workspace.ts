@@ -68,6 +68,8 @@ export const getDisplayWorkspaceBuildStatus = (
 export const DisplayWorkspaceBuildInitiatedByLanguage = {
   autostart: "system/autostart",
   autostop: "system/autostop",
+  maintenance: "system/maintenance",
+  agentFailure: "system/agent_failure",
 }
 
 export const getDisplayWorkspaceBuildInitiatedBy = (
@@ -80,6 +82,10 @@ export const getDisplayWorkspaceBuildInitiatedBy = (
       return DisplayWorkspaceBuildInitiatedByLanguage.autostart
     case "autostop":
       return DisplayWorkspaceBuildInitiatedByLanguage.autostop
+    case "maintenance":
+      return DisplayWorkspaceBuildInitiatedByLanguage.maintenance
+    case "agent_failure":
+      return DisplayWorkspaceBuildInitiatedByLanguage.agentFailure
   }
 }
 